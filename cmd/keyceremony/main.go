@@ -0,0 +1,40 @@
+// Command keyceremony generates the seed of a new master key version offline.
+//
+//	keyceremony -version 2               сид версии 2 и контрольные адреса для сверки
+//	keyceremony -version 3 -entropy 128  то же с 12 словами вместо 24
+//
+// Команда не читает конфигурацию и не подключается к базе: сид печатается в stdout в JSON
+// только на машине церемонии и добавляется в настроенные сиды до POST /admin/key_rotations.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+func main() {
+	var (
+		version     = flag.Int("version", 0, "key version of the new seed, the current maximum plus one")
+		entropyBits = flag.Int("entropy", 256, "seed entropy in bits: 128, 160, 192, 224 or 256")
+	)
+	flag.Parse()
+
+	if err := run(*version, *entropyBits); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(version, entropyBits int) error {
+	ceremony, err := usecases.GenerateKeyCeremony(version, entropyBits)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(ceremony)
+}
@@ -0,0 +1,122 @@
+// Command replay reads the order and transaction event journals for debugging and audits.
+//
+//	replay -order 42              история ордера
+//	replay -tx 0xabc...           история транзакции
+//	replay -until 2026-10-01      проекции дашборда, восстановленные из журналов на момент until
+//	replay -apply                 то же на текущий момент с заменой живых проекций дашборда
+//
+// Результат печатается в stdout в JSON. Конфигурация и подключение к базе те же, что у сервера.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	cfg "github.com/sand/crypto-p2p-trading-app/backend/config"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+func main() {
+	time.Local = time.UTC
+
+	var (
+		orderID = flag.Int64("order", 0, "print the event history of the order")
+		txHash  = flag.String("tx", "", "print the event history of the transaction")
+		until   = flag.String("until", "", "replay the journals up to this RFC 3339 timestamp or date, now by default")
+		apply   = flag.Bool("apply", false, "replace the live dashboard projections with the replayed ones")
+	)
+	flag.Parse()
+
+	if err := run(*orderID, *txHash, *until, *apply); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(orderID int64, txHash, until string, apply bool) error {
+	config, err := cfg.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Журнал пишется в stderr, чтобы stdout содержал только результат
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	pg, err := database.New(config,
+		database.MaxPoolSize(2),
+		database.ConnTimeout(config.DB.ConnectTimeout),
+		database.Isolation(pgx.ReadCommitted),
+	)
+	if err != nil {
+		return fmt.Errorf("postgres connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx := context.Background()
+	service := usecases.NewStateEventService(logger, repository.NewStateEventsRepository(logger, pg),
+		repository.NewDashboardRepository(logger, pg))
+
+	switch {
+	case orderID != 0:
+		events, err := service.GetOrderHistory(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		return printJSON(events)
+	case txHash != "":
+		events, err := service.GetTransactionHistory(ctx, txHash)
+		if err != nil {
+			return err
+		}
+		return printJSON(events)
+	}
+
+	at := time.Now().UTC()
+	if until != "" {
+		if apply {
+			return fmt.Errorf("-apply replaces the live projections and is allowed for the current state only")
+		}
+		if at, err = parseUntil(until); err != nil {
+			return err
+		}
+	}
+
+	replay, err := service.ReplayDashboard(ctx, at)
+	if err != nil {
+		return err
+	}
+	if apply {
+		if err = service.ApplyReplay(ctx, replay); err != nil {
+			return err
+		}
+		logger.Info("Dashboard projections replaced from the event journals",
+			"orders", len(replay.Orders), "deposits", len(replay.Deposits))
+	}
+	return printJSON(replay)
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -until %q: expected RFC 3339 timestamp or date", value)
+	}
+	return t, nil
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
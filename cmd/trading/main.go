@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
 	"log"
 	"log/slog"
@@ -10,20 +13,38 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	cfg "github.com/sand/crypto-p2p-trading-app/backend/config"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/shared"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/mocked"
 	repository "github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/workers"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/blockrec"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/captcha"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/chaos"
 	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/errreport"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/explorer"
+	applog "github.com/sand/crypto-p2p-trading-app/backend/pkg/logger"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcbatch"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcmanager"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/safe"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/sftp"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/simchain"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/solana"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/ton"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/webhook"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 
 	amlservices "github.com/sand/crypto-p2p-trading-app/backend/internal/aml/clients"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/handlers"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/offramp/providers"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
 )
 
@@ -68,7 +89,40 @@ func main() {
 		}
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, opts))
+	reportErrors := config.Tracing.SentryDSN != ""
+	if reportErrors {
+		reporter, err := errreport.NewSentryReporter(errreport.SentryOptions{
+			DSN:         config.Tracing.SentryDSN,
+			Environment: config.App.Environment,
+			Release:     config.Tracing.SentryRelease,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		errreport.SetDefault(reporter)
+		defer reporter.Flush(2 * time.Second)
+	}
+
+	logger, logCloser, err := applog.New(applog.Options{
+		Level:  opts.Level,
+		Format: config.Log.Format,
+		Sampling: applog.SamplingOptions{
+			MaxLevel:   slog.LevelDebug,
+			First:      config.Log.SampleFirst,
+			Thereafter: config.Log.SampleThereafter,
+		},
+		Sink:         config.Log.Sink,
+		SinkURL:      config.Log.SinkURL,
+		SinkIndex:    config.Log.SinkIndex,
+		SinkLabels:   map[string]string{"app": config.App.Name, "env": config.App.Environment},
+		Extract:      shared.LogAttrs,
+		ReportErrors: reportErrors,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logCloser.Close()
+
 	logger.Warn("Starting application with configuration",
 		"debug", config.App.Debug,
 		"blockchain_debug", config.Blockchain.Debug,
@@ -76,6 +130,64 @@ func main() {
 		"server_port", config.HTTP.Port,
 		"database_url", config.DB.DatabaseURL)
 
+	// Внедрение сбоев RPC, базы и AML для проверки отказоустойчивости, только в сборке с тегом chaos
+	faults, err := chaos.New(chaos.Config{
+		Enabled:        config.Chaos.Enabled,
+		RPCFailureRate: config.Chaos.RPCFailureRate,
+		DBErrorRate:    config.Chaos.DBErrorRate,
+		AMLDelayRate:   config.Chaos.AMLDelayRate,
+		AMLDelay:       time.Duration(config.Chaos.AMLDelay) * time.Second,
+	})
+	if err != nil {
+		logger.Error("Failed to configure fault injection", "error", err)
+		log.Fatal(err)
+	}
+	if faults != nil {
+		logger.Warn("Fault injection enabled",
+			"rpc_failure_rate", config.Chaos.RPCFailureRate,
+			"db_error_rate", config.Chaos.DBErrorRate,
+			"aml_delay_rate", config.Chaos.AMLDelayRate)
+	}
+
+	// Режим симуляции: BSC заменяется локальной симулированной сетью с тестовым USDT
+	var simChain *simchain.Chain
+	if config.Blockchain.Simulated {
+		simChain, err = initSimulatedChain(ctx, logger, config)
+		if err != nil {
+			logger.Error("Failed to start simulated chain", "error", err)
+			log.Fatal(err)
+		}
+		defer simChain.Close()
+	}
+
+	// Запись обработанных сканером блоков вместе с ответами RPC для последующего воспроизведения
+	if config.Blockchain.RecordDir != "" && config.Blockchain.ReplayDir != "" {
+		logger.Error("Block recording and replay cannot be enabled together")
+		log.Fatal("block recording and replay cannot be enabled together")
+	}
+	blockRecorder, err := blockrec.NewRecorder(config.Blockchain.RecordDir)
+	if err != nil {
+		logger.Error("Failed to configure block recording", "error", err)
+		log.Fatal(err)
+	}
+	if blockRecorder != nil {
+		logger.Warn("Block recording enabled", "dir", config.Blockchain.RecordDir)
+	}
+	// Воспроизведение блоков — пробный прогон сканера: подпись запрещена, внешние AML провайдеры не опрашиваются,
+	// воркеры выплат, подметания и доставки во внешние системы не запускаются
+	replaying := config.Blockchain.ReplayDir != ""
+
+	// Все RPC клиенты создаются через менеджер эндпоинтов с ограничением частоты запросов
+	rpcmanager.SetDefault(rpcmanager.NewManager(rpcmanager.Limits{
+		RequestsPerSecond: config.Blockchain.RPCRateLimit,
+		Burst:             config.Blockchain.RPCBurst,
+		MaxQueue:          config.Blockchain.RPCMaxQueue,
+		DailyBudget:       config.Blockchain.RPCDailyBudget,
+		Timeout:           time.Duration(config.Timeouts.RPC) * time.Second,
+		BreakerThreshold:  config.Blockchain.RPCBreakerThreshold,
+		BreakerCooldown:   time.Duration(config.Blockchain.RPCBreakerCooldown) * time.Second,
+	}).WithTransport(blockRecorder.Transport(faults.Transport(http.DefaultTransport))))
+
 	// Connect to Database
 	pg, err := database.New(config,
 		database.MaxPoolSize(config.DB.PoolMax),
@@ -97,29 +209,62 @@ func main() {
 	}
 	logger.Info("Database migrations completed successfully")
 
+	// Репозитории получают соединение через DBGetter, ошибки базы внедряются здесь
+	pg.DBGetter = faults.DB(pg.DBGetter)
+
 	// Create repositories
 	ordersRepository := repository.NewOrdersRepository(logger, pg)
 	walletsRepository := repository.NewWalletsRepository(logger, pg)
+	refundsRepository := repository.NewRefundsRepository(logger, pg)
 	transactionsRepository := repository.NewTransactionsRepository(logger, pg, ordersRepository, walletsRepository)
+	auditRepository := repository.NewAuditRepository(logger, pg)
+	twoFactorRepository := repository.NewTwoFactorRepository(logger, pg)
+	sessionsRepository := repository.NewSessionsRepository(logger, pg)
 
 	// Create usecases and components
-	dataService := mocked.NewDataService(logger)
+	if config.HTTP.WSBatchInterval <= 0 {
+		logger.Error("WebSocket batch interval must be positive", "interval", config.HTTP.WSBatchInterval)
+		log.Fatal("websocket batch interval must be positive")
+	}
+	dataService := mocked.NewDataService(logger, time.Duration(config.HTTP.WSBatchInterval)*time.Millisecond)
+	dataService.SetFeedSymbols(config.MarketData.Symbols)
 	dataService.InitializeTradingPairs()
 
-	orderService := usecases.NewOrderService(ordersRepository)
-	transactionService := usecases.NewTransactionService(transactionsRepository)
+	auditService := usecases.NewAuditService(logger, auditRepository)
 
-	walletService, err := usecases.NewWalletService(logger, config.WalletSeed, transactionService, walletsRepository, orderService)
+	// Реестр активов: контракт, точность, лимиты ордеров, комиссия вывода и AML порог
+	assetRegistry, err := initAssetRegistry(ctx, logger, pg, auditService)
 	if err != nil {
-		logger.Error("Failed to create wallet service", "error", err)
+		logger.Error("Failed to load asset registry", "error", err)
 		log.Fatal(err)
 	}
 
-	// Инициализируем AML сервис
-	amlService := initAMLService(logger, config, pg, transactionService)
+	orderService := usecases.NewOrderService(ordersRepository, assetRegistry, orderFingerprintDecimals(config), config.Orders.DepositMemos)
+	transactionService := usecases.NewTransactionService(logger, transactionsRepository)
+	twoFactorService := usecases.NewTwoFactorService(logger, twoFactorRepository, auditService,
+		config.Security.TwoFactorIssuer, config.Security.TwoFactorEnforced)
+	if err = twoFactorService.SetAdminSecrets(config.Admin.TwoFactorSecrets); err != nil {
+		logger.Error("Failed to configure admin two-factor secrets", "error", err)
+		log.Fatal(err)
+	}
+	if len(config.Admin.TwoFactorSecrets) == 0 {
+		logger.Warn("No admin two-factor secrets configured, admin actions that move funds are denied to everyone")
+	}
+	notifier := usecases.NewLogNotifier(logger)
 
-	// Initialize and run workers
-	initAndRunWorkers(ctx, logger, config, orderService, transactionService, walletService, amlService)
+	// Ссылки на обозреватели блоков в ответах API и уведомлениях
+	explorerLinks, err := explorer.New(config.Blockchain.ExplorerURLs)
+	if err != nil {
+		logger.Error("Failed to configure block explorer links", "error", err)
+		log.Fatal(err)
+	}
+	sessionService := usecases.NewSessionService(logger, sessionsRepository, auditService, notifier, nil, twoFactorService, usecases.SessionConfig{
+		TTL:         time.Duration(config.Security.SessionTTL) * time.Hour,
+		LoginSecret: config.Security.SessionLoginSecret,
+	})
+	if config.Security.SessionLoginSecret == "" {
+		logger.Warn("No session login secret configured, logins are disabled")
+	}
 
 	// create gRPC clients
 	bscClient, err := usecases.GetBSCClient(ctx, logger)
@@ -128,28 +273,806 @@ func main() {
 	}
 	defer bscClient.Close()
 
+	// On-chain черный список USDT: используется при переводах и в AML проверках
+	tokenBlacklist := amlservices.NewTokenBlacklistService(logger, bscClient, assetRegistry.Default().Contract)
+
+	// Пауза, смена параметров или владельца контракта токена приостанавливает выводы и свипы до подтверждения
+	tokenMonitor, err := initTokenMonitor(ctx, logger, config, pg, walletsRepository, auditService, assetRegistry)
+	if err != nil {
+		logger.Error("Failed to configure token monitoring", "error", err)
+		log.Fatal(err)
+	}
+
+	invoiceRates, err := usecases.NewStaticRateProvider(config.Orders.InvoiceRates)
+	if err != nil {
+		logger.Error("Failed to parse invoice rates", "error", err)
+		log.Fatal(err)
+	}
+	if len(config.Orders.InvoiceRates) == 0 {
+		logger.Warn("INVOICE_RATES is not configured: invoices, fiat payouts and fiat valuations are unavailable")
+	}
+
+	// Журнал исходящих транзакций: комиссии платформы и потраченный газ для отчетов P&L
+	ledgerService, err := usecases.NewLedgerService(logger, repository.NewLedgerRepository(logger, pg), assetRegistry, invoiceRates, usecases.LedgerConfig{
+		RateCurrency:   config.Reports.RateCurrency,
+		SettleInterval: time.Duration(config.Reports.LedgerSettleInterval) * time.Second,
+		GasBudgets: usecases.GasBudgetConfig{
+			DailyBNB:     config.Reports.GasBudgetDailyBNB,
+			DailyFiat:    config.Reports.GasBudgetDailyFiat,
+			PerOperation: config.Reports.GasBudgetPerOperation,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to configure ledger", "error", err)
+		log.Fatal(err)
+	}
+
+	// Фоновые обработчики отчитываются о работе в реестр: /admin/workers и проверка готовности /ready
+	workerRegistry := usecases.NewWorkerRegistry()
+
+	// Сиды по версиям: новые кошельки выводятся из активного, старые остаются подписываемыми после ротации
+	keyring, err := usecases.NewKeyring(usecases.KeyringConfig{
+		Seed:           config.WalletSeed,
+		Seeds:          config.Blockchain.WalletSeeds,
+		ActiveVersion:  config.Blockchain.ActiveKeyVersion,
+		ServiceVersion: config.Blockchain.ServiceKeyVersion,
+	})
+	if err != nil {
+		logger.Error("Failed to configure wallet keyring", "error", err)
+		log.Fatal(err)
+	}
+	logger.Info("Wallet keyring loaded", "versions", keyring.Versions(),
+		"active_version", keyring.ActiveVersion(), "service_version", keyring.ServiceVersion())
+
+	walletService, err := usecases.NewWalletService(logger, keyring, transactionService, walletsRepository, orderService, auditService, tokenBlacklist, tokenMonitor,
+		assetRegistry, ledgerService, usecases.ForwarderConfig{FactoryAddress: config.Forwarders.FactoryAddress, InitCodeHash: config.Forwarders.InitCodeHash},
+		usecases.StuckTxConfig{MaxSpeedups: config.Blockchain.MaxSpeedups, AutoCancel: config.Blockchain.StuckTxAutoCancel},
+		usecases.WalletReuseConfig{Enabled: config.Wallets.ReuseEnabled, MinCompletedOrders: config.Wallets.ReuseMinOrders},
+		usecases.WalletCacheConfig{Addresses: config.Wallets.AddressCacheSize, Balances: config.Wallets.BalanceCacheSize},
+		workerRegistry.Register("wallet_balance_monitor", usecases.BalanceMonitorInterval))
+	if err != nil {
+		logger.Error("Failed to create wallet service", "error", err)
+		log.Fatal(err)
+	}
+
+	// Инициализируем AML сервис
+	amlService, riskRollups := initAMLService(logger, config, pg, transactionService, tokenBlacklist, assetRegistry, faults)
+
+	if replaying {
+		walletService.DisableSigning("recorded blocks are being replayed")
+		amlService.SetReplayMode()
+		logger.Warn("REPLAY MODE: signing is disabled, external AML providers and payout workers are not started")
+	}
+
+	// Депозиты из мемпула — только предварительные уведомления, зачисление выполняется по блокам
+	mempoolDeposits := usecases.NewMempoolDepositService(logger, walletsRepository, usecases.NewLogNotifier(logger), time.Duration(config.Blockchain.MempoolDepositTTL)*time.Minute)
+
+	// Возвраты депозитов отправителю: по правилам (AML отказ, переплата) и вручную администратором
+	refundService := usecases.NewRefundService(logger, refundsRepository, transactionsRepository, walletsRepository,
+		walletService, auditService, notifier, explorerLinks, config.Orders.AutoExecuteRefunds)
+	transactionService.SetOverpaymentRefunds(refundService)
+	walletService.SetReplacementObserver(refundService)
+
+	// Лимиты скорости вывода: по пользователю и уровню, плюс общий отток с горячих кошельков
+	withdrawalLimits, err := usecases.NewWithdrawalLimitService(logger, repository.NewWithdrawalsRepository(logger, pg),
+		walletsRepository, assetRegistry, auditService, usecases.WithdrawalLimitsConfig{
+			Tiers:        config.Withdrawals.Tiers,
+			DefaultTier:  config.Withdrawals.DefaultTier,
+			GlobalHourly: config.Withdrawals.GlobalHourly,
+			GlobalDaily:  config.Withdrawals.GlobalDaily,
+		})
+	if err != nil {
+		logger.Error("Failed to configure withdrawal limits", "error", err)
+		log.Fatal(err)
+	}
+
+	// Пул заранее выведенных депозитных кошельков по уровням пользователей
+	walletPool, err := usecases.NewWalletPoolService(logger, walletsRepository, walletService, withdrawalLimits, usecases.WalletPoolConfig{
+		Sizes:    config.Wallets.PoolSizes,
+		Account:  config.Wallets.PoolAccount,
+		Interval: time.Duration(config.Wallets.PoolInterval) * time.Second,
+	})
+	if err != nil {
+		logger.Error("Failed to configure wallet pool", "error", err)
+		log.Fatal(err)
+	}
+	if walletPool.Enabled() {
+		walletService.SetWalletPool(walletPool)
+	}
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "wallet_pool", "chain": "bsc"})
+		logger.Info("Starting wallet pool")
+		walletPool.Start(ctx)
+	}()
+
+	// Омнибус режим: депозиты выбранных мерчантов принимаются на общий горячий кошелек и сопоставляются по сумме или мемо
+	omnibusDeposits, err := usecases.NewOmnibusDepositService(logger, repository.NewMerchantDepositsRepository(logger, pg),
+		walletsRepository, walletService, auditService, config.Orders.OmnibusWalletPath)
+	if err != nil {
+		logger.Error("Failed to configure omnibus deposits", "error", err)
+		log.Fatal(err)
+	}
+	if omnibusDeposits.Enabled() {
+		if _, err = omnibusDeposits.Wallet(ctx); err != nil {
+			logger.Error("Failed to register omnibus deposit wallet", "error", err)
+			log.Fatal(err)
+		}
+		walletService.SetOmnibusWallets(omnibusDeposits)
+		orderService.SetOmnibus(omnibusDeposits)
+	}
+
+	// Multisig казначейство: крупные переводы оформляются предложениями Gnosis Safe
+	treasuryService, err := initTreasuryService(logger, config, pg, walletService, auditService, ledgerService, withdrawalLimits)
+	if err != nil {
+		logger.Error("Failed to configure treasury", "error", err)
+		log.Fatal(err)
+	}
+
+	// Пакетная отправка выводов: одна multisend транзакция вместо перевода на каждый вывод
+	withdrawalBatches, err := initWithdrawalBatchService(logger, config, pg, walletsRepository, walletService, withdrawalLimits, workerRegistry)
+	if err != nil {
+		logger.Error("Failed to configure withdrawal batches", "error", err)
+		log.Fatal(err)
+	}
+	if withdrawalBatches != nil {
+		treasuryService.SetWithdrawalBatcher(withdrawalBatches)
+	}
+
+	// AML проверка адресов получателей выводов и выплат до подписи перевода
+	destinationScreening := usecases.NewDestinationScreeningService(logger, amlService, repository.NewDestinationScreeningsRepository(logger, pg), auditService)
+	treasuryService.SetDestinationScreening(destinationScreening)
+
+	sweepService, err := initSweepService(logger, config, walletsRepository, walletService, treasuryService)
+	if err != nil {
+		logger.Error("Failed to configure sweeps", "error", err)
+		log.Fatal(err)
+	}
+
+	dormantSweeps, err := initDormantSweepService(logger, config, pg, walletService, treasuryService, transactionsRepository, refundService)
+	if err != nil {
+		logger.Error("Failed to configure dormant wallet sweeps", "error", err)
+		log.Fatal(err)
+	}
+
+	// Ротация сида: версия последней активированной ротации применяется до выдачи новых кошельков
+	keyRotations, err := usecases.NewKeyRotationService(logger, repository.NewKeyRotationsRepository(logger, pg), keyring, walletService,
+		treasuryService, auditService, time.Duration(config.Blockchain.KeyRotationInterval)*time.Second)
+	if err != nil {
+		logger.Error("Failed to configure key rotations", "error", err)
+		log.Fatal(err)
+	}
+	if err = keyRotations.Restore(ctx); err != nil {
+		logger.Error("Failed to restore rotated key version", "error", err)
+		log.Fatal(err)
+	}
+
+	// Неизрасходованный газ возвращается с депозитных кошельков на газовый кошелек
+	bnbDust, err := usecases.NewBNBDustService(logger, walletsRepository, walletService, usecases.BNBDustConfig{
+		GasWallet: config.Sweeps.BNBDustGasWallet,
+		MinAmount: config.Sweeps.BNBDustMinAmount,
+		Interval:  time.Duration(config.Sweeps.BNBDustInterval) * time.Hour,
+	})
+	if err != nil {
+		logger.Error("Failed to configure BNB dust consolidation", "error", err)
+		log.Fatal(err)
+	}
+
+	// Депозиты на кошельки просроченных ордеров возвращаются отправителю или переоткрывают ордер
+	lateDepositInterval := time.Duration(config.Orders.LateDepositInterval) * time.Second
+	lateDeposits, err := usecases.NewLateDepositService(logger, repository.NewLateDepositsRepository(logger, pg), refundService,
+		assetRegistry, notifier, workerRegistry.Register("late_deposits", lateDepositInterval), usecases.LateDepositConfig{
+			Policy:   entities.LateDepositPolicy(config.Orders.LateDepositPolicy),
+			Interval: lateDepositInterval,
+		})
+	if err != nil {
+		logger.Error("Failed to configure late deposit handling", "error", err)
+		log.Fatal(err)
+	}
+
+	// Проекции дашборда, запросы дашборда читают только их
+	dashboardRepository := repository.NewDashboardRepository(logger, pg)
+	dashboardInterval := time.Duration(config.Workers.DashboardInterval) * time.Second
+	dashboardService, err := usecases.NewDashboardService(logger, dashboardRepository, invoiceRates,
+		workerRegistry.Register("dashboard_projections", dashboardInterval), usecases.DashboardConfig{
+			Interval:        dashboardInterval,
+			RebuildInterval: time.Duration(config.Workers.DashboardRebuildInterval) * time.Hour,
+		})
+	if err != nil {
+		logger.Error("Failed to configure dashboard projections", "error", err)
+		log.Fatal(err)
+	}
+	// Журналы изменений ордеров и транзакций пишутся триггерами БД, здесь только чтение и воспроизведение
+	stateEvents := usecases.NewStateEventService(logger, repository.NewStateEventsRepository(logger, pg), dashboardRepository)
+
+	// Архив сырых данных сети по зачисленным депозитам для compliance
+	evidenceInterval := time.Duration(config.Workers.EvidenceInterval) * time.Second
+	depositEvidence, err := usecases.NewDepositEvidenceService(logger, repository.NewDepositEvidenceRepository(logger, pg),
+		rpcbatch.New(bscClient.Client(), rpcbatch.DefaultBatchSize), workerRegistry.Register("deposit_evidence", evidenceInterval),
+		usecases.DepositEvidenceConfig{
+			Interval:    evidenceInterval,
+			BatchSize:   config.Workers.EvidenceBatchSize,
+			MaxAttempts: config.Workers.EvidenceMaxAttempts,
+		})
+	if err != nil {
+		logger.Error("Failed to configure deposit evidence archive", "error", err)
+		log.Fatal(err)
+	}
+
+	// Кеш курсов для фиатной оценки ответов API, история курсов — для оценки на момент транзакции
+	priceCacheInterval := time.Duration(config.Orders.PriceCacheInterval) * time.Second
+	priceCache, err := usecases.NewPriceCache(logger, repository.NewPricesRepository(logger, pg), assetRegistry, invoiceRates,
+		workerRegistry.Register("price_cache", priceCacheInterval), usecases.PriceCacheConfig{
+			Currencies:       config.Orders.PriceCurrencies,
+			Interval:         priceCacheInterval,
+			SnapshotInterval: time.Duration(config.Orders.PriceSnapshotInterval) * time.Minute,
+			MaxAge:           time.Duration(config.Orders.PriceMaxAge) * time.Minute,
+		})
+	if err != nil {
+		logger.Error("Failed to configure price cache", "error", err)
+		log.Fatal(err)
+	}
+
+	// Перенос кошельков из предыдущей системы с догрузкой балансов и истории депозитов
+	walletImportInterval := time.Duration(config.Wallets.ImportInterval) * time.Second
+	walletImports, err := usecases.NewWalletImportService(logger, repository.NewWalletImportsRepository(logger, pg), walletsRepository,
+		walletService, auditService, workerRegistry.Register("wallet_imports", walletImportInterval), walletImportInterval)
+	if err != nil {
+		logger.Error("Failed to configure wallet imports", "error", err)
+		log.Fatal(err)
+	}
+
+	// Выплаты мерчантам по завершенным ордерам за вычетом комиссии платформы
+	settlementService, err := usecases.NewSettlementService(logger, repository.NewSettlementsRepository(logger, pg), walletService,
+		treasuryService, auditService, notifier, usecases.SettlementConfig{
+			PayoutWalletID: config.Settlements.PayoutWalletID,
+			Interval:       time.Duration(config.Settlements.Interval) * time.Hour,
+			Delay:          time.Duration(config.Settlements.Delay) * time.Hour,
+			FeeBPS:         config.Settlements.FeeBPS,
+			MinAmount:      config.Settlements.MinAmount,
+		})
+	if err != nil {
+		logger.Error("Failed to configure merchant settlements", "error", err)
+		log.Fatal(err)
+	}
+
+	// Фиатные выплаты продавцам через off-ramp провайдеров
+	fiatPayouts, err := initFiatPayoutService(logger, config, pg, assetRegistry, invoiceRates, notifier)
+	if err != nil {
+		logger.Error("Failed to configure fiat payouts", "error", err)
+		log.Fatal(err)
+	}
+
+	forwarderSweeps, err := initForwarderSweepService(logger, config, walletsRepository, walletService)
+	if err != nil {
+		logger.Error("Failed to configure forwarder sweeps", "error", err)
+		log.Fatal(err)
+	}
+
+	// Initialize and run workers
+	// Число подтверждений депозита зависит от его суммы
+	confirmationPolicy, err := usecases.NewConfirmationPolicy(logger, invoiceRates, usecases.ConfirmationPolicyConfig{
+		Tiers:    config.Blockchain.ConfirmationTiers,
+		Currency: config.Blockchain.ConfirmationTiersCurrency,
+		Default:  config.Blockchain.RequiredConfirmations,
+	})
+	if err != nil {
+		logger.Error("Failed to configure confirmation tiers", "error", err)
+		log.Fatal(err)
+	}
+
+	// Удержание необычно крупных депозитов до ручного освобождения комплаенсом
+	depositHolds, err := usecases.NewDepositHoldService(logger, transactionsRepository, walletsRepository, auditService, notifier, usecases.DepositHoldConfig{
+		Multiple:   config.Orders.DepositHoldMultiple,
+		MinHistory: config.Orders.DepositHoldMinHistory,
+	})
+	if err != nil {
+		logger.Error("Failed to configure deposit holds", "error", err)
+		log.Fatal(err)
+	}
+
+	// Пыль, серии одинаковых микропереводов и прощупывание адресов записываются, но не зачисляются в ордера
+	depositFilters, err := usecases.NewDepositFilterService(logger, transactionsRepository, auditService, usecases.DepositFilterConfig{
+		SpamThreshold: config.Orders.DepositSpamThreshold,
+		SpamWindow:    time.Duration(config.Orders.DepositSpamWindow) * time.Minute,
+		ProbeWallets:  config.Orders.DepositProbeWallets,
+		ProbeWindow:   time.Duration(config.Orders.DepositProbeWindow) * time.Minute,
+	})
+	if err != nil {
+		logger.Error("Failed to configure deposit filters", "error", err)
+		log.Fatal(err)
+	}
+	if config.Orders.DepositProbeBlacklist {
+		depositFilters.SetProbingBlacklist(amlService)
+	}
+
+	scannerStates := repository.NewScannerStatesRepository(logger, pg)
+	bscProcessor := initAndRunWorkers(ctx, logger, config, workerRegistry, orderService, transactionService, walletService, amlService, mempoolDeposits, refundService, treasuryService, sweepService, confirmationPolicy, depositHolds, depositFilters, scannerStates, blockRecorder)
+
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "ledger_settler", "chain": "bsc"})
+		logger.Info("Starting ledger settlement worker")
+		ledgerService.Start(ctx)
+	}()
+
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "dormant_sweeper", "chain": "bsc"})
+			logger.Info("Starting dormant wallet sweep worker")
+			dormantSweeps.Start(ctx)
+		}()
+	}
+
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "key_rotation_sweeper", "chain": "bsc"})
+			logger.Info("Starting key rotation sweep worker")
+			keyRotations.Start(ctx)
+		}()
+	}
+
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "bnb_dust_consolidator", "chain": "bsc"})
+			logger.Info("Starting BNB dust consolidation worker")
+			bnbDust.Start(ctx)
+		}()
+	}
+
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "late_deposits"})
+			logger.Info("Starting late deposit worker")
+			lateDeposits.Start(ctx)
+		}()
+	}
+
+	if withdrawalBatches != nil && !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "withdrawal_batches", "chain": "bsc"})
+			logger.Info("Starting withdrawal batch worker")
+			withdrawalBatches.Start(ctx)
+		}()
+	}
+
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "wallet_imports", "chain": "bsc"})
+		logger.Info("Starting wallet import backfill worker")
+		walletImports.Start(ctx)
+	}()
+
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "deposit_evidence", "chain": "bsc"})
+		logger.Info("Starting deposit evidence archiver")
+		depositEvidence.Start(ctx)
+	}()
+
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "price_cache"})
+		logger.Info("Starting price cache")
+		priceCache.Start(ctx)
+	}()
+
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "dashboard_projections"})
+		logger.Info("Starting dashboard projector")
+		dashboardService.Start(ctx)
+	}()
+
+	// Свечи реальных пар из потока биржи, пропущенные за обрыв свечи догружаются по REST
+	if len(config.MarketData.Symbols) > 0 {
+		marketInterval := time.Duration(config.MarketData.Interval) * time.Minute
+		marketFeed, err := workers.NewMarketFeed(logger, workers.MarketFeedConfig{
+			StreamURL: config.MarketData.StreamURL,
+			Symbols:   config.MarketData.Symbols,
+			Interval:  marketInterval,
+			Window:    config.MarketData.Window,
+		}, dataService, repository.NewCandlesRepository(logger, pg),
+			workers.NewBinanceKlines(logger, config.MarketData.RESTURL, time.Duration(config.Timeouts.HTTP)*time.Second),
+			workerRegistry.Register("market_feed", marketInterval))
+		if err != nil {
+			logger.Error("Failed to configure market data feed", "error", err)
+			log.Fatal(err)
+		}
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "market_feed"})
+			marketFeed.Start(ctx)
+		}()
+	}
+
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "settlement", "chain": "bsc"})
+			logger.Info("Starting merchant settlement worker")
+			settlementService.Start(ctx)
+		}()
+	}
+
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "fiat_payouts"})
+			logger.Info("Starting fiat payout worker")
+			fiatPayouts.Start(ctx)
+		}()
+	}
+
+	if forwarderSweeps != nil && !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "forwarder_sweeper", "chain": "bsc"})
+			logger.Info("Starting forwarder sweep worker")
+			forwarderSweeps.Start(ctx)
+		}()
+	}
+
+	if config.Blockchain.TokenMonitoring {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "token_monitor", "chain": "bsc"})
+			logger.Info("Starting token admin events worker")
+			tokenMonitor.Start(ctx, bscClient)
+		}()
+	}
+
 	// Create handlers
-	websocketManager := handlers.NewWebSocketManager(logger)
-	httpHandler := handlers.NewHTTPHandler(logger, bscClient, dataService, walletService, orderService, transactionService)
+	websocketManager, err := handlers.NewWebSocketManager(logger, handlers.WebSocketConfig{
+		Compression:      config.HTTP.WSCompression,
+		CompressionLevel: config.HTTP.WSCompressionLevel,
+	})
+	if err != nil {
+		logger.Error("Failed to configure WebSocket compression", "error", err)
+		log.Fatal(err)
+	}
+	sessionHandler := handlers.NewSessionHandler(logger, sessionService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(logger, twoFactorService, sessionHandler)
+	accountClosuresRepository := repository.NewAccountClosuresRepository(logger, pg)
+	abuseGuard := initAbuseGuard(logger, config, ordersRepository, walletsRepository, accountClosuresRepository)
+	httpHandler := handlers.NewHTTPHandler(logger, bscClient, dataService, walletService, orderService, transactionService, twoFactorHandler, abuseGuard, treasuryService, explorerLinks, priceCache)
 	wsHandler := handlers.NewWebSocketHandler(logger, dataService, websocketManager)
+	depositHandler := handlers.NewDepositHandler(logger, mempoolDeposits)
+	orderBatches, err := usecases.NewOrderBatchService(logger, ordersRepository, walletService, orderService, usecases.OrderBatchConfig{
+		MaxOrders: config.Orders.BatchMaxOrders,
+		ChunkSize: config.Orders.BatchChunkSize,
+	})
+	if err != nil {
+		logger.Error("Failed to configure order batches", "error", err)
+		log.Fatal(err)
+	}
+	orderBatchHandler := handlers.NewOrderBatchHandler(logger, orderBatches, abuseGuard)
+	orderSchedules, err := usecases.NewOrderScheduleService(logger, repository.NewOrderSchedulesRepository(logger, pg), walletService, orderService, notifier,
+		usecases.OrderScheduleConfig{
+			Interval:   time.Duration(config.Orders.ScheduleInterval) * time.Second,
+			MaxPerUser: config.Orders.MaxSchedulesPerUser,
+		})
+	if err != nil {
+		logger.Error("Failed to configure order schedules", "error", err)
+		log.Fatal(err)
+	}
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "order_schedules"})
+		orderSchedules.Start(ctx)
+	}()
+	orderScheduleHandler := handlers.NewOrderScheduleHandler(logger, orderSchedules)
+	receiptsRepository := repository.NewReceiptsRepository(logger, pg)
+	receipts, err := usecases.NewReceiptService(logger, receiptsRepository, assetRegistry, explorerLinks, notifier, usecases.ReceiptConfig{
+		Interval:    time.Duration(config.Orders.ReceiptInterval) * time.Second,
+		MaxAttempts: config.Orders.ReceiptMaxAttempts,
+	})
+	if err != nil {
+		logger.Error("Failed to configure receipts", "error", err)
+		log.Fatal(err)
+	}
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "receipts"})
+			receipts.Start(ctx)
+		}()
+	}
+	orderEventsHandler := handlers.NewOrderEventsHandler(logger, websocketManager)
+	orderCompletionsRepository := repository.NewOrderCompletionsRepository(logger, pg)
+	orderCompletions, err := usecases.NewOrderCompletionService(logger, orderCompletionsRepository,
+		settlementService, orderEventsHandler, webhook.NewClient(time.Duration(config.Timeouts.RPC)*time.Second), assetRegistry, receipts,
+		usecases.OrderCompletionConfig{
+			Interval:      time.Duration(config.Orders.CompletionInterval) * time.Second,
+			MaxAttempts:   config.Orders.CompletionMaxAttempts,
+			WebhookURL:    config.Orders.CompletionWebhookURL,
+			WebhookSecret: config.Orders.CompletionWebhookSecret,
+		})
+	if err != nil {
+		logger.Error("Failed to configure order completions", "error", err)
+		log.Fatal(err)
+	}
+	if sweepService != nil {
+		orderCompletions.SetSweepScheduler(sweepService)
+	}
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "order_completions"})
+			orderCompletions.Start(ctx)
+		}()
+	}
+	receiptHandler := handlers.NewReceiptHandler(logger, receipts)
+	transactionDetails := usecases.NewTransactionDetailService(logger, transactionsRepository, walletsRepository, ordersRepository,
+		repository.NewAMLRepository(logger, pg), bscClient, explorerLinks)
+	transactionDetailHandler := handlers.NewTransactionDetailHandler(logger, transactionDetails)
+	activityHandler := handlers.NewActivityHandler(logger,
+		usecases.NewActivityService(logger, repository.NewActivityRepository(logger, pg), assetRegistry))
+	balancesHandler := handlers.NewBalancesHandler(logger,
+		usecases.NewBalanceService(logger, repository.NewBalancesRepository(logger, pg), assetRegistry))
+	paymentLinks := usecases.NewPaymentLinkService(ordersRepository, walletsRepository, assetRegistry)
+	paymentHandler := handlers.NewPaymentHandler(logger, paymentLinks)
+	orderTemplates := usecases.NewOrderTemplateService(logger, repository.NewOrderTemplatesRepository(logger, pg), walletService, orderService,
+		paymentLinks, assetRegistry)
+	orderTemplateHandler := handlers.NewOrderTemplateHandler(logger, orderTemplates, abuseGuard)
+	orderCancellationHandler := handlers.NewOrderCancellationHandler(logger,
+		usecases.NewOrderCancellationService(logger, ordersRepository, walletPool, notifier))
+
+	invoicesRepository := repository.NewInvoicesRepository(logger, pg)
+	invoiceService := usecases.NewInvoiceService(logger, invoicesRepository, orderService, walletService, paymentLinks, assetRegistry, ordersRepository, invoiceRates)
+	invoiceHandler := handlers.NewInvoiceHandler(logger, invoiceService)
+	feeHandler := handlers.NewFeeHandler(logger, bscClient, usecases.NewFeeEstimateService(logger, walletService, invoiceRates))
+	refundHandler := handlers.NewRefundHandler(logger, refundService, twoFactorHandler)
+	treasuryOverview, err := initTreasuryOverviewService(logger, config, pg, transactionsRepository, walletService)
+	if err != nil {
+		logger.Error("Failed to initialize treasury overview", "error", err)
+		log.Fatal(err)
+	}
+	treasuryHandler := handlers.NewTreasuryHandler(logger, bscClient, treasuryService, treasuryOverview)
+	tokenEventsHandler := handlers.NewTokenEventsHandler(logger, tokenMonitor)
+	assetHandler := handlers.NewAssetHandler(logger, assetRegistry)
+	reportHandler := handlers.NewReportHandler(logger, ledgerService)
+	privacyService := usecases.NewPrivacyService(logger, repository.NewPrivacyRepository(logger, pg),
+		ordersRepository, walletsRepository, transactionsRepository, refundsRepository, invoicesRepository, sessionsRepository,
+		twoFactorRepository, auditService, config.Privacy.ExportAMLNotes)
+	privacyHandler := handlers.NewPrivacyHandler(logger, privacyService)
+
+	// Закрытие аккаунта: свип остатков, архивация кошельков, отзыв сессий и отложенное обезличивание
+	accountClosures, err := initAccountClosureService(logger, config, accountClosuresRepository, walletsRepository, walletService,
+		treasuryService, sessionsRepository, privacyService, auditService)
+	if err != nil {
+		logger.Error("Failed to configure account closures", "error", err)
+		log.Fatal(err)
+	}
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "account_closure", "chain": "bsc"})
+			logger.Info("Starting account closure worker")
+			accountClosures.Start(ctx)
+		}()
+	}
+	accountClosureHandler := handlers.NewAccountClosureHandler(logger, accountClosures, twoFactorHandler)
+
+	// SLA задержки подтверждения и зачисления депозитов по сетям
+	depositSLA, err := usecases.NewDepositSLAService(logger, transactionsRepository, usecases.DepositSLAConfig{
+		ConfirmationSLA: config.DepositSLA.ConfirmationSLA,
+		CreditSLA:       config.DepositSLA.CreditSLA,
+		HardDeadline:    time.Duration(config.DepositSLA.HardDeadline) * time.Minute,
+		Window:          time.Duration(config.DepositSLA.Window) * time.Hour,
+		Interval:        time.Duration(config.DepositSLA.Interval) * time.Second,
+	})
+	if err != nil {
+		logger.Error("Failed to configure deposit SLA monitoring", "error", err)
+		log.Fatal(err)
+	}
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "deposit_sla"})
+		logger.Info("Starting deposit SLA monitor")
+		depositSLA.Start(ctx)
+	}()
+	depositSLAHandler := handlers.NewDepositSLAHandler(logger, depositSLA)
+	dormantSweepsHandler := handlers.NewDormantSweepsHandler(logger, dormantSweeps)
+	bnbDustHandler := handlers.NewBNBDustHandler(logger, bnbDust, twoFactorHandler)
+
+	// Вывод пустых неиспользуемых кошельков из мониторинга
+	walletGC, err := usecases.NewWalletGCService(logger, walletsRepository, walletService, usecases.WalletGCConfig{
+		Retention:  time.Duration(config.Wallets.GCRetention) * 24 * time.Hour,
+		Interval:   time.Duration(config.Wallets.GCInterval) * time.Hour,
+		NativeDust: config.Wallets.GCNativeDust,
+	})
+	if err != nil {
+		logger.Error("Failed to configure wallet garbage collection", "error", err)
+		log.Fatal(err)
+	}
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "wallet_gc", "chain": "bsc"})
+		logger.Info("Starting wallet garbage collector")
+		walletGC.Start(ctx)
+	}()
+	// Депозиты USDT в сети TON на общий кошелек с сопоставлением ордеров по мемо
+	// Сканер TON отчитывается в реестр, только если прием депозитов включен
+	var tonScanner usecases.WorkerTracker
+	if config.TON.DepositWallet != "" {
+		tonScanner = workerRegistry.Register("ton_scanner", time.Duration(config.TON.PollInterval)*time.Second)
+	}
+	tonDeposits, err := usecases.NewTonDepositService(logger, ton.NewClient(config.TON.APIURL, config.TON.APIKey, time.Duration(config.Timeouts.HTTP)*time.Second),
+		walletsRepository, transactionsRepository, transactionService, depositFilters, amlService, orderService, assetRegistry, tonScanner, usecases.TonDepositConfig{
+			DepositWallet: config.TON.DepositWallet,
+			JettonMaster:  config.TON.JettonMaster,
+			Network:       config.TON.Network,
+			Interval:      time.Duration(config.TON.PollInterval) * time.Second,
+		})
+	if err != nil {
+		logger.Error("Failed to configure TON deposits", "error", err)
+		log.Fatal(err)
+	}
+	// Ручная пауза сканеров сетей переживает перезапуск: TON восстанавливается до запуска сканирования,
+	// сканер BSC загружает паузу вместе с контрольной точкой
+	pausableScanners := map[entities.Chain]usecases.PausableScanner{entities.ChainBSC: bscProcessor}
+	if tonDeposits.Enabled() {
+		pausableScanners[entities.ChainTON] = tonDeposits
+	}
+	scannerControl := usecases.NewScannerControlService(logger, scannerStates, auditService, pausableScanners)
+	if err = scannerControl.Restore(ctx); err != nil {
+		logger.Error("Failed to restore scanner pauses", "error", err)
+		log.Fatal(err)
+	}
+	go func() {
+		defer errreport.Recover(map[string]string{"worker": "ton_deposits", "chain": "ton"})
+		logger.Info("Starting TON deposit scanner")
+		tonDeposits.Start(ctx)
+	}()
+	tonDepositHandler := handlers.NewTonDepositHandler(logger, tonDeposits, abuseGuard)
+	workersHandler := handlers.NewWorkersHandler(logger, workerRegistry)
+	statusHandler := handlers.NewStatusHandler(logger, initStatusService(logger, config, pg, amlService, assetRegistry, tokenMonitor, bscProcessor, tonDeposits, dataService))
+	stuckTransactionsHandler := handlers.NewStuckTransactionsHandler(logger, bscClient, walletService, twoFactorHandler)
+	withdrawalLimitsHandler := handlers.NewWithdrawalLimitsHandler(logger, withdrawalLimits)
+	depositHoldsHandler := handlers.NewDepositHoldsHandler(logger, depositHolds)
+	riskRollupHandler := handlers.NewRiskRollupHandler(logger, riskRollups)
+	settlementHandler := handlers.NewSettlementHandler(logger, settlementService, twoFactorHandler)
+	fiatPayoutHandler := handlers.NewFiatPayoutHandler(logger, fiatPayouts, twoFactorHandler)
+	ownershipProofHandler := handlers.NewOwnershipProofHandler(logger, usecases.NewOwnershipProofService(logger, walletsRepository, walletService))
+	merchantDepositsHandler := handlers.NewMerchantDepositsHandler(logger, omnibusDeposits)
+	scannersHandler := handlers.NewScannersHandler(logger, scannerControl)
+	// Песочница обслуживается окружением тестовой сети, боевое окружение отклоняет запросы мерчантов песочницы
+	sandboxHandler := handlers.NewSandboxHandler(logger,
+		usecases.NewSandboxService(logger, repository.NewSandboxRepository(logger, pg), auditService, assetRegistry.Default().Network))
+	rpcEndpointsHandler := handlers.NewRPCEndpointsHandler(logger, usecases.NewRPCEndpointService(logger, rpcmanager.Default(), bscProcessor, auditService))
 
 	// Create router
 	router := mux.NewRouter()
 
+	// Внутренние заметки и теги поддержки к транзакциям и ордерам
+	staffAnnotationsHandler := handlers.NewStaffAnnotationsHandler(logger,
+		usecases.NewStaffAnnotationService(logger, repository.NewStaffAnnotationsRepository(logger, pg), auditService))
+
+	// Действия операторов при инцидентах вместо ручного SQL
+	runbooks, err := usecases.NewRunbookService(logger, orderCompletionsRepository, receiptsRepository, transactionService,
+		amlService, orderCompletions, auditService, usecases.RunbookConfig{Operators: config.Admin.RunbookOperators})
+	if err != nil {
+		logger.Error("Failed to configure runbooks", "error", err)
+		log.Fatal(err)
+	}
+
+	// Administrative routes are served either by the main router or by a dedicated (m)TLS listener
+	adminRegistrars := []handlers.AdminRoutesRegistrar{refundHandler, treasuryHandler, tokenEventsHandler, assetHandler, reportHandler, privacyHandler, accountClosureHandler, depositSLAHandler, stuckTransactionsHandler, withdrawalLimitsHandler, depositHoldsHandler, dormantSweepsHandler, bnbDustHandler, settlementHandler, fiatPayoutHandler, workersHandler, handlers.NewWalletImportHandler(logger, walletImports), riskRollupHandler, handlers.NewDashboardHandler(logger, dashboardService), handlers.NewStateEventsHandler(logger, stateEvents), handlers.NewDepositEvidenceHandler(logger, depositEvidence), rpcEndpointsHandler, merchantDepositsHandler, scannersHandler, sandboxHandler, handlers.NewDestinationScreeningsHandler(logger, destinationScreening), handlers.NewKeyRotationsHandler(logger, keyRotations, twoFactorHandler), staffAnnotationsHandler, handlers.NewTradingPairsHandler(logger, dataService), handlers.NewRunbooksHandler(logger, runbooks, twoFactorHandler)}
+	if simChain != nil {
+		adminRegistrars = append(adminRegistrars, handlers.NewSimulationHandler(logger, simChain))
+	}
+	// Кран Solana devnet пополняет новые депозитные кошельки Solana в тестовых окружениях
+	if config.Blockchain.Debug {
+		solanaClient := solana.NewClient(config.SolanaFaucet.NodeURL, time.Duration(config.Timeouts.RPC)*time.Second)
+		solanaFees, err := initSolanaFees(logger, config, solanaClient)
+		if err != nil {
+			logger.Error("Failed to configure Solana priority fees", "error", err)
+			log.Fatal(err)
+		}
+		solanaFaucet, err := usecases.NewSolanaFaucetService(logger, solanaClient, solanaFees,
+			walletsRepository, usecases.SolanaFaucetConfig{
+				Network:       config.SolanaFaucet.Network,
+				AirdropSOL:    config.SolanaFaucet.AirdropSOL,
+				Mint:          config.SolanaFaucet.Mint,
+				MintAuthority: config.SolanaFaucet.MintAuthority,
+				MintDecimals:  config.SolanaFaucet.MintDecimals,
+				MintAmount:    config.SolanaFaucet.MintAmount,
+				Interval:      time.Duration(config.SolanaFaucet.PollInterval) * time.Second,
+			})
+		if err != nil {
+			logger.Error("Failed to configure Solana faucet", "error", err)
+			log.Fatal(err)
+		}
+		if !replaying {
+			go func() {
+				defer errreport.Recover(map[string]string{"worker": "solana_faucet", "chain": "solana"})
+				logger.Info("Starting Solana devnet faucet")
+				solanaFaucet.Start(ctx)
+			}()
+		}
+		adminRegistrars = append(adminRegistrars, handlers.NewSolanaFaucetHandler(logger, solanaFaucet))
+	}
+	// ERC-20 approvals мастер-кошелька контрактам форвардеров, коллектора и multisend
+	if config.Allowances.OwnerPath != "" {
+		tokenApprovals, err := initTokenApprovals(logger, config, pg, walletService, auditService)
+		if err != nil {
+			logger.Error("Failed to configure token approvals", "error", err)
+			log.Fatal(err)
+		}
+		if !replaying {
+			go func() {
+				defer errreport.Recover(map[string]string{"worker": "token_approvals", "chain": "bsc"})
+				logger.Info("Starting token approval monitor")
+				tokenApprovals.Start(ctx)
+			}()
+		}
+		adminRegistrars = append(adminRegistrars, handlers.NewTokenApprovalHandler(logger, tokenApprovals, twoFactorHandler))
+	}
+	// Суточная сверка для бухгалтерии на webhook и/или SFTP
+	if config.Reconciliation.ReconciliationWebhookURL != "" || config.Reconciliation.SFTPAddress != "" {
+		reconciliation, err := initReconciliation(logger, config, pg, transactionsRepository, assetRegistry)
+		if err != nil {
+			logger.Error("Failed to configure reconciliation", "error", err)
+			log.Fatal(err)
+		}
+		if !replaying {
+			go func() {
+				defer errreport.Recover(map[string]string{"worker": "reconciliation"})
+				logger.Info("Starting reconciliation delivery")
+				reconciliation.Start(ctx)
+			}()
+		}
+		adminRegistrars = append(adminRegistrars, handlers.NewReconciliationHandler(logger, reconciliation))
+	}
+	if withdrawalBatches != nil {
+		adminRegistrars = append(adminRegistrars, handlers.NewWithdrawalBatchesHandler(logger, withdrawalBatches, twoFactorHandler))
+	}
+	adminServer, err := initAdminServer(logger, config, router, auditService, adminRegistrars...)
+	if err != nil {
+		logger.Error("Failed to configure admin routes", "error", err)
+		log.Fatal(err)
+	}
+
 	// Register WebSocket routes before HTTP routes
 	wsHandler.RegisterRoutes(router)
+	sessionHandler.RegisterRoutes(router)
+	depositHandler.RegisterRoutes(router)
+	paymentHandler.RegisterRoutes(router)
+	invoiceHandler.RegisterRoutes(router)
+	refundHandler.RegisterRoutes(router)
+	feeHandler.RegisterRoutes(router)
+	assetHandler.RegisterRoutes(router)
+	accountClosureHandler.RegisterRoutes(router)
+	withdrawalLimitsHandler.RegisterRoutes(router)
+	settlementHandler.RegisterRoutes(router)
+	merchantDepositsHandler.RegisterRoutes(router)
+	sandboxHandler.RegisterRoutes(router)
+	fiatPayoutHandler.RegisterRoutes(router)
+	ownershipProofHandler.RegisterRoutes(router)
+	tonDepositHandler.RegisterRoutes(router)
+	orderBatchHandler.RegisterRoutes(router)
+	orderScheduleHandler.RegisterRoutes(router)
+	orderTemplateHandler.RegisterRoutes(router)
+	orderCancellationHandler.RegisterRoutes(router)
+	receiptHandler.RegisterRoutes(router)
+	orderEventsHandler.RegisterRoutes(router)
+	transactionDetailHandler.RegisterRoutes(router)
+	activityHandler.RegisterRoutes(router)
+	balancesHandler.RegisterRoutes(router)
+	statusHandler.RegisterRoutes(router)
+	workersHandler.RegisterRoutes(router)
 	httpHandler.RegisterRoutes(router)
 
 	// Configure CORS
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", handlers.TwoFactorCodeHeader, handlers.CaptchaTokenHeader, handlers.RequestIDHeader},
+		ExposedHeaders:   []string{handlers.RequestIDHeader},
 		AllowCredentials: true,
 	})
 
-	// Wrap router in CORS middleware
-	handler := c.Handler(router)
+	proxyTrust, err := handlers.NewProxyTrust(config.HTTP.TrustedProxies)
+	if err != nil {
+		logger.Error("Failed to parse trusted proxies", "error", err)
+		log.Fatal(err)
+	}
+
+	// Wrap router in CORS, sandbox, client address and correlation ID middlewares
+	handler := handlers.RequestID(proxyTrust.Middleware(handlers.Recover(logger, c.Handler(sandboxHandler.Guard(router)))))
+
+	tlsConfig, err := initTLS(logger, config)
+	if err != nil {
+		logger.Error("Failed to configure TLS", "error", err)
+		log.Fatal(err)
+	}
+	if tlsConfig != nil && config.HTTP.HSTSMaxAge > 0 {
+		handler = handlers.HSTS(config.HTTP.HSTSMaxAge, config.HTTP.HSTSIncludeSubdomains, handler)
+	}
 
 	// Create HTTP server with timeouts
 	server := &http.Server{
@@ -162,13 +1085,47 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		logger.Info("Starting server", "address", server.Addr)
-		if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Error("Server error", "error", err)
-			log.Fatal(err)
+		var serveErr error
+		if tlsConfig != nil {
+			server.TLSConfig = tlsConfig.config
+			logger.Info("Starting TLS server", "address", server.Addr)
+			serveErr = server.ListenAndServeTLS(tlsConfig.certFile, tlsConfig.keyFile)
+		} else {
+			logger.Info("Starting server", "address", server.Addr)
+			serveErr = server.ListenAndServe()
+		}
+		if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			logger.Error("Server error", "error", serveErr)
+			log.Fatal(serveErr)
 		}
 	}()
 
+	if tlsConfig != nil && tlsConfig.redirect != nil {
+		go func() {
+			logger.Info("Starting HTTP redirect server", "address", tlsConfig.redirect.Addr)
+			if serveErr := tlsConfig.redirect.ListenAndServe(); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+				logger.Error("Redirect server error", "error", serveErr)
+				log.Fatal(serveErr)
+			}
+		}()
+	}
+
+	if adminServer != nil {
+		go func() {
+			logger.Info("Starting admin server", "address", adminServer.Addr, "tls", adminServer.TLSConfig != nil)
+			var serveErr error
+			if adminServer.TLSConfig != nil {
+				serveErr = adminServer.ListenAndServeTLS(config.Admin.TLSCertFile, config.Admin.TLSKeyFile)
+			} else {
+				serveErr = adminServer.ListenAndServe()
+			}
+			if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+				logger.Error("Admin server error", "error", serveErr)
+				log.Fatal(serveErr)
+			}
+		}()
+	}
+
 	// Set up graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -179,6 +1136,18 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeoutSeconds*time.Second)
 	defer cancel()
 
+	if tlsConfig != nil && tlsConfig.redirect != nil {
+		if err = tlsConfig.redirect.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Redirect server forced to shutdown", "error", err)
+		}
+	}
+
+	if adminServer != nil {
+		if err = adminServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Admin server forced to shutdown", "error", err)
+		}
+	}
+
 	if err = server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server forced to shutdown", "error", err)
 		return
@@ -187,7 +1156,7 @@ func main() {
 	logger.Info("Server exited properly")
 }
 
-func initAMLService(logger *slog.Logger, config *cfg.Config, pg *database.Postgres, transactionService *usecases.TransactionServiceImpl) *usecases.AMLService {
+func initAMLService(logger *slog.Logger, config *cfg.Config, pg *database.Postgres, transactionService *usecases.TransactionServiceImpl, tokenBlacklist *amlservices.TokenBlacklistService, assetRegistry *usecases.AssetRegistry, faults *chaos.Injector) (*usecases.AMLService, *usecases.RiskRollupService) {
 	// Создаем AML репозиторий
 	amlRepository := repository.NewAMLRepository(logger, pg)
 
@@ -196,16 +1165,19 @@ func initAMLService(logger *slog.Logger, config *cfg.Config, pg *database.Postgr
 		logger,
 		config.AML.ChainalysisAPIKey,
 		config.AML.ChainalysisAPIURL,
+		time.Duration(config.Timeouts.AML)*time.Second,
 	)
 
 	ellipticService := amlservices.NewEllipticService(
 		logger,
 		config.AML.EllipticAPIKey,
 		config.AML.EllipticAPIURL,
+		time.Duration(config.Timeouts.AML)*time.Second,
 	)
 
 	localAMLService := amlservices.NewLocalAMLService(
 		logger,
+		assetRegistry,
 		config.AML.TransactionThreshold,
 	)
 
@@ -213,6 +1185,7 @@ func initAMLService(logger *slog.Logger, config *cfg.Config, pg *database.Postgr
 		logger,
 		config.AML.AMLBotAPIKey,
 		config.AML.AMLBotAPIURL,
+		time.Duration(config.Timeouts.AML)*time.Second,
 	)
 
 	// Создаем основной AML сервис
@@ -223,30 +1196,78 @@ func initAMLService(logger *slog.Logger, config *cfg.Config, pg *database.Postgr
 		ellipticService,
 		localAMLService,
 		amlbotService,
+		tokenBlacklist,
 		transactionService, // Используем transactionService из параметров
 		pg.Transactor,      // Добавляем транзактор
+		faults,
 	)
 
+	// Сводный риск кошельков пользователя ужесточает проверку его следующих депозитов
+	riskRollups, err := usecases.NewRiskRollupService(logger, amlRepository, usecases.RiskRollupConfig{
+		ElevatedScore: config.AML.UserRiskElevated,
+		ReviewScore:   config.AML.TightenedReviewScore,
+		RejectScore:   config.AML.TightenedRejectScore,
+	})
+	if err != nil {
+		logger.Error("Failed to configure AML risk rollups", "error", err)
+		log.Fatal(err)
+	}
+	amlService.SetRiskRollup(riskRollups)
+
+	// Адреса, добавленные правилами платформы в локальный черный список, переживают перезапуск
+	if err = amlService.LoadLocalBlacklist(context.Background()); err != nil {
+		logger.Error("Failed to load local AML blacklist", "error", err)
+		log.Fatal(err)
+	}
+
+	// Сценарные вердикты для проверки сценариев флага, ручной проверки и одобрения на стенде
+	if config.AML.ScriptedProvider {
+		if !config.Blockchain.Debug {
+			log.Fatal("scripted AML provider requires BLOCKCHAIN_DEBUG_MODE")
+		}
+		scripted, err := amlservices.NewScriptedAMLService(logger, assetRegistry, config.AML.ScriptedRules)
+		if err != nil {
+			logger.Error("Failed to configure scripted AML provider", "error", err)
+			log.Fatal(err)
+		}
+		amlService.SetScriptedProvider(scripted)
+	}
+
 	logger.Info("AML service initialized",
 		"chainalysis_enabled", chainalysisService.IsEnabled(),
 		"elliptic_enabled", ellipticService.IsEnabled(),
 		"amlbot_enabled", amlbotService.IsEnabled(),
+		"scripted_provider", config.AML.ScriptedProvider,
+		"user_risk_elevated", config.AML.UserRiskElevated,
 	)
 
-	return amlService
+	return amlService, riskRollups
 }
 
 func initAndRunWorkers(
 	ctx context.Context,
 	logger *slog.Logger,
 	config *cfg.Config,
+	workerRegistry *usecases.WorkerRegistry,
 	orderService *usecases.OrderService,
 	transactionService *usecases.TransactionServiceImpl,
 	walletService *usecases.WalletService,
-	amlService workers.AMLService,
-) {
+	amlService *usecases.AMLService,
+	mempoolDeposits *usecases.MempoolDepositService,
+	refundService *usecases.RefundService,
+	treasuryService *usecases.TreasuryService,
+	sweepService *usecases.SweepService,
+	confirmationPolicy *usecases.ConfirmationPolicy,
+	depositHolds *usecases.DepositHoldService,
+	depositFilters *usecases.DepositFilterService,
+	scannerStates *repository.ScannerStatesRepository,
+	blockRecorder *blockrec.Recorder,
+) *workers.BinanceSmartChain {
+	replaying := config.Blockchain.ReplayDir != ""
+
 	// Initialize blockchain processor с реальным AML сервисом
-	bscBlockchainProcessor := workers.NewBinanceSmartChain(logger, config, transactionService, walletService, amlService, orderService)
+	bscBlockchainProcessor := workers.NewBinanceSmartChain(logger, config, transactionService, walletService, amlService, orderService, mempoolDeposits, refundService, confirmationPolicy, depositHolds, depositFilters,
+		scannerStates, blockRecorder, workerRegistry.Register("bsc_scanner", scannerStallTimeout(config)))
 
 	// Initialize order cleaner worker with configuration from config
 	orderCleaner := workers.NewOrderCleaner(
@@ -254,19 +1275,527 @@ func initAndRunWorkers(
 		orderService,
 		time.Duration(config.Workers.OrderExpiration)*time.Minute,      // Use OrderExpiration from config (in minutes)
 		time.Duration(config.Workers.OrderCleanupInterval)*time.Minute, // Use OrderCleanupInterval from config (in minutes)
+		workerRegistry.Register("order_cleaner", time.Duration(config.Workers.OrderCleanupInterval)*time.Minute),
 	)
 
 	// Start blockchain subscription in a goroutine
 	go func() {
+		defer errreport.Recover(map[string]string{"worker": "bsc_scanner", "chain": "bsc"})
+		if config.Blockchain.ReplayDir != "" {
+			replayRecordedBlocks(ctx, logger, bscBlockchainProcessor, config.Blockchain.ReplayDir)
+			return
+		}
 		logger.Info("Starting blockchain monitoring worker")
 		bscBlockchainProcessor.SubscribeToTransactions(ctx, config.RPCURL)
 	}()
 
+	// Повторная обработка очереди AML-проверок, не выполненных при приеме депозита
+	if !replaying {
+		amlProcessing := workerRegistry.Register("aml_processing", usecases.AMLProcessingInterval)
+		go func() {
+			logger.Info("Starting AML processing worker")
+			amlService.StartBackgroundProcessing(ctx, amlProcessing)
+		}()
+	}
+
+	if config.Blockchain.MempoolMonitoring && config.Blockchain.ReplayDir == "" {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "mempool_monitor", "chain": "bsc"})
+			logger.Info("Starting mempool monitoring worker")
+			bscBlockchainProcessor.SubscribeToMempool(ctx)
+		}()
+	}
+
+	// Отправленные возвраты завершаются по квитанциям независимо от автоматического исполнения
+	if !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "refund_executor"})
+			logger.Info("Starting refund executor worker", "auto_execute", config.Orders.AutoExecuteRefunds)
+			refundService.Start(ctx)
+		}()
+	}
+
+	if treasuryService.Enabled() && !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "safe_proposals"})
+			logger.Info("Starting safe proposals worker")
+			treasuryService.Start(ctx)
+		}()
+	}
+
+	if sweepService != nil && !replaying {
+		go func() {
+			defer errreport.Recover(map[string]string{"worker": "sweeper", "chain": "bsc"})
+			logger.Info("Starting sweep worker")
+			sweepService.Start(ctx)
+		}()
+	}
+
 	// Start order cleaner worker in a goroutine
 	go func() {
+		defer errreport.Recover(map[string]string{"worker": "order_cleaner"})
 		logger.Info("Starting order cleaner worker")
 		orderCleaner.Start(ctx)
 	}()
 
 	logger.Info("All workers initialized and started")
+
+	return bscBlockchainProcessor
+}
+
+// initStatusService собирает подсистемы публичной страницы статуса. Сканер считается деградированным,
+// если не отмечался дольше таймаута зависания, websocket — если цены не обновлялись.
+func initStatusService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	pg *database.Postgres,
+	amlService *usecases.AMLService,
+	assetRegistry *usecases.AssetRegistry,
+	tokenMonitor *usecases.TokenMonitorService,
+	bscProcessor *workers.BinanceSmartChain,
+	tonDeposits *usecases.TonDepositService,
+	dataService *mocked.DataService,
+) *usecases.StatusService {
+	components := []usecases.StatusComponent{
+		{Name: "bsc_scanner", Heartbeat: bscProcessor, StaleAfter: scannerStallTimeout(config)},
+	}
+	if tonDeposits.Enabled() {
+		components = append(components, usecases.StatusComponent{
+			Name:       "ton_scanner",
+			Heartbeat:  tonDeposits,
+			StaleAfter: 3 * time.Duration(config.TON.PollInterval) * time.Second,
+		})
+	}
+	components = append(components, usecases.StatusComponent{Name: "websockets", Heartbeat: dataService, StaleAfter: 30 * time.Second})
+
+	return usecases.NewStatusService(logger, pg.Pool, amlService, assetRegistry, tokenMonitor, components...)
+}
+
+// scannerStallTimeout — время без обработанных блоков, после которого сканер BSC считается зависшим
+func scannerStallTimeout(config *cfg.Config) time.Duration {
+	if config.Blockchain.ScannerStallTimeout <= 0 {
+		return 3 * time.Minute
+	}
+	return time.Duration(config.Blockchain.ScannerStallTimeout) * time.Minute
+}
+
+func initAbuseGuard(logger *slog.Logger, config *cfg.Config, ordersRepository *repository.OrdersRepository, walletsRepository *repository.WalletsRepository, accountClosuresRepository *repository.AccountClosuresRepository) *usecases.AbuseGuard {
+	var captchaVerifier usecases.CaptchaVerifier
+	if config.Security.CaptchaSecret != "" {
+		captchaVerifier = captcha.NewVerifier(logger, config.Security.CaptchaSecret, config.Security.CaptchaVerifyURL,
+			time.Duration(config.Timeouts.HTTP)*time.Second)
+		logger.Info("Captcha verification enabled", "verify_url", config.Security.CaptchaVerifyURL)
+	}
+
+	return usecases.NewAbuseGuard(logger, ordersRepository, walletsRepository, accountClosuresRepository, captchaVerifier, usecases.AbuseGuardLimits{
+		MaxPendingOrders: config.Security.MaxPendingOrders,
+		MaxUnusedWallets: config.Security.MaxUnusedWallets,
+		Cooldown:         time.Duration(config.Security.WalletCooldown) * time.Second,
+	})
+}
+
+func initTreasuryService(logger *slog.Logger, config *cfg.Config, pg *database.Postgres, walletService *usecases.WalletService, auditService *usecases.AuditService, ledgerService *usecases.LedgerService, withdrawalLimits *usecases.WithdrawalLimitService) (*usecases.TreasuryService, error) {
+	var safeService usecases.SafeTransactionService
+	if config.Treasury.SafeAddress != "" {
+		safeService = safe.NewClient(config.Treasury.SafeServiceURL, time.Duration(config.Timeouts.HTTP)*time.Second)
+		logger.Info("Multisig treasury enabled",
+			"safe", config.Treasury.SafeAddress,
+			"proposal_threshold", config.Treasury.ProposalThreshold)
+	}
+
+	return usecases.NewTreasuryService(logger, repository.NewSafeProposalsRepository(logger, pg), safeService, walletService, auditService, ledgerService, withdrawalLimits, usecases.TreasuryConfig{
+		SafeAddress:       config.Treasury.SafeAddress,
+		ProposalThreshold: config.Treasury.ProposalThreshold,
+		ProposerPath:      config.Treasury.ProposerPath,
+		PollInterval:      time.Duration(config.Treasury.PollInterval) * time.Second,
+	})
+}
+
+// initWithdrawalBatchService returns nil when withdrawal batching is disabled. Batches are sent through
+// the sweep BatchCollector, which deposit wallets already approve for batch sweeps.
+func initWithdrawalBatchService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	pg *database.Postgres,
+	walletsRepository *repository.WalletsRepository,
+	walletService *usecases.WalletService,
+	withdrawalLimits *usecases.WithdrawalLimitService,
+	workerRegistry *usecases.WorkerRegistry,
+) (*usecases.WithdrawalBatchService, error) {
+	if config.Treasury.WithdrawalBatchInterval <= 0 {
+		return nil, nil
+	}
+	if config.Sweeps.CollectorAddress == "" {
+		return nil, errors.New("withdrawal batching requires SWEEP_COLLECTOR_ADDRESS")
+	}
+
+	interval := time.Duration(config.Treasury.WithdrawalBatchInterval) * time.Minute
+	return usecases.NewWithdrawalBatchService(logger, repository.NewWithdrawalsRepository(logger, pg), walletsRepository, walletService,
+		withdrawalLimits, workerRegistry.Register("withdrawal_batches", interval), usecases.WithdrawalBatchConfig{
+			CollectorAddress: config.Sweeps.CollectorAddress,
+			OperatorPath:     config.Sweeps.RelayerPath,
+			Interval:         interval,
+			MaxSize:          config.Treasury.WithdrawalBatchSize,
+		})
+}
+
+// initTreasuryOverviewService assigns wallet roles: the Safe is cold storage, a sweep destination other than the Safe is hot
+func initTreasuryOverviewService(logger *slog.Logger, config *cfg.Config, pg *database.Postgres, transactionsRepository *repository.TransactionsRepository, walletService *usecases.WalletService) (*usecases.TreasuryOverviewService, error) {
+	hot := append([]string{}, config.Treasury.HotWallets...)
+	cold := append([]string{}, config.Treasury.ColdWallets...)
+	if config.Treasury.SafeAddress != "" {
+		cold = append(cold, config.Treasury.SafeAddress)
+	}
+	if config.Sweeps.Destination != "" && !strings.EqualFold(config.Sweeps.Destination, config.Treasury.SafeAddress) {
+		hot = append(hot, config.Sweeps.Destination)
+	}
+
+	return usecases.NewTreasuryOverviewService(logger, transactionsRepository, repository.NewSafeProposalsRepository(logger, pg), walletService, usecases.TreasuryOverviewConfig{
+		HotWallets:     hot,
+		ColdWallets:    cold,
+		SweepMinAmount: config.Sweeps.MinAmount,
+	})
+}
+
+// initAssetRegistry loads assets of the network selected by BLOCKCHAIN_DEBUG_MODE
+// initSolanaFees настраивает приоритетные комиссии транзакций Solana по множителям приоритетов
+func initSolanaFees(logger *slog.Logger, config *cfg.Config, client *solana.Client) (*usecases.SolanaPriorityFeeService, error) {
+	return usecases.NewSolanaPriorityFeeService(logger, client, usecases.SolanaPriorityFeeConfig{
+		ComputeUnitLimit: config.Solana.ComputeUnitLimit,
+		Percentile:       config.Solana.PriorityFeePercentile,
+		Multipliers: map[string]float64{
+			usecases.PriorityLow:    config.Solana.PriorityMultiplierLow,
+			usecases.PriorityMedium: config.Solana.PriorityMultiplierMedium,
+			usecases.PriorityHigh:   config.Solana.PriorityMultiplierHigh,
+		},
+		MinMicroLamports: config.Solana.PriorityFeeMin,
+		MaxMicroLamports: config.Solana.PriorityFeeMax,
+	})
+}
+
+// initSimulatedChain запускает симулированную сеть и направляет в нее все подключения к BSC.
+// Вызывается до создания любых клиентов BSC.
+func initSimulatedChain(ctx context.Context, logger *slog.Logger, config *cfg.Config) (*simchain.Chain, error) {
+	chain, err := simchain.New(simchain.Config{
+		HTTPPort:      config.Blockchain.SimulatedHTTPPort,
+		WSPort:        config.Blockchain.SimulatedWSPort,
+		BlockInterval: time.Duration(config.Blockchain.SimulatedBlockInterval) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shared.UseSimulatedChain(shared.SimulatedChain{
+		HTTPURL:      chain.HTTPURL(),
+		WSURL:        chain.WSURL(),
+		TokenAddress: simchain.TokenAddress.Hex(),
+	})
+	go chain.Start(ctx)
+
+	logger.Warn("SIMULATION MODE: BSC is replaced by a simulated chain, do not use in production",
+		"http_url", chain.HTTPURL(),
+		"ws_url", chain.WSURL(),
+		"token", simchain.TokenAddress.Hex())
+	return chain, nil
+}
+
+// replayRecordedBlocks прогоняет записанные блоки через сканер вместо подписки на сеть.
+// Сервер продолжает работать, чтобы результат можно было изучить через админ API.
+func replayRecordedBlocks(ctx context.Context, logger *slog.Logger, bscProcessor *workers.BinanceSmartChain, dir string) {
+	recordings, err := blockrec.Load(dir)
+	if err != nil {
+		logger.Error("Failed to load block recordings", "dir", dir, "error", err)
+		return
+	}
+
+	logger.Warn("REPLAY MODE: processing recorded blocks instead of the live chain", "dir", dir, "blocks", len(recordings))
+	if err = bscProcessor.ReplayBlocks(ctx, recordings); err != nil {
+		logger.Error("Block replay failed", "dir", dir, "error", err)
+		return
+	}
+	logger.Warn("Block replay completed, the scanner stays stopped", "blocks", len(recordings))
+}
+
+// initTokenApprovals allows approvals to the batch collector and the forwarder factory in addition to the configured spenders
+func initTokenApprovals(logger *slog.Logger, config *cfg.Config, pg *database.Postgres, walletService *usecases.WalletService, auditService *usecases.AuditService) (*usecases.TokenApprovalService, error) {
+	spenders := append([]string(nil), config.Allowances.Spenders...)
+	for _, contract := range []string{config.Sweeps.CollectorAddress, config.Forwarders.FactoryAddress} {
+		if contract != "" {
+			spenders = append(spenders, contract)
+		}
+	}
+
+	return usecases.NewTokenApprovalService(logger, repository.NewTokenApprovalsRepository(logger, pg), walletService, auditService,
+		usecases.TokenApprovalConfig{
+			OwnerPath:     config.Allowances.OwnerPath,
+			Spenders:      spenders,
+			MaxAmount:     config.Allowances.MaxAmount,
+			DefaultExpiry: time.Duration(config.Allowances.DefaultExpiry) * time.Hour,
+			MaxExpiry:     time.Duration(config.Allowances.MaxExpiry) * time.Hour,
+			CheckInterval: time.Duration(config.Allowances.CheckInterval) * time.Minute,
+		})
+}
+
+// initReconciliation configures the accounting targets: the webhook and, when an address is set, the SFTP drop
+func initReconciliation(logger *slog.Logger, config *cfg.Config, pg *database.Postgres, transactionsRepository *repository.TransactionsRepository, assetRegistry *usecases.AssetRegistry) (*usecases.ReconciliationService, error) {
+	timeout := time.Duration(config.Timeouts.RPC) * time.Second
+
+	var uploader usecases.ReconciliationUploader
+	if config.Reconciliation.SFTPAddress != "" {
+		uploader = sftp.NewUploader(sftp.Config{
+			Address:    config.Reconciliation.SFTPAddress,
+			User:       config.Reconciliation.SFTPUser,
+			Password:   config.Reconciliation.SFTPPassword,
+			PrivateKey: config.Reconciliation.SFTPPrivateKey,
+			HostKey:    config.Reconciliation.SFTPHostKey,
+			Timeout:    timeout,
+		})
+	}
+
+	return usecases.NewReconciliationService(logger, repository.NewLedgerRepository(logger, pg), transactionsRepository,
+		repository.NewReconciliationRepository(logger, pg), assetRegistry, webhook.NewClient(timeout), uploader,
+		usecases.ReconciliationConfig{
+			WebhookURL:    config.Reconciliation.ReconciliationWebhookURL,
+			SigningSecret: config.Reconciliation.ReconciliationSecret,
+			SFTPDirectory: config.Reconciliation.SFTPDirectory,
+			Hour:          config.Reconciliation.ReconciliationHour,
+		})
+}
+
+func initAssetRegistry(ctx context.Context, logger *slog.Logger, pg *database.Postgres, auditService *usecases.AuditService) (*usecases.AssetRegistry, error) {
+	network := entities.NetworkMainnet
+	if _, ok := shared.Simulated(); ok {
+		network = entities.NetworkSimulated
+	} else if shared.IsBlockchainDebugMode() {
+		network = entities.NetworkTestnet
+	}
+
+	registry := usecases.NewAssetRegistry(logger, repository.NewAssetsRepository(logger, pg), auditService, entities.ChainBSC, network)
+	if err := registry.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+func initTokenMonitor(ctx context.Context, logger *slog.Logger, config *cfg.Config, pg *database.Postgres, walletsRepository *repository.WalletsRepository, auditService *usecases.AuditService, assetRegistry *usecases.AssetRegistry) (*usecases.TokenMonitorService, error) {
+	tokens := config.Blockchain.MonitoredTokens
+	if len(tokens) == 0 {
+		for _, asset := range assetRegistry.List() {
+			if asset.Contract != "" {
+				tokens = append(tokens, asset.Contract)
+			}
+		}
+	}
+
+	monitor, err := usecases.NewTokenMonitorService(logger, repository.NewTokenEventsRepository(logger, pg), walletsRepository,
+		repository.NewScannerStatesRepository(logger, pg), auditService, tokens, time.Duration(config.Blockchain.TokenPollInterval)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	// Неподтвержденные события продолжают действовать после перезапуска
+	if err = monitor.LoadHalts(ctx); err != nil {
+		return nil, err
+	}
+
+	// Опрос продолжается с последнего обработанного блока, события за время простоя не теряются
+	if err = monitor.LoadCheckpoint(ctx); err != nil {
+		return nil, err
+	}
+
+	return monitor, nil
+}
+
+// initSweepService returns nil when sweeps are disabled
+func initSweepService(logger *slog.Logger, config *cfg.Config, walletsRepository *repository.WalletsRepository, walletService *usecases.WalletService, treasuryService *usecases.TreasuryService) (*usecases.SweepService, error) {
+	if !config.Sweeps.Enabled {
+		return nil, nil
+	}
+
+	destination := config.Sweeps.Destination
+	if destination == "" {
+		destination = config.Treasury.SafeAddress
+	}
+
+	return usecases.NewSweepService(logger, walletsRepository, walletService, usecases.SweepConfig{
+		Destination: destination,
+		MinAmount:   config.Sweeps.MinAmount,
+		Interval:    time.Duration(config.Sweeps.Interval) * time.Minute,
+		Gasless:     config.Sweeps.Gasless && config.Sweeps.RelayerPath != "",
+		RelayerPath: config.Sweeps.RelayerPath,
+
+		CollectorAddress: config.Sweeps.CollectorAddress,
+		BatchSize:        config.Sweeps.BatchSize,
+	})
+}
+
+func initFiatPayoutService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	pg *database.Postgres,
+	assetRegistry *usecases.AssetRegistry,
+	rates usecases.RateProvider,
+	notifier usecases.Notifier,
+) (*usecases.FiatPayoutService, error) {
+	settleAfter := time.Duration(config.FiatPayouts.StubSettleAfter) * time.Second
+
+	var payoutProviders []usecases.FiatPayoutProvider
+	for _, method := range config.FiatPayouts.Methods {
+		switch entities.FiatPayoutMethod(strings.TrimSpace(method)) {
+		case entities.FiatPayoutBankTransfer:
+			payoutProviders = append(payoutProviders, providers.NewBankTransferProvider(logger, config.FiatPayouts.BankCurrencies, settleAfter))
+		case entities.FiatPayoutSEPA:
+			payoutProviders = append(payoutProviders, providers.NewSEPAProvider(logger, settleAfter))
+		case entities.FiatPayoutSBP:
+			payoutProviders = append(payoutProviders, providers.NewSBPProvider(logger, settleAfter))
+		default:
+			return nil, fmt.Errorf("unknown fiat payout method %q", method)
+		}
+	}
+
+	return usecases.NewFiatPayoutService(logger, repository.NewFiatPayoutsRepository(logger, pg), assetRegistry, rates, notifier,
+		payoutProviders, usecases.FiatPayoutConfig{
+			PollInterval: time.Duration(config.FiatPayouts.PollInterval) * time.Second,
+			MaxAttempts:  config.FiatPayouts.MaxAttempts,
+		})
+}
+
+func initDormantSweepService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	pg *database.Postgres,
+	walletService *usecases.WalletService,
+	treasuryService *usecases.TreasuryService,
+	transactionsRepository *repository.TransactionsRepository,
+	refundService *usecases.RefundService,
+) (*usecases.DormantSweepService, error) {
+	destination := config.Sweeps.Destination
+	if destination == "" {
+		destination = config.Treasury.SafeAddress
+	}
+
+	var idleAfter time.Duration
+	if config.Sweeps.Enabled {
+		idleAfter = time.Duration(config.Sweeps.DormantDays) * 24 * time.Hour
+	}
+
+	return usecases.NewDormantSweepService(logger, repository.NewDormantRecoveriesRepository(logger, pg), walletService,
+		treasuryService, transactionsRepository, refundService, usecases.DormantSweepConfig{
+			IdleAfter:   idleAfter,
+			Interval:    time.Duration(config.Sweeps.DormantInterval) * time.Hour,
+			Action:      usecases.DormantSweepAction(config.Sweeps.DormantAction),
+			MinAmount:   config.Sweeps.DormantMinAmount,
+			Destination: destination,
+		})
+}
+
+func initAccountClosureService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	accountClosuresRepository *repository.AccountClosuresRepository,
+	walletsRepository *repository.WalletsRepository,
+	walletService *usecases.WalletService,
+	treasuryService *usecases.TreasuryService,
+	sessionsRepository *repository.SessionsRepository,
+	privacyService *usecases.PrivacyService,
+	auditService *usecases.AuditService,
+) (*usecases.AccountClosureService, error) {
+	destination := config.Closures.SweepDestination
+	if destination == "" {
+		destination = config.Sweeps.Destination
+	}
+	if destination == "" {
+		destination = config.Treasury.SafeAddress
+	}
+	if destination == "" {
+		logger.Warn("Account closure sweep destination is not configured, closures with balances will wait")
+	}
+
+	return usecases.NewAccountClosureService(logger, accountClosuresRepository, walletsRepository, walletService, treasuryService,
+		sessionsRepository, privacyService, auditService, usecases.AccountClosureConfig{
+			Destination:  destination,
+			Interval:     time.Duration(config.Closures.Interval) * time.Minute,
+			ErasureDelay: time.Duration(config.Closures.ErasureDelay) * 24 * time.Hour,
+		})
+}
+
+// initAdminServer registers /admin and /metrics routes. If a dedicated admin port is configured,
+// the routes are served only by a separate server, optionally with TLS and client certificate verification.
+func initAdminServer(logger *slog.Logger, config *cfg.Config, router *mux.Router, auditService *usecases.AuditService, registrars ...handlers.AdminRoutesRegistrar) (*http.Server, error) {
+	requireClientCert := config.Admin.ClientCAFile != ""
+
+	guard, err := handlers.NewAdminGuard(logger, auditService, config.Admin.AllowedCIDRs, requireClientCert)
+	if err != nil {
+		return nil, err
+	}
+	adminHandler := handlers.NewAdminHandler(logger, auditService, guard, registrars...)
+
+	if config.Admin.Port == "" {
+		if requireClientCert {
+			return nil, errors.New("admin client CA requires a dedicated admin port")
+		}
+		adminHandler.RegisterRoutes(router)
+		return nil, nil
+	}
+
+	adminRouter := mux.NewRouter()
+	adminHandler.RegisterRoutes(adminRouter)
+
+	server := &http.Server{
+		Addr:         ":" + config.Admin.Port,
+		Handler:      handlers.RequestID(adminRouter),
+		ReadTimeout:  readTimeoutSeconds * time.Second,
+		WriteTimeout: writeTimeoutSeconds * time.Second,
+		IdleTimeout:  idleTimeoutSeconds * time.Second,
+	}
+
+	if config.Admin.TLSCertFile == "" || config.Admin.TLSKeyFile == "" {
+		if requireClientCert {
+			return nil, errors.New("admin client CA requires admin TLS certificate and key")
+		}
+		return server, nil
+	}
+
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if requireClientCert {
+		caPEM, err := os.ReadFile(config.Admin.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read admin client CA: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("admin client CA file contains no certificates")
+		}
+		server.TLSConfig.ClientCAs = clientCAs
+		// Сертификат запрашивается, но проверка его наличия выполняется в AdminGuard, чтобы отказ попал в аудит
+		server.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return server, nil
+}
+
+// orderFingerprintDecimals returns the number of decimals of unique order amounts, 0 disables fingerprinting
+func orderFingerprintDecimals(config *cfg.Config) int {
+	if !config.Orders.AmountFingerprinting {
+		return 0
+	}
+	return config.Orders.FingerprintDecimals
+}
+
+// initForwarderSweepService returns nil when CREATE2 forwarders are not configured
+func initForwarderSweepService(logger *slog.Logger, config *cfg.Config, walletsRepository *repository.WalletsRepository, walletService *usecases.WalletService) (*usecases.ForwarderSweepService, error) {
+	if !walletService.ForwardersEnabled() {
+		return nil, nil
+	}
+
+	logger.Info("CREATE2 forwarder deposit addresses enabled", "factory", config.Forwarders.FactoryAddress)
+
+	return usecases.NewForwarderSweepService(logger, walletsRepository, walletService, usecases.ForwarderSweepConfig{
+		SenderPath: config.Forwarders.SenderPath,
+		MinAmount:  config.Forwarders.MinAmount,
+		Interval:   time.Duration(config.Forwarders.Interval) * time.Minute,
+		BatchSize:  config.Forwarders.BatchSize,
+	})
 }
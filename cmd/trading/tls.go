@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	cfg "github.com/sand/crypto-p2p-trading-app/backend/config"
+)
+
+const httpsPort = "443"
+
+// tlsSetup описывает, как основной сервер терминирует TLS
+type tlsSetup struct {
+	config   *tls.Config
+	certFile string
+	keyFile  string
+	// redirect обслуживает HTTP порт: редирект на HTTPS и, для autocert, HTTP-01 challenge
+	redirect *http.Server
+}
+
+// initTLS returns nil when TLS is disabled. Certificates are loaded either from files
+// or obtained from Let's Encrypt via autocert with the HTTP-01 challenge.
+func initTLS(logger *slog.Logger, config *cfg.Config) (*tlsSetup, error) {
+	httpConfig := config.HTTP
+
+	useAutocert := len(httpConfig.AutocertDomains) > 0
+	useFiles := httpConfig.TLSCertFile != "" || httpConfig.TLSKeyFile != ""
+
+	if !useAutocert && !useFiles {
+		return nil, nil
+	}
+	if useAutocert && useFiles {
+		return nil, errors.New("TLS certificate files and autocert domains are mutually exclusive")
+	}
+	if useFiles && (httpConfig.TLSCertFile == "" || httpConfig.TLSKeyFile == "") {
+		return nil, errors.New("both TLS certificate and key files must be set")
+	}
+
+	setup := &tlsSetup{
+		config: &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	redirectHandler := httpsRedirectHandler(httpConfig.Port)
+
+	if useAutocert {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(httpConfig.AutocertDomains...),
+			Cache:      autocert.DirCache(httpConfig.AutocertCacheDir),
+			Email:      httpConfig.AutocertEmail,
+		}
+		setup.config.GetCertificate = manager.GetCertificate
+		setup.config.NextProtos = append(setup.config.NextProtos, "h2", "http/1.1")
+		// HTTP-01 challenge обслуживается на HTTP порту, остальные запросы перенаправляются на HTTPS
+		redirectHandler = manager.HTTPHandler(redirectHandler)
+
+		logger.Info("TLS enabled with autocert", "domains", httpConfig.AutocertDomains, "cache_dir", httpConfig.AutocertCacheDir)
+	} else {
+		setup.certFile = httpConfig.TLSCertFile
+		setup.keyFile = httpConfig.TLSKeyFile
+
+		logger.Info("TLS enabled with certificate files", "cert_file", httpConfig.TLSCertFile)
+	}
+
+	if httpConfig.RedirectPort != "" {
+		setup.redirect = &http.Server{
+			Addr:         ":" + httpConfig.RedirectPort,
+			Handler:      redirectHandler,
+			ReadTimeout:  readTimeoutSeconds * time.Second,
+			WriteTimeout: writeTimeoutSeconds * time.Second,
+			IdleTimeout:  idleTimeoutSeconds * time.Second,
+		}
+	}
+
+	return setup, nil
+}
+
+// httpsRedirectHandler permanently redirects plain HTTP requests to the HTTPS listener
+func httpsRedirectHandler(tlsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "" && tlsPort != httpsPort {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
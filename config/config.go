@@ -11,14 +11,36 @@ import (
 
 type (
 	Config struct {
-		App        `json:"app"     toml:"app"`
-		Blockchain `json:"blockchain" toml:"blockchain"`
-		HTTP       `json:"http"    toml:"http"`
-		DB         `json:"db"      toml:"db"`
-		Log        `json:"logger"  toml:"logger"`
-		Tracing    `json:"tracing" toml:"tracing"`
-		AML        `json:"aml"     toml:"aml"`
-		Workers    `json:"workers" toml:"workers"`
+		App            `json:"app"     toml:"app"`
+		Blockchain     `json:"blockchain" toml:"blockchain"`
+		HTTP           `json:"http"    toml:"http"`
+		DB             `json:"db"      toml:"db"`
+		Log            `json:"logger"  toml:"logger"`
+		Tracing        `json:"tracing" toml:"tracing"`
+		AML            `json:"aml"     toml:"aml"`
+		Workers        `json:"workers" toml:"workers"`
+		Orders         `json:"orders"  toml:"orders"`
+		Security       `json:"security" toml:"security"`
+		Admin          `json:"admin"   toml:"admin"`
+		Treasury       `json:"treasury" toml:"treasury"`
+		Sweeps         `json:"sweeps"  toml:"sweeps"`
+		Forwarders     `json:"forwarders" toml:"forwarders"`
+		Allowances     `json:"allowances" toml:"allowances"`
+		Reports        `json:"reports" toml:"reports"`
+		Reconciliation `json:"reconciliation" toml:"reconciliation"`
+		Privacy        `json:"privacy" toml:"privacy"`
+		Closures       `json:"closures" toml:"closures"`
+		DepositSLA     `json:"deposit_sla" toml:"deposit_sla"`
+		Withdrawals    `json:"withdrawals" toml:"withdrawals"`
+		Wallets        `json:"wallets" toml:"wallets"`
+		Settlements    `json:"settlements" toml:"settlements"`
+		FiatPayouts    `json:"fiat_payouts" toml:"fiat_payouts"`
+		TON            `json:"ton" toml:"ton"`
+		Solana         `json:"solana" toml:"solana"`
+		SolanaFaucet   `json:"solana_faucet" toml:"solana_faucet"`
+		Timeouts       `json:"timeouts" toml:"timeouts"`
+		MarketData     `json:"market_data" toml:"market_data"`
+		Chaos          `json:"chaos" toml:"chaos"`
 	}
 
 	App struct {
@@ -29,6 +51,29 @@ type (
 
 	HTTP struct {
 		Port string ` json:"port" toml:"port" env:"HTTP_PORT"`
+
+		// TLS: либо файлы сертификата, либо домены для Let's Encrypt autocert
+		TLSCertFile      string   `json:"tls_cert_file" toml:"tls_cert_file" env:"HTTP_TLS_CERT_FILE"`
+		TLSKeyFile       string   `json:"tls_key_file" toml:"tls_key_file" env:"HTTP_TLS_KEY_FILE"`
+		AutocertDomains  []string `json:"autocert_domains" toml:"autocert_domains" env:"HTTP_AUTOCERT_DOMAINS" env-separator:","`
+		AutocertEmail    string   `json:"autocert_email" toml:"autocert_email" env:"HTTP_AUTOCERT_EMAIL"`
+		AutocertCacheDir string   `json:"autocert_cache_dir" toml:"autocert_cache_dir" env:"HTTP_AUTOCERT_CACHE_DIR" env-default:"./certs"`
+
+		// Порт для HTTP->HTTPS редиректа и HTTP-01 challenge, пустое значение отключает редирект
+		RedirectPort string `json:"redirect_port" toml:"redirect_port" env:"HTTP_REDIRECT_PORT" env-default:"80"`
+
+		// Подсети обратных прокси (балансировщик, CDN), которым разрешено передавать адрес клиента
+		// в X-Forwarded-For и X-Real-IP. От остальных адресов эти заголовки игнорируются
+		TrustedProxies []string `json:"trusted_proxies" toml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" env-separator:","`
+
+		HSTSMaxAge            int  `json:"hsts_max_age" toml:"hsts_max_age" env:"HTTP_HSTS_MAX_AGE" env-default:"31536000"` // Default 1 year
+		HSTSIncludeSubdomains bool `json:"hsts_include_subdomains" toml:"hsts_include_subdomains" env:"HTTP_HSTS_INCLUDE_SUBDOMAINS" env-default:"false"`
+
+		// WebSocket: сжатие кадров permessage-deflate для клиентов, которые его поддерживают (уровень flate 1-9),
+		// и интервал, с которым накопленные обновления пар отправляются подписчикам
+		WSCompression      bool `json:"ws_compression" toml:"ws_compression" env:"WS_COMPRESSION" env-default:"true"`
+		WSCompressionLevel int  `json:"ws_compression_level" toml:"ws_compression_level" env:"WS_COMPRESSION_LEVEL" env-default:"1"`
+		WSBatchInterval    int  `json:"ws_batch_interval" toml:"ws_batch_interval" env:"WS_BATCH_INTERVAL" env-default:"500"` // Milliseconds
 	}
 
 	DB struct {
@@ -39,10 +84,70 @@ type (
 	}
 
 	Blockchain struct {
-		Debug                 bool   `json:"blockchain_debug" toml:"blockchain_debug" env:"BLOCKCHAIN_DEBUG_MODE" env-default:"false"`
-		RPCURL                string `json:"rpc_url" toml:"rpc_url" env:"RPC_URL" env-default:"https://bsc-dataseed.binance.org/"`
-		WalletSeed            string `json:"wallet_seed" toml:"wallet_seed" env:"WALLET_SEED" env-default:"your secure seed phrase here"`
-		RequiredConfirmations uint64 `json:"required_confirmations" toml:"required_confirmations" env:"REQUIRED_CONFIRMATIONS" env-default:"3"`
+		Debug      bool   `json:"blockchain_debug" toml:"blockchain_debug" env:"BLOCKCHAIN_DEBUG_MODE" env-default:"false"`
+		RPCURL     string `json:"rpc_url" toml:"rpc_url" env:"RPC_URL" env-default:"https://bsc-dataseed.binance.org/"`
+		WalletSeed string `json:"wallet_seed" toml:"wallet_seed" env:"WALLET_SEED" env-default:"your secure seed phrase here"`
+		// Дополнительные сиды на время ротации в формате "версия:мнемоника", WALLET_SEED — версия 1.
+		// Новые кошельки выводятся из активной версии, кошельки старых версий остаются доступными для вывода
+		WalletSeeds           []string `json:"wallet_seeds" toml:"wallet_seeds" env:"WALLET_SEEDS" env-separator:","`
+		ActiveKeyVersion      int      `json:"active_key_version" toml:"active_key_version" env:"WALLET_ACTIVE_KEY_VERSION" env-default:"1"`
+		ServiceKeyVersion     int      `json:"service_key_version" toml:"service_key_version" env:"WALLET_SERVICE_KEY_VERSION" env-default:"1"`
+		KeyRotationInterval   int      `json:"key_rotation_interval" toml:"key_rotation_interval" env:"KEY_ROTATION_SWEEP_INTERVAL" env-default:"60"` // Seconds
+		RequiredConfirmations uint64   `json:"required_confirmations" toml:"required_confirmations" env:"REQUIRED_CONFIRMATIONS" env-default:"3"`
+		// Подтверждения по сумме депозита: chain/ASSET=limit:confs;limit:confs;confs, например bsc/USDT=100:1;5000:3;12.
+		// Лимиты в валюте ConfirmationTiersCurrency по курсам INVOICE_RATES; без правила действует RequiredConfirmations
+		ConfirmationTiers         []string `json:"confirmation_tiers" toml:"confirmation_tiers" env:"CONFIRMATION_TIERS" env-separator:","`
+		ConfirmationTiersCurrency string   `json:"confirmation_tiers_currency" toml:"confirmation_tiers_currency" env:"CONFIRMATION_TIERS_CURRENCY" env-default:"USD"`
+
+		// Сканер блоков переподключается к следующему эндпоинту, если отстал от головы сети
+		// больше чем на MaxHeadLag блоков или не обработал ни одного блока за ScannerStallTimeout. 0 отключает проверку.
+		MaxHeadLag          uint64 `json:"max_head_lag" toml:"max_head_lag" env:"MAX_HEAD_LAG" env-default:"100"`
+		ScannerStallTimeout int    `json:"scanner_stall_timeout" toml:"scanner_stall_timeout" env:"SCANNER_STALL_TIMEOUT" env-default:"3"` // minutes
+
+		// Исходящая транзакция, не подтвержденная после MaxSpeedups ускорений, эскалируется операторам
+		// и при StuckTxAutoCancel отменяется нулевым переводом с тем же нонсом
+		MaxSpeedups       int  `json:"max_speedups" toml:"max_speedups" env:"MAX_SPEEDUPS" env-default:"3"`
+		StuckTxAutoCancel bool `json:"stuck_tx_auto_cancel" toml:"stuck_tx_auto_cancel" env:"STUCK_TX_AUTO_CANCEL" env-default:"false"`
+
+		// Ограничения запросов к каждому RPC эндпоинту, чтобы публичные ноды не блокировали клиента
+		RPCRateLimit   float64 `json:"rpc_rate_limit" toml:"rpc_rate_limit" env:"RPC_RATE_LIMIT" env-default:"8"` // requests per second, 0 disables
+		RPCBurst       int     `json:"rpc_burst" toml:"rpc_burst" env:"RPC_BURST" env-default:"16"`
+		RPCMaxQueue    int     `json:"rpc_max_queue" toml:"rpc_max_queue" env:"RPC_MAX_QUEUE" env-default:"200"`
+		RPCDailyBudget int64   `json:"rpc_daily_budget" toml:"rpc_daily_budget" env:"RPC_DAILY_BUDGET" env-default:"0"` // 0 - unlimited
+
+		// После RPCBreakerThreshold ошибок подряд эндпоинт отключается на RPCBreakerCooldown, затем пропускается
+		// один пробный запрос. 0 отключает автомат.
+		RPCBreakerThreshold int `json:"rpc_breaker_threshold" toml:"rpc_breaker_threshold" env:"RPC_BREAKER_THRESHOLD" env-default:"5"`
+		RPCBreakerCooldown  int `json:"rpc_breaker_cooldown" toml:"rpc_breaker_cooldown" env:"RPC_BREAKER_COOLDOWN" env-default:"30"` // seconds
+
+		// Мониторинг мемпула для предварительных уведомлений о депозитах (нужна нода с eth_subscribe newPendingTransactions)
+		MempoolMonitoring bool `json:"mempool_monitoring" toml:"mempool_monitoring" env:"MEMPOOL_MONITORING" env-default:"false"`
+		MempoolDepositTTL int  `json:"mempool_deposit_ttl" toml:"mempool_deposit_ttl" env:"MEMPOOL_DEPOSIT_TTL" env-default:"30"` // minutes
+
+		// Мониторинг административных событий контрактов токенов (пауза, черный список, параметры).
+		// Пустой список — отслеживаются контракты активов из реестра.
+		TokenMonitoring   bool     `json:"token_monitoring" toml:"token_monitoring" env:"TOKEN_MONITORING" env-default:"true"`
+		MonitoredTokens   []string `json:"monitored_tokens" toml:"monitored_tokens" env:"MONITORED_TOKENS" env-separator:","`
+		TokenPollInterval int      `json:"token_poll_interval" toml:"token_poll_interval" env:"TOKEN_POLL_INTERVAL" env-default:"30"` // seconds
+
+		// Ссылки на обозреватели блоков в ответах API и уведомлениях. chain/network=url заменяет обозреватель
+		// по умолчанию, например bsc/testnet=https://explorer.example для собственного обозревателя
+		ExplorerURLs []string `json:"explorer_urls" toml:"explorer_urls" env:"EXPLORER_URLS" env-separator:","`
+
+		// Режим симуляции для CI: вместо BSC запускается локальная симулированная сеть с тестовым токеном USDT,
+		// депозиты отправляются через админ API. Не включать в продакшене.
+		Simulated              bool `json:"simulated" toml:"simulated" env:"BLOCKCHAIN_SIMULATED" env-default:"false"`
+		SimulatedHTTPPort      int  `json:"simulated_http_port" toml:"simulated_http_port" env:"SIMULATED_HTTP_PORT" env-default:"18545"`
+		SimulatedWSPort        int  `json:"simulated_ws_port" toml:"simulated_ws_port" env:"SIMULATED_WS_PORT" env-default:"18546"`
+		SimulatedBlockInterval int  `json:"simulated_block_interval" toml:"simulated_block_interval" env:"SIMULATED_BLOCK_INTERVAL" env-default:"1"` // seconds
+
+		// Запись и воспроизведение обработки блоков для разбора ошибок зачисления. RecordDir — каталог, куда сканер
+		// сохраняет каждый блок с переводами USDT вместе с ответами RPC, полученными при его обработке.
+		// ReplayDir — каталог или файл записи: вместо подписки на сеть записанные блоки прогоняются через сканер.
+		// Воспроизведение меняет базу как обычная обработка, запускать только на копии базы. Это пробный прогон:
+		// подпись запрещена, внешние AML провайдеры не опрашиваются, воркеры выплат и доставки не запускаются.
+		RecordDir string `json:"record_dir" toml:"record_dir" env:"BLOCKCHAIN_RECORD_DIR" env-default:""`
+		ReplayDir string `json:"replay_dir" toml:"replay_dir" env:"BLOCKCHAIN_REPLAY_DIR" env-default:""`
 	}
 
 	AML struct {
@@ -58,21 +163,401 @@ type (
 		AMLBotAPIKey string `json:"amlbot_api_key" toml:"amlbot_api_key" env:"AMLBOT_API_KEY" env-default:""`
 		AMLBotAPIURL string `json:"amlbot_api_url" toml:"amlbot_api_url" env:"AMLBOT_API_URL" env-default:"https://api.amlbot.com/v1"`
 
-		// Local AML checks configuration
-		TransactionThreshold string `json:"transaction_threshold" toml:"transaction_threshold" env:"AML_TRANSACTION_THRESHOLD" env-default:"5000.0"`
+		// Сценарный AML провайдер для стендов вместо внешних API, только с BLOCKCHAIN_DEBUG_MODE.
+		// Правила "address:окончание=оценка" и "amount:минимум=оценка" (сумма в единицах актива), оценка error — отказ провайдера;
+		// без правил адрес на bad блокируется, на fee и депозит от 10000 уходят на ручную проверку
+		ScriptedProvider bool     `json:"scripted_provider" toml:"scripted_provider" env:"AML_SCRIPTED_PROVIDER" env-default:"false"`
+		ScriptedRules    []string `json:"scripted_rules" toml:"scripted_rules" env:"AML_SCRIPTED_RULES" env-separator:","`
+
+		// Local AML checks configuration. Пустое значение — порог актива из реестра (assets.aml_threshold)
+		TransactionThreshold string `json:"transaction_threshold" toml:"transaction_threshold" env:"AML_TRANSACTION_THRESHOLD"`
+
+		// Депозиты пользователя со сводным риском не ниже UserRiskElevated проверяются по ужесточенным порогам
+		// ручной проверки и отклонения, 0 отключает ужесточение
+		UserRiskElevated     float64 `json:"user_risk_elevated" toml:"user_risk_elevated" env:"AML_USER_RISK_ELEVATED" env-default:"0.5"`
+		TightenedReviewScore float64 `json:"tightened_review_score" toml:"tightened_review_score" env:"AML_TIGHTENED_REVIEW_SCORE" env-default:"0.3"`
+		TightenedRejectScore float64 `json:"tightened_reject_score" toml:"tightened_reject_score" env:"AML_TIGHTENED_REJECT_SCORE" env-default:"0.5"`
+	}
+
+	Admin struct {
+		// Подсети, которым разрешён доступ к /admin и /metrics
+		AllowedCIDRs []string `json:"allowed_cidrs" toml:"allowed_cidrs" env:"ADMIN_ALLOWED_CIDRS" env-separator:"," env-default:"127.0.0.0/8,::1/128"`
+
+		// Отдельный порт для административных маршрутов. Если не задан, маршруты обслуживает основной сервер.
+		Port         string `json:"port" toml:"port" env:"ADMIN_PORT"`
+		TLSCertFile  string `json:"tls_cert_file" toml:"tls_cert_file" env:"ADMIN_TLS_CERT_FILE"`
+		TLSKeyFile   string `json:"tls_key_file" toml:"tls_key_file" env:"ADMIN_TLS_KEY_FILE"`
+		ClientCAFile string `json:"client_ca_file" toml:"client_ca_file" env:"ADMIN_CLIENT_CA_FILE"` // Enables mTLS on the admin port
+
+		// Права на действия ранбуков: "оператор=действие|действие" или "оператор=*". Оператор — CN клиентского сертификата
+		// или IP без mTLS. Действия: retry_dead_letter, rerun_aml, recredit_deposit, replay_webhook. Пусто — запрещено всем.
+		RunbookOperators []string `json:"runbook_operators" toml:"runbook_operators" env:"ADMIN_RUNBOOK_OPERATORS" env-separator:","`
+
+		// Секреты TOTP операторов для действий, перемещающих средства: "оператор=BASE32 секрет". Оператор — как в RunbookOperators.
+		// Оператор без секрета не может выполнять такие действия.
+		TwoFactorSecrets []string `json:"two_factor_secrets" toml:"two_factor_secrets" env:"ADMIN_TWO_FACTOR_SECRETS" env-separator:","`
 	}
 
 	Log struct {
-		Level slog.Level `json:"level" toml:"level" env:"LOG_LEVEL"`
+		Level  slog.Level `json:"level" toml:"level" env:"LOG_LEVEL"`
+		Format string     `json:"format" toml:"format" env:"LOG_FORMAT" env-default:"text"` // text or json
+
+		// Сэмплирование debug записей: первые N одинаковых сообщений в секунду, далее каждое M-е. 0 отключает.
+		SampleFirst      uint64 `json:"sample_first" toml:"sample_first" env:"LOG_SAMPLE_FIRST" env-default:"0"`
+		SampleThereafter uint64 `json:"sample_thereafter" toml:"sample_thereafter" env:"LOG_SAMPLE_THEREAFTER" env-default:"100"`
+
+		// Отправка логов во внешнее хранилище: loki или elasticsearch
+		Sink      string `json:"sink" toml:"sink" env:"LOG_SINK"`
+		SinkURL   string `json:"sink_url" toml:"sink_url" env:"LOG_SINK_URL"`
+		SinkIndex string `json:"sink_index" toml:"sink_index" env:"LOG_SINK_INDEX" env-default:"p2p-trading-logs"`
 	}
 
 	Tracing struct {
 		URL string ` json:"url" toml:"url" env:"TRACING_URL"`
+
+		// Sentry error reporting, disabled when DSN is empty
+		SentryDSN     string `json:"sentry_dsn" toml:"sentry_dsn" env:"SENTRY_DSN"`
+		SentryRelease string `json:"sentry_release" toml:"sentry_release" env:"SENTRY_RELEASE"`
 	}
 
 	Workers struct {
 		OrderExpiration      int `json:"order_expiration" toml:"order_expiration" env:"ORDER_EXPIRATION" env-default:"180"`                 // Default 180 minutes (3 hours)
 		OrderCleanupInterval int `json:"order_cleanup_interval" toml:"order_cleanup_interval" env:"ORDER_CLEANUP_INTERVAL" env-default:"5"` // Default 5 minutes
+		// Проекции дашборда: период инкрементального обновления и полной пересборки
+		DashboardInterval        int `json:"dashboard_interval" toml:"dashboard_interval" env:"DASHBOARD_INTERVAL" env-default:"30"`                         // Default 30 seconds
+		DashboardRebuildInterval int `json:"dashboard_rebuild_interval" toml:"dashboard_rebuild_interval" env:"DASHBOARD_REBUILD_INTERVAL" env-default:"24"` // Default 24 hours
+		// Архив ончейн-доказательств зачисленных депозитов: период, размер пачки и число попыток для депозита
+		EvidenceInterval    int `json:"evidence_interval" toml:"evidence_interval" env:"EVIDENCE_INTERVAL" env-default:"60"` // Default 60 seconds
+		EvidenceBatchSize   int `json:"evidence_batch_size" toml:"evidence_batch_size" env:"EVIDENCE_BATCH_SIZE" env-default:"50"`
+		EvidenceMaxAttempts int `json:"evidence_max_attempts" toml:"evidence_max_attempts" env:"EVIDENCE_MAX_ATTEMPTS" env-default:"10"`
+	}
+
+	Orders struct {
+		// Уникальная дробная добавка к сумме ордера (100 -> 100.0037), позволяет принимать оплату
+		// нескольких ордеров на один кошелек и сопоставлять переводы по точной сумме
+		AmountFingerprinting bool `json:"amount_fingerprinting" toml:"amount_fingerprinting" env:"ORDER_AMOUNT_FINGERPRINTING" env-default:"false"`
+		FingerprintDecimals  int  `json:"fingerprint_decimals" toml:"fingerprint_decimals" env:"ORDER_FINGERPRINT_DECIMALS" env-default:"4"`
+		// Уникальное мемо депозита для каждого ордера: перевод на общий адрес сопоставляется с ордером по мемо
+		DepositMemos bool `json:"deposit_memos" toml:"deposit_memos" env:"ORDER_DEPOSIT_MEMOS" env-default:"false"`
+		// Омнибус режим: депозиты мерчантов, переключенных администратором, принимаются на один горячий кошелек
+		// с этим HD путем и сопоставляются по уникальной сумме или мемо. Пустой путь отключает режим
+		OmnibusWalletPath string `json:"omnibus_wallet_path" toml:"omnibus_wallet_path" env:"ORDER_OMNIBUS_WALLET_PATH"`
+
+		// Пакетное создание ордеров: максимум ордеров в запросе и размер пачки, создаваемой в одной транзакции БД
+		BatchMaxOrders int `json:"batch_max_orders" toml:"batch_max_orders" env:"ORDER_BATCH_MAX_ORDERS" env-default:"100"`
+		BatchChunkSize int `json:"batch_chunk_size" toml:"batch_chunk_size" env:"ORDER_BATCH_CHUNK_SIZE" env-default:"20"`
+
+		// Регулярные ордера: период проверки расписаний в секундах и лимит активных и приостановленных расписаний пользователя
+		ScheduleInterval    int `json:"schedule_interval" toml:"schedule_interval" env:"ORDER_SCHEDULE_INTERVAL" env-default:"60"`
+		MaxSchedulesPerUser int `json:"max_schedules_per_user" toml:"max_schedules_per_user" env:"ORDER_MAX_SCHEDULES" env-default:"20"`
+
+		// Квитанции об оплате ордеров отправляются через уведомления: период отправки в секундах и число попыток доставки
+		ReceiptInterval    int `json:"receipt_interval" toml:"receipt_interval" env:"ORDER_RECEIPT_INTERVAL" env-default:"30"`
+		ReceiptMaxAttempts int `json:"receipt_max_attempts" toml:"receipt_max_attempts" env:"ORDER_RECEIPT_MAX_ATTEMPTS" env-default:"5"`
+
+		// Обработка оплаченных ордеров: начисления мерчанта, события WebSocket и webhook, свип и квитанции.
+		// Период в секундах, число попыток и webhook событий с секретом подписи (пустой адрес отключает webhook)
+		CompletionInterval      int    `json:"completion_interval" toml:"completion_interval" env:"ORDER_COMPLETION_INTERVAL" env-default:"10"`
+		CompletionMaxAttempts   int    `json:"completion_max_attempts" toml:"completion_max_attempts" env:"ORDER_COMPLETION_MAX_ATTEMPTS" env-default:"10"`
+		CompletionWebhookURL    string `json:"completion_webhook_url" toml:"completion_webhook_url" env:"ORDER_COMPLETION_WEBHOOK_URL"`
+		CompletionWebhookSecret string `json:"completion_webhook_secret" toml:"completion_webhook_secret" env:"ORDER_COMPLETION_WEBHOOK_SECRET"`
+
+		// Курсы для котирования счетов и комиссий в форме ASSET/FIAT=rate (стоимость одной единицы актива в фиате).
+		// Значений по умолчанию нет: устаревший курс хуже отсутствующего. Без курса пары счета и фиатные выплаты
+		// в этой валюте отклоняются, а оценки в фиате не показываются
+		InvoiceRates []string `json:"invoice_rates" toml:"invoice_rates" env:"INVOICE_RATES" env-separator:","`
+
+		// Кеш курсов для фиатной оценки балансов, транзакций и ордеров: валюты оценки (первая — по умолчанию),
+		// период обновления в секундах, период снимков неизменившегося курса и срок годности курса в минутах
+		PriceCurrencies       []string `json:"price_currencies" toml:"price_currencies" env:"PRICE_CURRENCIES" env-separator:"," env-default:"USD,EUR,RUB"`
+		PriceCacheInterval    int      `json:"price_cache_interval" toml:"price_cache_interval" env:"PRICE_CACHE_INTERVAL" env-default:"60"`
+		PriceSnapshotInterval int      `json:"price_snapshot_interval" toml:"price_snapshot_interval" env:"PRICE_SNAPSHOT_INTERVAL" env-default:"60"`
+		PriceMaxAge           int      `json:"price_max_age" toml:"price_max_age" env:"PRICE_MAX_AGE" env-default:"1440"`
+
+		// Возвраты: автоматическое создание для отклоненных AML депозитов и исполнение без участия администратора
+		RefundAMLRejected  bool `json:"refund_aml_rejected" toml:"refund_aml_rejected" env:"REFUND_AML_REJECTED" env-default:"true"`
+		AutoExecuteRefunds bool `json:"auto_execute_refunds" toml:"auto_execute_refunds" env:"AUTO_EXECUTE_REFUNDS" env-default:"false"`
+
+		// Депозит, пришедший после истечения ордера: refund — вернуть отправителю, reopen — переоткрыть ордер
+		// и зачесть депозит. Период поиска поздних депозитов в секундах
+		LateDepositPolicy   string `json:"late_deposit_policy" toml:"late_deposit_policy" env:"LATE_DEPOSIT_POLICY" env-default:"refund"`
+		LateDepositInterval int    `json:"late_deposit_interval" toml:"late_deposit_interval" env:"LATE_DEPOSIT_INTERVAL" env-default:"60"`
+
+		// Депозит больше DepositHoldMultiple средних депозитов пользователя удерживается до ручного освобождения.
+		// Правило действует при наличии DepositHoldMinHistory предыдущих депозитов, пусто или 0 отключает удержание
+		DepositHoldMultiple   string `json:"deposit_hold_multiple" toml:"deposit_hold_multiple" env:"DEPOSIT_HOLD_MULTIPLE" env-default:"10"`
+		DepositHoldMinHistory int    `json:"deposit_hold_min_history" toml:"deposit_hold_min_history" env:"DEPOSIT_HOLD_MIN_HISTORY" env-default:"3"`
+
+		// Серия из DepositSpamThreshold одинаковых переводов меньше минимальной суммы ордера от одного отправителя
+		// за DepositSpamWindow минут не зачисляется в ордера, 0 отключает правило
+		DepositSpamThreshold int `json:"deposit_spam_threshold" toml:"deposit_spam_threshold" env:"DEPOSIT_SPAM_THRESHOLD" env-default:"5"`
+		DepositSpamWindow    int `json:"deposit_spam_window" toml:"deposit_spam_window" env:"DEPOSIT_SPAM_WINDOW" env-default:"60"`
+
+		// Отправитель, чьи переводы меньше минимальной суммы ордера получили DepositProbeWallets разных депозитных
+		// кошельков за DepositProbeWindow минут, прощупывает адреса: переводы не зачисляются, поднимается оповещение,
+		// при DepositProbeBlacklist отправитель попадает в локальный черный список AML. 0 отключает правило
+		DepositProbeWallets   int  `json:"deposit_probe_wallets" toml:"deposit_probe_wallets" env:"DEPOSIT_PROBE_WALLETS" env-default:"5"`
+		DepositProbeWindow    int  `json:"deposit_probe_window" toml:"deposit_probe_window" env:"DEPOSIT_PROBE_WINDOW" env-default:"60"`
+		DepositProbeBlacklist bool `json:"deposit_probe_blacklist" toml:"deposit_probe_blacklist" env:"DEPOSIT_PROBE_BLACKLIST" env-default:"false"`
+	}
+
+	Treasury struct {
+		// Gnosis Safe казначейства: крупные выводы и свипы оформляются предложениями через Safe Transaction Service.
+		// Пустой адрес отключает multisig, все переводы отправляются напрямую.
+		SafeAddress    string `json:"safe_address" toml:"safe_address" env:"TREASURY_SAFE_ADDRESS"`
+		SafeServiceURL string `json:"safe_service_url" toml:"safe_service_url" env:"TREASURY_SAFE_SERVICE_URL" env-default:"https://safe-transaction-bsc.safe.global"`
+		// Путь деривации ключа, добавленного владельцем или делегатом Safe
+		ProposerPath      string `json:"proposer_path" toml:"proposer_path" env:"TREASURY_PROPOSER_PATH"`
+		ProposalThreshold string `json:"proposal_threshold" toml:"proposal_threshold" env:"TREASURY_PROPOSAL_THRESHOLD" env-default:"10000"` // In asset units
+		PollInterval      int    `json:"poll_interval" toml:"poll_interval" env:"TREASURY_POLL_INTERVAL" env-default:"30"`                   // Default 30 seconds
+
+		// Роли кошельков для /admin/treasury: Safe считается холодным, адрес назначения свипов — горячим
+		HotWallets  []string `json:"hot_wallets" toml:"hot_wallets" env:"TREASURY_HOT_WALLETS" env-separator:","`
+		ColdWallets []string `json:"cold_wallets" toml:"cold_wallets" env:"TREASURY_COLD_WALLETS" env-separator:","`
+
+		// Прямые выводы копятся в очереди и раз в WithdrawalBatchInterval минут отправляются одной транзакцией
+		// BatchCollector.multisend (контракт и оператор — SWEEP_COLLECTOR_ADDRESS и SWEEP_RELAYER_PATH).
+		// 0 — выводы отправляются сразу
+		WithdrawalBatchInterval int `json:"withdrawal_batch_interval" toml:"withdrawal_batch_interval" env:"WITHDRAWAL_BATCH_INTERVAL" env-default:"0"` // minutes
+		WithdrawalBatchSize     int `json:"withdrawal_batch_size" toml:"withdrawal_batch_size" env:"WITHDRAWAL_BATCH_SIZE" env-default:"50"`
+	}
+
+	Sweeps struct {
+		// Консолидация USDT с депозитных кошельков, адрес назначения по умолчанию — Safe казначейства
+		Enabled     bool   `json:"enabled" toml:"enabled" env:"SWEEPS_ENABLED" env-default:"false"`
+		Destination string `json:"destination" toml:"destination" env:"SWEEP_DESTINATION"`
+		MinAmount   string `json:"min_amount" toml:"min_amount" env:"SWEEP_MIN_AMOUNT" env-default:"10"` // USDT
+		Interval    int    `json:"interval" toml:"interval" env:"SWEEP_INTERVAL" env-default:"60"`       // Default 60 minutes
+
+		// Gasless свипы через EIP-2612 permit: газ оплачивает relayer с балансом BNB
+		Gasless     bool   `json:"gasless" toml:"gasless" env:"SWEEP_GASLESS" env-default:"true"`
+		RelayerPath string `json:"relayer_path" toml:"relayer_path" env:"SWEEP_RELAYER_PATH"`
+
+		// Пакетные свипы через контракт contracts/BatchCollector.sol, оператор контракта — relayer
+		CollectorAddress string `json:"collector_address" toml:"collector_address" env:"SWEEP_COLLECTOR_ADDRESS"`
+		BatchSize        int    `json:"batch_size" toml:"batch_size" env:"SWEEP_BATCH_SIZE" env-default:"50"`
+
+		// Остатки на кошельках без ожидающих ордеров дольше DormantDays дней (0 — отключено): sweep или refund.
+		// Работает при включенных свипах, адрес назначения — тот же
+		DormantDays      int    `json:"dormant_days" toml:"dormant_days" env:"DORMANT_SWEEP_DAYS" env-default:"30"`
+		DormantAction    string `json:"dormant_action" toml:"dormant_action" env:"DORMANT_SWEEP_ACTION" env-default:"sweep"`
+		DormantMinAmount string `json:"dormant_min_amount" toml:"dormant_min_amount" env:"DORMANT_SWEEP_MIN_AMOUNT" env-default:"0.5"` // USDT
+		DormantInterval  int    `json:"dormant_interval" toml:"dormant_interval" env:"DORMANT_SWEEP_INTERVAL" env-default:"24"`        // Hours
+
+		// Остатки BNB на депозитных кошельках (неизрасходованный газ) от BNBDustMinAmount возвращаются на газовый кошелек,
+		// если остаток больше комиссии перевода. Пустой BNBDustGasWallet отключает задачу, отчет доступен всегда
+		BNBDustGasWallet string `json:"bnb_dust_gas_wallet" toml:"bnb_dust_gas_wallet" env:"BNB_DUST_GAS_WALLET"`
+		BNBDustMinAmount string `json:"bnb_dust_min_amount" toml:"bnb_dust_min_amount" env:"BNB_DUST_MIN_AMOUNT" env-default:"0.001"` // BNB
+		BNBDustInterval  int    `json:"bnb_dust_interval" toml:"bnb_dust_interval" env:"BNB_DUST_INTERVAL" env-default:"24"`          // Hours
+	}
+
+	Forwarders struct {
+		// CREATE2 форвардеры (contracts/ForwarderFactory.sol) как депозитные адреса ордеров вместо HD кошельков.
+		// Пустой адрес фабрики отключает схему.
+		FactoryAddress string `json:"factory_address" toml:"factory_address" env:"FORWARDER_FACTORY_ADDRESS"`
+		InitCodeHash   string `json:"init_code_hash" toml:"init_code_hash" env:"FORWARDER_INIT_CODE_HASH"` // ForwarderFactory.initCodeHash()
+		// Ключ, оплачивающий газ деплоя и flush форвардеров
+		SenderPath string `json:"sender_path" toml:"sender_path" env:"FORWARDER_SENDER_PATH"`
+		MinAmount  string `json:"min_amount" toml:"min_amount" env:"FORWARDER_MIN_AMOUNT" env-default:"1"` // USDT
+		Interval   int    `json:"interval" toml:"interval" env:"FORWARDER_INTERVAL" env-default:"10"`      // Default 10 minutes
+		BatchSize  int    `json:"batch_size" toml:"batch_size" env:"FORWARDER_BATCH_SIZE" env-default:"50"`
+	}
+
+	Allowances struct {
+		// ERC-20 approvals с мастер-кошелька контрактам форвардеров, коллектора и multisend. Пустой путь отключает менеджер
+		OwnerPath string `json:"owner_path" toml:"owner_path" env:"ALLOWANCE_OWNER_PATH"`
+		// Контракты, которым можно выдать approval, помимо коллектора и фабрики форвардеров
+		Spenders      []string `json:"spenders" toml:"spenders" env:"ALLOWANCE_SPENDERS" env-separator:","`
+		MaxAmount     string   `json:"max_amount" toml:"max_amount" env:"ALLOWANCE_MAX_AMOUNT" env-default:"100000"`          // USDT
+		DefaultExpiry int      `json:"default_expiry" toml:"default_expiry" env:"ALLOWANCE_DEFAULT_EXPIRY" env-default:"168"` // Hours
+		MaxExpiry     int      `json:"max_expiry" toml:"max_expiry" env:"ALLOWANCE_MAX_EXPIRY" env-default:"720"`             // Hours
+		CheckInterval int      `json:"check_interval" toml:"check_interval" env:"ALLOWANCE_CHECK_INTERVAL" env-default:"10"`  // Minutes
+	}
+
+	Reports struct {
+		// Газ в отчете P&L пересчитывается в единицы актива через курсы BNB и актива к этой валюте (INVOICE_RATES)
+		RateCurrency string `json:"rate_currency" toml:"rate_currency" env:"REPORTS_RATE_CURRENCY" env-default:"USD"`
+		// Интервал получения квитанций отправленных транзакций для учета газа
+		LedgerSettleInterval int `json:"ledger_settle_interval" toml:"ledger_settle_interval" env:"LEDGER_SETTLE_INTERVAL" env-default:"60"` // Default 60 seconds
+		// Суточные бюджеты газа (UTC): транзакции сверх бюджета откладываются до следующего запуска. Пусто или 0 — без лимита
+		GasBudgetDailyBNB  string `json:"gas_budget_daily_bnb" toml:"gas_budget_daily_bnb" env:"GAS_BUDGET_DAILY_BNB"`
+		GasBudgetDailyFiat string `json:"gas_budget_daily_fiat" toml:"gas_budget_daily_fiat" env:"GAS_BUDGET_DAILY_FIAT"` // В валюте REPORTS_RATE_CURRENCY
+		// Лимиты по видам операций в BNB: sweep=0.2,withdrawal=1
+		GasBudgetPerOperation []string `json:"gas_budget_per_operation" toml:"gas_budget_per_operation" env:"GAS_BUDGET_PER_OPERATION" env-separator:","`
+	}
+
+	Reconciliation struct {
+		// Суточная сверка для бухгалтерии отправляется на webhook и/или загружается по SFTP. Без обоих каналов отключена
+		ReconciliationWebhookURL string `json:"webhook_url" toml:"webhook_url" env:"RECONCILIATION_WEBHOOK_URL"`
+		// Секрет HMAC-SHA256 подписи сводки, обязателен при включенной сверке
+		ReconciliationSecret string `json:"secret" toml:"secret" env:"RECONCILIATION_SECRET"`
+		ReconciliationHour   int    `json:"hour" toml:"hour" env:"RECONCILIATION_HOUR" env-default:"2"`         // UTC hour after which the previous day is sent
+		SFTPAddress          string `json:"sftp_address" toml:"sftp_address" env:"RECONCILIATION_SFTP_ADDRESS"` // host:port
+		SFTPUser             string `json:"sftp_user" toml:"sftp_user" env:"RECONCILIATION_SFTP_USER"`
+		SFTPPassword         string `json:"sftp_password" toml:"sftp_password" env:"RECONCILIATION_SFTP_PASSWORD"`
+		SFTPPrivateKey       string `json:"sftp_private_key" toml:"sftp_private_key" env:"RECONCILIATION_SFTP_PRIVATE_KEY"` // PEM
+		// Ключ сервера в формате authorized_keys, без него подключение отклоняется
+		SFTPHostKey   string `json:"sftp_host_key" toml:"sftp_host_key" env:"RECONCILIATION_SFTP_HOST_KEY"`
+		SFTPDirectory string `json:"sftp_directory" toml:"sftp_directory" env:"RECONCILIATION_SFTP_DIRECTORY" env-default:"."`
+	}
+
+	Privacy struct {
+		// Раскрывать заметки AML в выгрузке данных пользователя. По умолчанию скрыты: во многих юрисдикциях
+		// сообщать клиенту о подозрениях запрещено (tipping-off)
+		ExportAMLNotes bool `json:"export_aml_notes" toml:"export_aml_notes" env:"PRIVACY_EXPORT_AML_NOTES" env-default:"false"`
+	}
+
+	Closures struct {
+		// Остатки закрываемых аккаунтов переводятся сюда, по умолчанию — адрес назначения свипов или Safe казначейства
+		SweepDestination string `json:"sweep_destination" toml:"sweep_destination" env:"CLOSURE_SWEEP_DESTINATION"`
+		Interval         int    `json:"interval" toml:"interval" env:"CLOSURE_INTERVAL" env-default:"5"` // Default 5 minutes
+		// Персональные данные закрытого аккаунта обезличиваются по истечении срока хранения
+		ErasureDelay int `json:"erasure_delay" toml:"erasure_delay" env:"CLOSURE_ERASURE_DELAY" env-default:"30"` // Default 30 days
+	}
+
+	DepositSLA struct {
+		// SLA по p95 задержки от обнаружения депозита до подтверждения и до зачисления, в форме chain=seconds
+		ConfirmationSLA []string `json:"confirmation_sla" toml:"confirmation_sla" env:"DEPOSIT_CONFIRMATION_SLA" env-separator:"," env-default:"bsc=180"`
+		CreditSLA       []string `json:"credit_sla" toml:"credit_sla" env:"DEPOSIT_CREDIT_SLA" env-separator:"," env-default:"bsc=300"`
+		// Депозит, не зачисленный за этот срок, поднимает отдельное оповещение
+		HardDeadline int `json:"hard_deadline" toml:"hard_deadline" env:"DEPOSIT_HARD_DEADLINE" env-default:"30"` // Default 30 minutes
+		Window       int `json:"window" toml:"window" env:"DEPOSIT_SLA_WINDOW" env-default:"24"`                  // Default 24 hours
+		Interval     int `json:"interval" toml:"interval" env:"DEPOSIT_SLA_INTERVAL" env-default:"60"`            // Default 60 seconds
+	}
+
+	Withdrawals struct {
+		// Лимиты вывода по уровням пользователей в единицах актива за скользящий час и сутки: basic=1000/5000,verified=10000/50000.
+		// Пустой список отключает пользовательские лимиты, 0 — без ограничения
+		Tiers       []string `json:"tiers" toml:"tiers" env:"WITHDRAWAL_TIERS" env-separator:","`
+		DefaultTier string   `json:"default_tier" toml:"default_tier" env:"WITHDRAWAL_DEFAULT_TIER" env-default:"basic"`
+		// Общий лимит оттока с горячих кошельков, пусто — без ограничения
+		GlobalHourly string `json:"global_hourly" toml:"global_hourly" env:"WITHDRAWAL_GLOBAL_HOURLY"`
+		GlobalDaily  string `json:"global_daily" toml:"global_daily" env:"WITHDRAWAL_GLOBAL_DAILY"`
+	}
+
+	Wallets struct {
+		// Повторная выдача свободного кошелька постоянному клиенту вместо нового
+		ReuseEnabled   bool `json:"reuse_enabled" toml:"reuse_enabled" env:"WALLET_REUSE_ENABLED" env-default:"false"`
+		ReuseMinOrders int  `json:"reuse_min_orders" toml:"reuse_min_orders" env:"WALLET_REUSE_MIN_ORDERS" env-default:"1"`
+		// Кошельки без депозитов дольше GCRetention дней выводятся из мониторинга, 0 — отключено. Интервал в часах.
+		GCRetention  int    `json:"gc_retention" toml:"gc_retention" env:"WALLET_GC_RETENTION" env-default:"90"`
+		GCInterval   int    `json:"gc_interval" toml:"gc_interval" env:"WALLET_GC_INTERVAL" env-default:"6"`
+		GCNativeDust string `json:"gc_native_dust" toml:"gc_native_dust" env:"WALLET_GC_NATIVE_DUST" env-default:"0.0001"`
+		// Пул заранее выведенных кошельков по уровням пользователей: basic=20,verified=50. Пусто — пул отключен.
+		// Кошельки пула выводятся от служебного аккаунта PoolAccount, он должен быть больше любого ID пользователя
+		PoolSizes    []string `json:"pool_sizes" toml:"pool_sizes" env:"WALLET_POOL_SIZES" env-separator:","`
+		PoolAccount  int64    `json:"pool_account" toml:"pool_account" env:"WALLET_POOL_ACCOUNT" env-default:"4000000"`
+		PoolInterval int      `json:"pool_interval" toml:"pool_interval" env:"WALLET_POOL_INTERVAL" env-default:"30"` // Seconds
+		// Период проверки очереди импортов кошельков из предыдущей системы
+		ImportInterval int `json:"import_interval" toml:"import_interval" env:"WALLET_IMPORT_INTERVAL" env-default:"30"` // Seconds
+		// Размеры LRU кешей отслеживаемых адресов и балансов, вытесненные записи читаются из базы
+		AddressCacheSize int `json:"address_cache_size" toml:"address_cache_size" env:"WALLET_ADDRESS_CACHE_SIZE" env-default:"100000"`
+		BalanceCacheSize int `json:"balance_cache_size" toml:"balance_cache_size" env:"WALLET_BALANCE_CACHE_SIZE" env-default:"100000"`
+	}
+
+	Settlements struct {
+		// Выплаты мерчантам по завершенным ордерам с кошелька PayoutWalletID (ID в таблице wallets), 0 — отключено.
+		// Ордер включается в выплату через Delay часов после завершения, комиссия платформы в базисных пунктах
+		PayoutWalletID int    `json:"payout_wallet_id" toml:"payout_wallet_id" env:"SETTLEMENT_PAYOUT_WALLET_ID" env-default:"0"`
+		Interval       int    `json:"interval" toml:"interval" env:"SETTLEMENT_INTERVAL" env-default:"24"` // Hours
+		Delay          int    `json:"delay" toml:"delay" env:"SETTLEMENT_DELAY" env-default:"24"`          // Hours
+		FeeBPS         int    `json:"fee_bps" toml:"fee_bps" env:"SETTLEMENT_FEE_BPS" env-default:"100"`
+		MinAmount      string `json:"min_amount" toml:"min_amount" env:"SETTLEMENT_MIN_AMOUNT" env-default:"10"` // USDT
+	}
+
+	FiatPayouts struct {
+		// Способы фиатных выплат продавцам: bank_transfer, sepa, sbp. Пустой список отключает выплаты.
+		// Пока подключены только заглушки провайдеров, исполняющие выплату через StubSettleAfter секунд
+		Methods         []string `json:"methods" toml:"methods" env:"FIAT_PAYOUT_METHODS" env-separator:","`
+		BankCurrencies  []string `json:"bank_currencies" toml:"bank_currencies" env:"FIAT_PAYOUT_BANK_CURRENCIES" env-separator:"," env-default:"USD,EUR"`
+		PollInterval    int      `json:"poll_interval" toml:"poll_interval" env:"FIAT_PAYOUT_POLL_INTERVAL" env-default:"60"` // Seconds
+		MaxAttempts     int      `json:"max_attempts" toml:"max_attempts" env:"FIAT_PAYOUT_MAX_ATTEMPTS" env-default:"5"`
+		StubSettleAfter int      `json:"stub_settle_after" toml:"stub_settle_after" env:"FIAT_PAYOUT_STUB_SETTLE_AFTER" env-default:"300"` // Seconds
+	}
+
+	TON struct {
+		// Общий депозитный кошелек TON для оплаты ордеров жетоном с комментарием (мемо). Пусто — прием TON отключен.
+		// Переводы читаются из индексатора toncenter v3, без APIKey запросы ограничены по частоте
+		DepositWallet string `json:"deposit_wallet" toml:"deposit_wallet" env:"TON_DEPOSIT_WALLET"`
+		JettonMaster  string `json:"jetton_master" toml:"jetton_master" env:"TON_JETTON_MASTER" env-default:"EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs"` // USDT
+		Network       string `json:"network" toml:"network" env:"TON_NETWORK" env-default:"mainnet"`
+		APIURL        string `json:"api_url" toml:"api_url" env:"TON_API_URL" env-default:"https://toncenter.com/api/v3"`
+		APIKey        string `json:"api_key" toml:"api_key" env:"TON_API_KEY"`
+		PollInterval  int    `json:"poll_interval" toml:"poll_interval" env:"TON_POLL_INTERVAL" env-default:"10"` // Seconds
+	}
+
+	// Отправка транзакций Solana: лимит вычислений по умолчанию и приоритетная комиссия. Цена единицы вычислений —
+	// перцентиль комиссий недавних слотов (getRecentPrioritizationFees) с множителем приоритета, как у газа BSC,
+	// в пределах PriorityFeeMin..PriorityFeeMax микролампортов
+	Solana struct {
+		ComputeUnitLimit         uint32  `json:"compute_unit_limit" toml:"compute_unit_limit" env:"SOLANA_COMPUTE_UNIT_LIMIT" env-default:"200000"`
+		PriorityFeePercentile    int     `json:"priority_fee_percentile" toml:"priority_fee_percentile" env:"SOLANA_PRIORITY_FEE_PERCENTILE" env-default:"75"`
+		PriorityMultiplierLow    float64 `json:"priority_multiplier_low" toml:"priority_multiplier_low" env:"SOLANA_PRIORITY_MULTIPLIER_LOW" env-default:"0.8"`
+		PriorityMultiplierMedium float64 `json:"priority_multiplier_medium" toml:"priority_multiplier_medium" env:"SOLANA_PRIORITY_MULTIPLIER_MEDIUM" env-default:"1.0"`
+		PriorityMultiplierHigh   float64 `json:"priority_multiplier_high" toml:"priority_multiplier_high" env:"SOLANA_PRIORITY_MULTIPLIER_HIGH" env-default:"1.3"`
+		PriorityFeeMin           uint64  `json:"priority_fee_min" toml:"priority_fee_min" env:"SOLANA_PRIORITY_FEE_MIN" env-default:"1000"`
+		PriorityFeeMax           uint64  `json:"priority_fee_max" toml:"priority_fee_max" env:"SOLANA_PRIORITY_FEE_MAX" env-default:"5000000"`
+	}
+
+	// Кран Solana devnet для тестовых окружений, работает только в режиме BLOCKCHAIN_DEBUG_MODE.
+	// Новые депозитные кошельки Solana получают SOL из аирдропа и тестовые SPL токены, если задан минт
+	// и ключ его mint authority (base58 или JSON массив solana-keygen). Ключ же платит комиссии минта.
+	SolanaFaucet struct {
+		NodeURL       string `json:"node_url" toml:"node_url" env:"SOLANA_FAUCET_NODE_URL" env-default:"https://api.devnet.solana.com"`
+		Network       string `json:"network" toml:"network" env:"SOLANA_FAUCET_NETWORK" env-default:"devnet"`
+		AirdropSOL    string `json:"airdrop_sol" toml:"airdrop_sol" env:"SOLANA_FAUCET_AIRDROP_SOL" env-default:"1"`
+		Mint          string `json:"mint" toml:"mint" env:"SOLANA_FAUCET_MINT"`
+		MintAuthority string `json:"mint_authority" toml:"mint_authority" env:"SOLANA_FAUCET_MINT_AUTHORITY"`
+		MintDecimals  int    `json:"mint_decimals" toml:"mint_decimals" env:"SOLANA_FAUCET_MINT_DECIMALS" env-default:"6"`
+		MintAmount    string `json:"mint_amount" toml:"mint_amount" env:"SOLANA_FAUCET_MINT_AMOUNT" env-default:"1000"`
+		PollInterval  int    `json:"poll_interval" toml:"poll_interval" env:"SOLANA_FAUCET_POLL_INTERVAL" env-default:"30"` // Seconds, 0 — только ручной запрос
+	}
+
+	Timeouts struct {
+		// Дедлайны внешних вызовов в секундах, 0 — без дедлайна. RPC ограничивает каждый HTTP запрос к ноде,
+		// AML — одну проверку у внешнего провайдера, HTTP — запросы к остальным провайдерам (CAPTCHA, Safe, toncenter)
+		RPC  int `json:"rpc" toml:"rpc" env:"TIMEOUT_RPC" env-default:"15"`
+		AML  int `json:"aml" toml:"aml" env:"TIMEOUT_AML" env-default:"10"`
+		HTTP int `json:"http" toml:"http" env:"TIMEOUT_HTTP" env-default:"10"`
+	}
+
+	MarketData struct {
+		// Свечи пар Symbols берутся из потока биржи вместо симуляции, пустой список — все пары симулируются.
+		// После переподключения пропущенные свечи догружаются по REST, закрытые свечи сохраняются в таблицу candles
+		Symbols   []string `json:"symbols" toml:"symbols" env:"MARKET_DATA_SYMBOLS" env-separator:","`
+		StreamURL string   `json:"stream_url" toml:"stream_url" env:"MARKET_DATA_STREAM_URL" env-default:"wss://stream.binance.com:9443"`
+		RESTURL   string   `json:"rest_url" toml:"rest_url" env:"MARKET_DATA_REST_URL" env-default:"https://api.binance.com"`
+		Interval  int      `json:"interval" toml:"interval" env:"MARKET_DATA_INTERVAL" env-default:"5"` // Minutes
+		Window    int      `json:"window" toml:"window" env:"MARKET_DATA_WINDOW" env-default:"288"`     // Candles restored after an outage at most
+	}
+
+	Chaos struct {
+		// Внедрение сбоев для проверки отказоустойчивости на стенде. Работает только в сборке с тегом chaos,
+		// доли — вероятность сбоя одного вызова от 0 до 1
+		Enabled        bool    `json:"enabled" toml:"enabled" env:"CHAOS_ENABLED" env-default:"false"`
+		RPCFailureRate float64 `json:"rpc_failure_rate" toml:"rpc_failure_rate" env:"CHAOS_RPC_FAILURE_RATE" env-default:"0"`
+		DBErrorRate    float64 `json:"db_error_rate" toml:"db_error_rate" env:"CHAOS_DB_ERROR_RATE" env-default:"0"`
+		AMLDelayRate   float64 `json:"aml_delay_rate" toml:"aml_delay_rate" env:"CHAOS_AML_DELAY_RATE" env-default:"0"`
+		AMLDelay       int     `json:"aml_delay" toml:"aml_delay" env:"CHAOS_AML_DELAY" env-default:"15"` // Seconds
+	}
+
+	Security struct {
+		// Two-factor authentication for operations that move funds
+		TwoFactorEnforced bool   `json:"two_factor_enforced" toml:"two_factor_enforced" env:"TWO_FACTOR_ENFORCED" env-default:"false"`
+		TwoFactorIssuer   string `json:"two_factor_issuer" toml:"two_factor_issuer" env:"TWO_FACTOR_ISSUER" env-default:"P2P Exchange"`
+
+		SessionTTL int `json:"session_ttl" toml:"session_ttl" env:"SESSION_TTL" env-default:"720"` // Default 720 hours (30 days)
+		// Shared secret of the identity service that signs login challenges, login is disabled when empty
+		SessionLoginSecret string `json:"session_login_secret" toml:"session_login_secret" env:"SESSION_LOGIN_SECRET" env-default:""`
+
+		// Anti-abuse limits for wallet generation and order creation, 0 disables the limit
+		MaxPendingOrders int `json:"max_pending_orders" toml:"max_pending_orders" env:"MAX_PENDING_ORDERS" env-default:"5"`
+		MaxUnusedWallets int `json:"max_unused_wallets" toml:"max_unused_wallets" env:"MAX_UNUSED_WALLETS" env-default:"20"`
+		WalletCooldown   int `json:"wallet_cooldown" toml:"wallet_cooldown" env:"WALLET_COOLDOWN" env-default:"10"` // Default 10 seconds
+
+		// CAPTCHA (reCAPTCHA/hCaptcha/Turnstile siteverify), disabled when secret is empty
+		CaptchaSecret    string `json:"captcha_secret" toml:"captcha_secret" env:"CAPTCHA_SECRET" env-default:""`
+		CaptchaVerifyURL string `json:"captcha_verify_url" toml:"captcha_verify_url" env:"CAPTCHA_VERIFY_URL" env-default:"https://hcaptcha.com/siteverify"`
 	}
 )
 
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+interface IERC20 {
+    function transferFrom(address from, address to, uint256 amount) external returns (bool);
+}
+
+/// @title BatchCollector
+/// @notice Pulls approved token balances from many deposit wallets to one destination in a single transaction
+/// and pays batched withdrawals from deposit wallets to their recipients.
+/// Deposit wallets approve this contract once; only the operator (the backend sweeper key) may collect,
+/// otherwise anyone could spend the allowances.
+contract BatchCollector {
+    address public operator;
+
+    event OperatorChanged(address indexed previous, address indexed next);
+
+    error NotOperator();
+    error LengthMismatch();
+    error TransferFailed(address from);
+
+    constructor(address initialOperator) {
+        operator = initialOperator;
+        emit OperatorChanged(address(0), initialOperator);
+    }
+
+    modifier onlyOperator() {
+        if (msg.sender != operator) revert NotOperator();
+        _;
+    }
+
+    function setOperator(address next) external onlyOperator {
+        emit OperatorChanged(operator, next);
+        operator = next;
+    }
+
+    /// @notice Transfers amounts[i] of token from from[i] to `to`
+    function collect(address token, address[] calldata from, uint256[] calldata amounts, address to) external onlyOperator {
+        if (from.length != amounts.length) revert LengthMismatch();
+
+        for (uint256 i = 0; i < from.length; i++) {
+            // BSC-USD возвращает bool, некоторые токены ничего не возвращают
+            (bool ok, bytes memory data) =
+                token.call(abi.encodeWithSelector(IERC20.transferFrom.selector, from[i], to, amounts[i]));
+            if (!ok || (data.length != 0 && !abi.decode(data, (bool)))) revert TransferFailed(from[i]);
+        }
+    }
+
+    /// @notice Transfers amounts[i] of token from from[i] to to[i]. Any failed transfer reverts the whole batch,
+    /// the backend then pays the withdrawals one by one.
+    function multisend(address token, address[] calldata from, address[] calldata to, uint256[] calldata amounts)
+        external
+        onlyOperator
+    {
+        if (from.length != amounts.length || to.length != amounts.length) revert LengthMismatch();
+
+        for (uint256 i = 0; i < from.length; i++) {
+            (bool ok, bytes memory data) =
+                token.call(abi.encodeWithSelector(IERC20.transferFrom.selector, from[i], to[i], amounts[i]));
+            if (!ok || (data.length != 0 && !abi.decode(data, (bool)))) revert TransferFailed(from[i]);
+        }
+    }
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+interface IERC20 {
+    function balanceOf(address account) external view returns (uint256);
+    function transfer(address to, uint256 amount) external returns (bool);
+}
+
+/// @title Forwarder
+/// @notice Deposit address deployed with CREATE2 per order. Anyone may flush: tokens only ever go to the
+/// factory destination (master wallet or treasury), so no private key is needed for the deposit address.
+contract Forwarder {
+    address public immutable destination;
+
+    error TransferFailed();
+
+    constructor() {
+        // Адрес назначения берется у фабрики, чтобы init code (и его хеш) не зависел от параметров
+        destination = ForwarderFactory(msg.sender).destination();
+    }
+
+    /// @notice Native BNB is forwarded as soon as it is received
+    receive() external payable {
+        (bool ok,) = destination.call{value: msg.value}("");
+        if (!ok) revert TransferFailed();
+    }
+
+    /// @notice Transfers the whole token balance to the destination
+    function flush(address token) external {
+        uint256 balance = IERC20(token).balanceOf(address(this));
+        if (balance == 0) return;
+
+        // BSC-USD возвращает bool, некоторые токены ничего не возвращают
+        (bool ok, bytes memory data) = token.call(abi.encodeWithSelector(IERC20.transfer.selector, destination, balance));
+        if (!ok || (data.length != 0 && !abi.decode(data, (bool)))) revert TransferFailed();
+    }
+}
+
+/// @title ForwarderFactory
+/// @notice Deploys forwarders at deterministic addresses and flushes them. The backend computes deposit
+/// addresses off-chain as CREATE2(factory, salt, keccak256(type(Forwarder).creationCode)) and deploys
+/// a forwarder only after a deposit arrived, in the same transaction that flushes it.
+contract ForwarderFactory {
+    address public immutable destination;
+
+    event ForwarderDeployed(address indexed forwarder, bytes32 indexed salt);
+
+    constructor(address destination_) {
+        destination = destination_;
+    }
+
+    /// @notice Hash of the forwarder init code, configured in the backend for address computation
+    function initCodeHash() external pure returns (bytes32) {
+        return keccak256(type(Forwarder).creationCode);
+    }
+
+    function computeAddress(bytes32 salt) public view returns (address) {
+        bytes32 hash = keccak256(abi.encodePacked(bytes1(0xff), address(this), salt, keccak256(type(Forwarder).creationCode)));
+        return address(uint160(uint256(hash)));
+    }
+
+    function deploy(bytes32 salt) public returns (address forwarder) {
+        forwarder = address(new Forwarder{salt: salt}());
+        emit ForwarderDeployed(forwarder, salt);
+    }
+
+    /// @notice Deploys missing forwarders and flushes token balances of all of them to the destination
+    function flush(bytes32[] calldata salts, address token) external {
+        for (uint256 i = 0; i < salts.length; i++) {
+            address forwarder = computeAddress(salts[i]);
+            if (forwarder.code.length == 0) {
+                deploy(salts[i]);
+            }
+            Forwarder(payable(forwarder)).flush(token);
+        }
+    }
+}
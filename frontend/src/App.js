@@ -10,6 +10,17 @@ import {NotificationProvider} from './context/NotificationContext';
 import NotificationContainer from './components/NotificationContainer';
 import './App.css';
 
+// Determine WebSocket base URL based on API_URL
+const wsBaseUrl = () => {
+  if (BASE_URL.startsWith('https://')) {
+    return `wss://${BASE_URL.substring(8)}`; // Remove 'https://'
+  }
+  if (BASE_URL.startsWith('http://')) {
+    return `ws://${BASE_URL.substring(7)}`; // Remove 'http://'
+  }
+  return `ws://${BASE_URL}`;
+};
+
 function App() {
   const [tradingPairs, setTradingPairs] = useState([]);
   const [selectedPair, setSelectedPair] = useState('');
@@ -97,17 +108,7 @@ function App() {
       ws.current.close();
     }
 
-    // Determine WebSocket URL based on API_URL
-    let wsProtocol = 'ws://';
-    let wsHost = BASE_URL;
-    if (BASE_URL.startsWith('https://')) {
-      wsProtocol = 'wss://';
-      wsHost = BASE_URL.substring(8); // Remove 'https://'
-    } else if (BASE_URL.startsWith('http://')) {
-      wsHost = BASE_URL.substring(7); // Remove 'http://'
-    }
-
-    const wsUrl = `${wsProtocol}${wsHost}/ws/${selectedPair}`;
+    const wsUrl = `${wsBaseUrl()}/ws/${selectedPair}`;
     console.log(`Setting up WebSocket for ${selectedPair} to ${wsUrl}`);
     const socket = new WebSocket(wsUrl);
 
@@ -131,6 +132,23 @@ function App() {
           });
         }
 
+        // Merge candles backfilled by the server after a feed outage
+        if (update.backfill && update.backfill.length > 0) {
+          setCandleData(prev => {
+            const byTime = new Map(prev.map(candle => [candle.time, candle]));
+            update.backfill.forEach(candle => {
+              byTime.set(candle.time / 1000, {
+                time: candle.time / 1000, // Convert from milliseconds to seconds
+                open: candle.open,
+                high: candle.high,
+                low: candle.low,
+                close: candle.close,
+              });
+            });
+            return Array.from(byTime.values()).sort((a, b) => a.time - b.time);
+          });
+        }
+
         // Update candle data
         if (update.lastCandle) {
           const candle = update.lastCandle;
@@ -169,6 +187,32 @@ function App() {
     };
   }, [selectedPair]);
 
+  // Refresh the pair list when pairs are added or disabled on the server
+  useEffect(() => {
+    const socket = new WebSocket(`${wsBaseUrl()}/ws/meta`);
+
+    socket.onmessage = async (event) => {
+      try {
+        const message = JSON.parse(event.data);
+        if (message.type !== 'pairs_updated') return;
+
+        const pairs = await fetchTradingPairs();
+        setTradingPairs(pairs);
+        setSelectedPair(current =>
+          pairs.some(p => p.symbol === current) || pairs.length === 0 ? current : pairs[0].symbol
+        );
+      } catch (error) {
+        console.error('Error refreshing trading pairs:', error);
+      }
+    };
+
+    socket.onerror = (error) => {
+      console.error('Meta WebSocket error:', error);
+    };
+
+    return () => socket.close();
+  }, []);
+
   const handleSelectPair = (symbol) => {
     setSelectedPair(symbol);
 
@@ -123,6 +123,21 @@ export const getWalletBalances = async (userId) => {
   }
 };
 
+// Get cached balances of several wallets by ID and/or address in one request
+export const queryWalletBalances = async ({ walletIds = [], addresses = [] }, userId) => {
+  try {
+    const query = userId ? `?user_id=${userId}` : '';
+    const response = await axios.post(`${BASE_URL}/wallet/balances/query${query}`, {
+      wallet_ids: walletIds,
+      addresses,
+    });
+    return response.data;
+  } catch (error) {
+    console.error('Error querying wallet balances:', error);
+    throw error;
+  }
+};
+
 // Get balance for a specific wallet
 export const getWalletBalance = async (address) => {
   try {
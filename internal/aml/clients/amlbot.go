@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -16,12 +17,13 @@ type AMLBotService struct {
 	logger    *slog.Logger
 	apiKey    string
 	apiURL    string
+	timeout   time.Duration
 	client    *http.Client
 	isEnabled bool
 }
 
 // NewAMLBotService создает новый сервис для проверки транзакций через AMLBot
-func NewAMLBotService(logger *slog.Logger, apiKey, apiURL string) *AMLBotService {
+func NewAMLBotService(logger *slog.Logger, apiKey, apiURL string, timeout time.Duration) *AMLBotService {
 	isEnabled := apiKey != "" && apiURL != ""
 
 	if !isEnabled {
@@ -39,7 +41,8 @@ func NewAMLBotService(logger *slog.Logger, apiKey, apiURL string) *AMLBotService
 		logger:    logger,
 		apiKey:    apiKey,
 		apiURL:    apiURL,
-		client:    &http.Client{Timeout: 10 * time.Second},
+		timeout:   timeout,
+		client:    &http.Client{},
 		isEnabled: isEnabled,
 	}
 }
@@ -62,6 +65,10 @@ func (s *AMLBotService) CheckAddress(ctx context.Context, address string) (*enti
 		}, nil
 	}
 
+	// Запрос к провайдеру ограничен дедлайном, 0 — без дедлайна
+	ctx, cancel := timeouts.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
 	// Формирование запроса к AMLBot API
 	// AMLBot обычно использует форму для отправки или query параметры
 	apiEndpoint := fmt.Sprintf("%s/address/check", s.apiURL)
@@ -84,7 +91,7 @@ func (s *AMLBotService) CheckAddress(ctx context.Context, address string) (*enti
 	/*
 		resp, err := s.client.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("failed to send request to AMLBot: %w", err)
+			return nil, fmt.Errorf("failed to send request to AMLBot: %w", timeouts.Classify("amlbot", err))
 		}
 		defer resp.Body.Close()
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
 	"log/slog"
 	"net/http"
 	"time"
@@ -14,12 +15,13 @@ type EllipticService struct {
 	logger    *slog.Logger
 	apiKey    string
 	apiURL    string
+	timeout   time.Duration
 	client    *http.Client
 	isEnabled bool
 }
 
 // NewEllipticService создает новый сервис для проверки транзакций через Elliptic (TRM Labs)
-func NewEllipticService(logger *slog.Logger, apiKey, apiURL string) *EllipticService {
+func NewEllipticService(logger *slog.Logger, apiKey, apiURL string, timeout time.Duration) *EllipticService {
 	isEnabled := apiKey != "" && apiURL != ""
 
 	if !isEnabled {
@@ -30,7 +32,8 @@ func NewEllipticService(logger *slog.Logger, apiKey, apiURL string) *EllipticSer
 		logger:    logger,
 		apiKey:    apiKey,
 		apiURL:    apiURL,
-		client:    &http.Client{Timeout: 10 * time.Second},
+		timeout:   timeout,
+		client:    &http.Client{},
 		isEnabled: isEnabled,
 	}
 }
@@ -53,6 +56,10 @@ func (s *EllipticService) CheckAddress(ctx context.Context, address string) (*en
 		}, nil
 	}
 
+	// Запрос к провайдеру ограничен дедлайном, 0 — без дедлайна
+	ctx, cancel := timeouts.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
 	// Формирование запроса к Elliptic API
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/v1/address/%s", s.apiURL, address), nil)
 	if err != nil {
@@ -69,7 +76,7 @@ func (s *EllipticService) CheckAddress(ctx context.Context, address string) (*en
 
 	// resp, err := s.client.Do(req)
 	// if err != nil {
-	//     return nil, fmt.Errorf("failed to send request to Elliptic: %w", err)
+	//     return nil, fmt.Errorf("failed to send request to Elliptic: %w", timeouts.Classify("elliptic", err))
 	// }
 	// defer resp.Body.Close()
 
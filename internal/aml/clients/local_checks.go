@@ -7,25 +7,38 @@ import (
 	"log/slog"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 )
 
+// AssetTiers возвращает актив расчетов: его точность и AML порог из реестра активов
+type AssetTiers interface {
+	Default() entities.Asset
+}
+
 // LocalAMLService представляет сервис для локальных AML проверок без обращения к внешним API
 type LocalAMLService struct {
 	logger *slog.Logger
 
-	// В реальной системе здесь могут быть локальные списки санкций и черные списки
+	// В реальной системе здесь могут быть локальные списки санкций и черные списки.
+	// Правила платформы пополняют список во время работы.
+	riskyMu             sync.RWMutex
 	knownRiskyAddresses map[string]float64
 
-	// Пороговые значения для срабатывания проверок
+	// Пороговые значения для срабатывания проверок: порог актива из реестра, если не задан общий
+	assets               AssetTiers
 	transactionThreshold *big.Float
 }
 
-// NewLocalAMLService создает новый сервис для локальных AML проверок
-func NewLocalAMLService(logger *slog.Logger, thresholdAmount string) *LocalAMLService {
-	threshold, _ := new(big.Float).SetString(thresholdAmount)
-	if threshold == nil {
-		threshold = new(big.Float).SetFloat64(5000.0) // Значение по умолчанию, если не удалось распарсить
+// NewLocalAMLService создает новый сервис для локальных AML проверок.
+// Пустой thresholdAmount означает порог актива из реестра.
+func NewLocalAMLService(logger *slog.Logger, assets AssetTiers, thresholdAmount string) *LocalAMLService {
+	var threshold *big.Float
+	if thresholdAmount != "" {
+		threshold, _ = new(big.Float).SetString(thresholdAmount)
+		if threshold == nil {
+			threshold = new(big.Float).SetFloat64(5000.0) // Значение по умолчанию, если не удалось распарсить
+		}
 	}
 
 	// Инициализируем тестовый список рискованных адресов
@@ -35,22 +48,37 @@ func NewLocalAMLService(logger *slog.Logger, thresholdAmount string) *LocalAMLSe
 	riskyAddresses["0xabcdef123456789abcdef123456789abcdef1234"] = 0.7 // Средний риск
 
 	logger.Info("Initialized local AML service",
-		"threshold", threshold.String(),
+		"threshold", thresholdAmount,
 		"known_risky_addresses", len(riskyAddresses))
 
 	return &LocalAMLService{
 		logger:               logger,
 		knownRiskyAddresses:  riskyAddresses,
+		assets:               assets,
 		transactionThreshold: threshold,
 	}
 }
 
+// AddRiskyAddress добавляет адрес в локальный список рискованных адресов, большая оценка не понижается
+func (s *LocalAMLService) AddRiskyAddress(address string, riskScore float64) {
+	lowercaseAddress := strings.ToLower(address)
+
+	s.riskyMu.Lock()
+	defer s.riskyMu.Unlock()
+	if current, ok := s.knownRiskyAddresses[lowercaseAddress]; ok && current >= riskScore {
+		return
+	}
+	s.knownRiskyAddresses[lowercaseAddress] = riskScore
+}
+
 // CheckAddress проверяет адрес на риски локально
 func (s *LocalAMLService) CheckAddress(ctx context.Context, address string) (*entities.AddressRiskInfo, error) {
 	lowercaseAddress := strings.ToLower(address)
 
 	// Проверяем, известен ли адрес как рискованный
+	s.riskyMu.RLock()
 	riskScore, known := s.knownRiskyAddresses[lowercaseAddress]
+	s.riskyMu.RUnlock()
 
 	var riskLevel entities.RiskLevel
 	if known {
@@ -177,15 +205,24 @@ func (s *LocalAMLService) checkTransactionAmount(amount string) float64 {
 		return 0.5 // Средний риск по умолчанию при ошибке парсинга
 	}
 
-	// Конвертируем amount из "сырых" единиц (с 18 десятичными знаками)
+	asset := s.assets.Default()
+	threshold := s.transactionThreshold
+	if threshold == nil {
+		threshold, _ = new(big.Float).SetString(asset.AMLThreshold)
+	}
+	if threshold == nil || threshold.Sign() <= 0 {
+		return 0.5
+	}
+
+	// Конвертируем amount из "сырых" единиц (с точностью актива)
 	// в стандартные единицы для сравнения с порогом
-	divisor := new(big.Float).SetFloat64(1e18)
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.Decimals)), nil))
 	adjustedAmount := new(big.Float).Quo(amountFloat, divisor)
 
 	// Проверяем, превышает ли сумма пороговое значение
-	if adjustedAmount.Cmp(s.transactionThreshold) >= 0 {
+	if adjustedAmount.Cmp(threshold) >= 0 {
 		// Вычисляем риск в зависимости от того, насколько превышен порог
-		ratio := new(big.Float).Quo(adjustedAmount, s.transactionThreshold)
+		ratio := new(big.Float).Quo(adjustedAmount, threshold)
 
 		// Конвертируем соотношение в float64 для расчета риска
 		ratioFloat, _ := ratio.Float64()
@@ -0,0 +1,203 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// Оценка адресов и сумм, не попавших ни под одно правило
+const scriptedDefaultScore = 0.1
+
+// DefaultScriptedRules — сценарии по умолчанию: адрес на "bad" блокируется, на "fee" уходит на ручную проверку,
+// депозит от 10000 единиц актива уходит на ручную проверку, остальное проходит
+var DefaultScriptedRules = []string{
+	"address:bad=0.9",
+	"address:fee=0.6",
+	"amount:10000=0.6",
+}
+
+// ErrScriptedProviderFailure возвращается правилом с результатом error для проверки отказа провайдера
+var ErrScriptedProviderFailure = errors.New("scripted AML provider failure")
+
+// scriptedRule — сценарий: адрес с окончанием pattern или сумма не меньше minAmount получает оценку score
+type scriptedRule struct {
+	address   string
+	minAmount *big.Float
+	score     float64
+	fail      bool
+}
+
+// ScriptedAMLService — детерминированный AML провайдер для стендов: вердикты задаются правилами
+// по окончанию адреса и сумме, без обращения к внешним API
+type ScriptedAMLService struct {
+	logger *slog.Logger
+	assets AssetTiers
+	rules  []scriptedRule
+}
+
+// NewScriptedAMLService создает провайдер по правилам вида "address:окончание=оценка" и "amount:минимум=оценка".
+// Оценка error имитирует отказ провайдера. Правила проверяются по порядку, срабатывает первое подходящее.
+func NewScriptedAMLService(logger *slog.Logger, assets AssetTiers, rules []string) (*ScriptedAMLService, error) {
+	if len(rules) == 0 {
+		rules = DefaultScriptedRules
+	}
+
+	parsed := make([]scriptedRule, 0, len(rules))
+	for _, entry := range rules {
+		rule, err := parseScriptedRule(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid scripted AML rule %q: %w", entry, err)
+		}
+		parsed = append(parsed, rule)
+	}
+
+	logger.Warn("Scripted AML provider enabled, external AML providers are not used", "rules", len(parsed))
+
+	return &ScriptedAMLService{
+		logger: logger,
+		assets: assets,
+		rules:  parsed,
+	}, nil
+}
+
+func parseScriptedRule(entry string) (scriptedRule, error) {
+	kind, rest, ok := strings.Cut(entry, ":")
+	if !ok {
+		return scriptedRule{}, errors.New("expected kind:pattern=score")
+	}
+	pattern, outcome, ok := strings.Cut(rest, "=")
+	if !ok || pattern == "" {
+		return scriptedRule{}, errors.New("expected kind:pattern=score")
+	}
+
+	var rule scriptedRule
+	switch kind {
+	case "address":
+		rule.address = strings.ToLower(pattern)
+	case "amount":
+		minAmount, ok := new(big.Float).SetString(pattern)
+		if !ok || minAmount.Sign() < 0 {
+			return scriptedRule{}, errors.New("amount must be a non-negative number")
+		}
+		rule.minAmount = minAmount
+	default:
+		return scriptedRule{}, fmt.Errorf("unknown kind %q", kind)
+	}
+
+	if outcome == "error" {
+		rule.fail = true
+		return rule, nil
+	}
+	score, err := strconv.ParseFloat(outcome, 64)
+	if err != nil || score < 0 || score > 1 {
+		return scriptedRule{}, errors.New("score must be between 0 and 1 or error")
+	}
+	rule.score = score
+	return rule, nil
+}
+
+// IsEnabled возвращает статус активации сервиса
+func (s *ScriptedAMLService) IsEnabled() bool {
+	return true
+}
+
+// CheckAddress возвращает оценку первого правила по окончанию адреса
+func (s *ScriptedAMLService) CheckAddress(ctx context.Context, address string) (*entities.AddressRiskInfo, error) {
+	score, rule, err := s.match(strings.ToLower(address), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Scripted AML address check completed", "address", address, "risk_score", score, "rule", rule)
+
+	return &entities.AddressRiskInfo{
+		Address:     address,
+		RiskLevel:   scriptedRiskLevel(score),
+		RiskScore:   score,
+		LastChecked: time.Now(),
+		Category:    "scripted",
+		Source:      "scripted_aml",
+		Tags:        []string{"scripted", rule},
+	}, nil
+}
+
+// CheckTransaction возвращает оценку первого правила по окончанию адреса отправителя или сумме
+func (s *ScriptedAMLService) CheckTransaction(ctx context.Context, txHash, sourceAddress, destinationAddress, amount string) (*entities.AMLCheckResult, error) {
+	score, rule, err := s.match(strings.ToLower(sourceAddress), s.assetAmount(amount))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.AMLCheckResult{
+		TransactionHash:      txHash,
+		WalletAddress:        destinationAddress,
+		SourceAddress:        sourceAddress,
+		RiskLevel:            scriptedRiskLevel(score),
+		RiskSource:           entities.RiskSourceBehavioral,
+		RiskScore:            score,
+		Approved:             score < 0.7, // Те же пороги, что у остальных провайдеров
+		CheckedAt:            time.Now(),
+		Notes:                fmt.Sprintf("Scripted scenario: %s", rule),
+		RequiresReview:       score >= 0.5,
+		ExternalServicesUsed: []string{"scripted_aml"},
+	}
+
+	s.logger.InfoContext(ctx, "Scripted AML transaction check completed",
+		"tx_hash", txHash,
+		"risk_score", result.RiskScore,
+		"approved", result.Approved,
+		"requires_review", result.RequiresReview,
+		"rule", rule)
+
+	return result, nil
+}
+
+// match возвращает оценку первого подходящего правила и его описание для заметок проверки
+func (s *ScriptedAMLService) match(address string, amount *big.Float) (float64, string, error) {
+	for _, rule := range s.rules {
+		var description string
+		switch {
+		case rule.address != "" && strings.HasSuffix(address, rule.address):
+			description = "address:" + rule.address
+		case rule.minAmount != nil && amount != nil && amount.Cmp(rule.minAmount) >= 0:
+			description = "amount:" + rule.minAmount.Text('f', -1)
+		default:
+			continue
+		}
+
+		if rule.fail {
+			return 0, description, fmt.Errorf("%w: %s", ErrScriptedProviderFailure, description)
+		}
+		return rule.score, description, nil
+	}
+	return scriptedDefaultScore, "default", nil
+}
+
+// assetAmount переводит сумму из минимальных единиц в единицы актива, nil — сумму не удалось разобрать
+func (s *ScriptedAMLService) assetAmount(amount string) *big.Float {
+	units, ok := new(big.Float).SetString(amount)
+	if !ok {
+		return nil
+	}
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(s.assets.Default().Decimals)), nil))
+	return units.Quo(units, divisor)
+}
+
+func scriptedRiskLevel(score float64) entities.RiskLevel {
+	switch {
+	case score >= 0.7:
+		return entities.RiskLevelHigh
+	case score >= 0.4:
+		return entities.RiskLevelMedium
+	default:
+		return entities.RiskLevelLow
+	}
+}
@@ -0,0 +1,98 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// isBlackListedSelector — селектор isBlackListed(address) контрактов Tether (ERC20 и TRC20)
+var isBlackListedSelector = crypto.Keccak256([]byte("isBlackListed(address)"))[:4]
+
+// TokenBlacklistService проверяет адреса по on-chain черному списку контракта USDT.
+// Токены без черного списка (например, Binance-Peg BSC-USD) считаются не поддерживающими проверку.
+type TokenBlacklistService struct {
+	logger *slog.Logger
+	client *ethclient.Client
+	token  common.Address
+
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+// NewTokenBlacklistService создает сервис проверки черного списка токена
+func NewTokenBlacklistService(logger *slog.Logger, client *ethclient.Client, tokenAddress string) *TokenBlacklistService {
+	return &TokenBlacklistService{
+		logger: logger,
+		client: client,
+		token:  common.HexToAddress(tokenAddress),
+	}
+}
+
+// IsBlacklisted reports whether the token contract has blacklisted the address
+func (s *TokenBlacklistService) IsBlacklisted(ctx context.Context, address string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, fmt.Errorf("invalid address %q", address)
+	}
+
+	s.mu.Lock()
+	if s.checked && !s.supported {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.mu.Unlock()
+
+	data := append(append([]byte{}, isBlackListedSelector...), common.LeftPadBytes(common.HexToAddress(address).Bytes(), 32)...)
+	result, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &s.token, Data: data}, nil)
+	if err != nil && !strings.Contains(err.Error(), "execution reverted") {
+		return false, fmt.Errorf("failed to query token blacklist: %w", err)
+	}
+
+	// Откат или пустой ответ означает, что у контракта нет функции isBlackListed
+	supported := err == nil && len(result) == 32
+	s.mu.Lock()
+	if !s.checked {
+		s.checked = true
+		s.supported = supported
+		if !supported {
+			s.logger.WarnContext(ctx, "Token contract has no blacklist, screening disabled", "token", s.token.Hex())
+		}
+	}
+	s.mu.Unlock()
+
+	if !supported {
+		return false, nil
+	}
+
+	return result[31] != 0, nil
+}
+
+// CheckAddress возвращает информацию о риске для адреса из черного списка токена или nil, если адрес не заблокирован
+func (s *TokenBlacklistService) CheckAddress(ctx context.Context, address string) (*entities.AddressRiskInfo, error) {
+	blacklisted, err := s.IsBlacklisted(ctx, address)
+	if err != nil || !blacklisted {
+		return nil, err
+	}
+
+	s.logger.WarnContext(ctx, "Address is blacklisted by token contract", "address", address, "token", s.token.Hex())
+
+	return &entities.AddressRiskInfo{
+		Address:     address,
+		RiskLevel:   entities.RiskLevelHigh,
+		RiskScore:   1.0,
+		LastChecked: time.Now(),
+		Category:    "token_blacklist",
+		Source:      "usdt_contract",
+		Tags:        []string{"blacklisted"},
+	}, nil
+}
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+// AccountClosureStatus represents the state of an account closure
+type AccountClosureStatus string
+
+const (
+	AccountClosureRequested AccountClosureStatus = "requested" // Новые ордера заблокированы, ожидает обработчика
+	AccountClosureSweeping  AccountClosureStatus = "sweeping"  // Балансы кошельков переводятся в казначейство
+	AccountClosureClosed    AccountClosureStatus = "closed"    // Кошельки архивированы, сессии отозваны
+)
+
+// AccountClosure — закрытие аккаунта по запросу пользователя
+type AccountClosure struct {
+	ID              string               `json:"id"`
+	UserID          int64                `json:"user_id"`
+	Status          AccountClosureStatus `json:"status"`
+	Reason          string               `json:"reason"`
+	WalletsArchived int                  `json:"wallets_archived"`
+	SessionsRevoked int                  `json:"sessions_revoked"`
+	LastSweepAt     *time.Time           `json:"last_sweep_at,omitempty"`
+	Error           *string              `json:"error,omitempty"`
+	// Персональные данные обезличиваются после EraseAfter, финансовые записи сохраняются
+	EraseAfter  *time.Time `json:"erase_after,omitempty"`
+	ErasedAt    *time.Time `json:"erased_at,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+}
+
+// AccountClosureBlockers — незавершенные операции, при которых закрыть аккаунт нельзя
+type AccountClosureBlockers struct {
+	PendingOrders       int `json:"pending_orders"`
+	OpenRefunds         int `json:"open_refunds"`
+	UnconfirmedDeposits int `json:"unconfirmed_deposits"`
+}
+
+// Any reports whether at least one operation blocks the closure
+func (b AccountClosureBlockers) Any() bool {
+	return b.PendingOrders > 0 || b.OpenRefunds > 0 || b.UnconfirmedDeposits > 0
+}
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// ActivityKind — вид записи в ленте активности пользователя
+type ActivityKind string
+
+const (
+	ActivityOrder      ActivityKind = "order"
+	ActivityDeposit    ActivityKind = "deposit"
+	ActivityWithdrawal ActivityKind = "withdrawal"
+	ActivityAccount    ActivityKind = "account" // Заметное событие аккаунта из журнала аудита
+)
+
+// ActivityItem — запись ленты активности пользователя
+type ActivityItem struct {
+	Kind ActivityKind `json:"kind"`
+	// ID ордера, вывода или события аудита, для депозита — хеш транзакции
+	Ref string `json:"ref"`
+	// Статус ордера, депозита или вывода, для события аккаунта — тип события
+	Status string `json:"status"`
+	// Сумма в единицах актива
+	Amount     string         `json:"amount,omitempty"`
+	Asset      string         `json:"asset,omitempty"`
+	TxHash     *string        `json:"tx_hash,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+
+	// Сеть кошелька депозита или вывода: по ней сумма в минимальных единицах переводится в единицы актива
+	Chain   Chain  `json:"-"`
+	Network string `json:"-"`
+	Units   bool   `json:"-"`
+}
+
+// ActivityPage — страница ленты, следующая страница запрашивается по NextCursor
+type ActivityPage struct {
+	Items      []ActivityItem `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -57,3 +57,50 @@ type TransactionCheck struct {
 	CreatedAt     time.Time `json:"created_at"`
 	Processed     bool      `json:"processed"`
 }
+
+// RiskRollup — сводный риск по AML проверкам входящих переводов кошелька или всех кошельков пользователя
+type RiskRollup struct {
+	// Пусто для сводки пользователя
+	WalletAddress string    `json:"wallet_address,omitempty"`
+	UserID        int64     `json:"user_id"`
+	RiskScore     float64   `json:"risk_score"`
+	RiskLevel     RiskLevel `json:"risk_level"`
+	// Наибольший риск отдельного перевода
+	MaxRiskScore float64 `json:"max_risk_score"`
+	Checks       int     `json:"checks"`
+	// Число переводов, не прошедших проверку
+	Flagged int `json:"flagged"`
+	// Число кошельков в сводке пользователя
+	Wallets       int       `json:"wallets,omitempty"`
+	LastCheckedAt time.Time `json:"last_checked_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ScreeningVerdict — решение AML проверки адреса получателя исходящего перевода, пороги те же, что у депозитов
+type ScreeningVerdict string
+
+const (
+	ScreeningApproved ScreeningVerdict = "approved" // Низкий риск, перевод отправляется
+	ScreeningReview   ScreeningVerdict = "review"   // Перевод отправляется и попадает на ручной разбор
+	ScreeningBlocked  ScreeningVerdict = "blocked"  // Перевод не отправляется
+)
+
+// DestinationScreening — AML проверка адреса получателя вывода или выплаты, связанная с исходящей транзакцией
+type DestinationScreening struct {
+	ID        string               `json:"id"`
+	Kind      TreasuryTransferKind `json:"kind"`
+	ToAddress string               `json:"to_address"`
+	// Сумма в минимальных единицах актива
+	Amount    string           `json:"amount"`
+	RiskLevel RiskLevel        `json:"risk_level"`
+	RiskScore float64          `json:"risk_score"`
+	Category  string           `json:"category,omitempty"`
+	Source    string           `json:"source,omitempty"`
+	Verdict   ScreeningVerdict `json:"verdict"`
+	// Исходящий перевод: отправленная транзакция, предложение Safe или вывод в очереди пакета
+	TxHash       *string   `json:"tx_hash,omitempty"`
+	SafeTxHash   *string   `json:"safe_tx_hash,omitempty"`
+	WithdrawalID *string   `json:"withdrawal_id,omitempty"`
+	InitiatedBy  string    `json:"initiated_by"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
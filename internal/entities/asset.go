@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// Asset — актив, принимаемый платформой: токен в конкретной сети с точностью и лимитами.
+// Суммы лимитов и комиссий хранятся в единицах актива (например "10.5" USDT).
+type Asset struct {
+	ID       int    `json:"id"`
+	Code     string `json:"code"`
+	Chain    Chain  `json:"chain"`
+	Network  string `json:"network"`
+	Contract string `json:"contract,omitempty"` // Пусто для нативной монеты сети
+	Decimals int    `json:"decimals"`
+
+	MinOrderAmount string  `json:"min_order_amount"`
+	MaxOrderAmount *string `json:"max_order_amount,omitempty"` // nil — без ограничения
+	WithdrawalFee  string  `json:"withdrawal_fee"`
+	// Порог суммы, начиная с которого локальная AML проверка повышает риск перевода
+	AMLThreshold string `json:"aml_threshold"`
+	// Переводы меньше этой суммы записываются как пыль и не зачисляются в ордера
+	MinDepositAmount string `json:"min_deposit_amount"`
+
+	DepositsEnabled    bool `json:"deposits_enabled"`
+	WithdrawalsEnabled bool `json:"withdrawals_enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AssetUpdate — изменяемые администратором параметры актива, nil оставляет значение без изменений
+type AssetUpdate struct {
+	MinOrderAmount     *string `json:"min_order_amount"`
+	MaxOrderAmount     *string `json:"max_order_amount"` // "" снимает ограничение
+	WithdrawalFee      *string `json:"withdrawal_fee"`
+	AMLThreshold       *string `json:"aml_threshold"`
+	MinDepositAmount   *string `json:"min_deposit_amount"`
+	DepositsEnabled    *bool   `json:"deposits_enabled"`
+	WithdrawalsEnabled *bool   `json:"withdrawals_enabled"`
+}
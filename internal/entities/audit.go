@@ -0,0 +1,116 @@
+package entities
+
+import "time"
+
+// AuditEventType представляет тип события в журнале аудита
+type AuditEventType string
+
+const (
+	// AuditEventKeySigning фиксирует каждое использование приватного ключа для подписи
+	AuditEventKeySigning AuditEventType = "key_signing"
+
+	// События двухфакторной аутентификации
+	AuditEventTwoFactorEnrolled AuditEventType = "two_factor_enrolled"
+	AuditEventTwoFactorVerified AuditEventType = "two_factor_verified"
+	AuditEventTwoFactorFailed   AuditEventType = "two_factor_failed"
+
+	// События сессий пользователей
+	AuditEventSessionCreated AuditEventType = "session_created"
+	AuditEventSessionRevoked AuditEventType = "session_revoked"
+	AuditEventNewDeviceLogin AuditEventType = "new_device_login"
+	AuditEventLoginFailed    AuditEventType = "login_failed"
+
+	// AuditEventAdminAccessDenied фиксирует отклонённые обращения к административным маршрутам
+	AuditEventAdminAccessDenied AuditEventType = "admin_access_denied"
+
+	// AuditEventRefundExecuted фиксирует отправку средств отправителю депозита
+	AuditEventRefundExecuted AuditEventType = "refund_executed"
+
+	// AuditEventSafeProposalCreated фиксирует предложение транзакции multisig казначейства
+	AuditEventSafeProposalCreated AuditEventType = "safe_proposal_created"
+
+	// AuditEventTokenEventAcknowledged фиксирует подтверждение административного события контракта токена
+	AuditEventTokenEventAcknowledged AuditEventType = "token_event_acknowledged"
+
+	// AuditEventAssetUpdated фиксирует изменение лимитов и флагов актива
+	AuditEventAssetUpdated AuditEventType = "asset_updated"
+
+	// AuditEventUserDataExported и AuditEventUserErased фиксируют обработку запросов субъекта данных (GDPR)
+	AuditEventUserDataExported AuditEventType = "user_data_exported"
+	AuditEventUserErased       AuditEventType = "user_erased"
+
+	// AuditEventStuckTxBumped и AuditEventStuckTxCancelled фиксируют ручные действия с зависшими транзакциями
+	AuditEventStuckTxBumped    AuditEventType = "stuck_tx_bumped"
+	AuditEventStuckTxCancelled AuditEventType = "stuck_tx_cancelled"
+
+	// AuditEventAccountClosureRequested и AuditEventAccountClosed фиксируют закрытие аккаунта пользователем
+	AuditEventAccountClosureRequested AuditEventType = "account_closure_requested"
+	AuditEventAccountClosed           AuditEventType = "account_closed"
+
+	// AuditEventDepositHeld и AuditEventDepositReleased фиксируют удержание крупного депозита и его освобождение
+	AuditEventDepositHeld     AuditEventType = "deposit_held"
+	AuditEventDepositReleased AuditEventType = "deposit_released"
+
+	// AuditEventDepositSpamIgnored фиксирует срабатывание правила против серии одинаковых микропереводов
+	AuditEventDepositSpamIgnored AuditEventType = "deposit_spam_ignored"
+
+	// AuditEventDepositProbingDetected фиксирует прощупывание депозитных адресов микропереводами одного отправителя
+	AuditEventDepositProbingDetected AuditEventType = "deposit_probing_detected"
+
+	// AuditEventWithdrawalTierChanged фиксирует назначение пользователю уровня лимитов вывода
+	AuditEventWithdrawalTierChanged AuditEventType = "withdrawal_tier_changed"
+
+	// AuditEventSettlementAccountChanged фиксирует смену адреса выплат мерчанта
+	AuditEventSettlementAccountChanged AuditEventType = "settlement_account_changed"
+
+	// AuditEventWalletsImported фиксирует регистрацию кошельков, перенесенных из предыдущей системы
+	AuditEventWalletsImported AuditEventType = "wallets_imported"
+
+	// AuditEventTokenApprovalIssued и AuditEventTokenApprovalRevoked фиксируют выдачу и отзыв ERC-20 approvals мастер-кошелька
+	AuditEventTokenApprovalIssued  AuditEventType = "token_approval_issued"
+	AuditEventTokenApprovalRevoked AuditEventType = "token_approval_revoked"
+
+	// AuditEventRPCEndpointDisabled, AuditEventRPCEndpointEnabled и AuditEventRPCFailoverForced фиксируют ручное
+	// управление RPC эндпоинтами
+	AuditEventRPCEndpointDisabled AuditEventType = "rpc_endpoint_disabled"
+	AuditEventRPCEndpointEnabled  AuditEventType = "rpc_endpoint_enabled"
+	AuditEventRPCFailoverForced   AuditEventType = "rpc_failover_forced"
+
+	// AuditEventMerchantDepositModeChanged фиксирует смену режима приема депозитов мерчанта
+	AuditEventMerchantDepositModeChanged AuditEventType = "merchant_deposit_mode_changed"
+
+	// AuditEventScannerPaused и AuditEventScannerResumed фиксируют ручную паузу и возобновление сканера сети
+	AuditEventScannerPaused  AuditEventType = "scanner_paused"
+	AuditEventScannerResumed AuditEventType = "scanner_resumed"
+
+	// AuditEventMerchantSandboxChanged фиксирует перевод мерчанта в песочницу и обратно
+	AuditEventMerchantSandboxChanged AuditEventType = "merchant_sandbox_changed"
+
+	// AuditEventDestinationFlagged фиксирует адрес получателя вывода, отправленный на разбор или заблокированный AML проверкой
+	AuditEventDestinationFlagged AuditEventType = "destination_flagged"
+
+	// Ротация мастер-сида: запуск, активация версии, свипы старых кошельков и завершение
+	AuditEventKeyRotationStarted      AuditEventType = "key_rotation_started"
+	AuditEventKeyRotationActivated    AuditEventType = "key_rotation_activated"
+	AuditEventKeyRotationSweepsQueued AuditEventType = "key_rotation_sweeps_queued"
+	AuditEventKeyRotationCompleted    AuditEventType = "key_rotation_completed"
+
+	// Внутренние заметки и теги сотрудников к транзакциям и ордерам
+	AuditEventStaffNoteAdded  AuditEventType = "staff_note_added"
+	AuditEventStaffTagAdded   AuditEventType = "staff_tag_added"
+	AuditEventStaffTagRemoved AuditEventType = "staff_tag_removed"
+
+	// Действия операторов по ранбукам: выполненные и отклоненные по правам
+	AuditEventRunbookExecuted AuditEventType = "runbook_executed"
+	AuditEventRunbookDenied   AuditEventType = "runbook_denied"
+)
+
+// AuditEvent represents a single immutable entry of the audit log
+type AuditEvent struct {
+	ID        int64          `json:"id"`
+	EventType AuditEventType `json:"event_type"`
+	Actor     string         `json:"actor"`
+	Subject   string         `json:"subject"`
+	Details   map[string]any `json:"details,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package entities
+
+// UserBalance — баланс пользователя в одном активе, рассчитанный по депозитам и выводам его кошельков
+type UserBalance struct {
+	Asset string `json:"asset"`
+	// Подтвержденные депозиты без удержаний за вычетом выводов, в единицах актива
+	Available string `json:"available"`
+	// Депозиты, ожидающие подтверждений
+	Pending string `json:"pending"`
+	// Депозиты, удержанные AML проверкой или комплаенсом, и суммы выводов, зарезервированные до отправки
+	Held string `json:"held"`
+
+	// Сеть кошельков: по ней суммы в минимальных единицах переводятся в единицы актива
+	Chain   Chain  `json:"-"`
+	Network string `json:"-"`
+}
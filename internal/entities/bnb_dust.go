@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// BNBDustWallet — остаток BNB на депозитном кошельке. Суммы в BNB.
+type BNBDustWallet struct {
+	WalletID int    `json:"wallet_id"`
+	UserID   int64  `json:"user_id"`
+	Address  string `json:"address"`
+	Balance  string `json:"balance"`
+	// Комиссия перевода всего остатка на газовый кошелек по текущей цене газа
+	Fee string `json:"fee"`
+	// Остаток не ниже порога и больше комиссии, кошелек консолидируется следующим проходом
+	Consolidatable bool `json:"consolidatable"`
+}
+
+// BNBDustRun — итог прохода консолидации
+type BNBDustRun struct {
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	Consolidated int       `json:"consolidated"`
+	Failed       int       `json:"failed"`
+	Amount       string    `json:"amount"` // Отправлено на газовый кошелек за вычетом комиссий, BNB
+}
+
+// BNBDustReport — BNB, оставшийся на депозитных кошельках после свипов
+type BNBDustReport struct {
+	GasWallet      string          `json:"gas_wallet,omitempty"`
+	MinAmount      string          `json:"min_amount"`
+	GasPrice       string          `json:"gas_price"` // Wei
+	Total          string          `json:"total"`
+	Consolidatable string          `json:"consolidatable"` // Сумма остатков, которые стоит вернуть, за вычетом комиссий
+	Wallets        []BNBDustWallet `json:"wallets"`
+	LastRun        *BNBDustRun     `json:"last_run,omitempty"`
+}
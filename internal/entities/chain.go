@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Chain — блокчейн, к которому относится кошелек
+type Chain string
+
+const (
+	ChainBSC      Chain = "bsc"
+	ChainEthereum Chain = "ethereum"
+	ChainTron     Chain = "tron"
+	ChainSolana   Chain = "solana"
+	ChainBitcoin  Chain = "bitcoin"
+	ChainTON      Chain = "ton"
+)
+
+// Окружения сети. Для Tron и Solana допустимы и собственные имена (nile, shasta, devnet)
+const (
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	// NetworkSimulated — локальная симулированная сеть BSC для CI
+	NetworkSimulated = "simulated"
+)
+
+// AddressFormat — формат записи адреса, по нему адрес валидируется и сравнивается
+type AddressFormat string
+
+const (
+	AddressFormatEVMHex        AddressFormat = "evm_hex"        // 0x + 40 hex, регистр не важен (EIP-55 checksum)
+	AddressFormatTronBase58    AddressFormat = "tron_base58"    // T + base58check
+	AddressFormatSolanaBase58  AddressFormat = "solana_base58"  // base58 публичного ключа ed25519
+	AddressFormatBitcoinBech32 AddressFormat = "bitcoin_bech32" // bc1/tb1 (segwit, taproot)
+	AddressFormatBitcoinBase58 AddressFormat = "bitcoin_base58" // P2PKH/P2SH
+	AddressFormatTONRaw        AddressFormat = "ton_raw"        // workchain:hex, user-friendly форма приводится к ней
+)
+
+var addressPatterns = map[AddressFormat]*regexp.Regexp{
+	AddressFormatEVMHex:        regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`),
+	AddressFormatTronBase58:    regexp.MustCompile(`^T[1-9A-HJ-NP-Za-km-z]{33}$`),
+	AddressFormatSolanaBase58:  regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`),
+	AddressFormatBitcoinBech32: regexp.MustCompile(`^(bc1|tb1|bcrt1)[02-9ac-hj-np-z]{8,87}$`),
+	AddressFormatBitcoinBase58: regexp.MustCompile(`^[123mn][1-9A-HJ-NP-Za-km-z]{25,34}$`),
+	AddressFormatTONRaw:        regexp.MustCompile(`^-?[0-9]{1,3}:[0-9a-f]{64}$`),
+}
+
+// DefaultAddressFormat returns the address format of the chain. Bitcoin defaults to bech32.
+func (c Chain) DefaultAddressFormat() (AddressFormat, error) {
+	switch c {
+	case ChainBSC, ChainEthereum:
+		return AddressFormatEVMHex, nil
+	case ChainTron:
+		return AddressFormatTronBase58, nil
+	case ChainSolana:
+		return AddressFormatSolanaBase58, nil
+	case ChainBitcoin:
+		return AddressFormatBitcoinBech32, nil
+	case ChainTON:
+		return AddressFormatTONRaw, nil
+	default:
+		return "", fmt.Errorf("unsupported chain %q", c)
+	}
+}
+
+// NativeCoin returns the code and decimals of the chain's native coin that pays the fees
+func (c Chain) NativeCoin() (string, int, error) {
+	switch c {
+	case ChainBSC:
+		return "BNB", 18, nil
+	case ChainEthereum:
+		return "ETH", 18, nil
+	case ChainTron:
+		return "TRX", 6, nil
+	case ChainSolana:
+		return "SOL", 9, nil
+	case ChainBitcoin:
+		return "BTC", 8, nil
+	case ChainTON:
+		return "TON", 9, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported chain %q", c)
+	}
+}
+
+// ValidateAddress checks the address against the format (syntax only, checksums are verified by chain clients)
+func ValidateAddress(format AddressFormat, address string) error {
+	pattern, ok := addressPatterns[format]
+	if !ok {
+		return fmt.Errorf("unsupported address format %q", format)
+	}
+	if !pattern.MatchString(address) {
+		return fmt.Errorf("invalid %s address %q", format, address)
+	}
+	return nil
+}
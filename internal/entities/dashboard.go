@@ -0,0 +1,98 @@
+package entities
+
+import "time"
+
+// DashboardOrderDay — число ордеров дня создания в статусе
+type DashboardOrderDay struct {
+	Day    time.Time   `json:"day"`
+	Status OrderStatus `json:"status"`
+	Orders int         `json:"orders"`
+}
+
+// DashboardDepositDay — депозиты дня обнаружения по сети, объем подтвержденных в минимальных единицах
+type DashboardDepositDay struct {
+	Day       time.Time `json:"day"`
+	Chain     Chain     `json:"chain"`
+	Network   string    `json:"network"`
+	Detected  int       `json:"detected"`
+	Confirmed int       `json:"confirmed"`
+	Credited  int       `json:"credited"`
+	Ignored   int       `json:"ignored"`
+	Volume    string    `json:"volume"`
+}
+
+// DashboardAMLBacklog — текущая очередь AML: непроверенные переводы и депозиты, ожидающие ручного решения
+type DashboardAMLBacklog struct {
+	QueuedChecks    int        `json:"queued_checks"`
+	OldestQueuedAt  *time.Time `json:"oldest_queued_at,omitempty"`
+	FlaggedDeposits int        `json:"flagged_deposits"`
+	HeldDeposits    int        `json:"held_deposits"`
+	FlaggedOrders   int        `json:"flagged_orders"`
+	RefreshedAt     time.Time  `json:"refreshed_at"`
+}
+
+// DashboardProjectionCursor — позиция проектора в изменениях исходной таблицы
+type DashboardProjectionCursor struct {
+	Projection  string    `json:"projection"`
+	Watermark   time.Time `json:"watermark"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// DashboardOverview — сводка дашборда за период из проекций
+type DashboardOverview struct {
+	From           time.Time             `json:"from"`
+	To             time.Time             `json:"to"`
+	OrdersByStatus map[OrderStatus]int   `json:"orders_by_status"`
+	Orders         []DashboardOrderDay   `json:"orders"`
+	Deposits       []DashboardDepositDay `json:"deposits"`
+	AMLBacklog     *DashboardAMLBacklog  `json:"aml_backlog,omitempty"`
+	// Позиции проекторов: данные актуальны на момент watermark
+	Cursors []DashboardProjectionCursor `json:"cursors"`
+}
+
+// DepositStatsGranularity — длина интервала статистики депозитов
+type DepositStatsGranularity string
+
+const (
+	DepositStatsHourly DepositStatsGranularity = "hour"
+	DepositStatsDaily  DepositStatsGranularity = "day"
+)
+
+// DepositStatsBucket — подтвержденные депозиты актива за час или день. Суммы в минимальных единицах актива,
+// вкладчики — уникальные пользователи за интервал
+type DepositStatsBucket struct {
+	Start          time.Time `json:"start"`
+	AssetID        int       `json:"asset_id"`
+	Asset          string    `json:"asset"`
+	Chain          Chain     `json:"chain"`
+	Network        string    `json:"network"`
+	Decimals       int       `json:"decimals"`
+	Deposits       int       `json:"deposits"`
+	Volume         string    `json:"volume"`
+	AverageDeposit string    `json:"average_deposit"`
+	Depositors     int       `json:"depositors"`
+}
+
+// DepositStatsAsset — итог актива за период. Пара и объем в фиатной валюте указываются, если для пары есть курс
+type DepositStatsAsset struct {
+	AssetID        int    `json:"asset_id"`
+	Asset          string `json:"asset"`
+	Chain          Chain  `json:"chain"`
+	Network        string `json:"network"`
+	Deposits       int    `json:"deposits"`
+	Volume         string `json:"volume"`
+	AverageDeposit string `json:"average_deposit"`
+	Pair           string `json:"pair,omitempty"`
+	FiatVolume     string `json:"fiat_volume,omitempty"`
+}
+
+// DepositStats — статистика депозитов за период из проекций дашборда
+type DepositStats struct {
+	From        time.Time               `json:"from"`
+	To          time.Time               `json:"to"`
+	Granularity DepositStatsGranularity `json:"granularity"`
+	Buckets     []DepositStatsBucket    `json:"buckets"`
+	Assets      []DepositStatsAsset     `json:"assets"`
+	// Позиции проекторов статистики: данные актуальны на момент watermark
+	Cursors []DashboardProjectionCursor `json:"cursors"`
+}
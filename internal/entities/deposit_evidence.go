@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DepositEvidence — архивная запись ончейн-доказательства зачисленного депозита.
+// Bundle — gzip JSON EvidenceBundle, SHA256 — хеш несжатого JSON
+type DepositEvidence struct {
+	TxHash      string     `json:"tx_hash"`
+	Chain       Chain      `json:"chain"`
+	BlockNumber *int64     `json:"block_number,omitempty"`
+	BlockHash   *string    `json:"block_hash,omitempty"`
+	Bundle      []byte     `json:"-"`
+	SHA256      *string    `json:"sha256,omitempty"`
+	Attempts    int        `json:"attempts"`
+	LastError   *string    `json:"last_error,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// EvidenceBundle — ответы ноды в исходном виде: транзакция с input, квитанция и заголовок блока
+type EvidenceBundle struct {
+	TxHash      string          `json:"tx_hash"`
+	Chain       Chain           `json:"chain"`
+	FetchedAt   time.Time       `json:"fetched_at"`
+	Transaction json.RawMessage `json:"transaction"`
+	Receipt     json.RawMessage `json:"receipt"`
+	Block       json.RawMessage `json:"block"`
+}
+
+// PendingEvidence — зачисленный депозит без архивного доказательства
+type PendingEvidence struct {
+	TxHash      string
+	BlockNumber int64
+}
+
+// DepositEvidenceView — архивная запись с распакованным и проверенным доказательством
+type DepositEvidenceView struct {
+	DepositEvidence
+	Bundle *EvidenceBundle `json:"bundle"`
+}
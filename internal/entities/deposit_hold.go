@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// DepositHold — депозит, удержанный до ручного освобождения: сумма значительно превышает обычные депозиты пользователя
+type DepositHold struct {
+	TxHash        string     `json:"tx_hash"`
+	UserID        int64      `json:"user_id"`
+	WalletAddress string     `json:"wallet_address"`
+	FromAddress   string     `json:"from_address"`
+	Amount        string     `json:"amount"`
+	Reason        string     `json:"reason"`
+	Confirmed     bool       `json:"confirmed"`
+	HeldAt        time.Time  `json:"held_at"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	ReleasedBy    *string    `json:"released_by,omitempty"`
+}
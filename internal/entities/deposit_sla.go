@@ -0,0 +1,43 @@
+package entities
+
+import "time"
+
+// DepositLatency — задержки депозитов сети за окно наблюдения: от обнаружения до подтверждения и до зачисления
+type DepositLatency struct {
+	Chain    Chain  `json:"chain"`
+	Network  string `json:"network"`
+	Deposits int    `json:"deposits"`
+	// Перцентили в секундах, nil — нет подтвержденных (зачисленных) депозитов за окно
+	ConfirmationP50 *float64 `json:"confirmation_p50_seconds,omitempty"`
+	ConfirmationP95 *float64 `json:"confirmation_p95_seconds,omitempty"`
+	CreditP50       *float64 `json:"credit_p50_seconds,omitempty"`
+	CreditP95       *float64 `json:"credit_p95_seconds,omitempty"`
+
+	// SLA сети в секундах, 0 — SLA не задан
+	ConfirmationSLA      float64 `json:"confirmation_sla_seconds"`
+	CreditSLA            float64 `json:"credit_sla_seconds"`
+	ConfirmationBreached bool    `json:"confirmation_breached"`
+	CreditBreached       bool    `json:"credit_breached"`
+}
+
+// OverdueDeposit — депозит, не зачисленный дольше жесткого срока
+type OverdueDeposit struct {
+	TxHash        string    `json:"tx_hash"`
+	Chain         Chain     `json:"chain"`
+	Network       string    `json:"network"`
+	WalletAddress string    `json:"wallet_address"`
+	Amount        string    `json:"amount"`
+	BlockNumber   int64     `json:"block_number"`
+	Confirmed     bool      `json:"confirmed"`
+	DetectedAt    time.Time `json:"detected_at"`
+	AgeSeconds    float64   `json:"age_seconds"`
+}
+
+// DepositSLAReport — состояние SLA подтверждения депозитов для панели администратора
+type DepositSLAReport struct {
+	GeneratedAt         time.Time        `json:"generated_at"`
+	WindowSeconds       float64          `json:"window_seconds"`
+	HardDeadlineSeconds float64          `json:"hard_deadline_seconds"`
+	Chains              []DepositLatency `json:"chains"`
+	Overdue             []OverdueDeposit `json:"overdue"`
+}
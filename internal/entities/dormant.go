@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// DormantRecoveryAction describes what was done with the balance of a dormant wallet
+type DormantRecoveryAction string
+
+const (
+	DormantRecoverySwept  DormantRecoveryAction = "swept"  // Остаток собран на адрес свипов
+	DormantRecoveryRefund DormantRecoveryAction = "refund" // Создан возврат отправителю последнего депозита
+	DormantRecoveryFailed DormantRecoveryAction = "failed" // Свип или возврат не удался, повтор в следующий запуск
+)
+
+// DormantRecovery — остаток на депозитном кошельке без ожидающего ордера (поздняя оплата, пыль)
+type DormantRecovery struct {
+	ID            int                   `json:"id"`
+	WalletID      int                   `json:"wallet_id"`
+	UserID        int64                 `json:"user_id"`
+	WalletAddress string                `json:"wallet_address"`
+	Amount        string                `json:"amount"` // В минимальных единицах актива
+	Action        DormantRecoveryAction `json:"action"`
+	TxHash        *string               `json:"tx_hash,omitempty"`
+	RefundID      *string               `json:"refund_id,omitempty"`
+	Error         *string               `json:"error,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// DormantRecoveryTotal — число и сумма восстановленных остатков по действию
+type DormantRecoveryTotal struct {
+	Action DormantRecoveryAction `json:"action"`
+	Count  int                   `json:"count"`
+	Amount string                `json:"amount"` // В единицах актива
+}
+
+// DormantRecoveryReport — отчет о восстановленных средствах за период
+type DormantRecoveryReport struct {
+	Since      time.Time              `json:"since"`
+	Asset      string                 `json:"asset"`
+	Totals     []DormantRecoveryTotal `json:"totals"`
+	Recoveries []DormantRecovery      `json:"recoveries"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// FeeQuote — стоимость перевода при заданном приоритете
+type FeeQuote struct {
+	Priority string `json:"priority"`
+	GasPrice string `json:"gas_price"` // wei
+	Fee      string `json:"fee"`       // wei
+	FeeBNB   string `json:"fee_bnb"`
+	FeeFiat  string `json:"fee_fiat,omitempty"`
+}
+
+// FeeEstimate is the expected cost of a transfer, valid until ValidUntil
+type FeeEstimate struct {
+	Asset string `json:"asset"`
+	// Комиссия платформы за вывод в единицах актива, взимается сверх комиссии сети
+	WithdrawalFee string     `json:"withdrawal_fee"`
+	GasLimit      uint64     `json:"gas_limit"`
+	FiatCurrency  string     `json:"fiat_currency,omitempty"`
+	Quotes        []FeeQuote `json:"quotes"`
+	ValidUntil    time.Time  `json:"valid_until"`
+}
@@ -0,0 +1,76 @@
+package entities
+
+import "time"
+
+// FiatPayoutMethod — способ фиатной выплаты продавцу
+type FiatPayoutMethod string
+
+const (
+	FiatPayoutBankTransfer FiatPayoutMethod = "bank_transfer" // Банковский перевод по номеру счета и SWIFT/BIC
+	FiatPayoutSEPA         FiatPayoutMethod = "sepa"          // SEPA перевод в евро по IBAN
+	FiatPayoutSBP          FiatPayoutMethod = "sbp"           // Система быстрых платежей по номеру телефона
+)
+
+// FiatPayoutStatus represents the state of a fiat payout
+type FiatPayoutStatus string
+
+const (
+	FiatPayoutPending    FiatPayoutStatus = "pending"    // Создана, еще не принята провайдером
+	FiatPayoutProcessing FiatPayoutStatus = "processing" // Принята провайдером, ожидает исполнения
+	FiatPayoutCompleted  FiatPayoutStatus = "completed"  // Средства зачислены получателю
+	FiatPayoutFailed     FiatPayoutStatus = "failed"     // Отклонена провайдером или оператором, проводка сторнирована
+	// Попытки отправки исчерпаны: провайдер мог принять выплату, поэтому проводка остается в пути,
+	// а ордер нельзя выплатить повторно, пока оператор не сверит выплату с провайдером
+	FiatPayoutReview FiatPayoutStatus = "review"
+)
+
+// FiatPayoutDestination — реквизиты получателя, набор полей зависит от способа выплаты
+type FiatPayoutDestination struct {
+	AccountHolder string `json:"account_holder"`
+	AccountNumber string `json:"account_number,omitempty"` // bank_transfer
+	BIC           string `json:"bic,omitempty"`            // bank_transfer, sepa
+	IBAN          string `json:"iban,omitempty"`           // sepa
+	Phone         string `json:"phone,omitempty"`          // sbp
+	BankID        string `json:"bank_id,omitempty"`        // sbp, идентификатор банка-участника СБП
+}
+
+// FiatPayout — выплата продавцу фиатного эквивалента завершенного ордера
+type FiatPayout struct {
+	ID      string           `json:"id"`
+	UserID  int64            `json:"user_id"`
+	OrderID int              `json:"order_id"`
+	Method  FiatPayoutMethod `json:"method"`
+	Status  FiatPayoutStatus `json:"status"`
+	// Сумма ордера в единицах актива и курс, по которому она пересчитана в фиат
+	Asset         string                `json:"asset"`
+	CryptoAmount  string                `json:"crypto_amount"`
+	Rate          string                `json:"rate"`
+	FiatAmount    string                `json:"fiat_amount"`
+	Currency      string                `json:"currency"`
+	Destination   FiatPayoutDestination `json:"destination"`
+	ProviderRef   *string               `json:"provider_ref,omitempty"`
+	Attempts      int                   `json:"attempts"`
+	FailureReason *string               `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	CompletedAt   *time.Time            `json:"completed_at,omitempty"`
+}
+
+// FiatPayoutUpdate — состояние выплаты на стороне провайдера
+type FiatPayoutUpdate struct {
+	ProviderRef   string
+	Status        FiatPayoutStatus
+	FailureReason string
+}
+
+// FiatLedgerPosting — проводка по фиатной выплате: сумма списывается со счета DebitAccount на счет CreditAccount
+type FiatLedgerPosting struct {
+	ID            int64     `json:"id"`
+	PayoutID      string    `json:"payout_id"`
+	DebitAccount  string    `json:"debit_account"`
+	CreditAccount string    `json:"credit_account"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	Memo          string    `json:"memo"`
+	CreatedAt     time.Time `json:"created_at"`
+}
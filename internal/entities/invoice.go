@@ -0,0 +1,56 @@
+package entities
+
+import "time"
+
+// InvoiceStatus represents the lifecycle state of an invoice
+type InvoiceStatus string
+
+const (
+	// InvoiceStatusOpen — счет создан, покупатель ещё не выбрал актив
+	InvoiceStatusOpen InvoiceStatus = "open"
+	// InvoiceStatusAwaitingPayment — под выбранный актив создан ордер и кошелек
+	InvoiceStatusAwaitingPayment InvoiceStatus = "awaiting_payment"
+	// InvoiceStatusPaid — ордер оплачен
+	InvoiceStatusPaid InvoiceStatus = "paid"
+	// InvoiceStatusExpired — срок оплаты истек
+	InvoiceStatusExpired InvoiceStatus = "expired"
+)
+
+// Invoice — счет мерчанта в фиатной валюте, оплачиваемый одним из принимаемых активов
+type Invoice struct {
+	ID             string        `json:"id"`
+	MerchantID     int64         `json:"merchant_id"`
+	FiatAmount     string        `json:"fiat_amount"`
+	FiatCurrency   string        `json:"fiat_currency"`
+	AcceptedAssets []string      `json:"accepted_assets"`
+	Description    string        `json:"description,omitempty"`
+	Status         InvoiceStatus `json:"status"`
+	StatusToken    string        `json:"status_token"`
+	SelectedAsset  *string       `json:"selected_asset,omitempty"`
+	CryptoAmount   *string       `json:"crypto_amount,omitempty"`
+	QuoteRate      *string       `json:"quote_rate,omitempty"`
+	OrderID        *int          `json:"order_id,omitempty"`
+	WalletAddress  *string       `json:"wallet_address,omitempty"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// InvoiceQuote — сумма счета в одном из принимаемых активов по текущему курсу
+type InvoiceQuote struct {
+	Asset  string `json:"asset"`
+	Amount string `json:"amount"`
+	Rate   string `json:"rate"` // fiat per one unit of the asset
+}
+
+// InvoiceStatusPage — публичное представление счета для страницы оплаты
+type InvoiceStatusPage struct {
+	ID           string          `json:"id"`
+	FiatAmount   string          `json:"fiat_amount"`
+	FiatCurrency string          `json:"fiat_currency"`
+	Description  string          `json:"description,omitempty"`
+	Status       InvoiceStatus   `json:"status"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	Quotes       []InvoiceQuote  `json:"quotes,omitempty"`
+	Payment      *PaymentRequest `json:"payment,omitempty"`
+}
@@ -0,0 +1,65 @@
+package entities
+
+import "time"
+
+// KeyRotationStatus — этап ротации мастер-сида
+type KeyRotationStatus string
+
+const (
+	KeyRotationVerified  KeyRotationStatus = "verified"  // Образец адресов нового сида сверен, версия еще не активна
+	KeyRotationActive    KeyRotationStatus = "active"    // Новые кошельки выводятся из нового сида
+	KeyRotationSweeping  KeyRotationStatus = "sweeping"  // Остатки кошельков старых сидов переводятся на кошельки нового
+	KeyRotationCompleted KeyRotationStatus = "completed" // Все кошельки старых сидов обработаны
+)
+
+// KeyRotationSweepStatus — результат свипа кошелька старого сида
+type KeyRotationSweepStatus string
+
+const (
+	KeyRotationSweepPending KeyRotationSweepStatus = "pending"
+	KeyRotationSweepSwept   KeyRotationSweepStatus = "swept"
+	KeyRotationSweepSkipped KeyRotationSweepStatus = "skipped" // Нулевой остаток
+	KeyRotationSweepFailed  KeyRotationSweepStatus = "failed"
+)
+
+// KeyRotation — ротация мастер-сида с прогрессом свипов со старых кошельков
+type KeyRotation struct {
+	ID              int               `json:"id"`
+	FromVersion     int               `json:"from_version"`
+	ToVersion       int               `json:"to_version"`
+	Status          KeyRotationStatus `json:"status"`
+	SampleAddresses []string          `json:"sample_addresses"`
+	StartedBy       string            `json:"started_by"`
+	ActivatedBy     *string           `json:"activated_by,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	ActivatedAt     *time.Time        `json:"activated_at,omitempty"`
+	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+	WalletsTotal    int               `json:"wallets_total"`
+	WalletsPending  int               `json:"wallets_pending"`
+	WalletsSwept    int               `json:"wallets_swept"`
+	WalletsSkipped  int               `json:"wallets_skipped"`
+	WalletsFailed   int               `json:"wallets_failed"`
+}
+
+// KeyRotationSweep — перевод остатка кошелька старого сида на кошелек того же пользователя из нового сида
+type KeyRotationSweep struct {
+	RotationID  int                    `json:"rotation_id"`
+	WalletID    int                    `json:"wallet_id"`
+	UserID      int64                  `json:"user_id"`
+	FromAddress string                 `json:"from_address"`
+	ToWalletID  *int                   `json:"to_wallet_id,omitempty"`
+	ToAddress   *string                `json:"to_address,omitempty"`
+	Amount      *string                `json:"amount,omitempty"` // В минимальных единицах актива
+	Status      KeyRotationSweepStatus `json:"status"`
+	TxHash      *string                `json:"tx_hash,omitempty"`
+	Error       *string                `json:"error,omitempty"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// KeyCeremony — новый сид и адреса, выведенные из него для сверки. Сид показывается один раз и нигде не хранится.
+type KeyCeremony struct {
+	Version         int      `json:"version"`
+	Mnemonic        string   `json:"mnemonic"`
+	SeedEntry       string   `json:"seed_entry"` // Строка для WALLET_SEEDS в формате "версия:мнемоника"
+	SampleAddresses []string `json:"sample_addresses"`
+}
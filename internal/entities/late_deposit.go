@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// LateDepositPolicy — что делать с депозитом, пришедшим на кошелек просроченного ордера
+type LateDepositPolicy string
+
+const (
+	LateDepositPolicyRefund LateDepositPolicy = "refund" // Вернуть депозит отправителю
+	LateDepositPolicyReopen LateDepositPolicy = "reopen" // Переоткрыть ордер и зачесть депозит в его оплату
+)
+
+// LateDepositAction describes what was done with a late deposit
+type LateDepositAction string
+
+const (
+	LateDepositRefund   LateDepositAction = "refund"   // Создан возврат отправителю
+	LateDepositReopened LateDepositAction = "reopened" // Ордер переоткрыт, депозит обрабатывается заново
+	LateDepositFailed   LateDepositAction = "failed"   // Возврат невозможен, например неизвестен отправитель
+)
+
+// LateDeposit — зачтенный депозит, не оплативший ни одного ордера, на кошельке ордера,
+// истекшего до зачисления депозита
+type LateDeposit struct {
+	TxHash        string    `db:"tx_hash"`
+	WalletAddress string    `db:"wallet_address"`
+	FromAddress   string    `db:"from_address"`
+	Amount        string    `db:"amount"` // В минимальных единицах актива
+	OrderID       int       `db:"order_id"`
+	UserID        int64     `db:"user_id"`
+	Asset         string    `db:"asset"`
+	Decimals      int       `db:"decimals"`
+	ExpiredAt     time.Time `db:"expired_at"`
+}
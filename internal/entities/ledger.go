@@ -0,0 +1,159 @@
+package entities
+
+import "time"
+
+// LedgerEntryKind describes why a transaction was sent by the platform
+type LedgerEntryKind string
+
+const (
+	LedgerKindWithdrawal  LedgerEntryKind = "withdrawal"  // Вывод средств пользователем
+	LedgerKindSweep       LedgerEntryKind = "sweep"       // Консолидация депозитных кошельков, включая approve и flush форвардеров
+	LedgerKindRefund      LedgerEntryKind = "refund"      // Возврат депозита отправителю
+	LedgerKindOperational LedgerEntryKind = "operational" // Прочие переводы, например пополнение BNB
+)
+
+// LedgerEntryStatus represents the on-chain outcome of the transaction
+type LedgerEntryStatus string
+
+const (
+	LedgerStatusPending   LedgerEntryStatus = "pending"   // Квитанция еще не получена
+	LedgerStatusConfirmed LedgerEntryStatus = "confirmed" // Транзакция исполнена
+	LedgerStatusFailed    LedgerEntryStatus = "failed"    // Транзакция откатилась, газ потрачен
+	LedgerStatusDropped   LedgerEntryStatus = "dropped"   // Квитанция так и не появилась
+)
+
+// LedgerEntry — исходящая транзакция платформы. Суммы в минимальных единицах:
+// amount и fee — в единицах актива, gas_price, effective_gas_price и gas_cost — в wei BNB.
+type LedgerEntry struct {
+	ID          string          `json:"id"`
+	AssetID     int             `json:"asset_id"`
+	Kind        LedgerEntryKind `json:"kind"`
+	Operation   string          `json:"operation"`
+	TxHash      string          `json:"tx_hash"`
+	FromAddress string          `json:"from_address"`
+	ToAddress   string          `json:"to_address"`
+	Amount      string          `json:"amount"`
+	Fee         string          `json:"fee"` // Комиссия платформы, удержанная с пользователя
+	GasPrice    string          `json:"gas_price"`
+	GasLimit    int64           `json:"gas_limit"`
+	GasUsed     *int64          `json:"gas_used,omitempty"`
+	GasCost     *string         `json:"gas_cost,omitempty"`
+	// Фактическая цена газа из квитанции, gas_price — предложенная при отправке
+	EffectiveGasPrice *string           `json:"effective_gas_price,omitempty"`
+	Status            LedgerEntryStatus `json:"status"`
+	CreatedAt         time.Time         `json:"created_at"`
+	SettledAt         *time.Time        `json:"settled_at,omitempty"`
+
+	// Газ в валюте учета по курсу нативной монеты на момент исполнения, пусто без курса
+	GasCurrency  *string `json:"gas_currency,omitempty"` // Нативная монета сети, которой оплачен газ
+	GasRate      *string `json:"gas_rate,omitempty"`     // Курс монеты к валюте учета
+	FiatCurrency *string `json:"fiat_currency,omitempty"`
+	GasCostFiat  *string `json:"gas_cost_fiat,omitempty"`
+}
+
+// LedgerGasConversion — газ транзакции, пересчитанный в валюту учета при расчете по квитанции
+type LedgerGasConversion struct {
+	GasCurrency  string
+	GasRate      string
+	FiatCurrency string
+	GasCostFiat  string
+}
+
+// LedgerSummary — агрегат журнала за период по активу и виду транзакций (минимальные единицы)
+type LedgerSummary struct {
+	PeriodStart  time.Time
+	AssetID      int
+	Kind         LedgerEntryKind
+	Transactions int
+	Volume       string
+	Fees         string
+	GasCost      string
+}
+
+// LedgerGasFiatSummary — газ за период по активу в валюте учета по курсам на момент исполнения
+type LedgerGasFiatSummary struct {
+	PeriodStart time.Time
+	AssetID     int
+	GasCostFiat string
+	Unconverted int // Исполненные транзакции без пересчета в валюту учета
+}
+
+// PnLPeriod — шаг группировки отчета
+type PnLPeriod string
+
+const (
+	PnLPeriodDay   PnLPeriod = "day"
+	PnLPeriodWeek  PnLPeriod = "week"
+	PnLPeriodMonth PnLPeriod = "month"
+)
+
+// PnLRow — доходы и расходы платформы по активу за период. Суммы актива в его единицах, газ в BNB.
+type PnLRow struct {
+	PeriodStart time.Time `json:"period_start"`
+	Asset       string    `json:"asset"`
+
+	Withdrawals      int    `json:"withdrawals"`
+	WithdrawalVolume string `json:"withdrawal_volume"`
+	FeesCollected    string `json:"fees_collected"`
+
+	Sweeps         int    `json:"sweeps"`
+	SweepGas       string `json:"sweep_gas"`
+	WithdrawalGas  string `json:"withdrawal_gas"`
+	RefundGas      string `json:"refund_gas"`
+	OperationalGas string `json:"operational_gas"`
+	GasSpent       string `json:"gas_spent"`
+
+	// Газ в валюте учета по курсам на момент исполнения транзакций
+	GasSpentFiat string `json:"gas_spent_fiat,omitempty"`
+	// Транзакции, газ которых не пересчитан в валюту учета: курс был недоступен при расчете
+	GasUnconverted int `json:"gas_unconverted,omitempty"`
+	// Газ в единицах актива: стоимость в валюте учета по курсу актива, а если часть газа не пересчитана —
+	// по текущему курсу BNB/актив. Пусто без курсов.
+	GasSpentInAsset string `json:"gas_spent_in_asset,omitempty"`
+	// Собранные комиссии за вычетом газа в единицах актива, пусто без курсов
+	NetMargin string `json:"net_margin,omitempty"`
+}
+
+// PnLReport — отчет P&L за интервал [From, To)
+type PnLReport struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Period       PnLPeriod `json:"period"`
+	RateCurrency string    `json:"rate_currency"` // Валюта учета: газ в ней и через нее в единицах актива
+	Rows         []PnLRow  `json:"rows"`
+}
+
+// FeeStats — комиссии исполненных транзакций за день по сети и виду транзакций (wei BNB)
+type FeeStats struct {
+	Day          time.Time
+	Chain        Chain
+	Network      string
+	Kind         LedgerEntryKind
+	Transactions int
+	GasPriceSum  string // Сумма фактических цен газа, для средней цены
+	GasCost      string
+	MaxGasCost   string
+}
+
+// FeeAggregate — комиссии группы транзакций: суммы в BNB, цена газа в gwei
+type FeeAggregate struct {
+	Day             *time.Time      `json:"day,omitempty"`
+	Chain           Chain           `json:"chain,omitempty"`
+	Network         string          `json:"network,omitempty"`
+	Kind            LedgerEntryKind `json:"kind,omitempty"`
+	Transactions    int             `json:"transactions"`
+	TotalFee        string          `json:"total_fee"`
+	AverageFee      string          `json:"average_fee"`
+	MaxFee          string          `json:"max_fee"`
+	AverageGasPrice string          `json:"average_gas_price_gwei"`
+}
+
+// FeeAnalytics — аналитика комиссий за интервал [From, To): средняя комиссия по видам транзакций,
+// дневной тренд и комиссии по сетям. Учитываются исполненные и откатившиеся транзакции, газ оплачен в обоих случаях.
+type FeeAnalytics struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	ByKind  []FeeAggregate `json:"by_kind"`
+	Daily   []FeeAggregate `json:"daily"`
+	ByChain []FeeAggregate `json:"by_chain"`
+}
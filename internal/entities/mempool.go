@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// MempoolDeposit — перевод на наш кошелек, замеченный в мемпуле до включения в блок.
+// Используется только для отображения пользователю: зачисление выполняется после подтверждений.
+type MempoolDeposit struct {
+	TxHash      string    `json:"tx_hash"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Amount      string    `json:"amount"`
+	UserID      int64     `json:"user_id"`
+	SeenAt      time.Time `json:"seen_at"`
+	Provisional bool      `json:"provisional"`
+}
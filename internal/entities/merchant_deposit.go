@@ -0,0 +1,29 @@
+package entities
+
+import "time"
+
+// DepositMode — режим приема депозитов мерчанта
+type DepositMode string
+
+const (
+	DepositModeDedicated DepositMode = "dedicated" // Отдельный депозитный кошелек на ордер или клиента
+	DepositModeOmnibus   DepositMode = "omnibus"   // Общий горячий кошелек платформы
+)
+
+// DepositAttribution — как депозит на омнибус кошелек сопоставляется с ордером
+type DepositAttribution string
+
+const (
+	DepositAttributionAmount DepositAttribution = "amount" // По уникальной сумме к оплате
+	DepositAttributionMemo   DepositAttribution = "memo"   // По мемо в переводе
+)
+
+// MerchantDepositSettings — режим приема депозитов мерчанта
+type MerchantDepositSettings struct {
+	MerchantID  int64              `json:"merchant_id"`
+	Mode        DepositMode        `json:"mode"`
+	Attribution DepositAttribution `json:"attribution"`
+	UpdatedBy   *string            `json:"updated_by,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
@@ -1,16 +1,107 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// MaxDepositMemoLength — наибольшая длина мемо депозита, как у колонок orders.memo и transactions.memo
+const MaxDepositMemoLength = 64
+
+// OrderStatus represents the state of an order, stored as the order_status_type enum
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"   // Ожидает оплаты
+	OrderStatusCompleted OrderStatus = "completed" // Оплачен переводом на кошелек ордера
+	OrderStatusExpired   OrderStatus = "expired"   // Не оплачен вовремя, поздний депозит возвращается или переоткрывает ордер
+	OrderStatusCancelled OrderStatus = "cancelled" // Отменен пользователем до оплаты
+)
+
+// OrderCancelReason — код причины, которую пользователь указывает при отмене ордера
+type OrderCancelReason string
+
+const (
+	OrderCancelReasonChangedMind  OrderCancelReason = "changed_mind"  // Покупка больше не нужна
+	OrderCancelReasonWrongAmount  OrderCancelReason = "wrong_amount"  // Ордер создан с неверной суммой
+	OrderCancelReasonDuplicate    OrderCancelReason = "duplicate"     // Повторно созданный ордер
+	OrderCancelReasonPaymentIssue OrderCancelReason = "payment_issue" // Не удается оплатить: нет средств, сеть, кошелек
+	OrderCancelReasonOther        OrderCancelReason = "other"
+)
+
+// OrderCancelReasons перечисляет допустимые коды причин отмены
+var OrderCancelReasons = []OrderCancelReason{
+	OrderCancelReasonChangedMind,
+	OrderCancelReasonWrongAmount,
+	OrderCancelReasonDuplicate,
+	OrderCancelReasonPaymentIssue,
+	OrderCancelReasonOther,
+}
 
 // Order represents a user order in our system
 type Order struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	WalletID  int       `json:"wallet_id"`
-	Amount    string    `json:"amount"`
-	Status    string    `json:"status"`
-	AMLStatus AMLStatus `json:"aml_status"`
-	AMLNotes  *string   `json:"aml_notes,omitempty"`
+	ID       int `json:"id"`
+	UserID   int `json:"user_id"`
+	WalletID int `json:"wallet_id"`
+	// Актив ордера из реестра активов
+	AssetID *int `json:"asset_id,omitempty" db:"asset_id"`
+	// Сумма в единицах актива (NUMERIC), в JSON отдается строкой
+	Amount decimal.Decimal `json:"amount"`
+	// Уникальная сумма к оплате, если ордер использует общий кошелек (fingerprinting)
+	ExpectedAmount *decimal.Decimal `json:"expected_amount,omitempty" db:"expected_amount"`
+	// Мемо (тег) депозита, которое плательщик указывает в переводе на общий адрес
+	Memo      *string     `json:"memo,omitempty" db:"memo"`
+	Status    OrderStatus `json:"status"`
+	AMLStatus AMLStatus   `json:"aml_status"`
+	AMLNotes  *string     `json:"aml_notes,omitempty"`
+	// Ордер песочницы: создан на кошельке тестовой сети, не выплачивается и не смешивается с боевыми данными
+	IsTest    bool      `json:"is_test" db:"is_test"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// OrderCancellation — результат отмены ордера пользователем
+type OrderCancellation struct {
+	OrderID     int               `json:"order_id"`
+	UserID      int               `json:"user_id"`
+	WalletID    int               `json:"wallet_id"`
+	Reason      OrderCancelReason `json:"reason"`
+	CancelledAt time.Time         `json:"cancelled_at"`
+	// Кошелек ордера из пула не получал переводов и вернулся в пул
+	WalletReleased bool `json:"wallet_released"`
+}
+
+// OrderPayment описывает, как оплатить созданный ордер
+type OrderPayment struct {
+	OrderID int `json:"order_id"`
+	// Точная сумма перевода, по ней сопоставляется оплата
+	Amount string `json:"pay_amount"`
+	// Мемо, которое нужно указать в переводе, пусто если мемо не используются
+	Memo string `json:"memo,omitempty"`
+}
+
+// Статусы позиций пакетного создания ордеров
+const (
+	OrderBatchItemCreated = "created"
+	OrderBatchItemFailed  = "failed"
+)
+
+// OrderBatchItem — позиция пакетного создания ордеров
+type OrderBatchItem struct {
+	// Идентификатор позиции у мерчанта, например номер импортируемого счета, возвращается в результате
+	Reference string `json:"reference,omitempty"`
+	Amount    string `json:"amount"`
+}
+
+// OrderBatchResult — результат создания позиции пакета, Index — номер позиции в запросе
+type OrderBatchResult struct {
+	Index     int    `json:"index"`
+	Reference string `json:"reference,omitempty"`
+	Status    string `json:"status"`
+	WalletID  int    `json:"wallet_id,omitempty"`
+	Wallet    string `json:"wallet,omitempty"`
+	PayAmount string `json:"pay_amount,omitempty"`
+	Memo      string `json:"memo,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// OrderCompletedEventType — тип события оплаты ордера в webhook и WebSocket
+const OrderCompletedEventType = "order.completed"
+
+// OrderCompletedEvent — событие оплаты ордера для мерчанта: отправляется на webhook и подписчикам WebSocket
+type OrderCompletedEvent struct {
+	Event      string          `json:"event"`
+	OrderID    int             `json:"order_id"`
+	MerchantID int64           `json:"merchant_id"`
+	Amount     decimal.Decimal `json:"amount"`
+	// Пусто для ордеров, созданных до реестра активов
+	Asset       string    `json:"asset"`
+	TxHash      *string   `json:"tx_hash,omitempty"`
+	IsTest      bool      `json:"is_test"`
+	CompletedAt time.Time `json:"completed_at"`
+
+	// Число неудачных попыток обработки
+	Attempts int `json:"-"`
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// OrderScheduleStatus represents the state of a recurring order schedule
+type OrderScheduleStatus string
+
+const (
+	OrderScheduleActive    OrderScheduleStatus = "active"    // Ордера создаются по расписанию
+	OrderSchedulePaused    OrderScheduleStatus = "paused"    // Приостановлено пользователем, можно возобновить
+	OrderScheduleCancelled OrderScheduleStatus = "cancelled" // Отменено пользователем
+	OrderScheduleFinished  OrderScheduleStatus = "finished"  // Создано max_occurrences ордеров или активаций больше нет
+)
+
+// OrderSchedule — шаблон регулярного ордера и cron-расписание (UTC), по которому создаются ордера
+type OrderSchedule struct {
+	ID          int                 `json:"id"`
+	UserID      int64               `json:"user_id"`
+	Amount      decimal.Decimal     `json:"amount"`
+	Description string              `json:"description,omitempty"`
+	Cron        string              `json:"cron"`
+	Status      OrderScheduleStatus `json:"status"`
+	// Время создания следующего ордера, пусто для неактивных расписаний
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	Occurrences    int        `json:"occurrences"`
+	MaxOccurrences *int       `json:"max_occurrences,omitempty"`
+	// Ошибка последней неудачной попытки создать ордер, сбрасывается после успешной
+	LastError *string   `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// OrderTemplate — многоразовый шаблон ордера мерчанта, по которому ордер создается одним запросом
+type OrderTemplate struct {
+	ID      int    `json:"id"`
+	UserID  int64  `json:"user_id"`
+	Name    string `json:"name"`
+	AssetID int    `json:"asset_id"`
+	// Код актива из реестра, например USDT
+	Asset string `json:"asset"`
+	// Пусто, если сумма указывается при создании каждого ордера
+	Amount      *decimal.Decimal `json:"amount,omitempty"`
+	Description string           `json:"description,omitempty"`
+	// Адрес, на который отправляются уведомления об ордерах шаблона
+	CallbackURL *string    `json:"callback_url,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TemplateOrder — ордер, созданный по шаблону, с реквизитами оплаты для страницы оплаты
+type TemplateOrder struct {
+	OrderID     int             `json:"order_id"`
+	TemplateID  int             `json:"template_id"`
+	WalletID    int             `json:"wallet_id"`
+	Wallet      string          `json:"wallet"`
+	Asset       string          `json:"asset"`
+	PayAmount   string          `json:"pay_amount"`
+	Memo        string          `json:"memo,omitempty"`
+	Description string          `json:"description,omitempty"`
+	CallbackURL *string         `json:"callback_url,omitempty"`
+	Payment     *PaymentRequest `json:"payment,omitempty"`
+}
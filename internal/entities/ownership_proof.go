@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// OwnershipProofStandard — стандарт подписи доказательства владения (personal_sign)
+const OwnershipProofStandard = "EIP-191"
+
+// OwnershipProof — подписанное ключом депозитного кошелька сообщение с вызовом пользователя.
+// Адрес, восстановленный из подписи сообщения по EIP-191, совпадает с адресом кошелька.
+type OwnershipProof struct {
+	Address   string    `json:"address"`
+	Chain     Chain     `json:"chain"`
+	Network   string    `json:"network"`
+	Message   string    `json:"message"`
+	Signature string    `json:"signature"` // 0x + r || s || v, v = 27/28
+	Standard  string    `json:"standard"`
+	IssuedAt  time.Time `json:"issued_at"`
+}
+
+// OwnershipVerification — результат проверки доказательства владения
+type OwnershipVerification struct {
+	Valid  bool   `json:"valid"`
+	Signer string `json:"signer,omitempty"`
+}
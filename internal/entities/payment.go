@@ -0,0 +1,14 @@
+package entities
+
+// PaymentRequest — реквизиты для оплаты ордера: кошелек, токен, сумма и EIP-681 ссылка
+type PaymentRequest struct {
+	OrderID       int    `json:"order_id"`
+	WalletAddress string `json:"wallet_address"`
+	TokenAddress  string `json:"token_address"`
+	ChainID       int64  `json:"chain_id"`
+	Amount        string `json:"amount"`
+	AmountWei     string `json:"amount_wei"`
+	URI           string `json:"uri"`
+	// Мемо, которое нужно указать в переводе, пусто если ордер сопоставляется без мемо
+	Memo string `json:"memo,omitempty"`
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// PriceSnapshot — курс актива сети к фиатной валюте на момент обновления кеша курсов
+type PriceSnapshot struct {
+	Chain      Chain           `json:"chain"`
+	Asset      string          `json:"asset"`
+	Fiat       string          `json:"fiat"`
+	Rate       decimal.Decimal `json:"rate"` // Стоимость одной единицы актива в фиатной валюте
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// FiatValue — приблизительная стоимость суммы в фиатной валюте. Пустое значение — курс неизвестен
+type FiatValue struct {
+	Currency string `json:"currency"`
+	// По текущему курсу из кеша
+	Current string `json:"current,omitempty"`
+	// По курсу на момент транзакции или создания ордера
+	AtTime string `json:"at_time,omitempty"`
+	// Время обновления текущего курса
+	RateAsOf *time.Time `json:"rate_as_of,omitempty"`
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// UserDataExport — все данные, которые платформа хранит о пользователе (GDPR, статья 15)
+type UserDataExport struct {
+	UserID      int64     `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Orders       []Order                `json:"orders"`
+	Wallets      []WalletDetailExtended `json:"wallets"`
+	Transactions []Transaction          `json:"transactions"`
+	Refunds      []Refund               `json:"refunds"`
+	Invoices     []Invoice              `json:"invoices"`
+	Sessions     []Session              `json:"sessions"`
+	TwoFactor    *TwoFactorSecret       `json:"two_factor,omitempty"`
+	AuditEvents  []AuditEvent           `json:"audit_events"`
+
+	// Заметки AML проверок раскрываются только если это разрешено (запрет на уведомление о подозрениях)
+	AMLNotesIncluded bool `json:"aml_notes_included"`
+	// Erasure заполнен, если персональные данные пользователя уже удалены
+	Erasure *UserErasure `json:"erasure,omitempty"`
+}
+
+// UserErasure — результат обезличивания персональных данных пользователя
+type UserErasure struct {
+	UserID              int64     `json:"user_id"`
+	RequestedBy         string    `json:"requested_by"`
+	Reason              string    `json:"reason"`
+	SessionsAnonymized  int       `json:"sessions_anonymized"`
+	AuditEventsRedacted int       `json:"audit_events_redacted"`
+	TwoFactorRemoved    bool      `json:"two_factor_removed"`
+	ErasedAt            time.Time `json:"erased_at"`
+}
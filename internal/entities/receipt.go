@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// OrderReceiptStatus represents the delivery state of a receipt
+type OrderReceiptStatus string
+
+const (
+	OrderReceiptPending OrderReceiptStatus = "pending" // Ожидает отправки
+	OrderReceiptSent    OrderReceiptStatus = "sent"    // Отправлена
+	OrderReceiptFailed  OrderReceiptStatus = "failed"  // Не отправлена после всех попыток, можно отправить повторно
+)
+
+// OrderReceipt — квитанция об оплате ордера и состояние ее отправки
+type OrderReceipt struct {
+	OrderID   int                `json:"order_id"`
+	Status    OrderReceiptStatus `json:"status"`
+	Attempts  int                `json:"attempts"`
+	LastError *string            `json:"last_error,omitempty"`
+	SentAt    *time.Time         `json:"sent_at,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// ReceiptDetails — данные оплаченного ордера для квитанции
+type ReceiptDetails struct {
+	OrderID  int
+	UserID   int64
+	Attempts int
+	Amount   decimal.Decimal
+	// Сумма перевода, если ордер оплачивался уникальной суммой
+	PaidAmount  *decimal.Decimal
+	Asset       string
+	Chain       Chain
+	Network     string
+	TxHash      *string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// ReceiptBranding — оформление квитанций мерчанта. Шаблоны записываются в синтаксисе text/template,
+// пустой шаблон заменяется шаблоном по умолчанию
+type ReceiptBranding struct {
+	MerchantID      int64     `json:"merchant_id"`
+	BrandName       string    `json:"brand_name"`
+	SubjectTemplate string    `json:"subject_template"`
+	BodyTemplate    string    `json:"body_template"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
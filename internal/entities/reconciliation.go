@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+// DepositSummary — подтвержденные депозиты за период по активу сети (минимальные единицы).
+// AssetID равен 0, если для сети кошелька нет актива в реестре.
+type DepositSummary struct {
+	AssetID  int
+	Chain    Chain
+	Network  string
+	Deposits int
+	Volume   string
+}
+
+// ReconciliationAsset — итоги дня по активу: суммы актива в его единицах, газ в BNB
+type ReconciliationAsset struct {
+	Asset   string `json:"asset"`
+	Chain   Chain  `json:"chain"`
+	Network string `json:"network"`
+
+	Deposits      int    `json:"deposits"`
+	DepositVolume string `json:"deposit_volume"`
+
+	Withdrawals      int    `json:"withdrawals"`
+	WithdrawalVolume string `json:"withdrawal_volume"`
+	Refunds          int    `json:"refunds"`
+	RefundVolume     string `json:"refund_volume"`
+	FeesCollected    string `json:"fees_collected"`
+	GasSpent         string `json:"gas_spent"`
+}
+
+// ReconciliationSummary — сводка сверки за день [From, To) по UTC для учетных систем
+type ReconciliationSummary struct {
+	Day         string                `json:"day"` // YYYY-MM-DD
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Assets      []ReconciliationAsset `json:"assets"`
+}
+
+// ReconciliationReport — подписанная сводка дня и состояние ее доставки
+type ReconciliationReport struct {
+	Day         time.Time  `json:"day"`
+	Payload     string     `json:"-"` // JSON сводки в том виде, в котором он подписан и отправлен
+	Signature   string     `json:"signature"`
+	Attempts    int        `json:"attempts"`
+	LastError   *string    `json:"last_error,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"math/big"
+	"time"
+)
+
+// RefundReason describes why deposited funds are returned to the sender
+type RefundReason string
+
+const (
+	RefundReasonAMLRejected RefundReason = "aml_rejected" // Депозит отклонен AML проверкой
+	RefundReasonOverpayment RefundReason = "overpayment"  // Сумма перевода больше суммы ордеров
+	RefundReasonManual      RefundReason = "manual"       // Возврат создан администратором
+	RefundReasonDormant     RefundReason = "dormant"      // Остаток на неактивном кошельке без ожидающего ордера
+	RefundReasonLateDeposit RefundReason = "late_deposit" // Депозит пришел после истечения ордера
+)
+
+// RefundStatus represents the state of a refund
+type RefundStatus string
+
+const (
+	RefundStatusPending    RefundStatus = "pending"    // Ожидает исполнения
+	RefundStatusProcessing RefundStatus = "processing" // Транзакция возврата отправляется
+	RefundStatusSent       RefundStatus = "sent"       // Транзакция возврата отправлена, ждем квитанцию
+	RefundStatusUnknown    RefundStatus = "unknown"    // Узел не подтвердил прием транзакции, исход определяется по нонсу
+	RefundStatusCompleted  RefundStatus = "completed"  // Транзакция возврата подтверждена в блоке
+	RefundStatusFailed     RefundStatus = "failed"     // Транзакция не отправлена или откатилась, можно повторить
+)
+
+// Refund — возврат средств с депозитного кошелька на адрес отправителя
+type Refund struct {
+	ID            string       `json:"id"`
+	UserID        int64        `json:"user_id"`
+	DepositTxHash string       `json:"deposit_tx_hash"`
+	WalletAddress string       `json:"wallet_address"`
+	ToAddress     string       `json:"to_address"`
+	Amount        string       `json:"amount"` // wei
+	Reason        RefundReason `json:"reason"`
+	Status        RefundStatus `json:"status"`
+	RefundTxHash  *string      `json:"refund_tx_hash,omitempty"`
+	Error         *string      `json:"error,omitempty"`
+	InitiatedBy   string       `json:"initiated_by"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Nonce         *int64       `json:"nonce,omitempty"`
+}
+
+// Overpayment — часть зачисленного депозита сверх суммы ордеров, возвращается отправителю
+type Overpayment struct {
+	DepositTxHash string
+	Amount        *big.Int
+}
@@ -0,0 +1,10 @@
+package entities
+
+import "github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcmanager"
+
+// RPCEndpoints — состояние RPC эндпоинтов для операторов
+type RPCEndpoints struct {
+	Endpoints []rpcmanager.EndpointStats `json:"endpoints"`
+	// WebSocket эндпоинт, через который сейчас подписан сканер блоков; пусто, если сканер не подключен
+	ScannerEndpoint string `json:"scanner_endpoint"`
+}
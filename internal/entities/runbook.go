@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// RunbookAction — параметризованное действие оператора при разборе инцидента вместо ручного SQL
+type RunbookAction string
+
+const (
+	RunbookRetryDeadLetter RunbookAction = "retry_dead_letter" // Вернуть в очередь сброшенную обработку ордера или квитанцию
+	RunbookRerunAML        RunbookAction = "rerun_aml"         // Повторить AML проверку транзакции мимо сохраненного результата
+	RunbookRecreditDeposit RunbookAction = "recredit_deposit"  // Зачислить подтвержденный, но не обработанный депозит
+	RunbookReplayWebhook   RunbookAction = "replay_webhook"    // Повторно отправить webhook завершения ордера
+)
+
+// RunbookActions — все действия, для разбора прав операторов
+var RunbookActions = []RunbookAction{RunbookRetryDeadLetter, RunbookRerunAML, RunbookRecreditDeposit, RunbookReplayWebhook}
+
+// DeadLetterKind — очередь, из которой возвращается сброшенный элемент
+type DeadLetterKind string
+
+const (
+	DeadLetterOrderCompletion DeadLetterKind = "order_completion" // ID — номер ордера
+	DeadLetterOrderReceipt    DeadLetterKind = "order_receipt"    // ID — номер ордера
+)
+
+// RunbookRequest — параметры действия. Каждое действие использует только свои поля.
+type RunbookRequest struct {
+	Kind    DeadLetterKind `json:"kind,omitempty"`
+	OrderID int            `json:"order_id,omitempty"`
+	TxHash  string         `json:"tx_hash,omitempty"`
+	Reason  string         `json:"reason"`
+}
+
+// RunbookResult — итог выполненного действия
+type RunbookResult struct {
+	Action     RunbookAction   `json:"action"`
+	Subject    string          `json:"subject"`
+	Actor      string          `json:"actor"`
+	AMLResult  *AMLCheckResult `json:"aml_result,omitempty"`
+	ExecutedAt time.Time       `json:"executed_at"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// SandboxMerchant — мерчант в режиме песочницы: ордера только в тестовой сети
+type SandboxMerchant struct {
+	MerchantID int64     `json:"merchant_id"`
+	EnabledBy  *string   `json:"enabled_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SandboxStatus — режим мерчанта и окружение, которое отвечает на запрос
+type SandboxStatus struct {
+	MerchantID int64  `json:"merchant_id"`
+	Sandbox    bool   `json:"sandbox"`
+	Network    string `json:"network"`
+	// Окружение принимает запросы мерчанта: песочница обслуживается только окружением тестовой сети
+	Allowed bool `json:"allowed"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// ScannerState — состояние сканера сети: контрольная точка и ручная пауза
+type ScannerState struct {
+	Chain Chain `json:"chain"`
+	// Последний обработанный блок, nil — сканер не ведет контрольную точку по блокам
+	LastBlock   *int64     `json:"last_block,omitempty"`
+	Paused      bool       `json:"paused"`
+	PauseReason *string    `json:"pause_reason,omitempty"`
+	PausedAt    *time.Time `json:"paused_at,omitempty"`
+	UpdatedBy   *string    `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,54 @@
+package entities
+
+import "time"
+
+// Session represents a single login of a user from a device
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int64      `json:"user_id"`
+	TokenHash  string     `json:"-"`
+	DeviceID   string     `json:"device_id"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Country    string     `json:"country,omitempty"`
+	City       string     `json:"city,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive reports whether the session can still be used
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// LoginChallenge — одноразовый вызов входа. Сервис идентификации, проверивший учетные данные пользователя,
+// подписывает его общим секретом, и только по этой подписи создается сессия.
+type LoginChallenge struct {
+	ID        string    `json:"challenge_id"`
+	UserID    int64     `json:"user_id"`
+	Challenge string    `json:"challenge"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionCredential подтверждает вход: подпись вызова и код второго фактора, если он подключен
+type SessionCredential struct {
+	ChallengeID   string
+	Signature     string
+	TwoFactorCode string
+}
+
+// SessionClient описывает клиента, с которого выполняется вход
+type SessionClient struct {
+	IPAddress string
+	UserAgent string
+	Country   string
+	City      string
+}
+
+// GeoLocation is the approximate location of an IP address
+type GeoLocation struct {
+	Country string
+	City    string
+}
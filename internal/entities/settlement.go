@@ -0,0 +1,73 @@
+package entities
+
+import "time"
+
+// SettlementStatus represents the state of a merchant settlement payout
+type SettlementStatus string
+
+const (
+	SettlementPending  SettlementStatus = "pending"  // Ордера включены в пакет, выплата отправляется
+	SettlementPaid     SettlementStatus = "paid"     // Транзакция выплаты отправлена
+	SettlementProposed SettlementStatus = "proposed" // Выплата оформлена предложением Safe и ждет подписей владельцев
+	SettlementFailed   SettlementStatus = "failed"   // Выплата не состоялась, ордера войдут в следующий пакет
+	SettlementUnknown  SettlementStatus = "unknown"  // Узел не подтвердил прием транзакции выплаты, исход сверяет оператор
+)
+
+// SettlementAccount — адрес выплат мерчанта. FeeBPS переопределяет комиссию платформы по умолчанию.
+type SettlementAccount struct {
+	MerchantID int64     `json:"merchant_id"`
+	Address    string    `json:"address"`
+	FeeBPS     *int      `json:"fee_bps,omitempty"`
+	UpdatedBy  string    `json:"updated_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Settlement — выплата мерчанту суммы завершенных ордеров за вычетом комиссии.
+// Суммы в минимальных единицах актива.
+type Settlement struct {
+	ID          string           `json:"id"`
+	MerchantID  int64            `json:"merchant_id"`
+	AssetID     int              `json:"asset_id"`
+	Address     string           `json:"address"`
+	Orders      int              `json:"orders"`
+	GrossAmount string           `json:"gross_amount"`
+	FeeAmount   string           `json:"fee_amount"`
+	NetAmount   string           `json:"net_amount"`
+	FeeBPS      int              `json:"fee_bps"`
+	Status      SettlementStatus `json:"status"`
+	TxHash      *string          `json:"tx_hash,omitempty"`
+	SafeTxHash  *string          `json:"safe_tx_hash,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+	PeriodStart time.Time        `json:"period_start"` // Время завершения самого раннего ордера пакета
+	PeriodEnd   time.Time        `json:"period_end"`   // Время завершения самого позднего ордера пакета
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// SettlementOrder — ордер в отчете о выплате, сумма в единицах актива
+type SettlementOrder struct {
+	OrderID     int       `json:"order_id"`
+	Amount      string    `json:"amount"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// SettlementReport — выплата с составом ордеров, суммы в единицах актива
+type SettlementReport struct {
+	Settlement
+	Asset string            `json:"asset"`
+	Gross string            `json:"gross"`
+	Fee   string            `json:"fee"`
+	Net   string            `json:"net"`
+	Items []SettlementOrder `json:"items"`
+}
+
+// SettlementAccrual — начисления мерчанта: завершенные ордера, еще не вошедшие в выплату
+type SettlementAccrual struct {
+	MerchantID int64 `json:"merchant_id"`
+	AssetID    int   `json:"asset_id"`
+	Orders     int   `json:"orders"`
+	// Сумма ордеров в единицах актива
+	Amount    string    `json:"amount"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
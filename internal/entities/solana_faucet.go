@@ -0,0 +1,18 @@
+package entities
+
+// SolanaFaucetGrant — тестовые средства, отправленные краном devnet на кошелек.
+// Аирдроп и выпуск токена независимы: ошибка одного не отменяет другой.
+type SolanaFaucetGrant struct {
+	Address          string `json:"address"`
+	Network          string `json:"network"`
+	AirdropSOL       string `json:"airdrop_sol"`
+	AirdropSignature string `json:"airdrop_signature,omitempty"`
+	AirdropError     string `json:"airdrop_error,omitempty"`
+	// Заполнены, если кран выпускает тестовый SPL токен
+	Mint          string             `json:"mint,omitempty"`
+	TokenAccount  string             `json:"token_account,omitempty"`
+	TokenAmount   string             `json:"token_amount,omitempty"`
+	MintSignature string             `json:"mint_signature,omitempty"`
+	PriorityFee   *SolanaPriorityFee `json:"priority_fee,omitempty"`
+	MintError     string             `json:"mint_error,omitempty"`
+}
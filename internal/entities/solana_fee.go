@@ -0,0 +1,14 @@
+package entities
+
+// SolanaPriorityFee — лимит вычислений и приоритетная комиссия, с которыми отправляется транзакция Solana
+type SolanaPriorityFee struct {
+	Priority         string `json:"priority"`
+	ComputeUnitLimit uint32 `json:"compute_unit_limit"`
+	// Цена единицы вычислений в микролампортах и итоговая приоритетная комиссия в лампортах сверх базовой
+	MicroLamportsPerUnit uint64 `json:"micro_lamports_per_unit"`
+	FeeLamports          uint64 `json:"fee_lamports"`
+	// Перцентиль комиссий недавних слотов, от которого считалась цена
+	RecentFee uint64 `json:"recent_fee"`
+	// Комиссии недавних слотов недоступны, взята минимальная цена
+	Fallback bool `json:"fallback,omitempty"`
+}
@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// StaffSubjectType — тип объекта, к которому сотрудники добавляют заметки и теги
+type StaffSubjectType string
+
+const (
+	StaffSubjectTransaction StaffSubjectType = "transaction" // ID — хеш транзакции
+	StaffSubjectOrder       StaffSubjectType = "order"       // ID — номер ордера
+)
+
+// StaffNote — внутренняя заметка поддержки или комплаенса, пользователю не показывается
+type StaffNote struct {
+	ID          int              `json:"id"`
+	SubjectType StaffSubjectType `json:"subject_type"`
+	SubjectID   string           `json:"subject_id"`
+	Body        string           `json:"body"`
+	Author      string           `json:"author"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// StaffTag — внутренний тег объекта для поиска и разбора
+type StaffTag struct {
+	SubjectType StaffSubjectType `json:"subject_type"`
+	SubjectID   string           `json:"subject_id"`
+	Tag         string           `json:"tag"`
+	AddedBy     string           `json:"added_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// StaffAnnotations — заметки и теги одного объекта
+type StaffAnnotations struct {
+	SubjectType StaffSubjectType `json:"subject_type"`
+	SubjectID   string           `json:"subject_id"`
+	Tags        []StaffTag       `json:"tags"`
+	Notes       []StaffNote      `json:"notes"`
+}
+
+// StaffAnnotationFilter отбирает объекты по типу, тегу и тексту заметок. Пустые поля не ограничивают выборку.
+type StaffAnnotationFilter struct {
+	SubjectType StaffSubjectType
+	Tag         string
+	Query       string
+}
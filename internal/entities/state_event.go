@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Общие типы событий журналов, остальные совпадают с новым статусом ордера или изменением транзакции
+// (confirmed, credited, ignored, held, released, requeued, aml_flagged, ...)
+const (
+	StateEventSnapshot = "snapshot" // Состояние строки на момент включения журнала
+	StateEventCreated  = "created"
+	StateEventDetected = "detected"
+	StateEventDeleted  = "deleted"
+)
+
+// OrderEvent — неизменяемая запись об изменении ордера, State — строка orders после изменения
+type OrderEvent struct {
+	ID         int64           `json:"id"`
+	OrderID    int64           `json:"order_id"`
+	EventType  string          `json:"event_type"`
+	FromStatus *string         `json:"from_status,omitempty"`
+	ToStatus   *string         `json:"to_status,omitempty"`
+	Changed    []string        `json:"changed"`
+	State      json.RawMessage `json:"state"`
+	DBTxID     int64           `json:"db_tx_id"` // События одной транзакции БД имеют общий идентификатор
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// TransactionEvent — неизменяемая запись об изменении входящей транзакции, State — строка transactions после изменения
+type TransactionEvent struct {
+	ID         int64           `json:"id"`
+	TxHash     string          `json:"tx_hash"`
+	EventType  string          `json:"event_type"`
+	Changed    []string        `json:"changed"`
+	State      json.RawMessage `json:"state"`
+	DBTxID     int64           `json:"db_tx_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// WalletNetwork — сеть кошелька для отнесения депозитов при воспроизведении журнала
+type WalletNetwork struct {
+	Address string
+	Chain   Chain
+	Network string
+}
+
+// DashboardReplay — проекции дашборда, восстановленные из журналов событий на момент Until
+type DashboardReplay struct {
+	Until             time.Time             `json:"until"`
+	OrderEvents       int                   `json:"order_events"`
+	TransactionEvents int                   `json:"transaction_events"`
+	Orders            []DashboardOrderDay   `json:"orders"`
+	Deposits          []DashboardDepositDay `json:"deposits"`
+}
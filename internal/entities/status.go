@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// SubsystemState — грубое состояние подсистемы для публичной страницы статуса
+type SubsystemState string
+
+const (
+	SubsystemOperational SubsystemState = "operational"
+	SubsystemDegraded    SubsystemState = "degraded" // Работает с задержками или частично
+	SubsystemDown        SubsystemState = "down"
+)
+
+// SubsystemStatus — состояние одной подсистемы без внутренних подробностей
+type SubsystemStatus struct {
+	Name  string         `json:"name"`
+	State SubsystemState `json:"state"`
+}
+
+// DegradedModes — включенные ограничения работы: приостановленные депозиты и выводы активов,
+// остановка операций с токеном после административного события контракта
+type DegradedModes struct {
+	DepositsPaused        []string `json:"deposits_paused,omitempty"`
+	WithdrawalsPaused     []string `json:"withdrawals_paused,omitempty"`
+	TokenOperationsHalted bool     `json:"token_operations_halted"`
+}
+
+// SystemStatus — сводное состояние системы для публичной страницы статуса
+type SystemStatus struct {
+	Status        SubsystemState    `json:"status"`
+	StartedAt     time.Time         `json:"started_at"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Subsystems    []SubsystemStatus `json:"subsystems"`
+	DegradedModes DegradedModes     `json:"degraded_modes"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
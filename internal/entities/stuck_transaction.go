@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// StuckTransaction — исходящая транзакция, не попавшая в блок дольше допустимого времени ожидания
+type StuckTransaction struct {
+	TxHash      string    `json:"tx_hash"`
+	FromAddress string    `json:"from_address"`
+	ToAddress   string    `json:"to_address"`
+	Nonce       uint64    `json:"nonce"`
+	Amount      string    `json:"amount"`    // wei
+	GasPrice    string    `json:"gas_price"` // wei
+	Speedups    int       `json:"speedups"`
+	FirstSentAt time.Time `json:"first_sent_at"`
+	LastSentAt  time.Time `json:"last_sent_at"`
+	AgeSeconds  float64   `json:"age_seconds"`
+	// Escalated — автоматические ускорения исчерпаны, операторы оповещены
+	Escalated bool `json:"escalated"`
+	// Cancelling — транзакция заменена нулевым переводом на собственный адрес с тем же нонсом
+	Cancelling bool `json:"cancelling"`
+}
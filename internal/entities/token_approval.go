@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+// TokenApprovalStatus — состояние разрешения на списание токенов
+type TokenApprovalStatus string
+
+const (
+	TokenApprovalActive  TokenApprovalStatus = "active"  // Разрешение действует
+	TokenApprovalRevoked TokenApprovalStatus = "revoked" // Отозвано администратором
+	TokenApprovalExpired TokenApprovalStatus = "expired" // Отозвано автоматически по истечении срока
+)
+
+// TokenApproval — ERC-20 approval мастер-кошелька контракту. Amount и Allowance в единицах актива.
+type TokenApproval struct {
+	ID           int64               `json:"id"`
+	Token        string              `json:"token"`
+	Owner        string              `json:"owner"`
+	Spender      string              `json:"spender"`
+	Amount       string              `json:"amount"`
+	Allowance    *string             `json:"allowance,omitempty"` // Остаток разрешения при последней проверке
+	Purpose      string              `json:"purpose"`
+	Status       TokenApprovalStatus `json:"status"`
+	TxHash       string              `json:"tx_hash"`
+	RevokeTxHash *string             `json:"revoke_tx_hash,omitempty"`
+	CreatedBy    string              `json:"created_by"`
+	RevokedBy    *string             `json:"revoked_by,omitempty"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+	CheckedAt    *time.Time          `json:"checked_at,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	RevokedAt    *time.Time          `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// TokenAdminEvent — административное событие контракта токена (пауза, черный список, смена параметров или владельца)
+type TokenAdminEvent struct {
+	ID           int64  `json:"id"`
+	TokenAddress string `json:"token_address"`
+	EventName    string `json:"event_name"`
+	TxHash       string `json:"tx_hash"`
+	LogIndex     int    `json:"log_index"`
+	BlockNumber  int64  `json:"block_number"`
+	// Адрес, к которому относится событие (например, добавленный в черный список)
+	Subject *string `json:"subject,omitempty"`
+	// Halting — событие приостанавливает выводы и свипы токена до подтверждения администратором
+	Halting        bool       `json:"halting"`
+	AcknowledgedBy *string    `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -3,8 +3,6 @@ package entities
 import (
 	"sync"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // CandleData represents candle data for the chart.
@@ -19,15 +17,14 @@ type CandleData struct {
 
 // TradingPair represents a trading pair.
 type TradingPair struct {
-	Symbol          string                   `json:"symbol"`          // Pair symbol (e.g., BTCRUB).
-	LastPrice       float64                  `json:"lastPrice"`       // Last price.
-	PriceChange     float64                  `json:"priceChange"`     // Price change percentage.
-	OrdersPerSecond float64                  `json:"ordersPerSecond"` // Orders processed per second.
-	CandleData      []CandleData             `json:"-"`               // Historical candle data.
-	LastCandle      CandleData               `json:"-"`               // Last candle.
-	Subscribers     map[*websocket.Conn]bool `json:"-"`               // WebSocket update subscribers.
-	Mutex           sync.RWMutex             `json:"-"`               // Mutex for safe data access.
-	StopChan        chan struct{}            `json:"-"`               // Channel for stopping goroutines.
+	Symbol          string        `json:"symbol"`          // Pair symbol (e.g., BTCRUB).
+	LastPrice       float64       `json:"lastPrice"`       // Last price.
+	PriceChange     float64       `json:"priceChange"`     // Price change percentage.
+	OrdersPerSecond float64       `json:"ordersPerSecond"` // Orders processed per second.
+	CandleData      []CandleData  `json:"-"`               // Historical candle data.
+	LastCandle      CandleData    `json:"-"`               // Last candle.
+	Mutex           sync.RWMutex  `json:"-"`               // Mutex for safe data access.
+	StopChan        chan struct{} `json:"-"`               // Channel for stopping goroutines.
 
 	// Fields for tracking order processing speed
 	OrderCount      int64      `json:"-"` // Total number of orders processed
@@ -11,23 +11,39 @@ const (
 	AMLStatusCleared AMLStatus = "cleared" // Проверена вручную и одобрена
 )
 
+// DepositIgnoreReason объясняет, почему записанный депозит не сопоставляется с ордерами
+type DepositIgnoreReason string
+
+const (
+	DepositIgnoredDust DepositIgnoreReason = "dust" // Меньше минимальной суммы депозита актива
+	DepositIgnoredSpam DepositIgnoreReason = "spam" // Серия одинаковых микропереводов от одного отправителя
+	// Микроперевод отправителя, прощупывающего много депозитных адресов
+	DepositIgnoredProbing DepositIgnoreReason = "probing"
+)
+
 // Transaction represents a blockchain transaction in our system.
 type Transaction struct {
 	ID            int       `json:"id"`
 	TxHash        string    `json:"tx_hash"`
 	WalletAddress string    `json:"wallet_address"`
+	FromAddress   string    `json:"from_address"`
 	Amount        string    `json:"amount"`
 	BlockNumber   int64     `json:"block_number"`
 	Confirmed     bool      `json:"confirmed"`
 	Processed     bool      `json:"processed"`
 	AMLStatus     AMLStatus `json:"aml_status"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	// Депозит записан, но не зачисляется в ордера
+	IgnoredReason *DepositIgnoreReason `json:"ignored_reason,omitempty" db:"ignored_reason"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
 }
 
 type ConfirmedUnprocessedTransaction struct {
 	Id            int
 	TxHash        string
 	WalletAddress string
+	FromAddress   string
 	Amount        string
+	// Мемо перевода, пусто если перевод его не содержит
+	Memo string
 }
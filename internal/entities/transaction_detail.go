@@ -0,0 +1,52 @@
+package entities
+
+// OnChainStatus — состояние транзакции в сети
+type OnChainStatus string
+
+const (
+	OnChainStatusPending OnChainStatus = "pending" // Квитанции еще нет
+	OnChainStatusSuccess OnChainStatus = "success"
+	OnChainStatusFailed  OnChainStatus = "failed" // Транзакция откатилась
+)
+
+// OnChainTransaction — данные транзакции из сети на момент запроса
+type OnChainTransaction struct {
+	Status            OnChainStatus `json:"status"`
+	BlockNumber       uint64        `json:"block_number,omitempty"`
+	BlockHash         string        `json:"block_hash,omitempty"`
+	Confirmations     uint64        `json:"confirmations"`
+	GasUsed           uint64        `json:"gas_used,omitempty"`
+	EffectiveGasPrice string        `json:"effective_gas_price,omitempty"`
+}
+
+// TransactionDetail — сводные данные транзакции для поддержки и страницы транзакции:
+// запись в базе, состояние в сети, результат AML проверки и оплаченный ордер
+type TransactionDetail struct {
+	Transaction Transaction `json:"transaction"`
+	Chain       Chain       `json:"chain,omitempty"`
+	Network     string      `json:"network,omitempty"`
+	TxURL       string      `json:"tx_url,omitempty"`
+	// Нет, если сеть не опрашивается или запрос к ноде не удался (причина в OnChainError)
+	OnChain      *OnChainTransaction `json:"on_chain,omitempty"`
+	OnChainError string              `json:"on_chain_error,omitempty"`
+	AML          *AMLCheckResult     `json:"aml,omitempty"`
+	Order        *Order              `json:"order,omitempty"`
+}
+
+// DepositConfirmations — прогресс подтверждений депозита для индикатора на фронтенде
+type DepositConfirmations struct {
+	TxHash      string `json:"tx_hash"`
+	Chain       Chain  `json:"chain,omitempty"`
+	Network     string `json:"network,omitempty"`
+	BlockNumber int64  `json:"block_number"`
+	// Текущее число подтверждений и требуемое для суммы депозита
+	Confirmations         uint64 `json:"confirmations"`
+	RequiredConfirmations uint64 `json:"required_confirmations"`
+	Confirmed             bool   `json:"confirmed"`
+	// Депозит обработан: зачислен в ордер или исключен из сопоставления
+	Processed bool `json:"processed"`
+	// Средний интервал между последними блоками сети, 0 — неизвестен
+	BlockTimeSeconds float64 `json:"block_time_seconds,omitempty"`
+	// Оценка времени до требуемого числа подтверждений, нет — оценить нельзя
+	ETASeconds *int64 `json:"eta_seconds,omitempty"`
+}
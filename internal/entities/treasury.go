@@ -0,0 +1,104 @@
+package entities
+
+import "time"
+
+// TreasuryTransferKind describes why funds leave the treasury
+type TreasuryTransferKind string
+
+const (
+	TreasuryTransferWithdrawal TreasuryTransferKind = "withdrawal" // Вывод средств на внешний адрес
+	TreasuryTransferSweep      TreasuryTransferKind = "sweep"      // Консолидация средств депозитных кошельков
+	TreasuryTransferSettlement TreasuryTransferKind = "settlement" // Выплата мерчанту по завершенным ордерам
+)
+
+// SafeProposalStatus represents the state of a multisig proposal
+type SafeProposalStatus string
+
+const (
+	SafeProposalStatusProposed SafeProposalStatus = "proposed" // Ожидает подписей владельцев Safe
+	SafeProposalStatusExecuted SafeProposalStatus = "executed" // Исполнена в сети
+	SafeProposalStatusFailed   SafeProposalStatus = "failed"   // Исполнена, но транзакция откатилась
+)
+
+// SafeProposal — транзакция Gnosis Safe, предложенная вместо прямой отправки крупной суммы
+type SafeProposal struct {
+	ID                    string               `json:"id"`
+	SafeAddress           string               `json:"safe_address"`
+	SafeTxHash            string               `json:"safe_tx_hash"`
+	Nonce                 int64                `json:"nonce"`
+	Kind                  TreasuryTransferKind `json:"kind"`
+	FromWalletID          *int                 `json:"from_wallet_id,omitempty"`
+	ToAddress             string               `json:"to_address"`
+	Amount                string               `json:"amount"` // wei
+	Status                SafeProposalStatus   `json:"status"`
+	Confirmations         int                  `json:"confirmations"`
+	ConfirmationsRequired int                  `json:"confirmations_required"`
+	ExecutedTxHash        *string              `json:"executed_tx_hash,omitempty"`
+	InitiatedBy           string               `json:"initiated_by"`
+	CreatedAt             time.Time            `json:"created_at"`
+	UpdatedAt             time.Time            `json:"updated_at"`
+}
+
+// TreasuryTransfer is the outcome of a treasury transfer: a sent transaction, a multisig proposal
+// or a withdrawal queued for the next multisend batch
+type TreasuryTransfer struct {
+	TxHash   string        `json:"tx_hash,omitempty"`
+	Proposal *SafeProposal `json:"proposal,omitempty"`
+	Queued   *Withdrawal   `json:"queued,omitempty"`
+}
+
+// TreasuryWalletRole — назначение кошельков в обзоре казначейства
+type TreasuryWalletRole string
+
+const (
+	TreasuryRoleDeposit TreasuryWalletRole = "deposit" // Депозитные кошельки пользователей (float до свипа)
+	TreasuryRoleHot     TreasuryWalletRole = "hot"     // Операционные кошельки с ключами на сервере
+	TreasuryRoleCold    TreasuryWalletRole = "cold"    // Multisig Safe и внешние холодные кошельки
+)
+
+// TreasuryBalance — суммарный баланс актива на кошельках одной роли
+type TreasuryBalance struct {
+	Asset   string             `json:"asset"`
+	Role    TreasuryWalletRole `json:"role"`
+	Wallets int                `json:"wallets"`
+	Amount  string             `json:"amount"` // В единицах актива
+	Units   string             `json:"units"`  // В минимальных единицах (wei)
+}
+
+// PendingSweeps — средства, ожидающие консолидации
+type PendingSweeps struct {
+	Asset string `json:"asset"`
+	// Депозитные кошельки с балансом не меньше минимальной суммы свипа
+	Wallets int    `json:"wallets"`
+	Amount  string `json:"amount"`
+	// Свипы, оформленные предложениями Safe и ожидающие подписей владельцев
+	Proposals      int    `json:"proposals"`
+	ProposedAmount string `json:"proposed_amount"`
+}
+
+// WalletLedger — движение средств депозитного кошелька по данным базы (wei)
+type WalletLedger struct {
+	Address     string
+	Deposited   string
+	Unconfirmed string
+	Refunded    string
+}
+
+// UnreconciledWallet — кошелек, баланс которого не объясняется записанными депозитами и возвратами
+type UnreconciledWallet struct {
+	Address    string `json:"address"`
+	OnChain    string `json:"on_chain"`   // Баланс из кеша мониторинга
+	Ledger     string `json:"ledger"`     // Депозиты за вычетом возвратов
+	Difference string `json:"difference"` // OnChain - Ledger
+}
+
+// TreasuryOverview — агрегированное состояние средств платформы
+type TreasuryOverview struct {
+	Balances          []TreasuryBalance    `json:"balances"`
+	PendingSweeps     PendingSweeps        `json:"pending_sweeps"`
+	Unreconciled      []UnreconciledWallet `json:"unreconciled"`
+	UnreconciledTotal string               `json:"unreconciled_total"`
+	// Время самой старой записи кеша балансов депозитных кошельков
+	BalancesAsOf *time.Time `json:"balances_as_of,omitempty"`
+	GeneratedAt  time.Time  `json:"generated_at"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// TwoFactorSecret represents a TOTP enrollment of a user
+type TwoFactorSecret struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// TwoFactorEnrollment содержит данные, необходимые для настройки приложения-аутентификатора
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
@@ -5,15 +5,24 @@ import (
 	"time"
 )
 
+// LegacyKeyVersion — версия сида WALLET_SEED, из которого выведены кошельки до поддержки нескольких сидов
+const LegacyKeyVersion = 1
+
 // Wallet represents a tracked wallet in our system
 type Wallet struct {
-	ID             int       `db:"id"`
-	UserID         int64     `db:"user_id"`
-	Address        string    `db:"address"`
-	DerivationPath string    `db:"derivation_path"`
-	WalletIndex    uint32    `db:"wallet_index"`
-	IsTestnet      bool      `db:"is_testnet"`
-	CreatedAt      time.Time `db:"created_at"`
+	ID             int           `db:"id"`
+	UserID         int64         `db:"user_id"`
+	Address        string        `db:"address"`
+	DerivationPath string        `db:"derivation_path"`
+	WalletIndex    uint32        `db:"wallet_index"`
+	IsTestnet      bool          `db:"is_testnet"`
+	Chain          Chain         `db:"chain"`
+	Network        string        `db:"network"`
+	AddressFormat  AddressFormat `db:"address_format"`
+	KeyVersion     int           `db:"key_version"` // Версия мастер-сида, из которого выведен ключ
+	CreatedAt      time.Time     `db:"created_at"`
+	ArchivedAt     *time.Time    `db:"archived_at"` // Заполнено после закрытия аккаунта владельца
+	RetiredAt      *time.Time    `db:"retired_at"`  // Пустой кошелек выведен из мониторинга после срока хранения
 }
 
 // WalletDetail represents wallet information with ID and address
@@ -27,6 +36,8 @@ type WalletDetailExtended struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
 	Address   string    `json:"address"`
+	Chain     Chain     `json:"chain"`
+	Network   string    `json:"network"`
 	IsTestnet bool      `json:"is_testnet"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -53,3 +64,17 @@ type WalletBalance struct {
 	Status        BalanceStatus `json:"status"`
 	LastChecked   time.Time     `json:"last_checked"`
 }
+
+// CachedWalletBalance — баланс кошелька из кеша мониторинга в ответе массового запроса
+type CachedWalletBalance struct {
+	WalletID int `json:"wallet_id"`
+	WalletBalance
+}
+
+// WalletBalanceQueryResult — кешированные балансы запрошенных кошельков. Кошельки, которые не найдены,
+// принадлежат другому пользователю или еще не попали в кеш, перечислены в Missing*.
+type WalletBalanceQueryResult struct {
+	Balances         []CachedWalletBalance
+	MissingWalletIDs []int
+	MissingAddresses []string
+}
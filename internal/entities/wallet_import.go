@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// WalletImportSource — откуда взят список импортируемых кошельков
+type WalletImportSource string
+
+const (
+	WalletImportSourceRange WalletImportSource = "range" // Диапазон индексов путей деривации пользователя
+	WalletImportSourceCSV   WalletImportSource = "csv"   // CSV со строками address,derivation_path
+)
+
+// WalletImportStatus — состояние догрузки балансов и истории депозитов импортированных кошельков
+type WalletImportStatus string
+
+const (
+	WalletImportPending   WalletImportStatus = "pending"
+	WalletImportRunning   WalletImportStatus = "running"
+	WalletImportCompleted WalletImportStatus = "completed"
+	WalletImportFailed    WalletImportStatus = "failed"
+)
+
+// WalletImport — импорт кошельков из предыдущей системы. Кошельки регистрируются при создании импорта,
+// история депозитов с блока FromBlock догружается в фоне.
+type WalletImport struct {
+	ID              int64              `json:"id" db:"id"`
+	Source          WalletImportSource `json:"source" db:"source"`
+	Status          WalletImportStatus `json:"status" db:"status"`
+	FromBlock       int64              `json:"from_block" db:"from_block"`
+	WalletsTotal    int                `json:"wallets_total" db:"wallets_total"`
+	WalletsImported int                `json:"wallets_imported" db:"wallets_imported"` // Новые кошельки, уже отслеживаемые не считаются
+	DepositsFound   int                `json:"deposits_found" db:"deposits_found"`
+	RequestedBy     string             `json:"requested_by" db:"requested_by"`
+	Error           *string            `json:"error,omitempty" db:"error"`
+	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
+	CompletedAt     *time.Time         `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// WalletImportEntry — кошелек, который нужно перенести: адрес и путь деривации, из которого он выведен
+type WalletImportEntry struct {
+	Address        string
+	DerivationPath string
+	UserID         int64
+	Index          uint32
+}
@@ -0,0 +1,93 @@
+package entities
+
+import "time"
+
+// WithdrawalStatus represents the state of a user withdrawal
+type WithdrawalStatus string
+
+const (
+	WithdrawalPending   WithdrawalStatus = "pending"   // Лимит зарезервирован, перевод отправляется
+	WithdrawalQueued    WithdrawalStatus = "queued"    // Лимит зарезервирован, перевод ждет пакетной отправки
+	WithdrawalSubmitted WithdrawalStatus = "submitted" // Транзакция отправлена или создано предложение Safe
+	WithdrawalFailed    WithdrawalStatus = "failed"    // Перевод не состоялся, сумма не учитывается в лимитах
+	WithdrawalUnknown   WithdrawalStatus = "unknown"   // Узел не подтвердил прием транзакции, лимит зарезервирован до сверки оператором
+)
+
+// Withdrawal — вывод средств пользователем, учитываемый в лимитах скорости
+type Withdrawal struct {
+	ID        string `json:"id"`
+	UserID    int64  `json:"user_id"`
+	WalletID  int    `json:"wallet_id"`
+	ToAddress string `json:"to_address"`
+	// Сумма в минимальных единицах актива
+	Amount string `json:"amount"`
+	// Перевод с горячего кошелька; выводы через Safe не входят в общий лимит оттока
+	Hot         bool             `json:"hot"`
+	Status      WithdrawalStatus `json:"status"`
+	BatchID     *string          `json:"batch_id,omitempty"`
+	TxHash      *string          `json:"tx_hash,omitempty"`
+	SafeTxHash  *string          `json:"safe_tx_hash,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+	InitiatedBy string           `json:"initiated_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// WithdrawalBatchStatus represents the state of a multisend transaction paying several withdrawals
+type WithdrawalBatchStatus string
+
+const (
+	WithdrawalBatchSending   WithdrawalBatchStatus = "sending"   // Выводы закреплены за пакетом, транзакция отправляется
+	WithdrawalBatchSubmitted WithdrawalBatchStatus = "submitted" // Транзакция отправлена, ожидается квитанция
+	WithdrawalBatchConfirmed WithdrawalBatchStatus = "confirmed" // Транзакция исполнена, выводы оплачены
+	WithdrawalBatchFailed    WithdrawalBatchStatus = "failed"    // Пакет не отправлен или откатился, выводы отправлены по одному
+)
+
+// WithdrawalBatch — пакет выводов одного токена, оплаченный одной multisend транзакцией
+type WithdrawalBatch struct {
+	ID          string                `json:"id"`
+	Token       string                `json:"token"`
+	Status      WithdrawalBatchStatus `json:"status"`
+	Withdrawals int                   `json:"withdrawals"`
+	// Сумма выводов пакета в минимальных единицах актива
+	Amount    string    `json:"amount"`
+	TxHash    *string   `json:"tx_hash,omitempty"`
+	Error     *string   `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WithdrawalBatchDetails — пакет вместе с состоянием каждого вывода в нем
+type WithdrawalBatchDetails struct {
+	WithdrawalBatch
+	Items []Withdrawal `json:"items"`
+}
+
+// WithdrawalUsage — суммы выводов в минимальных единицах за скользящий час и сутки
+type WithdrawalUsage struct {
+	UserHourly   string
+	UserDaily    string
+	GlobalHourly string
+	GlobalDaily  string
+}
+
+// WithdrawalAllowance — лимит, использованная сумма и остаток в единицах актива. Пустой лимит — без ограничения.
+type WithdrawalAllowance struct {
+	Limit     string `json:"limit,omitempty"`
+	Used      string `json:"used"`
+	Remaining string `json:"remaining,omitempty"`
+}
+
+// WithdrawalLimits — состояние лимитов вывода пользователя
+type WithdrawalLimits struct {
+	UserID int64               `json:"user_id"`
+	Tier   string              `json:"tier"`
+	Asset  string              `json:"asset"`
+	Hourly WithdrawalAllowance `json:"hourly"`
+	Daily  WithdrawalAllowance `json:"daily"`
+	// Общий лимит оттока с горячих кошельков платформы за час и сутки
+	GlobalHourly WithdrawalAllowance `json:"global_hourly"`
+	GlobalDaily  WithdrawalAllowance `json:"global_daily"`
+	// Максимальная сумма, которую пользователь может вывести сейчас; пусто — без ограничения
+	Available string `json:"available,omitempty"`
+}
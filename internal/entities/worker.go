@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// WorkerState — состояние фонового обработчика по его последней работе
+type WorkerState string
+
+const (
+	WorkerRunning WorkerState = "running"
+	WorkerFailing WorkerState = "failing" // Последний проход завершился ошибкой
+	WorkerStalled WorkerState = "stalled" // Обработчик давно не отмечался
+)
+
+// WorkerStatus — последняя работа фонового обработчика
+type WorkerStatus struct {
+	Name            string      `json:"name"`
+	State           WorkerState `json:"state"`
+	IntervalSeconds int64       `json:"interval_seconds"`
+	LastHeartbeat   *time.Time  `json:"last_heartbeat,omitempty"`
+	LastSuccessAt   *time.Time  `json:"last_success_at,omitempty"`
+	LastErrorAt     *time.Time  `json:"last_error_at,omitempty"`
+	LastError       string      `json:"last_error,omitempty"`
+	LastProcessed   int         `json:"last_processed"`  // Записей, обработанных последним успешным проходом
+	TotalProcessed  int64       `json:"total_processed"` // Записей с запуска процесса
+	Lag             int64       `json:"lag"`             // Отставание: блоков для сканеров, записей в очереди для остальных
+}
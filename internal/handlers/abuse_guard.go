@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// CaptchaTokenHeader содержит токен CAPTCHA, полученный клиентом от провайдера
+const CaptchaTokenHeader = "X-Captcha-Token"
+
+type AbuseGuard interface {
+	Check(ctx context.Context, userID int64, action, captchaToken, remoteIP string) error
+}
+
+var _ AbuseGuard = (*usecases.AbuseGuard)(nil)
+
+// throttled wraps handlers that mint new deposit wallets with per-user caps, cooldowns and CAPTCHA checks
+func (h *HTTPHandler) throttled(action string, next http.HandlerFunc) http.HandlerFunc {
+	return throttle(h.logger, h.abuseGuard, action, next)
+}
+
+// throttle applies the abuse guard checks of the action before next, a nil guard disables them
+func throttle(logger *slog.Logger, guard AbuseGuard, action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if guard == nil {
+			next(w, r)
+			return
+		}
+
+		userID, ok := parseUserID(w, r)
+		if !ok {
+			return
+		}
+
+		err := guard.Check(r.Context(), userID, action, r.Header.Get(CaptchaTokenHeader), clientIP(r))
+		if err == nil {
+			next(w, r)
+			return
+		}
+
+		var cooldownErr *usecases.CooldownError
+		switch {
+		case errors.As(err, &cooldownErr):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(cooldownErr.RetryAfter.Seconds()))))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, usecases.ErrTooManyPendingOrders),
+			errors.Is(err, usecases.ErrTooManyUnusedWallets):
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, usecases.ErrCaptchaRequired),
+			errors.Is(err, usecases.ErrCaptchaInvalid),
+			errors.Is(err, usecases.ErrAccountClosed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			logger.Error("Abuse guard check failed", "error", err, "user_id", userID, "action", action)
+			http.Error(w, "Failed to process request", http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type AccountClosureService interface {
+	RequestClosure(ctx context.Context, userID int64, reason string) (*entities.AccountClosure, error)
+	GetClosure(ctx context.Context, userID int64) (*entities.AccountClosure, error)
+	GetClosures(ctx context.Context, status entities.AccountClosureStatus) ([]entities.AccountClosure, error)
+}
+
+var _ AccountClosureService = (*usecases.AccountClosureService)(nil)
+
+// AccountClosureHandler принимает запросы пользователей на закрытие аккаунта и отдает статус закрытия
+type AccountClosureHandler struct {
+	logger    *slog.Logger
+	service   AccountClosureService
+	twoFactor *TwoFactorHandler
+}
+
+func NewAccountClosureHandler(logger *slog.Logger, service AccountClosureService, twoFactor *TwoFactorHandler) *AccountClosureHandler {
+	return &AccountClosureHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *AccountClosureHandler) RegisterRoutes(router *mux.Router) {
+	// Закрытие необратимо и выводит остатки с кошельков, поэтому требует второго фактора
+	router.HandleFunc("/account/close", h.twoFactor.RequireSecondFactor(OperationAccountClosure, RequestingUser, h.RequestClosureHandler)).Methods("POST")
+	router.HandleFunc("/account/closure", h.GetClosureHandler).Methods("GET")
+}
+
+func (h *AccountClosureHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/account_closures", h.GetClosuresHandler).Methods("GET")
+}
+
+type requestClosureRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AccountClosureHandler) RequestClosureHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req requestClosureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	closure, err := h.service.RequestClosure(r.Context(), userID, req.Reason)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, h.logger, closure)
+}
+
+func (h *AccountClosureHandler) GetClosureHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	closure, err := h.service.GetClosure(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, closure)
+}
+
+func (h *AccountClosureHandler) GetClosuresHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.AccountClosureStatus(r.URL.Query().Get("status"))
+
+	closures, err := h.service.GetClosures(r.Context(), status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, closures)
+}
+
+func (h *AccountClosureHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrClosureNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrClosureAlreadyExists),
+		errors.Is(err, usecases.ErrClosureBlocked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Account closure request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
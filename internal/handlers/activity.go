@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type ActivityService interface {
+	GetUserActivity(ctx context.Context, userID int64, cursor string, limit int) (*entities.ActivityPage, error)
+}
+
+var _ ActivityService = (*usecases.ActivityService)(nil)
+
+// ActivityHandler отдает ленту активности пользователя: ордера, депозиты, выводы и события аккаунта
+type ActivityHandler struct {
+	logger  *slog.Logger
+	service ActivityService
+}
+
+func NewActivityHandler(logger *slog.Logger, service ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *ActivityHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/users/me/activity", h.GetActivityHandler).Methods("GET")
+}
+
+func (h *ActivityHandler) GetActivityHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.service.GetUserActivity(r.Context(), userID, r.URL.Query().Get("cursor"), limit)
+	if errors.Is(err, usecases.ErrInvalidActivityCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get user activity", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(page); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+const defaultAuditEventsLimit = 100
+
+type AuditService interface {
+	AuditRecorder
+	GetEvents(ctx context.Context, eventType entities.AuditEventType, subject string, limit int) ([]entities.AuditEvent, error)
+}
+
+var _ AuditService = (*usecases.AuditService)(nil)
+
+// AdminRoutesRegistrar is implemented by handlers that expose routes under the guarded /admin prefix
+type AdminRoutesRegistrar interface {
+	RegisterAdminRoutes(admin *mux.Router)
+}
+
+// AdminHandler serves the administrative surface: /admin/* and /metrics
+type AdminHandler struct {
+	logger     *slog.Logger
+	audit      AuditService
+	guard      *AdminGuard
+	registrars []AdminRoutesRegistrar
+}
+
+func NewAdminHandler(logger *slog.Logger, audit AuditService, guard *AdminGuard, registrars ...AdminRoutesRegistrar) *AdminHandler {
+	return &AdminHandler{
+		logger:     logger,
+		audit:      audit,
+		guard:      guard,
+		registrars: registrars,
+	}
+}
+
+// AdminRouter returns the /admin subrouter protected by the admin guard, so other handlers can mount admin routes
+func (h *AdminHandler) AdminRouter(router *mux.Router) *mux.Router {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(h.guard.Middleware)
+	return admin
+}
+
+func (h *AdminHandler) RegisterRoutes(router *mux.Router) {
+	// Метрики процесса и приложения в формате expvar
+	router.Handle("/metrics", h.guard.Middleware(expvar.Handler())).Methods("GET")
+
+	admin := h.AdminRouter(router)
+	admin.HandleFunc("/audit", h.GetAuditEventsHandler).Methods("GET")
+
+	for _, registrar := range h.registrars {
+		registrar.RegisterAdminRoutes(admin)
+	}
+}
+
+func (h *AdminHandler) GetAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	eventType := entities.AuditEventType(r.URL.Query().Get("event_type"))
+	subject := r.URL.Query().Get("subject")
+
+	limit := defaultAuditEventsLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := h.audit.GetEvents(r.Context(), eventType, subject, limit)
+	if err != nil {
+		h.logger.Error("Failed to get audit events", "error", err)
+		http.Error(w, "Failed to get audit events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(events); err != nil {
+		h.logger.Error("Failed to encode audit events", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+type AuditRecorder interface {
+	Record(ctx context.Context, eventType entities.AuditEventType, actor, subject string, details map[string]any) error
+}
+
+// AdminGuard ограничивает доступ к административным маршрутам (/admin, /metrics)
+// списком разрешённых подсетей и, при необходимости, клиентским сертификатом (mTLS).
+type AdminGuard struct {
+	logger            *slog.Logger
+	audit             AuditRecorder
+	allowed           []*net.IPNet
+	requireClientCert bool
+}
+
+// NewAdminGuard creates a new guard. An empty CIDR list denies all addresses.
+// The client address is always taken from the connection, proxy headers are not trusted here.
+func NewAdminGuard(logger *slog.Logger, audit AuditRecorder, cidrs []string, requireClientCert bool) (*AdminGuard, error) {
+	allowed, err := parseCIDRs(cidrs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin CIDR %w", err)
+	}
+
+	return &AdminGuard{
+		logger:            logger,
+		audit:             audit,
+		allowed:           allowed,
+		requireClientCert: requireClientCert,
+	}, nil
+}
+
+// Middleware rejects requests from addresses outside of the allowlist or without a verified client certificate
+func (g *AdminGuard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := remoteHost(r)
+
+		if !g.isAllowed(net.ParseIP(host)) {
+			g.reject(w, r, host, "ip_not_allowed")
+			return
+		}
+
+		if g.requireClientCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			g.reject(w, r, host, "client_certificate_required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (g *AdminGuard) isAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range g.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *AdminGuard) reject(w http.ResponseWriter, r *http.Request, ip, reason string) {
+	g.logger.WarnContext(r.Context(), "Admin access denied",
+		"ip", ip,
+		"path", r.URL.Path,
+		"reason", reason)
+
+	if g.audit != nil {
+		details := map[string]any{
+			"reason":     reason,
+			"method":     r.Method,
+			"user_agent": r.UserAgent(),
+		}
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			details["client_cert_subject"] = r.TLS.PeerCertificates[0].Subject.String()
+		}
+		// Ошибка уже залогирована внутри AuditService
+		_ = g.audit.Record(r.Context(), entities.AuditEventAdminAccessDenied, ip, r.URL.Path, details)
+	}
+
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
+
+// adminActor identifies the operator of an admin request for the audit log:
+// the client certificate subject when mTLS is used, otherwise the remote address
+func adminActor(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return "admin:" + r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	return "admin:" + remoteHost(r)
+}
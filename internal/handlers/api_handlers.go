@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,28 +17,67 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/explorer"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
 )
 
 var _ OrderService = (*usecases.OrderService)(nil)
 
+// TreasuryTransfers отправляет переводы напрямую или через предложение multisig казначейства
+type TreasuryTransfers interface {
+	Transfer(ctx context.Context, client *ethclient.Client, kind entities.TreasuryTransferKind, fromWalletID int, toAddress string, amount *big.Int, initiatedBy string) (*entities.TreasuryTransfer, error)
+}
+
+var _ TreasuryTransfers = (*usecases.TreasuryService)(nil)
+
+// ExplorerLinks строит ссылки на обозреватель блоков, пустая строка — у сети нет обозревателя
+type ExplorerLinks interface {
+	TxURL(chain, network, hash string) string
+	AddressURL(chain, network, address string) string
+}
+
+var _ ExplorerLinks = (*explorer.Links)(nil)
+
+// FiatPrices оценивает суммы в фиатной валюте по кешу курсов
+type FiatPrices interface {
+	DefaultCurrency() string
+	BalanceValue(chain entities.Chain, asset string, amount decimal.Decimal, fiat string) *entities.FiatValue
+	TransactionValues(ctx context.Context, asset entities.Asset, transactions []entities.Transaction, fiat string) []*entities.FiatValue
+	OrderValues(ctx context.Context, orders []entities.Order, fiat string) []*entities.FiatValue
+}
+
+var _ FiatPrices = (*usecases.PriceCache)(nil)
+
 type HTTPHandler struct {
 	logger             *slog.Logger
 	dataService        *mocked.DataService
 	walletService      workers.WalletService
 	orderService       OrderService
 	transactionService workers.TransactionService
+	twoFactor          *TwoFactorHandler
+	abuseGuard         AbuseGuard
+	treasury           TreasuryTransfers
+	links              ExplorerLinks
+	prices             FiatPrices
 
 	bscClient *ethclient.Client
 }
 
-func NewHTTPHandler(logger *slog.Logger, bscClient *ethclient.Client, dataService *mocked.DataService, walletService workers.WalletService, orderService OrderService, transactionService workers.TransactionService) *HTTPHandler {
+func NewHTTPHandler(logger *slog.Logger, bscClient *ethclient.Client, dataService *mocked.DataService, walletService workers.WalletService, orderService OrderService, transactionService workers.TransactionService, twoFactor *TwoFactorHandler, abuseGuard AbuseGuard, treasury TreasuryTransfers, links ExplorerLinks, prices FiatPrices) *HTTPHandler {
 	return &HTTPHandler{
 		logger:             logger,
 		dataService:        dataService,
 		walletService:      walletService,
 		orderService:       orderService,
 		transactionService: transactionService,
+		twoFactor:          twoFactor,
+		abuseGuard:         abuseGuard,
+		treasury:           treasury,
+		links:              links,
+		prices:             prices,
 		bscClient:          bscClient,
 	}
 }
@@ -47,17 +87,18 @@ func (h *HTTPHandler) RegisterRoutes(router *mux.Router) {
 
 	// Orders
 	router.HandleFunc("/orders/user", h.GetUserOrders).Methods("GET")
-	router.HandleFunc("/create_order", h.CreateOrder).Methods("POST")
+	router.HandleFunc("/create_order", h.throttled(usecases.AbuseActionOrderCreation, h.CreateOrder)).Methods("POST")
 	router.HandleFunc("/orders/{orderId:[0-9]+}", h.DeleteOrderHandler).Methods("DELETE")
 
 	// Wallets
-	router.HandleFunc("/wallet/generate", h.GenerateWallet).Methods("POST")
+	router.HandleFunc("/wallet/generate", h.throttled(usecases.AbuseActionWalletGeneration, h.GenerateWallet)).Methods("POST")
 	router.HandleFunc("/wallets/user", h.GetUserWallets).Methods("GET")
 	router.HandleFunc("/wallets/ids", h.GetWalletDetailsHandler).Methods("GET")
 	router.HandleFunc("/wallet/balance", h.CheckWalletBalance).Methods("GET")
 	router.HandleFunc("/wallet/balances", h.GetWalletBalancesHandler).Methods("GET")
+	router.HandleFunc("/wallet/balances/query", h.QueryWalletBalancesHandler).Methods("POST")
 	router.HandleFunc("/wallet/details", h.GetWalletDetailsHandler).Methods("GET")
-	router.HandleFunc("/wallet/transfer", h.TransferFundsHandler).Methods("POST")
+	router.HandleFunc("/wallet/transfer", h.twoFactor.RequireSecondFactor(OperationWalletTransfer, h.walletOwner, h.TransferFundsHandler)).Methods("POST")
 	router.HandleFunc("/wallets/extended", h.GetWalletDetailsExtendedHandler).Methods("GET")
 	router.HandleFunc("/wallet/{walletId:[0-9]+}", h.DeleteWalletHandler).Methods("DELETE")
 
@@ -68,6 +109,9 @@ func (h *HTTPHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/data/pairs", h.GetTradingPairsHandler).Methods("GET")
 	router.HandleFunc("/data/candles/{symbol}", h.GetCandlesHandler).Methods("GET")
 
+	// Two-factor authentication
+	h.twoFactor.RegisterRoutes(router)
+
 	// Static files - register last to avoid intercepting other routes.
 	fs := http.FileServer(http.Dir("./static"))
 	router.PathPrefix("/").Handler(http.StripPrefix("/", fs))
@@ -87,7 +131,27 @@ func (h *HTTPHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	json.NewEncoder(w).Encode(orders)
+
+	values := h.prices.OrderValues(r.Context(), orders, h.fiatCurrency(r))
+	views := make([]userOrder, 0, len(orders))
+	for i, order := range orders {
+		views = append(views, userOrder{Order: order, Fiat: values[i]})
+	}
+	json.NewEncoder(w).Encode(views)
+}
+
+// userOrder — ордер с приблизительной стоимостью в фиатной валюте
+type userOrder struct {
+	entities.Order
+	Fiat *entities.FiatValue `json:"fiat,omitempty"`
+}
+
+// fiatCurrency возвращает валюту оценки из параметра fiat или валюту по умолчанию
+func (h *HTTPHandler) fiatCurrency(r *http.Request) string {
+	if fiat := r.URL.Query().Get("fiat"); fiat != "" {
+		return fiat
+	}
+	return h.prices.DefaultCurrency()
 }
 
 func (h *HTTPHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
@@ -102,38 +166,107 @@ func (h *HTTPHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := strconv.ParseInt(userIDParam, 10, 64)
 
-	// Here we always generate new deposit wallet for order
-	walletID, address, err := h.walletService.GenerateWalletForUser(r.Context(), userID)
-	if err != nil {
-		h.logger.Error("[Create Order] Error generating wallet", "error", err)
-		http.Error(w, fmt.Sprintf("Failed to generate wallet: %v", err), http.StatusInternalServerError)
+	// Лимиты актива проверяем до генерации кошелька
+	if err = h.orderService.ValidateAmount(amountParam); err != nil {
+		h.writeOrderError(w, err)
 		return
 	}
-	h.logger.Info("Generated new wallet for user", "user_id", userID, "wallet", address)
 
-	err = h.orderService.CreateOrder(r.Context(), int(userID), walletID, amountParam)
+	var walletID int
+	var address string
+
+	// С уникальными суммами или мемо ордера можно оплачивать на уже существующий кошелек пользователя,
+	// иначе для каждого ордера генерируется новый кошелек
+	sharedWallets := h.orderService.UsesAmountFingerprints() || h.orderService.UsesDepositMemos()
+	if walletIDParam := r.URL.Query().Get("wallet_id"); walletIDParam != "" && sharedWallets {
+		walletID, address, err = h.findUserWallet(r, userID, walletIDParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		walletID, address, err = h.walletService.GenerateDepositWallet(r.Context(), userID)
+		if err != nil {
+			h.logger.Error("[Create Order] Error generating wallet", "error", err)
+			http.Error(w, fmt.Sprintf("Failed to generate wallet: %v", err), http.StatusInternalServerError)
+			return
+		}
+		h.logger.Info("Generated new wallet for user", "user_id", userID, "wallet", address)
+	}
+
+	payment, err := h.orderService.CreateOrder(r.Context(), int(userID), walletID, amountParam)
 	if err != nil {
 		h.logger.Error("[Create Order] Error creating order", "error", err, "user_id", userID, "wallet", address)
-		http.Error(w, fmt.Sprintf("Failed to create order: %v", err), http.StatusInternalServerError)
+		h.writeOrderError(w, err)
 		return
 	}
 
-	h.logger.Info("[Create Order] Order created successfully", "user_id", userID, "wallet", address, "amount", amountParam)
+	h.logger.Info("[Create Order] Order created successfully", "user_id", userID, "wallet", address, "amount", amountParam,
+		"pay_amount", payment.Amount, "memo", payment.Memo)
+
+	response := map[string]any{
+		"status":     "success",
+		"wallet_id":  walletID,
+		"wallet":     address,
+		"pay_amount": payment.Amount, // точная сумма перевода, по ней сопоставляется оплата
+		"wallet_url": h.addressURL(address),
+	}
+	if payment.Memo != "" {
+		response["memo"] = payment.Memo // мемо, которое нужно указать в переводе
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]any{
-		"status":    "success",
-		"wallet_id": walletID,
-		"wallet":    address,
-	})
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeOrderError maps asset limit errors to client errors
+func (h *HTTPHandler) writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrOrderAmountOutOfRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrDepositsDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, fmt.Sprintf("Failed to create order: %v", err), errorStatus(err))
+	}
+}
+
+// errorStatus returns 504 when an external call (RPC node, AML or HTTP provider) missed its deadline, 500 otherwise
+func errorStatus(err error) int {
+	if timeouts.IsTimeout(err) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// findUserWallet returns the wallet of the user with the given ID
+func (h *HTTPHandler) findUserWallet(r *http.Request, userID int64, walletIDParam string) (int, string, error) {
+	walletID, err := strconv.Atoi(walletIDParam)
+	if err != nil {
+		return 0, "", errors.New("invalid wallet ID format")
+	}
+
+	wallets, err := h.walletService.GetWalletDetailsForUser(r.Context(), userID)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get user wallets: %w", err)
+	}
+
+	for _, wallet := range wallets {
+		if int(wallet.ID) == walletID {
+			return walletID, wallet.Address, nil
+		}
+	}
+
+	return 0, "", errors.New("wallet not found for user")
 }
 
 // GetTradingPairsHandler returns a list of trading pairs.
 func (h *HTTPHandler) GetTradingPairsHandler(w http.ResponseWriter, _ *http.Request) {
-	pairs := make([]map[string]any, 0, len(h.dataService.TradingPairs))
+	tradingPairs := h.dataService.Pairs()
+	pairs := make([]map[string]any, 0, len(tradingPairs))
 
-	for _, pair := range h.dataService.TradingPairs {
+	for _, pair := range tradingPairs {
 		pair.Mutex.RLock()
 		pairData := map[string]any{
 			"symbol":          pair.Symbol,
@@ -190,8 +323,36 @@ func (h *HTTPHandler) GetWalletTransactions(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	asset := h.walletService.Asset()
+	values := h.prices.TransactionValues(r.Context(), asset, transactions, h.fiatCurrency(r))
+	views := make([]walletTransaction, 0, len(transactions))
+	for i, transaction := range transactions {
+		views = append(views, walletTransaction{
+			Transaction: transaction,
+			TxURL:       h.links.TxURL(string(asset.Chain), asset.Network, transaction.TxHash),
+			WalletURL:   h.addressURL(transaction.WalletAddress),
+			FromURL:     h.addressURL(transaction.FromAddress),
+			Fiat:        values[i],
+		})
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transactions)
+	json.NewEncoder(w).Encode(views)
+}
+
+// walletTransaction — транзакция кошелька со ссылками на обозреватель и фиатной оценкой суммы
+type walletTransaction struct {
+	entities.Transaction
+	TxURL     string              `json:"tx_url,omitempty"`
+	WalletURL string              `json:"wallet_url,omitempty"`
+	FromURL   string              `json:"from_url,omitempty"`
+	Fiat      *entities.FiatValue `json:"fiat,omitempty"`
+}
+
+// addressURL возвращает ссылку на адрес в обозревателе сети кошельков
+func (h *HTTPHandler) addressURL(address string) string {
+	asset := h.walletService.Asset()
+	return h.links.AddressURL(string(asset.Chain), asset.Network, address)
 }
 
 // GenerateWallet generates a new wallet for a specific user
@@ -224,9 +385,10 @@ func (h *HTTPHandler) GenerateWallet(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":    "success",
-		"wallet_id": walletID,
-		"wallet":    address,
+		"status":     "success",
+		"wallet_id":  walletID,
+		"wallet":     address,
+		"wallet_url": h.addressURL(address),
 		// Note: We don't have direct access to the index here, but it's stored in the database
 	})
 }
@@ -332,6 +494,15 @@ func (h *HTTPHandler) GetWalletDetailsExtendedHandler(w http.ResponseWriter, r *
 	json.NewEncoder(w).Encode(walletDetails)
 }
 
+// walletOwner returns the owner of the wallet_id the transfer is sent from
+func (h *HTTPHandler) walletOwner(r *http.Request) (int64, error) {
+	walletID, err := strconv.Atoi(r.URL.Query().Get("wallet_id"))
+	if err != nil {
+		return 0, err
+	}
+	return h.walletService.WalletOwner(r.Context(), walletID)
+}
+
 // TransferFundsHandler transfers funds from a wallet to another address
 func (h *HTTPHandler) TransferFundsHandler(w http.ResponseWriter, r *http.Request) {
 	// Get parameters from request
@@ -353,29 +524,73 @@ func (h *HTTPHandler) TransferFundsHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Parse amount (convert from USDT to wei - multiply by 10^18)
-	amountFloat, err := strconv.ParseFloat(amountParam, 64)
-	if err != nil {
+	// Parse amount (convert from asset units to the token's minimal units)
+	amount, err := decimal.Parse(amountParam)
+	if err != nil || amount.Sign() <= 0 {
 		h.logger.Error("Invalid amount format", "error", err, "amount", amountParam)
 		http.Error(w, "Invalid amount format", http.StatusBadRequest)
 		return
 	}
 
-	// Convert to wei (multiply by 10^18)
-	amountWei := new(big.Float).Mul(
-		big.NewFloat(amountFloat),
-		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)),
-	)
-
-	// Convert to big.Int
-	amountInt := new(big.Int)
-	amountWei.Int(amountInt)
+	// Сумма точнее минимальной единицы токена отклоняется, а не округляется молча
+	asset := h.walletService.Asset()
+	amountInt, err := amount.Units(asset.Decimals)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Amount has more than %d decimals", asset.Decimals), http.StatusBadRequest)
+		return
+	}
 
-	// Transfer funds
-	txHash, err := h.walletService.TransferFunds(r.Context(), h.bscClient, fromWalletID, toAddress, amountInt)
+	// Transfer funds, large amounts are proposed to the multisig treasury instead of being sent
+	transfer, err := h.treasury.Transfer(r.Context(), h.bscClient, entities.TreasuryTransferWithdrawal, fromWalletID, toAddress, amountInt, "api:wallet_transfer")
+	if errors.Is(err, usecases.ErrTokenHalted) || errors.Is(err, usecases.ErrWithdrawalsDisabled) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, usecases.ErrAddressBlacklisted) || errors.Is(err, usecases.ErrDestinationBlocked) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, usecases.ErrWithdrawalLimitExceeded) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	var simErr *usecases.SimulationError
+	if errors.As(err, &simErr) {
+		// Транзакция откатилась бы в сети: возвращаем причину вместо отправки
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "rejected",
+			"reason":  simErr.Reason,
+			"message": simErr.Message,
+		})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Error transferring funds", "error", err, "from_wallet", fromWalletID, "to", toAddress, "amount", amountParam)
-		http.Error(w, fmt.Sprintf("Failed to transfer funds: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to transfer funds: %v", err), errorStatus(err))
+		return
+	}
+
+	if transfer.Queued != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":        "queued",
+			"withdrawal_id": transfer.Queued.ID,
+			"message":       fmt.Sprintf("Transfer of %s %s to %s will be sent with the next withdrawal batch", amountParam, asset.Code, toAddress),
+		})
+		return
+	}
+
+	if transfer.Proposal != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "proposed",
+			"safe_tx_hash": transfer.Proposal.SafeTxHash,
+			"message":      fmt.Sprintf("Transfer of %s %s to %s requires multisig approval", amountParam, asset.Code, toAddress),
+		})
 		return
 	}
 
@@ -384,8 +599,9 @@ func (h *HTTPHandler) TransferFundsHandler(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":  "success",
-		"tx_hash": txHash,
-		"message": fmt.Sprintf("Successfully initiated transfer of %s USDT from wallet ID %d to %s", amountParam, fromWalletID, toAddress),
+		"tx_hash": transfer.TxHash,
+		"tx_url":  h.links.TxURL(string(asset.Chain), asset.Network, transfer.TxHash),
+		"message": fmt.Sprintf("Successfully initiated transfer of %s %s from wallet ID %d to %s", amountParam, asset.Code, fromWalletID, toAddress),
 	})
 }
 
@@ -408,29 +624,34 @@ func (h *HTTPHandler) CheckWalletBalance(w http.ResponseWriter, r *http.Request)
 	balance, err := walletService.GetWalletBalance(r.Context(), address)
 	if err != nil {
 		h.logger.Error("Failed to get wallet balance", "error", err, "address", address)
-		http.Error(w, fmt.Sprintf("Failed to get balance: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to get balance: %v", err), errorStatus(err))
 		return
 	}
 
 	// Конвертируем значения в читаемые строки для ответа
-	bnbFloat := usecases.WeiToEther(balance.NativeBalance)
-	tokenFloat := usecases.WeiToEther(balance.TokenBalance)
+	bnbAmount := usecases.WeiToEther(balance.NativeBalance)
+	tokenAmount := usecases.WeiToEther(balance.TokenBalance)
 
 	// Готовим ответ
+	fiat := h.fiatCurrency(r)
 	response := struct {
-		Address           string `json:"address"`
-		TokenBalanceWei   string `json:"token_balance_wei"`
-		TokenBalanceEther string `json:"token_balance_ether"`
-		BNBBalanceWei     string `json:"bnb_balance_wei"`
-		BNBBalanceEther   string `json:"bnb_balance_ether"`
-		Status            string `json:"status"`
-		LastChecked       string `json:"last_checked"`
+		Address           string              `json:"address"`
+		TokenBalanceWei   string              `json:"token_balance_wei"`
+		TokenBalanceEther string              `json:"token_balance_ether"`
+		TokenBalanceFiat  *entities.FiatValue `json:"token_balance_fiat,omitempty"`
+		BNBBalanceWei     string              `json:"bnb_balance_wei"`
+		BNBBalanceEther   string              `json:"bnb_balance_ether"`
+		BNBBalanceFiat    *entities.FiatValue `json:"bnb_balance_fiat,omitempty"`
+		Status            string              `json:"status"`
+		LastChecked       string              `json:"last_checked"`
 	}{
 		Address:           balance.Address,
 		TokenBalanceWei:   balance.TokenBalance.String(),
-		TokenBalanceEther: tokenFloat.Text('f', 18),
+		TokenBalanceEther: tokenAmount.StringFixed(18),
+		TokenBalanceFiat:  h.tokenBalanceValue(tokenAmount, fiat),
 		BNBBalanceWei:     balance.NativeBalance.String(),
-		BNBBalanceEther:   bnbFloat.Text('f', 18),
+		BNBBalanceEther:   bnbAmount.StringFixed(18),
+		BNBBalanceFiat:    h.nativeBalanceValue(bnbAmount, fiat),
 		Status:            string(balance.Status),
 		LastChecked:       balance.LastChecked.Format("2006-01-02 15:04:05"),
 	}
@@ -442,7 +663,7 @@ func (h *HTTPHandler) CheckWalletBalance(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// GetWalletBalancesHandler возвращает информацию о балансах всех отслеживаемых кошельков
+// GetWalletBalancesHandler возвращает балансы отслеживаемых кошельков пользователя
 func (h *HTTPHandler) GetWalletBalancesHandler(w http.ResponseWriter, r *http.Request) {
 	// Получаем user_id из query параметров
 	userIDStr := r.URL.Query().Get("user_id")
@@ -473,31 +694,10 @@ func (h *HTTPHandler) GetWalletBalancesHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Преобразуем big.Int значения в читаемые строки для JSON
-	type balanceInfo struct {
-		Address           string `json:"address"`
-		TokenBalanceWei   string `json:"token_balance_wei"`
-		TokenBalanceEther string `json:"token_balance_ether"`
-		BNBBalanceWei     string `json:"bnb_balance_wei"`
-		BNBBalanceEther   string `json:"bnb_balance_ether"`
-		Status            string `json:"status"`
-		LastChecked       string `json:"last_checked"`
-	}
-
-	result := make(map[string]balanceInfo)
+	fiat := h.fiatCurrency(r)
+	result := make(map[string]walletBalanceView)
 	for addr, balance := range balances {
-		bnbFloat := usecases.WeiToEther(balance.NativeBalance)
-		tokenFloat := usecases.WeiToEther(balance.TokenBalance)
-
-		result[addr] = balanceInfo{
-			Address:           balance.Address,
-			TokenBalanceWei:   balance.TokenBalance.String(),
-			TokenBalanceEther: tokenFloat.Text('f', 18),
-			BNBBalanceWei:     balance.NativeBalance.String(),
-			BNBBalanceEther:   bnbFloat.Text('f', 18),
-			Status:            string(balance.Status),
-			LastChecked:       balance.LastChecked.Format("2006-01-02 15:04:05"),
-		}
+		result[addr] = h.walletBalanceView(balance, fiat)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -508,6 +708,123 @@ func (h *HTTPHandler) GetWalletBalancesHandler(w http.ResponseWriter, r *http.Re
 	}
 }
 
+type walletBalanceQueryRequest struct {
+	WalletIDs []int    `json:"wallet_ids"`
+	Addresses []string `json:"addresses"`
+}
+
+type cachedWalletBalanceView struct {
+	WalletID int `json:"wallet_id"`
+	walletBalanceView
+}
+
+type walletBalanceQueryResponse struct {
+	Balances []cachedWalletBalanceView `json:"balances"`
+	Missing  struct {
+		WalletIDs []int    `json:"wallet_ids"`
+		Addresses []string `json:"addresses"`
+	} `json:"missing"`
+}
+
+// QueryWalletBalancesHandler returns cached balances of up to usecases.CachedBalanceQueryLimit wallets given by ID
+// or address in one response. The optional user_id query parameter limits the result to the user's wallets.
+func (h *HTTPHandler) QueryWalletBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	var userID int64
+	if userIDParam := r.URL.Query().Get("user_id"); userIDParam != "" {
+		var err error
+		userID, err = strconv.ParseInt(userIDParam, 10, 64)
+		if err != nil || userID <= 0 {
+			http.Error(w, "Invalid user_id format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var req walletBalanceQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	walletService, ok := h.walletService.(*usecases.WalletService)
+	if !ok {
+		http.Error(w, "WalletService implementation does not support balance monitoring", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := walletService.QueryCachedBalances(r.Context(), userID, req.WalletIDs, req.Addresses)
+	if errors.Is(err, usecases.ErrInvalidBalanceQuery) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to query wallet balances", "error", err)
+		http.Error(w, "Failed to query wallet balances", errorStatus(err))
+		return
+	}
+
+	fiat := h.fiatCurrency(r)
+	response := walletBalanceQueryResponse{Balances: make([]cachedWalletBalanceView, 0, len(result.Balances))}
+	for _, balance := range result.Balances {
+		response.Balances = append(response.Balances, cachedWalletBalanceView{
+			WalletID:          balance.WalletID,
+			walletBalanceView: h.walletBalanceView(&balance.WalletBalance, fiat),
+		})
+	}
+	response.Missing.WalletIDs = append([]int{}, result.MissingWalletIDs...)
+	response.Missing.Addresses = append([]string{}, result.MissingAddresses...)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode wallet balances", "error", err)
+	}
+}
+
+// walletBalanceView — баланс кошелька в ответах API: суммы в wei и единицах актива, оценка в фиате
+type walletBalanceView struct {
+	Address           string              `json:"address"`
+	TokenBalanceWei   string              `json:"token_balance_wei"`
+	TokenBalanceEther string              `json:"token_balance_ether"`
+	TokenBalanceFiat  *entities.FiatValue `json:"token_balance_fiat,omitempty"`
+	BNBBalanceWei     string              `json:"bnb_balance_wei"`
+	BNBBalanceEther   string              `json:"bnb_balance_ether"`
+	BNBBalanceFiat    *entities.FiatValue `json:"bnb_balance_fiat,omitempty"`
+	Status            string              `json:"status"`
+	LastChecked       string              `json:"last_checked"`
+}
+
+func (h *HTTPHandler) walletBalanceView(balance *entities.WalletBalance, fiat string) walletBalanceView {
+	bnbAmount := usecases.WeiToEther(balance.NativeBalance)
+	tokenAmount := usecases.WeiToEther(balance.TokenBalance)
+
+	return walletBalanceView{
+		Address:           balance.Address,
+		TokenBalanceWei:   balance.TokenBalance.String(),
+		TokenBalanceEther: tokenAmount.StringFixed(18),
+		TokenBalanceFiat:  h.tokenBalanceValue(tokenAmount, fiat),
+		BNBBalanceWei:     balance.NativeBalance.String(),
+		BNBBalanceEther:   bnbAmount.StringFixed(18),
+		BNBBalanceFiat:    h.nativeBalanceValue(bnbAmount, fiat),
+		Status:            string(balance.Status),
+		LastChecked:       balance.LastChecked.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// tokenBalanceValue оценивает баланс токена кошельков по текущему курсу
+func (h *HTTPHandler) tokenBalanceValue(amount decimal.Decimal, fiat string) *entities.FiatValue {
+	asset := h.walletService.Asset()
+	return h.prices.BalanceValue(asset.Chain, asset.Code, amount, fiat)
+}
+
+// nativeBalanceValue оценивает баланс нативной монеты сети кошельков по текущему курсу
+func (h *HTTPHandler) nativeBalanceValue(amount decimal.Decimal, fiat string) *entities.FiatValue {
+	chain := h.walletService.Asset().Chain
+	native, _, err := chain.NativeCoin()
+	if err != nil {
+		return nil
+	}
+	return h.prices.BalanceValue(chain, native, amount, fiat)
+}
+
 // DeleteOrderHandler handles requests to delete a pending order.
 func (h *HTTPHandler) DeleteOrderHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -573,7 +890,9 @@ func (h *HTTPHandler) DeleteWalletHandler(w http.ResponseWriter, r *http.Request
 		// Provide appropriate HTTP response based on the error type
 		// This requires the repository/service to return specific error types
 		// For now, using a generic error message, but consider refining this
-		if err.Error() == fmt.Sprintf("wallet %d not found or not deletable", walletID) { // Example check, replace with actual error handling
+		if errors.Is(err, usecases.ErrWalletInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else if err.Error() == fmt.Sprintf("wallet %d not found or not deletable", walletID) { // Example check, replace with actual error handling
 			http.Error(w, err.Error(), http.StatusNotFound) // Or StatusForbidden/StatusBadRequest depending on logic
 		} else {
 			http.Error(w, "Failed to delete wallet", http.StatusInternalServerError)
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type AssetRegistry interface {
+	List() []entities.Asset
+	UpdateAsset(ctx context.Context, id int, update entities.AssetUpdate, actor string) (*entities.Asset, error)
+}
+
+var _ AssetRegistry = (*usecases.AssetRegistry)(nil)
+
+// AssetHandler отдает реестр активов клиентам и позволяет администраторам менять лимиты и флаги
+type AssetHandler struct {
+	logger   *slog.Logger
+	registry AssetRegistry
+}
+
+func NewAssetHandler(logger *slog.Logger, registry AssetRegistry) *AssetHandler {
+	return &AssetHandler{
+		logger:   logger,
+		registry: registry,
+	}
+}
+
+func (h *AssetHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/assets", h.GetAssetsHandler).Methods("GET")
+}
+
+func (h *AssetHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/assets", h.GetAssetsHandler).Methods("GET")
+	admin.HandleFunc("/assets/{id:[0-9]+}", h.UpdateAssetHandler).Methods("POST")
+}
+
+func (h *AssetHandler) GetAssetsHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, h.logger, h.registry.List())
+}
+
+func (h *AssetHandler) UpdateAssetHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid asset ID format", http.StatusBadRequest)
+		return
+	}
+
+	var update entities.AssetUpdate
+	if err = json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.registry.UpdateAsset(r.Context(), id, update, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, asset)
+}
+
+func (h *AssetHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrAssetNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidAssetUpdate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Asset request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type BalanceService interface {
+	GetUserBalances(ctx context.Context, userID int64) ([]entities.UserBalance, error)
+}
+
+var _ BalanceService = (*usecases.BalanceService)(nil)
+
+// BalancesHandler отдает доступный, ожидающий и удержанный баланс пользователя по активам
+type BalancesHandler struct {
+	logger  *slog.Logger
+	service BalanceService
+}
+
+func NewBalancesHandler(logger *slog.Logger, service BalanceService) *BalancesHandler {
+	return &BalancesHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *BalancesHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/users/me/balances", h.GetBalancesHandler).Methods("GET")
+}
+
+func (h *BalancesHandler) GetBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	balances, err := h.service.GetUserBalances(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get user balances", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(balances); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type BNBDustService interface {
+	GetReport(ctx context.Context) (*entities.BNBDustReport, error)
+	ConsolidateAll(ctx context.Context) (*entities.BNBDustRun, error)
+}
+
+var _ BNBDustService = (*usecases.BNBDustService)(nil)
+
+// BNBDustHandler показывает администраторам остатки BNB на депозитных кошельках и запускает их консолидацию
+type BNBDustHandler struct {
+	logger    *slog.Logger
+	service   BNBDustService
+	twoFactor *TwoFactorHandler
+}
+
+func NewBNBDustHandler(logger *slog.Logger, service BNBDustService, twoFactor *TwoFactorHandler) *BNBDustHandler {
+	return &BNBDustHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *BNBDustHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/sweeps/bnb-dust", h.GetReportHandler).Methods("GET")
+	admin.HandleFunc("/sweeps/bnb-dust/consolidate", h.twoFactor.RequireAdminSecondFactor(OperationDustConsolidation, h.ConsolidateHandler)).Methods("POST")
+}
+
+func (h *BNBDustHandler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetReport(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get BNB dust report", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	writeJSON(w, h.logger, report)
+}
+
+// ConsolidateHandler runs a consolidation pass right away and returns its outcome
+func (h *BNBDustHandler) ConsolidateHandler(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.ConsolidateAll(r.Context())
+	switch {
+	case errors.Is(err, usecases.ErrBNBDustDisabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "BNB dust consolidation failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "BNB dust consolidation requested", "actor", adminActor(r), "consolidated", run.Consolidated)
+	writeJSON(w, h.logger, run)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientIPKey — ключ контекста с адресом клиента, определённым ProxyTrust
+type clientIPKey struct{}
+
+// ProxyTrust определяет адрес клиента. Заголовки X-Forwarded-For и X-Real-IP принимаются
+// только от доверенных прокси, иначе любой клиент мог бы подставить чужой адрес.
+type ProxyTrust struct {
+	trusted []*net.IPNet
+}
+
+// NewProxyTrust creates the resolver. An empty CIDR list trusts no proxy and always uses the connection address.
+func NewProxyTrust(cidrs []string) (*ProxyTrust, error) {
+	trusted, err := parseCIDRs(cidrs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+	}
+	return &ProxyTrust{trusted: trusted}, nil
+}
+
+// Middleware resolves the client address once and stores it in the request context
+func (p *ProxyTrust) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientIPKey{}, p.ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientIP returns the address of the client. When the connection comes from a trusted proxy,
+// X-Forwarded-For is read from right to left and the first address that is not a trusted proxy wins:
+// the entries on the left are supplied by the client and can be forged.
+func (p *ProxyTrust) ClientIP(r *http.Request) string {
+	remote := remoteHost(r)
+	if !p.isTrusted(remote) {
+		return remote
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				// Испорченная цепочка: левее этой записи ничему верить нельзя
+				break
+			}
+			client = hop
+			if !p.isTrusted(hop) {
+				break
+			}
+		}
+		return client
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remote
+}
+
+func (p *ProxyTrust) isTrusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the client address resolved by ProxyTrust, or the connection address
+// when the middleware is not installed
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// parseCIDRs parses a list of subnets, skipping empty entries
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
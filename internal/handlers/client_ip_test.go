@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyTrustClientIP(t *testing.T) {
+	trust, err := NewProxyTrust([]string{"10.0.0.0/8", " ", "192.168.1.1/32"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		{name: "direct client", remote: "203.0.113.7:5123", want: "203.0.113.7"},
+		{name: "untrusted peer forges headers", remote: "203.0.113.7:5123", forwarded: []string{"1.1.1.1"}, realIP: "2.2.2.2", want: "203.0.113.7"},
+		{name: "trusted proxy", remote: "10.0.0.2:80", forwarded: []string{"198.51.100.4"}, want: "198.51.100.4"},
+		{name: "client prepends forged hop", remote: "10.0.0.2:80", forwarded: []string{"1.1.1.1, 198.51.100.4"}, want: "198.51.100.4"},
+		{name: "proxy chain", remote: "10.0.0.2:80", forwarded: []string{"198.51.100.4, 192.168.1.1", "10.0.0.9"}, want: "198.51.100.4"},
+		{name: "only trusted hops", remote: "10.0.0.2:80", forwarded: []string{"10.0.0.5"}, want: "10.0.0.5"},
+		{name: "malformed hop", remote: "10.0.0.2:80", forwarded: []string{"198.51.100.4, garbage"}, want: "10.0.0.2"},
+		{name: "real ip from trusted proxy", remote: "10.0.0.2:80", realIP: "198.51.100.4", want: "198.51.100.4"},
+		{name: "invalid real ip", remote: "10.0.0.2:80", realIP: "unknown", want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			assert.Equal(t, tt.want, trust.ClientIP(r))
+		})
+	}
+}
+
+func TestProxyTrustMiddleware(t *testing.T) {
+	trust, err := NewProxyTrust(nil)
+	require.NoError(t, err)
+
+	var got string
+	handler := trust.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = clientIP(r)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:5123"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "203.0.113.7", got)
+}
+
+func TestNewProxyTrustInvalidCIDR(t *testing.T) {
+	_, err := NewProxyTrust([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
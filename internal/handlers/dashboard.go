@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type DashboardService interface {
+	GetOverview(ctx context.Context, from, to time.Time) (*entities.DashboardOverview, error)
+	Rebuild(ctx context.Context) ([]entities.DashboardProjectionCursor, error)
+	GetDepositStats(ctx context.Context, granularity entities.DepositStatsGranularity, from, to time.Time, asset, fiat string) (*entities.DepositStats, error)
+}
+
+var _ DashboardService = (*usecases.DashboardService)(nil)
+
+// DashboardHandler отдает сводку дашборда из проекций и позволяет пересобрать их
+type DashboardHandler struct {
+	logger  *slog.Logger
+	service DashboardService
+}
+
+func NewDashboardHandler(logger *slog.Logger, service DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DashboardHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/dashboard", h.GetOverviewHandler).Methods("GET")
+	admin.HandleFunc("/dashboard/deposits", h.GetDepositStatsHandler).Methods("GET")
+	admin.HandleFunc("/dashboard/rebuild", h.RebuildHandler).Methods("POST")
+}
+
+// GetOverviewHandler accepts from and to as RFC 3339 timestamps or dates (to is exclusive), days are UTC
+func (h *DashboardHandler) GetOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseReportRange(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	overview, err := h.service.GetOverview(r.Context(), from, to)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, overview)
+}
+
+// GetDepositStatsHandler returns deposit statistics per asset for granularity=hour|day (day by default)
+// over from and to as in GetOverviewHandler, optionally of one asset code and valued in fiat
+func (h *DashboardHandler) GetDepositStatsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to, ok := parseReportRange(w, query)
+	if !ok {
+		return
+	}
+	granularity := entities.DepositStatsDaily
+	if value := query.Get("granularity"); value != "" {
+		granularity = entities.DepositStatsGranularity(value)
+	}
+
+	stats, err := h.service.GetDepositStats(r.Context(), granularity, from, to, query.Get("asset"), query.Get("fiat"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, stats)
+}
+
+// RebuildHandler recomputes the projections from scratch and returns the new cursors
+func (h *DashboardHandler) RebuildHandler(w http.ResponseWriter, r *http.Request) {
+	cursors, err := h.service.Rebuild(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Dashboard projections rebuilt on request", "actor", adminActor(r))
+	writeJSON(w, h.logger, cursors)
+}
+
+func (h *DashboardHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidReportRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Dashboard request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type DepositEvidenceService interface {
+	GetEvidence(ctx context.Context, txHash string) (*entities.DepositEvidenceView, error)
+	GetEvidenceArchive(ctx context.Context, txHash string) (*entities.DepositEvidence, error)
+}
+
+var _ DepositEvidenceService = (*usecases.DepositEvidenceService)(nil)
+
+// DepositEvidenceHandler отдает compliance архивные ончейн-доказательства зачисленных депозитов
+type DepositEvidenceHandler struct {
+	logger  *slog.Logger
+	service DepositEvidenceService
+}
+
+func NewDepositEvidenceHandler(logger *slog.Logger, service DepositEvidenceService) *DepositEvidenceHandler {
+	return &DepositEvidenceHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DepositEvidenceHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/transactions/{txHash}/evidence", h.GetEvidenceHandler).Methods("GET")
+}
+
+// GetEvidenceHandler returns the verified evidence as JSON, ?format=gzip downloads the archived bundle as stored
+// with its checksum in X-Evidence-SHA256
+func (h *DepositEvidenceHandler) GetEvidenceHandler(w http.ResponseWriter, r *http.Request) {
+	txHash := mux.Vars(r)["txHash"]
+
+	if r.URL.Query().Get("format") == "gzip" {
+		evidence, err := h.service.GetEvidenceArchive(r.Context(), txHash)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="evidence_%s.json.gz"`, evidence.TxHash))
+		w.Header().Set("X-Evidence-SHA256", *evidence.SHA256)
+		if _, err = w.Write(evidence.Bundle); err != nil {
+			h.logger.Error("Failed to write evidence archive", "error", err)
+		}
+		return
+	}
+
+	evidence, err := h.service.GetEvidence(r.Context(), txHash)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, evidence)
+}
+
+func (h *DepositEvidenceHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrDepositEvidenceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrDepositEvidenceCorrupted):
+		h.logger.ErrorContext(r.Context(), "Corrupted deposit evidence requested", "error", err, "actor", adminActor(r))
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Deposit evidence request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
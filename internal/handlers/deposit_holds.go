@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type DepositHoldsService interface {
+	GetHolds(ctx context.Context) ([]entities.DepositHold, error)
+	ReleaseHold(ctx context.Context, txHash, actor string) error
+}
+
+var _ DepositHoldsService = (*usecases.DepositHoldService)(nil)
+
+// DepositHoldsHandler показывает комплаенсу удержанные крупные депозиты и освобождает их для зачисления
+type DepositHoldsHandler struct {
+	logger  *slog.Logger
+	service DepositHoldsService
+}
+
+func NewDepositHoldsHandler(logger *slog.Logger, service DepositHoldsService) *DepositHoldsHandler {
+	return &DepositHoldsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DepositHoldsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/deposits/holds", h.GetHoldsHandler).Methods("GET")
+	admin.HandleFunc("/deposits/{txHash}/release", h.ReleaseHoldHandler).Methods("POST")
+}
+
+func (h *DepositHoldsHandler) GetHoldsHandler(w http.ResponseWriter, r *http.Request) {
+	holds, err := h.service.GetHolds(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, holds)
+}
+
+func (h *DepositHoldsHandler) ReleaseHoldHandler(w http.ResponseWriter, r *http.Request) {
+	txHash := mux.Vars(r)["txHash"]
+
+	if err := h.service.ReleaseHold(r.Context(), txHash, adminActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, map[string]string{"status": "released", "tx_hash": txHash})
+}
+
+func (h *DepositHoldsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrDepositHoldNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		h.logger.ErrorContext(r.Context(), "Deposit hold request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
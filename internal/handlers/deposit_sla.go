@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type DepositSLAService interface {
+	GetReport(ctx context.Context) (*entities.DepositSLAReport, error)
+}
+
+var _ DepositSLAService = (*usecases.DepositSLAService)(nil)
+
+// DepositSLAHandler показывает администраторам задержки подтверждения депозитов по сетям и просроченные депозиты
+type DepositSLAHandler struct {
+	logger  *slog.Logger
+	service DepositSLAService
+}
+
+func NewDepositSLAHandler(logger *slog.Logger, service DepositSLAService) *DepositSLAHandler {
+	return &DepositSLAHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DepositSLAHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/deposits/sla", h.GetReportHandler).Methods("GET")
+}
+
+func (h *DepositSLAHandler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetReport(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to build deposit SLA report", "error", err)
+		http.Error(w, "Failed to build deposit SLA report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
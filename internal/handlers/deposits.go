@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type PendingDepositsService interface {
+	GetPendingDeposits(ctx context.Context, userID int64) []entities.MempoolDeposit
+}
+
+var _ PendingDepositsService = (*usecases.MempoolDepositService)(nil)
+
+// DepositHandler отдает предварительные депозиты из мемпула. Они не зачислены и могут не попасть в блок.
+type DepositHandler struct {
+	logger  *slog.Logger
+	service PendingDepositsService
+}
+
+func NewDepositHandler(logger *slog.Logger, service PendingDepositsService) *DepositHandler {
+	return &DepositHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DepositHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/wallet/pending_deposits", h.GetPendingDepositsHandler).Methods("GET")
+}
+
+func (h *DepositHandler) GetPendingDepositsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	deposits := h.service.GetPendingDeposits(r.Context(), userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deposits); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
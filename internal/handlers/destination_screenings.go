@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type DestinationScreeningService interface {
+	GetScreenings(ctx context.Context, verdict entities.ScreeningVerdict) ([]entities.DestinationScreening, error)
+}
+
+var _ DestinationScreeningService = (*usecases.DestinationScreeningService)(nil)
+
+// DestinationScreeningsHandler показывает комплаенсу AML проверки адресов получателей выводов и выплат
+type DestinationScreeningsHandler struct {
+	logger  *slog.Logger
+	service DestinationScreeningService
+}
+
+func NewDestinationScreeningsHandler(logger *slog.Logger, service DestinationScreeningService) *DestinationScreeningsHandler {
+	return &DestinationScreeningsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DestinationScreeningsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/treasury/screenings", h.GetScreeningsHandler).Methods("GET")
+}
+
+// GetScreeningsHandler returns the latest screenings, filtered by the verdict query parameter
+func (h *DestinationScreeningsHandler) GetScreeningsHandler(w http.ResponseWriter, r *http.Request) {
+	verdict := entities.ScreeningVerdict(r.URL.Query().Get("verdict"))
+
+	screenings, err := h.service.GetScreenings(r.Context(), verdict)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, screenings)
+}
+
+func (h *DestinationScreeningsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidScreeningVerdict):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Destination screenings request failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
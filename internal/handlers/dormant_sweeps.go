@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// dormantReportDefaultRange — период отчета, если from не указан
+const dormantReportDefaultRange = 30 * 24 * time.Hour
+
+type DormantSweepsService interface {
+	GetReport(ctx context.Context, since time.Time) (*entities.DormantRecoveryReport, error)
+}
+
+var _ DormantSweepsService = (*usecases.DormantSweepService)(nil)
+
+// DormantSweepsHandler отдает администраторам отчет о средствах, собранных с неактивных кошельков
+type DormantSweepsHandler struct {
+	logger  *slog.Logger
+	service DormantSweepsService
+}
+
+func NewDormantSweepsHandler(logger *slog.Logger, service DormantSweepsService) *DormantSweepsHandler {
+	return &DormantSweepsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *DormantSweepsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/sweeps/dormant", h.GetReportHandler).Methods("GET")
+}
+
+// GetReportHandler accepts from as an RFC 3339 timestamp or a date, the last 30 days by default
+func (h *DormantSweepsHandler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	since := time.Now().UTC().Add(-dormantReportDefaultRange)
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := parseReportTime(value)
+		if err != nil {
+			http.Error(w, "Invalid from parameter", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	report, err := h.service.GetReport(r.Context(), since)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get dormant sweep report", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, report)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+const defaultFeeFiatCurrency = "USD"
+
+type FeeEstimateService interface {
+	EstimateTransfer(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress, amount, fiat string) (*entities.FeeEstimate, error)
+}
+
+var _ FeeEstimateService = (*usecases.FeeEstimateService)(nil)
+
+// FeeHandler отдает оценку комиссии перевода, чтобы клиент показал стоимость до подтверждения
+type FeeHandler struct {
+	logger    *slog.Logger
+	service   FeeEstimateService
+	bscClient *ethclient.Client
+}
+
+func NewFeeHandler(logger *slog.Logger, bscClient *ethclient.Client, service FeeEstimateService) *FeeHandler {
+	return &FeeHandler{
+		logger:    logger,
+		service:   service,
+		bscClient: bscClient,
+	}
+}
+
+func (h *FeeHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/wallet/transfer/estimate", h.EstimateTransferHandler).Methods("GET")
+}
+
+// EstimateTransferHandler accepts the same parameters as /wallet/transfer and an optional fiat currency
+func (h *FeeHandler) EstimateTransferHandler(w http.ResponseWriter, r *http.Request) {
+	fromWalletIDParam := r.URL.Query().Get("wallet_id")
+	toAddress := r.URL.Query().Get("to_address")
+	amount := r.URL.Query().Get("amount")
+
+	if fromWalletIDParam == "" || toAddress == "" || amount == "" {
+		http.Error(w, "Missing required parameters: wallet_id, to_address, or amount", http.StatusBadRequest)
+		return
+	}
+
+	fromWalletID, err := strconv.Atoi(fromWalletIDParam)
+	if err != nil {
+		http.Error(w, "Invalid wallet ID format", http.StatusBadRequest)
+		return
+	}
+
+	fiat := r.URL.Query().Get("fiat")
+	if fiat == "" {
+		fiat = defaultFeeFiatCurrency
+	}
+
+	estimate, err := h.service.EstimateTransfer(r.Context(), h.bscClient, fromWalletID, toAddress, amount, fiat)
+	if errors.Is(err, usecases.ErrInvalidFeeEstimateRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to estimate transfer fee", "error", err, "wallet_id", fromWalletID)
+		http.Error(w, "Failed to estimate transfer fee", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(estimate); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
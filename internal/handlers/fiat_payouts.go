@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type FiatPayoutService interface {
+	Methods() []entities.FiatPayoutMethod
+	RequestPayout(ctx context.Context, userID int64, req usecases.FiatPayoutRequest) (*entities.FiatPayout, error)
+	GetUserPayouts(ctx context.Context, userID int64) ([]entities.FiatPayout, error)
+	GetPayout(ctx context.Context, userID int64, id string) (*entities.FiatPayout, error)
+	GetPayouts(ctx context.Context, status entities.FiatPayoutStatus) ([]entities.FiatPayout, error)
+	GetPostings(ctx context.Context, id string) ([]entities.FiatLedgerPosting, error)
+	ResolveReview(ctx context.Context, id string, completed bool, reason, actor string) (*entities.FiatPayout, error)
+}
+
+var _ FiatPayoutService = (*usecases.FiatPayoutService)(nil)
+
+// FiatPayoutHandler принимает от продавцов запросы на фиатные выплаты, администраторам отдает выплаты и проводки
+type FiatPayoutHandler struct {
+	logger    *slog.Logger
+	service   FiatPayoutService
+	twoFactor *TwoFactorHandler
+}
+
+func NewFiatPayoutHandler(logger *slog.Logger, service FiatPayoutService, twoFactor *TwoFactorHandler) *FiatPayoutHandler {
+	return &FiatPayoutHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *FiatPayoutHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/payouts/fiat/methods", h.GetMethodsHandler).Methods("GET")
+	router.HandleFunc("/payouts/fiat", h.GetUserPayoutsHandler).Methods("GET")
+	// Реквизиты получателя определяют, куда уйдут деньги, поэтому выплата требует второго фактора
+	router.HandleFunc("/payouts/fiat", h.twoFactor.RequireSecondFactor(OperationFiatPayout, RequestingUser, h.RequestPayoutHandler)).Methods("POST")
+	router.HandleFunc("/payouts/fiat/{id}", h.GetUserPayoutHandler).Methods("GET")
+}
+
+func (h *FiatPayoutHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/payouts/fiat", h.GetPayoutsHandler).Methods("GET")
+	admin.HandleFunc("/payouts/fiat/{id}", h.GetPayoutHandler).Methods("GET")
+	admin.HandleFunc("/payouts/fiat/{id}/postings", h.GetPostingsHandler).Methods("GET")
+	admin.HandleFunc("/payouts/fiat/{id}/resolve", h.ResolveReviewHandler).Methods("POST")
+}
+
+type fiatPayoutRequest struct {
+	OrderID     int                            `json:"order_id"`
+	Method      entities.FiatPayoutMethod      `json:"method"`
+	Currency    string                         `json:"currency"`
+	Destination entities.FiatPayoutDestination `json:"destination"`
+}
+
+func (h *FiatPayoutHandler) GetMethodsHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, h.logger, h.service.Methods())
+}
+
+func (h *FiatPayoutHandler) RequestPayoutHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req fiatPayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	payout, err := h.service.RequestPayout(r.Context(), userID, usecases.FiatPayoutRequest{
+		OrderID:     req.OrderID,
+		Method:      req.Method,
+		Currency:    req.Currency,
+		Destination: req.Destination,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, payout)
+}
+
+func (h *FiatPayoutHandler) GetUserPayoutsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	payouts, err := h.service.GetUserPayouts(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, payouts)
+}
+
+func (h *FiatPayoutHandler) GetUserPayoutHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	payout, err := h.service.GetPayout(r.Context(), userID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, payout)
+}
+
+func (h *FiatPayoutHandler) GetPayoutsHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.FiatPayoutStatus(r.URL.Query().Get("status"))
+
+	payouts, err := h.service.GetPayouts(r.Context(), status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, payouts)
+}
+
+func (h *FiatPayoutHandler) GetPayoutHandler(w http.ResponseWriter, r *http.Request) {
+	payout, err := h.service.GetPayout(r.Context(), 0, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, payout)
+}
+
+func (h *FiatPayoutHandler) GetPostingsHandler(w http.ResponseWriter, r *http.Request) {
+	postings, err := h.service.GetPostings(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, postings)
+}
+
+type resolveFiatPayoutRequest struct {
+	// true — провайдер исполнил выплату, false — выплата до провайдера не дошла
+	Completed bool   `json:"completed"`
+	Reason    string `json:"reason"`
+}
+
+// ResolveReviewHandler finishes a payout in manual review after the operator checked it with the provider
+func (h *FiatPayoutHandler) ResolveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req resolveFiatPayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	payout, err := h.service.ResolveReview(r.Context(), mux.Vars(r)["id"], req.Completed, req.Reason, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, payout)
+}
+
+func (h *FiatPayoutHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrFiatPayoutNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidFiatPayoutRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrOrderNotPayable),
+		errors.Is(err, usecases.ErrFiatPayoutNotInReview):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecases.ErrRateUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		h.logger.ErrorContext(r.Context(), "Fiat payout request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
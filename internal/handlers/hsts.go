@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HSTS adds the Strict-Transport-Security header to every response served over TLS
+func HSTS(maxAge int, includeSubdomains bool, next http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d", maxAge)
+	if includeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
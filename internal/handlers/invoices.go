@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type InvoiceService interface {
+	CreateInvoice(ctx context.Context, merchantID int64, req usecases.CreateInvoiceRequest) (*entities.Invoice, error)
+	GetMerchantInvoices(ctx context.Context, merchantID int64) ([]entities.Invoice, error)
+	GetStatusPage(ctx context.Context, token string) (*entities.InvoiceStatusPage, error)
+	SelectAsset(ctx context.Context, token, asset string) (*entities.InvoiceStatusPage, error)
+}
+
+var _ InvoiceService = (*usecases.InvoiceService)(nil)
+
+type InvoiceHandler struct {
+	logger  *slog.Logger
+	service InvoiceService
+}
+
+func NewInvoiceHandler(logger *slog.Logger, service InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *InvoiceHandler) RegisterRoutes(router *mux.Router) {
+	// Merchant endpoints
+	router.HandleFunc("/invoices", h.CreateInvoiceHandler).Methods("POST")
+	router.HandleFunc("/invoices", h.GetMerchantInvoicesHandler).Methods("GET")
+
+	// Public status page endpoints, accessible by the status token only
+	router.HandleFunc("/public/invoices/{token}", h.GetInvoiceStatusHandler).Methods("GET")
+	router.HandleFunc("/public/invoices/{token}/select", h.SelectAssetHandler).Methods("POST")
+}
+
+type createInvoiceRequest struct {
+	FiatAmount     string   `json:"fiat_amount"`
+	FiatCurrency   string   `json:"fiat_currency"`
+	AcceptedAssets []string `json:"accepted_assets"`
+	Description    string   `json:"description"`
+	ExpiresIn      int      `json:"expires_in"` // minutes
+}
+
+func (h *InvoiceHandler) CreateInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req createInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.service.CreateInvoice(r.Context(), merchantID, usecases.CreateInvoiceRequest{
+		FiatAmount:     req.FiatAmount,
+		FiatCurrency:   req.FiatCurrency,
+		AcceptedAssets: req.AcceptedAssets,
+		Description:    req.Description,
+		ExpiresIn:      time.Duration(req.ExpiresIn) * time.Minute,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, invoice)
+}
+
+func (h *InvoiceHandler) GetMerchantInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	invoices, err := h.service.GetMerchantInvoices(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, invoices)
+}
+
+func (h *InvoiceHandler) GetInvoiceStatusHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := h.service.GetStatusPage(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, page)
+}
+
+func (h *InvoiceHandler) SelectAssetHandler(w http.ResponseWriter, r *http.Request) {
+	asset := r.URL.Query().Get("asset")
+	if asset == "" {
+		http.Error(w, "Missing required parameters: asset", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.SelectAsset(r.Context(), mux.Vars(r)["token"], asset)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, page)
+}
+
+func (h *InvoiceHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvoiceNotFound):
+		http.Error(w, "Invoice not found", http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvoiceExpired):
+		http.Error(w, "Invoice expired", http.StatusGone)
+	case errors.Is(err, usecases.ErrInvoiceAssetSelected):
+		http.Error(w, "Invoice asset already selected", http.StatusConflict)
+	case errors.Is(err, usecases.ErrInvalidInvoiceRequest),
+		errors.Is(err, usecases.ErrAssetNotAccepted),
+		errors.Is(err, usecases.ErrAssetNotSupported),
+		errors.Is(err, usecases.ErrOrderAmountOutOfRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrRateUnavailable),
+		errors.Is(err, usecases.ErrDepositsDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		h.logger.ErrorContext(r.Context(), "Invoice request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type KeyRotationService interface {
+	StartRotation(ctx context.Context, toVersion int, expected []string, actor string) (*entities.KeyRotation, error)
+	Activate(ctx context.Context, id int, actor string) (*entities.KeyRotation, error)
+	ScheduleSweeps(ctx context.Context, id int, actor string) (*entities.KeyRotation, error)
+	GetRotation(ctx context.Context, id int) (*entities.KeyRotation, error)
+	GetRotations(ctx context.Context) ([]entities.KeyRotation, error)
+	GetSweeps(ctx context.Context, id int, status entities.KeyRotationSweepStatus) ([]entities.KeyRotationSweep, error)
+}
+
+var _ KeyRotationService = (*usecases.KeyRotationService)(nil)
+
+// KeyRotationsHandler ведет ротацию мастер-сида: сверка, активация версии и свипы старых кошельков.
+// Церемония нового сида выполняется офлайн (cmd/keyceremony), сид не проходит через сервер.
+type KeyRotationsHandler struct {
+	logger    *slog.Logger
+	service   KeyRotationService
+	twoFactor *TwoFactorHandler
+}
+
+func NewKeyRotationsHandler(logger *slog.Logger, service KeyRotationService, twoFactor *TwoFactorHandler) *KeyRotationsHandler {
+	return &KeyRotationsHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *KeyRotationsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/key_rotations", h.GetRotationsHandler).Methods("GET")
+	admin.HandleFunc("/key_rotations", h.StartRotationHandler).Methods("POST")
+	admin.HandleFunc("/key_rotations/{id:[0-9]+}", h.GetRotationHandler).Methods("GET")
+	admin.HandleFunc("/key_rotations/{id:[0-9]+}/activate", h.twoFactor.RequireAdminSecondFactor(OperationKeyRotation, h.ActivateHandler)).Methods("POST")
+	admin.HandleFunc("/key_rotations/{id:[0-9]+}/sweeps", h.twoFactor.RequireAdminSecondFactor(OperationKeyRotation, h.ScheduleSweepsHandler)).Methods("POST")
+	admin.HandleFunc("/key_rotations/{id:[0-9]+}/sweeps", h.GetSweepsHandler).Methods("GET")
+}
+
+type startKeyRotationRequest struct {
+	ToVersion       int      `json:"to_version"`
+	SampleAddresses []string `json:"sample_addresses"`
+}
+
+func (h *KeyRotationsHandler) StartRotationHandler(w http.ResponseWriter, r *http.Request) {
+	var req startKeyRotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rotation, err := h.service.StartRotation(r.Context(), req.ToVersion, req.SampleAddresses, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, rotation)
+}
+
+func (h *KeyRotationsHandler) GetRotationsHandler(w http.ResponseWriter, r *http.Request) {
+	rotations, err := h.service.GetRotations(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, rotations)
+}
+
+func (h *KeyRotationsHandler) GetRotationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRotationID(w, r)
+	if !ok {
+		return
+	}
+
+	rotation, err := h.service.GetRotation(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, rotation)
+}
+
+func (h *KeyRotationsHandler) ActivateHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRotationID(w, r)
+	if !ok {
+		return
+	}
+
+	rotation, err := h.service.Activate(r.Context(), id, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, rotation)
+}
+
+func (h *KeyRotationsHandler) ScheduleSweepsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRotationID(w, r)
+	if !ok {
+		return
+	}
+
+	rotation, err := h.service.ScheduleSweeps(r.Context(), id, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, h.logger, rotation)
+}
+
+// GetSweepsHandler returns the sweeps of the rotation, filtered by the status query parameter
+func (h *KeyRotationsHandler) GetSweepsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRotationID(w, r)
+	if !ok {
+		return
+	}
+
+	sweeps, err := h.service.GetSweeps(r.Context(), id, entities.KeyRotationSweepStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, sweeps)
+}
+
+func parseRotationID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid rotation ID format", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *KeyRotationsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrKeyRotationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrKeyVersionNotConfigured),
+		errors.Is(err, usecases.ErrKeySampleMismatch):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, usecases.ErrKeyRotationInProgress),
+		errors.Is(err, usecases.ErrKeyRotationState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Key rotation request failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type MerchantDepositService interface {
+	GetSettings(ctx context.Context, merchantID int64) (*entities.MerchantDepositSettings, error)
+	GetOmnibusMerchants(ctx context.Context) ([]entities.MerchantDepositSettings, error)
+	SetSettings(ctx context.Context, merchantID int64, mode entities.DepositMode, attribution entities.DepositAttribution, actor string) (*entities.MerchantDepositSettings, error)
+}
+
+var _ MerchantDepositService = (*usecases.OmnibusDepositService)(nil)
+
+// MerchantDepositsHandler показывает мерчанту режим приема депозитов, администраторы переключают мерчантов
+// между отдельными кошельками и общим омнибус кошельком
+type MerchantDepositsHandler struct {
+	logger  *slog.Logger
+	service MerchantDepositService
+}
+
+func NewMerchantDepositsHandler(logger *slog.Logger, service MerchantDepositService) *MerchantDepositsHandler {
+	return &MerchantDepositsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *MerchantDepositsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/deposit_settings", h.GetSettingsHandler).Methods("GET")
+}
+
+func (h *MerchantDepositsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/merchants/omnibus", h.GetOmnibusMerchantsHandler).Methods("GET")
+	admin.HandleFunc("/merchants/{merchantId:[0-9]+}/deposit_settings", h.GetMerchantSettingsHandler).Methods("GET")
+	admin.HandleFunc("/merchants/{merchantId:[0-9]+}/deposit_settings", h.SetMerchantSettingsHandler).Methods("PUT")
+}
+
+type setDepositSettingsRequest struct {
+	Mode        entities.DepositMode        `json:"mode"`
+	Attribution entities.DepositAttribution `json:"attribution"`
+}
+
+func (h *MerchantDepositsHandler) GetSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, settings)
+}
+
+func (h *MerchantDepositsHandler) GetOmnibusMerchantsHandler(w http.ResponseWriter, r *http.Request) {
+	merchants, err := h.service.GetOmnibusMerchants(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, merchants)
+}
+
+func (h *MerchantDepositsHandler) GetMerchantSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.ParseInt(mux.Vars(r)["merchantId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid merchant ID format", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, settings)
+}
+
+func (h *MerchantDepositsHandler) SetMerchantSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.ParseInt(mux.Vars(r)["merchantId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid merchant ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req setDepositSettingsRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.service.SetSettings(r.Context(), merchantID, req.Mode, req.Attribution, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, settings)
+}
+
+func (h *MerchantDepositsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidDepositSettings):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrOmnibusDisabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Merchant deposit settings request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
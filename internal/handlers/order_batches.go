@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type OrderBatchService interface {
+	CreateOrders(ctx context.Context, userID int64, items []entities.OrderBatchItem) ([]entities.OrderBatchResult, error)
+}
+
+var _ OrderBatchService = (*usecases.OrderBatchService)(nil)
+
+// OrderBatchHandler создает пакет ордеров мерчанта, например при импорте счетов
+type OrderBatchHandler struct {
+	logger     *slog.Logger
+	service    OrderBatchService
+	abuseGuard AbuseGuard
+}
+
+func NewOrderBatchHandler(logger *slog.Logger, service OrderBatchService, abuseGuard AbuseGuard) *OrderBatchHandler {
+	return &OrderBatchHandler{
+		logger:     logger,
+		service:    service,
+		abuseGuard: abuseGuard,
+	}
+}
+
+func (h *OrderBatchHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/orders/batch", throttle(h.logger, h.abuseGuard, usecases.AbuseActionBatchOrderCreation, h.CreateOrdersHandler)).Methods("POST")
+}
+
+type createOrderBatchRequest struct {
+	Orders []entities.OrderBatchItem `json:"orders"`
+}
+
+func (h *OrderBatchHandler) CreateOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req createOrderBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.service.CreateOrders(r.Context(), userID, req.Orders)
+	if errors.Is(err, usecases.ErrInvalidOrderBatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create order batch", "error", err, "user_id", userID)
+		http.Error(w, "Failed to create orders", errorStatus(err))
+		return
+	}
+
+	created, failed := 0, 0
+	for _, result := range results {
+		if result.Status == entities.OrderBatchItemCreated {
+			created++
+		} else {
+			failed++
+		}
+	}
+
+	// Частичный успех: 207, результат каждой позиции в теле ответа
+	status := http.StatusCreated
+	if failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"created": created,
+		"failed":  failed,
+		"results": results,
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type OrderCancellationService interface {
+	CancelOrder(ctx context.Context, userID int64, orderID int, reason entities.OrderCancelReason) (*entities.OrderCancellation, error)
+}
+
+var _ OrderCancellationService = (*usecases.OrderCancellationService)(nil)
+
+// OrderCancellationHandler отменяет неоплаченные ордера пользователя с указанием причины
+type OrderCancellationHandler struct {
+	logger  *slog.Logger
+	service OrderCancellationService
+}
+
+func NewOrderCancellationHandler(logger *slog.Logger, service OrderCancellationService) *OrderCancellationHandler {
+	return &OrderCancellationHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *OrderCancellationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/orders/{orderId:[0-9]+}/cancel", h.CancelOrderHandler).Methods("POST")
+}
+
+type orderCancelRequest struct {
+	Reason entities.OrderCancelReason `json:"reason"`
+}
+
+// CancelOrderHandler cancels a pending order of the user. Unlike DELETE, the order is kept with the reason code.
+func (h *OrderCancellationHandler) CancelOrderHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	orderID, err := strconv.Atoi(mux.Vars(r)["orderId"])
+	if err != nil {
+		http.Error(w, "Invalid order ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req orderCancelRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	cancellation, err := h.service.CancelOrder(r.Context(), userID, orderID, req.Reason)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(cancellation); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *OrderCancellationHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidCancelReason):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrOrderNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrOrderNotPending), errors.Is(err, usecases.ErrOrderPaymentPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Order cancellation failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// orderEventWriteTimeout ограничивает отправку события одному клиенту, чтобы медленный клиент не задерживал остальных
+const orderEventWriteTimeout = 5 * time.Second
+
+var _ usecases.OrderEventPublisher = (*OrderEventsHandler)(nil)
+
+// OrderEventsHandler передает мерчанту события его ордеров по WebSocket
+type OrderEventsHandler struct {
+	logger           *slog.Logger
+	websocketManager *Manager
+
+	mu          sync.Mutex
+	subscribers map[int64]map[*websocket.Conn]struct{}
+}
+
+func NewOrderEventsHandler(logger *slog.Logger, websocketManager *Manager) *OrderEventsHandler {
+	return &OrderEventsHandler{
+		logger:           logger,
+		websocketManager: websocketManager,
+		subscribers:      make(map[int64]map[*websocket.Conn]struct{}),
+	}
+}
+
+func (h *OrderEventsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/users/me/orders/events", h.HandleConnection)
+}
+
+// HandleConnection streams the events of the user's orders until the client disconnects
+func (h *OrderEventsHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := h.websocketManager.Upgrade(w, r)
+	if err != nil {
+		h.logger.Error("Error upgrading connection", "error", err)
+		return
+	}
+
+	h.subscribe(userID, conn)
+	defer h.unsubscribe(userID, conn)
+
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// PublishOrderCompleted sends the event to every connection of the merchant. Failed connections are dropped.
+func (h *OrderEventsHandler) PublishOrderCompleted(ctx context.Context, event entities.OrderCompletedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn := range h.subscribers[event.MerchantID] {
+		_ = conn.SetWriteDeadline(time.Now().Add(orderEventWriteTimeout))
+		if err := conn.WriteJSON(event); err != nil {
+			h.logger.WarnContext(ctx, "Failed to send order event", "error", err, "order_id", event.OrderID)
+			conn.Close()
+			delete(h.subscribers[event.MerchantID], conn)
+		}
+	}
+}
+
+func (h *OrderEventsHandler) subscribe(userID int64, conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*websocket.Conn]struct{})
+	}
+	h.subscribers[userID][conn] = struct{}{}
+}
+
+func (h *OrderEventsHandler) unsubscribe(userID int64, conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	conn.Close()
+	delete(h.subscribers[userID], conn)
+	if len(h.subscribers[userID]) == 0 {
+		delete(h.subscribers, userID)
+	}
+}
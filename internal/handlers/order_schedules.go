@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type OrderScheduleService interface {
+	CreateSchedule(ctx context.Context, userID int64, req usecases.OrderScheduleRequest) (*entities.OrderSchedule, error)
+	GetUserSchedules(ctx context.Context, userID int64) ([]entities.OrderSchedule, error)
+	PauseSchedule(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error)
+	ResumeSchedule(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error)
+	CancelSchedule(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error)
+}
+
+var _ OrderScheduleService = (*usecases.OrderScheduleService)(nil)
+
+// OrderScheduleHandler управляет регулярными ордерами пользователя: создание, пауза, возобновление и отмена
+type OrderScheduleHandler struct {
+	logger  *slog.Logger
+	service OrderScheduleService
+}
+
+func NewOrderScheduleHandler(logger *slog.Logger, service OrderScheduleService) *OrderScheduleHandler {
+	return &OrderScheduleHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *OrderScheduleHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/orders/schedules", h.CreateScheduleHandler).Methods("POST")
+	router.HandleFunc("/orders/schedules", h.GetSchedulesHandler).Methods("GET")
+	router.HandleFunc("/orders/schedules/{id:[0-9]+}/pause", h.PauseScheduleHandler).Methods("POST")
+	router.HandleFunc("/orders/schedules/{id:[0-9]+}/resume", h.ResumeScheduleHandler).Methods("POST")
+	router.HandleFunc("/orders/schedules/{id:[0-9]+}", h.CancelScheduleHandler).Methods("DELETE")
+}
+
+type orderScheduleRequest struct {
+	Amount         string `json:"amount"`
+	Cron           string `json:"cron"`
+	Description    string `json:"description"`
+	MaxOccurrences *int   `json:"max_occurrences"`
+}
+
+func (h *OrderScheduleHandler) CreateScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req orderScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(r.Context(), userID, usecases.OrderScheduleRequest{
+		Amount:         req.Amount,
+		Cron:           req.Cron,
+		Description:    req.Description,
+		MaxOccurrences: req.MaxOccurrences,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, schedule)
+}
+
+func (h *OrderScheduleHandler) GetSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.service.GetUserSchedules(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, schedules)
+}
+
+func (h *OrderScheduleHandler) PauseScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.PauseSchedule)
+}
+
+func (h *OrderScheduleHandler) ResumeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.ResumeSchedule)
+}
+
+func (h *OrderScheduleHandler) CancelScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.CancelSchedule)
+}
+
+func (h *OrderScheduleHandler) changeStatus(w http.ResponseWriter, r *http.Request,
+	change func(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error)) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := change(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, schedule)
+}
+
+func (h *OrderScheduleHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrOrderScheduleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidOrderSchedule), errors.Is(err, usecases.ErrOrderAmountOutOfRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrOrderScheduleNotAllowed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecases.ErrTooManyOrderSchedules):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, usecases.ErrDepositsDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		h.logger.ErrorContext(r.Context(), "Order schedule request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type OrderTemplateService interface {
+	CreateTemplate(ctx context.Context, userID int64, req usecases.OrderTemplateRequest) (*entities.OrderTemplate, error)
+	GetUserTemplates(ctx context.Context, userID int64) ([]entities.OrderTemplate, error)
+	GetTemplate(ctx context.Context, userID int64, id int) (*entities.OrderTemplate, error)
+	ArchiveTemplate(ctx context.Context, userID int64, id int) error
+	CreateOrder(ctx context.Context, userID int64, id int, amount string) (*entities.TemplateOrder, error)
+}
+
+var _ OrderTemplateService = (*usecases.OrderTemplateService)(nil)
+
+// OrderTemplateHandler управляет шаблонами ордеров мерчанта и создает по ним ордера с платежными ссылками
+type OrderTemplateHandler struct {
+	logger     *slog.Logger
+	service    OrderTemplateService
+	abuseGuard AbuseGuard
+}
+
+func NewOrderTemplateHandler(logger *slog.Logger, service OrderTemplateService, abuseGuard AbuseGuard) *OrderTemplateHandler {
+	return &OrderTemplateHandler{
+		logger:     logger,
+		service:    service,
+		abuseGuard: abuseGuard,
+	}
+}
+
+func (h *OrderTemplateHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/orders/templates", h.CreateTemplateHandler).Methods("POST")
+	router.HandleFunc("/orders/templates", h.GetTemplatesHandler).Methods("GET")
+	router.HandleFunc("/orders/templates/{id:[0-9]+}", h.GetTemplateHandler).Methods("GET")
+	router.HandleFunc("/orders/templates/{id:[0-9]+}", h.ArchiveTemplateHandler).Methods("DELETE")
+	router.HandleFunc("/orders/templates/{id:[0-9]+}/orders",
+		throttle(h.logger, h.abuseGuard, usecases.AbuseActionOrderCreation, h.CreateOrderHandler)).Methods("POST")
+}
+
+type orderTemplateRequest struct {
+	Name        string `json:"name"`
+	Asset       string `json:"asset"`
+	Amount      string `json:"amount"`
+	Description string `json:"description"`
+	CallbackURL string `json:"callback_url"`
+}
+
+type templateOrderRequest struct {
+	Amount string `json:"amount"`
+}
+
+func (h *OrderTemplateHandler) CreateTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req orderTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.service.CreateTemplate(r.Context(), userID, usecases.OrderTemplateRequest{
+		Name:        req.Name,
+		Asset:       req.Asset,
+		Amount:      req.Amount,
+		Description: req.Description,
+		CallbackURL: req.CallbackURL,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, template)
+}
+
+func (h *OrderTemplateHandler) GetTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := h.service.GetUserTemplates(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, templates)
+}
+
+func (h *OrderTemplateHandler) GetTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.service.GetTemplate(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, template)
+}
+
+func (h *OrderTemplateHandler) ArchiveTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ArchiveTemplate(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateOrderHandler creates an order from the template. The body is optional and carries the amount
+// for templates without a fixed amount.
+func (h *OrderTemplateHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var req templateOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.CreateOrder(r.Context(), userID, id, req.Amount)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, order)
+}
+
+func (h *OrderTemplateHandler) parseTemplateID(w http.ResponseWriter, r *http.Request) (int64, int, bool) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return userID, id, true
+}
+
+func (h *OrderTemplateHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrOrderTemplateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidOrderTemplate), errors.Is(err, usecases.ErrAssetNotSupported),
+		errors.Is(err, usecases.ErrOrderAmountOutOfRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrDepositsDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		h.logger.ErrorContext(r.Context(), "Order template request failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
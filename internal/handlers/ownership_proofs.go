@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type OwnershipProofService interface {
+	ProveOwnership(ctx context.Context, userID int64, address, challenge string) (*entities.OwnershipProof, error)
+	VerifyOwnership(address, message, signature string) (*entities.OwnershipVerification, error)
+}
+
+var _ OwnershipProofService = (*usecases.OwnershipProofService)(nil)
+
+// OwnershipProofHandler подписывает вызов ключом депозитного кошелька и проверяет такие подписи
+type OwnershipProofHandler struct {
+	logger  *slog.Logger
+	service OwnershipProofService
+}
+
+func NewOwnershipProofHandler(logger *slog.Logger, service OwnershipProofService) *OwnershipProofHandler {
+	return &OwnershipProofHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *OwnershipProofHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/wallets/{address}/ownership-proof", h.ProveOwnershipHandler).Methods("POST")
+	router.HandleFunc("/ownership-proofs/verify", h.VerifyOwnershipHandler).Methods("POST")
+}
+
+type ownershipProofRequest struct {
+	Challenge string `json:"challenge"`
+}
+
+type ownershipVerificationRequest struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+func (h *OwnershipProofHandler) ProveOwnershipHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ownershipProofRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	proof, err := h.service.ProveOwnership(r.Context(), userID, mux.Vars(r)["address"], req.Challenge)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(proof); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+// VerifyOwnershipHandler не требует пользователя: проверка подписи опирается только на публичные данные
+func (h *OwnershipProofHandler) VerifyOwnershipHandler(w http.ResponseWriter, r *http.Request) {
+	var req ownershipVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	verification, err := h.service.VerifyOwnership(req.Address, req.Message, req.Signature)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, verification)
+}
+
+func (h *OwnershipProofHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrWalletNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidOwnershipChallenge),
+		errors.Is(err, usecases.ErrOwnershipProofNotSupported):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Ownership proof request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
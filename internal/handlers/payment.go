@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/qrcode"
+)
+
+// Размер модуля QR кода в пикселях для PNG
+const qrModuleSize = 8
+
+type PaymentLinkService interface {
+	GetPaymentRequest(ctx context.Context, userID int64, orderID int) (*entities.PaymentRequest, error)
+}
+
+var _ PaymentLinkService = (*usecases.PaymentLinkService)(nil)
+
+type PaymentHandler struct {
+	logger  *slog.Logger
+	service PaymentLinkService
+}
+
+func NewPaymentHandler(logger *slog.Logger, service PaymentLinkService) *PaymentHandler {
+	return &PaymentHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *PaymentHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/orders/{orderId:[0-9]+}/payment", h.GetPaymentHandler).Methods("GET")
+}
+
+// GetPaymentHandler returns payment details of the order: JSON by default,
+// or the QR code of the EIP-681 link with format=png / format=svg
+func (h *PaymentHandler) GetPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	orderID, err := strconv.Atoi(mux.Vars(r)["orderId"])
+	if err != nil {
+		http.Error(w, "Invalid order ID format", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.service.GetPaymentRequest(r.Context(), userID, orderID)
+	switch {
+	case errors.Is(err, usecases.ErrOrderNotFound):
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	case errors.Is(err, usecases.ErrOrderNotPending):
+		http.Error(w, "Order is not awaiting payment", http.StatusConflict)
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "Failed to build payment request", "error", err, "order_id", orderID)
+		http.Error(w, "Failed to build payment request", http.StatusInternalServerError)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" || format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(payment); err != nil {
+			h.logger.Error("Failed to encode response", "error", err)
+		}
+		return
+	}
+
+	code, err := qrcode.Encode(payment.URI)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to encode payment QR code", "error", err, "order_id", orderID)
+		http.Error(w, "Failed to generate QR code", http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case "png":
+		image, err := code.PNG(qrModuleSize)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "Failed to render payment QR code", "error", err, "order_id", orderID)
+			http.Error(w, "Failed to generate QR code", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write(code.SVG())
+	default:
+		http.Error(w, "Unsupported format, use json, png or svg", http.StatusBadRequest)
+	}
+}
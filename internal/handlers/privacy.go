@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type PrivacyService interface {
+	ExportUserData(ctx context.Context, userID int64, actor string) (*entities.UserDataExport, error)
+	EraseUserData(ctx context.Context, userID int64, reason, actor string) (*entities.UserErasure, error)
+}
+
+var _ PrivacyService = (*usecases.PrivacyService)(nil)
+
+// PrivacyHandler позволяет администраторам исполнять запросы пользователей на выгрузку и удаление персональных данных
+type PrivacyHandler struct {
+	logger  *slog.Logger
+	service PrivacyService
+}
+
+func NewPrivacyHandler(logger *slog.Logger, service PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *PrivacyHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/users/{userId:[0-9]+}/export", h.ExportUserDataHandler).Methods("GET")
+	admin.HandleFunc("/users/{userId:[0-9]+}/erase", h.EraseUserDataHandler).Methods("POST")
+}
+
+type eraseUserDataRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *PrivacyHandler) ExportUserDataHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.service.ExportUserData(r.Context(), userID, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user_%d_export.json"`, userID))
+	writeJSON(w, h.logger, export)
+}
+
+func (h *PrivacyHandler) EraseUserDataHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req eraseUserDataRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	erasure, err := h.service.EraseUserData(r.Context(), userID, req.Reason, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, erasure)
+}
+
+func (h *PrivacyHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrErasureBlocked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Privacy request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
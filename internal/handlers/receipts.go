@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type ReceiptService interface {
+	ResendReceipt(ctx context.Context, userID int64, orderID int) (*entities.OrderReceipt, error)
+	GetBranding(ctx context.Context, merchantID int64) (*entities.ReceiptBranding, error)
+	SaveBranding(ctx context.Context, branding entities.ReceiptBranding) (*entities.ReceiptBranding, error)
+}
+
+var _ ReceiptService = (*usecases.ReceiptService)(nil)
+
+// ReceiptHandler повторно отправляет квитанции об оплате ордеров и управляет оформлением квитанций мерчанта
+type ReceiptHandler struct {
+	logger  *slog.Logger
+	service ReceiptService
+}
+
+func NewReceiptHandler(logger *slog.Logger, service ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *ReceiptHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/orders/{orderId:[0-9]+}/receipt/resend", h.ResendReceiptHandler).Methods("POST")
+	router.HandleFunc("/receipts/branding", h.GetBrandingHandler).Methods("GET")
+	router.HandleFunc("/receipts/branding", h.SaveBrandingHandler).Methods("PUT")
+}
+
+type receiptBrandingRequest struct {
+	BrandName       string `json:"brand_name"`
+	SubjectTemplate string `json:"subject_template"`
+	BodyTemplate    string `json:"body_template"`
+}
+
+func (h *ReceiptHandler) ResendReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	orderID, err := strconv.Atoi(mux.Vars(r)["orderId"])
+	if err != nil {
+		http.Error(w, "Invalid order ID format", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := h.service.ResendReceipt(r.Context(), userID, orderID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, h.logger, receipt)
+}
+
+func (h *ReceiptHandler) GetBrandingHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	branding, err := h.service.GetBranding(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, branding)
+}
+
+func (h *ReceiptHandler) SaveBrandingHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req receiptBrandingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	branding, err := h.service.SaveBranding(r.Context(), entities.ReceiptBranding{
+		MerchantID:      userID,
+		BrandName:       req.BrandName,
+		SubjectTemplate: req.SubjectTemplate,
+		BodyTemplate:    req.BodyTemplate,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, branding)
+}
+
+func (h *ReceiptHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var cooldownErr *usecases.CooldownError
+	switch {
+	case errors.As(err, &cooldownErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(cooldownErr.RetryAfter.Seconds()))))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, usecases.ErrReceiptNotAvailable):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidReceiptBranding):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Receipt request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
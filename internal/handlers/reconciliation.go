@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type ReconciliationService interface {
+	GetReports(ctx context.Context) ([]entities.ReconciliationReport, error)
+	Preview(ctx context.Context, day time.Time) (*entities.ReconciliationSummary, error)
+	Resend(ctx context.Context, day time.Time) (*entities.ReconciliationReport, error)
+}
+
+var _ ReconciliationService = (*usecases.ReconciliationService)(nil)
+
+// ReconciliationHandler показывает суточные сверки для бухгалтерии и их доставку, позволяет отправить день повторно
+type ReconciliationHandler struct {
+	logger  *slog.Logger
+	service ReconciliationService
+}
+
+func NewReconciliationHandler(logger *slog.Logger, service ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *ReconciliationHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/reports/reconciliation", h.GetReportsHandler).Methods("GET")
+	admin.HandleFunc("/reports/reconciliation/{day}", h.PreviewHandler).Methods("GET")
+	admin.HandleFunc("/reports/reconciliation/{day}/send", h.ResendHandler).Methods("POST")
+}
+
+// GetReportsHandler lists the latest daily reconciliations with their delivery state
+func (h *ReconciliationHandler) GetReportsHandler(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.service.GetReports(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, reports)
+}
+
+// PreviewHandler builds the summary of a past day (YYYY-MM-DD) from current data without sending it
+func (h *ReconciliationHandler) PreviewHandler(w http.ResponseWriter, r *http.Request) {
+	day, err := time.Parse(time.DateOnly, mux.Vars(r)["day"])
+	if err != nil {
+		http.Error(w, "Invalid day", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.service.Preview(r.Context(), day)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, summary)
+}
+
+// ResendHandler regenerates the reconciliation of a past day and delivers it, the result shows the delivery outcome
+func (h *ReconciliationHandler) ResendHandler(w http.ResponseWriter, r *http.Request) {
+	day, err := time.Parse(time.DateOnly, mux.Vars(r)["day"])
+	if err != nil {
+		http.Error(w, "Invalid day", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.Resend(r.Context(), day)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Reconciliation resent", "day", day.Format(time.DateOnly), "actor", adminActor(r))
+	writeJSON(w, h.logger, report)
+}
+
+func (h *ReconciliationHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidReportRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Reconciliation request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/errreport"
+)
+
+// Recover reports panics raised by HTTP handlers and responds with 500 instead of dropping the connection
+func Recover(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// ErrAbortHandler — штатный способ прервать ответ, его обрабатывает net/http
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			tags := map[string]string{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if tpl, err := route.GetPathTemplate(); err == nil {
+					tags["route"] = tpl
+				}
+			}
+
+			errreport.CapturePanic(r.Context(), recovered, tags)
+			logger.ErrorContext(r.Context(), "Panic in HTTP handler", "panic", recovered, "path", r.URL.Path)
+
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
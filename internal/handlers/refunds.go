@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type RefundService interface {
+	RequestRefund(ctx context.Context, depositTxHash string, amount *big.Int, reason entities.RefundReason, initiatedBy string) (*entities.Refund, error)
+	ExecuteRefund(ctx context.Context, refundID, executedBy string) (*entities.Refund, error)
+	GetUserRefunds(ctx context.Context, userID int64) ([]entities.Refund, error)
+	GetRefunds(ctx context.Context, status entities.RefundStatus) ([]entities.Refund, error)
+}
+
+var _ RefundService = (*usecases.RefundService)(nil)
+
+// RefundHandler отдает пользователю статусы возвратов и позволяет администратору создавать и исполнять возвраты
+type RefundHandler struct {
+	logger    *slog.Logger
+	service   RefundService
+	twoFactor *TwoFactorHandler
+}
+
+func NewRefundHandler(logger *slog.Logger, service RefundService, twoFactor *TwoFactorHandler) *RefundHandler {
+	return &RefundHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *RefundHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/refunds", h.GetUserRefundsHandler).Methods("GET")
+}
+
+func (h *RefundHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/refunds", h.GetRefundsHandler).Methods("GET")
+	admin.HandleFunc("/refunds", h.CreateRefundHandler).Methods("POST")
+	admin.HandleFunc("/refunds/{id}/execute", h.twoFactor.RequireAdminSecondFactor(OperationRefundExecution, h.ExecuteRefundHandler)).Methods("POST")
+}
+
+func (h *RefundHandler) GetUserRefundsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	refunds, err := h.service.GetUserRefunds(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, refunds)
+}
+
+func (h *RefundHandler) GetRefundsHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.RefundStatus(r.URL.Query().Get("status"))
+
+	refunds, err := h.service.GetRefunds(r.Context(), status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, refunds)
+}
+
+type createRefundRequest struct {
+	TxHash string `json:"tx_hash"`
+	Amount string `json:"amount"` // wei, пусто — вся сумма депозита
+}
+
+func (h *RefundHandler) CreateRefundHandler(w http.ResponseWriter, r *http.Request) {
+	var req createRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.TxHash == "" {
+		http.Error(w, "Missing required parameters: tx_hash", http.StatusBadRequest)
+		return
+	}
+
+	var amount *big.Int
+	if req.Amount != "" {
+		var ok bool
+		amount, ok = new(big.Int).SetString(req.Amount, 10)
+		if !ok {
+			http.Error(w, "Invalid amount format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	refund, err := h.service.RequestRefund(r.Context(), req.TxHash, amount, entities.RefundReasonManual, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, refund)
+}
+
+func (h *RefundHandler) ExecuteRefundHandler(w http.ResponseWriter, r *http.Request) {
+	refund, err := h.service.ExecuteRefund(r.Context(), mux.Vars(r)["id"], adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, refund)
+}
+
+func (h *RefundHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrRefundNotFound):
+		http.Error(w, "Refund not found", http.StatusNotFound)
+	case errors.Is(err, usecases.ErrRefundDepositNotFound):
+		http.Error(w, "Deposit transaction not found", http.StatusNotFound)
+	case errors.Is(err, usecases.ErrRefundExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecases.ErrRefundNotAllowed):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		h.logger.ErrorContext(r.Context(), "Refund request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
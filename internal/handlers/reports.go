@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// reportDefaultRange — интервал отчета, если from не указан
+const reportDefaultRange = 30 * 24 * time.Hour
+
+type ReportService interface {
+	GetPnLReport(ctx context.Context, from, to time.Time, period entities.PnLPeriod) (*entities.PnLReport, error)
+	GetFeeAnalytics(ctx context.Context, from, to time.Time) (*entities.FeeAnalytics, error)
+}
+
+var _ ReportService = (*usecases.LedgerService)(nil)
+
+// ReportHandler отдает финансовые отчеты по журналу исходящих транзакций, в JSON или CSV для бухгалтерии
+type ReportHandler struct {
+	logger  *slog.Logger
+	service ReportService
+}
+
+func NewReportHandler(logger *slog.Logger, service ReportService) *ReportHandler {
+	return &ReportHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *ReportHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/reports/pnl", h.GetPnLReportHandler).Methods("GET")
+	admin.HandleFunc("/reports/fees", h.GetFeeAnalyticsHandler).Methods("GET")
+}
+
+// GetPnLReportHandler accepts from and to as RFC 3339 timestamps or dates (to is exclusive),
+// period=day|week|month and format=csv for a CSV download
+func (h *ReportHandler) GetPnLReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to, ok := parseReportRange(w, query)
+	if !ok {
+		return
+	}
+
+	period, err := usecases.ParsePnLPeriod(query.Get("period"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetPnLReport(r.Context(), from, to, period)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidReportRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "Failed to build P&L report", "error", err)
+		http.Error(w, "Failed to build P&L report", http.StatusInternalServerError)
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		h.writePnLCSV(w, report)
+		return
+	}
+
+	writeJSON(w, h.logger, report)
+}
+
+// GetFeeAnalyticsHandler returns the gas paid per transaction kind, per day and per chain.
+// Accepts from and to like the P&L report.
+func (h *ReportHandler) GetFeeAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseReportRange(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	analytics, err := h.service.GetFeeAnalytics(r.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidReportRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "Failed to build fee analytics", "error", err)
+		http.Error(w, "Failed to build fee analytics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, analytics)
+}
+
+func (h *ReportHandler) writePnLCSV(w http.ResponseWriter, report *entities.PnLReport) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="pnl_%s_%s.csv"`,
+		report.From.Format("20060102"), report.To.Format("20060102")))
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{
+		"period_start", "asset", "withdrawals", "withdrawal_volume", "fees_collected", "sweeps",
+		"sweep_gas_bnb", "withdrawal_gas_bnb", "refund_gas_bnb", "operational_gas_bnb", "gas_spent_bnb",
+		"gas_spent_fiat", "gas_unconverted", "gas_spent_in_asset", "net_margin", "rate_currency",
+	})
+	for _, row := range report.Rows {
+		_ = writer.Write([]string{
+			row.PeriodStart.Format(time.RFC3339), row.Asset,
+			strconv.Itoa(row.Withdrawals), row.WithdrawalVolume, row.FeesCollected, strconv.Itoa(row.Sweeps),
+			row.SweepGas, row.WithdrawalGas, row.RefundGas, row.OperationalGas, row.GasSpent,
+			row.GasSpentFiat, strconv.Itoa(row.GasUnconverted), row.GasSpentInAsset, row.NetMargin, report.RateCurrency,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.Error("Failed to write CSV report", "error", err)
+	}
+}
+
+// parseReportRange читает интервал отчета: to по умолчанию — текущий момент, from — reportDefaultRange до to.
+// При ошибке ответ уже записан.
+func parseReportRange(w http.ResponseWriter, query url.Values) (from, to time.Time, ok bool) {
+	to = time.Now().UTC()
+	if value := query.Get("to"); value != "" {
+		parsed, err := parseReportTime(value)
+		if err != nil {
+			http.Error(w, "Invalid to parameter", http.StatusBadRequest)
+			return from, to, false
+		}
+		to = parsed
+	}
+	from = to.Add(-reportDefaultRange)
+	if value := query.Get("from"); value != "" {
+		parsed, err := parseReportTime(value)
+		if err != nil {
+			http.Error(w, "Invalid from parameter", http.StatusBadRequest)
+			return from, to, false
+		}
+		from = parsed
+	}
+	return from, to, true
+}
+
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
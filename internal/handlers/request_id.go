@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/shared"
+)
+
+// RequestIDHeader передаёт идентификатор запроса между клиентом, прокси и сервисом
+const RequestIDHeader = "X-Request-ID"
+
+// Максимальная длина идентификатора, принимаемого от клиента
+const maxRequestIDLength = 128
+
+// RequestID assigns a correlation ID to every request (reusing the one sent by the client or proxy),
+// returns it in the response and stores it in the request context together with the user ID.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := shared.WithRequestID(r.Context(), requestID)
+		if userID, err := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64); err == nil {
+			ctx = shared.WithUserID(ctx, userID)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
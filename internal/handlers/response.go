@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// writeJSON encodes v as the JSON response body. The status line is already sent when encoding fails,
+// so the error is only logged.
+func writeJSON(w http.ResponseWriter, logger *slog.Logger, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// defaultRiskyUserScore — нижняя граница сводного риска в списке пользователей, если min_score не указан
+const defaultRiskyUserScore = 0.4
+
+type RiskRollupService interface {
+	GetWalletRisk(ctx context.Context, walletAddress string) (*entities.RiskRollup, error)
+	GetUserRisk(ctx context.Context, userID int64) (*entities.RiskRollup, error)
+	GetRiskyUsers(ctx context.Context, minScore float64) ([]entities.RiskRollup, error)
+}
+
+var _ RiskRollupService = (*usecases.RiskRollupService)(nil)
+
+// RiskRollupHandler показывает комплаенсу сводный риск кошельков и пользователей
+type RiskRollupHandler struct {
+	logger  *slog.Logger
+	service RiskRollupService
+}
+
+func NewRiskRollupHandler(logger *slog.Logger, service RiskRollupService) *RiskRollupHandler {
+	return &RiskRollupHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *RiskRollupHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/aml/risk/wallets/{address}", h.GetWalletRiskHandler).Methods("GET")
+	admin.HandleFunc("/aml/risk/users", h.GetRiskyUsersHandler).Methods("GET")
+	admin.HandleFunc("/aml/risk/users/{userId:[0-9]+}", h.GetUserRiskHandler).Methods("GET")
+}
+
+func (h *RiskRollupHandler) GetWalletRiskHandler(w http.ResponseWriter, r *http.Request) {
+	rollup, err := h.service.GetWalletRisk(r.Context(), mux.Vars(r)["address"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, rollup)
+}
+
+func (h *RiskRollupHandler) GetUserRiskHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	rollup, err := h.service.GetUserRisk(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, rollup)
+}
+
+// GetRiskyUsersHandler lists users with the rollup of at least min_score, the riskiest first
+func (h *RiskRollupHandler) GetRiskyUsersHandler(w http.ResponseWriter, r *http.Request) {
+	minScore := defaultRiskyUserScore
+	if v := r.URL.Query().Get("min_score"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			http.Error(w, "Invalid min_score parameter", http.StatusBadRequest)
+			return
+		}
+		minScore = parsed
+	}
+
+	rollups, err := h.service.GetRiskyUsers(r.Context(), minScore)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, rollups)
+}
+
+func (h *RiskRollupHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrRiskRollupNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		h.logger.ErrorContext(r.Context(), "Risk rollup request failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type RPCEndpointService interface {
+	GetEndpoints(ctx context.Context) *entities.RPCEndpoints
+	Disable(ctx context.Context, actor, rawURL, reason string) error
+	Enable(ctx context.Context, actor, rawURL string) error
+	Failover(ctx context.Context, actor string) error
+}
+
+var _ RPCEndpointService = (*usecases.RPCEndpointService)(nil)
+
+// RPCEndpointsHandler показывает операторам состояние RPC эндпоинтов и позволяет вывести эндпоинт из ротации
+type RPCEndpointsHandler struct {
+	logger  *slog.Logger
+	service RPCEndpointService
+}
+
+func NewRPCEndpointsHandler(logger *slog.Logger, service RPCEndpointService) *RPCEndpointsHandler {
+	return &RPCEndpointsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+type rpcEndpointRequest struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+func (h *RPCEndpointsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/rpc-endpoints", h.GetEndpointsHandler).Methods("GET")
+	admin.HandleFunc("/rpc-endpoints/disable", h.DisableHandler).Methods("POST")
+	admin.HandleFunc("/rpc-endpoints/enable", h.EnableHandler).Methods("POST")
+	admin.HandleFunc("/rpc-endpoints/failover", h.FailoverHandler).Methods("POST")
+}
+
+func (h *RPCEndpointsHandler) GetEndpointsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.logger, h.service.GetEndpoints(r.Context()))
+}
+
+func (h *RPCEndpointsHandler) DisableHandler(w http.ResponseWriter, r *http.Request) {
+	var req rpcEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Disable(r.Context(), adminActor(r), req.URL, req.Reason); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, map[string]string{"status": "disabled", "url": req.URL})
+}
+
+func (h *RPCEndpointsHandler) EnableHandler(w http.ResponseWriter, r *http.Request) {
+	var req rpcEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Enable(r.Context(), adminActor(r), req.URL); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, map[string]string{"status": "enabled", "url": req.URL})
+}
+
+// FailoverHandler moves the block scanner to the next WebSocket endpoint
+func (h *RPCEndpointsHandler) FailoverHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Failover(r.Context(), adminActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, map[string]string{"status": "failover_started"})
+}
+
+func (h *RPCEndpointsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrRPCEndpointNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrRPCFailoverUnavailable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "RPC endpoint request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
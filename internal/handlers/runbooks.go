@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type RunbookService interface {
+	Execute(ctx context.Context, actor string, action entities.RunbookAction, req entities.RunbookRequest) (*entities.RunbookResult, error)
+}
+
+var _ RunbookService = (*usecases.RunbookService)(nil)
+
+// RunbooksHandler выполняет действия операторов при инцидентах: повтор сброшенных элементов очередей, повтор AML
+// проверки, зачисление зависшего депозита и повтор webhook. Права операторов задаются конфигурацией.
+type RunbooksHandler struct {
+	logger    *slog.Logger
+	service   RunbookService
+	twoFactor *TwoFactorHandler
+}
+
+func NewRunbooksHandler(logger *slog.Logger, service RunbookService, twoFactor *TwoFactorHandler) *RunbooksHandler {
+	return &RunbooksHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *RunbooksHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/runbooks/{action}", h.twoFactor.RequireAdminSecondFactor(OperationRunbook, h.ExecuteHandler)).Methods("POST")
+}
+
+// ExecuteHandler runs the runbook action named in the path with the parameters from the request body
+func (h *RunbooksHandler) ExecuteHandler(w http.ResponseWriter, r *http.Request) {
+	var req entities.RunbookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.Execute(r.Context(), adminActor(r), entities.RunbookAction(mux.Vars(r)["action"]), req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, result)
+}
+
+func (h *RunbooksHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidRunbookRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrRunbookForbidden):
+		h.logger.WarnContext(r.Context(), "Runbook action denied", "error", err, "actor", adminActor(r))
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, usecases.ErrOrderNotFound),
+		errors.Is(err, usecases.ErrTransactionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrRunbookNotApplicable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Runbook action failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/shared"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type SandboxService interface {
+	CheckAccess(ctx context.Context, merchantID int64) error
+	GetStatus(ctx context.Context, merchantID int64) (*entities.SandboxStatus, error)
+	GetSandboxMerchants(ctx context.Context) ([]entities.SandboxMerchant, error)
+	SetSandbox(ctx context.Context, merchantID int64, sandbox bool, actor string) (*entities.SandboxStatus, error)
+}
+
+var _ SandboxService = (*usecases.SandboxService)(nil)
+
+// SandboxHandler показывает мерчанту режим песочницы, администраторы переводят мерчантов в песочницу и обратно
+type SandboxHandler struct {
+	logger  *slog.Logger
+	service SandboxService
+}
+
+func NewSandboxHandler(logger *slog.Logger, service SandboxService) *SandboxHandler {
+	return &SandboxHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *SandboxHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/sandbox", h.GetStatusHandler).Methods("GET")
+}
+
+func (h *SandboxHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/merchants/sandbox", h.GetSandboxMerchantsHandler).Methods("GET")
+	admin.HandleFunc("/merchants/{merchantId:[0-9]+}/sandbox", h.GetMerchantStatusHandler).Methods("GET")
+	admin.HandleFunc("/merchants/{merchantId:[0-9]+}/sandbox", h.SetMerchantSandboxHandler).Methods("PUT")
+}
+
+type setSandboxRequest struct {
+	Sandbox *bool `json:"sandbox"`
+}
+
+// Guard refuses requests made for sandbox merchants when the environment serves mainnet.
+// Requests without a user and administrative requests are passed through.
+func (h *SandboxHandler) Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := shared.UserID(r.Context())
+		if !ok || strings.HasPrefix(r.URL.Path, "/admin/") || r.URL.Path == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := h.service.CheckAccess(r.Context(), userID); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *SandboxHandler) GetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.GetStatus(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, status)
+}
+
+func (h *SandboxHandler) GetSandboxMerchantsHandler(w http.ResponseWriter, r *http.Request) {
+	merchants, err := h.service.GetSandboxMerchants(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, merchants)
+}
+
+func (h *SandboxHandler) GetMerchantStatusHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.ParseInt(mux.Vars(r)["merchantId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid merchant ID format", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.service.GetStatus(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, status)
+}
+
+func (h *SandboxHandler) SetMerchantSandboxHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.ParseInt(mux.Vars(r)["merchantId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid merchant ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req setSandboxRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil || req.Sandbox == nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.service.SetSandbox(r.Context(), merchantID, *req.Sandbox, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, status)
+}
+
+func (h *SandboxHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrSandboxMerchant):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		h.logger.ErrorContext(r.Context(), "Sandbox request failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
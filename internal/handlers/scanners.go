@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type ScannerControlService interface {
+	GetScanners(ctx context.Context) ([]entities.ScannerState, error)
+	Pause(ctx context.Context, chain entities.Chain, reason, actor string) (*entities.ScannerState, error)
+	Resume(ctx context.Context, chain entities.Chain, actor string) (*entities.ScannerState, error)
+}
+
+var _ ScannerControlService = (*usecases.ScannerControlService)(nil)
+
+// ScannersHandler позволяет операторам приостановить сканер сети на время инцидента и возобновить его
+type ScannersHandler struct {
+	logger  *slog.Logger
+	service ScannerControlService
+}
+
+func NewScannersHandler(logger *slog.Logger, service ScannerControlService) *ScannersHandler {
+	return &ScannersHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+type scannerPauseRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *ScannersHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/scanners", h.GetScannersHandler).Methods("GET")
+	admin.HandleFunc("/scanners/{chain}/pause", h.PauseHandler).Methods("POST")
+	admin.HandleFunc("/scanners/{chain}/resume", h.ResumeHandler).Methods("POST")
+}
+
+func (h *ScannersHandler) GetScannersHandler(w http.ResponseWriter, r *http.Request) {
+	scanners, err := h.service.GetScanners(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, scanners)
+}
+
+// PauseHandler stops the chain scanner, the reason is required
+func (h *ScannersHandler) PauseHandler(w http.ResponseWriter, r *http.Request) {
+	var req scannerPauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Reason == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	state, err := h.service.Pause(r.Context(), entities.Chain(mux.Vars(r)["chain"]), req.Reason, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, state)
+}
+
+func (h *ScannersHandler) ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := h.service.Resume(r.Context(), entities.Chain(mux.Vars(r)["chain"]), adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, state)
+}
+
+func (h *ScannersHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrScannerNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrScannerAlreadyPaused), errors.Is(err, usecases.ErrScannerNotPaused):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Scanner control request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
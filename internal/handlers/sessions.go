@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/shared"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// Заголовок со страной клиента, который проставляет CDN (Cloudflare)
+const countryHeader = "CF-IPCountry"
+
+// sessionKey — ключ контекста с сессией, проверенной Authenticated
+type sessionKey struct{}
+
+type SessionService interface {
+	Authenticate(ctx context.Context, token string) (*entities.Session, error)
+	IssueChallenge(ctx context.Context, userID int64) (*entities.LoginChallenge, error)
+	StartSession(ctx context.Context, userID int64, credential entities.SessionCredential, client entities.SessionClient) (*entities.Session, string, error)
+	GetActiveSessions(ctx context.Context, userID int64) ([]entities.Session, error)
+	GetLoginHistory(ctx context.Context, userID int64, limit int) ([]entities.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID int64, currentSessionID string) (int64, error)
+}
+
+var _ SessionService = (*usecases.SessionService)(nil)
+
+type SessionHandler struct {
+	logger  *slog.Logger
+	service SessionService
+}
+
+func NewSessionHandler(logger *slog.Logger, service SessionService) *SessionHandler {
+	return &SessionHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *SessionHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/sessions/challenge", h.ChallengeHandler).Methods("POST")
+	router.HandleFunc("/sessions", h.StartSessionHandler).Methods("POST")
+	router.HandleFunc("/sessions/active", h.Authenticated(h.GetActiveSessionsHandler)).Methods("GET")
+	router.HandleFunc("/sessions/history", h.Authenticated(h.GetLoginHistoryHandler)).Methods("GET")
+	router.HandleFunc("/sessions/revoke_others", h.Authenticated(h.RevokeOtherSessionsHandler)).Methods("POST")
+	router.HandleFunc("/sessions/{sessionId}", h.Authenticated(h.RevokeSessionHandler)).Methods("DELETE")
+}
+
+// Authenticated requires a bearer token of an active session. The session is stored in the request context
+// and a user_id parameter, when present, has to belong to it.
+func (h *SessionHandler) Authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Missing session token", http.StatusUnauthorized)
+			return
+		}
+
+		session, err := h.service.Authenticate(r.Context(), strings.TrimSpace(token))
+		if errors.Is(err, usecases.ErrSessionNotFound) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			h.logger.Error("Failed to authenticate session", "error", err)
+			http.Error(w, "Failed to authenticate session", http.StatusInternalServerError)
+			return
+		}
+
+		if userIDParam := r.URL.Query().Get("user_id"); userIDParam != "" && userIDParam != strconv.FormatInt(session.UserID, 10) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), sessionKey{}, session)
+		ctx = shared.WithUserID(ctx, session.UserID)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// currentSession returns the session verified by Authenticated
+func currentSession(r *http.Request) *entities.Session {
+	session, _ := r.Context().Value(sessionKey{}).(*entities.Session)
+	return session
+}
+
+// ChallengeHandler issues a login challenge for the identity service to sign after it verified the user's credentials
+func (h *SessionHandler) ChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	challenge, err := h.service.IssueChallenge(r.Context(), userID)
+	if err != nil {
+		h.writeLoginError(w, err, userID)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, h.logger, challenge)
+}
+
+type startSessionRequest struct {
+	ChallengeID string `json:"challenge_id"`
+	Signature   string `json:"signature"`
+}
+
+type startSessionResponse struct {
+	Session *entities.Session `json:"session"`
+	Token   string            `json:"token"`
+}
+
+// StartSessionHandler exchanges a signed login challenge, and the X-2FA-Code of a user with a second factor, for a session
+func (h *SessionHandler) StartSessionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req startSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ChallengeID == "" || req.Signature == "" {
+		http.Error(w, "Missing required fields: challenge_id, signature", http.StatusBadRequest)
+		return
+	}
+
+	credential := entities.SessionCredential{
+		ChallengeID:   req.ChallengeID,
+		Signature:     req.Signature,
+		TwoFactorCode: r.Header.Get(TwoFactorCodeHeader),
+	}
+	client := entities.SessionClient{
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+		Country:   r.Header.Get(countryHeader),
+	}
+
+	session, token, err := h.service.StartSession(r.Context(), userID, credential, client)
+	if err != nil {
+		h.writeLoginError(w, err, userID)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, h.logger, startSessionResponse{Session: session, Token: token})
+}
+
+func (h *SessionHandler) GetActiveSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := currentSession(r).UserID
+
+	sessions, err := h.service.GetActiveSessions(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get active sessions", "error", err, "user_id", userID)
+		http.Error(w, "Failed to get active sessions", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, sessions)
+}
+
+func (h *SessionHandler) GetLoginHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	userID := currentSession(r).UserID
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			http.Error(w, "Invalid limit format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sessions, err := h.service.GetLoginHistory(r.Context(), userID, limit)
+	if err != nil {
+		h.logger.Error("Failed to get login history", "error", err, "user_id", userID)
+		http.Error(w, "Failed to get login history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, sessions)
+}
+
+func (h *SessionHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	userID := currentSession(r).UserID
+
+	sessionID := mux.Vars(r)["sessionId"]
+
+	err := h.service.RevokeSession(r.Context(), userID, sessionID)
+	if errors.Is(err, usecases.ErrSessionNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to revoke session", "error", err, "user_id", userID, "session_id", sessionID)
+		http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessionsHandler revokes every session of the user except the one the request is authenticated with
+func (h *SessionHandler) RevokeOtherSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	session := currentSession(r)
+	userID := session.UserID
+
+	count, err := h.service.RevokeOtherSessions(r.Context(), userID, session.ID)
+	if err != nil {
+		h.logger.Error("Failed to revoke sessions", "error", err, "user_id", userID)
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, map[string]int64{"revoked": count})
+}
+
+func (h *SessionHandler) writeLoginError(w http.ResponseWriter, err error, userID int64) {
+	switch {
+	case errors.Is(err, usecases.ErrSessionInvalidCredential),
+		errors.Is(err, usecases.ErrTwoFactorRequired),
+		errors.Is(err, usecases.ErrTwoFactorInvalidCode):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, usecases.ErrSessionLoginDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		h.logger.Error("Failed to start session", "error", err, "user_id", userID)
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/shared"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type stubSessionService struct {
+	SessionService
+	sessions map[string]*entities.Session
+	err      error
+}
+
+func (s *stubSessionService) Authenticate(_ context.Context, token string) (*entities.Session, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	session, ok := s.sessions[token]
+	if !ok {
+		return nil, usecases.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func TestSessionHandlerAuthenticated(t *testing.T) {
+	service := &stubSessionService{sessions: map[string]*entities.Session{
+		"valid-token": {ID: "session-1", UserID: 42},
+	}}
+	h := NewSessionHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), service)
+
+	var called bool
+	handler := h.Authenticated(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		session := currentSession(r)
+		assert.Equal(t, "session-1", session.ID)
+
+		userID, ok := shared.UserID(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), userID)
+	})
+
+	tests := []struct {
+		name          string
+		url           string
+		authorization string
+		wantStatus    int
+		wantCalled    bool
+	}{
+		{name: "no token", url: "/sessions/active", wantStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", url: "/sessions/active", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", url: "/sessions/active", authorization: "Bearer other-token", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", url: "/sessions/active", authorization: "Bearer valid-token", wantStatus: http.StatusOK, wantCalled: true},
+		{name: "matching user", url: "/sessions/active?user_id=42", authorization: "Bearer valid-token", wantStatus: http.StatusOK, wantCalled: true},
+		{name: "other user", url: "/sessions/active?user_id=7", authorization: "Bearer valid-token", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
+
+func TestSessionHandlerAuthenticatedServiceError(t *testing.T) {
+	service := &stubSessionService{err: errors.New("database is down")}
+	h := NewSessionHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), service)
+
+	handler := h.Authenticated(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not be called")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/sessions/active", nil)
+	r.Header.Set("Authorization", "Bearer valid-token")
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
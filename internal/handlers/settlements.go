@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type SettlementService interface {
+	GetAccount(ctx context.Context, merchantID int64) (*entities.SettlementAccount, error)
+	GetAccrual(ctx context.Context, merchantID int64) (*entities.SettlementAccrual, error)
+	SetAccount(ctx context.Context, merchantID int64, address string, feeBPS *int, actor string) (*entities.SettlementAccount, error)
+	GetMerchantSettlements(ctx context.Context, merchantID int64) ([]entities.Settlement, error)
+	GetSettlements(ctx context.Context, status entities.SettlementStatus) ([]entities.Settlement, error)
+	GetReport(ctx context.Context, merchantID int64, id string) (*entities.SettlementReport, error)
+}
+
+var _ SettlementService = (*usecases.SettlementService)(nil)
+
+// SettlementHandler отдает мерчантам выплаты и отчеты по ним, администраторам — все выплаты и комиссии мерчантов
+type SettlementHandler struct {
+	logger    *slog.Logger
+	service   SettlementService
+	twoFactor *TwoFactorHandler
+}
+
+func NewSettlementHandler(logger *slog.Logger, service SettlementService, twoFactor *TwoFactorHandler) *SettlementHandler {
+	return &SettlementHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *SettlementHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/settlements", h.GetMerchantSettlementsHandler).Methods("GET")
+	router.HandleFunc("/settlements/account", h.GetAccountHandler).Methods("GET")
+	router.HandleFunc("/settlements/accrual", h.GetAccrualHandler).Methods("GET")
+	// Адрес выплат определяет, куда уйдут средства мерчанта, поэтому его смена требует второго фактора
+	router.HandleFunc("/settlements/account", h.twoFactor.RequireSecondFactor(OperationSettlementAccount, RequestingUser, h.SetAccountHandler)).Methods("PUT")
+	router.HandleFunc("/settlements/{id}/report", h.GetMerchantReportHandler).Methods("GET")
+}
+
+func (h *SettlementHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/settlements", h.GetSettlementsHandler).Methods("GET")
+	admin.HandleFunc("/settlements/{id}/report", h.GetReportHandler).Methods("GET")
+	admin.HandleFunc("/merchants/{merchantId:[0-9]+}/settlement_account", h.twoFactor.RequireAdminSecondFactor(OperationMerchantSettlementAccount, h.SetMerchantAccountHandler)).Methods("PUT")
+}
+
+type setSettlementAccountRequest struct {
+	Address string `json:"address"`
+	FeeBPS  *int   `json:"fee_bps"`
+}
+
+func (h *SettlementHandler) GetMerchantSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	settlements, err := h.service.GetMerchantSettlements(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, settlements)
+}
+
+// GetAccrualHandler returns the completed orders of the merchant waiting for the next settlement
+func (h *SettlementHandler) GetAccrualHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	accrual, err := h.service.GetAccrual(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, accrual)
+}
+
+func (h *SettlementHandler) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := h.service.GetAccount(r.Context(), merchantID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, account)
+}
+
+// SetAccountHandler sets the settlement address of the merchant, the fee can only be changed by administrators
+func (h *SettlementHandler) SetAccountHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req setSettlementAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.service.SetAccount(r.Context(), merchantID, req.Address, nil, strconv.FormatInt(merchantID, 10))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, account)
+}
+
+func (h *SettlementHandler) SetMerchantAccountHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.ParseInt(mux.Vars(r)["merchantId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid merchant ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req setSettlementAccountRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.service.SetAccount(r.Context(), merchantID, req.Address, req.FeeBPS, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, account)
+}
+
+func (h *SettlementHandler) GetMerchantReportHandler(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeReport(w, r, merchantID)
+}
+
+func (h *SettlementHandler) GetSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.SettlementStatus(r.URL.Query().Get("status"))
+
+	settlements, err := h.service.GetSettlements(r.Context(), status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, settlements)
+}
+
+func (h *SettlementHandler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeReport(w, r, 0)
+}
+
+// writeReport responds with the settlement report in JSON or, with format=csv, as a CSV download
+func (h *SettlementHandler) writeReport(w http.ResponseWriter, r *http.Request, merchantID int64) {
+	report, err := h.service.GetReport(r.Context(), merchantID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		h.writeReportCSV(w, report)
+		return
+	}
+
+	writeJSON(w, h.logger, report)
+}
+
+func (h *SettlementHandler) writeReportCSV(w http.ResponseWriter, report *entities.SettlementReport) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="settlement_%d_%s.csv"`,
+		report.MerchantID, report.CreatedAt.Format("20060102")))
+
+	var txHash string
+	switch {
+	case report.TxHash != nil:
+		txHash = *report.TxHash
+	case report.SafeTxHash != nil:
+		txHash = *report.SafeTxHash
+	}
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"settlement_id", "merchant_id", "asset", "status", "address", "tx_hash",
+		"orders", "gross", "fee_bps", "fee", "net", "period_start", "period_end"})
+	_ = writer.Write([]string{
+		report.ID, strconv.FormatInt(report.MerchantID, 10), report.Asset, string(report.Status), report.Address, txHash,
+		strconv.Itoa(report.Orders), report.Gross, strconv.Itoa(report.FeeBPS), report.Fee, report.Net,
+		report.PeriodStart.Format(time.RFC3339), report.PeriodEnd.Format(time.RFC3339),
+	})
+	_ = writer.Write(nil)
+	_ = writer.Write([]string{"order_id", "amount", "completed_at"})
+	for _, item := range report.Items {
+		_ = writer.Write([]string{strconv.Itoa(item.OrderID), item.Amount, item.CompletedAt.Format(time.RFC3339)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.Error("Failed to write CSV settlement report", "error", err)
+	}
+}
+
+func (h *SettlementHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrSettlementNotFound),
+		errors.Is(err, usecases.ErrSettlementAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrInvalidSettlementAccount),
+		errors.Is(err, usecases.ErrAddressBlacklisted):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Settlement request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
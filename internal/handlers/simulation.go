@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/simchain"
+)
+
+// Нативная монета EVM сетей делится на 10^18 wei
+const nativeDecimals = 18
+
+type SimulatedChain interface {
+	Deposit(ctx context.Context, to common.Address, amount *big.Int, memo []byte) (common.Hash, error)
+	Fund(ctx context.Context, to common.Address, wei *big.Int) (common.Hash, error)
+}
+
+var _ SimulatedChain = (*simchain.Chain)(nil)
+
+// SimulationHandler отправляет депозиты и нативную монету в симулированной сети, чтобы CI мог
+// пройти путь депозит → AML → завершение ордера без публичных сетей. Регистрируется только в режиме симуляции.
+type SimulationHandler struct {
+	logger *slog.Logger
+	chain  SimulatedChain
+}
+
+func NewSimulationHandler(logger *slog.Logger, chain SimulatedChain) *SimulationHandler {
+	return &SimulationHandler{
+		logger: logger,
+		chain:  chain,
+	}
+}
+
+func (h *SimulationHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/simulation/deposits", h.DepositHandler).Methods("POST")
+	admin.HandleFunc("/simulation/fund", h.FundHandler).Methods("POST")
+}
+
+type simulationTransferRequest struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"` // в единицах токена (USDT) или нативной монеты, например "12.5"
+	Memo    string `json:"memo"`   // только для депозитов, добавляется после аргументов transfer
+}
+
+// DepositHandler sends test USDT from the faucet to the address
+func (h *SimulationHandler) DepositHandler(w http.ResponseWriter, r *http.Request) {
+	to, amount, req, ok := h.parseRequest(w, r, simchain.TokenDecimals)
+	if !ok {
+		return
+	}
+
+	txHash, err := h.chain.Deposit(r.Context(), to, amount, []byte(req.Memo))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to send simulated deposit", "error", err, "address", req.Address)
+		http.Error(w, "Failed to send deposit", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Simulated deposit sent", "address", req.Address, "amount", req.Amount, "tx_hash", txHash.Hex())
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, map[string]string{"tx_hash": txHash.Hex()})
+}
+
+// FundHandler sends the native coin from the faucet to the address, e.g. to pay gas of sweeps
+func (h *SimulationHandler) FundHandler(w http.ResponseWriter, r *http.Request) {
+	to, amount, req, ok := h.parseRequest(w, r, nativeDecimals)
+	if !ok {
+		return
+	}
+
+	txHash, err := h.chain.Fund(r.Context(), to, amount)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to fund simulated address", "error", err, "address", req.Address)
+		http.Error(w, "Failed to fund address", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, map[string]string{"tx_hash": txHash.Hex()})
+}
+
+func (h *SimulationHandler) parseRequest(w http.ResponseWriter, r *http.Request, decimals int) (common.Address, *big.Int, simulationTransferRequest, bool) {
+	var req simulationTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return common.Address{}, nil, req, false
+	}
+	if !common.IsHexAddress(req.Address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return common.Address{}, nil, req, false
+	}
+
+	value, err := decimal.Parse(req.Amount)
+	if err != nil {
+		http.Error(w, "Invalid amount format", http.StatusBadRequest)
+		return common.Address{}, nil, req, false
+	}
+	amount, err := value.Units(decimals)
+	if err != nil {
+		http.Error(w, "Invalid amount precision", http.StatusBadRequest)
+		return common.Address{}, nil, req, false
+	}
+	if amount.Sign() <= 0 {
+		http.Error(w, "Amount must be positive", http.StatusBadRequest)
+		return common.Address{}, nil, req, false
+	}
+
+	return common.HexToAddress(req.Address), amount, req, true
+}
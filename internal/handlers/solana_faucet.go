@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type SolanaFaucet interface {
+	Fund(ctx context.Context, address string) (*entities.SolanaFaucetGrant, error)
+}
+
+var _ SolanaFaucet = (*usecases.SolanaFaucetService)(nil)
+
+// SolanaFaucetHandler пополняет адреса Solana devnet тестовыми SOL и токенами.
+// Регистрируется только в режиме BLOCKCHAIN_DEBUG_MODE.
+type SolanaFaucetHandler struct {
+	logger *slog.Logger
+	faucet SolanaFaucet
+}
+
+func NewSolanaFaucetHandler(logger *slog.Logger, faucet SolanaFaucet) *SolanaFaucetHandler {
+	return &SolanaFaucetHandler{
+		logger: logger,
+		faucet: faucet,
+	}
+}
+
+func (h *SolanaFaucetHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/debug/solana/faucet", h.FundHandler).Methods("POST")
+}
+
+type solanaFaucetRequest struct {
+	Address string `json:"address"`
+}
+
+// FundHandler airdrops devnet SOL and mints test tokens to the address
+func (h *SolanaFaucetHandler) FundHandler(w http.ResponseWriter, r *http.Request) {
+	var req solanaFaucetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := h.faucet.Fund(r.Context(), req.Address)
+	switch {
+	case errors.Is(err, usecases.ErrInvalidSolanaAddress):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecases.ErrSolanaFaucetFailed):
+		h.logger.ErrorContext(r.Context(), "Solana faucet failed", "error", err, "address", req.Address)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "Solana faucet request failed", "error", err, "address", req.Address)
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(grant); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
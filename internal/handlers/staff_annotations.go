@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type StaffAnnotationService interface {
+	GetAnnotations(ctx context.Context, subjectType entities.StaffSubjectType, subjectID string) (*entities.StaffAnnotations, error)
+	AddNote(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, body, actor string) (*entities.StaffNote, error)
+	AddTag(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, tag, actor string) (*entities.StaffTag, error)
+	RemoveTag(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, tag, actor string) error
+	Search(ctx context.Context, filter entities.StaffAnnotationFilter) ([]entities.StaffAnnotations, error)
+}
+
+var _ StaffAnnotationService = (*usecases.StaffAnnotationService)(nil)
+
+// StaffAnnotationsHandler дает поддержке и комплаенсу внутренние заметки и теги к транзакциям и ордерам.
+// Маршруты только административные, пользователям аннотации не показываются.
+type StaffAnnotationsHandler struct {
+	logger  *slog.Logger
+	service StaffAnnotationService
+}
+
+func NewStaffAnnotationsHandler(logger *slog.Logger, service StaffAnnotationService) *StaffAnnotationsHandler {
+	return &StaffAnnotationsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *StaffAnnotationsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	for _, prefix := range []string{"/transactions/{txHash}", "/orders/{orderId:[0-9]+}"} {
+		admin.HandleFunc(prefix+"/annotations", h.GetAnnotationsHandler).Methods("GET")
+		admin.HandleFunc(prefix+"/notes", h.AddNoteHandler).Methods("POST")
+		admin.HandleFunc(prefix+"/tags", h.AddTagHandler).Methods("POST")
+		admin.HandleFunc(prefix+"/tags/{tag}", h.RemoveTagHandler).Methods("DELETE")
+	}
+	admin.HandleFunc("/annotations", h.SearchHandler).Methods("GET")
+}
+
+func (h *StaffAnnotationsHandler) GetAnnotationsHandler(w http.ResponseWriter, r *http.Request) {
+	subjectType, subjectID := staffSubject(r)
+
+	annotations, err := h.service.GetAnnotations(r.Context(), subjectType, subjectID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, annotations)
+}
+
+type addStaffNoteRequest struct {
+	Body string `json:"body"`
+}
+
+func (h *StaffAnnotationsHandler) AddNoteHandler(w http.ResponseWriter, r *http.Request) {
+	var req addStaffNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	subjectType, subjectID := staffSubject(r)
+	note, err := h.service.AddNote(r.Context(), subjectType, subjectID, req.Body, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, note)
+}
+
+type addStaffTagRequest struct {
+	Tag string `json:"tag"`
+}
+
+func (h *StaffAnnotationsHandler) AddTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req addStaffTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	subjectType, subjectID := staffSubject(r)
+	tag, err := h.service.AddTag(r.Context(), subjectType, subjectID, req.Tag, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, tag)
+}
+
+func (h *StaffAnnotationsHandler) RemoveTagHandler(w http.ResponseWriter, r *http.Request) {
+	subjectType, subjectID := staffSubject(r)
+	if err := h.service.RemoveTag(r.Context(), subjectType, subjectID, mux.Vars(r)["tag"], adminActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SearchHandler returns annotated transactions and orders filtered by the type, tag and q (note text) query parameters
+func (h *StaffAnnotationsHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	annotations, err := h.service.Search(r.Context(), entities.StaffAnnotationFilter{
+		SubjectType: entities.StaffSubjectType(query.Get("type")),
+		Tag:         query.Get("tag"),
+		Query:       query.Get("q"),
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, annotations)
+}
+
+// staffSubject возвращает объект аннотации из пути: хеш транзакции или номер ордера
+func staffSubject(r *http.Request) (entities.StaffSubjectType, string) {
+	vars := mux.Vars(r)
+	if orderID, ok := vars["orderId"]; ok {
+		return entities.StaffSubjectOrder, orderID
+	}
+	return entities.StaffSubjectTransaction, vars["txHash"]
+}
+
+func (h *StaffAnnotationsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidStaffAnnotation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrOrderNotFound),
+		errors.Is(err, usecases.ErrTransactionNotFound),
+		errors.Is(err, usecases.ErrStaffTagNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrStaffTagExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Staff annotations request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type StateEventService interface {
+	GetOrderHistory(ctx context.Context, orderID int64) ([]entities.OrderEvent, error)
+	GetTransactionHistory(ctx context.Context, txHash string) ([]entities.TransactionEvent, error)
+}
+
+var _ StateEventService = (*usecases.StateEventService)(nil)
+
+// StateEventsHandler отдает журналы изменений ордеров и транзакций для разбора инцидентов и аудита
+type StateEventsHandler struct {
+	logger  *slog.Logger
+	service StateEventService
+}
+
+func NewStateEventsHandler(logger *slog.Logger, service StateEventService) *StateEventsHandler {
+	return &StateEventsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *StateEventsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/orders/{orderId:[0-9]+}/events", h.GetOrderEventsHandler).Methods("GET")
+	admin.HandleFunc("/transactions/{txHash}/events", h.GetTransactionEventsHandler).Methods("GET")
+}
+
+func (h *StateEventsHandler) GetOrderEventsHandler(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(mux.Vars(r)["orderId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.service.GetOrderHistory(r.Context(), orderID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, events)
+}
+
+func (h *StateEventsHandler) GetTransactionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.GetTransactionHistory(r.Context(), mux.Vars(r)["txHash"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, events)
+}
+
+func (h *StateEventsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrOrderNotFound), errors.Is(err, usecases.ErrTransactionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		h.logger.ErrorContext(r.Context(), "State events request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
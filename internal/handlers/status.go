@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type StatusService interface {
+	GetStatus(ctx context.Context) *entities.SystemStatus
+}
+
+var _ StatusService = (*usecases.StatusService)(nil)
+
+// StatusHandler отдает публичный статус системы для страницы статуса
+type StatusHandler struct {
+	logger  *slog.Logger
+	service StatusService
+}
+
+func NewStatusHandler(logger *slog.Logger, service StatusService) *StatusHandler {
+	return &StatusHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *StatusHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/status", h.GetStatusHandler).Methods("GET")
+}
+
+// GetStatusHandler responds 503 while any subsystem is down so that uptime monitors can alert on the status code
+func (h *StatusHandler) GetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status := h.service.GetStatus(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if status.Status == entities.SubsystemDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
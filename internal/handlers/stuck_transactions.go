@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type StuckTransactionsService interface {
+	GetStuckTransactions() []entities.StuckTransaction
+	BumpTransaction(ctx context.Context, client *ethclient.Client, txHash, actor string) (string, error)
+	CancelTransaction(ctx context.Context, client *ethclient.Client, txHash, actor string) (string, error)
+}
+
+var _ StuckTransactionsService = (*usecases.WalletService)(nil)
+
+// StuckTransactionsHandler показывает администраторам зависшие исходящие транзакции и позволяет ускорить или отменить их
+type StuckTransactionsHandler struct {
+	logger    *slog.Logger
+	bscClient *ethclient.Client
+	service   StuckTransactionsService
+	twoFactor *TwoFactorHandler
+}
+
+func NewStuckTransactionsHandler(logger *slog.Logger, bscClient *ethclient.Client, service StuckTransactionsService, twoFactor *TwoFactorHandler) *StuckTransactionsHandler {
+	return &StuckTransactionsHandler{
+		logger:    logger,
+		bscClient: bscClient,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *StuckTransactionsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/transactions/stuck", h.GetStuckTransactionsHandler).Methods("GET")
+	admin.HandleFunc("/transactions/{txHash}/bump", h.twoFactor.RequireAdminSecondFactor(OperationTransactionReplacement, h.BumpTransactionHandler)).Methods("POST")
+	admin.HandleFunc("/transactions/{txHash}/cancel", h.twoFactor.RequireAdminSecondFactor(OperationTransactionReplacement, h.CancelTransactionHandler)).Methods("POST")
+}
+
+type replacementResponse struct {
+	OriginalTxHash string `json:"original_tx_hash"`
+	TxHash         string `json:"tx_hash"`
+}
+
+func (h *StuckTransactionsHandler) GetStuckTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.logger, h.service.GetStuckTransactions())
+}
+
+func (h *StuckTransactionsHandler) BumpTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	txHash := mux.Vars(r)["txHash"]
+
+	newTxHash, err := h.service.BumpTransaction(r.Context(), h.bscClient, txHash, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, replacementResponse{OriginalTxHash: txHash, TxHash: newTxHash})
+}
+
+func (h *StuckTransactionsHandler) CancelTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	txHash := mux.Vars(r)["txHash"]
+
+	newTxHash, err := h.service.CancelTransaction(r.Context(), h.bscClient, txHash, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, replacementResponse{OriginalTxHash: txHash, TxHash: newTxHash})
+}
+
+func (h *StuckTransactionsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrPendingTxNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrTxAlreadyCancelling):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Failed to replace stuck transaction", "error", err)
+		http.Error(w, "Failed to replace transaction", http.StatusInternalServerError)
+	}
+}
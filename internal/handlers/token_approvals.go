@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type TokenApprovalService interface {
+	GetApprovals(ctx context.Context, status entities.TokenApprovalStatus) ([]entities.TokenApproval, error)
+	Approve(ctx context.Context, request usecases.TokenApprovalRequest, actor string) (*entities.TokenApproval, error)
+	Revoke(ctx context.Context, id int64, actor string) (*entities.TokenApproval, error)
+}
+
+var _ TokenApprovalService = (*usecases.TokenApprovalService)(nil)
+
+// TokenApprovalHandler управляет ERC-20 approvals мастер-кошелька: список, выдача и отзыв
+type TokenApprovalHandler struct {
+	logger    *slog.Logger
+	service   TokenApprovalService
+	twoFactor *TwoFactorHandler
+}
+
+func NewTokenApprovalHandler(logger *slog.Logger, service TokenApprovalService, twoFactor *TwoFactorHandler) *TokenApprovalHandler {
+	return &TokenApprovalHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *TokenApprovalHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/allowances", h.GetApprovalsHandler).Methods("GET")
+	admin.HandleFunc("/allowances", h.twoFactor.RequireAdminSecondFactor(OperationTokenAllowance, h.ApproveHandler)).Methods("POST")
+	admin.HandleFunc("/allowances/{id:[0-9]+}/revoke", h.twoFactor.RequireAdminSecondFactor(OperationTokenAllowance, h.RevokeHandler)).Methods("POST")
+}
+
+type tokenApprovalRequest struct {
+	Spender        string `json:"spender"`
+	Amount         string `json:"amount"`
+	ExpiresInHours int    `json:"expires_in_hours"` // 0 — срок по умолчанию
+	Purpose        string `json:"purpose"`
+}
+
+// GetApprovalsHandler lists the latest approvals, ?status=active|revoked|expired filters them
+func (h *TokenApprovalHandler) GetApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.TokenApprovalStatus(r.URL.Query().Get("status"))
+
+	approvals, err := h.service.GetApprovals(r.Context(), status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, approvals)
+}
+
+func (h *TokenApprovalHandler) ApproveHandler(w http.ResponseWriter, r *http.Request) {
+	var req tokenApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	approval, err := h.service.Approve(r.Context(), usecases.TokenApprovalRequest{
+		Spender:   req.Spender,
+		Amount:    req.Amount,
+		ExpiresIn: time.Duration(req.ExpiresInHours) * time.Hour,
+		Purpose:   req.Purpose,
+	}, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, approval)
+}
+
+func (h *TokenApprovalHandler) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid approval ID", http.StatusBadRequest)
+		return
+	}
+
+	approval, err := h.service.Revoke(r.Context(), id, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, approval)
+}
+
+func (h *TokenApprovalHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTokenApproval):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrTokenApprovalNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrTokenApprovalExists), errors.Is(err, usecases.ErrTokenHalted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Token approval request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type TokenMonitorService interface {
+	GetEvents(ctx context.Context) ([]entities.TokenAdminEvent, error)
+	Acknowledge(ctx context.Context, id int64, acknowledgedBy string) (*entities.TokenAdminEvent, error)
+}
+
+var _ TokenMonitorService = (*usecases.TokenMonitorService)(nil)
+
+// TokenEventsHandler отдает администраторам события контрактов токенов и снимает приостановку после их подтверждения
+type TokenEventsHandler struct {
+	logger  *slog.Logger
+	service TokenMonitorService
+}
+
+func NewTokenEventsHandler(logger *slog.Logger, service TokenMonitorService) *TokenEventsHandler {
+	return &TokenEventsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *TokenEventsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/token-events", h.GetEventsHandler).Methods("GET")
+	admin.HandleFunc("/token-events/{id}/acknowledge", h.AcknowledgeHandler).Methods("POST")
+}
+
+func (h *TokenEventsHandler) GetEventsHandler(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.GetEvents(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, events)
+}
+
+func (h *TokenEventsHandler) AcknowledgeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid event ID format", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.service.Acknowledge(r.Context(), id, adminActor(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, event)
+}
+
+func (h *TokenEventsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrTokenEventNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		h.logger.ErrorContext(r.Context(), "Token events request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type TonDepositService interface {
+	DepositAddress() string
+	CreateOrder(ctx context.Context, userID int64, amount string) (*entities.OrderPayment, error)
+}
+
+var _ TonDepositService = (*usecases.TonDepositService)(nil)
+
+// TonDepositHandler создает ордера с оплатой USDT в сети TON на общий депозитный кошелек с мемо
+type TonDepositHandler struct {
+	logger     *slog.Logger
+	service    TonDepositService
+	abuseGuard AbuseGuard
+}
+
+func NewTonDepositHandler(logger *slog.Logger, service TonDepositService, abuseGuard AbuseGuard) *TonDepositHandler {
+	return &TonDepositHandler{
+		logger:     logger,
+		service:    service,
+		abuseGuard: abuseGuard,
+	}
+}
+
+func (h *TonDepositHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ton/create_order", throttle(h.logger, h.abuseGuard, usecases.AbuseActionOrderCreation, h.CreateOrderHandler)).Methods("POST")
+}
+
+func (h *TonDepositHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	amount := r.URL.Query().Get("amount")
+	if amount == "" {
+		http.Error(w, "Missing required parameters: amount", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.service.CreateOrder(r.Context(), userID, amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrOrderAmountOutOfRange):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, usecases.ErrTonDepositsDisabled),
+			errors.Is(err, usecases.ErrAssetNotSupported),
+			errors.Is(err, usecases.ErrDepositsDisabled):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			h.logger.Error("[TON] Failed to create order", "error", err, "user_id", userID)
+			http.Error(w, "Failed to create order", errorStatus(err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":     "success",
+		"chain":      entities.ChainTON,
+		"wallet":     h.service.DepositAddress(),
+		"memo":       payment.Memo,   // комментарий перевода, без него оплата не будет зачислена
+		"pay_amount": payment.Amount, // сумма в USDT
+	})
+}
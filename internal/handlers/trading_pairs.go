@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/mocked"
+)
+
+type TradingPairsService interface {
+	AddTradingPair(symbol string, initialPrice float64) (*entities.TradingPair, error)
+	DisableTradingPair(symbol string) error
+}
+
+var _ TradingPairsService = (*mocked.DataService)(nil)
+
+// TradingPairsHandler добавляет и отключает торговые пары. Подписчики /ws/meta получают событие pairs_updated.
+type TradingPairsHandler struct {
+	logger  *slog.Logger
+	service TradingPairsService
+}
+
+func NewTradingPairsHandler(logger *slog.Logger, service TradingPairsService) *TradingPairsHandler {
+	return &TradingPairsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *TradingPairsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/pairs", h.AddPairHandler).Methods("POST")
+	admin.HandleFunc("/pairs/{symbol}", h.DisablePairHandler).Methods("DELETE")
+}
+
+type addTradingPairRequest struct {
+	Symbol       string  `json:"symbol"`
+	InitialPrice float64 `json:"initial_price"`
+}
+
+type tradingPairView struct {
+	Symbol    string  `json:"symbol"`
+	LastPrice float64 `json:"lastPrice"`
+}
+
+func (h *TradingPairsHandler) AddPairHandler(w http.ResponseWriter, r *http.Request) {
+	var req addTradingPairRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	pair, err := h.service.AddTradingPair(strings.ToUpper(strings.TrimSpace(req.Symbol)), req.InitialPrice)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Trading pair added by admin", "symbol", pair.Symbol, "actor", adminActor(r))
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, h.logger, tradingPairView{Symbol: pair.Symbol, LastPrice: req.InitialPrice})
+}
+
+func (h *TradingPairsHandler) DisablePairHandler(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(mux.Vars(r)["symbol"])
+	if err := h.service.DisableTradingPair(symbol); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Trading pair disabled by admin", "symbol", symbol, "actor", adminActor(r))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TradingPairsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTradingPair):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrTradingPairNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrTradingPairExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.ErrorContext(r.Context(), "Trading pairs request failed", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type TransactionDetailService interface {
+	GetTransactionDetail(ctx context.Context, userID int64, txHash string) (*entities.TransactionDetail, error)
+	GetConfirmations(ctx context.Context, userID int64, txHash string) (*entities.DepositConfirmations, error)
+}
+
+var _ TransactionDetailService = (*usecases.TransactionDetailService)(nil)
+
+// TransactionDetailHandler отдает сводные данные транзакции для страницы транзакции и поддержки
+type TransactionDetailHandler struct {
+	logger  *slog.Logger
+	service TransactionDetailService
+}
+
+func NewTransactionDetailHandler(logger *slog.Logger, service TransactionDetailService) *TransactionDetailHandler {
+	return &TransactionDetailHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *TransactionDetailHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/transactions/{txHash:0x[0-9a-fA-F]{64}}", h.GetTransactionHandler).Methods("GET")
+	router.HandleFunc("/transactions/{txHash:0x[0-9a-fA-F]{64}}/confirmations", h.GetConfirmationsHandler).Methods("GET")
+}
+
+func (h *TransactionDetailHandler) GetTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetTransactionDetail(r.Context(), userID, mux.Vars(r)["txHash"])
+	if errors.Is(err, usecases.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get transaction detail", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(detail); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+// GetConfirmationsHandler returns the confirmation progress of a deposit for a progress bar
+func (h *TransactionDetailHandler) GetConfirmationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	progress, err := h.service.GetConfirmations(r.Context(), userID, mux.Vars(r)["txHash"])
+	if errors.Is(err, usecases.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get deposit confirmations", "error", err)
+		http.Error(w, "Internal server error", errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(progress); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type TreasuryService interface {
+	GetProposals(ctx context.Context, status entities.SafeProposalStatus) ([]entities.SafeProposal, error)
+	GetProposal(ctx context.Context, safeTxHash string) (*entities.SafeProposal, error)
+}
+
+type TreasuryOverviewService interface {
+	GetOverview(ctx context.Context, client *ethclient.Client) (*entities.TreasuryOverview, error)
+}
+
+var (
+	_ TreasuryService         = (*usecases.TreasuryService)(nil)
+	_ TreasuryOverviewService = (*usecases.TreasuryOverviewService)(nil)
+)
+
+// TreasuryHandler отдает администраторам сводку средств платформы, предложения multisig казначейства и их подтверждения
+type TreasuryHandler struct {
+	logger    *slog.Logger
+	bscClient *ethclient.Client
+	service   TreasuryService
+	overview  TreasuryOverviewService
+}
+
+func NewTreasuryHandler(logger *slog.Logger, bscClient *ethclient.Client, service TreasuryService, overview TreasuryOverviewService) *TreasuryHandler {
+	return &TreasuryHandler{
+		logger:    logger,
+		bscClient: bscClient,
+		service:   service,
+		overview:  overview,
+	}
+}
+
+func (h *TreasuryHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/treasury", h.GetOverviewHandler).Methods("GET")
+	admin.HandleFunc("/treasury/proposals", h.GetProposalsHandler).Methods("GET")
+	admin.HandleFunc("/treasury/proposals/{safeTxHash}", h.GetProposalHandler).Methods("GET")
+}
+
+func (h *TreasuryHandler) GetOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	overview, err := h.overview.GetOverview(r.Context(), h.bscClient)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to build treasury overview", "error", err)
+		http.Error(w, "Failed to build treasury overview", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, overview)
+}
+
+func (h *TreasuryHandler) GetProposalsHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.SafeProposalStatus(r.URL.Query().Get("status"))
+
+	proposals, err := h.service.GetProposals(r.Context(), status)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get safe proposals", "error", err)
+		http.Error(w, "Failed to get safe proposals", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, proposals)
+}
+
+func (h *TreasuryHandler) GetProposalHandler(w http.ResponseWriter, r *http.Request) {
+	proposal, err := h.service.GetProposal(r.Context(), mux.Vars(r)["safeTxHash"])
+	if errors.Is(err, usecases.ErrSafeProposalNotFound) {
+		http.Error(w, "Safe proposal not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to get safe proposal", "error", err)
+		http.Error(w, "Failed to get safe proposal", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, h.logger, proposal)
+}
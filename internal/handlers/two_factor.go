@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// Заголовок, в котором клиент передаёт код второго фактора для защищённых операций
+const TwoFactorCodeHeader = "X-2FA-Code"
+
+// Операции, требующие подтверждения вторым фактором
+const (
+	OperationWalletTransfer    = "wallet_transfer"
+	OperationAccountClosure    = "account_closure"
+	OperationSettlementAccount = "settlement_account"
+	OperationFiatPayout        = "fiat_payout"
+
+	// Действия администратора, перемещающие средства
+	OperationRefundExecution           = "refund_execution"
+	OperationTransactionReplacement    = "transaction_replacement"
+	OperationDustConsolidation         = "dust_consolidation"
+	OperationTokenAllowance            = "token_allowance"
+	OperationWithdrawalBatch           = "withdrawal_batch"
+	OperationKeyRotation               = "key_rotation"
+	OperationRunbook                   = "runbook"
+	OperationMerchantSettlementAccount = "merchant_settlement_account"
+)
+
+type TwoFactorService interface {
+	Enroll(ctx context.Context, userID int64) (*entities.TwoFactorEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userID int64, code string) error
+	VerifyOperation(ctx context.Context, userID int64, operation, code string) error
+	VerifyAdminOperation(ctx context.Context, actor, operation, code string) error
+}
+
+var _ TwoFactorService = (*usecases.TwoFactorService)(nil)
+
+type TwoFactorHandler struct {
+	logger   *slog.Logger
+	service  TwoFactorService
+	sessions *SessionHandler
+}
+
+// NewTwoFactorHandler creates a new handler. Enrollment is available only within an authenticated session.
+func NewTwoFactorHandler(logger *slog.Logger, service TwoFactorService, sessions *SessionHandler) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		logger:   logger,
+		service:  service,
+		sessions: sessions,
+	}
+}
+
+func (h *TwoFactorHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/2fa/enroll", h.sessions.Authenticated(h.EnrollHandler)).Methods("POST")
+	router.HandleFunc("/2fa/confirm", h.sessions.Authenticated(h.ConfirmHandler)).Methods("POST")
+}
+
+// EnrollHandler enrolls the user of the authenticated session, never the user_id parameter:
+// otherwise anyone could enroll a secret for a user who has not enrolled yet
+func (h *TwoFactorHandler) EnrollHandler(w http.ResponseWriter, r *http.Request) {
+	userID := currentSession(r).UserID
+
+	enrollment, err := h.service.Enroll(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to enroll two-factor authentication", "error", err, "user_id", userID)
+		http.Error(w, "Failed to enroll two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err = json.NewEncoder(w).Encode(enrollment); err != nil {
+		h.logger.Error("Failed to encode enrollment", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *TwoFactorHandler) ConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	userID := currentSession(r).UserID
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		http.Error(w, "Missing required parameters: code", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ConfirmEnrollment(r.Context(), userID, code); err != nil {
+		h.writeError(w, err, "user_id", userID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResourceOwner returns the user who owns the resource the request acts on
+type ResourceOwner func(r *http.Request) (int64, error)
+
+// RequestingUser is the owner of routes that act only on the resources of the user_id query parameter
+func RequestingUser(r *http.Request) (int64, error) {
+	return strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+}
+
+// RequireSecondFactor wraps a handler that moves funds and rejects requests without a valid second factor.
+// The user is taken from the user_id query parameter and must be the owner of the resource the request acts on,
+// the code from the X-2FA-Code header.
+func (h *TwoFactorHandler) RequireSecondFactor(operation string, owner ResourceOwner, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := parseUserID(w, r)
+		if !ok {
+			return
+		}
+
+		ownerID, err := owner(r)
+		if err != nil {
+			h.writeError(w, err, "user_id", userID)
+			return
+		}
+		if ownerID != userID {
+			h.writeError(w, usecases.ErrTwoFactorOwnerMismatch, "user_id", userID)
+			return
+		}
+
+		err = h.service.VerifyOperation(r.Context(), userID, operation, r.Header.Get(TwoFactorCodeHeader))
+		if err != nil {
+			h.writeError(w, err, "user_id", userID)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// RequireAdminSecondFactor wraps an admin handler that moves funds. The operator of the request
+// (client certificate CN or IP, see adminActor) has to confirm it with a TOTP code in the X-2FA-Code header.
+func (h *TwoFactorHandler) RequireAdminSecondFactor(operation string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actor := adminActor(r)
+		if err := h.service.VerifyAdminOperation(r.Context(), actor, operation, r.Header.Get(TwoFactorCodeHeader)); err != nil {
+			h.writeError(w, err, "actor", actor, "operation", operation)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (h *TwoFactorHandler) writeError(w http.ResponseWriter, err error, logArgs ...any) {
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &numErr):
+		http.Error(w, "Invalid resource ID format", http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrWalletNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecases.ErrTwoFactorOwnerMismatch):
+		h.logger.Warn("Second factor user does not own the resource", logArgs...)
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, usecases.ErrTwoFactorRequired),
+		errors.Is(err, usecases.ErrTwoFactorInvalidCode):
+		h.logger.Warn("Two-factor verification failed", append([]any{"error", err}, logArgs...)...)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, usecases.ErrTwoFactorNotEnrolled),
+		errors.Is(err, usecases.ErrTwoFactorEnrollmentRequired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		h.logger.Error("Two-factor verification error", append([]any{"error", err}, logArgs...)...)
+		http.Error(w, "Failed to verify second factor", http.StatusInternalServerError)
+	}
+}
+
+func parseUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userIDParam := r.URL.Query().Get("user_id")
+	if userIDParam == "" {
+		http.Error(w, "Missing required parameters: user_id", http.StatusBadRequest)
+		return 0, false
+	}
+
+	userID, err := strconv.ParseInt(userIDParam, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// stubTwoFactorService принимает код оператора "123456" и запоминает проверенные операции
+type stubTwoFactorService struct {
+	TwoFactorService
+	operations []string
+	enrolled   []int64
+}
+
+func (s *stubTwoFactorService) Enroll(_ context.Context, userID int64) (*entities.TwoFactorEnrollment, error) {
+	s.enrolled = append(s.enrolled, userID)
+	return &entities.TwoFactorEnrollment{Secret: "JBSWY3DPEHPK3PXP"}, nil
+}
+
+func (s *stubTwoFactorService) VerifyAdminOperation(_ context.Context, _, operation, code string) error {
+	s.operations = append(s.operations, operation)
+	switch code {
+	case "":
+		return usecases.ErrTwoFactorRequired
+	case "123456":
+		return nil
+	default:
+		return usecases.ErrTwoFactorInvalidCode
+	}
+}
+
+func TestAdminRoutesMovingFundsRequireSecondFactor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := &stubTwoFactorService{}
+	twoFactor := NewTwoFactorHandler(logger, service, nil)
+
+	admin := mux.NewRouter().PathPrefix("/admin").Subrouter()
+	for _, registrar := range []AdminRoutesRegistrar{
+		NewRefundHandler(logger, nil, twoFactor),
+		NewStuckTransactionsHandler(logger, nil, nil, twoFactor),
+		NewBNBDustHandler(logger, nil, twoFactor),
+		NewTokenApprovalHandler(logger, nil, twoFactor),
+		NewWithdrawalBatchesHandler(logger, nil, twoFactor),
+		NewKeyRotationsHandler(logger, nil, twoFactor),
+		NewRunbooksHandler(logger, nil, twoFactor),
+		NewSettlementHandler(logger, nil, twoFactor),
+	} {
+		registrar.RegisterAdminRoutes(admin)
+	}
+
+	routes := []struct {
+		method    string
+		path      string
+		operation string
+	}{
+		{http.MethodPost, "/admin/refunds/refund-1/execute", OperationRefundExecution},
+		{http.MethodPost, "/admin/transactions/0xabc/bump", OperationTransactionReplacement},
+		{http.MethodPost, "/admin/transactions/0xabc/cancel", OperationTransactionReplacement},
+		{http.MethodPost, "/admin/sweeps/bnb-dust/consolidate", OperationDustConsolidation},
+		{http.MethodPost, "/admin/allowances", OperationTokenAllowance},
+		{http.MethodPost, "/admin/allowances/1/revoke", OperationTokenAllowance},
+		{http.MethodPost, "/admin/treasury/withdrawal-batches/run", OperationWithdrawalBatch},
+		{http.MethodPost, "/admin/key_rotations/1/activate", OperationKeyRotation},
+		{http.MethodPost, "/admin/key_rotations/1/sweeps", OperationKeyRotation},
+		{http.MethodPost, "/admin/runbooks/rerun_aml", OperationRunbook},
+		{http.MethodPut, "/admin/merchants/1/settlement_account", OperationMerchantSettlementAccount},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			service.operations = nil
+
+			rec := httptest.NewRecorder()
+			admin.ServeHTTP(rec, httptest.NewRequest(route.method, route.path, nil))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+			req := httptest.NewRequest(route.method, route.path, nil)
+			req.Header.Set(TwoFactorCodeHeader, "000000")
+			rec = httptest.NewRecorder()
+			admin.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+			assert.Equal(t, []string{route.operation, route.operation}, service.operations)
+		})
+	}
+}
+
+func TestTwoFactorEnrollUsesSessionUser(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sessions := NewSessionHandler(logger, &stubSessionService{sessions: map[string]*entities.Session{
+		"valid-token": {ID: "session-1", UserID: 42},
+	}})
+	service := &stubTwoFactorService{}
+	router := mux.NewRouter()
+	NewTwoFactorHandler(logger, service, sessions).RegisterRoutes(router)
+
+	tests := []struct {
+		name          string
+		url           string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "no session", url: "/2fa/enroll?user_id=42", wantStatus: http.StatusUnauthorized},
+		{name: "other user", url: "/2fa/enroll?user_id=7", authorization: "Bearer valid-token", wantStatus: http.StatusForbidden},
+		{name: "session user", url: "/2fa/enroll", authorization: "Bearer valid-token", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.url, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+
+	assert.Equal(t, []int64{42}, service.enrolled)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// maxWalletImportBody ограничивает размер CSV: тысяча строк адреса и пути укладывается с запасом
+const maxWalletImportBody = 1 << 20
+
+type WalletImportService interface {
+	ImportRange(ctx context.Context, userID int64, fromIndex, toIndex uint32, fromBlock int64, requestedBy string) (*entities.WalletImport, error)
+	ImportCSV(ctx context.Context, data io.Reader, fromBlock int64, requestedBy string) (*entities.WalletImport, error)
+	GetImport(ctx context.Context, id int64) (*entities.WalletImport, error)
+	GetImports(ctx context.Context) ([]entities.WalletImport, error)
+}
+
+var _ WalletImportService = (*usecases.WalletImportService)(nil)
+
+// WalletImportHandler принимает от администраторов кошельки предыдущей системы и показывает ход догрузки их истории
+type WalletImportHandler struct {
+	logger  *slog.Logger
+	service WalletImportService
+}
+
+func NewWalletImportHandler(logger *slog.Logger, service WalletImportService) *WalletImportHandler {
+	return &WalletImportHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *WalletImportHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/wallets/imports", h.ImportHandler).Methods("POST")
+	admin.HandleFunc("/wallets/imports", h.GetImportsHandler).Methods("GET")
+	admin.HandleFunc("/wallets/imports/{id:[0-9]+}", h.GetImportHandler).Methods("GET")
+}
+
+type walletImportRangeRequest struct {
+	UserID    int64  `json:"user_id"`
+	FromIndex uint32 `json:"from_index"`
+	ToIndex   uint32 `json:"to_index"`
+	FromBlock int64  `json:"from_block"`
+}
+
+// ImportHandler accepts a JSON range of derivation indexes, or a text/csv body of address,derivation_path
+// rows with the from_block query parameter
+func (h *WalletImportHandler) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxWalletImportBody)
+
+	var (
+		walletImport *entities.WalletImport
+		err          error
+	)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		fromBlock, parseErr := strconv.ParseInt(r.URL.Query().Get("from_block"), 10, 64)
+		if parseErr != nil {
+			http.Error(w, "Invalid from_block parameter", http.StatusBadRequest)
+			return
+		}
+		walletImport, err = h.service.ImportCSV(r.Context(), body, fromBlock, adminActor(r))
+	} else {
+		var req walletImportRangeRequest
+		if err = json.NewDecoder(body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		walletImport, err = h.service.ImportRange(r.Context(), req.UserID, req.FromIndex, req.ToIndex, req.FromBlock, adminActor(r))
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(walletImport); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *WalletImportHandler) GetImportsHandler(w http.ResponseWriter, r *http.Request) {
+	imports, err := h.service.GetImports(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, imports)
+}
+
+func (h *WalletImportHandler) GetImportHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid import ID format", http.StatusBadRequest)
+		return
+	}
+
+	walletImport, err := h.service.GetImport(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, walletImport)
+}
+
+func (h *WalletImportHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidWalletImport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecases.ErrWalletImportNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		h.logger.ErrorContext(r.Context(), "Wallet import request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
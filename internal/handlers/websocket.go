@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"compress/flate"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,22 +14,38 @@ const (
 	defaultBufferSize = 1024 // 1KB buffer size for WebSocket connections
 )
 
+// WebSocketConfig configures permessage-deflate compression of WebSocket frames
+type WebSocketConfig struct {
+	Compression bool
+	// Уровень flate от 1 (быстрее) до 9 (сильнее)
+	CompressionLevel int
+}
+
 type Manager struct {
-	upgrader websocket.Upgrader
-	logger   *slog.Logger
+	upgrader         websocket.Upgrader
+	compressionLevel int
+	logger           *slog.Logger
 }
 
-func NewWebSocketManager(logger *slog.Logger) *Manager {
+func NewWebSocketManager(logger *slog.Logger, config WebSocketConfig) (*Manager, error) {
+	if config.Compression && (config.CompressionLevel < flate.BestSpeed || config.CompressionLevel > flate.BestCompression) {
+		return nil, fmt.Errorf("websocket compression level must be between %d and %d, got %d",
+			flate.BestSpeed, flate.BestCompression, config.CompressionLevel)
+	}
+
 	return &Manager{
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  defaultBufferSize,
 			WriteBufferSize: defaultBufferSize,
+			// Сжатие включается, только если клиент предложил permessage-deflate при подключении
+			EnableCompression: config.Compression,
 			CheckOrigin: func(_ *http.Request) bool {
 				return true // Allow connections from any origin
 			},
 		},
-		logger: logger,
-	}
+		compressionLevel: config.CompressionLevel,
+		logger:           logger,
+	}, nil
 }
 
 func (m *Manager) Upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
@@ -38,6 +55,13 @@ func (m *Manager) Upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Co
 		return nil, fmt.Errorf("websocket upgrade error: %w", err)
 	}
 
+	if m.upgrader.EnableCompression {
+		if err = conn.SetCompressionLevel(m.compressionLevel); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("websocket compression level: %w", err)
+		}
+	}
+
 	// Set handler for connection closure
 	conn.SetCloseHandler(func(code int, text string) error {
 		m.logger.Info("WebSocket connection closed", "code", code, "text", text)
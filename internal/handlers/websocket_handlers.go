@@ -3,10 +3,12 @@ package handlers
 import (
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/mocked"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 )
 
 type WebSocketHandler struct {
@@ -28,15 +30,18 @@ func NewWebSocketHandler(
 }
 
 func (h *WebSocketHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ws", h.HandleBatchConnection)
+	router.HandleFunc("/ws/meta", h.HandleMetaConnection)
 	router.HandleFunc("/ws/{symbol}", h.HandleConnection)
 }
 
+// HandleConnection streams updates of one pair, one frame per update
 func (h *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	symbol := vars["symbol"]
 
 	// Check if the trading pair exists
-	_, exists := h.dataService.TradingPairs[symbol]
+	_, exists := h.dataService.Pair(symbol)
 	if !exists {
 		http.Error(w, "Trading pair not found", http.StatusNotFound)
 		return
@@ -58,15 +63,67 @@ func (h *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Keep connection open and handle disconnection
+	h.serve(conn, symbol)
+}
+
+// HandleBatchConnection streams updates of the pairs listed in ?symbols=BTCRUB,ETHRUB.
+// Updates of all pairs are sent together as {"updates": [...]} once per batch interval.
+func (h *WebSocketHandler) HandleBatchConnection(w http.ResponseWriter, r *http.Request) {
+	var symbols []string
+	for _, symbol := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+		if symbol = strings.TrimSpace(symbol); symbol == "" {
+			continue
+		}
+		if _, exists := h.dataService.Pair(symbol); !exists {
+			http.Error(w, "Trading pair not found: "+symbol, http.StatusNotFound)
+			return
+		}
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		http.Error(w, "symbols parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := h.websocketManager.Upgrade(w, r)
+	if err != nil {
+		h.logger.Error("Error upgrading connection", "error", err)
+		return
+	}
+
+	h.logger.Info("New batched WebSocket connection", "symbols", symbols)
+
+	if err = h.dataService.AddBatchSubscriber(symbols, conn); err != nil {
+		h.logger.Error("Error adding subscriber", "error", err)
+		conn.Close()
+		return
+	}
+
+	h.serve(conn, strings.Join(symbols, ","))
+}
+
+// HandleMetaConnection streams meta events, e.g. {"type": "pairs_updated", "pairs": [...]} when pairs are added
+// or disabled, so clients refresh the pair list without polling /data/pairs.
+func (h *WebSocketHandler) HandleMetaConnection(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.websocketManager.Upgrade(w, r)
+	if err != nil {
+		h.logger.Error("Error upgrading connection", "error", err)
+		return
+	}
+
+	h.logger.Info("New meta WebSocket connection")
+
+	h.dataService.AddMetaSubscriber(conn)
+	h.serve(conn, "meta")
+}
+
+// serve keeps the connection open until the client disconnects
+func (h *WebSocketHandler) serve(conn *websocket.Conn, symbols string) {
 	for {
 		_, _, readErr := conn.ReadMessage()
 		if readErr != nil {
-			h.logger.Error("WebSocket connection closed", "symbol", symbol, "error", readErr)
-			removeErr := h.dataService.RemoveSubscriber(symbol, conn)
-			if removeErr != nil {
-				h.logger.Error("Error removing subscriber", "error", removeErr)
-			}
+			h.logger.Error("WebSocket connection closed", "symbol", symbols, "error", readErr)
+			h.dataService.RemoveSubscriber(conn)
 			break
 		}
 	}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type WithdrawalBatchService interface {
+	GetBatches(ctx context.Context, status entities.WithdrawalBatchStatus) ([]entities.WithdrawalBatch, error)
+	GetBatch(ctx context.Context, id string) (*entities.WithdrawalBatchDetails, error)
+	RunBatches(ctx context.Context) (int, error)
+}
+
+var _ WithdrawalBatchService = (*usecases.WithdrawalBatchService)(nil)
+
+// WithdrawalBatchesHandler показывает операторам пакеты выводов с состоянием каждого вывода и запускает отправку очереди
+type WithdrawalBatchesHandler struct {
+	logger    *slog.Logger
+	service   WithdrawalBatchService
+	twoFactor *TwoFactorHandler
+}
+
+func NewWithdrawalBatchesHandler(logger *slog.Logger, service WithdrawalBatchService, twoFactor *TwoFactorHandler) *WithdrawalBatchesHandler {
+	return &WithdrawalBatchesHandler{
+		logger:    logger,
+		service:   service,
+		twoFactor: twoFactor,
+	}
+}
+
+func (h *WithdrawalBatchesHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/treasury/withdrawal-batches", h.GetBatchesHandler).Methods("GET")
+	admin.HandleFunc("/treasury/withdrawal-batches/run", h.twoFactor.RequireAdminSecondFactor(OperationWithdrawalBatch, h.RunBatchesHandler)).Methods("POST")
+	admin.HandleFunc("/treasury/withdrawal-batches/{id}", h.GetBatchHandler).Methods("GET")
+}
+
+func (h *WithdrawalBatchesHandler) GetBatchesHandler(w http.ResponseWriter, r *http.Request) {
+	status := entities.WithdrawalBatchStatus(r.URL.Query().Get("status"))
+
+	batches, err := h.service.GetBatches(r.Context(), status)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, batches)
+}
+
+func (h *WithdrawalBatchesHandler) GetBatchHandler(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.service.GetBatch(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, batch)
+}
+
+// RunBatchesHandler sends the queued withdrawals now instead of waiting for the next scheduled batch
+func (h *WithdrawalBatchesHandler) RunBatchesHandler(w http.ResponseWriter, r *http.Request) {
+	sent, err := h.service.RunBatches(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Withdrawal batch run requested", "actor", adminActor(r), "sent", sent)
+	writeJSON(w, h.logger, map[string]int{"sent": sent})
+}
+
+func (h *WithdrawalBatchesHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrWithdrawalBatchNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		h.logger.ErrorContext(r.Context(), "Withdrawal batch request failed", "error", err, "actor", adminActor(r))
+		http.Error(w, "Internal server error", errorStatus(err))
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type WithdrawalLimitsService interface {
+	GetLimits(ctx context.Context, userID int64) (*entities.WithdrawalLimits, error)
+	SetUserTier(ctx context.Context, userID int64, tier, actor string) error
+}
+
+var _ WithdrawalLimitsService = (*usecases.WithdrawalLimitService)(nil)
+
+// WithdrawalLimitsHandler отдает пользователям оставшийся лимит вывода, администраторам — назначение уровней
+type WithdrawalLimitsHandler struct {
+	logger  *slog.Logger
+	service WithdrawalLimitsService
+}
+
+func NewWithdrawalLimitsHandler(logger *slog.Logger, service WithdrawalLimitsService) *WithdrawalLimitsHandler {
+	return &WithdrawalLimitsHandler{
+		logger:  logger,
+		service: service,
+	}
+}
+
+func (h *WithdrawalLimitsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/withdrawals/limits", h.GetLimitsHandler).Methods("GET")
+}
+
+func (h *WithdrawalLimitsHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/users/{userId:[0-9]+}/withdrawal_limits", h.GetUserLimitsHandler).Methods("GET")
+	admin.HandleFunc("/users/{userId:[0-9]+}/withdrawal_tier", h.SetUserTierHandler).Methods("PUT")
+}
+
+type setWithdrawalTierRequest struct {
+	Tier string `json:"tier"`
+}
+
+func (h *WithdrawalLimitsHandler) GetLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	limits, err := h.service.GetLimits(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, limits)
+}
+
+func (h *WithdrawalLimitsHandler) GetUserLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	limits, err := h.service.GetLimits(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, limits)
+}
+
+func (h *WithdrawalLimitsHandler) SetUserTierHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req setWithdrawalTierRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.SetUserTier(r.Context(), userID, req.Tier, adminActor(r)); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	limits, err := h.service.GetLimits(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, h.logger, limits)
+}
+
+func (h *WithdrawalLimitsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrUnknownWithdrawalTier):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.ErrorContext(r.Context(), "Withdrawal limits request failed", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
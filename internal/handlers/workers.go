@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+type WorkerRegistry interface {
+	Workers() []entities.WorkerStatus
+	Stalled() []string
+}
+
+var _ WorkerRegistry = (*usecases.WorkerRegistry)(nil)
+
+// WorkersHandler exposes the background worker registry: the full report under /admin/workers
+// and the readiness check that fails while any worker is stalled
+type WorkersHandler struct {
+	logger   *slog.Logger
+	registry WorkerRegistry
+}
+
+func NewWorkersHandler(logger *slog.Logger, registry WorkerRegistry) *WorkersHandler {
+	return &WorkersHandler{
+		logger:   logger,
+		registry: registry,
+	}
+}
+
+func (h *WorkersHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ready", h.ReadyHandler).Methods("GET")
+}
+
+func (h *WorkersHandler) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/workers", h.GetWorkersHandler).Methods("GET")
+}
+
+// ReadyHandler responds 503 with the names of stalled workers, so the instance is taken out of rotation
+func (h *WorkersHandler) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	stalled := h.registry.Stalled()
+
+	response := map[string]any{"ready": len(stalled) == 0}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if len(stalled) > 0 {
+		response["stalled_workers"] = stalled
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+func (h *WorkersHandler) GetWorkersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.registry.Workers()); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// ErrInvalidDestination возвращается, если реквизиты получателя не подходят способу выплаты
+var ErrInvalidDestination = errors.New("invalid payout destination")
+
+var (
+	bicPattern           = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	accountNumberPattern = regexp.MustCompile(`^[A-Z0-9]{6,34}$`)
+	ibanPattern          = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	sbpPhonePattern      = regexp.MustCompile(`^\+7[0-9]{10}$`)
+	sbpBankIDPattern     = regexp.MustCompile(`^1[0-9]{11}$`)
+)
+
+// NewBankTransferProvider создает заглушку банковского перевода по номеру счета и SWIFT/BIC
+func NewBankTransferProvider(logger *slog.Logger, currencies []string, settleAfter time.Duration) *StubProvider {
+	return newStubProvider(logger, entities.FiatPayoutBankTransfer, currencies, settleAfter, func(destination entities.FiatPayoutDestination) error {
+		if !accountNumberPattern.MatchString(normalize(destination.AccountNumber)) {
+			return fmt.Errorf("%w: invalid account number", ErrInvalidDestination)
+		}
+		if !bicPattern.MatchString(normalize(destination.BIC)) {
+			return fmt.Errorf("%w: invalid BIC", ErrInvalidDestination)
+		}
+		return nil
+	})
+}
+
+// NewSEPAProvider создает заглушку SEPA перевода, выплаты только в евро
+func NewSEPAProvider(logger *slog.Logger, settleAfter time.Duration) *StubProvider {
+	return newStubProvider(logger, entities.FiatPayoutSEPA, []string{"EUR"}, settleAfter, func(destination entities.FiatPayoutDestination) error {
+		if !validIBAN(destination.IBAN) {
+			return fmt.Errorf("%w: invalid IBAN", ErrInvalidDestination)
+		}
+		if destination.BIC != "" && !bicPattern.MatchString(normalize(destination.BIC)) {
+			return fmt.Errorf("%w: invalid BIC", ErrInvalidDestination)
+		}
+		return nil
+	})
+}
+
+// NewSBPProvider создает заглушку выплат через СБП по номеру телефона, выплаты только в рублях
+func NewSBPProvider(logger *slog.Logger, settleAfter time.Duration) *StubProvider {
+	return newStubProvider(logger, entities.FiatPayoutSBP, []string{"RUB"}, settleAfter, func(destination entities.FiatPayoutDestination) error {
+		if !sbpPhonePattern.MatchString(strings.TrimSpace(destination.Phone)) {
+			return fmt.Errorf("%w: phone must be in the form +7XXXXXXXXXX", ErrInvalidDestination)
+		}
+		if !sbpBankIDPattern.MatchString(strings.TrimSpace(destination.BankID)) {
+			return fmt.Errorf("%w: invalid SBP bank ID", ErrInvalidDestination)
+		}
+		return nil
+	})
+}
+
+// validIBAN проверяет формат и контрольную сумму IBAN (ISO 13616, mod 97)
+func validIBAN(iban string) bool {
+	iban = normalize(iban)
+	if !ibanPattern.MatchString(iban) {
+		return false
+	}
+
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	value, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(value, big.NewInt(97)).Int64() == 1
+}
+
+func normalize(value string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+}
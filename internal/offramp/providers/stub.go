@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// StubProvider имитирует провайдера выплат: принимает любую выплату с корректными реквизитами
+// и сообщает об исполнении через settleAfter. Время создания закодировано в идентификаторе выплаты,
+// поэтому статус восстанавливается и после перезапуска.
+type StubProvider struct {
+	logger      *slog.Logger
+	method      entities.FiatPayoutMethod
+	currencies  map[string]bool
+	settleAfter time.Duration
+	validate    func(destination entities.FiatPayoutDestination) error
+}
+
+func newStubProvider(
+	logger *slog.Logger,
+	method entities.FiatPayoutMethod,
+	currencies []string,
+	settleAfter time.Duration,
+	validate func(destination entities.FiatPayoutDestination) error,
+) *StubProvider {
+	supported := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		supported[strings.ToUpper(currency)] = true
+	}
+
+	logger.Warn("Fiat payout provider is a stub, payouts are not sent to a bank", "method", method)
+
+	return &StubProvider{
+		logger:      logger,
+		method:      method,
+		currencies:  supported,
+		settleAfter: settleAfter,
+		validate:    validate,
+	}
+}
+
+// Method возвращает способ выплаты, который обслуживает провайдер
+func (p *StubProvider) Method() entities.FiatPayoutMethod {
+	return p.method
+}
+
+// SupportsCurrency сообщает, может ли провайдер выплатить сумму в валюте
+func (p *StubProvider) SupportsCurrency(currency string) bool {
+	return p.currencies[strings.ToUpper(currency)]
+}
+
+// ValidateDestination проверяет реквизиты получателя до создания выплаты
+func (p *StubProvider) ValidateDestination(destination entities.FiatPayoutDestination) error {
+	if strings.TrimSpace(destination.AccountHolder) == "" {
+		return fmt.Errorf("%w: account holder is required", ErrInvalidDestination)
+	}
+	return p.validate(destination)
+}
+
+// CreatePayout регистрирует выплату у провайдера. ID выплаты служит ключом идемпотентности.
+func (p *StubProvider) CreatePayout(ctx context.Context, payout *entities.FiatPayout) (*entities.FiatPayoutUpdate, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate payout reference: %w", err)
+	}
+	ref := fmt.Sprintf("%s-%d-%s", p.method, time.Now().UnixNano(), hex.EncodeToString(suffix))
+
+	p.logger.InfoContext(ctx, "Stub fiat payout accepted",
+		"method", p.method, "payout_id", payout.ID, "provider_ref", ref, "amount", payout.FiatAmount, "currency", payout.Currency)
+
+	return &entities.FiatPayoutUpdate{ProviderRef: ref, Status: entities.FiatPayoutProcessing}, nil
+}
+
+// GetPayoutStatus возвращает состояние выплаты по идентификатору провайдера
+func (p *StubProvider) GetPayoutStatus(_ context.Context, ref string) (*entities.FiatPayoutUpdate, error) {
+	parts := strings.Split(ref, "-")
+	if len(parts) != 3 || parts[0] != string(p.method) {
+		return &entities.FiatPayoutUpdate{ProviderRef: ref, Status: entities.FiatPayoutFailed, FailureReason: "unknown payout reference"}, nil
+	}
+	createdAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return &entities.FiatPayoutUpdate{ProviderRef: ref, Status: entities.FiatPayoutFailed, FailureReason: "unknown payout reference"}, nil
+	}
+
+	if time.Since(time.Unix(0, createdAt)) < p.settleAfter {
+		return &entities.FiatPayoutUpdate{ProviderRef: ref, Status: entities.FiatPayoutProcessing}, nil
+	}
+	return &entities.FiatPayoutUpdate{ProviderRef: ref, Status: entities.FiatPayoutCompleted}, nil
+}
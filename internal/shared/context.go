@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey — собственный тип ключей контекста, чтобы не пересекаться с ключами других пакетов
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	txIDKey
+	userIDKey
+)
+
+// WithRequestID returns a context carrying the request (correlation) ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request (correlation) ID from the context or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithTxID returns a context carrying the internal ID of an outgoing blockchain transaction
+func WithTxID(ctx context.Context, txID string) context.Context {
+	return context.WithValue(ctx, txIDKey, txID)
+}
+
+// TxID returns the internal transaction ID from the context or an empty string
+func TxID(ctx context.Context) string {
+	id, _ := ctx.Value(txIDKey).(string)
+	return id
+}
+
+// WithUserID returns a context carrying the ID of the user the request is made for
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the user ID from the context
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey).(int64)
+	return id, ok
+}
+
+// LogAttrs returns the correlation attributes stored in the context, for use by the logging handler
+func LogAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if id := RequestID(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if id := TxID(ctx); id != "" {
+		attrs = append(attrs, slog.String("tx_id", id))
+	}
+	if id, ok := UserID(ctx); ok {
+		attrs = append(attrs, slog.Int64("user_id", id))
+	}
+	return attrs
+}
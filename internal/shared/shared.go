@@ -3,6 +3,7 @@ package shared
 import (
 	"os"
 	"strings"
+	"sync/atomic"
 )
 
 const EnvBlockchainDebugMode = "BLOCKCHAIN_DEBUG_MODE"
@@ -12,3 +13,27 @@ func IsBlockchainDebugMode() bool {
 	debugMode := os.Getenv(EnvBlockchainDebugMode)
 	return strings.ToLower(debugMode) == "true" || strings.ToLower(debugMode) == "1"
 }
+
+// SimulatedChain описывает локальную симулированную сеть, заменяющую BSC в режиме симуляции
+type SimulatedChain struct {
+	HTTPURL      string
+	WSURL        string
+	TokenAddress string
+}
+
+var simulatedChain atomic.Pointer[SimulatedChain]
+
+// UseSimulatedChain routes all BSC connections to the simulated chain. It must be called before
+// any BSC client is created.
+func UseSimulatedChain(chain SimulatedChain) {
+	simulatedChain.Store(&chain)
+}
+
+// Simulated returns the simulated chain if simulation mode is enabled
+func Simulated() (SimulatedChain, bool) {
+	chain := simulatedChain.Load()
+	if chain == nil {
+		return SimulatedChain{}, false
+	}
+	return *chain, true
+}
@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// Действия, на которые распространяются ограничения
+const (
+	AbuseActionWalletGeneration = "wallet_generation"
+	AbuseActionOrderCreation    = "order_creation"
+	// Пакетное создание ордеров мерчантом не ограничено числом ожидающих ордеров, только кулдауном и CAPTCHA
+	AbuseActionBatchOrderCreation = "batch_order_creation"
+)
+
+// Размер карты кулдаунов, после которого из неё удаляются истёкшие записи
+const cooldownPruneThreshold = 10000
+
+type PendingOrdersCounter interface {
+	CountPendingOrders(ctx context.Context, userID int64) (int, error)
+}
+
+type UnusedWalletsCounter interface {
+	CountUnusedWallets(ctx context.Context, userID int64) (int, error)
+}
+
+// ClosingAccountsChecker сообщает, запросил ли пользователь закрытие аккаунта
+type ClosingAccountsChecker interface {
+	IsClosing(ctx context.Context, userID int64) (bool, error)
+}
+
+var (
+	_ PendingOrdersCounter   = (*repository.OrdersRepository)(nil)
+	_ UnusedWalletsCounter   = (*repository.WalletsRepository)(nil)
+	_ ClosingAccountsChecker = (*repository.AccountClosuresRepository)(nil)
+)
+
+// CaptchaVerifier проверяет токен CAPTCHA, полученный клиентом
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// AbuseGuardLimits задаёт ограничения на создание кошельков и ордеров. Нулевое значение отключает ограничение.
+type AbuseGuardLimits struct {
+	MaxPendingOrders int
+	MaxUnusedWallets int
+	Cooldown         time.Duration
+}
+
+// CooldownError is returned when the user repeats the action too early
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *CooldownError) Unwrap() error {
+	return ErrTooManyRequests
+}
+
+// AbuseGuard protects wallet generation and order creation from clients
+// that try to exhaust derivation indexes and bloat the monitored wallet set.
+type AbuseGuard struct {
+	logger  *slog.Logger
+	orders  PendingOrdersCounter
+	wallets UnusedWalletsCounter
+	closing ClosingAccountsChecker
+	captcha CaptchaVerifier
+	limits  AbuseGuardLimits
+
+	mu        sync.Mutex
+	cooldowns map[string]time.Time
+}
+
+// NewAbuseGuard creates a new abuse guard. captcha may be nil, in that case no CAPTCHA is required.
+func NewAbuseGuard(logger *slog.Logger, orders PendingOrdersCounter, wallets UnusedWalletsCounter, closing ClosingAccountsChecker, captcha CaptchaVerifier, limits AbuseGuardLimits) *AbuseGuard {
+	return &AbuseGuard{
+		logger:    logger,
+		orders:    orders,
+		wallets:   wallets,
+		closing:   closing,
+		captcha:   captcha,
+		limits:    limits,
+		cooldowns: make(map[string]time.Time),
+	}
+}
+
+// Check verifies that the user is allowed to perform the action right now.
+// A successful check starts the cooldown for the user and action.
+func (g *AbuseGuard) Check(ctx context.Context, userID int64, action, captchaToken, remoteIP string) error {
+	// Закрываемый аккаунт не получает новых кошельков и ордеров, независимо от лимитов
+	closing, err := g.closing.IsClosing(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if closing {
+		return ErrAccountClosed
+	}
+
+	if g.captcha != nil {
+		if captchaToken == "" {
+			return ErrCaptchaRequired
+		}
+		if err := g.captcha.Verify(ctx, captchaToken, remoteIP); err != nil {
+			g.logger.WarnContext(ctx, "Captcha verification failed", "error", err, "user_id", userID, "action", action)
+			return fmt.Errorf("%w: %v", ErrCaptchaInvalid, err)
+		}
+	}
+
+	if g.limits.MaxUnusedWallets > 0 {
+		unused, err := g.wallets.CountUnusedWallets(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if unused >= g.limits.MaxUnusedWallets {
+			g.logger.WarnContext(ctx, "Unused wallets limit reached", "user_id", userID, "action", action, "unused", unused)
+			return ErrTooManyUnusedWallets
+		}
+	}
+
+	if action == AbuseActionOrderCreation && g.limits.MaxPendingOrders > 0 {
+		pending, err := g.orders.CountPendingOrders(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if pending >= g.limits.MaxPendingOrders {
+			g.logger.WarnContext(ctx, "Pending orders limit reached", "user_id", userID, "pending", pending)
+			return ErrTooManyPendingOrders
+		}
+	}
+
+	return g.reserveCooldown(userID, action)
+}
+
+func (g *AbuseGuard) reserveCooldown(userID int64, action string) error {
+	if g.limits.Cooldown <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d", action, userID)
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until, ok := g.cooldowns[key]; ok && now.Before(until) {
+		return &CooldownError{RetryAfter: until.Sub(now)}
+	}
+
+	if len(g.cooldowns) >= cooldownPruneThreshold {
+		for k, until := range g.cooldowns {
+			if now.After(until) {
+				delete(g.cooldowns, k)
+			}
+		}
+	}
+
+	g.cooldowns[key] = now.Add(g.limits.Cooldown)
+	return nil
+}
@@ -0,0 +1,400 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	accountClosuresListLimit = 100
+	// Отправленный свип должен успеть подтвердиться, прежде чем остаток на кошельке будет отправлен повторно
+	closureSweepWait = 10 * time.Minute
+	// Инициатор переводов и обезличивания в журнале аудита
+	closureWorkerActor = "worker:account_closure"
+)
+
+type AccountClosuresRepository interface {
+	CreateClosure(ctx context.Context, closure *entities.AccountClosure) (bool, error)
+	FindByUserID(ctx context.Context, userID int64) (*entities.AccountClosure, error)
+	FindByStatus(ctx context.Context, status entities.AccountClosureStatus, limit int) ([]entities.AccountClosure, error)
+	FindDueErasures(ctx context.Context, now time.Time, limit int) ([]entities.AccountClosure, error)
+	CountBlockers(ctx context.Context, userID int64) (entities.AccountClosureBlockers, error)
+	UpdateStatus(ctx context.Context, id string, status entities.AccountClosureStatus, errorMessage *string) error
+	MarkSwept(ctx context.Context, id string) error
+	MarkClosed(ctx context.Context, closure *entities.AccountClosure) error
+	MarkErased(ctx context.Context, id string) error
+	SetError(ctx context.Context, id, errorMessage string) error
+}
+
+type ClosureWalletsRepository interface {
+	GetAllTrackedWalletsForUser(ctx context.Context, userID int64) ([]entities.Wallet, error)
+	ArchiveUserWallets(ctx context.Context, userID int64) (int, error)
+}
+
+// ClosureBalances читает остатки депозитных кошельков закрываемого аккаунта
+type ClosureBalances interface {
+	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
+	CheckTokenHalt() error
+}
+
+type ClosureTransfers interface {
+	Transfer(ctx context.Context, client *ethclient.Client, kind entities.TreasuryTransferKind, fromWalletID int, toAddress string, amount *big.Int, initiatedBy string) (*entities.TreasuryTransfer, error)
+}
+
+type ClosureSessions interface {
+	RevokeAllSessions(ctx context.Context, userID int64, exceptID string) (int64, error)
+}
+
+type ClosureErasure interface {
+	EraseUserData(ctx context.Context, userID int64, reason, actor string) (*entities.UserErasure, error)
+}
+
+var (
+	_ AccountClosuresRepository = (*repository.AccountClosuresRepository)(nil)
+	_ ClosureWalletsRepository  = (*repository.WalletsRepository)(nil)
+	_ ClosureBalances           = (*WalletService)(nil)
+	_ ClosureTransfers          = (*TreasuryService)(nil)
+	_ ClosureSessions           = (*repository.SessionsRepository)(nil)
+	_ ClosureErasure            = (*PrivacyService)(nil)
+)
+
+// AccountClosureConfig describes where balances of closed accounts go and how long personal data is retained
+type AccountClosureConfig struct {
+	Destination  string
+	Interval     time.Duration
+	ErasureDelay time.Duration
+}
+
+// AccountClosureService закрывает аккаунты по запросу пользователя. С момента запроса новые ордера
+// и кошельки запрещены (AbuseGuard), а обработчик переводит остатки с депозитных кошельков в казначейство,
+// архивирует кошельки, отзывает сессии и по истечении срока хранения обезличивает персональные данные.
+type AccountClosureService struct {
+	logger    *slog.Logger
+	repo      AccountClosuresRepository
+	wallets   ClosureWalletsRepository
+	balances  ClosureBalances
+	transfers ClosureTransfers
+	sessions  ClosureSessions
+	erasure   ClosureErasure
+	audit     *AuditService
+
+	destination  string
+	interval     time.Duration
+	erasureDelay time.Duration
+}
+
+func NewAccountClosureService(
+	logger *slog.Logger,
+	repo AccountClosuresRepository,
+	wallets ClosureWalletsRepository,
+	balances ClosureBalances,
+	transfers ClosureTransfers,
+	sessions ClosureSessions,
+	erasure ClosureErasure,
+	audit *AuditService,
+	config AccountClosureConfig,
+) (*AccountClosureService, error) {
+	if config.Destination != "" && !common.IsHexAddress(config.Destination) {
+		return nil, fmt.Errorf("invalid account closure sweep destination %q", config.Destination)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("account closure interval must be positive")
+	}
+	if config.ErasureDelay < 0 {
+		return nil, errors.New("account closure erasure delay must not be negative")
+	}
+
+	var destination string
+	if config.Destination != "" {
+		destination = common.HexToAddress(config.Destination).Hex()
+	}
+
+	return &AccountClosureService{
+		logger:       logger,
+		repo:         repo,
+		wallets:      wallets,
+		balances:     balances,
+		transfers:    transfers,
+		sessions:     sessions,
+		erasure:      erasure,
+		audit:        audit,
+		destination:  destination,
+		interval:     config.Interval,
+		erasureDelay: config.ErasureDelay,
+	}, nil
+}
+
+// RequestClosure starts closing the account of the user. The request is refused while the user has
+// pending orders, open refunds or unconfirmed deposits.
+func (s *AccountClosureService) RequestClosure(ctx context.Context, userID int64, reason string) (*entities.AccountClosure, error) {
+	existing, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrClosureAlreadyExists
+	}
+
+	blockers, err := s.repo.CountBlockers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blockers.Any() {
+		return nil, closureBlockedError(blockers)
+	}
+
+	closure := &entities.AccountClosure{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Status: entities.AccountClosureRequested,
+		Reason: strings.TrimSpace(reason),
+	}
+	created, err := s.repo.CreateClosure(ctx, closure)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrClosureAlreadyExists
+	}
+
+	subject := strconv.FormatInt(userID, 10)
+	if err = s.audit.Record(ctx, entities.AuditEventAccountClosureRequested, subject, subject, map[string]any{
+		"closure_id": closure.ID,
+		"reason":     closure.Reason,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record account closure audit", "error", err, "user_id", userID)
+	}
+
+	s.logger.InfoContext(ctx, "Account closure requested", "user_id", userID, "closure_id", closure.ID)
+	return closure, nil
+}
+
+// GetClosure returns the closure of the user
+func (s *AccountClosureService) GetClosure(ctx context.Context, userID int64) (*entities.AccountClosure, error) {
+	closure, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if closure == nil {
+		return nil, ErrClosureNotFound
+	}
+	return closure, nil
+}
+
+// GetClosures returns closures with the given status. Empty status returns all closures.
+func (s *AccountClosureService) GetClosures(ctx context.Context, status entities.AccountClosureStatus) ([]entities.AccountClosure, error) {
+	return s.repo.FindByStatus(ctx, status, accountClosuresListLimit)
+}
+
+// Start advances open closures and erases personal data of closed accounts on the configured interval
+func (s *AccountClosureService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ProcessAll(ctx)
+		}
+	}
+}
+
+// ProcessAll advances every open closure by as many steps as possible and erases data whose retention ended
+func (s *AccountClosureService) ProcessAll(ctx context.Context) {
+	var open []entities.AccountClosure
+	for _, status := range []entities.AccountClosureStatus{entities.AccountClosureRequested, entities.AccountClosureSweeping} {
+		closures, err := s.repo.FindByStatus(ctx, status, accountClosuresListLimit)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to get account closures", "error", err, "status", status)
+			return
+		}
+		open = append(open, closures...)
+	}
+
+	if len(open) > 0 {
+		client, err := GetBSCClient(ctx, s.logger)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to create BSC client for account closures", "error", err)
+			return
+		}
+		defer client.Close()
+
+		for i := range open {
+			if err = s.process(ctx, client, &open[i]); err != nil {
+				s.logger.WarnContext(ctx, "Account closure is not completed yet",
+					"error", err,
+					"closure_id", open[i].ID,
+					"user_id", open[i].UserID,
+					"status", open[i].Status)
+				if err = s.repo.SetError(ctx, open[i].ID, err.Error()); err != nil {
+					s.logger.ErrorContext(ctx, "Failed to store account closure error", "error", err, "closure_id", open[i].ID)
+				}
+			}
+		}
+	}
+
+	s.eraseDue(ctx)
+}
+
+func (s *AccountClosureService) process(ctx context.Context, client *ethclient.Client, closure *entities.AccountClosure) error {
+	switch closure.Status {
+	case entities.AccountClosureRequested:
+		// Ордер мог быть создан между проверкой запроса и записью закрытия: ждем, пока он завершится или истечет
+		blockers, err := s.repo.CountBlockers(ctx, closure.UserID)
+		if err != nil {
+			return err
+		}
+		if blockers.Any() {
+			return closureBlockedError(blockers)
+		}
+		if err = s.repo.UpdateStatus(ctx, closure.ID, entities.AccountClosureSweeping, nil); err != nil {
+			return err
+		}
+		closure.Status = entities.AccountClosureSweeping
+		fallthrough
+	case entities.AccountClosureSweeping:
+		swept, err := s.sweep(ctx, client, closure)
+		if err != nil || !swept {
+			return err
+		}
+		return s.close(ctx, closure)
+	default:
+		return nil
+	}
+}
+
+// sweep sends remaining balances of the user wallets to the destination.
+// Returns true once every wallet of the user is empty.
+func (s *AccountClosureService) sweep(ctx context.Context, client *ethclient.Client, closure *entities.AccountClosure) (bool, error) {
+	if err := s.balances.CheckTokenHalt(); err != nil {
+		return false, err
+	}
+
+	wallets, err := s.wallets.GetAllTrackedWalletsForUser(ctx, closure.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	waiting := closure.LastSweepAt != nil && time.Since(*closure.LastSweepAt) < closureSweepWait
+
+	var remaining, sent int
+	for _, wallet := range wallets {
+		balance, err := s.balances.GetERC20TokenBalance(ctx, client, wallet.Address)
+		if err != nil {
+			return false, fmt.Errorf("failed to get balance of wallet %s: %w", wallet.Address, err)
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+		remaining++
+
+		// Форвардеры опустошаются фабрикой, а предыдущий свип может быть еще не подтвержден
+		if IsForwarderPath(wallet.DerivationPath) || waiting {
+			continue
+		}
+		if s.destination == "" {
+			return false, errors.New("account closure sweep destination is not configured")
+		}
+
+		transfer, err := s.transfers.Transfer(ctx, client, entities.TreasuryTransferSweep, wallet.ID, s.destination, balance, closureWorkerActor)
+		if err != nil {
+			return false, fmt.Errorf("failed to sweep wallet %s: %w", wallet.Address, err)
+		}
+		sent++
+		s.logger.InfoContext(ctx, "Closed account wallet swept",
+			"closure_id", closure.ID,
+			"user_id", closure.UserID,
+			"wallet", wallet.Address,
+			"amount", balance.String(),
+			"tx_hash", transfer.TxHash)
+	}
+
+	if sent > 0 {
+		if err = s.repo.MarkSwept(ctx, closure.ID); err != nil {
+			return false, err
+		}
+	}
+
+	return remaining == 0, nil
+}
+
+// close archives wallets, revokes sessions and schedules erasure of personal data.
+// The platform has no API keys: sessions are the only credentials of the user.
+func (s *AccountClosureService) close(ctx context.Context, closure *entities.AccountClosure) error {
+	archived, err := s.wallets.ArchiveUserWallets(ctx, closure.UserID)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.sessions.RevokeAllSessions(ctx, closure.UserID, "")
+	if err != nil {
+		return err
+	}
+
+	eraseAfter := time.Now().Add(s.erasureDelay)
+	closure.WalletsArchived = archived
+	closure.SessionsRevoked = int(revoked)
+	closure.EraseAfter = &eraseAfter
+	if err = s.repo.MarkClosed(ctx, closure); err != nil {
+		return err
+	}
+	closure.Status = entities.AccountClosureClosed
+
+	if err = s.audit.Record(ctx, entities.AuditEventAccountClosed, closureWorkerActor, strconv.FormatInt(closure.UserID, 10), map[string]any{
+		"closure_id":       closure.ID,
+		"wallets_archived": archived,
+		"sessions_revoked": revoked,
+		"erase_after":      eraseAfter.UTC().Format(time.RFC3339),
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record account closed audit", "error", err, "closure_id", closure.ID)
+	}
+
+	s.logger.InfoContext(ctx, "Account closed",
+		"closure_id", closure.ID,
+		"user_id", closure.UserID,
+		"wallets_archived", archived,
+		"sessions_revoked", revoked,
+		"erase_after", eraseAfter)
+	return nil
+}
+
+// eraseDue erases personal data of closed accounts whose retention period ended
+func (s *AccountClosureService) eraseDue(ctx context.Context) {
+	closures, err := s.repo.FindDueErasures(ctx, time.Now(), accountClosuresListLimit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get account closures due for erasure", "error", err)
+		return
+	}
+
+	for _, closure := range closures {
+		if _, err = s.erasure.EraseUserData(ctx, closure.UserID, "account closed", closureWorkerActor); err != nil {
+			s.logger.WarnContext(ctx, "Failed to erase closed account data", "error", err, "closure_id", closure.ID, "user_id", closure.UserID)
+			if err = s.repo.SetError(ctx, closure.ID, err.Error()); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to store account closure error", "error", err, "closure_id", closure.ID)
+			}
+			continue
+		}
+		if err = s.repo.MarkErased(ctx, closure.ID); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to mark closed account erased", "error", err, "closure_id", closure.ID)
+		}
+	}
+}
+
+func closureBlockedError(blockers entities.AccountClosureBlockers) error {
+	return fmt.Errorf("%w: %d pending orders, %d open refunds, %d unconfirmed deposits",
+		ErrClosureBlocked, blockers.PendingOrders, blockers.OpenRefunds, blockers.UnconfirmedDeposits)
+}
@@ -0,0 +1,149 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+const (
+	DefaultActivityLimit = 50
+	MaxActivityLimit     = 200
+)
+
+// События журнала аудита, которые пользователь видит в ленте активности
+var activityAccountEvents = []string{
+	string(entities.AuditEventNewDeviceLogin),
+	string(entities.AuditEventSessionRevoked),
+	string(entities.AuditEventTwoFactorEnrolled),
+	string(entities.AuditEventUserDataExported),
+	string(entities.AuditEventAccountClosureRequested),
+	string(entities.AuditEventAccountClosed),
+	string(entities.AuditEventDepositHeld),
+	string(entities.AuditEventWithdrawalTierChanged),
+	string(entities.AuditEventSettlementAccountChanged),
+}
+
+type ActivityRepository interface {
+	FindUserActivity(ctx context.Context, userID int64, eventTypes []string, before *time.Time, kind entities.ActivityKind, ref string, limit int) ([]entities.ActivityItem, error)
+}
+
+// ActivityAssets переводит суммы депозитов и выводов из минимальных единиц по активу сети кошелька
+type ActivityAssets interface {
+	Default() entities.Asset
+	FindOnNetwork(code string, chain entities.Chain, network string) (entities.Asset, error)
+}
+
+var (
+	_ ActivityRepository = (*repository.ActivityRepository)(nil)
+	_ ActivityAssets     = (*AssetRegistry)(nil)
+)
+
+// ActivityService returns the activity timeline of a user: orders, deposits, withdrawals and notable account events
+type ActivityService struct {
+	logger *slog.Logger
+	repo   ActivityRepository
+	assets ActivityAssets
+}
+
+func NewActivityService(logger *slog.Logger, repo ActivityRepository, assets ActivityAssets) *ActivityService {
+	return &ActivityService{
+		logger: logger,
+		repo:   repo,
+		assets: assets,
+	}
+}
+
+// GetUserActivity returns a page of the user's activity, newest first. An empty cursor starts from the latest
+// activity, the next page is requested with NextCursor of the previous one.
+func (s *ActivityService) GetUserActivity(ctx context.Context, userID int64, cursor string, limit int) (*entities.ActivityPage, error) {
+	if limit <= 0 {
+		limit = DefaultActivityLimit
+	}
+	limit = min(limit, MaxActivityLimit)
+
+	var before *time.Time
+	var kind entities.ActivityKind
+	var ref string
+	if cursor != "" {
+		at, cursorKind, cursorRef, err := decodeActivityCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		before, kind, ref = &at, cursorKind, cursorRef
+	}
+
+	// Лишняя запись показывает, есть ли следующая страница
+	items, err := s.repo.FindUserActivity(ctx, userID, activityAccountEvents, before, kind, ref, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entities.ActivityPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeActivityCursor(last.OccurredAt, last.Kind, last.Ref)
+	}
+
+	for i := range page.Items {
+		s.formatAmount(ctx, &page.Items[i])
+	}
+
+	return page, nil
+}
+
+// formatAmount переводит сумму депозита или вывода в единицы актива и заполняет актив ордеров,
+// созданных до реестра активов
+func (s *ActivityService) formatAmount(ctx context.Context, item *entities.ActivityItem) {
+	if !item.Units {
+		if item.Kind == entities.ActivityOrder && item.Asset == "" {
+			item.Asset = s.assets.Default().Code
+		}
+		return
+	}
+
+	asset, err := s.assets.FindOnNetwork(DefaultAssetCode, item.Chain, item.Network)
+	if err != nil {
+		asset = s.assets.Default()
+	}
+	item.Asset = asset.Code
+
+	units, ok := new(big.Int).SetString(item.Amount, 10)
+	if !ok {
+		s.logger.WarnContext(ctx, "Invalid activity amount", "kind", item.Kind, "ref", item.Ref, "amount", item.Amount)
+		return
+	}
+	item.Amount = decimal.FromUnits(units, asset.Decimals).String()
+}
+
+// Курсор — момент, вид и идентификатор последней записи страницы
+func encodeActivityCursor(at time.Time, kind entities.ActivityKind, ref string) string {
+	raw := strconv.FormatInt(at.UnixNano(), 10) + "|" + string(kind) + "|" + ref
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeActivityCursor(cursor string) (time.Time, entities.ActivityKind, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", "", fmt.Errorf("%w: malformed cursor", ErrInvalidActivityCursor)
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return time.Time{}, "", "", fmt.Errorf("%w: malformed cursor", ErrInvalidActivityCursor)
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", "", fmt.Errorf("%w: malformed cursor", ErrInvalidActivityCursor)
+	}
+	return time.Unix(0, nanos).UTC(), entities.ActivityKind(parts[1]), parts[2], nil
+}
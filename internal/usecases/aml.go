@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/chaos"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/errreport"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
 	"log/slog"
 	"math/big"
 	"sync"
@@ -15,6 +18,9 @@ import (
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/aml/clients"
 )
 
+// AMLProcessingInterval — период фоновой обработки очереди AML-проверок
+const AMLProcessingInterval = 5 * time.Minute
+
 // AMLService представляет основной сервис для AML проверок
 type AMLService struct {
 	logger      *slog.Logger
@@ -23,13 +29,42 @@ type AMLService struct {
 	elliptic    *clients.EllipticService
 	local       *clients.LocalAMLService
 	amlbot      *clients.AMLBotService
+	blacklist   *clients.TokenBlacklistService
 	txService   TransactionService
 	transactor  *tx.Transactor
 
 	// Семафор для ограничения одновременных внешних проверок
 	checkSemaphore chan struct{}
+
+	// Задержка ответов провайдеров при проверке отказоустойчивости на стенде
+	faults AMLFaults
+
+	// Последнее обращение к каждому внешнему провайдеру завершилось ошибкой
+	providersMu     sync.Mutex
+	providerFailing map[string]bool
+
+	// Сводный риск кошельков и пользователей, nil — не ведется
+	rollups AMLRiskRollup
+
+	// Сценарный провайдер стендов заменяет внешних провайдеров, nil — отключен
+	scripted *clients.ScriptedAMLService
+	// Воспроизведение записанных блоков: внешние провайдеры не опрашиваются
+	replaying bool
+}
+
+// AMLRiskRollup ведет сводный риск кошельков и ужесточает проверку депозитов рискованных пользователей
+type AMLRiskRollup interface {
+	Tighten(ctx context.Context, result *entities.AMLCheckResult) error
+	Refresh(ctx context.Context, walletAddress string) error
 }
 
+// AMLFaults задерживает ответы внешних провайдеров при внедрении сбоев
+type AMLFaults interface {
+	DelayAML(ctx context.Context)
+}
+
+var _ AMLFaults = (*chaos.Injector)(nil)
+
 // TransactionService интерфейс для работы с транзакциями
 type TransactionService interface {
 	MarkTransactionAMLFlagged(ctx context.Context, txHash string) error
@@ -43,20 +78,120 @@ func NewAMLService(
 	elliptic *clients.EllipticService,
 	local *clients.LocalAMLService,
 	amlbot *clients.AMLBotService,
+	blacklist *clients.TokenBlacklistService,
 	txService TransactionService,
 	transactor *tx.Transactor,
+	faults AMLFaults,
 ) *AMLService {
 	return &AMLService{
-		logger:         logger,
-		repo:           repo,
-		chainalysis:    chainalysis,
-		elliptic:       elliptic,
-		local:          local,
-		amlbot:         amlbot,
-		txService:      txService,
-		transactor:     transactor,
-		checkSemaphore: make(chan struct{}, 5), // Максимум 5 одновременных внешних проверок
+		logger:          logger,
+		repo:            repo,
+		chainalysis:     chainalysis,
+		elliptic:        elliptic,
+		local:           local,
+		amlbot:          amlbot,
+		blacklist:       blacklist,
+		txService:       txService,
+		transactor:      transactor,
+		faults:          faults,
+		checkSemaphore:  make(chan struct{}, 5), // Максимум 5 одновременных внешних проверок
+		providerFailing: make(map[string]bool),
+	}
+}
+
+// SetRiskRollup enables the wallet risk rollup: every saved result updates it and tightens later checks of the user
+func (s *AMLService) SetRiskRollup(rollups AMLRiskRollup) {
+	s.rollups = rollups
+}
+
+// SetScriptedProvider replaces the external providers with scripted verdicts, so flag, review and clear flows
+// can be tested end to end on staging without external APIs
+func (s *AMLService) SetScriptedProvider(scripted *clients.ScriptedAMLService) {
+	s.scripted = scripted
+}
+
+// SetReplayMode stops querying external providers while recorded blocks are replayed: stored results of the database
+// are reused and transactions without one are checked only against the local rules
+func (s *AMLService) SetReplayMode() {
+	s.replaying = true
+}
+
+// localBlacklistScore — оценка риска адресов, добавленных в локальный черный список правилами платформы
+const localBlacklistScore = 0.9
+
+// LoadLocalBlacklist loads the persisted local blacklist into the local AML provider
+func (s *AMLService) LoadLocalBlacklist(ctx context.Context) error {
+	entries, err := s.repo.FindLocalBlacklist(ctx)
+	if err != nil {
+		return err
+	}
+	for address, score := range entries {
+		s.local.AddRiskyAddress(address, score)
+	}
+
+	s.logger.InfoContext(ctx, "Loaded local AML blacklist", "addresses", len(entries))
+	return nil
+}
+
+// BlacklistAddress adds the address to the local AML blacklist: later transfers from it are rejected by the local
+// check and the cached address risk is replaced right away
+func (s *AMLService) BlacklistAddress(ctx context.Context, address, reason string) error {
+	if err := s.repo.SaveLocalBlacklistEntry(ctx, address, localBlacklistScore, reason); err != nil {
+		return err
 	}
+	s.local.AddRiskyAddress(address, localBlacklistScore)
+
+	if err := s.repo.SaveAddressRiskInfo(ctx, &entities.AddressRiskInfo{
+		Address:   address,
+		RiskLevel: entities.RiskLevelHigh,
+		RiskScore: localBlacklistScore,
+		Category:  "local_blacklist",
+		Source:    "local_aml",
+		Tags:      []string{"local_blacklist", reason},
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save address risk info", "error", err, "address", address)
+	}
+
+	s.logger.WarnContext(ctx, "Address added to local AML blacklist", "address", address, "reason", reason)
+	return nil
+}
+
+// externalEnabled сообщает, обращаться ли к внешним провайдерам: сценарный провайдер их заменяет,
+// при воспроизведении блоков они отключены
+func (s *AMLService) externalEnabled() bool {
+	return s.scripted == nil && !s.replaying
+}
+
+// ProviderHealth returns the number of enabled external AML providers and of those whose last check failed
+func (s *AMLService) ProviderHealth() (enabled, failing int) {
+	if s.scripted != nil {
+		enabled++
+	}
+	if s.externalEnabled() && s.chainalysis.IsEnabled() {
+		enabled++
+	}
+	if s.externalEnabled() && s.elliptic.IsEnabled() {
+		enabled++
+	}
+	if s.externalEnabled() && s.amlbot != nil && s.amlbot.IsEnabled() {
+		enabled++
+	}
+
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+	for _, failed := range s.providerFailing {
+		if failed {
+			failing++
+		}
+	}
+	return enabled, failing
+}
+
+func (s *AMLService) recordProvider(name string, err error) {
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+
+	s.providerFailing[name] = err != nil
 }
 
 // CheckTransaction выполняет AML проверку транзакции
@@ -86,6 +221,23 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 		return existingResult, nil
 	}
 
+	return s.runTransactionCheck(ctx, txHashStr, sourceAddress, destinationAddress, amountStr)
+}
+
+// RecheckTransaction runs the AML check of the transaction again, ignoring the stored result. The new result
+// is stored alongside the previous ones and becomes the latest.
+func (s *AMLService) RecheckTransaction(ctx context.Context, txHash common.Hash, sourceAddress, destinationAddress string, amount *big.Int) (*entities.AMLCheckResult, error) {
+	s.logger.InfoContext(ctx, "Re-running AML check for transaction",
+		"tx_hash", txHash.Hex(),
+		"source", sourceAddress,
+		"destination", destinationAddress,
+		"amount", amount.String())
+
+	return s.runTransactionCheck(ctx, txHash.Hex(), sourceAddress, destinationAddress, amount.String())
+}
+
+// runTransactionCheck опрашивает провайдеров, сохраняет самый строгий результат и обновляет статус транзакции
+func (s *AMLService) runTransactionCheck(ctx context.Context, txHashStr, sourceAddress, destinationAddress, amountStr string) (*entities.AMLCheckResult, error) {
 	// Сохраняем транзакцию в очередь на проверку
 	check := &entities.TransactionCheck{
 		TxHash:        txHashStr,
@@ -105,8 +257,8 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 
 	// Запускаем все доступные проверки параллельно
 	var wg sync.WaitGroup
-	resultChan := make(chan *entities.AMLCheckResult, 3) // Для 3 потенциальных результатов
-	errorChan := make(chan error, 3)
+	resultChan := make(chan *entities.AMLCheckResult, 5) // По одному результату на каждую проверку
+	errorChan := make(chan error, 5)
 
 	// Всегда выполняем локальную проверку
 	wg.Add(1)
@@ -120,8 +272,25 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 		resultChan <- result
 	}()
 
+	// Сценарный провайдер вместо внешних, если включен
+	if s.scripted != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s.faults.DelayAML(ctx)
+			result, err := s.scripted.CheckTransaction(ctx, txHashStr, sourceAddress, destinationAddress, amountStr)
+			s.recordProvider("scripted", err)
+			if err != nil {
+				errorChan <- fmt.Errorf("scripted check failed: %w", err)
+				return
+			}
+			resultChan <- result
+		}()
+	}
+
 	// Проверка через Chainalysis, если сервис активирован
-	if s.chainalysis.IsEnabled() {
+	if s.externalEnabled() && s.chainalysis.IsEnabled() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -130,9 +299,11 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 			s.checkSemaphore <- struct{}{}
 			defer func() { <-s.checkSemaphore }()
 
+			s.faults.DelayAML(ctx)
 			result, err := s.chainalysis.CheckTransaction(ctx, txHashStr, sourceAddress, destinationAddress, amountStr)
+			s.recordProvider("chainalysis", err)
 			if err != nil {
-				errorChan <- fmt.Errorf("chainalysis check failed: %w", err)
+				errorChan <- fmt.Errorf("chainalysis check failed: %w", timeouts.Classify("chainalysis", err))
 				return
 			}
 			resultChan <- result
@@ -140,7 +311,7 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 	}
 
 	// Проверка через Elliptic, если сервис активирован
-	if s.elliptic.IsEnabled() {
+	if s.externalEnabled() && s.elliptic.IsEnabled() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -149,9 +320,11 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 			s.checkSemaphore <- struct{}{}
 			defer func() { <-s.checkSemaphore }()
 
+			s.faults.DelayAML(ctx)
 			result, err := s.elliptic.CheckTransaction(ctx, txHashStr, sourceAddress, destinationAddress, amountStr)
+			s.recordProvider("elliptic", err)
 			if err != nil {
-				errorChan <- fmt.Errorf("elliptic check failed: %w", err)
+				errorChan <- fmt.Errorf("elliptic check failed: %w", timeouts.Classify("elliptic", err))
 				return
 			}
 			resultChan <- result
@@ -159,7 +332,7 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 	}
 
 	// Проверка через AMLBot, если сервис активирован
-	if s.amlbot != nil && s.amlbot.IsEnabled() {
+	if s.externalEnabled() && s.amlbot != nil && s.amlbot.IsEnabled() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -168,15 +341,33 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 			s.checkSemaphore <- struct{}{}
 			defer func() { <-s.checkSemaphore }()
 
+			s.faults.DelayAML(ctx)
 			result, err := s.amlbot.CheckTransaction(ctx, txHashStr, sourceAddress, destinationAddress, amountStr)
+			s.recordProvider("amlbot", err)
 			if err != nil {
-				errorChan <- fmt.Errorf("amlbot check failed: %w", err)
+				errorChan <- fmt.Errorf("amlbot check failed: %w", timeouts.Classify("amlbot", err))
 				return
 			}
 			resultChan <- result
 		}()
 	}
 
+	// Черный список контракта USDT: средства с/на такие адреса заморожены эмитентом
+	if s.blacklist != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.checkTokenBlacklist(ctx, txHashStr, sourceAddress, destinationAddress)
+			if err != nil {
+				errorChan <- fmt.Errorf("token blacklist check failed: %w", err)
+				return
+			}
+			if result != nil {
+				resultChan <- result
+			}
+		}()
+	}
+
 	// Ждем завершения всех проверок
 	go func() {
 		wg.Wait()
@@ -217,8 +408,17 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 	// Дополняем информацию о всех использованных сервисах
 	finalResult.ExternalServicesUsed = servicesUsed
 
+	// Пороги ужесточаются по риску предыдущих депозитов пользователя
+	if s.rollups != nil {
+		if err := s.rollups.Tighten(ctx, finalResult); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to apply user risk to AML result",
+				"error", err,
+				"tx_hash", txHashStr)
+		}
+	}
+
 	// Сохраняем результат в базу и обновляем статус транзакции в одной транзакции
-	err = s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
 		// Сохраняем результат проверки
 		if err := s.repo.SaveCheckResult(txCtx, finalResult); err != nil {
 			return fmt.Errorf("failed to save AML check result: %w", err)
@@ -229,6 +429,12 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 			return fmt.Errorf("failed to mark transaction as processed: %w", err)
 		}
 
+		if s.rollups != nil {
+			if err := s.rollups.Refresh(txCtx, finalResult.WalletAddress); err != nil {
+				return fmt.Errorf("failed to refresh wallet risk: %w", err)
+			}
+		}
+
 		// Если транзакция не прошла проверку, обновляем её статус в основной таблице transactions
 		if !finalResult.Approved && s.txService != nil {
 			if err := s.txService.MarkTransactionAMLFlagged(txCtx, txHashStr); err != nil {
@@ -260,6 +466,23 @@ func (s *AMLService) CheckTransaction(ctx context.Context, txHash common.Hash, s
 
 // CheckAddress выполняет AML проверку адреса
 func (s *AMLService) CheckAddress(ctx context.Context, address string) (*entities.AddressRiskInfo, error) {
+	// Черный список USDT проверяется до кэша: адрес могли заблокировать после последней проверки
+	if s.blacklist != nil {
+		blacklisted, err := s.blacklist.CheckAddress(ctx, address)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Token blacklist check failed",
+				"error", err,
+				"address", address)
+		} else if blacklisted != nil {
+			if err := s.repo.SaveAddressRiskInfo(ctx, blacklisted); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to save address risk info",
+					"error", err,
+					"address", address)
+			}
+			return blacklisted, nil
+		}
+	}
+
 	// Проверяем, есть ли информация в кэше
 	cachedInfo, err := s.repo.GetAddressRiskInfo(ctx, address)
 	if err != nil {
@@ -288,7 +511,19 @@ func (s *AMLService) CheckAddress(ctx context.Context, address string) (*entitie
 	// Если доступны внешние сервисы, пробуем использовать их для более точной проверки
 	var externalResult *entities.AddressRiskInfo
 
-	if s.chainalysis.IsEnabled() {
+	if s.scripted != nil {
+		scriptedResult, scriptedErr := s.scripted.CheckAddress(ctx, address)
+		s.recordProvider("scripted", scriptedErr)
+		if scriptedErr != nil {
+			s.logger.ErrorContext(ctx, "Scripted address check failed",
+				"error", scriptedErr,
+				"address", address)
+		} else if scriptedResult.RiskScore > localResult.RiskScore {
+			externalResult = scriptedResult
+		}
+	}
+
+	if s.externalEnabled() && s.chainalysis.IsEnabled() {
 		s.checkSemaphore <- struct{}{}
 		chainalysisResult, chainalysisErr := s.chainalysis.CheckAddress(ctx, address)
 		<-s.checkSemaphore
@@ -302,7 +537,7 @@ func (s *AMLService) CheckAddress(ctx context.Context, address string) (*entitie
 		}
 	}
 
-	if externalResult == nil && s.elliptic.IsEnabled() {
+	if externalResult == nil && s.externalEnabled() && s.elliptic.IsEnabled() {
 		s.checkSemaphore <- struct{}{}
 		ellipticResult, ellipticErr := s.elliptic.CheckAddress(ctx, address)
 		<-s.checkSemaphore
@@ -317,7 +552,7 @@ func (s *AMLService) CheckAddress(ctx context.Context, address string) (*entitie
 	}
 
 	// Проверка через AMLBot, если сервис активирован
-	if externalResult == nil && s.amlbot != nil && s.amlbot.IsEnabled() {
+	if externalResult == nil && s.externalEnabled() && s.amlbot != nil && s.amlbot.IsEnabled() {
 		s.checkSemaphore <- struct{}{}
 		amlbotResult, amlbotErr := s.amlbot.CheckAddress(ctx, address)
 		<-s.checkSemaphore
@@ -354,15 +589,15 @@ func (s *AMLService) CheckAddress(ctx context.Context, address string) (*entitie
 	return finalResult, nil
 }
 
-// ProcessPendingChecks обрабатывает очередь ожидающих AML-проверок транзакций
-func (s *AMLService) ProcessPendingChecks(ctx context.Context) error {
+// ProcessPendingChecks обрабатывает очередь ожидающих AML-проверок транзакций и возвращает число обработанных
+func (s *AMLService) ProcessPendingChecks(ctx context.Context) (int, error) {
 	checks, err := s.repo.GetPendingChecks(ctx, 50) // Ограничиваем максимальное количество
 	if err != nil {
-		return fmt.Errorf("failed to get pending checks: %w", err)
+		return 0, fmt.Errorf("failed to get pending checks: %w", err)
 	}
 
 	if len(checks) == 0 {
-		return nil // Нет транзакций для проверки
+		return 0, nil // Нет транзакций для проверки
 	}
 
 	s.logger.InfoContext(ctx, "Processing pending AML checks", "count", len(checks))
@@ -372,6 +607,7 @@ func (s *AMLService) ProcessPendingChecks(ctx context.Context) error {
 		wg.Add(1)
 		go func(c entities.TransactionCheck) {
 			defer wg.Done()
+			defer errreport.Recover(map[string]string{"worker": "aml_processing", "tx_hash": c.TxHash})
 
 			checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel()
@@ -407,18 +643,20 @@ func (s *AMLService) ProcessPendingChecks(ctx context.Context) error {
 	wg.Wait()
 	s.logger.InfoContext(ctx, "Completed processing pending AML checks", "count", len(checks))
 
-	return nil
+	return len(checks), nil
 }
 
-// StartBackgroundProcessing запускает фоновую обработку очереди AML-проверок
-func (s *AMLService) StartBackgroundProcessing(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
+// StartBackgroundProcessing запускает фоновую обработку очереди AML-проверок и сообщает о каждом проходе трекеру
+func (s *AMLService) StartBackgroundProcessing(ctx context.Context, tracker WorkerTracker) {
+	defer errreport.Recover(map[string]string{"worker": "aml_processing"})
+
+	ticker := time.NewTicker(AMLProcessingInterval)
 	defer ticker.Stop()
 
 	s.logger.Info("Starting background AML checks processing")
 
 	// Выполняем начальную обработку
-	if err := s.ProcessPendingChecks(ctx); err != nil {
+	if err := s.processPendingPass(ctx, tracker); err != nil {
 		s.logger.Error("Failed to process initial pending AML checks", "error", err)
 	}
 
@@ -428,9 +666,57 @@ func (s *AMLService) StartBackgroundProcessing(ctx context.Context) {
 			s.logger.Info("Stopping background AML checks processing")
 			return
 		case <-ticker.C:
-			if err := s.ProcessPendingChecks(ctx); err != nil {
+			if err := s.processPendingPass(ctx, tracker); err != nil {
 				s.logger.Error("Failed to process pending AML checks", "error", err)
 			}
 		}
 	}
 }
+
+// processPendingPass обрабатывает очередь и сообщает трекеру число обработанных проверок и оставшуюся очередь
+func (s *AMLService) processPendingPass(ctx context.Context, tracker WorkerTracker) error {
+	processed, err := s.ProcessPendingChecks(ctx)
+	tracker.Done(processed, err)
+	if err != nil {
+		return err
+	}
+
+	pending, err := s.repo.CountPendingChecks(ctx)
+	if err != nil {
+		return err
+	}
+	tracker.SetLag(pending)
+	return nil
+}
+
+// checkTokenBlacklist возвращает отклоняющий результат, если источник или получатель в черном списке USDT, иначе nil
+func (s *AMLService) checkTokenBlacklist(ctx context.Context, txHash, sourceAddress, destinationAddress string) (*entities.AMLCheckResult, error) {
+	for _, address := range []string{sourceAddress, destinationAddress} {
+		if address == "" {
+			continue
+		}
+		info, err := s.blacklist.CheckAddress(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			continue
+		}
+
+		return &entities.AMLCheckResult{
+			TransactionHash:      txHash,
+			WalletAddress:        destinationAddress,
+			SourceAddress:        sourceAddress,
+			RiskLevel:            entities.RiskLevelHigh,
+			RiskSource:           entities.RiskSourceSanctionsList,
+			RiskScore:            info.RiskScore,
+			Approved:             false,
+			CheckedAt:            time.Now(),
+			Notes:                fmt.Sprintf("address %s is blacklisted by the USDT contract", address),
+			RequiresReview:       true,
+			ExternalServicesUsed: []string{"usdt_blacklist"},
+		}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,259 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// DefaultAssetCode — актив расчетов по ордерам, депозитам и свипам
+const DefaultAssetCode = "USDT"
+
+type AssetsRepository interface {
+	FindAssets(ctx context.Context) ([]entities.Asset, error)
+	UpdateAsset(ctx context.Context, asset *entities.Asset) (*entities.Asset, error)
+}
+
+var _ AssetsRepository = (*repository.AssetsRepository)(nil)
+
+// AssetRegistry хранит в памяти реестр активов из таблицы assets: точность, контракт, лимиты ордеров,
+// комиссию вывода и AML порог. Активы доступны только в сети, которую обслуживает приложение.
+type AssetRegistry struct {
+	logger *slog.Logger
+	repo   AssetsRepository
+	audit  *AuditService
+
+	chain   entities.Chain
+	network string
+
+	mu     sync.RWMutex
+	assets []entities.Asset
+}
+
+func NewAssetRegistry(logger *slog.Logger, repo AssetsRepository, audit *AuditService, chain entities.Chain, network string) *AssetRegistry {
+	return &AssetRegistry{
+		logger:  logger,
+		repo:    repo,
+		audit:   audit,
+		chain:   chain,
+		network: network,
+	}
+}
+
+// Load reads the registry from the database. The default asset must be registered for the served network.
+func (r *AssetRegistry) Load(ctx context.Context) error {
+	assets, err := r.repo.FindAssets(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.assets = assets
+	r.mu.Unlock()
+
+	if _, err = r.Find(DefaultAssetCode); err != nil {
+		return fmt.Errorf("default asset %s on %s %s: %w", DefaultAssetCode, r.chain, r.network, err)
+	}
+	return nil
+}
+
+// List returns the assets of the served network
+func (r *AssetRegistry) List() []entities.Asset {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	assets := make([]entities.Asset, 0, len(r.assets))
+	for _, asset := range r.assets {
+		if asset.Chain == r.chain && asset.Network == r.network {
+			assets = append(assets, asset)
+		}
+	}
+	return assets
+}
+
+// Find returns the asset by code on the served network
+func (r *AssetRegistry) Find(code string) (entities.Asset, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, asset := range r.List() {
+		if asset.Code == code {
+			return asset, nil
+		}
+	}
+	return entities.Asset{}, fmt.Errorf("%w: %s", ErrAssetNotSupported, code)
+}
+
+// FindOnNetwork returns the asset by code on another network, e.g. for deposits accepted by a dedicated chain module
+func (r *AssetRegistry) FindOnNetwork(code string, chain entities.Chain, network string) (entities.Asset, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, asset := range r.assets {
+		if asset.Code == code && asset.Chain == chain && asset.Network == network {
+			return asset, nil
+		}
+	}
+	return entities.Asset{}, fmt.Errorf("%w: %s on %s %s", ErrAssetNotSupported, code, chain, network)
+}
+
+// FindByID returns the asset by ID regardless of the network, e.g. for orders created before a network switch
+func (r *AssetRegistry) FindByID(id int) (entities.Asset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, asset := range r.assets {
+		if asset.ID == id {
+			return asset, nil
+		}
+	}
+	return entities.Asset{}, ErrAssetNotFound
+}
+
+// Default returns the settlement asset. Load guarantees it is registered.
+func (r *AssetRegistry) Default() entities.Asset {
+	asset, _ := r.Find(DefaultAssetCode)
+	return asset
+}
+
+// DepositCodes returns codes of the assets that currently accept deposits
+func (r *AssetRegistry) DepositCodes() []string {
+	var codes []string
+	for _, asset := range r.List() {
+		if asset.DepositsEnabled {
+			codes = append(codes, asset.Code)
+		}
+	}
+	return codes
+}
+
+// ValidateOrderAmount checks that deposits of the asset are enabled and the amount is within its order limits
+func (r *AssetRegistry) ValidateOrderAmount(asset entities.Asset, amount string) error {
+	if !asset.DepositsEnabled {
+		return fmt.Errorf("%w: %s", ErrDepositsDisabled, asset.Code)
+	}
+
+	value, err := decimal.Parse(amount)
+	if err != nil || value.Sign() <= 0 {
+		return fmt.Errorf("%w: invalid amount %q", ErrOrderAmountOutOfRange, amount)
+	}
+	if _, err = value.Units(asset.Decimals); err != nil {
+		return fmt.Errorf("%w: amount %q has more than %d decimals", ErrOrderAmountOutOfRange, amount, asset.Decimals)
+	}
+
+	if minAmount, err := decimal.Parse(asset.MinOrderAmount); err == nil && value.Cmp(minAmount) < 0 {
+		return fmt.Errorf("%w: minimum is %s %s", ErrOrderAmountOutOfRange, asset.MinOrderAmount, asset.Code)
+	}
+	if asset.MaxOrderAmount != nil {
+		if maxAmount, err := decimal.Parse(*asset.MaxOrderAmount); err == nil && value.Cmp(maxAmount) > 0 {
+			return fmt.Errorf("%w: maximum is %s %s", ErrOrderAmountOutOfRange, *asset.MaxOrderAmount, asset.Code)
+		}
+	}
+
+	return nil
+}
+
+// UpdateAsset changes limits, fee and flags of the asset and records the change in the audit log
+func (r *AssetRegistry) UpdateAsset(ctx context.Context, id int, update entities.AssetUpdate, actor string) (*entities.Asset, error) {
+	current, err := r.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := current
+	for _, field := range []struct {
+		name  string
+		value *string
+		dst   *string
+	}{
+		{"min_order_amount", update.MinOrderAmount, &asset.MinOrderAmount},
+		{"withdrawal_fee", update.WithdrawalFee, &asset.WithdrawalFee},
+		{"aml_threshold", update.AMLThreshold, &asset.AMLThreshold},
+		{"min_deposit_amount", update.MinDepositAmount, &asset.MinDepositAmount},
+	} {
+		if field.value == nil {
+			continue
+		}
+		if err = validateAssetAmount(*field.value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidAssetUpdate, field.name, err)
+		}
+		*field.dst = *field.value
+	}
+
+	if update.MaxOrderAmount != nil {
+		if *update.MaxOrderAmount == "" {
+			asset.MaxOrderAmount = nil
+		} else {
+			if err = validateAssetAmount(*update.MaxOrderAmount); err != nil {
+				return nil, fmt.Errorf("%w: max_order_amount: %v", ErrInvalidAssetUpdate, err)
+			}
+			maxAmount := *update.MaxOrderAmount
+			asset.MaxOrderAmount = &maxAmount
+		}
+	}
+	if asset.MaxOrderAmount != nil {
+		minAmount, minErr := decimal.Parse(asset.MinOrderAmount)
+		maxAmount, maxErr := decimal.Parse(*asset.MaxOrderAmount)
+		if minErr == nil && maxErr == nil && maxAmount.Cmp(minAmount) < 0 {
+			return nil, fmt.Errorf("%w: max_order_amount is below min_order_amount", ErrInvalidAssetUpdate)
+		}
+	}
+
+	if update.DepositsEnabled != nil {
+		asset.DepositsEnabled = *update.DepositsEnabled
+	}
+	if update.WithdrawalsEnabled != nil {
+		asset.WithdrawalsEnabled = *update.WithdrawalsEnabled
+	}
+
+	updated, err := r.repo.UpdateAsset(ctx, &asset)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrAssetNotFound
+	}
+
+	r.mu.Lock()
+	for i := range r.assets {
+		if r.assets[i].ID == updated.ID {
+			r.assets[i] = *updated
+		}
+	}
+	r.mu.Unlock()
+
+	if err = r.audit.Record(ctx, entities.AuditEventAssetUpdated, actor, updated.Code, map[string]any{
+		"asset_id": updated.ID,
+		"chain":    updated.Chain,
+		"network":  updated.Network,
+		"before":   current,
+		"after":    updated,
+	}); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to record asset update audit", "error", err, "asset_id", updated.ID)
+	}
+
+	r.logger.InfoContext(ctx, "Asset updated",
+		"asset_id", updated.ID,
+		"code", updated.Code,
+		"network", updated.Network,
+		"deposits_enabled", updated.DepositsEnabled,
+		"withdrawals_enabled", updated.WithdrawalsEnabled,
+		"updated_by", actor)
+
+	return updated, nil
+}
+
+// validateAssetAmount accepts non-negative decimal amounts such as "0", "10" or "0.5"
+func validateAssetAmount(amount string) error {
+	value, err := decimal.Parse(amount)
+	if err != nil || value.Sign() < 0 {
+		return fmt.Errorf("invalid amount %q", amount)
+	}
+	return nil
+}
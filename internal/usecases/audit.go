@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type AuditRepository interface {
+	InsertEvent(ctx context.Context, event *entities.AuditEvent) error
+	FindEvents(ctx context.Context, eventType entities.AuditEventType, subject string, limit int) ([]entities.AuditEvent, error)
+}
+
+var _ AuditRepository = (*repository.AuditRepository)(nil)
+
+// AuditService записывает события в журнал аудита
+type AuditService struct {
+	logger *slog.Logger
+	repo   AuditRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(logger *slog.Logger, repo AuditRepository) *AuditService {
+	return &AuditService{logger: logger, repo: repo}
+}
+
+// Record сохраняет событие аудита. Ошибка записи логируется и возвращается вызывающему коду,
+// чтобы тот сам решил, допустимо ли продолжать операцию без записи в журнал.
+func (s *AuditService) Record(ctx context.Context, eventType entities.AuditEventType, actor, subject string, details map[string]any) error {
+	event := &entities.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		Subject:   subject,
+		Details:   details,
+	}
+
+	if err := s.repo.InsertEvent(ctx, event); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record audit event",
+			"error", err,
+			"event_type", eventType,
+			"actor", actor,
+			"subject", subject)
+		return err
+	}
+
+	s.logger.DebugContext(ctx, "Audit event recorded",
+		"id", event.ID,
+		"event_type", eventType,
+		"actor", actor,
+		"subject", subject)
+	return nil
+}
+
+// GetEvents returns the latest audit events filtered by type and subject
+func (s *AuditService) GetEvents(ctx context.Context, eventType entities.AuditEventType, subject string, limit int) ([]entities.AuditEvent, error) {
+	return s.repo.FindEvents(ctx, eventType, subject, limit)
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"slices"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+type BalancesRepository interface {
+	FindUserBalances(ctx context.Context, userID int64) ([]entities.UserBalance, error)
+}
+
+var _ BalancesRepository = (*repository.BalancesRepository)(nil)
+
+// BalanceService returns user balances derived from the deposits and withdrawals of their wallets,
+// so clients do not have to infer them from raw transaction lists
+type BalanceService struct {
+	logger *slog.Logger
+	repo   BalancesRepository
+	assets ActivityAssets
+}
+
+func NewBalanceService(logger *slog.Logger, repo BalancesRepository, assets ActivityAssets) *BalanceService {
+	return &BalanceService{
+		logger: logger,
+		repo:   repo,
+		assets: assets,
+	}
+}
+
+// GetUserBalances returns the available, pending and held balance of the user per asset in asset units.
+// Balances of the same asset on several networks are summed.
+func (s *BalanceService) GetUserBalances(ctx context.Context, userID int64) ([]entities.UserBalance, error) {
+	rows, err := s.repo.FindUserBalances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type totals struct{ available, pending, held decimal.Decimal }
+	byAsset := make(map[string]*totals)
+	codes := make([]string, 0, len(rows))
+	for _, row := range rows {
+		asset, err := s.assets.FindOnNetwork(DefaultAssetCode, row.Chain, row.Network)
+		if err != nil {
+			asset = s.assets.Default()
+		}
+
+		t, ok := byAsset[asset.Code]
+		if !ok {
+			t = &totals{}
+			byAsset[asset.Code] = t
+			codes = append(codes, asset.Code)
+		}
+		t.available = t.available.Add(s.toAssetUnits(ctx, row.Available, asset))
+		t.pending = t.pending.Add(s.toAssetUnits(ctx, row.Pending, asset))
+		t.held = t.held.Add(s.toAssetUnits(ctx, row.Held, asset))
+	}
+	slices.Sort(codes)
+
+	balances := make([]entities.UserBalance, 0, len(codes))
+	for _, code := range codes {
+		t := byAsset[code]
+		balances = append(balances, entities.UserBalance{
+			Asset:     code,
+			Available: t.available.String(),
+			Pending:   t.pending.String(),
+			Held:      t.held.String(),
+		})
+	}
+	return balances, nil
+}
+
+// toAssetUnits переводит сумму в минимальных единицах в единицы актива
+func (s *BalanceService) toAssetUnits(ctx context.Context, amount string, asset entities.Asset) decimal.Decimal {
+	units, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		s.logger.WarnContext(ctx, "Invalid balance amount", "asset", asset.Code, "amount", amount)
+		return decimal.Zero
+	}
+	return decimal.FromUnits(units, asset.Decimals)
+}
@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// batchCollectorABI — ABI контракта contracts/BatchCollector.sol и ERC20 методов allowance/approve
+const batchCollectorABI = `[
+	{"name":"collect","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"token","type":"address"},{"name":"from","type":"address[]"},
+		{"name":"amounts","type":"uint256[]"},{"name":"to","type":"address"}],"outputs":[]},
+	{"name":"multisend","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"token","type":"address"},{"name":"from","type":"address[]"},
+		{"name":"to","type":"address[]"},{"name":"amounts","type":"uint256[]"}],"outputs":[]},
+	{"name":"allowance","type":"function","stateMutability":"view","inputs":[
+		{"name":"owner","type":"address"},{"name":"spender","type":"address"}],
+		"outputs":[{"name":"","type":"uint256"}]},
+	{"name":"approve","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],
+		"outputs":[{"name":"","type":"bool"}]}
+]`
+
+var parsedBatchCollectorABI = mustParseABI(batchCollectorABI)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI: %v", err))
+	}
+	return parsed
+}
+
+// TokenAllowance returns the USDT allowance granted by owner to spender
+func (bsc *WalletService) TokenAllowance(ctx context.Context, client *ethclient.Client, owner, spender common.Address) (*big.Int, error) {
+	data, err := parsedBatchCollectorABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("error packing data for allowance: %w", err)
+	}
+
+	tokenAddr := common.HexToAddress(bsc.smartContractAddress)
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &tokenAddr, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error calling token contract: %w", err)
+	}
+	if len(result) != 32 {
+		return nil, fmt.Errorf("unexpected allowance result length %d", len(result))
+	}
+
+	return new(big.Int).SetBytes(result), nil
+}
+
+// ApproveSpender grants spender an unlimited USDT allowance from the deposit wallet.
+// This is a one-time transaction paid with the wallet's BNB; afterwards the wallet is swept in batches.
+func (bsc *WalletService) ApproveSpender(ctx context.Context, client *ethclient.Client, walletID int, spender common.Address) (string, error) {
+	wallet, err := bsc.repo.FindWalletByID(ctx, walletID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find wallet with ID %d: %w", walletID, err)
+	}
+	if wallet == nil {
+		return "", fmt.Errorf("wallet with ID %d not found", walletID)
+	}
+
+	data, err := parsedBatchCollectorABI.Pack("approve", spender, math.MaxBig256)
+	if err != nil {
+		return "", fmt.Errorf("error packing data for approve: %w", err)
+	}
+
+	owner := common.HexToAddress(wallet.Address)
+	tokenAddr := common.HexToAddress(bsc.smartContractAddress)
+
+	if err = bsc.simulateTransaction(ctx, client, owner, tokenAddr, big.NewInt(0), data); err != nil {
+		return "", err
+	}
+
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: owner, To: &tokenAddr, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate approve gas: %w", err)
+	}
+
+	return bsc.sendTransaction(ctx, client, wallet.KeyVersion, wallet.DerivationPath, owner, tokenAddr, big.NewInt(0),
+		gasLimit*12/10, nil, data, PriorityLow, SignOperationApprove)
+}
+
+// TokenAddress returns the contract of the token the service transfers
+func (bsc *WalletService) TokenAddress() common.Address {
+	return common.HexToAddress(bsc.smartContractAddress)
+}
+
+// ServiceAddress derives the address of a platform key (collector operator, relayer, master wallet) by its path
+func (bsc *WalletService) ServiceAddress(path string) (common.Address, error) {
+	userID, index, err := ParseDerivationPath(path)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid service key path: %w", err)
+	}
+	return bsc.signer.DeriveAddress(bsc.keyring.ServiceVersion(), userID, index)
+}
+
+// ApproveFromPath sets the USDT allowance of the platform key at ownerPath for spender to amount.
+// A zero amount revokes the allowance.
+func (bsc *WalletService) ApproveFromPath(ctx context.Context, client *ethclient.Client, ownerPath string, spender common.Address, amount *big.Int) (string, error) {
+	owner, err := bsc.ServiceAddress(ownerPath)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := parsedBatchCollectorABI.Pack("approve", spender, amount)
+	if err != nil {
+		return "", fmt.Errorf("error packing data for approve: %w", err)
+	}
+
+	tokenAddr := common.HexToAddress(bsc.smartContractAddress)
+
+	if err = bsc.simulateTransaction(ctx, client, owner, tokenAddr, big.NewInt(0), data); err != nil {
+		return "", err
+	}
+
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: owner, To: &tokenAddr, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate approve gas: %w", err)
+	}
+
+	return bsc.sendTransaction(ctx, client, bsc.keyring.ServiceVersion(), ownerPath, owner, tokenAddr, big.NewInt(0),
+		gasLimit*12/10, nil, data, PriorityMedium, SignOperationApprove)
+}
+
+// CollectBatch pulls amounts from the given wallets to the destination with one BatchCollector.collect
+// transaction sent by the collector operator (operatorPath)
+func (bsc *WalletService) CollectBatch(
+	ctx context.Context,
+	client *ethclient.Client,
+	collector common.Address,
+	operatorPath string,
+	from []common.Address,
+	amounts []*big.Int,
+	to common.Address,
+) (string, error) {
+	userID, index, err := ParseDerivationPath(operatorPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid collector operator path: %w", err)
+	}
+	operator, err := bsc.signer.DeriveAddress(bsc.keyring.ServiceVersion(), userID, index)
+	if err != nil {
+		return "", err
+	}
+
+	if err = bsc.CheckTokenHalt(); err != nil {
+		return "", err
+	}
+
+	screened := make([]string, 0, len(from)+1)
+	for _, addr := range from {
+		screened = append(screened, addr.Hex())
+	}
+	if err = bsc.ScreenAddresses(ctx, append(screened, to.Hex())...); err != nil {
+		return "", err
+	}
+
+	data, err := parsedBatchCollectorABI.Pack("collect", common.HexToAddress(bsc.smartContractAddress), from, amounts, to)
+	if err != nil {
+		return "", fmt.Errorf("error packing data for collect: %w", err)
+	}
+
+	if err = bsc.simulateTransaction(ctx, client, operator, collector, big.NewInt(0), data); err != nil {
+		return "", err
+	}
+
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: operator, To: &collector, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate collect gas: %w", err)
+	}
+
+	return bsc.sendTransaction(ctx, client, bsc.keyring.ServiceVersion(), operatorPath, operator, collector, big.NewInt(0),
+		gasLimit*12/10, nil, data, PriorityMedium, SignOperationBatchCollect)
+}
+
+// MultiSend pays amounts[i] from the wallet from[i] to to[i] with one BatchCollector.multisend transaction
+// sent by the collector operator (operatorPath). A single failed transfer reverts the whole transaction.
+func (bsc *WalletService) MultiSend(
+	ctx context.Context,
+	client *ethclient.Client,
+	collector common.Address,
+	operatorPath string,
+	from []common.Address,
+	to []common.Address,
+	amounts []*big.Int,
+) (string, error) {
+	operator, err := bsc.ServiceAddress(operatorPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid collector operator: %w", err)
+	}
+
+	if err = bsc.CheckTokenHalt(); err != nil {
+		return "", err
+	}
+
+	screened := make([]string, 0, len(from)+len(to))
+	for _, addr := range append(append([]common.Address{}, from...), to...) {
+		screened = append(screened, addr.Hex())
+	}
+	if err = bsc.ScreenAddresses(ctx, screened...); err != nil {
+		return "", err
+	}
+
+	data, err := parsedBatchCollectorABI.Pack("multisend", common.HexToAddress(bsc.smartContractAddress), from, to, amounts)
+	if err != nil {
+		return "", fmt.Errorf("error packing data for multisend: %w", err)
+	}
+
+	if err = bsc.simulateTransaction(ctx, client, operator, collector, big.NewInt(0), data); err != nil {
+		return "", err
+	}
+
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: operator, To: &collector, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate multisend gas: %w", err)
+	}
+
+	return bsc.sendTransaction(ctx, client, bsc.keyring.ServiceVersion(), operatorPath, operator, collector, big.NewInt(0),
+		gasLimit*12/10, nil, data, PriorityMedium, SignOperationMultiSend)
+}
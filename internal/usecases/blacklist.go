@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/aml/clients"
+)
+
+// AddressBlacklist проверяет адреса по черному списку контракта токена
+type AddressBlacklist interface {
+	IsBlacklisted(ctx context.Context, address string) (bool, error)
+}
+
+// TokenHalts сообщает, приостановлены ли операции с токеном из-за административного события контракта
+type TokenHalts interface {
+	CheckToken(token string) error
+}
+
+var (
+	_ AddressBlacklist = (*clients.TokenBlacklistService)(nil)
+	_ TokenHalts       = (*TokenMonitorService)(nil)
+)
+
+// ScreenAddresses returns ErrAddressBlacklisted if any of the addresses is blacklisted by the USDT contract:
+// such a transfer would revert on-chain and the funds on a blacklisted wallet are frozen by the issuer
+func (bsc *WalletService) ScreenAddresses(ctx context.Context, addresses ...string) error {
+	if bsc.blacklist == nil {
+		return nil
+	}
+
+	for _, address := range addresses {
+		blacklisted, err := bsc.blacklist.IsBlacklisted(ctx, address)
+		if err != nil {
+			return err
+		}
+		if blacklisted {
+			bsc.logger.WarnContext(ctx, "Transfer blocked: address is blacklisted by USDT contract", "address", address)
+			return fmt.Errorf("%w: %s", ErrAddressBlacklisted, address)
+		}
+	}
+
+	return nil
+}
+
+// CheckTokenHalt returns ErrTokenHalted while USDT withdrawals and sweeps are paused by a token admin event
+func (bsc *WalletService) CheckTokenHalt() error {
+	if bsc.halts == nil {
+		return nil
+	}
+	return bsc.halts.CheckToken(bsc.smartContractAddress)
+}
@@ -0,0 +1,306 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcbatch"
+)
+
+// nativeTransferGas — газ простого перевода BNB, им же оценивает комиссию TransferAllBNB
+const nativeTransferGas = 21000
+
+var bnbDustConsolidated = expvar.NewInt("bsc_bnb_dust_consolidations")
+
+type BNBDustWalletsRepository interface {
+	GetAllTrackedWallets(ctx context.Context) ([]entities.Wallet, error)
+}
+
+type BNBDustTransfers interface {
+	GetGasPriceWithPriority(ctx context.Context, client *ethclient.Client, priority string) (*big.Int, error)
+	TransferAllBNBWithPriority(ctx context.Context, toAddress, depositUserWalletAddress string, userID, index int, priority string) (string, error)
+	Chain() entities.Chain
+}
+
+var (
+	_ BNBDustWalletsRepository = (*repository.WalletsRepository)(nil)
+	_ BNBDustTransfers         = (*WalletService)(nil)
+)
+
+// BNBDustConfig задает консолидацию остатков BNB. Пустой GasWallet отключает задачу, отчет доступен всегда.
+type BNBDustConfig struct {
+	GasWallet string
+	// Минимальный остаток в BNB, меньшие остатки остаются на кошельках
+	MinAmount string
+	Interval  time.Duration
+}
+
+// BNBDustService reports BNB left on deposit wallets after sweeps (gas top-ups that were not spent)
+// and periodically returns balances above the threshold to the gas wallet. Wallets whose balance
+// does not cover the transfer fee are skipped.
+type BNBDustService struct {
+	logger    *slog.Logger
+	repo      BNBDustWalletsRepository
+	transfers BNBDustTransfers
+
+	gasWallet string
+	minAmount *big.Int
+	interval  time.Duration
+
+	// Один проход консолидации за раз: периодический и запущенный администратором не пересекаются
+	runMu   sync.Mutex
+	lastMu  sync.Mutex
+	lastRun *entities.BNBDustRun
+}
+
+func NewBNBDustService(logger *slog.Logger, repo BNBDustWalletsRepository, transfers BNBDustTransfers, config BNBDustConfig) (*BNBDustService, error) {
+	minAmount, err := parseBNBAmount(config.MinAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BNB dust minimum amount: %w", err)
+	}
+	if minAmount == nil {
+		minAmount = new(big.Int)
+	}
+
+	s := &BNBDustService{
+		logger:    logger,
+		repo:      repo,
+		transfers: transfers,
+		minAmount: minAmount,
+		interval:  config.Interval,
+	}
+	if config.GasWallet == "" {
+		return s, nil
+	}
+
+	if !common.IsHexAddress(config.GasWallet) {
+		return nil, fmt.Errorf("invalid BNB dust gas wallet %q", config.GasWallet)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("BNB dust consolidation interval must be positive")
+	}
+	s.gasWallet = common.HexToAddress(config.GasWallet).Hex()
+
+	return s, nil
+}
+
+// Start periodically consolidates BNB dust until ctx is cancelled
+func (s *BNBDustService) Start(ctx context.Context) {
+	if s.gasWallet == "" {
+		s.logger.Info("BNB dust consolidation is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ConsolidateAll(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "BNB dust consolidation failed", "error", err)
+			}
+		}
+	}
+}
+
+// GetReport returns BNB balances of deposit wallets with the fee of returning each of them
+func (s *BNBDustService) GetReport(ctx context.Context) (*entities.BNBDustReport, error) {
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	dust, gasPrice, err := s.scan(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	consolidatable := new(big.Int)
+	for _, wallet := range dust {
+		total.Add(total, wallet.balance)
+		if wallet.Consolidatable {
+			consolidatable.Add(consolidatable, new(big.Int).Sub(wallet.balance, wallet.fee))
+		}
+	}
+
+	report := &entities.BNBDustReport{
+		GasWallet:      s.gasWallet,
+		MinAmount:      WeiToEther(s.minAmount).String(),
+		GasPrice:       gasPrice.String(),
+		Total:          WeiToEther(total).String(),
+		Consolidatable: WeiToEther(consolidatable).String(),
+		Wallets:        make([]entities.BNBDustWallet, 0, len(dust)),
+	}
+	for _, wallet := range dust {
+		report.Wallets = append(report.Wallets, wallet.BNBDustWallet)
+	}
+
+	s.lastMu.Lock()
+	if s.lastRun != nil {
+		lastRun := *s.lastRun
+		report.LastRun = &lastRun
+	}
+	s.lastMu.Unlock()
+
+	return report, nil
+}
+
+// ConsolidateAll returns BNB of every wallet above the threshold and its transfer fee to the gas wallet
+func (s *BNBDustService) ConsolidateAll(ctx context.Context) (*entities.BNBDustRun, error) {
+	if s.gasWallet == "" {
+		return nil, ErrBNBDustDisabled
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	run := &entities.BNBDustRun{StartedAt: time.Now().UTC()}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	dust, _, err := s.scan(ctx, client)
+	client.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	sent := new(big.Int)
+	for _, wallet := range dust {
+		if !wallet.Consolidatable {
+			continue
+		}
+
+		txHash, err := s.consolidate(ctx, wallet)
+		if err != nil {
+			run.Failed++
+			s.logger.ErrorContext(ctx, "Failed to consolidate BNB dust",
+				"error", err,
+				"wallet", wallet.Address,
+				"balance", wallet.Balance)
+			continue
+		}
+
+		run.Consolidated++
+		bnbDustConsolidated.Add(1)
+		sent.Add(sent, new(big.Int).Sub(wallet.balance, wallet.fee))
+		s.logger.InfoContext(ctx, "BNB dust consolidated",
+			"wallet", wallet.Address,
+			"to", s.gasWallet,
+			"balance", wallet.Balance,
+			"fee", wallet.Fee,
+			"tx_hash", txHash)
+	}
+
+	run.Amount = WeiToEther(sent).String()
+	run.FinishedAt = time.Now().UTC()
+
+	s.lastMu.Lock()
+	s.lastRun = run
+	s.lastMu.Unlock()
+
+	s.logger.InfoContext(ctx, "BNB dust consolidation completed",
+		"wallets", len(dust),
+		"consolidated", run.Consolidated,
+		"failed", run.Failed,
+		"amount", run.Amount)
+	return run, nil
+}
+
+func (s *BNBDustService) consolidate(ctx context.Context, wallet bnbDust) (string, error) {
+	userID, index, err := ParseDerivationPath(wallet.derivationPath)
+	if err != nil {
+		return "", err
+	}
+	// Перевод пересчитывает комиссию по цене газа на момент отправки и отправляет остаток за ее вычетом
+	return s.transfers.TransferAllBNBWithPriority(ctx, s.gasWallet, wallet.Address, int(userID), int(index), PriorityLow)
+}
+
+type bnbDust struct {
+	entities.BNBDustWallet
+	derivationPath string
+	balance        *big.Int
+	fee            *big.Int
+}
+
+// scan читает балансы BNB депозитных кошельков сети batch запросами и оценивает комиссию возврата каждого
+func (s *BNBDustService) scan(ctx context.Context, client *ethclient.Client) ([]bnbDust, *big.Int, error) {
+	gasPrice, err := s.transfers.GetGasPriceWithPriority(ctx, client, PriorityLow)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	fee := new(big.Int).Mul(gasPrice, big.NewInt(nativeTransferGas))
+
+	tracked, err := s.repo.GetAllTrackedWallets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chain := s.transfers.Chain()
+	wallets := make([]entities.Wallet, 0, len(tracked))
+	for _, wallet := range tracked {
+		// Форвардеры без ключа, их баланс BNB выводится только через фабрику
+		if wallet.Chain != chain || IsForwarderPath(wallet.DerivationPath) || strings.EqualFold(wallet.Address, s.gasWallet) {
+			continue
+		}
+		wallets = append(wallets, wallet)
+	}
+	if len(wallets) == 0 {
+		return nil, gasPrice, nil
+	}
+
+	addresses := make([]common.Address, len(wallets))
+	for i, wallet := range wallets {
+		addresses[i] = common.HexToAddress(wallet.Address)
+	}
+	balances, balanceErrs, err := rpcbatch.New(client.Client(), rpcbatch.DefaultBatchSize).BalancesAt(ctx, addresses)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get BNB balances: %w", err)
+	}
+
+	dust := make([]bnbDust, 0)
+	for i, wallet := range wallets {
+		if balanceErrs[i] != nil {
+			s.logger.WarnContext(ctx, "Failed to get BNB balance", "error", balanceErrs[i], "wallet", wallet.Address)
+			continue
+		}
+		balance := balances[i]
+		if balance.Sign() <= 0 {
+			continue
+		}
+
+		dust = append(dust, bnbDust{
+			BNBDustWallet: entities.BNBDustWallet{
+				WalletID:       wallet.ID,
+				UserID:         wallet.UserID,
+				Address:        wallet.Address,
+				Balance:        WeiToEther(balance).String(),
+				Fee:            WeiToEther(fee).String(),
+				Consolidatable: balance.Cmp(s.minAmount) >= 0 && balance.Cmp(fee) > 0,
+			},
+			derivationPath: wallet.DerivationPath,
+			balance:        balance,
+			fee:            fee,
+		})
+	}
+
+	return dust, gasPrice, nil
+}
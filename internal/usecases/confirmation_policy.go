@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// confirmationPolicyAnyAsset — правило для всех активов сети, если для актива нет своего
+const confirmationPolicyAnyAsset = "*"
+
+// ConfirmationPolicyConfig задает число подтверждений в зависимости от суммы депозита.
+// Правило: chain/ASSET=limit:confs;limit:confs;confs — депозит меньше limit (в валюте Currency) требует confs
+// подтверждений, последнее значение без лимита действует для остальных сумм. Например "bsc/USDT=100:1;5000:3;12".
+type ConfirmationPolicyConfig struct {
+	Tiers    []string
+	Currency string
+	// Число подтверждений для сетей и активов без правил
+	Default uint64
+}
+
+type confirmationTier struct {
+	below         *big.Rat // nil — для любой суммы
+	confirmations uint64
+}
+
+// ConfirmationPolicy returns the number of confirmations a deposit requires: small deposits are credited
+// after fewer blocks, large ones wait longer to make a reorganization attack unprofitable.
+type ConfirmationPolicy struct {
+	logger *slog.Logger
+	rates  RateProvider
+
+	currency string
+	fallback uint64
+	tiers    map[string][]confirmationTier
+}
+
+func NewConfirmationPolicy(logger *slog.Logger, rates RateProvider, config ConfirmationPolicyConfig) (*ConfirmationPolicy, error) {
+	if config.Default == 0 {
+		return nil, fmt.Errorf("default required confirmations must be positive")
+	}
+
+	policy := &ConfirmationPolicy{
+		logger:   logger,
+		rates:    rates,
+		currency: strings.ToUpper(strings.TrimSpace(config.Currency)),
+		fallback: config.Default,
+		tiers:    make(map[string][]confirmationTier, len(config.Tiers)),
+	}
+
+	for _, entry := range config.Tiers {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rules, ok := strings.Cut(entry, "=")
+		chain, asset, okKey := strings.Cut(key, "/")
+		if !ok || !okKey {
+			return nil, fmt.Errorf("confirmation tiers %q must be in the form chain/asset=limit:confs;...;confs", entry)
+		}
+
+		tiers, err := parseConfirmationTiers(rules)
+		if err != nil {
+			return nil, fmt.Errorf("confirmation tiers %q: %w", entry, err)
+		}
+		policy.tiers[confirmationPolicyKey(entities.Chain(strings.ToLower(strings.TrimSpace(chain))), asset)] = tiers
+	}
+
+	if len(policy.tiers) > 0 && (rates == nil || policy.currency == "") {
+		return nil, fmt.Errorf("confirmation tiers require rates and a currency")
+	}
+
+	return policy, nil
+}
+
+func parseConfirmationTiers(rules string) ([]confirmationTier, error) {
+	var tiers []confirmationTier
+	for _, rule := range strings.Split(rules, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		var tier confirmationTier
+		limit, confs, bounded := strings.Cut(rule, ":")
+		if !bounded {
+			confs = limit
+		} else {
+			below, ok := new(big.Rat).SetString(strings.TrimSpace(limit))
+			if !ok || below.Sign() <= 0 {
+				return nil, fmt.Errorf("invalid amount limit %q", limit)
+			}
+			tier.below = below
+		}
+
+		n, err := strconv.ParseUint(strings.TrimSpace(confs), 10, 64)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid number of confirmations %q", confs)
+		}
+		tier.confirmations = n
+		tiers = append(tiers, tier)
+	}
+
+	if len(tiers) == 0 || tiers[len(tiers)-1].below != nil {
+		return nil, fmt.Errorf("the last tier must set confirmations for any amount")
+	}
+
+	// Правила применяются по возрастанию лимита; правило без лимита — последнее
+	sort.SliceStable(tiers, func(i, j int) bool {
+		if tiers[i].below == nil || tiers[j].below == nil {
+			return tiers[j].below == nil && tiers[i].below != nil
+		}
+		return tiers[i].below.Cmp(tiers[j].below) < 0
+	})
+
+	return tiers, nil
+}
+
+func confirmationPolicyKey(chain entities.Chain, asset string) string {
+	return string(chain) + "/" + strings.ToUpper(strings.TrimSpace(asset))
+}
+
+// Required returns the number of confirmations for a deposit of amount minimal units of the asset.
+// When the rate is unavailable the deposit waits for the largest tier.
+func (p *ConfirmationPolicy) Required(ctx context.Context, chain entities.Chain, asset entities.Asset, amount *big.Int) uint64 {
+	tiers, ok := p.tiers[confirmationPolicyKey(chain, asset.Code)]
+	if !ok {
+		tiers, ok = p.tiers[confirmationPolicyKey(chain, confirmationPolicyAnyAsset)]
+	}
+	if !ok {
+		return p.fallback
+	}
+
+	highest := tiers[len(tiers)-1].confirmations
+	rate, err := p.rates.Rate(ctx, asset.Code, p.currency)
+	if err != nil {
+		p.logger.WarnContext(ctx, "Rate unavailable for confirmation tiers, using the highest tier",
+			"asset", asset.Code, "currency", p.currency, "error", err)
+		return highest
+	}
+
+	value := new(big.Rat).SetFrac(amount, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.Decimals)), nil))
+	value.Mul(value, rate)
+	for _, tier := range tiers {
+		if tier.below == nil || value.Cmp(tier.below) < 0 {
+			return tier.confirmations
+		}
+	}
+
+	return highest
+}
@@ -0,0 +1,292 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// dashboardProjectionOverlap — проектор перечитывает изменения с отступом назад от курсора: строка, чья транзакция
+// зафиксирована позже соседних, все равно попадает в проекцию. Повторный пересчет дня идемпотентен
+const dashboardProjectionOverlap = time.Minute
+
+// maxHourlyDepositStatsRange ограничивает период почасовой статистики депозитов
+const maxHourlyDepositStatsRange = 31 * 24 * time.Hour
+
+// dashboardProjections — проекции, которые обновляет проектор дашборда
+var dashboardProjections = []string{
+	repository.DashboardProjectionOrders,
+	repository.DashboardProjectionDeposits,
+	repository.DashboardProjectionDepositStatsHourly,
+	repository.DashboardProjectionDepositStatsDaily,
+}
+
+type DashboardRepository interface {
+	FindCursor(ctx context.Context, projection string) (time.Time, error)
+	FindCursors(ctx context.Context) ([]entities.DashboardProjectionCursor, error)
+	Project(ctx context.Context, projection string, since time.Time, rebuild bool) (int, error)
+	RefreshAMLBacklog(ctx context.Context) error
+	FindOrderDays(ctx context.Context, from, to time.Time) ([]entities.DashboardOrderDay, error)
+	FindDepositDays(ctx context.Context, from, to time.Time) ([]entities.DashboardDepositDay, error)
+	FindAMLBacklog(ctx context.Context) (*entities.DashboardAMLBacklog, error)
+	FindDepositStats(ctx context.Context, granularity entities.DepositStatsGranularity, from, to time.Time, asset string) ([]entities.DepositStatsBucket, error)
+}
+
+var _ DashboardRepository = (*repository.DashboardRepository)(nil)
+
+// DashboardConfig задает частоту обновления проекций и их полной пересборки
+type DashboardConfig struct {
+	Interval time.Duration
+	// Полная пересборка убирает из проекций удаленные строки, которых нет в потоке изменений
+	RebuildInterval time.Duration
+}
+
+// DashboardService maintains the read model of the admin dashboard: order counts per status, deposits per day
+// with hourly and daily deposit statistics per asset, and the AML backlog. Projections are refreshed incrementally from rows changed since the cursor of each
+// projection, so dashboard queries never touch the order and transaction tables.
+type DashboardService struct {
+	logger  *slog.Logger
+	repo    DashboardRepository
+	rates   RateProvider
+	tracker WorkerTracker
+
+	interval        time.Duration
+	rebuildInterval time.Duration
+
+	// mu не дает фоновому обновлению и пересборке по запросу работать одновременно
+	mu          sync.Mutex
+	lastRebuild time.Time
+}
+
+func NewDashboardService(logger *slog.Logger, repo DashboardRepository, rates RateProvider, tracker WorkerTracker, config DashboardConfig) (*DashboardService, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("dashboard projection interval must be positive")
+	}
+	if config.RebuildInterval < config.Interval {
+		return nil, fmt.Errorf("dashboard rebuild interval %s must not be shorter than the projection interval %s",
+			config.RebuildInterval, config.Interval)
+	}
+
+	return &DashboardService{
+		logger:          logger,
+		repo:            repo,
+		rates:           rates,
+		tracker:         tracker,
+		interval:        config.Interval,
+		rebuildInterval: config.RebuildInterval,
+	}, nil
+}
+
+// Start refreshes the projections until ctx is cancelled. The first pass rebuilds them.
+func (s *DashboardService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			days, err := s.Refresh(ctx, time.Since(s.lastRebuildAt()) >= s.rebuildInterval)
+			s.tracker.Done(days, err)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Dashboard projection failed", "error", err)
+			}
+		}
+	}
+}
+
+// Refresh applies the changes since the cursors to the projections and snapshots the AML backlog.
+// Returns the number of recomputed days.
+func (s *DashboardService) Refresh(ctx context.Context, rebuild bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total int
+	for _, projection := range dashboardProjections {
+		since, err := s.repo.FindCursor(ctx, projection)
+		if err != nil {
+			return total, err
+		}
+		if !since.IsZero() {
+			since = since.Add(-dashboardProjectionOverlap)
+		}
+
+		days, err := s.repo.Project(ctx, projection, since, rebuild)
+		if err != nil {
+			return total, err
+		}
+		total += days
+	}
+
+	if err := s.repo.RefreshAMLBacklog(ctx); err != nil {
+		return total, err
+	}
+
+	if rebuild {
+		s.lastRebuild = time.Now()
+		s.logger.InfoContext(ctx, "Dashboard projections rebuilt", "days", total)
+	}
+	return total, nil
+}
+
+// Rebuild recomputes the projections from scratch
+func (s *DashboardService) Rebuild(ctx context.Context) ([]entities.DashboardProjectionCursor, error) {
+	if _, err := s.Refresh(ctx, true); err != nil {
+		return nil, err
+	}
+	return s.repo.FindCursors(ctx)
+}
+
+// GetOverview returns orders and deposits per day in [from, to) with the current AML backlog, read from the projections
+func (s *DashboardService) GetOverview(ctx context.Context, from, to time.Time) (*entities.DashboardOverview, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidReportRequest)
+	}
+
+	orders, err := s.repo.FindOrderDays(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deposits, err := s.repo.FindDepositDays(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	backlog, err := s.repo.FindAMLBacklog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursors, err := s.repo.FindCursors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byStatus := make(map[entities.OrderStatus]int)
+	for _, day := range orders {
+		byStatus[day.Status] += day.Orders
+	}
+
+	return &entities.DashboardOverview{
+		From:           from,
+		To:             to,
+		OrdersByStatus: byStatus,
+		Orders:         orders,
+		Deposits:       deposits,
+		AMLBacklog:     backlog,
+		Cursors:        cursors,
+	}, nil
+}
+
+// GetDepositStats returns confirmed deposits per asset and hour or day in [from, to), of one asset code when set,
+// with totals per asset. When fiat is set the totals are valued in the ASSET/FIAT pair at the configured rate.
+func (s *DashboardService) GetDepositStats(ctx context.Context, granularity entities.DepositStatsGranularity, from, to time.Time, asset, fiat string) (*entities.DepositStats, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidReportRequest)
+	}
+	switch granularity {
+	case entities.DepositStatsDaily:
+	case entities.DepositStatsHourly:
+		if to.Sub(from) > maxHourlyDepositStatsRange {
+			return nil, fmt.Errorf("%w: hourly statistics are limited to %s", ErrInvalidReportRequest, maxHourlyDepositStatsRange)
+		}
+	default:
+		return nil, fmt.Errorf("%w: granularity must be %q or %q", ErrInvalidReportRequest, entities.DepositStatsHourly, entities.DepositStatsDaily)
+	}
+
+	buckets, err := s.repo.FindDepositStats(ctx, granularity, from, to, strings.ToUpper(strings.TrimSpace(asset)))
+	if err != nil {
+		return nil, err
+	}
+	cursors, err := s.repo.FindCursors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &entities.DepositStats{
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+		Buckets:     buckets,
+		Assets:      s.depositStatsTotals(ctx, buckets, strings.ToUpper(strings.TrimSpace(fiat))),
+	}
+	projection := repository.DashboardProjectionDepositStatsDaily
+	if granularity == entities.DepositStatsHourly {
+		projection = repository.DashboardProjectionDepositStatsHourly
+	}
+	for _, cursor := range cursors {
+		if cursor.Projection == projection {
+			stats.Cursors = append(stats.Cursors, cursor)
+		}
+	}
+	return stats, nil
+}
+
+// depositStatsTotals суммирует интервалы по активу. Курс пары запрашивается один раз на актив,
+// без курса итог отдается только в минимальных единицах
+func (s *DashboardService) depositStatsTotals(ctx context.Context, buckets []entities.DepositStatsBucket, fiat string) []entities.DepositStatsAsset {
+	var (
+		totals   []entities.DepositStatsAsset
+		volumes  []*big.Int
+		decimals []int
+		index    = make(map[int]int)
+	)
+	for _, bucket := range buckets {
+		i, ok := index[bucket.AssetID]
+		if !ok {
+			i = len(totals)
+			index[bucket.AssetID] = i
+			totals = append(totals, entities.DepositStatsAsset{
+				AssetID: bucket.AssetID,
+				Asset:   bucket.Asset,
+				Chain:   bucket.Chain,
+				Network: bucket.Network,
+			})
+			volumes = append(volumes, new(big.Int))
+			decimals = append(decimals, bucket.Decimals)
+		}
+
+		volume, ok := new(big.Int).SetString(bucket.Volume, 10)
+		if !ok {
+			s.logger.WarnContext(ctx, "Invalid deposit stats volume", "asset_id", bucket.AssetID, "start", bucket.Start, "volume", bucket.Volume)
+			continue
+		}
+		totals[i].Deposits += bucket.Deposits
+		volumes[i].Add(volumes[i], volume)
+	}
+
+	for i := range totals {
+		totals[i].Volume = volumes[i].String()
+		totals[i].AverageDeposit = "0"
+		if totals[i].Deposits > 0 {
+			totals[i].AverageDeposit = new(big.Int).Quo(volumes[i], big.NewInt(int64(totals[i].Deposits))).String()
+		}
+		if fiat == "" {
+			continue
+		}
+
+		rate, err := s.rates.Rate(ctx, totals[i].Asset, fiat)
+		if err != nil {
+			s.logger.DebugContext(ctx, "Rate unavailable for deposit stats", "asset", totals[i].Asset, "fiat", fiat, "error", err)
+			continue
+		}
+		unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals[i])), nil)
+		amount := new(big.Rat).SetFrac(volumes[i], unit)
+		totals[i].Pair = rateKey(totals[i].Asset, fiat)
+		totals[i].FiatVolume = amount.Mul(amount, rate).FloatString(2)
+	}
+	return totals
+}
+
+func (s *DashboardService) lastRebuildAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastRebuild
+}
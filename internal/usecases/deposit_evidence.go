@@ -0,0 +1,257 @@
+package usecases
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcbatch"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+type DepositEvidenceRepository interface {
+	FindPendingDeposits(ctx context.Context, chain entities.Chain, maxAttempts, limit int) ([]entities.PendingEvidence, error)
+	SaveEvidence(ctx context.Context, evidence *entities.DepositEvidence) error
+	RecordFailure(ctx context.Context, txHash string, chain entities.Chain, message string) error
+	FindEvidence(ctx context.Context, txHash string) (*entities.DepositEvidence, error)
+}
+
+// DepositEvidenceChain возвращает ответы ноды по транзакциям без разбора
+type DepositEvidenceChain interface {
+	RawEvidence(ctx context.Context, hashes []common.Hash) ([]rpcbatch.RawEvidence, []error, error)
+}
+
+var (
+	_ DepositEvidenceRepository = (*repository.DepositEvidenceRepository)(nil)
+	_ DepositEvidenceChain      = (*rpcbatch.Batcher)(nil)
+)
+
+// DepositEvidenceConfig задает период архивации, размер пачки и число попыток для депозита
+type DepositEvidenceConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+}
+
+// DepositEvidenceService archives the raw on-chain evidence of every credited BSC deposit: the transaction with
+// its input data, the receipt and the block header exactly as returned by the node. Bundles are stored gzip
+// compressed with the SHA-256 of the uncompressed JSON, so compliance can reproduce them without an explorer.
+type DepositEvidenceService struct {
+	logger  *slog.Logger
+	repo    DepositEvidenceRepository
+	chain   DepositEvidenceChain
+	tracker WorkerTracker
+
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+func NewDepositEvidenceService(logger *slog.Logger, repo DepositEvidenceRepository, chain DepositEvidenceChain, tracker WorkerTracker, config DepositEvidenceConfig) (*DepositEvidenceService, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("deposit evidence interval must be positive")
+	}
+	if config.BatchSize <= 0 || config.MaxAttempts <= 0 {
+		return nil, errors.New("deposit evidence batch size and max attempts must be positive")
+	}
+
+	return &DepositEvidenceService{
+		logger:      logger,
+		repo:        repo,
+		chain:       chain,
+		tracker:     tracker,
+		interval:    config.Interval,
+		batchSize:   config.BatchSize,
+		maxAttempts: config.MaxAttempts,
+	}, nil
+}
+
+// Start archives evidence of newly credited deposits until ctx is cancelled
+func (s *DepositEvidenceService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			archived, err := s.Archive(ctx)
+			s.tracker.Done(archived, err)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Deposit evidence archival failed", "error", err)
+			}
+		}
+	}
+}
+
+// Archive fetches and stores the evidence of one batch of credited deposits and returns the number archived.
+// A deposit whose evidence cannot be fetched or does not match the stored transaction is retried later.
+func (s *DepositEvidenceService) Archive(ctx context.Context) (int, error) {
+	pending, err := s.repo.FindPendingDeposits(ctx, entities.ChainBSC, s.maxAttempts, s.batchSize)
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+
+	hashes := make([]common.Hash, len(pending))
+	for i, deposit := range pending {
+		hashes[i] = common.HexToHash(deposit.TxHash)
+	}
+	raw, errs, err := s.chain.RawEvidence(ctx, hashes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch deposit evidence: %w", timeouts.Classify("rpc", err))
+	}
+
+	var archived int
+	for i, deposit := range pending {
+		evidence, err := s.buildEvidence(deposit, raw[i], errs[i])
+		if err != nil {
+			s.logger.WarnContext(ctx, "Deposit evidence not archived", "error", err, "tx_hash", deposit.TxHash)
+			if err = s.repo.RecordFailure(ctx, deposit.TxHash, entities.ChainBSC, err.Error()); err != nil {
+				return archived, err
+			}
+			continue
+		}
+
+		if err = s.repo.SaveEvidence(ctx, evidence); err != nil {
+			return archived, err
+		}
+		archived++
+	}
+
+	if archived > 0 {
+		s.logger.InfoContext(ctx, "Deposit evidence archived", "deposits", archived)
+	}
+	return archived, nil
+}
+
+// buildEvidence проверяет, что ответы ноды относятся к сохраненной транзакции, и упаковывает их
+func (s *DepositEvidenceService) buildEvidence(deposit entities.PendingEvidence, raw rpcbatch.RawEvidence, fetchErr error) (*entities.DepositEvidence, error) {
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	var receipt struct {
+		TransactionHash common.Hash    `json:"transactionHash"`
+		BlockHash       common.Hash    `json:"blockHash"`
+		BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(raw.Receipt, &receipt); err != nil {
+		return nil, fmt.Errorf("invalid receipt: %w", err)
+	}
+	var block struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(raw.Block, &block); err != nil {
+		return nil, fmt.Errorf("invalid block: %w", err)
+	}
+
+	if !strings.EqualFold(receipt.TransactionHash.Hex(), deposit.TxHash) {
+		return nil, fmt.Errorf("receipt belongs to %s", receipt.TransactionHash.Hex())
+	}
+	if block.Hash != receipt.BlockHash {
+		return nil, fmt.Errorf("block %s does not match the receipt block %s", block.Hash.Hex(), receipt.BlockHash.Hex())
+	}
+	// Депозит, переставленный реорганизацией в другой блок, архивируется с фактическим блоком
+	if int64(receipt.BlockNumber) != deposit.BlockNumber {
+		s.logger.Warn("Deposit mined in a different block than recorded",
+			"tx_hash", deposit.TxHash, "recorded_block", deposit.BlockNumber, "receipt_block", uint64(receipt.BlockNumber))
+	}
+
+	payload, err := json.Marshal(entities.EvidenceBundle{
+		TxHash:      deposit.TxHash,
+		Chain:       entities.ChainBSC,
+		FetchedAt:   time.Now().UTC(),
+		Transaction: raw.Transaction,
+		Receipt:     raw.Receipt,
+		Block:       raw.Block,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode evidence: %w", err)
+	}
+
+	var compressed bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to compress evidence: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress evidence: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	checksum := hex.EncodeToString(sum[:])
+	blockNumber := int64(receipt.BlockNumber)
+	blockHash := receipt.BlockHash.Hex()
+	return &entities.DepositEvidence{
+		TxHash:      deposit.TxHash,
+		Chain:       entities.ChainBSC,
+		BlockNumber: &blockNumber,
+		BlockHash:   &blockHash,
+		Bundle:      compressed.Bytes(),
+		SHA256:      &checksum,
+	}, nil
+}
+
+// GetEvidence returns the archived evidence of the deposit with the bundle unpacked and verified against its checksum
+func (s *DepositEvidenceService) GetEvidence(ctx context.Context, txHash string) (*entities.DepositEvidenceView, error) {
+	evidence, payload, err := s.loadEvidence(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundle entities.EvidenceBundle
+	if err = json.Unmarshal(payload, &bundle); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDepositEvidenceCorrupted, err)
+	}
+	return &entities.DepositEvidenceView{DepositEvidence: *evidence, Bundle: &bundle}, nil
+}
+
+// GetEvidenceArchive returns the verified compressed bundle as stored, for download
+func (s *DepositEvidenceService) GetEvidenceArchive(ctx context.Context, txHash string) (*entities.DepositEvidence, error) {
+	evidence, _, err := s.loadEvidence(ctx, txHash)
+	return evidence, err
+}
+
+func (s *DepositEvidenceService) loadEvidence(ctx context.Context, txHash string) (*entities.DepositEvidence, []byte, error) {
+	evidence, err := s.repo.FindEvidence(ctx, txHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if evidence == nil || evidence.ArchivedAt == nil || evidence.SHA256 == nil {
+		return nil, nil, ErrDepositEvidenceNotFound
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(evidence.Bundle))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDepositEvidenceCorrupted, err)
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDepositEvidenceCorrupted, err)
+	}
+
+	sum := sha256.Sum256(payload)
+	if hex.EncodeToString(sum[:]) != *evidence.SHA256 {
+		s.logger.ErrorContext(ctx, "Deposit evidence checksum mismatch", "tx_hash", evidence.TxHash)
+		return nil, nil, ErrDepositEvidenceCorrupted
+	}
+	return evidence, payload, nil
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+type DepositFiltersRepository interface {
+	IgnoreTransaction(ctx context.Context, txHash string, reason entities.DepositIgnoreReason) (bool, error)
+	CountIdenticalDeposits(ctx context.Context, fromAddress string, amount *big.Int, since time.Time) (int, error)
+	IgnoreIdenticalDeposits(ctx context.Context, fromAddress string, amount *big.Int, since time.Time, reason entities.DepositIgnoreReason) (int64, error)
+	CountProbedWallets(ctx context.Context, fromAddress string, below *big.Int, since time.Time) (int, error)
+	IgnoreProbingDeposits(ctx context.Context, fromAddress string, below *big.Int, since time.Time, reason entities.DepositIgnoreReason) (int64, error)
+}
+
+// DepositProbingBlacklist добавляет отправителя, прощупывающего депозитные адреса, в локальный черный список AML
+type DepositProbingBlacklist interface {
+	BlacklistAddress(ctx context.Context, address, reason string) error
+}
+
+var (
+	_ DepositFiltersRepository = (*repository.TransactionsRepository)(nil)
+	_ DepositProbingBlacklist  = (*AMLService)(nil)
+)
+
+var depositProbingAlerts = expvar.NewInt("deposit_probing_alerts")
+
+// DepositFilterConfig задает правила против серии микропереводов и прощупывания депозитных адресов.
+// SpamThreshold <= 0 и ProbeWallets <= 0 отключают соответствующее правило.
+type DepositFilterConfig struct {
+	// Число одинаковых микропереводов от одного отправителя за SpamWindow, с которого они не зачисляются
+	SpamThreshold int
+	SpamWindow    time.Duration
+	// Число разных депозитных кошельков, получивших микропереводы от одного отправителя за ProbeWindow,
+	// с которого отправитель считается прощупывающим адреса
+	ProbeWallets int
+	ProbeWindow  time.Duration
+}
+
+// DepositFilterService keeps dust and spam out of order matching. A recorded deposit below the minimum deposit
+// of its asset is ignored as dust. A series of identical transfers below the minimum order amount from one sender
+// is ignored as spam: such transfers cannot pay an order and are usually address poisoning.
+// Micro-transfers from a sender that reached many distinct deposit wallets are address probing: they are ignored,
+// a security alert is raised and the sender is optionally blacklisted in the local AML list.
+// Ignored deposits stay recorded and are never credited.
+type DepositFilterService struct {
+	logger    *slog.Logger
+	repo      DepositFiltersRepository
+	audit     *AuditService
+	blacklist DepositProbingBlacklist
+
+	spamThreshold int
+	spamWindow    time.Duration
+	probeWallets  int
+	probeWindow   time.Duration
+
+	// Время последнего оповещения о прощупывании по отправителю: одно оповещение за окно
+	alertsMu       sync.Mutex
+	probeAlertedAt map[string]time.Time
+}
+
+func NewDepositFilterService(logger *slog.Logger, repo DepositFiltersRepository, audit *AuditService, config DepositFilterConfig) (*DepositFilterService, error) {
+	if config.SpamThreshold > 0 && config.SpamWindow <= 0 {
+		return nil, fmt.Errorf("deposit spam window must be positive")
+	}
+	if config.ProbeWallets > 0 && config.ProbeWindow <= 0 {
+		return nil, fmt.Errorf("deposit probing window must be positive")
+	}
+
+	return &DepositFilterService{
+		logger:         logger,
+		repo:           repo,
+		audit:          audit,
+		spamThreshold:  config.SpamThreshold,
+		spamWindow:     config.SpamWindow,
+		probeWallets:   config.ProbeWallets,
+		probeWindow:    config.ProbeWindow,
+		probeAlertedAt: make(map[string]time.Time),
+	}, nil
+}
+
+// SetProbingBlacklist enables adding senders that probe deposit addresses to the local AML blacklist
+func (s *DepositFilterService) SetProbingBlacklist(blacklist DepositProbingBlacklist) {
+	s.blacklist = blacklist
+}
+
+// FilterDeposit marks the recorded deposit as ignored if it probes deposit addresses, is dust or part of
+// a micro-transfer series. Reports whether the deposit is ignored, the caller then skips further checks of the deposit.
+func (s *DepositFilterService) FilterDeposit(ctx context.Context, asset entities.Asset, txHash, fromAddress string, amount *big.Int) (bool, error) {
+	value := decimal.FromUnits(amount, asset.Decimals)
+
+	// Микроперевод — сумма, которой не оплатить ни один ордер актива
+	minOrder, err := decimal.Parse(asset.MinOrderAmount)
+	micro := err == nil && value.Cmp(minOrder) < 0
+
+	// Прощупывание проверяется до пыли: адреса обычно прощупывают нулевыми и пылевыми переводами
+	if micro && s.probeWallets > 0 && fromAddress != "" {
+		probing, err := s.checkProbing(ctx, asset, txHash, fromAddress, value, minOrder)
+		if err != nil || probing {
+			return probing, err
+		}
+	}
+
+	if minDeposit, err := decimal.Parse(asset.MinDepositAmount); err == nil && value.Cmp(minDeposit) < 0 {
+		ignored, err := s.repo.IgnoreTransaction(ctx, txHash, entities.DepositIgnoredDust)
+		if err != nil {
+			return false, err
+		}
+		if ignored {
+			s.logger.InfoContext(ctx, "Dust deposit ignored",
+				"tx_hash", txHash,
+				"from", fromAddress,
+				"amount", value.String(),
+				"min_deposit", asset.MinDepositAmount,
+				"asset", asset.Code)
+		}
+		return ignored, nil
+	}
+
+	if s.spamThreshold <= 0 || fromAddress == "" || !micro {
+		return false, nil
+	}
+
+	since := time.Now().Add(-s.spamWindow)
+	count, err := s.repo.CountIdenticalDeposits(ctx, fromAddress, amount, since)
+	if err != nil {
+		return false, err
+	}
+	if count < s.spamThreshold {
+		return false, nil
+	}
+
+	// Вместе с текущим не зачисляются и еще не зачтенные переводы серии
+	ignored, err := s.repo.IgnoreIdenticalDeposits(ctx, fromAddress, amount, since, entities.DepositIgnoredSpam)
+	if err != nil {
+		return false, err
+	}
+
+	// Оповещение комплаенса уходит в Sentry вместе с ошибками
+	s.logger.ErrorContext(ctx, "Repeated micro-transfers ignored as spam",
+		"tx_hash", txHash,
+		"from", fromAddress,
+		"amount", value.String(),
+		"asset", asset.Code,
+		"transfers", count,
+		"ignored", ignored,
+		"window", s.spamWindow.String())
+
+	if err = s.audit.Record(ctx, entities.AuditEventDepositSpamIgnored, "rule:deposit_spam", fromAddress, map[string]any{
+		"tx_hash":   txHash,
+		"asset":     asset.Code,
+		"amount":    value.String(),
+		"transfers": count,
+		"ignored":   ignored,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record deposit spam audit", "error", err, "tx_hash", txHash)
+	}
+
+	return true, nil
+}
+
+// checkProbing ignores micro-transfers of a sender that reached probeWallets distinct deposit wallets within
+// the probing window. The security alert and the blacklisting happen once per sender and window.
+func (s *DepositFilterService) checkProbing(ctx context.Context, asset entities.Asset, txHash, fromAddress string, value, minOrder decimal.Decimal) (bool, error) {
+	below := minOrder.UnitsRounded(asset.Decimals, decimal.RoundUp)
+	since := time.Now().Add(-s.probeWindow)
+
+	wallets, err := s.repo.CountProbedWallets(ctx, fromAddress, below, since)
+	if err != nil {
+		return false, err
+	}
+	if wallets < s.probeWallets {
+		return false, nil
+	}
+
+	// Вместе с текущим не зачисляются и еще не зачтенные микропереводы отправителя за окно
+	ignored, err := s.repo.IgnoreProbingDeposits(ctx, fromAddress, below, since, entities.DepositIgnoredProbing)
+	if err != nil {
+		return false, err
+	}
+	if !s.markProbeAlerted(fromAddress) {
+		return true, nil
+	}
+
+	depositProbingAlerts.Add(1)
+	// Оповещение безопасности уходит в Sentry вместе с ошибками
+	s.logger.ErrorContext(ctx, "Deposit address probing detected",
+		"tx_hash", txHash,
+		"from", fromAddress,
+		"amount", value.String(),
+		"asset", asset.Code,
+		"wallets", wallets,
+		"ignored", ignored,
+		"window", s.probeWindow.String(),
+		"blacklist", s.blacklist != nil)
+
+	if err = s.audit.Record(ctx, entities.AuditEventDepositProbingDetected, "rule:deposit_probing", fromAddress, map[string]any{
+		"tx_hash":     txHash,
+		"asset":       asset.Code,
+		"amount":      value.String(),
+		"wallets":     wallets,
+		"ignored":     ignored,
+		"blacklisted": s.blacklist != nil,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record deposit probing audit", "error", err, "tx_hash", txHash)
+	}
+
+	if s.blacklist != nil {
+		if err = s.blacklist.BlacklistAddress(ctx, fromAddress, "deposit_probing"); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to blacklist probing sender", "error", err, "from", fromAddress)
+		}
+	}
+
+	return true, nil
+}
+
+// markProbeAlerted reports whether the sender was not alerted within the probing window and marks it alerted
+func (s *DepositFilterService) markProbeAlerted(fromAddress string) bool {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	now := time.Now()
+	if alertedAt, ok := s.probeAlertedAt[fromAddress]; ok && now.Sub(alertedAt) < s.probeWindow {
+		return false
+	}
+	// Устаревшие отметки забываются, чтобы карта не росла
+	for address, alertedAt := range s.probeAlertedAt {
+		if now.Sub(alertedAt) >= s.probeWindow {
+			delete(s.probeAlertedAt, address)
+		}
+	}
+	s.probeAlertedAt[fromAddress] = now
+	return true
+}
@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const depositHoldsListLimit = 200
+
+type DepositHoldsRepository interface {
+	GetUserDepositAverage(ctx context.Context, userID int64, excludeTxHash string) (int, string, error)
+	HoldTransaction(ctx context.Context, txHash, reason string) error
+	ReleaseHold(ctx context.Context, txHash, releasedBy string) (bool, error)
+	FindHeldDeposits(ctx context.Context, limit int) ([]entities.DepositHold, error)
+}
+
+type DepositHoldWallets interface {
+	FindWalletByAddress(ctx context.Context, address string) (*entities.Wallet, error)
+}
+
+var (
+	_ DepositHoldsRepository = (*repository.TransactionsRepository)(nil)
+	_ DepositHoldWallets     = (*repository.WalletsRepository)(nil)
+)
+
+// DepositHoldConfig задает правило удержания. Multiple <= 0 отключает правило.
+type DepositHoldConfig struct {
+	// Депозит больше Multiple средних депозитов пользователя удерживается, например "10"
+	Multiple string
+	// Правило применяется, если у пользователя не меньше MinHistory предыдущих депозитов
+	MinHistory int
+}
+
+// DepositHoldService places deposits far above the user's historical average on a manual-release hold.
+// A held deposit is confirmed as usual but is not credited to orders until compliance releases it.
+// The hold is independent of the AML status.
+type DepositHoldService struct {
+	logger   *slog.Logger
+	repo     DepositHoldsRepository
+	wallets  DepositHoldWallets
+	audit    *AuditService
+	notifier Notifier
+
+	multiple   *big.Rat
+	minHistory int
+}
+
+func NewDepositHoldService(
+	logger *slog.Logger,
+	repo DepositHoldsRepository,
+	wallets DepositHoldWallets,
+	audit *AuditService,
+	notifier Notifier,
+	config DepositHoldConfig,
+) (*DepositHoldService, error) {
+	s := &DepositHoldService{
+		logger:     logger,
+		repo:       repo,
+		wallets:    wallets,
+		audit:      audit,
+		notifier:   notifier,
+		minHistory: max(config.MinHistory, 1),
+	}
+
+	if value := strings.TrimSpace(config.Multiple); value != "" {
+		multiple, ok := new(big.Rat).SetString(value)
+		if !ok || multiple.Sign() < 0 {
+			return nil, fmt.Errorf("invalid deposit hold multiple %q", value)
+		}
+		if multiple.Sign() > 0 {
+			s.multiple = multiple
+		}
+	}
+
+	return s, nil
+}
+
+// CheckDeposit holds the recorded deposit if it exceeds the configured multiple of the user's average deposit
+func (s *DepositHoldService) CheckDeposit(ctx context.Context, txHash, walletAddress string, amount *big.Int) error {
+	if s.multiple == nil {
+		return nil
+	}
+
+	wallet, err := s.wallets.FindWalletByAddress(ctx, walletAddress)
+	if err != nil {
+		return err
+	}
+	if wallet == nil {
+		return nil
+	}
+
+	count, averageValue, err := s.repo.GetUserDepositAverage(ctx, wallet.UserID, txHash)
+	if err != nil {
+		return err
+	}
+	if count < s.minHistory {
+		return nil
+	}
+	average, ok := new(big.Int).SetString(averageValue, 10)
+	if !ok {
+		return fmt.Errorf("invalid average deposit %q", averageValue)
+	}
+	if average.Sign() <= 0 {
+		return nil
+	}
+
+	threshold := new(big.Rat).Mul(new(big.Rat).SetInt(average), s.multiple)
+	if new(big.Rat).SetInt(amount).Cmp(threshold) <= 0 {
+		return nil
+	}
+
+	reason := fmt.Sprintf("deposit of %s exceeds %s times the average of %d previous deposits (%s)",
+		amount.String(), s.multiple.RatString(), count, average.String())
+	if err = s.repo.HoldTransaction(ctx, txHash, reason); err != nil {
+		return err
+	}
+
+	// Оповещение комплаенса уходит в Sentry вместе с ошибками
+	s.logger.ErrorContext(ctx, "Unusually large deposit placed on hold",
+		"tx_hash", txHash,
+		"user_id", wallet.UserID,
+		"wallet", walletAddress,
+		"amount", amount.String(),
+		"average", average.String(),
+		"deposits", count)
+
+	if err = s.audit.Record(ctx, entities.AuditEventDepositHeld, "rule:large_deposit", strconv.FormatInt(wallet.UserID, 10), map[string]any{
+		"tx_hash": txHash,
+		"amount":  amount.String(),
+		"average": average.String(),
+		"reason":  reason,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record deposit hold", "error", err, "tx_hash", txHash)
+	}
+
+	message := fmt.Sprintf("Your deposit %s is under review and will be credited once the review is completed", txHash)
+	if err = s.notifier.Notify(ctx, wallet.UserID, "Deposit under review", message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to notify user about deposit hold", "error", err, "tx_hash", txHash)
+	}
+
+	return nil
+}
+
+// ReleaseHold lets a held deposit be credited
+func (s *DepositHoldService) ReleaseHold(ctx context.Context, txHash, actor string) error {
+	released, err := s.repo.ReleaseHold(ctx, txHash, actor)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrDepositHoldNotFound
+	}
+
+	if err = s.audit.Record(ctx, entities.AuditEventDepositReleased, actor, txHash, nil); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record deposit release", "error", err, "tx_hash", txHash)
+	}
+
+	s.logger.InfoContext(ctx, "Deposit hold released", "tx_hash", txHash, "actor", actor)
+	return nil
+}
+
+// GetHolds returns deposits currently on hold
+func (s *DepositHoldService) GetHolds(ctx context.Context) ([]entities.DepositHold, error) {
+	return s.repo.FindHeldDeposits(ctx, depositHoldsListLimit)
+}
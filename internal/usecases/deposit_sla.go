@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// depositSLAOverdueLimit ограничивает число просроченных депозитов в отчете
+const depositSLAOverdueLimit = 200
+
+var (
+	depositSLABreaches = expvar.NewInt("deposit_sla_breaches")
+	depositsOverdue    = expvar.NewInt("deposits_overdue")
+)
+
+type DepositLatencyRepository interface {
+	GetDepositLatencies(ctx context.Context, since time.Time) ([]entities.DepositLatency, error)
+	FindOverdueDeposits(ctx context.Context, detectedBefore time.Time, limit int) ([]entities.OverdueDeposit, error)
+}
+
+var _ DepositLatencyRepository = (*repository.TransactionsRepository)(nil)
+
+// DepositSLAConfig describes latency objectives of deposit confirmation and crediting.
+// SLA maps hold entries in the form chain=seconds, chains without an entry are not checked against p95.
+type DepositSLAConfig struct {
+	ConfirmationSLA []string
+	CreditSLA       []string
+	HardDeadline    time.Duration
+	Window          time.Duration
+	Interval        time.Duration
+}
+
+// DepositSLAService отслеживает задержку от обнаружения депозита до подтверждения и зачисления по сетям.
+// Превышение SLA по p95 и депозиты, не зачисленные к жесткому сроку, попадают в лог ошибок (и в Sentry),
+// в метрики /metrics и в отчет панели администратора.
+type DepositSLAService struct {
+	logger *slog.Logger
+	repo   DepositLatencyRepository
+
+	confirmationSLA map[entities.Chain]time.Duration
+	creditSLA       map[entities.Chain]time.Duration
+	hardDeadline    time.Duration
+	window          time.Duration
+	interval        time.Duration
+
+	// Оповещения отправляются при переходе в нарушение, а не на каждой проверке
+	mu       sync.Mutex
+	breached map[string]bool
+	overdue  map[string]bool
+}
+
+func NewDepositSLAService(logger *slog.Logger, repo DepositLatencyRepository, config DepositSLAConfig) (*DepositSLAService, error) {
+	confirmationSLA, err := parseChainDurations(config.ConfirmationSLA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit confirmation SLA: %w", err)
+	}
+	creditSLA, err := parseChainDurations(config.CreditSLA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit credit SLA: %w", err)
+	}
+	if config.HardDeadline <= 0 || config.Window <= 0 || config.Interval <= 0 {
+		return nil, errors.New("deposit SLA deadline, window and interval must be positive")
+	}
+
+	return &DepositSLAService{
+		logger:          logger,
+		repo:            repo,
+		confirmationSLA: confirmationSLA,
+		creditSLA:       creditSLA,
+		hardDeadline:    config.HardDeadline,
+		window:          config.Window,
+		interval:        config.Interval,
+		breached:        make(map[string]bool),
+		overdue:         make(map[string]bool),
+	}, nil
+}
+
+// parseChainDurations parses entries in the form chain=seconds
+func parseChainDurations(entries []string) (map[entities.Chain]time.Duration, error) {
+	durations := make(map[entities.Chain]time.Duration, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		chain, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be in the form chain=seconds", entry)
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("entry %q must have a positive number of seconds", entry)
+		}
+		durations[entities.Chain(strings.ToLower(strings.TrimSpace(chain)))] = time.Duration(seconds) * time.Second
+	}
+	return durations, nil
+}
+
+// Start checks the SLA on the configured interval until ctx is cancelled
+func (s *DepositSLAService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.GetReport(ctx)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Failed to check deposit SLA", "error", err)
+				continue
+			}
+			s.alert(ctx, report)
+		}
+	}
+}
+
+// GetReport returns latency percentiles per chain over the window and deposits not credited by the hard deadline
+func (s *DepositSLAService) GetReport(ctx context.Context) (*entities.DepositSLAReport, error) {
+	now := time.Now()
+
+	latencies, err := s.repo.GetDepositLatencies(ctx, now.Add(-s.window))
+	if err != nil {
+		return nil, err
+	}
+	for i := range latencies {
+		latency := &latencies[i]
+		if sla, ok := s.confirmationSLA[latency.Chain]; ok {
+			latency.ConfirmationSLA = sla.Seconds()
+			latency.ConfirmationBreached = latency.ConfirmationP95 != nil && *latency.ConfirmationP95 > sla.Seconds()
+		}
+		if sla, ok := s.creditSLA[latency.Chain]; ok {
+			latency.CreditSLA = sla.Seconds()
+			latency.CreditBreached = latency.CreditP95 != nil && *latency.CreditP95 > sla.Seconds()
+		}
+	}
+
+	overdue, err := s.repo.FindOverdueDeposits(ctx, now.Add(-s.hardDeadline), depositSLAOverdueLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if latencies == nil {
+		latencies = []entities.DepositLatency{}
+	}
+	return &entities.DepositSLAReport{
+		GeneratedAt:         now.UTC(),
+		WindowSeconds:       s.window.Seconds(),
+		HardDeadlineSeconds: s.hardDeadline.Seconds(),
+		Chains:              latencies,
+		Overdue:             overdue,
+	}, nil
+}
+
+func (s *DepositSLAService) alert(ctx context.Context, report *entities.DepositSLAReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, latency := range report.Chains {
+		s.alertLatency(ctx, latency, "confirmation", latency.ConfirmationBreached, latency.ConfirmationP95, latency.ConfirmationSLA)
+		s.alertLatency(ctx, latency, "credit", latency.CreditBreached, latency.CreditP95, latency.CreditSLA)
+	}
+
+	depositsOverdue.Set(int64(len(report.Overdue)))
+	current := make(map[string]bool, len(report.Overdue))
+	for _, deposit := range report.Overdue {
+		current[deposit.TxHash] = true
+		if s.overdue[deposit.TxHash] {
+			continue
+		}
+		s.logger.ErrorContext(ctx, "Deposit exceeded hard confirmation deadline",
+			"tx_hash", deposit.TxHash,
+			"chain", deposit.Chain,
+			"network", deposit.Network,
+			"wallet", deposit.WalletAddress,
+			"amount", deposit.Amount,
+			"confirmed", deposit.Confirmed,
+			"age", time.Duration(deposit.AgeSeconds*float64(time.Second)).Round(time.Second).String(),
+			"deadline", time.Duration(report.HardDeadlineSeconds*float64(time.Second)).String())
+	}
+	// Зачисленные депозиты забываются, чтобы карта не росла
+	s.overdue = current
+}
+
+func (s *DepositSLAService) alertLatency(ctx context.Context, latency entities.DepositLatency, stage string, breached bool, p95 *float64, sla float64) {
+	key := string(latency.Chain) + "/" + latency.Network + "/" + stage
+	if !breached {
+		if s.breached[key] {
+			s.logger.InfoContext(ctx, "Deposit SLA recovered", "chain", latency.Chain, "network", latency.Network, "stage", stage)
+		}
+		delete(s.breached, key)
+		return
+	}
+	if s.breached[key] {
+		return
+	}
+
+	s.breached[key] = true
+	depositSLABreaches.Add(1)
+	s.logger.ErrorContext(ctx, "Deposit SLA breached",
+		"chain", latency.Chain,
+		"network", latency.Network,
+		"stage", stage,
+		"p95_seconds", *p95,
+		"sla_seconds", sla,
+		"deposits", latency.Deposits)
+}
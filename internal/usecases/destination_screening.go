@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// Пороги решения те же, что у AML проверок депозитов: от reviewScore перевод уходит на ручной разбор,
+// от blockScore не отправляется
+const (
+	destinationReviewScore = 0.5
+	destinationBlockScore  = 0.7
+
+	destinationScreeningsListLimit = 100
+)
+
+// AddressScreener проверяет риск адреса через черный список, кэш и внешних AML провайдеров
+type AddressScreener interface {
+	CheckAddress(ctx context.Context, address string) (*entities.AddressRiskInfo, error)
+}
+
+type DestinationScreeningsRepository interface {
+	CreateScreening(ctx context.Context, screening *entities.DestinationScreening) error
+	AttachTransfer(ctx context.Context, id string, txHash, safeTxHash, withdrawalID *string) error
+	FindScreenings(ctx context.Context, verdict entities.ScreeningVerdict, limit int) ([]entities.DestinationScreening, error)
+}
+
+var (
+	_ AddressScreener                 = (*AMLService)(nil)
+	_ DestinationScreeningsRepository = (*repository.DestinationScreeningsRepository)(nil)
+)
+
+// DestinationScreeningService screens the destinations of withdrawals and settlements before the transfer is signed.
+// Risky destinations are blocked, medium risk ones are sent and flagged for review. Every screening is stored
+// together with the outgoing transaction.
+type DestinationScreeningService struct {
+	logger *slog.Logger
+	aml    AddressScreener
+	repo   DestinationScreeningsRepository
+	audit  *AuditService
+}
+
+func NewDestinationScreeningService(logger *slog.Logger, aml AddressScreener, repo DestinationScreeningsRepository, audit *AuditService) *DestinationScreeningService {
+	return &DestinationScreeningService{
+		logger: logger,
+		aml:    aml,
+		repo:   repo,
+		audit:  audit,
+	}
+}
+
+// Screen checks the destination and stores the verdict. A blocked destination returns ErrDestinationBlocked,
+// a failed check refuses the transfer as well, since funds cannot be recalled once sent.
+func (s *DestinationScreeningService) Screen(
+	ctx context.Context,
+	kind entities.TreasuryTransferKind,
+	toAddress string,
+	amount *big.Int,
+	initiatedBy string,
+) (*entities.DestinationScreening, error) {
+	risk, err := s.aml.CheckAddress(ctx, toAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to screen destination %s: %w", toAddress, err)
+	}
+
+	screening := &entities.DestinationScreening{
+		ID:          uuid.NewString(),
+		Kind:        kind,
+		ToAddress:   toAddress,
+		Amount:      amount.String(),
+		RiskLevel:   risk.RiskLevel,
+		RiskScore:   risk.RiskScore,
+		Category:    risk.Category,
+		Source:      risk.Source,
+		Verdict:     screeningVerdict(risk),
+		InitiatedBy: initiatedBy,
+	}
+	if err = s.repo.CreateScreening(ctx, screening); err != nil {
+		return nil, err
+	}
+
+	if screening.Verdict != entities.ScreeningApproved {
+		s.logger.WarnContext(ctx, "Transfer destination flagged by AML screening",
+			"screening_id", screening.ID,
+			"kind", kind,
+			"to_address", toAddress,
+			"risk_score", risk.RiskScore,
+			"verdict", screening.Verdict)
+		if err = s.audit.Record(ctx, entities.AuditEventDestinationFlagged, initiatedBy, toAddress, map[string]any{
+			"screening_id": screening.ID,
+			"kind":         kind,
+			"amount":       screening.Amount,
+			"risk_score":   risk.RiskScore,
+			"verdict":      screening.Verdict,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record destination screening", "error", err, "screening_id", screening.ID)
+		}
+	}
+
+	if screening.Verdict == entities.ScreeningBlocked {
+		return screening, fmt.Errorf("%w: %s risk %.2f", ErrDestinationBlocked, toAddress, risk.RiskScore)
+	}
+	return screening, nil
+}
+
+// Attach links the screening to the sent transfer. The transfer is already sent, so a failure is only logged.
+func (s *DestinationScreeningService) Attach(ctx context.Context, screening *entities.DestinationScreening, transfer *entities.TreasuryTransfer) {
+	var txHash, safeTxHash, withdrawalID *string
+	switch {
+	case transfer.Queued != nil:
+		withdrawalID = &transfer.Queued.ID
+	case transfer.Proposal != nil:
+		safeTxHash = &transfer.Proposal.SafeTxHash
+	default:
+		txHash = &transfer.TxHash
+	}
+
+	if err := s.repo.AttachTransfer(ctx, screening.ID, txHash, safeTxHash, withdrawalID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to attach transfer to destination screening", "error", err, "screening_id", screening.ID)
+	}
+}
+
+// GetScreenings returns the latest screenings with the verdict, of all verdicts if it is empty
+func (s *DestinationScreeningService) GetScreenings(ctx context.Context, verdict entities.ScreeningVerdict) ([]entities.DestinationScreening, error) {
+	switch verdict {
+	case "", entities.ScreeningApproved, entities.ScreeningReview, entities.ScreeningBlocked:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidScreeningVerdict, verdict)
+	}
+	return s.repo.FindScreenings(ctx, verdict, destinationScreeningsListLimit)
+}
+
+// screeningVerdict переводит риск адреса в решение: черный список и высокий уровень блокируют перевод независимо от оценки
+func screeningVerdict(risk *entities.AddressRiskInfo) entities.ScreeningVerdict {
+	switch {
+	case risk.RiskLevel == entities.RiskLevelHigh || risk.RiskScore >= destinationBlockScore:
+		return entities.ScreeningBlocked
+	case risk.RiskScore >= destinationReviewScore:
+		return entities.ScreeningReview
+	default:
+		return entities.ScreeningApproved
+	}
+}
@@ -0,0 +1,314 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	dormantSweepBatchSize   = 100
+	dormantRecoveriesLimit  = 500
+	dormantSweepInitiatedBy = "worker:dormant_sweep"
+)
+
+var dormantFundsRecovered = expvar.NewInt("bsc_dormant_wallets_recovered")
+
+// DormantSweepAction — что делать с остатком неактивного кошелька
+type DormantSweepAction string
+
+const (
+	DormantSweepActionSweep  DormantSweepAction = "sweep"  // Собрать остаток на адрес свипов
+	DormantSweepActionRefund DormantSweepAction = "refund" // Вернуть отправителю последнего депозита, иначе собрать
+)
+
+type DormantRecoveriesRepository interface {
+	FindDormantWallets(ctx context.Context, idleBefore time.Time, limit int) ([]entities.Wallet, error)
+	CreateRecovery(ctx context.Context, recovery *entities.DormantRecovery) error
+	FindRecoveries(ctx context.Context, since time.Time, limit int) ([]entities.DormantRecovery, error)
+	GetRecoveryTotals(ctx context.Context, since time.Time) ([]entities.DormantRecoveryTotal, error)
+}
+
+type DormantSweepWallets interface {
+	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
+	CheckTokenHalt() error
+	Asset() entities.Asset
+}
+
+type DormantSweepTransactions interface {
+	FindTransactionsByWallet(ctx context.Context, walletAddress string) ([]entities.Transaction, error)
+}
+
+type DormantSweepRefunds interface {
+	RequestRefund(ctx context.Context, depositTxHash string, amount *big.Int, reason entities.RefundReason, initiatedBy string) (*entities.Refund, error)
+}
+
+var (
+	_ DormantRecoveriesRepository = (*repository.DormantRecoveriesRepository)(nil)
+	_ DormantSweepWallets         = (*WalletService)(nil)
+	_ DormantSweepTransactions    = (*repository.TransactionsRepository)(nil)
+	_ DormantSweepRefunds         = (*RefundService)(nil)
+)
+
+// DormantSweepConfig задает поиск неактивных кошельков. Нулевой IdleAfter отключает задачу.
+type DormantSweepConfig struct {
+	// Кошелек считается неактивным, если по нему не было депозитов и ордеров дольше IdleAfter
+	IdleAfter time.Duration
+	Interval  time.Duration
+	Action    DormantSweepAction
+	// Минимальный остаток в единицах актива, меньшие остатки не стоят газа
+	MinAmount   string
+	Destination string
+}
+
+// DormantSweepService recovers balances left on deposit wallets without a pending order: late payments
+// for expired orders and dust below the regular sweep minimum. Balances are swept to the sweep destination
+// or flagged for refund to the sender of the last deposit, every outcome is kept for the recovery report.
+type DormantSweepService struct {
+	logger       *slog.Logger
+	repo         DormantRecoveriesRepository
+	wallets      DormantSweepWallets
+	transfers    SweepTransfers
+	transactions DormantSweepTransactions
+	refunds      DormantSweepRefunds
+
+	idleAfter   time.Duration
+	interval    time.Duration
+	action      DormantSweepAction
+	minAmount   *big.Int
+	destination string
+}
+
+func NewDormantSweepService(
+	logger *slog.Logger,
+	repo DormantRecoveriesRepository,
+	wallets DormantSweepWallets,
+	transfers SweepTransfers,
+	transactions DormantSweepTransactions,
+	refunds DormantSweepRefunds,
+	config DormantSweepConfig,
+) (*DormantSweepService, error) {
+	s := &DormantSweepService{
+		logger:       logger,
+		repo:         repo,
+		wallets:      wallets,
+		transfers:    transfers,
+		transactions: transactions,
+		refunds:      refunds,
+		idleAfter:    config.IdleAfter,
+		interval:     config.Interval,
+		action:       config.Action,
+	}
+	if config.IdleAfter <= 0 {
+		return s, nil
+	}
+
+	if config.Action != DormantSweepActionSweep && config.Action != DormantSweepActionRefund {
+		return nil, fmt.Errorf("unknown dormant sweep action %q", config.Action)
+	}
+	if !common.IsHexAddress(config.Destination) {
+		return nil, fmt.Errorf("invalid dormant sweep destination %q", config.Destination)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("dormant sweep interval must be positive")
+	}
+	minAmount, err := tokenAmountToUnits(config.MinAmount, wallets.Asset().Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dormant sweep minimum amount: %w", err)
+	}
+	s.minAmount = minAmount
+	s.destination = common.HexToAddress(config.Destination).Hex()
+
+	return s, nil
+}
+
+// Start periodically recovers dormant balances until ctx is cancelled
+func (s *DormantSweepService) Start(ctx context.Context) {
+	if s.idleAfter <= 0 {
+		s.logger.Info("Dormant wallet sweep is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RecoverAll(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Dormant wallet sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// RecoverAll sweeps or flags for refund balances of wallets idle for longer than the configured period
+func (s *DormantSweepService) RecoverAll(ctx context.Context) error {
+	if err := s.wallets.CheckTokenHalt(); err != nil {
+		s.logger.WarnContext(ctx, "Dormant wallet sweep skipped", "reason", err.Error())
+		return nil
+	}
+
+	wallets, err := s.repo.FindDormantWallets(ctx, time.Now().Add(-s.idleAfter), dormantSweepBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	var recovered int
+	for _, wallet := range wallets {
+		// Форвардеры опустошаются через фабрику, ключа у них нет
+		if strings.EqualFold(wallet.Address, s.destination) || IsForwarderPath(wallet.DerivationPath) {
+			continue
+		}
+
+		balance, err := s.wallets.GetERC20TokenBalance(ctx, client, wallet.Address)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get dormant wallet balance", "error", err, "wallet", wallet.Address)
+			continue
+		}
+		if balance.Cmp(s.minAmount) < 0 {
+			continue
+		}
+
+		recovery := s.recover(ctx, client, wallet, balance)
+		if err = s.repo.CreateRecovery(ctx, recovery); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record dormant wallet recovery", "error", err, "wallet", wallet.Address)
+		}
+		if recovery.Action != entities.DormantRecoveryFailed {
+			dormantFundsRecovered.Add(1)
+			recovered++
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Dormant wallet sweep completed", "wallets", len(wallets), "recovered", recovered, "action", s.action)
+	return nil
+}
+
+func (s *DormantSweepService) recover(ctx context.Context, client *ethclient.Client, wallet entities.Wallet, balance *big.Int) *entities.DormantRecovery {
+	recovery := &entities.DormantRecovery{
+		WalletID:      wallet.ID,
+		UserID:        wallet.UserID,
+		WalletAddress: wallet.Address,
+		Amount:        balance.String(),
+	}
+
+	if s.action == DormantSweepActionRefund {
+		refund, err := s.flagRefund(ctx, wallet, balance)
+		switch {
+		case errors.Is(err, ErrRefundExists):
+			// Последний депозит уже возвращался, остаток собирается свипом
+		case err != nil:
+			s.markFailed(ctx, recovery, err)
+			return recovery
+		case refund != nil:
+			recovery.Action = entities.DormantRecoveryRefund
+			recovery.RefundID = &refund.ID
+			recovery.Amount = refund.Amount
+			s.logger.InfoContext(ctx, "Dormant wallet balance flagged for refund",
+				"wallet", wallet.Address, "refund_id", refund.ID, "to", refund.ToAddress, "amount", refund.Amount)
+			return recovery
+		}
+		// Без отправителя с известным адресом остаток собирается свипом
+	}
+
+	transfer, err := s.transfers.Transfer(ctx, client, entities.TreasuryTransferSweep, wallet.ID, s.destination, balance, dormantSweepInitiatedBy)
+	if err != nil {
+		s.markFailed(ctx, recovery, err)
+		return recovery
+	}
+
+	txHash := transfer.TxHash
+	if transfer.Proposal != nil {
+		txHash = transfer.Proposal.SafeTxHash
+	}
+	recovery.Action = entities.DormantRecoverySwept
+	recovery.TxHash = &txHash
+	s.logger.InfoContext(ctx, "Dormant wallet swept",
+		"wallet", wallet.Address, "to", s.destination, "amount", balance.String(), "tx_hash", txHash)
+
+	return recovery
+}
+
+// flagRefund creates a refund of the balance, capped by the last deposit, to its sender.
+// Returns nil if the wallet has no deposit with a known sender.
+func (s *DormantSweepService) flagRefund(ctx context.Context, wallet entities.Wallet, balance *big.Int) (*entities.Refund, error) {
+	transactions, err := s.transactions.FindTransactionsByWallet(ctx, wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Транзакции отсортированы от новых к старым
+	for _, deposit := range transactions {
+		if !common.IsHexAddress(deposit.FromAddress) {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(deposit.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		if amount.Cmp(balance) > 0 {
+			amount = new(big.Int).Set(balance)
+		}
+		return s.refunds.RequestRefund(ctx, deposit.TxHash, amount, entities.RefundReasonDormant, dormantSweepInitiatedBy)
+	}
+
+	return nil, nil
+}
+
+func (s *DormantSweepService) markFailed(ctx context.Context, recovery *entities.DormantRecovery, err error) {
+	message := err.Error()
+	recovery.Action = entities.DormantRecoveryFailed
+	recovery.Error = &message
+	s.logger.ErrorContext(ctx, "Failed to recover dormant wallet balance",
+		"error", err, "wallet", recovery.WalletAddress, "amount", recovery.Amount, "action", s.action)
+}
+
+// GetReport returns recovered funds since the given time: totals by action and individual recoveries
+func (s *DormantSweepService) GetReport(ctx context.Context, since time.Time) (*entities.DormantRecoveryReport, error) {
+	totals, err := s.repo.GetRecoveryTotals(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	recoveries, err := s.repo.FindRecoveries(ctx, since, dormantRecoveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := s.wallets.Asset()
+	for i := range totals {
+		amount, ok := new(big.Int).SetString(totals[i].Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid dormant recovery total %q", totals[i].Amount)
+		}
+		totals[i].Amount = unitsToTokenAmount(amount, asset.Decimals)
+	}
+
+	return &entities.DormantRecoveryReport{
+		Since:      since,
+		Asset:      asset.Code,
+		Totals:     totals,
+		Recoveries: recoveries,
+	}, nil
+}
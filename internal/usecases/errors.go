@@ -4,4 +4,200 @@ import "errors"
 
 var (
 	ErrTradingPairNotFound = errors.New("trading pair not found")
+	ErrTradingPairExists   = errors.New("trading pair already exists")
+	ErrInvalidTradingPair  = errors.New("invalid trading pair")
+
+	// Orders
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderNotPending     = errors.New("order is not pending")
+	ErrInvalidOrderBatch   = errors.New("invalid order batch")
+	ErrInvalidCancelReason = errors.New("invalid order cancel reason")
+	ErrOrderPaymentPending = errors.New("a deposit to the order wallet is being processed")
+
+	// Wallets
+	ErrWalletInUse         = errors.New("wallet is referenced by orders or transactions")
+	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrInvalidBalanceQuery = errors.New("invalid wallet balance query")
+
+	// Wallet imports
+	ErrInvalidWalletImport  = errors.New("invalid wallet import")
+	ErrWalletImportNotFound = errors.New("wallet import not found")
+
+	// Ownership proofs
+	ErrInvalidOwnershipChallenge  = errors.New("invalid ownership challenge")
+	ErrOwnershipProofNotSupported = errors.New("ownership proofs are supported only for EVM wallets")
+
+	// Invoices
+	ErrInvoiceNotFound       = errors.New("invoice not found")
+	ErrInvoiceExpired        = errors.New("invoice expired")
+	ErrInvoiceAssetSelected  = errors.New("invoice asset already selected")
+	ErrAssetNotAccepted      = errors.New("asset is not accepted by the invoice")
+	ErrAssetNotSupported     = errors.New("asset is not supported")
+	ErrInvalidInvoiceRequest = errors.New("invalid invoice request")
+	ErrRateUnavailable       = errors.New("exchange rate unavailable")
+
+	// Assets
+	ErrAssetNotFound           = errors.New("asset not found")
+	ErrInvalidAssetUpdate      = errors.New("invalid asset update")
+	ErrDepositsDisabled        = errors.New("deposits of the asset are disabled")
+	ErrWithdrawalsDisabled     = errors.New("withdrawals of the asset are disabled")
+	ErrWithdrawalLimitExceeded = errors.New("withdrawal limit exceeded")
+	ErrUnknownWithdrawalTier   = errors.New("unknown withdrawal limits tier")
+	ErrOrderAmountOutOfRange   = errors.New("order amount is outside the asset limits")
+
+	// Recurring orders
+	ErrOrderScheduleNotFound   = errors.New("order schedule not found")
+	ErrInvalidOrderSchedule    = errors.New("invalid order schedule")
+	ErrOrderScheduleNotAllowed = errors.New("order schedule cannot be changed in its current status")
+	ErrTooManyOrderSchedules   = errors.New("too many order schedules")
+
+	// Order templates
+	ErrOrderTemplateNotFound = errors.New("order template not found")
+	ErrInvalidOrderTemplate  = errors.New("invalid order template")
+
+	// Receipts
+	ErrReceiptNotAvailable    = errors.New("receipt is available only for completed orders")
+	ErrInvalidReceiptBranding = errors.New("invalid receipt branding")
+
+	// TON deposits
+	ErrTonDepositsDisabled = errors.New("TON deposits are disabled")
+
+	// Refunds
+	ErrRefundNotFound        = errors.New("refund not found")
+	ErrRefundExists          = errors.New("refund for the deposit already exists")
+	ErrRefundNotAllowed      = errors.New("refund is not allowed")
+	ErrRefundDepositNotFound = errors.New("deposit transaction not found")
+
+	// Transactions
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	// Activity feed
+	ErrInvalidActivityCursor = errors.New("invalid activity cursor")
+
+	// Deposit holds
+	ErrDepositHoldNotFound = errors.New("deposit is not on hold")
+
+	// Risk rollups
+	ErrRiskRollupNotFound = errors.New("no AML checks recorded for the wallet or user")
+
+	// BNB dust
+	ErrBNBDustDisabled = errors.New("BNB dust consolidation is disabled")
+
+	// Solana faucet
+	ErrInvalidSolanaAddress = errors.New("invalid Solana address")
+	ErrSolanaFaucetFailed   = errors.New("Solana faucet could not fund the address")
+
+	// Token approvals
+	ErrTokenApprovalNotFound = errors.New("token approval not found or not active")
+	ErrTokenApprovalExists   = errors.New("spender already has an active approval, revoke it first")
+	ErrInvalidTokenApproval  = errors.New("invalid token approval request")
+
+	// Deposit evidence
+	ErrDepositEvidenceNotFound  = errors.New("deposit evidence is not archived")
+	ErrDepositEvidenceCorrupted = errors.New("deposit evidence does not match its checksum")
+
+	// Transfers
+	ErrAddressBlacklisted = errors.New("address is blacklisted by the token contract")
+	ErrTokenHalted        = errors.New("token operations are halted until the admin event is acknowledged")
+	ErrForwarderWallet    = errors.New("forwarder wallet funds can only be flushed to the factory destination")
+	ErrDestinationBlocked = errors.New("destination address is blocked by AML screening")
+	ErrSigningDisabled    = errors.New("signing is disabled")
+
+	// Destination screenings
+	ErrInvalidScreeningVerdict = errors.New("invalid screening verdict")
+
+	// Token admin events
+	ErrTokenEventNotFound = errors.New("token admin event not found or already acknowledged")
+
+	// Fee estimates
+	ErrInvalidFeeEstimateRequest = errors.New("invalid fee estimate request")
+
+	// Treasury
+	ErrSafeProposalNotFound = errors.New("safe proposal not found")
+
+	// Stuck outgoing transactions
+	ErrPendingTxNotFound   = errors.New("pending outgoing transaction not found")
+	ErrTxAlreadyCancelling = errors.New("transaction is already a cancellation")
+
+	// Gas budgets
+	ErrGasBudgetExceeded = errors.New("gas budget exceeded")
+
+	// Personal data
+	ErrErasureBlocked = errors.New("personal data cannot be erased while the user has open orders or refunds")
+
+	// Account closure
+	ErrAccountClosed        = errors.New("account is closed or being closed")
+	ErrClosureAlreadyExists = errors.New("account closure already requested")
+	ErrClosureBlocked       = errors.New("account cannot be closed while orders, refunds or deposits are pending")
+	ErrClosureNotFound      = errors.New("account closure not found")
+
+	// Merchant settlements
+	ErrSettlementNotFound        = errors.New("settlement not found")
+	ErrSettlementAccountNotFound = errors.New("settlement account is not configured")
+	ErrInvalidSettlementAccount  = errors.New("invalid settlement account")
+
+	// Fiat payouts
+	ErrFiatPayoutNotFound       = errors.New("fiat payout not found")
+	ErrInvalidFiatPayoutRequest = errors.New("invalid fiat payout request")
+	ErrOrderNotPayable          = errors.New("order is not completed or is already paid out")
+	ErrFiatPayoutNotInReview    = errors.New("fiat payout is not in manual review")
+
+	// Reports
+	ErrInvalidReportRequest = errors.New("invalid report request")
+
+	// Two-factor authentication
+	ErrTwoFactorRequired           = errors.New("two-factor code required")
+	ErrTwoFactorInvalidCode        = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnrolled        = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorEnrollmentRequired = errors.New("two-factor enrollment required for this operation")
+	ErrTwoFactorOwnerMismatch      = errors.New("second factor user does not own the resource")
+
+	// Sessions
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrSessionInvalidCredential = errors.New("invalid login credential")
+	ErrSessionLoginDisabled     = errors.New("login is not configured")
+
+	// Withdrawal batches
+	ErrWithdrawalBatchNotFound = errors.New("withdrawal batch not found")
+
+	// RPC endpoints
+	ErrRPCEndpointNotFound    = errors.New("rpc endpoint not found")
+	ErrRPCFailoverUnavailable = errors.New("block scanner is not running")
+
+	// Omnibus deposits
+	ErrInvalidDepositSettings = errors.New("invalid deposit settings")
+	ErrOmnibusDisabled        = errors.New("omnibus deposits are disabled")
+
+	// Scanner controls
+	ErrScannerNotFound      = errors.New("scanner not found")
+	ErrScannerAlreadyPaused = errors.New("scanner is already paused")
+	ErrScannerNotPaused     = errors.New("scanner is not paused")
+
+	// Sandbox
+	ErrSandboxMerchant = errors.New("sandbox merchants must use the sandbox environment")
+
+	// Anti-abuse throttling
+	ErrTooManyRequests      = errors.New("too many requests")
+	ErrTooManyPendingOrders = errors.New("too many pending orders")
+	ErrTooManyUnusedWallets = errors.New("too many unused wallets")
+	ErrCaptchaRequired      = errors.New("captcha verification required")
+	ErrCaptchaInvalid       = errors.New("captcha verification failed")
+
+	// Key rotations
+	ErrKeyRotationNotFound     = errors.New("key rotation not found")
+	ErrKeyRotationInProgress   = errors.New("another key rotation is in progress")
+	ErrKeyRotationState        = errors.New("key rotation is not in the required state")
+	ErrKeyVersionNotConfigured = errors.New("no seed configured for key version")
+	ErrKeySampleMismatch       = errors.New("derived addresses do not match the ceremony sample")
+	ErrInvalidSeedStrength     = errors.New("invalid seed strength")
+
+	// Staff annotations
+	ErrInvalidStaffAnnotation = errors.New("invalid staff annotation")
+	ErrStaffTagExists         = errors.New("tag is already attached")
+	ErrStaffTagNotFound       = errors.New("tag is not attached")
+
+	// Runbooks
+	ErrInvalidRunbookRequest = errors.New("invalid runbook request")
+	ErrRunbookForbidden      = errors.New("operator is not allowed to run this action")
+	ErrRunbookNotApplicable  = errors.New("runbook action does not apply to the current state")
 )
@@ -0,0 +1,107 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+const (
+	// feeEstimateValidity — цена газа на BSC меняется медленно, но оценку стоит обновлять перед отправкой
+	feeEstimateValidity = time.Minute
+	bnbDecimals         = 18
+	gweiDecimals        = 9 // Цена газа в gwei — wei с 9 знаками
+)
+
+var feePriorities = []string{PriorityLow, PriorityMedium, PriorityHigh}
+
+type FeeWallets interface {
+	EstimateTransferGas(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (uint64, error)
+	GetGasPriceWithPriority(ctx context.Context, client *ethclient.Client, priority string) (*big.Int, error)
+	Asset() entities.Asset
+}
+
+var _ FeeWallets = (*WalletService)(nil)
+
+// FeeEstimateService оценивает стоимость перевода до его подтверждения пользователем
+type FeeEstimateService struct {
+	logger  *slog.Logger
+	wallets FeeWallets
+	rates   RateProvider
+}
+
+func NewFeeEstimateService(logger *slog.Logger, wallets FeeWallets, rates RateProvider) *FeeEstimateService {
+	return &FeeEstimateService{
+		logger:  logger,
+		wallets: wallets,
+		rates:   rates,
+	}
+}
+
+// EstimateTransfer returns the gas limit and fee per priority for a token transfer of amount (in asset units)
+// together with the platform withdrawal fee of the asset. Fiat values are omitted when no BNB rate for the currency is available.
+func (s *FeeEstimateService) EstimateTransfer(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress, amount, fiat string) (*entities.FeeEstimate, error) {
+	if !common.IsHexAddress(toAddress) {
+		return nil, fmt.Errorf("%w: invalid destination address", ErrInvalidFeeEstimateRequest)
+	}
+	asset := s.wallets.Asset()
+	amountWei, err := tokenAmountToUnits(amount, asset.Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFeeEstimateRequest, err)
+	}
+
+	gasLimit, err := s.wallets.EstimateTransferGas(ctx, client, fromWalletID, toAddress, amountWei)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &entities.FeeEstimate{
+		Asset:         asset.Code,
+		WithdrawalFee: asset.WithdrawalFee,
+		GasLimit:      gasLimit,
+		Quotes:        make([]entities.FeeQuote, 0, len(feePriorities)),
+		ValidUntil:    time.Now().Add(feeEstimateValidity).UTC(),
+	}
+
+	var rate *big.Rat
+	if fiat = strings.ToUpper(strings.TrimSpace(fiat)); fiat != "" {
+		rate, err = s.rates.Rate(ctx, "BNB", fiat)
+		if err != nil {
+			s.logger.DebugContext(ctx, "BNB rate unavailable for fee estimate", "fiat", fiat, "error", err)
+		} else {
+			estimate.FiatCurrency = fiat
+		}
+	}
+
+	unit := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(bnbDecimals), nil))
+	for _, priority := range feePriorities {
+		gasPrice, err := s.wallets.GetGasPriceWithPriority(ctx, client, priority)
+		if err != nil {
+			return nil, err
+		}
+
+		fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+		feeBNB := new(big.Rat).Quo(new(big.Rat).SetInt(fee), unit)
+
+		quote := entities.FeeQuote{
+			Priority: priority,
+			GasPrice: gasPrice.String(),
+			Fee:      fee.String(),
+			FeeBNB:   feeBNB.FloatString(8),
+		}
+		if rate != nil {
+			quote.FeeFiat = new(big.Rat).Mul(feeBNB, rate).FloatString(2)
+		}
+		estimate.Quotes = append(estimate.Quotes, quote)
+	}
+
+	return estimate, nil
+}
@@ -0,0 +1,478 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+const (
+	fiatPayoutBatchSize  = 100
+	fiatPayoutsListLimit = 100
+	// Фиатные суммы округляются вниз до копеек/центов
+	fiatPayoutDecimals = 2
+)
+
+var (
+	fiatPayoutsCompleted = expvar.NewInt("fiat_payouts_completed")
+	fiatPayoutsFailed    = expvar.NewInt("fiat_payouts_failed")
+	fiatPayoutsReview    = expvar.NewInt("fiat_payouts_review")
+)
+
+// FiatPayoutProvider — провайдер фиатных выплат (банк, SEPA, СБП).
+// CreatePayout возвращает ошибку только для временных сбоев, отказ провайдера приходит статусом failed.
+type FiatPayoutProvider interface {
+	Method() entities.FiatPayoutMethod
+	SupportsCurrency(currency string) bool
+	ValidateDestination(destination entities.FiatPayoutDestination) error
+	CreatePayout(ctx context.Context, payout *entities.FiatPayout) (*entities.FiatPayoutUpdate, error)
+	GetPayoutStatus(ctx context.Context, providerRef string) (*entities.FiatPayoutUpdate, error)
+}
+
+type FiatPayoutsRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	LockPayableOrder(ctx context.Context, userID int64, orderID int) (*entities.Order, error)
+	CreatePayout(ctx context.Context, payout *entities.FiatPayout) error
+	AddPosting(ctx context.Context, posting *entities.FiatLedgerPosting) error
+	MarkSubmitted(ctx context.Context, id, providerRef string) error
+	RecordAttemptError(ctx context.Context, id, reason string) (int, error)
+	MarkFinished(ctx context.Context, id string, status entities.FiatPayoutStatus, reason *string) (bool, error)
+	MarkReview(ctx context.Context, id, reason string) (bool, error)
+	FindByID(ctx context.Context, id string) (*entities.FiatPayout, error)
+	FindByUser(ctx context.Context, userID int64, limit int) ([]entities.FiatPayout, error)
+	FindByStatus(ctx context.Context, status entities.FiatPayoutStatus, limit int) ([]entities.FiatPayout, error)
+	FindPostings(ctx context.Context, payoutID string) ([]entities.FiatLedgerPosting, error)
+}
+
+// FiatPayoutAssets находит актив ордера для пересчета в фиат
+type FiatPayoutAssets interface {
+	FindByID(id int) (entities.Asset, error)
+	Default() entities.Asset
+}
+
+var (
+	_ FiatPayoutsRepository = (*repository.FiatPayoutsRepository)(nil)
+	_ FiatPayoutAssets      = (*AssetRegistry)(nil)
+)
+
+// FiatPayoutConfig задает опрос провайдеров и повтор неудачных отправок
+type FiatPayoutConfig struct {
+	PollInterval time.Duration
+	// После MaxAttempts временных ошибок отправки выплата уходит на ручную проверку
+	MaxAttempts int
+}
+
+// FiatPayoutRequest — запрос продавца на выплату фиатного эквивалента завершенного ордера
+type FiatPayoutRequest struct {
+	OrderID     int
+	Method      entities.FiatPayoutMethod
+	Currency    string
+	Destination entities.FiatPayoutDestination
+}
+
+// FiatPayoutService pays sellers the fiat equivalent of their completed orders through off-ramp providers.
+// Every payout is posted to the fiat ledger: seller -> in transit on request, in transit -> provider on completion,
+// and back to the seller when the provider rejects the payout. A payout whose submission attempts are exhausted
+// may still have been accepted by the provider, so it stays in transit in manual review until an operator resolves it.
+type FiatPayoutService struct {
+	logger    *slog.Logger
+	repo      FiatPayoutsRepository
+	assets    FiatPayoutAssets
+	rates     RateProvider
+	notifier  Notifier
+	providers map[entities.FiatPayoutMethod]FiatPayoutProvider
+
+	pollInterval time.Duration
+	maxAttempts  int
+}
+
+func NewFiatPayoutService(
+	logger *slog.Logger,
+	repo FiatPayoutsRepository,
+	assets FiatPayoutAssets,
+	rates RateProvider,
+	notifier Notifier,
+	providers []FiatPayoutProvider,
+	config FiatPayoutConfig,
+) (*FiatPayoutService, error) {
+	if config.PollInterval <= 0 {
+		return nil, errors.New("fiat payout poll interval must be positive")
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, errors.New("fiat payout max attempts must be positive")
+	}
+
+	byMethod := make(map[entities.FiatPayoutMethod]FiatPayoutProvider, len(providers))
+	for _, provider := range providers {
+		if _, ok := byMethod[provider.Method()]; ok {
+			return nil, fmt.Errorf("duplicate fiat payout provider for %s", provider.Method())
+		}
+		byMethod[provider.Method()] = provider
+	}
+
+	return &FiatPayoutService{
+		logger:       logger,
+		repo:         repo,
+		assets:       assets,
+		rates:        rates,
+		notifier:     notifier,
+		providers:    byMethod,
+		pollInterval: config.PollInterval,
+		maxAttempts:  config.MaxAttempts,
+	}, nil
+}
+
+// Methods returns the payout methods with a configured provider
+func (s *FiatPayoutService) Methods() []entities.FiatPayoutMethod {
+	methods := make([]entities.FiatPayoutMethod, 0, len(s.providers))
+	for method := range s.providers {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i] < methods[j] })
+	return methods
+}
+
+// RequestPayout creates a payout of the order's fiat equivalent and submits it to the provider.
+// A submission error leaves the payout pending, the worker retries it.
+func (s *FiatPayoutService) RequestPayout(ctx context.Context, userID int64, req FiatPayoutRequest) (*entities.FiatPayout, error) {
+	provider, ok := s.providers[req.Method]
+	if !ok {
+		return nil, fmt.Errorf("%w: payout method %q is not available", ErrInvalidFiatPayoutRequest, req.Method)
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !provider.SupportsCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s payouts in %s are not supported", ErrInvalidFiatPayoutRequest, req.Method, currency)
+	}
+	if err := provider.ValidateDestination(req.Destination); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFiatPayoutRequest, err)
+	}
+
+	payout := &entities.FiatPayout{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		OrderID:     req.OrderID,
+		Method:      req.Method,
+		Status:      entities.FiatPayoutPending,
+		Currency:    currency,
+		Destination: req.Destination,
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		order, err := s.repo.LockPayableOrder(txCtx, userID, req.OrderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return ErrOrderNotPayable
+		}
+
+		if err = s.quote(ctx, payout, order); err != nil {
+			return err
+		}
+		if err = s.repo.CreatePayout(txCtx, payout); err != nil {
+			return err
+		}
+		return s.post(txCtx, payout, sellerAccount(userID), inTransitAccount(payout.Method), "payout requested")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Fiat payout requested", "payout_id", payout.ID, "user_id", userID, "order_id", payout.OrderID,
+		"method", payout.Method, "amount", payout.FiatAmount, "currency", payout.Currency)
+
+	s.submit(ctx, provider, payout)
+	return payout, nil
+}
+
+// quote converts the order amount to the payout currency, rounding down to fiatPayoutDecimals
+func (s *FiatPayoutService) quote(ctx context.Context, payout *entities.FiatPayout, order *entities.Order) error {
+	asset := s.assets.Default()
+	if order.AssetID != nil {
+		var err error
+		if asset, err = s.assets.FindByID(*order.AssetID); err != nil {
+			return err
+		}
+	}
+
+	rate, err := s.rates.Rate(ctx, asset.Code, payout.Currency)
+	if err != nil {
+		return err
+	}
+	fiatAmount := order.Amount.Mul(decimal.FromRat(rate)).Round(fiatPayoutDecimals, decimal.RoundDown)
+	if fiatAmount.Sign() <= 0 {
+		return fmt.Errorf("%w: order amount is too small for a payout", ErrInvalidFiatPayoutRequest)
+	}
+
+	payout.Asset = asset.Code
+	payout.CryptoAmount = order.Amount.String()
+	payout.Rate = rate.FloatString(8)
+	payout.FiatAmount = fiatAmount.StringFixed(fiatPayoutDecimals)
+	return nil
+}
+
+// Start polls providers for pending and processing payouts until ctx is cancelled
+func (s *FiatPayoutService) Start(ctx context.Context) {
+	if len(s.providers) == 0 {
+		s.logger.Info("Fiat payouts are disabled, no providers configured")
+		return
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ProcessAll(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Fiat payout processing failed", "error", err)
+			}
+		}
+	}
+}
+
+// ProcessAll retries submission of pending payouts and polls the status of processing ones
+func (s *FiatPayoutService) ProcessAll(ctx context.Context) error {
+	pending, err := s.repo.FindByStatus(ctx, entities.FiatPayoutPending, fiatPayoutBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		provider, ok := s.providers[pending[i].Method]
+		if !ok {
+			// Выплата, которую уже пытались отправить, могла дойти до провайдера
+			if pending[i].Attempts > 0 {
+				s.review(ctx, &pending[i], "payout method is no longer available")
+			} else {
+				s.fail(ctx, &pending[i], "payout method is no longer available")
+			}
+			continue
+		}
+		s.submit(ctx, provider, &pending[i])
+	}
+
+	processing, err := s.repo.FindByStatus(ctx, entities.FiatPayoutProcessing, fiatPayoutBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range processing {
+		s.poll(ctx, &processing[i])
+	}
+
+	return nil
+}
+
+func (s *FiatPayoutService) submit(ctx context.Context, provider FiatPayoutProvider, payout *entities.FiatPayout) {
+	update, err := provider.CreatePayout(ctx, payout)
+	if err != nil {
+		attempts, recordErr := s.repo.RecordAttemptError(ctx, payout.ID, err.Error())
+		if recordErr != nil {
+			s.logger.ErrorContext(ctx, "Failed to record fiat payout attempt", "error", recordErr, "payout_id", payout.ID)
+			return
+		}
+		s.logger.WarnContext(ctx, "Fiat payout submission failed", "error", err, "payout_id", payout.ID, "attempts", attempts)
+		if attempts >= s.maxAttempts {
+			s.review(ctx, payout, fmt.Sprintf("submission failed after %d attempts: %v", attempts, err))
+		}
+		return
+	}
+
+	if update.Status == entities.FiatPayoutFailed {
+		s.fail(ctx, payout, update.FailureReason)
+		return
+	}
+	if err = s.repo.MarkSubmitted(ctx, payout.ID, update.ProviderRef); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record fiat payout submission", "error", err,
+			"payout_id", payout.ID, "provider_ref", update.ProviderRef)
+		return
+	}
+	payout.Status = entities.FiatPayoutProcessing
+	payout.ProviderRef = &update.ProviderRef
+
+	if update.Status == entities.FiatPayoutCompleted {
+		s.complete(ctx, payout)
+	}
+}
+
+func (s *FiatPayoutService) poll(ctx context.Context, payout *entities.FiatPayout) {
+	provider, ok := s.providers[payout.Method]
+	if !ok || payout.ProviderRef == nil {
+		s.logger.WarnContext(ctx, "Cannot poll fiat payout status", "payout_id", payout.ID, "method", payout.Method)
+		return
+	}
+
+	update, err := provider.GetPayoutStatus(ctx, *payout.ProviderRef)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to get fiat payout status", "error", err, "payout_id", payout.ID)
+		return
+	}
+
+	switch update.Status {
+	case entities.FiatPayoutCompleted:
+		s.complete(ctx, payout)
+	case entities.FiatPayoutFailed:
+		s.fail(ctx, payout, update.FailureReason)
+	}
+}
+
+func (s *FiatPayoutService) complete(ctx context.Context, payout *entities.FiatPayout) {
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		finished, err := s.repo.MarkFinished(txCtx, payout.ID, entities.FiatPayoutCompleted, nil)
+		if err != nil || !finished {
+			return err
+		}
+		return s.post(txCtx, payout, inTransitAccount(payout.Method), providerAccount(payout.Method), "payout completed")
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to complete fiat payout", "error", err, "payout_id", payout.ID)
+		return
+	}
+	fiatPayoutsCompleted.Add(1)
+
+	s.logger.InfoContext(ctx, "Fiat payout completed", "payout_id", payout.ID, "user_id", payout.UserID,
+		"amount", payout.FiatAmount, "currency", payout.Currency)
+	s.notify(ctx, payout, "Payout completed",
+		fmt.Sprintf("Payout of %s %s for order %d has been sent", payout.FiatAmount, payout.Currency, payout.OrderID))
+}
+
+// fail marks the payout failed and reverses the posting, so the order can be paid out again
+func (s *FiatPayoutService) fail(ctx context.Context, payout *entities.FiatPayout, reason string) {
+	if reason == "" {
+		reason = "rejected by provider"
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		finished, err := s.repo.MarkFinished(txCtx, payout.ID, entities.FiatPayoutFailed, &reason)
+		if err != nil || !finished {
+			return err
+		}
+		return s.post(txCtx, payout, inTransitAccount(payout.Method), sellerAccount(payout.UserID), "payout failed: "+reason)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to mark fiat payout failed", "error", err, "payout_id", payout.ID)
+		return
+	}
+	fiatPayoutsFailed.Add(1)
+
+	s.logger.WarnContext(ctx, "Fiat payout failed", "payout_id", payout.ID, "user_id", payout.UserID, "reason", reason)
+	s.notify(ctx, payout, "Payout failed",
+		fmt.Sprintf("Payout of %s %s for order %d failed: %s. You can request a new payout", payout.FiatAmount, payout.Currency, payout.OrderID, reason))
+}
+
+// review moves the payout to manual review. The posting stays in transit and the order stays paid out:
+// a submission that timed out may have been accepted by the provider.
+func (s *FiatPayoutService) review(ctx context.Context, payout *entities.FiatPayout, reason string) {
+	moved, err := s.repo.MarkReview(ctx, payout.ID, reason)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to move fiat payout to review", "error", err, "payout_id", payout.ID)
+		return
+	}
+	if !moved {
+		return
+	}
+	fiatPayoutsReview.Add(1)
+
+	s.logger.WarnContext(ctx, "Fiat payout requires manual review", "payout_id", payout.ID, "user_id", payout.UserID, "reason", reason)
+	s.notify(ctx, payout, "Payout under review",
+		fmt.Sprintf("Payout of %s %s for order %d is delayed and is being checked with the provider", payout.FiatAmount, payout.Currency, payout.OrderID))
+}
+
+// ResolveReview finishes a payout in manual review once the operator checked it with the provider:
+// completed posts it to the provider, otherwise the posting is reversed and the order can be paid out again
+func (s *FiatPayoutService) ResolveReview(ctx context.Context, id string, completed bool, reason, actor string) (*entities.FiatPayout, error) {
+	payout, err := s.GetPayout(ctx, 0, id)
+	if err != nil {
+		return nil, err
+	}
+	if payout.Status != entities.FiatPayoutReview {
+		return nil, ErrFiatPayoutNotInReview
+	}
+
+	s.logger.InfoContext(ctx, "Resolving fiat payout review", "payout_id", id, "completed", completed, "actor", actor)
+	if completed {
+		s.complete(ctx, payout)
+	} else {
+		if reason == "" {
+			reason = "not received by provider"
+		}
+		s.fail(ctx, payout, fmt.Sprintf("%s (resolved by %s)", reason, actor))
+	}
+
+	return s.GetPayout(ctx, 0, id)
+}
+
+func (s *FiatPayoutService) post(ctx context.Context, payout *entities.FiatPayout, debit, credit, memo string) error {
+	return s.repo.AddPosting(ctx, &entities.FiatLedgerPosting{
+		PayoutID:      payout.ID,
+		DebitAccount:  debit,
+		CreditAccount: credit,
+		Amount:        payout.FiatAmount,
+		Currency:      payout.Currency,
+		Memo:          memo,
+	})
+}
+
+func (s *FiatPayoutService) notify(ctx context.Context, payout *entities.FiatPayout, subject, message string) {
+	if err := s.notifier.Notify(ctx, payout.UserID, subject, message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to notify user about fiat payout", "error", err, "payout_id", payout.ID)
+	}
+}
+
+// GetUserPayouts returns the latest payouts of the user
+func (s *FiatPayoutService) GetUserPayouts(ctx context.Context, userID int64) ([]entities.FiatPayout, error) {
+	return s.repo.FindByUser(ctx, userID, fiatPayoutsListLimit)
+}
+
+// GetPayout returns the payout. A positive userID restricts access to the user's own payouts.
+func (s *FiatPayoutService) GetPayout(ctx context.Context, userID int64, id string) (*entities.FiatPayout, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrFiatPayoutNotFound
+	}
+
+	payout, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payout == nil || (userID > 0 && payout.UserID != userID) {
+		return nil, ErrFiatPayoutNotFound
+	}
+	return payout, nil
+}
+
+// GetPayouts returns payouts with the given status, all statuses if empty
+func (s *FiatPayoutService) GetPayouts(ctx context.Context, status entities.FiatPayoutStatus) ([]entities.FiatPayout, error) {
+	return s.repo.FindByStatus(ctx, status, fiatPayoutsListLimit)
+}
+
+// GetPostings returns ledger postings of the payout
+func (s *FiatPayoutService) GetPostings(ctx context.Context, id string) ([]entities.FiatLedgerPosting, error) {
+	if _, err := s.GetPayout(ctx, 0, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindPostings(ctx, id)
+}
+
+func sellerAccount(userID int64) string {
+	return fmt.Sprintf("seller:%d", userID)
+}
+
+func inTransitAccount(method entities.FiatPayoutMethod) string {
+	return "payouts_in_transit:" + string(method)
+}
+
+func providerAccount(method entities.FiatPayoutMethod) string {
+	return "provider:" + string(method)
+}
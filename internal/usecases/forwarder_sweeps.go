@@ -0,0 +1,175 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// ForwarderWallets деплоит и опустошает CREATE2 форвардеры
+type ForwarderWallets interface {
+	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
+	FlushForwarders(ctx context.Context, client *ethclient.Client, salts []common.Hash, senderPath string) (string, error)
+	CheckTokenHalt() error
+	Asset() entities.Asset
+}
+
+var _ ForwarderWallets = (*WalletService)(nil)
+
+// ForwarderSweepConfig describes how forwarder deposit addresses are flushed
+type ForwarderSweepConfig struct {
+	// Ключ, оплачивающий газ деплоя и flush
+	SenderPath string
+	MinAmount  string // В единицах актива
+	Interval   time.Duration
+	BatchSize  int
+}
+
+// ForwarderSweepService periodically deploys forwarders that received deposits and flushes them
+// to the factory destination, one factory transaction per batch. Forwarders holding funds owed back
+// to the sender (unfinished refunds, held or AML-rejected deposits) are not flushed.
+type ForwarderSweepService struct {
+	logger  *slog.Logger
+	repo    SweepWalletsRepository
+	wallets ForwarderWallets
+
+	senderPath string
+	minAmount  *big.Int
+	interval   time.Duration
+	batchSize  int
+}
+
+func NewForwarderSweepService(
+	logger *slog.Logger,
+	repo SweepWalletsRepository,
+	wallets ForwarderWallets,
+	config ForwarderSweepConfig,
+) (*ForwarderSweepService, error) {
+	if _, _, err := ParseDerivationPath(config.SenderPath); err != nil {
+		return nil, fmt.Errorf("invalid forwarder sender path: %w", err)
+	}
+	minAmount, err := tokenAmountToUnits(config.MinAmount, wallets.Asset().Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid forwarder minimum amount: %w", err)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("forwarder sweep interval must be positive")
+	}
+	if config.BatchSize <= 0 {
+		return nil, errors.New("forwarder batch size must be positive")
+	}
+
+	return &ForwarderSweepService{
+		logger:     logger,
+		repo:       repo,
+		wallets:    wallets,
+		senderPath: config.SenderPath,
+		minAmount:  minAmount,
+		interval:   config.Interval,
+		batchSize:  config.BatchSize,
+	}, nil
+}
+
+// Start flushes forwarders on the configured interval until ctx is cancelled
+func (s *ForwarderSweepService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.FlushAll(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Forwarder sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// FlushAll flushes every forwarder holding at least the minimum amount
+func (s *ForwarderSweepService) FlushAll(ctx context.Context) error {
+	ctx = withLedgerTag(ctx, entities.LedgerKindSweep, nil, nil)
+
+	if err := s.wallets.CheckTokenHalt(); err != nil {
+		s.logger.WarnContext(ctx, "Forwarder sweep skipped", "reason", err.Error())
+		return nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	wallets, err := s.repo.GetAllTrackedWallets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tracked wallets: %w", err)
+	}
+	// Форвардер нельзя опустошить никуда, кроме адреса фабрики: средства под возвратом или удержанием остаются на нем
+	frozen, err := s.repo.FindAddressesWithFrozenFunds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get wallets with frozen funds: %w", err)
+	}
+
+	var (
+		salts   []common.Hash
+		flushed int
+	)
+	flush := func() {
+		if len(salts) == 0 {
+			return
+		}
+		txHash, err := s.wallets.FlushForwarders(ctx, client, salts, s.senderPath)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to flush forwarders", "error", err, "forwarders", len(salts))
+		} else {
+			flushed += len(salts)
+			s.logger.InfoContext(ctx, "Forwarders flushed", "forwarders", len(salts), "tx_hash", txHash)
+		}
+		salts = nil
+	}
+
+	for _, wallet := range wallets {
+		if !IsForwarderPath(wallet.DerivationPath) {
+			continue
+		}
+		if frozen[strings.ToLower(wallet.Address)] {
+			s.logger.DebugContext(ctx, "Forwarder with frozen funds skipped by sweep", "wallet", wallet.Address)
+			continue
+		}
+
+		salt, err := ForwarderSalt(wallet.DerivationPath)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Invalid forwarder wallet", "error", err, "wallet", wallet.Address)
+			continue
+		}
+
+		balance, err := s.wallets.GetERC20TokenBalance(ctx, client, wallet.Address)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get forwarder balance", "error", err, "wallet", wallet.Address)
+			continue
+		}
+		if balance.Cmp(s.minAmount) < 0 {
+			continue
+		}
+
+		salts = append(salts, salt)
+		if len(salts) == s.batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	s.logger.InfoContext(ctx, "Forwarder sweep completed", "flushed", flushed)
+	return nil
+}
@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ForwarderPathPrefix помечает кошельки-форвардеры: вместо пути деривации хранится CREATE2 salt, ключа у адреса нет
+const ForwarderPathPrefix = "create2:"
+
+// forwarderFactoryABI — ABI контракта contracts/ForwarderFactory.sol
+const forwarderFactoryABI = `[
+	{"name":"flush","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"salts","type":"bytes32[]"},{"name":"token","type":"address"}],"outputs":[]}
+]`
+
+var parsedForwarderFactoryABI = mustParseABI(forwarderFactoryABI)
+
+// ForwarderConfig describes the CREATE2 forwarder factory. An empty FactoryAddress disables forwarders.
+type ForwarderConfig struct {
+	FactoryAddress string
+	// keccak256 init code контракта Forwarder, возвращается ForwarderFactory.initCodeHash()
+	InitCodeHash string
+}
+
+// IsForwarderPath reports whether the wallet is a CREATE2 forwarder rather than an HD wallet
+func IsForwarderPath(derivationPath string) bool {
+	return strings.HasPrefix(derivationPath, ForwarderPathPrefix)
+}
+
+// ForwarderSalt extracts the CREATE2 salt from the forwarder wallet path
+func ForwarderSalt(derivationPath string) (common.Hash, error) {
+	salt := strings.TrimPrefix(derivationPath, ForwarderPathPrefix)
+	if salt == derivationPath || len(common.FromHex(salt)) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid forwarder path %q", derivationPath)
+	}
+	return common.HexToHash(salt), nil
+}
+
+// forwarderSalt derives a unique salt from the user ID and the wallet index of the user
+func forwarderSalt(userID int64, index uint32) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(big.NewInt(userID).Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(uint64(index)).Bytes(), 32),
+	)
+}
+
+// ForwardersEnabled reports whether deposit addresses are issued as CREATE2 forwarders
+func (bsc *WalletService) ForwardersEnabled() bool {
+	return bsc.forwarderFactory != (common.Address{})
+}
+
+// ComputeForwarderAddress returns the address the factory deploys the forwarder with this salt to
+func (bsc *WalletService) ComputeForwarderAddress(salt common.Hash) common.Address {
+	return crypto.CreateAddress2(bsc.forwarderFactory, salt, bsc.forwarderInitCodeHash.Bytes())
+}
+
+// GenerateForwarderForUser issues a new CREATE2 forwarder deposit address. The contract is not deployed
+// until a deposit arrives: the address is computed off-chain and the forwarder worker deploys and flushes it.
+func (bsc *WalletService) GenerateForwarderForUser(ctx context.Context, userID int64) (int, string, error) {
+	if !bsc.ForwardersEnabled() {
+		return 0, "", fmt.Errorf("forwarder factory is not configured")
+	}
+
+	return bsc.generateWallet(ctx, userID, func(index uint32) (common.Address, string, error) {
+		salt := forwarderSalt(userID, index)
+		return bsc.ComputeForwarderAddress(salt), ForwarderPathPrefix + salt.Hex(), nil
+	})
+}
+
+// GenerateDepositWallet issues a deposit address for an order: the omnibus wallet for merchants in the omnibus mode,
+// an idle wallet of a repeat customer when reuse is enabled, a forwarder when the factory is configured,
+// otherwise an HD wallet from the pool or a new one
+func (bsc *WalletService) GenerateDepositWallet(ctx context.Context, userID int64) (int, string, error) {
+	if bsc.omnibus != nil {
+		if id, address, ok := bsc.omnibus.DepositWallet(ctx, userID); ok {
+			return id, address, nil
+		}
+	}
+	if id, address, ok := bsc.reusableWallet(ctx, userID); ok {
+		return id, address, nil
+	}
+	if bsc.ForwardersEnabled() {
+		return bsc.GenerateForwarderForUser(ctx, userID)
+	}
+	if bsc.pool != nil {
+		if id, address, ok := bsc.pool.Claim(ctx, userID); ok {
+			return id, address, nil
+		}
+	}
+	return bsc.GenerateWalletForUser(ctx, userID)
+}
+
+// FlushForwarders deploys missing forwarders and moves their USDT to the factory destination
+// with one factory transaction sent from senderPath
+func (bsc *WalletService) FlushForwarders(ctx context.Context, client *ethclient.Client, salts []common.Hash, senderPath string) (string, error) {
+	if !bsc.ForwardersEnabled() {
+		return "", fmt.Errorf("forwarder factory is not configured")
+	}
+	if err := bsc.CheckTokenHalt(); err != nil {
+		return "", err
+	}
+
+	userID, index, err := ParseDerivationPath(senderPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarder sender path: %w", err)
+	}
+	sender, err := bsc.signer.DeriveAddress(bsc.keyring.ServiceVersion(), userID, index)
+	if err != nil {
+		return "", err
+	}
+
+	saltBytes := make([][32]byte, len(salts))
+	for i, salt := range salts {
+		saltBytes[i] = salt
+	}
+	data, err := parsedForwarderFactoryABI.Pack("flush", saltBytes, common.HexToAddress(bsc.smartContractAddress))
+	if err != nil {
+		return "", fmt.Errorf("error packing data for flush: %w", err)
+	}
+
+	if err = bsc.simulateTransaction(ctx, client, sender, bsc.forwarderFactory, big.NewInt(0), data); err != nil {
+		return "", err
+	}
+
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: sender, To: &bsc.forwarderFactory, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate flush gas: %w", err)
+	}
+
+	return bsc.sendTransaction(ctx, client, bsc.keyring.ServiceVersion(), senderPath, sender, bsc.forwarderFactory, big.NewInt(0),
+		gasLimit*12/10, nil, data, PriorityLow, SignOperationForwarderFlush)
+}
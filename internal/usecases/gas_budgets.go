@@ -0,0 +1,222 @@
+package usecases
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// Число транзакций, отложенных из-за превышения бюджета газа
+var gasBudgetDeferrals = expvar.NewInt("bsc_gas_budget_deferrals")
+
+// GasBudgetConfig ограничивает расход газа за сутки (UTC). Пустое значение отключает соответствующий лимит.
+type GasBudgetConfig struct {
+	// Суточный лимит на все операции в BNB, например "0.5"
+	DailyBNB string
+	// Суточный лимит на все операции в валюте курсов (RateCurrency)
+	DailyFiat string
+	// Суточные лимиты по видам операций в BNB: "sweep=0.2", "withdrawal=1"
+	PerOperation []string
+}
+
+type gasBudgets struct {
+	daily     *big.Int
+	dailyFiat *big.Rat
+	perKind   map[entities.LedgerEntryKind]*big.Int
+}
+
+func (b gasBudgets) enabled() bool {
+	return b.daily != nil || b.dailyFiat != nil || len(b.perKind) > 0
+}
+
+func parseGasBudgets(config GasBudgetConfig) (gasBudgets, error) {
+	var (
+		budgets gasBudgets
+		err     error
+	)
+
+	if budgets.daily, err = parseBNBAmount(config.DailyBNB); err != nil {
+		return budgets, fmt.Errorf("daily BNB budget: %w", err)
+	}
+
+	if value := strings.TrimSpace(config.DailyFiat); value != "" {
+		fiat, ok := new(big.Rat).SetString(value)
+		if !ok || fiat.Sign() < 0 {
+			return budgets, fmt.Errorf("daily fiat budget %q must be a non-negative number", value)
+		}
+		if fiat.Sign() > 0 {
+			budgets.dailyFiat = fiat
+		}
+	}
+
+	budgets.perKind = make(map[entities.LedgerEntryKind]*big.Int, len(config.PerOperation))
+	for _, entry := range config.PerOperation {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return budgets, fmt.Errorf("entry %q must be in the form operation=bnb", entry)
+		}
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		switch entities.LedgerEntryKind(kind) {
+		case entities.LedgerKindWithdrawal, entities.LedgerKindSweep, entities.LedgerKindRefund, entities.LedgerKindOperational:
+		default:
+			return budgets, fmt.Errorf("entry %q has unknown operation %q", entry, kind)
+		}
+		limit, err := parseBNBAmount(value)
+		if err != nil {
+			return budgets, fmt.Errorf("entry %q: %w", entry, err)
+		}
+		if limit != nil {
+			budgets.perKind[entities.LedgerEntryKind(kind)] = limit
+		}
+	}
+
+	return budgets, nil
+}
+
+// parseBNBAmount converts a decimal BNB amount to wei; zero or empty means no limit
+func parseBNBAmount(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("amount %q must be a non-negative number of BNB", value)
+	}
+	if amount.Sign() == 0 {
+		return nil, nil
+	}
+	amount.Mul(amount, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(bnbDecimals), nil)))
+	return new(big.Int).Quo(amount.Num(), amount.Denom()), nil
+}
+
+// ReserveGasBudget is called before signing: it returns ErrGasBudgetExceeded when spending gasCost wei
+// on an operation of the given kind would exceed today's budget, otherwise it reserves the cost and returns
+// the reservation ID. The check and the reservation run under one lock, so concurrent transactions cannot
+// both fit into the same remainder. The caller defers an operation over budget until the next run,
+// so sweeps and speed-ups stop during fee spikes instead of draining the gas wallets.
+// An empty ID means nothing was reserved.
+func (s *LedgerService) ReserveGasBudget(ctx context.Context, kind entities.LedgerEntryKind, gasCost *big.Int) (string, error) {
+	if !s.budgets.enabled() || gasCost == nil || gasCost.Sign() <= 0 {
+		return "", nil
+	}
+
+	// Курс берем до блокировки, чтобы не держать ее на время запроса к источнику курсов
+	var rate *big.Rat
+	if s.budgets.dailyFiat != nil {
+		var err error
+		if rate, err = s.rates.Rate(ctx, "BNB", s.rateCurrency); err != nil {
+			return "", fmt.Errorf("failed to get BNB rate for gas budget: %w", err)
+		}
+	}
+
+	id := uuid.NewString()
+	err := s.repo.WithinGasBudgetLock(ctx, func(ctx context.Context) error {
+		if err := s.checkGasBudget(ctx, kind, gasCost, rate); err != nil {
+			return err
+		}
+		return s.repo.CreateReservation(ctx, id, kind, gasCost.String())
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// ReleaseGasBudget drops the reservation once the transaction is recorded in the journal or was never sent.
+// A failure only leaves the reservation counted until the end of the day, so it is logged.
+func (s *LedgerService) ReleaseGasBudget(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+	if err := s.repo.DeleteReservation(ctx, id); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to release gas reservation", "reservation_id", id, "error", err)
+	}
+}
+
+// checkGasBudget compares today's spending and reservations plus gasCost with the budgets
+func (s *LedgerService) checkGasBudget(ctx context.Context, kind entities.LedgerEntryKind, gasCost *big.Int, rate *big.Rat) error {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	spentByKind, err := s.repo.GasSpentSince(ctx, day)
+	if err != nil {
+		return fmt.Errorf("failed to get gas spent today: %w", err)
+	}
+
+	total := new(big.Int)
+	spent := make(map[entities.LedgerEntryKind]*big.Int, len(spentByKind))
+	for k, value := range spentByKind {
+		amount, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return fmt.Errorf("invalid gas spent %q for %s", value, k)
+		}
+		spent[k] = amount
+		total.Add(total, amount)
+	}
+
+	if limit, ok := s.budgets.perKind[kind]; ok {
+		kindSpent := spent[kind]
+		if kindSpent == nil {
+			kindSpent = new(big.Int)
+		}
+		if projected := new(big.Int).Add(kindSpent, gasCost); projected.Cmp(limit) > 0 {
+			return s.deferOverBudget(ctx, day, string(kind), kind, gasCost, kindSpent, limit.String(), "wei")
+		}
+	}
+
+	projected := new(big.Int).Add(total, gasCost)
+	if s.budgets.daily != nil && projected.Cmp(s.budgets.daily) > 0 {
+		return s.deferOverBudget(ctx, day, "daily", kind, gasCost, total, s.budgets.daily.String(), "wei")
+	}
+
+	if s.budgets.dailyFiat != nil {
+		fiat := new(big.Rat).SetFrac(projected, new(big.Int).Exp(big.NewInt(10), big.NewInt(bnbDecimals), nil))
+		fiat.Mul(fiat, rate)
+		if fiat.Cmp(s.budgets.dailyFiat) > 0 {
+			return s.deferOverBudget(ctx, day, "daily_fiat", kind, gasCost, total, s.budgets.dailyFiat.FloatString(2), s.rateCurrency)
+		}
+	}
+
+	return nil
+}
+
+// deferOverBudget counts the deferral and alerts once per budget per day
+func (s *LedgerService) deferOverBudget(ctx context.Context, day time.Time, budget string, kind entities.LedgerEntryKind,
+	gasCost, spent *big.Int, limit, unit string) error {
+
+	gasBudgetDeferrals.Add(1)
+
+	dayKey := day.Format(time.DateOnly)
+	s.alertsMu.Lock()
+	alerted := s.alertedAt[budget] == dayKey
+	s.alertedAt[budget] = dayKey
+	s.alertsMu.Unlock()
+
+	if !alerted {
+		s.logger.ErrorContext(ctx, "Gas budget exceeded, deferring outgoing transactions",
+			"budget", budget,
+			"kind", kind,
+			"gas_cost", gasCost.String(),
+			"spent", spent.String(),
+			"limit", limit,
+			"unit", unit,
+			"day", dayKey)
+	} else {
+		s.logger.WarnContext(ctx, "Outgoing transaction deferred by gas budget",
+			"budget", budget, "kind", kind, "gas_cost", gasCost.String())
+	}
+
+	return fmt.Errorf("%w: %s budget %s %s, spent %s wei today", ErrGasBudgetExceeded, budget, limit, unit, spent.String())
+}
@@ -0,0 +1,375 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+const (
+	// DefaultInvoiceExpiry — срок оплаты счета, если мерчант не указал свой
+	DefaultInvoiceExpiry = time.Hour
+	maxInvoiceExpiry     = 7 * 24 * time.Hour
+
+	// Число знаков суммы в активе при пересчете из фиата, сумма округляется вверх
+	invoiceQuoteDecimals = 6
+
+	invoiceStatusTokenBytes = 24
+	merchantInvoicesLimit   = 100
+)
+
+type InvoicesRepository interface {
+	CreateInvoice(ctx context.Context, invoice *entities.Invoice) error
+	FindByStatusToken(ctx context.Context, token string) (*entities.Invoice, error)
+	FindByMerchant(ctx context.Context, merchantID int64, limit int) ([]entities.Invoice, error)
+	AttachOrder(ctx context.Context, invoiceID string, quote entities.InvoiceQuote, orderID int, walletAddress string) (bool, error)
+	UpdateStatus(ctx context.Context, invoiceID string, status entities.InvoiceStatus) error
+}
+
+var _ InvoicesRepository = (*repository.InvoicesRepository)(nil)
+
+type InvoiceOrderService interface {
+	CreateOrder(ctx context.Context, userID, walletID int, amount string) (*entities.OrderPayment, error)
+}
+
+type InvoiceWalletService interface {
+	GenerateDepositWallet(ctx context.Context, userID int64) (int, string, error)
+}
+
+type InvoicePaymentLinks interface {
+	GetPaymentRequest(ctx context.Context, userID int64, orderID int) (*entities.PaymentRequest, error)
+}
+
+// InvoiceAssets — активы, принимающие депозиты: для них есть депозитные кошельки и сканирование переводов
+type InvoiceAssets interface {
+	DepositCodes() []string
+}
+
+var (
+	_ InvoiceOrderService  = (*OrderService)(nil)
+	_ InvoiceWalletService = (*WalletService)(nil)
+	_ InvoicePaymentLinks  = (*PaymentLinkService)(nil)
+	_ InvoiceAssets        = (*AssetRegistry)(nil)
+)
+
+// CreateInvoiceRequest — параметры счета, задаваемые мерчантом
+type CreateInvoiceRequest struct {
+	FiatAmount     string
+	FiatCurrency   string
+	AcceptedAssets []string
+	Description    string
+	ExpiresIn      time.Duration
+}
+
+// InvoiceService — движок счетов: котирует фиатную сумму в принимаемых активах и при выборе актива
+// создает под счет ордер и депозитный кошелек мерчанта
+type InvoiceService struct {
+	logger   *slog.Logger
+	repo     InvoicesRepository
+	orders   InvoiceOrderService
+	wallets  InvoiceWalletService
+	payments InvoicePaymentLinks
+	assets   InvoiceAssets
+	rates    RateProvider
+
+	// Для проверки статуса оплаты ордера
+	orderLookup PaymentOrdersRepository
+}
+
+func NewInvoiceService(
+	logger *slog.Logger,
+	repo InvoicesRepository,
+	orders InvoiceOrderService,
+	wallets InvoiceWalletService,
+	payments InvoicePaymentLinks,
+	assets InvoiceAssets,
+	orderLookup PaymentOrdersRepository,
+	rates RateProvider,
+) *InvoiceService {
+	return &InvoiceService{
+		logger:      logger,
+		repo:        repo,
+		orders:      orders,
+		wallets:     wallets,
+		payments:    payments,
+		assets:      assets,
+		orderLookup: orderLookup,
+		rates:       rates,
+	}
+}
+
+// CreateInvoice создает счет мерчанта
+func (s *InvoiceService) CreateInvoice(ctx context.Context, merchantID int64, req CreateInvoiceRequest) (*entities.Invoice, error) {
+	amount, err := decimal.Parse(req.FiatAmount)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid fiat amount", ErrInvalidInvoiceRequest)
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.FiatCurrency))
+	if currency == "" {
+		return nil, fmt.Errorf("%w: fiat currency is required", ErrInvalidInvoiceRequest)
+	}
+
+	supported := s.assets.DepositCodes()
+	assets := make([]string, 0, len(req.AcceptedAssets))
+	for _, asset := range req.AcceptedAssets {
+		asset = strings.ToUpper(strings.TrimSpace(asset))
+		if !slices.Contains(supported, asset) {
+			return nil, fmt.Errorf("%w: %s", ErrAssetNotSupported, asset)
+		}
+		if !slices.Contains(assets, asset) {
+			assets = append(assets, asset)
+		}
+	}
+	if len(assets) == 0 {
+		assets = supported
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("%w: no assets accept deposits", ErrAssetNotSupported)
+	}
+
+	expiresIn := req.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = DefaultInvoiceExpiry
+	}
+	if expiresIn > maxInvoiceExpiry {
+		return nil, fmt.Errorf("%w: expiry exceeds %s", ErrInvalidInvoiceRequest, maxInvoiceExpiry)
+	}
+
+	token, err := newInvoiceStatusToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &entities.Invoice{
+		ID:             uuid.New().String(),
+		MerchantID:     merchantID,
+		FiatAmount:     req.FiatAmount,
+		FiatCurrency:   currency,
+		AcceptedAssets: assets,
+		Description:    req.Description,
+		Status:         entities.InvoiceStatusOpen,
+		StatusToken:    token,
+		ExpiresAt:      time.Now().Add(expiresIn),
+	}
+
+	if err := s.repo.CreateInvoice(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Invoice created",
+		"invoice_id", invoice.ID,
+		"merchant_id", merchantID,
+		"fiat_amount", invoice.FiatAmount,
+		"fiat_currency", invoice.FiatCurrency,
+		"accepted_assets", assets)
+
+	return invoice, nil
+}
+
+// GetMerchantInvoices возвращает последние счета мерчанта с актуальными статусами
+func (s *InvoiceService) GetMerchantInvoices(ctx context.Context, merchantID int64) ([]entities.Invoice, error) {
+	invoices, err := s.repo.FindByMerchant(ctx, merchantID, merchantInvoicesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range invoices {
+		s.refreshStatus(ctx, &invoices[i])
+	}
+
+	return invoices, nil
+}
+
+// GetStatusPage возвращает публичный статус счета по токену страницы оплаты
+func (s *InvoiceService) GetStatusPage(ctx context.Context, token string) (*entities.InvoiceStatusPage, error) {
+	invoice, err := s.findByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshStatus(ctx, invoice)
+
+	page := &entities.InvoiceStatusPage{
+		ID:           invoice.ID,
+		FiatAmount:   invoice.FiatAmount,
+		FiatCurrency: invoice.FiatCurrency,
+		Description:  invoice.Description,
+		Status:       invoice.Status,
+		ExpiresAt:    invoice.ExpiresAt,
+	}
+
+	switch invoice.Status {
+	case entities.InvoiceStatusOpen:
+		// Пока актив не выбран, показываем суммы во всех принимаемых активах
+		for _, asset := range invoice.AcceptedAssets {
+			quote, err := s.quote(ctx, invoice, asset)
+			if err != nil {
+				s.logger.WarnContext(ctx, "Failed to quote invoice", "error", err, "invoice_id", invoice.ID, "asset", asset)
+				continue
+			}
+			page.Quotes = append(page.Quotes, *quote)
+		}
+	case entities.InvoiceStatusAwaitingPayment:
+		page.Quotes = []entities.InvoiceQuote{{Asset: *invoice.SelectedAsset, Amount: *invoice.CryptoAmount, Rate: *invoice.QuoteRate}}
+		page.Payment, err = s.payments.GetPaymentRequest(ctx, invoice.MerchantID, *invoice.OrderID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get invoice payment details", "error", err, "invoice_id", invoice.ID)
+		}
+	}
+
+	return page, nil
+}
+
+// SelectAsset фиксирует курс выбранного актива и создает ордер с депозитным кошельком мерчанта
+func (s *InvoiceService) SelectAsset(ctx context.Context, token, asset string) (*entities.InvoiceStatusPage, error) {
+	invoice, err := s.findByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshStatus(ctx, invoice)
+
+	asset = strings.ToUpper(strings.TrimSpace(asset))
+	switch invoice.Status {
+	case entities.InvoiceStatusOpen:
+	case entities.InvoiceStatusExpired:
+		return nil, ErrInvoiceExpired
+	default:
+		if invoice.SelectedAsset != nil && *invoice.SelectedAsset == asset {
+			return s.GetStatusPage(ctx, token)
+		}
+		return nil, ErrInvoiceAssetSelected
+	}
+
+	if !slices.Contains(invoice.AcceptedAssets, asset) {
+		return nil, ErrAssetNotAccepted
+	}
+
+	quote, err := s.quote(ctx, invoice, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Мерчант в омнибус режиме получает общий кошелек, ордер на нем определяется по сумме или мемо
+	walletID, address, err := s.wallets.GenerateDepositWallet(ctx, invoice.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate wallet for invoice: %w", err)
+	}
+
+	payment, err := s.orders.CreateOrder(ctx, int(invoice.MerchantID), walletID, quote.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order for invoice: %w", err)
+	}
+	quote.Amount = payment.Amount
+	orderID := payment.OrderID
+
+	attached, err := s.repo.AttachOrder(ctx, invoice.ID, *quote, orderID, address)
+	if err != nil {
+		return nil, err
+	}
+	if !attached {
+		return nil, ErrInvoiceAssetSelected
+	}
+
+	s.logger.InfoContext(ctx, "Invoice asset selected",
+		"invoice_id", invoice.ID,
+		"asset", asset,
+		"amount", quote.Amount,
+		"rate", quote.Rate,
+		"order_id", orderID,
+		"wallet", address)
+
+	return s.GetStatusPage(ctx, token)
+}
+
+func (s *InvoiceService) findByToken(ctx context.Context, token string) (*entities.Invoice, error) {
+	invoice, err := s.repo.FindByStatusToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// quote пересчитывает фиатную сумму счета в актив, округляя вверх до invoiceQuoteDecimals знаков
+func (s *InvoiceService) quote(ctx context.Context, invoice *entities.Invoice, asset string) (*entities.InvoiceQuote, error) {
+	rate, err := s.rates.Rate(ctx, asset, invoice.FiatCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	fiat, err := decimal.Parse(invoice.FiatAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fiat amount of invoice %s: %w", invoice.ID, err)
+	}
+
+	amount := decimal.FromRat(new(big.Rat).Quo(fiat.Rat(), rate)).Round(invoiceQuoteDecimals, decimal.RoundUp)
+	return &entities.InvoiceQuote{
+		Asset:  asset,
+		Amount: amount.String(),
+		Rate:   rate.FloatString(8),
+	}, nil
+}
+
+// refreshStatus переводит счет в paid по завершенному ордеру или в expired по истечении срока
+func (s *InvoiceService) refreshStatus(ctx context.Context, invoice *entities.Invoice) {
+	if invoice.Status == entities.InvoiceStatusPaid || invoice.Status == entities.InvoiceStatusExpired {
+		return
+	}
+
+	status := invoice.Status
+	if invoice.OrderID != nil {
+		order, err := s.orderLookup.FindOrderByID(ctx, *invoice.OrderID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to check invoice order", "error", err, "invoice_id", invoice.ID)
+			return
+		}
+		switch {
+		case order != nil && order.Status == entities.OrderStatusCompleted:
+			status = entities.InvoiceStatusPaid
+		case order == nil || time.Now().After(invoice.ExpiresAt):
+			// Ордер удален очисткой просроченных ордеров или срок счета истек
+			status = entities.InvoiceStatusExpired
+		}
+	} else if time.Now().After(invoice.ExpiresAt) {
+		status = entities.InvoiceStatusExpired
+	}
+
+	if status == invoice.Status {
+		return
+	}
+
+	if err := s.repo.UpdateStatus(ctx, invoice.ID, status); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update invoice status", "error", err, "invoice_id", invoice.ID)
+		return
+	}
+
+	s.logger.InfoContext(ctx, "Invoice status changed",
+		"invoice_id", invoice.ID,
+		"from", invoice.Status,
+		"to", status)
+	invoice.Status = status
+}
+
+func newInvoiceStatusToken() (string, error) {
+	buf := make([]byte, invoiceStatusTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invoice token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,438 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sandquattro/go-bip32"
+	"github.com/sandquattro/go-bip39"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	// Образец адресов для сверки сида: первые кошельки первых пользователей
+	keyRotationSampleSize  = 5
+	keyRotationBatchSize   = 50
+	keyRotationsListLimit  = 50
+	keyRotationSweepLimit  = 500
+	keyRotationInitiatedBy = "worker:key_rotation"
+)
+
+type KeyRotationsRepository interface {
+	CreateRotation(ctx context.Context, rotation *entities.KeyRotation) error
+	FindRotation(ctx context.Context, id int) (*entities.KeyRotation, error)
+	FindRotations(ctx context.Context, limit int) ([]entities.KeyRotation, error)
+	FindRotationByStatus(ctx context.Context, status entities.KeyRotationStatus) (*entities.KeyRotation, error)
+	FindActivatedVersion(ctx context.Context) (int, error)
+	ActivateRotation(ctx context.Context, id int, activatedBy string) (bool, error)
+	QueueSweeps(ctx context.Context, id, toVersion int, chain entities.Chain, network, skipPathPrefix string) (int, bool, error)
+	FindPendingSweeps(ctx context.Context, rotationID, limit int) ([]entities.KeyRotationSweep, error)
+	FindSweeps(ctx context.Context, rotationID int, status entities.KeyRotationSweepStatus, limit int) ([]entities.KeyRotationSweep, error)
+	FindDestination(ctx context.Context, rotationID int, userID int64) (*int, *string, error)
+	UpdateSweep(ctx context.Context, sweep *entities.KeyRotationSweep) error
+	CompleteRotation(ctx context.Context, id int) (bool, error)
+}
+
+// RotationKeyring переключает версию сида, из которого выводятся новые кошельки
+type RotationKeyring interface {
+	ActiveVersion() int
+	Versions() []int
+	Configured(version int) bool
+	SetActive(version int) error
+}
+
+type KeyRotationWallets interface {
+	DeriveAddress(keyVersion int, userID, index int64) (common.Address, error)
+	GenerateWalletForUser(ctx context.Context, userID int64) (int, string, error)
+	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
+	CheckTokenHalt() error
+	Chain() entities.Chain
+	Network() string
+}
+
+var (
+	_ KeyRotationsRepository = (*repository.KeyRotationsRepository)(nil)
+	_ RotationKeyring        = (*Keyring)(nil)
+	_ KeyRotationWallets     = (*WalletService)(nil)
+)
+
+// KeyRotationService guides a master seed rotation. An offline ceremony (cmd/keyceremony) generates the new seed
+// and a sample of its addresses; once the seed is configured the rotation verifies the sample against the keyring, activates the new key version
+// for new wallets and sweeps balances of old-seed wallets to new-seed wallets of the same users.
+type KeyRotationService struct {
+	logger    *slog.Logger
+	repo      KeyRotationsRepository
+	keyring   RotationKeyring
+	wallets   KeyRotationWallets
+	transfers SweepTransfers
+	audit     *AuditService
+	interval  time.Duration
+}
+
+func NewKeyRotationService(
+	logger *slog.Logger,
+	repo KeyRotationsRepository,
+	keyring RotationKeyring,
+	wallets KeyRotationWallets,
+	transfers SweepTransfers,
+	audit *AuditService,
+	interval time.Duration,
+) (*KeyRotationService, error) {
+	if interval <= 0 {
+		return nil, errors.New("key rotation sweep interval must be positive")
+	}
+
+	return &KeyRotationService{
+		logger:    logger,
+		repo:      repo,
+		keyring:   keyring,
+		wallets:   wallets,
+		transfers: transfers,
+		audit:     audit,
+		interval:  interval,
+	}, nil
+}
+
+// Restore activates the key version of the latest activated rotation, so a restart keeps deriving new wallets
+// from the rotated seed even if the configured active version was not updated yet
+func (s *KeyRotationService) Restore(ctx context.Context) error {
+	version, err := s.repo.FindActivatedVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version == 0 || version == s.keyring.ActiveVersion() {
+		return nil
+	}
+
+	if err = s.keyring.SetActive(version); err != nil {
+		return fmt.Errorf("%w: rotated version %d", ErrKeyVersionNotConfigured, version)
+	}
+	s.logger.InfoContext(ctx, "Restored rotated key version", "active_version", version)
+	return nil
+}
+
+// GenerateKeyCeremony generates the seed of the key version and derives the sample addresses to verify it with.
+// It runs offline in the keyceremony command: the seed never reaches the server and has to be added
+// to the configured seeds before StartRotation.
+func GenerateKeyCeremony(version, entropyBits int) (*entities.KeyCeremony, error) {
+	if version < 2 {
+		return nil, fmt.Errorf("key version must be at least 2, got %d", version)
+	}
+
+	// bip39 принимает 128–256 бит с шагом 32, как и GenerateSeedPhrase
+	entropy, err := bip39.NewEntropy(entropyBits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSeedStrength, err)
+	}
+	defer wipeBytes(entropy)
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mnemonic: %w", err)
+	}
+
+	masterKey, err := bip32.NewMasterKey(bip39.NewSeed(mnemonic, ""))
+	if err != nil {
+		return nil, errors.New("failed to create master key of the new seed")
+	}
+	defer wipeBytes(masterKey.Key)
+
+	sample, err := sampleAddresses(func(userID, index int64) (common.Address, error) {
+		childKey, err := GetChildKey(masterKey, userID, index)
+		if err != nil {
+			return common.Address{}, err
+		}
+		defer wipeBytes(childKey.Key)
+
+		privateKey, address, err := GetWalletPrivateKey(childKey)
+		wipePrivateKey(privateKey)
+		return address, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.KeyCeremony{
+		Version:         version,
+		Mnemonic:        mnemonic,
+		SeedEntry:       fmt.Sprintf("%d:%s", version, mnemonic),
+		SampleAddresses: sample,
+	}, nil
+}
+
+// StartRotation verifies that the configured seed of the version derives the ceremony sample and records the rotation.
+// The version is not active until the rotation is activated.
+func (s *KeyRotationService) StartRotation(ctx context.Context, toVersion int, expected []string, actor string) (*entities.KeyRotation, error) {
+	fromVersion := s.keyring.ActiveVersion()
+	if !s.keyring.Configured(toVersion) {
+		return nil, fmt.Errorf("%w: %d", ErrKeyVersionNotConfigured, toVersion)
+	}
+	if toVersion == fromVersion {
+		return nil, fmt.Errorf("%w: version %d is already active", ErrKeyRotationState, toVersion)
+	}
+
+	sample, err := sampleAddresses(func(userID, index int64) (common.Address, error) {
+		return s.wallets.DeriveAddress(toVersion, userID, index)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(expected) != len(sample) {
+		return nil, fmt.Errorf("%w: expected %d addresses", ErrKeySampleMismatch, len(sample))
+	}
+	for i, address := range sample {
+		if !strings.EqualFold(address, strings.TrimSpace(expected[i])) {
+			return nil, fmt.Errorf("%w: address %d", ErrKeySampleMismatch, i+1)
+		}
+	}
+
+	rotation := &entities.KeyRotation{
+		FromVersion:     fromVersion,
+		ToVersion:       toVersion,
+		Status:          entities.KeyRotationVerified,
+		SampleAddresses: sample,
+		StartedBy:       actor,
+	}
+	err = s.repo.CreateRotation(ctx, rotation)
+	if errors.Is(err, repository.ErrUniqueViolation) {
+		return nil, ErrKeyRotationInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, entities.AuditEventKeyRotationStarted, actor, rotation, nil)
+	return rotation, nil
+}
+
+// Activate switches new wallets to the seed of the verified rotation
+func (s *KeyRotationService) Activate(ctx context.Context, id int, actor string) (*entities.KeyRotation, error) {
+	rotation, err := s.GetRotation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rotation.Status != entities.KeyRotationVerified {
+		return nil, fmt.Errorf("%w: rotation is %s", ErrKeyRotationState, rotation.Status)
+	}
+	if !s.keyring.Configured(rotation.ToVersion) {
+		return nil, fmt.Errorf("%w: %d", ErrKeyVersionNotConfigured, rotation.ToVersion)
+	}
+
+	activated, err := s.repo.ActivateRotation(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !activated {
+		return nil, fmt.Errorf("%w: rotation is no longer verified", ErrKeyRotationState)
+	}
+	if err = s.keyring.SetActive(rotation.ToVersion); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Key version activated", "rotation_id", id, "from_version", rotation.FromVersion,
+		"to_version", rotation.ToVersion)
+	s.record(ctx, entities.AuditEventKeyRotationActivated, actor, rotation, nil)
+	return s.GetRotation(ctx, id)
+}
+
+// ScheduleSweeps queues sweeps of all open wallets derived from other seeds to new-seed wallets of their users
+func (s *KeyRotationService) ScheduleSweeps(ctx context.Context, id int, actor string) (*entities.KeyRotation, error) {
+	rotation, err := s.GetRotation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Форвардеры опустошаются через фабрику, ключа у них нет
+	queued, moved, err := s.repo.QueueSweeps(ctx, id, rotation.ToVersion, s.wallets.Chain(), s.wallets.Network(), ForwarderPathPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, fmt.Errorf("%w: rotation is %s", ErrKeyRotationState, rotation.Status)
+	}
+
+	s.logger.InfoContext(ctx, "Key rotation sweeps queued", "rotation_id", id, "wallets", queued)
+	s.record(ctx, entities.AuditEventKeyRotationSweepsQueued, actor, rotation, map[string]any{"wallets": queued})
+	return s.GetRotation(ctx, id)
+}
+
+// GetRotation returns the rotation with its sweep progress
+func (s *KeyRotationService) GetRotation(ctx context.Context, id int) (*entities.KeyRotation, error) {
+	rotation, err := s.repo.FindRotation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rotation == nil {
+		return nil, ErrKeyRotationNotFound
+	}
+	return rotation, nil
+}
+
+// GetRotations returns the latest rotations
+func (s *KeyRotationService) GetRotations(ctx context.Context) ([]entities.KeyRotation, error) {
+	return s.repo.FindRotations(ctx, keyRotationsListLimit)
+}
+
+// GetSweeps returns the sweeps of the rotation, of all statuses if status is empty
+func (s *KeyRotationService) GetSweeps(ctx context.Context, id int, status entities.KeyRotationSweepStatus) ([]entities.KeyRotationSweep, error) {
+	if _, err := s.GetRotation(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindSweeps(ctx, id, status, keyRotationSweepLimit)
+}
+
+// Start periodically sweeps old-seed wallets of the sweeping rotation until ctx is cancelled
+func (s *KeyRotationService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SweepPending(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Key rotation sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// SweepPending sweeps a batch of queued old-seed wallets and completes the rotation once none is left.
+// A failed sweep is kept as failed and reported in the rotation progress.
+func (s *KeyRotationService) SweepPending(ctx context.Context) error {
+	rotation, err := s.repo.FindRotationByStatus(ctx, entities.KeyRotationSweeping)
+	if err != nil || rotation == nil {
+		return err
+	}
+	if err = s.wallets.CheckTokenHalt(); err != nil {
+		s.logger.WarnContext(ctx, "Key rotation sweep skipped", "reason", err.Error())
+		return nil
+	}
+
+	sweeps, err := s.repo.FindPendingSweeps(ctx, rotation.ID, keyRotationBatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(sweeps) > 0 {
+		client, err := GetBSCClient(ctx, s.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create BSC client: %w", err)
+		}
+		defer client.Close()
+
+		for i := range sweeps {
+			if ctx.Err() != nil {
+				return nil
+			}
+			sweep := &sweeps[i]
+			s.sweep(ctx, client, rotation, sweep)
+			if err = s.repo.UpdateSweep(ctx, sweep); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to record key rotation sweep", "error", err, "wallet_id", sweep.WalletID)
+			}
+		}
+	}
+
+	completed, err := s.repo.CompleteRotation(ctx, rotation.ID)
+	if err != nil || !completed {
+		return err
+	}
+
+	rotation, err = s.GetRotation(ctx, rotation.ID)
+	if err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "Key rotation completed", "rotation_id", rotation.ID, "swept", rotation.WalletsSwept,
+		"skipped", rotation.WalletsSkipped, "failed", rotation.WalletsFailed)
+	s.record(ctx, entities.AuditEventKeyRotationCompleted, keyRotationInitiatedBy, rotation, map[string]any{
+		"swept":   rotation.WalletsSwept,
+		"skipped": rotation.WalletsSkipped,
+		"failed":  rotation.WalletsFailed,
+	})
+	return nil
+}
+
+// sweep переводит остаток кошелька старого сида на кошелек пользователя из нового сида, результат записывается в sweep
+func (s *KeyRotationService) sweep(ctx context.Context, client *ethclient.Client, rotation *entities.KeyRotation, sweep *entities.KeyRotationSweep) {
+	balance, err := s.wallets.GetERC20TokenBalance(ctx, client, sweep.FromAddress)
+	if err != nil {
+		s.failSweep(ctx, sweep, fmt.Errorf("failed to get balance: %w", err))
+		return
+	}
+	amount := balance.String()
+	sweep.Amount = &amount
+	if balance.Sign() == 0 {
+		sweep.Status = entities.KeyRotationSweepSkipped
+		return
+	}
+
+	sweep.ToWalletID, sweep.ToAddress, err = s.repo.FindDestination(ctx, rotation.ID, sweep.UserID)
+	if err != nil {
+		s.failSweep(ctx, sweep, err)
+		return
+	}
+	if sweep.ToWalletID == nil {
+		walletID, address, err := s.wallets.GenerateWalletForUser(ctx, sweep.UserID)
+		if err != nil {
+			s.failSweep(ctx, sweep, fmt.Errorf("failed to generate new-seed wallet: %w", err))
+			return
+		}
+		sweep.ToWalletID, sweep.ToAddress = &walletID, &address
+	}
+
+	transfer, err := s.transfers.Transfer(ctx, client, entities.TreasuryTransferSweep, sweep.WalletID, *sweep.ToAddress, balance, keyRotationInitiatedBy)
+	if err != nil {
+		s.failSweep(ctx, sweep, err)
+		return
+	}
+
+	txHash := transfer.TxHash
+	if transfer.Proposal != nil {
+		txHash = transfer.Proposal.SafeTxHash
+	}
+	sweep.Status = entities.KeyRotationSweepSwept
+	sweep.TxHash = &txHash
+	s.logger.InfoContext(ctx, "Old-seed wallet swept", "rotation_id", rotation.ID, "from", sweep.FromAddress,
+		"to", *sweep.ToAddress, "amount", amount, "tx_hash", txHash)
+}
+
+func (s *KeyRotationService) failSweep(ctx context.Context, sweep *entities.KeyRotationSweep, err error) {
+	s.logger.WarnContext(ctx, "Failed to sweep old-seed wallet", "error", err, "wallet", sweep.FromAddress)
+	reason := err.Error()
+	sweep.Status = entities.KeyRotationSweepFailed
+	sweep.Error = &reason
+}
+
+func (s *KeyRotationService) record(ctx context.Context, event entities.AuditEventType, actor string, rotation *entities.KeyRotation, details map[string]any) {
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["from_version"] = rotation.FromVersion
+	details["to_version"] = rotation.ToVersion
+	if err := s.audit.Record(ctx, event, actor, fmt.Sprintf("key_rotation:%d", rotation.ID), details); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record key rotation event", "error", err, "rotation_id", rotation.ID, "event", event)
+	}
+}
+
+// sampleAddresses выводит адреса первых кошельков первых пользователей для сверки сида
+func sampleAddresses(derive func(userID, index int64) (common.Address, error)) ([]string, error) {
+	sample := make([]string, 0, keyRotationSampleSize)
+	for userID := int64(1); userID <= keyRotationSampleSize; userID++ {
+		address, err := derive(userID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive sample address: %w", err)
+		}
+		sample = append(sample, address.Hex())
+	}
+	return sample, nil
+}
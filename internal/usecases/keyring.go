@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sandquattro/go-bip32"
+	"github.com/sandquattro/go-bip39"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// KeyringConfig задает мастер-сиды по версиям. Seed — сид версии 1, Seeds — дополнительные сиды
+// в формате "версия:мнемоника"
+type KeyringConfig struct {
+	Seed  string
+	Seeds []string
+	// Версия сида, из которого выводятся новые депозитные кошельки
+	ActiveVersion int
+	// Версия сида служебных ключей (релейер, оператор свипов, отправитель форвардеров, казначейство):
+	// их адреса не меняются при смене активного сида
+	ServiceVersion int
+}
+
+// Keyring holds the master keys of all seeds in use. During a seed rotation new wallets are derived
+// from the active seed, while wallets derived from older seeds stay spendable with their own key version.
+type Keyring struct {
+	keys map[int]*bip32.Key
+	// Меняется при активации ротации сида без перезапуска
+	active  atomic.Int64
+	service int
+}
+
+func NewKeyring(config KeyringConfig) (*Keyring, error) {
+	seeds := map[int]string{}
+	if config.Seed != "" {
+		seeds[entities.LegacyKeyVersion] = config.Seed
+	}
+	for _, entry := range config.Seeds {
+		value, mnemonic, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		version, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || version <= 0 {
+			return nil, errors.New("wallet seeds must be in the version:mnemonic form with a positive version")
+		}
+		if _, exists := seeds[version]; exists {
+			return nil, fmt.Errorf("duplicate seed for key version %d", version)
+		}
+		seeds[version] = strings.TrimSpace(mnemonic)
+	}
+
+	keyring := &Keyring{
+		keys:    make(map[int]*bip32.Key, len(seeds)),
+		service: config.ServiceVersion,
+	}
+	keyring.active.Store(int64(config.ActiveVersion))
+	for version, seed := range seeds {
+		masterKey, err := bip32.NewMasterKey(bip39.NewSeed(seed, ""))
+		if err != nil {
+			// Сид в ошибку не попадает
+			return nil, fmt.Errorf("failed to create master key of version %d", version)
+		}
+		keyring.keys[version] = masterKey
+	}
+
+	if _, ok := keyring.keys[config.ActiveVersion]; !ok {
+		return nil, fmt.Errorf("no seed configured for active key version %d", config.ActiveVersion)
+	}
+	if _, ok := keyring.keys[config.ServiceVersion]; !ok {
+		return nil, fmt.Errorf("no seed configured for service key version %d", config.ServiceVersion)
+	}
+
+	return keyring, nil
+}
+
+// Key returns the master key of the version
+func (k *Keyring) Key(version int) (*bip32.Key, error) {
+	masterKey, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("no seed configured for key version %d", version)
+	}
+	return masterKey, nil
+}
+
+// ActiveVersion returns the version new deposit wallets are derived from
+func (k *Keyring) ActiveVersion() int {
+	return int(k.active.Load())
+}
+
+// SetActive derives new deposit wallets from the seed of the version, which must be configured
+func (k *Keyring) SetActive(version int) error {
+	if _, ok := k.keys[version]; !ok {
+		return fmt.Errorf("no seed configured for key version %d", version)
+	}
+	k.active.Store(int64(version))
+	return nil
+}
+
+// Configured reports whether a seed of the version is loaded
+func (k *Keyring) Configured(version int) bool {
+	_, ok := k.keys[version]
+	return ok
+}
+
+// ServiceVersion returns the version of service keys
+func (k *Keyring) ServiceVersion() int {
+	return k.service
+}
+
+// Versions returns the configured key versions in ascending order
+func (k *Keyring) Versions() []int {
+	versions := make([]int, 0, len(k.keys))
+	for version := range k.keys {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions
+}
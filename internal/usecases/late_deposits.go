@@ -0,0 +1,205 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+const (
+	lateDepositBatchSize   = 100
+	lateDepositInitiatedBy = "rule:late_deposit"
+	// Депозиты, зачисленные раньше, не проверяются: до появления статуса expired ордера удалялись
+	lateDepositLookback = 7 * 24 * time.Hour
+)
+
+type LateDepositsRepository interface {
+	FindLateDeposits(ctx context.Context, since time.Time, limit int) ([]entities.LateDeposit, error)
+	RecordLateDeposit(ctx context.Context, deposit entities.LateDeposit, action entities.LateDepositAction, refundID, reason *string) error
+	ReopenOrder(ctx context.Context, deposit entities.LateDeposit) error
+}
+
+type LateDepositRefunds interface {
+	RequestRefund(ctx context.Context, depositTxHash string, amount *big.Int, reason entities.RefundReason, initiatedBy string) (*entities.Refund, error)
+}
+
+// LateDepositAssets — актив по умолчанию для ордеров, созданных до реестра активов
+type LateDepositAssets interface {
+	Default() entities.Asset
+}
+
+var (
+	_ LateDepositsRepository = (*repository.LateDepositsRepository)(nil)
+	_ LateDepositRefunds     = (*RefundService)(nil)
+	_ LateDepositAssets      = (*AssetRegistry)(nil)
+)
+
+// LateDepositConfig задает политику для поздних депозитов и период их поиска
+type LateDepositConfig struct {
+	Policy   entities.LateDepositPolicy
+	Interval time.Duration
+}
+
+// LateDepositService handles deposits that arrive on the wallet of an already expired order. Instead of
+// keeping the funds silently, the deposit is refunded to the sender or the order is reopened and paid
+// with the deposit, according to the policy, and the user is notified.
+type LateDepositService struct {
+	logger   *slog.Logger
+	repo     LateDepositsRepository
+	refunds  LateDepositRefunds
+	assets   LateDepositAssets
+	notifier Notifier
+	tracker  WorkerTracker
+
+	policy   entities.LateDepositPolicy
+	interval time.Duration
+}
+
+func NewLateDepositService(
+	logger *slog.Logger,
+	repo LateDepositsRepository,
+	refunds LateDepositRefunds,
+	assets LateDepositAssets,
+	notifier Notifier,
+	tracker WorkerTracker,
+	config LateDepositConfig,
+) (*LateDepositService, error) {
+	if config.Policy != entities.LateDepositPolicyRefund && config.Policy != entities.LateDepositPolicyReopen {
+		return nil, fmt.Errorf("unknown late deposit policy %q", config.Policy)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("late deposit interval must be positive")
+	}
+
+	return &LateDepositService{
+		logger:   logger,
+		repo:     repo,
+		refunds:  refunds,
+		assets:   assets,
+		notifier: notifier,
+		tracker:  tracker,
+		policy:   config.Policy,
+		interval: config.Interval,
+	}, nil
+}
+
+// Start periodically handles late deposits until ctx is cancelled
+func (s *LateDepositService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.HandleLateDeposits(ctx)
+			s.tracker.Done(count, err)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Late deposit handling failed", "error", err)
+			}
+		}
+	}
+}
+
+// HandleLateDeposits refunds late deposits or reopens their orders and returns the number of handled deposits.
+// A deposit that failed for a transient reason is retried on the next pass.
+func (s *LateDepositService) HandleLateDeposits(ctx context.Context) (int, error) {
+	deposits, err := s.repo.FindLateDeposits(ctx, time.Now().Add(-lateDepositLookback), lateDepositBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, deposit := range deposits {
+		if ctx.Err() != nil {
+			break
+		}
+		if deposit.Asset == "" {
+			deposit.Asset = s.assets.Default().Code
+		}
+
+		if s.policy == entities.LateDepositPolicyReopen {
+			err = s.reopen(ctx, deposit)
+			// Мемо или точную сумму ордера уже занял новый ордер кошелька, такой депозит возвращается
+			if errors.Is(err, repository.ErrUniqueViolation) {
+				s.logger.WarnContext(ctx, "Expired order cannot be reopened, refunding late deposit",
+					"order_id", deposit.OrderID, "tx_hash", deposit.TxHash)
+				err = s.refund(ctx, deposit)
+			}
+		} else {
+			err = s.refund(ctx, deposit)
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to handle late deposit", "error", err, "tx_hash", deposit.TxHash, "order_id", deposit.OrderID)
+			continue
+		}
+		handled++
+	}
+
+	return handled, nil
+}
+
+func (s *LateDepositService) reopen(ctx context.Context, deposit entities.LateDeposit) error {
+	if err := s.repo.ReopenOrder(ctx, deposit); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Expired order reopened by late deposit",
+		"order_id", deposit.OrderID, "tx_hash", deposit.TxHash, "amount", deposit.Amount)
+	s.notify(ctx, deposit, "Order reopened",
+		fmt.Sprintf("A payment of %s %s for order #%d arrived after the order expired. The order has been reopened and the payment will be credited to it.",
+			s.formatAmount(deposit), deposit.Asset, deposit.OrderID))
+	return nil
+}
+
+func (s *LateDepositService) refund(ctx context.Context, deposit entities.LateDeposit) error {
+	refund, err := s.refunds.RequestRefund(ctx, deposit.TxHash, nil, entities.RefundReasonLateDeposit, lateDepositInitiatedBy)
+	if errors.Is(err, ErrRefundNotAllowed) || errors.Is(err, ErrRefundExists) {
+		// Повторная попытка ничего не изменит, депозит остается на кошельке до решения администратора
+		reason := err.Error()
+		if err = s.repo.RecordLateDeposit(ctx, deposit, entities.LateDepositFailed, nil, &reason); err != nil {
+			return err
+		}
+		s.logger.WarnContext(ctx, "Late deposit cannot be refunded", "reason", reason, "tx_hash", deposit.TxHash, "order_id", deposit.OrderID)
+		s.notify(ctx, deposit, "Payment received after order expired",
+			fmt.Sprintf("A payment of %s %s for order #%d arrived after the order expired and could not be refunded automatically. Please contact support.",
+				s.formatAmount(deposit), deposit.Asset, deposit.OrderID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.RecordLateDeposit(ctx, deposit, entities.LateDepositRefund, &refund.ID, nil); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Late deposit refund created",
+		"order_id", deposit.OrderID, "tx_hash", deposit.TxHash, "refund_id", refund.ID, "amount", deposit.Amount)
+	s.notify(ctx, deposit, "Payment received after order expired",
+		fmt.Sprintf("A payment of %s %s for order #%d arrived after the order expired. It will be refunded to %s.",
+			s.formatAmount(deposit), deposit.Asset, deposit.OrderID, refund.ToAddress))
+	return nil
+}
+
+func (s *LateDepositService) formatAmount(deposit entities.LateDeposit) string {
+	units, ok := new(big.Int).SetString(deposit.Amount, 10)
+	if !ok {
+		return deposit.Amount
+	}
+	return decimal.FromUnits(units, deposit.Decimals).String()
+}
+
+func (s *LateDepositService) notify(ctx context.Context, deposit entities.LateDeposit, subject, message string) {
+	if err := s.notifier.Notify(ctx, deposit.UserID, subject, message); err != nil {
+		s.logger.WarnContext(ctx, "Failed to notify user about late deposit", "error", err, "tx_hash", deposit.TxHash)
+	}
+}
@@ -0,0 +1,641 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// LedgerOperationSafeExecution — исполнение предложения Safe владельцем, подпись ключом платформы не требуется
+const LedgerOperationSafeExecution = "safe_execution"
+
+const (
+	ledgerSettleBatch = 100
+	// Транзакция без квитанции дольше этого срока считается выброшенной из мемпула
+	ledgerDropAfter = 24 * time.Hour
+	// Знаков после запятой у курсов и сумм в валюте учета
+	ledgerFiatDecimals = 8
+)
+
+type LedgerRepository interface {
+	CreateEntry(ctx context.Context, entry *entities.LedgerEntry) error
+	ReplaceTxHash(ctx context.Context, oldTxHash, newTxHash, gasPrice string) error
+	CancelEntry(ctx context.Context, oldTxHash, newTxHash, gasPrice string) error
+	FindPending(ctx context.Context, limit int) ([]entities.LedgerEntry, error)
+	Settle(ctx context.Context, id string, status entities.LedgerEntryStatus, gasUsed *int64, gasCost, effectiveGasPrice *string, conversion *entities.LedgerGasConversion) error
+	Summarize(ctx context.Context, from, to time.Time, period entities.PnLPeriod) ([]entities.LedgerSummary, error)
+	SummarizeGasFiat(ctx context.Context, from, to time.Time, period entities.PnLPeriod, fiatCurrency string) ([]entities.LedgerGasFiatSummary, error)
+	GasSpentSince(ctx context.Context, since time.Time) (map[entities.LedgerEntryKind]string, error)
+	WithinGasBudgetLock(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateReservation(ctx context.Context, id string, kind entities.LedgerEntryKind, gasCost string) error
+	DeleteReservation(ctx context.Context, id string) error
+	FeeStats(ctx context.Context, from, to time.Time) ([]entities.FeeStats, error)
+}
+
+type LedgerAssets interface {
+	Default() entities.Asset
+	FindByID(id int) (entities.Asset, error)
+}
+
+var (
+	_ LedgerRepository = (*repository.LedgerRepository)(nil)
+	_ LedgerAssets     = (*AssetRegistry)(nil)
+)
+
+// ledgerTagKey помечает контекст отправки видом операции, суммой и комиссией платформы
+type ledgerTagKey struct{}
+
+type ledgerTag struct {
+	kind   entities.LedgerEntryKind
+	amount *big.Int
+	fee    *big.Int
+}
+
+// withLedgerTag returns a context whose outgoing transactions are recorded with the given kind, amount and platform fee
+func withLedgerTag(ctx context.Context, kind entities.LedgerEntryKind, amount, fee *big.Int) context.Context {
+	return context.WithValue(ctx, ledgerTagKey{}, ledgerTag{kind: kind, amount: amount, fee: fee})
+}
+
+// ledgerKindFromContext returns the kind the outgoing transaction of the context is recorded with
+func ledgerKindFromContext(ctx context.Context) entities.LedgerEntryKind {
+	if tag, ok := ctx.Value(ledgerTagKey{}).(ledgerTag); ok {
+		return tag.kind
+	}
+	return entities.LedgerKindOperational
+}
+
+type LedgerConfig struct {
+	// Валюта учета: газ записывается в ней при расчете и пересчитывается в единицы актива через ее курсы
+	RateCurrency   string
+	SettleInterval time.Duration
+	GasBudgets     GasBudgetConfig
+}
+
+// LedgerService records every transaction sent by the platform and settles its gas cost from the receipt,
+// converted to the rate currency at the rate of the chain's native coin at settlement.
+// The journal is the source of the P&L report: collected fees against gas spent on sweeps, withdrawals and refunds.
+type LedgerService struct {
+	logger *slog.Logger
+	repo   LedgerRepository
+	assets LedgerAssets
+	rates  RateProvider
+
+	rateCurrency   string
+	settleInterval time.Duration
+
+	budgets   gasBudgets
+	alertsMu  sync.Mutex
+	alertedAt map[string]string
+}
+
+func NewLedgerService(logger *slog.Logger, repo LedgerRepository, assets LedgerAssets, rates RateProvider, config LedgerConfig) (*LedgerService, error) {
+	if config.SettleInterval <= 0 {
+		return nil, errors.New("ledger settle interval must be positive")
+	}
+
+	budgets, err := parseGasBudgets(config.GasBudgets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas budgets: %w", err)
+	}
+	if budgets.dailyFiat != nil && (rates == nil || strings.TrimSpace(config.RateCurrency) == "") {
+		return nil, errors.New("fiat gas budget requires rates and a rate currency")
+	}
+
+	return &LedgerService{
+		logger:         logger,
+		repo:           repo,
+		assets:         assets,
+		rates:          rates,
+		rateCurrency:   strings.ToUpper(strings.TrimSpace(config.RateCurrency)),
+		settleInterval: config.SettleInterval,
+		budgets:        budgets,
+		alertedAt:      make(map[string]string),
+	}, nil
+}
+
+// RecordSent stores a sent transaction. The transaction is already broadcast,
+// so a failure to record it is only logged.
+func (s *LedgerService) RecordSent(ctx context.Context, txHash, operation string, from, to common.Address, gasPrice *big.Int, gasLimit uint64) {
+	tag, ok := ctx.Value(ledgerTagKey{}).(ledgerTag)
+	if !ok {
+		tag = ledgerTag{kind: entities.LedgerKindOperational}
+	}
+
+	entry := &entities.LedgerEntry{
+		ID:          uuid.New().String(),
+		AssetID:     s.assets.Default().ID,
+		Kind:        tag.kind,
+		Operation:   operation,
+		TxHash:      txHash,
+		FromAddress: from.Hex(),
+		ToAddress:   to.Hex(),
+		Amount:      "0",
+		Fee:         "0",
+		GasPrice:    gasPrice.String(),
+		GasLimit:    int64(gasLimit),
+		Status:      entities.LedgerStatusPending,
+	}
+	if tag.amount != nil {
+		entry.Amount = tag.amount.String()
+	}
+	if tag.fee != nil {
+		entry.Fee = tag.fee.String()
+	}
+
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record ledger entry", "error", err, "tx_hash", txHash, "kind", tag.kind)
+	}
+}
+
+// RecordReplaced moves the pending entry to the speed-up transaction that replaced it
+func (s *LedgerService) RecordReplaced(ctx context.Context, oldTxHash, newTxHash string, gasPrice *big.Int) {
+	if err := s.repo.ReplaceTxHash(ctx, oldTxHash, newTxHash, gasPrice.String()); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update replaced ledger entry", "error", err,
+			"original_tx_hash", oldTxHash, "tx_hash", newTxHash)
+	}
+}
+
+// RecordCancelled replaces the hash of a cancelled transaction: the entry becomes an operational zero-value
+// transfer to the sender, only the gas of the cancellation is accounted
+func (s *LedgerService) RecordCancelled(ctx context.Context, oldTxHash, newTxHash string, gasPrice *big.Int) {
+	if err := s.repo.CancelEntry(ctx, oldTxHash, newTxHash, gasPrice.String()); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update cancelled ledger entry", "error", err,
+			"original_tx_hash", oldTxHash, "tx_hash", newTxHash)
+	}
+}
+
+// Start periodically settles pending entries
+func (s *LedgerService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.settleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.settlePending(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to settle ledger entries", "error", err)
+			}
+		}
+	}
+}
+
+func (s *LedgerService) settlePending(ctx context.Context) error {
+	entries, err := s.repo.FindPending(ctx, ledgerSettleBatch)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	for _, entry := range entries {
+		if err = s.settle(ctx, client, entry); err != nil {
+			s.logger.WarnContext(ctx, "Failed to settle ledger entry", "error", err, "tx_hash", entry.TxHash)
+		}
+	}
+	return nil
+}
+
+func (s *LedgerService) settle(ctx context.Context, client *ethclient.Client, entry entities.LedgerEntry) error {
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(entry.TxHash))
+	if errors.Is(err, ethereum.NotFound) {
+		if time.Since(entry.CreatedAt) < ledgerDropAfter {
+			return nil
+		}
+		s.logger.WarnContext(ctx, "Ledger transaction dropped without receipt", "tx_hash", entry.TxHash, "kind", entry.Kind)
+		return s.repo.Settle(ctx, entry.ID, entities.LedgerStatusDropped, nil, nil, nil, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		gasPrice, _ = new(big.Int).SetString(entry.GasPrice, 10)
+	}
+	gasUsed := int64(receipt.GasUsed)
+	gasCostWei := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	gasCost := gasCostWei.String()
+	effectiveGasPrice := gasPrice.String()
+
+	status := entities.LedgerStatusConfirmed
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		status = entities.LedgerStatusFailed
+	}
+
+	return s.repo.Settle(ctx, entry.ID, status, &gasUsed, &gasCost, &effectiveGasPrice, s.convertGas(ctx, entry, gasCostWei))
+}
+
+// convertGas converts the gas cost in minimal units of the native coin of the asset's chain (BNB, SOL, TRX)
+// to the rate currency at the current rate. Returns nil when the rate is unavailable, the entry stays unconverted.
+func (s *LedgerService) convertGas(ctx context.Context, entry entities.LedgerEntry, gasCost *big.Int) *entities.LedgerGasConversion {
+	if s.rates == nil || s.rateCurrency == "" {
+		return nil
+	}
+	asset, err := s.assets.FindByID(entry.AssetID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Unknown asset of ledger entry, gas not converted", "error", err, "tx_hash", entry.TxHash)
+		return nil
+	}
+	coin, decimals, err := asset.Chain.NativeCoin()
+	if err != nil {
+		s.logger.WarnContext(ctx, "Unknown native coin of ledger entry, gas not converted", "error", err, "tx_hash", entry.TxHash)
+		return nil
+	}
+	rate, err := s.rates.Rate(ctx, coin, s.rateCurrency)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Native coin rate unavailable, gas not converted", "error", err,
+			"tx_hash", entry.TxHash, "coin", coin, "currency", s.rateCurrency)
+		return nil
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	cost := new(big.Rat).SetFrac(gasCost, scale)
+	cost.Mul(cost, rate)
+
+	return &entities.LedgerGasConversion{
+		GasCurrency:  coin,
+		GasRate:      rate.FloatString(ledgerFiatDecimals),
+		FiatCurrency: s.rateCurrency,
+		GasCostFiat:  cost.FloatString(ledgerFiatDecimals),
+	}
+}
+
+// ParsePnLPeriod validates the grouping step of the report, day by default
+func ParsePnLPeriod(period string) (entities.PnLPeriod, error) {
+	switch p := entities.PnLPeriod(strings.ToLower(strings.TrimSpace(period))); p {
+	case "":
+		return entities.PnLPeriodDay, nil
+	case entities.PnLPeriodDay, entities.PnLPeriodWeek, entities.PnLPeriodMonth:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: unknown period %q", ErrInvalidReportRequest, period)
+	}
+}
+
+// GetPnLReport aggregates the ledger over [from, to) per period and asset. Fees count confirmed transactions only,
+// gas counts every mined transaction because reverted ones are paid as well. Gas in the rate currency uses the rates
+// recorded at execution time; gas in asset units is derived from it unless some transactions were not converted.
+func (s *LedgerService) GetPnLReport(ctx context.Context, from, to time.Time, period entities.PnLPeriod) (*entities.PnLReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidReportRequest)
+	}
+
+	summaries, err := s.repo.Summarize(ctx, from, to, period)
+	if err != nil {
+		return nil, err
+	}
+
+	type totals struct {
+		withdrawals, sweeps                       int
+		volume, fees                              *big.Int
+		sweepGas, withdrawalGas, refundGas, opGas *big.Int
+	}
+
+	var keys []pnlRowKey
+	acc := make(map[pnlRowKey]*totals)
+	for _, summary := range summaries {
+		key := pnlRowKey{period: summary.PeriodStart.UTC(), assetID: summary.AssetID}
+		t, ok := acc[key]
+		if !ok {
+			t = &totals{
+				volume: new(big.Int), fees: new(big.Int),
+				sweepGas: new(big.Int), withdrawalGas: new(big.Int), refundGas: new(big.Int), opGas: new(big.Int),
+			}
+			acc[key] = t
+			keys = append(keys, key)
+		}
+
+		volume, fees, gas, err := parseLedgerSummary(summary)
+		if err != nil {
+			return nil, err
+		}
+		t.fees.Add(t.fees, fees)
+
+		switch summary.Kind {
+		case entities.LedgerKindWithdrawal:
+			t.withdrawals += summary.Transactions
+			t.volume.Add(t.volume, volume)
+			t.withdrawalGas.Add(t.withdrawalGas, gas)
+		case entities.LedgerKindSweep:
+			t.sweeps += summary.Transactions
+			t.sweepGas.Add(t.sweepGas, gas)
+		case entities.LedgerKindRefund:
+			t.refundGas.Add(t.refundGas, gas)
+		default:
+			t.opGas.Add(t.opGas, gas)
+		}
+	}
+
+	gasFiat, err := s.gasFiatTotals(ctx, from, to, period)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entities.PnLReport{
+		From:         from.UTC(),
+		To:           to.UTC(),
+		Period:       period,
+		RateCurrency: s.rateCurrency,
+		Rows:         make([]entities.PnLRow, 0, len(keys)),
+	}
+
+	gasRates := make(map[int]*big.Rat)
+	assetRates := make(map[int]*big.Rat)
+	for _, key := range keys {
+		t := acc[key]
+		asset, err := s.assets.FindByID(key.assetID)
+		if err != nil {
+			return nil, fmt.Errorf("asset %d of ledger entries: %w", key.assetID, err)
+		}
+
+		gas := new(big.Int).Add(t.sweepGas, t.withdrawalGas)
+		gas.Add(gas, t.refundGas).Add(gas, t.opGas)
+
+		row := entities.PnLRow{
+			PeriodStart:      key.period,
+			Asset:            asset.Code,
+			Withdrawals:      t.withdrawals,
+			WithdrawalVolume: unitsToTokenAmount(t.volume, asset.Decimals),
+			FeesCollected:    unitsToTokenAmount(t.fees, asset.Decimals),
+			Sweeps:           t.sweeps,
+			SweepGas:         unitsToTokenAmount(t.sweepGas, bnbDecimals),
+			WithdrawalGas:    unitsToTokenAmount(t.withdrawalGas, bnbDecimals),
+			RefundGas:        unitsToTokenAmount(t.refundGas, bnbDecimals),
+			OperationalGas:   unitsToTokenAmount(t.opGas, bnbDecimals),
+			GasSpent:         unitsToTokenAmount(gas, bnbDecimals),
+		}
+
+		var units *big.Rat
+		if gasFiat != nil {
+			fiat, ok := gasFiat[key]
+			if !ok {
+				fiat = gasFiatTotal{cost: new(big.Rat)}
+			}
+			row.GasSpentFiat = fiat.cost.FloatString(ledgerFiatDecimals)
+			row.GasUnconverted = fiat.unconverted
+
+			if fiat.unconverted == 0 {
+				assetRate, ok := assetRates[asset.ID]
+				if !ok {
+					assetRate = s.assetRate(ctx, asset)
+					assetRates[asset.ID] = assetRate
+				}
+				if assetRate != nil {
+					// валюта учета -> минимальные единицы актива
+					units = new(big.Rat).Quo(fiat.cost, assetRate)
+					units.Mul(units, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.Decimals)), nil)))
+				}
+			}
+		}
+		if units == nil {
+			rate, ok := gasRates[asset.ID]
+			if !ok {
+				rate = s.gasRate(ctx, asset)
+				gasRates[asset.ID] = rate
+			}
+			if rate != nil {
+				// wei BNB -> минимальные единицы актива
+				units = new(big.Rat).Mul(new(big.Rat).SetInt(gas), rate)
+			}
+		}
+		if units != nil {
+			// Округление до ближайшей минимальной единицы
+			gasUnits, _ := new(big.Int).SetString(units.FloatString(0), 10)
+			row.GasSpentInAsset = unitsToTokenAmount(gasUnits, asset.Decimals)
+			row.NetMargin = unitsToTokenAmount(new(big.Int).Sub(t.fees, gasUnits), asset.Decimals)
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	return report, nil
+}
+
+// GetFeeAnalytics aggregates gas paid by transactions mined from [from, to): the average fee per transaction kind
+// (e.g. per sweep), the daily trend per kind and the fees per chain. Used to tune sweep scheduling.
+func (s *LedgerService) GetFeeAnalytics(ctx context.Context, from, to time.Time) (*entities.FeeAnalytics, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidReportRequest)
+	}
+
+	stats, err := s.repo.FeeStats(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type dayKey struct {
+		day  time.Time
+		kind entities.LedgerEntryKind
+	}
+	type chainKey struct {
+		chain   entities.Chain
+		network string
+	}
+
+	byKind := newFeeTotals[entities.LedgerEntryKind]()
+	daily := newFeeTotals[dayKey]()
+	byChain := newFeeTotals[chainKey]()
+	for _, row := range stats {
+		values, err := parseFeeStats(row)
+		if err != nil {
+			return nil, err
+		}
+		byKind.add(row.Kind, row.Transactions, values)
+		daily.add(dayKey{day: row.Day.UTC(), kind: row.Kind}, row.Transactions, values)
+		byChain.add(chainKey{chain: row.Chain, network: row.Network}, row.Transactions, values)
+	}
+
+	analytics := &entities.FeeAnalytics{
+		From:    from,
+		To:      to,
+		ByKind:  make([]entities.FeeAggregate, 0, len(byKind.keys)),
+		Daily:   make([]entities.FeeAggregate, 0, len(daily.keys)),
+		ByChain: make([]entities.FeeAggregate, 0, len(byChain.keys)),
+	}
+	for _, kind := range byKind.keys {
+		aggregate := byKind.totals[kind].aggregate()
+		aggregate.Kind = kind
+		analytics.ByKind = append(analytics.ByKind, aggregate)
+	}
+	for _, key := range daily.keys {
+		aggregate := daily.totals[key].aggregate()
+		day := key.day
+		aggregate.Day = &day
+		aggregate.Kind = key.kind
+		analytics.Daily = append(analytics.Daily, aggregate)
+	}
+	for _, key := range byChain.keys {
+		aggregate := byChain.totals[key].aggregate()
+		aggregate.Chain = key.chain
+		aggregate.Network = key.network
+		analytics.ByChain = append(analytics.ByChain, aggregate)
+	}
+
+	return analytics, nil
+}
+
+// feeTotals накапливает комиссии групп в порядке первого появления ключа
+type feeTotals[K comparable] struct {
+	keys   []K
+	totals map[K]*feeTotal
+}
+
+type feeTotal struct {
+	transactions int
+	gasPriceSum  *big.Int
+	gasCost      *big.Int
+	maxGasCost   *big.Int
+}
+
+func newFeeTotals[K comparable]() *feeTotals[K] {
+	return &feeTotals[K]{totals: make(map[K]*feeTotal)}
+}
+
+func (f *feeTotals[K]) add(key K, transactions int, values [3]*big.Int) {
+	t, ok := f.totals[key]
+	if !ok {
+		t = &feeTotal{gasPriceSum: new(big.Int), gasCost: new(big.Int), maxGasCost: new(big.Int)}
+		f.totals[key] = t
+		f.keys = append(f.keys, key)
+	}
+	t.transactions += transactions
+	t.gasPriceSum.Add(t.gasPriceSum, values[0])
+	t.gasCost.Add(t.gasCost, values[1])
+	if values[2].Cmp(t.maxGasCost) > 0 {
+		t.maxGasCost.Set(values[2])
+	}
+}
+
+func (t *feeTotal) aggregate() entities.FeeAggregate {
+	aggregate := entities.FeeAggregate{
+		Transactions:    t.transactions,
+		TotalFee:        unitsToTokenAmount(t.gasCost, bnbDecimals),
+		AverageFee:      "0",
+		MaxFee:          unitsToTokenAmount(t.maxGasCost, bnbDecimals),
+		AverageGasPrice: "0",
+	}
+	if t.transactions > 0 {
+		count := big.NewInt(int64(t.transactions))
+		aggregate.AverageFee = unitsToTokenAmount(new(big.Int).Quo(t.gasCost, count), bnbDecimals)
+		aggregate.AverageGasPrice = unitsToTokenAmount(new(big.Int).Quo(t.gasPriceSum, count), gweiDecimals)
+	}
+	return aggregate
+}
+
+func parseFeeStats(row entities.FeeStats) ([3]*big.Int, error) {
+	var values [3]*big.Int
+	for i, text := range []string{row.GasPriceSum, row.GasCost, row.MaxGasCost} {
+		value, ok := new(big.Int).SetString(text, 10)
+		if !ok {
+			return values, fmt.Errorf("invalid fee stats sum %q for %s", text, row.Kind)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// pnlRowKey — строка отчета P&L: период и актив
+type pnlRowKey struct {
+	period  time.Time
+	assetID int
+}
+
+// gasFiatTotal — газ строки отчета в валюте учета и число исполненных транзакций без пересчета
+type gasFiatTotal struct {
+	cost        *big.Rat
+	unconverted int
+}
+
+// gasFiatTotals returns the gas in the rate currency per period and asset, or nil when no rate currency is configured
+func (s *LedgerService) gasFiatTotals(ctx context.Context, from, to time.Time, period entities.PnLPeriod) (map[pnlRowKey]gasFiatTotal, error) {
+	if s.rateCurrency == "" {
+		return nil, nil
+	}
+	summaries, err := s.repo.SummarizeGasFiat(ctx, from, to, period, s.rateCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[pnlRowKey]gasFiatTotal, len(summaries))
+	for _, summary := range summaries {
+		cost, ok := new(big.Rat).SetString(summary.GasCostFiat)
+		if !ok {
+			return nil, fmt.Errorf("invalid ledger gas fiat sum %q for asset %d", summary.GasCostFiat, summary.AssetID)
+		}
+		totals[pnlRowKey{period: summary.PeriodStart.UTC(), assetID: summary.AssetID}] = gasFiatTotal{
+			cost:        cost,
+			unconverted: summary.Unconverted,
+		}
+	}
+	return totals, nil
+}
+
+// assetRate returns the rate of one asset unit in the rate currency, or nil when it is unavailable
+func (s *LedgerService) assetRate(ctx context.Context, asset entities.Asset) *big.Rat {
+	if s.rates == nil || s.rateCurrency == "" {
+		return nil
+	}
+	rate, err := s.rates.Rate(ctx, asset.Code, s.rateCurrency)
+	if err != nil {
+		s.logger.DebugContext(ctx, "Asset rate unavailable for P&L report", "asset", asset.Code, "currency", s.rateCurrency, "error", err)
+		return nil
+	}
+	return rate
+}
+
+// gasRate returns the number of minimal asset units per wei of BNB, or nil when rates are unavailable
+func (s *LedgerService) gasRate(ctx context.Context, asset entities.Asset) *big.Rat {
+	if s.rates == nil || s.rateCurrency == "" {
+		return nil
+	}
+	bnbRate, err := s.rates.Rate(ctx, "BNB", s.rateCurrency)
+	if err != nil {
+		s.logger.DebugContext(ctx, "BNB rate unavailable for P&L report", "currency", s.rateCurrency, "error", err)
+		return nil
+	}
+	assetRate := s.assetRate(ctx, asset)
+	if assetRate == nil {
+		return nil
+	}
+
+	assetScale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.Decimals)), nil)
+	bnbScale := new(big.Int).Exp(big.NewInt(10), big.NewInt(bnbDecimals), nil)
+
+	rate := new(big.Rat).Quo(bnbRate, assetRate)
+	return rate.Mul(rate, new(big.Rat).SetFrac(assetScale, bnbScale))
+}
+
+func parseLedgerSummary(summary entities.LedgerSummary) (volume, fees, gas *big.Int, err error) {
+	values := make([]*big.Int, 3)
+	for i, text := range []string{summary.Volume, summary.Fees, summary.GasCost} {
+		value, ok := new(big.Int).SetString(text, 10)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("invalid ledger sum %q for %s", text, summary.Kind)
+		}
+		values[i] = value
+	}
+	return values[0], values[1], values[2], nil
+}
@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// DefaultMempoolDepositTTL — сколько хранить предварительный депозит, если транзакция так и не попала в блок
+const DefaultMempoolDepositTTL = 30 * time.Minute
+
+type MempoolWalletsRepository interface {
+	FindWalletByAddress(ctx context.Context, address string) (*entities.Wallet, error)
+}
+
+var _ MempoolWalletsRepository = (*repository.WalletsRepository)(nil)
+
+// MempoolDepositService хранит депозиты, замеченные в мемпуле, и уведомляет о них пользователей.
+// Сервис не влияет на зачисление средств: транзакции и ордера обновляются только после включения в блок.
+type MempoolDepositService struct {
+	logger   *slog.Logger
+	repo     MempoolWalletsRepository
+	notifier Notifier
+	ttl      time.Duration
+
+	mu       sync.Mutex
+	deposits map[string]entities.MempoolDeposit // tx hash -> deposit
+}
+
+func NewMempoolDepositService(logger *slog.Logger, repo MempoolWalletsRepository, notifier Notifier, ttl time.Duration) *MempoolDepositService {
+	if ttl <= 0 {
+		ttl = DefaultMempoolDepositTTL
+	}
+	return &MempoolDepositService{
+		logger:   logger,
+		repo:     repo,
+		notifier: notifier,
+		ttl:      ttl,
+		deposits: make(map[string]entities.MempoolDeposit),
+	}
+}
+
+// RecordMempoolDeposit сохраняет предварительный депозит и уведомляет владельца кошелька.
+// Повторно замеченные транзакции игнорируются.
+func (s *MempoolDepositService) RecordMempoolDeposit(ctx context.Context, deposit entities.MempoolDeposit) error {
+	s.mu.Lock()
+	s.evictExpiredLocked()
+	_, seen := s.deposits[deposit.TxHash]
+	s.mu.Unlock()
+	if seen {
+		return nil
+	}
+
+	wallet, err := s.repo.FindWalletByAddress(ctx, deposit.To)
+	if err != nil {
+		return fmt.Errorf("failed to find wallet for mempool deposit: %w", err)
+	}
+	if wallet == nil {
+		return nil
+	}
+
+	deposit.UserID = wallet.UserID
+	deposit.Provisional = true
+	if deposit.SeenAt.IsZero() {
+		deposit.SeenAt = time.Now()
+	}
+
+	s.mu.Lock()
+	s.deposits[deposit.TxHash] = deposit
+	s.mu.Unlock()
+
+	s.logger.InfoContext(ctx, "Deposit seen in mempool",
+		"tx_hash", deposit.TxHash,
+		"from", deposit.From,
+		"to", deposit.To,
+		"amount", deposit.Amount,
+		"user_id", deposit.UserID)
+
+	if s.notifier != nil {
+		amount := deposit.Amount
+		if wei, ok := new(big.Int).SetString(deposit.Amount, 10); ok {
+			amount = WeiToEther(wei).StringFixed(2)
+		}
+		message := fmt.Sprintf("Incoming deposit of %s USDT to %s detected, waiting for confirmations", amount, deposit.To)
+		if err := s.notifier.Notify(ctx, deposit.UserID, "Deposit seen in mempool", message); err != nil {
+			s.logger.WarnContext(ctx, "Failed to notify user about mempool deposit",
+				"error", err,
+				"tx_hash", deposit.TxHash,
+				"user_id", deposit.UserID)
+		}
+	}
+
+	return nil
+}
+
+// ForgetMempoolDeposit удаляет предварительный депозит, когда транзакция попала в блок
+func (s *MempoolDepositService) ForgetMempoolDeposit(txHash string) {
+	s.mu.Lock()
+	delete(s.deposits, txHash)
+	s.mu.Unlock()
+}
+
+// GetPendingDeposits возвращает депозиты пользователя, которые ещё не попали в блок
+func (s *MempoolDepositService) GetPendingDeposits(_ context.Context, userID int64) []entities.MempoolDeposit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpiredLocked()
+
+	deposits := make([]entities.MempoolDeposit, 0)
+	for _, deposit := range s.deposits {
+		if deposit.UserID == userID {
+			deposits = append(deposits, deposit)
+		}
+	}
+	sort.Slice(deposits, func(i, j int) bool {
+		return deposits[i].SeenAt.After(deposits[j].SeenAt)
+	})
+
+	return deposits
+}
+
+func (s *MempoolDepositService) evictExpiredLocked() {
+	cutoff := time.Now().Add(-s.ttl)
+	for hash, deposit := range s.deposits {
+		if deposit.SeenAt.Before(cutoff) {
+			delete(s.deposits, hash)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package mocked
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
+)
+
+// BackfillCandles merges candles restored from the candle store or fetched over REST after a feed outage
+// into the in-memory window of the pair.
+// Missing candles are inserted and candles that differ are replaced; the window keeps the latest maxCandleCount.
+// Inserted and corrected candles are sent to subscribers with the next update. Returns the number of changed candles.
+func (s *DataService) BackfillCandles(symbol string, candles []entities.CandleData) (int, error) {
+	pair, ok := s.Pair(symbol)
+	if !ok {
+		return 0, usecases.ErrTradingPairNotFound
+	}
+
+	pair.Mutex.Lock()
+	window := make(map[int64]entities.CandleData, len(pair.CandleData)+len(candles))
+	for _, candle := range pair.CandleData {
+		window[candle.Time] = candle
+	}
+	var changed []entities.CandleData
+	for _, candle := range candles {
+		if existing, ok := window[candle.Time]; ok && existing == candle {
+			continue
+		}
+		window[candle.Time] = candle
+		changed = append(changed, candle)
+	}
+	if len(changed) > 0 {
+		merged := make([]entities.CandleData, 0, len(window))
+		for _, candle := range window {
+			merged = append(merged, candle)
+		}
+		sort.Slice(merged, func(i, j int) bool { return merged[i].Time < merged[j].Time })
+		if len(merged) > maxCandleCount {
+			merged = merged[len(merged)-maxCandleCount:]
+		}
+		pair.CandleData = merged
+
+		// Последняя свеча пары из фида приходит из догрузки, пока поток не прислал более новую
+		if latest := merged[len(merged)-1]; latest.Time >= pair.LastCandle.Time {
+			pair.LastCandle = latest
+			pair.LastPrice = latest.Close
+		}
+	}
+	pair.Mutex.Unlock()
+
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Time < changed[j].Time })
+	s.logger.Info("Backfilled candles", "symbol", symbol, "changed", len(changed),
+		"from", changed[0].Time, "to", changed[len(changed)-1].Time)
+
+	s.queueUpdate(pair, changed)
+
+	return len(changed), nil
+}
+
+// ApplyCandle applies a kline received from the market-data feed: the candle of the same time is replaced,
+// a newer one is appended to the window, and the pair state is sent to subscribers with the next update.
+func (s *DataService) ApplyCandle(symbol string, candle entities.CandleData) error {
+	pair, ok := s.Pair(symbol)
+	if !ok {
+		return usecases.ErrTradingPairNotFound
+	}
+
+	pair.Mutex.Lock()
+	switch last := len(pair.CandleData) - 1; {
+	case last >= 0 && pair.CandleData[last].Time == candle.Time:
+		pair.CandleData[last] = candle
+	case last < 0 || pair.CandleData[last].Time < candle.Time:
+		pair.CandleData = append(pair.CandleData, candle)
+		if len(pair.CandleData) > maxCandleCount {
+			pair.CandleData = pair.CandleData[len(pair.CandleData)-maxCandleCount:]
+		}
+	default:
+		// Запоздавшая свеча из середины окна исправляется догрузкой
+		pair.Mutex.Unlock()
+		_, err := s.BackfillCandles(symbol, []entities.CandleData{candle})
+		return err
+	}
+	if oldPrice := pair.LastPrice; oldPrice > 0 {
+		pair.PriceChange = ((candle.Close - oldPrice) / oldPrice) * percentMultiplier
+	}
+	pair.LastPrice = candle.Close
+	pair.LastCandle = candle
+	pair.Mutex.Unlock()
+
+	s.BroadcastUpdate(pair)
+	s.lastUpdate.Store(time.Now().UnixNano())
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package mocked
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// newTestDataService создает сервис с одной парой без симуляции и одним подписчиком, чтобы обновления попадали в очередь
+func newTestDataService(candles ...entities.CandleData) (*DataService, *entities.TradingPair) {
+	s := NewDataService(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second)
+	pair := NewTradingPair("BTCRUB", btcInitialPrice)
+	pair.CandleData = append(pair.CandleData, candles...)
+	if len(candles) > 0 {
+		pair.LastCandle = candles[len(candles)-1]
+	}
+	s.TradingPairs[pair.Symbol] = pair
+	s.subscribers[&websocket.Conn{}] = &subscriber{symbols: []string{pair.Symbol}}
+	return s, pair
+}
+
+func testCandle(minute int64, price float64) entities.CandleData {
+	return entities.CandleData{Time: minute * 60_000, Open: price, High: price, Low: price, Close: price, Volume: 1}
+}
+
+func TestBackfillCandlesMergesWindow(t *testing.T) {
+	s, pair := newTestDataService(testCandle(1, 100), testCandle(2, 101), testCandle(3, 102))
+
+	changed, err := s.BackfillCandles("BTCRUB", []entities.CandleData{
+		testCandle(2, 101), // совпадает с окном
+		testCandle(3, 103), // исправлена
+		testCandle(5, 105), // пропущена
+		testCandle(4, 104), // пропущена
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, changed)
+
+	assert.Equal(t, []entities.CandleData{
+		testCandle(1, 100), testCandle(2, 101), testCandle(3, 103), testCandle(4, 104), testCandle(5, 105),
+	}, pair.CandleData)
+	assert.Equal(t, testCandle(5, 105), pair.LastCandle)
+
+	pending, _, _ := s.takePending()
+	assert.Equal(t, []entities.CandleData{testCandle(3, 103), testCandle(4, 104), testCandle(5, 105)}, pending["BTCRUB"].Backfill)
+
+	_, err = s.BackfillCandles("ETHRUB", nil)
+	assert.Error(t, err)
+}
+
+// Кадр, отправленный между постановкой обновления и догруженных свечей в очередь, терял свечи
+func TestBackfillCandlesSurviveConcurrentFlush(t *testing.T) {
+	s, _ := newTestDataService()
+	const backfills = 5000
+
+	// Несколько отправителей кадров забирают очередь непрерывно, чтобы попасть между двумя шагами догрузки
+	var mu sync.Mutex
+	received := make(map[int64]bool, backfills)
+	collect := func(pending map[string]pairUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, candle := range pending["BTCRUB"].Backfill {
+			received[candle.Time] = true
+		}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				pending, _, _ := s.takePending()
+				collect(pending)
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	for i := range int64(backfills) {
+		changed, err := s.BackfillCandles("BTCRUB", []entities.CandleData{testCandle(i+1, 100)})
+		require.NoError(t, err)
+		require.Equal(t, 1, changed)
+	}
+	close(done)
+	wg.Wait()
+
+	pending, _, _ := s.takePending()
+	collect(pending)
+	assert.Len(t, received, backfills)
+}
+
+func TestApplyCandle(t *testing.T) {
+	s, pair := newTestDataService(testCandle(1, 100), testCandle(2, 100))
+
+	// Обновление незакрытой свечи заменяет ее, новая свеча добавляется в окно
+	require.NoError(t, s.ApplyCandle("BTCRUB", testCandle(2, 110)))
+	require.NoError(t, s.ApplyCandle("BTCRUB", testCandle(3, 121)))
+	assert.Equal(t, []entities.CandleData{testCandle(1, 100), testCandle(2, 110), testCandle(3, 121)}, pair.CandleData)
+	assert.Equal(t, 121.0, pair.LastPrice)
+	assert.InDelta(t, 10.0, pair.PriceChange, 1e-9)
+
+	// Запоздавшая свеча из середины окна исправляет его и уходит подписчикам как догрузка
+	require.NoError(t, s.ApplyCandle("BTCRUB", testCandle(1, 90)))
+	assert.Equal(t, testCandle(1, 90), pair.CandleData[0])
+	assert.Equal(t, testCandle(3, 121), pair.LastCandle)
+
+	pending, _, _ := s.takePending()
+	assert.Equal(t, testCandle(3, 121), pending["BTCRUB"].LastCandle)
+	assert.Equal(t, []entities.CandleData{testCandle(1, 90)}, pending["BTCRUB"].Backfill)
+}
@@ -2,8 +2,14 @@ package mocked
 
 import (
 	"crypto/rand"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"math/big"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases"
@@ -55,17 +61,74 @@ const (
 	realtimePriceVariationMax = 0.004 // Maximum price variation for real-time updates (0.4%).
 	realtimePriceVariationMin = 0.002 // Minimum price variation for real-time updates (0.2%).
 	percentMultiplier         = 100   // Multiplier to convert decimal to percentage.
+
+	// WebSocket constants.
+	writeTimeout = 5 * time.Second // Deadline for writing one frame to a subscriber.
+
+	// Meta channel events.
+	metaEventPairsUpdated = "pairs_updated" // The list of trading pairs changed.
 )
 
+// pairSymbolPattern matches symbols of pairs added at runtime, e.g. BTCRUB.
+var pairSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{4,20}$`)
+
+// pairUpdate is the state of a pair sent to subscribers.
+type pairUpdate struct {
+	Symbol          string              `json:"symbol"`
+	LastPrice       float64             `json:"lastPrice"`
+	PriceChange     float64             `json:"priceChange"`
+	OrdersPerSecond float64             `json:"ordersPerSecond"`
+	LastCandle      entities.CandleData `json:"lastCandle"`
+	// Candles inserted or corrected by a backfill since the last frame, oldest first.
+	Backfill []entities.CandleData `json:"backfill,omitempty"`
+}
+
+// batchFrame is the frame of a connection subscribed to several pairs: the latest update of every changed pair.
+type batchFrame struct {
+	Updates []pairUpdate `json:"updates"`
+}
+
+// metaEvent is a frame of the meta channel: a change of the service state that is not a price update.
+type metaEvent struct {
+	Type  string   `json:"type"`
+	Pairs []string `json:"pairs,omitempty"`
+}
+
+// subscriber is a WebSocket connection and the pairs it is subscribed to.
+// A connection to a single pair receives its updates as separate frames, as before batching.
+// A meta connection receives meta events only.
+type subscriber struct {
+	symbols []string
+	batched bool
+	meta    bool
+}
+
 type DataService struct {
+	// Pairs are added and disabled at runtime through the admin API, access goes through pairsMu
 	TradingPairs map[string]*entities.TradingPair
+	pairsMu      sync.RWMutex
 	logger       *slog.Logger
+
+	lastUpdate atomic.Int64 // Unix time in nanoseconds of the last price update.
+
+	// Updates are collected per pair and sent every batchInterval, only the latest update of a pair is kept
+	batchInterval time.Duration
+	mu            sync.Mutex
+	subscribers   map[*websocket.Conn]*subscriber
+	pending       map[string]pairUpdate
+	pendingMeta   *metaEvent
+
+	// Pairs served by the market-data feed are not simulated, their candles come from the feed
+	feedSymbols map[string]bool
 }
 
-func NewDataService(logger *slog.Logger) *DataService {
+func NewDataService(logger *slog.Logger, batchInterval time.Duration) *DataService {
 	return &DataService{
-		TradingPairs: make(map[string]*entities.TradingPair),
-		logger:       logger,
+		TradingPairs:  make(map[string]*entities.TradingPair),
+		logger:        logger,
+		batchInterval: batchInterval,
+		subscribers:   make(map[*websocket.Conn]*subscriber),
+		pending:       make(map[string]pairUpdate),
 	}
 }
 
@@ -77,7 +140,6 @@ func NewTradingPair(symbol string, initialPrice float64) *entities.TradingPair {
 		PriceChange:     0,
 		OrdersPerSecond: 0,
 		CandleData:      make([]entities.CandleData, 0),
-		Subscribers:     make(map[*websocket.Conn]bool),
 		StopChan:        make(chan struct{}),
 		LastOrderTime:   time.Now(),
 	}
@@ -97,6 +159,14 @@ func secureFloat64(logger *slog.Logger) float64 {
 	return float64(n.Int64()) / float64(maxVal.Int64())
 }
 
+// SetFeedSymbols marks the pairs whose candles come from the market-data feed, must be called before InitializeTradingPairs.
+func (s *DataService) SetFeedSymbols(symbols []string) {
+	s.feedSymbols = make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		s.feedSymbols[symbol] = true
+	}
+}
+
 // InitializeTradingPairs initializes trading pairs with initial data.
 func (s *DataService) InitializeTradingPairs() {
 	// Create trading pairs with initial prices
@@ -108,10 +178,100 @@ func (s *DataService) InitializeTradingPairs() {
 
 	// Generate initial candle data
 	for _, pair := range s.TradingPairs {
+		// Окно пар из фида заполняется сохраненными и догруженными свечами
+		if s.feedSymbols[pair.Symbol] {
+			continue
+		}
 		s.GenerateInitialCandleData(pair)
 		// Start simulation in a separate goroutine
 		go s.SimulateTradingData(pair)
 	}
+
+	go s.flushUpdates()
+}
+
+// Pair returns the trading pair with the symbol.
+func (s *DataService) Pair(symbol string) (*entities.TradingPair, bool) {
+	s.pairsMu.RLock()
+	defer s.pairsMu.RUnlock()
+	pair, ok := s.TradingPairs[symbol]
+	return pair, ok
+}
+
+// Pairs returns the trading pairs sorted by symbol.
+func (s *DataService) Pairs() []*entities.TradingPair {
+	s.pairsMu.RLock()
+	pairs := make([]*entities.TradingPair, 0, len(s.TradingPairs))
+	for _, pair := range s.TradingPairs {
+		pairs = append(pairs, pair)
+	}
+	s.pairsMu.RUnlock()
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Symbol < pairs[j].Symbol })
+	return pairs
+}
+
+// AddTradingPair starts simulating a new pair and notifies meta subscribers that the pair list changed.
+func (s *DataService) AddTradingPair(symbol string, initialPrice float64) (*entities.TradingPair, error) {
+	if !pairSymbolPattern.MatchString(symbol) {
+		return nil, fmt.Errorf("%w: symbol must be 4 to 20 uppercase letters or digits", usecases.ErrInvalidTradingPair)
+	}
+	if initialPrice <= 0 {
+		return nil, fmt.Errorf("%w: initial price must be positive", usecases.ErrInvalidTradingPair)
+	}
+
+	pair := NewTradingPair(symbol, initialPrice)
+	s.GenerateInitialCandleData(pair)
+
+	s.pairsMu.Lock()
+	if _, ok := s.TradingPairs[symbol]; ok {
+		s.pairsMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", usecases.ErrTradingPairExists, symbol)
+	}
+	s.TradingPairs[symbol] = pair
+	s.pairsMu.Unlock()
+
+	go s.SimulateTradingData(pair)
+
+	s.logger.Info("Trading pair added", "symbol", symbol, "initialPrice", initialPrice)
+	s.notifyPairsUpdated()
+	return pair, nil
+}
+
+// DisableTradingPair stops the pair and removes it from the list. Subscribers of the pair stop receiving updates,
+// meta subscribers are notified that the pair list changed.
+func (s *DataService) DisableTradingPair(symbol string) error {
+	s.pairsMu.Lock()
+	pair, ok := s.TradingPairs[symbol]
+	if !ok {
+		s.pairsMu.Unlock()
+		return usecases.ErrTradingPairNotFound
+	}
+	delete(s.TradingPairs, symbol)
+	s.pairsMu.Unlock()
+
+	close(pair.StopChan)
+
+	s.mu.Lock()
+	delete(s.pending, symbol)
+	s.mu.Unlock()
+
+	s.logger.Info("Trading pair disabled", "symbol", symbol)
+	s.notifyPairsUpdated()
+	return nil
+}
+
+// notifyPairsUpdated queues a pairs_updated event with the current symbols for the next frame of meta subscribers.
+func (s *DataService) notifyPairsUpdated() {
+	pairs := s.Pairs()
+	event := &metaEvent{Type: metaEventPairsUpdated, Pairs: make([]string, 0, len(pairs))}
+	for _, pair := range pairs {
+		event.Pairs = append(event.Pairs, pair.Symbol)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingMeta = event
 }
 
 // updatePriceAndCandle updates the price and current candle.
@@ -263,6 +423,16 @@ func (s *DataService) initializeCurrentCandle(
 func (s *DataService) handlePriceUpdate(pair *entities.TradingPair, currentCandle *entities.CandleData) {
 	s.updatePriceAndCandle(pair, currentCandle)
 	s.BroadcastUpdate(pair)
+	s.lastUpdate.Store(time.Now().UnixNano())
+}
+
+// LastHeartbeat returns the time of the last price update sent to WebSocket subscribers.
+func (s *DataService) LastHeartbeat() time.Time {
+	nanos := s.lastUpdate.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
 }
 
 // handleCandleUpdate handles the candle ticker update.
@@ -314,39 +484,152 @@ func (s *DataService) SimulateTradingData(pair *entities.TradingPair) {
 	}
 }
 
-// BroadcastUpdate sends updates to all subscribers.
+// BroadcastUpdate queues the current state of the pair for the next frame sent to its subscribers.
 func (s *DataService) BroadcastUpdate(pair *entities.TradingPair) {
+	s.queueUpdate(pair, nil)
+}
+
+// queueUpdate queues the current state of the pair together with the backfilled candles.
+// Both are queued under one lock, so a frame flushed in between never carries the update without the candles.
+func (s *DataService) queueUpdate(pair *entities.TradingPair, backfill []entities.CandleData) {
 	pair.Mutex.RLock()
-	defer pair.Mutex.RUnlock()
+	update := pairUpdate{
+		Symbol:          pair.Symbol,
+		LastPrice:       pair.LastPrice,
+		PriceChange:     pair.PriceChange,
+		OrdersPerSecond: pair.OrdersPerSecond,
+		LastCandle:      pair.LastCandle,
+	}
+	pair.Mutex.RUnlock()
 
-	// If there are no subscribers, exit
-	if len(pair.Subscribers) == 0 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// If there are no subscribers, nothing is queued
+	if len(s.subscribers) == 0 {
 		return
 	}
+	// Backfilled candles are kept until the frame is sent, the rest of the update is replaced by the latest state
+	if queued, ok := s.pending[update.Symbol]; ok {
+		update.Backfill = queued.Backfill
+	}
+	update.Backfill = append(update.Backfill, backfill...)
+	s.pending[update.Symbol] = update
+}
 
-	// Prepare data for sending
-	update := map[string]interface{}{
-		"symbol":          pair.Symbol,
-		"lastPrice":       pair.LastPrice,
-		"priceChange":     pair.PriceChange,
-		"ordersPerSecond": pair.OrdersPerSecond,
-		"lastCandle":      pair.LastCandle,
+// takePending returns the queued updates, the meta event and the subscribers to send them to, and resets the queue.
+func (s *DataService) takePending() (map[string]pairUpdate, *metaEvent, map[*websocket.Conn]*subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending
+	s.pending = make(map[string]pairUpdate, len(pending))
+	meta := s.pendingMeta
+	s.pendingMeta = nil
+	subscribers := make(map[*websocket.Conn]*subscriber, len(s.subscribers))
+	for conn, sub := range s.subscribers {
+		subscribers[conn] = sub
 	}
 
-	// Send update to all subscribers
-	for conn := range pair.Subscribers {
-		err := conn.WriteJSON(update)
+	return pending, meta, subscribers
+}
+
+// flushUpdates sends the queued updates every batch interval. It is the only writer to subscriber connections.
+func (s *DataService) flushUpdates() {
+	ticker := time.NewTicker(s.batchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		pending, meta, subscribers := s.takePending()
+		if len(pending) == 0 && meta == nil {
+			continue
+		}
+
+		metaMessage, err := prepareMetaEvent(meta)
 		if err != nil {
-			s.logger.Error("Error sending update to subscriber", "error", err)
-			conn.Close()
-			delete(pair.Subscribers, conn)
+			s.logger.Error("Error encoding meta event", "error", err)
 		}
+
+		// Frame of a single pair is compressed once and shared by all connections to that pair
+		prepared := make(map[string]*websocket.PreparedMessage)
+		for conn, sub := range subscribers {
+			if sub.meta {
+				err = writeMeta(conn, metaMessage)
+			} else {
+				err = s.writeUpdates(conn, sub, pending, prepared)
+			}
+			if err != nil {
+				s.logger.Error("Error sending update to subscriber", "error", err)
+				conn.Close()
+				s.mu.Lock()
+				delete(s.subscribers, conn)
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+func (s *DataService) writeUpdates(conn *websocket.Conn, sub *subscriber, pending map[string]pairUpdate, prepared map[string]*websocket.PreparedMessage) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+
+	if !sub.batched {
+		update, ok := pending[sub.symbols[0]]
+		if !ok {
+			return nil
+		}
+		message, ok := prepared[update.Symbol]
+		if !ok {
+			data, err := json.Marshal(update)
+			if err != nil {
+				return err
+			}
+			if message, err = websocket.NewPreparedMessage(websocket.TextMessage, data); err != nil {
+				return err
+			}
+			prepared[update.Symbol] = message
+		}
+		return conn.WritePreparedMessage(message)
+	}
+
+	var frame batchFrame
+	for _, symbol := range sub.symbols {
+		if update, ok := pending[symbol]; ok {
+			frame.Updates = append(frame.Updates, update)
+		}
+	}
+	if len(frame.Updates) == 0 {
+		return nil
+	}
+	return conn.WriteJSON(frame)
+}
+
+// prepareMetaEvent encodes the event once for all meta subscribers, nil when there is no event.
+func prepareMetaEvent(event *metaEvent) (*websocket.PreparedMessage, error) {
+	if event == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
 	}
+	return websocket.NewPreparedMessage(websocket.TextMessage, data)
+}
+
+func writeMeta(conn *websocket.Conn, message *websocket.PreparedMessage) error {
+	if message == nil {
+		return nil
+	}
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return conn.WritePreparedMessage(message)
 }
 
 // GetCandleData returns candle data for a pair.
 func (s *DataService) GetCandleData(symbol string) ([]entities.CandleData, error) {
-	pair, ok := s.TradingPairs[symbol]
+	pair, ok := s.Pair(symbol)
 	if !ok {
 		return nil, usecases.ErrTradingPairNotFound
 	}
@@ -361,30 +644,55 @@ func (s *DataService) GetCandleData(symbol string) ([]entities.CandleData, error
 	return result, nil
 }
 
-// AddSubscriber adds a subscriber for receiving updates.
+// AddSubscriber subscribes the connection to updates of a single pair, sent as separate frames.
 func (s *DataService) AddSubscriber(symbol string, conn *websocket.Conn) error {
-	pair, ok := s.TradingPairs[symbol]
-	if !ok {
-		return usecases.ErrTradingPairNotFound
+	return s.addSubscriber(conn, &subscriber{symbols: []string{symbol}})
+}
+
+// AddBatchSubscriber subscribes the connection to updates of several pairs, sent together in one frame per batch interval.
+func (s *DataService) AddBatchSubscriber(symbols []string, conn *websocket.Conn) error {
+	unique := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		unique[symbol] = true
 	}
+	sub := &subscriber{symbols: make([]string, 0, len(unique)), batched: true}
+	for symbol := range unique {
+		sub.symbols = append(sub.symbols, symbol)
+	}
+	sort.Strings(sub.symbols)
 
-	pair.Mutex.Lock()
-	defer pair.Mutex.Unlock()
-	pair.Subscribers[conn] = true
-	s.logger.Info("Added subscriber for pair", "symbol", symbol, "totalSubscribers", len(pair.Subscribers))
-	return nil
+	return s.addSubscriber(conn, sub)
 }
 
-// RemoveSubscriber removes a subscriber.
-func (s *DataService) RemoveSubscriber(symbol string, conn *websocket.Conn) error {
-	pair, ok := s.TradingPairs[symbol]
-	if !ok {
+// AddMetaSubscriber subscribes the connection to meta events, such as changes of the pair list.
+func (s *DataService) AddMetaSubscriber(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[conn] = &subscriber{meta: true}
+	s.logger.Info("Added meta subscriber", "totalSubscribers", len(s.subscribers))
+}
+
+func (s *DataService) addSubscriber(conn *websocket.Conn, sub *subscriber) error {
+	if len(sub.symbols) == 0 {
 		return usecases.ErrTradingPairNotFound
 	}
+	for _, symbol := range sub.symbols {
+		if _, ok := s.Pair(symbol); !ok {
+			return usecases.ErrTradingPairNotFound
+		}
+	}
 
-	pair.Mutex.Lock()
-	defer pair.Mutex.Unlock()
-	delete(pair.Subscribers, conn)
-	s.logger.Info("Removed subscriber for pair", "symbol", symbol, "remainingSubscribers", len(pair.Subscribers))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[conn] = sub
+	s.logger.Info("Added subscriber for pairs", "symbols", sub.symbols, "totalSubscribers", len(s.subscribers))
 	return nil
 }
+
+// RemoveSubscriber removes a subscriber.
+func (s *DataService) RemoveSubscriber(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, conn)
+	s.logger.Info("Removed subscriber", "remainingSubscribers", len(s.subscribers))
+}
@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/explorer"
+)
+
+// Notifier доставляет уведомления пользователям (email, push, мессенджеры)
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, subject, message string) error
+}
+
+// ExplorerLinks строит ссылки на обозреватель блоков для транзакций и адресов. Пустая строка — у сети нет обозревателя
+type ExplorerLinks interface {
+	TxURL(chain, network, hash string) string
+	AddressURL(chain, network, address string) string
+}
+
+var _ ExplorerLinks = (*explorer.Links)(nil)
+
+// LogNotifier пишет уведомления в лог. Используется, пока не подключен реальный канал доставки.
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLogNotifier creates a notifier that only logs messages
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, userID int64, subject, message string) error {
+	n.logger.InfoContext(ctx, "User notification",
+		"user_id", userID,
+		"subject", subject,
+		"message", message)
+	return nil
+}
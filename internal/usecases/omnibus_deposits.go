@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type MerchantDepositsRepository interface {
+	FindSettings(ctx context.Context, merchantID int64) (*entities.MerchantDepositSettings, error)
+	FindSettingsByMode(ctx context.Context, mode entities.DepositMode) ([]entities.MerchantDepositSettings, error)
+	SetSettings(ctx context.Context, settings *entities.MerchantDepositSettings) error
+}
+
+type OmnibusWalletsRepository interface {
+	FindWalletByChainAddress(ctx context.Context, chain entities.Chain, network, address string) (*entities.Wallet, error)
+	TrackWallet(ctx context.Context, wallet *entities.Wallet) (int, error)
+	MarkOmnibusWallet(ctx context.Context, id int) error
+}
+
+// OmnibusDepositWallets выдает омнибус кошелек мерчантам в омнибус режиме
+type OmnibusDepositWallets interface {
+	DepositWallet(ctx context.Context, merchantID int64) (int, string, bool)
+}
+
+// OmnibusWalletDeriver выводит горячий кошелек и добавляет его в мониторинг сканера
+type OmnibusWalletDeriver interface {
+	DeriveHDWallet(account int64, index uint32) (*entities.Wallet, error)
+	RememberWallet(address string)
+}
+
+var (
+	_ MerchantDepositsRepository = (*repository.MerchantDepositsRepository)(nil)
+	_ OmnibusWalletsRepository   = (*repository.WalletsRepository)(nil)
+	_ OmnibusWalletDeriver       = (*WalletService)(nil)
+	_ OmnibusDepositWallets      = (*OmnibusDepositService)(nil)
+)
+
+// OmnibusDepositService routes deposits of merchants in the omnibus mode to one hot wallet of the platform.
+// Orders on it are attributed by a unique amount or by a memo, as chosen per merchant. Merchants without
+// settings keep dedicated deposit wallets.
+type OmnibusDepositService struct {
+	logger  *slog.Logger
+	repo    MerchantDepositsRepository
+	wallets OmnibusWalletsRepository
+	deriver OmnibusWalletDeriver
+	audit   *AuditService
+
+	// HD путь горячего кошелька, пусто — омнибус режим отключен
+	walletPath string
+
+	mu     sync.Mutex
+	wallet *entities.Wallet
+}
+
+func NewOmnibusDepositService(logger *slog.Logger, repo MerchantDepositsRepository, wallets OmnibusWalletsRepository, deriver OmnibusWalletDeriver, audit *AuditService, walletPath string) (*OmnibusDepositService, error) {
+	if walletPath != "" {
+		if _, _, err := ParseDerivationPath(walletPath); err != nil {
+			return nil, fmt.Errorf("invalid omnibus wallet path: %w", err)
+		}
+	}
+
+	return &OmnibusDepositService{
+		logger:     logger,
+		repo:       repo,
+		wallets:    wallets,
+		deriver:    deriver,
+		audit:      audit,
+		walletPath: walletPath,
+	}, nil
+}
+
+// Enabled reports whether merchants can be switched to the omnibus mode
+func (s *OmnibusDepositService) Enabled() bool {
+	return s.walletPath != ""
+}
+
+// Wallet returns the omnibus wallet, registering and marking it on first use. It is registered at startup,
+// so calls inside order transactions return the cached wallet and never register it in a rolled back transaction.
+func (s *OmnibusDepositService) Wallet(ctx context.Context) (*entities.Wallet, error) {
+	if !s.Enabled() {
+		return nil, ErrOmnibusDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wallet != nil {
+		return s.wallet, nil
+	}
+
+	account, index, err := ParseDerivationPath(s.walletPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid omnibus wallet path: %w", err)
+	}
+	derived, err := s.deriver.DeriveHDWallet(account, uint32(index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive omnibus wallet: %w", err)
+	}
+
+	wallet, err := s.wallets.FindWalletByChainAddress(ctx, derived.Chain, derived.Network, derived.Address)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		if derived.ID, err = s.wallets.TrackWallet(ctx, derived); err != nil {
+			return nil, fmt.Errorf("failed to register omnibus wallet: %w", err)
+		}
+		wallet = derived
+	}
+	if err = s.wallets.MarkOmnibusWallet(ctx, wallet.ID); err != nil {
+		return nil, err
+	}
+	s.deriver.RememberWallet(wallet.Address)
+
+	s.logger.InfoContext(ctx, "Omnibus deposit wallet registered", "wallet_id", wallet.ID, "address", wallet.Address)
+	s.wallet = wallet
+	return wallet, nil
+}
+
+// DepositWallet returns the omnibus wallet if the merchant is in the omnibus mode. On a lookup error
+// the merchant gets a dedicated wallet: its deposits are still attributed, only not pooled.
+func (s *OmnibusDepositService) DepositWallet(ctx context.Context, merchantID int64) (int, string, bool) {
+	if !s.Enabled() {
+		return 0, "", false
+	}
+
+	settings, err := s.repo.FindSettings(ctx, merchantID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to load merchant deposit settings", "error", err, "merchant_id", merchantID)
+		return 0, "", false
+	}
+	if settings == nil || settings.Mode != entities.DepositModeOmnibus {
+		return 0, "", false
+	}
+
+	wallet, err := s.Wallet(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Omnibus wallet unavailable", "error", err, "merchant_id", merchantID)
+		return 0, "", false
+	}
+	return wallet.ID, wallet.Address, true
+}
+
+// Attribution returns how an order of the merchant on the wallet is attributed, false if the wallet is not
+// the omnibus one. Orders on the omnibus wallet are always attributed, by amount if the merchant has no settings.
+func (s *OmnibusDepositService) Attribution(ctx context.Context, merchantID int64, walletID int) (entities.DepositAttribution, bool, error) {
+	if !s.Enabled() {
+		return "", false, nil
+	}
+
+	wallet, err := s.Wallet(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if wallet.ID != walletID {
+		return "", false, nil
+	}
+
+	settings, err := s.repo.FindSettings(ctx, merchantID)
+	if err != nil {
+		return "", false, err
+	}
+	if settings == nil {
+		return entities.DepositAttributionAmount, true, nil
+	}
+	return settings.Attribution, true, nil
+}
+
+// GetSettings returns the deposit settings of the merchant, dedicated if they are not configured
+func (s *OmnibusDepositService) GetSettings(ctx context.Context, merchantID int64) (*entities.MerchantDepositSettings, error) {
+	settings, err := s.repo.FindSettings(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &entities.MerchantDepositSettings{
+			MerchantID:  merchantID,
+			Mode:        entities.DepositModeDedicated,
+			Attribution: entities.DepositAttributionAmount,
+		}
+	}
+	return settings, nil
+}
+
+// GetOmnibusMerchants returns the settings of merchants in the omnibus mode
+func (s *OmnibusDepositService) GetOmnibusMerchants(ctx context.Context) ([]entities.MerchantDepositSettings, error) {
+	return s.repo.FindSettingsByMode(ctx, entities.DepositModeOmnibus)
+}
+
+// SetSettings switches the deposit mode of the merchant. Pending orders keep their wallet and attribution,
+// the new mode applies to orders created afterwards.
+func (s *OmnibusDepositService) SetSettings(ctx context.Context, merchantID int64, mode entities.DepositMode, attribution entities.DepositAttribution, actor string) (*entities.MerchantDepositSettings, error) {
+	switch mode {
+	case entities.DepositModeDedicated:
+	case entities.DepositModeOmnibus:
+		if !s.Enabled() {
+			return nil, ErrOmnibusDisabled
+		}
+		// Кошелек регистрируется до переключения, чтобы первый ордер мерчанта не упал на его выводе
+		if _, err := s.Wallet(ctx); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidDepositSettings, mode)
+	}
+
+	if attribution == "" {
+		attribution = entities.DepositAttributionAmount
+	}
+	if attribution != entities.DepositAttributionAmount && attribution != entities.DepositAttributionMemo {
+		return nil, fmt.Errorf("%w: unknown attribution %q", ErrInvalidDepositSettings, attribution)
+	}
+
+	settings := &entities.MerchantDepositSettings{
+		MerchantID:  merchantID,
+		Mode:        mode,
+		Attribution: attribution,
+		UpdatedBy:   &actor,
+	}
+	if err := s.repo.SetSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{"mode": mode, "attribution": attribution}
+	if err := s.audit.Record(ctx, entities.AuditEventMerchantDepositModeChanged, actor, strconv.FormatInt(merchantID, 10), details); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record merchant deposit mode change", "error", err, "merchant_id", merchantID)
+	}
+
+	s.logger.InfoContext(ctx, "Merchant deposit mode changed", "merchant_id", merchantID, "mode", mode, "attribution", attribution, "actor", actor)
+	return settings, nil
+}
+
+// SetOmnibusWallets routes deposit wallets of merchants in the omnibus mode to the omnibus wallet
+func (bsc *WalletService) SetOmnibusWallets(omnibus OmnibusDepositWallets) {
+	bsc.omnibus = omnibus
+}
@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type OrderBatchRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type OrderBatchWallets interface {
+	GenerateDepositWallet(ctx context.Context, userID int64) (int, string, error)
+}
+
+type OrderBatchOrders interface {
+	ValidateAmount(amount string) error
+	CreateOrder(ctx context.Context, userID, walletID int, amount string) (*entities.OrderPayment, error)
+}
+
+var (
+	_ OrderBatchRepository = (*repository.OrdersRepository)(nil)
+	_ OrderBatchWallets    = (*WalletService)(nil)
+	_ OrderBatchOrders     = (*OrderService)(nil)
+)
+
+// OrderBatchConfig ограничивает размер пакета и пачки, создаваемой в одной транзакции
+type OrderBatchConfig struct {
+	MaxOrders int
+	ChunkSize int
+}
+
+// OrderBatchService creates many orders of a merchant at once. Every order gets its own deposit wallet,
+// claimed from the wallet pool when possible. Orders are created in chunks, one database transaction
+// per chunk; a failed order is rolled back to its savepoint without affecting the rest of the chunk.
+type OrderBatchService struct {
+	logger  *slog.Logger
+	repo    OrderBatchRepository
+	wallets OrderBatchWallets
+	orders  OrderBatchOrders
+
+	maxOrders int
+	chunkSize int
+}
+
+func NewOrderBatchService(logger *slog.Logger, repo OrderBatchRepository, wallets OrderBatchWallets, orders OrderBatchOrders, config OrderBatchConfig) (*OrderBatchService, error) {
+	if config.MaxOrders <= 0 {
+		return nil, fmt.Errorf("order batch size limit must be positive")
+	}
+	if config.ChunkSize <= 0 {
+		return nil, fmt.Errorf("order batch chunk size must be positive")
+	}
+
+	return &OrderBatchService{
+		logger:    logger,
+		repo:      repo,
+		wallets:   wallets,
+		orders:    orders,
+		maxOrders: config.MaxOrders,
+		chunkSize: config.ChunkSize,
+	}, nil
+}
+
+// CreateOrders creates the orders of the user and returns a result per item in the order of the request.
+// An error is returned only for a batch that is rejected as a whole.
+func (s *OrderBatchService) CreateOrders(ctx context.Context, userID int64, items []entities.OrderBatchItem) ([]entities.OrderBatchResult, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: no orders", ErrInvalidOrderBatch)
+	}
+	if len(items) > s.maxOrders {
+		return nil, fmt.Errorf("%w: %d orders, at most %d are allowed", ErrInvalidOrderBatch, len(items), s.maxOrders)
+	}
+
+	results := make([]entities.OrderBatchResult, len(items))
+	for start := 0; start < len(items); start += s.chunkSize {
+		end := min(start+s.chunkSize, len(items))
+		s.createChunk(ctx, userID, start, items[start:end], results[start:end])
+	}
+
+	created := 0
+	for _, result := range results {
+		if result.Status == entities.OrderBatchItemCreated {
+			created++
+		}
+	}
+	s.logger.InfoContext(ctx, "Order batch processed", "user_id", userID, "orders", len(items), "created", created)
+
+	return results, nil
+}
+
+// createChunk создает пачку ордеров в одной транзакции. Если транзакцию не удалось зафиксировать,
+// ни один ордер пачки не создан.
+func (s *OrderBatchService) createChunk(ctx context.Context, userID int64, offset int, items []entities.OrderBatchItem, results []entities.OrderBatchResult) {
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		for i, item := range items {
+			results[i] = s.createItem(txCtx, userID, offset+i, item)
+		}
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	s.logger.ErrorContext(ctx, "Failed to commit order batch chunk", "error", err, "user_id", userID, "offset", offset, "size", len(items))
+	for i, item := range items {
+		results[i] = entities.OrderBatchResult{
+			Index:     offset + i,
+			Reference: item.Reference,
+			Status:    entities.OrderBatchItemFailed,
+			Error:     err.Error(),
+		}
+	}
+}
+
+// createItem создает ордер позиции в точке сохранения: ошибка откатывает только эту позицию
+func (s *OrderBatchService) createItem(ctx context.Context, userID int64, index int, item entities.OrderBatchItem) entities.OrderBatchResult {
+	result := entities.OrderBatchResult{
+		Index:     index,
+		Reference: item.Reference,
+		Status:    entities.OrderBatchItemFailed,
+	}
+
+	// Лимиты актива проверяем до выдачи кошелька
+	if err := s.orders.ValidateAmount(item.Amount); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		walletID, address, err := s.wallets.GenerateDepositWallet(txCtx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to allocate wallet: %w", err)
+		}
+
+		payment, err := s.orders.CreateOrder(txCtx, int(userID), walletID, item.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.WalletID = walletID
+		result.Wallet = address
+		result.PayAmount = payment.Amount
+		result.Memo = payment.Memo
+		return nil
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to create batch order", "error", err, "user_id", userID, "index", index, "reference", item.Reference)
+		return entities.OrderBatchResult{
+			Index:     index,
+			Reference: item.Reference,
+			Status:    entities.OrderBatchItemFailed,
+			Error:     err.Error(),
+		}
+	}
+
+	result.Status = entities.OrderBatchItemCreated
+	return result
+}
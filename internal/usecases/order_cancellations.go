@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type OrderCancellationRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	CancelOrder(ctx context.Context, orderID int, userID int64, reason entities.OrderCancelReason) (*entities.OrderCancellation, error)
+	FindOrderByID(ctx context.Context, orderID int) (*entities.Order, error)
+}
+
+// OrderWalletPool возвращает в пул кошелек отмененного ордера
+type OrderWalletPool interface {
+	Release(ctx context.Context, walletID int) (bool, error)
+}
+
+var (
+	_ OrderCancellationRepository = (*repository.OrdersRepository)(nil)
+	_ OrderWalletPool             = (*WalletPoolService)(nil)
+)
+
+// OrderCancellationService lets users cancel their unpaid orders with a reason code. The wallet of a cancelled
+// order goes back to the pool if it came from the pool and was never used, and the user is notified.
+type OrderCancellationService struct {
+	logger   *slog.Logger
+	repo     OrderCancellationRepository
+	pool     OrderWalletPool
+	notifier Notifier
+}
+
+func NewOrderCancellationService(logger *slog.Logger, repo OrderCancellationRepository, pool OrderWalletPool, notifier Notifier) *OrderCancellationService {
+	return &OrderCancellationService{
+		logger:   logger,
+		repo:     repo,
+		pool:     pool,
+		notifier: notifier,
+	}
+}
+
+// CancelOrder cancels a pending order of the user that has no deposit in progress
+func (s *OrderCancellationService) CancelOrder(ctx context.Context, userID int64, orderID int, reason entities.OrderCancelReason) (*entities.OrderCancellation, error) {
+	if !slices.Contains(entities.OrderCancelReasons, reason) {
+		return nil, fmt.Errorf("%w: %q, expected one of %v", ErrInvalidCancelReason, reason, entities.OrderCancelReasons)
+	}
+
+	var cancellation *entities.OrderCancellation
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		cancellation, err = s.repo.CancelOrder(txCtx, orderID, userID, reason)
+		if err != nil {
+			return err
+		}
+		if cancellation == nil {
+			return s.notCancelledError(txCtx, userID, orderID)
+		}
+
+		// Освобождение кошелька в той же транзакции: кошелек не уйдет в пул, если отмена не сохранится
+		cancellation.WalletReleased, err = s.pool.Release(txCtx, cancellation.WalletID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Order #%d was cancelled, reason: %s.", cancellation.OrderID, cancellation.Reason)
+	if err = s.notifier.Notify(ctx, userID, "Order cancelled", message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to notify user about order cancellation", "error", err, "order_id", orderID)
+	}
+
+	return cancellation, nil
+}
+
+// notCancelledError объясняет, почему ордер не был отменен
+func (s *OrderCancellationService) notCancelledError(ctx context.Context, userID int64, orderID int) error {
+	order, err := s.repo.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order == nil || int64(order.UserID) != userID {
+		return ErrOrderNotFound
+	}
+	if order.Status != entities.OrderStatusPending {
+		return fmt.Errorf("%w: order is %s", ErrOrderNotPending, order.Status)
+	}
+	return ErrOrderPaymentPending
+}
@@ -0,0 +1,226 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/webhook"
+)
+
+// orderCompletionBatchSize — сколько оплаченных ордеров обрабатывается за один проход
+const orderCompletionBatchSize = 100
+
+type OrderCompletionsRepository interface {
+	FindPendingCompletions(ctx context.Context, limit int) ([]entities.OrderCompletedEvent, error)
+	MarkProcessed(ctx context.Context, orderID int) error
+	MarkAttemptFailed(ctx context.Context, orderID int, reason string, giveUp bool) error
+	FindCompletion(ctx context.Context, orderID int) (*entities.OrderCompletedEvent, error)
+}
+
+// SettlementAccruals пересчитывает начисления мерчанта к следующей выплате
+type SettlementAccruals interface {
+	RefreshAccrual(ctx context.Context, merchantID int64) error
+}
+
+// OrderEventPublisher доставляет события ордеров подключенным клиентам мерчанта
+type OrderEventPublisher interface {
+	PublishOrderCompleted(ctx context.Context, event entities.OrderCompletedEvent)
+}
+
+// OrderCompletionWebhook отправляет подписанное событие на webhook
+type OrderCompletionWebhook interface {
+	Send(ctx context.Context, url string, body []byte, signature string) error
+}
+
+// SweepScheduler запускает внеочередной свип депозитных кошельков
+type SweepScheduler interface {
+	Schedule()
+}
+
+// ReceiptSender отправляет квитанции из очереди без ожидания следующего периода
+type ReceiptSender interface {
+	Wake()
+}
+
+var (
+	_ OrderCompletionsRepository = (*repository.OrderCompletionsRepository)(nil)
+	_ SettlementAccruals         = (*SettlementService)(nil)
+	_ OrderCompletionWebhook     = (*webhook.Client)(nil)
+	_ SweepScheduler             = (*SweepService)(nil)
+	_ ReceiptSender              = (*ReceiptService)(nil)
+)
+
+// OrderCompletionConfig задает период обработки оплаченных ордеров и webhook событий. Пустой WebhookURL отключает webhook.
+type OrderCompletionConfig struct {
+	Interval      time.Duration
+	MaxAttempts   int
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// OrderCompletionService runs the post-completion pipeline for paid orders. Orders are queued in the same transaction
+// that completes them; for each one the merchant accrual is refreshed, the event is published to connected clients
+// and posted to the webhook. A failed step retries the whole order, so clients may see an event more than once.
+// After a pass with paid orders a sweep is scheduled and queued receipts are sent.
+type OrderCompletionService struct {
+	logger   *slog.Logger
+	repo     OrderCompletionsRepository
+	accruals SettlementAccruals
+	events   OrderEventPublisher
+	webhook  OrderCompletionWebhook
+	assets   ActivityAssets
+	sweeps   SweepScheduler
+	receipts ReceiptSender
+
+	interval      time.Duration
+	maxAttempts   int
+	webhookURL    string
+	webhookSecret string
+}
+
+func NewOrderCompletionService(
+	logger *slog.Logger,
+	repo OrderCompletionsRepository,
+	accruals SettlementAccruals,
+	events OrderEventPublisher,
+	webhookClient OrderCompletionWebhook,
+	assets ActivityAssets,
+	receipts ReceiptSender,
+	config OrderCompletionConfig,
+) (*OrderCompletionService, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("order completion interval must be positive")
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, errors.New("order completion attempts must be positive")
+	}
+	if config.WebhookURL != "" && config.WebhookSecret == "" {
+		return nil, errors.New("order completion webhook requires a signing secret")
+	}
+
+	return &OrderCompletionService{
+		logger:        logger,
+		repo:          repo,
+		accruals:      accruals,
+		events:        events,
+		webhook:       webhookClient,
+		assets:        assets,
+		receipts:      receipts,
+		interval:      config.Interval,
+		maxAttempts:   config.MaxAttempts,
+		webhookURL:    config.WebhookURL,
+		webhookSecret: config.WebhookSecret,
+	}, nil
+}
+
+// SetSweepScheduler schedules a sweep after orders were paid. Without it deposit wallets are swept on their interval only.
+func (s *OrderCompletionService) SetSweepScheduler(sweeps SweepScheduler) {
+	s.sweeps = sweeps
+}
+
+// Start periodically processes paid orders until ctx is cancelled
+func (s *OrderCompletionService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.ProcessPending(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ProcessPending(ctx)
+		}
+	}
+}
+
+// ProcessPending runs the pipeline for queued paid orders. An order whose step failed is retried
+// on the next pass until the attempts are exhausted.
+func (s *OrderCompletionService) ProcessPending(ctx context.Context) {
+	events, err := s.repo.FindPendingCompletions(ctx, orderCompletionBatchSize)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to find pending order completions", "error", err)
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err = s.process(ctx, event)
+		if err == nil {
+			if err = s.repo.MarkProcessed(ctx, event.OrderID); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to mark order completion processed", "error", err, "order_id", event.OrderID)
+			}
+			continue
+		}
+
+		giveUp := event.Attempts+1 >= s.maxAttempts
+		s.logger.WarnContext(ctx, "Order completion step failed", "error", err, "order_id", event.OrderID,
+			"attempt", event.Attempts+1, "give_up", giveUp)
+		if err = s.repo.MarkAttemptFailed(ctx, event.OrderID, err.Error(), giveUp); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record order completion error", "error", err, "order_id", event.OrderID)
+		}
+	}
+
+	// Квитанции уже в очереди: ставятся вместе с завершением ордера
+	s.receipts.Wake()
+	if s.sweeps != nil {
+		s.sweeps.Schedule()
+	}
+}
+
+// ReplayWebhook posts the completion event of the order to the webhook again without rerunning the pipeline
+func (s *OrderCompletionService) ReplayWebhook(ctx context.Context, orderID int) error {
+	if s.webhookURL == "" {
+		return fmt.Errorf("%w: order completion webhook is disabled", ErrRunbookNotApplicable)
+	}
+
+	event, err := s.repo.FindCompletion(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return ErrOrderNotFound
+	}
+	if event.Asset == "" {
+		event.Asset = s.assets.Default().Code
+	}
+
+	return s.sendWebhook(ctx, *event)
+}
+
+func (s *OrderCompletionService) process(ctx context.Context, event entities.OrderCompletedEvent) error {
+	if event.Asset == "" {
+		event.Asset = s.assets.Default().Code
+	}
+
+	if err := s.accruals.RefreshAccrual(ctx, event.MerchantID); err != nil {
+		return fmt.Errorf("failed to refresh merchant accrual: %w", err)
+	}
+
+	s.events.PublishOrderCompleted(ctx, event)
+
+	if s.webhookURL == "" {
+		return nil
+	}
+	return s.sendWebhook(ctx, event)
+}
+
+func (s *OrderCompletionService) sendWebhook(ctx context.Context, event entities.OrderCompletedEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode order event: %w", err)
+	}
+	return s.webhook.Send(ctx, s.webhookURL, payload, webhook.Sign(s.webhookSecret, payload))
+}
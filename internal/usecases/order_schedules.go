@@ -0,0 +1,356 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/cron"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+type OrderSchedulesRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateSchedule(ctx context.Context, schedule *entities.OrderSchedule) error
+	CountOpenSchedules(ctx context.Context, userID int64) (int, error)
+	FindByUser(ctx context.Context, userID int64) ([]entities.OrderSchedule, error)
+	FindUserSchedule(ctx context.Context, id int, userID int64) (*entities.OrderSchedule, error)
+	FindDueScheduleIDs(ctx context.Context, now time.Time, limit int) ([]int, error)
+	LockDueSchedule(ctx context.Context, id int, now time.Time) (*entities.OrderSchedule, error)
+	RecordRun(ctx context.Context, id int, ranAt time.Time, nextRunAt *time.Time, status entities.OrderScheduleStatus) error
+	RecordRunError(ctx context.Context, id int, reason string) error
+	UpdateStatus(ctx context.Context, id int, userID int64, from []entities.OrderScheduleStatus,
+		status entities.OrderScheduleStatus, nextRunAt *time.Time) (*entities.OrderSchedule, error)
+}
+
+var _ OrderSchedulesRepository = (*repository.OrderSchedulesRepository)(nil)
+
+// Расписаний, обрабатываемых за один проход планировщика
+const orderScheduleBatchSize = 100
+
+// OrderScheduleConfig задает период проверки расписаний и лимит открытых расписаний на пользователя
+type OrderScheduleConfig struct {
+	Interval   time.Duration
+	MaxPerUser int
+}
+
+// OrderScheduleRequest is a template of the recurring order
+type OrderScheduleRequest struct {
+	Amount         string
+	Cron           string
+	Description    string
+	MaxOccurrences *int
+}
+
+// OrderScheduleService creates orders from stored templates on a cron schedule, e.g. a monthly invoice.
+// Every occurrence gets its own deposit wallet like a regular order and the user is notified about it.
+type OrderScheduleService struct {
+	logger   *slog.Logger
+	repo     OrderSchedulesRepository
+	wallets  OrderBatchWallets
+	orders   OrderBatchOrders
+	notifier Notifier
+
+	interval   time.Duration
+	maxPerUser int
+}
+
+func NewOrderScheduleService(
+	logger *slog.Logger,
+	repo OrderSchedulesRepository,
+	wallets OrderBatchWallets,
+	orders OrderBatchOrders,
+	notifier Notifier,
+	config OrderScheduleConfig,
+) (*OrderScheduleService, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("order schedule interval must be positive")
+	}
+	if config.MaxPerUser <= 0 {
+		return nil, fmt.Errorf("order schedule limit must be positive")
+	}
+
+	return &OrderScheduleService{
+		logger:     logger,
+		repo:       repo,
+		wallets:    wallets,
+		orders:     orders,
+		notifier:   notifier,
+		interval:   config.Interval,
+		maxPerUser: config.MaxPerUser,
+	}, nil
+}
+
+// CreateSchedule validates the template and stores an active schedule with its first run
+func (s *OrderScheduleService) CreateSchedule(ctx context.Context, userID int64, req OrderScheduleRequest) (*entities.OrderSchedule, error) {
+	if err := s.orders.ValidateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	amount, err := decimal.Parse(req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid amount %q", ErrInvalidOrderSchedule, req.Amount)
+	}
+
+	schedule, err := cron.Parse(req.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOrderSchedule, err)
+	}
+	if req.MaxOccurrences != nil && *req.MaxOccurrences <= 0 {
+		return nil, fmt.Errorf("%w: max_occurrences must be positive", ErrInvalidOrderSchedule)
+	}
+
+	next := schedule.Next(time.Now().UTC())
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: schedule %q never runs", ErrInvalidOrderSchedule, req.Cron)
+	}
+
+	count, err := s.repo.CountOpenSchedules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= s.maxPerUser {
+		return nil, fmt.Errorf("%w: at most %d active or paused schedules are allowed", ErrTooManyOrderSchedules, s.maxPerUser)
+	}
+
+	orderSchedule := &entities.OrderSchedule{
+		UserID:         userID,
+		Amount:         amount,
+		Description:    strings.TrimSpace(req.Description),
+		Cron:           schedule.String(),
+		Status:         entities.OrderScheduleActive,
+		NextRunAt:      &next,
+		MaxOccurrences: req.MaxOccurrences,
+	}
+	if err = s.repo.CreateSchedule(ctx, orderSchedule); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Order schedule created", "schedule_id", orderSchedule.ID, "user_id", userID,
+		"cron", orderSchedule.Cron, "next_run_at", next)
+
+	return orderSchedule, nil
+}
+
+// GetUserSchedules returns schedules of the user
+func (s *OrderScheduleService) GetUserSchedules(ctx context.Context, userID int64) ([]entities.OrderSchedule, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+// PauseSchedule stops creating orders until the schedule is resumed
+func (s *OrderScheduleService) PauseSchedule(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error) {
+	schedule, err := s.repo.UpdateStatus(ctx, id, userID,
+		[]entities.OrderScheduleStatus{entities.OrderScheduleActive}, entities.OrderSchedulePaused, nil)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, s.statusError(ctx, userID, id)
+	}
+
+	s.logger.InfoContext(ctx, "Order schedule paused", "schedule_id", id, "user_id", userID)
+	return schedule, nil
+}
+
+// ResumeSchedule reactivates a paused schedule. Activations missed while paused are skipped,
+// the next order is created at the next activation after now.
+func (s *OrderScheduleService) ResumeSchedule(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error) {
+	schedule, err := s.repo.FindUserSchedule(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrOrderScheduleNotFound
+	}
+	if schedule.Status != entities.OrderSchedulePaused {
+		return nil, fmt.Errorf("%w: schedule is %s", ErrOrderScheduleNotAllowed, schedule.Status)
+	}
+
+	parsed, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored schedule %d: %w", id, err)
+	}
+
+	status, next := entities.OrderScheduleActive, parsed.Next(time.Now().UTC())
+	var nextRunAt *time.Time
+	if next.IsZero() {
+		status = entities.OrderScheduleFinished
+	} else {
+		nextRunAt = &next
+	}
+
+	schedule, err = s.repo.UpdateStatus(ctx, id, userID,
+		[]entities.OrderScheduleStatus{entities.OrderSchedulePaused}, status, nextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, s.statusError(ctx, userID, id)
+	}
+
+	s.logger.InfoContext(ctx, "Order schedule resumed", "schedule_id", id, "user_id", userID, "status", status, "next_run_at", nextRunAt)
+	return schedule, nil
+}
+
+// CancelSchedule stops the schedule for good, orders already created are not affected
+func (s *OrderScheduleService) CancelSchedule(ctx context.Context, userID int64, id int) (*entities.OrderSchedule, error) {
+	schedule, err := s.repo.UpdateStatus(ctx, id, userID,
+		[]entities.OrderScheduleStatus{entities.OrderScheduleActive, entities.OrderSchedulePaused}, entities.OrderScheduleCancelled, nil)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, s.statusError(ctx, userID, id)
+	}
+
+	s.logger.InfoContext(ctx, "Order schedule cancelled", "schedule_id", id, "user_id", userID)
+	return schedule, nil
+}
+
+// statusError объясняет, почему статус расписания не изменился: его нет или оно в неподходящем статусе
+func (s *OrderScheduleService) statusError(ctx context.Context, userID int64, id int) error {
+	schedule, err := s.repo.FindUserSchedule(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if schedule == nil {
+		return ErrOrderScheduleNotFound
+	}
+	return fmt.Errorf("%w: schedule is %s", ErrOrderScheduleNotAllowed, schedule.Status)
+}
+
+// Start periodically creates orders of due schedules until ctx is cancelled
+func (s *OrderScheduleService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.RunDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunDue(ctx)
+		}
+	}
+}
+
+// RunDue creates an order for every due schedule. Schedules are locked one by one,
+// so several instances of the service do not create the same occurrence twice.
+func (s *OrderScheduleService) RunDue(ctx context.Context) {
+	now := time.Now().UTC()
+	ids, err := s.repo.FindDueScheduleIDs(ctx, now, orderScheduleBatchSize)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to find due order schedules", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		if err = s.runSchedule(ctx, id, now); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to run order schedule", "error", err, "schedule_id", id)
+		}
+	}
+}
+
+// scheduledOrder — ордер, созданный по расписанию, для уведомления после фиксации транзакции
+type scheduledOrder struct {
+	walletID int
+	wallet   string
+	payment  *entities.OrderPayment
+}
+
+func (s *OrderScheduleService) runSchedule(ctx context.Context, id int, now time.Time) error {
+	var (
+		schedule  *entities.OrderSchedule
+		created   *scheduledOrder
+		runErr    error
+		firstFail bool
+	)
+
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		schedule, err = s.repo.LockDueSchedule(txCtx, id, now)
+		if err != nil || schedule == nil {
+			return err
+		}
+
+		parsed, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return fmt.Errorf("failed to parse stored schedule: %w", err)
+		}
+
+		// Кошелек и ордер создаются в точке сохранения: при ошибке расписание остается на месте
+		// и попытка повторяется на следующем проходе
+		runErr = s.repo.WithinTransaction(txCtx, func(itemCtx context.Context) error {
+			walletID, address, err := s.wallets.GenerateDepositWallet(itemCtx, schedule.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to allocate wallet: %w", err)
+			}
+
+			payment, err := s.orders.CreateOrder(itemCtx, int(schedule.UserID), walletID, schedule.Amount.String())
+			if err != nil {
+				return err
+			}
+
+			created = &scheduledOrder{walletID: walletID, wallet: address, payment: payment}
+			return nil
+		})
+		if runErr != nil {
+			firstFail = schedule.LastError == nil
+			return s.repo.RecordRunError(txCtx, id, runErr.Error())
+		}
+
+		// Пропущенные активации (например, после простоя сервиса) не догоняются:
+		// следующий ордер создается при ближайшей активации после текущего момента
+		status, next := entities.OrderScheduleActive, parsed.Next(now)
+		nextRunAt := &next
+		if next.IsZero() || (schedule.MaxOccurrences != nil && schedule.Occurrences+1 >= *schedule.MaxOccurrences) {
+			status, nextRunAt = entities.OrderScheduleFinished, nil
+		}
+		schedule.Status = status
+		return s.repo.RecordRun(txCtx, id, now, nextRunAt, status)
+	})
+	if err != nil {
+		return err
+	}
+	if schedule == nil {
+		return nil
+	}
+
+	if runErr != nil {
+		s.logger.WarnContext(ctx, "Failed to create scheduled order", "error", runErr, "schedule_id", id, "user_id", schedule.UserID)
+		// Пользователь узнает о сбое один раз, а не на каждой повторной попытке
+		if firstFail {
+			message := fmt.Sprintf("Recurring order #%d could not be created and will be retried: %v", id, runErr)
+			if err = s.notifier.Notify(ctx, schedule.UserID, "Recurring order failed", message); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to notify user about scheduled order failure", "error", err, "schedule_id", id)
+			}
+		}
+		return nil
+	}
+
+	s.logger.InfoContext(ctx, "Scheduled order created", "schedule_id", id, "user_id", schedule.UserID,
+		"wallet_id", created.walletID, "pay_amount", created.payment.Amount, "status", schedule.Status)
+
+	message := fmt.Sprintf("Recurring order #%d created: pay %s to %s", id, created.payment.Amount, created.wallet)
+	if created.payment.Memo != "" {
+		message += fmt.Sprintf(" with memo %s", created.payment.Memo)
+	}
+	if schedule.Description != "" {
+		message += fmt.Sprintf(" (%s)", schedule.Description)
+	}
+	if schedule.Status == entities.OrderScheduleFinished {
+		message += ". This was the last order of the schedule"
+	}
+	if err = s.notifier.Notify(ctx, schedule.UserID, "Recurring order created", message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to notify user about scheduled order", "error", err, "schedule_id", id)
+	}
+
+	return nil
+}
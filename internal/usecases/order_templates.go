@@ -0,0 +1,244 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+const (
+	maxOrderTemplateNameLength = 100
+	maxCallbackURLLength       = 2048
+)
+
+type OrderTemplatesRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateTemplate(ctx context.Context, template *entities.OrderTemplate) error
+	FindByUser(ctx context.Context, userID int64) ([]entities.OrderTemplate, error)
+	FindUserTemplate(ctx context.Context, id int, userID int64) (*entities.OrderTemplate, error)
+	ArchiveTemplate(ctx context.Context, id int, userID int64) (bool, error)
+	LinkOrder(ctx context.Context, orderID, templateID int) error
+}
+
+type OrderTemplateOrders interface {
+	CreateAssetOrder(ctx context.Context, userID, walletID int, asset entities.Asset, amount string) (*entities.OrderPayment, error)
+}
+
+// OrderTemplateAssets — активы обслуживаемой сети: у них есть депозитные кошельки и платежные ссылки
+type OrderTemplateAssets interface {
+	Find(code string) (entities.Asset, error)
+	FindByID(id int) (entities.Asset, error)
+	ValidateOrderAmount(asset entities.Asset, amount string) error
+}
+
+var (
+	_ OrderTemplatesRepository = (*repository.OrderTemplatesRepository)(nil)
+	_ OrderTemplateOrders      = (*OrderService)(nil)
+	_ OrderTemplateAssets      = (*AssetRegistry)(nil)
+)
+
+// OrderTemplateRequest — параметры шаблона, задаваемые мерчантом
+type OrderTemplateRequest struct {
+	Name        string
+	Asset       string
+	Amount      string
+	Description string
+	CallbackURL string
+}
+
+// OrderTemplateService stores reusable order templates of merchants and creates orders with their
+// payment links from a template in one call
+type OrderTemplateService struct {
+	logger   *slog.Logger
+	repo     OrderTemplatesRepository
+	wallets  OrderBatchWallets
+	orders   OrderTemplateOrders
+	payments InvoicePaymentLinks
+	assets   OrderTemplateAssets
+}
+
+func NewOrderTemplateService(
+	logger *slog.Logger,
+	repo OrderTemplatesRepository,
+	wallets OrderBatchWallets,
+	orders OrderTemplateOrders,
+	payments InvoicePaymentLinks,
+	assets OrderTemplateAssets,
+) *OrderTemplateService {
+	return &OrderTemplateService{
+		logger:   logger,
+		repo:     repo,
+		wallets:  wallets,
+		orders:   orders,
+		payments: payments,
+		assets:   assets,
+	}
+}
+
+// CreateTemplate validates and stores the template of the user
+func (s *OrderTemplateService) CreateTemplate(ctx context.Context, userID int64, req OrderTemplateRequest) (*entities.OrderTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxOrderTemplateNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidOrderTemplate, maxOrderTemplateNameLength)
+	}
+
+	asset, err := s.assets.Find(req.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &entities.OrderTemplate{
+		UserID:      userID,
+		Name:        name,
+		AssetID:     asset.ID,
+		Asset:       asset.Code,
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	if req.Amount != "" {
+		if err = s.assets.ValidateOrderAmount(asset, req.Amount); err != nil {
+			return nil, err
+		}
+		amount, err := decimal.Parse(req.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid amount %q", ErrInvalidOrderTemplate, req.Amount)
+		}
+		template.Amount = &amount
+	}
+
+	if req.CallbackURL != "" {
+		if err = validateCallbackURL(req.CallbackURL); err != nil {
+			return nil, err
+		}
+		template.CallbackURL = &req.CallbackURL
+	}
+
+	if err = s.repo.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Order template created", "template_id", template.ID, "user_id", userID, "asset", asset.Code)
+
+	return template, nil
+}
+
+// GetUserTemplates returns templates of the user that are not archived
+func (s *OrderTemplateService) GetUserTemplates(ctx context.Context, userID int64) ([]entities.OrderTemplate, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+// GetTemplate returns the template of the user
+func (s *OrderTemplateService) GetTemplate(ctx context.Context, userID int64, id int) (*entities.OrderTemplate, error) {
+	template, err := s.repo.FindUserTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrOrderTemplateNotFound
+	}
+	return template, nil
+}
+
+// ArchiveTemplate stops using the template for new orders, orders already created from it are not affected
+func (s *OrderTemplateService) ArchiveTemplate(ctx context.Context, userID int64, id int) error {
+	archived, err := s.repo.ArchiveTemplate(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !archived {
+		return ErrOrderTemplateNotFound
+	}
+
+	s.logger.InfoContext(ctx, "Order template archived", "template_id", id, "user_id", userID)
+	return nil
+}
+
+// CreateOrder creates an order with a new deposit wallet from the template and returns its payment details.
+// amount is required only for templates without an amount and must be empty otherwise.
+func (s *OrderTemplateService) CreateOrder(ctx context.Context, userID int64, id int, amount string) (*entities.TemplateOrder, error) {
+	template, err := s.GetTemplate(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.ArchivedAt != nil {
+		return nil, ErrOrderTemplateNotFound
+	}
+
+	switch {
+	case template.Amount != nil && amount != "":
+		return nil, fmt.Errorf("%w: template %d has a fixed amount", ErrInvalidOrderTemplate, id)
+	case template.Amount != nil:
+		amount = template.Amount.String()
+	case amount == "":
+		return nil, fmt.Errorf("%w: amount is required for template %d", ErrInvalidOrderTemplate, id)
+	}
+
+	asset, err := s.assets.FindByID(template.AssetID)
+	if err != nil {
+		return nil, fmt.Errorf("asset of template %d: %w", id, err)
+	}
+	// Лимиты актива проверяем до выдачи кошелька, они могли измениться после создания шаблона
+	if err = s.assets.ValidateOrderAmount(asset, amount); err != nil {
+		return nil, err
+	}
+
+	order := &entities.TemplateOrder{
+		TemplateID:  template.ID,
+		Asset:       asset.Code,
+		Description: template.Description,
+		CallbackURL: template.CallbackURL,
+	}
+	err = s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		walletID, address, err := s.wallets.GenerateDepositWallet(txCtx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to allocate wallet: %w", err)
+		}
+
+		payment, err := s.orders.CreateAssetOrder(txCtx, int(userID), walletID, asset, amount)
+		if err != nil {
+			return err
+		}
+		if err = s.repo.LinkOrder(txCtx, payment.OrderID, template.ID); err != nil {
+			return err
+		}
+
+		order.OrderID = payment.OrderID
+		order.WalletID = walletID
+		order.Wallet = address
+		order.PayAmount = payment.Amount
+		order.Memo = payment.Memo
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Order created from template", "template_id", id, "order_id", order.OrderID, "user_id", userID,
+		"wallet", order.Wallet, "pay_amount", order.PayAmount)
+
+	// Ордер уже создан: без ссылки его можно оплатить по адресу и сумме из ответа
+	order.Payment, err = s.payments.GetPaymentRequest(ctx, userID, order.OrderID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to build template order payment link", "error", err, "order_id", order.OrderID)
+	}
+
+	return order, nil
+}
+
+// validateCallbackURL допускает только абсолютные https адреса
+func validateCallbackURL(raw string) error {
+	if len(raw) > maxCallbackURLLength {
+		return fmt.Errorf("%w: callback URL is longer than %d characters", ErrInvalidOrderTemplate, maxCallbackURLLength)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: callback URL must be an absolute https URL", ErrInvalidOrderTemplate)
+	}
+	return nil
+}
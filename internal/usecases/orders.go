@@ -2,36 +2,203 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
 )
 
 type OrdersRepository interface {
 	FindUserOrders(ctx context.Context, userID int) ([]entities.Order, error)
-	InsertOrder(ctx context.Context, userID, walletID int, amount string) error
-	UpdateOrderStatus(ctx context.Context, walletID int, amount *big.Int) error
+	InsertOrder(ctx context.Context, userID, walletID, assetID int, amount decimal.Decimal, memo string) (int, error)
+	InsertOrderWithExpectedAmount(ctx context.Context, userID, walletID, assetID int, amount, expectedAmount decimal.Decimal, memo string) (int, error)
+	UpdateOrderStatus(ctx context.Context, walletID int, amount *big.Int, memo, txHash string) (*big.Int, error)
 	RemoveOldOrders(ctx context.Context, olderThan time.Duration) (int64, error)
 	UpdateOrderAMLStatus(ctx context.Context, orderID int, status entities.AMLStatus, notes string) error
 	FindOrderByWalletAddress(ctx context.Context, walletAddress string) (int, error)
 	DeleteOrder(ctx context.Context, orderID int) error
 }
 
+// OrderAssets определяет актив ордера и его лимиты
+type OrderAssets interface {
+	Default() entities.Asset
+	ValidateOrderAmount(asset entities.Asset, amount string) error
+}
+
+// OrderOmnibus определяет, как сопоставляется оплата ордера мерчанта на омнибус кошельке
+type OrderOmnibus interface {
+	Attribution(ctx context.Context, merchantID int64, walletID int) (entities.DepositAttribution, bool, error)
+}
+
+var (
+	_ OrderAssets  = (*AssetRegistry)(nil)
+	_ OrderOmnibus = (*OmnibusDepositService)(nil)
+)
+
+// Число попыток подобрать свободную уникальную сумму для ордера
+const maxFingerprintAttempts = 10
+
+// Число попыток подобрать свободное мемо для ордера
+const maxMemoAttempts = 5
+
+// Знаков уникальной добавки для ордеров на омнибус кошельке, если fingerprinting в конфигурации отключен
+const omnibusFingerprintDecimals = 4
+
 type OrderService struct {
-	repo OrdersRepository
+	repo   OrdersRepository
+	assets OrderAssets
+
+	// Число знаков дробной добавки к сумме ордера, 0 - fingerprinting отключен
+	fingerprintDecimals int
+	// Генерировать мемо депозита для каждого ордера
+	depositMemos bool
+	// Атрибуция ордеров на омнибус кошельке, nil — омнибус режим отключен
+	omnibus OrderOmnibus
 }
 
-func NewOrderService(repo OrdersRepository) *OrderService {
-	return &OrderService{repo: repo}
+// NewOrderService creates the order service. fingerprintDecimals > 0 enables unique amount
+// fingerprints, so several pending orders can share one deposit wallet. depositMemos enables
+// a deposit memo per order, matched against the memo of the transfer.
+func NewOrderService(repo OrdersRepository, assets OrderAssets, fingerprintDecimals int, depositMemos bool) *OrderService {
+	return &OrderService{repo: repo, assets: assets, fingerprintDecimals: fingerprintDecimals, depositMemos: depositMemos}
+}
+
+// SetOmnibus enables the per-merchant attribution of orders created on the omnibus wallet
+func (os *OrderService) SetOmnibus(omnibus OrderOmnibus) {
+	os.omnibus = omnibus
+}
+
+// UsesAmountFingerprints сообщает, сопоставляются ли ордера по уникальной сумме
+func (os *OrderService) UsesAmountFingerprints() bool {
+	return os.fingerprintDecimals > 0
+}
+
+// UsesDepositMemos сообщает, назначается ли ордерам мемо депозита
+func (os *OrderService) UsesDepositMemos() bool {
+	return os.depositMemos
 }
 
 func (os *OrderService) GetUserOrders(ctx context.Context, userID int) ([]entities.Order, error) {
 	return os.repo.FindUserOrders(ctx, userID)
 }
 
-func (os *OrderService) CreateOrder(ctx context.Context, userID, walletID int, amount string) error {
-	return os.repo.InsertOrder(ctx, userID, walletID, amount)
+// ValidateAmount checks the order amount against the limits of the settlement asset
+func (os *OrderService) ValidateAmount(amount string) error {
+	return os.assets.ValidateOrderAmount(os.assets.Default(), amount)
+}
+
+// CreateOrder создает ордер и возвращает сумму, которую нужно перевести, и мемо перевода.
+// При включенном fingerprinting к сумме добавляется уникальная для кошелька дробная часть,
+// при включенных мемо ордеру назначается уникальное для кошелька числовое мемо.
+// На омнибус кошельке ордер получает уникальную сумму или мемо по настройке мерчанта.
+func (os *OrderService) CreateOrder(ctx context.Context, userID, walletID int, amount string) (*entities.OrderPayment, error) {
+	return os.createAttributedOrder(ctx, userID, walletID, os.assets.Default(), amount)
+}
+
+// CreateAssetOrder создает ордер в активе asset обслуживаемой сети, сумма и мемо назначаются как в CreateOrder
+func (os *OrderService) CreateAssetOrder(ctx context.Context, userID, walletID int, asset entities.Asset, amount string) (*entities.OrderPayment, error) {
+	return os.createAttributedOrder(ctx, userID, walletID, asset, amount)
+}
+
+// createAttributedOrder создает ордер с атрибуцией из конфигурации, а на омнибус кошельке — с атрибуцией мерчанта
+func (os *OrderService) createAttributedOrder(ctx context.Context, userID, walletID int, asset entities.Asset, amount string) (*entities.OrderPayment, error) {
+	fingerprintDecimals, withMemo := os.fingerprintDecimals, os.depositMemos
+	if os.omnibus != nil {
+		attribution, ok, err := os.omnibus.Attribution(ctx, int64(userID), walletID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			fingerprintDecimals, withMemo = 0, true
+			if attribution == entities.DepositAttributionAmount {
+				fingerprintDecimals, withMemo = max(os.fingerprintDecimals, omnibusFingerprintDecimals), false
+			}
+		}
+	}
+	return os.createOrder(ctx, userID, walletID, asset, amount, fingerprintDecimals, withMemo)
+}
+
+// CreateMemoOrder создает ордер в активе asset на общем депозитном адресе: оплата сопоставляется только по мемо,
+// поэтому мемо назначается всегда, а сумма остается без уникальной добавки
+func (os *OrderService) CreateMemoOrder(ctx context.Context, userID, walletID int, asset entities.Asset, amount string) (*entities.OrderPayment, error) {
+	return os.createOrder(ctx, userID, walletID, asset, amount, 0, true)
+}
+
+// createOrder создает ордер, fingerprintDecimals > 0 добавляет к сумме уникальную дробную часть с таким числом знаков
+func (os *OrderService) createOrder(ctx context.Context, userID, walletID int, asset entities.Asset, amount string, fingerprintDecimals int, withMemo bool) (*entities.OrderPayment, error) {
+	if err := os.assets.ValidateOrderAmount(asset, amount); err != nil {
+		return nil, err
+	}
+	value, err := decimal.Parse(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	for range maxMemoAttempts {
+		var memo string
+		if withMemo {
+			memo = newDepositMemo()
+		}
+
+		orderID, payAmount, err := os.insertOrder(ctx, userID, walletID, asset, value, memo, fingerprintDecimals)
+		if memo != "" && errors.Is(err, repository.ErrUniqueViolation) {
+			// Мемо уже занято другим ожидающим ордером кошелька
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &entities.OrderPayment{OrderID: orderID, Amount: payAmount, Memo: memo}, nil
+	}
+
+	return nil, fmt.Errorf("failed to assign unique memo for wallet %d after %d attempts", walletID, maxMemoAttempts)
+}
+
+// insertOrder сохраняет ордер и возвращает его ID и сумму к оплате
+func (os *OrderService) insertOrder(ctx context.Context, userID, walletID int, asset entities.Asset, value decimal.Decimal, memo string, fingerprintDecimals int) (int, string, error) {
+	if fingerprintDecimals <= 0 {
+		orderID, err := os.repo.InsertOrder(ctx, userID, walletID, asset.ID, value, memo)
+		return orderID, value.String(), orderInsertError(err)
+	}
+
+	for range maxFingerprintAttempts {
+		// Добавка от 1 до 10^decimals-1 минимальных единиц, например 0.0001..0.9999
+		maxSuffix := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fingerprintDecimals)), nil).Int64() - 1
+		expectedAmount, err := addAmountFingerprint(value, rand.Int64N(maxSuffix)+1, fingerprintDecimals, asset.Decimals)
+		if err != nil {
+			return 0, "", err
+		}
+
+		orderID, err := os.repo.InsertOrderWithExpectedAmount(ctx, userID, walletID, asset.ID, value, expectedAmount, memo)
+		if err != nil {
+			return 0, "", orderInsertError(err)
+		}
+		if orderID != 0 {
+			return orderID, expectedAmount.String(), nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("failed to assign unique amount for wallet %d after %d attempts", walletID, maxFingerprintAttempts)
+}
+
+// newDepositMemo возвращает случайное числовое мемо, которое укладывается в uint32, как destination tag в XRP
+func newDepositMemo() string {
+	return strconv.FormatUint(uint64(rand.Uint32N(math.MaxUint32))+1, 10)
+}
+
+// orderInsertError сообщает о сумме, отклоненной ограничениями таблицы ордеров, как об ошибке клиента
+func orderInsertError(err error) error {
+	if errors.Is(err, repository.ErrCheckViolation) {
+		return fmt.Errorf("%w: %w", ErrOrderAmountOutOfRange, err)
+	}
+	return err
 }
 
 func (os *OrderService) RemoveOldOrders(ctx context.Context, olderThan time.Duration) (int64, error) {
@@ -55,3 +222,18 @@ func (os *OrderService) GetOrderIdForWallet(ctx context.Context, walletAddress s
 func (os *OrderService) DeleteOrder(ctx context.Context, orderID int) error {
 	return os.repo.DeleteOrder(ctx, orderID)
 }
+
+// addAmountFingerprint добавляет к сумме suffix единиц decimals-го знака: (100, 37, 4) -> 100.0037
+func addAmountFingerprint(amount decimal.Decimal, suffix int64, decimals, assetDecimals int) (decimal.Decimal, error) {
+	if amount.Sign() <= 0 {
+		return decimal.Zero, fmt.Errorf("invalid order amount %s", amount)
+	}
+
+	value := amount.Add(decimal.FromUnits(big.NewInt(suffix), decimals))
+
+	// Сумма с добавкой должна оставаться представимой в минимальных единицах токена
+	if _, err := value.Units(assetDecimals); err != nil {
+		return decimal.Zero, fmt.Errorf("fingerprinted amount of %s: %w", amount, err)
+	}
+	return value, nil
+}
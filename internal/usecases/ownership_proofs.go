@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	maxOwnershipChallengeLength = 128
+	ownershipProofTitle         = "Deposit address ownership proof"
+)
+
+type OwnershipProofWallets interface {
+	FindWalletByAddress(ctx context.Context, address string) (*entities.Wallet, error)
+}
+
+// OwnershipProofSigner подписывает дайджест ключом кошелька с заданными версией сида и путем деривации
+type OwnershipProofSigner interface {
+	SignHash(ctx context.Context, keyVersion int, derivationPath string, hash common.Hash, operation string) (common.Address, []byte, error)
+}
+
+var (
+	_ OwnershipProofWallets = (*repository.WalletsRepository)(nil)
+	_ OwnershipProofSigner  = (*WalletService)(nil)
+)
+
+// OwnershipProofService proves that the platform controls a deposit address: a message with the challenge
+// of the user is signed with the key of the wallet according to EIP-191, so anyone can recover the signer.
+// The message is built by the service and carries a fixed prefix, so the signature cannot be reused as
+// a transaction or a typed-data (permit) signature.
+type OwnershipProofService struct {
+	logger  *slog.Logger
+	wallets OwnershipProofWallets
+	signer  OwnershipProofSigner
+}
+
+func NewOwnershipProofService(logger *slog.Logger, wallets OwnershipProofWallets, signer OwnershipProofSigner) *OwnershipProofService {
+	return &OwnershipProofService{
+		logger:  logger,
+		wallets: wallets,
+		signer:  signer,
+	}
+}
+
+// ProveOwnership signs the challenge with the key of the user's deposit wallet
+func (s *OwnershipProofService) ProveOwnership(ctx context.Context, userID int64, address, challenge string) (*entities.OwnershipProof, error) {
+	if err := validateOwnershipChallenge(challenge); err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(address) {
+		return nil, ErrWalletNotFound
+	}
+
+	// Адреса кошельков хранятся в checksum-формате EIP-55
+	wallet, err := s.wallets.FindWalletByAddress(ctx, common.HexToAddress(address).Hex())
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil || wallet.UserID != userID || wallet.ArchivedAt != nil {
+		return nil, ErrWalletNotFound
+	}
+	if wallet.AddressFormat != entities.AddressFormatEVMHex {
+		return nil, ErrOwnershipProofNotSupported
+	}
+
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	checksummed := common.HexToAddress(wallet.Address)
+	message := ownershipMessage(checksummed, wallet.Chain, wallet.Network, challenge, issuedAt)
+
+	signer, signature, err := s.signer.SignHash(ctx, wallet.KeyVersion, wallet.DerivationPath, common.BytesToHash(accounts.TextHash([]byte(message))), SignOperationOwnershipProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign ownership proof: %w", err)
+	}
+	if signer != checksummed {
+		return nil, fmt.Errorf("ownership proof signed by %s instead of %s", signer.Hex(), checksummed.Hex())
+	}
+
+	s.logger.InfoContext(ctx, "Ownership proof signed", "user_id", userID, "address", checksummed.Hex())
+
+	return &entities.OwnershipProof{
+		Address:   checksummed.Hex(),
+		Chain:     wallet.Chain,
+		Network:   wallet.Network,
+		Message:   message,
+		Signature: hexutil.Encode(signature),
+		Standard:  entities.OwnershipProofStandard,
+		IssuedAt:  issuedAt,
+	}, nil
+}
+
+// VerifyOwnership recovers the signer of an EIP-191 message and compares it with the address
+func (s *OwnershipProofService) VerifyOwnership(address, message, signature string) (*entities.OwnershipVerification, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address", ErrInvalidOwnershipChallenge)
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidOwnershipChallenge)
+	}
+	// Кошельки подписывают с v = 27/28, восстановление ожидает 0/1
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return &entities.OwnershipVerification{Valid: false}, nil
+	}
+
+	signer := crypto.PubkeyToAddress(*publicKey)
+	return &entities.OwnershipVerification{
+		Valid:  signer == common.HexToAddress(address),
+		Signer: signer.Hex(),
+	}, nil
+}
+
+// Вызов — печатаемые ASCII символы, переводы строк сделали бы сообщение неоднозначным
+func validateOwnershipChallenge(challenge string) error {
+	if challenge == "" || len(challenge) > maxOwnershipChallengeLength {
+		return fmt.Errorf("%w: challenge must be 1 to %d characters", ErrInvalidOwnershipChallenge, maxOwnershipChallengeLength)
+	}
+	for _, c := range challenge {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Errorf("%w: challenge must contain printable ASCII characters only", ErrInvalidOwnershipChallenge)
+		}
+	}
+	return nil
+}
+
+func ownershipMessage(address common.Address, chain entities.Chain, network, challenge string, issuedAt time.Time) string {
+	return strings.Join([]string{
+		ownershipProofTitle,
+		"Address: " + address.Hex(),
+		fmt.Sprintf("Network: %s %s", chain, network),
+		"Challenge: " + challenge,
+		"Issued at: " + issuedAt.Format(time.RFC3339),
+	}, "\n")
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+const (
+	bscMainnetChainID = 56
+	bscTestnetChainID = 97
+	// Симулированная сеть go-ethereum
+	simulatedChainID = 1337
+)
+
+type PaymentOrdersRepository interface {
+	FindOrderByID(ctx context.Context, orderID int) (*entities.Order, error)
+}
+
+type PaymentWalletsRepository interface {
+	FindWalletByID(ctx context.Context, id int) (*entities.Wallet, error)
+}
+
+// PaymentAssets находит актив ордера; ордера без актива относятся к активу по умолчанию
+type PaymentAssets interface {
+	FindByID(id int) (entities.Asset, error)
+	Default() entities.Asset
+}
+
+var (
+	_ PaymentOrdersRepository  = (*repository.OrdersRepository)(nil)
+	_ PaymentWalletsRepository = (*repository.WalletsRepository)(nil)
+	_ PaymentAssets            = (*AssetRegistry)(nil)
+)
+
+// PaymentLinkService формирует платежные ссылки EIP-681 для оплаты ордеров
+type PaymentLinkService struct {
+	orders  PaymentOrdersRepository
+	wallets PaymentWalletsRepository
+	assets  PaymentAssets
+}
+
+func NewPaymentLinkService(orders PaymentOrdersRepository, wallets PaymentWalletsRepository, assets PaymentAssets) *PaymentLinkService {
+	return &PaymentLinkService{orders: orders, wallets: wallets, assets: assets}
+}
+
+// GetPaymentRequest возвращает реквизиты оплаты ордера пользователя
+func (s *PaymentLinkService) GetPaymentRequest(ctx context.Context, userID int64, orderID int) (*entities.PaymentRequest, error) {
+	order, err := s.orders.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || int64(order.UserID) != userID {
+		return nil, ErrOrderNotFound
+	}
+	if order.Status != entities.OrderStatusPending {
+		return nil, ErrOrderNotPending
+	}
+
+	wallet, err := s.wallets.FindWalletByID(ctx, order.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("wallet %d of order %d not found", order.WalletID, order.ID)
+	}
+
+	// При fingerprinting оплачивать нужно уникальную сумму, иначе перевод не сопоставится с ордером
+	amount := order.Amount
+	if order.ExpectedAmount != nil {
+		amount = *order.ExpectedAmount
+	}
+
+	asset := s.assets.Default()
+	if order.AssetID != nil {
+		if asset, err = s.assets.FindByID(*order.AssetID); err != nil {
+			return nil, fmt.Errorf("asset of order %d: %w", order.ID, err)
+		}
+	}
+
+	amountWei, err := amount.Units(asset.Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount of order %d: %w", order.ID, err)
+	}
+
+	chainID := int64(bscMainnetChainID)
+	switch asset.Network {
+	case entities.NetworkTestnet:
+		chainID = bscTestnetChainID
+	case entities.NetworkSimulated:
+		chainID = simulatedChainID
+	}
+	tokenAddress := asset.Contract
+
+	var memo string
+	if order.Memo != nil {
+		memo = *order.Memo
+	}
+
+	return &entities.PaymentRequest{
+		OrderID:       order.ID,
+		WalletAddress: wallet.Address,
+		TokenAddress:  tokenAddress,
+		ChainID:       chainID,
+		Amount:        amount.String(),
+		AmountWei:     amountWei.String(),
+		// EIP-681: ethereum:<token>@<chain_id>/transfer?address=<recipient>&uint256=<amount>
+		URI:  fmt.Sprintf("ethereum:%s@%d/transfer?address=%s&uint256=%s", tokenAddress, chainID, wallet.Address, amountWei.String()),
+		Memo: memo,
+	}, nil
+}
+
+// tokenAmountToUnits переводит положительную десятичную сумму в минимальные единицы токена без потери точности
+func tokenAmountToUnits(amount string, decimals int) (*big.Int, error) {
+	value, err := decimal.Parse(amount)
+	if err != nil || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid decimal amount %q", amount)
+	}
+
+	units, err := value.Units(decimals)
+	if err != nil {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+
+	return units, nil
+}
+
+// unitsToTokenAmount переводит минимальные единицы токена в десятичную сумму без лишних нулей: (1500, 3) -> "1.5"
+func unitsToTokenAmount(units *big.Int, decimals int) string {
+	return decimal.FromUnits(units, decimals).String()
+}
@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// permitDeadline — срок действия подписи permit
+	permitDeadline = 30 * time.Minute
+	// transferFrom нельзя оценить до исполнения permit: allowance еще не выдан
+	permitTransferFromGasLimit = 100000
+)
+
+var (
+	// ErrPermitNotSupported is returned when the token does not implement EIP-2612
+	ErrPermitNotSupported = errors.New("token does not support permit")
+
+	permitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+	domainSeparatorSelector = crypto.Keccak256([]byte("DOMAIN_SEPARATOR()"))[:4]
+	noncesSelector          = crypto.Keccak256([]byte("nonces(address)"))[:4]
+	permitSelector          = crypto.Keccak256([]byte("permit(address,address,uint256,uint256,uint8,bytes32,bytes32)"))[:4]
+	transferFromSelector    = crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+)
+
+// SupportsPermit reports whether the USDT contract implements EIP-2612 (DOMAIN_SEPARATOR and nonces)
+func (bsc *WalletService) SupportsPermit(ctx context.Context, client *ethclient.Client) (bool, error) {
+	tokenAddr := common.HexToAddress(bsc.smartContractAddress)
+
+	if _, err := bsc.permitDomainSeparator(ctx, client, tokenAddr); err != nil {
+		if errors.Is(err, ErrPermitNotSupported) {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, err := bsc.permitNonce(ctx, client, tokenAddr, common.Address{}); err != nil {
+		if errors.Is(err, ErrPermitNotSupported) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SweepWithPermit moves amount of USDT from a deposit wallet without BNB on it: the deposit wallet signs
+// an EIP-2612 permit for the relayer, and the relayer (a funded wallet at relayerPath) submits permit
+// and transferFrom paying for gas. Returns the hash of the transferFrom transaction.
+func (bsc *WalletService) SweepWithPermit(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int, relayerPath string) (string, error) {
+	wallet, err := bsc.repo.FindWalletByID(ctx, fromWalletID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find wallet with ID %d: %w", fromWalletID, err)
+	}
+	if wallet == nil {
+		return "", fmt.Errorf("wallet with ID %d not found", fromWalletID)
+	}
+
+	relayerUserID, relayerIndex, err := ParseDerivationPath(relayerPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid relayer path: %w", err)
+	}
+	relayer, err := bsc.signer.DeriveAddress(bsc.keyring.ServiceVersion(), relayerUserID, relayerIndex)
+	if err != nil {
+		return "", err
+	}
+
+	if err = bsc.CheckTokenHalt(); err != nil {
+		return "", err
+	}
+	if err = bsc.ScreenAddresses(ctx, wallet.Address, toAddress); err != nil {
+		return "", err
+	}
+
+	tokenAddr := common.HexToAddress(bsc.smartContractAddress)
+	owner := common.HexToAddress(wallet.Address)
+
+	domainSeparator, err := bsc.permitDomainSeparator(ctx, client, tokenAddr)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := bsc.permitNonce(ctx, client, tokenAddr, owner)
+	if err != nil {
+		return "", err
+	}
+	deadline := big.NewInt(time.Now().Add(permitDeadline).Unix())
+
+	digest := crypto.Keccak256Hash(
+		[]byte{0x19, 0x01},
+		domainSeparator.Bytes(),
+		crypto.Keccak256(
+			permitTypeHash.Bytes(),
+			common.LeftPadBytes(owner.Bytes(), 32),
+			common.LeftPadBytes(relayer.Bytes(), 32),
+			common.LeftPadBytes(amount.Bytes(), 32),
+			common.LeftPadBytes(nonce.Bytes(), 32),
+			common.LeftPadBytes(deadline.Bytes(), 32),
+		),
+	)
+
+	// Подпись permit ключом депозитного кошелька: BNB на нем не нужен
+	signature, err := bsc.signer.SignHash(ctx, wallet.KeyVersion, wallet.DerivationPath, owner, digest, SignOperationPermit)
+	if err != nil {
+		return "", err
+	}
+
+	permitData := make([]byte, 0, 4+7*32)
+	permitData = append(permitData, permitSelector...)
+	permitData = append(permitData, common.LeftPadBytes(owner.Bytes(), 32)...)
+	permitData = append(permitData, common.LeftPadBytes(relayer.Bytes(), 32)...)
+	permitData = append(permitData, common.LeftPadBytes(amount.Bytes(), 32)...)
+	permitData = append(permitData, common.LeftPadBytes(deadline.Bytes(), 32)...)
+	permitData = append(permitData, common.LeftPadBytes([]byte{signature[64]}, 32)...)
+	permitData = append(permitData, signature[:32]...)
+	permitData = append(permitData, signature[32:64]...)
+
+	if err = bsc.simulateTransaction(ctx, client, relayer, tokenAddr, big.NewInt(0), permitData); err != nil {
+		return "", err
+	}
+
+	permitGas, err := client.EstimateGas(ctx, ethereum.CallMsg{
+		From: relayer,
+		To:   &tokenAddr,
+		Data: permitData,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate permit gas: %w", err)
+	}
+
+	permitTxHash, err := bsc.sendTransaction(ctx, client, bsc.keyring.ServiceVersion(), relayerPath, relayer, tokenAddr, big.NewInt(0),
+		permitGas*12/10, nil, permitData, PriorityMedium, SignOperationPermitRelay)
+	if err != nil {
+		return "", fmt.Errorf("failed to send permit: %w", err)
+	}
+
+	transferData := make([]byte, 0, 4+3*32)
+	transferData = append(transferData, transferFromSelector...)
+	transferData = append(transferData, common.LeftPadBytes(owner.Bytes(), 32)...)
+	transferData = append(transferData, common.LeftPadBytes(common.HexToAddress(toAddress).Bytes(), 32)...)
+	transferData = append(transferData, common.LeftPadBytes(amount.Bytes(), 32)...)
+
+	// transferFrom следует за permit с nonce + 1 и исполнится только после него
+	txHash, err := bsc.sendTransaction(ctx, client, bsc.keyring.ServiceVersion(), relayerPath, relayer, tokenAddr, big.NewInt(0),
+		permitTransferFromGasLimit, nil, transferData, PriorityMedium, SignOperationPermitRelay)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transferFrom after permit %s: %w", permitTxHash, err)
+	}
+
+	bsc.logger.InfoContext(ctx, "Gasless sweep submitted",
+		"wallet", wallet.Address,
+		"to", toAddress,
+		"amount", amount.String(),
+		"relayer", relayer.Hex(),
+		"permit_tx_hash", permitTxHash,
+		"tx_hash", txHash)
+
+	return txHash, nil
+}
+
+func (bsc *WalletService) permitDomainSeparator(ctx context.Context, client *ethclient.Client, token common.Address) (common.Hash, error) {
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: domainSeparatorSelector}, nil)
+	if err != nil || len(result) != 32 {
+		return common.Hash{}, fmt.Errorf("%w: DOMAIN_SEPARATOR unavailable", ErrPermitNotSupported)
+	}
+	return common.BytesToHash(result), nil
+}
+
+func (bsc *WalletService) permitNonce(ctx context.Context, client *ethclient.Client, token, owner common.Address) (*big.Int, error) {
+	data := append(append([]byte{}, noncesSelector...), common.LeftPadBytes(owner.Bytes(), 32)...)
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil || len(result) != 32 {
+		return nil, fmt.Errorf("%w: nonces unavailable", ErrPermitNotSupported)
+	}
+	return new(big.Int).SetBytes(result), nil
+}
@@ -0,0 +1,341 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// fiatValuePlaces — знаков после запятой в фиатной оценке
+const fiatValuePlaces = 2
+
+type PricesRepository interface {
+	InsertSnapshots(ctx context.Context, snapshots []entities.PriceSnapshot) error
+	FindRatesAt(ctx context.Context, chain entities.Chain, asset, fiat string, moments []time.Time, maxAge time.Duration) ([]*decimal.Decimal, error)
+}
+
+// PriceAssets — реестр активов, курсы которых держит кеш
+type PriceAssets interface {
+	List() []entities.Asset
+	FindByID(id int) (entities.Asset, error)
+	Default() entities.Asset
+}
+
+var (
+	_ PricesRepository = (*repository.PricesRepository)(nil)
+	_ PriceAssets      = (*AssetRegistry)(nil)
+)
+
+// PriceCacheConfig задает валюты оценки, период обновления курсов, период снимков и срок годности курса.
+// Первая валюта используется, если клиент не указал свою
+type PriceCacheConfig struct {
+	Currencies []string
+	Interval   time.Duration
+	// Неизменившийся курс записывается в историю не чаще SnapshotInterval
+	SnapshotInterval time.Duration
+	// Текущий курс старше MaxAge не показывается, исторический ищется не дальше MaxAge до момента
+	MaxAge time.Duration
+}
+
+// priceKey — курс актива сети к фиатной валюте
+type priceKey struct {
+	chain entities.Chain
+	asset string
+	fiat  string
+}
+
+type cachedPrice struct {
+	rate       decimal.Decimal
+	updatedAt  time.Time
+	snapshotAt time.Time
+}
+
+// PriceCache keeps the fiat rates of the served assets and the native coins of their chains in memory,
+// refreshed from the rate provider. Changed rates are recorded as snapshots, so transactions and orders are
+// valued both at the current rate and at the rate of the moment they were created.
+type PriceCache struct {
+	logger  *slog.Logger
+	repo    PricesRepository
+	assets  PriceAssets
+	rates   RateProvider
+	tracker WorkerTracker
+
+	currencies       []string
+	interval         time.Duration
+	snapshotInterval time.Duration
+	maxAge           time.Duration
+
+	mu     sync.RWMutex
+	prices map[priceKey]cachedPrice
+}
+
+func NewPriceCache(logger *slog.Logger, repo PricesRepository, assets PriceAssets, rates RateProvider, tracker WorkerTracker, config PriceCacheConfig) (*PriceCache, error) {
+	if config.Interval <= 0 || config.SnapshotInterval <= 0 || config.MaxAge <= 0 {
+		return nil, errors.New("price cache interval, snapshot interval and max age must be positive")
+	}
+
+	currencies := make([]string, 0, len(config.Currencies))
+	for _, currency := range config.Currencies {
+		if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) == 0 {
+		return nil, errors.New("price cache requires at least one fiat currency")
+	}
+
+	return &PriceCache{
+		logger:           logger,
+		repo:             repo,
+		assets:           assets,
+		rates:            rates,
+		tracker:          tracker,
+		currencies:       currencies,
+		interval:         config.Interval,
+		snapshotInterval: config.SnapshotInterval,
+		maxAge:           config.MaxAge,
+		prices:           make(map[priceKey]cachedPrice),
+	}, nil
+}
+
+// DefaultCurrency returns the fiat currency used when the client does not ask for one
+func (c *PriceCache) DefaultCurrency() string {
+	return c.currencies[0]
+}
+
+// Start refreshes the rates immediately and then every interval until ctx is cancelled
+func (c *PriceCache) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		refreshed, err := c.Refresh(ctx)
+		c.tracker.Done(refreshed, err)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "Price cache refresh failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh requests the rates of every served asset and native coin in every currency and returns the number
+// of rates updated. A pair without a rate is skipped, other provider errors fail the refresh after the rest
+// of the pairs are updated.
+func (c *PriceCache) Refresh(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	updates := make(map[priceKey]cachedPrice)
+	var snapshots []entities.PriceSnapshot
+	var errs []error
+	for _, coin := range c.coins() {
+		for _, fiat := range c.currencies {
+			rate, err := c.rates.Rate(ctx, coin.asset, fiat)
+			if errors.Is(err, ErrRateUnavailable) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("rate %s/%s: %w", coin.asset, fiat, err))
+				continue
+			}
+
+			key := priceKey{chain: coin.chain, asset: coin.asset, fiat: fiat}
+			price := cachedPrice{rate: decimal.FromRat(rate), updatedAt: now}
+			c.mu.RLock()
+			previous, ok := c.prices[key]
+			c.mu.RUnlock()
+			if ok && previous.rate.Equal(price.rate) && now.Sub(previous.snapshotAt) < c.snapshotInterval {
+				price.snapshotAt = previous.snapshotAt
+			} else {
+				price.snapshotAt = now
+				snapshots = append(snapshots, entities.PriceSnapshot{
+					Chain:      coin.chain,
+					Asset:      coin.asset,
+					Fiat:       fiat,
+					Rate:       price.rate,
+					RecordedAt: now,
+				})
+			}
+			updates[key] = price
+		}
+	}
+
+	if len(snapshots) > 0 {
+		if err := c.repo.InsertSnapshots(ctx, snapshots); err != nil {
+			// Курсы обновляются и без снимков, снимок повторится при следующем обновлении
+			errs = append(errs, err)
+			for _, snapshot := range snapshots {
+				key := priceKey{chain: snapshot.Chain, asset: snapshot.Asset, fiat: snapshot.Fiat}
+				price := updates[key]
+				price.snapshotAt = time.Time{}
+				updates[key] = price
+			}
+		}
+	}
+
+	c.mu.Lock()
+	for key, price := range updates {
+		c.prices[key] = price
+	}
+	c.mu.Unlock()
+
+	return len(updates), errors.Join(errs...)
+}
+
+// coins returns the served assets and the native coins of their chains
+func (c *PriceCache) coins() []priceKey {
+	seen := make(map[priceKey]bool)
+	var coins []priceKey
+	add := func(chain entities.Chain, asset string) {
+		key := priceKey{chain: chain, asset: strings.ToUpper(asset)}
+		if !seen[key] {
+			seen[key] = true
+			coins = append(coins, key)
+		}
+	}
+
+	for _, asset := range c.assets.List() {
+		add(asset.Chain, asset.Code)
+		if native, _, err := asset.Chain.NativeCoin(); err == nil {
+			add(asset.Chain, native)
+		}
+	}
+	return coins
+}
+
+// Price returns the cached rate of the chain asset and the time it was refreshed.
+// False if the rate is unknown or older than the max age.
+func (c *PriceCache) Price(chain entities.Chain, asset, fiat string) (decimal.Decimal, time.Time, bool) {
+	c.mu.RLock()
+	price, ok := c.prices[priceKey{chain: chain, asset: strings.ToUpper(asset), fiat: strings.ToUpper(fiat)}]
+	c.mu.RUnlock()
+
+	if !ok || time.Since(price.updatedAt) > c.maxAge {
+		return decimal.Zero, time.Time{}, false
+	}
+	return price.rate, price.updatedAt, true
+}
+
+// BalanceValue values the amount of the chain asset at the current rate, nil if the rate is unknown
+func (c *PriceCache) BalanceValue(chain entities.Chain, asset string, amount decimal.Decimal, fiat string) *entities.FiatValue {
+	value := c.currentValue(chain, asset, amount, fiat)
+	if value.Current == "" {
+		return nil
+	}
+	return value
+}
+
+// TransactionValues values the transaction amounts of the asset at the current rate and at the rate
+// of the moment each transaction was recorded. The result is aligned with transactions.
+func (c *PriceCache) TransactionValues(ctx context.Context, asset entities.Asset, transactions []entities.Transaction, fiat string) []*entities.FiatValue {
+	amounts := make([]*decimal.Decimal, len(transactions))
+	moments := make([]time.Time, len(transactions))
+	for i, transaction := range transactions {
+		if units, ok := new(big.Int).SetString(transaction.Amount, 10); ok {
+			amount := decimal.FromUnits(units, asset.Decimals)
+			amounts[i] = &amount
+		}
+		moments[i] = transaction.CreatedAt
+	}
+
+	return c.values(ctx, asset, amounts, moments, fiat)
+}
+
+// OrderValues values the order amounts at the current rate and at the rate of the order creation.
+// The result is aligned with orders, orders of unknown assets get nil.
+func (c *PriceCache) OrderValues(ctx context.Context, orders []entities.Order, fiat string) []*entities.FiatValue {
+	type group struct {
+		asset   entities.Asset
+		indexes []int
+	}
+	groups := make(map[int]*group)
+	var ids []int
+	for i, order := range orders {
+		asset := c.assets.Default()
+		if order.AssetID != nil {
+			var err error
+			if asset, err = c.assets.FindByID(*order.AssetID); err != nil {
+				continue
+			}
+		}
+
+		g, ok := groups[asset.ID]
+		if !ok {
+			g = &group{asset: asset}
+			groups[asset.ID] = g
+			ids = append(ids, asset.ID)
+		}
+		g.indexes = append(g.indexes, i)
+	}
+
+	values := make([]*entities.FiatValue, len(orders))
+	for _, id := range ids {
+		g := groups[id]
+		amounts := make([]*decimal.Decimal, len(g.indexes))
+		moments := make([]time.Time, len(g.indexes))
+		for j, i := range g.indexes {
+			amounts[j] = &orders[i].Amount
+			moments[j] = orders[i].CreatedAt
+		}
+
+		for j, value := range c.values(ctx, g.asset, amounts, moments, fiat) {
+			values[g.indexes[j]] = value
+		}
+	}
+	return values
+}
+
+// values builds the fiat values of the amounts. Historical rates are best effort: on a lookup error
+// only the current values are returned.
+func (c *PriceCache) values(ctx context.Context, asset entities.Asset, amounts []*decimal.Decimal, moments []time.Time, fiat string) []*entities.FiatValue {
+	values := make([]*entities.FiatValue, len(amounts))
+	fiat = strings.ToUpper(strings.TrimSpace(fiat))
+	if !slices.Contains(c.currencies, fiat) {
+		return values
+	}
+
+	code := strings.ToUpper(asset.Code)
+	rates, err := c.repo.FindRatesAt(ctx, asset.Chain, code, fiat, moments, c.maxAge)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Historical rates unavailable", "error", err, "asset", code, "chain", asset.Chain, "fiat", fiat)
+		rates = nil
+	}
+
+	for i, amount := range amounts {
+		if amount == nil {
+			continue
+		}
+
+		value := c.currentValue(asset.Chain, code, *amount, fiat)
+		if i < len(rates) && rates[i] != nil {
+			value.AtTime = amount.Mul(*rates[i]).StringFixed(fiatValuePlaces)
+		}
+		if value.Current != "" || value.AtTime != "" {
+			values[i] = value
+		}
+	}
+	return values
+}
+
+func (c *PriceCache) currentValue(chain entities.Chain, asset string, amount decimal.Decimal, fiat string) *entities.FiatValue {
+	value := &entities.FiatValue{Currency: strings.ToUpper(strings.TrimSpace(fiat))}
+	if rate, asOf, ok := c.Price(chain, asset, value.Currency); ok {
+		value.Current = amount.Mul(rate).StringFixed(fiatValuePlaces)
+		value.RateAsOf = &asOf
+	}
+	return value
+}
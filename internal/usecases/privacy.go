@@ -0,0 +1,227 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// privacyExportLimit ограничивает число сессий, счетов и событий аудита в выгрузке
+const privacyExportLimit = 10000
+
+type PrivacyRepository interface {
+	FindUserAuditEvents(ctx context.Context, userID int64, limit int) ([]entities.AuditEvent, error)
+	CountOpenRefunds(ctx context.Context, userID int64) (int, error)
+	FindErasure(ctx context.Context, userID int64) (*entities.UserErasure, error)
+	EraseUser(ctx context.Context, erasure *entities.UserErasure) error
+}
+
+type PrivacyOrders interface {
+	FindUserOrders(ctx context.Context, userID int) ([]entities.Order, error)
+	CountPendingOrders(ctx context.Context, userID int64) (int, error)
+}
+
+type PrivacyWallets interface {
+	GetAllTrackedWalletsForUser(ctx context.Context, userID int64) ([]entities.Wallet, error)
+}
+
+type PrivacyTransactions interface {
+	FindTransactionsByWallet(ctx context.Context, walletAddress string) ([]entities.Transaction, error)
+}
+
+type PrivacyRefunds interface {
+	FindByUserID(ctx context.Context, userID int64) ([]entities.Refund, error)
+}
+
+type PrivacyInvoices interface {
+	FindByMerchant(ctx context.Context, merchantID int64, limit int) ([]entities.Invoice, error)
+}
+
+type PrivacySessions interface {
+	FindByUserID(ctx context.Context, userID int64, activeOnly bool, limit int) ([]entities.Session, error)
+}
+
+type PrivacyTwoFactor interface {
+	FindByUserID(ctx context.Context, userID int64) (*entities.TwoFactorSecret, error)
+}
+
+var (
+	_ PrivacyRepository   = (*repository.PrivacyRepository)(nil)
+	_ PrivacyOrders       = (*repository.OrdersRepository)(nil)
+	_ PrivacyWallets      = (*repository.WalletsRepository)(nil)
+	_ PrivacyTransactions = (*repository.TransactionsRepository)(nil)
+	_ PrivacyRefunds      = (*repository.RefundsRepository)(nil)
+	_ PrivacyInvoices     = (*repository.InvoicesRepository)(nil)
+	_ PrivacySessions     = (*repository.SessionsRepository)(nil)
+	_ PrivacyTwoFactor    = (*repository.TwoFactorRepository)(nil)
+)
+
+// PrivacyService обрабатывает запросы субъекта данных: выгрузку всех данных пользователя
+// и обезличивание персональных полей с сохранением финансовых записей для комплаенса
+type PrivacyService struct {
+	logger       *slog.Logger
+	repo         PrivacyRepository
+	orders       PrivacyOrders
+	wallets      PrivacyWallets
+	transactions PrivacyTransactions
+	refunds      PrivacyRefunds
+	invoices     PrivacyInvoices
+	sessions     PrivacySessions
+	twoFactor    PrivacyTwoFactor
+	audit        *AuditService
+
+	// Раскрывать заметки AML проверок в выгрузке, если это допускает юрисдикция
+	exportAMLNotes bool
+}
+
+func NewPrivacyService(
+	logger *slog.Logger,
+	repo PrivacyRepository,
+	orders PrivacyOrders,
+	wallets PrivacyWallets,
+	transactions PrivacyTransactions,
+	refunds PrivacyRefunds,
+	invoices PrivacyInvoices,
+	sessions PrivacySessions,
+	twoFactor PrivacyTwoFactor,
+	audit *AuditService,
+	exportAMLNotes bool,
+) *PrivacyService {
+	return &PrivacyService{
+		logger:         logger,
+		repo:           repo,
+		orders:         orders,
+		wallets:        wallets,
+		transactions:   transactions,
+		refunds:        refunds,
+		invoices:       invoices,
+		sessions:       sessions,
+		twoFactor:      twoFactor,
+		audit:          audit,
+		exportAMLNotes: exportAMLNotes,
+	}
+}
+
+// ExportUserData collects everything stored about the user. Derivation paths and secrets are never exported.
+func (s *PrivacyService) ExportUserData(ctx context.Context, userID int64, actor string) (*entities.UserDataExport, error) {
+	export := &entities.UserDataExport{
+		UserID:           userID,
+		GeneratedAt:      time.Now().UTC(),
+		AMLNotesIncluded: s.exportAMLNotes,
+	}
+
+	orders, err := s.orders.FindUserOrders(ctx, int(userID))
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		if !s.exportAMLNotes {
+			orders[i].AMLNotes = nil
+		}
+	}
+	export.Orders = orders
+
+	wallets, err := s.wallets.GetAllTrackedWalletsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Wallets = make([]entities.WalletDetailExtended, 0, len(wallets))
+	export.Transactions = []entities.Transaction{}
+	for _, wallet := range wallets {
+		export.Wallets = append(export.Wallets, entities.WalletDetailExtended{
+			ID:        int64(wallet.ID),
+			UserID:    wallet.UserID,
+			Address:   wallet.Address,
+			Chain:     wallet.Chain,
+			Network:   wallet.Network,
+			IsTestnet: wallet.IsTestnet,
+			CreatedAt: wallet.CreatedAt,
+		})
+
+		transactions, err := s.transactions.FindTransactionsByWallet(ctx, wallet.Address)
+		if err != nil {
+			return nil, err
+		}
+		export.Transactions = append(export.Transactions, transactions...)
+	}
+
+	if export.Refunds, err = s.refunds.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Invoices, err = s.invoices.FindByMerchant(ctx, userID, privacyExportLimit); err != nil {
+		return nil, err
+	}
+	if export.Sessions, err = s.sessions.FindByUserID(ctx, userID, false, privacyExportLimit); err != nil {
+		return nil, err
+	}
+	if export.TwoFactor, err = s.twoFactor.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.AuditEvents, err = s.repo.FindUserAuditEvents(ctx, userID, privacyExportLimit); err != nil {
+		return nil, err
+	}
+	if export.Erasure, err = s.repo.FindErasure(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err = s.audit.Record(ctx, entities.AuditEventUserDataExported, actor, strconv.FormatInt(userID, 10), map[string]any{
+		"orders":           len(export.Orders),
+		"wallets":          len(export.Wallets),
+		"transactions":     len(export.Transactions),
+		"aml_notes_export": s.exportAMLNotes,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record user data export audit", "error", err, "user_id", userID)
+	}
+
+	return export, nil
+}
+
+// EraseUserData anonymizes personal fields of the user: sessions lose IP, user agent, location and device,
+// the two-factor secret is removed and audit events are redacted. Orders, wallets, transactions, refunds
+// and AML results are retained as financial records. Erasure is refused while orders or refunds are open.
+func (s *PrivacyService) EraseUserData(ctx context.Context, userID int64, reason, actor string) (*entities.UserErasure, error) {
+	pendingOrders, err := s.orders.CountPendingOrders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	openRefunds, err := s.repo.CountOpenRefunds(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pendingOrders > 0 || openRefunds > 0 {
+		return nil, ErrErasureBlocked
+	}
+
+	erasure := &entities.UserErasure{
+		UserID:      userID,
+		RequestedBy: actor,
+		Reason:      strings.TrimSpace(reason),
+	}
+	if err = s.repo.EraseUser(ctx, erasure); err != nil {
+		return nil, err
+	}
+
+	// Событие не содержит персональных данных: только идентификатор и счетчики
+	if err = s.audit.Record(ctx, entities.AuditEventUserErased, actor, strconv.FormatInt(userID, 10), map[string]any{
+		"reason":                erasure.Reason,
+		"sessions_anonymized":   erasure.SessionsAnonymized,
+		"audit_events_redacted": erasure.AuditEventsRedacted,
+		"two_factor_removed":    erasure.TwoFactorRemoved,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record user erasure audit", "error", err, "user_id", userID)
+	}
+
+	s.logger.InfoContext(ctx, "User personal data erased",
+		"user_id", userID,
+		"sessions_anonymized", erasure.SessionsAnonymized,
+		"audit_events_redacted", erasure.AuditEventsRedacted,
+		"two_factor_removed", erasure.TwoFactorRemoved,
+		"erased_by", actor)
+
+	return erasure, nil
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RateProvider возвращает курс актива: сколько единиц фиатной валюты стоит одна единица актива
+type RateProvider interface {
+	Rate(ctx context.Context, asset, fiat string) (*big.Rat, error)
+}
+
+// StaticRateProvider отдает курсы из конфигурации. Используется, пока не подключен внешний источник котировок.
+type StaticRateProvider struct {
+	rates map[string]*big.Rat // "USDT/RUB" -> rate
+}
+
+// NewStaticRateProvider parses rates in the form "USDT/USD=1", "USDT/RUB=92.5"
+func NewStaticRateProvider(rates []string) (*StaticRateProvider, error) {
+	p := &StaticRateProvider{rates: make(map[string]*big.Rat, len(rates))}
+	for _, entry := range rates {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pair, value, ok := strings.Cut(entry, "=")
+		asset, fiat, okPair := strings.Cut(pair, "/")
+		if !ok || !okPair {
+			return nil, fmt.Errorf("invalid rate %q, expected ASSET/FIAT=rate", entry)
+		}
+
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid rate value in %q", entry)
+		}
+
+		p.rates[rateKey(asset, fiat)] = rate
+	}
+
+	return p, nil
+}
+
+func (p *StaticRateProvider) Rate(_ context.Context, asset, fiat string) (*big.Rat, error) {
+	rate, ok := p.rates[rateKey(asset, fiat)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, asset, fiat)
+	}
+	return new(big.Rat).Set(rate), nil
+}
+
+func rateKey(asset, fiat string) string {
+	return strings.ToUpper(strings.TrimSpace(asset)) + "/" + strings.ToUpper(strings.TrimSpace(fiat))
+}
@@ -0,0 +1,325 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	// Квитанций, отправляемых за один проход
+	receiptBatchSize = 100
+	// Повторная отправка квитанции не чаще этого интервала
+	receiptResendCooldown = time.Minute
+
+	maxReceiptBrandNameLength = 100
+	maxReceiptTemplateLength  = 10000
+	// Название в квитанции, если мерчант не задал свое
+	defaultReceiptBrandName = "P2P Exchange"
+)
+
+const (
+	defaultReceiptSubject = `{{.BrandName}}: payment received for order #{{.OrderID}}`
+	defaultReceiptBody    = `Payment for order #{{.OrderID}} has been received.
+
+Amount: {{.Amount}} {{.Asset}}
+{{- if .PaidAmount}}
+Paid: {{.PaidAmount}} {{.Asset}}
+{{- end}}
+Transaction: {{.TxHash}}
+{{- if .ExplorerURL}}
+View in explorer: {{.ExplorerURL}}
+{{- end}}
+Created: {{.CreatedAt}}
+Paid at: {{.CompletedAt}}
+
+Thank you for using {{.BrandName}}.`
+)
+
+var (
+	defaultReceiptSubjectTemplate = template.Must(newReceiptTemplate("subject", defaultReceiptSubject))
+	defaultReceiptBodyTemplate    = template.Must(newReceiptTemplate("body", defaultReceiptBody))
+)
+
+type ReceiptsRepository interface {
+	FindPendingReceipts(ctx context.Context, limit int) ([]entities.ReceiptDetails, error)
+	MarkSent(ctx context.Context, orderID int) error
+	MarkAttemptFailed(ctx context.Context, orderID int, reason string, giveUp bool) error
+	FindUserReceipt(ctx context.Context, orderID int, userID int64) (*entities.OrderReceipt, error)
+	RequeueReceipt(ctx context.Context, orderID int, userID int64) (*entities.OrderReceipt, error)
+	FindBranding(ctx context.Context, merchantID int64) (*entities.ReceiptBranding, error)
+	SaveBranding(ctx context.Context, branding *entities.ReceiptBranding) error
+}
+
+// ReceiptAssets — актив по умолчанию для ордеров, созданных до реестра активов
+type ReceiptAssets interface {
+	Default() entities.Asset
+}
+
+var (
+	_ ReceiptsRepository = (*repository.ReceiptsRepository)(nil)
+	_ ReceiptAssets      = (*AssetRegistry)(nil)
+)
+
+// ReceiptConfig задает период отправки квитанций и число попыток доставки
+type ReceiptConfig struct {
+	Interval    time.Duration
+	MaxAttempts int
+}
+
+// ReceiptView — данные, доступные в шаблонах квитанции
+type ReceiptView struct {
+	BrandName   string
+	OrderID     int
+	Amount      string
+	PaidAmount  string
+	Asset       string
+	TxHash      string
+	ExplorerURL string
+	CreatedAt   string
+	CompletedAt string
+}
+
+// ReceiptService sends receipts of completed orders through the notifier. Receipts are queued
+// when an order is completed and rendered with the merchant's branding templates.
+type ReceiptService struct {
+	logger   *slog.Logger
+	repo     ReceiptsRepository
+	assets   ReceiptAssets
+	links    ExplorerLinks
+	notifier Notifier
+
+	interval    time.Duration
+	maxAttempts int
+	wake        chan struct{}
+}
+
+func NewReceiptService(logger *slog.Logger, repo ReceiptsRepository, assets ReceiptAssets, links ExplorerLinks, notifier Notifier, config ReceiptConfig) (*ReceiptService, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("receipt interval must be positive")
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("receipt attempts must be positive")
+	}
+
+	return &ReceiptService{
+		logger:      logger,
+		repo:        repo,
+		assets:      assets,
+		links:       links,
+		notifier:    notifier,
+		interval:    config.Interval,
+		maxAttempts: config.MaxAttempts,
+		wake:        make(chan struct{}, 1),
+	}, nil
+}
+
+// Start periodically sends queued receipts until ctx is cancelled. Wake sends them before the next interval.
+func (s *ReceiptService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.SendPending(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SendPending(ctx)
+		case <-s.wake:
+			s.SendPending(ctx)
+		}
+	}
+}
+
+// Wake sends the queued receipts right away, e.g. after orders were paid
+func (s *ReceiptService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SendPending renders and sends queued receipts. A receipt that could not be sent is retried
+// on the next pass until the attempts are exhausted.
+func (s *ReceiptService) SendPending(ctx context.Context) {
+	receipts, err := s.repo.FindPendingReceipts(ctx, receiptBatchSize)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to find pending receipts", "error", err)
+		return
+	}
+
+	for _, receipt := range receipts {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err = s.send(ctx, receipt)
+		if err == nil {
+			if err = s.repo.MarkSent(ctx, receipt.OrderID); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to mark receipt sent", "error", err, "order_id", receipt.OrderID)
+			}
+			continue
+		}
+
+		giveUp := receipt.Attempts+1 >= s.maxAttempts
+		s.logger.WarnContext(ctx, "Failed to send receipt", "error", err, "order_id", receipt.OrderID,
+			"attempt", receipt.Attempts+1, "give_up", giveUp)
+		if err = s.repo.MarkAttemptFailed(ctx, receipt.OrderID, err.Error(), giveUp); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record receipt error", "error", err, "order_id", receipt.OrderID)
+		}
+	}
+}
+
+func (s *ReceiptService) send(ctx context.Context, receipt entities.ReceiptDetails) error {
+	branding, err := s.repo.FindBranding(ctx, receipt.UserID)
+	if err != nil {
+		return err
+	}
+
+	subject, body, err := s.render(receipt, branding)
+	if err != nil {
+		return err
+	}
+
+	if err = s.notifier.Notify(ctx, receipt.UserID, subject, body); err != nil {
+		return fmt.Errorf("failed to deliver receipt: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Receipt sent", "order_id", receipt.OrderID, "user_id", receipt.UserID)
+	return nil
+}
+
+// ResendReceipt queues the receipt of the user's completed order for sending again
+func (s *ReceiptService) ResendReceipt(ctx context.Context, userID int64, orderID int) (*entities.OrderReceipt, error) {
+	current, err := s.repo.FindUserReceipt(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		if current.Status == entities.OrderReceiptPending {
+			return current, nil
+		}
+		if time.Since(current.UpdatedAt) < receiptResendCooldown {
+			return nil, &CooldownError{RetryAfter: receiptResendCooldown - time.Since(current.UpdatedAt)}
+		}
+	}
+
+	receipt, err := s.repo.RequeueReceipt(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, ErrReceiptNotAvailable
+	}
+
+	s.logger.InfoContext(ctx, "Receipt queued for resending", "order_id", orderID, "user_id", userID)
+	return receipt, nil
+}
+
+// GetBranding returns the receipt branding of the merchant, the defaults if it is not configured
+func (s *ReceiptService) GetBranding(ctx context.Context, merchantID int64) (*entities.ReceiptBranding, error) {
+	branding, err := s.repo.FindBranding(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if branding == nil {
+		branding = &entities.ReceiptBranding{MerchantID: merchantID}
+	}
+	return branding, nil
+}
+
+// SaveBranding validates the templates by rendering a sample receipt and stores the branding of the merchant
+func (s *ReceiptService) SaveBranding(ctx context.Context, branding entities.ReceiptBranding) (*entities.ReceiptBranding, error) {
+	branding.BrandName = strings.TrimSpace(branding.BrandName)
+	if len(branding.BrandName) > maxReceiptBrandNameLength {
+		return nil, fmt.Errorf("%w: brand name is longer than %d characters", ErrInvalidReceiptBranding, maxReceiptBrandNameLength)
+	}
+	if len(branding.SubjectTemplate) > maxReceiptTemplateLength || len(branding.BodyTemplate) > maxReceiptTemplateLength {
+		return nil, fmt.Errorf("%w: templates are limited to %d characters", ErrInvalidReceiptBranding, maxReceiptTemplateLength)
+	}
+
+	sample := entities.ReceiptDetails{
+		OrderID:   1,
+		Asset:     DefaultAssetCode,
+		Chain:     entities.ChainBSC,
+		Network:   entities.NetworkMainnet,
+		CreatedAt: time.Now(),
+	}
+	if _, _, err := s.render(sample, &branding); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReceiptBranding, err)
+	}
+
+	if err := s.repo.SaveBranding(ctx, &branding); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Receipt branding saved", "merchant_id", branding.MerchantID)
+	return &branding, nil
+}
+
+// render формирует тему и текст квитанции по шаблонам мерчанта или шаблонам по умолчанию
+func (s *ReceiptService) render(receipt entities.ReceiptDetails, branding *entities.ReceiptBranding) (string, string, error) {
+	subjectTemplate, bodyTemplate := defaultReceiptSubjectTemplate, defaultReceiptBodyTemplate
+	view := ReceiptView{
+		BrandName: defaultReceiptBrandName,
+		OrderID:   receipt.OrderID,
+		Amount:    receipt.Amount.String(),
+		Asset:     receipt.Asset,
+		CreatedAt: receipt.CreatedAt.UTC().Format(time.RFC1123),
+	}
+
+	if branding != nil {
+		var err error
+		if branding.BrandName != "" {
+			view.BrandName = branding.BrandName
+		}
+		if branding.SubjectTemplate != "" {
+			if subjectTemplate, err = newReceiptTemplate("subject", branding.SubjectTemplate); err != nil {
+				return "", "", fmt.Errorf("invalid subject template: %w", err)
+			}
+		}
+		if branding.BodyTemplate != "" {
+			if bodyTemplate, err = newReceiptTemplate("body", branding.BodyTemplate); err != nil {
+				return "", "", fmt.Errorf("invalid body template: %w", err)
+			}
+		}
+	}
+
+	// Ордера до реестра активов относятся к активу по умолчанию
+	if view.Asset == "" {
+		asset := s.assets.Default()
+		view.Asset, receipt.Chain, receipt.Network = asset.Code, asset.Chain, asset.Network
+	}
+	if receipt.PaidAmount != nil && !receipt.PaidAmount.Equal(receipt.Amount) {
+		view.PaidAmount = receipt.PaidAmount.String()
+	}
+	if receipt.TxHash != nil {
+		view.TxHash = *receipt.TxHash
+		view.ExplorerURL = s.links.TxURL(string(receipt.Chain), receipt.Network, view.TxHash)
+	}
+	if receipt.CompletedAt != nil {
+		view.CompletedAt = receipt.CompletedAt.UTC().Format(time.RFC1123)
+	}
+
+	var subject, body strings.Builder
+	if err := subjectTemplate.Execute(&subject, view); err != nil {
+		return "", "", fmt.Errorf("failed to render receipt subject: %w", err)
+	}
+	if err := bodyTemplate.Execute(&body, view); err != nil {
+		return "", "", fmt.Errorf("failed to render receipt body: %w", err)
+	}
+
+	// Тема письма — одна строка
+	return strings.Join(strings.Fields(subject.String()), " "), body.String(), nil
+}
+
+func newReceiptTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
@@ -0,0 +1,365 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/sftp"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/webhook"
+)
+
+const (
+	// reconciliationRetryDays — сколько дней повторяется доставка неотправленных сверок
+	reconciliationRetryDays = 7
+	reconciliationListLimit = 60
+	// reconciliationCheckInterval — как часто проверяется готовность сверки и повторяются неудачные доставки
+	reconciliationCheckInterval = 15 * time.Minute
+)
+
+type ReconciliationLedger interface {
+	Summarize(ctx context.Context, from, to time.Time, period entities.PnLPeriod) ([]entities.LedgerSummary, error)
+}
+
+type ReconciliationDeposits interface {
+	SummarizeDeposits(ctx context.Context, from, to time.Time) ([]entities.DepositSummary, error)
+}
+
+type ReconciliationReportsRepository interface {
+	SaveReport(ctx context.Context, report *entities.ReconciliationReport) error
+	FindReport(ctx context.Context, day time.Time) (*entities.ReconciliationReport, error)
+	FindReports(ctx context.Context, limit int) ([]entities.ReconciliationReport, error)
+	FindUndeliveredReports(ctx context.Context, since time.Time) ([]entities.ReconciliationReport, error)
+	RecordDelivery(ctx context.Context, day time.Time, deliveryErr *string) error
+}
+
+type ReconciliationWebhook interface {
+	Send(ctx context.Context, url string, body []byte, signature string) error
+}
+
+type ReconciliationUploader interface {
+	Upload(ctx context.Context, path string, data []byte) error
+}
+
+var (
+	_ ReconciliationLedger            = (*repository.LedgerRepository)(nil)
+	_ ReconciliationDeposits          = (*repository.TransactionsRepository)(nil)
+	_ ReconciliationReportsRepository = (*repository.ReconciliationRepository)(nil)
+	_ ReconciliationWebhook           = (*webhook.Client)(nil)
+	_ ReconciliationUploader          = (*sftp.Uploader)(nil)
+)
+
+// ReconciliationConfig задает доставку сверок. Сверка дня отправляется после Hour часов UTC следующего дня.
+type ReconciliationConfig struct {
+	WebhookURL    string
+	SigningSecret string
+	SFTPDirectory string
+	Hour          int
+}
+
+// ReconciliationService sends the daily signed reconciliation summary to accounting: deposits, withdrawals,
+// refunds, collected fees and gas per asset for a UTC day, built from the deposits and the outgoing ledger.
+// The summary is signed with HMAC-SHA256 and posted to the webhook and/or uploaded to the SFTP target;
+// failed deliveries are retried with the same payload for a week.
+type ReconciliationService struct {
+	logger   *slog.Logger
+	ledger   ReconciliationLedger
+	deposits ReconciliationDeposits
+	reports  ReconciliationReportsRepository
+	assets   LedgerAssets
+	webhook  ReconciliationWebhook
+	uploader ReconciliationUploader
+
+	webhookURL    string
+	secret        string
+	sftpDirectory string
+	hour          int
+}
+
+// NewReconciliationService creates the service. webhook or uploader may be nil, but not both.
+func NewReconciliationService(
+	logger *slog.Logger,
+	ledger ReconciliationLedger,
+	deposits ReconciliationDeposits,
+	reports ReconciliationReportsRepository,
+	assets LedgerAssets,
+	webhook ReconciliationWebhook,
+	uploader ReconciliationUploader,
+	config ReconciliationConfig,
+) (*ReconciliationService, error) {
+	if webhook != nil && config.WebhookURL == "" {
+		webhook = nil
+	}
+	if webhook == nil && uploader == nil {
+		return nil, errors.New("reconciliation requires a webhook URL or an SFTP target")
+	}
+	if config.SigningSecret == "" {
+		return nil, errors.New("reconciliation signing secret is required")
+	}
+	if config.Hour < 0 || config.Hour > 23 {
+		return nil, fmt.Errorf("reconciliation hour must be between 0 and 23, got %d", config.Hour)
+	}
+
+	return &ReconciliationService{
+		logger:        logger,
+		ledger:        ledger,
+		deposits:      deposits,
+		reports:       reports,
+		assets:        assets,
+		webhook:       webhook,
+		uploader:      uploader,
+		webhookURL:    config.WebhookURL,
+		secret:        config.SigningSecret,
+		sftpDirectory: config.SFTPDirectory,
+		hour:          config.Hour,
+	}, nil
+}
+
+// Start sends due reconciliations and retries failed deliveries until ctx is cancelled
+func (s *ReconciliationService) Start(ctx context.Context) {
+	ticker := time.NewTicker(reconciliationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunDue(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Reconciliation run failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunDue generates the reconciliation of the last due day if it does not exist yet
+// and delivers every undelivered report of the retry window
+func (s *ReconciliationService) RunDue(ctx context.Context) error {
+	now := time.Now().UTC()
+	due := now.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	if now.Hour() < s.hour {
+		due = due.AddDate(0, 0, -1)
+	}
+
+	existing, err := s.reports.FindReport(ctx, due)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		if _, err = s.generate(ctx, due); err != nil {
+			return err
+		}
+	}
+
+	pending, err := s.reports.FindUndeliveredReports(ctx, due.AddDate(0, 0, -reconciliationRetryDays))
+	if err != nil {
+		return err
+	}
+	for _, report := range pending {
+		s.deliver(ctx, report)
+	}
+	return nil
+}
+
+// GetReports returns the latest reconciliation reports with their delivery state
+func (s *ReconciliationService) GetReports(ctx context.Context) ([]entities.ReconciliationReport, error) {
+	return s.reports.FindReports(ctx, reconciliationListLimit)
+}
+
+// Preview builds the summary of the day from current data without storing or sending it
+func (s *ReconciliationService) Preview(ctx context.Context, day time.Time) (*entities.ReconciliationSummary, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+	if !day.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		return nil, fmt.Errorf("%w: reconciliation is available for past days only", ErrInvalidReportRequest)
+	}
+	return s.summarize(ctx, day)
+}
+
+// Resend regenerates the reconciliation of a past day from current data and delivers it right away
+func (s *ReconciliationService) Resend(ctx context.Context, day time.Time) (*entities.ReconciliationReport, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+	if !day.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		return nil, fmt.Errorf("%w: reconciliation is available for past days only", ErrInvalidReportRequest)
+	}
+
+	report, err := s.generate(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(ctx, *report)
+	return s.reports.FindReport(ctx, day)
+}
+
+// generate строит и подписывает сводку дня и сохраняет ее для доставки
+func (s *ReconciliationService) generate(ctx context.Context, day time.Time) (*entities.ReconciliationReport, error) {
+	summary, err := s.summarize(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode reconciliation: %w", err)
+	}
+
+	report := &entities.ReconciliationReport{
+		Day:       day,
+		Payload:   string(payload),
+		Signature: webhook.Sign(s.secret, payload),
+	}
+	if err = s.reports.SaveReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Reconciliation generated", "day", summary.Day, "assets", len(summary.Assets))
+	return report, nil
+}
+
+// deliver отправляет сохраненную сводку во все настроенные каналы и записывает результат попытки
+func (s *ReconciliationService) deliver(ctx context.Context, report entities.ReconciliationReport) {
+	payload := []byte(report.Payload)
+	day := report.Day.Format(time.DateOnly)
+
+	var errs []error
+	if s.webhook != nil {
+		if err := s.webhook.Send(ctx, s.webhookURL, payload, report.Signature); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if s.uploader != nil {
+		// Подпись загружается первой: файл сверки появляется, когда ее уже можно проверить
+		name := path.Join(s.sftpDirectory, "reconciliation_"+report.Day.Format("20060102")+".json")
+		err := s.uploader.Upload(ctx, name+".sig", []byte("sha256="+report.Signature+"\n"))
+		if err == nil {
+			err = s.uploader.Upload(ctx, name, payload)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sftp: %w", err))
+		}
+	}
+
+	var deliveryErr *string
+	if err := errors.Join(errs...); err != nil {
+		message := err.Error()
+		deliveryErr = &message
+		s.logger.ErrorContext(ctx, "Reconciliation delivery failed", "error", err, "day", day, "attempt", report.Attempts+1)
+	} else {
+		s.logger.InfoContext(ctx, "Reconciliation delivered", "day", day)
+	}
+
+	if err := s.reports.RecordDelivery(ctx, report.Day, deliveryErr); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record reconciliation delivery", "error", err, "day", day)
+	}
+}
+
+// summarize собирает итоги дня по активам из депозитов и журнала исходящих транзакций
+func (s *ReconciliationService) summarize(ctx context.Context, day time.Time) (*entities.ReconciliationSummary, error) {
+	from, to := day, day.AddDate(0, 0, 1)
+
+	deposits, err := s.deposits.SummarizeDeposits(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := s.ledger.Summarize(ctx, from, to, entities.PnLPeriodDay)
+	if err != nil {
+		return nil, err
+	}
+
+	type totals struct {
+		row                                  entities.ReconciliationAsset
+		decimals                             int
+		deposits, withdrawals, refunds, fees *big.Int
+		gas                                  *big.Int
+	}
+	type assetKey struct {
+		assetID int
+		chain   entities.Chain
+		network string
+	}
+
+	var keys []assetKey
+	acc := make(map[assetKey]*totals)
+	get := func(key assetKey) *totals {
+		if t, ok := acc[key]; ok {
+			return t
+		}
+		t := &totals{
+			row:         entities.ReconciliationAsset{Chain: key.chain, Network: key.network},
+			deposits:    new(big.Int),
+			withdrawals: new(big.Int),
+			refunds:     new(big.Int),
+			fees:        new(big.Int),
+			gas:         new(big.Int),
+		}
+		if asset, err := s.assets.FindByID(key.assetID); err == nil {
+			t.row.Asset, t.row.Chain, t.row.Network, t.decimals = asset.Code, asset.Chain, asset.Network, asset.Decimals
+		}
+		acc[key] = t
+		keys = append(keys, key)
+		return t
+	}
+	keyOf := func(assetID int, chain entities.Chain, network string) assetKey {
+		if asset, err := s.assets.FindByID(assetID); err == nil {
+			return assetKey{assetID: asset.ID, chain: asset.Chain, network: asset.Network}
+		}
+		return assetKey{chain: chain, network: network}
+	}
+
+	for _, summary := range deposits {
+		volume, ok := new(big.Int).SetString(summary.Volume, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid deposit volume %q", summary.Volume)
+		}
+		t := get(keyOf(summary.AssetID, summary.Chain, summary.Network))
+		t.row.Deposits += summary.Deposits
+		t.deposits.Add(t.deposits, volume)
+	}
+
+	for _, summary := range ledger {
+		volume, fees, gas, err := parseLedgerSummary(summary)
+		if err != nil {
+			return nil, err
+		}
+		t := get(keyOf(summary.AssetID, "", ""))
+		t.gas.Add(t.gas, gas)
+		t.fees.Add(t.fees, fees)
+		switch summary.Kind {
+		case entities.LedgerKindWithdrawal:
+			t.row.Withdrawals += summary.Transactions
+			t.withdrawals.Add(t.withdrawals, volume)
+		case entities.LedgerKindRefund:
+			t.row.Refunds += summary.Transactions
+			t.refunds.Add(t.refunds, volume)
+		}
+	}
+
+	summary := &entities.ReconciliationSummary{
+		Day:         day.Format(time.DateOnly),
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now().UTC(),
+		Assets:      make([]entities.ReconciliationAsset, 0, len(keys)),
+	}
+	for _, key := range keys {
+		t := acc[key]
+		row := t.row
+		row.DepositVolume = unitsToTokenAmount(t.deposits, t.decimals)
+		row.WithdrawalVolume = unitsToTokenAmount(t.withdrawals, t.decimals)
+		row.RefundVolume = unitsToTokenAmount(t.refunds, t.decimals)
+		row.FeesCollected = unitsToTokenAmount(t.fees, t.decimals)
+		row.GasSpent = unitsToTokenAmount(t.gas, bnbDecimals)
+		if row.Asset == "" {
+			row.Asset = strings.ToUpper(string(row.Chain))
+		}
+		summary.Assets = append(summary.Assets, row)
+	}
+
+	return summary, nil
+}
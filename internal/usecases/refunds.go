@@ -0,0 +1,496 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	refundsListLimit      = 100
+	refundProcessInterval = time.Minute
+	// Время после отправки, в течение которого занятый нонс без квитанции не считается вытеснением:
+	// узел может отдать нонс раньше, чем проиндексирует квитанцию
+	refundNonceGracePeriod = 10 * time.Minute
+)
+
+type RefundsRepository interface {
+	WithinDepositLock(ctx context.Context, depositTxHash string, fn func(ctx context.Context) error) error
+	SumActive(ctx context.Context, depositTxHash, excludeID string) (string, error)
+	CreateRefund(ctx context.Context, refund *entities.Refund) (bool, error)
+	FindByID(ctx context.Context, id string) (*entities.Refund, error)
+	FindByUserID(ctx context.Context, userID int64) ([]entities.Refund, error)
+	FindByStatus(ctx context.Context, status entities.RefundStatus, limit int) ([]entities.Refund, error)
+	TransitionStatus(ctx context.Context, id string, to entities.RefundStatus, from ...entities.RefundStatus) (bool, error)
+	MarkSent(ctx context.Context, id, refundTxHash string, nonce *int64) error
+	MarkUnknown(ctx context.Context, id, refundTxHash string, nonce *int64, reason string) error
+	ReplaceTxHash(ctx context.Context, oldTxHash, newTxHash string) error
+	MarkCompleted(ctx context.Context, id, refundTxHash string) error
+	MarkFailed(ctx context.Context, id, reason string) error
+}
+
+type RefundTransactionsRepository interface {
+	FindTransactionByHash(ctx context.Context, txHash string) (*entities.Transaction, error)
+}
+
+type RefundWalletsRepository interface {
+	FindWalletByAddress(ctx context.Context, address string) (*entities.Wallet, error)
+}
+
+// RefundTransfer отправляет USDT с депозитного кошелька
+type RefundTransfer interface {
+	TransferFunds(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (string, error)
+	TrackedNonce(txHash string) (uint64, bool)
+	Asset() entities.Asset
+}
+
+var (
+	_ RefundsRepository            = (*repository.RefundsRepository)(nil)
+	_ RefundTransactionsRepository = (*repository.TransactionsRepository)(nil)
+	_ RefundWalletsRepository      = (*repository.WalletsRepository)(nil)
+	_ RefundTransfer               = (*WalletService)(nil)
+	_ TxReplacementObserver        = (*RefundService)(nil)
+)
+
+// RefundService управляет возвратами депозитов: создание по правилам (AML отказ, переплата)
+// или администратором, исполнение переводом с депозитного кошелька на адрес отправителя
+type RefundService struct {
+	logger       *slog.Logger
+	repo         RefundsRepository
+	transactions RefundTransactionsRepository
+	wallets      RefundWalletsRepository
+	transfer     RefundTransfer
+	audit        *AuditService
+	notifier     Notifier
+	links        ExplorerLinks
+
+	// Исполнять возвраты автоматически, без подтверждения администратора
+	autoExecute bool
+}
+
+func NewRefundService(
+	logger *slog.Logger,
+	repo RefundsRepository,
+	transactions RefundTransactionsRepository,
+	wallets RefundWalletsRepository,
+	transfer RefundTransfer,
+	audit *AuditService,
+	notifier Notifier,
+	links ExplorerLinks,
+	autoExecute bool,
+) *RefundService {
+	return &RefundService{
+		logger:       logger,
+		repo:         repo,
+		transactions: transactions,
+		wallets:      wallets,
+		transfer:     transfer,
+		audit:        audit,
+		notifier:     notifier,
+		links:        links,
+		autoExecute:  autoExecute,
+	}
+}
+
+// RequestRefund создает возврат депозита. amount == nil означает возврат всей суммы депозита.
+func (s *RefundService) RequestRefund(ctx context.Context, depositTxHash string, amount *big.Int, reason entities.RefundReason, initiatedBy string) (*entities.Refund, error) {
+	deposit, err := s.transactions.FindTransactionByHash(ctx, depositTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if deposit == nil {
+		return nil, ErrRefundDepositNotFound
+	}
+	if !common.IsHexAddress(deposit.FromAddress) {
+		return nil, fmt.Errorf("%w: sender address of the deposit is unknown", ErrRefundNotAllowed)
+	}
+
+	depositAmount, ok := new(big.Int).SetString(deposit.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount of deposit %s", depositTxHash)
+	}
+	if amount == nil {
+		amount = depositAmount
+	}
+	if amount.Sign() <= 0 || amount.Cmp(depositAmount) > 0 {
+		return nil, fmt.Errorf("%w: amount must be positive and not exceed the deposit", ErrRefundNotAllowed)
+	}
+
+	wallet, err := s.wallets.FindWalletByAddress(ctx, deposit.WalletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("deposit wallet %s not found", deposit.WalletAddress)
+	}
+
+	refund := &entities.Refund{
+		ID:            uuid.New().String(),
+		UserID:        wallet.UserID,
+		DepositTxHash: deposit.TxHash,
+		WalletAddress: deposit.WalletAddress,
+		ToAddress:     deposit.FromAddress,
+		Amount:        amount.String(),
+		Reason:        reason,
+		Status:        entities.RefundStatusPending,
+		InitiatedBy:   initiatedBy,
+	}
+
+	// Сумма с другими возвратами депозита проверяется под блокировкой депозита: автоматический возврат переплаты
+	// и ручной возврат вместе не должны превысить депозит
+	var created bool
+	err = s.repo.WithinDepositLock(ctx, deposit.TxHash, func(ctx context.Context) error {
+		if err := s.checkRefundable(ctx, deposit.TxHash, "", amount, depositAmount); err != nil {
+			return err
+		}
+		created, err = s.repo.CreateRefund(ctx, refund)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrRefundExists
+	}
+
+	s.logger.InfoContext(ctx, "Refund requested",
+		"refund_id", refund.ID,
+		"deposit_tx_hash", refund.DepositTxHash,
+		"to", refund.ToAddress,
+		"amount", refund.Amount,
+		"reason", refund.Reason,
+		"initiated_by", initiatedBy)
+
+	s.notify(ctx, refund, "Refund requested",
+		fmt.Sprintf("A refund of %s USDT for deposit %s to %s has been created", WeiToEther(amount).StringFixed(6), refund.DepositTxHash, refund.ToAddress))
+
+	return refund, nil
+}
+
+// ExecuteRefund отправляет транзакцию возврата. Повторное исполнение возможно для pending и failed возвратов.
+// Возврат завершается по квитанции транзакции, см. resolveRefunds.
+func (s *RefundService) ExecuteRefund(ctx context.Context, refundID, executedBy string) (*entities.Refund, error) {
+	refund, err := s.repo.FindByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, ErrRefundNotFound
+	}
+
+	deposit, err := s.transactions.FindTransactionByHash(ctx, refund.DepositTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if deposit == nil {
+		return nil, ErrRefundDepositNotFound
+	}
+	depositAmount, ok := new(big.Int).SetString(deposit.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount of deposit %s", refund.DepositTxHash)
+	}
+	amount, ok := new(big.Int).SetString(refund.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount of refund %s", refund.ID)
+	}
+
+	// Переход в processing защищает от двойной отправки при параллельных запросах.
+	// Пока возврат был failed, по депозиту могли создать другие возвраты: повтор не должен превысить депозит.
+	var locked bool
+	err = s.repo.WithinDepositLock(ctx, refund.DepositTxHash, func(ctx context.Context) error {
+		if err := s.checkRefundable(ctx, refund.DepositTxHash, refund.ID, amount, depositAmount); err != nil {
+			return err
+		}
+		locked, err = s.repo.TransitionStatus(ctx, refund.ID, entities.RefundStatusProcessing, entities.RefundStatusPending, entities.RefundStatusFailed)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("%w: refund is %s", ErrRefundNotAllowed, refund.Status)
+	}
+
+	txHash, execErr := s.send(ctx, refund)
+
+	// Транзакция подписана, но узел не подтвердил прием: повторная отправка с новым нонсом может вернуть средства дважды
+	var broadcastErr *BroadcastError
+	if errors.As(execErr, &broadcastErr) {
+		nonce := int64(broadcastErr.Nonce)
+		s.logger.ErrorContext(ctx, "Refund broadcast not confirmed, outcome will be resolved by nonce",
+			"error", execErr,
+			"refund_id", refund.ID,
+			"refund_tx_hash", broadcastErr.TxHash,
+			"nonce", nonce)
+		if err := s.repo.MarkUnknown(ctx, refund.ID, broadcastErr.TxHash, &nonce, execErr.Error()); err != nil {
+			return nil, err
+		}
+
+		message := execErr.Error()
+		refund.Status = entities.RefundStatusUnknown
+		refund.RefundTxHash = &broadcastErr.TxHash
+		refund.Nonce = &nonce
+		refund.Error = &message
+		return refund, nil
+	}
+
+	if execErr != nil {
+		s.logger.ErrorContext(ctx, "Refund execution failed",
+			"error", execErr,
+			"refund_id", refund.ID,
+			"deposit_tx_hash", refund.DepositTxHash)
+		if err := s.repo.MarkFailed(ctx, refund.ID, execErr.Error()); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to execute refund: %w", execErr)
+	}
+
+	var nonce *int64
+	if n, ok := s.transfer.TrackedNonce(txHash); ok {
+		value := int64(n)
+		nonce = &value
+	}
+	if err := s.repo.MarkSent(ctx, refund.ID, txHash, nonce); err != nil {
+		return nil, err
+	}
+
+	if s.audit != nil {
+		if err := s.audit.Record(ctx, entities.AuditEventRefundExecuted, executedBy, refund.ToAddress, map[string]any{
+			"refund_id":       refund.ID,
+			"deposit_tx_hash": refund.DepositTxHash,
+			"refund_tx_hash":  txHash,
+			"amount":          refund.Amount,
+			"reason":          refund.Reason,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record refund audit event", "error", err, "refund_id", refund.ID)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Refund sent",
+		"refund_id", refund.ID,
+		"refund_tx_hash", txHash,
+		"to", refund.ToAddress,
+		"amount", refund.Amount)
+
+	refund.Status = entities.RefundStatusSent
+	refund.RefundTxHash = &txHash
+	refund.Nonce = nonce
+	refund.Error = nil
+
+	message := fmt.Sprintf("Refund of %s USDT has been sent to %s, transaction %s", WeiToEther(amount).StringFixed(6), refund.ToAddress, txHash)
+	asset := s.transfer.Asset()
+	if url := s.links.TxURL(string(asset.Chain), asset.Network, txHash); url != "" {
+		message += "\nView in explorer: " + url
+	}
+	s.notify(ctx, refund, "Refund sent", message)
+
+	return refund, nil
+}
+
+func (s *RefundService) send(ctx context.Context, refund *entities.Refund) (string, error) {
+	amount, ok := new(big.Int).SetString(refund.Amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid refund amount %q", refund.Amount)
+	}
+
+	wallet, err := s.wallets.FindWalletByAddress(ctx, refund.WalletAddress)
+	if err != nil {
+		return "", err
+	}
+	if wallet == nil {
+		return "", fmt.Errorf("deposit wallet %s not found", refund.WalletAddress)
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return "", fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	ctx = withLedgerTag(ctx, entities.LedgerKindRefund, amount, nil)
+	return s.transfer.TransferFunds(ctx, client, wallet.ID, refund.ToAddress, amount)
+}
+
+// TxReplaced follows speedups of refund transactions. A cancelled transaction keeps its hash:
+// its receipt never appears and the refund fails once the nonce is taken by the cancellation.
+func (s *RefundService) TxReplaced(ctx context.Context, oldTxHash, newTxHash string, cancelled bool) {
+	if cancelled {
+		return
+	}
+	if err := s.repo.ReplaceTxHash(ctx, oldTxHash, newTxHash); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to follow replaced refund transaction", "error", err,
+			"original_tx_hash", oldTxHash, "tx_hash", newTxHash)
+	}
+}
+
+// resolveRefunds завершает отправленные возвраты по квитанциям, а возвраты с неизвестным исходом — по нонсу
+func (s *RefundService) resolveRefunds(ctx context.Context) {
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create BSC client for refund resolution", "error", err)
+		return
+	}
+	defer client.Close()
+
+	for _, status := range []entities.RefundStatus{entities.RefundStatusSent, entities.RefundStatusUnknown} {
+		refunds, err := s.repo.FindByStatus(ctx, status, refundsListLimit)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to get refunds to resolve", "error", err, "status", status)
+			continue
+		}
+		for i := range refunds {
+			if err := s.resolveRefund(ctx, client, &refunds[i]); err != nil {
+				s.logger.WarnContext(ctx, "Failed to resolve refund", "error", err, "refund_id", refunds[i].ID)
+			}
+		}
+	}
+}
+
+func (s *RefundService) resolveRefund(ctx context.Context, client *ethclient.Client, refund *entities.Refund) error {
+	if refund.RefundTxHash == nil {
+		return nil
+	}
+	txHash := *refund.RefundTxHash
+
+	// Нонс читаем до квитанции: если он занят, а квитанции нет, в блок попала другая транзакция
+	var latestNonce uint64
+	if refund.Nonce != nil {
+		nonce, err := client.NonceAt(ctx, common.HexToAddress(refund.WalletAddress), nil)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce: %w", err)
+		}
+		latestNonce = nonce
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err == nil {
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			s.logger.ErrorContext(ctx, "Refund transaction reverted", "refund_id", refund.ID, "refund_tx_hash", txHash)
+			return s.repo.MarkFailed(ctx, refund.ID, "refund transaction reverted")
+		}
+		return s.complete(ctx, refund, txHash)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	// Без нонса исход определяет оператор по квитанции
+	if refund.Nonce == nil || latestNonce <= uint64(*refund.Nonce) || time.Since(refund.UpdatedAt) < refundNonceGracePeriod {
+		return nil
+	}
+
+	// Нонс занят другой транзакцией (отменой или следующей отправкой), транзакция возврата уже не попадет в блок
+	s.logger.WarnContext(ctx, "Refund transaction was not mined, its nonce is used by another transaction",
+		"refund_id", refund.ID,
+		"refund_tx_hash", txHash,
+		"nonce", *refund.Nonce,
+		"status", refund.Status)
+	return s.repo.MarkFailed(ctx, refund.ID, "refund transaction was not mined, its nonce is used by another transaction")
+}
+
+func (s *RefundService) complete(ctx context.Context, refund *entities.Refund, txHash string) error {
+	if err := s.repo.MarkCompleted(ctx, refund.ID, txHash); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Refund completed",
+		"refund_id", refund.ID,
+		"refund_tx_hash", txHash,
+		"to", refund.ToAddress,
+		"amount", refund.Amount)
+
+	// Возврат с неизвестным исходом не уведомлял пользователя об отправке
+	if refund.Status == entities.RefundStatusUnknown {
+		amount, _ := new(big.Int).SetString(refund.Amount, 10)
+		s.notify(ctx, refund, "Refund sent",
+			fmt.Sprintf("Refund of %s USDT has been sent to %s, transaction %s", WeiToEther(amount).StringFixed(6), refund.ToAddress, txHash))
+	}
+
+	return nil
+}
+
+// GetUserRefunds возвращает возвраты пользователя
+func (s *RefundService) GetUserRefunds(ctx context.Context, userID int64) ([]entities.Refund, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// checkRefundable возвращает ErrRefundNotAllowed, если amount вместе с другими не failed возвратами депозита
+// превышает сумму депозита. Вызывается под блокировкой возвратов депозита.
+func (s *RefundService) checkRefundable(ctx context.Context, depositTxHash, excludeID string, amount, depositAmount *big.Int) error {
+	value, err := s.repo.SumActive(ctx, depositTxHash, excludeID)
+	if err != nil {
+		return err
+	}
+	refunded, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return fmt.Errorf("invalid refunded amount %q of deposit %s", value, depositTxHash)
+	}
+	if new(big.Int).Add(refunded, amount).Cmp(depositAmount) > 0 {
+		return fmt.Errorf("%w: refunds of the deposit would exceed its amount, %s already refunded", ErrRefundNotAllowed, refunded.String())
+	}
+	return nil
+}
+
+// GetRefunds возвращает возвраты с указанным статусом (пустой статус — все)
+func (s *RefundService) GetRefunds(ctx context.Context, status entities.RefundStatus) ([]entities.Refund, error) {
+	return s.repo.FindByStatus(ctx, status, refundsListLimit)
+}
+
+// RequestAMLRefund создает возврат депозита, отклоненного AML проверкой
+func (s *RefundService) RequestAMLRefund(ctx context.Context, depositTxHash string) error {
+	_, err := s.RequestRefund(ctx, depositTxHash, nil, entities.RefundReasonAMLRejected, "rule:aml_rejected")
+	if err != nil && !errors.Is(err, ErrRefundExists) {
+		return err
+	}
+	return nil
+}
+
+// Start periodically resolves sent refunds and executes pending refunds when automatic execution is enabled
+func (s *RefundService) Start(ctx context.Context) {
+	ticker := time.NewTicker(refundProcessInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.resolveRefunds(ctx)
+			if !s.autoExecute {
+				continue
+			}
+
+			refunds, err := s.repo.FindByStatus(ctx, entities.RefundStatusPending, refundsListLimit)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Failed to get pending refunds", "error", err)
+				continue
+			}
+			for _, refund := range refunds {
+				if _, err := s.ExecuteRefund(ctx, refund.ID, "rule:auto_execute"); err != nil {
+					s.logger.WarnContext(ctx, "Automatic refund execution failed", "error", err, "refund_id", refund.ID)
+				}
+			}
+		}
+	}
+}
+
+func (s *RefundService) notify(ctx context.Context, refund *entities.Refund, subject, message string) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, refund.UserID, subject, message); err != nil {
+		s.logger.WarnContext(ctx, "Failed to notify user about refund", "error", err, "refund_id", refund.ID)
+	}
+}
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+const (
+	testDepositTxHash = "0x5a1f2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e2f4a6b8c0d2e4f6a8b0c2d4e6f80"
+	testDepositSender = "0x1111111111111111111111111111111111111111"
+	testDepositWallet = "0x2222222222222222222222222222222222222222"
+)
+
+// stubRefundsRepository хранит возвраты в памяти, блокировка депозита исполняет fn без транзакции
+type stubRefundsRepository struct {
+	RefundsRepository
+	refunds []entities.Refund
+}
+
+func (r *stubRefundsRepository) WithinDepositLock(ctx context.Context, _ string, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *stubRefundsRepository) SumActive(_ context.Context, depositTxHash, excludeID string) (string, error) {
+	total := new(big.Int)
+	for _, refund := range r.refunds {
+		if refund.DepositTxHash != depositTxHash || refund.Status == entities.RefundStatusFailed || refund.ID == excludeID {
+			continue
+		}
+		amount, _ := new(big.Int).SetString(refund.Amount, 10)
+		total.Add(total, amount)
+	}
+	return total.String(), nil
+}
+
+func (r *stubRefundsRepository) CreateRefund(_ context.Context, refund *entities.Refund) (bool, error) {
+	for _, existing := range r.refunds {
+		if existing.DepositTxHash == refund.DepositTxHash && existing.Reason == refund.Reason {
+			return false, nil
+		}
+	}
+	r.refunds = append(r.refunds, *refund)
+	return true, nil
+}
+
+func (r *stubRefundsRepository) FindByID(_ context.Context, id string) (*entities.Refund, error) {
+	for i := range r.refunds {
+		if r.refunds[i].ID == id {
+			refund := r.refunds[i]
+			return &refund, nil
+		}
+	}
+	return nil, nil
+}
+
+type stubRefundTransactions struct{}
+
+func (stubRefundTransactions) FindTransactionByHash(_ context.Context, txHash string) (*entities.Transaction, error) {
+	if txHash != testDepositTxHash {
+		return nil, nil
+	}
+	return &entities.Transaction{TxHash: txHash, WalletAddress: testDepositWallet, FromAddress: testDepositSender, Amount: "100"}, nil
+}
+
+type stubRefundWallets struct{}
+
+func (stubRefundWallets) FindWalletByAddress(_ context.Context, address string) (*entities.Wallet, error) {
+	return &entities.Wallet{ID: 1, UserID: 7, Address: address}, nil
+}
+
+func newTestRefundService(repo *stubRefundsRepository) *RefundService {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRefundService(logger, repo, stubRefundTransactions{}, stubRefundWallets{}, nil, nil, nil, nil, false)
+}
+
+func TestRequestRefundRejectsRefundsOverDeposit(t *testing.T) {
+	repo := &stubRefundsRepository{}
+	service := newTestRefundService(repo)
+	ctx := context.Background()
+
+	_, err := service.RequestRefund(ctx, testDepositTxHash, big.NewInt(60), entities.RefundReasonOverpayment, "rule:overpayment")
+	require.NoError(t, err)
+
+	_, err = service.RequestRefund(ctx, testDepositTxHash, big.NewInt(41), entities.RefundReasonManual, "admin")
+	require.ErrorIs(t, err, ErrRefundNotAllowed)
+
+	refund, err := service.RequestRefund(ctx, testDepositTxHash, big.NewInt(40), entities.RefundReasonManual, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "40", refund.Amount)
+	assert.Len(t, repo.refunds, 2)
+}
+
+func TestRequestRefundIgnoresFailedRefunds(t *testing.T) {
+	repo := &stubRefundsRepository{refunds: []entities.Refund{{
+		ID:            "failed",
+		DepositTxHash: testDepositTxHash,
+		Amount:        "100",
+		Reason:        entities.RefundReasonOverpayment,
+		Status:        entities.RefundStatusFailed,
+	}}}
+	service := newTestRefundService(repo)
+
+	_, err := service.RequestRefund(context.Background(), testDepositTxHash, nil, entities.RefundReasonManual, "admin")
+	require.NoError(t, err)
+}
+
+func TestExecuteRefundRejectsRetryOverDeposit(t *testing.T) {
+	repo := &stubRefundsRepository{refunds: []entities.Refund{
+		{
+			ID:            "failed",
+			DepositTxHash: testDepositTxHash,
+			Amount:        "100",
+			Reason:        entities.RefundReasonOverpayment,
+			Status:        entities.RefundStatusFailed,
+		},
+		{
+			ID:            "manual",
+			DepositTxHash: testDepositTxHash,
+			Amount:        "50",
+			Reason:        entities.RefundReasonManual,
+			Status:        entities.RefundStatusCompleted,
+		},
+	}}
+	service := newTestRefundService(repo)
+
+	_, err := service.ExecuteRefund(context.Background(), "failed", "admin")
+	require.ErrorIs(t, err, ErrRefundNotAllowed)
+}
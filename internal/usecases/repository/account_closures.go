@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const accountClosureColumns = `id, user_id, status, reason, wallets_archived, sessions_revoked, last_sweep_at, error,
+                               erase_after, erased_at, requested_at, updated_at, closed_at`
+
+// AccountClosuresRepository stores user-initiated account closures.
+type AccountClosuresRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewAccountClosuresRepository creates a new account closures repository.
+func NewAccountClosuresRepository(logger *slog.Logger, pg *database.Postgres) *AccountClosuresRepository {
+	return &AccountClosuresRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateClosure inserts a closure. Returns false if the user already requested a closure.
+func (r *AccountClosuresRepository) CreateClosure(ctx context.Context, closure *entities.AccountClosure) (bool, error) {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO account_closures (id, user_id, status, reason)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id) DO NOTHING
+		 RETURNING requested_at, updated_at`,
+		closure.ID, closure.UserID, closure.Status, closure.Reason,
+	).Scan(&closure.RequestedAt, &closure.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create account closure: %w", err)
+	}
+
+	return true, nil
+}
+
+// FindByUserID retrieves the closure of the user. Returns nil if the user did not request a closure.
+func (r *AccountClosuresRepository) FindByUserID(ctx context.Context, userID int64) (*entities.AccountClosure, error) {
+	closures, err := r.query(ctx, `SELECT `+accountClosureColumns+` FROM account_closures WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(closures) == 0 {
+		return nil, nil
+	}
+
+	return &closures[0], nil
+}
+
+// FindByStatus retrieves closures with the given status, oldest first. Empty status returns all closures.
+func (r *AccountClosuresRepository) FindByStatus(ctx context.Context, status entities.AccountClosureStatus, limit int) ([]entities.AccountClosure, error) {
+	return r.query(ctx,
+		`SELECT `+accountClosureColumns+` FROM account_closures WHERE ($1 = '' OR status = $1) ORDER BY requested_at LIMIT $2`,
+		status, limit)
+}
+
+// FindDueErasures retrieves closed accounts whose retention period ended before now and data is not erased yet
+func (r *AccountClosuresRepository) FindDueErasures(ctx context.Context, now time.Time, limit int) ([]entities.AccountClosure, error) {
+	return r.query(ctx,
+		`SELECT `+accountClosureColumns+`
+		   FROM account_closures
+		  WHERE status = 'closed' AND erased_at IS NULL AND erase_after <= $1
+		  ORDER BY erase_after
+		  LIMIT $2`,
+		now, limit)
+}
+
+func (r *AccountClosuresRepository) query(ctx context.Context, query string, args ...any) ([]entities.AccountClosure, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account closures: %w", err)
+	}
+	defer rows.Close()
+
+	closures, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.AccountClosure])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect account closure rows: %w", err)
+	}
+
+	return closures, nil
+}
+
+// IsClosing reports whether the user requested a closure, regardless of its progress
+func (r *AccountClosuresRepository) IsClosing(ctx context.Context, userID int64) (bool, error) {
+	var exists bool
+	err := r.db(ctx).QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM account_closures WHERE user_id = $1)",
+		userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check account closure of user %d: %w", userID, err)
+	}
+
+	return exists, nil
+}
+
+// CountBlockers counts pending orders, open refunds and unconfirmed deposits of the user
+func (r *AccountClosuresRepository) CountBlockers(ctx context.Context, userID int64) (entities.AccountClosureBlockers, error) {
+	var blockers entities.AccountClosureBlockers
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT (SELECT COUNT(*) FROM orders WHERE user_id = $1 AND status = 'pending'),
+		        (SELECT COUNT(*) FROM refunds WHERE user_id = $1 AND status IN ('pending', 'processing', 'sent', 'unknown')),
+		        (SELECT COUNT(*)
+		           FROM transactions t
+		           JOIN wallets w ON w.address = t.wallet_address
+		          WHERE w.user_id = $1 AND NOT t.confirmed)`,
+		userID).Scan(&blockers.PendingOrders, &blockers.OpenRefunds, &blockers.UnconfirmedDeposits)
+	if err != nil {
+		return blockers, fmt.Errorf("failed to count closure blockers of user %d: %w", userID, err)
+	}
+
+	return blockers, nil
+}
+
+// UpdateStatus moves the closure to the status and stores the error of the last processing attempt.
+// A nil error clears the previous one.
+func (r *AccountClosuresRepository) UpdateStatus(ctx context.Context, id string, status entities.AccountClosureStatus, errorMessage *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE account_closures SET status = $2, error = $3, updated_at = NOW() WHERE id = $1",
+		id, status, errorMessage)
+	if err != nil {
+		return fmt.Errorf("failed to update account closure status: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSwept records the time sweep transfers were sent for the closure
+func (r *AccountClosuresRepository) MarkSwept(ctx context.Context, id string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE account_closures SET last_sweep_at = NOW(), updated_at = NOW() WHERE id = $1",
+		id)
+	if err != nil {
+		return fmt.Errorf("failed to mark account closure swept: %w", err)
+	}
+
+	return nil
+}
+
+// MarkClosed completes the closure and schedules erasure of personal data
+func (r *AccountClosuresRepository) MarkClosed(ctx context.Context, closure *entities.AccountClosure) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`UPDATE account_closures
+		    SET status = 'closed', wallets_archived = $2, sessions_revoked = $3, erase_after = $4, error = NULL,
+		        closed_at = NOW(), updated_at = NOW()
+		  WHERE id = $1
+		  RETURNING closed_at, updated_at`,
+		closure.ID, closure.WalletsArchived, closure.SessionsRevoked, closure.EraseAfter,
+	).Scan(&closure.ClosedAt, &closure.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark account closure closed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkErased records that personal data of the closed account was erased
+func (r *AccountClosuresRepository) MarkErased(ctx context.Context, id string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE account_closures SET erased_at = NOW(), error = NULL, updated_at = NOW() WHERE id = $1",
+		id)
+	if err != nil {
+		return fmt.Errorf("failed to mark account closure erased: %w", err)
+	}
+
+	return nil
+}
+
+// SetError stores the error of the last processing attempt without changing the status
+func (r *AccountClosuresRepository) SetError(ctx context.Context, id, errorMessage string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE account_closures SET error = $2, updated_at = NOW() WHERE id = $1",
+		id, errorMessage)
+	if err != nil {
+		return fmt.Errorf("failed to store account closure error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// ActivityRepository builds the activity timeline of a user from orders, deposits, withdrawals and the audit log.
+type ActivityRepository struct {
+	logger *slog.Logger
+	db     tx.DBGetter
+}
+
+// NewActivityRepository creates a new activity repository.
+func NewActivityRepository(logger *slog.Logger, pg *database.Postgres) *ActivityRepository {
+	return &ActivityRepository{
+		logger: logger,
+		db:     pg.DBGetter,
+	}
+}
+
+// FindUserActivity returns the latest activity of the user, newest first. The page continues after
+// the item identified by (before, kind, ref) when before is set. Account events are limited to eventTypes.
+func (r *ActivityRepository) FindUserActivity(ctx context.Context, userID int64, eventTypes []string, before *time.Time, kind entities.ActivityKind, ref string, limit int) ([]entities.ActivityItem, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT kind, ref, status, amount, asset, tx_hash, details, occurred_at, chain, network, units
+		   FROM (
+		         SELECT 'order' AS kind, o.id::text AS ref, o.status::text AS status, o.amount::text AS amount,
+		                COALESCE(a.code, '') AS asset, o.completed_tx_hash AS tx_hash, NULL::jsonb AS details,
+		                o.created_at AS occurred_at, ''::varchar AS chain, ''::varchar AS network, FALSE AS units
+		           FROM orders o
+		           LEFT JOIN assets a ON a.id = o.asset_id
+		          WHERE o.user_id = $1
+		         UNION ALL
+		         SELECT 'deposit', t.tx_hash,
+		                CASE WHEN t.on_hold THEN 'on_hold' WHEN t.processed THEN 'credited'
+		                     WHEN t.confirmed THEN 'confirmed' ELSE 'pending' END,
+		                t.amount, '', t.tx_hash, NULL, t.created_at, w.chain, w.network, TRUE
+		           FROM transactions t
+		           JOIN wallets w ON w.address = t.wallet_address
+		          WHERE w.user_id = $1
+		         UNION ALL
+		         SELECT 'withdrawal', wd.id::text, wd.status, wd.amount, '', wd.tx_hash, NULL, wd.created_at,
+		                w.chain, w.network, TRUE
+		           FROM withdrawals wd
+		           JOIN wallets w ON w.id = wd.wallet_id
+		          WHERE wd.user_id = $1
+		         UNION ALL
+		         SELECT 'account', al.id::text, al.event_type, '', '', NULL, al.details, al.created_at, '', '', FALSE
+		           FROM audit_log al
+		          WHERE (al.subject = $2 OR al.actor = $2) AND al.event_type = ANY($3)
+		        ) activity
+		  WHERE $4::timestamptz IS NULL OR (occurred_at, kind, ref) < ($4::timestamptz, $5, $6)
+		  ORDER BY occurred_at DESC, kind DESC, ref DESC
+		  LIMIT $7`,
+		userID, strconv.FormatInt(userID, 10), eventTypes, before, string(kind), ref, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user activity: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.ActivityItem])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user activity: %w", err)
+	}
+
+	return items, nil
+}
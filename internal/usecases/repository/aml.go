@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
 	"log/slog"
+	"strings"
 	"time"
 
 	tx "github.com/Thiht/transactor/pgx"
@@ -51,7 +52,7 @@ func (r *AMLRepository) SaveCheckResult(ctx context.Context, result *entities.AM
 	).Scan(&result.ID)
 
 	if err != nil {
-		return fmt.Errorf("failed to save AML check result: %w", err)
+		return fmt.Errorf("failed to save AML check result: %w", constraintError(err))
 	}
 
 	return nil
@@ -151,6 +152,46 @@ func (r *AMLRepository) GetAddressRiskInfo(ctx context.Context, address string)
 	return &riskInfo, nil
 }
 
+// SaveLocalBlacklistEntry добавляет адрес в локальный черный список AML или обновляет оценку и причину
+func (r *AMLRepository) SaveLocalBlacklistEntry(ctx context.Context, address string, riskScore float64, reason string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO local_aml_blacklist (address, risk_score, reason)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (address) DO UPDATE SET risk_score = $2, reason = $3`,
+		strings.ToLower(address), riskScore, reason)
+	if err != nil {
+		return fmt.Errorf("failed to save local blacklist entry: %w", err)
+	}
+
+	return nil
+}
+
+// FindLocalBlacklist возвращает оценки риска адресов локального черного списка
+func (r *AMLRepository) FindLocalBlacklist(ctx context.Context) (map[string]float64, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT address, risk_score FROM local_aml_blacklist`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query local blacklist: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make(map[string]float64)
+	for rows.Next() {
+		var (
+			address string
+			score   float64
+		)
+		if err = rows.Scan(&address, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan local blacklist entry: %w", err)
+		}
+		entries[address] = score
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate local blacklist: %w", err)
+	}
+
+	return entries, nil
+}
+
 // AddTransactionForChecking добавляет транзакцию в очередь на проверку
 func (r *AMLRepository) AddTransactionForChecking(ctx context.Context, check *entities.TransactionCheck) error {
 	query := `INSERT INTO aml_transaction_checks 
@@ -208,6 +249,17 @@ func (r *AMLRepository) GetPendingChecks(ctx context.Context, limit int) ([]enti
 	return checks, nil
 }
 
+// CountPendingChecks возвращает размер очереди транзакций, ожидающих проверки
+func (r *AMLRepository) CountPendingChecks(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM aml_transaction_checks WHERE processed = false`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending checks: %w", err)
+	}
+
+	return count, nil
+}
+
 // MarkCheckAsProcessed отмечает проверку как обработанную
 func (r *AMLRepository) MarkCheckAsProcessed(ctx context.Context, txHash string) error {
 	query := `UPDATE aml_transaction_checks 
@@ -221,3 +273,113 @@ func (r *AMLRepository) MarkCheckAsProcessed(ctx context.Context, txHash string)
 
 	return nil
 }
+
+// Сводка пользователя по рискам его кошельков, колонки в порядке scanRiskRollup
+const userRiskRollupColumns = `'', user_id, MAX(risk_score), MAX(max_risk_score), SUM(checks)::INTEGER, SUM(flagged)::INTEGER,
+                               COUNT(*)::INTEGER, MAX(last_checked_at), MAX(updated_at)`
+
+// RefreshWalletRisk пересчитывает сводный риск кошелька по всем AML проверкам его входящих переводов.
+// Сводный риск 0.7*максимум + 0.3*среднее: один рискованный перевод заметен, а серия средних его повышает.
+func (r *AMLRepository) RefreshWalletRisk(ctx context.Context, walletAddress string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO wallet_risk_scores (wallet_address, user_id, risk_score, max_risk_score, checks, flagged, last_checked_at, updated_at)
+		 SELECT c.wallet_address,
+		        (SELECT w.user_id FROM wallets w WHERE w.address = c.wallet_address ORDER BY w.id LIMIT 1),
+		        0.7 * MAX(c.risk_score) + 0.3 * AVG(c.risk_score), MAX(c.risk_score),
+		        COUNT(*), COUNT(*) FILTER (WHERE NOT c.approved), MAX(c.checked_at), NOW()
+		   FROM aml_checks c
+		  WHERE c.wallet_address = $1
+		  GROUP BY c.wallet_address
+		 ON CONFLICT (wallet_address) DO UPDATE
+		    SET user_id = EXCLUDED.user_id, risk_score = EXCLUDED.risk_score, max_risk_score = EXCLUDED.max_risk_score,
+		        checks = EXCLUDED.checks, flagged = EXCLUDED.flagged, last_checked_at = EXCLUDED.last_checked_at,
+		        updated_at = NOW()`,
+		walletAddress)
+	if err != nil {
+		return fmt.Errorf("failed to refresh wallet risk: %w", err)
+	}
+
+	return nil
+}
+
+// GetWalletRisk возвращает сводный риск кошелька или nil, если его переводы не проверялись
+func (r *AMLRepository) GetWalletRisk(ctx context.Context, walletAddress string) (*entities.RiskRollup, error) {
+	return r.findRiskRollup(ctx,
+		`SELECT wallet_address, COALESCE(user_id, 0), risk_score, max_risk_score, checks, flagged, 0, last_checked_at, updated_at
+		   FROM wallet_risk_scores
+		  WHERE wallet_address = $1`,
+		walletAddress)
+}
+
+// GetUserRisk возвращает сводный риск пользователя по всем его кошелькам или nil, если их переводы не проверялись
+func (r *AMLRepository) GetUserRisk(ctx context.Context, userID int64) (*entities.RiskRollup, error) {
+	return r.findRiskRollup(ctx,
+		`SELECT `+userRiskRollupColumns+`
+		   FROM wallet_risk_scores
+		  WHERE user_id = $1
+		  GROUP BY user_id`,
+		userID)
+}
+
+// GetUserRiskByWallet возвращает сводный риск владельца кошелька или nil
+func (r *AMLRepository) GetUserRiskByWallet(ctx context.Context, walletAddress string) (*entities.RiskRollup, error) {
+	return r.findRiskRollup(ctx,
+		`SELECT `+userRiskRollupColumns+`
+		   FROM wallet_risk_scores
+		  WHERE user_id = (SELECT w.user_id FROM wallets w WHERE w.address = $1 ORDER BY w.id LIMIT 1)
+		  GROUP BY user_id`,
+		walletAddress)
+}
+
+// FindRiskyUsers возвращает сводки пользователей с риском не ниже minScore, самые рискованные первыми
+func (r *AMLRepository) FindRiskyUsers(ctx context.Context, minScore float64, limit int) ([]entities.RiskRollup, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+userRiskRollupColumns+`
+		   FROM wallet_risk_scores
+		  WHERE user_id IS NOT NULL
+		  GROUP BY user_id
+		 HAVING MAX(risk_score) >= $1
+		  ORDER BY MAX(risk_score) DESC, user_id
+		  LIMIT $2`,
+		minScore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query risky users: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []entities.RiskRollup
+	for rows.Next() {
+		rollup, err := scanRiskRollup(rows)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, *rollup)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read risky users: %w", err)
+	}
+
+	return rollups, nil
+}
+
+func (r *AMLRepository) findRiskRollup(ctx context.Context, query string, args ...any) (*entities.RiskRollup, error) {
+	rollup, err := scanRiskRollup(r.db(ctx).QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rollup, err
+}
+
+func scanRiskRollup(row pgx.Row) (*entities.RiskRollup, error) {
+	var rollup entities.RiskRollup
+	err := row.Scan(&rollup.WalletAddress, &rollup.UserID, &rollup.RiskScore, &rollup.MaxRiskScore, &rollup.Checks,
+		&rollup.Flagged, &rollup.Wallets, &rollup.LastCheckedAt, &rollup.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan risk rollup: %w", err)
+	}
+
+	return &rollup, nil
+}
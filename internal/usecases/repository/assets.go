@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const assetColumns = `id, code, chain, network, contract_address, decimals, min_order_amount, max_order_amount,
+                      withdrawal_fee, aml_threshold, min_deposit_amount, deposits_enabled, withdrawals_enabled, created_at, updated_at`
+
+// AssetsRepository stores the registry of supported assets.
+type AssetsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewAssetsRepository creates a new assets repository.
+func NewAssetsRepository(logger *slog.Logger, pg *database.Postgres) *AssetsRepository {
+	return &AssetsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindAssets retrieves all registered assets
+func (r *AssetsRepository) FindAssets(ctx context.Context) ([]entities.Asset, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+assetColumns+` FROM assets ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Asset])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect asset rows: %w", err)
+	}
+
+	return assets, nil
+}
+
+// UpdateAsset saves limits, fee and flags of the asset. Returns nil if the asset does not exist.
+func (r *AssetsRepository) UpdateAsset(ctx context.Context, asset *entities.Asset) (*entities.Asset, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`UPDATE assets SET min_order_amount = $2, max_order_amount = $3, withdrawal_fee = $4, aml_threshold = $5,
+		                   min_deposit_amount = $6, deposits_enabled = $7, withdrawals_enabled = $8, updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+assetColumns,
+		asset.ID, asset.MinOrderAmount, asset.MaxOrderAmount, asset.WithdrawalFee, asset.AMLThreshold,
+		asset.MinDepositAmount, asset.DepositsEnabled, asset.WithdrawalsEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update asset: %w", err)
+	}
+	defer rows.Close()
+
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.Asset])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect asset row: %w", err)
+	}
+
+	return &updated, nil
+}
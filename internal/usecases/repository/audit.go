@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// AuditRepository stores audit log entries.
+type AuditRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewAuditRepository creates a new audit repository.
+func NewAuditRepository(logger *slog.Logger, pg *database.Postgres) *AuditRepository {
+	return &AuditRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// InsertEvent appends a new entry to the audit log
+func (r *AuditRepository) InsertEvent(ctx context.Context, event *entities.AuditEvent) error {
+	details := event.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	err := r.db(ctx).QueryRow(ctx,
+		"INSERT INTO audit_log (event_type, actor, subject, details) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		event.EventType, event.Actor, event.Subject, details).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	return nil
+}
+
+// FindEvents retrieves the latest audit log entries, optionally filtered by event type and subject
+func (r *AuditRepository) FindEvents(ctx context.Context, eventType entities.AuditEventType, subject string, limit int) ([]entities.AuditEvent, error) {
+	query := `SELECT id, event_type, actor, subject, details, created_at
+                FROM audit_log
+               WHERE ($1 = '' OR event_type = $1)
+                 AND ($2 = '' OR subject = $2)
+               ORDER BY id DESC
+               LIMIT $3`
+
+	rows, err := r.db(ctx).Query(ctx, query, string(eventType), subject, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.AuditEvent])
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to collect audit events rows", "error", err)
+		return nil, fmt.Errorf("failed to collect audit events rows: %w", err)
+	}
+
+	return events, nil
+}
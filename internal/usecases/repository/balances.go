@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// BalancesRepository derives user balances from the deposits and withdrawals of their wallets.
+type BalancesRepository struct {
+	logger *slog.Logger
+	db     tx.DBGetter
+}
+
+// NewBalancesRepository creates a new balances repository.
+func NewBalancesRepository(logger *slog.Logger, pg *database.Postgres) *BalancesRepository {
+	return &BalancesRepository{
+		logger: logger,
+		db:     pg.DBGetter,
+	}
+}
+
+// FindUserBalances returns the balances of the user in minimal units by wallet chain and network.
+// Ignored deposits (dust and spam) and deposits being refunded are not counted, every withdrawal that has not
+// failed is subtracted from the available balance, and those not sent yet are also reported as held.
+func (r *BalancesRepository) FindUserBalances(ctx context.Context, userID int64) ([]entities.UserBalance, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`WITH deposits AS (
+		         SELECT w.chain, w.network,
+		                COALESCE(SUM(t.amount::NUMERIC) FILTER (
+		                    WHERE t.confirmed AND NOT t.on_hold AND t.aml_status <> 'flagged'), 0) AS cleared,
+		                COALESCE(SUM(t.amount::NUMERIC) FILTER (WHERE NOT t.confirmed), 0) AS pending,
+		                COALESCE(SUM(t.amount::NUMERIC) FILTER (
+		                    WHERE t.confirmed AND (t.on_hold OR t.aml_status = 'flagged')), 0) AS held
+		           FROM transactions t
+		           JOIN wallets w ON w.address = t.wallet_address
+		          WHERE w.user_id = $1
+		            AND t.ignored_reason IS NULL
+		            AND NOT EXISTS (SELECT 1 FROM refunds rf
+		                             WHERE rf.deposit_tx_hash = t.tx_hash AND rf.status <> 'failed')
+		          GROUP BY w.chain, w.network
+		     ),
+		     withdrawn AS (
+		         SELECT w.chain, w.network,
+		                SUM(wd.amount::NUMERIC) AS spent,
+		                COALESCE(SUM(wd.amount::NUMERIC) FILTER (WHERE wd.status IN ('pending', 'queued', 'unknown')), 0) AS reserved
+		           FROM withdrawals wd
+		           JOIN wallets w ON w.id = wd.wallet_id
+		          WHERE wd.user_id = $1 AND wd.status <> 'failed'
+		          GROUP BY w.chain, w.network
+		     )
+		SELECT ''::varchar AS asset,
+		       (COALESCE(d.cleared, 0) - COALESCE(wd.spent, 0))::TEXT AS available,
+		       COALESCE(d.pending, 0)::TEXT AS pending,
+		       (COALESCE(d.held, 0) + COALESCE(wd.reserved, 0))::TEXT AS held,
+		       COALESCE(d.chain, wd.chain) AS chain,
+		       COALESCE(d.network, wd.network) AS network
+		  FROM deposits d
+		  FULL JOIN withdrawn wd ON wd.chain = d.chain AND wd.network = d.network
+		 ORDER BY chain, network`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.UserBalance])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user balances: %w", err)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// CandlesRepository stores candles of the pairs served by the market-data feed
+type CandlesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewCandlesRepository creates a new candles repository.
+func NewCandlesRepository(logger *slog.Logger, pg *database.Postgres) *CandlesRepository {
+	return &CandlesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// UpsertCandles inserts the candles of the pair in one transaction, a stored candle of the same time is replaced
+func (r *CandlesRepository) UpsertCandles(ctx context.Context, symbol string, candles []entities.CandleData) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		for _, candle := range candles {
+			_, err := r.db(txCtx).Exec(txCtx,
+				`INSERT INTO candles (symbol, open_time, open, high, low, close, volume)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7)
+				 ON CONFLICT (symbol, open_time) DO UPDATE
+				    SET open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+				        close = EXCLUDED.close, volume = EXCLUDED.volume, updated_at = NOW()`,
+				symbol, candle.Time, candle.Open, candle.High, candle.Low, candle.Close, candle.Volume)
+			if err != nil {
+				return fmt.Errorf("failed to upsert candle %s at %d: %w", symbol, candle.Time, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindCandlesSince returns the stored candles of the pair opened at or after since (milliseconds), oldest first
+func (r *CandlesRepository) FindCandlesSince(ctx context.Context, symbol string, since int64) ([]entities.CandleData, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT open_time, open, high, low, close, volume
+		   FROM candles
+		  WHERE symbol = $1 AND open_time >= $2
+		  ORDER BY open_time`,
+		symbol, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candles of %s: %w", symbol, err)
+	}
+	defer rows.Close()
+
+	candles, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.CandleData])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect candles of %s: %w", symbol, err)
+	}
+
+	return candles, nil
+}
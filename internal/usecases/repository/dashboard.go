@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// Проекции дашборда, они же ключи курсоров
+const (
+	DashboardProjectionOrders             = "orders"
+	DashboardProjectionDeposits           = "deposits"
+	DashboardProjectionDepositStatsHourly = "deposit_stats_hourly"
+	DashboardProjectionDepositStatsDaily  = "deposit_stats_daily"
+)
+
+// depositStatsChanged возвращает дни подтверждения депозитов, измененных после $1
+const depositStatsChanged = `SELECT COALESCE(ARRAY_AGG(DISTINCT (confirmed_at AT TIME ZONE 'UTC')::DATE)
+                                              FILTER (WHERE confirmed_at IS NOT NULL), '{}'),
+                                    MAX(updated_at)
+                               FROM transactions WHERE updated_at > $1`
+
+// dashboardProjection описывает пересчет дней проекции по изменениям исходной таблицы
+type dashboardProjection struct {
+	table string
+	// changed возвращает дни созданных строк, измененных после $1, и наибольший updated_at среди них
+	changed string
+	// insert пересчитывает дни $1::DATE[]
+	insert string
+}
+
+var dashboardProjections = map[string]dashboardProjection{
+	DashboardProjectionOrders: {
+		table: "dashboard_orders_daily",
+		changed: `SELECT COALESCE(ARRAY_AGG(DISTINCT (created_at AT TIME ZONE 'UTC')::DATE), '{}'), MAX(updated_at)
+		            FROM orders WHERE updated_at > $1`,
+		insert: `INSERT INTO dashboard_orders_daily (day, status, orders)
+		         SELECT d.day, o.status::TEXT, COUNT(*)
+		           FROM UNNEST($1::DATE[]) AS d(day)
+		           JOIN orders o ON o.created_at >= d.day::TIMESTAMP AT TIME ZONE 'UTC'
+		                        AND o.created_at < (d.day + 1)::TIMESTAMP AT TIME ZONE 'UTC'
+		          GROUP BY 1, 2`,
+	},
+	DashboardProjectionDeposits: {
+		table: "dashboard_deposits_daily",
+		changed: `SELECT COALESCE(ARRAY_AGG(DISTINCT (created_at AT TIME ZONE 'UTC')::DATE), '{}'), MAX(updated_at)
+		            FROM transactions WHERE updated_at > $1`,
+		insert: `INSERT INTO dashboard_deposits_daily (day, chain, network, detected, confirmed, credited, ignored, volume)
+		         SELECT d.day, w.chain, w.network,
+		                COUNT(*),
+		                COUNT(*) FILTER (WHERE t.confirmed AND t.ignored_reason IS NULL),
+		                COUNT(*) FILTER (WHERE t.credited_at IS NOT NULL AND t.ignored_reason IS NULL),
+		                COUNT(*) FILTER (WHERE t.ignored_reason IS NOT NULL),
+		                COALESCE(SUM(t.amount::NUMERIC) FILTER (WHERE t.confirmed AND t.ignored_reason IS NULL), 0)
+		           FROM UNNEST($1::DATE[]) AS d(day)
+		           JOIN transactions t ON t.created_at >= d.day::TIMESTAMP AT TIME ZONE 'UTC'
+		                              AND t.created_at < (d.day + 1)::TIMESTAMP AT TIME ZONE 'UTC'
+		           JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		          GROUP BY 1, 2, 3`,
+	},
+	DashboardProjectionDepositStatsHourly: {
+		table:   "deposit_stats_hourly",
+		changed: depositStatsChanged,
+		insert: `INSERT INTO deposit_stats_hourly (day, hour, asset_id, deposits, volume, depositors)
+		         SELECT d.day, EXTRACT(HOUR FROM t.confirmed_at AT TIME ZONE 'UTC')::SMALLINT, a.id,
+		                COUNT(*), SUM(t.amount::NUMERIC), COUNT(DISTINCT w.user_id)
+		           FROM UNNEST($1::DATE[]) AS d(day)
+		           JOIN transactions t ON t.confirmed_at >= d.day::TIMESTAMP AT TIME ZONE 'UTC'
+		                              AND t.confirmed_at < (d.day + 1)::TIMESTAMP AT TIME ZONE 'UTC'
+		           JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		           JOIN assets a ON a.code = 'USDT' AND a.chain = w.chain AND a.network = w.network
+		          WHERE t.confirmed AND t.ignored_reason IS NULL
+		          GROUP BY 1, 2, 3`,
+	},
+	DashboardProjectionDepositStatsDaily: {
+		table:   "deposit_stats_daily",
+		changed: depositStatsChanged,
+		insert: `INSERT INTO deposit_stats_daily (day, asset_id, deposits, volume, depositors)
+		         SELECT d.day, a.id, COUNT(*), SUM(t.amount::NUMERIC), COUNT(DISTINCT w.user_id)
+		           FROM UNNEST($1::DATE[]) AS d(day)
+		           JOIN transactions t ON t.confirmed_at >= d.day::TIMESTAMP AT TIME ZONE 'UTC'
+		                              AND t.confirmed_at < (d.day + 1)::TIMESTAMP AT TIME ZONE 'UTC'
+		           JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		           JOIN assets a ON a.code = 'USDT' AND a.chain = w.chain AND a.network = w.network
+		          WHERE t.confirmed AND t.ignored_reason IS NULL
+		          GROUP BY 1, 2`,
+	},
+}
+
+// depositStatsQueries читают статистику депозитов за [$1, $2) по гранулярности, $3 — код актива или пустая строка
+var depositStatsQueries = map[entities.DepositStatsGranularity]string{
+	entities.DepositStatsHourly: `
+		SELECT (s.day + s.hour * INTERVAL '1 hour') AT TIME ZONE 'UTC', s.asset_id, a.code, a.chain, a.network, a.decimals,
+		       s.deposits, s.volume::TEXT, TRUNC(s.volume / s.deposits)::TEXT, s.depositors
+		  FROM deposit_stats_hourly s
+		  JOIN assets a ON a.id = s.asset_id
+		 WHERE s.day >= ($1::TIMESTAMPTZ AT TIME ZONE 'UTC')::DATE AND s.day <= ($2::TIMESTAMPTZ AT TIME ZONE 'UTC')::DATE
+		   AND (s.day + s.hour * INTERVAL '1 hour') >= $1::TIMESTAMPTZ AT TIME ZONE 'UTC'
+		   AND (s.day + s.hour * INTERVAL '1 hour') < $2::TIMESTAMPTZ AT TIME ZONE 'UTC'
+		   AND ($3 = '' OR a.code = $3)
+		 ORDER BY s.day, s.hour, a.code, a.chain, a.network`,
+	entities.DepositStatsDaily: `
+		SELECT s.day::TIMESTAMP AT TIME ZONE 'UTC', s.asset_id, a.code, a.chain, a.network, a.decimals,
+		       s.deposits, s.volume::TEXT, TRUNC(s.volume / s.deposits)::TEXT, s.depositors
+		  FROM deposit_stats_daily s
+		  JOIN assets a ON a.id = s.asset_id
+		 WHERE s.day >= $1::DATE AND s.day < $2::DATE
+		   AND ($3 = '' OR a.code = $3)
+		 ORDER BY s.day, a.code, a.chain, a.network`,
+}
+
+// DashboardRepository maintains the dashboard projections and serves the dashboard queries from them only
+type DashboardRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewDashboardRepository creates a new dashboard repository.
+func NewDashboardRepository(logger *slog.Logger, pg *database.Postgres) *DashboardRepository {
+	return &DashboardRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindCursor returns the watermark of the projection, zero time if it was never built
+func (r *DashboardRepository) FindCursor(ctx context.Context, projection string) (time.Time, error) {
+	var watermark time.Time
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT watermark FROM dashboard_projection_cursors WHERE projection = $1`, projection).Scan(&watermark)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query dashboard cursor: %w", err)
+	}
+
+	return watermark, nil
+}
+
+// FindCursors returns the positions of all projections
+func (r *DashboardRepository) FindCursors(ctx context.Context) ([]entities.DashboardProjectionCursor, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT projection, watermark, refreshed_at FROM dashboard_projection_cursors ORDER BY projection`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dashboard cursors: %w", err)
+	}
+	defer rows.Close()
+
+	cursors, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DashboardProjectionCursor])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dashboard cursor rows: %w", err)
+	}
+
+	return cursors, nil
+}
+
+// Project recomputes the days of the projection touched by rows changed after since and moves its cursor.
+// With rebuild the projection is cleared and recomputed from all rows, which also drops deleted ones.
+// Returns the number of recomputed days.
+func (r *DashboardRepository) Project(ctx context.Context, projection string, since time.Time, rebuild bool) (int, error) {
+	p, ok := dashboardProjections[projection]
+	if !ok {
+		return 0, fmt.Errorf("unknown dashboard projection %q", projection)
+	}
+	if rebuild {
+		since = time.Time{}
+	}
+
+	var days int
+	err := r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var (
+			changed   []time.Time
+			watermark *time.Time
+		)
+		if err := r.db(txCtx).QueryRow(txCtx, p.changed, since).Scan(&changed, &watermark); err != nil {
+			return fmt.Errorf("failed to query changed days: %w", err)
+		}
+
+		if rebuild {
+			if _, err := r.db(txCtx).Exec(txCtx, `DELETE FROM `+p.table); err != nil {
+				return fmt.Errorf("failed to clear %s: %w", p.table, err)
+			}
+		} else if len(changed) > 0 {
+			if _, err := r.db(txCtx).Exec(txCtx, `DELETE FROM `+p.table+` WHERE day = ANY($1::DATE[])`, changed); err != nil {
+				return fmt.Errorf("failed to clear changed days of %s: %w", p.table, err)
+			}
+		}
+		if len(changed) > 0 {
+			if _, err := r.db(txCtx).Exec(txCtx, p.insert, changed); err != nil {
+				return fmt.Errorf("failed to project %s: %w", p.table, err)
+			}
+		}
+
+		// Без изменений курсор остается на месте, обновляется только время проверки
+		_, err := r.db(txCtx).Exec(txCtx,
+			`INSERT INTO dashboard_projection_cursors (projection, watermark)
+			 VALUES ($1, COALESCE($2, $3))
+			 ON CONFLICT (projection) DO UPDATE
+			    SET watermark = GREATEST(dashboard_projection_cursors.watermark, EXCLUDED.watermark),
+			        refreshed_at = NOW()`,
+			projection, watermark, since)
+		if err != nil {
+			return fmt.Errorf("failed to move dashboard cursor: %w", err)
+		}
+
+		days = len(changed)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to project %s: %w", projection, err)
+	}
+
+	return days, nil
+}
+
+// RefreshAMLBacklog snapshots the current AML queue
+func (r *DashboardRepository) RefreshAMLBacklog(ctx context.Context) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO dashboard_aml_backlog (id, queued_checks, oldest_queued_at, flagged_deposits, held_deposits, flagged_orders, refreshed_at)
+		 SELECT TRUE,
+		        (SELECT COUNT(*) FROM aml_transaction_checks WHERE NOT processed),
+		        (SELECT MIN(created_at) AT TIME ZONE 'UTC' FROM aml_transaction_checks WHERE NOT processed),
+		        (SELECT COUNT(*) FROM transactions WHERE aml_status = 'flagged'),
+		        (SELECT COUNT(*) FROM transactions WHERE on_hold),
+		        (SELECT COUNT(*) FROM orders WHERE aml_status = 'flagged'),
+		        NOW()
+		 ON CONFLICT (id) DO UPDATE
+		    SET queued_checks = EXCLUDED.queued_checks,
+		        oldest_queued_at = EXCLUDED.oldest_queued_at,
+		        flagged_deposits = EXCLUDED.flagged_deposits,
+		        held_deposits = EXCLUDED.held_deposits,
+		        flagged_orders = EXCLUDED.flagged_orders,
+		        refreshed_at = EXCLUDED.refreshed_at`)
+	if err != nil {
+		return fmt.Errorf("failed to refresh AML backlog: %w", err)
+	}
+
+	return nil
+}
+
+// FindOrderDays returns order counts per creation day and status in [from, to)
+func (r *DashboardRepository) FindOrderDays(ctx context.Context, from, to time.Time) ([]entities.DashboardOrderDay, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT day::TIMESTAMP, status, orders FROM dashboard_orders_daily
+		  WHERE day >= $1::DATE AND day < $2::DATE
+		  ORDER BY day, status`,
+		from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dashboard orders: %w", err)
+	}
+	defer rows.Close()
+
+	days, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DashboardOrderDay])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dashboard order rows: %w", err)
+	}
+
+	return days, nil
+}
+
+// FindDepositDays returns deposit counts per detection day and network in [from, to)
+func (r *DashboardRepository) FindDepositDays(ctx context.Context, from, to time.Time) ([]entities.DashboardDepositDay, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT day::TIMESTAMP, chain, network, detected, confirmed, credited, ignored, volume::TEXT
+		   FROM dashboard_deposits_daily
+		  WHERE day >= $1::DATE AND day < $2::DATE
+		  ORDER BY day, chain, network`,
+		from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dashboard deposits: %w", err)
+	}
+	defer rows.Close()
+
+	days, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DashboardDepositDay])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dashboard deposit rows: %w", err)
+	}
+
+	return days, nil
+}
+
+// FindAMLBacklog returns the last AML queue snapshot or nil
+func (r *DashboardRepository) FindAMLBacklog(ctx context.Context) (*entities.DashboardAMLBacklog, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT queued_checks, oldest_queued_at, flagged_deposits, held_deposits, flagged_orders, refreshed_at
+		   FROM dashboard_aml_backlog`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AML backlog: %w", err)
+	}
+
+	backlog, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.DashboardAMLBacklog])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect AML backlog: %w", err)
+	}
+
+	return &backlog, nil
+}
+
+// FindDepositStats returns confirmed deposits per asset and hour or day starting in [from, to), of one asset code when set
+func (r *DashboardRepository) FindDepositStats(ctx context.Context, granularity entities.DepositStatsGranularity, from, to time.Time, asset string) ([]entities.DepositStatsBucket, error) {
+	query, ok := depositStatsQueries[granularity]
+	if !ok {
+		return nil, fmt.Errorf("unknown deposit stats granularity %q", granularity)
+	}
+
+	rows, err := r.db(ctx).Query(ctx, query, from, to, asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deposit stats: %w", err)
+	}
+	defer rows.Close()
+
+	buckets, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DepositStatsBucket])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deposit stats rows: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// ReplaceDays replaces the order and deposit projections with rows rebuilt elsewhere, e.g. replayed from the event journals.
+// Cursors are kept: the projector continues to apply changes on top of the replaced rows.
+func (r *DashboardRepository) ReplaceDays(ctx context.Context, orders []entities.DashboardOrderDay, deposits []entities.DashboardDepositDay) error {
+	var (
+		orderDays     = make([]time.Time, len(orders))
+		orderStatuses = make([]string, len(orders))
+		orderCounts   = make([]int, len(orders))
+	)
+	for i, row := range orders {
+		orderDays[i], orderStatuses[i], orderCounts[i] = row.Day, string(row.Status), row.Orders
+	}
+
+	var (
+		depositDays = make([]time.Time, len(deposits))
+		chains      = make([]string, len(deposits))
+		networks    = make([]string, len(deposits))
+		detected    = make([]int, len(deposits))
+		confirmed   = make([]int, len(deposits))
+		credited    = make([]int, len(deposits))
+		ignored     = make([]int, len(deposits))
+		volumes     = make([]string, len(deposits))
+	)
+	for i, row := range deposits {
+		depositDays[i], chains[i], networks[i] = row.Day, string(row.Chain), row.Network
+		detected[i], confirmed[i], credited[i], ignored[i], volumes[i] = row.Detected, row.Confirmed, row.Credited, row.Ignored, row.Volume
+	}
+
+	err := r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := r.db(txCtx).Exec(txCtx, `DELETE FROM dashboard_orders_daily`); err != nil {
+			return err
+		}
+		if _, err := r.db(txCtx).Exec(txCtx,
+			`INSERT INTO dashboard_orders_daily (day, status, orders)
+			 SELECT * FROM UNNEST($1::DATE[], $2::TEXT[], $3::INTEGER[])`,
+			orderDays, orderStatuses, orderCounts); err != nil {
+			return err
+		}
+
+		if _, err := r.db(txCtx).Exec(txCtx, `DELETE FROM dashboard_deposits_daily`); err != nil {
+			return err
+		}
+		_, err := r.db(txCtx).Exec(txCtx,
+			`INSERT INTO dashboard_deposits_daily (day, chain, network, detected, confirmed, credited, ignored, volume)
+			 SELECT * FROM UNNEST($1::DATE[], $2::TEXT[], $3::TEXT[], $4::INTEGER[], $5::INTEGER[], $6::INTEGER[], $7::INTEGER[], $8::NUMERIC[])`,
+			depositDays, chains, networks, detected, confirmed, credited, ignored, volumes)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace dashboard projections: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const depositEvidenceColumns = `tx_hash, chain, block_number, block_hash, bundle, sha256, attempts, last_error, archived_at, created_at`
+
+// DepositEvidenceRepository stores the archived on-chain evidence of credited deposits
+type DepositEvidenceRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewDepositEvidenceRepository creates a new deposit evidence repository.
+func NewDepositEvidenceRepository(logger *slog.Logger, pg *database.Postgres) *DepositEvidenceRepository {
+	return &DepositEvidenceRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindPendingDeposits returns credited deposits to wallets of the chain without archived evidence, oldest credit first.
+// Deposits that failed maxAttempts times are skipped.
+func (r *DepositEvidenceRepository) FindPendingDeposits(ctx context.Context, chain entities.Chain, maxAttempts, limit int) ([]entities.PendingEvidence, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT t.tx_hash, t.block_number
+		   FROM transactions t
+		   JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address) AND w.chain = $1
+		   LEFT JOIN deposit_evidence e ON e.tx_hash = t.tx_hash
+		  WHERE t.credited_at IS NOT NULL AND t.ignored_reason IS NULL
+		    AND (e.tx_hash IS NULL OR (e.archived_at IS NULL AND e.attempts < $2))
+		  ORDER BY t.credited_at
+		  LIMIT $3`,
+		chain, maxAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deposits without evidence: %w", err)
+	}
+	defer rows.Close()
+
+	pending, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.PendingEvidence])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect pending evidence rows: %w", err)
+	}
+
+	return pending, nil
+}
+
+// SaveEvidence archives the evidence. Archived rows are immutable.
+func (r *DepositEvidenceRepository) SaveEvidence(ctx context.Context, evidence *entities.DepositEvidence) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO deposit_evidence (tx_hash, chain, block_number, block_hash, bundle, sha256, attempts, archived_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, 1, NOW())
+		 ON CONFLICT (tx_hash) DO UPDATE
+		    SET block_number = EXCLUDED.block_number,
+		        block_hash = EXCLUDED.block_hash,
+		        bundle = EXCLUDED.bundle,
+		        sha256 = EXCLUDED.sha256,
+		        attempts = deposit_evidence.attempts + 1,
+		        last_error = NULL,
+		        archived_at = EXCLUDED.archived_at
+		 RETURNING attempts, archived_at, created_at`,
+		evidence.TxHash, evidence.Chain, evidence.BlockNumber, evidence.BlockHash, evidence.Bundle, evidence.SHA256,
+	).Scan(&evidence.Attempts, &evidence.ArchivedAt, &evidence.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save deposit evidence: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed archival attempt
+func (r *DepositEvidenceRepository) RecordFailure(ctx context.Context, txHash string, chain entities.Chain, message string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO deposit_evidence (tx_hash, chain, attempts, last_error)
+		 VALUES ($1, $2, 1, $3)
+		 ON CONFLICT (tx_hash) DO UPDATE
+		    SET attempts = deposit_evidence.attempts + 1,
+		        last_error = EXCLUDED.last_error`,
+		txHash, chain, message)
+	if err != nil {
+		return fmt.Errorf("failed to record deposit evidence failure: %w", err)
+	}
+
+	return nil
+}
+
+// FindEvidence returns the evidence record of the transaction or nil
+func (r *DepositEvidenceRepository) FindEvidence(ctx context.Context, txHash string) (*entities.DepositEvidence, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+depositEvidenceColumns+` FROM deposit_evidence WHERE LOWER(tx_hash) = LOWER($1)`, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deposit evidence: %w", err)
+	}
+
+	evidence, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.DepositEvidence])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deposit evidence: %w", err)
+	}
+
+	return &evidence, nil
+}
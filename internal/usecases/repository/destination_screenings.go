@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const destinationScreeningColumns = `id, kind, to_address, amount, risk_level, risk_score, category, source, verdict,
+                                     tx_hash, safe_tx_hash, withdrawal_id, initiated_by, checked_at`
+
+// DestinationScreeningsRepository stores AML screenings of withdrawal and settlement destinations
+type DestinationScreeningsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewDestinationScreeningsRepository creates a new destination screenings repository.
+func NewDestinationScreeningsRepository(logger *slog.Logger, pg *database.Postgres) *DestinationScreeningsRepository {
+	return &DestinationScreeningsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateScreening inserts the screening result before the transfer is sent
+func (r *DestinationScreeningsRepository) CreateScreening(ctx context.Context, screening *entities.DestinationScreening) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO destination_screenings (id, kind, to_address, amount, risk_level, risk_score, category, source,
+		                                     verdict, initiated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING checked_at`,
+		screening.ID, screening.Kind, screening.ToAddress, screening.Amount, screening.RiskLevel, screening.RiskScore,
+		screening.Category, screening.Source, screening.Verdict, screening.InitiatedBy,
+	).Scan(&screening.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create destination screening: %w", err)
+	}
+
+	return nil
+}
+
+// AttachTransfer links the screening to the outgoing transaction, Safe proposal or queued withdrawal
+func (r *DestinationScreeningsRepository) AttachTransfer(ctx context.Context, id string, txHash, safeTxHash, withdrawalID *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE destination_screenings SET tx_hash = $2, safe_tx_hash = $3, withdrawal_id = $4 WHERE id = $1`,
+		id, txHash, safeTxHash, withdrawalID)
+	if err != nil {
+		return fmt.Errorf("failed to attach transfer to destination screening: %w", err)
+	}
+
+	return nil
+}
+
+// FindScreenings returns the latest screenings, of all verdicts if verdict is empty
+func (r *DestinationScreeningsRepository) FindScreenings(ctx context.Context, verdict entities.ScreeningVerdict, limit int) ([]entities.DestinationScreening, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+destinationScreeningColumns+`
+		   FROM destination_screenings
+		  WHERE $1 = '' OR verdict = $1
+		  ORDER BY checked_at DESC
+		  LIMIT $2`,
+		string(verdict), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query destination screenings: %w", err)
+	}
+	defer rows.Close()
+
+	screenings, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DestinationScreening])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect destination screenings: %w", err)
+	}
+
+	return screenings, nil
+}
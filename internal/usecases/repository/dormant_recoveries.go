@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// DormantRecoveriesRepository finds dormant deposit wallets and stores what was recovered from them.
+type DormantRecoveriesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewDormantRecoveriesRepository creates a new dormant recoveries repository.
+func NewDormantRecoveriesRepository(logger *slog.Logger, pg *database.Postgres) *DormantRecoveriesRepository {
+	return &DormantRecoveriesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindDormantWallets retrieves active wallets without pending orders, open refunds or held deposits
+// and without any deposit or order activity since idleBefore. Unclaimed pool wallets are skipped.
+// The balance is checked by the caller.
+func (r *DormantRecoveriesRepository) FindDormantWallets(ctx context.Context, idleBefore time.Time, limit int) ([]entities.Wallet, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+walletColumns+`
+		   FROM wallets w
+		  WHERE w.retired_at IS NULL AND w.created_at < $1
+		    AND (w.pool_tier IS NULL OR w.pool_claimed_at IS NOT NULL)
+		    AND NOT EXISTS (SELECT 1 FROM orders o
+		                     WHERE o.wallet_id = w.id AND (o.status = 'pending' OR o.updated_at >= $1))
+		    AND NOT EXISTS (SELECT 1 FROM transactions t
+		                     WHERE t.wallet_address = w.address AND (NOT t.processed OR t.on_hold OR t.created_at >= $1))
+		    AND NOT EXISTS (SELECT 1 FROM refunds rf
+		                     WHERE rf.wallet_address = w.address AND rf.status IN ('pending', 'processing', 'sent', 'unknown'))
+		    AND NOT EXISTS (SELECT 1 FROM dormant_recoveries d
+		                     WHERE d.wallet_id = w.id AND d.action <> 'failed' AND d.created_at >= $1)
+		  ORDER BY w.id
+		  LIMIT $2`,
+		idleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dormant wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Wallet])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dormant wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// CreateRecovery stores the outcome of a dormant wallet recovery
+func (r *DormantRecoveriesRepository) CreateRecovery(ctx context.Context, recovery *entities.DormantRecovery) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO dormant_recoveries (wallet_id, user_id, wallet_address, amount, action, tx_hash, refund_id, error)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING id, created_at`,
+		recovery.WalletID, recovery.UserID, recovery.WalletAddress, recovery.Amount, recovery.Action,
+		recovery.TxHash, recovery.RefundID, recovery.Error,
+	).Scan(&recovery.ID, &recovery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create dormant recovery: %w", err)
+	}
+
+	return nil
+}
+
+// FindRecoveries retrieves recoveries since the given time, newest first
+func (r *DormantRecoveriesRepository) FindRecoveries(ctx context.Context, since time.Time, limit int) ([]entities.DormantRecovery, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, wallet_id, user_id, wallet_address, amount, action, tx_hash, refund_id::text, error, created_at
+		   FROM dormant_recoveries
+		  WHERE created_at >= $1
+		  ORDER BY created_at DESC
+		  LIMIT $2`,
+		since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dormant recoveries: %w", err)
+	}
+	defer rows.Close()
+
+	recoveries, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DormantRecovery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dormant recoveries: %w", err)
+	}
+
+	return recoveries, nil
+}
+
+// GetRecoveryTotals returns the number and the sum in minimal units of recoveries since the given time by action
+func (r *DormantRecoveriesRepository) GetRecoveryTotals(ctx context.Context, since time.Time) ([]entities.DormantRecoveryTotal, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT action, COUNT(*)::int, COALESCE(SUM(amount::numeric), 0)::text
+		   FROM dormant_recoveries
+		  WHERE created_at >= $1
+		  GROUP BY action
+		  ORDER BY action`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dormant recovery totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DormantRecoveryTotal])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dormant recovery totals: %w", err)
+	}
+
+	return totals, nil
+}
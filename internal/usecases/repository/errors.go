@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды ошибок PostgreSQL для нарушений ограничений схемы
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+	// Значение не приводится к типу колонки, в том числе неизвестное значение enum статуса
+	pgInvalidTextRepresentation = "22P02"
+)
+
+var (
+	// ErrUniqueViolation is returned when a record with the same unique key already exists
+	ErrUniqueViolation = errors.New("record already exists")
+	// ErrForeignKeyViolation is returned when a referenced record does not exist or a deleted record is still referenced
+	ErrForeignKeyViolation = errors.New("record references a missing record or is still referenced")
+	// ErrCheckViolation is returned when a value is rejected by a check constraint, a NOT NULL column or an enum type
+	ErrCheckViolation = errors.New("value violates a schema constraint")
+)
+
+// constraintError maps a PostgreSQL constraint violation to the matching repository error,
+// keeping the original error with the constraint name in the chain. Other errors are returned unchanged.
+func constraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return fmt.Errorf("%w: %w", ErrUniqueViolation, err)
+	case pgForeignKeyViolation:
+		return fmt.Errorf("%w: %w", ErrForeignKeyViolation, err)
+	case pgCheckViolation, pgNotNullViolation, pgInvalidTextRepresentation:
+		return fmt.Errorf("%w: %w", ErrCheckViolation, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const fiatPayoutColumns = `id, user_id, order_id, method, status, asset, crypto_amount, rate, fiat_amount, currency, destination,
+	provider_ref, attempts, failure_reason, created_at, updated_at, completed_at`
+
+// FiatPayoutsRepository stores fiat payouts to sellers and their ledger postings.
+type FiatPayoutsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewFiatPayoutsRepository creates a new fiat payouts repository.
+func NewFiatPayoutsRepository(logger *slog.Logger, pg *database.Postgres) *FiatPayoutsRepository {
+	return &FiatPayoutsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, so a payout and its postings are stored together
+func (r *FiatPayoutsRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, fn)
+}
+
+// LockPayableOrder locks a completed order of the user that is not paid by a merchant settlement or an active fiat payout.
+// Sandbox orders are never payable. Returns nil if there is no such order.
+func (r *FiatPayoutsRepository) LockPayableOrder(ctx context.Context, userID int64, orderID int) (*entities.Order, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, user_id, wallet_id, asset_id, amount, expected_amount, memo, status, aml_status, aml_notes, is_test, created_at, updated_at
+		   FROM orders o
+		  WHERE o.id = $1 AND o.user_id = $2 AND o.status = 'completed' AND o.settlement_id IS NULL AND NOT o.is_test
+		    AND NOT EXISTS (SELECT 1 FROM fiat_payouts p WHERE p.order_id = o.id AND p.status <> 'failed')
+		  FOR UPDATE`,
+		orderID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payable order: %w", err)
+	}
+	defer rows.Close()
+
+	order, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Order])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect payable order: %w", err)
+	}
+
+	return &order, nil
+}
+
+// CreatePayout inserts a pending payout
+func (r *FiatPayoutsRepository) CreatePayout(ctx context.Context, payout *entities.FiatPayout) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO fiat_payouts (id, user_id, order_id, method, status, asset, crypto_amount, rate, fiat_amount, currency, destination)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 RETURNING created_at, updated_at`,
+		payout.ID, payout.UserID, payout.OrderID, payout.Method, payout.Status, payout.Asset, payout.CryptoAmount,
+		payout.Rate, payout.FiatAmount, payout.Currency, payout.Destination,
+	).Scan(&payout.CreatedAt, &payout.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create fiat payout: %w", err)
+	}
+
+	return nil
+}
+
+// AddPosting stores a ledger posting of the payout
+func (r *FiatPayoutsRepository) AddPosting(ctx context.Context, posting *entities.FiatLedgerPosting) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO fiat_ledger_postings (payout_id, debit_account, credit_account, amount, currency, memo)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, created_at`,
+		posting.PayoutID, posting.DebitAccount, posting.CreditAccount, posting.Amount, posting.Currency, posting.Memo,
+	).Scan(&posting.ID, &posting.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add fiat ledger posting: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSubmitted stores the provider reference of a payout accepted by the provider
+func (r *FiatPayoutsRepository) MarkSubmitted(ctx context.Context, id, providerRef string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE fiat_payouts SET status = 'processing', provider_ref = $2, attempts = attempts + 1, updated_at = NOW()
+		  WHERE id = $1 AND status = 'pending'`,
+		id, providerRef)
+	if err != nil {
+		return fmt.Errorf("failed to mark fiat payout submitted: %w", err)
+	}
+
+	return nil
+}
+
+// RecordAttemptError stores the error of a failed submission attempt, the payout stays pending for a retry
+func (r *FiatPayoutsRepository) RecordAttemptError(ctx context.Context, id, reason string) (int, error) {
+	var attempts int
+	err := r.db(ctx).QueryRow(ctx,
+		`UPDATE fiat_payouts SET attempts = attempts + 1, failure_reason = $2, updated_at = NOW()
+		  WHERE id = $1
+		  RETURNING attempts`,
+		id, reason).Scan(&attempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record fiat payout attempt: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// MarkFinished moves a pending, processing or reviewed payout to completed or failed.
+// Returns false if the payout has already finished.
+func (r *FiatPayoutsRepository) MarkFinished(ctx context.Context, id string, status entities.FiatPayoutStatus, reason *string) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE fiat_payouts
+		    SET status = $2, failure_reason = COALESCE($3, failure_reason), updated_at = NOW(),
+		        completed_at = CASE WHEN $2 = 'completed' THEN NOW() END
+		  WHERE id = $1 AND status IN ('pending', 'processing', 'review')`,
+		id, status, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to finish fiat payout: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// MarkReview moves a pending payout to manual review. Returns false if the payout is no longer pending.
+func (r *FiatPayoutsRepository) MarkReview(ctx context.Context, id, reason string) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE fiat_payouts SET status = 'review', failure_reason = $2, updated_at = NOW()
+		  WHERE id = $1 AND status = 'pending'`,
+		id, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to move fiat payout to review: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// FindByID returns the payout or nil if it does not exist
+func (r *FiatPayoutsRepository) FindByID(ctx context.Context, id string) (*entities.FiatPayout, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+fiatPayoutColumns+` FROM fiat_payouts WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fiat payout: %w", err)
+	}
+	defer rows.Close()
+
+	payout, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.FiatPayout])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect fiat payout: %w", err)
+	}
+
+	return &payout, nil
+}
+
+// FindByUser retrieves payouts of the user, newest first
+func (r *FiatPayoutsRepository) FindByUser(ctx context.Context, userID int64, limit int) ([]entities.FiatPayout, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+fiatPayoutColumns+` FROM fiat_payouts WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user fiat payouts: %w", err)
+	}
+	defer rows.Close()
+
+	payouts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.FiatPayout])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user fiat payouts: %w", err)
+	}
+
+	return payouts, nil
+}
+
+// FindByStatus retrieves payouts with the given status, all payouts if status is empty, oldest first
+func (r *FiatPayoutsRepository) FindByStatus(ctx context.Context, status entities.FiatPayoutStatus, limit int) ([]entities.FiatPayout, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+fiatPayoutColumns+` FROM fiat_payouts WHERE $1 = '' OR status = $1 ORDER BY created_at LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fiat payouts: %w", err)
+	}
+	defer rows.Close()
+
+	payouts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.FiatPayout])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect fiat payouts: %w", err)
+	}
+
+	return payouts, nil
+}
+
+// FindPostings retrieves ledger postings of the payout in the order they were made
+func (r *FiatPayoutsRepository) FindPostings(ctx context.Context, payoutID string) ([]entities.FiatLedgerPosting, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, payout_id, debit_account, credit_account, amount, currency, memo, created_at
+		   FROM fiat_ledger_postings WHERE payout_id = $1 ORDER BY id`,
+		payoutID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fiat ledger postings: %w", err)
+	}
+	defer rows.Close()
+
+	postings, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.FiatLedgerPosting])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect fiat ledger postings: %w", err)
+	}
+
+	return postings, nil
+}
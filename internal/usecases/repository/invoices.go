@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const invoiceColumns = `id, merchant_id, fiat_amount, fiat_currency, accepted_assets, description, status, status_token,
+                        selected_asset, crypto_amount, quote_rate, order_id, wallet_address, expires_at, created_at, updated_at`
+
+// InvoicesRepository stores merchant invoices.
+type InvoicesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewInvoicesRepository creates a new invoices repository.
+func NewInvoicesRepository(logger *slog.Logger, pg *database.Postgres) *InvoicesRepository {
+	return &InvoicesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateInvoice inserts a new invoice
+func (r *InvoicesRepository) CreateInvoice(ctx context.Context, invoice *entities.Invoice) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO invoices (id, merchant_id, fiat_amount, fiat_currency, accepted_assets, description, status, status_token, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING created_at, updated_at`,
+		invoice.ID, invoice.MerchantID, invoice.FiatAmount, invoice.FiatCurrency, invoice.AcceptedAssets,
+		invoice.Description, invoice.Status, invoice.StatusToken, invoice.ExpiresAt,
+	).Scan(&invoice.CreatedAt, &invoice.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	return nil
+}
+
+// FindByStatusToken retrieves an invoice by its public status token
+func (r *InvoicesRepository) FindByStatusToken(ctx context.Context, token string) (*entities.Invoice, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE status_token = $1`, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoice: %w", err)
+	}
+	defer rows.Close()
+
+	invoice, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.Invoice])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect invoice row: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+// FindByMerchant retrieves invoices of a merchant, newest first
+func (r *InvoicesRepository) FindByMerchant(ctx context.Context, merchantID int64, limit int) ([]entities.Invoice, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE merchant_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Invoice])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect invoice rows: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// AttachOrder records the selected asset and the order created for it.
+// Returns false if the invoice is no longer open (another request selected an asset first).
+func (r *InvoicesRepository) AttachOrder(ctx context.Context, invoiceID string, quote entities.InvoiceQuote, orderID int, walletAddress string) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE invoices
+		    SET selected_asset = $2, crypto_amount = $3, quote_rate = $4, order_id = $5, wallet_address = $6,
+		        status = $7, updated_at = NOW()
+		  WHERE id = $1 AND status = $8`,
+		invoiceID, quote.Asset, quote.Amount, quote.Rate, orderID, walletAddress,
+		entities.InvoiceStatusAwaitingPayment, entities.InvoiceStatusOpen)
+	if err != nil {
+		return false, fmt.Errorf("failed to attach order to invoice: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// UpdateStatus changes the invoice status
+func (r *InvoicesRepository) UpdateStatus(ctx context.Context, invoiceID string, status entities.InvoiceStatus) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE invoices SET status = $2, updated_at = NOW() WHERE id = $1",
+		invoiceID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// Счетчики свипов считаются по таблице свипов, чтобы прогресс не расходился с записями
+const keyRotationColumns = `r.id, r.from_version, r.to_version, r.status, r.sample_addresses, r.started_by, r.activated_by,
+                            r.created_at, r.activated_at, r.completed_at,
+                            COUNT(s.wallet_id)::int,
+                            COUNT(s.wallet_id) FILTER (WHERE s.status = 'pending')::int,
+                            COUNT(s.wallet_id) FILTER (WHERE s.status = 'swept')::int,
+                            COUNT(s.wallet_id) FILTER (WHERE s.status = 'skipped')::int,
+                            COUNT(s.wallet_id) FILTER (WHERE s.status = 'failed')::int`
+
+const keyRotationSweepColumns = `rotation_id, wallet_id, user_id, from_address, to_wallet_id, to_address, amount, status,
+                                 tx_hash, error, updated_at`
+
+// KeyRotationsRepository stores master seed rotations and the sweeps of wallets derived from old seeds
+type KeyRotationsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewKeyRotationsRepository creates a new key rotations repository.
+func NewKeyRotationsRepository(logger *slog.Logger, pg *database.Postgres) *KeyRotationsRepository {
+	return &KeyRotationsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateRotation inserts a verified rotation. Returns ErrUniqueViolation if another rotation is not completed.
+func (r *KeyRotationsRepository) CreateRotation(ctx context.Context, rotation *entities.KeyRotation) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO key_rotations (from_version, to_version, status, sample_addresses, started_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at`,
+		rotation.FromVersion, rotation.ToVersion, rotation.Status, rotation.SampleAddresses, rotation.StartedBy,
+	).Scan(&rotation.ID, &rotation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create key rotation: %w", constraintError(err))
+	}
+
+	return nil
+}
+
+// FindRotation retrieves the rotation with its sweep progress. Returns nil if it does not exist.
+func (r *KeyRotationsRepository) FindRotation(ctx context.Context, id int) (*entities.KeyRotation, error) {
+	rotations, err := r.queryRotations(ctx, `WHERE r.id = $1`, 1, id)
+	if err != nil || len(rotations) == 0 {
+		return nil, err
+	}
+	return &rotations[0], nil
+}
+
+// FindRotations returns the latest rotations with their sweep progress
+func (r *KeyRotationsRepository) FindRotations(ctx context.Context, limit int) ([]entities.KeyRotation, error) {
+	return r.queryRotations(ctx, ``, limit)
+}
+
+// FindRotationByStatus returns the latest rotation in the status. Returns nil if there is none.
+func (r *KeyRotationsRepository) FindRotationByStatus(ctx context.Context, status entities.KeyRotationStatus) (*entities.KeyRotation, error) {
+	rotations, err := r.queryRotations(ctx, `WHERE r.status = $1`, 1, status)
+	if err != nil || len(rotations) == 0 {
+		return nil, err
+	}
+	return &rotations[0], nil
+}
+
+// FindActivatedVersion returns the key version of the latest activated rotation. Returns 0 if no rotation was activated.
+func (r *KeyRotationsRepository) FindActivatedVersion(ctx context.Context) (int, error) {
+	var version int
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT to_version FROM key_rotations WHERE activated_at IS NOT NULL ORDER BY activated_at DESC LIMIT 1`,
+	).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query activated key version: %w", err)
+	}
+
+	return version, nil
+}
+
+// ActivateRotation marks a verified rotation active. Returns false if the rotation is not verified.
+func (r *KeyRotationsRepository) ActivateRotation(ctx context.Context, id int, activatedBy string) (bool, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE key_rotations SET status = 'active', activated_by = $2, activated_at = NOW()
+		  WHERE id = $1 AND status = 'verified'`,
+		id, activatedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to activate key rotation: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// QueueSweeps queues a sweep of every open wallet of the chain and network derived from another key version,
+// except keyless wallets whose derivation path starts with skipPathPrefix, and moves the active rotation to sweeping.
+// Returns the number of queued wallets and false if the rotation is not active.
+func (r *KeyRotationsRepository) QueueSweeps(ctx context.Context, id, toVersion int, chain entities.Chain, network, skipPathPrefix string) (int, bool, error) {
+	var queued int
+	var moved bool
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		tag, err := r.db(ctx).Exec(ctx,
+			`UPDATE key_rotations SET status = 'sweeping' WHERE id = $1 AND status = 'active'`, id)
+		if err != nil {
+			return fmt.Errorf("failed to update key rotation status: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+		moved = true
+
+		tag, err = r.db(ctx).Exec(ctx,
+			`INSERT INTO key_rotation_sweeps (rotation_id, wallet_id, user_id, from_address)
+			 SELECT $1, id, user_id, address
+			   FROM wallets
+			  WHERE key_version <> $2 AND chain = $3 AND network = $4
+			    AND archived_at IS NULL AND retired_at IS NULL
+			    AND NOT starts_with(derivation_path, $5)
+			 ON CONFLICT DO NOTHING`,
+			id, toVersion, chain, network, skipPathPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to queue key rotation sweeps: %w", err)
+		}
+		queued = int(tag.RowsAffected())
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return queued, moved, nil
+}
+
+// FindPendingSweeps returns queued sweeps of the rotation
+func (r *KeyRotationsRepository) FindPendingSweeps(ctx context.Context, rotationID, limit int) ([]entities.KeyRotationSweep, error) {
+	return r.querySweeps(ctx,
+		`SELECT `+keyRotationSweepColumns+`
+		   FROM key_rotation_sweeps
+		  WHERE rotation_id = $1 AND status = 'pending'
+		  ORDER BY user_id, wallet_id
+		  LIMIT $2`,
+		rotationID, limit)
+}
+
+// FindSweeps returns the sweeps of the rotation, of all statuses if status is empty
+func (r *KeyRotationsRepository) FindSweeps(ctx context.Context, rotationID int, status entities.KeyRotationSweepStatus, limit int) ([]entities.KeyRotationSweep, error) {
+	return r.querySweeps(ctx,
+		`SELECT `+keyRotationSweepColumns+`
+		   FROM key_rotation_sweeps
+		  WHERE rotation_id = $1 AND ($2 = '' OR status = $2)
+		  ORDER BY updated_at DESC
+		  LIMIT $3`,
+		rotationID, string(status), limit)
+}
+
+// FindDestination returns the new-seed wallet that already received a sweep of the user in the rotation.
+// Returns nil if none of the user's wallets was swept yet.
+func (r *KeyRotationsRepository) FindDestination(ctx context.Context, rotationID int, userID int64) (*int, *string, error) {
+	var walletID int
+	var address string
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT to_wallet_id, to_address
+		   FROM key_rotation_sweeps
+		  WHERE rotation_id = $1 AND user_id = $2 AND to_wallet_id IS NOT NULL
+		  LIMIT 1`,
+		rotationID, userID,
+	).Scan(&walletID, &address)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query key rotation destination: %w", err)
+	}
+
+	return &walletID, &address, nil
+}
+
+// UpdateSweep stores the outcome of the sweep
+func (r *KeyRotationsRepository) UpdateSweep(ctx context.Context, sweep *entities.KeyRotationSweep) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`UPDATE key_rotation_sweeps
+		    SET to_wallet_id = $3, to_address = $4, amount = $5, status = $6, tx_hash = $7, error = $8, updated_at = NOW()
+		  WHERE rotation_id = $1 AND wallet_id = $2
+		  RETURNING updated_at`,
+		sweep.RotationID, sweep.WalletID, sweep.ToWalletID, sweep.ToAddress, sweep.Amount, sweep.Status, sweep.TxHash, sweep.Error,
+	).Scan(&sweep.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update key rotation sweep: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteRotation marks the sweeping rotation completed once no sweep is pending.
+// Returns false if sweeps are still pending.
+func (r *KeyRotationsRepository) CompleteRotation(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE key_rotations SET status = 'completed', completed_at = NOW()
+		  WHERE id = $1 AND status = 'sweeping'
+		    AND NOT EXISTS (SELECT 1 FROM key_rotation_sweeps WHERE rotation_id = $1 AND status = 'pending')`,
+		id)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete key rotation: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *KeyRotationsRepository) queryRotations(ctx context.Context, where string, limit int, args ...any) ([]entities.KeyRotation, error) {
+	args = append(args, limit)
+	rows, err := r.db(ctx).Query(ctx,
+		fmt.Sprintf(`SELECT `+keyRotationColumns+`
+		   FROM key_rotations r
+		   LEFT JOIN key_rotation_sweeps s ON s.rotation_id = r.id
+		  %s
+		  GROUP BY r.id
+		  ORDER BY r.id DESC
+		  LIMIT $%d`, where, len(args)),
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query key rotations: %w", err)
+	}
+	defer rows.Close()
+
+	rotations, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.KeyRotation])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect key rotations: %w", err)
+	}
+
+	return rotations, nil
+}
+
+func (r *KeyRotationsRepository) querySweeps(ctx context.Context, query string, args ...any) ([]entities.KeyRotationSweep, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query key rotation sweeps: %w", err)
+	}
+	defer rows.Close()
+
+	sweeps, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.KeyRotationSweep])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect key rotation sweeps: %w", err)
+	}
+
+	return sweeps, nil
+}
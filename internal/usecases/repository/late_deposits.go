@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// LateDepositsRepository finds deposits credited to wallets of expired orders and stores how they were handled.
+type LateDepositsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewLateDepositsRepository creates a new late deposits repository.
+func NewLateDepositsRepository(logger *slog.Logger, pg *database.Postgres) *LateDepositsRepository {
+	return &LateDepositsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindLateDeposits retrieves deposits credited since the given time that paid no order, landed on a wallet
+// without pending orders and were credited after an order of the wallet expired. The latest expired order
+// is returned for each deposit, with memos it must match the memo of the deposit.
+func (r *LateDepositsRepository) FindLateDeposits(ctx context.Context, since time.Time, limit int) ([]entities.LateDeposit, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT t.tx_hash, t.wallet_address, t.from_address, t.amount,
+		        o.id AS order_id, o.user_id, o.asset, o.decimals, o.expired_at
+		   FROM transactions t
+		   JOIN wallets w ON w.address = t.wallet_address
+		   JOIN LATERAL (
+		         SELECT eo.id, eo.user_id::BIGINT AS user_id, COALESCE(a.code, '') AS asset,
+		                COALESCE(a.decimals, $3) AS decimals, eo.expired_at
+		           FROM orders eo
+		           LEFT JOIN assets a ON a.id = eo.asset_id
+		          WHERE eo.wallet_id = w.id AND eo.status = 'expired'
+		            AND eo.created_at <= t.created_at AND eo.expired_at <= COALESCE(t.credited_at, t.updated_at)
+		            AND (eo.memo IS NULL OR eo.memo = COALESCE(t.memo, ''))
+		          ORDER BY eo.expired_at DESC
+		          LIMIT 1) o ON TRUE
+		  WHERE t.processed AND NOT t.on_hold AND t.aml_status <> 'flagged'
+		    AND COALESCE(t.credited_at, t.updated_at) >= $1
+		    AND NOT EXISTS (SELECT 1 FROM orders c WHERE c.completed_tx_hash = t.tx_hash)
+		    AND NOT EXISTS (SELECT 1 FROM orders p WHERE p.wallet_id = w.id AND p.status = 'pending')
+		    AND NOT EXISTS (SELECT 1 FROM refunds rf WHERE rf.deposit_tx_hash = t.tx_hash)
+		    AND NOT EXISTS (SELECT 1 FROM late_deposits l WHERE l.tx_hash = t.tx_hash)
+		  ORDER BY t.credited_at
+		  LIMIT $2`,
+		since, limit, legacyOrderDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query late deposits: %w", err)
+	}
+	defer rows.Close()
+
+	deposits, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.LateDeposit])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect late deposits: %w", err)
+	}
+
+	return deposits, nil
+}
+
+// RecordLateDeposit stores how the late deposit was handled, a deposit is recorded only once
+func (r *LateDepositsRepository) RecordLateDeposit(ctx context.Context, deposit entities.LateDeposit, action entities.LateDepositAction, refundID, reason *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO late_deposits (tx_hash, order_id, user_id, wallet_address, amount, action, refund_id, error)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (tx_hash) DO NOTHING`,
+		deposit.TxHash, deposit.OrderID, deposit.UserID, deposit.WalletAddress, deposit.Amount, action, refundID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record late deposit: %w", err)
+	}
+
+	return nil
+}
+
+// ReopenOrder returns the expired order to pending and queues the deposit for crediting again, so the next
+// processing pass matches it with the order. The expiration period of the order restarts. Returns
+// ErrUniqueViolation if a pending order of the wallet already uses the memo or the exact amount of the order.
+func (r *LateDepositsRepository) ReopenOrder(ctx context.Context, deposit entities.LateDeposit) error {
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		result, err := r.db(ctx).Exec(ctx,
+			`UPDATE orders SET status = 'pending', expired_at = NULL, reopened_at = NOW(), updated_at = NOW()
+			  WHERE id = $1 AND status = 'expired'`,
+			deposit.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to reopen order %d: %w", deposit.OrderID, constraintError(err))
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("order %d is no longer expired", deposit.OrderID)
+		}
+
+		if _, err = r.db(ctx).Exec(ctx,
+			"UPDATE transactions SET processed = false, credited_at = NULL, updated_at = NOW() WHERE tx_hash = $1",
+			deposit.TxHash); err != nil {
+			return fmt.Errorf("failed to requeue deposit %s: %w", deposit.TxHash, err)
+		}
+
+		return r.RecordLateDeposit(ctx, deposit, entities.LateDepositReopened, nil, nil)
+	})
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const ledgerEntryColumns = `id, asset_id, kind, operation, tx_hash, from_address, to_address, amount, fee, gas_price,
+                            gas_limit, gas_used, gas_cost, effective_gas_price, status, created_at, settled_at,
+                            gas_currency, gas_rate, fiat_currency, gas_cost_fiat`
+
+// LedgerRepository stores outgoing transactions of the platform with fees and gas costs.
+type LedgerRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewLedgerRepository creates a new ledger repository.
+func NewLedgerRepository(logger *slog.Logger, pg *database.Postgres) *LedgerRepository {
+	return &LedgerRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateEntry inserts a sent transaction
+func (r *LedgerRepository) CreateEntry(ctx context.Context, entry *entities.LedgerEntry) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO ledger_entries (id, asset_id, kind, operation, tx_hash, from_address, to_address, amount, fee,
+		                             gas_price, gas_limit, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 RETURNING created_at`,
+		entry.ID, entry.AssetID, entry.Kind, entry.Operation, entry.TxHash, entry.FromAddress, entry.ToAddress,
+		entry.Amount, entry.Fee, entry.GasPrice, entry.GasLimit, entry.Status,
+	).Scan(&entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceTxHash points the entry to the replacement transaction sent with a higher gas price
+func (r *LedgerRepository) ReplaceTxHash(ctx context.Context, oldTxHash, newTxHash, gasPrice string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE ledger_entries SET tx_hash = $2, gas_price = $3 WHERE tx_hash = $1 AND status = 'pending'",
+		oldTxHash, newTxHash, gasPrice)
+	if err != nil {
+		return fmt.Errorf("failed to replace ledger entry tx hash: %w", err)
+	}
+
+	return nil
+}
+
+// CancelEntry replaces the hash of a cancelled transaction and turns the entry into an operational zero-value transfer
+func (r *LedgerRepository) CancelEntry(ctx context.Context, oldTxHash, newTxHash, gasPrice string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE ledger_entries
+		    SET tx_hash = $2, gas_price = $3, gas_limit = 21000, kind = 'operational', operation = 'cancel',
+		        to_address = from_address, amount = '0', fee = '0'
+		  WHERE tx_hash = $1 AND status = 'pending'`,
+		oldTxHash, newTxHash, gasPrice)
+	if err != nil {
+		return fmt.Errorf("failed to cancel ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// FindPending retrieves entries waiting for a receipt, oldest first
+func (r *LedgerRepository) FindPending(ctx context.Context, limit int) ([]entities.LedgerEntry, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+ledgerEntryColumns+` FROM ledger_entries WHERE status = 'pending' ORDER BY created_at LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.LedgerEntry])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect ledger entry rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Settle stores the outcome of the transaction. Dropped transactions have no gas usage,
+// the conversion is nil when gas was not paid or the rate was unavailable.
+func (r *LedgerRepository) Settle(ctx context.Context, id string, status entities.LedgerEntryStatus, gasUsed *int64, gasCost, effectiveGasPrice *string, conversion *entities.LedgerGasConversion) error {
+	var gasCurrency, gasRate, fiatCurrency, gasCostFiat *string
+	if conversion != nil {
+		gasCurrency, gasRate = &conversion.GasCurrency, &conversion.GasRate
+		fiatCurrency, gasCostFiat = &conversion.FiatCurrency, &conversion.GasCostFiat
+	}
+
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE ledger_entries
+		    SET status = $2, gas_used = $3, gas_cost = $4, effective_gas_price = $5, settled_at = NOW(),
+		        gas_currency = $6, gas_rate = $7, fiat_currency = $8, gas_cost_fiat = $9
+		  WHERE id = $1`,
+		id, status, gasUsed, gasCost, effectiveGasPrice, gasCurrency, gasRate, fiatCurrency, gasCostFiat)
+	if err != nil {
+		return fmt.Errorf("failed to settle ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// Summarize aggregates entries created in [from, to) by period, asset and kind.
+// Fees and volume count only confirmed transactions, gas counts every mined transaction including reverted ones.
+// WithinGasBudgetLock runs fn in a transaction holding the gas budget lock,
+// so concurrent transactions cannot both pass the check against the same spending
+func (r *LedgerRepository) WithinGasBudgetLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := r.db(txCtx).Exec(txCtx, "SELECT pg_advisory_xact_lock(hashtext('gas_budgets'))"); err != nil {
+			return fmt.Errorf("failed to acquire gas budget lock: %w", err)
+		}
+		return fn(txCtx)
+	})
+}
+
+// CreateReservation reserves gas in wei of a transaction about to be signed
+func (r *LedgerRepository) CreateReservation(ctx context.Context, id string, kind entities.LedgerEntryKind, gasCost string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"INSERT INTO gas_reservations (id, kind, gas_cost) VALUES ($1, $2, $3)",
+		id, kind, gasCost)
+	if err != nil {
+		return fmt.Errorf("failed to create gas reservation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReservation releases the reservation once the transaction is recorded or was never sent
+func (r *LedgerRepository) DeleteReservation(ctx context.Context, id string) error {
+	_, err := r.db(ctx).Exec(ctx, "DELETE FROM gas_reservations WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete gas reservation: %w", err)
+	}
+
+	return nil
+}
+
+// GasSpentSince returns gas in wei by entry kind: the settled cost, the maximum cost of pending transactions
+// and the reservations of transactions not recorded yet
+func (r *LedgerRepository) GasSpentSince(ctx context.Context, since time.Time) (map[entities.LedgerEntryKind]string, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT kind, COALESCE(SUM(cost), 0)::TEXT
+		   FROM (SELECT kind, COALESCE(gas_cost::NUMERIC, gas_price::NUMERIC * gas_limit) AS cost
+		           FROM ledger_entries
+		          WHERE created_at >= $1 AND status <> 'dropped'
+		          UNION ALL
+		         SELECT kind, gas_cost::NUMERIC
+		           FROM gas_reservations
+		          WHERE created_at >= $1) AS spent
+		  GROUP BY kind`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gas spent: %w", err)
+	}
+	defer rows.Close()
+
+	spent := make(map[entities.LedgerEntryKind]string)
+	for rows.Next() {
+		var (
+			kind  entities.LedgerEntryKind
+			value string
+		)
+		if err = rows.Scan(&kind, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan gas spent: %w", err)
+		}
+		spent[kind] = value
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gas spent: %w", err)
+	}
+
+	return spent, nil
+}
+
+func (r *LedgerRepository) Summarize(ctx context.Context, from, to time.Time, period entities.PnLPeriod) ([]entities.LedgerSummary, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT date_trunc($3, created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS period_start,
+		        asset_id,
+		        kind,
+		        COUNT(*) FILTER (WHERE status = 'confirmed') AS transactions,
+		        COALESCE(SUM(amount::NUMERIC) FILTER (WHERE status = 'confirmed'), 0)::TEXT AS volume,
+		        COALESCE(SUM(fee::NUMERIC) FILTER (WHERE status = 'confirmed'), 0)::TEXT AS fees,
+		        COALESCE(SUM(gas_cost::NUMERIC), 0)::TEXT AS gas_cost
+		   FROM ledger_entries
+		  WHERE created_at >= $1 AND created_at < $2
+		  GROUP BY 1, 2, 3
+		  ORDER BY 1, 2, 3`,
+		from, to, string(period))
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize ledger: %w", err)
+	}
+	defer rows.Close()
+
+	summaries, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.LedgerSummary])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect ledger summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// SummarizeGasFiat aggregates the gas of entries created in [from, to) by period and asset in the fiat currency
+// at the rates of execution time. Mined entries converted to another currency or not converted are only counted.
+func (r *LedgerRepository) SummarizeGasFiat(ctx context.Context, from, to time.Time, period entities.PnLPeriod, fiatCurrency string) ([]entities.LedgerGasFiatSummary, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT date_trunc($3, created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS period_start,
+		        asset_id,
+		        COALESCE(SUM(gas_cost_fiat::NUMERIC) FILTER (WHERE fiat_currency = $4), 0)::TEXT AS gas_cost_fiat,
+		        COUNT(*) FILTER (WHERE fiat_currency IS DISTINCT FROM $4) AS unconverted
+		   FROM ledger_entries
+		  WHERE created_at >= $1 AND created_at < $2 AND gas_cost IS NOT NULL
+		  GROUP BY 1, 2
+		  ORDER BY 1, 2`,
+		from, to, string(period), fiatCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize ledger gas in fiat: %w", err)
+	}
+	defer rows.Close()
+
+	summaries, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.LedgerGasFiatSummary])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect ledger gas fiat rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// FeeStats aggregates the gas paid by mined transactions created in [from, to) per UTC day, chain, network and kind
+func (r *LedgerRepository) FeeStats(ctx context.Context, from, to time.Time) ([]entities.FeeStats, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT date_trunc('day', l.created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS day,
+		        a.chain,
+		        a.network,
+		        l.kind,
+		        COUNT(*) AS transactions,
+		        COALESCE(SUM(COALESCE(l.effective_gas_price, l.gas_price)::NUMERIC), 0)::TEXT AS gas_price_sum,
+		        COALESCE(SUM(l.gas_cost::NUMERIC), 0)::TEXT AS gas_cost,
+		        COALESCE(MAX(l.gas_cost::NUMERIC), 0)::TEXT AS max_gas_cost
+		   FROM ledger_entries l
+		   JOIN assets a ON a.id = l.asset_id
+		  WHERE l.created_at >= $1 AND l.created_at < $2 AND l.gas_cost IS NOT NULL
+		  GROUP BY 1, 2, 3, 4
+		  ORDER BY 1, 2, 3, 4`,
+		from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fee stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.FeeStats])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect fee stats rows: %w", err)
+	}
+
+	return stats, nil
+}
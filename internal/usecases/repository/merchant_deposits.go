@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const merchantDepositColumns = `merchant_id, mode, attribution, updated_by, created_at, updated_at`
+
+// MerchantDepositsRepository stores the deposit mode of merchants
+type MerchantDepositsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewMerchantDepositsRepository creates a new merchant deposit settings repository.
+func NewMerchantDepositsRepository(logger *slog.Logger, pg *database.Postgres) *MerchantDepositsRepository {
+	return &MerchantDepositsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindSettings returns the deposit settings of the merchant or nil if they are not configured
+func (r *MerchantDepositsRepository) FindSettings(ctx context.Context, merchantID int64) (*entities.MerchantDepositSettings, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+merchantDepositColumns+` FROM merchant_deposit_settings WHERE merchant_id = $1`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant deposit settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.MerchantDepositSettings])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect merchant deposit settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// FindSettingsByMode returns the settings of merchants in the mode, most recently changed first
+func (r *MerchantDepositsRepository) FindSettingsByMode(ctx context.Context, mode entities.DepositMode) ([]entities.MerchantDepositSettings, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+merchantDepositColumns+` FROM merchant_deposit_settings WHERE mode = $1 ORDER BY updated_at DESC`, mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant deposit settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.MerchantDepositSettings])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect merchant deposit settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SetSettings creates or replaces the deposit settings of the merchant
+func (r *MerchantDepositsRepository) SetSettings(ctx context.Context, settings *entities.MerchantDepositSettings) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO merchant_deposit_settings (merchant_id, mode, attribution, updated_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (merchant_id) DO UPDATE
+		    SET mode = EXCLUDED.mode,
+		        attribution = EXCLUDED.attribution,
+		        updated_by = EXCLUDED.updated_by,
+		        updated_at = NOW()
+		 RETURNING created_at, updated_at`,
+		settings.MerchantID, settings.Mode, settings.Attribution, settings.UpdatedBy,
+	).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set merchant deposit settings: %w", err)
+	}
+
+	return nil
+}
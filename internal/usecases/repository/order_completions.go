@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// OrderCompletionsRepository stores the queue of completed orders waiting for the post-completion pipeline
+type OrderCompletionsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewOrderCompletionsRepository creates a new order completions repository.
+func NewOrderCompletionsRepository(logger *slog.Logger, pg *database.Postgres) *OrderCompletionsRepository {
+	return &OrderCompletionsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindPendingCompletions returns completed orders not processed yet, oldest first
+func (r *OrderCompletionsRepository) FindPendingCompletions(ctx context.Context, limit int) ([]entities.OrderCompletedEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT $2::text, o.id, o.user_id, o.amount, COALESCE(a.code, ''), o.completed_tx_hash, o.is_test,
+		        COALESCE(o.completed_at, o.updated_at), oc.attempts
+		   FROM order_completions oc
+		   JOIN orders o ON o.id = oc.order_id
+		   LEFT JOIN assets a ON a.id = o.asset_id
+		  WHERE oc.processed_at IS NULL AND NOT oc.failed
+		  ORDER BY oc.created_at
+		  LIMIT $1`,
+		limit, entities.OrderCompletedEventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending order completions: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.OrderCompletedEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect pending order completions: %w", err)
+	}
+
+	return events, nil
+}
+
+// MarkProcessed records that every step of the pipeline succeeded for the order
+func (r *OrderCompletionsRepository) MarkProcessed(ctx context.Context, orderID int) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_completions SET last_error = NULL, processed_at = NOW() WHERE order_id = $1`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to mark order completion processed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAttemptFailed records a failed attempt. The completion stays queued unless giveUp is set.
+func (r *OrderCompletionsRepository) MarkAttemptFailed(ctx context.Context, orderID int, reason string, giveUp bool) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_completions SET attempts = attempts + 1, last_error = $2, failed = $3 WHERE order_id = $1`,
+		orderID, reason, giveUp)
+	if err != nil {
+		return fmt.Errorf("failed to record order completion error: %w", err)
+	}
+
+	return nil
+}
+
+// RequeueFailed returns a completion given up after the last attempt to the queue with a fresh attempt budget.
+// Returns false if the order has no failed completion.
+func (r *OrderCompletionsRepository) RequeueFailed(ctx context.Context, orderID int) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_completions SET failed = FALSE, attempts = 0, last_error = NULL
+		  WHERE order_id = $1 AND failed AND processed_at IS NULL`, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue order completion: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// FindCompletion returns the completion event of the order whether it was processed or not, nil if the order was not queued
+func (r *OrderCompletionsRepository) FindCompletion(ctx context.Context, orderID int) (*entities.OrderCompletedEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT $2::text, o.id, o.user_id, o.amount, COALESCE(a.code, ''), o.completed_tx_hash, o.is_test,
+		        COALESCE(o.completed_at, o.updated_at), oc.attempts
+		   FROM order_completions oc
+		   JOIN orders o ON o.id = oc.order_id
+		   LEFT JOIN assets a ON a.id = o.asset_id
+		  WHERE oc.order_id = $1`,
+		orderID, entities.OrderCompletedEventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order completion: %w", err)
+	}
+	defer rows.Close()
+
+	event, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderCompletedEvent])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order completion: %w", err)
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const orderScheduleColumns = `id, user_id, amount, description, cron, status, next_run_at, last_run_at, occurrences,
+	max_occurrences, last_error, created_at, updated_at`
+
+// OrderSchedulesRepository stores recurring order schedules.
+type OrderSchedulesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewOrderSchedulesRepository creates a new order schedules repository.
+func NewOrderSchedulesRepository(logger *slog.Logger, pg *database.Postgres) *OrderSchedulesRepository {
+	return &OrderSchedulesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, so an order is created together with the schedule run
+func (r *OrderSchedulesRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, fn)
+}
+
+// CreateSchedule inserts an active schedule
+func (r *OrderSchedulesRepository) CreateSchedule(ctx context.Context, schedule *entities.OrderSchedule) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO order_schedules (user_id, amount, description, cron, status, next_run_at, max_occurrences)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id, created_at, updated_at`,
+		schedule.UserID, schedule.Amount, schedule.Description, schedule.Cron, schedule.Status, schedule.NextRunAt, schedule.MaxOccurrences,
+	).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order schedule: %w", constraintError(err))
+	}
+
+	return nil
+}
+
+// CountOpenSchedules returns the number of active and paused schedules of the user
+func (r *OrderSchedulesRepository) CountOpenSchedules(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COUNT(*) FROM order_schedules WHERE user_id = $1 AND status IN ('active', 'paused')`,
+		userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count order schedules: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindByUser retrieves schedules of the user, newest first
+func (r *OrderSchedulesRepository) FindByUser(ctx context.Context, userID int64) ([]entities.OrderSchedule, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderScheduleColumns+` FROM order_schedules WHERE user_id = $1 ORDER BY id DESC`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user order schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.OrderSchedule])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user order schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// FindUserSchedule returns the schedule of the user or nil if the user has no such schedule
+func (r *OrderSchedulesRepository) FindUserSchedule(ctx context.Context, id int, userID int64) (*entities.OrderSchedule, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderScheduleColumns+` FROM order_schedules WHERE id = $1 AND user_id = $2`,
+		id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order schedule: %w", err)
+	}
+	defer rows.Close()
+
+	schedule, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderSchedule])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// FindDueScheduleIDs returns active schedules whose next run is due, oldest first
+func (r *OrderSchedulesRepository) FindDueScheduleIDs(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id FROM order_schedules
+		  WHERE status = 'active' AND next_run_at <= $1
+		  ORDER BY next_run_at
+		  LIMIT $2`,
+		now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due order schedules: %w", err)
+	}
+	defer rows.Close()
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect due order schedules: %w", err)
+	}
+
+	return ids, nil
+}
+
+// LockDueSchedule locks the schedule if it is still active and due. Returns nil if it is not,
+// e.g. another instance has already run it or the user paused it meanwhile.
+func (r *OrderSchedulesRepository) LockDueSchedule(ctx context.Context, id int, now time.Time) (*entities.OrderSchedule, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderScheduleColumns+` FROM order_schedules
+		  WHERE id = $1 AND status = 'active' AND next_run_at <= $2
+		  FOR UPDATE SKIP LOCKED`,
+		id, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock order schedule: %w", err)
+	}
+	defer rows.Close()
+
+	schedule, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderSchedule])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// RecordRun counts a created order and moves the schedule to its next run, or to the given final status
+func (r *OrderSchedulesRepository) RecordRun(ctx context.Context, id int, ranAt time.Time, nextRunAt *time.Time, status entities.OrderScheduleStatus) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_schedules
+		    SET occurrences = occurrences + 1, last_run_at = $2, next_run_at = $3, status = $4, last_error = NULL, updated_at = NOW()
+		  WHERE id = $1`,
+		id, ranAt, nextRunAt, status)
+	if err != nil {
+		return fmt.Errorf("failed to record order schedule run: %w", err)
+	}
+
+	return nil
+}
+
+// RecordRunError stores the reason the order could not be created, the run is retried later
+func (r *OrderSchedulesRepository) RecordRunError(ctx context.Context, id int, reason string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_schedules SET last_error = $2, updated_at = NOW() WHERE id = $1`,
+		id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record order schedule error: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus moves a schedule of the user from one of the given statuses to status and sets its next run.
+// Returns nil if the user has no such schedule in one of the given statuses.
+func (r *OrderSchedulesRepository) UpdateStatus(ctx context.Context, id int, userID int64, from []entities.OrderScheduleStatus,
+	status entities.OrderScheduleStatus, nextRunAt *time.Time) (*entities.OrderSchedule, error) {
+	statuses := make([]string, len(from))
+	for i, s := range from {
+		statuses[i] = string(s)
+	}
+
+	rows, err := r.db(ctx).Query(ctx,
+		`UPDATE order_schedules
+		    SET status = $3, next_run_at = $4, updated_at = NOW()
+		  WHERE id = $1 AND user_id = $2 AND status = ANY($5)
+		  RETURNING `+orderScheduleColumns,
+		id, userID, status, nextRunAt, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order schedule status: %w", err)
+	}
+	defer rows.Close()
+
+	schedule, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderSchedule])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const orderTemplateColumns = `t.id, t.user_id, t.name, t.asset_id, a.code, t.amount, t.description, t.callback_url, t.archived_at,
+	t.created_at, t.updated_at`
+
+// OrderTemplatesRepository stores reusable order templates of merchants.
+type OrderTemplatesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewOrderTemplatesRepository creates a new order templates repository.
+func NewOrderTemplatesRepository(logger *slog.Logger, pg *database.Postgres) *OrderTemplatesRepository {
+	return &OrderTemplatesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, so the wallet, the order and its template link are created together
+func (r *OrderTemplatesRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, fn)
+}
+
+// CreateTemplate inserts the template
+func (r *OrderTemplatesRepository) CreateTemplate(ctx context.Context, template *entities.OrderTemplate) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO order_templates (user_id, name, asset_id, amount, description, callback_url)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, created_at, updated_at`,
+		template.UserID, template.Name, template.AssetID, template.Amount, template.Description, template.CallbackURL,
+	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order template: %w", constraintError(err))
+	}
+
+	return nil
+}
+
+// FindByUser retrieves templates of the user that are not archived, newest first
+func (r *OrderTemplatesRepository) FindByUser(ctx context.Context, userID int64) ([]entities.OrderTemplate, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderTemplateColumns+`
+		   FROM order_templates t
+		   JOIN assets a ON a.id = t.asset_id
+		  WHERE t.user_id = $1 AND t.archived_at IS NULL
+		  ORDER BY t.id DESC`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user order templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.OrderTemplate])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user order templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// FindUserTemplate returns the template of the user or nil if the user has no such template
+func (r *OrderTemplatesRepository) FindUserTemplate(ctx context.Context, id int, userID int64) (*entities.OrderTemplate, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderTemplateColumns+`
+		   FROM order_templates t
+		   JOIN assets a ON a.id = t.asset_id
+		  WHERE t.id = $1 AND t.user_id = $2`,
+		id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order template: %w", err)
+	}
+	defer rows.Close()
+
+	template, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderTemplate])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// ArchiveTemplate archives the template of the user. Returns false if the user has no such active template.
+func (r *OrderTemplatesRepository) ArchiveTemplate(ctx context.Context, id int, userID int64) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_templates SET archived_at = NOW(), updated_at = NOW()
+		  WHERE id = $1 AND user_id = $2 AND archived_at IS NULL`,
+		id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to archive order template: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// LinkOrder records the template the order was created from
+func (r *OrderTemplatesRepository) LinkOrder(ctx context.Context, orderID, templateID int) error {
+	_, err := r.db(ctx).Exec(ctx, `UPDATE orders SET template_id = $2 WHERE id = $1`, orderID, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to link order to template: %w", err)
+	}
+
+	return nil
+}
@@ -6,14 +6,33 @@ import (
 	"fmt"
 	"log/slog"
 	"math/big"
+	"slices"
 	"time"
 
 	tx "github.com/Thiht/transactor/pgx"
 	"github.com/jackc/pgx/v5"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
 	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
 )
 
+// legacyOrderDecimals — точность ордеров, созданных до реестра активов (USDT на BSC)
+const legacyOrderDecimals = 18
+
+// Границы суммы ордера, те же, что в ограничении orders_amount_valid
+const (
+	maxOrderAmountDecimals = 36
+	maxOrderAmountDigits   = 42
+)
+
+var maxOrderAmount = decimal.FromUnits(new(big.Int).Exp(big.NewInt(10), big.NewInt(maxOrderAmountDigits), nil), 0)
+
+// pendingOrder — ордер с точностью его актива для сопоставления с суммой перевода
+type pendingOrder struct {
+	entities.Order
+	Decimals int `db:"decimals"`
+}
+
 type OrdersRepository struct {
 	logger *slog.Logger
 
@@ -25,8 +44,13 @@ func NewOrdersRepository(logger *slog.Logger, pg *database.Postgres) *OrdersRepo
 	return &OrdersRepository{logger: logger, db: pg.DBGetter, transactor: pg.Transactor}
 }
 
+// WithinTransaction runs fn in a transaction, nested calls run in savepoints
+func (r *OrdersRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, fn)
+}
+
 func (r *OrdersRepository) FindUserOrders(ctx context.Context, userID int) ([]entities.Order, error) {
-	rows, err := r.db(ctx).Query(ctx, "SELECT id, user_id, wallet_id, amount, status, aml_status, aml_notes, created_at, updated_at FROM orders WHERE user_id = $1", userID)
+	rows, err := r.db(ctx).Query(ctx, "SELECT id, user_id, wallet_id, asset_id, amount, expected_amount, memo, status, aml_status, aml_notes, is_test, created_at, updated_at FROM orders WHERE user_id = $1", userID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -44,57 +68,139 @@ func (r *OrdersRepository) FindUserOrders(ctx context.Context, userID int) ([]en
 	return orders, nil
 }
 
-func (r *OrdersRepository) InsertOrder(ctx context.Context, userID, walletID int, amount string) error {
-	_, err := r.db(ctx).Exec(ctx, "INSERT INTO orders (user_id, wallet_id, amount, status) VALUES ($1, $2, $3, 'pending')", userID, walletID, amount)
-	return err
+// InsertOrder создает ордер и возвращает его ID. Пустое memo — ордер без мемо, занятое мемо кошелька возвращает ErrUniqueViolation.
+func (r *OrdersRepository) InsertOrder(ctx context.Context, userID, walletID, assetID int, amount decimal.Decimal, memo string) (int, error) {
+	if err := validateOrderAmount(amount); err != nil {
+		return 0, err
+	}
+
+	var id int
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO orders (user_id, wallet_id, asset_id, amount, memo, status, is_test)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), 'pending', COALESCE((SELECT is_testnet FROM wallets WHERE id = $2), FALSE))
+		 RETURNING id`,
+		userID, walletID, assetID, amount, memo).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert order: %w", constraintError(err))
+	}
+	return id, nil
+}
+
+// InsertOrderWithExpectedAmount создает ордер с уникальной суммой к оплате и возвращает его ID.
+// Возвращает 0, если такая сумма уже занята другим ожидающим ордером этого кошелька.
+func (r *OrdersRepository) InsertOrderWithExpectedAmount(ctx context.Context, userID, walletID, assetID int, amount, expectedAmount decimal.Decimal, memo string) (int, error) {
+	if err := validateOrderAmount(amount); err != nil {
+		return 0, err
+	}
+	if err := validateOrderAmount(expectedAmount); err != nil {
+		return 0, err
+	}
+	if expectedAmount.Cmp(amount) < 0 {
+		return 0, fmt.Errorf("%w: expected amount %s is below order amount %s", ErrCheckViolation, expectedAmount, amount)
+	}
+
+	var id int
+	err := r.db(ctx).QueryRow(ctx, `
+		INSERT INTO orders (user_id, wallet_id, asset_id, amount, expected_amount, memo, status, is_test)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), 'pending', COALESCE((SELECT is_testnet FROM wallets WHERE id = $2), FALSE))
+		ON CONFLICT (wallet_id, expected_amount) WHERE status = 'pending' AND expected_amount IS NOT NULL
+		DO NOTHING
+		RETURNING id`,
+		userID, walletID, assetID, amount, expectedAmount, memo).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert order with expected amount: %w", constraintError(err))
+	}
+
+	return id, nil
 }
 
-func (r *OrdersRepository) UpdateOrderStatus(ctx context.Context, walletID int, amount *big.Int) error {
+// UpdateOrderStatus completes pending orders of the wallet covered by the transfer amount.
+// A transfer with a memo completes only the orders with the same memo, or orders without a memo if none has it.
+// On an omnibus wallet orders of many merchants wait for payment, so a transfer completes only the order with its memo
+// or its exact unique amount, never orders without attribution.
+// Returns the overpaid amount left after completing at least one order.
+func (r *OrdersRepository) UpdateOrderStatus(ctx context.Context, walletID int, amount *big.Int, memo, txHash string) (*big.Int, error) {
+	var omnibus bool
+	err := r.db(ctx).QueryRow(ctx, "SELECT omnibus FROM wallets WHERE id = $1", walletID).Scan(&omnibus)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to query wallet %d: %w", walletID, err)
+	}
+
 	// Get all pending orders for this wallet
-	rows, err := r.db(ctx).Query(ctx, "SELECT * FROM orders WHERE wallet_id = $1 AND status = 'pending' ORDER BY id", walletID)
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT o.id, o.user_id, o.wallet_id, o.asset_id, o.amount, o.expected_amount, o.memo, o.status, o.aml_status, o.aml_notes, o.is_test,
+		       o.created_at, o.updated_at, COALESCE(a.decimals, $2) AS decimals
+		FROM orders o
+		LEFT JOIN assets a ON a.id = o.asset_id
+		WHERE o.wallet_id = $1 AND o.status = 'pending'
+		ORDER BY o.id`, walletID, legacyOrderDecimals)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil
+		return nil, nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to query pending orders by wallet id: %w", err)
+		return nil, fmt.Errorf("failed to query pending orders by wallet id: %w", err)
 	}
 	defer rows.Close()
 
-	orders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Order])
+	orders, err := pgx.CollectRows(rows, pgx.RowToStructByName[pendingOrder])
 	if err != nil {
 		r.logger.Error("failed to collect orders rows", "error", err)
-		return err
+		return nil, err
+	}
+	orders = ordersForMemo(orders, memo)
+	if omnibus {
+		orders = attributedOrders(orders)
+	}
+
+	// Ордера с уникальной суммой сопоставляются только по точному совпадению суммы перевода
+	for _, order := range orders {
+		if order.ExpectedAmount == nil {
+			continue
+		}
+
+		expectedWei, err := order.ExpectedAmount.Units(order.Decimals)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected amount in database for order %d: %w", order.ID, err)
+		}
+
+		if expectedWei.Cmp(amount) == 0 {
+			if err = r.completeOrder(ctx, order.ID, txHash); err != nil {
+				return nil, err
+			}
+
+			r.logger.Info("Order completed by exact amount", "order_id", order.ID, "wallet_id", walletID, "expected_amount", order.ExpectedAmount.String())
+			return nil, nil
+		}
 	}
 
 	var ordersUpdated bool
 	remainingAmount := new(big.Int).Set(amount)
 
 	for _, order := range orders {
-		// Convert order amount to big.Float for decimal handling
-		orderAmountFloat, _, err := new(big.Float).Parse(order.Amount, 10)
-		if err != nil {
-			return fmt.Errorf("invalid amount format in database for order %d: %w", order.ID, err)
+		// Ордер с уникальной суммой не закрываем частью другого перевода
+		if order.ExpectedAmount != nil {
+			continue
 		}
 
-		// Convert to wei (multiply by 10^18)
-		weiMultiplier := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
-		orderAmountInWei := new(big.Float).Mul(orderAmountFloat, weiMultiplier)
-
-		// Convert back to big.Int for comparison
-		orderAmount := new(big.Int)
-		orderAmountInWei.Int(orderAmount)
+		// Сумма ордера в минимальных единицах актива
+		orderAmount, err := order.Amount.Units(order.Decimals)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount in database for order %d: %w", order.ID, err)
+		}
 
-		r.logger.Info("Comparing amounts", "order_id", order.ID, "order_amount", order.Amount,
+		r.logger.Info("Comparing amounts", "order_id", order.ID, "order_amount", order.Amount.String(),
 			"order_amount_wei", orderAmount.String(), "transaction_amount", remainingAmount.String())
 
 		// If we have enough to cover this order
 		if remainingAmount.Cmp(orderAmount) >= 0 {
-			_, err = r.db(ctx).Exec(ctx, "UPDATE orders SET status = 'completed', updated_at = NOW() WHERE id = $1", order.ID)
-			if err != nil {
-				return fmt.Errorf("failed to update order %d: %w", order.ID, err)
+			if err = r.completeOrder(ctx, order.ID, txHash); err != nil {
+				return nil, err
 			}
 
-			r.logger.Info("Order completed", "order_id", order.ID, "wallet_id", walletID, "amount", order.Amount)
+			r.logger.Info("Order completed", "order_id", order.ID, "wallet_id", walletID, "amount", order.Amount.String())
 
 			// Subtract the order amount from remaining
 			remainingAmount.Sub(remainingAmount, orderAmount)
@@ -103,35 +209,89 @@ func (r *OrdersRepository) UpdateOrderStatus(ctx context.Context, walletID int,
 	}
 
 	if !ordersUpdated {
-		r.logger.Warn("No orders updated", "wallet_id", walletID, "amount", amount.String())
+		r.logger.Warn("No orders updated", "wallet_id", walletID, "amount", amount.String(), "memo", memo, "omnibus", omnibus)
 		// Don't return an error, as this might be a legitimate case (e.g., partial payment)
 		// Just log a warning instead
+		return nil, nil
 	}
 
-	return nil
+	return remainingAmount, nil
 }
 
+// completeOrder отмечает ордер оплаченным транзакцией txHash и ставит в очередь квитанцию об оплате и конвейер после оплаты
+func (r *OrdersRepository) completeOrder(ctx context.Context, orderID int, txHash string) error {
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := r.db(ctx).Exec(ctx,
+			`UPDATE orders SET status = 'completed', completed_tx_hash = $2, completed_at = NOW(), updated_at = NOW() WHERE id = $1`,
+			orderID, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to update order %d: %w", orderID, err)
+		}
+
+		_, err = r.db(ctx).Exec(ctx, `INSERT INTO order_receipts (order_id) VALUES ($1) ON CONFLICT (order_id) DO NOTHING`, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to queue receipt of order %d: %w", orderID, err)
+		}
+
+		_, err = r.db(ctx).Exec(ctx, `INSERT INTO order_completions (order_id) VALUES ($1) ON CONFLICT (order_id) DO NOTHING`, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to queue completion of order %d: %w", orderID, err)
+		}
+		return nil
+	})
+}
+
+// ordersForMemo оставляет ожидающие ордера, которые может закрыть перевод с этим мемо: ордера с тем же мемо,
+// а если таких нет — ордера без мемо. Ордер с мемо не закрывается переводом без мемо или с чужим мемо.
+func ordersForMemo(orders []pendingOrder, memo string) []pendingOrder {
+	var matched, withoutMemo []pendingOrder
+	for _, order := range orders {
+		switch {
+		case order.Memo == nil:
+			withoutMemo = append(withoutMemo, order)
+		case memo != "" && *order.Memo == memo:
+			matched = append(matched, order)
+		}
+	}
+
+	if len(matched) > 0 {
+		return matched
+	}
+	return withoutMemo
+}
+
+// attributedOrders оставляет ордера, которые перевод на омнибус кошелек может закрыть: с мемо или уникальной суммой.
+// Ордер без атрибуции на омнибус кошельке не закрывается, чтобы чужой перевод не зачелся в него по сумме
+func attributedOrders(orders []pendingOrder) []pendingOrder {
+	return slices.DeleteFunc(orders, func(order pendingOrder) bool {
+		return order.Memo == nil && order.ExpectedAmount == nil
+	})
+}
+
+// RemoveOldOrders expires pending orders created (or reopened) more than olderThan ago. Expired orders are kept,
+// so a deposit arriving after the expiration is recognized as late.
 func (r *OrdersRepository) RemoveOldOrders(ctx context.Context, olderThan time.Duration) (int64, error) {
 	// Calculate the cutoff time (current time - duration)
 	cutoffTime := time.Now().Add(-olderThan)
 
-	// Delete orders that are older than the cutoff time and still have 'pending' status
+	// Expire orders that are older than the cutoff time and still have 'pending' status
 	result, err := r.db(ctx).Exec(ctx,
-		"DELETE FROM orders WHERE status = 'pending' AND created_at < $1",
+		`UPDATE orders SET status = 'expired', expired_at = NOW(), updated_at = NOW()
+		  WHERE status = 'pending' AND COALESCE(reopened_at, created_at) < $1`,
 		cutoffTime)
 
 	if err != nil {
-		return 0, fmt.Errorf("failed to remove old orders: %w", err)
+		return 0, fmt.Errorf("failed to expire old orders: %w", err)
 	}
 
-	// Get the number of deleted rows
-	deletedCount := result.RowsAffected()
+	// Get the number of expired rows
+	expiredCount := result.RowsAffected()
 
-	if deletedCount > 0 {
-		r.logger.Info("Removed old pending orders", "count", deletedCount, "older_than", olderThan.String())
+	if expiredCount > 0 {
+		r.logger.Info("Expired old pending orders", "count", expiredCount, "older_than", olderThan.String())
 	}
 
-	return deletedCount, nil
+	return expiredCount, nil
 }
 
 // UpdateOrderAMLStatus обновляет AML статус ордера
@@ -149,6 +309,44 @@ func (r *OrdersRepository) UpdateOrderAMLStatus(ctx context.Context, orderID int
 	return nil
 }
 
+// FindOrderByID returns the order with the given ID or nil if it does not exist
+func (r *OrdersRepository) FindOrderByID(ctx context.Context, orderID int) (*entities.Order, error) {
+	rows, err := r.db(ctx).Query(ctx, "SELECT id, user_id, wallet_id, asset_id, amount, expected_amount, memo, status, aml_status, aml_notes, is_test, created_at, updated_at FROM orders WHERE id = $1", orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order by id: %w", err)
+	}
+	defer rows.Close()
+
+	order, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Order])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order row: %w", err)
+	}
+
+	return &order, nil
+}
+
+// FindOrderByTxHash returns the order paid by the transaction or nil if the transaction did not complete an order
+func (r *OrdersRepository) FindOrderByTxHash(ctx context.Context, txHash string) (*entities.Order, error) {
+	rows, err := r.db(ctx).Query(ctx, "SELECT id, user_id, wallet_id, asset_id, amount, expected_amount, memo, status, aml_status, aml_notes, is_test, created_at, updated_at FROM orders WHERE completed_tx_hash = $1", txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order by tx hash: %w", err)
+	}
+	defer rows.Close()
+
+	order, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Order])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order row: %w", err)
+	}
+
+	return &order, nil
+}
+
 // FindOrderByWalletAddress находит ID ордера по адресу кошелька
 func (r *OrdersRepository) FindOrderByWalletAddress(ctx context.Context, walletAddress string) (int, error) {
 	var orderID int
@@ -197,3 +395,54 @@ func (r *OrdersRepository) DeleteOrder(ctx context.Context, orderID int) error {
 	r.logger.Info("Pending order deleted successfully", "order_id", orderID)
 	return nil
 }
+
+// CancelOrder cancels a pending order of the user with the reason code. An order with a deposit still being
+// confirmed or held is not cancelled. Returns nil if no order was cancelled, the caller finds out why.
+func (r *OrdersRepository) CancelOrder(ctx context.Context, orderID int, userID int64, reason entities.OrderCancelReason) (*entities.OrderCancellation, error) {
+	var cancellation entities.OrderCancellation
+	err := r.db(ctx).QueryRow(ctx,
+		`UPDATE orders o
+		    SET status = 'cancelled', cancel_reason = $3, cancelled_at = NOW(), updated_at = NOW()
+		  WHERE o.id = $1 AND o.user_id = $2 AND o.status = 'pending'
+		    AND NOT EXISTS (SELECT 1 FROM wallets w JOIN transactions t ON t.wallet_address = w.address
+		                     WHERE w.id = o.wallet_id AND NOT t.processed)
+		  RETURNING o.id, o.user_id, o.wallet_id, o.cancel_reason, o.cancelled_at`,
+		orderID, userID, reason).Scan(
+		&cancellation.OrderID, &cancellation.UserID, &cancellation.WalletID, &cancellation.Reason, &cancellation.CancelledAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order %d: %w", orderID, err)
+	}
+
+	r.logger.InfoContext(ctx, "Order cancelled", "order_id", orderID, "user_id", userID, "reason", reason)
+	return &cancellation, nil
+}
+
+// CountPendingOrders returns the number of pending orders of the user
+func (r *OrdersRepository) CountPendingOrders(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		"SELECT COUNT(*) FROM orders WHERE user_id = $1 AND status = 'pending'",
+		userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending orders for user %d: %w", userID, err)
+	}
+
+	return count, nil
+}
+
+// validateOrderAmount отклоняет сумму, которую не пропустит ограничение orders_amount_valid, до запроса в базу
+func validateOrderAmount(amount decimal.Decimal) error {
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("%w: order amount %s must be positive", ErrCheckViolation, amount)
+	}
+	if _, err := amount.Units(maxOrderAmountDecimals); err != nil {
+		return fmt.Errorf("%w: invalid order amount: %w", ErrCheckViolation, err)
+	}
+	if amount.Cmp(maxOrderAmount) >= 0 {
+		return fmt.Errorf("%w: order amount %s must have at most %d integer digits", ErrCheckViolation, amount, maxOrderAmountDigits)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+)
+
+// PricesRepository stores the history of asset rates recorded by the price cache
+type PricesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewPricesRepository creates a new prices repository.
+func NewPricesRepository(logger *slog.Logger, pg *database.Postgres) *PricesRepository {
+	return &PricesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// InsertSnapshots records the rates in one transaction
+func (r *PricesRepository) InsertSnapshots(ctx context.Context, snapshots []entities.PriceSnapshot) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		for _, snapshot := range snapshots {
+			_, err := r.db(txCtx).Exec(txCtx,
+				`INSERT INTO price_snapshots (chain, asset, fiat, rate, recorded_at) VALUES ($1, $2, $3, $4, $5)`,
+				snapshot.Chain, snapshot.Asset, snapshot.Fiat, snapshot.Rate, snapshot.RecordedAt)
+			if err != nil {
+				return fmt.Errorf("failed to insert price snapshot %s/%s on %s: %w", snapshot.Asset, snapshot.Fiat, snapshot.Chain, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindRatesAt returns the latest recorded rate at each of the moments, aligned with them.
+// A moment without a snapshot in the preceding maxAge gets nil.
+func (r *PricesRepository) FindRatesAt(ctx context.Context, chain entities.Chain, asset, fiat string, moments []time.Time, maxAge time.Duration) ([]*decimal.Decimal, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT s.rate
+		   FROM unnest($4::timestamptz[]) WITH ORDINALITY AS m(at, n)
+		   LEFT JOIN LATERAL (
+		        SELECT rate
+		          FROM price_snapshots
+		         WHERE chain = $1 AND asset = $2 AND fiat = $3
+		           AND recorded_at <= m.at AND recorded_at > m.at - make_interval(secs => $5)
+		         ORDER BY recorded_at DESC
+		         LIMIT 1
+		   ) s ON TRUE
+		  ORDER BY m.n`,
+		chain, asset, fiat, moments, maxAge.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rates of %s/%s on %s: %w", asset, fiat, chain, err)
+	}
+	defer rows.Close()
+
+	rates := make([]*decimal.Decimal, 0, len(moments))
+	for rows.Next() {
+		var rate *decimal.Decimal
+		if err = rows.Scan(&rate); err != nil {
+			return nil, fmt.Errorf("failed to scan rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rates: %w", err)
+	}
+
+	return rates, nil
+}
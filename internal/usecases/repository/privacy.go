@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// auditPersonalFields — ключи details журнала аудита с персональными данными (события сессий)
+var auditPersonalFields = []string{"ip_address", "user_agent", "country", "city", "device_id"}
+
+// PrivacyRepository exports and erases personal data of users.
+type PrivacyRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewPrivacyRepository creates a new privacy repository.
+func NewPrivacyRepository(logger *slog.Logger, pg *database.Postgres) *PrivacyRepository {
+	return &PrivacyRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindUserAuditEvents retrieves audit entries performed by the user or about the user, newest first
+func (r *PrivacyRepository) FindUserAuditEvents(ctx context.Context, userID int64, limit int) ([]entities.AuditEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, event_type, actor, subject, details, created_at
+		   FROM audit_log
+		  WHERE actor = $1 OR subject = $1
+		  ORDER BY id DESC
+		  LIMIT $2`,
+		strconv.FormatInt(userID, 10), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.AuditEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user audit event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// CountOpenRefunds returns the number of refunds of the user that are not completed or failed yet
+func (r *PrivacyRepository) CountOpenRefunds(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		"SELECT COUNT(*) FROM refunds WHERE user_id = $1 AND status IN ('pending', 'processing', 'sent', 'unknown')",
+		userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count open refunds: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindErasure retrieves the latest erasure of the user. Returns nil if the user data was not erased.
+func (r *PrivacyRepository) FindErasure(ctx context.Context, userID int64) (*entities.UserErasure, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT user_id, requested_by, reason, sessions_anonymized, audit_events_redacted, two_factor_removed, erased_at
+		   FROM user_erasures
+		  WHERE user_id = $1`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user erasure: %w", err)
+	}
+	defer rows.Close()
+
+	erasure, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.UserErasure])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect user erasure row: %w", err)
+	}
+
+	return &erasure, nil
+}
+
+// EraseUser anonymizes sessions, removes the two-factor secret and redacts personal fields of the user audit events
+// in a single transaction, then stores the erasure with the resulting counts. Repeated erasure replaces the record.
+// Orders, wallets, transactions and refunds are kept as financial records.
+func (r *PrivacyRepository) EraseUser(ctx context.Context, erasure *entities.UserErasure) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		sessions, err := r.db(txCtx).Exec(txCtx,
+			`UPDATE user_sessions
+			    SET ip_address = '', user_agent = '', country = '', city = '', device_id = '',
+			        revoked_at = COALESCE(revoked_at, NOW())
+			  WHERE user_id = $1`,
+			erasure.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to anonymize sessions: %w", err)
+		}
+		erasure.SessionsAnonymized = int(sessions.RowsAffected())
+
+		twoFactor, err := r.db(txCtx).Exec(txCtx, "DELETE FROM user_two_factor WHERE user_id = $1", erasure.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to remove two-factor secret: %w", err)
+		}
+		erasure.TwoFactorRemoved = twoFactor.RowsAffected() > 0
+
+		audit, err := r.db(txCtx).Exec(txCtx,
+			`UPDATE audit_log
+			    SET details = details - $2::TEXT[]
+			  WHERE actor = $1 AND details ?| $2::TEXT[]`,
+			strconv.FormatInt(erasure.UserID, 10), auditPersonalFields)
+		if err != nil {
+			return fmt.Errorf("failed to redact audit events: %w", err)
+		}
+		erasure.AuditEventsRedacted = int(audit.RowsAffected())
+
+		err = r.db(txCtx).QueryRow(txCtx,
+			`INSERT INTO user_erasures (user_id, requested_by, reason, sessions_anonymized, audit_events_redacted, two_factor_removed)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (user_id) DO UPDATE
+			    SET requested_by = EXCLUDED.requested_by, reason = EXCLUDED.reason,
+			        sessions_anonymized = EXCLUDED.sessions_anonymized, audit_events_redacted = EXCLUDED.audit_events_redacted,
+			        two_factor_removed = EXCLUDED.two_factor_removed, erased_at = NOW()
+			 RETURNING erased_at`,
+			erasure.UserID, erasure.RequestedBy, erasure.Reason, erasure.SessionsAnonymized,
+			erasure.AuditEventsRedacted, erasure.TwoFactorRemoved,
+		).Scan(&erasure.ErasedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record user erasure: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// ReceiptsRepository stores the queue of order receipts and receipt branding of merchants.
+type ReceiptsRepository struct {
+	logger *slog.Logger
+	db     tx.DBGetter
+}
+
+// NewReceiptsRepository creates a new receipts repository.
+func NewReceiptsRepository(logger *slog.Logger, pg *database.Postgres) *ReceiptsRepository {
+	return &ReceiptsRepository{
+		logger: logger,
+		db:     pg.DBGetter,
+	}
+}
+
+// FindPendingReceipts returns details of paid orders whose receipts are waiting to be sent, oldest first
+func (r *ReceiptsRepository) FindPendingReceipts(ctx context.Context, limit int) ([]entities.ReceiptDetails, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT o.id, o.user_id, rc.attempts, o.amount, o.expected_amount,
+		        COALESCE(a.code, ''), COALESCE(a.chain, ''), COALESCE(a.network, ''),
+		        o.completed_tx_hash, o.created_at, o.completed_at
+		   FROM order_receipts rc
+		   JOIN orders o ON o.id = rc.order_id
+		   LEFT JOIN assets a ON a.id = o.asset_id
+		  WHERE rc.status = 'pending'
+		  ORDER BY rc.created_at
+		  LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.ReceiptDetails])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect pending receipts: %w", err)
+	}
+
+	return receipts, nil
+}
+
+// MarkSent records a delivered receipt
+func (r *ReceiptsRepository) MarkSent(ctx context.Context, orderID int) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_receipts
+		    SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = NOW(), updated_at = NOW()
+		  WHERE order_id = $1`,
+		orderID)
+	if err != nil {
+		return fmt.Errorf("failed to mark receipt sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAttemptFailed records a failed delivery attempt. The receipt stays pending unless giveUp is set.
+func (r *ReceiptsRepository) MarkAttemptFailed(ctx context.Context, orderID int, reason string, giveUp bool) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_receipts
+		    SET attempts = attempts + 1, last_error = $2,
+		        status = CASE WHEN $3::boolean THEN 'failed' ELSE status END, updated_at = NOW()
+		  WHERE order_id = $1`,
+		orderID, reason, giveUp)
+	if err != nil {
+		return fmt.Errorf("failed to record receipt error: %w", err)
+	}
+
+	return nil
+}
+
+// FindUserReceipt returns the receipt of the user's order or nil if there is none
+func (r *ReceiptsRepository) FindUserReceipt(ctx context.Context, orderID int, userID int64) (*entities.OrderReceipt, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT rc.order_id, rc.status, rc.attempts, rc.last_error, rc.sent_at, rc.created_at, rc.updated_at
+		   FROM order_receipts rc
+		   JOIN orders o ON o.id = rc.order_id
+		  WHERE rc.order_id = $1 AND o.user_id = $2`,
+		orderID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipt: %w", err)
+	}
+	defer rows.Close()
+
+	receipt, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderReceipt])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect receipt: %w", err)
+	}
+
+	return &receipt, nil
+}
+
+// RequeueReceipt queues the receipt of the user's completed order for sending again, also for orders
+// completed before receipts were introduced. Returns nil if the user has no such completed order.
+func (r *ReceiptsRepository) RequeueReceipt(ctx context.Context, orderID int, userID int64) (*entities.OrderReceipt, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`INSERT INTO order_receipts (order_id)
+		 SELECT id FROM orders WHERE id = $1 AND user_id = $2 AND status = 'completed'
+		 ON CONFLICT (order_id) DO UPDATE
+		    SET status = 'pending', attempts = 0, last_error = NULL, updated_at = NOW()
+		 RETURNING order_id, status, attempts, last_error, sent_at, created_at, updated_at`,
+		orderID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue receipt: %w", err)
+	}
+	defer rows.Close()
+
+	receipt, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.OrderReceipt])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect receipt: %w", err)
+	}
+
+	return &receipt, nil
+}
+
+// RequeueFailedReceipt returns a receipt given up after the last attempt to the queue with a fresh attempt budget.
+// Returns false if the order has no failed receipt.
+func (r *ReceiptsRepository) RequeueFailedReceipt(ctx context.Context, orderID int) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE order_receipts
+		    SET status = 'pending', attempts = 0, last_error = NULL, updated_at = NOW()
+		  WHERE order_id = $1 AND status = 'failed'`, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue failed receipt: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// FindBranding returns the receipt branding of the merchant or nil if it is not configured
+func (r *ReceiptsRepository) FindBranding(ctx context.Context, merchantID int64) (*entities.ReceiptBranding, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT merchant_id, brand_name, subject_template, body_template, updated_at
+		   FROM receipt_brandings
+		  WHERE merchant_id = $1`,
+		merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query receipt branding: %w", err)
+	}
+	defer rows.Close()
+
+	branding, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.ReceiptBranding])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect receipt branding: %w", err)
+	}
+
+	return &branding, nil
+}
+
+// SaveBranding creates or replaces the receipt branding of the merchant
+func (r *ReceiptsRepository) SaveBranding(ctx context.Context, branding *entities.ReceiptBranding) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO receipt_brandings (merchant_id, brand_name, subject_template, body_template)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (merchant_id) DO UPDATE
+		    SET brand_name = EXCLUDED.brand_name, subject_template = EXCLUDED.subject_template,
+		        body_template = EXCLUDED.body_template, updated_at = NOW()
+		 RETURNING updated_at`,
+		branding.MerchantID, branding.BrandName, branding.SubjectTemplate, branding.BodyTemplate,
+	).Scan(&branding.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save receipt branding: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const reconciliationReportColumns = `day, payload, signature, attempts, last_error, delivered_at, generated_at`
+
+// ReconciliationRepository stores daily reconciliation reports and their delivery state
+type ReconciliationRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewReconciliationRepository creates a new reconciliation repository.
+func NewReconciliationRepository(logger *slog.Logger, pg *database.Postgres) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// SaveReport stores the report of the day. A regenerated report replaces the previous one and is delivered again.
+func (r *ReconciliationRepository) SaveReport(ctx context.Context, report *entities.ReconciliationReport) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO reconciliation_reports (day, payload, signature)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (day) DO UPDATE
+		    SET payload = EXCLUDED.payload,
+		        signature = EXCLUDED.signature,
+		        last_error = NULL,
+		        delivered_at = NULL,
+		        generated_at = NOW()
+		 RETURNING attempts, generated_at`,
+		report.Day, report.Payload, report.Signature,
+	).Scan(&report.Attempts, &report.GeneratedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save reconciliation report: %w", err)
+	}
+
+	return nil
+}
+
+// FindReport returns the report of the day or nil
+func (r *ReconciliationRepository) FindReport(ctx context.Context, day time.Time) (*entities.ReconciliationReport, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+reconciliationReportColumns+` FROM reconciliation_reports WHERE day = $1`, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliation report: %w", err)
+	}
+
+	report, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.ReconciliationReport])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect reconciliation report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// FindReports retrieves the latest reports, newest first
+func (r *ReconciliationRepository) FindReports(ctx context.Context, limit int) ([]entities.ReconciliationReport, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+reconciliationReportColumns+` FROM reconciliation_reports ORDER BY day DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliation reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.ReconciliationReport])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect reconciliation report rows: %w", err)
+	}
+
+	return reports, nil
+}
+
+// FindUndeliveredReports retrieves reports of days since the given day that were not delivered, oldest first
+func (r *ReconciliationRepository) FindUndeliveredReports(ctx context.Context, since time.Time) ([]entities.ReconciliationReport, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+reconciliationReportColumns+` FROM reconciliation_reports
+		  WHERE delivered_at IS NULL AND day >= $1
+		  ORDER BY day`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query undelivered reconciliation reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.ReconciliationReport])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect reconciliation report rows: %w", err)
+	}
+
+	return reports, nil
+}
+
+// RecordDelivery stores the outcome of a delivery attempt: delivered without an error, failed with it
+func (r *ReconciliationRepository) RecordDelivery(ctx context.Context, day time.Time, deliveryErr *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE reconciliation_reports
+		    SET attempts = attempts + 1,
+		        last_error = $2,
+		        delivered_at = CASE WHEN $2::TEXT IS NULL THEN NOW() END
+		  WHERE day = $1`,
+		day, deliveryErr)
+	if err != nil {
+		return fmt.Errorf("failed to record reconciliation delivery: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const refundColumns = `id, user_id, deposit_tx_hash, wallet_address, to_address, amount, reason, status,
+                       refund_tx_hash, error, initiated_by, created_at, updated_at, nonce`
+
+// RefundsRepository stores refunds of deposits.
+type RefundsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewRefundsRepository creates a new refunds repository.
+func NewRefundsRepository(logger *slog.Logger, pg *database.Postgres) *RefundsRepository {
+	return &RefundsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// WithinDepositLock runs fn in a transaction holding the refunds lock of the deposit,
+// so concurrent refunds of one deposit cannot together exceed its amount
+func (r *RefundsRepository) WithinDepositLock(ctx context.Context, depositTxHash string, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := r.db(txCtx).Exec(txCtx, "SELECT pg_advisory_xact_lock(hashtext('refunds:' || $1))", depositTxHash); err != nil {
+			return fmt.Errorf("failed to acquire deposit refunds lock: %w", err)
+		}
+		return fn(txCtx)
+	})
+}
+
+// SumActive sums the refunds of the deposit that are not failed, except the refund with excludeID
+func (r *RefundsRepository) SumActive(ctx context.Context, depositTxHash, excludeID string) (string, error) {
+	var total string
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount::NUMERIC), 0)::TEXT
+		   FROM refunds
+		  WHERE deposit_tx_hash = $1 AND status <> 'failed' AND id::TEXT <> $2`,
+		depositTxHash, excludeID).Scan(&total)
+	if err != nil {
+		return "", fmt.Errorf("failed to sum refunds of the deposit: %w", err)
+	}
+
+	return total, nil
+}
+
+// CreateRefund inserts a refund. Returns false if a refund for the deposit with the same reason already exists.
+func (r *RefundsRepository) CreateRefund(ctx context.Context, refund *entities.Refund) (bool, error) {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO refunds (id, user_id, deposit_tx_hash, wallet_address, to_address, amount, reason, status, initiated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (deposit_tx_hash, reason) DO NOTHING
+		 RETURNING created_at, updated_at`,
+		refund.ID, refund.UserID, refund.DepositTxHash, refund.WalletAddress, refund.ToAddress,
+		refund.Amount, refund.Reason, refund.Status, refund.InitiatedBy,
+	).Scan(&refund.CreatedAt, &refund.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	return true, nil
+}
+
+// FindByID retrieves a refund by its ID
+func (r *RefundsRepository) FindByID(ctx context.Context, id string) (*entities.Refund, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+refundColumns+` FROM refunds WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refund: %w", err)
+	}
+	defer rows.Close()
+
+	refund, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.Refund])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect refund row: %w", err)
+	}
+
+	return &refund, nil
+}
+
+// FindByUserID retrieves refunds of a user, newest first
+func (r *RefundsRepository) FindByUserID(ctx context.Context, userID int64) ([]entities.Refund, error) {
+	return r.query(ctx, `SELECT `+refundColumns+` FROM refunds WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+// FindByStatus retrieves refunds with the given status, oldest first. Empty status returns all refunds.
+func (r *RefundsRepository) FindByStatus(ctx context.Context, status entities.RefundStatus, limit int) ([]entities.Refund, error) {
+	return r.query(ctx,
+		`SELECT `+refundColumns+` FROM refunds WHERE ($1 = '' OR status = $1) ORDER BY created_at LIMIT $2`,
+		status, limit)
+}
+
+func (r *RefundsRepository) query(ctx context.Context, query string, args ...any) ([]entities.Refund, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refunds: %w", err)
+	}
+	defer rows.Close()
+
+	refunds, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Refund])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect refund rows: %w", err)
+	}
+
+	return refunds, nil
+}
+
+// TransitionStatus moves the refund from one of the given statuses to the new one.
+// Returns false if the refund is not in any of the expected statuses.
+func (r *RefundsRepository) TransitionStatus(ctx context.Context, id string, to entities.RefundStatus, from ...entities.RefundStatus) (bool, error) {
+	fromStatuses := make([]string, len(from))
+	for i, status := range from {
+		fromStatuses[i] = string(status)
+	}
+
+	result, err := r.db(ctx).Exec(ctx,
+		"UPDATE refunds SET status = $2, updated_at = NOW() WHERE id = $1 AND status = ANY($3)",
+		id, to, fromStatuses)
+	if err != nil {
+		return false, fmt.Errorf("failed to update refund status: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// MarkSent stores the hash and the nonce of the broadcast refund transaction
+func (r *RefundsRepository) MarkSent(ctx context.Context, id, refundTxHash string, nonce *int64) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE refunds SET status = $2, refund_tx_hash = $3, nonce = COALESCE($4, nonce), error = NULL, updated_at = NOW()
+		 WHERE id = $1`,
+		id, entities.RefundStatusSent, refundTxHash, nonce)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund sent: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceTxHash points the sent refund to the speed-up transaction that replaced its transaction
+func (r *RefundsRepository) ReplaceTxHash(ctx context.Context, oldTxHash, newTxHash string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE refunds SET refund_tx_hash = $2, updated_at = NOW() WHERE refund_tx_hash = $1 AND status = $3",
+		oldTxHash, newTxHash, entities.RefundStatusSent)
+	if err != nil {
+		return fmt.Errorf("failed to replace refund transaction hash: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUnknown stores the refund transaction whose broadcast was not confirmed by the node
+func (r *RefundsRepository) MarkUnknown(ctx context.Context, id, refundTxHash string, nonce *int64, reason string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE refunds SET status = $2, refund_tx_hash = $3, nonce = COALESCE($4, nonce), error = $5, updated_at = NOW()
+		 WHERE id = $1`,
+		id, entities.RefundStatusUnknown, refundTxHash, nonce, reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund unknown: %w", err)
+	}
+
+	return nil
+}
+
+// MarkCompleted marks the refund confirmed by the receipt of its transaction
+func (r *RefundsRepository) MarkCompleted(ctx context.Context, id, refundTxHash string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE refunds SET status = $2, refund_tx_hash = $3, error = NULL, updated_at = NOW() WHERE id = $1",
+		id, entities.RefundStatusCompleted, refundTxHash)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund completed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed stores the error of the refund execution
+func (r *RefundsRepository) MarkFailed(ctx context.Context, id, reason string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE refunds SET status = $2, error = $3, updated_at = NOW() WHERE id = $1",
+		id, entities.RefundStatusFailed, reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const safeProposalColumns = `id, safe_address, safe_tx_hash, nonce, kind, from_wallet_id, to_address, amount, status,
+                             confirmations, confirmations_required, executed_tx_hash, initiated_by, created_at, updated_at`
+
+// SafeProposalsRepository stores multisig treasury proposals.
+type SafeProposalsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewSafeProposalsRepository creates a new safe proposals repository.
+func NewSafeProposalsRepository(logger *slog.Logger, pg *database.Postgres) *SafeProposalsRepository {
+	return &SafeProposalsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateProposal inserts a new proposal
+func (r *SafeProposalsRepository) CreateProposal(ctx context.Context, proposal *entities.SafeProposal) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO safe_proposals (id, safe_address, safe_tx_hash, nonce, kind, from_wallet_id, to_address, amount,
+		                             status, confirmations, confirmations_required, initiated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 RETURNING created_at, updated_at`,
+		proposal.ID, proposal.SafeAddress, proposal.SafeTxHash, proposal.Nonce, proposal.Kind, proposal.FromWalletID,
+		proposal.ToAddress, proposal.Amount, proposal.Status, proposal.Confirmations, proposal.ConfirmationsRequired,
+		proposal.InitiatedBy,
+	).Scan(&proposal.CreatedAt, &proposal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create safe proposal: %w", err)
+	}
+
+	return nil
+}
+
+// FindBySafeTxHash retrieves a proposal by its safeTxHash
+func (r *SafeProposalsRepository) FindBySafeTxHash(ctx context.Context, safeTxHash string) (*entities.SafeProposal, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+safeProposalColumns+` FROM safe_proposals WHERE safe_tx_hash = $1`, safeTxHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query safe proposal: %w", err)
+	}
+	defer rows.Close()
+
+	proposal, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.SafeProposal])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect safe proposal row: %w", err)
+	}
+
+	return &proposal, nil
+}
+
+// FindByStatus retrieves proposals with the given status, oldest first. Empty status returns all proposals.
+func (r *SafeProposalsRepository) FindByStatus(ctx context.Context, status entities.SafeProposalStatus, limit int) ([]entities.SafeProposal, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+safeProposalColumns+` FROM safe_proposals WHERE ($1 = '' OR status = $1) ORDER BY created_at LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query safe proposals: %w", err)
+	}
+	defer rows.Close()
+
+	proposals, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.SafeProposal])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect safe proposal rows: %w", err)
+	}
+
+	return proposals, nil
+}
+
+// NextNonce returns the nonce following the highest proposed nonce of the safe, or 0 if there are no proposals.
+// Proposals that are not executed yet occupy their nonces in the Safe queue.
+func (r *SafeProposalsRepository) NextNonce(ctx context.Context, safeAddress string) (uint64, error) {
+	var next int64
+	err := r.db(ctx).QueryRow(ctx,
+		"SELECT COALESCE(MAX(nonce) + 1, 0) FROM safe_proposals WHERE safe_address = $1",
+		safeAddress).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next safe nonce: %w", err)
+	}
+
+	return uint64(next), nil
+}
+
+// UpdateConfirmations stores the number of owner confirmations collected so far
+func (r *SafeProposalsRepository) UpdateConfirmations(ctx context.Context, id string, confirmations, required int) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE safe_proposals SET confirmations = $2, confirmations_required = $3, updated_at = NOW() WHERE id = $1",
+		id, confirmations, required)
+	if err != nil {
+		return fmt.Errorf("failed to update safe proposal confirmations: %w", err)
+	}
+
+	return nil
+}
+
+// MarkExecuted stores the result of the on-chain execution
+func (r *SafeProposalsRepository) MarkExecuted(ctx context.Context, id string, status entities.SafeProposalStatus, txHash string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE safe_proposals SET status = $2, executed_tx_hash = $3, updated_at = NOW() WHERE id = $1",
+		id, status, txHash)
+	if err != nil {
+		return fmt.Errorf("failed to mark safe proposal executed: %w", err)
+	}
+
+	return nil
+}
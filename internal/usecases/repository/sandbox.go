@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// SandboxRepository stores merchants running in the sandbox mode
+type SandboxRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewSandboxRepository creates a new sandbox merchants repository.
+func NewSandboxRepository(logger *slog.Logger, pg *database.Postgres) *SandboxRepository {
+	return &SandboxRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// IsSandbox reports whether the merchant runs in the sandbox mode
+func (r *SandboxRepository) IsSandbox(ctx context.Context, merchantID int64) (bool, error) {
+	var sandbox bool
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM sandbox_merchants WHERE merchant_id = $1)`, merchantID).Scan(&sandbox)
+	if err != nil {
+		return false, fmt.Errorf("failed to check sandbox merchant: %w", err)
+	}
+
+	return sandbox, nil
+}
+
+// FindSandboxMerchants returns merchants in the sandbox mode, most recently enabled first
+func (r *SandboxRepository) FindSandboxMerchants(ctx context.Context) ([]entities.SandboxMerchant, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT merchant_id, enabled_by, created_at FROM sandbox_merchants ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sandbox merchants: %w", err)
+	}
+	defer rows.Close()
+
+	merchants, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.SandboxMerchant])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect sandbox merchants: %w", err)
+	}
+
+	return merchants, nil
+}
+
+// EnableSandbox moves the merchant to the sandbox mode, keeping the original record if it is already there
+func (r *SandboxRepository) EnableSandbox(ctx context.Context, merchantID int64, actor string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO sandbox_merchants (merchant_id, enabled_by) VALUES ($1, $2)
+		 ON CONFLICT (merchant_id) DO NOTHING`,
+		merchantID, actor)
+	if err != nil {
+		return fmt.Errorf("failed to enable sandbox merchant: %w", err)
+	}
+
+	return nil
+}
+
+// DisableSandbox returns the merchant to the production mode
+func (r *SandboxRepository) DisableSandbox(ctx context.Context, merchantID int64) error {
+	_, err := r.db(ctx).Exec(ctx, `DELETE FROM sandbox_merchants WHERE merchant_id = $1`, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to disable sandbox merchant: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const scannerStateColumns = `chain, last_block, paused, pause_reason, paused_at, updated_by, updated_at`
+
+// ScannerStatesRepository stores the checkpoints and the pause state of chain scanners
+type ScannerStatesRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewScannerStatesRepository creates a new scanner states repository.
+func NewScannerStatesRepository(logger *slog.Logger, pg *database.Postgres) *ScannerStatesRepository {
+	return &ScannerStatesRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindState returns the state of the chain scanner or nil if it was never saved
+func (r *ScannerStatesRepository) FindState(ctx context.Context, chain entities.Chain) (*entities.ScannerState, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+scannerStateColumns+` FROM scanner_states WHERE chain = $1`, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scanner state: %w", err)
+	}
+	defer rows.Close()
+
+	state, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.ScannerState])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect scanner state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// FindStates returns the saved states of all chain scanners
+func (r *ScannerStatesRepository) FindStates(ctx context.Context) ([]entities.ScannerState, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+scannerStateColumns+` FROM scanner_states ORDER BY chain`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scanner states: %w", err)
+	}
+	defer rows.Close()
+
+	states, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.ScannerState])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect scanner states: %w", err)
+	}
+
+	return states, nil
+}
+
+// SaveCheckpoint stores the last block processed by the chain scanner
+func (r *ScannerStatesRepository) SaveCheckpoint(ctx context.Context, chain entities.Chain, block uint64) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO scanner_states (chain, last_block)
+		 VALUES ($1, $2)
+		 ON CONFLICT (chain) DO UPDATE
+		    SET last_block = EXCLUDED.last_block,
+		        updated_at = NOW()`,
+		chain, int64(block))
+	if err != nil {
+		return fmt.Errorf("failed to save scanner checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// SetPaused pauses or resumes the chain scanner. Resuming clears the pause reason.
+func (r *ScannerStatesRepository) SetPaused(ctx context.Context, chain entities.Chain, paused bool, reason, actor string) (*entities.ScannerState, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`INSERT INTO scanner_states (chain, paused, pause_reason, paused_at, updated_by)
+		 VALUES ($1, $2, CASE WHEN $2 THEN NULLIF($3, '') END, CASE WHEN $2 THEN NOW() END, $4)
+		 ON CONFLICT (chain) DO UPDATE
+		    SET paused = EXCLUDED.paused,
+		        pause_reason = EXCLUDED.pause_reason,
+		        paused_at = EXCLUDED.paused_at,
+		        updated_by = EXCLUDED.updated_by,
+		        updated_at = NOW()
+		 RETURNING `+scannerStateColumns,
+		chain, paused, reason, actor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set scanner pause: %w", err)
+	}
+	defer rows.Close()
+
+	state, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.ScannerState])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect scanner state: %w", err)
+	}
+
+	return &state, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const sessionColumns = `id, user_id, token_hash, device_id, ip_address, user_agent, country, city,
+                        created_at, last_seen_at, expires_at, revoked_at`
+
+// SessionsRepository stores user sessions and login history.
+type SessionsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewSessionsRepository creates a new sessions repository.
+func NewSessionsRepository(logger *slog.Logger, pg *database.Postgres) *SessionsRepository {
+	return &SessionsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateSession inserts a new session
+func (r *SessionsRepository) CreateSession(ctx context.Context, session *entities.Session) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO user_sessions (id, user_id, token_hash, device_id, ip_address, user_agent, country, city, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING created_at, last_seen_at`,
+		session.ID, session.UserID, session.TokenHash, session.DeviceID, session.IPAddress,
+		session.UserAgent, session.Country, session.City, session.ExpiresAt,
+	).Scan(&session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// CreateChallenge stores a login challenge and removes expired ones
+func (r *SessionsRepository) CreateChallenge(ctx context.Context, challenge *entities.LoginChallenge) error {
+	if _, err := r.db(ctx).Exec(ctx, "DELETE FROM session_challenges WHERE expires_at < NOW()"); err != nil {
+		return fmt.Errorf("failed to delete expired login challenges: %w", err)
+	}
+
+	_, err := r.db(ctx).Exec(ctx,
+		"INSERT INTO session_challenges (id, user_id, challenge, expires_at) VALUES ($1, $2, $3, $4)",
+		challenge.ID, challenge.UserID, challenge.Challenge, challenge.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeChallenge marks the unexpired challenge of the user as used and returns it.
+// Returns an empty string if the challenge does not exist, belongs to another user, expired or was already used.
+func (r *SessionsRepository) ConsumeChallenge(ctx context.Context, id string, userID int64) (string, error) {
+	var challenge string
+	err := r.db(ctx).QueryRow(ctx,
+		`UPDATE session_challenges SET used_at = NOW()
+		  WHERE id = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()
+		  RETURNING challenge`,
+		id, userID).Scan(&challenge)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to consume login challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// FindByTokenHash retrieves a session by the hash of its token
+func (r *SessionsRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entities.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE token_hash = $1`
+
+	rows, err := r.db(ctx).Query(ctx, query, tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session: %w", err)
+	}
+	defer rows.Close()
+
+	session, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.Session])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect session row: %w", err)
+	}
+
+	return &session, nil
+}
+
+// FindByUserID retrieves the latest sessions of a user, newest first
+func (r *SessionsRepository) FindByUserID(ctx context.Context, userID int64, activeOnly bool, limit int) ([]entities.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+                FROM user_sessions
+               WHERE user_id = $1
+                 AND (NOT $2 OR (revoked_at IS NULL AND expires_at > NOW()))
+               ORDER BY created_at DESC
+               LIMIT $3`
+
+	rows, err := r.db(ctx).Query(ctx, query, userID, activeOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Session])
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to collect sessions rows", "error", err)
+		return nil, fmt.Errorf("failed to collect sessions rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// HasDevice checks whether the user has ever logged in from the device
+func (r *SessionsRepository) HasDevice(ctx context.Context, userID int64, deviceID string) (bool, error) {
+	var exists bool
+	err := r.db(ctx).QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM user_sessions WHERE user_id = $1 AND device_id = $2)",
+		userID, deviceID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check known device: %w", err)
+	}
+
+	return exists, nil
+}
+
+// TouchSession updates the last activity time of the session
+func (r *SessionsRepository) TouchSession(ctx context.Context, id string) error {
+	_, err := r.db(ctx).Exec(ctx, "UPDATE user_sessions SET last_seen_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to update session activity: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeSession revokes a session owned by the user. Returns false if there was no such active session.
+func (r *SessionsRepository) RevokeSession(ctx context.Context, userID int64, id string) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		"UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL",
+		id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeAllSessions revokes all active sessions of the user except the given one
+func (r *SessionsRepository) RevokeAllSessions(ctx context.Context, userID int64, exceptID string) (int64, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		"UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL AND id::text <> $2",
+		userID, exceptID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
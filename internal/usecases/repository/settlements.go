@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const settlementColumns = `id, merchant_id, asset_id, address, orders, gross_amount, fee_amount, net_amount, fee_bps, status,
+	tx_hash, safe_tx_hash, error, period_start, period_end, created_at, updated_at`
+
+// SettlementsRepository stores merchant settlement accounts and settlement batches.
+type SettlementsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewSettlementsRepository creates a new settlements repository.
+func NewSettlementsRepository(logger *slog.Logger, pg *database.Postgres) *SettlementsRepository {
+	return &SettlementsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, so orders locked by fn stay locked until they are assigned to a batch
+func (r *SettlementsRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, fn)
+}
+
+// GetAccount returns the settlement account of the merchant or nil if it is not configured
+func (r *SettlementsRepository) GetAccount(ctx context.Context, merchantID int64) (*entities.SettlementAccount, error) {
+	var account entities.SettlementAccount
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT merchant_id, address, fee_bps, updated_by, created_at, updated_at
+		   FROM merchant_settlement_accounts WHERE merchant_id = $1`,
+		merchantID,
+	).Scan(&account.MerchantID, &account.Address, &account.FeeBPS, &account.UpdatedBy, &account.CreatedAt, &account.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement account: %w", err)
+	}
+
+	return &account, nil
+}
+
+// SetAccount creates or replaces the settlement address of the merchant, the fee override is kept when nil
+func (r *SettlementsRepository) SetAccount(ctx context.Context, account *entities.SettlementAccount) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO merchant_settlement_accounts (merchant_id, address, fee_bps, updated_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (merchant_id) DO UPDATE
+		    SET address = EXCLUDED.address,
+		        fee_bps = COALESCE(EXCLUDED.fee_bps, merchant_settlement_accounts.fee_bps),
+		        updated_by = EXCLUDED.updated_by,
+		        updated_at = NOW()
+		 RETURNING fee_bps, created_at, updated_at`,
+		account.MerchantID, account.Address, account.FeeBPS, account.UpdatedBy,
+	).Scan(&account.FeeBPS, &account.CreatedAt, &account.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set settlement account: %w", err)
+	}
+
+	return nil
+}
+
+// FindDueAccounts retrieves settlement accounts of merchants with unsettled orders of the asset completed before completedBefore.
+// Orders created before the asset registry (without asset_id) belong to assetID, orders flagged by AML are never settled.
+func (r *SettlementsRepository) FindDueAccounts(ctx context.Context, assetID int, completedBefore time.Time, limit int) ([]entities.SettlementAccount, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT a.merchant_id, a.address, a.fee_bps, a.updated_by, a.created_at, a.updated_at
+		   FROM merchant_settlement_accounts a
+		  WHERE EXISTS (SELECT 1 FROM orders o
+		                 WHERE o.user_id = a.merchant_id AND o.status = 'completed' AND o.settlement_id IS NULL
+		                   AND o.aml_status <> 'flagged'
+		                   AND COALESCE(o.asset_id, $1) = $1 AND o.updated_at < $2
+		                   AND NOT EXISTS (SELECT 1 FROM fiat_payouts p WHERE p.order_id = o.id AND p.status <> 'failed'))
+		  ORDER BY a.merchant_id
+		  LIMIT $3`,
+		assetID, completedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due settlement accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.SettlementAccount])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect settlement accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// LockUnsettledOrders retrieves and locks unsettled completed orders of the merchant, must be called within a transaction.
+// Orders locked by a concurrent batch and orders flagged by AML are skipped.
+func (r *SettlementsRepository) LockUnsettledOrders(ctx context.Context, merchantID int64, assetID int, completedBefore time.Time) ([]entities.Order, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, user_id, wallet_id, asset_id, amount, expected_amount, memo, status, aml_status, aml_notes, is_test, created_at, updated_at
+		   FROM orders o
+		  WHERE o.user_id = $1 AND o.status = 'completed' AND o.settlement_id IS NULL
+		    AND o.aml_status <> 'flagged'
+		    AND COALESCE(o.asset_id, $2) = $2 AND o.updated_at < $3
+		    AND NOT EXISTS (SELECT 1 FROM fiat_payouts p WHERE p.order_id = o.id AND p.status <> 'failed')
+		  ORDER BY id
+		  FOR UPDATE SKIP LOCKED`,
+		merchantID, assetID, completedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unsettled orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Order])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect unsettled orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// CreateSettlement inserts a pending settlement and assigns the orders to it
+func (r *SettlementsRepository) CreateSettlement(ctx context.Context, settlement *entities.Settlement, orderIDs []int) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO settlements (id, merchant_id, asset_id, address, orders, gross_amount, fee_amount, net_amount, fee_bps,
+		                          status, period_start, period_end)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 RETURNING created_at, updated_at`,
+		settlement.ID, settlement.MerchantID, settlement.AssetID, settlement.Address, settlement.Orders,
+		settlement.GrossAmount, settlement.FeeAmount, settlement.NetAmount, settlement.FeeBPS,
+		settlement.Status, settlement.PeriodStart, settlement.PeriodEnd,
+	).Scan(&settlement.CreatedAt, &settlement.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create settlement: %w", err)
+	}
+
+	if _, err = r.db(ctx).Exec(ctx, "UPDATE orders SET settlement_id = $1 WHERE id = ANY($2)", settlement.ID, orderIDs); err != nil {
+		return fmt.Errorf("failed to assign orders to settlement: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSubmitted stores the transaction or the Safe proposal paying the settlement
+func (r *SettlementsRepository) MarkSubmitted(ctx context.Context, id string, status entities.SettlementStatus, txHash, safeTxHash *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE settlements SET status = $2, tx_hash = $3, safe_tx_hash = $4, updated_at = NOW() WHERE id = $1`,
+		id, status, txHash, safeTxHash)
+	if err != nil {
+		return fmt.Errorf("failed to mark settlement submitted: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUnknown records the payout transaction whose broadcast was not confirmed by the node.
+// The orders stay in the batch until the operator reconciles the transaction.
+func (r *SettlementsRepository) MarkUnknown(ctx context.Context, id, txHash, errMsg string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE settlements SET status = $2, tx_hash = $3, error = $4, updated_at = NOW() WHERE id = $1`,
+		id, entities.SettlementUnknown, txHash, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to mark settlement unknown: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records the payout error and releases the orders for the next batch
+func (r *SettlementsRepository) MarkFailed(ctx context.Context, id, errMsg string) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := r.db(txCtx).Exec(txCtx,
+			`UPDATE settlements SET status = 'failed', error = $2, updated_at = NOW() WHERE id = $1`,
+			id, errMsg)
+		if err != nil {
+			return fmt.Errorf("failed to mark settlement failed: %w", err)
+		}
+
+		if _, err = r.db(txCtx).Exec(txCtx, "UPDATE orders SET settlement_id = NULL WHERE settlement_id = $1", id); err != nil {
+			return fmt.Errorf("failed to release settlement orders: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// FindByID returns the settlement or nil if it does not exist
+func (r *SettlementsRepository) FindByID(ctx context.Context, id string) (*entities.Settlement, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+settlementColumns+` FROM settlements WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settlement: %w", err)
+	}
+	defer rows.Close()
+
+	settlement, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.Settlement])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect settlement: %w", err)
+	}
+
+	return &settlement, nil
+}
+
+// FindByMerchant retrieves settlements of the merchant, newest first
+func (r *SettlementsRepository) FindByMerchant(ctx context.Context, merchantID int64, limit int) ([]entities.Settlement, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+settlementColumns+` FROM settlements WHERE merchant_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant settlements: %w", err)
+	}
+	defer rows.Close()
+
+	settlements, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Settlement])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect merchant settlements: %w", err)
+	}
+
+	return settlements, nil
+}
+
+// FindByStatus retrieves settlements with the given status, all settlements if status is empty, newest first
+func (r *SettlementsRepository) FindByStatus(ctx context.Context, status entities.SettlementStatus, limit int) ([]entities.Settlement, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+settlementColumns+` FROM settlements WHERE $1 = '' OR status = $1 ORDER BY created_at DESC LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settlements: %w", err)
+	}
+	defer rows.Close()
+
+	settlements, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Settlement])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect settlements: %w", err)
+	}
+
+	return settlements, nil
+}
+
+// FindSettlementOrders retrieves the orders paid by the settlement
+func (r *SettlementsRepository) FindSettlementOrders(ctx context.Context, id string) ([]entities.SettlementOrder, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, amount::TEXT, updated_at FROM orders WHERE settlement_id = $1 ORDER BY id`,
+		id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settlement orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.SettlementOrder])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect settlement orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// RefreshAccrual recalculates the accrual of the merchant from the completed orders not yet assigned to a settlement.
+// Orders without an asset belong to assetID, like in LockUnsettledOrders.
+func (r *SettlementsRepository) RefreshAccrual(ctx context.Context, merchantID int64, assetID int) (*entities.SettlementAccrual, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`INSERT INTO merchant_accruals (merchant_id, asset_id, orders, amount, updated_at)
+		 SELECT $1, $2, COUNT(*), COALESCE(SUM(o.amount::NUMERIC), 0)::TEXT, NOW()
+		   FROM orders o
+		  WHERE o.user_id = $1 AND o.status = 'completed' AND o.settlement_id IS NULL
+		    AND COALESCE(o.asset_id, $2) = $2
+		    AND NOT EXISTS (SELECT 1 FROM fiat_payouts p WHERE p.order_id = o.id AND p.status <> 'failed')
+		 ON CONFLICT (merchant_id, asset_id)
+		 DO UPDATE SET orders = EXCLUDED.orders, amount = EXCLUDED.amount, updated_at = EXCLUDED.updated_at
+		 RETURNING merchant_id, asset_id, orders, amount, updated_at`,
+		merchantID, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh merchant accrual: %w", err)
+	}
+	defer rows.Close()
+
+	accrual, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.SettlementAccrual])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect merchant accrual: %w", err)
+	}
+
+	return &accrual, nil
+}
+
+// FindAccrual returns the accrual of the merchant or nil if none was recorded
+func (r *SettlementsRepository) FindAccrual(ctx context.Context, merchantID int64, assetID int) (*entities.SettlementAccrual, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT merchant_id, asset_id, orders, amount, updated_at FROM merchant_accruals WHERE merchant_id = $1 AND asset_id = $2`,
+		merchantID, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant accrual: %w", err)
+	}
+	defer rows.Close()
+
+	accrual, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.SettlementAccrual])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect merchant accrual: %w", err)
+	}
+
+	return &accrual, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMLFlaggedOrdersAreNotSettled(t *testing.T) {
+	pg, logger := newTestPostgres(t)
+	ctx := context.Background()
+	settlements := NewSettlementsRepository(logger, pg)
+
+	// insertMerchant создает счет выплат мерчанта и завершенные ордера с заданными статусами AML
+	insertMerchant := func(amlStatuses ...string) (int64, []int) {
+		merchantID := randomUserID(t)
+		walletID, _ := insertTestWallet(t, pg, merchantID)
+
+		_, err := pg.Pool.Exec(ctx,
+			`INSERT INTO merchant_settlement_accounts (merchant_id, address) VALUES ($1, $2)`,
+			merchantID, randomHex(t, 20))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = pg.Pool.Exec(context.Background(), "DELETE FROM merchant_settlement_accounts WHERE merchant_id = $1", merchantID)
+		})
+
+		orderIDs := make([]int, 0, len(amlStatuses))
+		for _, amlStatus := range amlStatuses {
+			var orderID int
+			err = pg.Pool.QueryRow(ctx,
+				`INSERT INTO orders (user_id, wallet_id, amount, status, aml_status)
+				 VALUES ($1, $2, '10', 'completed', $3::aml_status_type) RETURNING id`,
+				merchantID, walletID, amlStatus).Scan(&orderID)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				deleteTestOrder(pg, orderID)
+			})
+			orderIDs = append(orderIDs, orderID)
+		}
+
+		return merchantID, orderIDs
+	}
+
+	flaggedMerchant, _ := insertMerchant("flagged")
+	mixedMerchant, mixedOrders := insertMerchant("flagged", "cleared", "none")
+
+	completedBefore := time.Now().Add(time.Hour)
+	accounts, err := settlements.FindDueAccounts(ctx, 1, completedBefore, 1_000_000)
+	require.NoError(t, err)
+	due := make(map[int64]bool, len(accounts))
+	for _, account := range accounts {
+		due[account.MerchantID] = true
+	}
+	assert.False(t, due[flaggedMerchant], "merchant with only flagged orders must not be due")
+	assert.True(t, due[mixedMerchant])
+
+	orders, err := settlements.LockUnsettledOrders(ctx, mixedMerchant, 1, completedBefore)
+	require.NoError(t, err)
+	lockedIDs := make([]int, 0, len(orders))
+	for _, order := range orders {
+		lockedIDs = append(lockedIDs, order.ID)
+	}
+	assert.ElementsMatch(t, mixedOrders[1:], lockedIDs)
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const (
+	staffNoteColumns = `id, subject_type, subject_id, body, author, created_at`
+	staffTagColumns  = `subject_type, subject_id, tag, added_by, created_at`
+)
+
+// StaffAnnotationsRepository stores internal notes and tags of transactions and orders
+type StaffAnnotationsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewStaffAnnotationsRepository creates a new staff annotations repository.
+func NewStaffAnnotationsRepository(logger *slog.Logger, pg *database.Postgres) *StaffAnnotationsRepository {
+	return &StaffAnnotationsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// SubjectExists reports whether the transaction with the hash or the order with the ID exists
+func (r *StaffAnnotationsRepository) SubjectExists(ctx context.Context, subjectType entities.StaffSubjectType, subjectID string) (bool, error) {
+	var exists bool
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT CASE $1
+		            WHEN 'transaction' THEN EXISTS (SELECT 1 FROM transactions WHERE tx_hash = $2)
+		            WHEN 'order' THEN EXISTS (SELECT 1 FROM orders WHERE id::text = $2)
+		            ELSE FALSE
+		        END`,
+		string(subjectType), subjectID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check annotation subject: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CreateNote inserts a note
+func (r *StaffAnnotationsRepository) CreateNote(ctx context.Context, note *entities.StaffNote) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO staff_notes (subject_type, subject_id, body, author)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at`,
+		note.SubjectType, note.SubjectID, note.Body, note.Author,
+	).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create staff note: %w", err)
+	}
+
+	return nil
+}
+
+// AddTag tags the subject. Returns false if the subject already has the tag.
+func (r *StaffAnnotationsRepository) AddTag(ctx context.Context, tag *entities.StaffTag) (bool, error) {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO staff_tags (subject_type, subject_id, tag, added_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT DO NOTHING
+		 RETURNING created_at`,
+		tag.SubjectType, tag.SubjectID, tag.Tag, tag.AddedBy,
+	).Scan(&tag.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to add staff tag: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoveTag removes the tag from the subject. Returns false if the subject did not have it.
+func (r *StaffAnnotationsRepository) RemoveTag(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, tag string) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`DELETE FROM staff_tags WHERE subject_type = $1 AND subject_id = $2 AND tag = $3`,
+		subjectType, subjectID, tag)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove staff tag: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// FindSubjects returns annotated subjects matching the filter, most recently annotated first.
+// The query matches note bodies case-insensitively.
+func (r *StaffAnnotationsRepository) FindSubjects(ctx context.Context, filter entities.StaffAnnotationFilter, limit int) ([]entities.StaffAnnotations, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`WITH subjects AS (
+		     SELECT subject_type, subject_id, MAX(created_at) AS annotated_at FROM (
+		         SELECT subject_type, subject_id, created_at FROM staff_notes
+		         UNION ALL
+		         SELECT subject_type, subject_id, created_at FROM staff_tags
+		     ) a
+		     GROUP BY subject_type, subject_id
+		 )
+		 SELECT s.subject_type, s.subject_id
+		   FROM subjects s
+		  WHERE ($1 = '' OR s.subject_type = $1)
+		    AND ($2 = '' OR EXISTS (SELECT 1 FROM staff_tags t
+		                             WHERE t.subject_type = s.subject_type AND t.subject_id = s.subject_id AND t.tag = $2))
+		    AND ($3 = '' OR EXISTS (SELECT 1 FROM staff_notes n
+		                             WHERE n.subject_type = s.subject_type AND n.subject_id = s.subject_id
+		                               AND strpos(lower(n.body), lower($3)) > 0))
+		  ORDER BY s.annotated_at DESC
+		  LIMIT $4`,
+		string(filter.SubjectType), filter.Tag, filter.Query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query annotated subjects: %w", err)
+	}
+	defer rows.Close()
+
+	subjects, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entities.StaffAnnotations, error) {
+		var subject entities.StaffAnnotations
+		err := row.Scan(&subject.SubjectType, &subject.SubjectID)
+		return subject, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect annotated subjects: %w", err)
+	}
+
+	return subjects, nil
+}
+
+// FindNotes returns the notes of the subjects, oldest first
+func (r *StaffAnnotationsRepository) FindNotes(ctx context.Context, subjects []entities.StaffAnnotations) ([]entities.StaffNote, error) {
+	types, ids := subjectArrays(subjects)
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+staffNoteColumns+`
+		   FROM staff_notes
+		   JOIN unnest($1::text[], $2::text[]) AS s(subject_type, subject_id) USING (subject_type, subject_id)
+		  ORDER BY created_at, id`,
+		types, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staff notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.StaffNote])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect staff notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// FindTags returns the tags of the subjects in alphabetical order
+func (r *StaffAnnotationsRepository) FindTags(ctx context.Context, subjects []entities.StaffAnnotations) ([]entities.StaffTag, error) {
+	types, ids := subjectArrays(subjects)
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+staffTagColumns+`
+		   FROM staff_tags
+		   JOIN unnest($1::text[], $2::text[]) AS s(subject_type, subject_id) USING (subject_type, subject_id)
+		  ORDER BY tag`,
+		types, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staff tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.StaffTag])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect staff tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// subjectArrays раскладывает объекты на параллельные массивы типов и ID для unnest
+func subjectArrays(subjects []entities.StaffAnnotations) ([]string, []string) {
+	types := make([]string, len(subjects))
+	ids := make([]string, len(subjects))
+	for i, subject := range subjects {
+		types[i] = string(subject.SubjectType)
+		ids[i] = subject.SubjectID
+	}
+	return types, ids
+}
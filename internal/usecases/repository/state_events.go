@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const (
+	orderEventColumns       = `id, order_id, event_type, from_status, to_status, changed, state, db_tx_id, occurred_at`
+	transactionEventColumns = `id, tx_hash, event_type, changed, state, db_tx_id, occurred_at`
+)
+
+// StateEventsRepository reads the order and transaction event journals. Events are written by database
+// triggers in the transaction that changes the row, so the repository never inserts them.
+type StateEventsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewStateEventsRepository creates a new state events repository.
+func NewStateEventsRepository(logger *slog.Logger, pg *database.Postgres) *StateEventsRepository {
+	return &StateEventsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindOrderEvents returns the events of the order, oldest first
+func (r *StateEventsRepository) FindOrderEvents(ctx context.Context, orderID int64) ([]entities.OrderEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderEventColumns+` FROM order_events WHERE order_id = $1 ORDER BY id`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.OrderEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// FindTransactionEvents returns the events of the transaction, oldest first
+func (r *StateEventsRepository) FindTransactionEvents(ctx context.Context, txHash string) ([]entities.TransactionEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+transactionEventColumns+` FROM transaction_events WHERE LOWER(tx_hash) = LOWER($1) ORDER BY id`, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.TransactionEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect transaction event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// ListOrderEvents returns a page of the order journal after the event afterID that occurred before until
+func (r *StateEventsRepository) ListOrderEvents(ctx context.Context, afterID int64, until time.Time, limit int) ([]entities.OrderEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+orderEventColumns+` FROM order_events
+		  WHERE id > $1 AND occurred_at < $2
+		  ORDER BY id LIMIT $3`,
+		afterID, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.OrderEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect order event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// ListTransactionEvents returns a page of the transaction journal after the event afterID that occurred before until
+func (r *StateEventsRepository) ListTransactionEvents(ctx context.Context, afterID int64, until time.Time, limit int) ([]entities.TransactionEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+transactionEventColumns+` FROM transaction_events
+		  WHERE id > $1 AND occurred_at < $2
+		  ORDER BY id LIMIT $3`,
+		afterID, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.TransactionEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect transaction event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// FindWalletNetworks returns the chain and network of every wallet, including archived and retired ones
+func (r *StateEventsRepository) FindWalletNetworks(ctx context.Context) ([]entities.WalletNetwork, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT LOWER(address), chain, network FROM wallets`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet networks: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.WalletNetwork])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallet network rows: %w", err)
+	}
+
+	return wallets, nil
+}
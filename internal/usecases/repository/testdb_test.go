@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/config"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// newTestPostgres подключается к базе из TEST_DATABASE_URL и применяет миграции.
+// Без переменной окружения тест пропускается: тесты репозиториев требуют PostgreSQL.
+func newTestPostgres(t *testing.T) (*database.Postgres, *slog.Logger) {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	require.NoError(t, database.RunMigrations(logger, databaseURL, "../../../migrations"))
+
+	pg, err := database.New(&config.Config{DB: config.DB{DatabaseURL: databaseURL}}, database.MaxPoolSize(4), database.ConnAttempts(1))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	return pg, logger
+}
+
+// randomHex возвращает случайную hex строку из n байт с префиксом 0x
+func randomHex(t *testing.T, n int) string {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return "0x" + hex.EncodeToString(b)
+}
+
+// randomUserID возвращает случайный идентификатор пользователя, не пересекающийся с другими тестами
+func randomUserID(t *testing.T) int64 {
+	t.Helper()
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<40))
+	require.NoError(t, err)
+	return n.Int64() + 1
+}
+
+// insertTestWallet создает кошелек пользователя и возвращает его ID и адрес
+func insertTestWallet(t *testing.T, pg *database.Postgres, userID int64) (int, string) {
+	t.Helper()
+
+	ctx := context.Background()
+	address := randomHex(t, 20)
+	var id int
+	err := pg.Pool.QueryRow(ctx,
+		`INSERT INTO wallets (user_id, address, derivation_path, wallet_index) VALUES ($1, $2, 'm/44/60/0/0/0', 0) RETURNING id`,
+		userID, address).Scan(&id)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = pg.Pool.Exec(context.Background(), "DELETE FROM transactions WHERE wallet_address = $1", address)
+		_, _ = pg.Pool.Exec(context.Background(), "DELETE FROM wallets WHERE id = $1", id)
+	})
+
+	return id, address
+}
+
+// deleteTestOrder удаляет ордер вместе с очередями, в которые он попал при оплате
+func deleteTestOrder(pg *database.Postgres, orderID int) {
+	ctx := context.Background()
+	_, _ = pg.Pool.Exec(ctx, "DELETE FROM order_receipts WHERE order_id = $1", orderID)
+	_, _ = pg.Pool.Exec(ctx, "DELETE FROM order_completions WHERE order_id = $1", orderID)
+	_, _ = pg.Pool.Exec(ctx, "DELETE FROM orders WHERE id = $1", orderID)
+}
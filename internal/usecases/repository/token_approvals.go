@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const tokenApprovalColumns = `id, token, owner, spender, amount, allowance, purpose, status, tx_hash, revoke_tx_hash,
+	created_by, revoked_by, expires_at, checked_at, created_at, revoked_at`
+
+// TokenApprovalsRepository stores ERC-20 approvals issued by the master wallet
+type TokenApprovalsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewTokenApprovalsRepository creates a new token approvals repository.
+func NewTokenApprovalsRepository(logger *slog.Logger, pg *database.Postgres) *TokenApprovalsRepository {
+	return &TokenApprovalsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateApproval inserts an issued approval. ErrUniqueViolation is returned if the spender already has an active one.
+func (r *TokenApprovalsRepository) CreateApproval(ctx context.Context, approval *entities.TokenApproval) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO token_approvals (token, owner, spender, amount, purpose, status, tx_hash, created_by, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING id, created_at`,
+		approval.Token, approval.Owner, approval.Spender, approval.Amount, approval.Purpose, approval.Status,
+		approval.TxHash, approval.CreatedBy, approval.ExpiresAt,
+	).Scan(&approval.ID, &approval.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create token approval: %w", constraintError(err))
+	}
+
+	return nil
+}
+
+// FindApprovalByID returns the approval or nil if it does not exist
+func (r *TokenApprovalsRepository) FindApprovalByID(ctx context.Context, id int64) (*entities.TokenApproval, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+tokenApprovalColumns+` FROM token_approvals WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token approval: %w", err)
+	}
+
+	approval, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.TokenApproval])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect token approval: %w", err)
+	}
+
+	return &approval, nil
+}
+
+// FindActiveApproval returns the active approval of the owner to the spender or nil
+func (r *TokenApprovalsRepository) FindActiveApproval(ctx context.Context, token, owner, spender string) (*entities.TokenApproval, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+tokenApprovalColumns+` FROM token_approvals
+		  WHERE token = $1 AND owner = $2 AND spender = $3 AND status = 'active'`,
+		token, owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active token approval: %w", err)
+	}
+
+	approval, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.TokenApproval])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect token approval: %w", err)
+	}
+
+	return &approval, nil
+}
+
+// FindApprovals retrieves the latest approvals, all statuses when status is empty
+func (r *TokenApprovalsRepository) FindApprovals(ctx context.Context, status entities.TokenApprovalStatus, limit int) ([]entities.TokenApproval, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+tokenApprovalColumns+` FROM token_approvals
+		  WHERE $1 = '' OR status = $1
+		  ORDER BY created_at DESC
+		  LIMIT $2`,
+		string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.TokenApproval])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect token approval rows: %w", err)
+	}
+
+	return approvals, nil
+}
+
+// UpdateAllowance stores the allowance read from the token contract
+func (r *TokenApprovalsRepository) UpdateAllowance(ctx context.Context, id int64, allowance string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE token_approvals SET allowance = $2, checked_at = NOW() WHERE id = $1",
+		id, allowance)
+	if err != nil {
+		return fmt.Errorf("failed to update token approval allowance: %w", err)
+	}
+
+	return nil
+}
+
+// CloseApproval marks an active approval revoked or expired. Returns false if it was not active.
+func (r *TokenApprovalsRepository) CloseApproval(ctx context.Context, id int64, status entities.TokenApprovalStatus, revokeTxHash, revokedBy string) (bool, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE token_approvals
+		    SET status = $2, revoke_tx_hash = $3, revoked_by = $4, allowance = '0', revoked_at = NOW()
+		  WHERE id = $1 AND status = 'active'`,
+		id, status, revokeTxHash, revokedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to close token approval: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
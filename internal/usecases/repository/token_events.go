@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const tokenAdminEventColumns = `id, token_address, event_name, tx_hash, log_index, block_number, subject, halting,
+                                acknowledged_by, acknowledged_at, created_at`
+
+// TokenEventsRepository stores administrative events of monitored token contracts.
+type TokenEventsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewTokenEventsRepository creates a new token events repository.
+func NewTokenEventsRepository(logger *slog.Logger, pg *database.Postgres) *TokenEventsRepository {
+	return &TokenEventsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateEvent inserts the event. Returns false if the log was already recorded.
+func (r *TokenEventsRepository) CreateEvent(ctx context.Context, event *entities.TokenAdminEvent) (bool, error) {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO token_admin_events (token_address, event_name, tx_hash, log_index, block_number, subject, halting)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (tx_hash, log_index) DO NOTHING
+		 RETURNING id, created_at`,
+		event.TokenAddress, event.EventName, event.TxHash, event.LogIndex, event.BlockNumber, event.Subject, event.Halting,
+	).Scan(&event.ID, &event.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create token admin event: %w", err)
+	}
+
+	return true, nil
+}
+
+// FindUnacknowledgedHalts retrieves halting events not yet acknowledged by an administrator
+func (r *TokenEventsRepository) FindUnacknowledgedHalts(ctx context.Context) ([]entities.TokenAdminEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+tokenAdminEventColumns+` FROM token_admin_events WHERE halting AND acknowledged_at IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token admin events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.TokenAdminEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect token admin event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// FindEvents retrieves the latest events, newest first
+func (r *TokenEventsRepository) FindEvents(ctx context.Context, limit int) ([]entities.TokenAdminEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+tokenAdminEventColumns+` FROM token_admin_events ORDER BY id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token admin events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.TokenAdminEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect token admin event rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// Acknowledge marks the event as acknowledged. Returns nil if the event does not exist or is already acknowledged.
+func (r *TokenEventsRepository) Acknowledge(ctx context.Context, id int64, acknowledgedBy string) (*entities.TokenAdminEvent, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`UPDATE token_admin_events SET acknowledged_by = $2, acknowledged_at = NOW()
+		 WHERE id = $1 AND acknowledged_at IS NULL
+		 RETURNING `+tokenAdminEventColumns, id, acknowledgedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge token admin event: %w", err)
+	}
+	defer rows.Close()
+
+	event, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.TokenAdminEvent])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect token admin event row: %w", err)
+	}
+
+	return &event, nil
+}
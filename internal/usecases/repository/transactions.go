@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math/big"
+	"time"
 
 	tx "github.com/Thiht/transactor/pgx"
 	"github.com/ethereum/go-ethereum/common"
@@ -38,7 +39,7 @@ func NewTransactionsRepository(logger *slog.Logger, pg *database.Postgres, order
 
 // FindTransactionsByWallet retrieves all transactions for a specific wallet.
 func (r *TransactionsRepository) FindTransactionsByWallet(ctx context.Context, walletAddress string) ([]entities.Transaction, error) {
-	query := `SELECT id, tx_hash, wallet_address, amount, block_number, confirmed, processed, aml_status, created_at, updated_at 
+	query := `SELECT id, tx_hash, wallet_address, from_address, amount, block_number, confirmed, processed, aml_status, ignored_reason, created_at, updated_at 
                 FROM transactions 
                WHERE wallet_address = $1 
                ORDER BY id DESC
@@ -61,8 +62,19 @@ func (r *TransactionsRepository) FindTransactionsByWallet(ctx context.Context, w
 	return transactions, nil
 }
 
-// InsertTransaction stores a new transaction in the database
-func (r *TransactionsRepository) InsertTransaction(ctx context.Context, txHash common.Hash, walletAddress string, amount *big.Int, blockNumber int64) error {
+// FindLastBlockNumber returns the highest block number recorded for deposits to the wallet, 0 if there are none
+func (r *TransactionsRepository) FindLastBlockNumber(ctx context.Context, walletAddress string) (int64, error) {
+	var blockNumber int64
+	err := r.db(ctx).QueryRow(ctx,
+		"SELECT COALESCE(MAX(block_number), 0) FROM transactions WHERE wallet_address = $1", walletAddress).Scan(&blockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query last block number: %w", err)
+	}
+	return blockNumber, nil
+}
+
+// InsertTransaction stores a new transaction in the database. An empty memo means the transfer has none.
+func (r *TransactionsRepository) InsertTransaction(ctx context.Context, txHash common.Hash, walletAddress, fromAddress, memo string, amount *big.Int, blockNumber int64, requiredConfirmations uint64) error {
 	// Check if transaction already exists
 	var exists bool
 
@@ -76,22 +88,41 @@ func (r *TransactionsRepository) InsertTransaction(ctx context.Context, txHash c
 		return nil
 	}
 
-	// Insert new transaction
-	_, err = r.db(ctx).Exec(ctx,
-		"INSERT INTO transactions (tx_hash, wallet_address, amount, block_number) VALUES ($1, $2, $3, $4)",
-		txHash.Hex(), walletAddress, amount.String(), blockNumber)
+	// Insert new transaction linked to the tracked wallet, EVM addresses may be tracked on several chains
+	result, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO transactions (tx_hash, wallet_id, wallet_address, from_address, amount, block_number, required_confirmations, memo, is_test)
+		 SELECT $1, w.id, w.address, $3, $4, $5, $6, NULLIF($7, ''), w.is_testnet
+		   FROM wallets w
+		  WHERE w.address = $2
+		  ORDER BY w.id
+		  LIMIT 1`,
+		txHash.Hex(), walletAddress, fromAddress, amount.String(), blockNumber, int64(requiredConfirmations), memo)
+	err = constraintError(err)
+	if errors.Is(err, ErrUniqueViolation) {
+		// Транзакцию записал параллельный обработчик между проверкой и вставкой
+		r.logger.Info("Transaction already recorded", "tx_hash", txHash.Hex())
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert transaction: %w", err)
 	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("failed to insert transaction %s: %w: wallet %s is not tracked", txHash.Hex(), ErrForeignKeyViolation, walletAddress)
+	}
 
-	r.logger.Info("Transaction recorded", "tx_hash", txHash.Hex(), "wallet", walletAddress, "amount", amount.String())
+	r.logger.Info("Transaction recorded", "tx_hash", txHash.Hex(), "wallet", walletAddress, "amount", amount.String(),
+		"memo", memo, "required_confirmations", requiredConfirmations)
 
 	return nil
 }
 
 // UpdateTransaction marks a transaction as confirmed after required confirmations
-func (r *TransactionsRepository) UpdateTransaction(ctx context.Context, txHash string) error {
-	_, err := r.db(ctx).Exec(ctx, "UPDATE transactions SET confirmed = true, updated_at = NOW() WHERE tx_hash = $1", txHash)
+func (r *TransactionsRepository) UpdateTransaction(ctx context.Context, txHash string, confirmations uint64) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE transactions
+		    SET confirmed = true, confirmations = GREATEST(confirmations, $2), confirmed_at = COALESCE(confirmed_at, NOW()), updated_at = NOW()
+		  WHERE tx_hash = $1`,
+		txHash, int64(confirmations))
 	if err != nil {
 		return fmt.Errorf("failed to confirm transaction: %w", err)
 	}
@@ -100,58 +131,181 @@ func (r *TransactionsRepository) UpdateTransaction(ctx context.Context, txHash s
 	return nil
 }
 
-// UpdatePendingTransactions processes all confirmed but unprocessed transactions
-func (r *TransactionsRepository) UpdatePendingTransactions(ctx context.Context) error {
-	// Get all confirmed but unprocessed transactions
-	rows, err := r.db(ctx).Query(ctx,
-		"SELECT id, tx_hash, wallet_address, amount FROM transactions WHERE confirmed = true AND processed = false")
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil
-	}
+// creditableDeposit отбирает подтвержденные необработанные депозиты, готовые к зачислению.
+// Депозит, отклоненный AML проверкой, не зачисляется: он возвращается отправителю или ждет ручного решения.
+const creditableDeposit = `confirmed = true AND processed = false AND confirmations >= required_confirmations AND NOT on_hold
+	AND aml_status <> 'flagged'`
+
+// UpdatePendingTransactions processes all confirmed but unprocessed transactions.
+// Returns the overpayments of the credited deposits that should be refunded to their senders.
+func (r *TransactionsRepository) UpdatePendingTransactions(ctx context.Context) ([]entities.Overpayment, error) {
+	// Get all confirmed but unprocessed transactions that reached the confirmations required for their amount
+	rows, err := r.db(ctx).Query(ctx, `SELECT tx_hash FROM transactions WHERE `+creditableDeposit)
 	if err != nil {
-		return fmt.Errorf("failed to query confirmed but unprocessed transactions: %w", err)
+		return nil, fmt.Errorf("failed to query confirmed but unprocessed transactions: %w", err)
 	}
-	defer rows.Close()
-
-	transactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.ConfirmedUnprocessedTransaction])
+	txHashes, err := pgx.CollectRows(rows, pgx.RowTo[string])
 	if err != nil {
 		r.logger.Error("failed to collect confirmed unprocessed rows", "error", err)
-		return err
+		return nil, err
 	}
 
-	processed := 0
-	for _, transaction := range transactions {
-		// Parse amount
-		amount, success := new(big.Int).SetString(transaction.Amount, 10)
-		if !success {
-			r.logger.Error("Invalid amount format", "tx_hash", transaction.TxHash, "amount", transaction.Amount)
+	var overpayments []entities.Overpayment
+	for _, txHash := range txHashes {
+		// Строка, заблокированная ручным зачислением, пропускается: она будет обработана там или на следующем проходе
+		credited, overpayment, err := r.creditLocked(ctx, txHash, "FOR UPDATE SKIP LOCKED")
+		if err != nil {
+			r.logger.Error("Failed to process transaction", "error", err, "tx_hash", txHash)
 			continue
 		}
-
-		wallet, err := r.wallets.FindWalletByAddress(ctx, transaction.WalletAddress)
-		if err != nil {
-			r.logger.Error("Failed to find wallet by address", "error", err, "address", transaction.WalletAddress)
+		if !credited {
 			continue
 		}
+		if overpayment != nil {
+			overpayments = append(overpayments, *overpayment)
+		}
+		r.logger.Info("Transaction processed", "tx_hash", txHash)
+	}
 
-		// Update orders for this wallet
-		if err = r.orders.UpdateOrderStatus(ctx, wallet.ID, amount); err != nil {
-			r.logger.Error("Failed to update order status", "error", err, "tx_hash", transaction.TxHash)
-			continue
+	return overpayments, nil
+}
+
+// CreditTransaction credits the single confirmed but unprocessed transaction the same way UpdatePendingTransactions does
+// and returns its overpayment, if any. Returns false if the transaction is not confirmed, already processed, on hold,
+// rejected by the AML check or short of confirmations.
+func (r *TransactionsRepository) CreditTransaction(ctx context.Context, txHash string) (bool, *entities.Overpayment, error) {
+	credited, overpayment, err := r.creditLocked(ctx, txHash, "FOR UPDATE")
+	if err != nil {
+		return false, nil, err
+	}
+
+	if credited {
+		r.logger.Info("Transaction credited manually", "tx_hash", txHash)
+	}
+	return credited, overpayment, nil
+}
+
+// creditLocked блокирует строку депозита и зачисляет его в одной транзакции.
+// Возвращает false, если депозит не готов к зачислению, уже обработан или заблокирован (SKIP LOCKED).
+func (r *TransactionsRepository) creditLocked(ctx context.Context, txHash, lock string) (bool, *entities.Overpayment, error) {
+	credited := false
+	var overpayment *entities.Overpayment
+	err := r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		rows, err := r.db(txCtx).Query(txCtx,
+			`SELECT id, tx_hash, wallet_address, from_address, amount, COALESCE(memo, '') AS memo
+			   FROM transactions
+			  WHERE tx_hash = $1 AND `+creditableDeposit+`
+			    `+lock, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to query confirmed unprocessed transaction: %w", err)
 		}
+		defer rows.Close()
 
-		// Mark transaction as processed
-		_, err = r.db(ctx).Exec(ctx, "UPDATE transactions SET processed = true, updated_at = NOW() WHERE id = $1", transaction.Id)
+		transaction, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.ConfirmedUnprocessedTransaction])
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
 		if err != nil {
-			r.logger.Error("Failed to mark transaction as processed", "error", err, "tx_hash", transaction.TxHash)
-			continue
+			return fmt.Errorf("failed to collect confirmed unprocessed transaction: %w", err)
 		}
 
-		processed++
-		r.logger.Info("Transaction processed", "tx_hash", transaction.TxHash, "wallet", transaction.WalletAddress, "amount", transaction.Amount)
+		if overpayment, err = r.credit(txCtx, transaction); err != nil {
+			return err
+		}
+		credited = true
+		return nil
+	})
+	if errors.Is(err, errTransactionAlreadyProcessed) {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
 	}
 
-	return nil
+	return credited, overpayment, nil
+}
+
+// errTransactionAlreadyProcessed откатывает зачисление, если депозит уже обработан параллельно
+var errTransactionAlreadyProcessed = errors.New("transaction already processed")
+
+// credit зачисляет депозит в ордера кошелька и отмечает транзакцию обработанной.
+// Вызывается в транзакции с заблокированной строкой депозита.
+// Возвращает переплату, которую нужно вернуть отправителю, или nil.
+func (r *TransactionsRepository) credit(ctx context.Context, transaction entities.ConfirmedUnprocessedTransaction) (*entities.Overpayment, error) {
+	amount, success := new(big.Int).SetString(transaction.Amount, 10)
+	if !success {
+		return nil, fmt.Errorf("invalid amount format %q", transaction.Amount)
+	}
+
+	wallet, err := r.wallets.FindWalletByAddress(ctx, transaction.WalletAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find wallet by address %s: %w", transaction.WalletAddress, err)
+	}
+
+	// Update orders for this wallet
+	overpaid, err := r.orders.UpdateOrderStatus(ctx, wallet.ID, amount, transaction.Memo, transaction.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	// Mark transaction as processed, ордера откатываются вместе с транзакцией, если депозит уже зачислен
+	result, err := r.db(ctx).Exec(ctx,
+		"UPDATE transactions SET processed = true, credited_at = NOW(), updated_at = NOW() WHERE id = $1 AND processed = false",
+		transaction.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark transaction as processed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, errTransactionAlreadyProcessed
+	}
+
+	if overpaid == nil || overpaid.Sign() <= 0 || transaction.FromAddress == "" {
+		return nil, nil
+	}
+	return &entities.Overpayment{DepositTxHash: transaction.TxHash, Amount: overpaid}, nil
+}
+
+// FindTransactionByHash retrieves a transaction by its hash
+func (r *TransactionsRepository) FindTransactionByHash(ctx context.Context, txHash string) (*entities.Transaction, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, tx_hash, wallet_address, from_address, amount, block_number, confirmed, processed, aml_status, ignored_reason, created_at, updated_at
+		   FROM transactions
+		  WHERE tx_hash = $1`, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction by hash: %w", err)
+	}
+	defer rows.Close()
+
+	transaction, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Transaction])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect transaction row: %w", err)
+	}
+
+	return &transaction, nil
+}
+
+// FindConfirmationProgress returns the recorded confirmations of the transaction or nil if it is not recorded
+func (r *TransactionsRepository) FindConfirmationProgress(ctx context.Context, txHash string) (*entities.DepositConfirmations, error) {
+	var progress entities.DepositConfirmations
+	var confirmations, required int64
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT tx_hash, block_number, confirmations, required_confirmations, processed
+		   FROM transactions
+		  WHERE tx_hash = $1`, txHash).Scan(
+		&progress.TxHash, &progress.BlockNumber, &confirmations, &required, &progress.Processed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction confirmations: %w", err)
+	}
+
+	progress.Confirmations = uint64(max(confirmations, 0))
+	progress.RequiredConfirmations = uint64(max(required, 0))
+	return &progress, nil
 }
 
 // UpdateTransactionAMLStatus обновляет AML статус транзакции
@@ -168,3 +322,258 @@ func (r *TransactionsRepository) UpdateTransactionAMLStatus(ctx context.Context,
 		"status", status)
 	return nil
 }
+
+// GetWalletLedgers sums recorded deposits and completed refunds per tracked wallet
+func (r *TransactionsRepository) GetWalletLedgers(ctx context.Context) ([]entities.WalletLedger, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT w.address,
+		       COALESCE(d.deposited, 0)::TEXT,
+		       COALESCE(d.unconfirmed, 0)::TEXT,
+		       COALESCE(f.refunded, 0)::TEXT
+		  FROM wallets w
+		  LEFT JOIN (SELECT LOWER(wallet_address) AS address,
+		                    SUM(amount::NUMERIC) AS deposited,
+		                    SUM(amount::NUMERIC) FILTER (WHERE NOT confirmed) AS unconfirmed
+		               FROM transactions
+		              GROUP BY LOWER(wallet_address)) d ON d.address = LOWER(w.address)
+		  LEFT JOIN (SELECT LOWER(wallet_address) AS address, SUM(amount::NUMERIC) AS refunded
+		               FROM refunds
+		              WHERE status = 'completed'
+		              GROUP BY LOWER(wallet_address)) f ON f.address = LOWER(w.address)
+		 ORDER BY w.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet ledgers: %w", err)
+	}
+	defer rows.Close()
+
+	ledgers, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.WalletLedger])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallet ledger rows: %w", err)
+	}
+
+	return ledgers, nil
+}
+
+// GetDepositLatencies computes confirmation and crediting latency percentiles per chain for deposits detected since the given time
+func (r *TransactionsRepository) GetDepositLatencies(ctx context.Context, since time.Time) ([]entities.DepositLatency, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT w.chain, w.network, COUNT(*),
+		       PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM t.confirmed_at - t.created_at)),
+		       PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM t.confirmed_at - t.created_at)),
+		       PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM t.credited_at - t.created_at)),
+		       PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM t.credited_at - t.created_at))
+		  FROM transactions t
+		  JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		 WHERE t.created_at >= $1
+		 GROUP BY w.chain, w.network
+		 ORDER BY w.chain, w.network`,
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deposit latencies: %w", err)
+	}
+	defer rows.Close()
+
+	var latencies []entities.DepositLatency
+	for rows.Next() {
+		var latency entities.DepositLatency
+		if err = rows.Scan(&latency.Chain, &latency.Network, &latency.Deposits,
+			&latency.ConfirmationP50, &latency.ConfirmationP95, &latency.CreditP50, &latency.CreditP95); err != nil {
+			return nil, fmt.Errorf("failed to scan deposit latency row: %w", err)
+		}
+		latencies = append(latencies, latency)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deposit latency rows: %w", err)
+	}
+
+	return latencies, nil
+}
+
+// FindOverdueDeposits retrieves deposits detected before the given time that are still not credited, oldest first.
+// Held deposits and deposits rejected by the AML check are not overdue.
+func (r *TransactionsRepository) FindOverdueDeposits(ctx context.Context, detectedBefore time.Time, limit int) ([]entities.OverdueDeposit, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT t.tx_hash, COALESCE(w.chain, ''), COALESCE(w.network, ''), t.wallet_address, t.amount, t.block_number,
+		       t.confirmed, t.created_at, EXTRACT(EPOCH FROM NOW() - t.created_at)::FLOAT8
+		  FROM transactions t
+		  LEFT JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		 WHERE NOT t.processed AND NOT t.on_hold AND t.aml_status <> 'flagged' AND t.created_at < $1
+		 ORDER BY t.created_at
+		 LIMIT $2`,
+		detectedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue deposits: %w", err)
+	}
+	defer rows.Close()
+
+	deposits, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.OverdueDeposit])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect overdue deposit rows: %w", err)
+	}
+
+	return deposits, nil
+}
+
+// SummarizeDeposits counts deposits confirmed in [from, to) per asset of the wallet network.
+// Deposits ignored as dust or spam are excluded.
+func (r *TransactionsRepository) SummarizeDeposits(ctx context.Context, from, to time.Time) ([]entities.DepositSummary, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT COALESCE(a.id, 0), w.chain, w.network, COUNT(*), COALESCE(SUM(t.amount::NUMERIC), 0)::TEXT
+		  FROM transactions t
+		  JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		  LEFT JOIN assets a ON a.code = 'USDT' AND a.chain = w.chain AND a.network = w.network
+		 WHERE t.confirmed AND t.ignored_reason IS NULL AND t.confirmed_at >= $1 AND t.confirmed_at < $2
+		 GROUP BY 1, 2, 3
+		 ORDER BY 2, 3, 1`,
+		from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize deposits: %w", err)
+	}
+	defer rows.Close()
+
+	summaries, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DepositSummary])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deposit summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GetUserDepositAverage returns the number and the average amount of the user's deposits other than excludeTxHash
+func (r *TransactionsRepository) GetUserDepositAverage(ctx context.Context, userID int64, excludeTxHash string) (int, string, error) {
+	var (
+		count   int
+		average string
+	)
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(TRUNC(AVG(t.amount::NUMERIC)), 0)::TEXT
+		  FROM transactions t
+		  JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		 WHERE w.user_id = $1 AND t.tx_hash <> $2`,
+		userID, excludeTxHash).Scan(&count, &average)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get user deposit average: %w", err)
+	}
+
+	return count, average, nil
+}
+
+// HoldTransaction keeps the deposit from being credited until it is released
+func (r *TransactionsRepository) HoldTransaction(ctx context.Context, txHash, reason string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE transactions SET on_hold = true, hold_reason = $2, held_at = NOW(), updated_at = NOW()
+		  WHERE tx_hash = $1 AND NOT processed`,
+		txHash, reason)
+	if err != nil {
+		return fmt.Errorf("failed to hold transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseHold releases a held deposit for crediting. Returns false if the deposit is not on hold.
+func (r *TransactionsRepository) ReleaseHold(ctx context.Context, txHash, releasedBy string) (bool, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE transactions SET on_hold = false, released_at = NOW(), released_by = $2, updated_at = NOW()
+		  WHERE tx_hash = $1 AND on_hold`,
+		txHash, releasedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to release transaction hold: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// IgnoreTransaction marks a not yet credited deposit as ignored for order matching. The deposit stays recorded
+// and is marked processed, so it is never credited. Returns false if the deposit is already processed.
+func (r *TransactionsRepository) IgnoreTransaction(ctx context.Context, txHash string, reason entities.DepositIgnoreReason) (bool, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE transactions SET ignored_reason = $2, processed = true, updated_at = NOW()
+		  WHERE tx_hash = $1 AND NOT processed`,
+		txHash, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to ignore transaction: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// CountIdenticalDeposits returns the number of deposits of the amount from the sender recorded since the given time
+func (r *TransactionsRepository) CountIdenticalDeposits(ctx context.Context, fromAddress string, amount *big.Int, since time.Time) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COUNT(*) FROM transactions WHERE from_address = $1 AND amount = $2 AND created_at >= $3`,
+		fromAddress, amount.String(), since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count identical deposits: %w", err)
+	}
+
+	return count, nil
+}
+
+// IgnoreIdenticalDeposits marks not yet credited deposits of the amount from the sender recorded since the given time
+// as ignored for order matching. Returns the number of ignored deposits.
+func (r *TransactionsRepository) IgnoreIdenticalDeposits(ctx context.Context, fromAddress string, amount *big.Int, since time.Time, reason entities.DepositIgnoreReason) (int64, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE transactions SET ignored_reason = $4, processed = true, updated_at = NOW()
+		  WHERE from_address = $1 AND amount = $2 AND created_at >= $3 AND NOT processed`,
+		fromAddress, amount.String(), since, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to ignore identical deposits: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// CountProbedWallets returns the number of distinct deposit wallets that received a transfer below the amount
+// from the sender since the given time
+func (r *TransactionsRepository) CountProbedWallets(ctx context.Context, fromAddress string, below *big.Int, since time.Time) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COUNT(DISTINCT LOWER(wallet_address)) FROM transactions
+		  WHERE from_address = $1 AND amount::NUMERIC < $2::NUMERIC AND created_at >= $3`,
+		fromAddress, below.String(), since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count probed wallets: %w", err)
+	}
+
+	return count, nil
+}
+
+// IgnoreProbingDeposits marks not yet credited deposits below the amount from the sender recorded since the given time
+// as ignored for order matching. Returns the number of ignored deposits.
+func (r *TransactionsRepository) IgnoreProbingDeposits(ctx context.Context, fromAddress string, below *big.Int, since time.Time, reason entities.DepositIgnoreReason) (int64, error) {
+	tag, err := r.db(ctx).Exec(ctx,
+		`UPDATE transactions SET ignored_reason = $4, processed = true, updated_at = NOW()
+		  WHERE from_address = $1 AND amount::NUMERIC < $2::NUMERIC AND created_at >= $3 AND NOT processed`,
+		fromAddress, below.String(), since, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to ignore probing deposits: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// FindHeldDeposits retrieves deposits on hold, oldest first
+func (r *TransactionsRepository) FindHeldDeposits(ctx context.Context, limit int) ([]entities.DepositHold, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT t.tx_hash, COALESCE(w.user_id, 0), t.wallet_address, t.from_address, t.amount, COALESCE(t.hold_reason, ''),
+		       t.confirmed, t.held_at, t.released_at, t.released_by
+		  FROM transactions t
+		  LEFT JOIN wallets w ON LOWER(w.address) = LOWER(t.wallet_address)
+		 WHERE t.on_hold
+		 ORDER BY t.held_at
+		 LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query held deposits: %w", err)
+	}
+	defer rows.Close()
+
+	holds, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.DepositHold])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect held deposit rows: %w", err)
+	}
+
+	return holds, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMLRejectedDepositIsNotCredited(t *testing.T) {
+	pg, logger := newTestPostgres(t)
+	ctx := context.Background()
+	transactions := NewTransactionsRepository(logger, pg, NewOrdersRepository(logger, pg), NewWalletsRepository(logger, pg))
+
+	for _, tc := range []struct {
+		amlStatus string
+		credited  bool
+	}{
+		{amlStatus: "flagged", credited: false},
+		{amlStatus: "cleared", credited: true},
+	} {
+		t.Run(tc.amlStatus, func(t *testing.T) {
+			userID := randomUserID(t)
+			walletID, address := insertTestWallet(t, pg, userID)
+
+			var orderID int
+			err := pg.Pool.QueryRow(ctx,
+				`INSERT INTO orders (user_id, wallet_id, amount) VALUES ($1, $2, '10') RETURNING id`,
+				userID, walletID).Scan(&orderID)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				deleteTestOrder(pg, orderID)
+			})
+
+			txHash := randomHex(t, 32)
+			_, err = pg.Pool.Exec(ctx,
+				`INSERT INTO transactions (tx_hash, wallet_id, wallet_address, from_address, amount, block_number,
+				                           confirmed, confirmations, required_confirmations, aml_status)
+				 VALUES ($1, $2, $3, $4, '10000000000000000000', 1, true, 15, 15, $5::aml_status_type)`,
+				txHash, walletID, address, randomHex(t, 20), tc.amlStatus)
+			require.NoError(t, err)
+
+			_, err = transactions.UpdatePendingTransactions(ctx)
+			require.NoError(t, err)
+			_, _, err = transactions.CreditTransaction(ctx, txHash)
+			require.NoError(t, err)
+
+			var processed bool
+			require.NoError(t, pg.Pool.QueryRow(ctx, "SELECT processed FROM transactions WHERE tx_hash = $1", txHash).Scan(&processed))
+			assert.Equal(t, tc.credited, processed)
+
+			var status string
+			require.NoError(t, pg.Pool.QueryRow(ctx, "SELECT status FROM orders WHERE id = $1", orderID).Scan(&status))
+			if tc.credited {
+				assert.Equal(t, "completed", status)
+			} else {
+				assert.Equal(t, "pending", status)
+			}
+		})
+	}
+}
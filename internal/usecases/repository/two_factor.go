@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+// TwoFactorRepository stores TOTP secrets of users.
+type TwoFactorRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewTwoFactorRepository creates a new two-factor repository.
+func NewTwoFactorRepository(logger *slog.Logger, pg *database.Postgres) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// FindByUserID retrieves the TOTP enrollment of a user
+func (r *TwoFactorRepository) FindByUserID(ctx context.Context, userID int64) (*entities.TwoFactorSecret, error) {
+	query := `SELECT user_id, secret, enabled, last_used_step, created_at, confirmed_at
+                FROM user_two_factor
+               WHERE user_id = $1`
+
+	var secret entities.TwoFactorSecret
+	err := r.db(ctx).QueryRow(ctx, query, userID).Scan(
+		&secret.UserID,
+		&secret.Secret,
+		&secret.Enabled,
+		&secret.LastUsedStep,
+		&secret.CreatedAt,
+		&secret.ConfirmedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query two-factor secret: %w", err)
+	}
+
+	return &secret, nil
+}
+
+// UpsertPendingSecret stores a new, not yet confirmed secret. Confirmed enrollments are never overwritten.
+func (r *TwoFactorRepository) UpsertPendingSecret(ctx context.Context, userID int64, secret string) error {
+	result, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO user_two_factor (user_id, secret, enabled) VALUES ($1, $2, false)
+		 ON CONFLICT (user_id) DO UPDATE SET secret = $2, last_used_step = 0, created_at = NOW()
+		 WHERE user_two_factor.enabled = false`,
+		userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to store two-factor secret: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled for user %d", userID)
+	}
+
+	return nil
+}
+
+// Enable marks the enrollment as confirmed
+func (r *TwoFactorRepository) Enable(ctx context.Context, userID int64, step int64) error {
+	_, err := r.db(ctx).Exec(ctx,
+		"UPDATE user_two_factor SET enabled = true, last_used_step = $2, confirmed_at = NOW() WHERE user_id = $1",
+		userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	r.logger.InfoContext(ctx, "Two-factor authentication enabled", "user_id", userID)
+	return nil
+}
+
+// UpdateLastUsedStep stores the last accepted time step. It only moves forward, which rejects replayed codes.
+func (r *TwoFactorRepository) UpdateLastUsedStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		"UPDATE user_two_factor SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2",
+		userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to update last used two-factor step: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// UpdateAdminLastUsedStep stores the step of the operator's code. Returns false if the step was already used.
+func (r *TwoFactorRepository) UpdateAdminLastUsedStep(ctx context.Context, operator string, step int64) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO admin_two_factor_steps (operator, last_used_step) VALUES ($1, $2)
+		 ON CONFLICT (operator) DO UPDATE SET last_used_step = EXCLUDED.last_used_step, updated_at = NOW()
+		  WHERE admin_two_factor_steps.last_used_step < EXCLUDED.last_used_step`,
+		operator, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to update last used admin two-factor step: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
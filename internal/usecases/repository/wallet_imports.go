@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const walletImportColumns = `id, source, status, from_block, wallets_total, wallets_imported, deposits_found,
+	requested_by, error, created_at, completed_at`
+
+// WalletImportsRepository stores wallet imports and the historical deposits found for imported wallets.
+type WalletImportsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewWalletImportsRepository creates a new wallet imports repository.
+func NewWalletImportsRepository(logger *slog.Logger, pg *database.Postgres) *WalletImportsRepository {
+	return &WalletImportsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// CreateImport stores the import together with the wallets whose history must be backfilled
+func (r *WalletImportsRepository) CreateImport(ctx context.Context, walletImport *entities.WalletImport, walletIDs []int) error {
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := r.db(ctx).QueryRow(ctx,
+			`INSERT INTO wallet_imports (source, status, from_block, wallets_total, wallets_imported, requested_by)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 RETURNING id, created_at`,
+			walletImport.Source, entities.WalletImportPending, walletImport.FromBlock, walletImport.WalletsTotal,
+			walletImport.WalletsImported, walletImport.RequestedBy).Scan(&walletImport.ID, &walletImport.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert wallet import: %w", err)
+		}
+		walletImport.Status = entities.WalletImportPending
+
+		for _, walletID := range walletIDs {
+			if _, err = r.db(ctx).Exec(ctx,
+				`INSERT INTO wallet_import_items (import_id, wallet_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+				walletImport.ID, walletID); err != nil {
+				return fmt.Errorf("failed to insert wallet import item: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindImport retrieves an import by ID, nil if it does not exist
+func (r *WalletImportsRepository) FindImport(ctx context.Context, id int64) (*entities.WalletImport, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+walletImportColumns+` FROM wallet_imports WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet import: %w", err)
+	}
+	defer rows.Close()
+
+	walletImport, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[entities.WalletImport])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallet import: %w", err)
+	}
+
+	return walletImport, nil
+}
+
+// FindImports retrieves the latest imports
+func (r *WalletImportsRepository) FindImports(ctx context.Context, limit int) ([]entities.WalletImport, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+walletImportColumns+` FROM wallet_imports ORDER BY id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.WalletImport])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallet imports: %w", err)
+	}
+
+	return imports, nil
+}
+
+// ClaimPendingImport marks the oldest unfinished import as running and returns it. Imports left running
+// by a stopped instance are claimed again, backfilled deposits are recorded once.
+func (r *WalletImportsRepository) ClaimPendingImport(ctx context.Context) (*entities.WalletImport, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`UPDATE wallet_imports SET status = $1
+		  WHERE id = (SELECT id FROM wallet_imports
+		               WHERE status IN ($2, $1)
+		               ORDER BY id
+		               LIMIT 1
+		               FOR UPDATE SKIP LOCKED)
+		 RETURNING `+walletImportColumns,
+		entities.WalletImportRunning, entities.WalletImportPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim wallet import: %w", err)
+	}
+	defer rows.Close()
+
+	walletImport, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[entities.WalletImport])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallet import: %w", err)
+	}
+
+	return walletImport, nil
+}
+
+// FindImportWallets retrieves the wallets of the import
+func (r *WalletImportsRepository) FindImportWallets(ctx context.Context, importID int64) ([]entities.Wallet, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+walletColumns+`
+		   FROM wallets
+		  WHERE id IN (SELECT wallet_id FROM wallet_import_items WHERE import_id = $1)
+		  ORDER BY id`,
+		importID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets of import %d: %w", importID, err)
+	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Wallet])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallets of import %d: %w", importID, err)
+	}
+
+	return wallets, nil
+}
+
+// FinishImport stores the outcome of the backfill, a nil reason completes the import.
+// Deposits are counted from the recorded history, so an interrupted and resumed backfill counts each once.
+func (r *WalletImportsRepository) FinishImport(ctx context.Context, id int64, reason *string) (*entities.WalletImport, error) {
+	status := entities.WalletImportCompleted
+	if reason != nil {
+		status = entities.WalletImportFailed
+	}
+
+	rows, err := r.db(ctx).Query(ctx,
+		`UPDATE wallet_imports
+		    SET status = $2, error = $3, completed_at = NOW(),
+		        deposits_found = (SELECT COUNT(*) FROM transactions t
+		                            JOIN wallet_import_items i ON i.wallet_id = t.wallet_id
+		                           WHERE i.import_id = $1 AND t.imported)
+		  WHERE id = $1
+		 RETURNING `+walletImportColumns,
+		id, status, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish wallet import %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	walletImport, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[entities.WalletImport])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallet import %d: %w", id, err)
+	}
+
+	return walletImport, nil
+}
+
+// RecordHistoricalDeposit stores a deposit received by an imported wallet before the import. The deposit
+// is stored as confirmed and processed, so it is never credited to an order. Returns false if the
+// transaction is already recorded.
+func (r *WalletImportsRepository) RecordHistoricalDeposit(ctx context.Context, wallet entities.Wallet, txHash common.Hash, fromAddress string, amount *big.Int, blockNumber int64) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO transactions (tx_hash, wallet_id, wallet_address, from_address, amount, block_number,
+		                           confirmed, confirmed_at, processed, required_confirmations, imported, is_test)
+		 VALUES ($1, $2, $3, $4, $5, $6, true, NOW(), true, 0, true, $7)
+		 ON CONFLICT (tx_hash) DO NOTHING`,
+		txHash.Hex(), wallet.ID, wallet.Address, fromAddress, amount.String(), blockNumber, wallet.IsTestnet)
+	if err != nil {
+		return false, fmt.Errorf("failed to record historical deposit %s: %w", txHash.Hex(), err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
+	"strings"
 	"time"
 
 	tx "github.com/Thiht/transactor/pgx"
@@ -13,6 +15,8 @@ import (
 	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
 )
 
+const walletColumns = `id, user_id, address, derivation_path, wallet_index, created_at, is_testnet, chain, network, address_format, key_version, archived_at, retired_at`
+
 // WalletsRepository handles wallet tracking and management.
 type WalletsRepository struct {
 	logger     *slog.Logger
@@ -29,14 +33,30 @@ func NewWalletsRepository(logger *slog.Logger, pg *database.Postgres) *WalletsRe
 	}
 }
 
-// FindWalletByAddress retrieves a wallet by its address.
+// FindWalletByAddress retrieves a wallet by its address on any chain.
+// EVM addresses may be tracked on several chains, the earliest wallet is returned.
 func (r *WalletsRepository) FindWalletByAddress(ctx context.Context, address string) (*entities.Wallet, error) {
-	query := `SELECT id, user_id, address, derivation_path, wallet_index, created_at, is_testnet 
+	query := `SELECT ` + walletColumns + `
+              FROM wallets 
+              WHERE address = $1
+              ORDER BY id
+              LIMIT 1`
+
+	return r.findWallet(ctx, query, address)
+}
+
+// FindWalletByChainAddress retrieves a wallet by its address on the given chain and network.
+func (r *WalletsRepository) FindWalletByChainAddress(ctx context.Context, chain entities.Chain, network, address string) (*entities.Wallet, error) {
+	query := `SELECT ` + walletColumns + `
               FROM wallets 
-              WHERE address = $1`
+              WHERE chain = $1 AND network = $2 AND address = $3`
 
+	return r.findWallet(ctx, query, chain, network, address)
+}
+
+func (r *WalletsRepository) findWallet(ctx context.Context, query string, args ...any) (*entities.Wallet, error) {
 	var wallet entities.Wallet
-	err := r.db(ctx).QueryRow(ctx, query, address).Scan(
+	err := r.db(ctx).QueryRow(ctx, query, args...).Scan(
 		&wallet.ID,
 		&wallet.UserID,
 		&wallet.Address,
@@ -44,13 +64,19 @@ func (r *WalletsRepository) FindWalletByAddress(ctx context.Context, address str
 		&wallet.WalletIndex,
 		&wallet.CreatedAt,
 		&wallet.IsTestnet,
+		&wallet.Chain,
+		&wallet.Network,
+		&wallet.AddressFormat,
+		&wallet.KeyVersion,
+		&wallet.ArchivedAt,
+		&wallet.RetiredAt,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query wallet by wallet address: %w", err)
+		return nil, fmt.Errorf("failed to query wallet: %w", err)
 	}
 
 	return &wallet, nil
@@ -58,45 +84,49 @@ func (r *WalletsRepository) FindWalletByAddress(ctx context.Context, address str
 
 // FindWalletByID retrieves a wallet by its id.
 func (r *WalletsRepository) FindWalletByID(ctx context.Context, id int) (*entities.Wallet, error) {
-	query := `SELECT id, user_id, address, derivation_path, wallet_index, created_at, is_testnet 
+	query := `SELECT ` + walletColumns + `
               FROM wallets 
               WHERE id = $1`
 
-	var wallet entities.Wallet
-	err := r.db(ctx).QueryRow(ctx, query, id).Scan(
-		&wallet.ID,
-		&wallet.UserID,
-		&wallet.Address,
-		&wallet.DerivationPath,
-		&wallet.WalletIndex,
-		&wallet.CreatedAt,
-		&wallet.IsTestnet,
-	)
+	return r.findWallet(ctx, query, id)
+}
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
+// FindWalletsByIDsOrAddresses retrieves the wallets with any of the IDs or addresses, retired ones included
+func (r *WalletsRepository) FindWalletsByIDsOrAddresses(ctx context.Context, ids []int, addresses []string) ([]entities.Wallet, error) {
+	query := `SELECT ` + walletColumns + `
+              FROM wallets 
+             WHERE id = ANY($1) OR address = ANY($2)
+              ORDER BY id`
+
+	rows, err := r.db(ctx).Query(ctx, query, ids, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets by ids or addresses: %w", err)
 	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Wallet])
 	if err != nil {
-		return nil, fmt.Errorf("failed to query wallet by wallet id: %w", err)
+		return nil, fmt.Errorf("failed to collect wallets by ids or addresses: %w", err)
 	}
 
-	return &wallet, nil
+	return wallets, nil
 }
 
-// IsWalletTracked checks if the given address is tracked by our system.
+// IsWalletTracked checks if the given address is tracked by our system on any chain. Retired wallets are not tracked.
 func (r *WalletsRepository) IsWalletTracked(ctx context.Context, address string) (bool, error) {
 	var exists bool
-	err := r.db(ctx).QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM wallets WHERE address = $1)", address).Scan(&exists)
+	err := r.db(ctx).QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM wallets WHERE address = $1 AND retired_at IS NULL)", address).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if wallet exists: %w", err)
 	}
 	return exists, nil
 }
 
-// GetAllTrackedWallets retrieves all tracked wallet addresses.
+// GetAllTrackedWallets retrieves all tracked wallet addresses except retired ones.
 func (r *WalletsRepository) GetAllTrackedWallets(ctx context.Context) ([]entities.Wallet, error) {
-	query := `SELECT id, user_id, address, derivation_path, wallet_index, created_at, is_testnet 
+	query := `SELECT ` + walletColumns + `
               FROM wallets 
+             WHERE retired_at IS NULL
               ORDER BY id`
 
 	rows, err := r.db(ctx).Query(ctx, query)
@@ -117,52 +147,392 @@ func (r *WalletsRepository) GetAllTrackedWallets(ctx context.Context) ([]entitie
 	return wallets, nil
 }
 
-// GetLastWalletIndexForUser retrieves the last used wallet index for a specific user
-func (r *WalletsRepository) GetLastWalletIndexForUser(ctx context.Context, userID int64) (uint32, error) {
-	var lastIndex uint32
+// FindTrackedWalletsPage retrieves up to limit tracked wallets with ID above afterID except retired ones, in ID order.
+// The last ID is the cursor of the next page.
+func (r *WalletsRepository) FindTrackedWalletsPage(ctx context.Context, afterID, limit int) ([]entities.Wallet, error) {
+	return r.findWallets(ctx, `SELECT `+walletColumns+`
+              FROM wallets 
+             WHERE retired_at IS NULL AND id > $1
+              ORDER BY id
+             LIMIT $2`, afterID, limit)
+}
+
+// FindRecentTrackedWallets retrieves up to limit most recently created tracked wallets except retired ones
+func (r *WalletsRepository) FindRecentTrackedWallets(ctx context.Context, limit int) ([]entities.Wallet, error) {
+	return r.findWallets(ctx, `SELECT `+walletColumns+`
+              FROM wallets 
+             WHERE retired_at IS NULL
+              ORDER BY id DESC
+             LIMIT $1`, limit)
+}
+
+func (r *WalletsRepository) findWallets(ctx context.Context, query string, args ...any) ([]entities.Wallet, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Wallet])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// FindAddressesWithFrozenFunds returns the lowercased addresses of deposit wallets whose funds must stay in place:
+// wallets with an unfinished refund, a held deposit or an AML-rejected deposit that was not refunded yet
+func (r *WalletsRepository) FindAddressesWithFrozenFunds(ctx context.Context) (map[string]bool, error) {
+	query := `SELECT wallet_address FROM refunds WHERE status <> 'completed'
+              UNION
+              SELECT wallet_address FROM transactions WHERE on_hold
+              UNION
+              SELECT t.wallet_address
+                FROM transactions t
+               WHERE t.aml_status = 'flagged'
+                 AND NOT EXISTS (
+                     SELECT 1 FROM refunds rf WHERE rf.deposit_tx_hash = t.tx_hash AND rf.status = 'completed'
+                 )`
+
+	rows, err := r.db(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets with frozen funds: %w", err)
+	}
+	defer rows.Close()
+
+	addresses := make(map[string]bool)
+	for rows.Next() {
+		var address string
+		if err = rows.Scan(&address); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet with frozen funds: %w", err)
+		}
+		addresses[strings.ToLower(address)] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate wallets with frozen funds: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// SaveWalletBalances upserts the latest balances of the wallets and returns the statuses stored before,
+// by address. Addresses must be unique.
+func (r *WalletsRepository) SaveWalletBalances(ctx context.Context, balances []entities.WalletBalance) (map[string]entities.BalanceStatus, error) {
+	addresses := make([]string, len(balances))
+	tokens := make([]string, len(balances))
+	natives := make([]string, len(balances))
+	statuses := make([]string, len(balances))
+	checkedAt := make([]time.Time, len(balances))
+	for i, balance := range balances {
+		addresses[i] = balance.Address
+		tokens[i] = balance.TokenBalance.String()
+		natives[i] = balance.NativeBalance.String()
+		statuses[i] = string(balance.Status)
+		checkedAt[i] = balance.LastChecked
+	}
+
+	// CTE previous читает снимок до вставки, поэтому возвращает прежние статусы
+	rows, err := r.db(ctx).Query(ctx,
+		`WITH input AS (
+		     SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::timestamptz[])
+		         AS i(address, token_balance, native_balance, status, checked_at)
+		 ), previous AS (
+		     SELECT b.address, b.status FROM wallet_balances b JOIN input USING (address)
+		 ), saved AS (
+		     INSERT INTO wallet_balances (address, token_balance, native_balance, status, checked_at)
+		     SELECT address, token_balance::numeric, native_balance::numeric, status, checked_at FROM input
+		     ON CONFLICT (address) DO UPDATE
+		        SET token_balance = EXCLUDED.token_balance, native_balance = EXCLUDED.native_balance,
+		            status = EXCLUDED.status, checked_at = EXCLUDED.checked_at
+		 )
+		 SELECT address, status FROM previous`,
+		addresses, tokens, natives, statuses, checkedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	previous := make(map[string]entities.BalanceStatus)
+	for rows.Next() {
+		var address string
+		var status entities.BalanceStatus
+		if err = rows.Scan(&address, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan previous wallet balance: %w", err)
+		}
+		previous[address] = status
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to save wallet balances: %w", err)
+	}
+
+	return previous, nil
+}
+
+// FindWalletBalances returns the stored balances of the wallets with the addresses, by address
+func (r *WalletsRepository) FindWalletBalances(ctx context.Context, addresses []string) (map[string]*entities.WalletBalance, error) {
+	found, err := r.findWalletBalances(ctx,
+		`SELECT address, token_balance::text, native_balance::text, status, checked_at
+		   FROM wallet_balances
+		  WHERE address = ANY($1)`, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]*entities.WalletBalance, len(found))
+	for i := range found {
+		balances[found[i].Address] = &found[i]
+	}
+	return balances, nil
+}
+
+// FindWalletBalancesPage returns up to limit stored balances of tracked wallets ordered by address,
+// starting after afterAddress
+func (r *WalletsRepository) FindWalletBalancesPage(ctx context.Context, afterAddress string, limit int) ([]entities.WalletBalance, error) {
+	return r.findWalletBalances(ctx,
+		`SELECT b.address, b.token_balance::text, b.native_balance::text, b.status, b.checked_at
+		   FROM wallet_balances b
+		  WHERE b.address > $1
+		    AND EXISTS (SELECT 1 FROM wallets w WHERE w.address = b.address AND w.retired_at IS NULL)
+		  ORDER BY b.address
+		  LIMIT $2`, afterAddress, limit)
+}
+
+func (r *WalletsRepository) findWalletBalances(ctx context.Context, query string, args ...any) ([]entities.WalletBalance, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []entities.WalletBalance
+	for rows.Next() {
+		var balance entities.WalletBalance
+		var token, native string
+		if err = rows.Scan(&balance.Address, &token, &native, &balance.Status, &balance.LastChecked); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet balance: %w", err)
+		}
+
+		var ok bool
+		if balance.TokenBalance, ok = new(big.Int).SetString(token, 10); !ok {
+			return nil, fmt.Errorf("invalid token balance %q of wallet %s", token, balance.Address)
+		}
+		if balance.NativeBalance, ok = new(big.Int).SetString(native, 10); !ok {
+			return nil, fmt.Errorf("invalid native balance %q of wallet %s", native, balance.Address)
+		}
+		balances = append(balances, balance)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wallet balances: %w", err)
+	}
+
+	return balances, nil
+}
+
+// FindChainWalletsCreatedSince retrieves active wallets of the chain and network created since the time
+// with ID above afterID, oldest first. The ID is the cursor of callers polling for new wallets.
+func (r *WalletsRepository) FindChainWalletsCreatedSince(ctx context.Context, chain entities.Chain, network string, since time.Time, afterID, limit int) ([]entities.Wallet, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+walletColumns+`
+		   FROM wallets
+		  WHERE chain = $1 AND network = $2 AND created_at >= $3 AND id > $4 AND retired_at IS NULL
+		  ORDER BY id
+		  LIMIT $5`,
+		chain, network, since, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query new %s wallets: %w", chain, err)
+	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Wallet])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect new %s wallets: %w", chain, err)
+	}
+
+	return wallets, nil
+}
+
+// NextWalletIndex atomically allocates the next wallet index of the user on the chain and network.
+// The counter row is locked by the upsert, so concurrent callers on any instance get distinct indexes.
+// A missing counter starts after the highest index already used by the user's wallets.
+func (r *WalletsRepository) NextWalletIndex(ctx context.Context, chain entities.Chain, network string, userID int64) (uint32, error) {
+	var index uint32
 
 	err := r.db(ctx).QueryRow(ctx,
-		"SELECT COALESCE(MAX(wallet_index), 0) FROM wallets WHERE user_id = $1",
-		userID).Scan(&lastIndex)
+		`INSERT INTO wallet_index_counters (chain, network, user_id, last_index)
+		 VALUES ($1, $2, $3, (SELECT COALESCE(MAX(wallet_index), 0) + 1 FROM wallets WHERE chain = $1 AND network = $2 AND user_id = $3))
+		 ON CONFLICT (chain, network, user_id) DO UPDATE
+		    SET last_index = wallet_index_counters.last_index + 1, updated_at = NOW()
+		 RETURNING last_index`,
+		chain, network, userID).Scan(&index)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate wallet index for user %d: %w", userID, err)
+	}
+	return index, nil
+}
 
+// ReserveWalletIndex moves the index counter of the user past the given index, so wallets generated later
+// do not collide with a wallet tracked with an explicit index.
+func (r *WalletsRepository) ReserveWalletIndex(ctx context.Context, chain entities.Chain, network string, userID int64, index uint32) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO wallet_index_counters (chain, network, user_id, last_index)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (chain, network, user_id) DO UPDATE
+		    SET last_index = GREATEST(wallet_index_counters.last_index, EXCLUDED.last_index), updated_at = NOW()`,
+		chain, network, userID, index)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get last wallet index for user %d: %w", userID, err)
+		return fmt.Errorf("failed to reserve wallet index %d for user %d: %w", index, userID, err)
 	}
-	return lastIndex, nil
+	return nil
+}
+
+// TrackWallet adds a wallet to the tracking system. The address is validated against the wallet address format;
+// an empty format defaults to the format of the chain.
+func (r *WalletsRepository) TrackWallet(ctx context.Context, wallet *entities.Wallet) (int, error) {
+	return r.trackWallet(ctx, wallet, nil)
 }
 
-// TrackWalletWithUserAndIndex adds a wallet address to the tracking system with a specific user and index
-func (r *WalletsRepository) TrackWalletWithUserAndIndex(ctx context.Context, address string, derivationPath string, userID int64, index uint32, isTestnet bool) (int, error) {
+// TrackPoolWallet adds an unclaimed wallet of the tier to the pool. It is tracked like any other wallet,
+// but belongs to the pool account until an order claims it.
+func (r *WalletsRepository) TrackPoolWallet(ctx context.Context, wallet *entities.Wallet, tier string) (int, error) {
+	return r.trackWallet(ctx, wallet, &tier)
+}
+
+func (r *WalletsRepository) trackWallet(ctx context.Context, wallet *entities.Wallet, poolTier *string) (int, error) {
+	if wallet.AddressFormat == "" {
+		format, err := wallet.Chain.DefaultAddressFormat()
+		if err != nil {
+			return 0, err
+		}
+		wallet.AddressFormat = format
+	}
+	if err := entities.ValidateAddress(wallet.AddressFormat, wallet.Address); err != nil {
+		return 0, err
+	}
+	// Кошельки без ключа (форвардеры, TON) и выведенные до ротации сидов относятся к исходному сиду
+	if wallet.KeyVersion == 0 {
+		wallet.KeyVersion = entities.LegacyKeyVersion
+	}
+
 	// Check if wallet already exists
-	exists, err := r.IsWalletTracked(ctx, address)
+	existing, err := r.FindWalletByChainAddress(ctx, wallet.Chain, wallet.Network, wallet.Address)
 	if err != nil {
 		return 0, err
 	}
 
-	if exists {
-		r.logger.DebugContext(ctx, "Wallet already tracked", "address", address)
+	if existing != nil {
+		r.logger.DebugContext(ctx, "Wallet already tracked", "address", wallet.Address, "chain", wallet.Chain)
 		return 0, nil
 	}
 
-	var id int
+	wallet.IsTestnet = wallet.Network != entities.NetworkMainnet
+	wallet.CreatedAt = time.Now()
+
 	// Insert new wallet with user ID and index
 	err = r.db(ctx).QueryRow(ctx,
-		"INSERT INTO wallets (address, derivation_path, user_id, wallet_index, created_at, is_testnet) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		address, derivationPath, userID, index, time.Now(), isTestnet).Scan(&id)
+		`INSERT INTO wallets (address, derivation_path, user_id, wallet_index, created_at, is_testnet, chain, network, address_format, key_version, pool_tier)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		wallet.Address, wallet.DerivationPath, wallet.UserID, wallet.WalletIndex, wallet.CreatedAt, wallet.IsTestnet,
+		wallet.Chain, wallet.Network, wallet.AddressFormat, wallet.KeyVersion, poolTier).Scan(&wallet.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert wallet: %w", constraintError(err))
+	}
+
+	r.logger.InfoContext(ctx, "Wallet added to tracking",
+		"address", wallet.Address,
+		"chain", wallet.Chain,
+		"network", wallet.Network,
+		"user", wallet.UserID,
+		"index", wallet.WalletIndex)
+	return wallet.ID, nil
+}
+
+// CountPoolWallets returns the number of unclaimed pool wallets of the tier on the chain and network
+func (r *WalletsRepository) CountPoolWallets(ctx context.Context, chain entities.Chain, network, tier string) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COUNT(*) FROM wallets
+		  WHERE chain = $1 AND network = $2 AND pool_tier = $3 AND pool_claimed_at IS NULL AND retired_at IS NULL`,
+		chain, network, tier).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pool wallets of tier %s: %w", tier, err)
+	}
+
+	return count, nil
+}
+
+// ClaimPoolWallet atomically assigns the oldest unclaimed pool wallet of the tier to the user with the next
+// wallet index of the user. Concurrent claims skip locked rows, so each wallet is claimed once.
+// Returns nil if the pool of the tier is empty.
+func (r *WalletsRepository) ClaimPoolWallet(ctx context.Context, chain entities.Chain, network, tier string, userID int64) (*entities.Wallet, error) {
+	var claimed *entities.Wallet
+	err := r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var id int
+		err := r.db(txCtx).QueryRow(txCtx,
+			`SELECT id FROM wallets
+			  WHERE chain = $1 AND network = $2 AND pool_tier = $3 AND pool_claimed_at IS NULL AND retired_at IS NULL
+			  ORDER BY id
+			  LIMIT 1
+			  FOR UPDATE SKIP LOCKED`,
+			chain, network, tier).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock pool wallet: %w", err)
+		}
+
+		index, err := r.NextWalletIndex(txCtx, chain, network, userID)
+		if err != nil {
+			return err
+		}
+
+		// Срок жизни кошелька (сборка неиспользуемых, неактивные остатки) отсчитывается от выдачи пользователю
+		claimed, err = r.findWallet(txCtx,
+			`UPDATE wallets
+			    SET user_id = $2, wallet_index = $3, pool_claimed_at = NOW(), created_at = NOW()
+			  WHERE id = $1
+			  RETURNING `+walletColumns,
+			id, userID, index)
+		if err != nil {
+			return fmt.Errorf("failed to claim pool wallet %d: %w", id, constraintError(err))
+		}
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to insert wallet: %w", err)
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// ReleasePoolWallet returns a claimed pool wallet to the pool account with its original derivation index, so the
+// next claim issues it again. Only a wallet that never received a transfer and has no orders other than
+// cancelled ones is released. Returns false if the wallet is not such a pool wallet.
+func (r *WalletsRepository) ReleasePoolWallet(ctx context.Context, walletID int, account int64) (bool, error) {
+	// Путь кошелька пула m/44'/60'/account'/0/index: шестой сегмент — индекс в аккаунте пула
+	result, err := r.db(ctx).Exec(ctx,
+		`UPDATE wallets w
+		    SET user_id = $2, wallet_index = split_part(w.derivation_path, '/', 6)::INTEGER, pool_claimed_at = NULL
+		  WHERE w.id = $1 AND w.pool_tier IS NOT NULL AND w.pool_claimed_at IS NOT NULL
+		    AND w.archived_at IS NULL AND w.retired_at IS NULL
+		    AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.wallet_id = w.id AND o.status <> 'cancelled')
+		    AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.wallet_address = w.address)`,
+		walletID, account)
+	if err != nil {
+		return false, fmt.Errorf("failed to release pool wallet %d: %w", walletID, constraintError(err))
 	}
 
-	r.logger.InfoContext(ctx, "Wallet added to tracking", "address", address, "user", userID, "index", index)
-	return id, nil
+	return result.RowsAffected() > 0, nil
 }
 
 // GetAllTrackedWalletsForUser retrieves all tracked wallet addresses for a specific user.
 func (r *WalletsRepository) GetAllTrackedWalletsForUser(ctx context.Context, userID int64) ([]entities.Wallet, error) {
-	query := `SELECT id, user_id, address, derivation_path, wallet_index, created_at, is_testnet 
+	query := `SELECT ` + walletColumns + `
               FROM wallets 
               WHERE user_id = $1
-              ORDER BY wallet_index`
+              ORDER BY chain, network, wallet_index`
 
 	rows, err := r.db(ctx).Query(ctx, query, userID)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -182,11 +552,12 @@ func (r *WalletsRepository) GetAllTrackedWalletsForUser(ctx context.Context, use
 	return wallets, nil
 }
 
-// DeleteWallet removes a wallet from the tracking system
+// DeleteWallet removes a wallet from the tracking system.
+// Returns ErrForeignKeyViolation if orders or transactions still reference the wallet.
 func (r *WalletsRepository) DeleteWallet(ctx context.Context, id int) error {
 	result, err := r.db(ctx).Exec(ctx, "DELETE FROM wallets WHERE id = $1", id)
 	if err != nil {
-		return fmt.Errorf("failed to delete wallet: %w", err)
+		return fmt.Errorf("failed to delete wallet: %w", constraintError(err))
 	}
 
 	if result.RowsAffected() == 0 {
@@ -196,3 +567,95 @@ func (r *WalletsRepository) DeleteWallet(ctx context.Context, id int) error {
 	r.logger.InfoContext(ctx, "Wallet deleted from tracking", "wallet_id", id)
 	return nil
 }
+
+// CountUnusedWallets returns the number of user wallets that never received a transaction
+func (r *WalletsRepository) CountUnusedWallets(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COUNT(*)
+		   FROM wallets w
+		  WHERE w.user_id = $1
+		    AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.wallet_address = w.address)`,
+		userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unused wallets for user %d: %w", userID, err)
+	}
+
+	return count, nil
+}
+
+// ArchiveUserWallets marks all wallets of the user as archived. Archived wallets stay tracked for late deposits.
+func (r *WalletsRepository) ArchiveUserWallets(ctx context.Context, userID int64) (int, error) {
+	result, err := r.db(ctx).Exec(ctx,
+		"UPDATE wallets SET archived_at = NOW() WHERE user_id = $1 AND archived_at IS NULL",
+		userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive wallets of user %d: %w", userID, err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// FindReusableWallet returns the latest active wallet of a repeat customer that has no pending order
+// and no uncredited deposit. Returns nil if the user has fewer completed orders or no such wallet.
+func (r *WalletsRepository) FindReusableWallet(ctx context.Context, chain entities.Chain, network string, userID int64, minCompletedOrders int) (*entities.Wallet, error) {
+	return r.findWallet(ctx,
+		`SELECT `+walletColumns+`
+		   FROM wallets w
+		  WHERE w.user_id = $1 AND w.chain = $2 AND w.network = $3
+		    AND w.archived_at IS NULL AND w.retired_at IS NULL
+		    AND (SELECT COUNT(*) FROM orders o WHERE o.user_id = $1 AND o.status = 'completed') >= $4
+		    AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.wallet_id = w.id AND o.status = 'pending')
+		    AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.wallet_address = w.address AND NOT t.processed)
+		  ORDER BY w.created_at DESC
+		  LIMIT 1`,
+		userID, chain, network, minCompletedOrders)
+}
+
+// FindRetirementCandidates retrieves active wallets idle since idleBefore: created earlier, without pending orders,
+// uncredited deposits or deposits after idleBefore. Unclaimed pool wallets and omnibus wallets are kept.
+// The balance is checked by the caller.
+func (r *WalletsRepository) FindRetirementCandidates(ctx context.Context, idleBefore time.Time, limit int) ([]entities.Wallet, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+walletColumns+`
+		   FROM wallets w
+		  WHERE w.retired_at IS NULL AND w.created_at < $1
+		    AND (w.pool_tier IS NULL OR w.pool_claimed_at IS NOT NULL) AND NOT w.omnibus
+		    AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.wallet_id = w.id AND o.status = 'pending')
+		    AND NOT EXISTS (SELECT 1 FROM transactions t
+		                     WHERE t.wallet_address = w.address AND (NOT t.processed OR t.created_at >= $1))
+		  ORDER BY w.created_at
+		  LIMIT $2`,
+		idleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets for retirement: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[entities.Wallet])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect wallets for retirement: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// RetireWallet removes the wallet from active monitoring
+func (r *WalletsRepository) RetireWallet(ctx context.Context, id int) error {
+	_, err := r.db(ctx).Exec(ctx, "UPDATE wallets SET retired_at = NOW() WHERE id = $1 AND retired_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to retire wallet %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// MarkOmnibusWallet marks the wallet as omnibus: its orders are completed only by an attributed deposit
+func (r *WalletsRepository) MarkOmnibusWallet(ctx context.Context, id int) error {
+	_, err := r.db(ctx).Exec(ctx, "UPDATE wallets SET omnibus = TRUE WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to mark wallet %d as omnibus: %w", id, err)
+	}
+
+	return nil
+}
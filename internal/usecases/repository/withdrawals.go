@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/database"
+)
+
+const withdrawalColumns = `id, user_id, wallet_id, to_address, amount, hot, status, batch_id, tx_hash, safe_tx_hash, error,
+                           initiated_by, created_at, updated_at`
+
+const withdrawalBatchColumns = `id, token, status, withdrawals, amount, tx_hash, error, created_at, updated_at`
+
+// WithdrawalsRepository stores user withdrawals, their multisend batches and withdrawal limit tiers.
+type WithdrawalsRepository struct {
+	logger     *slog.Logger
+	db         tx.DBGetter
+	transactor *tx.Transactor
+}
+
+// NewWithdrawalsRepository creates a new withdrawals repository.
+func NewWithdrawalsRepository(logger *slog.Logger, pg *database.Postgres) *WithdrawalsRepository {
+	return &WithdrawalsRepository{
+		logger:     logger,
+		db:         pg.DBGetter,
+		transactor: pg.Transactor,
+	}
+}
+
+// WithinLimitsLock runs fn in a transaction holding the withdrawal limits lock,
+// so concurrent withdrawals cannot both pass the check against the same usage
+func (r *WithdrawalsRepository) WithinLimitsLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := r.db(txCtx).Exec(txCtx, "SELECT pg_advisory_xact_lock(hashtext('withdrawal_limits'))"); err != nil {
+			return fmt.Errorf("failed to acquire withdrawal limits lock: %w", err)
+		}
+		return fn(txCtx)
+	})
+}
+
+// GetUsage sums withdrawals that are not failed: of the user and of the hot wallets, since hourSince and daySince
+func (r *WithdrawalsRepository) GetUsage(ctx context.Context, userID int64, hourSince, daySince time.Time) (*entities.WithdrawalUsage, error) {
+	var usage entities.WithdrawalUsage
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount::NUMERIC) FILTER (WHERE user_id = $1 AND created_at >= $2), 0)::TEXT,
+		        COALESCE(SUM(amount::NUMERIC) FILTER (WHERE user_id = $1), 0)::TEXT,
+		        COALESCE(SUM(amount::NUMERIC) FILTER (WHERE hot AND created_at >= $2), 0)::TEXT,
+		        COALESCE(SUM(amount::NUMERIC) FILTER (WHERE hot), 0)::TEXT
+		   FROM withdrawals
+		  WHERE created_at >= $3 AND status <> 'failed'`,
+		userID, hourSince, daySince,
+	).Scan(&usage.UserHourly, &usage.UserDaily, &usage.GlobalHourly, &usage.GlobalDaily)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// CreateWithdrawal inserts a pending withdrawal
+func (r *WithdrawalsRepository) CreateWithdrawal(ctx context.Context, withdrawal *entities.Withdrawal) error {
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO withdrawals (id, user_id, wallet_id, to_address, amount, hot, status, initiated_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING created_at, updated_at`,
+		withdrawal.ID, withdrawal.UserID, withdrawal.WalletID, withdrawal.ToAddress, withdrawal.Amount,
+		withdrawal.Hot, withdrawal.Status, withdrawal.InitiatedBy,
+	).Scan(&withdrawal.CreatedAt, &withdrawal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create withdrawal: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSubmitted stores the transaction or the Safe proposal paying the withdrawal
+func (r *WithdrawalsRepository) MarkSubmitted(ctx context.Context, id string, txHash, safeTxHash *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE withdrawals SET status = 'submitted', tx_hash = $2, safe_tx_hash = $3, updated_at = NOW() WHERE id = $1`,
+		id, txHash, safeTxHash)
+	if err != nil {
+		return fmt.Errorf("failed to mark withdrawal submitted: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUnknown stores the transaction of a withdrawal whose broadcast was not confirmed by the node, the amount stays reserved
+func (r *WithdrawalsRepository) MarkUnknown(ctx context.Context, id, txHash, errMsg string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE withdrawals SET status = 'unknown', tx_hash = $2, error = $3, updated_at = NOW() WHERE id = $1`,
+		id, txHash, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to mark withdrawal unknown: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed releases the reserved amount of a withdrawal that was not sent
+func (r *WithdrawalsRepository) MarkFailed(ctx context.Context, id, errMsg string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE withdrawals SET status = 'failed', error = $2, updated_at = NOW() WHERE id = $1`,
+		id, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to mark withdrawal failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkQueued leaves the reserved withdrawal for the next multisend batch
+func (r *WithdrawalsRepository) MarkQueued(ctx context.Context, id string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE withdrawals SET status = 'queued', updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark withdrawal queued: %w", err)
+	}
+
+	return nil
+}
+
+// FindQueuedWithdrawals retrieves queued withdrawals not claimed by a batch, oldest first
+func (r *WithdrawalsRepository) FindQueuedWithdrawals(ctx context.Context, limit int) ([]entities.Withdrawal, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+withdrawalColumns+` FROM withdrawals
+		  WHERE status = 'queued' AND batch_id IS NULL
+		  ORDER BY created_at LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queued withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	withdrawals, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Withdrawal])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect withdrawal rows: %w", err)
+	}
+
+	return withdrawals, nil
+}
+
+// CreateBatch stores the batch and claims its withdrawals before the transaction is sent, so a withdrawal
+// is never paid by two batches. Fails if any of the withdrawals is no longer queued.
+func (r *WithdrawalsRepository) CreateBatch(ctx context.Context, batch *entities.WithdrawalBatch, withdrawalIDs []string) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		err := r.db(txCtx).QueryRow(txCtx,
+			`INSERT INTO withdrawal_batches (id, token, status, withdrawals, amount)
+			 VALUES ($1, $2, $3, $4, $5)
+			 RETURNING created_at, updated_at`,
+			batch.ID, batch.Token, batch.Status, batch.Withdrawals, batch.Amount,
+		).Scan(&batch.CreatedAt, &batch.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create withdrawal batch: %w", err)
+		}
+
+		tag, err := r.db(txCtx).Exec(txCtx,
+			`UPDATE withdrawals SET batch_id = $1, updated_at = NOW()
+			  WHERE id = ANY($2) AND status = 'queued' AND batch_id IS NULL`,
+			batch.ID, withdrawalIDs)
+		if err != nil {
+			return fmt.Errorf("failed to claim batch withdrawals: %w", err)
+		}
+		if tag.RowsAffected() != int64(len(withdrawalIDs)) {
+			return fmt.Errorf("failed to claim batch withdrawals: %d of %d are still queued", tag.RowsAffected(), len(withdrawalIDs))
+		}
+
+		return nil
+	})
+}
+
+// MarkBatchSubmitted stores the multisend transaction on the batch and on every withdrawal it pays
+func (r *WithdrawalsRepository) MarkBatchSubmitted(ctx context.Context, id, txHash string) error {
+	return r.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := r.db(txCtx).Exec(txCtx,
+			`UPDATE withdrawal_batches SET status = 'submitted', tx_hash = $2, updated_at = NOW() WHERE id = $1`,
+			id, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to mark withdrawal batch submitted: %w", err)
+		}
+
+		_, err = r.db(txCtx).Exec(txCtx,
+			`UPDATE withdrawals SET status = 'submitted', tx_hash = $2, updated_at = NOW()
+			  WHERE batch_id = $1 AND status = 'queued'`,
+			id, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to mark batch withdrawals submitted: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// MarkBatchStatus sets the final status of the batch. Withdrawals of a failed batch keep it as batch_id.
+func (r *WithdrawalsRepository) MarkBatchStatus(ctx context.Context, id string, status entities.WithdrawalBatchStatus, errMsg *string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`UPDATE withdrawal_batches SET status = $2, error = $3, updated_at = NOW() WHERE id = $1`,
+		id, status, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to update withdrawal batch status: %w", err)
+	}
+
+	return nil
+}
+
+// FindBatchesByStatus retrieves batches with the given status, newest first. Empty status returns all batches.
+func (r *WithdrawalsRepository) FindBatchesByStatus(ctx context.Context, status entities.WithdrawalBatchStatus, limit int) ([]entities.WithdrawalBatch, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+withdrawalBatchColumns+` FROM withdrawal_batches
+		  WHERE ($1 = '' OR status = $1)
+		  ORDER BY created_at DESC LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withdrawal batches: %w", err)
+	}
+	defer rows.Close()
+
+	batches, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.WithdrawalBatch])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect withdrawal batch rows: %w", err)
+	}
+
+	return batches, nil
+}
+
+// FindBatchByID retrieves a batch by its ID. Returns nil if the batch does not exist.
+func (r *WithdrawalsRepository) FindBatchByID(ctx context.Context, id string) (*entities.WithdrawalBatch, error) {
+	rows, err := r.db(ctx).Query(ctx, `SELECT `+withdrawalBatchColumns+` FROM withdrawal_batches WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withdrawal batch: %w", err)
+	}
+	defer rows.Close()
+
+	batch, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entities.WithdrawalBatch])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect withdrawal batch row: %w", err)
+	}
+
+	return &batch, nil
+}
+
+// FindBatchWithdrawals retrieves the withdrawals claimed by the batch
+func (r *WithdrawalsRepository) FindBatchWithdrawals(ctx context.Context, batchID string) ([]entities.Withdrawal, error) {
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+withdrawalColumns+` FROM withdrawals WHERE batch_id = $1 ORDER BY created_at`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	withdrawals, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entities.Withdrawal])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect withdrawal rows: %w", err)
+	}
+
+	return withdrawals, nil
+}
+
+// GetUserTier returns the limits tier assigned to the user. Returns an empty string if no tier is assigned.
+func (r *WithdrawalsRepository) GetUserTier(ctx context.Context, userID int64) (string, error) {
+	var tier string
+	err := r.db(ctx).QueryRow(ctx, "SELECT tier FROM user_withdrawal_tiers WHERE user_id = $1", userID).Scan(&tier)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get withdrawal tier: %w", err)
+	}
+
+	return tier, nil
+}
+
+// SetUserTier assigns the limits tier to the user
+func (r *WithdrawalsRepository) SetUserTier(ctx context.Context, userID int64, tier, updatedBy string) error {
+	_, err := r.db(ctx).Exec(ctx,
+		`INSERT INTO user_withdrawal_tiers (user_id, tier, updated_by)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE
+		    SET tier = EXCLUDED.tier, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		userID, tier, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to set withdrawal tier: %w", err)
+	}
+
+	return nil
+}
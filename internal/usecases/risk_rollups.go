@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const riskyUsersListLimit = 200
+
+type RiskRollupRepository interface {
+	RefreshWalletRisk(ctx context.Context, walletAddress string) error
+	GetWalletRisk(ctx context.Context, walletAddress string) (*entities.RiskRollup, error)
+	GetUserRisk(ctx context.Context, userID int64) (*entities.RiskRollup, error)
+	GetUserRiskByWallet(ctx context.Context, walletAddress string) (*entities.RiskRollup, error)
+	FindRiskyUsers(ctx context.Context, minScore float64, limit int) ([]entities.RiskRollup, error)
+}
+
+var (
+	_ RiskRollupRepository = (*repository.AMLRepository)(nil)
+	_ AMLRiskRollup        = (*RiskRollupService)(nil)
+)
+
+// RiskRollupConfig задает ужесточение проверки депозитов пользователя с повышенным сводным риском.
+// ElevatedScore <= 0 отключает ужесточение, сводный риск при этом все равно ведется.
+type RiskRollupConfig struct {
+	// Сводный риск пользователя, начиная с которого пороги его депозитов ужесточаются
+	ElevatedScore float64
+	// Ужесточенные пороги риска перевода: ручной проверки и отклонения
+	ReviewScore float64
+	RejectScore float64
+}
+
+// RiskRollupService maintains the aggregated risk score of every deposit wallet from the AML results of its inbound
+// transfers and rolls them up per user. Deposits of a user with an elevated rollup are checked against tighter
+// review and rejection thresholds. The rollups are exposed to compliance.
+type RiskRollupService struct {
+	logger *slog.Logger
+	repo   RiskRollupRepository
+
+	elevatedScore float64
+	reviewScore   float64
+	rejectScore   float64
+}
+
+func NewRiskRollupService(logger *slog.Logger, repo RiskRollupRepository, config RiskRollupConfig) (*RiskRollupService, error) {
+	if config.ElevatedScore > 0 {
+		if config.ReviewScore <= 0 || config.RejectScore <= 0 || config.ReviewScore > config.RejectScore {
+			return nil, fmt.Errorf("tightened AML thresholds must be positive and review score must not exceed reject score")
+		}
+	}
+
+	return &RiskRollupService{
+		logger:        logger,
+		repo:          repo,
+		elevatedScore: config.ElevatedScore,
+		reviewScore:   config.ReviewScore,
+		rejectScore:   config.RejectScore,
+	}, nil
+}
+
+// Tighten applies the tightened thresholds to the AML result of a deposit if the owner of the destination wallet
+// has an elevated rollup. The rollup is read before the deposit is counted in it.
+func (s *RiskRollupService) Tighten(ctx context.Context, result *entities.AMLCheckResult) error {
+	if s.elevatedScore <= 0 {
+		return nil
+	}
+
+	rollup, err := s.repo.GetUserRiskByWallet(ctx, result.WalletAddress)
+	if err != nil {
+		return err
+	}
+	if rollup == nil || rollup.RiskScore < s.elevatedScore {
+		return nil
+	}
+
+	switch {
+	case result.Approved && result.RiskScore >= s.rejectScore:
+		result.Approved = false
+		result.RequiresReview = true
+		result.Notes += fmt.Sprintf("; user risk %.2f lowered the rejection threshold to %.2f", rollup.RiskScore, s.rejectScore)
+	case !result.RequiresReview && result.RiskScore >= s.reviewScore:
+		result.RequiresReview = true
+		result.Notes += fmt.Sprintf("; user risk %.2f lowered the review threshold to %.2f", rollup.RiskScore, s.reviewScore)
+	default:
+		return nil
+	}
+
+	s.logger.WarnContext(ctx, "AML thresholds tightened by user risk",
+		"tx_hash", result.TransactionHash,
+		"user_id", rollup.UserID,
+		"user_risk", rollup.RiskScore,
+		"risk_score", result.RiskScore,
+		"approved", result.Approved,
+		"requires_review", result.RequiresReview)
+	return nil
+}
+
+// Refresh recomputes the rollup of the wallet after a new AML result is saved
+func (s *RiskRollupService) Refresh(ctx context.Context, walletAddress string) error {
+	return s.repo.RefreshWalletRisk(ctx, walletAddress)
+}
+
+// GetWalletRisk returns the rollup of the wallet
+func (s *RiskRollupService) GetWalletRisk(ctx context.Context, walletAddress string) (*entities.RiskRollup, error) {
+	rollup, err := s.repo.GetWalletRisk(ctx, walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if rollup == nil {
+		return nil, ErrRiskRollupNotFound
+	}
+
+	rollup.RiskLevel = rollupRiskLevel(rollup.RiskScore)
+	return rollup, nil
+}
+
+// GetUserRisk returns the rollup of all wallets of the user
+func (s *RiskRollupService) GetUserRisk(ctx context.Context, userID int64) (*entities.RiskRollup, error) {
+	rollup, err := s.repo.GetUserRisk(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rollup == nil {
+		return nil, ErrRiskRollupNotFound
+	}
+
+	rollup.RiskLevel = rollupRiskLevel(rollup.RiskScore)
+	return rollup, nil
+}
+
+// GetRiskyUsers returns rollups of users with the risk score of at least minScore, the riskiest first
+func (s *RiskRollupService) GetRiskyUsers(ctx context.Context, minScore float64) ([]entities.RiskRollup, error) {
+	rollups, err := s.repo.FindRiskyUsers(ctx, minScore, riskyUsersListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rollups {
+		rollups[i].RiskLevel = rollupRiskLevel(rollups[i].RiskScore)
+	}
+	return rollups, nil
+}
+
+// rollupRiskLevel переводит сводный риск в уровень по тем же границам, что и у проверок переводов
+func rollupRiskLevel(score float64) entities.RiskLevel {
+	switch {
+	case score >= 0.7:
+		return entities.RiskLevelHigh
+	case score >= 0.4:
+		return entities.RiskLevelMedium
+	default:
+		return entities.RiskLevelLow
+	}
+}
@@ -0,0 +1,107 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcmanager"
+)
+
+type RPCEndpointManager interface {
+	Stats() []rpcmanager.EndpointStats
+	Disable(rawURL, reason string) error
+	Enable(rawURL string) error
+}
+
+var _ RPCEndpointManager = (*rpcmanager.Manager)(nil)
+
+// BlockScanner is the BSC block scanner worker
+type BlockScanner interface {
+	CurrentEndpoint() string
+	Failover() bool
+}
+
+// RPCEndpointService lets operators inspect endpoint health and take a misbehaving endpoint out of rotation
+type RPCEndpointService struct {
+	logger  *slog.Logger
+	manager RPCEndpointManager
+	scanner BlockScanner
+	audit   *AuditService
+}
+
+func NewRPCEndpointService(logger *slog.Logger, manager RPCEndpointManager, scanner BlockScanner, audit *AuditService) *RPCEndpointService {
+	return &RPCEndpointService{
+		logger:  logger,
+		manager: manager,
+		scanner: scanner,
+		audit:   audit,
+	}
+}
+
+// GetEndpoints returns usage, health and breaker state of every endpoint dialed so far
+func (s *RPCEndpointService) GetEndpoints(_ context.Context) *entities.RPCEndpoints {
+	return &entities.RPCEndpoints{
+		Endpoints:       s.manager.Stats(),
+		ScannerEndpoint: s.scanner.CurrentEndpoint(),
+	}
+}
+
+// Disable takes the endpoint out of rotation until it is enabled again. If the block scanner is subscribed
+// through it, the scanner fails over to the next endpoint.
+func (s *RPCEndpointService) Disable(ctx context.Context, actor, rawURL, reason string) error {
+	if err := s.manager.Disable(rawURL, reason); err != nil {
+		return s.mapError(err)
+	}
+
+	failover := sameEndpoint(s.scanner.CurrentEndpoint(), rawURL) && s.scanner.Failover()
+	s.logger.WarnContext(ctx, "RPC endpoint disabled", "endpoint", rawURL, "reason", reason, "actor", actor, "failover", failover)
+
+	if err := s.audit.Record(ctx, entities.AuditEventRPCEndpointDisabled, actor, rawURL, map[string]any{
+		"reason":   reason,
+		"failover": failover,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record rpc endpoint audit", "error", err, "endpoint", rawURL)
+	}
+	return nil
+}
+
+// Enable returns the endpoint to rotation with a closed breaker
+func (s *RPCEndpointService) Enable(ctx context.Context, actor, rawURL string) error {
+	if err := s.manager.Enable(rawURL); err != nil {
+		return s.mapError(err)
+	}
+
+	s.logger.InfoContext(ctx, "RPC endpoint enabled", "endpoint", rawURL, "actor", actor)
+	if err := s.audit.Record(ctx, entities.AuditEventRPCEndpointEnabled, actor, rawURL, nil); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record rpc endpoint audit", "error", err, "endpoint", rawURL)
+	}
+	return nil
+}
+
+// Failover moves the block scanner to the next WebSocket endpoint without disabling the current one
+func (s *RPCEndpointService) Failover(ctx context.Context, actor string) error {
+	endpoint := s.scanner.CurrentEndpoint()
+	if !s.scanner.Failover() {
+		return ErrRPCFailoverUnavailable
+	}
+
+	s.logger.WarnContext(ctx, "Block scanner failover forced", "endpoint", endpoint, "actor", actor)
+	if err := s.audit.Record(ctx, entities.AuditEventRPCFailoverForced, actor, endpoint, nil); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record rpc endpoint audit", "error", err, "endpoint", endpoint)
+	}
+	return nil
+}
+
+func (s *RPCEndpointService) mapError(err error) error {
+	if errors.Is(err, rpcmanager.ErrUnknownEndpoint) {
+		return ErrRPCEndpointNotFound
+	}
+	return err
+}
+
+func sameEndpoint(a, b string) bool {
+	return a != "" && strings.TrimRight(a, "/") == strings.TrimRight(b, "/")
+}
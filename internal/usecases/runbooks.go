@@ -0,0 +1,265 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// runbookReasonMaxLength — предел длины причины действия в журнале аудита
+const runbookReasonMaxLength = 500
+
+// RunbookCompletions возвращает в очередь сброшенную обработку оплаченного ордера
+type RunbookCompletions interface {
+	RequeueFailed(ctx context.Context, orderID int) (bool, error)
+}
+
+// RunbookReceipts возвращает в очередь сброшенную квитанцию
+type RunbookReceipts interface {
+	RequeueFailedReceipt(ctx context.Context, orderID int) (bool, error)
+}
+
+// RunbookTransactions находит и зачисляет депозиты
+type RunbookTransactions interface {
+	FindTransactionByHash(ctx context.Context, txHash string) (*entities.Transaction, error)
+	CreditTransaction(ctx context.Context, txHash string) (bool, error)
+}
+
+// RunbookAML повторяет AML проверку транзакции
+type RunbookAML interface {
+	RecheckTransaction(ctx context.Context, txHash common.Hash, sourceAddress, destinationAddress string, amount *big.Int) (*entities.AMLCheckResult, error)
+}
+
+// RunbookWebhooks повторно отправляет webhook завершения ордера
+type RunbookWebhooks interface {
+	ReplayWebhook(ctx context.Context, orderID int) error
+}
+
+var (
+	_ RunbookCompletions  = (*repository.OrderCompletionsRepository)(nil)
+	_ RunbookReceipts     = (*repository.ReceiptsRepository)(nil)
+	_ RunbookTransactions = (*TransactionServiceImpl)(nil)
+	_ RunbookAML          = (*AMLService)(nil)
+	_ RunbookWebhooks     = (*OrderCompletionService)(nil)
+)
+
+// RunbookService runs parameterized incident actions on behalf of admin operators instead of ad-hoc SQL.
+// Each operator may run only the actions granted to them; executed, failed and denied actions are audited.
+type RunbookService struct {
+	logger       *slog.Logger
+	completions  RunbookCompletions
+	receipts     RunbookReceipts
+	transactions RunbookTransactions
+	aml          RunbookAML
+	webhooks     RunbookWebhooks
+	audit        *AuditService
+
+	// Разрешенные действия по оператору: CN клиентского сертификата или IP
+	grants map[string][]entities.RunbookAction
+}
+
+// RunbookConfig задает права операторов записями "оператор=действие|действие" или "оператор=*".
+// Оператор — CN клиентского сертификата админки или, без mTLS, IP клиента. Без записей действия запрещены всем.
+type RunbookConfig struct {
+	Operators []string
+}
+
+func parseRunbookGrants(entries []string) (map[string][]entities.RunbookAction, error) {
+	grants := make(map[string][]entities.RunbookAction, len(entries))
+	for _, entry := range entries {
+		operator, actions, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		operator = strings.TrimSpace(operator)
+		if !ok || operator == "" || actions == "" {
+			return nil, fmt.Errorf("invalid runbook grant %q: expected operator=action|action", entry)
+		}
+
+		for _, name := range strings.Split(actions, "|") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				grants[operator] = append(grants[operator], entities.RunbookActions...)
+				continue
+			}
+			action := entities.RunbookAction(name)
+			if !slices.Contains(entities.RunbookActions, action) {
+				return nil, fmt.Errorf("invalid runbook grant %q: unknown action %q", entry, name)
+			}
+			grants[operator] = append(grants[operator], action)
+		}
+	}
+	return grants, nil
+}
+
+func NewRunbookService(
+	logger *slog.Logger,
+	completions RunbookCompletions,
+	receipts RunbookReceipts,
+	transactions RunbookTransactions,
+	aml RunbookAML,
+	webhooks RunbookWebhooks,
+	audit *AuditService,
+	config RunbookConfig,
+) (*RunbookService, error) {
+	grants, err := parseRunbookGrants(config.Operators)
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		logger.Warn("No runbook operators configured, runbook actions are denied to everyone")
+	}
+
+	return &RunbookService{
+		logger:       logger,
+		completions:  completions,
+		receipts:     receipts,
+		transactions: transactions,
+		aml:          aml,
+		webhooks:     webhooks,
+		audit:        audit,
+		grants:       grants,
+	}, nil
+}
+
+// Execute runs the action for the actor ("admin:" followed by the operator) if the operator is granted it
+func (s *RunbookService) Execute(ctx context.Context, actor string, action entities.RunbookAction, req entities.RunbookRequest) (*entities.RunbookResult, error) {
+	subject, err := runbookSubject(action, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > runbookReasonMaxLength {
+		return nil, fmt.Errorf("%w: reason must be 1 to %d characters", ErrInvalidRunbookRequest, runbookReasonMaxLength)
+	}
+
+	details := map[string]any{"action": action, "reason": req.Reason}
+	if req.Kind != "" {
+		details["kind"] = req.Kind
+	}
+
+	if !slices.Contains(s.grants[strings.TrimPrefix(actor, "admin:")], action) {
+		s.record(ctx, entities.AuditEventRunbookDenied, actor, subject, details)
+		return nil, fmt.Errorf("%w: %s", ErrRunbookForbidden, action)
+	}
+
+	result := &entities.RunbookResult{Action: action, Subject: subject, Actor: actor}
+	switch action {
+	case entities.RunbookRetryDeadLetter:
+		err = s.retryDeadLetter(ctx, req)
+	case entities.RunbookRerunAML:
+		result.AMLResult, err = s.rerunAML(ctx, req.TxHash)
+	case entities.RunbookRecreditDeposit:
+		err = s.recreditDeposit(ctx, req.TxHash)
+	case entities.RunbookReplayWebhook:
+		err = s.webhooks.ReplayWebhook(ctx, req.OrderID)
+	}
+	if err != nil {
+		details["error"] = err.Error()
+		s.record(ctx, entities.AuditEventRunbookExecuted, actor, subject, details)
+		return nil, err
+	}
+
+	if result.AMLResult != nil {
+		details["risk_score"] = result.AMLResult.RiskScore
+		details["approved"] = result.AMLResult.Approved
+	}
+	s.record(ctx, entities.AuditEventRunbookExecuted, actor, subject, details)
+
+	s.logger.InfoContext(ctx, "Runbook action executed", "action", action, "subject", subject, "actor", actor)
+	result.ExecutedAt = time.Now()
+	return result, nil
+}
+
+func (s *RunbookService) retryDeadLetter(ctx context.Context, req entities.RunbookRequest) error {
+	var requeued bool
+	var err error
+	switch req.Kind {
+	case entities.DeadLetterOrderCompletion:
+		requeued, err = s.completions.RequeueFailed(ctx, req.OrderID)
+	case entities.DeadLetterOrderReceipt:
+		requeued, err = s.receipts.RequeueFailedReceipt(ctx, req.OrderID)
+	}
+	if err != nil {
+		return err
+	}
+	if !requeued {
+		return fmt.Errorf("%w: order %d has no failed %s", ErrRunbookNotApplicable, req.OrderID, req.Kind)
+	}
+	return nil
+}
+
+func (s *RunbookService) rerunAML(ctx context.Context, txHash string) (*entities.AMLCheckResult, error) {
+	transaction, err := s.findTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, ok := new(big.Int).SetString(transaction.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q of transaction %s", transaction.Amount, txHash)
+	}
+	return s.aml.RecheckTransaction(ctx, common.HexToHash(transaction.TxHash), transaction.FromAddress, transaction.WalletAddress, amount)
+}
+
+func (s *RunbookService) recreditDeposit(ctx context.Context, txHash string) error {
+	if _, err := s.findTransaction(ctx, txHash); err != nil {
+		return err
+	}
+
+	credited, err := s.transactions.CreditTransaction(ctx, txHash)
+	if err != nil {
+		return err
+	}
+	if !credited {
+		return fmt.Errorf("%w: transaction %s is not confirmed, already processed, on hold or short of confirmations",
+			ErrRunbookNotApplicable, txHash)
+	}
+	return nil
+}
+
+func (s *RunbookService) findTransaction(ctx context.Context, txHash string) (*entities.Transaction, error) {
+	transaction, err := s.transactions.FindTransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+func (s *RunbookService) record(ctx context.Context, event entities.AuditEventType, actor, subject string, details map[string]any) {
+	if err := s.audit.Record(ctx, event, actor, subject, details); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record runbook action", "error", err, "subject", subject, "event", event)
+	}
+}
+
+// runbookSubject проверяет параметры действия и возвращает объект для журнала аудита
+func runbookSubject(action entities.RunbookAction, req entities.RunbookRequest) (string, error) {
+	switch action {
+	case entities.RunbookRetryDeadLetter:
+		if req.Kind != entities.DeadLetterOrderCompletion && req.Kind != entities.DeadLetterOrderReceipt {
+			return "", fmt.Errorf("%w: unknown dead-letter kind %q", ErrInvalidRunbookRequest, req.Kind)
+		}
+		fallthrough
+	case entities.RunbookReplayWebhook:
+		if req.OrderID <= 0 {
+			return "", fmt.Errorf("%w: order_id is required", ErrInvalidRunbookRequest)
+		}
+		return fmt.Sprintf("order:%d", req.OrderID), nil
+	case entities.RunbookRerunAML, entities.RunbookRecreditDeposit:
+		if req.TxHash == "" {
+			return "", fmt.Errorf("%w: tx_hash is required", ErrInvalidRunbookRequest)
+		}
+		return "transaction:" + req.TxHash, nil
+	default:
+		return "", fmt.Errorf("%w: unknown action %q", ErrInvalidRunbookRequest, action)
+	}
+}
@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+func TestParseRunbookGrants(t *testing.T) {
+	grants, err := parseRunbookGrants([]string{
+		" ops-1 = rerun_aml | replay_webhook ",
+		"10.0.0.5=*",
+		"ops-1=recredit_deposit",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []entities.RunbookAction{entities.RunbookRerunAML, entities.RunbookReplayWebhook, entities.RunbookRecreditDeposit}, grants["ops-1"])
+	assert.Equal(t, entities.RunbookActions, grants["10.0.0.5"])
+	assert.Len(t, grants, 2)
+}
+
+func TestParseRunbookGrantsEmpty(t *testing.T) {
+	grants, err := parseRunbookGrants(nil)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+}
+
+func TestParseRunbookGrantsInvalid(t *testing.T) {
+	invalid := []string{
+		"ops-1",
+		"=rerun_aml",
+		" =rerun_aml",
+		"ops-1=",
+		"ops-1=drop_tables",
+		"ops-1=rerun_aml|",
+		"ops-1=RERUN_AML",
+	}
+	for _, entry := range invalid {
+		_, err := parseRunbookGrants([]string{entry})
+		assert.Error(t, err, entry)
+	}
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type SandboxRepository interface {
+	IsSandbox(ctx context.Context, merchantID int64) (bool, error)
+	FindSandboxMerchants(ctx context.Context) ([]entities.SandboxMerchant, error)
+	EnableSandbox(ctx context.Context, merchantID int64, actor string) error
+	DisableSandbox(ctx context.Context, merchantID int64) error
+}
+
+var _ SandboxRepository = (*repository.SandboxRepository)(nil)
+
+// SandboxService keeps sandbox merchants on the test network. The environment serving mainnet refuses their
+// requests, so their orders are created by the sandbox environment on testnet wallets and token contracts
+// and are flagged as test data.
+type SandboxService struct {
+	logger *slog.Logger
+	repo   SandboxRepository
+	audit  *AuditService
+
+	// Сеть, которую обслуживает окружение
+	network string
+}
+
+func NewSandboxService(logger *slog.Logger, repo SandboxRepository, audit *AuditService, network string) *SandboxService {
+	return &SandboxService{
+		logger:  logger,
+		repo:    repo,
+		audit:   audit,
+		network: network,
+	}
+}
+
+// Production reports whether the environment serves mainnet and refuses sandbox merchants
+func (s *SandboxService) Production() bool {
+	return s.network == entities.NetworkMainnet
+}
+
+// CheckAccess refuses requests of sandbox merchants in the production environment
+func (s *SandboxService) CheckAccess(ctx context.Context, merchantID int64) error {
+	if !s.Production() {
+		return nil
+	}
+
+	sandbox, err := s.repo.IsSandbox(ctx, merchantID)
+	if err != nil {
+		return err
+	}
+	if sandbox {
+		return ErrSandboxMerchant
+	}
+	return nil
+}
+
+// GetStatus returns the mode of the merchant and whether the environment serves it
+func (s *SandboxService) GetStatus(ctx context.Context, merchantID int64) (*entities.SandboxStatus, error) {
+	sandbox, err := s.repo.IsSandbox(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.SandboxStatus{
+		MerchantID: merchantID,
+		Sandbox:    sandbox,
+		Network:    s.network,
+		Allowed:    !sandbox || !s.Production(),
+	}, nil
+}
+
+// GetSandboxMerchants returns merchants in the sandbox mode
+func (s *SandboxService) GetSandboxMerchants(ctx context.Context) ([]entities.SandboxMerchant, error) {
+	return s.repo.FindSandboxMerchants(ctx)
+}
+
+// SetSandbox moves the merchant to the sandbox mode or back. Orders already created keep their network.
+func (s *SandboxService) SetSandbox(ctx context.Context, merchantID int64, sandbox bool, actor string) (*entities.SandboxStatus, error) {
+	var err error
+	if sandbox {
+		err = s.repo.EnableSandbox(ctx, merchantID, actor)
+	} else {
+		err = s.repo.DisableSandbox(ctx, merchantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.audit.Record(ctx, entities.AuditEventMerchantSandboxChanged, actor, strconv.FormatInt(merchantID, 10), map[string]any{"sandbox": sandbox}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record merchant sandbox change", "error", err, "merchant_id", merchantID)
+	}
+
+	s.logger.InfoContext(ctx, "Merchant sandbox mode changed", "merchant_id", merchantID, "sandbox", sandbox, "actor", actor)
+	return s.GetStatus(ctx, merchantID)
+}
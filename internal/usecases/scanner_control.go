@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type ScannerStatesRepository interface {
+	FindStates(ctx context.Context) ([]entities.ScannerState, error)
+	SetPaused(ctx context.Context, chain entities.Chain, paused bool, reason, actor string) (*entities.ScannerState, error)
+}
+
+// PausableScanner is a chain scanner that operators can pause during an incident
+type PausableScanner interface {
+	Paused() bool
+	Pause()
+	Resume() bool
+}
+
+var (
+	_ ScannerStatesRepository = (*repository.ScannerStatesRepository)(nil)
+	_ PausableScanner         = (*TonDepositService)(nil)
+)
+
+// ScannerControlService pauses and resumes chain scanners. A paused scanner neither processes blocks nor
+// credits deposits, the pause survives restarts. On resume the scanner catches up from its checkpoint.
+type ScannerControlService struct {
+	logger   *slog.Logger
+	repo     ScannerStatesRepository
+	audit    *AuditService
+	scanners map[entities.Chain]PausableScanner
+}
+
+func NewScannerControlService(logger *slog.Logger, repo ScannerStatesRepository, audit *AuditService, scanners map[entities.Chain]PausableScanner) *ScannerControlService {
+	return &ScannerControlService{
+		logger:   logger,
+		repo:     repo,
+		audit:    audit,
+		scanners: scanners,
+	}
+}
+
+// Restore pauses the scanners that were paused before the restart
+func (s *ScannerControlService) Restore(ctx context.Context) error {
+	states, err := s.repo.FindStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		if scanner, ok := s.scanners[state.Chain]; ok && state.Paused {
+			scanner.Pause()
+			s.logger.WarnContext(ctx, "Chain scanner remains paused", "chain", state.Chain, "reason", state.PauseReason)
+		}
+	}
+	return nil
+}
+
+// GetScanners returns the state of every controllable scanner ordered by chain
+func (s *ScannerControlService) GetScanners(ctx context.Context) ([]entities.ScannerState, error) {
+	saved, err := s.repo.FindStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]entities.ScannerState, 0, len(s.scanners))
+	for chain, scanner := range s.scanners {
+		state := entities.ScannerState{Chain: chain}
+		if i := slices.IndexFunc(saved, func(st entities.ScannerState) bool { return st.Chain == chain }); i >= 0 {
+			state = saved[i]
+		}
+		// Сохраненная пауза применяется при запуске, текущее состояние берется у сканера
+		state.Paused = scanner.Paused()
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b entities.ScannerState) int {
+		return cmp.Compare(a.Chain, b.Chain)
+	})
+	return states, nil
+}
+
+// Pause stops the chain scanner. The pause is stored before the scanner stops, so it survives a restart.
+func (s *ScannerControlService) Pause(ctx context.Context, chain entities.Chain, reason, actor string) (*entities.ScannerState, error) {
+	scanner, ok := s.scanners[chain]
+	if !ok {
+		return nil, ErrScannerNotFound
+	}
+	if scanner.Paused() {
+		return nil, ErrScannerAlreadyPaused
+	}
+
+	state, err := s.repo.SetPaused(ctx, chain, true, reason, actor)
+	if err != nil {
+		return nil, err
+	}
+	scanner.Pause()
+
+	s.logger.WarnContext(ctx, "Chain scanner paused", "chain", chain, "reason", reason, "actor", actor)
+	if err = s.audit.Record(ctx, entities.AuditEventScannerPaused, actor, string(chain), map[string]any{
+		"reason":     reason,
+		"last_block": state.LastBlock,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record scanner pause", "error", err, "chain", chain)
+	}
+	return state, nil
+}
+
+// Resume restarts the chain scanner, which backfills the blocks produced during the pause
+func (s *ScannerControlService) Resume(ctx context.Context, chain entities.Chain, actor string) (*entities.ScannerState, error) {
+	scanner, ok := s.scanners[chain]
+	if !ok {
+		return nil, ErrScannerNotFound
+	}
+	if !scanner.Paused() {
+		return nil, ErrScannerNotPaused
+	}
+
+	state, err := s.repo.SetPaused(ctx, chain, false, "", actor)
+	if err != nil {
+		return nil, err
+	}
+	scanner.Resume()
+
+	s.logger.InfoContext(ctx, "Chain scanner resumed", "chain", chain, "actor", actor, "from_block", state.LastBlock)
+	if err = s.audit.Record(ctx, entities.AuditEventScannerResumed, actor, string(chain), map[string]any{
+		"from_block": state.LastBlock,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record scanner resume", "error", err, "chain", chain)
+	}
+	return state, nil
+}
@@ -0,0 +1,340 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	sessionTokenSize     = 32
+	sessionHistoryLimit  = 50
+	sessionChallengeSize = 32
+	// Время, за которое сервис идентификации должен подписать вызов входа
+	sessionChallengeTTL = 5 * time.Minute
+	// Операция второго фактора при входе
+	sessionLoginOperation = "login"
+)
+
+type SessionsRepository interface {
+	CreateChallenge(ctx context.Context, challenge *entities.LoginChallenge) error
+	ConsumeChallenge(ctx context.Context, id string, userID int64) (string, error)
+	CreateSession(ctx context.Context, session *entities.Session) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entities.Session, error)
+	FindByUserID(ctx context.Context, userID int64, activeOnly bool, limit int) ([]entities.Session, error)
+	HasDevice(ctx context.Context, userID int64, deviceID string) (bool, error)
+	TouchSession(ctx context.Context, id string) error
+	RevokeSession(ctx context.Context, userID int64, id string) (bool, error)
+	RevokeAllSessions(ctx context.Context, userID int64, exceptID string) (int64, error)
+}
+
+// SessionTwoFactor проверяет второй фактор пользователя при входе
+type SessionTwoFactor interface {
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	VerifyOperation(ctx context.Context, userID int64, operation, code string) error
+}
+
+var (
+	_ SessionsRepository = (*repository.SessionsRepository)(nil)
+	_ SessionTwoFactor   = (*TwoFactorService)(nil)
+)
+
+// GeoResolver определяет примерное местоположение по IP адресу
+type GeoResolver interface {
+	Resolve(ctx context.Context, ip string) (entities.GeoLocation, error)
+}
+
+type SessionConfig struct {
+	TTL time.Duration
+	// Общий секрет с сервисом идентификации: он проверяет учетные данные пользователя и подписывает вызов входа.
+	// Пустой секрет запрещает вход.
+	LoginSecret string
+}
+
+// SessionService tracks user sessions, login history and known devices
+type SessionService struct {
+	logger    *slog.Logger
+	repo      SessionsRepository
+	audit     *AuditService
+	notifier  Notifier
+	geo       GeoResolver
+	twoFactor SessionTwoFactor
+
+	ttl         time.Duration
+	loginSecret []byte
+}
+
+// NewSessionService creates a new session service. geo may be nil, in that case only
+// the location supplied by the client (e.g. CDN country header) is stored.
+func NewSessionService(logger *slog.Logger, repo SessionsRepository, audit *AuditService, notifier Notifier, geo GeoResolver,
+	twoFactor SessionTwoFactor, config SessionConfig) *SessionService {
+
+	return &SessionService{
+		logger:      logger,
+		repo:        repo,
+		audit:       audit,
+		notifier:    notifier,
+		geo:         geo,
+		twoFactor:   twoFactor,
+		ttl:         config.TTL,
+		loginSecret: []byte(config.LoginSecret),
+	}
+}
+
+// IssueChallenge creates a single-use login challenge for the user. The identity service that verified
+// the user's credentials signs it with SignLoginChallenge, and the signature is exchanged for a session.
+func (s *SessionService) IssueChallenge(ctx context.Context, userID int64) (*entities.LoginChallenge, error) {
+	if len(s.loginSecret) == 0 {
+		return nil, ErrSessionLoginDisabled
+	}
+
+	buf := make([]byte, sessionChallengeSize)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate login challenge: %w", err)
+	}
+
+	challenge := &entities.LoginChallenge{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Challenge: hex.EncodeToString(buf),
+		ExpiresAt: time.Now().Add(sessionChallengeTTL),
+	}
+	if err := s.repo.CreateChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// SignLoginChallenge returns the hex HMAC-SHA256 of "user_id:challenge" with the login secret
+func SignLoginChallenge(secret string, userID int64, challenge string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(userID, 10) + ":" + challenge))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyLogin consumes the challenge and checks its signature and, if the user enrolled, the second factor.
+// The challenge is consumed before the checks, so every signature or code guess costs a new challenge.
+func (s *SessionService) verifyLogin(ctx context.Context, userID int64, credential entities.SessionCredential) error {
+	if len(s.loginSecret) == 0 {
+		return ErrSessionLoginDisabled
+	}
+	if _, err := uuid.Parse(credential.ChallengeID); err != nil {
+		return ErrSessionInvalidCredential
+	}
+
+	challenge, err := s.repo.ConsumeChallenge(ctx, credential.ChallengeID, userID)
+	if err != nil {
+		return err
+	}
+	expected := SignLoginChallenge(string(s.loginSecret), userID, challenge)
+	if challenge == "" || !hmac.Equal([]byte(strings.ToLower(credential.Signature)), []byte(expected)) {
+		s.recordLoginFailure(ctx, userID, "invalid_signature")
+		return ErrSessionInvalidCredential
+	}
+
+	if s.twoFactor == nil {
+		return nil
+	}
+	enabled, err := s.twoFactor.IsEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	if err = s.twoFactor.VerifyOperation(ctx, userID, sessionLoginOperation, credential.TwoFactorCode); err != nil {
+		s.recordLoginFailure(ctx, userID, "second_factor")
+		return err
+	}
+
+	return nil
+}
+
+// StartSession verifies the login credential and records a login, returning the session together with its bearer token.
+// The token itself is never stored, only its SHA-256 hash.
+func (s *SessionService) StartSession(ctx context.Context, userID int64, credential entities.SessionCredential, client entities.SessionClient) (*entities.Session, string, error) {
+	if err := s.verifyLogin(ctx, userID, credential); err != nil {
+		return nil, "", err
+	}
+
+	token, err := generateSessionToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if client.Country == "" && s.geo != nil && client.IPAddress != "" {
+		location, geoErr := s.geo.Resolve(ctx, client.IPAddress)
+		if geoErr != nil {
+			s.logger.WarnContext(ctx, "Failed to resolve IP location", "error", geoErr, "ip", client.IPAddress)
+		} else {
+			client.Country = location.Country
+			client.City = location.City
+		}
+	}
+
+	deviceID := DeviceID(client.UserAgent)
+
+	knownDevice, err := s.repo.HasDevice(ctx, userID, deviceID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := &entities.Session{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		TokenHash: hashSessionToken(token),
+		DeviceID:  deviceID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Country:   client.Country,
+		City:      client.City,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+
+	if err = s.repo.CreateSession(ctx, session); err != nil {
+		return nil, "", err
+	}
+
+	s.recordAudit(ctx, entities.AuditEventSessionCreated, session)
+
+	if !knownDevice {
+		s.recordAudit(ctx, entities.AuditEventNewDeviceLogin, session)
+		s.notifyNewDevice(ctx, session)
+	}
+
+	s.logger.InfoContext(ctx, "Session started",
+		"user_id", userID,
+		"session_id", session.ID,
+		"new_device", !knownDevice)
+
+	return session, token, nil
+}
+
+// Authenticate resolves an active session by its bearer token
+func (s *SessionService) Authenticate(ctx context.Context, token string) (*entities.Session, error) {
+	session, err := s.repo.FindByTokenHash(ctx, hashSessionToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || !session.IsActive(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+
+	if err = s.repo.TouchSession(ctx, session.ID); err != nil {
+		s.logger.WarnContext(ctx, "Failed to update session activity", "error", err, "session_id", session.ID)
+	}
+
+	return session, nil
+}
+
+// GetActiveSessions returns the sessions that can still be used
+func (s *SessionService) GetActiveSessions(ctx context.Context, userID int64) ([]entities.Session, error) {
+	return s.repo.FindByUserID(ctx, userID, true, sessionHistoryLimit)
+}
+
+// GetLoginHistory returns the latest logins including revoked and expired sessions
+func (s *SessionService) GetLoginHistory(ctx context.Context, userID int64, limit int) ([]entities.Session, error) {
+	if limit <= 0 || limit > sessionHistoryLimit {
+		limit = sessionHistoryLimit
+	}
+	return s.repo.FindByUserID(ctx, userID, false, limit)
+}
+
+// RevokeSession revokes a single session of the user
+func (s *SessionService) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	revoked, err := s.repo.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+
+	s.recordAudit(ctx, entities.AuditEventSessionRevoked, &entities.Session{ID: sessionID, UserID: userID})
+	return nil
+}
+
+// RevokeOtherSessions revokes all sessions of the user except the current one
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID int64, currentSessionID string) (int64, error) {
+	count, err := s.repo.RevokeAllSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.InfoContext(ctx, "Sessions revoked", "user_id", userID, "count", count)
+	return count, nil
+}
+
+func (s *SessionService) notifyNewDevice(ctx context.Context, session *entities.Session) {
+	if s.notifier == nil {
+		return
+	}
+
+	location := session.Country
+	if session.City != "" {
+		location = session.City + ", " + session.Country
+	}
+
+	message := fmt.Sprintf("New login to your account from %s (IP %s, %s) at %s. If this wasn't you, revoke the session and change your credentials.",
+		session.UserAgent, session.IPAddress, location, session.CreatedAt.Format(time.RFC1123))
+
+	if err := s.notifier.Notify(ctx, session.UserID, "New device login", message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to send new device notification", "error", err, "user_id", session.UserID)
+	}
+}
+
+func (s *SessionService) recordLoginFailure(ctx context.Context, userID int64, reason string) {
+	s.logger.WarnContext(ctx, "Login rejected", "user_id", userID, "reason", reason)
+	if s.audit == nil {
+		return
+	}
+	// Ошибка уже залогирована внутри AuditService
+	_ = s.audit.Record(ctx, entities.AuditEventLoginFailed, strconv.FormatInt(userID, 10), strconv.FormatInt(userID, 10), map[string]any{
+		"reason": reason,
+	})
+}
+
+func (s *SessionService) recordAudit(ctx context.Context, eventType entities.AuditEventType, session *entities.Session) {
+	if s.audit == nil {
+		return
+	}
+	// Ошибка уже залогирована внутри AuditService
+	_ = s.audit.Record(ctx, eventType, strconv.FormatInt(session.UserID, 10), session.ID, map[string]any{
+		"device_id":  session.DeviceID,
+		"ip_address": session.IPAddress,
+		"user_agent": session.UserAgent,
+		"country":    session.Country,
+	})
+}
+
+// DeviceID returns a stable identifier of the client device derived from its user agent
+func DeviceID(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+func generateSessionToken() (string, error) {
+	buf := make([]byte, sessionTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
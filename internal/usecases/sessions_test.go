@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+const testLoginSecret = "identity-service-secret"
+
+// stubSessionsRepository хранит вызовы входа и сессии в памяти
+type stubSessionsRepository struct {
+	SessionsRepository
+	challenges map[string]*entities.LoginChallenge
+	used       map[string]bool
+	sessions   []*entities.Session
+}
+
+func newStubSessionsRepository() *stubSessionsRepository {
+	return &stubSessionsRepository{
+		challenges: make(map[string]*entities.LoginChallenge),
+		used:       make(map[string]bool),
+	}
+}
+
+func (r *stubSessionsRepository) CreateChallenge(_ context.Context, challenge *entities.LoginChallenge) error {
+	r.challenges[challenge.ID] = challenge
+	return nil
+}
+
+func (r *stubSessionsRepository) ConsumeChallenge(_ context.Context, id string, userID int64) (string, error) {
+	challenge, ok := r.challenges[id]
+	if !ok || challenge.UserID != userID || r.used[id] || time.Now().After(challenge.ExpiresAt) {
+		return "", nil
+	}
+	r.used[id] = true
+	return challenge.Challenge, nil
+}
+
+func (r *stubSessionsRepository) HasDevice(context.Context, int64, string) (bool, error) {
+	return true, nil
+}
+
+func (r *stubSessionsRepository) CreateSession(_ context.Context, session *entities.Session) error {
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+// stubSessionTwoFactor подключен у пользователей из enabled и принимает код "123456"
+type stubSessionTwoFactor struct {
+	enabled map[int64]bool
+}
+
+func (s stubSessionTwoFactor) IsEnabled(_ context.Context, userID int64) (bool, error) {
+	return s.enabled[userID], nil
+}
+
+func (s stubSessionTwoFactor) VerifyOperation(_ context.Context, _ int64, _, code string) error {
+	switch code {
+	case "":
+		return ErrTwoFactorRequired
+	case "123456":
+		return nil
+	default:
+		return ErrTwoFactorInvalidCode
+	}
+}
+
+func newTestSessionService(repo *stubSessionsRepository, secret string, twoFactorUsers ...int64) *SessionService {
+	twoFactor := stubSessionTwoFactor{enabled: make(map[int64]bool)}
+	for _, userID := range twoFactorUsers {
+		twoFactor.enabled[userID] = true
+	}
+	return NewSessionService(slog.New(slog.NewTextHandler(io.Discard, nil)), repo, nil, nil, nil, twoFactor,
+		SessionConfig{TTL: time.Hour, LoginSecret: secret})
+}
+
+func TestStartSessionRequiresSignedChallenge(t *testing.T) {
+	repo := newStubSessionsRepository()
+	service := newTestSessionService(repo, testLoginSecret)
+	ctx := context.Background()
+
+	// Без подписи сервиса идентификации сессия не создается
+	challenge, err := service.IssueChallenge(ctx, 42)
+	require.NoError(t, err)
+	_, _, err = service.StartSession(ctx, 42, entities.SessionCredential{
+		ChallengeID: challenge.ID,
+		Signature:   SignLoginChallenge("wrong-secret", 42, challenge.Challenge),
+	}, entities.SessionClient{})
+	require.ErrorIs(t, err, ErrSessionInvalidCredential)
+
+	// Вызов одноразовый: после неудачной попытки правильная подпись уже не принимается
+	_, _, err = service.StartSession(ctx, 42, entities.SessionCredential{
+		ChallengeID: challenge.ID,
+		Signature:   SignLoginChallenge(testLoginSecret, 42, challenge.Challenge),
+	}, entities.SessionClient{})
+	require.ErrorIs(t, err, ErrSessionInvalidCredential)
+
+	// Подпись вызова другого пользователя не подходит
+	challenge, err = service.IssueChallenge(ctx, 42)
+	require.NoError(t, err)
+	_, _, err = service.StartSession(ctx, 7, entities.SessionCredential{
+		ChallengeID: challenge.ID,
+		Signature:   SignLoginChallenge(testLoginSecret, 7, challenge.Challenge),
+	}, entities.SessionClient{})
+	require.ErrorIs(t, err, ErrSessionInvalidCredential)
+
+	challenge, err = service.IssueChallenge(ctx, 42)
+	require.NoError(t, err)
+	session, token, err := service.StartSession(ctx, 42, entities.SessionCredential{
+		ChallengeID: challenge.ID,
+		Signature:   SignLoginChallenge(testLoginSecret, 42, challenge.Challenge),
+	}, entities.SessionClient{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), session.UserID)
+	assert.NotEmpty(t, token)
+	assert.Len(t, repo.sessions, 1)
+}
+
+func TestStartSessionRequiresEnrolledSecondFactor(t *testing.T) {
+	repo := newStubSessionsRepository()
+	service := newTestSessionService(repo, testLoginSecret, 42)
+	ctx := context.Background()
+
+	start := func(code string) error {
+		challenge, err := service.IssueChallenge(ctx, 42)
+		require.NoError(t, err)
+		_, _, err = service.StartSession(ctx, 42, entities.SessionCredential{
+			ChallengeID:   challenge.ID,
+			Signature:     SignLoginChallenge(testLoginSecret, 42, challenge.Challenge),
+			TwoFactorCode: code,
+		}, entities.SessionClient{})
+		return err
+	}
+
+	assert.ErrorIs(t, start(""), ErrTwoFactorRequired)
+	assert.ErrorIs(t, start("000000"), ErrTwoFactorInvalidCode)
+	assert.NoError(t, start("123456"))
+	assert.Len(t, repo.sessions, 1)
+}
+
+func TestLoginDisabledWithoutSecret(t *testing.T) {
+	service := newTestSessionService(newStubSessionsRepository(), "")
+
+	_, err := service.IssueChallenge(context.Background(), 42)
+	assert.ErrorIs(t, err, ErrSessionLoginDisabled)
+	_, _, err = service.StartSession(context.Background(), 42, entities.SessionCredential{
+		ChallengeID: "00000000-0000-0000-0000-000000000000",
+		Signature:   "00",
+	}, entities.SessionClient{})
+	assert.ErrorIs(t, err, ErrSessionLoginDisabled)
+}
@@ -0,0 +1,451 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	settlementBatchSize   = 100
+	settlementsListLimit  = 100
+	settlementInitiatedBy = "worker:settlement"
+	// Комиссия задается в базисных пунктах: 10000 bps = 100%
+	settlementMaxFeeBPS = 10000
+)
+
+var settlementsPaid = expvar.NewInt("bsc_settlements_paid")
+
+type SettlementsRepository interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	GetAccount(ctx context.Context, merchantID int64) (*entities.SettlementAccount, error)
+	SetAccount(ctx context.Context, account *entities.SettlementAccount) error
+	FindDueAccounts(ctx context.Context, assetID int, completedBefore time.Time, limit int) ([]entities.SettlementAccount, error)
+	LockUnsettledOrders(ctx context.Context, merchantID int64, assetID int, completedBefore time.Time) ([]entities.Order, error)
+	CreateSettlement(ctx context.Context, settlement *entities.Settlement, orderIDs []int) error
+	MarkSubmitted(ctx context.Context, id string, status entities.SettlementStatus, txHash, safeTxHash *string) error
+	MarkUnknown(ctx context.Context, id, txHash, errMsg string) error
+	MarkFailed(ctx context.Context, id, errMsg string) error
+	FindByID(ctx context.Context, id string) (*entities.Settlement, error)
+	FindByMerchant(ctx context.Context, merchantID int64, limit int) ([]entities.Settlement, error)
+	FindByStatus(ctx context.Context, status entities.SettlementStatus, limit int) ([]entities.Settlement, error)
+	FindSettlementOrders(ctx context.Context, id string) ([]entities.SettlementOrder, error)
+	RefreshAccrual(ctx context.Context, merchantID int64, assetID int) (*entities.SettlementAccrual, error)
+	FindAccrual(ctx context.Context, merchantID int64, assetID int) (*entities.SettlementAccrual, error)
+}
+
+// SettlementWallets проверяет адреса выплат и состояние контракта токена
+type SettlementWallets interface {
+	ScreenAddresses(ctx context.Context, addresses ...string) error
+	CheckTokenHalt() error
+	Asset() entities.Asset
+}
+
+var (
+	_ SettlementsRepository = (*repository.SettlementsRepository)(nil)
+	_ SettlementWallets     = (*WalletService)(nil)
+)
+
+// SettlementConfig задает выплаты мерчантам. Нулевой PayoutWalletID отключает выплаты.
+type SettlementConfig struct {
+	// Кошелек платформы, с которого выплачиваются расчеты
+	PayoutWalletID int
+	Interval       time.Duration
+	// Ордер попадает в расчет не раньше, чем через Delay после завершения (окно для возвратов и разбирательств)
+	Delay time.Duration
+	// Комиссия платформы в базисных пунктах, если для мерчанта не задана своя
+	FeeBPS int
+	// Минимальная сумма выплаты в единицах актива, меньшие суммы копятся до следующего пакета
+	MinAmount string
+}
+
+// SettlementService periodically pays merchants for their completed orders: orders are grouped into a batch per merchant,
+// the platform fee is netted and the rest is transferred to the merchant's settlement address from the payout wallet.
+// A payout failed before broadcast releases its orders into the next batch, a payout whose broadcast
+// was not confirmed keeps them until reconciled by the operator. Each batch can be downloaded as a report.
+type SettlementService struct {
+	logger    *slog.Logger
+	repo      SettlementsRepository
+	wallets   SettlementWallets
+	transfers SweepTransfers
+	audit     *AuditService
+	notifier  Notifier
+
+	payoutWalletID int
+	interval       time.Duration
+	delay          time.Duration
+	feeBPS         int
+	minAmount      *big.Int
+}
+
+func NewSettlementService(
+	logger *slog.Logger,
+	repo SettlementsRepository,
+	wallets SettlementWallets,
+	transfers SweepTransfers,
+	audit *AuditService,
+	notifier Notifier,
+	config SettlementConfig,
+) (*SettlementService, error) {
+	s := &SettlementService{
+		logger:         logger,
+		repo:           repo,
+		wallets:        wallets,
+		transfers:      transfers,
+		audit:          audit,
+		notifier:       notifier,
+		payoutWalletID: config.PayoutWalletID,
+		interval:       config.Interval,
+		delay:          config.Delay,
+		feeBPS:         config.FeeBPS,
+	}
+
+	if config.FeeBPS < 0 || config.FeeBPS > settlementMaxFeeBPS {
+		return nil, fmt.Errorf("settlement fee must be between 0 and %d bps, got %d", settlementMaxFeeBPS, config.FeeBPS)
+	}
+	if config.PayoutWalletID <= 0 {
+		return s, nil
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("settlement interval must be positive")
+	}
+	if config.Delay < 0 {
+		return nil, errors.New("settlement delay must not be negative")
+	}
+	minAmount, err := tokenAmountToUnits(config.MinAmount, wallets.Asset().Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settlement minimum amount: %w", err)
+	}
+	s.minAmount = minAmount
+
+	return s, nil
+}
+
+// Start periodically settles merchants until ctx is cancelled
+func (s *SettlementService) Start(ctx context.Context) {
+	if s.payoutWalletID <= 0 {
+		s.logger.Info("Merchant settlements are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SettleAll(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Merchant settlement failed", "error", err)
+			}
+		}
+	}
+}
+
+// SettleAll creates and pays a settlement batch for every merchant with unsettled completed orders
+func (s *SettlementService) SettleAll(ctx context.Context) error {
+	if err := s.wallets.CheckTokenHalt(); err != nil {
+		s.logger.WarnContext(ctx, "Merchant settlement skipped", "reason", err.Error())
+		return nil
+	}
+
+	asset := s.wallets.Asset()
+	completedBefore := time.Now().Add(-s.delay)
+
+	accounts, err := s.repo.FindDueAccounts(ctx, asset.ID, completedBefore, settlementBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	var paid int
+	for _, account := range accounts {
+		settlement, err := s.createBatch(ctx, account, completedBefore)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to create settlement batch", "error", err, "merchant_id", account.MerchantID)
+			continue
+		}
+		if settlement == nil {
+			continue
+		}
+		s.refreshAccrual(ctx, account.MerchantID)
+
+		if s.pay(ctx, client, settlement) {
+			paid++
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Merchant settlement completed", "merchants", len(accounts), "paid", paid)
+	return nil
+}
+
+// createBatch assigns unsettled orders of the merchant to a new pending settlement.
+// Returns nil if the net amount is below the minimum payout.
+func (s *SettlementService) createBatch(ctx context.Context, account entities.SettlementAccount, completedBefore time.Time) (*entities.Settlement, error) {
+	asset := s.wallets.Asset()
+	feeBPS := s.feeBPS
+	if account.FeeBPS != nil {
+		feeBPS = *account.FeeBPS
+	}
+
+	var settlement *entities.Settlement
+	err := s.repo.WithinTransaction(ctx, func(txCtx context.Context) error {
+		orders, err := s.repo.LockUnsettledOrders(txCtx, account.MerchantID, asset.ID, completedBefore)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		gross := new(big.Int)
+		orderIDs := make([]int, 0, len(orders))
+		periodStart, periodEnd := orders[0].UpdatedAt, orders[0].UpdatedAt
+		for _, order := range orders {
+			amount, err := order.Amount.Units(asset.Decimals)
+			if err != nil {
+				return fmt.Errorf("invalid amount of order %d: %w", order.ID, err)
+			}
+			gross.Add(gross, amount)
+			orderIDs = append(orderIDs, order.ID)
+			if order.UpdatedAt.Before(periodStart) {
+				periodStart = order.UpdatedAt
+			}
+			if order.UpdatedAt.After(periodEnd) {
+				periodEnd = order.UpdatedAt
+			}
+		}
+
+		// Комиссия округляется вниз: мерчант не теряет на округлении
+		fee := new(big.Int).Mul(gross, big.NewInt(int64(feeBPS)))
+		fee.Quo(fee, big.NewInt(settlementMaxFeeBPS))
+		net := new(big.Int).Sub(gross, fee)
+		if net.Cmp(s.minAmount) < 0 {
+			s.logger.DebugContext(ctx, "Settlement below minimum payout", "merchant_id", account.MerchantID, "net", net.String())
+			return nil
+		}
+
+		settlement = &entities.Settlement{
+			ID:          uuid.NewString(),
+			MerchantID:  account.MerchantID,
+			AssetID:     asset.ID,
+			Address:     account.Address,
+			Orders:      len(orders),
+			GrossAmount: gross.String(),
+			FeeAmount:   fee.String(),
+			NetAmount:   net.String(),
+			FeeBPS:      feeBPS,
+			Status:      entities.SettlementPending,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+		}
+		return s.repo.CreateSettlement(txCtx, settlement, orderIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+// pay transfers the net amount to the merchant. A settlement left pending after a crash is not retried automatically,
+// since the transfer may have been sent.
+func (s *SettlementService) pay(ctx context.Context, client *ethclient.Client, settlement *entities.Settlement) bool {
+	net, _ := new(big.Int).SetString(settlement.NetAmount, 10)
+
+	transfer, err := s.transfer(ctx, client, settlement, net)
+
+	// Транзакция выплаты могла попасть в сеть: ордера не возвращаются в начисления, чтобы не выплатить их дважды
+	var broadcastErr *BroadcastError
+	if errors.As(err, &broadcastErr) {
+		s.logger.ErrorContext(ctx, "Settlement payout broadcast not confirmed, operator reconciliation required", "error", err,
+			"settlement_id", settlement.ID, "merchant_id", settlement.MerchantID, "tx_hash", broadcastErr.TxHash,
+			"nonce", broadcastErr.Nonce, "net", settlement.NetAmount)
+		if markErr := s.repo.MarkUnknown(ctx, settlement.ID, broadcastErr.TxHash, err.Error()); markErr != nil {
+			s.logger.ErrorContext(ctx, "Failed to mark settlement unknown", "error", markErr, "settlement_id", settlement.ID)
+		}
+		return false
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to pay merchant settlement", "error", err,
+			"settlement_id", settlement.ID, "merchant_id", settlement.MerchantID, "net", settlement.NetAmount)
+		if markErr := s.repo.MarkFailed(ctx, settlement.ID, err.Error()); markErr != nil {
+			s.logger.ErrorContext(ctx, "Failed to mark settlement failed", "error", markErr, "settlement_id", settlement.ID)
+		}
+		// Ордера неудачной выплаты вернулись в начисления
+		s.refreshAccrual(ctx, settlement.MerchantID)
+		return false
+	}
+
+	status := entities.SettlementPaid
+	var txHash, safeTxHash *string
+	if transfer.Proposal != nil {
+		status = entities.SettlementProposed
+		safeTxHash = &transfer.Proposal.SafeTxHash
+	} else {
+		txHash = &transfer.TxHash
+	}
+	if err = s.repo.MarkSubmitted(ctx, settlement.ID, status, txHash, safeTxHash); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record settlement payout", "error", err, "settlement_id", settlement.ID)
+	}
+	settlementsPaid.Add(1)
+
+	asset := s.wallets.Asset()
+	message := fmt.Sprintf("Settlement %s: %s %s for %d orders (fee %s %s) sent to %s",
+		settlement.ID, unitsToTokenAmount(net, asset.Decimals), asset.Code, settlement.Orders,
+		s.formatUnits(settlement.FeeAmount), asset.Code, settlement.Address)
+	if err = s.notifier.Notify(ctx, settlement.MerchantID, "Settlement paid", message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to notify merchant about settlement", "error", err, "settlement_id", settlement.ID)
+	}
+
+	s.logger.InfoContext(ctx, "Merchant settlement paid", "settlement_id", settlement.ID, "merchant_id", settlement.MerchantID,
+		"orders", settlement.Orders, "net", settlement.NetAmount, "status", status)
+	return true
+}
+
+func (s *SettlementService) transfer(ctx context.Context, client *ethclient.Client, settlement *entities.Settlement, net *big.Int) (*entities.TreasuryTransfer, error) {
+	// Адрес мог попасть в черный список токена после настройки
+	if err := s.wallets.ScreenAddresses(ctx, settlement.Address); err != nil {
+		return nil, err
+	}
+	return s.transfers.Transfer(ctx, client, entities.TreasuryTransferSettlement, s.payoutWalletID, settlement.Address, net, settlementInitiatedBy)
+}
+
+// GetAccount returns the settlement account of the merchant
+func (s *SettlementService) GetAccount(ctx context.Context, merchantID int64) (*entities.SettlementAccount, error) {
+	account, err := s.repo.GetAccount(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrSettlementAccountNotFound
+	}
+	return account, nil
+}
+
+// SetAccount sets the settlement address of the merchant. feeBPS overrides the default fee and is set by administrators only.
+func (s *SettlementService) SetAccount(ctx context.Context, merchantID int64, address string, feeBPS *int, actor string) (*entities.SettlementAccount, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidSettlementAccount, address)
+	}
+	if feeBPS != nil && (*feeBPS < 0 || *feeBPS > settlementMaxFeeBPS) {
+		return nil, fmt.Errorf("%w: fee must be between 0 and %d bps", ErrInvalidSettlementAccount, settlementMaxFeeBPS)
+	}
+	address = common.HexToAddress(address).Hex()
+	if err := s.wallets.ScreenAddresses(ctx, address); err != nil {
+		return nil, err
+	}
+
+	account := &entities.SettlementAccount{
+		MerchantID: merchantID,
+		Address:    address,
+		FeeBPS:     feeBPS,
+		UpdatedBy:  actor,
+	}
+	if err := s.repo.SetAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{"address": address}
+	if feeBPS != nil {
+		details["fee_bps"] = *feeBPS
+	}
+	if err := s.audit.Record(ctx, entities.AuditEventSettlementAccountChanged, actor, strconv.FormatInt(merchantID, 10), details); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record settlement account change", "error", err, "merchant_id", merchantID)
+	}
+
+	return account, nil
+}
+
+// GetMerchantSettlements returns the latest settlements of the merchant
+func (s *SettlementService) GetMerchantSettlements(ctx context.Context, merchantID int64) ([]entities.Settlement, error) {
+	return s.repo.FindByMerchant(ctx, merchantID, settlementsListLimit)
+}
+
+// RefreshAccrual recalculates the accrual of the merchant: completed orders not yet assigned to a settlement
+func (s *SettlementService) RefreshAccrual(ctx context.Context, merchantID int64) error {
+	_, err := s.repo.RefreshAccrual(ctx, merchantID, s.wallets.Asset().ID)
+	return err
+}
+
+func (s *SettlementService) refreshAccrual(ctx context.Context, merchantID int64) {
+	if err := s.RefreshAccrual(ctx, merchantID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to refresh merchant accrual", "error", err, "merchant_id", merchantID)
+	}
+}
+
+// GetAccrual returns the accrual of the merchant, empty if the merchant has no completed orders yet
+func (s *SettlementService) GetAccrual(ctx context.Context, merchantID int64) (*entities.SettlementAccrual, error) {
+	assetID := s.wallets.Asset().ID
+	accrual, err := s.repo.FindAccrual(ctx, merchantID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if accrual == nil {
+		return &entities.SettlementAccrual{MerchantID: merchantID, AssetID: assetID, Amount: "0"}, nil
+	}
+	return accrual, nil
+}
+
+// GetSettlements returns the latest settlements with the given status, all statuses if empty
+func (s *SettlementService) GetSettlements(ctx context.Context, status entities.SettlementStatus) ([]entities.Settlement, error) {
+	return s.repo.FindByStatus(ctx, status, settlementsListLimit)
+}
+
+// GetReport returns the settlement with its orders. A positive merchantID restricts access to the merchant's own settlements.
+func (s *SettlementService) GetReport(ctx context.Context, merchantID int64, id string) (*entities.SettlementReport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrSettlementNotFound
+	}
+
+	settlement, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil || (merchantID > 0 && settlement.MerchantID != merchantID) {
+		return nil, ErrSettlementNotFound
+	}
+
+	items, err := s.repo.FindSettlementOrders(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.SettlementReport{
+		Settlement: *settlement,
+		Asset:      s.wallets.Asset().Code,
+		Gross:      s.formatUnits(settlement.GrossAmount),
+		Fee:        s.formatUnits(settlement.FeeAmount),
+		Net:        s.formatUnits(settlement.NetAmount),
+		Items:      items,
+	}, nil
+}
+
+func (s *SettlementService) formatUnits(units string) string {
+	value, ok := new(big.Int).SetString(units, 10)
+	if !ok {
+		return units
+	}
+	return unitsToTokenAmount(value, s.wallets.Asset().Decimals)
+}
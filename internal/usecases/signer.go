@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sandquattro/go-bip32"
+)
+
+// Операции, для которых выполняется подпись транзакций
+const (
+	SignOperationTokenTransfer  = "token_transfer"
+	SignOperationNativeTransfer = "native_transfer"
+	SignOperationSpeedup        = "speedup"
+	SignOperationCancel         = "cancel"
+	SignOperationSafeProposal   = "safe_proposal"
+	SignOperationPermit         = "permit"
+	SignOperationPermitRelay    = "permit_relay"
+	SignOperationApprove        = "approve"
+	SignOperationBatchCollect   = "batch_collect"
+	SignOperationMultiSend      = "multisend"
+	SignOperationForwarderFlush = "forwarder_flush"
+	SignOperationOwnershipProof = "ownership_proof"
+)
+
+// KeySigner подписывает транзакции ключами депозитных кошельков.
+// Приватный ключ не хранится: он заново выводится из сида версии ключа и пути деривации на время
+// одной подписи, после чего ключевой материал затирается, а факт подписи записывается в журнал аудита.
+type KeySigner struct {
+	logger  *slog.Logger
+	keyring *Keyring
+	audit   *AuditService
+
+	// Причина запрета подписи (воспроизведение блоков), пустая — подпись разрешена
+	disabled string
+}
+
+// NewKeySigner creates a new short-lived key signer
+func NewKeySigner(logger *slog.Logger, keyring *Keyring, audit *AuditService) *KeySigner {
+	return &KeySigner{
+		logger:  logger,
+		keyring: keyring,
+		audit:   audit,
+	}
+}
+
+// Disable refuses every later signature with ErrSigningDisabled. Called at startup before any worker runs.
+func (s *KeySigner) Disable(reason string) {
+	s.disabled = reason
+}
+
+func (s *KeySigner) checkEnabled() error {
+	if s.disabled != "" {
+		return fmt.Errorf("%w: %s", ErrSigningDisabled, s.disabled)
+	}
+	return nil
+}
+
+// childKey derives the key of the path from the seed of the key version
+func (s *KeySigner) childKey(keyVersion int, userID, index int64) (*bip32.Key, error) {
+	if s.keyring == nil {
+		return nil, errors.New("master key not initialized")
+	}
+	masterKey, err := s.keyring.Key(keyVersion)
+	if err != nil {
+		return nil, err
+	}
+	return GetChildKey(masterKey, userID, index)
+}
+
+// SignTx re-derives the key for derivationPath from the seed of keyVersion, verifies it controls
+// the expected address, signs the transaction and wipes the key material before returning.
+func (s *KeySigner) SignTx(
+	ctx context.Context,
+	keyVersion int,
+	derivationPath string,
+	expected common.Address,
+	tx *types.Transaction,
+	chainID *big.Int,
+	operation string,
+) (*types.Transaction, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+
+	userID, index, err := ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	childKey, err := s.childKey(keyVersion, userID, index)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(childKey.Key)
+
+	privateKey, address, err := GetWalletPrivateKey(childKey)
+	if err != nil {
+		return nil, err
+	}
+	defer wipePrivateKey(privateKey)
+
+	if address != expected {
+		return nil, fmt.Errorf("cannot derive correct private key for wallet %s, generated %s instead",
+			expected.Hex(), address.Hex())
+	}
+
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	// Подпись без записи в журнал аудита не допускается: такая транзакция не будет отправлена
+	if s.audit != nil {
+		details := map[string]any{
+			"operation":       operation,
+			"derivation_path": derivationPath,
+			"key_version":     keyVersion,
+			"tx_hash":         signedTx.Hash().Hex(),
+			"nonce":           signedTx.Nonce(),
+			"chain_id":        chainID.String(),
+		}
+		if to := signedTx.To(); to != nil {
+			details["to"] = to.Hex()
+		}
+
+		if err = s.audit.Record(ctx, entities.AuditEventKeySigning, "wallet_service", address.Hex(), details); err != nil {
+			return nil, fmt.Errorf("failed to record signing event: %w", err)
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Transaction signed",
+		"address", address.Hex(),
+		"operation", operation,
+		"tx_hash", signedTx.Hash().Hex())
+
+	return signedTx, nil
+}
+
+// SignHash signs a 32-byte digest (e.g. an EIP-712 hash) with the key for derivationPath.
+// The signature is returned in the [R || S || V] form with V = 27/28, as expected by Safe contracts.
+func (s *KeySigner) SignHash(ctx context.Context, keyVersion int, derivationPath string, expected common.Address, hash common.Hash, operation string) ([]byte, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+
+	userID, index, err := ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	childKey, err := s.childKey(keyVersion, userID, index)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(childKey.Key)
+
+	privateKey, address, err := GetWalletPrivateKey(childKey)
+	if err != nil {
+		return nil, err
+	}
+	defer wipePrivateKey(privateKey)
+
+	if address != expected {
+		return nil, fmt.Errorf("cannot derive correct private key for wallet %s, generated %s instead",
+			expected.Hex(), address.Hex())
+	}
+
+	signature, err := crypto.Sign(hash.Bytes(), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign hash: %w", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	if s.audit != nil {
+		if err = s.audit.Record(ctx, entities.AuditEventKeySigning, "wallet_service", address.Hex(), map[string]any{
+			"operation":       operation,
+			"derivation_path": derivationPath,
+			"key_version":     keyVersion,
+			"hash":            hash.Hex(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to record signing event: %w", err)
+		}
+	}
+
+	return signature, nil
+}
+
+// DeriveAddress returns the address for the given user and index derived from the seed of keyVersion
+// without keeping the private key around
+func (s *KeySigner) DeriveAddress(keyVersion int, userID, index int64) (common.Address, error) {
+	childKey, err := s.childKey(keyVersion, userID, index)
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer wipeBytes(childKey.Key)
+
+	privateKey, address, err := GetWalletPrivateKey(childKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	wipePrivateKey(privateKey)
+
+	return address, nil
+}
+
+// wipeBytes затирает содержимое слайса нулями
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// wipePrivateKey затирает скаляр приватного ключа
+func wipePrivateKey(key *ecdsa.PrivateKey) {
+	if key == nil || key.D == nil {
+		return
+	}
+	words := key.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	key.D.SetInt64(0)
+}
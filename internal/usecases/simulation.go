@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Причины, по которым симуляция транзакции завершилась откатом
+const (
+	SimulationReasonPaused                = "token_paused"
+	SimulationReasonBlacklisted           = "address_blacklisted"
+	SimulationReasonInsufficientBalance   = "insufficient_balance"
+	SimulationReasonInsufficientAllowance = "insufficient_allowance"
+	SimulationReasonReverted              = "reverted"
+)
+
+// SimulationError is returned when eth_call of a transaction reverts, so it is not broadcast
+type SimulationError struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("transaction simulation reverted (%s): %s", e.Reason, e.Message)
+}
+
+// simulateTransaction runs the call against the pending state and converts a revert into *SimulationError
+func (bsc *WalletService) simulateTransaction(ctx context.Context, client *ethclient.Client, from, to common.Address, value *big.Int, data []byte) error {
+	_, err := client.PendingCallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+	if err == nil {
+		return nil
+	}
+
+	message, reverted := revertMessage(err)
+	if !reverted {
+		return fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+
+	simErr := &SimulationError{Reason: classifyRevert(message), Message: message}
+	bsc.logger.WarnContext(ctx, "Transaction simulation reverted",
+		"from", from.Hex(),
+		"to", to.Hex(),
+		"reason", simErr.Reason,
+		"message", message)
+
+	return simErr
+}
+
+// revertMessage extracts the revert reason from an eth_call error
+func revertMessage(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if encoded, ok := dataErr.ErrorData().(string); ok {
+			if raw, decodeErr := hexutil.Decode(encoded); decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(raw); unpackErr == nil {
+					return reason, true
+				}
+			}
+		}
+	}
+
+	msg := err.Error()
+	if idx := strings.Index(msg, "execution reverted"); idx >= 0 {
+		reason := strings.TrimPrefix(msg[idx+len("execution reverted"):], ":")
+		return strings.TrimSpace(reason), true
+	}
+
+	return "", false
+}
+
+// classifyRevert maps common ERC-20 revert messages (OpenZeppelin, Tether, BSC-USD) to a reason code
+func classifyRevert(message string) string {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "paused"):
+		return SimulationReasonPaused
+	case strings.Contains(msg, "blacklist"), strings.Contains(msg, "blocked"), strings.Contains(msg, "frozen"):
+		return SimulationReasonBlacklisted
+	case strings.Contains(msg, "allowance"):
+		return SimulationReasonInsufficientAllowance
+	case strings.Contains(msg, "exceeds balance"), strings.Contains(msg, "insufficient balance"):
+		return SimulationReasonInsufficientBalance
+	default:
+		return SimulationReasonReverted
+	}
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// revertDataError mimics the JSON-RPC error of a reverted eth_call carrying the revert data
+type revertDataError struct {
+	message string
+	data    any
+}
+
+func (e *revertDataError) Error() string  { return e.message }
+func (e *revertDataError) ErrorCode() int { return 3 }
+func (e *revertDataError) ErrorData() any { return e.data }
+
+func encodeRevert(t *testing.T, reason string) string {
+	t.Helper()
+
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	packed, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	require.NoError(t, err)
+
+	selector := crypto.Keccak256([]byte("Error(string)"))[:4]
+	return hexutil.Encode(append(selector, packed...))
+}
+
+func TestRevertMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantMessage  string
+		wantReverted bool
+	}{
+		{
+			name:         "revert data",
+			err:          &revertDataError{message: "execution reverted", data: encodeRevert(t, "Pausable: paused")},
+			wantMessage:  "Pausable: paused",
+			wantReverted: true,
+		},
+		{
+			name:         "wrapped revert data",
+			err:          fmt.Errorf("call failed: %w", &revertDataError{message: "execution reverted", data: encodeRevert(t, "ERC20: insufficient allowance")}),
+			wantMessage:  "ERC20: insufficient allowance",
+			wantReverted: true,
+		},
+		{
+			name:         "undecodable data falls back to the message",
+			err:          &revertDataError{message: "execution reverted: BEP20: transfer amount exceeds balance", data: "0xdeadbeef"},
+			wantMessage:  "BEP20: transfer amount exceeds balance",
+			wantReverted: true,
+		},
+		{
+			name:         "reason in the message",
+			err:          errors.New("execution reverted: Blacklistable: account is blacklisted"),
+			wantMessage:  "Blacklistable: account is blacklisted",
+			wantReverted: true,
+		},
+		{
+			name:         "revert without reason",
+			err:          errors.New("execution reverted"),
+			wantMessage:  "",
+			wantReverted: true,
+		},
+		{
+			name:         "not a revert",
+			err:          errors.New("dial tcp: connection refused"),
+			wantReverted: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, reverted := revertMessage(tt.err)
+			assert.Equal(t, tt.wantReverted, reverted)
+			assert.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+func TestClassifyRevert(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Pausable: paused", SimulationReasonPaused},
+		{"Blacklistable: account is blacklisted", SimulationReasonBlacklisted},
+		{"address is BLOCKED", SimulationReasonBlacklisted},
+		{"account frozen", SimulationReasonBlacklisted},
+		{"ERC20: insufficient allowance", SimulationReasonInsufficientAllowance},
+		{"ERC20: transfer amount exceeds allowance", SimulationReasonInsufficientAllowance},
+		{"BEP20: transfer amount exceeds balance", SimulationReasonInsufficientBalance},
+		{"insufficient balance for transfer", SimulationReasonInsufficientBalance},
+		{"", SimulationReasonReverted},
+		{"SafeMath: subtraction overflow", SimulationReasonReverted},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyRevert(tt.message), tt.message)
+	}
+}
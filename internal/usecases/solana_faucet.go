@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/solana"
+)
+
+// solanaFaucetBatchSize ограничивает число кошельков, пополняемых за один проход
+const solanaFaucetBatchSize = 20
+
+// solanaNativeDecimals — SOL делится на 10^9 лампортов
+const solanaNativeDecimals = 9
+
+// solanaFaucetMintUnits — лимит вычислений транзакции крана: создание аккаунта токена и выпуск укладываются с запасом
+const solanaFaucetMintUnits = 30_000
+
+type SolanaFaucetClient interface {
+	RequestAirdrop(ctx context.Context, to solana.PublicKey, lamports uint64) (string, error)
+	LatestBlockhash(ctx context.Context) (solana.PublicKey, error)
+	MinimumBalanceForRentExemption(ctx context.Context, size uint64) (uint64, error)
+	SendTransaction(ctx context.Context, tx solana.Transaction) (string, error)
+}
+
+// SolanaComputeBudget prices a Solana transaction by priority and returns its compute budget instructions
+type SolanaComputeBudget interface {
+	ComputeBudget(ctx context.Context, priority string, units uint32, writable []solana.PublicKey) ([]solana.Instruction, entities.SolanaPriorityFee)
+}
+
+type SolanaFaucetWallets interface {
+	FindChainWalletsCreatedSince(ctx context.Context, chain entities.Chain, network string, since time.Time, afterID, limit int) ([]entities.Wallet, error)
+}
+
+var (
+	_ SolanaFaucetClient  = (*solana.Client)(nil)
+	_ SolanaFaucetWallets = (*repository.WalletsRepository)(nil)
+)
+
+// SolanaFaucetConfig задает суммы крана. Пустой Mint отключает выпуск токена, нулевой Interval — пополнение новых кошельков.
+type SolanaFaucetConfig struct {
+	Network       string
+	AirdropSOL    string
+	Mint          string
+	MintAuthority string // Секретный ключ mint authority, он же платит комиссии выпуска
+	MintDecimals  int
+	MintAmount    string
+	Interval      time.Duration
+}
+
+// SolanaFaucetService funds Solana deposit wallets in test environments: it requests a devnet SOL airdrop and
+// mints test SPL tokens into a new token account owned by the wallet, so QA can exercise the Solana deposit
+// pipeline end to end. New deposit wallets are funded as they appear, other addresses on request.
+// It must only run in blockchain debug mode.
+type SolanaFaucetService struct {
+	logger  *slog.Logger
+	client  SolanaFaucetClient
+	fees    SolanaComputeBudget
+	wallets SolanaFaucetWallets
+
+	network         string
+	airdropSOL      string
+	airdropLamports uint64
+	mint            *solana.PublicKey
+	authority       solana.Keypair
+	mintAmount      string
+	mintUnits       uint64
+	interval        time.Duration
+}
+
+func NewSolanaFaucetService(logger *slog.Logger, client SolanaFaucetClient, fees SolanaComputeBudget, wallets SolanaFaucetWallets, config SolanaFaucetConfig) (*SolanaFaucetService, error) {
+	if config.Network == entities.NetworkMainnet {
+		return nil, fmt.Errorf("Solana faucet cannot run on mainnet")
+	}
+
+	airdrop, err := decimal.Parse(config.AirdropSOL)
+	if err != nil || airdrop.Sign() < 0 {
+		return nil, fmt.Errorf("invalid Solana faucet airdrop amount %q", config.AirdropSOL)
+	}
+	lamports, err := airdrop.Units(solanaNativeDecimals)
+	if err != nil || !lamports.IsUint64() {
+		return nil, fmt.Errorf("invalid Solana faucet airdrop amount %q", config.AirdropSOL)
+	}
+
+	service := &SolanaFaucetService{
+		logger:          logger,
+		client:          client,
+		fees:            fees,
+		wallets:         wallets,
+		network:         config.Network,
+		airdropSOL:      airdrop.String(),
+		airdropLamports: lamports.Uint64(),
+		interval:        config.Interval,
+	}
+
+	if config.Mint != "" {
+		mint, err := solana.ParsePublicKey(config.Mint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Solana faucet mint: %w", err)
+		}
+		if service.authority, err = solana.ParseKeypair(config.MintAuthority); err != nil {
+			return nil, fmt.Errorf("invalid Solana faucet mint authority: %w", err)
+		}
+
+		amount, err := decimal.Parse(config.MintAmount)
+		if err != nil || amount.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid Solana faucet mint amount %q", config.MintAmount)
+		}
+		units, err := amount.Units(config.MintDecimals)
+		if err != nil || !units.IsUint64() {
+			return nil, fmt.Errorf("invalid Solana faucet mint amount %q for %d decimals", config.MintAmount, config.MintDecimals)
+		}
+		service.mint = &mint
+		service.mintAmount = amount.String()
+		service.mintUnits = units.Uint64()
+	}
+	if service.airdropLamports == 0 && service.mint == nil {
+		return nil, fmt.Errorf("Solana faucet has neither an airdrop amount nor a mint")
+	}
+
+	return service, nil
+}
+
+// Start funds Solana wallets of the faucet network created after the start until the context is done
+func (s *SolanaFaucetService) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Info("Solana faucet funds addresses on request only")
+		return
+	}
+
+	since := time.Now()
+	lastID := 0
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			wallets, err := s.wallets.FindChainWalletsCreatedSince(ctx, entities.ChainSolana, s.network, since, lastID, solanaFaucetBatchSize)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Failed to find new Solana wallets", "error", err)
+				continue
+			}
+
+			for _, wallet := range wallets {
+				// Кошелек не пополняется повторно, даже если кран ответил ошибкой: его можно пополнить вручную
+				lastID = wallet.ID
+				if _, err = s.Fund(ctx, wallet.Address); err != nil {
+					s.logger.ErrorContext(ctx, "Failed to fund new Solana wallet", "error", err, "wallet_id", wallet.ID, "address", wallet.Address)
+				}
+			}
+		}
+	}
+}
+
+// Fund airdrops SOL to the address and mints test tokens to it. ErrSolanaFaucetFailed is returned only
+// if nothing was sent, partial failures are reported in the grant.
+func (s *SolanaFaucetService) Fund(ctx context.Context, address string) (*entities.SolanaFaucetGrant, error) {
+	owner, err := solana.ParsePublicKey(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSolanaAddress, err)
+	}
+
+	grant := &entities.SolanaFaucetGrant{
+		Address:    owner.String(),
+		Network:    s.network,
+		AirdropSOL: s.airdropSOL,
+	}
+
+	var errs []error
+	if s.airdropLamports > 0 {
+		if grant.AirdropSignature, err = s.client.RequestAirdrop(ctx, owner, s.airdropLamports); err != nil {
+			grant.AirdropError = err.Error()
+			errs = append(errs, fmt.Errorf("airdrop: %w", err))
+		}
+	}
+	if s.mint != nil {
+		if err = s.mintTo(ctx, owner, grant); err != nil {
+			grant.MintError = err.Error()
+			errs = append(errs, fmt.Errorf("mint: %w", err))
+		}
+	}
+
+	if grant.AirdropSignature == "" && grant.MintSignature == "" {
+		return nil, fmt.Errorf("%w: %w", ErrSolanaFaucetFailed, errors.Join(errs...))
+	}
+
+	s.logger.InfoContext(ctx, "Solana faucet funded address",
+		"address", grant.Address,
+		"network", grant.Network,
+		"airdrop_sol", grant.AirdropSOL,
+		"airdrop_signature", grant.AirdropSignature,
+		"token_account", grant.TokenAccount,
+		"token_amount", grant.TokenAmount,
+		"mint_signature", grant.MintSignature,
+		"errors", errors.Join(errs...))
+	return grant, nil
+}
+
+// mintTo создает аккаунт токена, принадлежащий кошельку, и выпускает в него тестовые токены одной транзакцией
+func (s *SolanaFaucetService) mintTo(ctx context.Context, owner solana.PublicKey, grant *entities.SolanaFaucetGrant) error {
+	account, err := solana.NewKeypair()
+	if err != nil {
+		return err
+	}
+	rent, err := s.client.MinimumBalanceForRentExemption(ctx, solana.TokenAccountSize)
+	if err != nil {
+		return err
+	}
+	blockhash, err := s.client.LatestBlockhash(ctx)
+	if err != nil {
+		return err
+	}
+
+	payer := s.authority.PublicKey()
+	instructions, fee := s.fees.ComputeBudget(ctx, PriorityLow, solanaFaucetMintUnits, []solana.PublicKey{payer, *s.mint})
+	instructions = append(instructions,
+		solana.CreateAccount(payer, account.PublicKey(), rent, solana.TokenAccountSize, solana.TokenProgramID),
+		solana.InitializeTokenAccount(account.PublicKey(), *s.mint, owner),
+		solana.MintTo(*s.mint, account.PublicKey(), payer, s.mintUnits),
+	)
+	tx, err := solana.NewTransaction(instructions, blockhash, s.authority, account)
+	if err != nil {
+		return err
+	}
+
+	signature, err := s.client.SendTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	grant.Mint = s.mint.String()
+	grant.TokenAccount = account.PublicKey().String()
+	grant.TokenAmount = s.mintAmount
+	grant.MintSignature = signature
+	grant.PriorityFee = &fee
+	return nil
+}
@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/solana"
+)
+
+type SolanaFeeClient interface {
+	RecentPrioritizationFees(ctx context.Context, writable []solana.PublicKey) ([]solana.PrioritizationFee, error)
+}
+
+var (
+	_ SolanaFeeClient     = (*solana.Client)(nil)
+	_ SolanaComputeBudget = (*SolanaPriorityFeeService)(nil)
+)
+
+// SolanaPriorityFeeConfig задает приоритетные комиссии Solana. Цена единицы вычислений — перцентиль комиссий
+// недавних слотов по записываемым аккаунтам, умноженный на множитель приоритета, как у цены газа BSC,
+// и ограниченный MinMicroLamports и MaxMicroLamports.
+type SolanaPriorityFeeConfig struct {
+	ComputeUnitLimit uint32 // Лимит вычислений, если отправитель не задал свой
+	Percentile       int
+	Multipliers      map[string]float64 // По приоритетам low, medium, high
+	MinMicroLamports uint64
+	MaxMicroLamports uint64
+}
+
+// SolanaPriorityFeeService prices Solana transactions (sweeps, withdrawals, faucet mints) from recent
+// prioritization fees and prepends the compute budget instructions to them
+type SolanaPriorityFeeService struct {
+	logger *slog.Logger
+	client SolanaFeeClient
+
+	unitLimit   uint32
+	percentile  int
+	multipliers map[string]float64
+	minPrice    uint64
+	maxPrice    uint64
+}
+
+func NewSolanaPriorityFeeService(logger *slog.Logger, client SolanaFeeClient, config SolanaPriorityFeeConfig) (*SolanaPriorityFeeService, error) {
+	if config.ComputeUnitLimit == 0 || config.ComputeUnitLimit > solana.MaxComputeUnitLimit {
+		return nil, fmt.Errorf("Solana compute unit limit must be between 1 and %d", solana.MaxComputeUnitLimit)
+	}
+	if config.Percentile < 1 || config.Percentile > 100 {
+		return nil, fmt.Errorf("Solana priority fee percentile must be between 1 and 100")
+	}
+	for _, priority := range feePriorities {
+		if config.Multipliers[priority] <= 0 {
+			return nil, fmt.Errorf("Solana priority fee multiplier for %s priority must be positive", priority)
+		}
+	}
+	if config.MaxMicroLamports < config.MinMicroLamports {
+		return nil, fmt.Errorf("Solana maximum priority fee must not be below the minimum")
+	}
+
+	return &SolanaPriorityFeeService{
+		logger:      logger,
+		client:      client,
+		unitLimit:   config.ComputeUnitLimit,
+		percentile:  config.Percentile,
+		multipliers: config.Multipliers,
+		minPrice:    config.MinMicroLamports,
+		maxPrice:    config.MaxMicroLamports,
+	}, nil
+}
+
+// Quote prices a transaction of the priority writing to the accounts. A zero units uses the configured limit.
+// Without recent fee data the minimum price is used, so sends are not blocked by the RPC.
+func (s *SolanaPriorityFeeService) Quote(ctx context.Context, priority string, units uint32, writable []solana.PublicKey) entities.SolanaPriorityFee {
+	multiplier, ok := s.multipliers[priority]
+	if !ok {
+		priority = PriorityMedium
+		multiplier = s.multipliers[PriorityMedium]
+	}
+	if units == 0 {
+		units = s.unitLimit
+	}
+
+	fee := entities.SolanaPriorityFee{
+		Priority:         priority,
+		ComputeUnitLimit: units,
+	}
+
+	recent, err := s.client.RecentPrioritizationFees(ctx, writable)
+	if err != nil || len(recent) == 0 {
+		s.logger.WarnContext(ctx, "Recent Solana prioritization fees unavailable, using the minimum price",
+			"error", err,
+			"priority", priority)
+		fee.Fallback = true
+		fee.MicroLamportsPerUnit = s.minPrice
+	} else {
+		fee.RecentFee = feePercentile(recent, s.percentile)
+		price := float64(fee.RecentFee) * multiplier
+		fee.MicroLamportsPerUnit = s.maxPrice
+		if price < float64(s.maxPrice) {
+			fee.MicroLamportsPerUnit = max(uint64(math.Ceil(price)), s.minPrice)
+		}
+	}
+
+	fee.FeeLamports = solana.PriorityFeeLamports(fee.ComputeUnitLimit, fee.MicroLamportsPerUnit)
+	return fee
+}
+
+// ComputeBudget returns the compute budget instructions to put first in a transaction of the priority
+func (s *SolanaPriorityFeeService) ComputeBudget(ctx context.Context, priority string, units uint32, writable []solana.PublicKey) ([]solana.Instruction, entities.SolanaPriorityFee) {
+	fee := s.Quote(ctx, priority, units, writable)
+	return []solana.Instruction{
+		solana.SetComputeUnitLimit(fee.ComputeUnitLimit),
+		solana.SetComputeUnitPrice(fee.MicroLamportsPerUnit),
+	}, fee
+}
+
+// feePercentile возвращает перцентиль комиссий по слотам методом ближайшего ранга
+func feePercentile(recent []solana.PrioritizationFee, percentile int) uint64 {
+	fees := make([]uint64, 0, len(recent))
+	for _, slot := range recent {
+		fees = append(fees, slot.PrioritizationFee)
+	}
+	slices.Sort(fees)
+
+	rank := (percentile*len(fees) + 99) / 100
+	return fees[max(rank, 1)-1]
+}
@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	staffNoteMaxLength        = 4000
+	staffAnnotationsListLimit = 100
+)
+
+// Теги в нижнем регистре: буквы, цифры и разделители _ - . :
+var staffTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,49}$`)
+
+type StaffAnnotationsRepository interface {
+	SubjectExists(ctx context.Context, subjectType entities.StaffSubjectType, subjectID string) (bool, error)
+	CreateNote(ctx context.Context, note *entities.StaffNote) error
+	AddTag(ctx context.Context, tag *entities.StaffTag) (bool, error)
+	RemoveTag(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, tag string) (bool, error)
+	FindSubjects(ctx context.Context, filter entities.StaffAnnotationFilter, limit int) ([]entities.StaffAnnotations, error)
+	FindNotes(ctx context.Context, subjects []entities.StaffAnnotations) ([]entities.StaffNote, error)
+	FindTags(ctx context.Context, subjects []entities.StaffAnnotations) ([]entities.StaffTag, error)
+}
+
+var _ StaffAnnotationsRepository = (*repository.StaffAnnotationsRepository)(nil)
+
+// StaffAnnotationService keeps internal notes and tags that support and compliance attach to transactions
+// and orders. Annotations are served by the admin API only and are never shown to users.
+type StaffAnnotationService struct {
+	logger *slog.Logger
+	repo   StaffAnnotationsRepository
+	audit  *AuditService
+}
+
+func NewStaffAnnotationService(logger *slog.Logger, repo StaffAnnotationsRepository, audit *AuditService) *StaffAnnotationService {
+	return &StaffAnnotationService{
+		logger: logger,
+		repo:   repo,
+		audit:  audit,
+	}
+}
+
+// GetAnnotations returns the notes and tags of the transaction or order
+func (s *StaffAnnotationService) GetAnnotations(ctx context.Context, subjectType entities.StaffSubjectType, subjectID string) (*entities.StaffAnnotations, error) {
+	if err := s.checkSubject(ctx, subjectType, subjectID); err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.load(ctx, []entities.StaffAnnotations{{SubjectType: subjectType, SubjectID: subjectID}})
+	if err != nil {
+		return nil, err
+	}
+	return &annotations[0], nil
+}
+
+// AddNote attaches a note to the transaction or order
+func (s *StaffAnnotationService) AddNote(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, body, actor string) (*entities.StaffNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len(body) > staffNoteMaxLength {
+		return nil, fmt.Errorf("%w: note must be 1 to %d characters", ErrInvalidStaffAnnotation, staffNoteMaxLength)
+	}
+	if err := s.checkSubject(ctx, subjectType, subjectID); err != nil {
+		return nil, err
+	}
+
+	note := &entities.StaffNote{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Body:        body,
+		Author:      actor,
+	}
+	if err := s.repo.CreateNote(ctx, note); err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, entities.AuditEventStaffNoteAdded, actor, subjectType, subjectID, map[string]any{"note_id": note.ID})
+	return note, nil
+}
+
+// AddTag tags the transaction or order. Tags are lowercased.
+func (s *StaffAnnotationService) AddTag(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, tag, actor string) (*entities.StaffTag, error) {
+	tag, err := normalizeStaffTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkSubject(ctx, subjectType, subjectID); err != nil {
+		return nil, err
+	}
+
+	staffTag := &entities.StaffTag{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Tag:         tag,
+		AddedBy:     actor,
+	}
+	added, err := s.repo.AddTag(ctx, staffTag)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, fmt.Errorf("%w: %s", ErrStaffTagExists, tag)
+	}
+
+	s.record(ctx, entities.AuditEventStaffTagAdded, actor, subjectType, subjectID, map[string]any{"tag": tag})
+	return staffTag, nil
+}
+
+// RemoveTag removes the tag from the transaction or order
+func (s *StaffAnnotationService) RemoveTag(ctx context.Context, subjectType entities.StaffSubjectType, subjectID, tag, actor string) error {
+	tag, err := normalizeStaffTag(tag)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveTag(ctx, subjectType, subjectID, tag)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("%w: %s", ErrStaffTagNotFound, tag)
+	}
+
+	s.record(ctx, entities.AuditEventStaffTagRemoved, actor, subjectType, subjectID, map[string]any{"tag": tag})
+	return nil
+}
+
+// Search returns annotated transactions and orders with the tag and notes containing the query,
+// most recently annotated first. An empty filter returns the latest annotated subjects.
+func (s *StaffAnnotationService) Search(ctx context.Context, filter entities.StaffAnnotationFilter) ([]entities.StaffAnnotations, error) {
+	switch filter.SubjectType {
+	case "", entities.StaffSubjectTransaction, entities.StaffSubjectOrder:
+	default:
+		return nil, fmt.Errorf("%w: unknown subject type %q", ErrInvalidStaffAnnotation, filter.SubjectType)
+	}
+	if filter.Tag != "" {
+		tag, err := normalizeStaffTag(filter.Tag)
+		if err != nil {
+			return nil, err
+		}
+		filter.Tag = tag
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	subjects, err := s.repo.FindSubjects(ctx, filter, staffAnnotationsListLimit)
+	if err != nil || len(subjects) == 0 {
+		return subjects, err
+	}
+	return s.load(ctx, subjects)
+}
+
+// load дополняет объекты их заметками и тегами
+func (s *StaffAnnotationService) load(ctx context.Context, subjects []entities.StaffAnnotations) ([]entities.StaffAnnotations, error) {
+	notes, err := s.repo.FindNotes(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.repo.FindTags(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[entities.StaffSubjectType]map[string]int, 2)
+	for i := range subjects {
+		subjects[i].Notes = []entities.StaffNote{}
+		subjects[i].Tags = []entities.StaffTag{}
+		if index[subjects[i].SubjectType] == nil {
+			index[subjects[i].SubjectType] = make(map[string]int)
+		}
+		index[subjects[i].SubjectType][subjects[i].SubjectID] = i
+	}
+	for _, note := range notes {
+		i := index[note.SubjectType][note.SubjectID]
+		subjects[i].Notes = append(subjects[i].Notes, note)
+	}
+	for _, tag := range tags {
+		i := index[tag.SubjectType][tag.SubjectID]
+		subjects[i].Tags = append(subjects[i].Tags, tag)
+	}
+	return subjects, nil
+}
+
+func (s *StaffAnnotationService) checkSubject(ctx context.Context, subjectType entities.StaffSubjectType, subjectID string) error {
+	exists, err := s.repo.SubjectExists(ctx, subjectType, subjectID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if subjectType == entities.StaffSubjectOrder {
+		return ErrOrderNotFound
+	}
+	return ErrTransactionNotFound
+}
+
+func (s *StaffAnnotationService) record(ctx context.Context, event entities.AuditEventType, actor string, subjectType entities.StaffSubjectType, subjectID string, details map[string]any) {
+	subject := fmt.Sprintf("%s:%s", subjectType, subjectID)
+	if err := s.audit.Record(ctx, event, actor, subject, details); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record staff annotation", "error", err, "subject", subject, "event", event)
+	}
+}
+
+func normalizeStaffTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !staffTagPattern.MatchString(tag) {
+		return "", fmt.Errorf("%w: invalid tag %q", ErrInvalidStaffAnnotation, tag)
+	}
+	return tag, nil
+}
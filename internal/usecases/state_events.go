@@ -0,0 +1,255 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// stateEventReplayPage — сколько событий журнала читается за один запрос при воспроизведении
+const stateEventReplayPage = 5000
+
+type StateEventsRepository interface {
+	FindOrderEvents(ctx context.Context, orderID int64) ([]entities.OrderEvent, error)
+	FindTransactionEvents(ctx context.Context, txHash string) ([]entities.TransactionEvent, error)
+	ListOrderEvents(ctx context.Context, afterID int64, until time.Time, limit int) ([]entities.OrderEvent, error)
+	ListTransactionEvents(ctx context.Context, afterID int64, until time.Time, limit int) ([]entities.TransactionEvent, error)
+	FindWalletNetworks(ctx context.Context) ([]entities.WalletNetwork, error)
+}
+
+type StateEventsProjections interface {
+	ReplaceDays(ctx context.Context, orders []entities.DashboardOrderDay, deposits []entities.DashboardDepositDay) error
+}
+
+var (
+	_ StateEventsRepository  = (*repository.StateEventsRepository)(nil)
+	_ StateEventsProjections = (*repository.DashboardRepository)(nil)
+)
+
+// StateEventService exposes the order and transaction event journals and replays them into read models.
+// The journals are written by database triggers in the same transaction as the state change.
+type StateEventService struct {
+	logger      *slog.Logger
+	repo        StateEventsRepository
+	projections StateEventsProjections
+}
+
+func NewStateEventService(logger *slog.Logger, repo StateEventsRepository, projections StateEventsProjections) *StateEventService {
+	return &StateEventService{
+		logger:      logger,
+		repo:        repo,
+		projections: projections,
+	}
+}
+
+// GetOrderHistory returns every recorded state change of the order, oldest first
+func (s *StateEventService) GetOrderHistory(ctx context.Context, orderID int64) ([]entities.OrderEvent, error) {
+	events, err := s.repo.FindOrderEvents(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	return events, nil
+}
+
+// GetTransactionHistory returns every recorded state change of the transaction, oldest first
+func (s *StateEventService) GetTransactionHistory(ctx context.Context, txHash string) ([]entities.TransactionEvent, error) {
+	events, err := s.repo.FindTransactionEvents(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrTransactionNotFound
+	}
+	return events, nil
+}
+
+// orderEventState — поля строки orders, нужные проекциям
+type orderEventState struct {
+	Status    entities.OrderStatus `json:"status"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// transactionEventState — поля строки transactions, нужные проекциям
+type transactionEventState struct {
+	WalletAddress string     `json:"wallet_address"`
+	Amount        string     `json:"amount"`
+	Confirmed     bool       `json:"confirmed"`
+	CreditedAt    *time.Time `json:"credited_at"`
+	IgnoredReason *string    `json:"ignored_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ReplayDashboard rebuilds the dashboard projections from the event journals as of until: the last event
+// of every row before until is its state, deleted rows are dropped
+func (s *StateEventService) ReplayDashboard(ctx context.Context, until time.Time) (*entities.DashboardReplay, error) {
+	replay := &entities.DashboardReplay{Until: until}
+
+	orders := make(map[int64]*orderEventState)
+	for afterID := int64(0); ; {
+		events, err := s.repo.ListOrderEvents(ctx, afterID, until, stateEventReplayPage)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			afterID = event.ID
+			replay.OrderEvents++
+			if event.EventType == entities.StateEventDeleted {
+				delete(orders, event.OrderID)
+				continue
+			}
+			var state orderEventState
+			if err = json.Unmarshal(event.State, &state); err != nil {
+				return nil, fmt.Errorf("invalid state of order event %d: %w", event.ID, err)
+			}
+			orders[event.OrderID] = &state
+		}
+		if len(events) < stateEventReplayPage {
+			break
+		}
+	}
+
+	deposits := make(map[string]*transactionEventState)
+	for afterID := int64(0); ; {
+		events, err := s.repo.ListTransactionEvents(ctx, afterID, until, stateEventReplayPage)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			afterID = event.ID
+			replay.TransactionEvents++
+			if event.EventType == entities.StateEventDeleted {
+				delete(deposits, event.TxHash)
+				continue
+			}
+			var state transactionEventState
+			if err = json.Unmarshal(event.State, &state); err != nil {
+				return nil, fmt.Errorf("invalid state of transaction event %d: %w", event.ID, err)
+			}
+			deposits[event.TxHash] = &state
+		}
+		if len(events) < stateEventReplayPage {
+			break
+		}
+	}
+
+	replay.Orders = replayOrderDays(orders)
+
+	wallets, err := s.repo.FindWalletNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replay.Deposits, err = replayDepositDays(deposits, wallets)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Event journals replayed",
+		"until", until,
+		"order_events", replay.OrderEvents,
+		"transaction_events", replay.TransactionEvents,
+		"orders", len(orders),
+		"deposits", len(deposits))
+	return replay, nil
+}
+
+// ApplyReplay replaces the live dashboard projections with the replayed ones
+func (s *StateEventService) ApplyReplay(ctx context.Context, replay *entities.DashboardReplay) error {
+	return s.projections.ReplaceDays(ctx, replay.Orders, replay.Deposits)
+}
+
+func replayOrderDays(orders map[int64]*orderEventState) []entities.DashboardOrderDay {
+	type dayKey struct {
+		day    time.Time
+		status entities.OrderStatus
+	}
+	counts := make(map[dayKey]int)
+	for _, order := range orders {
+		counts[dayKey{day: order.CreatedAt.UTC().Truncate(24 * time.Hour), status: order.Status}]++
+	}
+
+	days := make([]entities.DashboardOrderDay, 0, len(counts))
+	for key, count := range counts {
+		days = append(days, entities.DashboardOrderDay{Day: key.day, Status: key.status, Orders: count})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if !days[i].Day.Equal(days[j].Day) {
+			return days[i].Day.Before(days[j].Day)
+		}
+		return days[i].Status < days[j].Status
+	})
+	return days
+}
+
+// replayDepositDays считает депозиты так же, как проектор: по дню обнаружения и сети кошелька,
+// депозиты на неизвестные кошельки не учитываются
+func replayDepositDays(deposits map[string]*transactionEventState, wallets []entities.WalletNetwork) ([]entities.DashboardDepositDay, error) {
+	networks := make(map[string]entities.WalletNetwork, len(wallets))
+	for _, wallet := range wallets {
+		networks[wallet.Address] = wallet
+	}
+
+	type dayKey struct {
+		day     time.Time
+		chain   entities.Chain
+		network string
+	}
+	rows := make(map[dayKey]*entities.DashboardDepositDay)
+	volumes := make(map[dayKey]*big.Int)
+	for txHash, deposit := range deposits {
+		wallet, ok := networks[strings.ToLower(deposit.WalletAddress)]
+		if !ok {
+			continue
+		}
+		key := dayKey{day: deposit.CreatedAt.UTC().Truncate(24 * time.Hour), chain: wallet.Chain, network: wallet.Network}
+		row, ok := rows[key]
+		if !ok {
+			row = &entities.DashboardDepositDay{Day: key.day, Chain: key.chain, Network: key.network}
+			rows[key] = row
+			volumes[key] = new(big.Int)
+		}
+
+		row.Detected++
+		if deposit.IgnoredReason != nil {
+			row.Ignored++
+			continue
+		}
+		if deposit.CreditedAt != nil {
+			row.Credited++
+		}
+		if deposit.Confirmed {
+			amount, ok := new(big.Int).SetString(deposit.Amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid amount %q of transaction %s", deposit.Amount, txHash)
+			}
+			row.Confirmed++
+			volumes[key].Add(volumes[key], amount)
+		}
+	}
+
+	days := make([]entities.DashboardDepositDay, 0, len(rows))
+	for key, row := range rows {
+		row.Volume = volumes[key].String()
+		days = append(days, *row)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if !days[i].Day.Equal(days[j].Day) {
+			return days[i].Day.Before(days[j].Day)
+		}
+		if days[i].Chain != days[j].Chain {
+			return days[i].Chain < days[j].Chain
+		}
+		return days[i].Network < days[j].Network
+	})
+	return days, nil
+}
@@ -0,0 +1,180 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+const (
+	// Снимок статуса кешируется: страница статуса публичная и не должна нагружать базу
+	statusCacheTTL     = 5 * time.Second
+	statusPingTimeout  = 2 * time.Second
+	statusDownAfterAge = 3 // Подсистема без работы дольше StaleAfter * statusDownAfterAge считается недоступной
+)
+
+type StatusDatabase interface {
+	Ping(ctx context.Context) error
+}
+
+// StatusHeartbeat — подсистема, отмечающая время последней успешной работы
+type StatusHeartbeat interface {
+	LastHeartbeat() time.Time
+}
+
+type StatusAML interface {
+	ProviderHealth() (enabled, failing int)
+}
+
+type StatusAssets interface {
+	List() []entities.Asset
+}
+
+type StatusTokens interface {
+	Halted() bool
+}
+
+var (
+	_ StatusDatabase  = (*pgxpool.Pool)(nil)
+	_ StatusHeartbeat = (*TonDepositService)(nil)
+	_ StatusAML       = (*AMLService)(nil)
+	_ StatusAssets    = (*AssetRegistry)(nil)
+	_ StatusTokens    = (*TokenMonitorService)(nil)
+)
+
+// StatusComponent — подсистема на странице статуса. Без работы дольше StaleAfter подсистема деградирована.
+type StatusComponent struct {
+	Name       string
+	Heartbeat  StatusHeartbeat
+	StaleAfter time.Duration
+}
+
+// StatusService reports coarse health of subsystems for a public status page. Errors and endpoints
+// are never exposed, only the state of each subsystem and the enabled degraded modes.
+type StatusService struct {
+	logger     *slog.Logger
+	db         StatusDatabase
+	aml        StatusAML
+	assets     StatusAssets
+	tokens     StatusTokens
+	components []StatusComponent
+	startedAt  time.Time
+
+	mu     sync.Mutex
+	cached *entities.SystemStatus
+}
+
+func NewStatusService(logger *slog.Logger, db StatusDatabase, aml StatusAML, assets StatusAssets, tokens StatusTokens, components ...StatusComponent) *StatusService {
+	return &StatusService{
+		logger:     logger,
+		db:         db,
+		aml:        aml,
+		assets:     assets,
+		tokens:     tokens,
+		components: components,
+		startedAt:  time.Now(),
+	}
+}
+
+// GetStatus returns the current system status, reusing a snapshot taken within the last few seconds
+func (s *StatusService) GetStatus(ctx context.Context) *entities.SystemStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cached.UpdatedAt) < statusCacheTTL {
+		return s.cached
+	}
+
+	now := time.Now()
+	status := &entities.SystemStatus{
+		Status:        entities.SubsystemOperational,
+		StartedAt:     s.startedAt.UTC(),
+		UptimeSeconds: int64(now.Sub(s.startedAt).Seconds()),
+		UpdatedAt:     now.UTC(),
+	}
+
+	status.Subsystems = append(status.Subsystems, entities.SubsystemStatus{Name: "database", State: s.databaseState(ctx)})
+	for _, component := range s.components {
+		status.Subsystems = append(status.Subsystems, entities.SubsystemStatus{
+			Name:  component.Name,
+			State: s.heartbeatState(component, now),
+		})
+	}
+	status.Subsystems = append(status.Subsystems, entities.SubsystemStatus{Name: "aml", State: s.amlState()})
+
+	for _, asset := range s.assets.List() {
+		if !asset.DepositsEnabled {
+			status.DegradedModes.DepositsPaused = append(status.DegradedModes.DepositsPaused, asset.Code)
+		}
+		if !asset.WithdrawalsEnabled {
+			status.DegradedModes.WithdrawalsPaused = append(status.DegradedModes.WithdrawalsPaused, asset.Code)
+		}
+	}
+	status.DegradedModes.TokenOperationsHalted = s.tokens.Halted()
+
+	for _, subsystem := range status.Subsystems {
+		status.Status = worseState(status.Status, subsystem.State)
+	}
+	modes := status.DegradedModes
+	if len(modes.DepositsPaused) > 0 || len(modes.WithdrawalsPaused) > 0 || modes.TokenOperationsHalted {
+		status.Status = worseState(status.Status, entities.SubsystemDegraded)
+	}
+
+	s.cached = status
+	return status
+}
+
+func (s *StatusService) databaseState(ctx context.Context) entities.SubsystemState {
+	pingCtx, cancel := context.WithTimeout(ctx, statusPingTimeout)
+	defer cancel()
+
+	if err := s.db.Ping(pingCtx); err != nil {
+		s.logger.WarnContext(ctx, "Status database ping failed", "error", err)
+		return entities.SubsystemDown
+	}
+	return entities.SubsystemOperational
+}
+
+// heartbeatState оценивает подсистему по времени ее последней работы. Сразу после запуска
+// подсистема еще не успела отметиться и считается работающей.
+func (s *StatusService) heartbeatState(component StatusComponent, now time.Time) entities.SubsystemState {
+	last := component.Heartbeat.LastHeartbeat()
+	if last.IsZero() {
+		last = s.startedAt
+	}
+
+	age := now.Sub(last)
+	switch {
+	case age <= component.StaleAfter:
+		return entities.SubsystemOperational
+	case age <= component.StaleAfter*statusDownAfterAge:
+		return entities.SubsystemDegraded
+	default:
+		return entities.SubsystemDown
+	}
+}
+
+// amlState: локальная проверка доступна всегда, поэтому сбой внешних провайдеров — деградация, а не отказ
+func (s *StatusService) amlState() entities.SubsystemState {
+	if _, failing := s.aml.ProviderHealth(); failing > 0 {
+		return entities.SubsystemDegraded
+	}
+	return entities.SubsystemOperational
+}
+
+func worseState(a, b entities.SubsystemState) entities.SubsystemState {
+	rank := map[entities.SubsystemState]int{
+		entities.SubsystemOperational: 0,
+		entities.SubsystemDegraded:    1,
+		entities.SubsystemDown:        2,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// escalatedTransactions — число исходящих транзакций, не подтвержденных после всех автоматических ускорений
+var escalatedTransactions = expvar.NewInt("bsc_escalated_transactions")
+
+// StuckTxConfig describes how outgoing transactions still pending after automatic speedups are escalated
+type StuckTxConfig struct {
+	// Число автоматических ускорений, после которого транзакция эскалируется
+	MaxSpeedups int
+	// Отменять эскалированную транзакцию нулевым переводом на собственный адрес с тем же нонсом
+	AutoCancel bool
+}
+
+// escalateTransaction alerts operators once about a transaction that is still pending after all automatic speedups
+// and cancels it if auto-cancel is enabled. Escalated transactions are no longer sped up automatically.
+func (bsc *WalletService) escalateTransaction(ctx context.Context, client *ethclient.Client, pendingTx *PendingTransaction) {
+	bsc.pendingTxsMu.Lock()
+	alreadyEscalated := pendingTx.Escalated
+	pendingTx.Escalated = true
+	bsc.pendingTxsMu.Unlock()
+
+	if !alreadyEscalated {
+		escalatedTransactions.Add(1)
+		bsc.logger.ErrorContext(ctx, "Outgoing transaction stuck after speedups, operator action required",
+			"tx_hash", pendingTx.TxHash,
+			"from", pendingTx.FromAddress.Hex(),
+			"to", pendingTx.ToAddress.Hex(),
+			"nonce", pendingTx.Nonce,
+			"speedups", pendingTx.Speedups,
+			"gas_price", pendingTx.GasPrice.String(),
+			"pending_for", time.Since(pendingTx.FirstSentAt).Round(time.Second).String(),
+			"auto_cancel", bsc.stuck.AutoCancel)
+	}
+
+	if !bsc.stuck.AutoCancel || pendingTx.Cancelling {
+		return
+	}
+	if _, err := bsc.replaceTransaction(ctx, client, pendingTx, true); err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to cancel stuck transaction", "tx_hash", pendingTx.TxHash, "error", err)
+	}
+}
+
+// GetStuckTransactions returns outgoing transactions pending longer than MaxPendingTxTime, oldest first
+func (bsc *WalletService) GetStuckTransactions() []entities.StuckTransaction {
+	now := time.Now()
+
+	bsc.pendingTxsMu.RLock()
+	stuck := make([]entities.StuckTransaction, 0)
+	for _, pendingTx := range bsc.pendingTxs {
+		if now.Sub(pendingTx.FirstSentAt) <= MaxPendingTxTime {
+			continue
+		}
+		stuck = append(stuck, entities.StuckTransaction{
+			TxHash:      pendingTx.TxHash,
+			FromAddress: pendingTx.FromAddress.Hex(),
+			ToAddress:   pendingTx.ToAddress.Hex(),
+			Nonce:       pendingTx.Nonce,
+			Amount:      pendingTx.Amount.String(),
+			GasPrice:    pendingTx.GasPrice.String(),
+			Speedups:    pendingTx.Speedups,
+			FirstSentAt: pendingTx.FirstSentAt,
+			LastSentAt:  pendingTx.CreatedAt,
+			AgeSeconds:  now.Sub(pendingTx.FirstSentAt).Seconds(),
+			Escalated:   pendingTx.Escalated,
+			Cancelling:  pendingTx.Cancelling,
+		})
+	}
+	bsc.pendingTxsMu.RUnlock()
+
+	sort.Slice(stuck, func(i, j int) bool { return stuck[i].FirstSentAt.Before(stuck[j].FirstSentAt) })
+	return stuck
+}
+
+// BumpTransaction replaces a pending outgoing transaction with the same one at a higher gas price
+func (bsc *WalletService) BumpTransaction(ctx context.Context, client *ethclient.Client, txHash, actor string) (string, error) {
+	return bsc.replacePendingByHash(ctx, client, txHash, actor, false)
+}
+
+// CancelTransaction replaces a pending outgoing transaction with a zero-value transfer to the sender
+func (bsc *WalletService) CancelTransaction(ctx context.Context, client *ethclient.Client, txHash, actor string) (string, error) {
+	return bsc.replacePendingByHash(ctx, client, txHash, actor, true)
+}
+
+func (bsc *WalletService) replacePendingByHash(ctx context.Context, client *ethclient.Client, txHash, actor string, cancel bool) (string, error) {
+	bsc.pendingTxsMu.RLock()
+	pendingTx, ok := bsc.pendingTxs[common.HexToHash(txHash).Hex()]
+	bsc.pendingTxsMu.RUnlock()
+	if !ok {
+		return "", ErrPendingTxNotFound
+	}
+	if cancel && pendingTx.Cancelling {
+		return "", ErrTxAlreadyCancelling
+	}
+
+	newTxHash, err := bsc.replaceTransaction(ctx, client, pendingTx, cancel)
+	if err != nil {
+		return "", err
+	}
+
+	event := entities.AuditEventStuckTxBumped
+	if cancel {
+		event = entities.AuditEventStuckTxCancelled
+	}
+	if err = bsc.audit.Record(ctx, event, actor, pendingTx.TxHash, map[string]any{
+		"new_tx_hash": newTxHash,
+		"from":        pendingTx.FromAddress.Hex(),
+		"nonce":       pendingTx.Nonce,
+		"speedups":    pendingTx.Speedups + 1,
+	}); err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to record stuck transaction audit", "error", err, "tx_hash", pendingTx.TxHash)
+	}
+
+	return newTxHash, nil
+}
@@ -0,0 +1,366 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// Внеочередной свип запускается не чаще, чем раз в sweepScheduleCooldown
+const sweepScheduleCooldown = time.Minute
+
+type SweepWalletsRepository interface {
+	GetAllTrackedWallets(ctx context.Context) ([]entities.Wallet, error)
+	FindAddressesWithFrozenFunds(ctx context.Context) (map[string]bool, error)
+}
+
+// SweepWallets читает балансы депозитных кошельков и выполняет свипы: обычные переводы или gasless через permit
+type SweepWallets interface {
+	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
+	SupportsPermit(ctx context.Context, client *ethclient.Client) (bool, error)
+	TransferFunds(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (string, error)
+	SweepWithPermit(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int, relayerPath string) (string, error)
+	TokenAllowance(ctx context.Context, client *ethclient.Client, owner, spender common.Address) (*big.Int, error)
+	ApproveSpender(ctx context.Context, client *ethclient.Client, walletID int, spender common.Address) (string, error)
+	CollectBatch(ctx context.Context, client *ethclient.Client, collector common.Address, operatorPath string, from []common.Address, amounts []*big.Int, to common.Address) (string, error)
+	ScreenAddresses(ctx context.Context, addresses ...string) error
+	CheckTokenHalt() error
+	Asset() entities.Asset
+}
+
+// SweepTransfers отправляет переводы с кошельков через казначейство: напрямую или предложением Safe
+type SweepTransfers interface {
+	Transfer(ctx context.Context, client *ethclient.Client, kind entities.TreasuryTransferKind, fromWalletID int, toAddress string, amount *big.Int, initiatedBy string) (*entities.TreasuryTransfer, error)
+}
+
+var (
+	_ SweepWalletsRepository = (*repository.WalletsRepository)(nil)
+	_ SweepWallets           = (*WalletService)(nil)
+	_ SweepTransfers         = (*TreasuryService)(nil)
+)
+
+// SweepConfig describes where and how deposit wallets are swept
+type SweepConfig struct {
+	Destination string
+	MinAmount   string // В единицах актива
+	Interval    time.Duration
+	// Gasless свипы через EIP-2612 permit, если токен его поддерживает
+	Gasless     bool
+	RelayerPath string
+	// Пакетные свипы через контракт BatchCollector: один collect вместо транзакции на кошелек.
+	// Оператор коллектора — ключ по RelayerPath.
+	CollectorAddress string
+	BatchSize        int
+}
+
+// SweepService periodically consolidates USDT from deposit wallets to the destination (master or treasury) wallet.
+// When the token supports permit, deposit wallets only sign and a funded relayer pays for gas,
+// so deposit wallets never need a BNB top-up. With a BatchCollector contract configured, approved wallets
+// are swept in batches with a single transaction per batch. Wallets holding funds owed back to the sender
+// (unfinished refunds, held or AML-rejected deposits) are not swept.
+type SweepService struct {
+	logger  *slog.Logger
+	repo    SweepWalletsRepository
+	wallets SweepWallets
+
+	destination string
+	minAmount   *big.Int
+	interval    time.Duration
+	gasless     bool
+	relayerPath string
+	collector   common.Address
+	batchSize   int
+
+	// Поддержка permit проверяется один раз: контракт токена не меняется
+	permitChecked   bool
+	permitSupported bool
+
+	// Внеочередной свип после оплаты ордеров, не чаще sweepScheduleCooldown
+	scheduled chan struct{}
+}
+
+func NewSweepService(
+	logger *slog.Logger,
+	repo SweepWalletsRepository,
+	wallets SweepWallets,
+	config SweepConfig,
+) (*SweepService, error) {
+	if !common.IsHexAddress(config.Destination) {
+		return nil, fmt.Errorf("invalid sweep destination %q", config.Destination)
+	}
+	minAmount, err := tokenAmountToUnits(config.MinAmount, wallets.Asset().Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sweep minimum amount: %w", err)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("sweep interval must be positive")
+	}
+	if config.Gasless || config.CollectorAddress != "" {
+		if _, _, err = ParseDerivationPath(config.RelayerPath); err != nil {
+			return nil, fmt.Errorf("invalid sweep relayer path: %w", err)
+		}
+	}
+
+	var collector common.Address
+	if config.CollectorAddress != "" {
+		if !common.IsHexAddress(config.CollectorAddress) {
+			return nil, fmt.Errorf("invalid batch collector address %q", config.CollectorAddress)
+		}
+		if config.BatchSize <= 0 {
+			return nil, errors.New("sweep batch size must be positive")
+		}
+		collector = common.HexToAddress(config.CollectorAddress)
+	}
+
+	return &SweepService{
+		logger:      logger,
+		repo:        repo,
+		wallets:     wallets,
+		destination: common.HexToAddress(config.Destination).Hex(),
+		minAmount:   minAmount,
+		interval:    config.Interval,
+		gasless:     config.Gasless,
+		relayerPath: config.RelayerPath,
+		collector:   collector,
+		batchSize:   config.BatchSize,
+		scheduled:   make(chan struct{}, 1),
+	}, nil
+}
+
+// Start runs sweeps on the configured interval and when scheduled until ctx is cancelled
+func (s *SweepService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	var lastSweep time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.scheduled:
+			if time.Since(lastSweep) < sweepScheduleCooldown {
+				continue
+			}
+		}
+
+		lastSweep = time.Now()
+		if err := s.SweepAll(ctx); err != nil {
+			s.logger.ErrorContext(ctx, "Sweep failed", "error", err)
+		}
+	}
+}
+
+// Schedule requests a sweep before the next interval, e.g. after orders were paid.
+// Requests made within sweepScheduleCooldown of the previous sweep are dropped.
+func (s *SweepService) Schedule() {
+	select {
+	case s.scheduled <- struct{}{}:
+	default:
+	}
+}
+
+// SweepAll sweeps every deposit wallet holding at least the minimum amount
+func (s *SweepService) SweepAll(ctx context.Context) error {
+	// Approve коллектора, permit и пакетный collect учитываются в журнале как свипы
+	ctx = withLedgerTag(ctx, entities.LedgerKindSweep, nil, nil)
+
+	if err := s.wallets.CheckTokenHalt(); err != nil {
+		s.logger.WarnContext(ctx, "Sweep skipped", "reason", err.Error())
+		return nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	wallets, err := s.sweepableWallets(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.collector != (common.Address{}) {
+		return s.sweepBatches(ctx, client, wallets)
+	}
+
+	gasless := s.useGasless(ctx, client)
+
+	var swept int
+	for _, wallet := range wallets {
+
+		balance, err := s.wallets.GetERC20TokenBalance(ctx, client, wallet.Address)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get wallet balance for sweep", "error", err, "wallet", wallet.Address)
+			continue
+		}
+		if balance.Cmp(s.minAmount) < 0 {
+			continue
+		}
+
+		txHash, err := s.sweep(ctx, client, wallet, balance, gasless)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to sweep wallet",
+				"error", err,
+				"wallet", wallet.Address,
+				"amount", balance.String(),
+				"gasless", gasless)
+			continue
+		}
+
+		swept++
+		s.logger.InfoContext(ctx, "Wallet swept",
+			"wallet", wallet.Address,
+			"to", s.destination,
+			"amount", balance.String(),
+			"gasless", gasless,
+			"tx_hash", txHash)
+	}
+
+	s.logger.InfoContext(ctx, "Sweep completed", "wallets", len(wallets), "swept", swept, "gasless", gasless)
+	return nil
+}
+
+// sweepableWallets returns the tracked wallets that may be swept
+func (s *SweepService) sweepableWallets(ctx context.Context) ([]entities.Wallet, error) {
+	wallets, err := s.repo.GetAllTrackedWallets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked wallets: %w", err)
+	}
+	// Средства, которые RefundService вернет отправителю, должны остаться на кошельке
+	frozen, err := s.repo.FindAddressesWithFrozenFunds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallets with frozen funds: %w", err)
+	}
+
+	sweepable := make([]entities.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		// Форвардеры опустошаются через фабрику, ключа у них нет
+		if strings.EqualFold(wallet.Address, s.destination) || IsForwarderPath(wallet.DerivationPath) {
+			continue
+		}
+		if frozen[strings.ToLower(wallet.Address)] {
+			s.logger.DebugContext(ctx, "Wallet with frozen funds skipped by sweep", "wallet", wallet.Address)
+			continue
+		}
+		sweepable = append(sweepable, wallet)
+	}
+	return sweepable, nil
+}
+
+// sweepBatches collects wallets that already approved the collector in batches of batchSize.
+// Wallets without allowance get a one-time approval and are collected on the next run.
+func (s *SweepService) sweepBatches(ctx context.Context, client *ethclient.Client, wallets []entities.Wallet) error {
+	destination := common.HexToAddress(s.destination)
+
+	var (
+		from    []common.Address
+		amounts []*big.Int
+		swept   int
+	)
+	flush := func() {
+		if len(from) == 0 {
+			return
+		}
+		txHash, err := s.wallets.CollectBatch(ctx, client, s.collector, s.relayerPath, from, amounts, destination)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to collect sweep batch", "error", err, "wallets", len(from))
+		} else {
+			swept += len(from)
+			s.logger.InfoContext(ctx, "Sweep batch collected",
+				"wallets", len(from),
+				"to", s.destination,
+				"tx_hash", txHash)
+		}
+		from, amounts = nil, nil
+	}
+
+	for _, wallet := range wallets {
+		owner := common.HexToAddress(wallet.Address)
+		balance, err := s.wallets.GetERC20TokenBalance(ctx, client, wallet.Address)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get wallet balance for sweep", "error", err, "wallet", wallet.Address)
+			continue
+		}
+		if balance.Cmp(s.minAmount) < 0 {
+			continue
+		}
+
+		// Один заблокированный кошелек откатил бы весь collect, поэтому он исключается из пачки
+		if err = s.wallets.ScreenAddresses(ctx, wallet.Address); err != nil {
+			s.logger.WarnContext(ctx, "Wallet excluded from batch sweep", "error", err, "wallet", wallet.Address)
+			continue
+		}
+
+		allowance, err := s.wallets.TokenAllowance(ctx, client, owner, s.collector)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get collector allowance", "error", err, "wallet", wallet.Address)
+			continue
+		}
+		if allowance.Cmp(balance) < 0 {
+			txHash, err := s.wallets.ApproveSpender(ctx, client, wallet.ID, s.collector)
+			if err != nil {
+				s.logger.WarnContext(ctx, "Failed to approve batch collector", "error", err, "wallet", wallet.Address)
+			} else {
+				s.logger.InfoContext(ctx, "Batch collector approved", "wallet", wallet.Address, "tx_hash", txHash)
+			}
+			continue
+		}
+
+		from = append(from, owner)
+		amounts = append(amounts, balance)
+		if len(from) == s.batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	s.logger.InfoContext(ctx, "Batch sweep completed", "wallets", len(wallets), "swept", swept)
+	return nil
+}
+
+func (s *SweepService) sweep(ctx context.Context, client *ethclient.Client, wallet entities.Wallet, amount *big.Int, gasless bool) (string, error) {
+	ctx = withLedgerTag(ctx, entities.LedgerKindSweep, amount, nil)
+	if gasless {
+		return s.wallets.SweepWithPermit(ctx, client, wallet.ID, s.destination, amount, s.relayerPath)
+	}
+
+	// Без permit депозитный кошелек сам платит за газ, BNB должен быть на нем заранее.
+	// Свип всегда отправляется с самого кошелька: предложение Safe оплатило бы его из казны, не опустошив кошелек
+	return s.wallets.TransferFunds(ctx, client, wallet.ID, s.destination, amount)
+}
+
+func (s *SweepService) useGasless(ctx context.Context, client *ethclient.Client) bool {
+	if !s.gasless {
+		return false
+	}
+	if s.permitChecked {
+		return s.permitSupported
+	}
+
+	supported, err := s.wallets.SupportsPermit(ctx, client)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to check permit support", "error", err)
+		return false
+	}
+
+	s.permitChecked = true
+	s.permitSupported = supported
+	if !supported {
+		s.logger.WarnContext(ctx, "USDT contract does not support EIP-2612 permit, sweeps require BNB on deposit wallets",
+			"token", s.wallets.Asset().Contract)
+	}
+
+	return supported
+}
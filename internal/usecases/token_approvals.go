@@ -0,0 +1,358 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	tokenApprovalsListLimit = 200
+	// tokenApprovalSystemActor — автор автоматического отзыва approvals с истекшим сроком
+	tokenApprovalSystemActor = "system"
+)
+
+type TokenApprovalsRepository interface {
+	CreateApproval(ctx context.Context, approval *entities.TokenApproval) error
+	FindApprovalByID(ctx context.Context, id int64) (*entities.TokenApproval, error)
+	FindActiveApproval(ctx context.Context, token, owner, spender string) (*entities.TokenApproval, error)
+	FindApprovals(ctx context.Context, status entities.TokenApprovalStatus, limit int) ([]entities.TokenApproval, error)
+	UpdateAllowance(ctx context.Context, id int64, allowance string) error
+	CloseApproval(ctx context.Context, id int64, status entities.TokenApprovalStatus, revokeTxHash, revokedBy string) (bool, error)
+}
+
+type TokenApprovalWallets interface {
+	TokenAllowance(ctx context.Context, client *ethclient.Client, owner, spender common.Address) (*big.Int, error)
+	ApproveFromPath(ctx context.Context, client *ethclient.Client, ownerPath string, spender common.Address, amount *big.Int) (string, error)
+	ServiceAddress(path string) (common.Address, error)
+	TokenAddress() common.Address
+	Asset() entities.Asset
+}
+
+var (
+	_ TokenApprovalsRepository = (*repository.TokenApprovalsRepository)(nil)
+	_ TokenApprovalWallets     = (*WalletService)(nil)
+)
+
+// TokenApprovalConfig задает approvals мастер-кошелька: разрешенные контракты, лимит суммы и сроки
+type TokenApprovalConfig struct {
+	// Путь деривации мастер-кошелька, выдающего approvals
+	OwnerPath string
+	Spenders  []string
+	MaxAmount string // В единицах актива
+	// Срок approval по умолчанию и максимальный срок, по истечении approval отзывается автоматически
+	DefaultExpiry time.Duration
+	MaxExpiry     time.Duration
+	CheckInterval time.Duration
+}
+
+// TokenApprovalRequest — запрос администратора на выдачу approval. Нулевой ExpiresIn — срок по умолчанию.
+type TokenApprovalRequest struct {
+	Spender   string
+	Amount    string
+	ExpiresIn time.Duration
+	Purpose   string
+}
+
+// TokenApprovalService issues, monitors and revokes ERC-20 approvals of the master wallet to the contracts
+// of the forwarder and multisend flows. Every approval is capped by the amount limit and expires: the monitor
+// refreshes the on-chain allowance and revokes expired approvals. Issuing and revoking are recorded in the audit log.
+type TokenApprovalService struct {
+	logger  *slog.Logger
+	repo    TokenApprovalsRepository
+	wallets TokenApprovalWallets
+	audit   *AuditService
+
+	ownerPath     string
+	owner         common.Address
+	spenders      map[common.Address]struct{}
+	maxAmount     *big.Int
+	defaultExpiry time.Duration
+	maxExpiry     time.Duration
+	checkInterval time.Duration
+}
+
+func NewTokenApprovalService(
+	logger *slog.Logger,
+	repo TokenApprovalsRepository,
+	wallets TokenApprovalWallets,
+	audit *AuditService,
+	config TokenApprovalConfig,
+) (*TokenApprovalService, error) {
+	owner, err := wallets.ServiceAddress(config.OwnerPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token approval owner: %w", err)
+	}
+	maxAmount, err := tokenAmountToUnits(config.MaxAmount, wallets.Asset().Decimals)
+	if err != nil || maxAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid token approval maximum amount %q", config.MaxAmount)
+	}
+	if config.DefaultExpiry <= 0 || config.MaxExpiry < config.DefaultExpiry {
+		return nil, errors.New("token approval expiry must be positive and not above the maximum expiry")
+	}
+	if config.CheckInterval <= 0 {
+		return nil, errors.New("token approval check interval must be positive")
+	}
+
+	spenders := make(map[common.Address]struct{}, len(config.Spenders))
+	for _, spender := range config.Spenders {
+		spender = strings.TrimSpace(spender)
+		if spender == "" {
+			continue
+		}
+		if !common.IsHexAddress(spender) {
+			return nil, fmt.Errorf("invalid token approval spender %q", spender)
+		}
+		spenders[common.HexToAddress(spender)] = struct{}{}
+	}
+	if len(spenders) == 0 {
+		return nil, errors.New("token approvals require at least one allowed spender")
+	}
+
+	return &TokenApprovalService{
+		logger:        logger,
+		repo:          repo,
+		wallets:       wallets,
+		audit:         audit,
+		ownerPath:     config.OwnerPath,
+		owner:         owner,
+		spenders:      spenders,
+		maxAmount:     maxAmount,
+		defaultExpiry: config.DefaultExpiry,
+		maxExpiry:     config.MaxExpiry,
+		checkInterval: config.CheckInterval,
+	}, nil
+}
+
+// Start refreshes allowances and revokes expired approvals on the check interval until ctx is cancelled
+func (s *TokenApprovalService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckAll(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Token approval check failed", "error", err)
+			}
+		}
+	}
+}
+
+// GetApprovals returns the latest approvals, filtered by status if it is set
+func (s *TokenApprovalService) GetApprovals(ctx context.Context, status entities.TokenApprovalStatus) ([]entities.TokenApproval, error) {
+	switch status {
+	case "", entities.TokenApprovalActive, entities.TokenApprovalRevoked, entities.TokenApprovalExpired:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidTokenApproval, status)
+	}
+	return s.repo.FindApprovals(ctx, status, tokenApprovalsListLimit)
+}
+
+// Approve grants an allowed spender an allowance of at most the configured limit from the master wallet.
+// A spender with an active approval must be revoked first: changing a non-zero allowance in place
+// lets the spender use both the old and the new amounts.
+func (s *TokenApprovalService) Approve(ctx context.Context, request TokenApprovalRequest, actor string) (*entities.TokenApproval, error) {
+	if !common.IsHexAddress(request.Spender) {
+		return nil, fmt.Errorf("%w: invalid spender address", ErrInvalidTokenApproval)
+	}
+	spender := common.HexToAddress(request.Spender)
+	if _, ok := s.spenders[spender]; !ok {
+		return nil, fmt.Errorf("%w: spender %s is not allowed", ErrInvalidTokenApproval, spender.Hex())
+	}
+
+	asset := s.wallets.Asset()
+	amount, err := tokenAmountToUnits(request.Amount, asset.Decimals)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid amount %q", ErrInvalidTokenApproval, request.Amount)
+	}
+	if amount.Cmp(s.maxAmount) > 0 {
+		return nil, fmt.Errorf("%w: amount exceeds the limit of %s %s", ErrInvalidTokenApproval,
+			unitsToTokenAmount(s.maxAmount, asset.Decimals), asset.Code)
+	}
+
+	expiresIn := request.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = s.defaultExpiry
+	}
+	if expiresIn < 0 || expiresIn > s.maxExpiry {
+		return nil, fmt.Errorf("%w: expiry must be positive and at most %s", ErrInvalidTokenApproval, s.maxExpiry)
+	}
+
+	token := s.wallets.TokenAddress().Hex()
+	existing, err := s.repo.FindActiveApproval(ctx, token, s.owner.Hex(), spender.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrTokenApprovalExists
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	txHash, err := s.wallets.ApproveFromPath(ctx, client, s.ownerPath, spender, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send approve: %w", err)
+	}
+
+	approval := &entities.TokenApproval{
+		Token:     token,
+		Owner:     s.owner.Hex(),
+		Spender:   spender.Hex(),
+		Amount:    unitsToTokenAmount(amount, asset.Decimals),
+		Purpose:   strings.TrimSpace(request.Purpose),
+		Status:    entities.TokenApprovalActive,
+		TxHash:    txHash,
+		CreatedBy: actor,
+		ExpiresAt: time.Now().Add(expiresIn).UTC(),
+	}
+	// Транзакция уже отправлена: без записи approval не будет отозван по сроку, поэтому ошибка возвращается с хешем
+	if err = s.repo.CreateApproval(ctx, approval); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record token approval", "error", err, "spender", approval.Spender, "tx_hash", txHash)
+		return nil, fmt.Errorf("approve %s sent but not recorded: %w", txHash, err)
+	}
+
+	if err = s.audit.Record(ctx, entities.AuditEventTokenApprovalIssued, actor, strconv.FormatInt(approval.ID, 10), map[string]any{
+		"token":      approval.Token,
+		"owner":      approval.Owner,
+		"spender":    approval.Spender,
+		"amount":     approval.Amount,
+		"purpose":    approval.Purpose,
+		"expires_at": approval.ExpiresAt,
+		"tx_hash":    txHash,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record token approval audit", "error", err, "id", approval.ID)
+	}
+	s.logger.InfoContext(ctx, "Token approval issued",
+		"id", approval.ID,
+		"spender", approval.Spender,
+		"amount", approval.Amount,
+		"expires_at", approval.ExpiresAt,
+		"tx_hash", txHash,
+		"actor", actor)
+
+	return approval, nil
+}
+
+// Revoke sets the allowance of an active approval to zero
+func (s *TokenApprovalService) Revoke(ctx context.Context, id int64, actor string) (*entities.TokenApproval, error) {
+	approval, err := s.repo.FindApprovalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil || approval.Status != entities.TokenApprovalActive {
+		return nil, ErrTokenApprovalNotFound
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	if err = s.revoke(ctx, client, approval, entities.TokenApprovalRevoked, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.FindApprovalByID(ctx, id)
+}
+
+// CheckAll reads the allowance of every active approval and revokes the expired ones
+func (s *TokenApprovalService) CheckAll(ctx context.Context) error {
+	approvals, err := s.repo.FindApprovals(ctx, entities.TokenApprovalActive, tokenApprovalsListLimit)
+	if err != nil {
+		return err
+	}
+	if len(approvals) == 0 {
+		return nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	decimals := s.wallets.Asset().Decimals
+	now := time.Now()
+	for _, approval := range approvals {
+		if !now.Before(approval.ExpiresAt) {
+			if err = s.revoke(ctx, client, &approval, entities.TokenApprovalExpired, tokenApprovalSystemActor); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to revoke expired token approval", "error", err, "id", approval.ID, "spender", approval.Spender)
+			}
+			continue
+		}
+
+		allowance, err := s.wallets.TokenAllowance(ctx, client, common.HexToAddress(approval.Owner), common.HexToAddress(approval.Spender))
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to read token allowance", "error", err, "id", approval.ID, "spender", approval.Spender)
+			continue
+		}
+		remaining := unitsToTokenAmount(allowance, decimals)
+		if err = s.repo.UpdateAllowance(ctx, approval.ID, remaining); err != nil {
+			s.logger.WarnContext(ctx, "Failed to store token allowance", "error", err, "id", approval.ID)
+			continue
+		}
+
+		// Разрешение больше выданного означает approve в обход менеджера
+		limit, err := tokenAmountToUnits(approval.Amount, decimals)
+		if err == nil && allowance.Cmp(limit) > 0 {
+			s.logger.ErrorContext(ctx, "Token allowance exceeds the issued approval",
+				"id", approval.ID,
+				"spender", approval.Spender,
+				"approved", approval.Amount,
+				"allowance", remaining)
+		}
+	}
+
+	return nil
+}
+
+func (s *TokenApprovalService) revoke(ctx context.Context, client *ethclient.Client, approval *entities.TokenApproval, status entities.TokenApprovalStatus, actor string) error {
+	txHash, err := s.wallets.ApproveFromPath(ctx, client, s.ownerPath, common.HexToAddress(approval.Spender), big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("failed to send revoke: %w", err)
+	}
+
+	closed, err := s.repo.CloseApproval(ctx, approval.ID, status, txHash, actor)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return ErrTokenApprovalNotFound
+	}
+
+	if err = s.audit.Record(ctx, entities.AuditEventTokenApprovalRevoked, actor, strconv.FormatInt(approval.ID, 10), map[string]any{
+		"token":   approval.Token,
+		"spender": approval.Spender,
+		"amount":  approval.Amount,
+		"status":  status,
+		"tx_hash": txHash,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record token approval audit", "error", err, "id", approval.ID)
+	}
+	s.logger.InfoContext(ctx, "Token approval revoked",
+		"id", approval.ID,
+		"spender", approval.Spender,
+		"status", status,
+		"tx_hash", txHash,
+		"actor", actor)
+
+	return nil
+}
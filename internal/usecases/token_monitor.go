@@ -0,0 +1,377 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	// tokenMonitorMaxBlockRange ограничивает диапазон eth_getLogs: публичные ноды отклоняют большие запросы
+	tokenMonitorMaxBlockRange = 1000
+	tokenEventsListLimit      = 100
+
+	// tokenMonitorCheckpoint — ключ контрольной точки монитора в scanner_states рядом с контрольными точками сканеров сетей
+	tokenMonitorCheckpoint entities.Chain = "bsc_token_events"
+)
+
+// tokenAdminEvent описывает отслеживаемое событие контракта токена
+type tokenAdminEvent struct {
+	name string
+	// halting — событие приостанавливает выводы и свипы до подтверждения
+	halting bool
+	// haltIfOurs — событие приостанавливает операции, только если его адрес — наш кошелек
+	haltIfOurs bool
+	// hasSubject — первый аргумент события (в topics или data) является адресом
+	hasSubject bool
+}
+
+// Административные события Tether (ERC20/TRC20 USDT) и стандартных контрактов OpenZeppelin
+var tokenAdminEvents = map[common.Hash]tokenAdminEvent{
+	crypto.Keccak256Hash([]byte("Pause()")):                               {name: "Pause", halting: true},
+	crypto.Keccak256Hash([]byte("Unpause()")):                             {name: "Unpause"},
+	crypto.Keccak256Hash([]byte("Paused(address)")):                       {name: "Paused", halting: true, hasSubject: true},
+	crypto.Keccak256Hash([]byte("Unpaused(address)")):                     {name: "Unpaused", hasSubject: true},
+	crypto.Keccak256Hash([]byte("AddedBlackList(address)")):               {name: "AddedBlackList", haltIfOurs: true, hasSubject: true},
+	crypto.Keccak256Hash([]byte("RemovedBlackList(address)")):             {name: "RemovedBlackList", hasSubject: true},
+	crypto.Keccak256Hash([]byte("DestroyedBlackFunds(address,uint256)")):  {name: "DestroyedBlackFunds", haltIfOurs: true, hasSubject: true},
+	crypto.Keccak256Hash([]byte("Params(uint256,uint256)")):               {name: "Params", halting: true},
+	crypto.Keccak256Hash([]byte("Deprecate(address)")):                    {name: "Deprecate", halting: true, hasSubject: true},
+	crypto.Keccak256Hash([]byte("OwnershipTransferred(address,address)")): {name: "OwnershipTransferred", halting: true, hasSubject: true},
+	crypto.Keccak256Hash([]byte("Upgraded(address)")):                     {name: "Upgraded", halting: true, hasSubject: true},
+	crypto.Keccak256Hash([]byte("AdminChanged(address,address)")):         {name: "AdminChanged", halting: true, hasSubject: true},
+}
+
+type TokenEventsRepository interface {
+	CreateEvent(ctx context.Context, event *entities.TokenAdminEvent) (bool, error)
+	FindUnacknowledgedHalts(ctx context.Context) ([]entities.TokenAdminEvent, error)
+	FindEvents(ctx context.Context, limit int) ([]entities.TokenAdminEvent, error)
+	Acknowledge(ctx context.Context, id int64, acknowledgedBy string) (*entities.TokenAdminEvent, error)
+}
+
+// TokenMonitorCheckpoints хранит последний обработанный монитором блок между перезапусками
+type TokenMonitorCheckpoints interface {
+	FindState(ctx context.Context, chain entities.Chain) (*entities.ScannerState, error)
+	SaveCheckpoint(ctx context.Context, chain entities.Chain, block uint64) error
+}
+
+// TokenMonitorWallets определяет, затрагивает ли событие черного списка наши кошельки
+type TokenMonitorWallets interface {
+	IsWalletTracked(ctx context.Context, address string) (bool, error)
+}
+
+var (
+	_ TokenEventsRepository   = (*repository.TokenEventsRepository)(nil)
+	_ TokenMonitorWallets     = (*repository.WalletsRepository)(nil)
+	_ TokenMonitorCheckpoints = (*repository.ScannerStatesRepository)(nil)
+)
+
+// TokenMonitorService отслеживает административные события контрактов токенов и приостанавливает
+// выводы и свипы затронутого токена, пока администратор не подтвердит событие
+type TokenMonitorService struct {
+	logger      *slog.Logger
+	repo        TokenEventsRepository
+	wallets     TokenMonitorWallets
+	checkpoints TokenMonitorCheckpoints
+	audit       *AuditService
+
+	tokens       []common.Address
+	pollInterval time.Duration
+
+	mu sync.RWMutex
+	// Количество неподтвержденных приостанавливающих событий по адресу токена (в нижнем регистре)
+	halts     map[string]int
+	lastBlock uint64
+}
+
+func NewTokenMonitorService(
+	logger *slog.Logger,
+	repo TokenEventsRepository,
+	wallets TokenMonitorWallets,
+	checkpoints TokenMonitorCheckpoints,
+	audit *AuditService,
+	tokens []string,
+	pollInterval time.Duration,
+) (*TokenMonitorService, error) {
+	addresses := make([]common.Address, 0, len(tokens))
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return nil, fmt.Errorf("invalid token address %q", token)
+		}
+		addresses = append(addresses, common.HexToAddress(token))
+	}
+
+	return &TokenMonitorService{
+		logger:       logger,
+		repo:         repo,
+		wallets:      wallets,
+		checkpoints:  checkpoints,
+		audit:        audit,
+		tokens:       addresses,
+		pollInterval: pollInterval,
+		halts:        make(map[string]int),
+	}, nil
+}
+
+// LoadHalts restores halts from events that were not acknowledged before the restart
+func (s *TokenMonitorService) LoadHalts(ctx context.Context) error {
+	events, err := s.repo.FindUnacknowledgedHalts(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		s.halts[strings.ToLower(event.TokenAddress)]++
+	}
+
+	if len(events) > 0 {
+		s.logger.WarnContext(ctx, "Token operations halted by unacknowledged admin events", "events", len(events))
+	}
+	return nil
+}
+
+// LoadCheckpoint restores the last processed block, so events emitted while the service was down are not missed.
+// Without a saved checkpoint polling starts from the chain head.
+func (s *TokenMonitorService) LoadCheckpoint(ctx context.Context) error {
+	state, err := s.checkpoints.FindState(ctx, tokenMonitorCheckpoint)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.LastBlock == nil || *state.LastBlock <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.lastBlock = uint64(*state.LastBlock)
+	s.mu.Unlock()
+
+	s.logger.InfoContext(ctx, "Token monitor checkpoint loaded", "block", *state.LastBlock)
+	return nil
+}
+
+// CheckToken returns ErrTokenHalted while the token has unacknowledged halting events
+func (s *TokenMonitorService) CheckToken(token string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.halts[strings.ToLower(token)] > 0 {
+		return fmt.Errorf("%w: %s", ErrTokenHalted, token)
+	}
+	return nil
+}
+
+// Halted reports whether operations with any monitored token are halted
+func (s *TokenMonitorService) Halted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, count := range s.halts {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEvents returns the latest recorded admin events
+func (s *TokenMonitorService) GetEvents(ctx context.Context) ([]entities.TokenAdminEvent, error) {
+	return s.repo.FindEvents(ctx, tokenEventsListLimit)
+}
+
+// Acknowledge confirms the event was reviewed and lifts its halt
+func (s *TokenMonitorService) Acknowledge(ctx context.Context, id int64, acknowledgedBy string) (*entities.TokenAdminEvent, error) {
+	event, err := s.repo.Acknowledge(ctx, id, acknowledgedBy)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrTokenEventNotFound
+	}
+
+	if event.Halting {
+		s.mu.Lock()
+		key := strings.ToLower(event.TokenAddress)
+		if s.halts[key] > 0 {
+			s.halts[key]--
+		}
+		s.mu.Unlock()
+	}
+
+	if err := s.audit.Record(ctx, entities.AuditEventTokenEventAcknowledged, acknowledgedBy, event.TokenAddress, map[string]any{
+		"event_id":   event.ID,
+		"event_name": event.EventName,
+		"tx_hash":    event.TxHash,
+		"halting":    event.Halting,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record token event audit", "error", err, "event_id", event.ID)
+	}
+
+	s.logger.InfoContext(ctx, "Token admin event acknowledged",
+		"event_id", event.ID,
+		"event_name", event.EventName,
+		"token", event.TokenAddress,
+		"acknowledged_by", acknowledgedBy)
+
+	return event, nil
+}
+
+// Start polls logs of the monitored tokens starting after the checkpoint, or from the current head without one
+func (s *TokenMonitorService) Start(ctx context.Context, client *ethclient.Client) {
+	if len(s.tokens) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.poll(ctx, client); err != nil {
+				s.logger.WarnContext(ctx, "Failed to poll token admin events", "error", err)
+			}
+		}
+	}
+}
+
+func (s *TokenMonitorService) poll(ctx context.Context, client *ethclient.Client) error {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	s.mu.RLock()
+	from := s.lastBlock + 1
+	if s.lastBlock == 0 {
+		from = head
+	}
+	s.mu.RUnlock()
+	if from > head {
+		return nil
+	}
+	to := head
+	if to-from >= tokenMonitorMaxBlockRange {
+		to = from + tokenMonitorMaxBlockRange - 1
+	}
+
+	topics := make([]common.Hash, 0, len(tokenAdminEvents))
+	for topic := range tokenAdminEvents {
+		topics = append(topics, topic)
+	}
+
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: s.tokens,
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to filter token logs: %w", err)
+	}
+
+	for _, log := range logs {
+		if err := s.handleLog(ctx, log); err != nil {
+			// Блок будет запрошен повторно, повторная запись события игнорируется
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.lastBlock = to
+	s.mu.Unlock()
+
+	// Без сохраненной контрольной точки после перезапуска блоки с момента остановки будут пропущены
+	if err := s.checkpoints.SaveCheckpoint(ctx, tokenMonitorCheckpoint, to); err != nil {
+		s.logger.WarnContext(ctx, "Failed to save token monitor checkpoint", "error", err, "block", to)
+	}
+	return nil
+}
+
+func (s *TokenMonitorService) handleLog(ctx context.Context, log types.Log) error {
+	if len(log.Topics) == 0 || log.Removed {
+		return nil
+	}
+	spec, ok := tokenAdminEvents[log.Topics[0]]
+	if !ok {
+		return nil
+	}
+
+	event := &entities.TokenAdminEvent{
+		TokenAddress: log.Address.Hex(),
+		EventName:    spec.name,
+		TxHash:       log.TxHash.Hex(),
+		LogIndex:     int(log.Index),
+		BlockNumber:  int64(log.BlockNumber),
+		Halting:      spec.halting,
+	}
+
+	if spec.hasSubject {
+		var subject common.Address
+		switch {
+		case len(log.Topics) > 1:
+			subject = common.BytesToAddress(log.Topics[1].Bytes())
+		case len(log.Data) >= 32:
+			subject = common.BytesToAddress(log.Data[:32])
+		}
+		if subject != (common.Address{}) {
+			hex := subject.Hex()
+			event.Subject = &hex
+		}
+	}
+
+	if spec.haltIfOurs && event.Subject != nil {
+		ours, err := s.wallets.IsWalletTracked(ctx, *event.Subject)
+		if err != nil {
+			return err
+		}
+		event.Halting = ours
+	}
+
+	created, err := s.repo.CreateEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+
+	if event.Halting {
+		s.mu.Lock()
+		s.halts[strings.ToLower(event.TokenAddress)]++
+		s.mu.Unlock()
+
+		// Ошибка уходит в errreport через LogHandler и поднимает операционный алерт
+		s.logger.ErrorContext(ctx, "Token admin event: withdrawals and sweeps halted until acknowledged",
+			"event_id", event.ID,
+			"event_name", event.EventName,
+			"token", event.TokenAddress,
+			"subject", event.Subject,
+			"tx_hash", event.TxHash)
+		return nil
+	}
+
+	s.logger.WarnContext(ctx, "Token admin event",
+		"event_id", event.ID,
+		"event_name", event.EventName,
+		"token", event.TokenAddress,
+		"subject", event.Subject,
+		"tx_hash", event.TxHash)
+	return nil
+}
@@ -0,0 +1,433 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/ton"
+)
+
+const (
+	// Переводы запрашиваются страницами, пока страница заполнена целиком
+	tonTransfersPageSize = 100
+	// Блоки TON финальны сразу после включения в мастерчейн, а индексатор отдает только их
+	tonRequiredConfirmations = 1
+	// Общий депозитный кошелек принадлежит платформе, а не пользователю
+	tonDepositWalletUserID = 0
+)
+
+type TonClient interface {
+	IncomingJettonTransfers(ctx context.Context, jettonWallet ton.Address, afterLT uint64, limit int) ([]ton.JettonTransfer, error)
+	JettonWalletAddress(ctx context.Context, master, owner ton.Address) (ton.Address, error)
+}
+
+type TonWalletsRepository interface {
+	FindWalletByChainAddress(ctx context.Context, chain entities.Chain, network, address string) (*entities.Wallet, error)
+	TrackWallet(ctx context.Context, wallet *entities.Wallet) (int, error)
+}
+
+type TonTransactionsRepository interface {
+	FindLastBlockNumber(ctx context.Context, walletAddress string) (int64, error)
+}
+
+type TonTransactions interface {
+	RecordTransaction(ctx context.Context, txHash common.Hash, walletAddress, fromAddress, memo string, amount *big.Int, blockNumber int64, requiredConfirmations uint64) error
+	ConfirmTransaction(ctx context.Context, txHash string, confirmations uint64) error
+	MarkTransactionAMLFlagged(ctx context.Context, txHash string) error
+	MarkTransactionAMLCleared(ctx context.Context, txHash string) error
+}
+
+type TonDepositFilter interface {
+	FilterDeposit(ctx context.Context, asset entities.Asset, txHash, fromAddress string, amount *big.Int) (bool, error)
+}
+
+type TonAML interface {
+	CheckTransaction(ctx context.Context, txHash common.Hash, sourceAddress, destinationAddress string, amount *big.Int) (*entities.AMLCheckResult, error)
+}
+
+type TonOrders interface {
+	CreateMemoOrder(ctx context.Context, userID, walletID int, asset entities.Asset, amount string) (*entities.OrderPayment, error)
+}
+
+type TonAssets interface {
+	FindOnNetwork(code string, chain entities.Chain, network string) (entities.Asset, error)
+}
+
+var (
+	_ TonClient                 = (*ton.Client)(nil)
+	_ TonWalletsRepository      = (*repository.WalletsRepository)(nil)
+	_ TonTransactionsRepository = (*repository.TransactionsRepository)(nil)
+	_ TonTransactions           = (*TransactionServiceImpl)(nil)
+	_ TonDepositFilter          = (*DepositFilterService)(nil)
+	_ TonAML                    = (*AMLService)(nil)
+	_ TonOrders                 = (*OrderService)(nil)
+	_ TonAssets                 = (*AssetRegistry)(nil)
+)
+
+// TonDepositConfig задает прием депозитов жетона в сети TON. Пустой адрес кошелька отключает прием.
+type TonDepositConfig struct {
+	// Общий депозитный кошелек платформы, переводы на него сопоставляются с ордерами по комментарию (мемо)
+	DepositWallet string
+	// Мастер-контракт жетона, например USDT
+	JettonMaster string
+	Network      string
+	Interval     time.Duration
+}
+
+// TonDepositService accepts jetton deposits on TON. Users pay to one shared deposit wallet and put the order memo
+// into the transfer comment, as on exchanges. Transfers are read from the toncenter indexer and pass the same
+// pipeline as BSC deposits: the transaction is recorded, checked by AML and credited to the order with the memo.
+type TonDepositService struct {
+	logger       *slog.Logger
+	client       TonClient
+	wallets      TonWalletsRepository
+	history      TonTransactionsRepository
+	transactions TonTransactions
+	filters      TonDepositFilter
+	aml          TonAML
+	orders       TonOrders
+	assets       TonAssets
+	tracker      WorkerTracker
+
+	deposit  ton.Address
+	master   ton.Address
+	network  string
+	interval time.Duration
+
+	mu       sync.Mutex
+	walletID int
+	// Кошелек жетона выводится из мастер-контракта при первом сканировании
+	jettonWallet ton.Address
+	// Логическое время последнего обработанного перевода, -1 — еще не загружено из базы
+	lastLT int64
+	// Время последнего успешного сканирования
+	lastScanAt time.Time
+	// Приостановлен оператором: переводы не сканируются, после возобновления сканирование продолжается с lastLT
+	paused bool
+}
+
+func NewTonDepositService(logger *slog.Logger, client TonClient, wallets TonWalletsRepository, history TonTransactionsRepository,
+	transactions TonTransactions, filters TonDepositFilter, aml TonAML, orders TonOrders, assets TonAssets, tracker WorkerTracker, config TonDepositConfig) (*TonDepositService, error) {
+	s := &TonDepositService{
+		logger:       logger,
+		client:       client,
+		wallets:      wallets,
+		history:      history,
+		transactions: transactions,
+		filters:      filters,
+		aml:          aml,
+		orders:       orders,
+		assets:       assets,
+		tracker:      tracker,
+		network:      config.Network,
+		interval:     config.Interval,
+		lastLT:       -1,
+	}
+	if config.DepositWallet == "" {
+		return s, nil
+	}
+
+	var err error
+	if s.deposit, err = ton.ParseAddress(config.DepositWallet); err != nil {
+		return nil, fmt.Errorf("TON deposit wallet: %w", err)
+	}
+	if s.master, err = ton.ParseAddress(config.JettonMaster); err != nil {
+		return nil, fmt.Errorf("TON jetton master: %w", err)
+	}
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("TON deposit polling interval must be positive")
+	}
+	return s, nil
+}
+
+// Enabled reports whether TON deposits are accepted
+func (s *TonDepositService) Enabled() bool {
+	return !s.deposit.IsZero()
+}
+
+// DepositAddress returns the shared deposit wallet in the non-bounceable form recommended for wallets
+func (s *TonDepositService) DepositAddress() string {
+	return s.deposit.Friendly(false, s.network != entities.NetworkMainnet)
+}
+
+// CreateOrder creates an order payable by a jetton transfer to the shared deposit wallet with the returned memo
+func (s *TonDepositService) CreateOrder(ctx context.Context, userID int64, amount string) (*entities.OrderPayment, error) {
+	if !s.Enabled() {
+		return nil, ErrTonDepositsDisabled
+	}
+
+	asset, err := s.assets.FindOnNetwork(DefaultAssetCode, entities.ChainTON, s.network)
+	if err != nil {
+		return nil, err
+	}
+
+	walletID, err := s.depositWalletID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.orders.CreateMemoOrder(ctx, int(userID), walletID, asset, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "TON order created", "user_id", userID, "wallet_id", walletID, "amount", payment.Amount, "memo", payment.Memo)
+	return payment, nil
+}
+
+// depositWalletID registers the shared deposit wallet on first use: orders and transactions reference it
+func (s *TonDepositService) depositWalletID(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.walletID != 0 {
+		return s.walletID, nil
+	}
+
+	address := s.deposit.Raw()
+	wallet, err := s.wallets.FindWalletByChainAddress(ctx, entities.ChainTON, s.network, address)
+	if err != nil {
+		return 0, err
+	}
+	if wallet != nil {
+		s.walletID = wallet.ID
+		return s.walletID, nil
+	}
+
+	id, err := s.wallets.TrackWallet(ctx, &entities.Wallet{
+		UserID:        tonDepositWalletUserID,
+		Address:       address,
+		IsTestnet:     s.network != entities.NetworkMainnet,
+		Chain:         entities.ChainTON,
+		Network:       s.network,
+		AddressFormat: entities.AddressFormatTONRaw,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to register TON deposit wallet: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "TON deposit wallet registered", "wallet_id", id, "address", s.DepositAddress())
+	s.walletID = id
+	return id, nil
+}
+
+// Start polls the indexer for new deposits until the context is cancelled
+func (s *TonDepositService) Start(ctx context.Context) {
+	if !s.Enabled() {
+		s.logger.Info("TON deposits are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if s.Paused() {
+			s.tracker.Beat()
+		} else {
+			processed, err := s.scan(ctx)
+			s.tracker.Done(processed, err)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Failed to scan TON deposits", "error", err)
+			} else {
+				s.mu.Lock()
+				s.lastScanAt = time.Now()
+				s.mu.Unlock()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Paused reports whether scanning is paused by an operator
+func (s *TonDepositService) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Pause stops scanning after the current scan. Transfers received meanwhile are picked up after resume.
+func (s *TonDepositService) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+// Resume continues scanning from the last processed transfer. It reports false when scanning is not paused.
+func (s *TonDepositService) Resume() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.paused {
+		return false
+	}
+	s.paused = false
+	return true
+}
+
+// LastHeartbeat returns the time of the last successful scan
+func (s *TonDepositService) LastHeartbeat() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastScanAt
+}
+
+// scan processes transfers received after the last processed one. A failed transfer stops the scan
+// and is retried on the next tick: recording is idempotent by transaction hash. Returns the number of processed transfers.
+func (s *TonDepositService) scan(ctx context.Context) (int, error) {
+	if _, err := s.depositWalletID(ctx); err != nil {
+		return 0, err
+	}
+
+	if s.jettonWallet.IsZero() {
+		jettonWallet, err := s.client.JettonWalletAddress(ctx, s.master, s.deposit)
+		if err != nil {
+			return 0, fmt.Errorf("failed to derive jetton wallet: %w", err)
+		}
+		s.jettonWallet = jettonWallet
+		s.logger.InfoContext(ctx, "TON jetton wallet derived", "owner", s.deposit.Raw(), "jetton_wallet", jettonWallet.Raw())
+	}
+
+	// Логическое время перевода хранится как номер блока транзакции: после перезапуска сканирование продолжается с него
+	if s.lastLT < 0 {
+		lastLT, err := s.history.FindLastBlockNumber(ctx, s.deposit.Raw())
+		if err != nil {
+			return 0, err
+		}
+		s.lastLT = lastLT
+	}
+
+	processed := 0
+	for {
+		transfers, err := s.client.IncomingJettonTransfers(ctx, s.jettonWallet, uint64(s.lastLT), tonTransfersPageSize)
+		if err != nil {
+			return processed, err
+		}
+
+		for _, transfer := range transfers {
+			lt, err := transfer.LT()
+			if err != nil {
+				return processed, fmt.Errorf("invalid logical time of TON transfer %s: %w", transfer.TransactionHash, err)
+			}
+
+			if !transfer.TransactionAborted {
+				if err = s.processTransfer(ctx, transfer, int64(lt)); err != nil {
+					return processed, err
+				}
+			}
+			s.lastLT = int64(lt)
+			processed++
+		}
+
+		if len(transfers) < tonTransfersPageSize {
+			return processed, nil
+		}
+	}
+}
+
+func (s *TonDepositService) processTransfer(ctx context.Context, transfer ton.JettonTransfer, lt int64) error {
+	amount, ok := new(big.Int).SetString(transfer.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		s.logger.WarnContext(ctx, "Skipping TON transfer with invalid amount", "tx_hash", transfer.TransactionHash, "amount", transfer.Amount)
+		return nil
+	}
+
+	hash, err := transfer.Hash()
+	if err != nil {
+		return fmt.Errorf("invalid hash of TON transfer %s: %w", transfer.TransactionHash, err)
+	}
+	txHash := common.BytesToHash(hash)
+
+	source := transfer.Source
+	if address, err := ton.ParseAddress(source); err == nil {
+		source = address.Raw()
+	}
+
+	memo := s.transferMemo(ctx, transfer)
+	destination := s.deposit.Raw()
+
+	s.logger.WarnContext(ctx, "TON jetton transfer to our wallet detected",
+		"tx_hash", txHash.Hex(),
+		"from", source,
+		"to", destination,
+		"amount", amount.String(),
+		"memo", memo,
+		"lt", lt)
+
+	if err = s.transactions.RecordTransaction(ctx, txHash, destination, source, memo, amount, lt, tonRequiredConfirmations); err != nil {
+		return fmt.Errorf("failed to record TON transfer %s: %w", txHash.Hex(), err)
+	}
+
+	asset, err := s.assets.FindOnNetwork(DefaultAssetCode, entities.ChainTON, s.network)
+	if err != nil {
+		return err
+	}
+	ignored, err := s.filters.FilterDeposit(ctx, asset, txHash.Hex(), source, amount)
+	if err != nil {
+		return fmt.Errorf("failed to filter TON transfer %s: %w", txHash.Hex(), err)
+	}
+	if ignored {
+		return nil
+	}
+
+	if s.aml != nil {
+		result, err := s.aml.CheckTransaction(ctx, txHash, source, destination, amount)
+		if err != nil {
+			return fmt.Errorf("AML check of TON transfer %s failed: %w", txHash.Hex(), err)
+		}
+
+		if !result.Approved {
+			// Отклоненный перевод не подтверждается и не зачисляется: его разбирает комплаенс
+			s.logger.WarnContext(ctx, "TON transfer flagged by AML check",
+				"tx_hash", txHash.Hex(),
+				"risk_level", result.RiskLevel,
+				"risk_source", result.RiskSource,
+				"notes", result.Notes)
+			if err = s.transactions.MarkTransactionAMLFlagged(ctx, txHash.Hex()); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		if err = s.transactions.MarkTransactionAMLCleared(ctx, txHash.Hex()); err != nil {
+			return err
+		}
+	}
+
+	// Зачисление по мемо выполняет общий обработчик подтвержденных транзакций
+	return s.transactions.ConfirmTransaction(ctx, txHash.Hex(), tonRequiredConfirmations)
+}
+
+// transferMemo returns the comment of the transfer, or an empty string if it has none or it cannot be an order memo
+func (s *TonDepositService) transferMemo(ctx context.Context, transfer ton.JettonTransfer) string {
+	comment, err := transfer.Comment()
+	if err != nil {
+		if !errors.Is(err, ton.ErrNotComment) {
+			s.logger.WarnContext(ctx, "Failed to decode TON transfer comment", "error", err, "tx_hash", transfer.TransactionHash)
+		}
+		return ""
+	}
+
+	memo := strings.TrimSpace(comment)
+	if len(memo) > entities.MaxDepositMemoLength {
+		s.logger.WarnContext(ctx, "TON transfer comment is too long for a memo", "tx_hash", transfer.TransactionHash, "length", len(memo))
+		return ""
+	}
+	return memo
+}
@@ -0,0 +1,288 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+type TransactionDetailTransactions interface {
+	FindTransactionByHash(ctx context.Context, txHash string) (*entities.Transaction, error)
+	FindConfirmationProgress(ctx context.Context, txHash string) (*entities.DepositConfirmations, error)
+}
+
+type TransactionDetailWallets interface {
+	FindWalletByAddress(ctx context.Context, address string) (*entities.Wallet, error)
+}
+
+type TransactionDetailOrders interface {
+	FindOrderByTxHash(ctx context.Context, txHash string) (*entities.Order, error)
+}
+
+type TransactionDetailAML interface {
+	GetCheckResultByTxHash(ctx context.Context, txHash string) (*entities.AMLCheckResult, error)
+}
+
+// TransactionDetailChain читает квитанцию транзакции и высоту сети BSC
+type TransactionDetailChain interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+const (
+	// Число последних блоков, по которым оценивается средний интервал блоков
+	blockTimeSampleBlocks = 20
+	// Оценка интервала блоков переиспользуется всеми запросами прогресса в течение этого времени
+	blockTimeCacheTTL = time.Minute
+)
+
+var (
+	_ TransactionDetailTransactions = (*repository.TransactionsRepository)(nil)
+	_ TransactionDetailWallets      = (*repository.WalletsRepository)(nil)
+	_ TransactionDetailOrders       = (*repository.OrdersRepository)(nil)
+	_ TransactionDetailAML          = (*repository.AMLRepository)(nil)
+	_ TransactionDetailChain        = (*ethclient.Client)(nil)
+)
+
+// TransactionDetailService собирает в один ответ сохраненную транзакцию, ее состояние в сети,
+// результат AML проверки и оплаченный ею ордер
+type TransactionDetailService struct {
+	logger       *slog.Logger
+	transactions TransactionDetailTransactions
+	wallets      TransactionDetailWallets
+	orders       TransactionDetailOrders
+	aml          TransactionDetailAML
+	chain        TransactionDetailChain
+	links        ExplorerLinks
+
+	blockTimeMu sync.Mutex
+	blockTime   time.Duration
+	blockTimeAt time.Time
+}
+
+func NewTransactionDetailService(
+	logger *slog.Logger,
+	transactions TransactionDetailTransactions,
+	wallets TransactionDetailWallets,
+	orders TransactionDetailOrders,
+	aml TransactionDetailAML,
+	chain TransactionDetailChain,
+	links ExplorerLinks,
+) *TransactionDetailService {
+	return &TransactionDetailService{
+		logger:       logger,
+		transactions: transactions,
+		wallets:      wallets,
+		orders:       orders,
+		aml:          aml,
+		chain:        chain,
+		links:        links,
+	}
+}
+
+// GetTransactionDetail returns the aggregated view of the transaction. The transaction must be a deposit
+// to a wallet of the user or pay an order of the user. A failed node request does not fail the call,
+// its reason is returned in OnChainError instead.
+func (s *TransactionDetailService) GetTransactionDetail(ctx context.Context, userID int64, txHash string) (*entities.TransactionDetail, error) {
+	// Хеши сохраняются в нижнем регистре
+	txHash = strings.ToLower(txHash)
+
+	transaction, wallet, order, err := s.findUserTransaction(ctx, userID, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &entities.TransactionDetail{
+		Transaction: *transaction,
+		Order:       order,
+	}
+	if wallet != nil {
+		detail.Chain, detail.Network = wallet.Chain, wallet.Network
+		detail.TxURL = s.links.TxURL(string(wallet.Chain), wallet.Network, txHash)
+	}
+
+	if detail.AML, err = s.aml.GetCheckResultByTxHash(ctx, txHash); err != nil {
+		return nil, err
+	}
+
+	// Квитанции запрашиваются только у ноды BSC, для остальных сетей достаточно данных сканера
+	if detail.Chain == entities.ChainBSC {
+		detail.OnChain, err = s.onChain(ctx, txHash)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get on-chain transaction data", "error", err, "tx_hash", txHash)
+			detail.OnChainError = err.Error()
+		}
+	}
+
+	return detail, nil
+}
+
+// GetConfirmations returns the confirmation progress of a deposit of the user with an ETA estimated from
+// recent block times. Confirmations of BSC deposits are read from the chain head, a failed node request
+// falls back to the confirmations recorded by the scanner without an ETA.
+func (s *TransactionDetailService) GetConfirmations(ctx context.Context, userID int64, txHash string) (*entities.DepositConfirmations, error) {
+	txHash = strings.ToLower(txHash)
+
+	_, wallet, _, err := s.findUserTransaction(ctx, userID, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := s.transactions.FindConfirmationProgress(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if wallet != nil {
+		progress.Chain, progress.Network = wallet.Chain, wallet.Network
+	}
+
+	if progress.Chain == entities.ChainBSC && progress.BlockNumber > 0 {
+		if err = s.liveConfirmations(ctx, progress); err != nil {
+			s.logger.WarnContext(ctx, "Failed to get live confirmations", "error", err, "tx_hash", txHash)
+		}
+	}
+
+	progress.Confirmed = progress.Confirmations >= progress.RequiredConfirmations
+	if progress.Confirmed {
+		var eta int64
+		progress.ETASeconds = &eta
+	} else if progress.BlockTimeSeconds > 0 {
+		remaining := float64(progress.RequiredConfirmations - progress.Confirmations)
+		eta := int64(math.Ceil(remaining * progress.BlockTimeSeconds))
+		progress.ETASeconds = &eta
+	}
+
+	return progress, nil
+}
+
+// liveConfirmations counts confirmations from the chain head and sets the average block time
+func (s *TransactionDetailService) liveConfirmations(ctx context.Context, progress *entities.DepositConfirmations) error {
+	head, err := s.chain.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", timeouts.Classify("rpc", err))
+	}
+	if blockNumber := uint64(progress.BlockNumber); head > blockNumber {
+		progress.Confirmations = max(progress.Confirmations, head-blockNumber)
+	}
+
+	blockTime, err := s.averageBlockTime(ctx, head)
+	if err != nil {
+		return err
+	}
+	progress.BlockTimeSeconds = blockTime.Seconds()
+	return nil
+}
+
+// averageBlockTime returns the average interval of the last blockTimeSampleBlocks blocks, cached for blockTimeCacheTTL
+func (s *TransactionDetailService) averageBlockTime(ctx context.Context, head uint64) (time.Duration, error) {
+	s.blockTimeMu.Lock()
+	defer s.blockTimeMu.Unlock()
+
+	if s.blockTime > 0 && time.Since(s.blockTimeAt) < blockTimeCacheTTL {
+		return s.blockTime, nil
+	}
+	if head < blockTimeSampleBlocks {
+		return 0, nil
+	}
+
+	latest, err := s.chain.HeaderByNumber(ctx, new(big.Int).SetUint64(head))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block %d: %w", head, timeouts.Classify("rpc", err))
+	}
+	earliest, err := s.chain.HeaderByNumber(ctx, new(big.Int).SetUint64(head-blockTimeSampleBlocks))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block %d: %w", head-blockTimeSampleBlocks, timeouts.Classify("rpc", err))
+	}
+	if latest.Time <= earliest.Time {
+		return 0, nil
+	}
+
+	// Время заголовка BSC — секунды, интервал блоков короче секунды усредняется по выборке
+	s.blockTime = time.Duration(latest.Time-earliest.Time) * time.Second / blockTimeSampleBlocks
+	s.blockTimeAt = time.Now()
+	return s.blockTime, nil
+}
+
+// findUserTransaction returns the transaction with its wallet and paid order. The transaction must be a deposit
+// to a wallet of the user or pay an order of the user, otherwise ErrTransactionNotFound is returned.
+func (s *TransactionDetailService) findUserTransaction(ctx context.Context, userID int64, txHash string) (*entities.Transaction, *entities.Wallet, *entities.Order, error) {
+	transaction, err := s.transactions.FindTransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if transaction == nil {
+		return nil, nil, nil, ErrTransactionNotFound
+	}
+
+	wallet, err := s.wallets.FindWalletByAddress(ctx, transaction.WalletAddress)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	order, err := s.orders.FindOrderByTxHash(ctx, txHash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Общий депозитный кошелек (мемо) принадлежит платформе, такую транзакцию видит владелец ордера
+	owned := wallet != nil && wallet.UserID == userID
+	if order != nil && int64(order.UserID) == userID {
+		owned = true
+	}
+	if !owned {
+		return nil, nil, nil, ErrTransactionNotFound
+	}
+
+	return transaction, wallet, order, nil
+}
+
+func (s *TransactionDetailService) onChain(ctx context.Context, txHash string) (*entities.OnChainTransaction, error) {
+	receipt, err := s.chain.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return &entities.OnChainTransaction{Status: entities.OnChainStatusPending}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", timeouts.Classify("rpc", err))
+	}
+
+	head, err := s.chain.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", timeouts.Classify("rpc", err))
+	}
+
+	tx := &entities.OnChainTransaction{
+		Status:      entities.OnChainStatusSuccess,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		BlockHash:   receipt.BlockHash.Hex(),
+		GasUsed:     receipt.GasUsed,
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		tx.Status = entities.OnChainStatusFailed
+	}
+	if receipt.EffectiveGasPrice != nil {
+		tx.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+	}
+	if head > tx.BlockNumber {
+		tx.Confirmations = head - tx.BlockNumber
+	}
+
+	return tx, nil
+}
@@ -2,6 +2,8 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"math/big"
 
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
@@ -11,42 +13,102 @@ import (
 
 type TransactionsRepository interface {
 	FindTransactionsByWallet(ctx context.Context, walletAddress string) ([]entities.Transaction, error)
-	InsertTransaction(ctx context.Context, txHash common.Hash, walletAddress string, amount *big.Int, blockNumber int64) error
-	UpdateTransaction(ctx context.Context, txHash string) error
-	UpdatePendingTransactions(ctx context.Context) error
+	InsertTransaction(ctx context.Context, txHash common.Hash, walletAddress, fromAddress, memo string, amount *big.Int, blockNumber int64, requiredConfirmations uint64) error
+	UpdateTransaction(ctx context.Context, txHash string, confirmations uint64) error
+	UpdatePendingTransactions(ctx context.Context) ([]entities.Overpayment, error)
+	CreditTransaction(ctx context.Context, txHash string) (bool, *entities.Overpayment, error)
+	FindTransactionByHash(ctx context.Context, txHash string) (*entities.Transaction, error)
 	UpdateTransactionAMLStatus(ctx context.Context, txHash string, status entities.AMLStatus) error
 }
 
+// OverpaymentRefunds создает возвраты переплаты зачисленных депозитов
+type OverpaymentRefunds interface {
+	RequestRefund(ctx context.Context, depositTxHash string, amount *big.Int, reason entities.RefundReason, initiatedBy string) (*entities.Refund, error)
+}
+
+var _ OverpaymentRefunds = (*RefundService)(nil)
+
 // TransactionServiceImpl handles blockchain transaction processing
 type TransactionServiceImpl struct {
-	repo TransactionsRepository
+	logger  *slog.Logger
+	repo    TransactionsRepository
+	refunds OverpaymentRefunds
 }
 
 // NewTransactionService creates a new transaction service
-func NewTransactionService(repo TransactionsRepository) *TransactionServiceImpl {
+func NewTransactionService(logger *slog.Logger, repo TransactionsRepository) *TransactionServiceImpl {
 	return &TransactionServiceImpl{
-		repo: repo,
+		logger: logger,
+		repo:   repo,
 	}
 }
 
+// SetOverpaymentRefunds включает возвраты переплаты: RefundService создается после сервиса транзакций
+func (ts *TransactionServiceImpl) SetOverpaymentRefunds(refunds OverpaymentRefunds) {
+	ts.refunds = refunds
+}
+
 // GetTransactionsByWallet retrieves all transactions for a specific wallet.
 func (ts *TransactionServiceImpl) GetTransactionsByWallet(ctx context.Context, walletAddress string) ([]entities.Transaction, error) {
 	return ts.repo.FindTransactionsByWallet(ctx, walletAddress)
 }
 
-// RecordTransaction stores a new transaction in the database
-func (ts *TransactionServiceImpl) RecordTransaction(ctx context.Context, txHash common.Hash, walletAddress string, amount *big.Int, blockNumber int64) error {
-	return ts.repo.InsertTransaction(ctx, txHash, walletAddress, amount, blockNumber)
+// RecordTransaction stores a new transaction in the database with the number of confirmations it requires.
+// The memo of the transfer, if any, is matched against order memos when the deposit is credited.
+func (ts *TransactionServiceImpl) RecordTransaction(ctx context.Context, txHash common.Hash, walletAddress, fromAddress, memo string, amount *big.Int, blockNumber int64, requiredConfirmations uint64) error {
+	return ts.repo.InsertTransaction(ctx, txHash, walletAddress, fromAddress, memo, amount, blockNumber, requiredConfirmations)
 }
 
 // ConfirmTransaction marks a transaction as confirmed after required confirmations
-func (ts *TransactionServiceImpl) ConfirmTransaction(ctx context.Context, txHash string) error {
-	return ts.repo.UpdateTransaction(ctx, txHash)
+func (ts *TransactionServiceImpl) ConfirmTransaction(ctx context.Context, txHash string, confirmations uint64) error {
+	return ts.repo.UpdateTransaction(ctx, txHash, confirmations)
 }
 
-// ProcessPendingTransactions processes all confirmed but unprocessed transactions
+// ProcessPendingTransactions processes all confirmed but unprocessed transactions and refunds their overpayments
 func (ts *TransactionServiceImpl) ProcessPendingTransactions(ctx context.Context) error {
-	return ts.repo.UpdatePendingTransactions(ctx)
+	overpayments, err := ts.repo.UpdatePendingTransactions(ctx)
+	for _, overpayment := range overpayments {
+		ts.refundOverpayment(ctx, overpayment)
+	}
+	return err
+}
+
+// FindTransactionByHash returns the recorded deposit or nil if it is not recorded
+func (ts *TransactionServiceImpl) FindTransactionByHash(ctx context.Context, txHash string) (*entities.Transaction, error) {
+	return ts.repo.FindTransactionByHash(ctx, txHash)
+}
+
+// CreditTransaction credits the single confirmed but unprocessed deposit and refunds its overpayment.
+// Returns false if the deposit is not confirmed, already processed, on hold or short of confirmations.
+func (ts *TransactionServiceImpl) CreditTransaction(ctx context.Context, txHash string) (bool, error) {
+	credited, overpayment, err := ts.repo.CreditTransaction(ctx, txHash)
+	if err != nil {
+		return false, err
+	}
+	if overpayment != nil {
+		ts.refundOverpayment(ctx, *overpayment)
+	}
+	return credited, nil
+}
+
+// refundOverpayment создает возврат переплаты, исполнение — через RefundService
+func (ts *TransactionServiceImpl) refundOverpayment(ctx context.Context, overpayment entities.Overpayment) {
+	if ts.refunds == nil {
+		ts.logger.WarnContext(ctx, "Overpayment refunds are not configured, overpayment is not refunded",
+			"tx_hash", overpayment.DepositTxHash, "amount", overpayment.Amount.String())
+		return
+	}
+
+	refund, err := ts.refunds.RequestRefund(ctx, overpayment.DepositTxHash, overpayment.Amount, entities.RefundReasonOverpayment, "rule:overpayment")
+	if errors.Is(err, ErrRefundExists) {
+		return
+	}
+	if err != nil {
+		ts.logger.ErrorContext(ctx, "Failed to create overpayment refund", "error", err, "tx_hash", overpayment.DepositTxHash)
+		return
+	}
+	ts.logger.InfoContext(ctx, "Overpayment refund created",
+		"tx_hash", overpayment.DepositTxHash, "amount", refund.Amount, "to", refund.ToAddress)
 }
 
 // MarkTransactionAMLFlagged отмечает транзакцию как подозрительную по результатам AML проверки
@@ -0,0 +1,504 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/safe"
+)
+
+const (
+	safeProposalsListLimit = 100
+	safeProposalOrigin     = "crypto-p2p-trading-app"
+)
+
+type SafeProposalsRepository interface {
+	CreateProposal(ctx context.Context, proposal *entities.SafeProposal) error
+	FindBySafeTxHash(ctx context.Context, safeTxHash string) (*entities.SafeProposal, error)
+	FindByStatus(ctx context.Context, status entities.SafeProposalStatus, limit int) ([]entities.SafeProposal, error)
+	NextNonce(ctx context.Context, safeAddress string) (uint64, error)
+	UpdateConfirmations(ctx context.Context, id string, confirmations, required int) error
+	MarkExecuted(ctx context.Context, id string, status entities.SafeProposalStatus, txHash string) error
+}
+
+// SafeTransactionService — API сервиса транзакций Safe
+type SafeTransactionService interface {
+	GetInfo(ctx context.Context, safe common.Address) (*safe.Info, error)
+	Propose(ctx context.Context, safe common.Address, tx *safe.Transaction, safeTxHash common.Hash, sender common.Address, signature []byte, origin string) error
+	GetTransaction(ctx context.Context, safeTxHash string) (*safe.MultisigTransaction, error)
+}
+
+// TreasuryWallets отправляет переводы напрямую и подписывает предложения ключом proposer'а
+type TreasuryWallets interface {
+	TransferFunds(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (string, error)
+	SignHash(ctx context.Context, keyVersion int, derivationPath string, hash common.Hash, operation string) (common.Address, []byte, error)
+	ServiceKeyVersion() int
+	ScreenAddresses(ctx context.Context, addresses ...string) error
+	CheckTokenHalt() error
+	Asset() entities.Asset
+}
+
+// WithdrawalLimiter резервирует сумму вывода в лимитах скорости до отправки перевода
+type WithdrawalLimiter interface {
+	Reserve(ctx context.Context, fromWalletID int, toAddress string, amount *big.Int, hot bool, initiatedBy string) (*entities.Withdrawal, error)
+	Complete(ctx context.Context, withdrawal *entities.Withdrawal, transfer *entities.TreasuryTransfer)
+	Release(ctx context.Context, withdrawal *entities.Withdrawal, cause error)
+}
+
+// DestinationScreener проверяет адрес получателя вывода до подписи перевода и связывает проверку с отправленным переводом
+type DestinationScreener interface {
+	Screen(ctx context.Context, kind entities.TreasuryTransferKind, toAddress string, amount *big.Int, initiatedBy string) (*entities.DestinationScreening, error)
+	Attach(ctx context.Context, screening *entities.DestinationScreening, transfer *entities.TreasuryTransfer)
+}
+
+// WithdrawalBatcher откладывает прямые выводы до следующей пакетной multisend транзакции
+type WithdrawalBatcher interface {
+	Enqueue(ctx context.Context, withdrawal *entities.Withdrawal) error
+}
+
+var (
+	_ SafeProposalsRepository = (*repository.SafeProposalsRepository)(nil)
+	_ SafeTransactionService  = (*safe.Client)(nil)
+	_ TreasuryWallets         = (*WalletService)(nil)
+	_ WithdrawalBatcher       = (*WithdrawalBatchService)(nil)
+	_ DestinationScreener     = (*DestinationScreeningService)(nil)
+)
+
+// TreasuryConfig describes the multisig treasury. An empty SafeAddress disables proposals.
+type TreasuryConfig struct {
+	SafeAddress string
+	// Переводы от этой суммы (в единицах актива) оформляются предложением Safe вместо прямой отправки
+	ProposalThreshold string
+	// Путь деривации ключа, зарегистрированного владельцем или делегатом Safe
+	ProposerPath string
+	PollInterval time.Duration
+}
+
+// TreasuryService routes sweeps and withdrawals: small amounts are sent directly from the deposit wallet,
+// amounts above the threshold are paid by the Safe treasury after the owners confirm the proposal.
+type TreasuryService struct {
+	logger  *slog.Logger
+	repo    SafeProposalsRepository
+	safe    SafeTransactionService
+	wallets TreasuryWallets
+	audit   *AuditService
+	ledger  TransactionLedger
+	limits  WithdrawalLimiter
+	batcher WithdrawalBatcher
+	screen  DestinationScreener
+
+	safeAddress  common.Address
+	threshold    *big.Int
+	proposerPath string
+	pollInterval time.Duration
+}
+
+func NewTreasuryService(
+	logger *slog.Logger,
+	repo SafeProposalsRepository,
+	safeService SafeTransactionService,
+	wallets TreasuryWallets,
+	audit *AuditService,
+	ledger TransactionLedger,
+	limits WithdrawalLimiter,
+	config TreasuryConfig,
+) (*TreasuryService, error) {
+	s := &TreasuryService{
+		logger:       logger,
+		repo:         repo,
+		safe:         safeService,
+		wallets:      wallets,
+		audit:        audit,
+		ledger:       ledger,
+		limits:       limits,
+		proposerPath: config.ProposerPath,
+		pollInterval: config.PollInterval,
+	}
+
+	if config.SafeAddress == "" {
+		return s, nil
+	}
+	if !common.IsHexAddress(config.SafeAddress) {
+		return nil, fmt.Errorf("invalid safe address %q", config.SafeAddress)
+	}
+	if _, _, err := ParseDerivationPath(config.ProposerPath); err != nil {
+		return nil, fmt.Errorf("invalid safe proposer path: %w", err)
+	}
+	threshold, err := tokenAmountToUnits(config.ProposalThreshold, wallets.Asset().Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid safe proposal threshold: %w", err)
+	}
+	if config.PollInterval <= 0 {
+		return nil, errors.New("safe poll interval must be positive")
+	}
+	s.threshold = threshold
+	s.safeAddress = common.HexToAddress(config.SafeAddress)
+
+	return s, nil
+}
+
+// SetWithdrawalBatcher queues direct withdrawals for multisend batches instead of sending them right away
+func (s *TreasuryService) SetWithdrawalBatcher(batcher WithdrawalBatcher) {
+	s.batcher = batcher
+}
+
+// SetDestinationScreening screens the destinations of withdrawals and settlements before the transfer is signed
+func (s *TreasuryService) SetDestinationScreening(screen DestinationScreener) {
+	s.screen = screen
+}
+
+// Enabled reports whether transfers above the threshold go through the Safe
+func (s *TreasuryService) Enabled() bool {
+	return s.safeAddress != (common.Address{}) && s.safe != nil
+}
+
+// Transfer sends amount to toAddress. Below the threshold the transfer is sent directly from the deposit wallet;
+// from the threshold on a Safe proposal paying from the treasury is created instead and must be confirmed by the owners.
+// Transfers into the Safe itself are always sent directly. With withdrawal batching direct withdrawals are
+// queued and paid by the next multisend batch. With destination screening the destinations of withdrawals and
+// settlements are screened first and a blocked destination returns ErrDestinationBlocked.
+func (s *TreasuryService) Transfer(
+	ctx context.Context,
+	client *ethclient.Client,
+	kind entities.TreasuryTransferKind,
+	fromWalletID int,
+	toAddress string,
+	amount *big.Int,
+	initiatedBy string,
+) (*entities.TreasuryTransfer, error) {
+	if asset := s.wallets.Asset(); kind == entities.TreasuryTransferWithdrawal && !asset.WithdrawalsEnabled {
+		return nil, fmt.Errorf("%w: %s", ErrWithdrawalsDisabled, asset.Code)
+	}
+
+	// Средства уходят с платформы безвозвратно, поэтому адрес проверяется до подписи; консолидации остаются внутри
+	if s.screen == nil || kind == entities.TreasuryTransferSweep {
+		return s.route(ctx, client, kind, fromWalletID, toAddress, amount, initiatedBy)
+	}
+	screening, err := s.screen.Screen(ctx, kind, toAddress, amount, initiatedBy)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := s.route(ctx, client, kind, fromWalletID, toAddress, amount, initiatedBy)
+	if err != nil {
+		return nil, err
+	}
+	s.screen.Attach(ctx, screening, transfer)
+
+	return transfer, nil
+}
+
+// route sends the transfer directly, proposes it to the Safe or queues it for the next batch
+func (s *TreasuryService) route(
+	ctx context.Context,
+	client *ethclient.Client,
+	kind entities.TreasuryTransferKind,
+	fromWalletID int,
+	toAddress string,
+	amount *big.Int,
+	initiatedBy string,
+) (*entities.TreasuryTransfer, error) {
+	fee, err := s.transferFee(kind)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withLedgerTag(ctx, ledgerKind(kind), amount, fee)
+	direct := !s.requiresProposal(toAddress, amount)
+
+	// Выводы пользователей проходят лимиты скорости: сумма резервируется до отправки и освобождается при ошибке
+	if kind != entities.TreasuryTransferWithdrawal || s.limits == nil {
+		return s.transfer(ctx, client, kind, direct, fromWalletID, toAddress, amount, initiatedBy)
+	}
+
+	withdrawal, err := s.limits.Reserve(ctx, fromWalletID, toAddress, amount, direct, initiatedBy)
+	if err != nil {
+		return nil, err
+	}
+	// Прямой вывод с пакетной отправкой ждет следующего пакета, лимит уже зарезервирован
+	if direct && s.batcher != nil {
+		if err = s.batcher.Enqueue(ctx, withdrawal); err != nil {
+			s.limits.Release(ctx, withdrawal, err)
+			return nil, err
+		}
+		return &entities.TreasuryTransfer{Queued: withdrawal}, nil
+	}
+	transfer, err := s.transfer(ctx, client, kind, direct, fromWalletID, toAddress, amount, initiatedBy)
+	if err != nil {
+		s.limits.Release(ctx, withdrawal, err)
+		return nil, err
+	}
+	s.limits.Complete(ctx, withdrawal, transfer)
+
+	return transfer, nil
+}
+
+func (s *TreasuryService) transfer(
+	ctx context.Context,
+	client *ethclient.Client,
+	kind entities.TreasuryTransferKind,
+	direct bool,
+	fromWalletID int,
+	toAddress string,
+	amount *big.Int,
+	initiatedBy string,
+) (*entities.TreasuryTransfer, error) {
+	if direct {
+		txHash, err := s.wallets.TransferFunds(ctx, client, fromWalletID, toAddress, amount)
+		if err != nil {
+			return nil, err
+		}
+		return &entities.TreasuryTransfer{TxHash: txHash}, nil
+	}
+
+	proposal, err := s.propose(ctx, client, kind, fromWalletID, toAddress, amount, initiatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.TreasuryTransfer{Proposal: proposal}, nil
+}
+
+// transferFee returns the platform fee charged for the transfer: the asset withdrawal fee for withdrawals, nothing for sweeps
+func (s *TreasuryService) transferFee(kind entities.TreasuryTransferKind) (*big.Int, error) {
+	if kind != entities.TreasuryTransferWithdrawal {
+		return nil, nil
+	}
+	asset := s.wallets.Asset()
+	fee, err := tokenAmountToUnits(asset.WithdrawalFee, asset.Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid withdrawal fee of %s: %w", asset.Code, err)
+	}
+	return fee, nil
+}
+
+func ledgerKind(kind entities.TreasuryTransferKind) entities.LedgerEntryKind {
+	switch kind {
+	case entities.TreasuryTransferWithdrawal, entities.TreasuryTransferSettlement:
+		return entities.LedgerKindWithdrawal
+	default:
+		return entities.LedgerKindSweep
+	}
+}
+
+func (s *TreasuryService) requiresProposal(toAddress string, amount *big.Int) bool {
+	if !s.Enabled() {
+		return false
+	}
+	if strings.EqualFold(toAddress, s.safeAddress.Hex()) {
+		return false
+	}
+	return amount.Cmp(s.threshold) >= 0
+}
+
+func (s *TreasuryService) propose(
+	ctx context.Context,
+	client *ethclient.Client,
+	kind entities.TreasuryTransferKind,
+	fromWalletID int,
+	toAddress string,
+	amount *big.Int,
+	initiatedBy string,
+) (*entities.SafeProposal, error) {
+	if !common.IsHexAddress(toAddress) {
+		return nil, fmt.Errorf("invalid destination address %q", toAddress)
+	}
+	if err := s.wallets.CheckTokenHalt(); err != nil {
+		return nil, err
+	}
+	if err := s.wallets.ScreenAddresses(ctx, toAddress); err != nil {
+		return nil, err
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	info, err := s.safe.GetInfo(ctx, s.safeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe info: %w", err)
+	}
+
+	// Nonce должен следовать за уже предложенными, но еще не исполненными транзакциями
+	nonce, err := s.repo.NextNonce(ctx, s.safeAddress.Hex())
+	if err != nil {
+		return nil, err
+	}
+	nonce = max(nonce, info.Nonce)
+
+	tx := &safe.Transaction{
+		To:        common.HexToAddress(s.wallets.Asset().Contract),
+		Value:     big.NewInt(0),
+		Data:      CreateERC20TransferData(toAddress, amount),
+		Operation: safe.OperationCall,
+		Nonce:     nonce,
+	}
+	safeTxHash := tx.Hash(chainID, s.safeAddress)
+
+	// Proposer — служебный ключ: его адрес среди владельцев Safe не меняется при смене активного сида
+	sender, signature, err := s.wallets.SignHash(ctx, s.wallets.ServiceKeyVersion(), s.proposerPath, safeTxHash, SignOperationSafeProposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign safe transaction: %w", err)
+	}
+
+	if err = s.safe.Propose(ctx, s.safeAddress, tx, safeTxHash, sender, signature, safeProposalOrigin); err != nil {
+		return nil, fmt.Errorf("failed to propose safe transaction: %w", err)
+	}
+
+	proposal := &entities.SafeProposal{
+		ID:                    uuid.New().String(),
+		SafeAddress:           s.safeAddress.Hex(),
+		SafeTxHash:            safeTxHash.Hex(),
+		Nonce:                 int64(nonce),
+		Kind:                  kind,
+		ToAddress:             common.HexToAddress(toAddress).Hex(),
+		Amount:                amount.String(),
+		Status:                entities.SafeProposalStatusProposed,
+		Confirmations:         1,
+		ConfirmationsRequired: info.Threshold,
+		InitiatedBy:           initiatedBy,
+	}
+	if fromWalletID > 0 {
+		proposal.FromWalletID = &fromWalletID
+	}
+
+	// Предложение уже в очереди Safe: при ошибке записи его все равно увидят владельцы
+	if err = s.repo.CreateProposal(ctx, proposal); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to store safe proposal", "error", err, "safe_tx_hash", proposal.SafeTxHash)
+		return nil, err
+	}
+
+	if s.audit != nil {
+		if err = s.audit.Record(ctx, entities.AuditEventSafeProposalCreated, initiatedBy, proposal.SafeAddress, map[string]any{
+			"safe_tx_hash": proposal.SafeTxHash,
+			"nonce":        proposal.Nonce,
+			"kind":         proposal.Kind,
+			"to":           proposal.ToAddress,
+			"amount":       proposal.Amount,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record safe proposal audit event", "error", err, "safe_tx_hash", proposal.SafeTxHash)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Safe transaction proposed",
+		"safe", proposal.SafeAddress,
+		"safe_tx_hash", proposal.SafeTxHash,
+		"nonce", nonce,
+		"kind", kind,
+		"to", proposal.ToAddress,
+		"amount", proposal.Amount,
+		"threshold", info.Threshold)
+
+	return proposal, nil
+}
+
+// GetProposals returns proposals with the given status (empty status — all)
+func (s *TreasuryService) GetProposals(ctx context.Context, status entities.SafeProposalStatus) ([]entities.SafeProposal, error) {
+	return s.repo.FindByStatus(ctx, status, safeProposalsListLimit)
+}
+
+// GetProposal returns a proposal by its safeTxHash
+func (s *TreasuryService) GetProposal(ctx context.Context, safeTxHash string) (*entities.SafeProposal, error) {
+	proposal, err := s.repo.FindBySafeTxHash(ctx, safeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, ErrSafeProposalNotFound
+	}
+	return proposal, nil
+}
+
+// Start polls the transaction service for confirmations and execution of the open proposals
+func (s *TreasuryService) Start(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollProposals(ctx)
+		}
+	}
+}
+
+func (s *TreasuryService) pollProposals(ctx context.Context) {
+	proposals, err := s.repo.FindByStatus(ctx, entities.SafeProposalStatusProposed, safeProposalsListLimit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get open safe proposals", "error", err)
+		return
+	}
+
+	for _, proposal := range proposals {
+		tx, err := s.safe.GetTransaction(ctx, proposal.SafeTxHash)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get safe transaction", "error", err, "safe_tx_hash", proposal.SafeTxHash)
+			continue
+		}
+
+		if !tx.IsExecuted {
+			if len(tx.Confirmations) != proposal.Confirmations || tx.ConfirmationsRequired != proposal.ConfirmationsRequired {
+				if err = s.repo.UpdateConfirmations(ctx, proposal.ID, len(tx.Confirmations), tx.ConfirmationsRequired); err != nil {
+					s.logger.ErrorContext(ctx, "Failed to update safe proposal confirmations", "error", err, "safe_tx_hash", proposal.SafeTxHash)
+				}
+			}
+			continue
+		}
+
+		status := entities.SafeProposalStatusExecuted
+		if tx.IsSuccessful != nil && !*tx.IsSuccessful {
+			status = entities.SafeProposalStatusFailed
+		}
+		var txHash string
+		if tx.TransactionHash != nil {
+			txHash = *tx.TransactionHash
+		}
+
+		if err = s.repo.MarkExecuted(ctx, proposal.ID, status, txHash); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to mark safe proposal executed", "error", err, "safe_tx_hash", proposal.SafeTxHash)
+			continue
+		}
+		if txHash != "" {
+			s.recordExecution(ctx, proposal, txHash)
+		}
+
+		s.logger.InfoContext(ctx, "Safe transaction executed",
+			"safe_tx_hash", proposal.SafeTxHash,
+			"tx_hash", txHash,
+			"status", status,
+			"confirmations", len(tx.Confirmations))
+	}
+}
+
+// recordExecution adds the Safe execution to the ledger. The transaction is sent by an owner,
+// so its gas price is unknown until the receipt is settled.
+func (s *TreasuryService) recordExecution(ctx context.Context, proposal entities.SafeProposal, txHash string) {
+	amount, ok := new(big.Int).SetString(proposal.Amount, 10)
+	if !ok {
+		s.logger.ErrorContext(ctx, "Invalid safe proposal amount", "amount", proposal.Amount, "safe_tx_hash", proposal.SafeTxHash)
+		return
+	}
+	fee, err := s.transferFee(proposal.Kind)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get safe execution fee", "error", err, "safe_tx_hash", proposal.SafeTxHash)
+		return
+	}
+
+	ctx = withLedgerTag(ctx, ledgerKind(proposal.Kind), amount, fee)
+	s.ledger.RecordSent(ctx, txHash, LedgerOperationSafeExecution, s.safeAddress, common.HexToAddress(proposal.ToAddress), big.NewInt(0), 0)
+}
@@ -0,0 +1,284 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// treasuryProposalsLimit ограничивает выборку ожидающих предложений Safe для подсчета свипов
+const treasuryProposalsLimit = 1000
+
+type TreasuryLedgerRepository interface {
+	GetWalletLedgers(ctx context.Context) ([]entities.WalletLedger, error)
+}
+
+// TreasuryOverviewWallets отдает балансы мониторинга и балансы кошельков вне мониторинга
+type TreasuryOverviewWallets interface {
+	LookupWalletBalances(ctx context.Context, addresses []string) (map[string]*entities.WalletBalance, error)
+	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
+	Asset() entities.Asset
+}
+
+type TreasuryOverviewProposals interface {
+	FindByStatus(ctx context.Context, status entities.SafeProposalStatus, limit int) ([]entities.SafeProposal, error)
+}
+
+var (
+	_ TreasuryLedgerRepository  = (*repository.TransactionsRepository)(nil)
+	_ TreasuryOverviewWallets   = (*WalletService)(nil)
+	_ TreasuryOverviewProposals = (*repository.SafeProposalsRepository)(nil)
+)
+
+// TreasuryOverviewConfig описывает роли кошельков. Safe казначейства всегда считается холодным кошельком.
+type TreasuryOverviewConfig struct {
+	HotWallets  []string
+	ColdWallets []string
+	// Кошельки с балансом от этой суммы (в единицах актива) ожидают свипа
+	SweepMinAmount string
+}
+
+// TreasuryOverviewService агрегирует средства платформы по ролям кошельков, ожидающие свипы
+// и расхождения балансов депозитных кошельков с записанными депозитами
+type TreasuryOverviewService struct {
+	logger    *slog.Logger
+	ledger    TreasuryLedgerRepository
+	proposals TreasuryOverviewProposals
+	wallets   TreasuryOverviewWallets
+
+	hot            []common.Address
+	cold           []common.Address
+	sweepMinAmount *big.Int
+}
+
+func NewTreasuryOverviewService(
+	logger *slog.Logger,
+	ledger TreasuryLedgerRepository,
+	proposals TreasuryOverviewProposals,
+	wallets TreasuryOverviewWallets,
+	config TreasuryOverviewConfig,
+) (*TreasuryOverviewService, error) {
+	hot, err := parseTreasuryAddresses(config.HotWallets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hot wallet: %w", err)
+	}
+	cold, err := parseTreasuryAddresses(config.ColdWallets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cold wallet: %w", err)
+	}
+	sweepMinAmount, err := tokenAmountToUnits(config.SweepMinAmount, wallets.Asset().Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sweep minimum amount: %w", err)
+	}
+
+	return &TreasuryOverviewService{
+		logger:         logger,
+		ledger:         ledger,
+		proposals:      proposals,
+		wallets:        wallets,
+		hot:            hot,
+		cold:           cold,
+		sweepMinAmount: sweepMinAmount,
+	}, nil
+}
+
+// GetOverview builds the overview. Deposit wallets are read from the balances stored by the monitor,
+// hot and cold wallets are not monitored and are queried from the chain.
+func (s *TreasuryOverviewService) GetOverview(ctx context.Context, client *ethclient.Client) (*entities.TreasuryOverview, error) {
+	asset := s.wallets.Asset()
+
+	ledgers, err := s.ledger.GetWalletLedgers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &entities.TreasuryOverview{
+		Unreconciled: []entities.UnreconciledWallet{},
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	roles := make(map[string]entities.TreasuryWalletRole, len(s.hot)+len(s.cold))
+	for _, address := range s.hot {
+		roles[strings.ToLower(address.Hex())] = entities.TreasuryRoleHot
+	}
+	for _, address := range s.cold {
+		roles[strings.ToLower(address.Hex())] = entities.TreasuryRoleCold
+	}
+
+	addresses := make([]string, 0, len(ledgers))
+	for _, ledger := range ledgers {
+		if _, ok := roles[strings.ToLower(ledger.Address)]; !ok {
+			addresses = append(addresses, ledger.Address)
+		}
+	}
+	cache, err := s.wallets.LookupWalletBalances(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	depositToken, depositNative := new(big.Int), new(big.Int)
+	unreconciledTotal := new(big.Int)
+	pendingWallets, pendingAmount := 0, new(big.Int)
+	depositWallets := 0
+
+	for _, ledger := range ledgers {
+		if _, ok := roles[strings.ToLower(ledger.Address)]; ok {
+			continue
+		}
+		depositWallets++
+
+		balance, ok := cache[ledger.Address]
+		if !ok {
+			continue
+		}
+		if overview.BalancesAsOf == nil || balance.LastChecked.Before(*overview.BalancesAsOf) {
+			checked := balance.LastChecked
+			overview.BalancesAsOf = &checked
+		}
+
+		depositToken.Add(depositToken, balance.TokenBalance)
+		depositNative.Add(depositNative, balance.NativeBalance)
+		if balance.TokenBalance.Cmp(s.sweepMinAmount) >= 0 {
+			pendingWallets++
+			pendingAmount.Add(pendingAmount, balance.TokenBalance)
+		}
+
+		// Исходящие свипы по кошелькам не записываются, поэтому баланс меньше депозитов — норма,
+		// а баланс больше депозитов за вычетом возвратов означает незаписанное поступление
+		expected, err := ledgerBalance(ledger)
+		if err != nil {
+			return nil, err
+		}
+		if diff := new(big.Int).Sub(balance.TokenBalance, expected); diff.Sign() > 0 {
+			unreconciledTotal.Add(unreconciledTotal, diff)
+			overview.Unreconciled = append(overview.Unreconciled, entities.UnreconciledWallet{
+				Address:    ledger.Address,
+				OnChain:    unitsToTokenAmount(balance.TokenBalance, asset.Decimals),
+				Ledger:     unitsToTokenAmount(expected, asset.Decimals),
+				Difference: unitsToTokenAmount(diff, asset.Decimals),
+			})
+		}
+	}
+
+	overview.Balances = append(overview.Balances,
+		treasuryBalance(asset.Code, entities.TreasuryRoleDeposit, depositWallets, depositToken, asset.Decimals),
+		treasuryBalance("BNB", entities.TreasuryRoleDeposit, depositWallets, depositNative, bnbDecimals))
+
+	for _, role := range []struct {
+		role      entities.TreasuryWalletRole
+		addresses []common.Address
+	}{
+		{entities.TreasuryRoleHot, s.hot},
+		{entities.TreasuryRoleCold, s.cold},
+	} {
+		token, native, err := s.liveBalances(ctx, client, role.addresses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s wallet balances: %w", role.role, err)
+		}
+		overview.Balances = append(overview.Balances,
+			treasuryBalance(asset.Code, role.role, len(role.addresses), token, asset.Decimals),
+			treasuryBalance("BNB", role.role, len(role.addresses), native, bnbDecimals))
+	}
+
+	proposals, err := s.proposals.FindByStatus(ctx, entities.SafeProposalStatusProposed, treasuryProposalsLimit)
+	if err != nil {
+		return nil, err
+	}
+	proposedAmount, proposalsCount := new(big.Int), 0
+	for _, proposal := range proposals {
+		if proposal.Kind != entities.TreasuryTransferSweep {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(proposal.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q of safe proposal %s", proposal.Amount, proposal.ID)
+		}
+		proposedAmount.Add(proposedAmount, amount)
+		proposalsCount++
+	}
+
+	overview.PendingSweeps = entities.PendingSweeps{
+		Asset:          asset.Code,
+		Wallets:        pendingWallets,
+		Amount:         unitsToTokenAmount(pendingAmount, asset.Decimals),
+		Proposals:      proposalsCount,
+		ProposedAmount: unitsToTokenAmount(proposedAmount, asset.Decimals),
+	}
+	overview.UnreconciledTotal = unitsToTokenAmount(unreconciledTotal, asset.Decimals)
+
+	if len(overview.Unreconciled) > 0 {
+		s.logger.WarnContext(ctx, "Deposit wallet balances exceed recorded deposits",
+			"wallets", len(overview.Unreconciled),
+			"difference", overview.UnreconciledTotal)
+	}
+
+	return overview, nil
+}
+
+// liveBalances sums token and BNB balances of wallets that are not covered by the balance monitor
+func (s *TreasuryOverviewService) liveBalances(ctx context.Context, client *ethclient.Client, addresses []common.Address) (*big.Int, *big.Int, error) {
+	token, native := new(big.Int), new(big.Int)
+	for _, address := range addresses {
+		tokenBalance, err := s.wallets.GetERC20TokenBalance(ctx, client, address.Hex())
+		if err != nil {
+			return nil, nil, err
+		}
+		nativeBalance, err := client.BalanceAt(ctx, address, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get BNB balance of %s: %w", address.Hex(), err)
+		}
+		token.Add(token, tokenBalance)
+		native.Add(native, nativeBalance)
+	}
+	return token, native, nil
+}
+
+// ledgerBalance returns deposits minus completed refunds of the wallet
+func ledgerBalance(ledger entities.WalletLedger) (*big.Int, error) {
+	deposited, ok := new(big.Int).SetString(ledger.Deposited, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid deposited amount %q of wallet %s", ledger.Deposited, ledger.Address)
+	}
+	refunded, ok := new(big.Int).SetString(ledger.Refunded, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid refunded amount %q of wallet %s", ledger.Refunded, ledger.Address)
+	}
+	return deposited.Sub(deposited, refunded), nil
+}
+
+func treasuryBalance(asset string, role entities.TreasuryWalletRole, wallets int, units *big.Int, decimals int) entities.TreasuryBalance {
+	return entities.TreasuryBalance{
+		Asset:   asset,
+		Role:    role,
+		Wallets: wallets,
+		Amount:  unitsToTokenAmount(units, decimals),
+		Units:   units.String(),
+	}
+}
+
+func parseTreasuryAddresses(addresses []string) ([]common.Address, error) {
+	parsed := make([]common.Address, 0, len(addresses))
+	seen := make(map[common.Address]bool, len(addresses))
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address %q", address)
+		}
+		if addr := common.HexToAddress(address); !seen[addr] {
+			seen[addr] = true
+			parsed = append(parsed, addr)
+		}
+	}
+	return parsed, nil
+}
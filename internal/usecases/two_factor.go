@@ -0,0 +1,214 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/totp"
+)
+
+// Допустимое отклонение часов клиента в шагах TOTP (±30 секунд)
+const twoFactorSkew = 1
+
+type TwoFactorRepository interface {
+	FindByUserID(ctx context.Context, userID int64) (*entities.TwoFactorSecret, error)
+	UpsertPendingSecret(ctx context.Context, userID int64, secret string) error
+	Enable(ctx context.Context, userID int64, step int64) error
+	UpdateLastUsedStep(ctx context.Context, userID int64, step int64) (bool, error)
+	UpdateAdminLastUsedStep(ctx context.Context, operator string, step int64) (bool, error)
+}
+
+var _ TwoFactorRepository = (*repository.TwoFactorRepository)(nil)
+
+// TwoFactorService handles TOTP enrollment and verification of sensitive operations
+type TwoFactorService struct {
+	logger *slog.Logger
+	repo   TwoFactorRepository
+	audit  *AuditService
+
+	issuer   string
+	enforced bool
+
+	// Секреты TOTP операторов админки: CN клиентского сертификата или IP
+	adminSecrets map[string]string
+}
+
+// NewTwoFactorService creates a new two-factor service.
+// If enforced is true, users without a confirmed enrollment cannot perform protected operations.
+func NewTwoFactorService(logger *slog.Logger, repo TwoFactorRepository, audit *AuditService, issuer string, enforced bool) *TwoFactorService {
+	return &TwoFactorService{
+		logger:   logger,
+		repo:     repo,
+		audit:    audit,
+		issuer:   issuer,
+		enforced: enforced,
+	}
+}
+
+// SetAdminSecrets configures TOTP secrets of admin operators with entries "operator=secret".
+// The operator is the client certificate CN of the admin server or, without mTLS, the client IP.
+func (s *TwoFactorService) SetAdminSecrets(entries []string) error {
+	secrets := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		operator, secret, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		operator, secret = strings.TrimSpace(operator), strings.TrimSpace(secret)
+		if !ok || operator == "" || secret == "" {
+			return fmt.Errorf("invalid admin two-factor secret for %q: expected operator=secret", operator)
+		}
+		if _, err := totp.CodeAt(secret, 0); err != nil {
+			return fmt.Errorf("invalid admin two-factor secret for %q: %w", operator, err)
+		}
+		secrets[operator] = secret
+	}
+
+	s.adminSecrets = secrets
+	return nil
+}
+
+// Enroll generates a new secret for the user. The enrollment is inactive until confirmed with a valid code.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID int64) (*entities.TwoFactorEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.repo.UpsertPendingSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &entities.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, fmt.Sprintf("user-%d", userID), secret),
+	}, nil
+}
+
+// ConfirmEnrollment activates the enrollment after the user proved the authenticator app is set up
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID int64, code string) error {
+	enrollment, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now(), twoFactorSkew)
+	if !ok {
+		s.recordAudit(ctx, entities.AuditEventTwoFactorFailed, userID, "confirm_enrollment")
+		return ErrTwoFactorInvalidCode
+	}
+
+	if err = s.repo.Enable(ctx, userID, step); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, entities.AuditEventTwoFactorEnrolled, userID, "confirm_enrollment")
+	return nil
+}
+
+// IsEnabled reports whether the user has a confirmed enrollment
+func (s *TwoFactorService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	enrollment, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return enrollment != nil && enrollment.Enabled, nil
+}
+
+// VerifyOperation checks the TOTP code of the user for a protected operation
+func (s *TwoFactorService) VerifyOperation(ctx context.Context, userID int64, operation, code string) error {
+	enrollment, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if enrollment == nil || !enrollment.Enabled {
+		if s.enforced {
+			return ErrTwoFactorEnrollmentRequired
+		}
+		// 2FA не настроена и не обязательна: операцию разрешаем
+		return nil
+	}
+
+	if code == "" {
+		return ErrTwoFactorRequired
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now(), twoFactorSkew)
+	if !ok || step <= enrollment.LastUsedStep {
+		s.recordAudit(ctx, entities.AuditEventTwoFactorFailed, userID, operation)
+		return ErrTwoFactorInvalidCode
+	}
+
+	// Защита от повторного использования кода: шаг может только расти
+	updated, err := s.repo.UpdateLastUsedStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		s.recordAudit(ctx, entities.AuditEventTwoFactorFailed, userID, operation)
+		return ErrTwoFactorInvalidCode
+	}
+
+	s.recordAudit(ctx, entities.AuditEventTwoFactorVerified, userID, operation)
+	return nil
+}
+
+// VerifyAdminOperation checks the TOTP code of the admin operator for an action that moves funds.
+// The actor is "admin:" followed by the operator; an operator without a configured secret is rejected.
+func (s *TwoFactorService) VerifyAdminOperation(ctx context.Context, actor, operation, code string) error {
+	operator := strings.TrimPrefix(actor, "admin:")
+	secret, ok := s.adminSecrets[operator]
+	if !ok {
+		return ErrTwoFactorEnrollmentRequired
+	}
+
+	if code == "" {
+		return ErrTwoFactorRequired
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), twoFactorSkew)
+	if !ok {
+		s.recordAdminAudit(ctx, entities.AuditEventTwoFactorFailed, actor, operation)
+		return ErrTwoFactorInvalidCode
+	}
+
+	// Защита от повторного использования кода: шаг может только расти
+	updated, err := s.repo.UpdateAdminLastUsedStep(ctx, operator, step)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		s.recordAdminAudit(ctx, entities.AuditEventTwoFactorFailed, actor, operation)
+		return ErrTwoFactorInvalidCode
+	}
+
+	s.recordAdminAudit(ctx, entities.AuditEventTwoFactorVerified, actor, operation)
+	return nil
+}
+
+func (s *TwoFactorService) recordAdminAudit(ctx context.Context, eventType entities.AuditEventType, actor, operation string) {
+	if s.audit == nil {
+		return
+	}
+	// Ошибка уже залогирована внутри AuditService
+	_ = s.audit.Record(ctx, eventType, actor, actor, map[string]any{
+		"operation": operation,
+	})
+}
+
+func (s *TwoFactorService) recordAudit(ctx context.Context, eventType entities.AuditEventType, userID int64, operation string) {
+	if s.audit == nil {
+		return
+	}
+	// Ошибка уже залогирована внутри AuditService
+	_ = s.audit.Record(ctx, eventType, strconv.FormatInt(userID, 10), strconv.FormatInt(userID, 10), map[string]any{
+		"operation": operation,
+	})
+}
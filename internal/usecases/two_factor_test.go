@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/totp"
+)
+
+// stubTwoFactorRepository хранит последние использованные шаги операторов в памяти
+type stubTwoFactorRepository struct {
+	TwoFactorRepository
+	adminSteps map[string]int64
+}
+
+func (r *stubTwoFactorRepository) UpdateAdminLastUsedStep(_ context.Context, operator string, step int64) (bool, error) {
+	if r.adminSteps[operator] >= step {
+		return false, nil
+	}
+	r.adminSteps[operator] = step
+	return true, nil
+}
+
+func TestVerifyAdminOperation(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	repo := &stubTwoFactorRepository{adminSteps: make(map[string]int64)}
+	service := NewTwoFactorService(slog.New(slog.NewTextHandler(io.Discard, nil)), repo, nil, "test", false)
+	require.NoError(t, service.SetAdminSecrets([]string{" ops-1 = " + secret}))
+
+	code, err := totp.CodeAt(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, service.VerifyAdminOperation(ctx, "admin:ops-2", "runbook", code), ErrTwoFactorEnrollmentRequired)
+	assert.ErrorIs(t, service.VerifyAdminOperation(ctx, "admin:ops-1", "runbook", ""), ErrTwoFactorRequired)
+	assert.ErrorIs(t, service.VerifyAdminOperation(ctx, "admin:ops-1", "runbook", "abcdef"), ErrTwoFactorInvalidCode)
+
+	require.NoError(t, service.VerifyAdminOperation(ctx, "admin:ops-1", "runbook", code))
+	// Код нельзя использовать повторно
+	assert.ErrorIs(t, service.VerifyAdminOperation(ctx, "admin:ops-1", "runbook", code), ErrTwoFactorInvalidCode)
+}
+
+func TestSetAdminSecretsInvalid(t *testing.T) {
+	service := NewTwoFactorService(slog.New(slog.NewTextHandler(io.Discard, nil)), &stubTwoFactorRepository{}, nil, "test", false)
+
+	for _, entry := range []string{"ops-1", "=JBSWY3DPEHPK3PXP", "ops-1=", "ops-1=not base32!"} {
+		assert.Error(t, service.SetAdminSecrets([]string{entry}), entry)
+	}
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"expvar"
+	"math/big"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/lru"
+)
+
+// DefaultWalletCacheSize — размер кешей кошельков и балансов, если он не задан
+const DefaultWalletCacheSize = 100000
+
+// walletBalancePageSize — сколько кошельков проверяется и сохраняется за один шаг прохода мониторинга балансов
+const walletBalancePageSize = 1000
+
+var (
+	walletCacheHits             = expvar.NewInt("wallet_cache_hits")
+	walletCacheMisses           = expvar.NewInt("wallet_cache_misses")
+	walletCacheEvictions        = expvar.NewInt("wallet_cache_evictions")
+	walletBalanceCacheHits      = expvar.NewInt("wallet_balance_cache_hits")
+	walletBalanceCacheMisses    = expvar.NewInt("wallet_balance_cache_misses")
+	walletBalanceCacheEvictions = expvar.NewInt("wallet_balance_cache_evictions")
+)
+
+func init() {
+	expvar.Publish("wallet_cache_hit_rate", expvar.Func(func() any {
+		return hitRate(walletCacheHits, walletCacheMisses)
+	}))
+	expvar.Publish("wallet_balance_cache_hit_rate", expvar.Func(func() any {
+		return hitRate(walletBalanceCacheHits, walletBalanceCacheMisses)
+	}))
+}
+
+// hitRate — доля попаданий в кеш с запуска, 0 до первого обращения
+func hitRate(hits, misses *expvar.Int) float64 {
+	total := hits.Value() + misses.Value()
+	if total == 0 {
+		return 0
+	}
+	return float64(hits.Value()) / float64(total)
+}
+
+// WalletCacheConfig ограничивает число кошельков в кешах отслеживаемых адресов и балансов.
+// Вытесненные записи читаются из базы при следующем обращении.
+type WalletCacheConfig struct {
+	Addresses int
+	Balances  int
+}
+
+func newWalletCaches(config WalletCacheConfig) (*lru.Cache[string, struct{}], *lru.Cache[string, *entities.WalletBalance]) {
+	if config.Addresses <= 0 {
+		config.Addresses = DefaultWalletCacheSize
+	}
+	if config.Balances <= 0 {
+		config.Balances = DefaultWalletCacheSize
+	}
+	return lru.New[string, struct{}](config.Addresses), lru.New[string, *entities.WalletBalance](config.Balances)
+}
+
+// isCachedWallet reports whether the address is in the cache of tracked wallets
+func (bsc *WalletService) isCachedWallet(address string) bool {
+	if _, ok := bsc.wallets.Get(address); ok {
+		walletCacheHits.Add(1)
+		return true
+	}
+	walletCacheMisses.Add(1)
+	return false
+}
+
+// cacheWallet adds the address to the cache of tracked wallets
+func (bsc *WalletService) cacheWallet(address string) {
+	if bsc.wallets.Add(address, struct{}{}) {
+		walletCacheEvictions.Add(1)
+	}
+}
+
+// cacheBalance adds the balance to the balance cache as the most recently used
+func (bsc *WalletService) cacheBalance(balance *entities.WalletBalance) {
+	if bsc.walletBalances.Add(balance.Address, balance) {
+		walletBalanceCacheEvictions.Add(1)
+	}
+}
+
+// LookupWalletBalances returns copies of the latest balances of the addresses from the cache, falling back to
+// the balances stored by the monitor for evicted wallets. Addresses never checked by the monitor are absent.
+func (bsc *WalletService) LookupWalletBalances(ctx context.Context, addresses []string) (map[string]*entities.WalletBalance, error) {
+	balances := make(map[string]*entities.WalletBalance, len(addresses))
+	var missing []string
+	for _, address := range addresses {
+		if _, ok := balances[address]; ok {
+			continue
+		}
+		if balance, ok := bsc.walletBalances.Get(address); ok {
+			walletBalanceCacheHits.Add(1)
+			balances[address] = copyWalletBalance(balance)
+			continue
+		}
+		walletBalanceCacheMisses.Add(1)
+		missing = append(missing, address)
+	}
+	if len(missing) == 0 {
+		return balances, nil
+	}
+
+	stored, err := bsc.repo.FindWalletBalances(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for address, balance := range stored {
+		bsc.cacheBalance(balance)
+		balances[address] = copyWalletBalance(balance)
+	}
+	return balances, nil
+}
+
+// saveBalances stores the balances checked by the monitor and refreshes the cached ones without promoting them,
+// so a pass over every wallet does not push out the wallets readers use. Returns the statuses stored before.
+func (bsc *WalletService) saveBalances(ctx context.Context, balances []entities.WalletBalance) (map[string]entities.BalanceStatus, error) {
+	for i := range balances {
+		bsc.walletBalances.Update(balances[i].Address, &balances[i])
+	}
+	return bsc.repo.SaveWalletBalances(ctx, balances)
+}
+
+func copyWalletBalance(balance *entities.WalletBalance) *entities.WalletBalance {
+	return &entities.WalletBalance{
+		Address:       balance.Address,
+		TokenBalance:  new(big.Int).Set(balance.TokenBalance),
+		NativeBalance: new(big.Int).Set(balance.NativeBalance),
+		Status:        balance.Status,
+		LastChecked:   balance.LastChecked,
+	}
+}
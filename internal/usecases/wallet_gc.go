@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const walletGCBatchSize = 100
+
+var walletsRetired = expvar.NewInt("bsc_wallets_retired")
+
+type WalletGCRepository interface {
+	FindRetirementCandidates(ctx context.Context, idleBefore time.Time, limit int) ([]entities.Wallet, error)
+	RetireWallet(ctx context.Context, id int) error
+}
+
+type WalletGCWallets interface {
+	Chain() entities.Chain
+	GetWalletBalance(ctx context.Context, address string) (*entities.WalletBalance, error)
+	ForgetWallet(address string)
+}
+
+var (
+	_ WalletGCRepository = (*repository.WalletsRepository)(nil)
+	_ WalletGCWallets    = (*WalletService)(nil)
+)
+
+// WalletGCConfig задает сборку неиспользуемых кошельков. Нулевой Retention отключает сборку.
+type WalletGCConfig struct {
+	// Кошелек выводится из мониторинга, если к нему не было депозитов дольше Retention
+	Retention time.Duration
+	Interval  time.Duration
+	// Остаток BNB на кошельке, который не мешает выводу из мониторинга, например "0.0001"
+	NativeDust string
+}
+
+// WalletGCService retires empty, swept deposit wallets from active monitoring after the retention period.
+// Retired wallets are not scanned for deposits, not reused and not loaded into the wallet cache.
+type WalletGCService struct {
+	logger  *slog.Logger
+	repo    WalletGCRepository
+	wallets WalletGCWallets
+
+	retention  time.Duration
+	interval   time.Duration
+	nativeDust *big.Int
+}
+
+func NewWalletGCService(logger *slog.Logger, repo WalletGCRepository, wallets WalletGCWallets, config WalletGCConfig) (*WalletGCService, error) {
+	dust, err := parseBNBAmount(config.NativeDust)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet GC native dust: %w", err)
+	}
+	if dust == nil {
+		dust = new(big.Int)
+	}
+	if config.Retention > 0 && config.Interval <= 0 {
+		return nil, fmt.Errorf("wallet GC interval must be positive")
+	}
+
+	return &WalletGCService{
+		logger:     logger,
+		repo:       repo,
+		wallets:    wallets,
+		retention:  config.Retention,
+		interval:   config.Interval,
+		nativeDust: dust,
+	}, nil
+}
+
+// Start periodically retires idle wallets until the context is cancelled
+func (s *WalletGCService) Start(ctx context.Context) {
+	if s.retention <= 0 {
+		s.logger.Info("Wallet garbage collection is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.collect(ctx); err != nil {
+				s.logger.ErrorContext(ctx, "Wallet garbage collection failed", "error", err)
+			}
+		}
+	}
+}
+
+func (s *WalletGCService) collect(ctx context.Context) error {
+	candidates, err := s.repo.FindRetirementCandidates(ctx, time.Now().Add(-s.retention), walletGCBatchSize)
+	if err != nil {
+		return err
+	}
+
+	retired := 0
+	for _, wallet := range candidates {
+		// Баланс проверяется только в обслуживаемой сети, кошельки других сетей (например общий депозитный TON) остаются
+		if wallet.Chain != s.wallets.Chain() {
+			continue
+		}
+
+		// Кошелек с остатком токена или газа не выводится: средства должны быть сначала собраны свипом
+		balance, err := s.wallets.GetWalletBalance(ctx, wallet.Address)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to check wallet balance before retirement", "error", err, "address", wallet.Address)
+			continue
+		}
+		if balance.TokenBalance.Sign() > 0 || balance.NativeBalance.Cmp(s.nativeDust) > 0 {
+			continue
+		}
+
+		if err = s.repo.RetireWallet(ctx, wallet.ID); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to retire wallet", "error", err, "wallet_id", wallet.ID)
+			continue
+		}
+		s.wallets.ForgetWallet(wallet.Address)
+		walletsRetired.Add(1)
+		retired++
+	}
+
+	if retired > 0 {
+		s.logger.InfoContext(ctx, "Retired idle wallets", "count", retired, "candidates", len(candidates))
+	}
+	return nil
+}
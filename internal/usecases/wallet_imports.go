@@ -0,0 +1,411 @@
+package usecases
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	walletImportMaxWallets = 1000
+	// Число адресов получателей в одном фильтре eth_getLogs
+	walletImportAddressBatch = 100
+	walletImportsListLimit   = 50
+)
+
+// walletImportTransferTopic — keccak256("Transfer(address,address,uint256)"), получатель во втором индексированном аргументе
+var walletImportTransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+type WalletImportsRepository interface {
+	CreateImport(ctx context.Context, walletImport *entities.WalletImport, walletIDs []int) error
+	FindImport(ctx context.Context, id int64) (*entities.WalletImport, error)
+	FindImports(ctx context.Context, limit int) ([]entities.WalletImport, error)
+	ClaimPendingImport(ctx context.Context) (*entities.WalletImport, error)
+	FindImportWallets(ctx context.Context, importID int64) ([]entities.Wallet, error)
+	FinishImport(ctx context.Context, id int64, reason *string) (*entities.WalletImport, error)
+	RecordHistoricalDeposit(ctx context.Context, wallet entities.Wallet, txHash common.Hash, fromAddress string, amount *big.Int, blockNumber int64) (bool, error)
+}
+
+// WalletImportTracking регистрирует импортированные кошельки с их исходными путями деривации
+type WalletImportTracking interface {
+	FindWalletByChainAddress(ctx context.Context, chain entities.Chain, network, address string) (*entities.Wallet, error)
+	TrackWallet(ctx context.Context, wallet *entities.Wallet) (int, error)
+	ReserveWalletIndex(ctx context.Context, chain entities.Chain, network string, userID int64, index uint32) error
+}
+
+type WalletImportWallets interface {
+	DeriveHDWallet(account int64, index uint32) (*entities.Wallet, error)
+	RememberWallet(address string)
+	GetWalletBalance(ctx context.Context, address string) (*entities.WalletBalance, error)
+	Asset() entities.Asset
+}
+
+var (
+	_ WalletImportsRepository = (*repository.WalletImportsRepository)(nil)
+	_ WalletImportTracking    = (*repository.WalletsRepository)(nil)
+	_ WalletImportWallets     = (*WalletService)(nil)
+)
+
+// WalletImportService moves deposit wallets of a previous system into tracking. Wallets are given as a range
+// of derivation indexes of a user or as a CSV of address and derivation path; every address is derived from
+// the master seed before it is tracked, so only wallets the platform can sign for are imported. Balances and
+// the deposit history since the given block are backfilled in the background; historical deposits are stored
+// as already credited and never pay orders.
+type WalletImportService struct {
+	logger   *slog.Logger
+	repo     WalletImportsRepository
+	tracking WalletImportTracking
+	wallets  WalletImportWallets
+	audit    *AuditService
+	tracker  WorkerTracker
+
+	interval time.Duration
+}
+
+func NewWalletImportService(
+	logger *slog.Logger,
+	repo WalletImportsRepository,
+	tracking WalletImportTracking,
+	wallets WalletImportWallets,
+	audit *AuditService,
+	tracker WorkerTracker,
+	interval time.Duration,
+) (*WalletImportService, error) {
+	if interval <= 0 {
+		return nil, errors.New("wallet import interval must be positive")
+	}
+
+	return &WalletImportService{
+		logger:   logger,
+		repo:     repo,
+		tracking: tracking,
+		wallets:  wallets,
+		audit:    audit,
+		tracker:  tracker,
+		interval: interval,
+	}, nil
+}
+
+// ImportRange imports wallets of the user with derivation indexes from fromIndex to toIndex inclusive
+func (s *WalletImportService) ImportRange(ctx context.Context, userID int64, fromIndex, toIndex uint32, fromBlock int64, requestedBy string) (*entities.WalletImport, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: user ID must be positive", ErrInvalidWalletImport)
+	}
+	if toIndex < fromIndex {
+		return nil, fmt.Errorf("%w: index range is empty", ErrInvalidWalletImport)
+	}
+	if toIndex-fromIndex >= walletImportMaxWallets {
+		return nil, fmt.Errorf("%w: at most %d wallets per import", ErrInvalidWalletImport, walletImportMaxWallets)
+	}
+
+	wallets := make([]*entities.Wallet, 0, toIndex-fromIndex+1)
+	for index := uint64(fromIndex); index <= uint64(toIndex); index++ {
+		wallet, err := s.derive(userID, int64(index))
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return s.register(ctx, entities.WalletImportSourceRange, wallets, fromBlock, requestedBy)
+}
+
+// ImportCSV imports wallets listed as address,derivation_path rows, a header row is optional.
+// Every address must match the address derived from its path.
+func (s *WalletImportService) ImportCSV(ctx context.Context, data io.Reader, fromBlock int64, requestedBy string) (*entities.WalletImport, error) {
+	reader := csv.NewReader(data)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var wallets []*entities.Wallet
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWalletImport, err)
+		}
+
+		address, path := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if line == 1 && strings.EqualFold(address, "address") {
+			continue
+		}
+		if len(wallets) == walletImportMaxWallets {
+			return nil, fmt.Errorf("%w: at most %d wallets per import", ErrInvalidWalletImport, walletImportMaxWallets)
+		}
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("%w: line %d: invalid address %q", ErrInvalidWalletImport, line, address)
+		}
+
+		userID, index, err := ParseDerivationPath(path)
+		if err != nil || FormatDerivationPath(userID, index) != path {
+			return nil, fmt.Errorf("%w: line %d: unsupported derivation path %q", ErrInvalidWalletImport, line, path)
+		}
+
+		wallet, err := s.derive(userID, index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if common.HexToAddress(address).Hex() != wallet.Address {
+			return nil, fmt.Errorf("%w: line %d: address %s does not match path %s", ErrInvalidWalletImport, line, address, path)
+		}
+		wallets = append(wallets, wallet)
+	}
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("%w: no wallets to import", ErrInvalidWalletImport)
+	}
+
+	return s.register(ctx, entities.WalletImportSourceCSV, wallets, fromBlock, requestedBy)
+}
+
+// GetImport returns the import with its backfill progress
+func (s *WalletImportService) GetImport(ctx context.Context, id int64) (*entities.WalletImport, error) {
+	walletImport, err := s.repo.FindImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if walletImport == nil {
+		return nil, ErrWalletImportNotFound
+	}
+	return walletImport, nil
+}
+
+// GetImports returns the latest imports
+func (s *WalletImportService) GetImports(ctx context.Context) ([]entities.WalletImport, error) {
+	return s.repo.FindImports(ctx, walletImportsListLimit)
+}
+
+// derive checks the key index of the path is addressable and derives the wallet
+func (s *WalletImportService) derive(userID, index int64) (*entities.Wallet, error) {
+	// Номер дочернего ключа — userID*1000+index, он должен поместиться в uint32
+	if userID < 0 || index < 0 || uint64(userID)*1000+uint64(index) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: derivation index %d of user %d is out of range", ErrInvalidWalletImport, index, userID)
+	}
+
+	wallet, err := s.wallets.DeriveHDWallet(userID, uint32(index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive wallet %d of user %d: %w", index, userID, err)
+	}
+	return wallet, nil
+}
+
+// register tracks the derived wallets and queues the backfill. Wallets already tracked with the same path
+// are included in the backfill, so a repeated import is safe.
+func (s *WalletImportService) register(ctx context.Context, source entities.WalletImportSource, wallets []*entities.Wallet, fromBlock int64, requestedBy string) (*entities.WalletImport, error) {
+	if fromBlock <= 0 {
+		return nil, fmt.Errorf("%w: from_block must be positive", ErrInvalidWalletImport)
+	}
+
+	walletIDs := make([]int, 0, len(wallets))
+	imported := 0
+	for _, wallet := range wallets {
+		existing, err := s.tracking.FindWalletByChainAddress(ctx, wallet.Chain, wallet.Network, wallet.Address)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			if existing.DerivationPath != wallet.DerivationPath {
+				return nil, fmt.Errorf("%w: wallet %s is already tracked with path %s", ErrInvalidWalletImport, wallet.Address, existing.DerivationPath)
+			}
+			walletIDs = append(walletIDs, existing.ID)
+			continue
+		}
+
+		walletID, err := s.tracking.TrackWallet(ctx, wallet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to track imported wallet %s: %w", wallet.Address, err)
+		}
+		// Следующие кошельки пользователя выдаются после импортированных индексов
+		if err = s.tracking.ReserveWalletIndex(ctx, wallet.Chain, wallet.Network, wallet.UserID, wallet.WalletIndex); err != nil {
+			return nil, err
+		}
+		s.wallets.RememberWallet(wallet.Address)
+
+		walletIDs = append(walletIDs, walletID)
+		imported++
+	}
+
+	walletImport := &entities.WalletImport{
+		Source:          source,
+		FromBlock:       fromBlock,
+		WalletsTotal:    len(wallets),
+		WalletsImported: imported,
+		RequestedBy:     requestedBy,
+	}
+	if err := s.repo.CreateImport(ctx, walletImport, walletIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, entities.AuditEventWalletsImported, requestedBy, fmt.Sprintf("wallet_import:%d", walletImport.ID), map[string]any{
+		"source":           source,
+		"wallets_total":    walletImport.WalletsTotal,
+		"wallets_imported": imported,
+		"from_block":       fromBlock,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record wallet import audit", "error", err, "import_id", walletImport.ID)
+	}
+
+	s.logger.InfoContext(ctx, "Wallets imported",
+		"import_id", walletImport.ID,
+		"source", source,
+		"wallets", len(wallets),
+		"new", imported,
+		"from_block", fromBlock,
+		"requested_by", requestedBy)
+
+	return walletImport, nil
+}
+
+// Start backfills queued imports until ctx is cancelled
+func (s *WalletImportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.Backfill(ctx)
+			s.tracker.Done(count, err)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Wallet import backfill failed", "error", err)
+			}
+		}
+	}
+}
+
+// Backfill refreshes balances and loads the deposit history of the oldest queued import and returns
+// the number of its wallets. An import interrupted by an RPC error is resumed on the next pass.
+func (s *WalletImportService) Backfill(ctx context.Context) (int, error) {
+	walletImport, err := s.repo.ClaimPendingImport(ctx)
+	if err != nil || walletImport == nil {
+		return 0, err
+	}
+
+	wallets, err := s.repo.FindImportWallets(ctx, walletImport.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	if uint64(walletImport.FromBlock) > head {
+		reason := fmt.Sprintf("from_block %d is ahead of the chain head %d", walletImport.FromBlock, head)
+		_, err = s.repo.FinishImport(ctx, walletImport.ID, &reason)
+		return 0, err
+	}
+
+	for _, wallet := range wallets {
+		if _, err := s.wallets.GetWalletBalance(ctx, wallet.Address); err != nil {
+			s.logger.WarnContext(ctx, "Failed to refresh imported wallet balance", "error", err, "wallet", wallet.Address)
+		}
+	}
+
+	for start := 0; start < len(wallets); start += walletImportAddressBatch {
+		end := min(start+walletImportAddressBatch, len(wallets))
+		if err = s.backfillDeposits(ctx, client, wallets[start:end], uint64(walletImport.FromBlock), head); err != nil {
+			return 0, err
+		}
+	}
+
+	finished, err := s.repo.FinishImport(ctx, walletImport.ID, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.InfoContext(ctx, "Wallet import backfill completed",
+		"import_id", finished.ID,
+		"wallets", len(wallets),
+		"deposits", finished.DepositsFound,
+		"from_block", finished.FromBlock,
+		"to_block", head)
+
+	return len(wallets), nil
+}
+
+// backfillDeposits records token transfers to the wallets in the block range
+func (s *WalletImportService) backfillDeposits(ctx context.Context, client *ethclient.Client, wallets []entities.Wallet, from, to uint64) error {
+	byAddress := make(map[common.Address]entities.Wallet, len(wallets))
+	recipients := make([]common.Hash, 0, len(wallets))
+	for _, wallet := range wallets {
+		address := common.HexToAddress(wallet.Address)
+		byAddress[address] = wallet
+		recipients = append(recipients, common.BytesToHash(address.Bytes()))
+	}
+	token := common.HexToAddress(s.wallets.Asset().Contract)
+
+	// Тот же предел диапазона eth_getLogs, что и у монитора контрактов токенов
+	for start := from; start <= to; start += tokenMonitorMaxBlockRange {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		end := min(start+tokenMonitorMaxBlockRange-1, to)
+
+		logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{token},
+			Topics:    [][]common.Hash{{walletImportTransferTopic}, nil, recipients},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to filter transfer logs from %d to %d: %w", start, end, err)
+		}
+
+		for _, log := range logs {
+			if err = s.recordDeposit(ctx, log, byAddress); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *WalletImportService) recordDeposit(ctx context.Context, log types.Log, byAddress map[common.Address]entities.Wallet) error {
+	if log.Removed || len(log.Topics) != 3 || len(log.Data) != 32 {
+		return nil
+	}
+	wallet, ok := byAddress[common.BytesToAddress(log.Topics[2].Bytes())]
+	if !ok {
+		return nil
+	}
+
+	from := common.BytesToAddress(log.Topics[1].Bytes())
+	amount := new(big.Int).SetBytes(log.Data)
+	// Транзакция хранится один раз: второй перевод той же транзакции на наши кошельки не записывается
+	recorded, err := s.repo.RecordHistoricalDeposit(ctx, wallet, log.TxHash, from.Hex(), amount, int64(log.BlockNumber))
+	if err != nil {
+		return err
+	}
+	if recorded {
+		s.logger.DebugContext(ctx, "Historical deposit recorded",
+			"wallet", wallet.Address, "tx_hash", log.TxHash.Hex(), "amount", amount.String(), "block_number", log.BlockNumber)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package usecases
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+// GetChildKey выводит ключ с индексом account*1000+index в uint32: ключи пула должны укладываться в этот диапазон,
+// иначе индекс переполнится и совпадет с ключами пользователей с малыми ID
+const maxWalletPoolAccount = math.MaxUint32/1000 - 1
+
+var (
+	walletPoolClaims   = expvar.NewInt("wallet_pool_claims")
+	walletPoolMisses   = expvar.NewInt("wallet_pool_misses")
+	walletPoolReleases = expvar.NewInt("wallet_pool_releases")
+)
+
+type WalletPoolRepository interface {
+	NextWalletIndex(ctx context.Context, chain entities.Chain, network string, userID int64) (uint32, error)
+	TrackPoolWallet(ctx context.Context, wallet *entities.Wallet, tier string) (int, error)
+	CountPoolWallets(ctx context.Context, chain entities.Chain, network, tier string) (int, error)
+	ClaimPoolWallet(ctx context.Context, chain entities.Chain, network, tier string, userID int64) (*entities.Wallet, error)
+	ReleasePoolWallet(ctx context.Context, walletID int, account int64) (bool, error)
+}
+
+type WalletPoolWallets interface {
+	Chain() entities.Chain
+	Network() string
+	DeriveHDWallet(account int64, index uint32) (*entities.Wallet, error)
+	RememberWallet(address string)
+}
+
+// WalletPoolTiers определяет уровень пользователя, из пула которого выдается кошелек
+type WalletPoolTiers interface {
+	UserTier(ctx context.Context, userID int64) (string, error)
+}
+
+var (
+	_ WalletPoolRepository = (*repository.WalletsRepository)(nil)
+	_ WalletPoolWallets    = (*WalletService)(nil)
+	_ WalletPoolTiers      = (*WithdrawalLimitService)(nil)
+	_ DepositWalletPool    = (*WalletPoolService)(nil)
+)
+
+// WalletPoolConfig задает пул заранее выведенных кошельков. Пустой список размеров отключает пул.
+type WalletPoolConfig struct {
+	// Целевое число свободных кошельков по уровням пользователей в форме tier=size, например "basic=20"
+	Sizes []string
+	// Служебный аккаунт деривации кошельков пула, должен быть больше любого ID пользователя
+	Account  int64
+	Interval time.Duration
+}
+
+// WalletPoolService keeps a pool of derived and registered deposit wallets per user tier, so order creation
+// claims a ready wallet with one database transaction instead of deriving a key. The pool is refilled
+// in the background: periodically and right after each claim.
+type WalletPoolService struct {
+	logger  *slog.Logger
+	repo    WalletPoolRepository
+	wallets WalletPoolWallets
+	tiers   WalletPoolTiers
+
+	sizes    map[string]int
+	account  int64
+	interval time.Duration
+
+	refill chan struct{}
+}
+
+func NewWalletPoolService(logger *slog.Logger, repo WalletPoolRepository, wallets WalletPoolWallets, tiers WalletPoolTiers, config WalletPoolConfig) (*WalletPoolService, error) {
+	sizes := make(map[string]int, len(config.Sizes))
+	for _, entry := range config.Sizes {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		size, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || size < 0 {
+			return nil, fmt.Errorf("wallet pool size %q must be in the form tier=size", entry)
+		}
+		sizes[strings.ToLower(strings.TrimSpace(name))] = size
+	}
+
+	if len(sizes) > 0 {
+		if config.Account <= 0 || config.Account > maxWalletPoolAccount {
+			return nil, fmt.Errorf("wallet pool account must be between 1 and %d", maxWalletPoolAccount)
+		}
+		if config.Interval <= 0 {
+			return nil, fmt.Errorf("wallet pool refill interval must be positive")
+		}
+	}
+
+	return &WalletPoolService{
+		logger:   logger,
+		repo:     repo,
+		wallets:  wallets,
+		tiers:    tiers,
+		sizes:    sizes,
+		account:  config.Account,
+		interval: config.Interval,
+		refill:   make(chan struct{}, 1),
+	}, nil
+}
+
+// Enabled reports whether any tier has a pool
+func (s *WalletPoolService) Enabled() bool {
+	return len(s.sizes) > 0
+}
+
+// Claim assigns a pooled wallet of the user's tier to the user. Returns false if the tier has no pool,
+// the pool is empty or the claim failed: the caller derives a new wallet instead.
+func (s *WalletPoolService) Claim(ctx context.Context, userID int64) (int, string, bool) {
+	if !s.Enabled() {
+		return 0, "", false
+	}
+
+	tier, err := s.tiers.UserTier(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user tier for wallet pool", "error", err, "user_id", userID)
+		return 0, "", false
+	}
+	if _, ok := s.sizes[tier]; !ok {
+		return 0, "", false
+	}
+
+	wallet, err := s.repo.ClaimPoolWallet(ctx, s.wallets.Chain(), s.wallets.Network(), tier, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to claim pool wallet", "error", err, "user_id", userID, "tier", tier)
+		return 0, "", false
+	}
+
+	// Пул расходуется: пополняем его, не дожидаясь тикера
+	s.requestRefill()
+
+	if wallet == nil {
+		walletPoolMisses.Add(1)
+		s.logger.WarnContext(ctx, "Wallet pool is empty, deriving a new wallet", "user_id", userID, "tier", tier)
+		return 0, "", false
+	}
+
+	walletPoolClaims.Add(1)
+	s.logger.InfoContext(ctx, "Claimed pool wallet", "user_id", userID, "tier", tier, "wallet_id", wallet.ID, "address", wallet.Address)
+	return wallet.ID, wallet.Address, true
+}
+
+// Release returns the wallet of a cancelled order to the pool if it is an unused pool wallet.
+// Reports whether the wallet was released.
+func (s *WalletPoolService) Release(ctx context.Context, walletID int) (bool, error) {
+	if !s.Enabled() {
+		return false, nil
+	}
+
+	released, err := s.repo.ReleasePoolWallet(ctx, walletID, s.account)
+	if err != nil || !released {
+		return false, err
+	}
+
+	walletPoolReleases.Add(1)
+	s.logger.InfoContext(ctx, "Pool wallet released", "wallet_id", walletID)
+	return true, nil
+}
+
+// Start fills the pools on startup and refills them until the context is cancelled
+func (s *WalletPoolService) Start(ctx context.Context) {
+	if !s.Enabled() {
+		s.logger.Info("Wallet pool is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.fill(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.fill(ctx)
+		case <-s.refill:
+			s.fill(ctx)
+		}
+	}
+}
+
+func (s *WalletPoolService) requestRefill() {
+	select {
+	case s.refill <- struct{}{}:
+	default:
+		// Пополнение уже запрошено
+	}
+}
+
+// fill tops up the pool of every tier to its target size. Several instances may fill concurrently
+// and overshoot the target slightly, extra wallets are claimed later.
+func (s *WalletPoolService) fill(ctx context.Context) {
+	tiers := make([]string, 0, len(s.sizes))
+	for tier := range s.sizes {
+		tiers = append(tiers, tier)
+	}
+	sort.Strings(tiers)
+
+	for _, tier := range tiers {
+		if err := s.fillTier(ctx, tier, s.sizes[tier]); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to refill wallet pool", "error", err, "tier", tier)
+		}
+	}
+}
+
+func (s *WalletPoolService) fillTier(ctx context.Context, tier string, size int) error {
+	available, err := s.repo.CountPoolWallets(ctx, s.wallets.Chain(), s.wallets.Network(), tier)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for ; available+added < size; added++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Индекс выделяется тем же счетчиком, что и для пользователей: аккаунт пула не повторит путь деривации
+		index, err := s.repo.NextWalletIndex(ctx, s.wallets.Chain(), s.wallets.Network(), s.account)
+		if err != nil {
+			return err
+		}
+		if uint64(s.account)*1000+uint64(index) > math.MaxUint32 {
+			return fmt.Errorf("wallet pool account %d has no key indexes left", s.account)
+		}
+
+		wallet, err := s.wallets.DeriveHDWallet(s.account, index)
+		if err != nil {
+			return fmt.Errorf("failed to derive pool wallet %d: %w", index, err)
+		}
+
+		if _, err = s.repo.TrackPoolWallet(ctx, wallet, tier); err != nil {
+			return fmt.Errorf("failed to register pool wallet %d: %w", index, err)
+		}
+		s.wallets.RememberWallet(wallet.Address)
+	}
+
+	if added > 0 {
+		s.logger.InfoContext(ctx, "Wallet pool refilled", "tier", tier, "added", added, "available", available+added)
+	}
+	return nil
+}
+
+// DepositWalletPool выдает заранее подготовленный депозитный кошелек пользователю
+type DepositWalletPool interface {
+	Claim(ctx context.Context, userID int64) (int, string, bool)
+}
+
+// SetWalletPool enables claiming HD deposit wallets from the pool before deriving new ones
+func (bsc *WalletService) SetWalletPool(pool DepositWalletPool) {
+	bsc.pool = pool
+}
+
+// DeriveHDWallet derives the HD wallet of the account and index on the service chain without tracking it
+func (bsc *WalletService) DeriveHDWallet(account int64, index uint32) (*entities.Wallet, error) {
+	address, err := bsc.signer.DeriveAddress(bsc.keyring.ActiveVersion(), account, int64(index))
+	if err != nil {
+		return nil, err
+	}
+	return bsc.newWallet(address.Hex(), FormatDerivationPath(account, int64(index)), account, index), nil
+}
@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+)
+
+// WalletReuseConfig задает политику повторного использования депозитных кошельков
+type WalletReuseConfig struct {
+	Enabled bool
+	// Кошелек переиспользуется, если у пользователя не меньше MinCompletedOrders завершенных заказов
+	MinCompletedOrders int
+}
+
+// reusableWallet returns the latest idle wallet of a repeat customer: no pending order and no uncredited deposit.
+// Lookup errors fall back to issuing a new wallet.
+func (bsc *WalletService) reusableWallet(ctx context.Context, userID int64) (int, string, bool) {
+	if !bsc.reuse.Enabled {
+		return 0, "", false
+	}
+
+	// Под мьютексом, чтобы два заказа не получили один кошелек одновременно
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+
+	wallet, err := bsc.repo.FindReusableWallet(ctx, bsc.Chain(), bsc.Network(), userID, max(bsc.reuse.MinCompletedOrders, 1))
+	if err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to find reusable wallet, issuing a new one", "error", err, "user_id", userID)
+		return 0, "", false
+	}
+	if wallet == nil {
+		return 0, "", false
+	}
+
+	bsc.logger.InfoContext(ctx, "Reusing deposit wallet of repeat customer", "user_id", userID, "wallet_id", wallet.ID, "address", wallet.Address)
+	return wallet.ID, wallet.Address, true
+}
+
+// ForgetWallet removes a retired wallet from the in-memory cache of tracked wallets
+func (bsc *WalletService) ForgetWallet(address string) {
+	bsc.wallets.Remove(address)
+}
+
+// RememberWallet adds a wallet registered outside the service to the in-memory cache of tracked wallets
+func (bsc *WalletService) RememberWallet(address string) {
+	bsc.cacheWallet(address)
+}
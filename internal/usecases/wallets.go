@@ -5,7 +5,6 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
-	"log"
 	"log/slog"
 	"math/big"
 	"strings"
@@ -13,18 +12,22 @@ import (
 	"time"
 
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/shared"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/decimal"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/errreport"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/lru"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcbatch"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcmanager"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 
 	"github.com/google/uuid"
 
-	"golang.org/x/exp/maps"
-
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
@@ -32,21 +35,6 @@ import (
 	"github.com/sandquattro/go-bip39"
 )
 
-// Blockchain network configuration
-const (
-	// Token contract addresses
-	MainnetUSDTAddress = "0x55d398326f99059fF775485246999027B3197955" // USDT on BSC Mainnet
-	TestnetUSDTAddress = "0x337610d27c682E347C9cD60BD4b3b107C9d34dDd" // USDT on BSC Testnet
-)
-
-// GetUSDTContractAddress returns the appropriate USDT contract address based on mode
-func GetUSDTContractAddress() string {
-	if shared.IsBlockchainDebugMode() {
-		return TestnetUSDTAddress
-	}
-	return MainnetUSDTAddress
-}
-
 // Параметры для логирования
 const (
 	// Статусы операций
@@ -76,6 +64,7 @@ const (
 	CriticalBalanceThresholdBNB   = "0.005"         // Критический порог баланса BNB
 	LowBalanceThresholdToken      = "10.0"          // Порог низкого баланса токена
 	CriticalBalanceThresholdToken = "5.0"           // Критический порог баланса токена
+	CachedBalanceQueryLimit       = 200             // Предел кошельков в одном массовом запросе балансов
 )
 
 // Структура для хранения данных о транзакциях для отслеживания
@@ -87,9 +76,21 @@ type PendingTransaction struct {
 	Amount      *big.Int
 	GasPrice    *big.Int
 	GasLimit    uint64
-	PrivateKey  *ecdsa.PrivateKey
-	Data        []byte
-	CreatedAt   time.Time
+	// Версия сида и путь деривации ключа отправителя: сам ключ не хранится и выводится заново при ускорении
+	KeyVersion     int
+	DerivationPath string
+	Data           []byte
+	CreatedAt      time.Time
+	// Идентификатор запроса, инициировавшего отправку: сохраняется для логов фонового ускорения
+	RequestID string
+
+	// Эскалация зависших транзакций: время первой отправки, число замен и состояние
+	FirstSentAt time.Time
+	Speedups    int
+	Escalated   bool
+	Cancelling  bool
+	// Вид операции в журнале: замены учитываются в бюджете газа той же операции
+	Kind entities.LedgerEntryKind
 }
 
 type WalletsRepository interface {
@@ -97,13 +98,38 @@ type WalletsRepository interface {
 	FindWalletByID(ctx context.Context, id int) (*entities.Wallet, error)
 	IsWalletTracked(ctx context.Context, address string) (bool, error)
 	GetAllTrackedWallets(ctx context.Context) ([]entities.Wallet, error)
-	GetLastWalletIndexForUser(ctx context.Context, userID int64) (uint32, error)
-	TrackWalletWithUserAndIndex(ctx context.Context, address string, derivationPath string, userID int64, index uint32, isTestNet bool) (int, error)
+	FindTrackedWalletsPage(ctx context.Context, afterID, limit int) ([]entities.Wallet, error)
+	FindRecentTrackedWallets(ctx context.Context, limit int) ([]entities.Wallet, error)
+	NextWalletIndex(ctx context.Context, chain entities.Chain, network string, userID int64) (uint32, error)
+	TrackWallet(ctx context.Context, wallet *entities.Wallet) (int, error)
 	GetAllTrackedWalletsForUser(ctx context.Context, userID int64) ([]entities.Wallet, error)
+	FindWalletsByIDsOrAddresses(ctx context.Context, ids []int, addresses []string) ([]entities.Wallet, error)
 	DeleteWallet(ctx context.Context, id int) error
+	FindReusableWallet(ctx context.Context, chain entities.Chain, network string, userID int64, minCompletedOrders int) (*entities.Wallet, error)
+	SaveWalletBalances(ctx context.Context, balances []entities.WalletBalance) (map[string]entities.BalanceStatus, error)
+	FindWalletBalances(ctx context.Context, addresses []string) (map[string]*entities.WalletBalance, error)
+	FindWalletBalancesPage(ctx context.Context, afterAddress string, limit int) ([]entities.WalletBalance, error)
 }
 
-var _ WalletsRepository = (*repository.WalletsRepository)(nil)
+// WalletAssets возвращает актив, которым оперирует сервис: контракт, точность и флаги
+type WalletAssets interface {
+	Default() entities.Asset
+}
+
+// TransactionLedger записывает каждую отправленную транзакцию для учета газа и комиссий
+type TransactionLedger interface {
+	RecordSent(ctx context.Context, txHash, operation string, from, to common.Address, gasPrice *big.Int, gasLimit uint64)
+	RecordReplaced(ctx context.Context, oldTxHash, newTxHash string, gasPrice *big.Int)
+	RecordCancelled(ctx context.Context, oldTxHash, newTxHash string, gasPrice *big.Int)
+	ReserveGasBudget(ctx context.Context, kind entities.LedgerEntryKind, gasCost *big.Int) (string, error)
+	ReleaseGasBudget(ctx context.Context, id string)
+}
+
+var (
+	_ WalletsRepository = (*repository.WalletsRepository)(nil)
+	_ WalletAssets      = (*AssetRegistry)(nil)
+	_ TransactionLedger = (*LedgerService)(nil)
+)
 
 type WalletService struct {
 	logger *slog.Logger
@@ -111,14 +137,34 @@ type WalletService struct {
 	isTestNet bool
 
 	erc20ABI, smartContractAddress string
+	assets                         WalletAssets
 
-	seed      string
-	masterKey *bip32.Key
-	wallets   map[string]bool // In-memory cache of tracked wallets
-	walletsMu sync.RWMutex    // Mutex for wallets map
+	signer  *KeySigner
+	keyring *Keyring
+	wallets *lru.Cache[string, struct{}] // Bounded cache of tracked wallets, misses are checked in the database
 
 	repo WalletsRepository
 
+	// Черный список контракта USDT для проверки отправителя и получателя перед переводом
+	blacklist AddressBlacklist
+	// Приостановка операций с токеном после паузы, смены параметров или владельца контракта
+	halts TokenHalts
+	// Журнал исходящих транзакций для отчетов P&L
+	ledger TransactionLedger
+	audit  *AuditService
+	// Эскалация транзакций, не подтвержденных после автоматических ускорений
+	stuck StuckTxConfig
+	// Повторное использование депозитных кошельков постоянных клиентов
+	reuse WalletReuseConfig
+	// Пул заранее выведенных кошельков, nil — кошельки выводятся при создании ордера
+	pool DepositWalletPool
+	// Общий горячий кошелек для мерчантов в омнибус режиме, nil — режим отключен
+	omnibus OmnibusDepositWallets
+
+	// CREATE2 форвардеры как депозитные адреса (contracts/ForwarderFactory.sol)
+	forwarderFactory      common.Address
+	forwarderInitCodeHash common.Hash
+
 	transactions *TransactionServiceImpl
 	orderService *OrderService // Добавляем OrderService для доступа к методам работы с заказами
 
@@ -126,35 +172,71 @@ type WalletService struct {
 	pendingTxs       map[string]*PendingTransaction       // Карта ожидающих транзакций (ключ - хеш транзакции)
 	pendingTxsByAddr map[common.Address]map[uint64]string // Карта адрес -> нонс -> хеш транзакции
 	pendingTxsMu     sync.RWMutex                         // Мьютекс для защиты карты транзакций
+	replacements     TxReplacementObserver                // Получатель замен транзакций, nil — не уведомлять
 
 	// Мониторинг балансов кошельков
-	walletBalances   map[string]*entities.WalletBalance // Карта адрес -> информация о балансе
-	walletBalancesMu sync.RWMutex                       // Мьютекс для защиты карты балансов
+	walletBalances *lru.Cache[string, *entities.WalletBalance] // Адрес -> баланс, вытесненные читаются из wallet_balances
+	balanceMonitor WorkerTracker
 
 	mu sync.Mutex
 }
 
 func NewWalletService(
 	logger *slog.Logger,
-	seed string,
+	keyring *Keyring,
 	transactions *TransactionServiceImpl,
 	walletsRepo *repository.WalletsRepository,
 	orderService *OrderService, // Добавляем параметр OrderService
+	audit *AuditService,
+	blacklist AddressBlacklist,
+	halts TokenHalts,
+	assets WalletAssets,
+	ledger TransactionLedger,
+	forwarders ForwarderConfig,
+	stuck StuckTxConfig,
+	reuse WalletReuseConfig,
+	cache WalletCacheConfig,
+	balanceMonitor WorkerTracker,
 ) (*WalletService, error) {
-	// Get the appropriate USDT contract address based on mode
-	contractAddress := GetUSDTContractAddress()
+	asset := assets.Default()
+	if !common.IsHexAddress(asset.Contract) {
+		return nil, fmt.Errorf("asset %s has invalid contract address %q", asset.Code, asset.Contract)
+	}
+
+	var factory common.Address
+	var initCodeHash common.Hash
+	if forwarders.FactoryAddress != "" {
+		if !common.IsHexAddress(forwarders.FactoryAddress) {
+			return nil, fmt.Errorf("invalid forwarder factory address %q", forwarders.FactoryAddress)
+		}
+		if len(common.FromHex(forwarders.InitCodeHash)) != common.HashLength {
+			return nil, fmt.Errorf("invalid forwarder init code hash %q", forwarders.InitCodeHash)
+		}
+		factory = common.HexToAddress(forwarders.FactoryAddress)
+		initCodeHash = common.HexToHash(forwarders.InitCodeHash)
+	}
 
 	ws := &WalletService{
 		logger: logger,
 
 		erc20ABI:             `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"type":"function"}]`,
-		smartContractAddress: contractAddress,
+		smartContractAddress: asset.Contract,
+		assets:               assets,
 
-		seed:         seed,
-		masterKey:    CreateMasterKey(seed),
-		wallets:      make(map[string]bool),
+		signer:       NewKeySigner(logger, keyring, audit),
+		keyring:      keyring,
 		transactions: transactions,
 		repo:         walletsRepo,
+		blacklist:    blacklist,
+		halts:        halts,
+		ledger:       ledger,
+		audit:        audit,
+		stuck:        stuck,
+		reuse:        reuse,
+
+		forwarderFactory:      factory,
+		forwarderInitCodeHash: initCodeHash,
+
 		orderService: orderService, // Инициализируем OrderService
 
 		// Инициализация карт для отслеживания транзакций
@@ -162,11 +244,14 @@ func NewWalletService(
 		pendingTxsByAddr: make(map[common.Address]map[uint64]string),
 
 		// Мониторинг балансов кошельков
-		walletBalances: make(map[string]*entities.WalletBalance),
+		balanceMonitor: balanceMonitor,
 	}
+	ws.wallets, ws.walletBalances = newWalletCaches(cache)
 
 	// Log which mode we're operating in
-	if shared.IsBlockchainDebugMode() {
+	if _, ok := shared.Simulated(); ok {
+		logger.Warn("Wallet service initialized in SIMULATION mode (using simulated chain)")
+	} else if shared.IsBlockchainDebugMode() {
 		ws.isTestNet = true
 		logger.Warn("Wallet service initialized in DEBUG mode (using BSC Testnet)")
 	} else {
@@ -191,12 +276,8 @@ func NewWalletService(
 // IsOurWallet checks if the given address belongs to our system
 func (bsc *WalletService) IsOurWallet(ctx context.Context, address string) (bool, error) {
 	// First check in-memory cache for performance
-	bsc.walletsMu.RLock()
-	cached, exists := bsc.wallets[address]
-	bsc.walletsMu.RUnlock()
-
-	if exists {
-		return cached, nil
+	if bsc.isCachedWallet(address) {
+		return true, nil
 	}
 
 	// If not in cache, check database
@@ -207,9 +288,7 @@ func (bsc *WalletService) IsOurWallet(ctx context.Context, address string) (bool
 
 	// Update cache if found in database
 	if tracked {
-		bsc.walletsMu.Lock()
-		bsc.wallets[address] = true
-		bsc.walletsMu.Unlock()
+		bsc.cacheWallet(address)
 	}
 
 	return tracked, nil
@@ -217,74 +296,105 @@ func (bsc *WalletService) IsOurWallet(ctx context.Context, address string) (bool
 
 // GenerateWalletForUser generates a new wallet address for a specific user
 func (bsc *WalletService) GenerateWalletForUser(ctx context.Context, userID int64) (int, string, error) {
-	if bsc.masterKey == nil {
-		return 0, "", errors.New("master key not initialized")
-	}
+	return bsc.generateWallet(ctx, userID, func(index uint32) (common.Address, string, error) {
+		// Create derivation path using the user ID and index
+		// Use the user ID as part of the path to ensure uniqueness
+		derivationPath := FormatDerivationPath(userID, int64(index))
+
+		// Derive the address from the active seed only, key material is wiped right after derivation
+		walletAddress, err := bsc.signer.DeriveAddress(bsc.keyring.ActiveVersion(), userID, int64(index))
+		return walletAddress, derivationPath, err
+	})
+}
 
-	bsc.mu.Lock()
-	defer bsc.mu.Unlock()
+// Число попыток выдать кошелек, если выделенный индекс уже занят
+const maxWalletIndexAttempts = 5
 
-	// Get the last used index from the database for this user
-	lastIndex, err := bsc.repo.GetLastWalletIndexForUser(ctx, userID)
-	if err != nil {
-		return 0, "", fmt.Errorf("failed to get last wallet index for user %d: %w", userID, err)
-	}
+// generateWallet allocates the next wallet index of the user and tracks the address returned by derive.
+// Indexes are allocated by the database, so concurrent order creation on several instances cannot reuse
+// a derivation path; an index taken by a wallet tracked outside the counter is skipped and allocation retried.
+func (bsc *WalletService) generateWallet(ctx context.Context, userID int64, derive func(index uint32) (common.Address, string, error)) (int, string, error) {
+	for attempt := 1; ; attempt++ {
+		newIndex, err := bsc.repo.NextWalletIndex(ctx, bsc.Chain(), bsc.Network(), userID)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to allocate wallet index for user %d: %w", userID, err)
+		}
 
-	// Increment the index for the new wallet
-	newIndex := lastIndex + 1
+		walletAddress, derivationPath, err := derive(newIndex)
+		if err != nil {
+			return 0, "", err
+		}
 
-	// Create derivation path using the user ID and index
-	// Use the user ID as part of the path to ensure uniqueness
-	derivationPath := fmt.Sprintf("m/44'/60'/%d'/0/%d", userID, newIndex)
+		address := walletAddress.Hex()
 
-	// Get child key and private key
-	childKey, err := GetChildKey(bsc.masterKey, userID, int64(newIndex))
-	if err != nil {
-		return 0, "", err
-	}
+		// Track this wallet in database with the user ID and index
+		walletID, err := bsc.repo.TrackWallet(ctx, bsc.newWallet(address, derivationPath, userID, newIndex))
+		if errors.Is(err, repository.ErrUniqueViolation) && attempt < maxWalletIndexAttempts {
+			bsc.logger.WarnContext(ctx, "Wallet index already taken, allocating another one",
+				"user", userID, "index", newIndex, "attempt", attempt)
+			continue
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to track wallet: %w", err)
+		}
 
-	_, walletAddress, err := GetWalletPrivateKey(childKey)
-	if err != nil {
-		return 0, "", err
+		// Update in-memory cache
+		bsc.cacheWallet(address)
+
+		bsc.logger.Info("Generated new wallet", "address", address, "path", derivationPath, "user", userID, "index", newIndex)
+		return walletID, address, nil
 	}
+}
 
-	address := walletAddress.Hex()
+// Chain returns the chain of the wallets managed by this service
+func (bsc *WalletService) Chain() entities.Chain {
+	return entities.ChainBSC
+}
 
-	// Track this wallet in database with the user ID and index
-	var walletID int
-	if walletID, err = bsc.repo.TrackWalletWithUserAndIndex(ctx, address, derivationPath, userID, newIndex, bsc.isTestNet); err != nil {
-		return 0, "", fmt.Errorf("failed to track wallet: %w", err)
+// Network returns the network of the wallets managed by this service
+func (bsc *WalletService) Network() string {
+	if _, ok := shared.Simulated(); ok {
+		return entities.NetworkSimulated
 	}
+	if bsc.isTestNet {
+		return entities.NetworkTestnet
+	}
+	return entities.NetworkMainnet
+}
 
-	// Update in-memory cache
-	bsc.walletsMu.Lock()
-	bsc.wallets[address] = true
-	bsc.walletsMu.Unlock()
+// Asset returns the current registry entry of the token the service transfers
+func (bsc *WalletService) Asset() entities.Asset {
+	return bsc.assets.Default()
+}
 
-	bsc.logger.Info("Generated new wallet", "address", address, "path", derivationPath, "user", userID, "index", newIndex)
-	return walletID, address, nil
+func (bsc *WalletService) newWallet(address, derivationPath string, userID int64, index uint32) *entities.Wallet {
+	return &entities.Wallet{
+		UserID:         userID,
+		Address:        address,
+		DerivationPath: derivationPath,
+		WalletIndex:    index,
+		Chain:          bsc.Chain(),
+		Network:        bsc.Network(),
+		AddressFormat:  entities.AddressFormatEVMHex,
+		KeyVersion:     bsc.keyring.ActiveVersion(),
+	}
 }
 
 // TrackWalletForUser adds a wallet address to the tracking system for a specific user
 func (bsc *WalletService) TrackWalletForUser(ctx context.Context, address string, derivationPath string, userID int64) error {
-	// Get the last used index from the database for this user
-	lastIndex, err := bsc.repo.GetLastWalletIndexForUser(ctx, userID)
+	// Allocate the index for the new wallet
+	newIndex, err := bsc.repo.NextWalletIndex(ctx, bsc.Chain(), bsc.Network(), userID)
 	if err != nil {
-		return fmt.Errorf("failed to get last wallet index for user %d: %w", userID, err)
+		return fmt.Errorf("failed to allocate wallet index for user %d: %w", userID, err)
 	}
 
-	// Increment the index for the new wallet
-	newIndex := lastIndex + 1
-
 	// Track this wallet in database with the user ID and index
-	if _, err = bsc.repo.TrackWalletWithUserAndIndex(ctx, address, derivationPath, userID, newIndex, bsc.isTestNet); err != nil {
+	if _, err = bsc.repo.TrackWallet(ctx, bsc.newWallet(address, derivationPath, userID, newIndex)); err != nil {
 		return fmt.Errorf("failed to track wallet: %w", err)
 	}
 
 	// Update in-memory cache
-	bsc.walletsMu.Lock()
-	bsc.wallets[address] = true
-	bsc.walletsMu.Unlock()
+	bsc.cacheWallet(address)
 
 	return nil
 }
@@ -335,6 +445,8 @@ func (bsc *WalletService) GetWalletDetailsExtendedForUser(ctx context.Context, u
 			ID:        int64(wallet.ID),
 			UserID:    wallet.UserID,
 			Address:   wallet.Address,
+			Chain:     wallet.Chain,
+			Network:   wallet.Network,
 			IsTestnet: wallet.IsTestnet,
 			CreatedAt: wallet.CreatedAt,
 		})
@@ -414,11 +526,36 @@ func (bsc *WalletService) GetGasPrice(ctx context.Context, client *ethclient.Cli
 	return bsc.GetGasPriceWithPriority(ctx, client, PriorityMedium)
 }
 
+// BroadcastError is returned when the node did not confirm the broadcast of a signed transaction.
+// The transaction may still have reached the network, so the send must not be retried with a new nonce
+// until the nonce is consumed or the receipt of TxHash is checked.
+type BroadcastError struct {
+	TxHash string
+	From   common.Address
+	Nonce  uint64
+	Err    error
+}
+
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("failed to send transaction %s: %v", e.TxHash, e.Err)
+}
+
+func (e *BroadcastError) Unwrap() error {
+	return e.Err
+}
+
+// IsBroadcastError reports whether the send failed after the signed transaction could have been broadcast
+func IsBroadcastError(err error) bool {
+	var broadcastErr *BroadcastError
+	return errors.As(err, &broadcastErr)
+}
+
 // sendTransaction выполняет общие шаги для отправки транзакции и ее отслеживания
 func (bsc *WalletService) sendTransaction(
 	ctx context.Context,
 	client *ethclient.Client,
-	privateKey *ecdsa.PrivateKey,
+	keyVersion int,
+	derivationPath string,
 	fromAddress common.Address,
 	toAddress common.Address,
 	value *big.Int,
@@ -426,16 +563,16 @@ func (bsc *WalletService) sendTransaction(
 	gasPrice *big.Int,
 	data []byte,
 	priority string,
+	operation string,
 ) (string, error) {
 	txID := uuid.New().String()
 	startTime := time.Now()
-	logCtx := context.WithValue(ctx, "tx_id", txID)
+	logCtx := shared.WithTxID(ctx, txID)
 
 	// Получаем nonce для отправителя
 	nonce, err := client.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to get nonce",
-			"tx_id", txID,
 			"error", err.Error(),
 			"address", fromAddress.Hex(),
 			"status", StatusFailure,
@@ -448,7 +585,6 @@ func (bsc *WalletService) sendTransaction(
 		gasPrice, err = bsc.GetGasPriceWithPriority(ctx, client, priority)
 		if err != nil {
 			bsc.logger.ErrorContext(logCtx, "Failed to get gas price",
-				"tx_id", txID,
 				"error", err.Error(),
 				"priority", priority,
 				"status", StatusFailure,
@@ -459,7 +595,6 @@ func (bsc *WalletService) sendTransaction(
 
 	// Логируем информацию о газе
 	bsc.logger.InfoContext(logCtx, "Transaction parameters",
-		"tx_id", txID,
 		"from", fromAddress.Hex(),
 		"to", toAddress.Hex(),
 		"value", value.String(),
@@ -475,45 +610,58 @@ func (bsc *WalletService) sendTransaction(
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to get chain ID",
-			"tx_id", txID,
 			"error", err.Error(),
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	// Подписываем транзакцию
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	// Рассчитываем общую стоимость газа и резервируем ее в суточном бюджете до подписи
+	gasCost := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit)))
+	kind := ledgerKindFromContext(ctx)
+	reservation, err := bsc.ledger.ReserveGasBudget(ctx, kind, gasCost)
 	if err != nil {
+		bsc.logger.WarnContext(logCtx, "Transaction deferred",
+			"error", err.Error(),
+			"kind", kind,
+			"gas_cost", gasCost.String(),
+			"status", StatusFailure,
+			"duration", time.Since(startTime).String())
+		return "", err
+	}
+
+	// Подписываем транзакцию ключом, выведенным только на время подписи
+	signedTx, err := bsc.signer.SignTx(ctx, keyVersion, derivationPath, fromAddress, tx, chainID, operation)
+	if err != nil {
+		bsc.ledger.ReleaseGasBudget(ctx, reservation)
 		bsc.logger.ErrorContext(logCtx, "Failed to sign transaction",
-			"tx_id", txID,
 			"error", err.Error(),
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
+		return "", err
 	}
 
-	// Рассчитываем общую стоимость газа
-	gasCost := new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit)))
+	txHash := signedTx.Hash().Hex()
 
-	// Отправляем транзакцию
+	// Отправляем транзакцию. Ошибка здесь не означает, что транзакция не попала в сеть:
+	// узел мог принять ее до таймаута или обрыва соединения, поэтому резерв газа при ошибке остается
 	err = client.SendTransaction(ctx, signedTx)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to send transaction",
-			"tx_id", txID,
 			"error", err.Error(),
+			"tx_hash", txHash,
+			"nonce", nonce,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return "", &BroadcastError{TxHash: txHash, From: fromAddress, Nonce: nonce, Err: err}
 	}
 
-	txHash := signedTx.Hash().Hex()
-
 	// Добавляем транзакцию для отслеживания и возможного ускорения
-	bsc.trackTransaction(txHash, fromAddress, toAddress, nonce, value, gasPrice, gasLimit, privateKey, data)
+	bsc.trackTransaction(txHash, fromAddress, toAddress, nonce, value, gasPrice, gasLimit, keyVersion, derivationPath, data, shared.RequestID(ctx), kind)
+	bsc.ledger.RecordSent(ctx, txHash, operation, fromAddress, toAddress, gasPrice, gasLimit)
+	bsc.ledger.ReleaseGasBudget(ctx, reservation)
 
 	bsc.logger.InfoContext(logCtx, "Transaction sent successfully",
-		"tx_id", txID,
 		"tx_hash", txHash,
 		"from", fromAddress.Hex(),
 		"to", toAddress.Hex(),
@@ -528,6 +676,30 @@ func (bsc *WalletService) sendTransaction(
 	return txHash, nil
 }
 
+// EstimateTransferGas estimates the gas limit of a USDT transfer from the wallet, with the same 20% buffer as TransferFunds
+func (bsc *WalletService) EstimateTransferGas(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (uint64, error) {
+	wallet, err := bsc.repo.FindWalletByID(ctx, fromWalletID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find wallet with ID %d: %w", fromWalletID, err)
+	}
+	if wallet == nil {
+		return 0, fmt.Errorf("wallet with ID %d not found", fromWalletID)
+	}
+
+	tokenAddress := common.HexToAddress(bsc.smartContractAddress)
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  common.HexToAddress(wallet.Address),
+		To:    &tokenAddress,
+		Value: big.NewInt(0),
+		Data:  CreateERC20TransferData(toAddress, amount),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	return gasLimit * 12 / 10, nil
+}
+
 // TransferFunds transfers USDT from a deposit wallet to a destination wallet
 func (bsc *WalletService) TransferFunds(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (string, error) {
 	return bsc.TransferFundsWithPriority(ctx, client, fromWalletID, toAddress, amount, PriorityMedium)
@@ -535,18 +707,13 @@ func (bsc *WalletService) TransferFunds(ctx context.Context, client *ethclient.C
 
 // TransferFundsWithPriority transfers USDT with specified priority level
 func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int, priority string) (string, error) {
-	if bsc.masterKey == nil {
-		return "", errors.New("master key not initialized")
-	}
-
 	// Создаем уникальный ID транзакции для отслеживания в логах
 	txID := uuid.New().String()
 	startTime := time.Now()
 
 	// Добавляем информацию о транзакции в контекст логирования
-	logCtx := context.WithValue(ctx, "tx_id", txID)
+	logCtx := shared.WithTxID(ctx, txID)
 	bsc.logger.InfoContext(logCtx, "Starting token transfer",
-		"tx_id", txID,
 		"from_wallet_id", fromWalletID,
 		"to_address", toAddress,
 		"amount", amount.String(),
@@ -557,7 +724,6 @@ func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client
 	wallet, err := bsc.repo.FindWalletByID(ctx, fromWalletID)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to find wallet",
-			"tx_id", txID,
 			"error", err.Error(),
 			"wallet_id", fromWalletID,
 			"status", StatusFailure,
@@ -567,62 +733,63 @@ func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client
 
 	if wallet == nil {
 		bsc.logger.ErrorContext(logCtx, "Wallet not found",
-			"tx_id", txID,
 			"wallet_id", fromWalletID,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", fmt.Errorf("wallet with ID %d not found", fromWalletID)
 	}
 
-	// Parse derivation path to get the child key index
+	// The key is derived by the signer from this path only at signing time
 	derivationPath := wallet.DerivationPath
 	bsc.logger.InfoContext(logCtx, "Using derivation path",
-		"tx_id", txID,
 		"path", derivationPath,
 		"wallet", wallet.Address)
 
-	// Extract user ID and index from derivation path
-	userID, index, err := ParseDerivationPath(derivationPath)
-	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to parse derivation path",
-			"tx_id", txID,
-			"error", err.Error(),
-			"path", derivationPath,
+	fromAddress := common.HexToAddress(wallet.Address)
+
+	if IsForwarderPath(wallet.DerivationPath) {
+		bsc.logger.ErrorContext(logCtx, "Transfer rejected: forwarder wallet has no key",
+			"wallet", wallet.Address,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
-		return "", err
+		return "", fmt.Errorf("%w: %s", ErrForwarderWallet, wallet.Address)
 	}
 
-	// Get child key and private key
-	childKey, err := GetChildKey(bsc.masterKey, userID, index)
-	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to get child key",
-			"tx_id", txID,
+	if err = bsc.CheckTokenHalt(); err != nil {
+		bsc.logger.ErrorContext(logCtx, "Transfer rejected: token operations halted",
 			"error", err.Error(),
-			"user_id", userID,
-			"index", index,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", err
 	}
 
-	privateKey, fromAddress, err := GetWalletPrivateKey(childKey)
-	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to get wallet private key",
-			"tx_id", txID,
+	if err = bsc.ScreenAddresses(ctx, wallet.Address, toAddress); err != nil {
+		bsc.logger.ErrorContext(logCtx, "Transfer screening failed",
 			"error", err.Error(),
+			"from", wallet.Address,
+			"to", toAddress,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", err
 	}
 
 	// Create token transfer data
-	// USDT contract address on BSC
-	tokenAddress := common.HexToAddress(GetUSDTContractAddress())
+	tokenAddress := common.HexToAddress(bsc.smartContractAddress)
 
 	// Create ERC20 transfer data
 	data := CreateERC20TransferData(toAddress, amount)
 
+	// Симулируем перевод, чтобы не тратить газ на транзакцию, которая откатится
+	if err = bsc.simulateTransaction(ctx, client, fromAddress, tokenAddress, big.NewInt(0), data); err != nil {
+		bsc.logger.ErrorContext(logCtx, "Transfer simulation failed",
+			"error", err.Error(),
+			"from", fromAddress.Hex(),
+			"to", toAddress,
+			"status", StatusFailure,
+			"duration", time.Since(startTime).String())
+		return "", err
+	}
+
 	// Estimate gas limit
 	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
 		From:  fromAddress,
@@ -632,7 +799,6 @@ func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client
 	})
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to estimate gas",
-			"tx_id", txID,
 			"error", err.Error(),
 			"from", fromAddress.Hex(),
 			"to", toAddress,
@@ -645,7 +811,6 @@ func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client
 	gasLimit = gasLimit * 12 / 10
 
 	bsc.logger.InfoContext(logCtx, "Estimated gas limit",
-		"tx_id", txID,
 		"gas_limit", gasLimit,
 		"gas_limit_with_buffer", gasLimit)
 
@@ -653,7 +818,6 @@ func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client
 	gasPrice, err := bsc.GetGasPriceWithPriority(ctx, client, priority)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to get gas price with priority",
-			"tx_id", txID,
 			"error", err.Error(),
 			"priority", priority,
 			"status", StatusFailure,
@@ -662,17 +826,16 @@ func (bsc *WalletService) TransferFundsWithPriority(ctx context.Context, client
 	}
 
 	// Send the transaction
-	txHash, err := bsc.sendTransaction(ctx, client, privateKey, fromAddress, tokenAddress, big.NewInt(0), gasLimit, gasPrice, data, priority)
+	txHash, err := bsc.sendTransaction(ctx, client, wallet.KeyVersion, derivationPath, fromAddress, tokenAddress, big.NewInt(0), gasLimit, gasPrice, data, priority, SignOperationTokenTransfer)
 	if err != nil {
 		return "", err
 	}
 
 	// Дополняем лог информацией о сумме токенов
 	bsc.logger.InfoContext(logCtx, "Token transfer complete",
-		"tx_id", txID,
 		"tx_hash", txHash,
 		"token_amount", amount.String(),
-		"token_address", GetUSDTContractAddress(),
+		"token_address", bsc.smartContractAddress,
 		"status", StatusSuccess,
 		"duration", time.Since(startTime).String())
 
@@ -689,9 +852,8 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 	startTime := time.Now()
 
 	// Добавляем информацию о транзакции в контекст логирования
-	logCtx := context.WithValue(ctx, "tx_id", txID)
+	logCtx := shared.WithTxID(ctx, txID)
 	bsc.logger.InfoContext(logCtx, "Starting BNB transfer",
-		"tx_id", txID,
 		"from_address", depositUserWalletAddress,
 		"to_address", toAddress,
 		"user_id", userID,
@@ -700,38 +862,34 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 		"status", StatusPending,
 		"operation", "transfer_all_bnb")
 
-	masterKey := CreateMasterKey(bsc.seed)
-
-	// Получаем child key
-	childKey, err := GetChildKey(masterKey, int64(userID), int64(index))
+	// Ключ выводится из сида кошелька: после ротации сидов у кошельков разные версии ключей
+	keyVersion, err := bsc.walletKeyVersion(ctx, depositUserWalletAddress)
 	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to get child key",
-			"tx_id", txID,
+		bsc.logger.ErrorContext(logCtx, "Failed to find wallet key version",
 			"error", err.Error(),
-			"user_id", userID,
-			"index", index,
+			"address", depositUserWalletAddress,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", err
 	}
 
-	// Конвертируем в ECDSA приватный ключ
-	privateKey, fromAddress, err := GetWalletPrivateKey(childKey)
+	// Проверяем, что путь деривации соответствует ожидаемому адресу, не извлекая приватный ключ
+	derivationPath := FormatDerivationPath(int64(userID), int64(index))
+	fromAddress, err := bsc.signer.DeriveAddress(keyVersion, int64(userID), int64(index))
 	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to get wallet private key",
-			"tx_id", txID,
+		bsc.logger.ErrorContext(logCtx, "Failed to derive wallet address",
 			"error", err.Error(),
+			"user_id", userID,
+			"index", index,
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", err
 	}
 
-	// Проверяем, что адрес соответствует ожидаемому
 	expectedAddress := depositUserWalletAddress
 
 	if !strings.EqualFold(fromAddress.Hex(), expectedAddress) {
 		bsc.logger.WarnContext(logCtx, "Generated address doesn't match expected",
-			"tx_id", txID,
 			"generated", fromAddress.Hex(),
 			"expected", expectedAddress,
 			"status", StatusFailure,
@@ -740,15 +898,14 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 			expectedAddress, fromAddress.Hex())
 	}
 
-	bsc.logger.InfoContext(logCtx, "Successfully derived private key for wallet",
-		"tx_id", txID,
-		"address", fromAddress.Hex())
+	bsc.logger.InfoContext(logCtx, "Derivation path verified for wallet",
+		"address", fromAddress.Hex(),
+		"path", derivationPath)
 
 	// Подключаемся к блокчейну
 	client, err := GetBSCClient(ctx, bsc.logger)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to connect to blockchain",
-			"tx_id", txID,
 			"error", err.Error(),
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
@@ -760,7 +917,6 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 	balance, err := client.BalanceAt(ctx, fromAddress, nil)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to get balance",
-			"tx_id", txID,
 			"error", err.Error(),
 			"address", fromAddress.Hex(),
 			"status", StatusFailure,
@@ -770,14 +926,12 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 
 	// Логируем текущий баланс
 	bsc.logger.InfoContext(logCtx, "Current BNB balance",
-		"tx_id", txID,
 		"balance_wei", balance.String(),
-		"balance_bnb", WeiToEther(balance).Text('f', 18))
+		"balance_bnb", WeiToEther(balance).StringFixed(18))
 
 	// Проверяем, что есть что отправлять
 	if balance.Cmp(big.NewInt(0)) <= 0 {
 		bsc.logger.WarnContext(logCtx, "Balance is zero, nothing to transfer",
-			"tx_id", txID,
 			"address", fromAddress.Hex(),
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
@@ -788,7 +942,6 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 	gasPrice, err := bsc.GetGasPriceWithPriority(ctx, client, priority)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to get gas price with priority",
-			"tx_id", txID,
 			"error", err.Error(),
 			"priority", priority,
 			"status", StatusFailure,
@@ -804,20 +957,18 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 
 	// Логируем информацию о газе
 	bsc.logger.InfoContext(logCtx, "Gas information",
-		"tx_id", txID,
 		"gas_price", gasPrice.String(),
 		"gas_limit", gasLimit,
 		"fee_wei", fee.String(),
-		"fee_bnb", WeiToEther(fee).Text('f', 18))
+		"fee_bnb", WeiToEther(fee).StringFixed(18))
 
 	// Проверяем, что баланс больше комиссии
 	if balance.Cmp(fee) <= 0 {
 		bsc.logger.WarnContext(logCtx, "Balance is less than transaction fee",
-			"tx_id", txID,
 			"balance_wei", balance.String(),
 			"fee_wei", fee.String(),
-			"balance_bnb", WeiToEther(balance).Text('f', 18),
-			"fee_bnb", WeiToEther(fee).Text('f', 18),
+			"balance_bnb", WeiToEther(balance).StringFixed(18),
+			"fee_bnb", WeiToEther(fee).StringFixed(18),
 			"status", StatusFailure,
 			"duration", time.Since(startTime).String())
 		return "", fmt.Errorf("balance is less than transaction fee: %s < %s",
@@ -828,61 +979,73 @@ func (bsc *WalletService) TransferAllBNBWithPriority(ctx context.Context, toAddr
 	amount := new(big.Int).Sub(balance, fee)
 
 	bsc.logger.InfoContext(logCtx, "Amount to transfer after fee",
-		"tx_id", txID,
 		"amount_wei", amount.String(),
-		"amount_bnb", WeiToEther(amount).Text('f', 18))
+		"amount_bnb", WeiToEther(amount).StringFixed(18))
 
 	// Адрес получателя
 	to := common.HexToAddress(toAddress)
 
 	// Отправляем транзакцию, используя общую логику
-	txHash, err := bsc.sendTransaction(ctx, client, privateKey, fromAddress, to, amount, gasLimit, gasPrice, nil, priority)
+	txHash, err := bsc.sendTransaction(ctx, client, keyVersion, derivationPath, fromAddress, to, amount, gasLimit, gasPrice, nil, priority, SignOperationNativeTransfer)
 	if err != nil {
 		return "", err
 	}
 
 	// Дополняем лог информацией об отправке BNB
 	bsc.logger.InfoContext(logCtx, "BNB transfer complete",
-		"tx_id", txID,
 		"tx_hash", txHash,
 		"amount_wei", amount.String(),
-		"amount_bnb", WeiToEther(amount).Text('f', 18),
+		"amount_bnb", WeiToEther(amount).StringFixed(18),
 		"fee_wei", fee.String(),
-		"fee_bnb", WeiToEther(fee).Text('f', 18),
+		"fee_bnb", WeiToEther(fee).StringFixed(18),
 		"status", StatusSuccess,
 		"duration", time.Since(startTime).String())
 
 	return txHash, nil
 }
 
-// loadWalletsFromDB loads all tracked wallets from the database into memory
+// loadWalletsFromDB warms the cache of tracked wallets with the most recently created ones, which receive most deposits
 func (bsc *WalletService) loadWalletsFromDB(ctx context.Context) error {
-	wallets, err := bsc.repo.GetAllTrackedWallets(ctx)
+	wallets, err := bsc.repo.FindRecentTrackedWallets(ctx, bsc.wallets.Capacity())
 	if err != nil {
 		return fmt.Errorf("failed to get tracked wallets: %w", err)
 	}
 
-	bsc.walletsMu.Lock()
-	defer bsc.walletsMu.Unlock()
-
-	// Clear the existing map and repopulate it
-	maps.Clear(bsc.wallets)
-	for _, wallet := range wallets {
-		bsc.wallets[wallet.Address] = true
+	// Clear the cache and repopulate it, oldest first so that the newest wallets are the most recently used
+	bsc.wallets.Purge()
+	for i := len(wallets) - 1; i >= 0; i-- {
+		bsc.wallets.Add(wallets[i].Address, struct{}{})
 	}
 
 	bsc.logger.Info("Loaded wallets from database", "count", len(wallets))
 	return nil
 }
 
-func CreateMasterKey(seed string) *bip32.Key {
-	seedBytes := bip39.NewSeed(seed, "")
-	masterKey, err := bip32.NewMasterKey(seedBytes)
+// ActiveKeyVersion returns the version of the seed new deposit wallets are derived from
+func (bsc *WalletService) ActiveKeyVersion() int {
+	return bsc.keyring.ActiveVersion()
+}
+
+// DeriveAddress returns the deposit wallet address of the user and index derived from the seed of keyVersion
+func (bsc *WalletService) DeriveAddress(keyVersion int, userID, index int64) (common.Address, error) {
+	return bsc.signer.DeriveAddress(keyVersion, userID, index)
+}
+
+// ServiceKeyVersion returns the version of the seed service keys (relayer, operators, proposer) are derived from
+func (bsc *WalletService) ServiceKeyVersion() int {
+	return bsc.keyring.ServiceVersion()
+}
+
+// walletKeyVersion returns the key version of the tracked wallet, addresses outside tracking are service keys
+func (bsc *WalletService) walletKeyVersion(ctx context.Context, address string) (int, error) {
+	wallet, err := bsc.repo.FindWalletByAddress(ctx, address)
 	if err != nil {
-		log.Fatal("Failed to create master key")
+		return 0, err
 	}
-
-	return masterKey
+	if wallet == nil {
+		return bsc.keyring.ServiceVersion(), nil
+	}
+	return wallet.KeyVersion, nil
 }
 
 // GetBSCClient connects to one of the BSC RPC endpoints
@@ -893,7 +1056,10 @@ func GetBSCClient(ctx context.Context, logger *slog.Logger) (*ethclient.Client,
 	// Список RPC эндпоинтов BSC (для резервирования)
 	var bscRpcEndpoints []string
 
-	if debugMode {
+	if sim, ok := shared.Simulated(); ok {
+		bscRpcEndpoints = []string{sim.HTTPURL}
+		logger.Info("Using simulated chain endpoint (SIMULATION MODE)")
+	} else if debugMode {
 		// Testnet endpoints for debug/test mode
 		bscRpcEndpoints = []string{
 			"https://data-seed-prebsc-1-s1.binance.org:8545/",
@@ -922,7 +1088,7 @@ func GetBSCClient(ctx context.Context, logger *slog.Logger) (*ethclient.Client,
 
 	for _, endpoint := range bscRpcEndpoints {
 		logger.Info("Trying to connect to BSC endpoint", "endpoint", endpoint)
-		client, err = ethclient.DialContext(ctx, endpoint)
+		client, err = rpcmanager.Dial(ctx, endpoint)
 		if err == nil {
 			logger.Info("Successfully connected to BSC", "endpoint", endpoint)
 			return client, nil
@@ -958,26 +1124,19 @@ func GetWalletPrivateKey(childKey *bip32.Key) (*ecdsa.PrivateKey, common.Address
 	return privateKey, address, nil
 }
 
-// WeiToEther converts wei amount to ether (or any token with 18 decimals)
-func WeiToEther(wei *big.Int) *big.Float {
-	return new(big.Float).Quo(
-		new(big.Float).SetInt(wei),
-		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)),
-	)
+// WeiToEther converts wei amount to ether (or any token with 18 decimals) without losing precision
+func WeiToEther(wei *big.Int) decimal.Decimal {
+	return decimal.FromUnits(wei, 18)
 }
 
-// EtherToWei converts ether amount to wei
-func EtherToWei(ether *big.Float) *big.Int {
-	// Create wei representation (10^18 wei = 1 ether)
-	ethWei := new(big.Float).Mul(
-		ether,
-		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)),
-	)
+// EtherToWei converts ether amount to wei, digits beyond 18 decimals are dropped
+func EtherToWei(ether decimal.Decimal) *big.Int {
+	return ether.UnitsRounded(18, decimal.RoundDown)
+}
 
-	// Convert to big.Int
-	wei := new(big.Int)
-	ethWei.Int(wei)
-	return wei
+// FormatDerivationPath builds the BIP-44 derivation path for the given user and index
+func FormatDerivationPath(userID, index int64) string {
+	return fmt.Sprintf("m/44'/60'/%d'/0/%d", userID, index)
 }
 
 // ParseDerivationPath extracts userID and index from derivation path
@@ -1023,6 +1182,8 @@ func CreateERC20TransferData(toAddress string, amount *big.Int) []byte {
 
 // monitorPendingTransactions запускает периодическую проверку зависших транзакций
 func (bsc *WalletService) monitorPendingTransactions(ctx context.Context) {
+	defer errreport.Recover(map[string]string{"worker": "pending_tx_monitor", "chain": "bsc"})
+
 	ticker := time.NewTicker(SpeedupCheckInterval)
 	defer ticker.Stop()
 
@@ -1032,7 +1193,10 @@ func (bsc *WalletService) monitorPendingTransactions(ctx context.Context) {
 			bsc.logger.Info("Stopping transaction monitoring due to context cancellation")
 			return
 		case <-ticker.C:
-			bsc.checkAndSpeedupPendingTransactions(ctx)
+			// Проход не переживает следующий тик: зависший RPC вызов не блокирует мониторинг
+			passCtx, cancel := context.WithTimeout(ctx, SpeedupCheckInterval)
+			bsc.checkAndSpeedupPendingTransactions(passCtx)
+			cancel()
 		}
 	}
 }
@@ -1066,8 +1230,10 @@ func (bsc *WalletService) checkAndSpeedupPendingTransactions(ctx context.Context
 				continue
 			}
 
-			// Если транзакция все еще в ожидании, ускоряем ее
-			if isPending {
+			// Если транзакция все еще в ожидании, ускоряем ее, а после исчерпания ускорений — эскалируем
+			if isPending && pendingTx.Speedups >= bsc.stuck.MaxSpeedups {
+				bsc.escalateTransaction(ctx, client, pendingTx)
+			} else if isPending {
 				if err := bsc.speedupTransaction(ctx, client, pendingTx); err != nil {
 					bsc.logger.Error("Failed to speed up transaction", "tx_hash", txHash, "error", err)
 				}
@@ -1081,63 +1247,94 @@ func (bsc *WalletService) checkAndSpeedupPendingTransactions(ctx context.Context
 
 // speedupTransaction ускоряет зависшую транзакцию, отправляя новую с тем же нонсом и увеличенной ценой газа
 func (bsc *WalletService) speedupTransaction(ctx context.Context, client *ethclient.Client, pendingTx *PendingTransaction) error {
+	_, err := bsc.replaceTransaction(ctx, client, pendingTx, false)
+	return err
+}
+
+// replaceTransaction отправляет замену ожидающей транзакции с тем же нонсом и увеличенной ценой газа.
+// При cancel замена — нулевой перевод BNB на адрес отправителя: исходный перевод больше не будет исполнен.
+func (bsc *WalletService) replaceTransaction(ctx context.Context, client *ethclient.Client, pendingTx *PendingTransaction, cancel bool) (string, error) {
+	// Продолжаем цепочку логов запроса, который отправил исходную транзакцию
+	if pendingTx.RequestID != "" {
+		ctx = shared.WithRequestID(ctx, pendingTx.RequestID)
+	}
+
 	// Создаем логический контекст для отслеживания
 	txID := uuid.New().String()
 	startTime := time.Now()
-	logCtx := context.WithValue(ctx, "tx_id", txID)
+	logCtx := shared.WithTxID(ctx, txID)
 
-	bsc.logger.InfoContext(logCtx, "Speeding up stuck transaction",
-		"tx_id", txID,
+	action, operation := "speedup", SignOperationSpeedup
+	if cancel {
+		action, operation = "cancel", SignOperationCancel
+	}
+
+	bsc.logger.InfoContext(logCtx, "Replacing stuck transaction",
+		"action", action,
 		"original_tx_hash", pendingTx.TxHash,
 		"from", pendingTx.FromAddress.Hex(),
 		"to", pendingTx.ToAddress.Hex(),
 		"nonce", pendingTx.Nonce,
+		"speedups", pendingTx.Speedups,
 		"original_gas_price", pendingTx.GasPrice.String(),
 		"status", StatusPending)
 
-	// Увеличиваем цену газа
-	newGasPrice := new(big.Int).Mul(pendingTx.GasPrice, big.NewInt(int64(SpeedupGasMultiplier*100)/100))
+	// Увеличиваем цену газа: нода принимает замену только с ценой выше исходной
+	newGasPrice := new(big.Int).Mul(pendingTx.GasPrice, big.NewInt(int64(SpeedupGasMultiplier*100)))
+	newGasPrice.Div(newGasPrice, big.NewInt(100))
 
 	// Создаем новую транзакцию с тем же нонсом, но с увеличенной ценой газа
-	tx := types.NewTransaction(
-		pendingTx.Nonce,
-		pendingTx.ToAddress,
-		pendingTx.Amount,
-		pendingTx.GasLimit,
-		newGasPrice,
-		pendingTx.Data,
-	)
+	toAddress, amount, gasLimit, data := pendingTx.ToAddress, pendingTx.Amount, pendingTx.GasLimit, pendingTx.Data
+	if cancel {
+		toAddress, amount, gasLimit, data = pendingTx.FromAddress, big.NewInt(0), params.TxGas, nil
+	}
+	tx := types.NewTransaction(pendingTx.Nonce, toAddress, amount, gasLimit, newGasPrice, data)
 
 	// Получаем ID сети
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to get chain ID for speedup",
-			"tx_id", txID, "error", err, "status", StatusFailure)
-		return fmt.Errorf("failed to get chain ID: %w", err)
+		bsc.logger.ErrorContext(logCtx, "Failed to get chain ID for replacement",
+			"error", err, "status", StatusFailure)
+		return "", fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	// В бюджет газа идет только прирост стоимости: исходная транзакция уже учтена в журнале
+	kind := pendingTx.Kind
+	if cancel {
+		kind = entities.LedgerKindOperational
+	}
+	extraCost := new(big.Int).Mul(newGasPrice, new(big.Int).SetUint64(gasLimit))
+	extraCost.Sub(extraCost, new(big.Int).Mul(pendingTx.GasPrice, new(big.Int).SetUint64(pendingTx.GasLimit)))
+	reservation, err := bsc.ledger.ReserveGasBudget(ctx, kind, extraCost)
+	if err != nil {
+		bsc.logger.WarnContext(logCtx, "Replacement deferred",
+			"error", err, "kind", kind, "status", StatusFailure)
+		return "", err
 	}
 
-	// Подписываем транзакцию
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), pendingTx.PrivateKey)
+	// Подписываем транзакцию заново выведенным ключом
+	signedTx, err := bsc.signer.SignTx(ctx, pendingTx.KeyVersion, pendingTx.DerivationPath, pendingTx.FromAddress, tx, chainID, operation)
 	if err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to sign speedup transaction",
-			"tx_id", txID, "error", err, "status", StatusFailure)
-		return fmt.Errorf("failed to sign transaction: %w", err)
+		bsc.ledger.ReleaseGasBudget(ctx, reservation)
+		bsc.logger.ErrorContext(logCtx, "Failed to sign replacement transaction",
+			"error", err, "status", StatusFailure)
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	// Отправляем транзакцию
+	// Отправляем транзакцию. При ошибке резерв газа остается: замена могла попасть в сеть
 	if err = client.SendTransaction(ctx, signedTx); err != nil {
-		bsc.logger.ErrorContext(logCtx, "Failed to send speedup transaction",
-			"tx_id", txID, "error", err, "status", StatusFailure)
-		return fmt.Errorf("failed to send transaction: %w", err)
+		bsc.logger.ErrorContext(logCtx, "Failed to send replacement transaction",
+			"error", err, "status", StatusFailure)
+		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
 	newTxHash := signedTx.Hash().Hex()
-	bsc.logger.InfoContext(logCtx, "Successfully sent speedup transaction",
-		"tx_id", txID,
+	bsc.logger.InfoContext(logCtx, "Successfully sent replacement transaction",
+		"action", action,
 		"new_tx_hash", newTxHash,
 		"original_tx_hash", pendingTx.TxHash,
 		"from", pendingTx.FromAddress.Hex(),
-		"to", pendingTx.ToAddress.Hex(),
+		"to", toAddress.Hex(),
 		"nonce", pendingTx.Nonce,
 		"original_gas_price", pendingTx.GasPrice.String(),
 		"new_gas_price", newGasPrice.String(),
@@ -1145,31 +1342,62 @@ func (bsc *WalletService) speedupTransaction(ctx context.Context, client *ethcli
 		"duration", time.Since(startTime).String())
 
 	// Обновляем информацию о транзакции в хранилище
-	bsc.trackTransaction(newTxHash, pendingTx.FromAddress, pendingTx.ToAddress, pendingTx.Nonce,
-		pendingTx.Amount, newGasPrice, pendingTx.GasLimit, pendingTx.PrivateKey, pendingTx.Data)
+	bsc.trackTransaction(newTxHash, pendingTx.FromAddress, toAddress, pendingTx.Nonce,
+		amount, newGasPrice, gasLimit, pendingTx.KeyVersion, pendingTx.DerivationPath, data, pendingTx.RequestID, kind)
+	bsc.pendingTxsMu.Lock()
+	if replacement, ok := bsc.pendingTxs[newTxHash]; ok {
+		replacement.FirstSentAt = pendingTx.FirstSentAt
+		replacement.Speedups = pendingTx.Speedups + 1
+		replacement.Escalated = pendingTx.Escalated
+		replacement.Cancelling = pendingTx.Cancelling || cancel
+	}
+	bsc.pendingTxsMu.Unlock()
 
 	// Удаляем старую транзакцию из отслеживания (прямо передаем txHash)
 	bsc.removePendingTransaction(pendingTx.TxHash, pendingTx.FromAddress, pendingTx.Nonce)
+	if cancel {
+		bsc.ledger.RecordCancelled(ctx, pendingTx.TxHash, newTxHash, newGasPrice)
+	} else {
+		bsc.ledger.RecordReplaced(ctx, pendingTx.TxHash, newTxHash, newGasPrice)
+	}
+	bsc.ledger.ReleaseGasBudget(ctx, reservation)
+	if bsc.replacements != nil {
+		bsc.replacements.TxReplaced(ctx, pendingTx.TxHash, newTxHash, cancel)
+	}
 
-	return nil
+	return newTxHash, nil
+}
+
+// TxReplacementObserver получает замены отправленных транзакций: ускорения и отмены с тем же нонсом
+type TxReplacementObserver interface {
+	TxReplaced(ctx context.Context, oldTxHash, newTxHash string, cancelled bool)
+}
+
+// SetReplacementObserver subscribes the observer to speedups and cancellations of sent transactions
+func (bsc *WalletService) SetReplacementObserver(observer TxReplacementObserver) {
+	bsc.replacements = observer
 }
 
 // trackTransaction добавляет транзакцию в список ожидающих для возможного ускорения
 func (bsc *WalletService) trackTransaction(txHash string, fromAddr, toAddr common.Address, nonce uint64,
-	amount, gasPrice *big.Int, gasLimit uint64, privKey *ecdsa.PrivateKey, data []byte) {
+	amount, gasPrice *big.Int, gasLimit uint64, keyVersion int, derivationPath string, data []byte, requestID string, kind entities.LedgerEntryKind) {
 
 	tx := &PendingTransaction{
-		TxHash:      txHash,
-		FromAddress: fromAddr,
-		ToAddress:   toAddr,
-		Nonce:       nonce,
-		Amount:      amount,
-		GasPrice:    gasPrice,
-		GasLimit:    gasLimit,
-		PrivateKey:  privKey,
-		Data:        data,
-		CreatedAt:   time.Now(),
-	}
+		TxHash:         txHash,
+		FromAddress:    fromAddr,
+		ToAddress:      toAddr,
+		Nonce:          nonce,
+		Amount:         amount,
+		GasPrice:       gasPrice,
+		GasLimit:       gasLimit,
+		KeyVersion:     keyVersion,
+		DerivationPath: derivationPath,
+		Data:           data,
+		CreatedAt:      time.Now(),
+		RequestID:      requestID,
+		Kind:           kind,
+	}
+	tx.FirstSentAt = tx.CreatedAt
 
 	bsc.pendingTxsMu.Lock()
 	defer bsc.pendingTxsMu.Unlock()
@@ -1186,6 +1414,18 @@ func (bsc *WalletService) trackTransaction(txHash string, fromAddr, toAddr commo
 	bsc.pendingTxsByAddr[fromAddr][nonce] = txHash
 }
 
+// TrackedNonce returns the nonce of an outgoing transaction that is still pending
+func (bsc *WalletService) TrackedNonce(txHash string) (uint64, bool) {
+	bsc.pendingTxsMu.RLock()
+	defer bsc.pendingTxsMu.RUnlock()
+
+	pendingTx, ok := bsc.pendingTxs[common.HexToHash(txHash).Hex()]
+	if !ok {
+		return 0, false
+	}
+	return pendingTx.Nonce, true
+}
+
 // removePendingTransaction удаляет транзакцию из списка ожидающих
 func (bsc *WalletService) removePendingTransaction(txHash string, fromAddr common.Address, nonce uint64) {
 	bsc.pendingTxsMu.Lock()
@@ -1208,15 +1448,40 @@ func (bsc *WalletService) removePendingTransaction(txHash string, fromAddr commo
 	}
 }
 
+// DisableSigning refuses all transaction and hash signatures, e.g. while recorded blocks are replayed
+func (bsc *WalletService) DisableSigning(reason string) {
+	bsc.signer.Disable(reason)
+}
+
+// SignHash signs a digest with the key for derivationPath derived from the seed of keyVersion
+// and returns the signer address with the signature
+func (bsc *WalletService) SignHash(ctx context.Context, keyVersion int, derivationPath string, hash common.Hash, operation string) (common.Address, []byte, error) {
+	userID, index, err := ParseDerivationPath(derivationPath)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	address, err := bsc.signer.DeriveAddress(keyVersion, userID, index)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	signature, err := bsc.signer.SignHash(ctx, keyVersion, derivationPath, address, hash, operation)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	return address, signature, nil
+}
+
 // CheckBalance retrieves the USDT balance for the given wallet address
 func (bsc *WalletService) CheckBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error) {
 	// Create a logger context for tracking
 	txID := uuid.New().String()
 	startTime := time.Now()
-	logCtx := context.WithValue(ctx, "tx_id", txID)
+	logCtx := shared.WithTxID(ctx, txID)
 
 	bsc.logger.InfoContext(logCtx, "Checking wallet balance",
-		"tx_id", txID,
 		"address", walletAddress,
 		"status", StatusPending)
 
@@ -1224,7 +1489,6 @@ func (bsc *WalletService) CheckBalance(ctx context.Context, client *ethclient.Cl
 	balance, err := bsc.GetERC20TokenBalance(ctx, client, walletAddress)
 	if err != nil {
 		bsc.logger.ErrorContext(logCtx, "Failed to get token balance",
-			"tx_id", txID,
 			"error", err.Error(),
 			"address", walletAddress,
 			"status", StatusFailure,
@@ -1234,7 +1498,6 @@ func (bsc *WalletService) CheckBalance(ctx context.Context, client *ethclient.Cl
 
 	// Log success
 	bsc.logger.InfoContext(logCtx, "Successfully retrieved token balance",
-		"tx_id", txID,
 		"address", walletAddress,
 		"balance", balance.String(),
 		"status", StatusSuccess,
@@ -1273,6 +1536,8 @@ func (bsc *WalletService) GenerateSeedPhrase(entropyBits int) (string, error) {
 
 // monitorWalletBalances запускает периодическую проверку балансов кошельков
 func (bsc *WalletService) monitorWalletBalances(ctx context.Context) {
+	defer errreport.Recover(map[string]string{"worker": "wallet_balance_monitor", "chain": "bsc"})
+
 	ticker := time.NewTicker(BalanceMonitorInterval)
 	defer ticker.Stop()
 
@@ -1280,7 +1545,7 @@ func (bsc *WalletService) monitorWalletBalances(ctx context.Context) {
 		"interval", BalanceMonitorInterval.String())
 
 	// Выполняем первоначальную проверку балансов
-	if err := bsc.checkAllWalletBalances(ctx); err != nil {
+	if err := bsc.checkWalletBalancesPass(ctx); err != nil {
 		bsc.logger.Error("Failed to perform initial wallet balance check", "error", err)
 	}
 
@@ -1290,33 +1555,63 @@ func (bsc *WalletService) monitorWalletBalances(ctx context.Context) {
 			bsc.logger.Info("Wallet balance monitoring stopped")
 			return
 		case <-ticker.C:
-			if err := bsc.checkAllWalletBalances(ctx); err != nil {
+			if err := bsc.checkWalletBalancesPass(ctx); err != nil {
 				bsc.logger.Error("Failed to check wallet balances", "error", err)
 			}
 		}
 	}
 }
 
-// checkAllWalletBalances проверяет балансы всех отслеживаемых кошельков
-func (bsc *WalletService) checkAllWalletBalances(ctx context.Context) error {
+// checkWalletBalancesPass ограничивает проход проверки балансов интервалом мониторинга и сообщает его результат реестру обработчиков
+func (bsc *WalletService) checkWalletBalancesPass(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, BalanceMonitorInterval)
+	defer cancel()
+
+	monitored, err := bsc.checkAllWalletBalances(ctx)
+	bsc.balanceMonitor.Done(monitored, err)
+
+	return err
+}
+
+// checkAllWalletBalances проверяет балансы всех отслеживаемых кошельков страницами по walletBalancePageSize,
+// чтобы память прохода не росла с числом кошельков. Возвращает число проверенных кошельков.
+func (bsc *WalletService) checkAllWalletBalances(ctx context.Context) (int, error) {
 	// Создаем клиент для запросов к блокчейну
 	client, err := GetBSCClient(ctx, bsc.logger)
 	if err != nil {
-		return fmt.Errorf("failed to create BSC client: %w", err)
+		return 0, fmt.Errorf("failed to create BSC client: %w", err)
 	}
 	defer client.Close()
 
-	// Получаем все отслеживаемые кошельки
-	wallets, err := bsc.repo.GetAllTrackedWallets(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get tracked wallets: %w", err)
+	checked, afterID := 0, 0
+	for {
+		wallets, err := bsc.repo.FindTrackedWalletsPage(ctx, afterID, walletBalancePageSize)
+		if err != nil {
+			return checked, fmt.Errorf("failed to get tracked wallets: %w", err)
+		}
+		if len(wallets) == 0 {
+			return checked, nil
+		}
+
+		pageChecked, err := bsc.checkWalletBalancesPage(ctx, client, wallets)
+		checked += pageChecked
+		if err != nil {
+			return checked, err
+		}
+		if len(wallets) < walletBalancePageSize {
+			return checked, nil
+		}
+		afterID = wallets[len(wallets)-1].ID
 	}
+}
 
-	// Преобразуем пороги в big.Int для сравнения
-	lowBNBThreshold, _ := new(big.Float).SetString(LowBalanceThresholdBNB)
-	criticalBNBThreshold, _ := new(big.Float).SetString(CriticalBalanceThresholdBNB)
-	lowTokenThreshold, _ := new(big.Float).SetString(LowBalanceThresholdToken)
-	criticalTokenThreshold, _ := new(big.Float).SetString(CriticalBalanceThresholdToken)
+// checkWalletBalancesPage проверяет балансы страницы кошельков, сохраняет их и логирует изменения статусов
+func (bsc *WalletService) checkWalletBalancesPage(ctx context.Context, client *ethclient.Client, wallets []entities.Wallet) (int, error) {
+	// Пороги заданы десятичными строками, перевод в Wei точный
+	lowBNBThreshold := decimal.MustParse(LowBalanceThresholdBNB)
+	criticalBNBThreshold := decimal.MustParse(CriticalBalanceThresholdBNB)
+	lowTokenThreshold := decimal.MustParse(LowBalanceThresholdToken)
+	criticalTokenThreshold := decimal.MustParse(CriticalBalanceThresholdToken)
 
 	// Преобразуем в Wei
 	lowBNBThresholdWei := EtherToWei(lowBNBThreshold)
@@ -1324,22 +1619,58 @@ func (bsc *WalletService) checkAllWalletBalances(ctx context.Context) error {
 	lowTokenThresholdWei := EtherToWei(lowTokenThreshold)
 	criticalTokenThresholdWei := EtherToWei(criticalTokenThreshold)
 
-	// Проверяем баланс каждого кошелька
-	for _, wallet := range wallets {
-		address := wallet.Address
-		walletAddress := common.HexToAddress(address)
+	// Балансы BNB и токена запрашиваются batch запросами, а не по два вызова на кошелек
+	parsedABI, err := abi.JSON(strings.NewReader(bsc.erc20ABI))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing ABI: %w", err)
+	}
+	tokenAddr := common.HexToAddress(bsc.smartContractAddress)
 
-		// Получаем баланс BNB
-		bnbBalance, err := client.BalanceAt(ctx, walletAddress, nil)
+	addresses := make([]common.Address, len(wallets))
+	calls := make([]ethereum.CallMsg, len(wallets))
+	for i, wallet := range wallets {
+		addresses[i] = common.HexToAddress(wallet.Address)
+		data, err := parsedABI.Pack("balanceOf", addresses[i])
 		if err != nil {
+			return 0, fmt.Errorf("error packing data for balanceOf: %w", err)
+		}
+		calls[i] = ethereum.CallMsg{To: &tokenAddr, Data: data}
+	}
+
+	batcher := rpcbatch.New(client.Client(), rpcbatch.DefaultBatchSize)
+	bnbBalances, bnbErrs, err := batcher.BalancesAt(ctx, addresses)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get BNB balances: %w", err)
+	}
+	tokenResults, tokenErrs, err := batcher.CallContracts(ctx, calls)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get token balances: %w", err)
+	}
+
+	// Проверяем баланс каждого кошелька. EVM адрес может отслеживаться в нескольких сетях, баланс у него один.
+	balances := make([]entities.WalletBalance, 0, len(wallets))
+	owners := make(map[string]int64, len(wallets))
+	for i, wallet := range wallets {
+		address := wallet.Address
+		if _, ok := owners[address]; ok {
+			continue
+		}
+
+		// Баланс BNB
+		if bnbErrs[i] != nil {
 			bsc.logger.ErrorContext(ctx, "Failed to get BNB balance",
 				"address", address,
-				"error", err)
+				"error", bnbErrs[i])
 			continue
 		}
+		bnbBalance := bnbBalances[i]
 
-		// Получаем баланс токена (USDT)
-		tokenBalance, err := bsc.GetERC20TokenBalance(ctx, client, address)
+		// Баланс токена (USDT)
+		var tokenBalance *big.Int
+		err := tokenErrs[i]
+		if err == nil {
+			err = parsedABI.UnpackIntoInterface(&tokenBalance, "balanceOf", tokenResults[i])
+		}
 		if err != nil {
 			bsc.logger.ErrorContext(ctx, "Failed to get token balance",
 				"address", address,
@@ -1362,26 +1693,34 @@ func (bsc *WalletService) checkAllWalletBalances(ctx context.Context) error {
 		}
 
 		// Сохраняем информацию о балансе
-		walletBalance := &entities.WalletBalance{
+		balances = append(balances, entities.WalletBalance{
 			Address:       address,
 			TokenBalance:  tokenBalance,
 			NativeBalance: bnbBalance,
 			Status:        status,
 			LastChecked:   time.Now(),
-		}
+		})
+		owners[address] = wallet.UserID
+	}
+	if len(balances) == 0 {
+		return 0, nil
+	}
+
+	// Прежние статусы берутся из базы: кеш хранит не все кошельки
+	previous, err := bsc.saveBalances(ctx, balances)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save wallet balances: %w", err)
+	}
 
-		// Проверяем, изменился ли статус баланса
-		bsc.walletBalancesMu.Lock()
-		prevBalance, exists := bsc.walletBalances[address]
-		bsc.walletBalances[address] = walletBalance
-		bsc.walletBalancesMu.Unlock()
+	for _, balance := range balances {
+		address, status := balance.Address, balance.Status
 
 		// Логируем информацию о балансе
-		bnbFloat := WeiToEther(bnbBalance)
-		tokenFloat := WeiToEther(tokenBalance)
+		bnbAmount := WeiToEther(balance.NativeBalance)
+		tokenAmount := WeiToEther(balance.TokenBalance)
 
 		// Логируем информацию только при изменении статуса или первой проверке
-		if !exists || prevBalance.Status != status {
+		if prevStatus, exists := previous[address]; !exists || prevStatus != status {
 			logLevel := slog.LevelInfo
 			if status == entities.BalanceStatusCritical {
 				logLevel = slog.LevelWarn
@@ -1391,25 +1730,26 @@ func (bsc *WalletService) checkAllWalletBalances(ctx context.Context) error {
 
 			bsc.logger.Log(ctx, logLevel, "Wallet balance status",
 				"address", address,
-				"bnb_balance", bnbFloat.Text('f', 18),
-				"token_balance", tokenFloat.Text('f', 18),
+				"bnb_balance", bnbAmount.StringFixed(18),
+				"token_balance", tokenAmount.StringFixed(18),
 				"status", status,
-				"user_id", wallet.UserID)
+				"user_id", owners[address])
 		} else {
 			// Для отладки, логируем на уровне Debug при отсутствии изменений
 			bsc.logger.DebugContext(ctx, "Wallet balance checked",
 				"address", address,
-				"bnb_balance", bnbFloat.Text('f', 18),
-				"token_balance", tokenFloat.Text('f', 18),
+				"bnb_balance", bnbAmount.StringFixed(18),
+				"token_balance", tokenAmount.StringFixed(18),
 				"status", status)
 		}
 	}
 
-	return nil
+	return len(balances), nil
 }
 
-// GetUserWalletsBalances возвращает информацию о балансах кошельков для указанного пользователя из кеша.
-// Важно: подразумевается, что monitorWalletBalances регулярно обновляет кеш bsc.walletBalances.
+// GetUserWalletsBalances возвращает информацию о балансах кошельков для указанного пользователя из кеша,
+// вытесненные из кеша читаются из wallet_balances.
+// Важно: подразумевается, что monitorWalletBalances регулярно обновляет балансы.
 func (bsc *WalletService) GetUserWalletsBalances(ctx context.Context, userID int) (map[string]*entities.WalletBalance, error) {
 	bsc.logger.DebugContext(ctx, "Fetching wallet balances for user", "user_id", userID)
 
@@ -1425,26 +1765,19 @@ func (bsc *WalletService) GetUserWalletsBalances(ctx context.Context, userID int
 		return make(map[string]*entities.WalletBalance), nil
 	}
 
-	// 2. Создать карту для результатов
-	userBalances := make(map[string]*entities.WalletBalance)
-
-	// 3. Прочитать актуальные балансы из кеша (безопасно)
-	bsc.walletBalancesMu.RLock()
-	defer bsc.walletBalancesMu.RUnlock()
+	// 2. Прочитать актуальные балансы кошельков пользователя (копии, без гонки данных)
+	addresses := make([]string, len(userWallets))
+	for i, wallet := range userWallets {
+		addresses[i] = wallet.Address
+	}
+	userBalances, err := bsc.LookupWalletBalances(ctx, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balances for user %d: %w", userID, err)
+	}
 
-	// 4. Отфильтровать балансы, оставив только те, что принадлежат пользователю
-	for _, wallet := range userWallets {
-		address := wallet.Address
-		if balance, ok := bsc.walletBalances[address]; ok {
-			// Копируем баланс, чтобы избежать гонки данных при возврате указателя
-			userBalances[address] = &entities.WalletBalance{
-				Address:       balance.Address,
-				TokenBalance:  new(big.Int).Set(balance.TokenBalance),  // Глубокое копирование
-				NativeBalance: new(big.Int).Set(balance.NativeBalance), // Глубокое копирование
-				Status:        balance.Status,
-				LastChecked:   balance.LastChecked,
-			}
-		} else {
+	// 3. Отметить кошельки, баланс которых еще не проверялся
+	for _, address := range addresses {
+		if _, ok := userBalances[address]; !ok {
 			// Логируем, если баланс для отслеживаемого кошелька пользователя отсутствует в кеше
 			// Это может указывать на задержку в monitorWalletBalances или другую проблему
 			bsc.logger.WarnContext(ctx, "Balance not found in cache for user's tracked wallet", "address", address, "user_id", userID)
@@ -1457,29 +1790,99 @@ func (bsc *WalletService) GetUserWalletsBalances(ctx context.Context, userID int
 	return userBalances, nil
 }
 
-// GetWalletBalances возвращает информацию о балансах всех отслеживаемых кошельков
+// GetWalletBalances проверяет балансы всех отслеживаемых кошельков и возвращает их полностью:
+// кеш в памяти хранит только недавно использованные кошельки, поэтому результат читается из wallet_balances страницами
 func (bsc *WalletService) GetWalletBalances(ctx context.Context) (map[string]*entities.WalletBalance, error) {
 	// Обновляем балансы перед возвратом
-	if err := bsc.checkAllWalletBalances(ctx); err != nil {
+	if _, err := bsc.checkAllWalletBalances(ctx); err != nil {
 		return nil, fmt.Errorf("failed to update wallet balances: %w", err)
 	}
 
-	// Копируем карту балансов для возврата
-	bsc.walletBalancesMu.RLock()
-	defer bsc.walletBalancesMu.RUnlock()
+	balances := make(map[string]*entities.WalletBalance)
+	after := ""
+	for {
+		page, err := bsc.repo.FindWalletBalancesPage(ctx, after, walletBalancePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wallet balances: %w", err)
+		}
+		for i := range page {
+			balances[page[i].Address] = &page[i]
+		}
+		if len(page) < walletBalancePageSize {
+			return balances, nil
+		}
+		after = page[len(page)-1].Address
+	}
+}
+
+// QueryCachedBalances returns cached balances of the wallets with the IDs or addresses without querying the chain.
+// A positive userID limits the result to the user's wallets. At most CachedBalanceQueryLimit wallets may be requested.
+func (bsc *WalletService) QueryCachedBalances(ctx context.Context, userID int64, walletIDs []int, addresses []string) (*entities.WalletBalanceQueryResult, error) {
+	requested := len(walletIDs) + len(addresses)
+	if requested == 0 || requested > CachedBalanceQueryLimit {
+		return nil, fmt.Errorf("%w: request 1 to %d wallets", ErrInvalidBalanceQuery, CachedBalanceQueryLimit)
+	}
+	if walletIDs == nil {
+		walletIDs = []int{}
+	}
+	if addresses == nil {
+		addresses = []string{}
+	}
 
-	balances := make(map[string]*entities.WalletBalance, len(bsc.walletBalances))
-	for addr, balance := range bsc.walletBalances {
-		balances[addr] = &entities.WalletBalance{
-			Address:       balance.Address,
-			TokenBalance:  new(big.Int).Set(balance.TokenBalance),
-			NativeBalance: new(big.Int).Set(balance.NativeBalance),
-			Status:        balance.Status,
-			LastChecked:   balance.LastChecked,
+	wallets, err := bsc.repo.FindWalletsByIDsOrAddresses(ctx, walletIDs, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.WalletBalanceQueryResult{Balances: make([]entities.CachedWalletBalance, 0, len(wallets))}
+	foundIDs := make(map[int]bool, len(wallets))
+	foundAddresses := make(map[string]bool, len(wallets))
+
+	owned := make([]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		if userID <= 0 || wallet.UserID == userID {
+			owned = append(owned, wallet.Address)
 		}
 	}
+	balances, err := bsc.LookupWalletBalances(ctx, owned)
+	if err != nil {
+		return nil, err
+	}
 
-	return balances, nil
+	for _, wallet := range wallets {
+		if userID > 0 && wallet.UserID != userID {
+			continue
+		}
+		balance, ok := balances[wallet.Address]
+		if !ok {
+			continue
+		}
+
+		foundIDs[wallet.ID] = true
+		// Один EVM адрес может отслеживаться в нескольких сетях, баланс в кеше у него один
+		if foundAddresses[wallet.Address] {
+			continue
+		}
+		foundAddresses[wallet.Address] = true
+		result.Balances = append(result.Balances, entities.CachedWalletBalance{
+			WalletID:      wallet.ID,
+			WalletBalance: *balance,
+		})
+	}
+
+	for _, id := range walletIDs {
+		if !foundIDs[id] {
+			result.MissingWalletIDs = append(result.MissingWalletIDs, id)
+		}
+	}
+	for _, address := range addresses {
+		if !foundAddresses[address] {
+			result.MissingAddresses = append(result.MissingAddresses, address)
+		}
+	}
+
+	bsc.logger.DebugContext(ctx, "Returning cached wallet balances", "requested", requested, "found", len(result.Balances))
+	return result, nil
 }
 
 // GetWalletBalance возвращает информацию о балансе конкретного кошелька
@@ -1514,11 +1917,11 @@ func (bsc *WalletService) GetWalletBalance(ctx context.Context, address string)
 		return nil, fmt.Errorf("failed to get token balance: %w", err)
 	}
 
-	// Преобразуем пороги в big.Int для сравнения
-	lowBNBThreshold, _ := new(big.Float).SetString(LowBalanceThresholdBNB)
-	criticalBNBThreshold, _ := new(big.Float).SetString(CriticalBalanceThresholdBNB)
-	lowTokenThreshold, _ := new(big.Float).SetString(LowBalanceThresholdToken)
-	criticalTokenThreshold, _ := new(big.Float).SetString(CriticalBalanceThresholdToken)
+	// Пороги заданы десятичными строками, перевод в Wei точный
+	lowBNBThreshold := decimal.MustParse(LowBalanceThresholdBNB)
+	criticalBNBThreshold := decimal.MustParse(CriticalBalanceThresholdBNB)
+	lowTokenThreshold := decimal.MustParse(LowBalanceThresholdToken)
+	criticalTokenThreshold := decimal.MustParse(CriticalBalanceThresholdToken)
 
 	// Преобразуем в Wei
 	lowBNBThresholdWei := EtherToWei(lowBNBThreshold)
@@ -1547,10 +1950,11 @@ func (bsc *WalletService) GetWalletBalance(ctx context.Context, address string)
 		LastChecked:   time.Now(),
 	}
 
-	// Обновляем кэш балансов
-	bsc.walletBalancesMu.Lock()
-	bsc.walletBalances[address] = walletBalance
-	bsc.walletBalancesMu.Unlock()
+	// Обновляем кэш балансов и сохраняем баланс, чтобы он пережил вытеснение из кеша
+	bsc.cacheBalance(walletBalance)
+	if _, err = bsc.repo.SaveWalletBalances(ctx, []entities.WalletBalance{*walletBalance}); err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to save wallet balance", "error", err, "address", address)
+	}
 
 	return walletBalance, nil
 }
@@ -1563,6 +1967,18 @@ func (bsc *WalletService) GetOrderIdForWallet(ctx context.Context, walletAddress
 	return bsc.orderService.GetOrderIdForWallet(ctx, walletAddress)
 }
 
+// WalletOwner returns the ID of the user the wallet belongs to
+func (bsc *WalletService) WalletOwner(ctx context.Context, walletID int) (int64, error) {
+	wallet, err := bsc.repo.FindWalletByID(ctx, walletID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find wallet %d: %w", walletID, err)
+	}
+	if wallet == nil {
+		return 0, ErrWalletNotFound
+	}
+	return wallet.UserID, nil
+}
+
 // DeleteWallet deletes a wallet by ID if it meets deletion criteria
 func (bsc *WalletService) DeleteWallet(ctx context.Context, walletID int) error {
 	bsc.logger.Info("Attempting to delete wallet", "wallet_id", walletID)
@@ -1617,18 +2033,18 @@ func (bsc *WalletService) DeleteWallet(ctx context.Context, walletID int) error
 	}
 
 	// Remove from in-memory cache
-	bsc.walletsMu.Lock()
-	delete(bsc.wallets, wallet.Address)
-	bsc.walletsMu.Unlock()
+	bsc.wallets.Remove(wallet.Address)
 
 	// Delete from database using repository
 	err = bsc.repo.DeleteWallet(ctx, walletID)
 	if err != nil {
 		bsc.logger.Error("Failed to delete wallet from database", "wallet_id", walletID, "error", err)
 		// Restore in cache if database deletion failed
-		bsc.walletsMu.Lock()
-		bsc.wallets[wallet.Address] = true
-		bsc.walletsMu.Unlock()
+		bsc.cacheWallet(wallet.Address)
+		// Завершенные ордера и депозиты ссылаются на кошелек внешними ключами
+		if errors.Is(err, repository.ErrForeignKeyViolation) {
+			return ErrWalletInUse
+		}
 		return fmt.Errorf("failed to delete wallet from database")
 	}
 
@@ -0,0 +1,437 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+const (
+	// Сколько выводов из очереди разбирается за один проход
+	withdrawalBatchQueueLimit = 500
+	withdrawalBatchListLimit  = 100
+)
+
+type WithdrawalBatchesRepository interface {
+	MarkQueued(ctx context.Context, id string) error
+	FindQueuedWithdrawals(ctx context.Context, limit int) ([]entities.Withdrawal, error)
+	CreateBatch(ctx context.Context, batch *entities.WithdrawalBatch, withdrawalIDs []string) error
+	MarkBatchSubmitted(ctx context.Context, id, txHash string) error
+	MarkBatchStatus(ctx context.Context, id string, status entities.WithdrawalBatchStatus, errMsg *string) error
+	FindBatchesByStatus(ctx context.Context, status entities.WithdrawalBatchStatus, limit int) ([]entities.WithdrawalBatch, error)
+	FindBatchByID(ctx context.Context, id string) (*entities.WithdrawalBatch, error)
+	FindBatchWithdrawals(ctx context.Context, batchID string) ([]entities.Withdrawal, error)
+}
+
+// WithdrawalBatchWallets отправляет пакет одной multisend транзакцией и выводы по одному при откате пакета
+type WithdrawalBatchWallets interface {
+	MultiSend(ctx context.Context, client *ethclient.Client, collector common.Address, operatorPath string, from, to []common.Address, amounts []*big.Int) (string, error)
+	TransferFunds(ctx context.Context, client *ethclient.Client, fromWalletID int, toAddress string, amount *big.Int) (string, error)
+	TokenAllowance(ctx context.Context, client *ethclient.Client, owner, spender common.Address) (*big.Int, error)
+	ScreenAddresses(ctx context.Context, addresses ...string) error
+	CheckTokenHalt() error
+	TokenAddress() common.Address
+	Asset() entities.Asset
+}
+
+var (
+	_ WithdrawalBatchesRepository = (*repository.WithdrawalsRepository)(nil)
+	_ WithdrawalBatchWallets      = (*WalletService)(nil)
+	_ WithdrawalLimiter           = (*WithdrawalLimitService)(nil)
+)
+
+// WithdrawalBatchConfig describes the multisend batches. The collector is the BatchCollector contract
+// deposit wallets approve for batch sweeps, OperatorPath is its operator key.
+type WithdrawalBatchConfig struct {
+	CollectorAddress string
+	OperatorPath     string
+	Interval         time.Duration
+	MaxSize          int
+}
+
+// WithdrawalBatchService pays queued withdrawals of the token with one BatchCollector.multisend transaction
+// per batch instead of a transfer per withdrawal. Withdrawals from wallets that have not approved the collector
+// and withdrawals of a batch that could not be sent or reverted on chain are sent one by one.
+// A batch interrupted while it was being sent stays in the sending status and is resolved by an operator:
+// its transaction may be on chain, so its withdrawals are never sent again automatically.
+type WithdrawalBatchService struct {
+	logger  *slog.Logger
+	repo    WithdrawalBatchesRepository
+	lookup  WithdrawalWallets
+	wallets WithdrawalBatchWallets
+	limits  WithdrawalLimiter
+	tracker WorkerTracker
+
+	collector    common.Address
+	operatorPath string
+	interval     time.Duration
+	maxSize      int
+
+	// Проход по таймеру и ручной запуск оператором не должны разбирать очередь одновременно
+	mu sync.Mutex
+}
+
+func NewWithdrawalBatchService(
+	logger *slog.Logger,
+	repo WithdrawalBatchesRepository,
+	lookup WithdrawalWallets,
+	wallets WithdrawalBatchWallets,
+	limits WithdrawalLimiter,
+	tracker WorkerTracker,
+	config WithdrawalBatchConfig,
+) (*WithdrawalBatchService, error) {
+	if !common.IsHexAddress(config.CollectorAddress) {
+		return nil, fmt.Errorf("invalid batch collector address %q", config.CollectorAddress)
+	}
+	if _, _, err := ParseDerivationPath(config.OperatorPath); err != nil {
+		return nil, fmt.Errorf("invalid batch collector operator path: %w", err)
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("withdrawal batch interval must be positive")
+	}
+	if config.MaxSize < 2 {
+		return nil, errors.New("withdrawal batch size must be at least 2")
+	}
+
+	return &WithdrawalBatchService{
+		logger:       logger,
+		repo:         repo,
+		lookup:       lookup,
+		wallets:      wallets,
+		limits:       limits,
+		tracker:      tracker,
+		collector:    common.HexToAddress(config.CollectorAddress),
+		operatorPath: config.OperatorPath,
+		interval:     config.Interval,
+		maxSize:      config.MaxSize,
+	}, nil
+}
+
+// Enqueue leaves the reserved withdrawal for the next batch
+func (s *WithdrawalBatchService) Enqueue(ctx context.Context, withdrawal *entities.Withdrawal) error {
+	if err := s.repo.MarkQueued(ctx, withdrawal.ID); err != nil {
+		return err
+	}
+	withdrawal.Status = entities.WithdrawalQueued
+
+	s.logger.InfoContext(ctx, "Withdrawal queued for batch",
+		"withdrawal_id", withdrawal.ID,
+		"wallet_id", withdrawal.WalletID,
+		"to", withdrawal.ToAddress,
+		"amount", withdrawal.Amount)
+	return nil
+}
+
+// Start sends batches on the configured interval until ctx is cancelled
+func (s *WithdrawalBatchService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.RunBatches(ctx)
+			s.tracker.Done(count, err)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Withdrawal batch run failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunBatches settles submitted batches by their receipts and sends the queued withdrawals.
+// Returns the number of withdrawals sent, in batches or one by one.
+func (s *WithdrawalBatchService) RunBatches(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.wallets.CheckTokenHalt(); err != nil {
+		s.logger.WarnContext(ctx, "Withdrawal batches skipped", "reason", err.Error())
+		return 0, nil
+	}
+
+	client, err := GetBSCClient(ctx, s.logger)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create BSC client: %w", err)
+	}
+	defer client.Close()
+
+	sent := s.settleSubmitted(ctx, client)
+
+	queued, err := s.repo.FindQueuedWithdrawals(ctx, withdrawalBatchQueueLimit)
+	if err != nil {
+		return sent, err
+	}
+	s.tracker.SetLag(int64(len(queued)))
+
+	for start := 0; start < len(queued); start += s.maxSize {
+		end := min(start+s.maxSize, len(queued))
+		count, err := s.sendBatch(ctx, client, queued[start:end])
+		sent += count
+		if err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// GetBatches returns batches with the given status, newest first (empty status — all)
+func (s *WithdrawalBatchService) GetBatches(ctx context.Context, status entities.WithdrawalBatchStatus) ([]entities.WithdrawalBatch, error) {
+	return s.repo.FindBatchesByStatus(ctx, status, withdrawalBatchListLimit)
+}
+
+// GetBatch returns the batch with the status of every withdrawal in it
+func (s *WithdrawalBatchService) GetBatch(ctx context.Context, id string) (*entities.WithdrawalBatchDetails, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrWithdrawalBatchNotFound
+	}
+
+	batch, err := s.repo.FindBatchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, ErrWithdrawalBatchNotFound
+	}
+
+	items, err := s.repo.FindBatchWithdrawals(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.WithdrawalBatchDetails{WithdrawalBatch: *batch, Items: items}, nil
+}
+
+// sendBatch sends the withdrawals that can be paid by the collector as one batch and the rest one by one
+func (s *WithdrawalBatchService) sendBatch(ctx context.Context, client *ethclient.Client, withdrawals []entities.Withdrawal) (int, error) {
+	var (
+		batched    []entities.Withdrawal
+		individual []entities.Withdrawal
+		from, to   []common.Address
+		amounts    []*big.Int
+		total      = new(big.Int)
+		// Несколько выводов с одного кошелька расходуют одно разрешение коллектору
+		needed = make(map[common.Address]*big.Int)
+	)
+
+	for _, withdrawal := range withdrawals {
+		amount, ok := new(big.Int).SetString(withdrawal.Amount, 10)
+		if !ok {
+			s.limits.Release(ctx, &withdrawal, fmt.Errorf("invalid withdrawal amount %q", withdrawal.Amount))
+			continue
+		}
+
+		wallet, err := s.lookup.FindWalletByID(ctx, withdrawal.WalletID)
+		if err != nil {
+			return 0, err
+		}
+		if wallet == nil {
+			s.limits.Release(ctx, &withdrawal, fmt.Errorf("wallet %d not found", withdrawal.WalletID))
+			continue
+		}
+
+		// Один заблокированный адрес откатил бы весь пакет, поэтому такой вывод отклоняется заранее
+		if err = s.wallets.ScreenAddresses(ctx, wallet.Address, withdrawal.ToAddress); err != nil {
+			s.logger.WarnContext(ctx, "Queued withdrawal rejected by screening", "error", err, "withdrawal_id", withdrawal.ID)
+			s.limits.Release(ctx, &withdrawal, err)
+			continue
+		}
+
+		owner := common.HexToAddress(wallet.Address)
+		spend := new(big.Int).Add(amount, valueOrZero(needed[owner]))
+		allowance, err := s.wallets.TokenAllowance(ctx, client, owner, s.collector)
+		if err != nil || allowance.Cmp(spend) < 0 {
+			individual = append(individual, withdrawal)
+			continue
+		}
+
+		needed[owner] = spend
+		batched = append(batched, withdrawal)
+		from = append(from, owner)
+		to = append(to, common.HexToAddress(withdrawal.ToAddress))
+		amounts = append(amounts, amount)
+		total.Add(total, amount)
+	}
+
+	// Пакет из одного вывода дороже обычного перевода
+	if len(batched) < 2 {
+		return s.sendIndividually(ctx, client, append(individual, batched...)), nil
+	}
+
+	batch := &entities.WithdrawalBatch{
+		ID:          uuid.New().String(),
+		Token:       s.wallets.TokenAddress().Hex(),
+		Status:      entities.WithdrawalBatchSending,
+		Withdrawals: len(batched),
+		Amount:      total.String(),
+	}
+	ids := make([]string, len(batched))
+	for i, withdrawal := range batched {
+		ids[i] = withdrawal.ID
+	}
+	if err := s.repo.CreateBatch(ctx, batch, ids); err != nil {
+		return 0, err
+	}
+
+	fee, err := s.withdrawalFee()
+	if err != nil {
+		return 0, err
+	}
+	batchCtx := withLedgerTag(ctx, entities.LedgerKindWithdrawal, total, new(big.Int).Mul(fee, big.NewInt(int64(len(batched)))))
+
+	txHash, err := s.wallets.MultiSend(batchCtx, client, s.collector, s.operatorPath, from, to, amounts)
+	var broadcastErr *BroadcastError
+	switch {
+	case errors.As(err, &broadcastErr):
+		// Транзакция могла уйти в сеть: повторная отправка выводов заплатила бы их дважды
+		s.logger.ErrorContext(ctx, "Withdrawal batch broadcast not confirmed, left for operator review",
+			"error", err, "batch_id", batch.ID, "tx_hash", broadcastErr.TxHash, "withdrawals", len(batched))
+		message := err.Error()
+		if err := s.repo.MarkBatchStatus(ctx, batch.ID, entities.WithdrawalBatchSending, &message); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record withdrawal batch error", "error", err, "batch_id", batch.ID)
+		}
+	case err != nil:
+		// Ошибка до отправки подписанной транзакции: пакет точно не в сети
+		s.logger.WarnContext(ctx, "Withdrawal batch not sent, sending withdrawals individually",
+			"error", err, "batch_id", batch.ID, "withdrawals", len(batched))
+		s.fail(ctx, batch.ID, err)
+		individual = append(individual, batched...)
+	default:
+		if err = s.repo.MarkBatchSubmitted(ctx, batch.ID, txHash); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to mark withdrawal batch submitted", "error", err, "batch_id", batch.ID, "tx_hash", txHash)
+		}
+		s.logger.InfoContext(ctx, "Withdrawal batch sent",
+			"batch_id", batch.ID,
+			"withdrawals", len(batched),
+			"amount", batch.Amount,
+			"tx_hash", txHash)
+	}
+
+	sent := s.sendIndividually(ctx, client, individual)
+	if err == nil {
+		sent += len(batched)
+	}
+	return sent, nil
+}
+
+// settleSubmitted confirms batches whose transaction succeeded and sends the withdrawals of reverted batches
+// one by one. Batches without a receipt yet are checked on the next run.
+func (s *WithdrawalBatchService) settleSubmitted(ctx context.Context, client *ethclient.Client) int {
+	batches, err := s.repo.FindBatchesByStatus(ctx, entities.WithdrawalBatchSubmitted, withdrawalBatchListLimit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get submitted withdrawal batches", "error", err)
+		return 0
+	}
+
+	var sent int
+	for _, batch := range batches {
+		if batch.TxHash == nil {
+			continue
+		}
+
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(*batch.TxHash))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to get withdrawal batch receipt", "error", err, "batch_id", batch.ID)
+			continue
+		}
+
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			if err = s.repo.MarkBatchStatus(ctx, batch.ID, entities.WithdrawalBatchConfirmed, nil); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to confirm withdrawal batch", "error", err, "batch_id", batch.ID)
+				continue
+			}
+			s.logger.InfoContext(ctx, "Withdrawal batch confirmed",
+				"batch_id", batch.ID,
+				"tx_hash", *batch.TxHash,
+				"block", receipt.BlockNumber,
+				"gas_used", receipt.GasUsed)
+			continue
+		}
+
+		withdrawals, err := s.repo.FindBatchWithdrawals(ctx, batch.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to get reverted batch withdrawals", "error", err, "batch_id", batch.ID)
+			continue
+		}
+		s.logger.WarnContext(ctx, "Withdrawal batch reverted, sending withdrawals individually",
+			"batch_id", batch.ID, "tx_hash", *batch.TxHash, "withdrawals", len(withdrawals))
+		s.fail(ctx, batch.ID, fmt.Errorf("multisend transaction %s reverted", *batch.TxHash))
+		sent += s.sendIndividually(ctx, client, withdrawals)
+	}
+
+	return sent
+}
+
+// sendIndividually pays each withdrawal with its own transfer, a withdrawal that cannot be sent releases its limit.
+// A withdrawal whose transfer may have been broadcast is left for the operator, see WithdrawalLimitService.Release.
+func (s *WithdrawalBatchService) sendIndividually(ctx context.Context, client *ethclient.Client, withdrawals []entities.Withdrawal) int {
+	fee, err := s.withdrawalFee()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get withdrawal fee", "error", err)
+		return 0
+	}
+
+	var sent int
+	for _, withdrawal := range withdrawals {
+		amount, ok := new(big.Int).SetString(withdrawal.Amount, 10)
+		if !ok {
+			s.limits.Release(ctx, &withdrawal, fmt.Errorf("invalid withdrawal amount %q", withdrawal.Amount))
+			continue
+		}
+
+		txCtx := withLedgerTag(ctx, entities.LedgerKindWithdrawal, amount, fee)
+		txHash, err := s.wallets.TransferFunds(txCtx, client, withdrawal.WalletID, withdrawal.ToAddress, amount)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to send withdrawal", "error", err, "withdrawal_id", withdrawal.ID)
+			s.limits.Release(ctx, &withdrawal, err)
+			continue
+		}
+
+		s.limits.Complete(ctx, &withdrawal, &entities.TreasuryTransfer{TxHash: txHash})
+		sent++
+	}
+
+	return sent
+}
+
+func (s *WithdrawalBatchService) fail(ctx context.Context, id string, cause error) {
+	message := cause.Error()
+	if err := s.repo.MarkBatchStatus(ctx, id, entities.WithdrawalBatchFailed, &message); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to mark withdrawal batch failed", "error", err, "batch_id", id)
+	}
+}
+
+func (s *WithdrawalBatchService) withdrawalFee() (*big.Int, error) {
+	asset := s.wallets.Asset()
+	fee, err := tokenAmountToUnits(asset.WithdrawalFee, asset.Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid withdrawal fee of %s: %w", asset.Code, err)
+	}
+	return fee, nil
+}
+
+func valueOrZero(value *big.Int) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value
+}
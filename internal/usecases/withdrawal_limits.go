@@ -0,0 +1,353 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/usecases/repository"
+)
+
+type WithdrawalsRepository interface {
+	WithinLimitsLock(ctx context.Context, fn func(ctx context.Context) error) error
+	GetUsage(ctx context.Context, userID int64, hourSince, daySince time.Time) (*entities.WithdrawalUsage, error)
+	CreateWithdrawal(ctx context.Context, withdrawal *entities.Withdrawal) error
+	MarkSubmitted(ctx context.Context, id string, txHash, safeTxHash *string) error
+	MarkUnknown(ctx context.Context, id, txHash, errMsg string) error
+	MarkFailed(ctx context.Context, id, errMsg string) error
+	GetUserTier(ctx context.Context, userID int64) (string, error)
+	SetUserTier(ctx context.Context, userID int64, tier, updatedBy string) error
+}
+
+type WithdrawalWallets interface {
+	FindWalletByID(ctx context.Context, id int) (*entities.Wallet, error)
+}
+
+type WithdrawalAssets interface {
+	Default() entities.Asset
+}
+
+var (
+	_ WithdrawalsRepository = (*repository.WithdrawalsRepository)(nil)
+	_ WithdrawalWallets     = (*repository.WalletsRepository)(nil)
+	_ WithdrawalAssets      = (*AssetRegistry)(nil)
+	_ WithdrawalLimiter     = (*WithdrawalLimitService)(nil)
+)
+
+// WithdrawalLimitsConfig задает лимиты вывода в единицах актива. Пустой или нулевой лимит — без ограничения.
+type WithdrawalLimitsConfig struct {
+	// Лимиты уровней в форме tier=hourly/daily, например "basic=1000/5000"
+	Tiers       []string
+	DefaultTier string
+	// Общий отток с горячих кошельков за час и сутки
+	GlobalHourly string
+	GlobalDaily  string
+}
+
+type withdrawalTier struct {
+	hourly, daily *big.Int
+}
+
+// WithdrawalLimitService enforces withdrawal velocity: amount per rolling hour and day per user by tier,
+// and a global cap on the outflow from hot wallets. Usage is reserved before the transfer is sent
+// and released if it fails.
+type WithdrawalLimitService struct {
+	logger  *slog.Logger
+	repo    WithdrawalsRepository
+	wallets WithdrawalWallets
+	assets  WithdrawalAssets
+	audit   *AuditService
+
+	tiers        map[string]withdrawalTier
+	defaultTier  string
+	globalHourly *big.Int
+	globalDaily  *big.Int
+}
+
+func NewWithdrawalLimitService(
+	logger *slog.Logger,
+	repo WithdrawalsRepository,
+	wallets WithdrawalWallets,
+	assets WithdrawalAssets,
+	audit *AuditService,
+	config WithdrawalLimitsConfig,
+) (*WithdrawalLimitService, error) {
+	decimals := assets.Default().Decimals
+
+	tiers := make(map[string]withdrawalTier, len(config.Tiers))
+	for _, entry := range config.Tiers {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		hourly, daily, okLimits := strings.Cut(value, "/")
+		if !ok || !okLimits {
+			return nil, fmt.Errorf("withdrawal tier %q must be in the form tier=hourly/daily", entry)
+		}
+		var (
+			tier withdrawalTier
+			err  error
+		)
+		if tier.hourly, err = parseWithdrawalLimit(hourly, decimals); err != nil {
+			return nil, fmt.Errorf("withdrawal tier %q: %w", entry, err)
+		}
+		if tier.daily, err = parseWithdrawalLimit(daily, decimals); err != nil {
+			return nil, fmt.Errorf("withdrawal tier %q: %w", entry, err)
+		}
+		tiers[strings.ToLower(strings.TrimSpace(name))] = tier
+	}
+
+	defaultTier := strings.ToLower(strings.TrimSpace(config.DefaultTier))
+	if _, ok := tiers[defaultTier]; len(tiers) > 0 && !ok {
+		return nil, fmt.Errorf("default withdrawal tier %q is not configured", config.DefaultTier)
+	}
+
+	globalHourly, err := parseWithdrawalLimit(config.GlobalHourly, decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid global hourly withdrawal limit: %w", err)
+	}
+	globalDaily, err := parseWithdrawalLimit(config.GlobalDaily, decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid global daily withdrawal limit: %w", err)
+	}
+
+	return &WithdrawalLimitService{
+		logger:       logger,
+		repo:         repo,
+		wallets:      wallets,
+		assets:       assets,
+		audit:        audit,
+		tiers:        tiers,
+		defaultTier:  defaultTier,
+		globalHourly: globalHourly,
+		globalDaily:  globalDaily,
+	}, nil
+}
+
+// parseWithdrawalLimit converts a limit in asset units to minimal units; empty or zero means no limit
+func parseWithdrawalLimit(value string, decimals int) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return nil, nil
+	}
+	return tokenAmountToUnits(value, decimals)
+}
+
+// Reserve checks the withdrawal against the limits and records it as pending. Hot marks a transfer
+// sent directly from a platform wallet; withdrawals paid by the Safe only count towards the user limits.
+func (s *WithdrawalLimitService) Reserve(ctx context.Context, fromWalletID int, toAddress string, amount *big.Int, hot bool, initiatedBy string) (*entities.Withdrawal, error) {
+	wallet, err := s.wallets.FindWalletByID(ctx, fromWalletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("wallet %d not found", fromWalletID)
+	}
+
+	tier, err := s.UserTier(ctx, wallet.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal := &entities.Withdrawal{
+		ID:          uuid.New().String(),
+		UserID:      wallet.UserID,
+		WalletID:    fromWalletID,
+		ToAddress:   common.HexToAddress(toAddress).Hex(),
+		Amount:      amount.String(),
+		Hot:         hot,
+		Status:      entities.WithdrawalPending,
+		InitiatedBy: initiatedBy,
+	}
+
+	err = s.repo.WithinLimitsLock(ctx, func(txCtx context.Context) error {
+		now := time.Now()
+		usage, err := s.repo.GetUsage(txCtx, wallet.UserID, now.Add(-time.Hour), now.Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+
+		limits := s.tiers[tier]
+		checks := []struct {
+			name  string
+			limit *big.Int
+			used  string
+			apply bool
+		}{
+			{"hourly", limits.hourly, usage.UserHourly, true},
+			{"daily", limits.daily, usage.UserDaily, true},
+			{"global hourly", s.globalHourly, usage.GlobalHourly, hot},
+			{"global daily", s.globalDaily, usage.GlobalDaily, hot},
+		}
+		for _, check := range checks {
+			if !check.apply || check.limit == nil {
+				continue
+			}
+			used, ok := new(big.Int).SetString(check.used, 10)
+			if !ok {
+				return fmt.Errorf("invalid %s withdrawal usage %q", check.name, check.used)
+			}
+			if new(big.Int).Add(used, amount).Cmp(check.limit) > 0 {
+				decimals := s.assets.Default().Decimals
+				remaining := new(big.Int).Sub(check.limit, used)
+				if remaining.Sign() < 0 {
+					remaining.SetInt64(0)
+				}
+				s.logger.WarnContext(ctx, "Withdrawal rejected by velocity limit",
+					"user_id", wallet.UserID,
+					"tier", tier,
+					"limit", check.name,
+					"amount", amount.String(),
+					"remaining", remaining.String())
+				return fmt.Errorf("%w: %s limit %s, remaining %s", ErrWithdrawalLimitExceeded, check.name,
+					unitsToTokenAmount(check.limit, decimals), unitsToTokenAmount(remaining, decimals))
+			}
+		}
+
+		return s.repo.CreateWithdrawal(txCtx, withdrawal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// Complete stores the transaction or the Safe proposal paying the withdrawal
+func (s *WithdrawalLimitService) Complete(ctx context.Context, withdrawal *entities.Withdrawal, transfer *entities.TreasuryTransfer) {
+	var txHash, safeTxHash *string
+	if transfer.TxHash != "" {
+		txHash = &transfer.TxHash
+	}
+	if transfer.Proposal != nil {
+		safeTxHash = &transfer.Proposal.SafeTxHash
+	}
+	if err := s.repo.MarkSubmitted(ctx, withdrawal.ID, txHash, safeTxHash); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update withdrawal", "error", err, "withdrawal_id", withdrawal.ID)
+	}
+}
+
+// Release returns the reserved amount of a withdrawal that was not sent. A withdrawal whose transaction
+// may have been broadcast keeps the reservation in the unknown status until an operator reconciles it.
+func (s *WithdrawalLimitService) Release(ctx context.Context, withdrawal *entities.Withdrawal, cause error) {
+	var broadcastErr *BroadcastError
+	if errors.As(cause, &broadcastErr) {
+		s.logger.ErrorContext(ctx, "Withdrawal broadcast not confirmed, operator reconciliation required",
+			"error", cause, "withdrawal_id", withdrawal.ID, "tx_hash", broadcastErr.TxHash, "nonce", broadcastErr.Nonce)
+		if err := s.repo.MarkUnknown(ctx, withdrawal.ID, broadcastErr.TxHash, cause.Error()); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to mark withdrawal unknown", "error", err, "withdrawal_id", withdrawal.ID)
+		}
+		return
+	}
+
+	if err := s.repo.MarkFailed(ctx, withdrawal.ID, cause.Error()); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to release withdrawal limit", "error", err, "withdrawal_id", withdrawal.ID)
+	}
+}
+
+// GetLimits returns the limits of the user with the used and remaining amounts
+func (s *WithdrawalLimitService) GetLimits(ctx context.Context, userID int64) (*entities.WithdrawalLimits, error) {
+	tier, err := s.UserTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	usage, err := s.repo.GetUsage(ctx, userID, now.Add(-time.Hour), now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	asset := s.assets.Default()
+	limits := &entities.WithdrawalLimits{
+		UserID: userID,
+		Tier:   tier,
+		Asset:  asset.Code,
+	}
+
+	var available *big.Int
+	allowance := func(limit *big.Int, usedValue string) (entities.WithdrawalAllowance, error) {
+		used, ok := new(big.Int).SetString(usedValue, 10)
+		if !ok {
+			return entities.WithdrawalAllowance{}, fmt.Errorf("invalid withdrawal usage %q", usedValue)
+		}
+		result := entities.WithdrawalAllowance{Used: unitsToTokenAmount(used, asset.Decimals)}
+		if limit == nil {
+			return result, nil
+		}
+		remaining := new(big.Int).Sub(limit, used)
+		if remaining.Sign() < 0 {
+			remaining.SetInt64(0)
+		}
+		if available == nil || remaining.Cmp(available) < 0 {
+			available = remaining
+		}
+		result.Limit = unitsToTokenAmount(limit, asset.Decimals)
+		result.Remaining = unitsToTokenAmount(remaining, asset.Decimals)
+		return result, nil
+	}
+
+	tierLimits := s.tiers[tier]
+	for _, item := range []struct {
+		target *entities.WithdrawalAllowance
+		limit  *big.Int
+		used   string
+	}{
+		{&limits.Hourly, tierLimits.hourly, usage.UserHourly},
+		{&limits.Daily, tierLimits.daily, usage.UserDaily},
+		{&limits.GlobalHourly, s.globalHourly, usage.GlobalHourly},
+		{&limits.GlobalDaily, s.globalDaily, usage.GlobalDaily},
+	} {
+		if *item.target, err = allowance(item.limit, item.used); err != nil {
+			return nil, err
+		}
+	}
+
+	if available != nil {
+		limits.Available = unitsToTokenAmount(available, asset.Decimals)
+	}
+
+	return limits, nil
+}
+
+// SetUserTier assigns the limits tier to the user
+func (s *WithdrawalLimitService) SetUserTier(ctx context.Context, userID int64, tier, actor string) error {
+	tier = strings.ToLower(strings.TrimSpace(tier))
+	if _, ok := s.tiers[tier]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownWithdrawalTier, tier)
+	}
+
+	if err := s.repo.SetUserTier(ctx, userID, tier, actor); err != nil {
+		return err
+	}
+
+	if err := s.audit.Record(ctx, entities.AuditEventWithdrawalTierChanged, actor, strconv.FormatInt(userID, 10), map[string]any{
+		"tier": tier,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record withdrawal tier change", "error", err, "user_id", userID)
+	}
+
+	return nil
+}
+
+// UserTier returns the assigned tier of the user, or the default tier if the assigned one is no longer configured
+func (s *WithdrawalLimitService) UserTier(ctx context.Context, userID int64) (string, error) {
+	tier, err := s.repo.GetUserTier(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := s.tiers[tier]; !ok {
+		return s.defaultTier, nil
+	}
+	return tier, nil
+}
@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// Обработчик считается зависшим, если не отмечался дольше workerStallFactor интервалов
+const workerStallFactor = 3
+
+// WorkerTracker принимает отчеты фонового обработчика о работе
+type WorkerTracker interface {
+	Beat()
+	Done(processed int, err error)
+	SetLag(lag int64)
+}
+
+var _ WorkerTracker = (*TrackedWorker)(nil)
+
+// WorkerRegistry collects heartbeats, run results and lag of background workers for /admin/workers
+// and readiness checks
+type WorkerRegistry struct {
+	mu      sync.Mutex
+	workers map[string]*TrackedWorker
+}
+
+func NewWorkerRegistry() *WorkerRegistry {
+	return &WorkerRegistry{
+		workers: make(map[string]*TrackedWorker),
+	}
+}
+
+// Register adds a worker expected to report at least once per interval. Registering the same name again
+// returns the existing tracker.
+func (r *WorkerRegistry) Register(name string, interval time.Duration) *TrackedWorker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if worker, ok := r.workers[name]; ok {
+		return worker
+	}
+
+	worker := &TrackedWorker{
+		name:         name,
+		interval:     interval,
+		registeredAt: time.Now(),
+	}
+	r.workers[name] = worker
+	return worker
+}
+
+// Workers returns the status of every registered worker sorted by name
+func (r *WorkerRegistry) Workers() []entities.WorkerStatus {
+	r.mu.Lock()
+	workers := make([]*TrackedWorker, 0, len(r.workers))
+	for _, worker := range r.workers {
+		workers = append(workers, worker)
+	}
+	r.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]entities.WorkerStatus, 0, len(workers))
+	for _, worker := range workers {
+		statuses = append(statuses, worker.status(now))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses
+}
+
+// Stalled returns the names of workers that have not reported for longer than their stall timeout
+func (r *WorkerRegistry) Stalled() []string {
+	var stalled []string
+	for _, status := range r.Workers() {
+		if status.State == entities.WorkerStalled {
+			stalled = append(stalled, status.Name)
+		}
+	}
+	return stalled
+}
+
+// TrackedWorker is the registry entry of a single worker
+type TrackedWorker struct {
+	name         string
+	interval     time.Duration
+	registeredAt time.Time
+
+	mu             sync.Mutex
+	lastHeartbeat  time.Time
+	lastSuccessAt  time.Time
+	lastErrorAt    time.Time
+	lastError      string
+	lastProcessed  int
+	totalProcessed int64
+	lag            int64
+}
+
+// Beat marks the worker alive, e.g. while a long run is in progress
+func (w *TrackedWorker) Beat() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastHeartbeat = time.Now()
+}
+
+// Done records a finished run. processed is the number of items handled by a successful run.
+func (w *TrackedWorker) Done(processed int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	w.lastHeartbeat = now
+	if err != nil {
+		w.lastErrorAt = now
+		w.lastError = err.Error()
+		return
+	}
+
+	w.lastSuccessAt = now
+	w.lastProcessed = processed
+	w.totalProcessed += int64(processed)
+}
+
+// SetLag records how far the worker is behind: blocks for scanners, queued items for the others
+func (w *TrackedWorker) SetLag(lag int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lag = lag
+}
+
+func (w *TrackedWorker) status(now time.Time) entities.WorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := entities.WorkerStatus{
+		Name:            w.name,
+		State:           entities.WorkerRunning,
+		IntervalSeconds: int64(w.interval.Seconds()),
+		LastHeartbeat:   optionalTime(w.lastHeartbeat),
+		LastSuccessAt:   optionalTime(w.lastSuccessAt),
+		LastErrorAt:     optionalTime(w.lastErrorAt),
+		LastError:       w.lastError,
+		LastProcessed:   w.lastProcessed,
+		TotalProcessed:  w.totalProcessed,
+		Lag:             w.lag,
+	}
+
+	// До первого отчета отсчет идет от регистрации
+	last := w.lastHeartbeat
+	if last.IsZero() {
+		last = w.registeredAt
+	}
+
+	switch {
+	case now.Sub(last) > w.interval*workerStallFactor:
+		status.State = entities.WorkerStalled
+	case w.lastErrorAt.After(w.lastSuccessAt):
+		status.State = entities.WorkerFailing
+	}
+
+	return status
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
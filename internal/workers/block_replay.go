@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/blockrec"
+)
+
+// ReplayBlocks runs the recorded blocks in block order through the same processing as live blocks, answering
+// its RPC calls from the recordings instead of a node. Wallets and orders use the configured services and AML
+// reuses the stored results without external providers, so a replay against a copy of the database reproduces
+// how the blocks were credited without signing or reaching external services.
+// Confirmation checks are not scheduled, the recordings end at their block.
+func (bsc *BinanceSmartChain) ReplayBlocks(ctx context.Context, recordings []*blockrec.Recording) error {
+	bsc.replaying = true
+	defer func() { bsc.replaying = false }()
+
+	for _, recording := range recordings {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := recording.BlockHeader()
+		if err != nil {
+			return err
+		}
+
+		replayer := blockrec.NewReplayer(recording)
+		client, err := replayer.Dial(ctx)
+		if err != nil {
+			return err
+		}
+		err = bsc.processBlock(ctx, client, header)
+		client.Close()
+		if err != nil {
+			return fmt.Errorf("failed to replay block %d: %w", recording.BlockNumber, err)
+		}
+
+		// Запрос, которого нет в записи, означает, что обработка пошла иначе, чем при записи блока
+		if missed := replayer.Missed(); len(missed) > 0 {
+			bsc.logger.WarnContext(ctx, "Replayed block made calls absent from the recording",
+				"block_number", recording.BlockNumber,
+				"block_hash", recording.BlockHash,
+				"missed", missed)
+		}
+		bsc.logger.InfoContext(ctx, "Recorded block replayed",
+			"block_number", recording.BlockNumber,
+			"block_hash", recording.BlockHash,
+			"calls", len(recording.Calls))
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package workers
+
+import (
+	"expvar"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// transferEventTopic — keccak256("Transfer(address,address,uint256)"), topic[0] события ERC-20 Transfer
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Счетчики pre-check по logs bloom, публикуются на /metrics
+var (
+	bloomBlocksSkipped = expvar.NewInt("bsc_bloom_blocks_skipped")
+	bloomBlocksMatched = expvar.NewInt("bsc_bloom_blocks_matched")
+)
+
+// mayContainUSDTTransfer проверяет logs bloom заголовка блока: если в фильтре нет адреса
+// USDT контракта или топика Transfer, успешных переводов USDT в блоке точно нет
+// и тело блока можно не загружать. Bloom допускает ложные срабатывания, но не пропуски.
+func mayContainUSDTTransfer(header *types.Header) bool {
+	if !bloomMayContainUSDTTransfer(header) {
+		bloomBlocksSkipped.Add(1)
+		return false
+	}
+
+	bloomBlocksMatched.Add(1)
+	return true
+}
+
+func bloomMayContainUSDTTransfer(header *types.Header) bool {
+	// Пустой bloom у непустого блока означает, что нода не вернула фильтр — проверить нельзя
+	if header.Bloom == (types.Bloom{}) && header.TxHash != types.EmptyTxsHash {
+		return true
+	}
+
+	contract := common.HexToAddress(USDTContractAddress)
+	return types.BloomLookup(header.Bloom, contract) && types.BloomLookup(header.Bloom, transferEventTopic)
+}
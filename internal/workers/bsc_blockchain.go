@@ -3,6 +3,7 @@ package workers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
@@ -15,6 +16,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/sand/crypto-p2p-trading-app/backend/config"
 	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/blockrec"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcmanager"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -62,6 +65,9 @@ const (
 
 // GetBSCWebSocketEndpoints BSC WebSocket endpoints
 func GetBSCWebSocketEndpoints() []string {
+	if sim, ok := shared.Simulated(); ok {
+		return []string{sim.WSURL}
+	}
 	if shared.IsBlockchainDebugMode() {
 		// Testnet WebSocket endpoints for debug/test mode
 		return []string{
@@ -93,22 +99,50 @@ var bscHTTPEndpoints = []string{
 
 type TransactionService interface {
 	GetTransactionsByWallet(ctx context.Context, walletAddress string) ([]entities.Transaction, error)
-	RecordTransaction(ctx context.Context, txHash common.Hash, walletAddress string, amount *big.Int, blockNumber int64) error
-	ConfirmTransaction(ctx context.Context, txHash string) error
+	RecordTransaction(ctx context.Context, txHash common.Hash, walletAddress, fromAddress, memo string, amount *big.Int, blockNumber int64, requiredConfirmations uint64) error
+	ConfirmTransaction(ctx context.Context, txHash string, confirmations uint64) error
 	ProcessPendingTransactions(ctx context.Context) error
 	MarkTransactionAMLFlagged(ctx context.Context, txHash string) error
 	MarkTransactionAMLCleared(ctx context.Context, txHash string) error
 }
 
+// RefundService создает возвраты депозитов, отклоненных AML проверкой
+type RefundService interface {
+	RequestAMLRefund(ctx context.Context, depositTxHash string) error
+}
+
+// ConfirmationPolicy определяет число подтверждений депозита в зависимости от суммы
+type ConfirmationPolicy interface {
+	Required(ctx context.Context, chain entities.Chain, asset entities.Asset, amount *big.Int) uint64
+}
+
+// DepositHoldService удерживает депозиты, значительно превышающие обычные депозиты пользователя
+type DepositHoldService interface {
+	CheckDeposit(ctx context.Context, txHash, walletAddress string, amount *big.Int) error
+}
+
+// DepositFilter исключает пыль и серии микропереводов из сопоставления с ордерами
+type DepositFilter interface {
+	FilterDeposit(ctx context.Context, asset entities.Asset, txHash, fromAddress string, amount *big.Int) (bool, error)
+}
+
 // AMLService определяет интерфейс для AML проверок
 type AMLService interface {
 	CheckTransaction(ctx context.Context, txHash common.Hash, sourceAddress, destinationAddress string, amount *big.Int) (*entities.AMLCheckResult, error)
 }
 
+// WorkerTracker принимает отчеты обработчика для реестра фоновых обработчиков
+type WorkerTracker interface {
+	Beat()
+	Done(processed int, err error)
+	SetLag(lag int64)
+}
+
 // WalletService defines the interface for wallet operations.
 type WalletService interface {
 	IsOurWallet(ctx context.Context, address string) (bool, error)
 	GenerateWalletForUser(ctx context.Context, userID int64) (int, string, error)
+	GenerateDepositWallet(ctx context.Context, userID int64) (int, string, error)
 	GetAllTrackedWalletsForUser(ctx context.Context, userID int64) ([]string, error)
 	GetWalletDetailsForUser(ctx context.Context, userID int64) ([]entities.WalletDetail, error)
 	GetERC20TokenBalance(ctx context.Context, client *ethclient.Client, walletAddress string) (*big.Int, error)
@@ -117,6 +151,8 @@ type WalletService interface {
 	TransferAllBNB(ctx context.Context, toAddress, depositUserWalletAddress string, userID, index int) (string, error)
 	GetOrderIdForWallet(ctx context.Context, walletAddress string) (int, error)
 	DeleteWallet(ctx context.Context, walletID int) error
+	WalletOwner(ctx context.Context, walletID int) (int64, error)
+	Asset() entities.Asset
 
 	// Методы мониторинга балансов
 	GetWalletBalances(ctx context.Context) (map[string]*entities.WalletBalance, error)
@@ -130,12 +166,11 @@ type OrderService interface {
 	MarkOrderAMLCleared(ctx context.Context, orderID int, notes string) error
 	RemoveOldOrders(ctx context.Context, olderThan time.Duration) (int64, error)
 	GetUserOrders(ctx context.Context, userID int) ([]entities.Order, error)
-	CreateOrder(ctx context.Context, userID, walletID int, amount string) error
+	CreateOrder(ctx context.Context, userID, walletID int, amount string) (*entities.OrderPayment, error)
 }
 
 const (
 	subscriptionRetryDelay = 10 * time.Second // Delay before retrying subscription
-	maxConcurrentChecks    = 100              // Максимальное количество одновременных проверок подтверждений
 
 	// Block fetching retry configuration
 	maxBlockFetchRetries = 5                // Maximum number of retries for block fetching
@@ -145,6 +180,9 @@ const (
 
 // GetContractAddress returns the appropriate contract address based on mode
 func GetContractAddress() string {
+	if sim, ok := shared.Simulated(); ok {
+		return sim.TokenAddress // Тестовый токен симулированной сети
+	}
 	if shared.IsBlockchainDebugMode() {
 		return "0x337610d27c682E347C9cD60BD4b3b107C9d34dDd" // USDT on BSC Testnet
 	}
@@ -168,13 +206,40 @@ type BinanceSmartChain struct {
 	wallets      WalletService
 	amlService   AMLService   // Добавляем сервис AML проверок
 	orders       OrderService // Добавляем сервис ордеров
-
-	// Семафор для ограничения одновременных проверок подтверждений
-	confirmationSemaphore chan struct{}
-
-	// Мьютекс для защиты lastProcessedBlock
+	mempool      MempoolDepositService
+	refunds      RefundService
+	policy       ConfirmationPolicy
+	holds        DepositHoldService
+	filters      DepositFilter
+	states       ScannerStates
+	tracker      WorkerTracker
+
+	// Запись обработанных блоков с ответами RPC для воспроизведения, nil — запись выключена
+	recorder *blockrec.Recorder
+	// При воспроизведении записанных блоков проверки подтверждений не планируются: записи заканчиваются на блоке
+	replaying bool
+
+	// Транзакции, ожидающие подтверждений: проверяются пачкой одним batch запросом
+	confirmationsMu      sync.Mutex
+	pendingConfirmations map[common.Hash]*pendingConfirmation
+	confirmationLoop     sync.Once
+
+	// Мьютекс для защиты lastProcessedBlock, lastProgressAt, текущей подписки, паузы и контрольной точки
 	mu                 sync.Mutex
 	lastProcessedBlock uint64
+	lastProgressAt     time.Time
+	currentEndpoint    string
+	cancelAttempt      context.CancelCauseFunc
+	// Канал закрывается при возобновлении, nil — сканер не приостановлен
+	resumed chan struct{}
+	// После возобновления пропущенные за паузу блоки догоняются без ограничения maxReconnectBackfill
+	resumeBackfill bool
+	// Последний сохраненный в базу блок и время сохранения
+	checkpointBlock uint64
+	checkpointAt    time.Time
+
+	// Смещение в списке WebSocket эндпоинтов: после остановки сканера подключаемся к следующему
+	endpointOffset int
 }
 
 func NewBinanceSmartChain(
@@ -184,6 +249,14 @@ func NewBinanceSmartChain(
 	wallets WalletService,
 	amlService AMLService,
 	orders OrderService,
+	mempool MempoolDepositService,
+	refunds RefundService,
+	policy ConfirmationPolicy,
+	holds DepositHoldService,
+	filters DepositFilter,
+	states ScannerStates,
+	recorder *blockrec.Recorder,
+	tracker WorkerTracker,
 ) *BinanceSmartChain {
 	// Refresh the USDTContractAddress to ensure it's set correctly based on current environment
 	USDTContractAddress = GetContractAddress()
@@ -197,24 +270,66 @@ func NewBinanceSmartChain(
 	}
 
 	return &BinanceSmartChain{
-		logger:                logger,
-		config:                config,
-		transactions:          transactions,
-		wallets:               wallets,
-		amlService:            amlService,
-		orders:                orders,
-		confirmationSemaphore: make(chan struct{}, maxConcurrentChecks),
+		logger:               logger,
+		config:               config,
+		transactions:         transactions,
+		wallets:              wallets,
+		amlService:           amlService,
+		orders:               orders,
+		mempool:              mempool,
+		refunds:              refunds,
+		policy:               policy,
+		holds:                holds,
+		filters:              filters,
+		states:               states,
+		recorder:             recorder,
+		tracker:              tracker,
+		pendingConfirmations: make(map[common.Hash]*pendingConfirmation),
 	}
 }
 
 // SubscribeToTransactions monitors incoming transactions via Web3.
 // The service will use WebSocket to listen for new blocks and process incoming transactions.
 func (bsc *BinanceSmartChain) SubscribeToTransactions(ctx context.Context, rpcURL string) {
+	bsc.loadState(ctx)
+
 	for {
+		if bsc.Paused() {
+			bsc.logger.WarnContext(ctx, "Block scanner is paused, waiting for resume")
+		}
+		if !bsc.waitWhilePaused(ctx) {
+			return
+		}
+
 		bsc.logger.InfoContext(ctx, "Starting blockchain monitoring via WebSocket...")
 
+		// Сторож отменяет подписку, если сканер отстал от сети или перестал получать блоки
+		bsc.mu.Lock()
+		bsc.lastProgressAt = time.Now()
+		bsc.mu.Unlock()
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		bsc.setAttempt(cancel)
+		go bsc.watchScanner(attemptCtx, cancel)
+
 		// Пытаемся использовать WebSocket подписку
-		if err := bsc.subscribeViaWebsocket(ctx); err != nil {
+		err := bsc.subscribeViaWebsocket(attemptCtx)
+		cause := context.Cause(attemptCtx)
+		bsc.setAttempt(nil)
+		cancel(nil)
+		bsc.saveCheckpoint(ctx, true)
+
+		if errors.Is(cause, errScannerPaused) && ctx.Err() == nil {
+			bsc.logger.WarnContext(ctx, "Block scanner paused", "last_processed", bsc.lastProcessed())
+			continue
+		}
+
+		if (errors.Is(cause, errScannerStalled) || errors.Is(cause, errScannerFailover)) && ctx.Err() == nil {
+			bsc.endpointOffset++
+			bsc.logger.WarnContext(ctx, "Failing over block scanner to the next WebSocket endpoint", "cause", cause)
+			continue
+		}
+
+		if err != nil {
 			bsc.logger.ErrorContext(ctx, "WebSocket subscription failed, retrying...",
 				"delay", subscriptionRetryDelay, "error", err)
 
@@ -240,11 +355,13 @@ func (bsc *BinanceSmartChain) subscribeViaWebsocket(ctx context.Context) error {
 
 	bsc.logger.InfoContext(ctx, "Attempting to connect via WebSocket")
 
-	for _, endpoint := range GetBSCWebSocketEndpoints() {
+	endpoints := GetBSCWebSocketEndpoints()
+	for i := range endpoints {
+		endpoint := endpoints[(bsc.endpointOffset+i)%len(endpoints)]
 		bsc.logger.InfoContext(ctx, "Trying WebSocket endpoint", "endpoint", endpoint)
 
 		// Создаем RPC клиент с WebSocket соединением
-		rpcClient, err = rpc.DialContext(ctx, endpoint)
+		rpcClient, err = rpcmanager.DialRPC(ctx, endpoint)
 		if err != nil {
 			bsc.logger.WarnContext(ctx, "Failed to connect to WebSocket endpoint",
 				"endpoint", endpoint, "error", err)
@@ -254,6 +371,9 @@ func (bsc *BinanceSmartChain) subscribeViaWebsocket(ctx context.Context) error {
 		// Создаем Ethereum клиент на основе RPC клиента
 		wsClient = ethclient.NewClient(rpcClient)
 		wsEndpoint = endpoint
+		// Смещение указывает на текущий эндпоинт, чтобы переключение вело к следующему после него
+		bsc.endpointOffset += i
+		bsc.setCurrentEndpoint(endpoint)
 		bsc.logger.InfoContext(ctx, "Successfully connected to WebSocket endpoint",
 			"endpoint", endpoint)
 		break
@@ -271,12 +391,23 @@ func (bsc *BinanceSmartChain) subscribeViaWebsocket(ctx context.Context) error {
 		return fmt.Errorf("failed to get current block number: %w", err)
 	}
 
+	// После переподключения пропущенные блоки догоняются от последнего обработанного,
+	// слишком большой разрыв пропускается, чтобы не задерживать новые депозиты.
+	// Блоки, пропущенные за ручную паузу, догоняются полностью
 	bsc.mu.Lock()
-	bsc.lastProcessedBlock = currentBlock
+	if bsc.lastProcessedBlock == 0 || (!bsc.resumeBackfill && currentBlock > bsc.lastProcessedBlock+maxReconnectBackfill) {
+		if bsc.lastProcessedBlock != 0 {
+			bsc.logger.ErrorContext(ctx, "Block gap too large to backfill after reconnect",
+				"from", bsc.lastProcessedBlock+1, "to", currentBlock, "max_backfill", maxReconnectBackfill)
+		}
+		bsc.lastProcessedBlock = currentBlock
+	}
+	startBlock := bsc.lastProcessedBlock
+	bsc.lastProgressAt = time.Now()
 	bsc.mu.Unlock()
 
 	bsc.logger.InfoContext(ctx, "Starting WebSocket monitoring from block",
-		"block", currentBlock, "endpoint", wsEndpoint)
+		"block", startBlock, "head", currentBlock, "endpoint", wsEndpoint)
 
 	// Создаем канал для получения заголовков новых блоков
 	headers := make(chan *types.Header)
@@ -324,21 +455,31 @@ func (bsc *BinanceSmartChain) subscribeViaWebsocket(ctx context.Context) error {
 				// Получаем пропущенные блоки через HTTP клиент
 				for missedBlock := lastProcessed + 1; missedBlock < blockNumber; missedBlock++ {
 					bsc.processBlockByNumber(ctx, httpClient, missedBlock)
+					// Блок, обработка которого прервана отменой подписки, не отмечается и обрабатывается заново
+					if ctx.Err() != nil {
+						return fmt.Errorf("WebSocket subscription done with %w", ctx.Err())
+					}
+					bsc.markBlockProcessed(missedBlock)
+					bsc.tracker.Done(1, nil)
+					bsc.saveCheckpoint(ctx, false)
 				}
 			}
 
 			// Обрабатываем текущий блок
-			if err := bsc.processBlockHeader(ctx, httpClient, header); err != nil {
+			err := bsc.processBlockHeader(ctx, httpClient, header)
+			if err != nil {
 				bsc.logger.ErrorContext(ctx, "Failed to process block header",
 					"block", blockNumber, "error", err)
 			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("WebSocket subscription done with %w", ctx.Err())
+			}
+			bsc.tracker.Done(1, err)
 
 			// Обновляем последний обработанный блок
-			bsc.mu.Lock()
-			if blockNumber > bsc.lastProcessedBlock {
-				bsc.lastProcessedBlock = blockNumber
-			}
-			bsc.mu.Unlock()
+			bsc.markBlockProcessed(blockNumber)
+			bsc.finishResumeBackfill()
+			bsc.saveCheckpoint(ctx, false)
 
 		case <-processTicker.C:
 			// Периодически обрабатываем ожидающие транзакции
@@ -356,11 +497,12 @@ func (bsc *BinanceSmartChain) processBlockByNumber(ctx context.Context, client *
 	maxRetries := 3
 	retryDelay := 500 * time.Millisecond
 
-	var block *types.Block
+	var header *types.Header
 	var err error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		block, err = client.BlockByNumber(ctx, big.NewInt(int64(blockNumber)))
+		// Загружаем только заголовок: тело блока запрашивается в processBlock после проверки bloom
+		header, err = client.HeaderByNumber(ctx, big.NewInt(int64(blockNumber)))
 		if err == nil {
 			break // Блок успешно получен
 		}
@@ -390,7 +532,7 @@ func (bsc *BinanceSmartChain) processBlockByNumber(ctx context.Context, client *
 		return
 	}
 
-	bsc.processBlock(ctx, client, block.Header())
+	bsc.processBlock(ctx, client, header)
 }
 
 // processBlockHeader обрабатывает заголовок блока
@@ -401,6 +543,11 @@ func (bsc *BinanceSmartChain) processBlockHeader(ctx context.Context, client *et
 	startTime := time.Now() // Добавляем измерение времени
 	blockNumber := header.Number.Uint64()
 
+	// Блоки без переводов USDT processBlock пропускает по bloom, не загружая тело блока
+	if !bloomMayContainUSDTTransfer(header) {
+		return bsc.processBlock(ctx, client, header)
+	}
+
 	// Fallback clients to use when primary client fails
 	var fallbackClient *ethclient.Client
 	var fallbackEndpoint string
@@ -410,7 +557,9 @@ func (bsc *BinanceSmartChain) processBlockHeader(ctx context.Context, client *et
 		currentClient := client
 		if attempt == 3 && fallbackClient == nil {
 			// Choose a fallback endpoint based on debug mode
-			if shared.IsBlockchainDebugMode() {
+			if sim, ok := shared.Simulated(); ok {
+				fallbackEndpoint = sim.HTTPURL
+			} else if shared.IsBlockchainDebugMode() {
 				// Testnet fallback endpoint
 				fallbackEndpoint = "https://data-seed-prebsc-2-s3.binance.org:8545"
 			} else {
@@ -419,7 +568,7 @@ func (bsc *BinanceSmartChain) processBlockHeader(ctx context.Context, client *et
 			}
 
 			var err error
-			fallbackClient, err = ethclient.Dial(fallbackEndpoint)
+			fallbackClient, err = rpcmanager.Dial(ctx, fallbackEndpoint)
 			if err != nil {
 				bsc.logger.WarnContext(ctx, "Failed to create fallback client",
 					"endpoint", fallbackEndpoint,
@@ -519,6 +668,22 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 	// Начинаем отсчет времени обработки блока
 	startTime := time.Now()
 
+	if !mayContainUSDTTransfer(header) {
+		bsc.logger.DebugContext(ctx, "Skipping block, logs bloom excludes USDT transfers",
+			"block_number", header.Number.Uint64())
+		return nil
+	}
+
+	// В режиме записи блок и все ответы RPC, полученные при его обработке, сохраняются для воспроизведения
+	ctx, recording := bsc.recorder.Begin(ctx, header)
+	defer func() {
+		if err := recording.Finish(); err != nil {
+			bsc.logger.WarnContext(ctx, "Failed to save block recording",
+				"block_number", header.Number.Uint64(),
+				"error", err)
+		}
+	}()
+
 	// Get the block
 	block, err := client.BlockByHash(ctx, header.Hash())
 	if err != nil {
@@ -586,6 +751,9 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 					amountBytes := data[36:68]
 					amount := new(big.Int).SetBytes(amountBytes)
 
+					// Мемо депозита передается байтами после параметров transfer, как это делают биржи
+					memo := transferMemo(data[68:])
+
 					// Обновляем данные логирования
 					txLogFields.To = recipientAddr
 					txLogFields.Amount = amount.String()
@@ -614,17 +782,49 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 					}
 
 					if isOurWallet {
+						// Транзакция попала в блок — предварительный депозит из мемпула больше не нужен
+						if bsc.mempool != nil {
+							bsc.mempool.ForgetMempoolDeposit(txHash)
+						}
+
 						bsc.logger.WarnContext(ctx, "USDT Transfer to our wallet detected",
 							"tx_id", txID,
 							"tx_hash", txHash,
 							"from", sender.Hex(),
 							"to", recipientAddr,
 							"amount", amount.String(),
+							"memo", memo,
 							"block_number", blockNumber,
 							"status", TxStatusPending)
 
 						// Выполняем AML проверку транзакции
 						if bsc.amlService != nil {
+							// Число подтверждений зависит от суммы депозита
+							required := bsc.policy.Required(ctx, entities.ChainBSC, bsc.wallets.Asset(), amount)
+
+							// Транзакция записывается до AML проверки: результат проверки ссылается на нее внешним ключом,
+							// а статус AML обновляется в уже существующей записи
+							if err = bsc.transactions.RecordTransaction(ctx, tx.Hash(), recipientAddr, sender.Hex(), memo, amount, int64(blockNumber), required); err != nil {
+								bsc.logger.ErrorContext(ctx, "Failed to record transaction",
+									"error", err,
+									"tx_id", txID,
+									"tx_hash", txHash)
+								continue
+							}
+
+							// Пыль и серии микропереводов записаны, но не проверяются и не зачисляются
+							if bsc.filters != nil {
+								ignored, filterErr := bsc.filters.FilterDeposit(ctx, bsc.wallets.Asset(), txHash, sender.Hex(), amount)
+								if filterErr != nil {
+									bsc.logger.ErrorContext(ctx, "Failed to filter deposit",
+										"error", filterErr,
+										"tx_id", txID,
+										"tx_hash", txHash)
+								} else if ignored {
+									continue
+								}
+							}
+
 							amlResult, amlErr := bsc.amlService.CheckTransaction(ctx, tx.Hash(), sender.Hex(), recipientAddr, amount)
 							if amlErr != nil {
 								bsc.logger.ErrorContext(ctx, "AML check failed",
@@ -653,6 +853,9 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 									}
 								}
 
+								// Отклоненный депозит возвращается, только если он помечен и поэтому не будет зачислен
+								amlFlagged := false
+
 								// Обновляем статус в зависимости от результата проверки
 								if !amlResult.Approved {
 									bsc.logger.WarnContext(ctx, "Transaction flagged by AML check",
@@ -669,6 +872,8 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 										bsc.logger.ErrorContext(ctx, "Failed to mark transaction as AML flagged",
 											"error", err,
 											"tx_hash", txHash)
+									} else {
+										amlFlagged = true
 									}
 
 									// Обновляем статус ордера если он найден
@@ -709,17 +914,26 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 									}
 								}
 
-								// Record the transaction
-								if err = bsc.transactions.RecordTransaction(ctx, tx.Hash(), recipientAddr, amount, int64(blockNumber)); err != nil {
-									bsc.logger.ErrorContext(ctx, "Failed to record transaction",
-										"error", err,
-										"tx_id", txID,
-										"tx_hash", txHash)
+								if amlFlagged && bsc.refunds != nil && bsc.config.Orders.RefundAMLRejected {
+									// Отклоненный депозит возвращаем отправителю
+									if err = bsc.refunds.RequestAMLRefund(ctx, txHash); err != nil {
+										bsc.logger.ErrorContext(ctx, "Failed to request refund for AML rejected deposit",
+											"error", err,
+											"tx_hash", txHash)
+									}
+								} else if amlResult.Approved && bsc.holds != nil {
+									// Необычно крупный депозит не зачисляется до ручного освобождения
+									if err = bsc.holds.CheckDeposit(ctx, txHash, recipientAddr, amount); err != nil {
+										bsc.logger.ErrorContext(ctx, "Failed to check deposit for hold",
+											"error", err,
+											"tx_hash", txHash)
+									}
 								}
 
-								// Check confirmations after RequiredConfirmations blocks
-								// Используем семафор для ограничения количества одновременных проверок
-								bsc.scheduleConfirmationCheck(ctx, client, tx.Hash(), blockNumber, txID)
+								// Check confirmations after the required number of blocks
+								if !bsc.replaying {
+									bsc.scheduleConfirmationCheck(ctx, tx.Hash(), blockNumber, txID, required)
+								}
 							}
 						}
 					}
@@ -738,100 +952,19 @@ func (bsc *BinanceSmartChain) processBlock(ctx context.Context, client *ethclien
 	return nil
 }
 
-// scheduleConfirmationCheck планирует проверку подтверждений с использованием семафора
-func (bsc *BinanceSmartChain) scheduleConfirmationCheck(
-	ctx context.Context,
-	client *ethclient.Client,
-	txHash common.Hash,
-	blockNumber uint64,
-	txID string,
-) {
-	// Создаем отдельную горутину для ожидания доступного слота в семафоре
-	go func() {
-		// Ожидаем доступный слот или завершение контекста
-		select {
-		case <-ctx.Done():
-			return
-		case bsc.confirmationSemaphore <- struct{}{}:
-			// Слот получен, запускаем проверку подтверждений
-			go func() {
-				defer func() { <-bsc.confirmationSemaphore }() // Освобождаем слот после выполнения
-				bsc.checkConfirmations(ctx, client, txHash, blockNumber, txID)
-			}()
-		}
-	}()
-}
-
-// checkConfirmations ждет требуемого количества подтверждений и затем подтверждает транзакцию.
-func (bsc *BinanceSmartChain) checkConfirmations(
-	ctx context.Context,
-	client *ethclient.Client,
-	txHash common.Hash,
-	blockNumber uint64,
-	txID string, // Добавлен параметр txID для связывания логов
-) {
-	// Создаём контекст с метками для отслеживания
-	startTime := time.Now()
-	txHashHex := txHash.Hex()
-
-	// Create a ticker to check every 30 seconds
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			bsc.logger.InfoContext(ctx, "Confirmation check cancelled",
-				"tx_id", txID,
-				"tx_hash", txHashHex,
-				"reason", ctx.Err().Error(),
-				"duration", time.Since(startTime).String())
-			return
-		case <-ticker.C:
-			// Get current block number
-			currentBlock, err := client.BlockNumber(ctx)
-			if err != nil {
-				bsc.logger.ErrorContext(ctx, "Failed to get current block number",
-					"error", err,
-					"tx_id", txID,
-					"tx_hash", txHashHex)
-				continue
-			}
-
-			// Рассчитываем количество подтверждений
-			confirmations := currentBlock - blockNumber
-
-			// Check if we have enough confirmations
-			if confirmations >= bsc.config.Blockchain.RequiredConfirmations {
-				// Confirm the transaction
-				if err = bsc.transactions.ConfirmTransaction(ctx, txHashHex); err != nil {
-					bsc.logger.ErrorContext(ctx, "Failed to confirm transaction",
-						"error", err,
-						"tx_id", txID,
-						"tx_hash", txHashHex,
-						"confirmations", confirmations,
-						"status", TxStatusFailed,
-						"duration", time.Since(startTime).String())
-				} else {
-					bsc.logger.InfoContext(ctx, "Transaction confirmed",
-						"tx_id", txID,
-						"tx_hash", txHashHex,
-						"confirmations", confirmations,
-						"status", TxStatusConfirmed,
-						"duration", time.Since(startTime).String())
-				}
-				return
-			}
-
-			bsc.logger.InfoContext(ctx, "Waiting for confirmations",
-				"tx_id", txID,
-				"tx_hash", txHashHex,
-				"current", confirmations,
-				"required", bsc.config.Blockchain.RequiredConfirmations,
-				"status", TxStatusPending,
-				"elapsed_time", time.Since(startTime).String())
+// transferMemo извлекает мемо депозита из байтов после параметров вызова transfer.
+// Мемо — печатная ASCII строка, дополненная нулями до 32 байт; иные данные мемо не считаются.
+func transferMemo(extra []byte) string {
+	memo := bytes.TrimRight(extra, "\x00")
+	if len(memo) == 0 || len(memo) > entities.MaxDepositMemoLength {
+		return ""
+	}
+	for _, b := range memo {
+		if b < 0x21 || b > 0x7e {
+			return ""
 		}
 	}
+	return string(memo)
 }
 
 // getHTTPClient создает HTTP-клиент для взаимодействия с блокчейном
@@ -839,11 +972,16 @@ func getHTTPClient(ctx context.Context, logger *slog.Logger) (*ethclient.Client,
 	var client *ethclient.Client
 	var err, lastErr error
 
+	endpoints := bscHTTPEndpoints
+	if sim, ok := shared.Simulated(); ok {
+		endpoints = []string{sim.HTTPURL}
+	}
+
 	// Пробуем подключиться к разным эндпоинтам
-	for _, endpoint := range bscHTTPEndpoints {
+	for _, endpoint := range endpoints {
 		logger.InfoContext(ctx, "Trying to connect to HTTP endpoint", "endpoint", endpoint)
 
-		client, err = ethclient.DialContext(ctx, endpoint)
+		client, err = rpcmanager.Dial(ctx, endpoint)
 		if err == nil {
 			logger.InfoContext(ctx, "Successfully connected to HTTP endpoint", "endpoint", endpoint)
 			return client, nil
@@ -0,0 +1,172 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/errreport"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcbatch"
+)
+
+// Интервал проверки подтверждений ожидающих транзакций
+const confirmationCheckInterval = 30 * time.Second
+
+// pendingConfirmation — входящая транзакция, ожидающая требуемого числа подтверждений
+type pendingConfirmation struct {
+	txHash      common.Hash
+	blockNumber uint64
+	txID        string
+	startTime   time.Time
+	// Требуемое число подтверждений по сумме депозита
+	required uint64
+}
+
+// scheduleConfirmationCheck ставит транзакцию в очередь проверки подтверждений.
+// Все ожидающие транзакции проверяются одним циклом, receipts запрашиваются batch запросами.
+func (bsc *BinanceSmartChain) scheduleConfirmationCheck(ctx context.Context, txHash common.Hash, blockNumber uint64, txID string, required uint64) {
+	bsc.confirmationsMu.Lock()
+	bsc.pendingConfirmations[txHash] = &pendingConfirmation{
+		txHash:      txHash,
+		blockNumber: blockNumber,
+		txID:        txID,
+		startTime:   time.Now(),
+		required:    required,
+	}
+	bsc.confirmationsMu.Unlock()
+
+	bsc.confirmationLoop.Do(func() {
+		go bsc.runConfirmationLoop(ctx)
+	})
+}
+
+func (bsc *BinanceSmartChain) runConfirmationLoop(ctx context.Context) {
+	defer errreport.Recover(map[string]string{"worker": "confirmation_checker", "chain": "bsc"})
+
+	ticker := time.NewTicker(confirmationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			bsc.confirmationsMu.Lock()
+			pending := len(bsc.pendingConfirmations)
+			bsc.confirmationsMu.Unlock()
+
+			bsc.logger.InfoContext(ctx, "Confirmation checks cancelled",
+				"pending", pending,
+				"reason", ctx.Err().Error())
+			return
+		case <-ticker.C:
+			bsc.checkPendingConfirmations(ctx)
+		}
+	}
+}
+
+// checkPendingConfirmations fetches the chain head and receipts of all pending transactions
+// in batch requests and confirms those that reached the required number of confirmations
+func (bsc *BinanceSmartChain) checkPendingConfirmations(ctx context.Context) {
+	// Приостановленный сканер не подтверждает депозиты, очередь проверяется после возобновления
+	if bsc.Paused() {
+		return
+	}
+
+	bsc.confirmationsMu.Lock()
+	pending := make([]*pendingConfirmation, 0, len(bsc.pendingConfirmations))
+	hashes := make([]common.Hash, 0, len(bsc.pendingConfirmations))
+	for hash, p := range bsc.pendingConfirmations {
+		pending = append(pending, p)
+		hashes = append(hashes, hash)
+	}
+	bsc.confirmationsMu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	client, err := getHTTPClient(ctx, bsc.logger)
+	if err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to create client for confirmation checks", "error", err)
+		return
+	}
+	defer client.Close()
+
+	currentBlock, receipts, err := rpcbatch.New(client.Client(), rpcbatch.DefaultBatchSize).
+		BlockNumberAndReceipts(ctx, hashes)
+	if err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to fetch receipts for confirmation checks",
+			"error", err,
+			"pending", len(pending))
+		return
+	}
+
+	for _, p := range pending {
+		bsc.processConfirmation(ctx, p, currentBlock, receipts[p.txHash])
+	}
+}
+
+func (bsc *BinanceSmartChain) processConfirmation(ctx context.Context, p *pendingConfirmation, currentBlock uint64, receipt *types.Receipt) {
+	txHashHex := p.txHash.Hex()
+
+	// Транзакции без receipt (ещё не видны ноде или исключены реорганизацией) ждут следующей проверки
+	blockNumber := p.blockNumber
+	if receipt != nil {
+		blockNumber = receipt.BlockNumber.Uint64()
+	}
+
+	if receipt != nil && receipt.Status != types.ReceiptStatusSuccessful {
+		bsc.logger.ErrorContext(ctx, "Transaction reverted, skipping confirmation",
+			"tx_id", p.txID,
+			"tx_hash", txHashHex,
+			"block_number", blockNumber,
+			"status", TxStatusFailed,
+			"duration", time.Since(p.startTime).String())
+		bsc.removePendingConfirmation(p.txHash)
+		return
+	}
+
+	var confirmations uint64
+	if currentBlock > blockNumber {
+		confirmations = currentBlock - blockNumber
+	}
+
+	if receipt == nil || confirmations < p.required {
+		bsc.logger.InfoContext(ctx, "Waiting for confirmations",
+			"tx_id", p.txID,
+			"tx_hash", txHashHex,
+			"current", confirmations,
+			"required", p.required,
+			"receipt_found", receipt != nil,
+			"status", TxStatusPending,
+			"elapsed_time", time.Since(p.startTime).String())
+		return
+	}
+
+	if err := bsc.transactions.ConfirmTransaction(ctx, txHashHex, confirmations); err != nil {
+		// Транзакция остаётся в очереди и будет подтверждена при следующей проверке
+		bsc.logger.ErrorContext(ctx, "Failed to confirm transaction",
+			"error", err,
+			"tx_id", p.txID,
+			"tx_hash", txHashHex,
+			"confirmations", confirmations,
+			"status", TxStatusFailed,
+			"duration", time.Since(p.startTime).String())
+		return
+	}
+
+	bsc.logger.InfoContext(ctx, "Transaction confirmed",
+		"tx_id", p.txID,
+		"tx_hash", txHashHex,
+		"confirmations", confirmations,
+		"status", TxStatusConfirmed,
+		"duration", time.Since(p.startTime).String())
+	bsc.removePendingConfirmation(p.txHash)
+}
+
+func (bsc *BinanceSmartChain) removePendingConfirmation(txHash common.Hash) {
+	bsc.confirmationsMu.Lock()
+	delete(bsc.pendingConfirmations, txHash)
+	bsc.confirmationsMu.Unlock()
+}
@@ -0,0 +1,314 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+const (
+	// marketFeedReadTimeout — поток присылает обновление свечи каждые несколько секунд, дольше тишины — обрыв
+	marketFeedReadTimeout = time.Minute
+	// marketFeedRetryDelay — пауза перед переподключением к потоку
+	marketFeedRetryDelay = 5 * time.Second
+)
+
+// MarketCandles is the candle window of the trading pairs sent to WebSocket subscribers
+type MarketCandles interface {
+	BackfillCandles(symbol string, candles []entities.CandleData) (int, error)
+	ApplyCandle(symbol string, candle entities.CandleData) error
+}
+
+// CandleStore persists candles of the pairs served by the feed
+type CandleStore interface {
+	UpsertCandles(ctx context.Context, symbol string, candles []entities.CandleData) error
+	FindCandlesSince(ctx context.Context, symbol string, since int64) ([]entities.CandleData, error)
+}
+
+// KlineSource fetches klines opened from from to to (milliseconds, inclusive) over REST
+type KlineSource interface {
+	Klines(ctx context.Context, symbol string, interval time.Duration, from, to int64) ([]entities.CandleData, error)
+}
+
+// MarketFeedConfig configures the market-data feed
+type MarketFeedConfig struct {
+	StreamURL string        // Binance combined stream endpoint, e.g. wss://stream.binance.com:9443
+	Symbols   []string      // Pairs taken from the feed
+	Interval  time.Duration // Candle interval
+	Window    int           // Candles restored after a restart or an outage at most
+}
+
+// MarketFeed streams klines of the configured pairs into the candle window and the candle store.
+// After every (re)connect, and whenever the stream skips a candle, the missed klines are fetched over REST,
+// persisted and backfilled into the window, so charts have no gaps.
+type MarketFeed struct {
+	logger  *slog.Logger
+	config  MarketFeedConfig
+	candles MarketCandles
+	store   CandleStore
+	klines  KlineSource
+	tracker WorkerTracker
+	dialer  *websocket.Dialer
+
+	interval   string
+	retryDelay time.Duration
+
+	mu sync.Mutex
+	// Время открытия последней полученной свечи пары, мс. С нее начинается догрузка после обрыва:
+	// на момент обрыва свеча могла быть еще не закрыта
+	lastSeen map[string]int64
+}
+
+// NewMarketFeed creates a new market-data feed
+func NewMarketFeed(logger *slog.Logger, config MarketFeedConfig, candles MarketCandles, store CandleStore, klines KlineSource, tracker WorkerTracker) (*MarketFeed, error) {
+	interval, ok := klineInterval(config.Interval)
+	if !ok {
+		return nil, fmt.Errorf("unsupported market feed interval %s", config.Interval)
+	}
+	if config.Window <= 0 {
+		return nil, fmt.Errorf("market feed window must be positive, got %d", config.Window)
+	}
+
+	return &MarketFeed{
+		logger:     logger,
+		config:     config,
+		candles:    candles,
+		store:      store,
+		klines:     klines,
+		tracker:    tracker,
+		dialer:     websocket.DefaultDialer,
+		interval:   interval,
+		retryDelay: marketFeedRetryDelay,
+		lastSeen:   make(map[string]int64, len(config.Symbols)),
+	}, nil
+}
+
+// Start restores the stored candles and streams klines until ctx is done, reconnecting after every outage
+func (f *MarketFeed) Start(ctx context.Context) {
+	f.logger.InfoContext(ctx, "Starting market data feed", "symbols", f.config.Symbols, "interval", f.interval)
+	f.restore(ctx)
+
+	for {
+		err := f.stream(ctx)
+		if ctx.Err() != nil {
+			f.logger.Info("Market data feed stopped")
+			return
+		}
+
+		f.logger.WarnContext(ctx, "Market data feed disconnected, reconnecting", "delay", f.retryDelay, "error", err)
+		select {
+		case <-ctx.Done():
+			f.logger.Info("Market data feed stopped")
+			return
+		case <-time.After(f.retryDelay):
+		}
+	}
+}
+
+// restore loads the stored candles of the window into the pairs
+func (f *MarketFeed) restore(ctx context.Context) {
+	since := f.windowStart(time.Now())
+	for _, symbol := range f.config.Symbols {
+		candles, err := f.store.FindCandlesSince(ctx, symbol, since)
+		if err != nil {
+			f.logger.ErrorContext(ctx, "Failed to load stored candles", "symbol", symbol, "error", err)
+			continue
+		}
+		if len(candles) == 0 {
+			continue
+		}
+		if _, err = f.candles.BackfillCandles(symbol, candles); err != nil {
+			f.logger.ErrorContext(ctx, "Failed to restore stored candles", "symbol", symbol, "error", err)
+			continue
+		}
+		f.markSeen(symbol, candles[len(candles)-1].Time)
+	}
+}
+
+// stream connects to the kline stream, backfills what was missed before the connection and applies klines until it drops
+func (f *MarketFeed) stream(ctx context.Context) error {
+	conn, _, err := f.dialer.DialContext(ctx, f.streamURL(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to market data stream: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	f.logger.InfoContext(ctx, "Connected to market data stream")
+
+	// Свечи, пропущенные до подключения (за время обрыва или простоя сервиса), догружаются по REST
+	for _, symbol := range f.config.Symbols {
+		f.backfill(ctx, symbol, time.Now())
+	}
+
+	for {
+		if err = conn.SetReadDeadline(time.Now().Add(marketFeedReadTimeout)); err != nil {
+			return err
+		}
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("failed to read market data stream: %w", err)
+		}
+
+		kline, err := parseKlineEvent(message)
+		if err != nil {
+			f.logger.WarnContext(ctx, "Skipping invalid market data message", "error", err)
+			continue
+		}
+		f.handleKline(ctx, kline)
+	}
+}
+
+// handleKline applies a streamed kline. Candles skipped by the stream are backfilled first
+func (f *MarketFeed) handleKline(ctx context.Context, kline streamedKline) {
+	f.tracker.Beat()
+
+	symbol, candle := kline.Symbol, kline.Candle
+	if hasCandleGap(f.seen(symbol), candle.Time, f.config.Interval) {
+		f.logger.WarnContext(ctx, "Market data stream skipped candles", "symbol", symbol,
+			"last_seen", f.seen(symbol), "received", candle.Time)
+		f.backfill(ctx, symbol, time.UnixMilli(candle.Time))
+	}
+
+	if err := f.candles.ApplyCandle(symbol, candle); err != nil {
+		f.logger.ErrorContext(ctx, "Failed to apply streamed candle", "symbol", symbol, "error", err)
+		return
+	}
+	// Сохраняются закрытые свечи, незакрытую на момент обрыва догрузка по REST сохранит уже закрытой
+	if kline.Closed {
+		if err := f.store.UpsertCandles(ctx, symbol, []entities.CandleData{candle}); err != nil {
+			f.logger.ErrorContext(ctx, "Failed to store streamed candle", "symbol", symbol, "error", err)
+		}
+	}
+	f.markSeen(symbol, candle.Time)
+}
+
+// backfill fetches the klines of the symbol missed before until, persists them and merges them into the window
+func (f *MarketFeed) backfill(ctx context.Context, symbol string, until time.Time) {
+	from := backfillStart(f.seen(symbol), f.windowStart(until))
+	candles, err := f.klines.Klines(ctx, symbol, f.config.Interval, from, until.UnixMilli())
+	if err == nil && len(candles) > 0 {
+		err = f.store.UpsertCandles(ctx, symbol, candles)
+	}
+	changed := 0
+	if err == nil && len(candles) > 0 {
+		changed, err = f.candles.BackfillCandles(symbol, candles)
+	}
+	f.tracker.Done(changed, err)
+	if err != nil {
+		f.logger.ErrorContext(ctx, "Failed to backfill candles", "symbol", symbol, "from", from, "error", err)
+		return
+	}
+
+	if len(candles) > 0 {
+		f.markSeen(symbol, candles[len(candles)-1].Time)
+	}
+}
+
+// windowStart returns the open time of the oldest candle of the window ending at now, in milliseconds
+func (f *MarketFeed) windowStart(now time.Time) int64 {
+	return now.Add(-f.config.Interval * time.Duration(f.config.Window-1)).Truncate(f.config.Interval).UnixMilli()
+}
+
+func (f *MarketFeed) streamURL() string {
+	streams := make([]string, 0, len(f.config.Symbols))
+	for _, symbol := range f.config.Symbols {
+		streams = append(streams, strings.ToLower(symbol)+"@kline_"+f.interval)
+	}
+	return strings.TrimRight(f.config.StreamURL, "/") + "/stream?streams=" + strings.Join(streams, "/")
+}
+
+func (f *MarketFeed) seen(symbol string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.lastSeen[symbol]
+}
+
+func (f *MarketFeed) markSeen(symbol string, openTime int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if openTime > f.lastSeen[symbol] {
+		f.lastSeen[symbol] = openTime
+	}
+}
+
+// hasCandleGap reports whether candles are missing between the last received candle and the one opened at openTime.
+// Before the first candle there is nothing to compare with: the window is backfilled on connect
+func hasCandleGap(lastSeen, openTime int64, interval time.Duration) bool {
+	return lastSeen != 0 && openTime > lastSeen+interval.Milliseconds()
+}
+
+// backfillStart returns the open time of the first kline to fetch: the last received candle, which may have been
+// incomplete, or the start of the window when nothing was received or the outage is longer than the window
+func backfillStart(lastSeen, windowStart int64) int64 {
+	if lastSeen < windowStart {
+		return windowStart
+	}
+	return lastSeen
+}
+
+// streamedKline is a kline event of the stream: the candle so far and whether it is closed
+type streamedKline struct {
+	Symbol string
+	Candle entities.CandleData
+	Closed bool
+}
+
+// parseKlineEvent parses a kline event of the combined stream
+func parseKlineEvent(message []byte) (streamedKline, error) {
+	var event struct {
+		Data struct {
+			Event string `json:"e"`
+			Kline struct {
+				OpenTime int64  `json:"t"`
+				Symbol   string `json:"s"`
+				Open     string `json:"o"`
+				High     string `json:"h"`
+				Low      string `json:"l"`
+				Close    string `json:"c"`
+				Volume   string `json:"v"`
+				Closed   bool   `json:"x"`
+			} `json:"k"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil {
+		return streamedKline{}, fmt.Errorf("failed to decode kline event: %w", err)
+	}
+	if event.Data.Event != "kline" {
+		return streamedKline{}, fmt.Errorf("unexpected event %q", event.Data.Event)
+	}
+
+	kline := event.Data.Kline
+	parsed := streamedKline{Symbol: kline.Symbol, Candle: entities.CandleData{Time: kline.OpenTime}, Closed: kline.Closed}
+	fields := []struct {
+		text  string
+		value *float64
+	}{
+		{kline.Open, &parsed.Candle.Open},
+		{kline.High, &parsed.Candle.High},
+		{kline.Low, &parsed.Candle.Low},
+		{kline.Close, &parsed.Candle.Close},
+		{kline.Volume, &parsed.Candle.Volume},
+	}
+	for _, field := range fields {
+		value, err := strconv.ParseFloat(field.text, 64)
+		if err != nil {
+			return streamedKline{}, fmt.Errorf("invalid kline of %s: %w", kline.Symbol, err)
+		}
+		*field.value = value
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,317 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+func TestHasCandleGap(t *testing.T) {
+	const minute = int64(60_000)
+
+	tests := []struct {
+		name     string
+		lastSeen int64
+		openTime int64
+		gap      bool
+	}{
+		{name: "nothing received yet", lastSeen: 0, openTime: 10 * minute, gap: false},
+		{name: "update of the same candle", lastSeen: 10 * minute, openTime: 10 * minute, gap: false},
+		{name: "next candle", lastSeen: 10 * minute, openTime: 11 * minute, gap: false},
+		{name: "one candle skipped", lastSeen: 10 * minute, openTime: 12 * minute, gap: true},
+		{name: "late candle", lastSeen: 10 * minute, openTime: 9 * minute, gap: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.gap, hasCandleGap(tt.lastSeen, tt.openTime, time.Minute))
+		})
+	}
+}
+
+func TestBackfillStart(t *testing.T) {
+	assert.Equal(t, int64(100), backfillStart(0, 100), "nothing received: the whole window")
+	assert.Equal(t, int64(100), backfillStart(40, 100), "outage longer than the window")
+	assert.Equal(t, int64(160), backfillStart(160, 100), "the last received candle is fetched again")
+}
+
+// fakeExchange отдает свечи по REST и поток свечей по WebSocket, каждое подключение к потоку обслуживает свой сценарий
+type fakeExchange struct {
+	mu       sync.Mutex
+	klines   map[int64]entities.CandleData
+	requests [][2]int64 // startTime, endTime запросов /api/v3/klines
+	conns    []func(conn *websocket.Conn)
+}
+
+func (e *fakeExchange) setKlines(candles ...entities.CandleData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, candle := range candles {
+		e.klines[candle.Time] = candle
+	}
+}
+
+func (e *fakeExchange) klineRequests() [][2]int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([][2]int64(nil), e.requests...)
+}
+
+func (e *fakeExchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v3/klines":
+		from, _ := strconv.ParseInt(r.URL.Query().Get("startTime"), 10, 64)
+		to, _ := strconv.ParseInt(r.URL.Query().Get("endTime"), 10, 64)
+
+		e.mu.Lock()
+		e.requests = append(e.requests, [2]int64{from, to})
+		var rows [][]any
+		for openTime, candle := range e.klines {
+			if openTime >= from && openTime <= to {
+				rows = append(rows, []any{candle.Time, format(candle.Open), format(candle.High), format(candle.Low),
+					format(candle.Close), format(candle.Volume), candle.Time + 59_999})
+			}
+		}
+		e.mu.Unlock()
+
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+		_ = json.NewEncoder(w).Encode(rows)
+	case "/stream":
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		e.mu.Lock()
+		var serve func(conn *websocket.Conn)
+		if len(e.conns) > 0 {
+			serve, e.conns = e.conns[0], e.conns[1:]
+		}
+		e.mu.Unlock()
+		if serve != nil {
+			serve(conn)
+			return
+		}
+		// Последнее подключение держится до остановки фида
+		for {
+			if _, _, err = conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func klineMessage(symbol string, candle entities.CandleData, closed bool) []byte {
+	return fmt.Appendf(nil,
+		`{"stream":"%s@kline_1m","data":{"e":"kline","s":"%s","k":{"t":%d,"s":"%s","i":"1m","o":"%s","h":"%s","l":"%s","c":"%s","v":"%s","x":%t}}}`,
+		strings.ToLower(symbol), symbol, candle.Time, symbol,
+		format(candle.Open), format(candle.High), format(candle.Low), format(candle.Close), format(candle.Volume), closed)
+}
+
+type stubMarketCandles struct {
+	mu      sync.Mutex
+	window  map[int64]entities.CandleData
+	applied []entities.CandleData
+}
+
+func (c *stubMarketCandles) BackfillCandles(_ string, candles []entities.CandleData) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := 0
+	for _, candle := range candles {
+		if c.window[candle.Time] != candle {
+			c.window[candle.Time] = candle
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (c *stubMarketCandles) ApplyCandle(_ string, candle entities.CandleData) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.window[candle.Time] = candle
+	c.applied = append(c.applied, candle)
+	return nil
+}
+
+func (c *stubMarketCandles) candle(openTime int64) entities.CandleData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.window[openTime]
+}
+
+type stubCandleStore struct {
+	mu      sync.Mutex
+	candles map[int64]entities.CandleData
+}
+
+func (s *stubCandleStore) UpsertCandles(_ context.Context, _ string, candles []entities.CandleData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, candle := range candles {
+		s.candles[candle.Time] = candle
+	}
+	return nil
+}
+
+func (s *stubCandleStore) FindCandlesSince(_ context.Context, _ string, since int64) ([]entities.CandleData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var candles []entities.CandleData
+	for openTime, candle := range s.candles {
+		if openTime >= since {
+			candles = append(candles, candle)
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool { return candles[i].Time < candles[j].Time })
+	return candles, nil
+}
+
+func (s *stubCandleStore) candle(openTime int64) (entities.CandleData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	candle, ok := s.candles[openTime]
+	return candle, ok
+}
+
+type stubTracker struct{}
+
+func (stubTracker) Beat()           {}
+func (stubTracker) Done(int, error) {}
+func (stubTracker) SetLag(int64)    {}
+
+func newTestMarketFeed(t *testing.T, exchange *fakeExchange) (*MarketFeed, *stubMarketCandles, *stubCandleStore) {
+	t.Helper()
+
+	server := httptest.NewServer(exchange)
+	t.Cleanup(server.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	candles := &stubMarketCandles{window: make(map[int64]entities.CandleData)}
+	store := &stubCandleStore{candles: make(map[int64]entities.CandleData)}
+	feed, err := NewMarketFeed(logger, MarketFeedConfig{
+		StreamURL: "ws" + strings.TrimPrefix(server.URL, "http"),
+		Symbols:   []string{"BTCRUB"},
+		Interval:  time.Minute,
+		Window:    10,
+	}, candles, store, NewBinanceKlines(logger, server.URL, time.Second), stubTracker{})
+	require.NoError(t, err)
+	feed.retryDelay = 10 * time.Millisecond
+
+	return feed, candles, store
+}
+
+func testKline(openTime int64, price float64) entities.CandleData {
+	return entities.CandleData{Time: openTime, Open: price, High: price, Low: price, Close: price, Volume: 1}
+}
+
+func TestMarketFeedBackfillsAfterReconnect(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	first := now.Add(-2 * time.Minute).UnixMilli()
+	second := now.Add(-time.Minute).UnixMilli()
+	third := now.UnixMilli()
+
+	exchange := &fakeExchange{klines: make(map[int64]entities.CandleData)}
+	exchange.setKlines(testKline(first, 100))
+	// Первое подключение после догрузки окна присылает незакрытую свечу и обрывается,
+	// пока свеча закрывается и открывается следующая
+	exchange.conns = append(exchange.conns, func(conn *websocket.Conn) {
+		for len(exchange.klineRequests()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, klineMessage("BTCRUB", testKline(second, 101), false))
+		exchange.setKlines(testKline(second, 105), testKline(third, 106))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "outage"))
+	})
+
+	feed, candles, store := newTestMarketFeed(t, exchange)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		feed.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	require.Eventually(t, func() bool {
+		_, ok := store.candle(third)
+		return ok && len(exchange.klineRequests()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Первое подключение догружает окно целиком, переподключение — с последней полученной свечи
+	requests := exchange.klineRequests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, feed.windowStart(time.UnixMilli(requests[0][1])), requests[0][0])
+	assert.Equal(t, second, requests[1][0])
+
+	// Незакрытая на момент обрыва свеча исправлена, пропущенная добавлена и в окно, и в хранилище
+	assert.Equal(t, testKline(second, 105), candles.candle(second))
+	assert.Equal(t, testKline(third, 106), candles.candle(third))
+	stored, ok := store.candle(second)
+	require.True(t, ok)
+	assert.Equal(t, testKline(second, 105), stored)
+}
+
+func TestMarketFeedBackfillsSkippedCandles(t *testing.T) {
+	base := time.Now().Truncate(time.Minute).Add(-5 * time.Minute).UnixMilli()
+	minute := time.Minute.Milliseconds()
+
+	exchange := &fakeExchange{klines: make(map[int64]entities.CandleData)}
+	exchange.setKlines(testKline(base+minute, 201), testKline(base+2*minute, 202), testKline(base+3*minute, 203))
+	feed, candles, store := newTestMarketFeed(t, exchange)
+	ctx := context.Background()
+
+	feed.handleKline(ctx, streamedKline{Symbol: "BTCRUB", Candle: testKline(base, 200), Closed: true})
+	assert.Empty(t, exchange.klineRequests())
+
+	// Поток перескочил две свечи: они догружаются по REST до применения новой
+	feed.handleKline(ctx, streamedKline{Symbol: "BTCRUB", Candle: testKline(base+3*minute, 203)})
+	requests := exchange.klineRequests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, [2]int64{base, base + 3*minute}, requests[0])
+	assert.Equal(t, testKline(base+minute, 201), candles.candle(base+minute))
+	assert.Equal(t, testKline(base+2*minute, 202), candles.candle(base+2*minute))
+
+	_, ok := store.candle(base)
+	assert.True(t, ok, "closed streamed candle is stored")
+	assert.Equal(t, []entities.CandleData{testKline(base, 200), testKline(base+3*minute, 203)}, candles.applied)
+}
+
+func TestParseKlineEvent(t *testing.T) {
+	kline, err := parseKlineEvent(klineMessage("BTCRUB", entities.CandleData{Time: 60_000, Open: 1.5, High: 2, Low: 1, Close: 1.75, Volume: 10}, true))
+	require.NoError(t, err)
+	assert.Equal(t, streamedKline{
+		Symbol: "BTCRUB",
+		Candle: entities.CandleData{Time: 60_000, Open: 1.5, High: 2, Low: 1, Close: 1.75, Volume: 10},
+		Closed: true,
+	}, kline)
+
+	_, err = parseKlineEvent([]byte(`{"data":{"e":"trade"}}`))
+	assert.Error(t, err)
+}
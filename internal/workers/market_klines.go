@@ -0,0 +1,151 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// maxKlinesPerRequest — предел числа свечей в одном ответе /api/v3/klines
+const maxKlinesPerRequest = 1000
+
+var _ KlineSource = (*BinanceKlines)(nil)
+
+// BinanceKlines fetches klines from the Binance REST API (/api/v3/klines)
+type BinanceKlines struct {
+	logger  *slog.Logger
+	apiURL  string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewBinanceKlines creates a kline client for the REST API at apiURL, e.g. https://api.binance.com
+func NewBinanceKlines(logger *slog.Logger, apiURL string, timeout time.Duration) *BinanceKlines {
+	return &BinanceKlines{
+		logger:  logger,
+		apiURL:  strings.TrimRight(apiURL, "/"),
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+}
+
+// Klines returns the klines of the symbol opened from from to to (milliseconds, inclusive), oldest first.
+// The last kline may still be open.
+func (k *BinanceKlines) Klines(ctx context.Context, symbol string, interval time.Duration, from, to int64) ([]entities.CandleData, error) {
+	name, ok := klineInterval(interval)
+	if !ok {
+		return nil, fmt.Errorf("unsupported kline interval %s", interval)
+	}
+
+	k.logger.DebugContext(ctx, "Fetching klines", "symbol", symbol, "interval", name, "from", from, "to", to)
+
+	var candles []entities.CandleData
+	for from <= to {
+		page, err := k.fetch(ctx, symbol, name, from, to)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, page...)
+		if len(page) < maxKlinesPerRequest {
+			break
+		}
+		from = page[len(page)-1].Time + 1
+	}
+
+	return candles, nil
+}
+
+func (k *BinanceKlines) fetch(ctx context.Context, symbol, interval string, from, to int64) ([]entities.CandleData, error) {
+	// Запрос к бирже ограничен дедлайном, 0 — без дедлайна
+	ctx, cancel := timeouts.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("interval", interval)
+	query.Set("startTime", strconv.FormatInt(from, 10))
+	query.Set("endTime", strconv.FormatInt(to, 10))
+	query.Set("limit", strconv.Itoa(maxKlinesPerRequest))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.apiURL+"/api/v3/klines?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create klines request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch klines of %s: %w", symbol, timeouts.Classify("klines", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("klines API returned status %d for %s: %s", resp.StatusCode, symbol, string(body))
+	}
+
+	// Свеча — массив [время открытия, open, high, low, close, volume, ...], цены и объем строками
+	var rows [][]json.RawMessage
+	if err = json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode klines of %s: %w", symbol, err)
+	}
+
+	candles := make([]entities.CandleData, 0, len(rows))
+	for _, row := range rows {
+		candle, err := parseKlineRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kline of %s: %w", symbol, err)
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+func parseKlineRow(row []json.RawMessage) (entities.CandleData, error) {
+	var candle entities.CandleData
+	if len(row) < 6 {
+		return candle, fmt.Errorf("expected at least 6 fields, got %d", len(row))
+	}
+	if err := json.Unmarshal(row[0], &candle.Time); err != nil {
+		return candle, fmt.Errorf("open time: %w", err)
+	}
+
+	values := []*float64{&candle.Open, &candle.High, &candle.Low, &candle.Close, &candle.Volume}
+	for i, value := range values {
+		var text string
+		if err := json.Unmarshal(row[i+1], &text); err != nil {
+			return candle, fmt.Errorf("field %d: %w", i+1, err)
+		}
+		parsed, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return candle, fmt.Errorf("field %d: %w", i+1, err)
+		}
+		*value = parsed
+	}
+
+	return candle, nil
+}
+
+// klineInterval returns the Binance name of the kline interval, e.g. 5m
+func klineInterval(interval time.Duration) (string, bool) {
+	switch interval {
+	case time.Minute, 3 * time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute:
+		return strconv.Itoa(int(interval/time.Minute)) + "m", true
+	case time.Hour, 2 * time.Hour, 4 * time.Hour, 6 * time.Hour, 8 * time.Hour, 12 * time.Hour:
+		return strconv.Itoa(int(interval/time.Hour)) + "h", true
+	case 24 * time.Hour:
+		return "1d", true
+	default:
+		return "", false
+	}
+}
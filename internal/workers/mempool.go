@@ -0,0 +1,138 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/rpcmanager"
+)
+
+// MempoolDepositService принимает предварительные депозиты из мемпула.
+// Это только UX событие: подтверждение и зачисление выполняются по блокам.
+type MempoolDepositService interface {
+	RecordMempoolDeposit(ctx context.Context, deposit entities.MempoolDeposit) error
+	ForgetMempoolDeposit(txHash string)
+}
+
+// SubscribeToMempool подписывается на полные pending транзакции (eth_subscribe newPendingTransactions с full=true)
+// и сообщает о переводах USDT на наши кошельки. Многие публичные ноды подписку не поддерживают —
+// в этом случае мониторинг мемпула отключается, сканирование блоков продолжает работать.
+func (bsc *BinanceSmartChain) SubscribeToMempool(ctx context.Context) {
+	if bsc.mempool == nil {
+		return
+	}
+
+	for {
+		err := bsc.subscribeToPendingTransactions(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		bsc.logger.WarnContext(ctx, "Mempool subscription failed, retrying...",
+			"delay", subscriptionRetryDelay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(subscriptionRetryDelay):
+		}
+	}
+}
+
+func (bsc *BinanceSmartChain) subscribeToPendingTransactions(ctx context.Context) error {
+	var lastErr error
+
+	for _, endpoint := range GetBSCWebSocketEndpoints() {
+		rpcClient, err := rpcmanager.DialRPC(ctx, endpoint)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		pending := make(chan *types.Transaction, 256)
+		subscription, err := rpcClient.EthSubscribe(ctx, pending, "newPendingTransactions", true)
+		if err != nil {
+			bsc.logger.InfoContext(ctx, "Endpoint does not support pending transaction subscription",
+				"endpoint", endpoint, "error", err)
+			rpcClient.Close()
+			lastErr = err
+			continue
+		}
+
+		bsc.logger.InfoContext(ctx, "Subscribed to pending transactions", "endpoint", endpoint)
+		err = bsc.watchMempool(ctx, subscription.Err(), pending)
+		subscription.Unsubscribe()
+		rpcClient.Close()
+		return err
+	}
+
+	return fmt.Errorf("no endpoint supports pending transaction subscription: %w", lastErr)
+}
+
+func (bsc *BinanceSmartChain) watchMempool(ctx context.Context, subErr <-chan error, pending <-chan *types.Transaction) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-subErr:
+			return fmt.Errorf("pending transaction subscription error: %w", err)
+		case tx := <-pending:
+			bsc.processPendingTransaction(ctx, tx)
+		}
+	}
+}
+
+func (bsc *BinanceSmartChain) processPendingTransaction(ctx context.Context, tx *types.Transaction) {
+	recipient, amount, ok := parseUSDTTransfer(tx)
+	if !ok {
+		return
+	}
+
+	recipientAddr := recipient.Hex()
+	isOurWallet, err := bsc.wallets.IsOurWallet(ctx, recipientAddr)
+	if err != nil || !isOurWallet {
+		return
+	}
+
+	var from string
+	if sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		from = sender.Hex()
+	}
+
+	err = bsc.mempool.RecordMempoolDeposit(ctx, entities.MempoolDeposit{
+		TxHash: tx.Hash().Hex(),
+		From:   from,
+		To:     recipientAddr,
+		Amount: amount.String(),
+		SeenAt: time.Now(),
+	})
+	if err != nil {
+		bsc.logger.ErrorContext(ctx, "Failed to record mempool deposit",
+			"error", err,
+			"tx_hash", tx.Hash().Hex(),
+			"to", recipientAddr)
+	}
+}
+
+// parseUSDTTransfer разбирает вызов transfer(address,uint256) USDT контракта
+func parseUSDTTransfer(tx *types.Transaction) (common.Address, *big.Int, bool) {
+	if tx.To() == nil || tx.To().Hex() != USDTContractAddress {
+		return common.Address{}, nil, false
+	}
+
+	data := tx.Data()
+	if len(data) < 4+32+32 || !bytes.Equal(data[:4], transferSig) {
+		return common.Address{}, nil, false
+	}
+
+	recipient := common.BytesToAddress(data[4+12 : 36])
+	amount := new(big.Int).SetBytes(data[36:68])
+	return recipient, amount, true
+}
@@ -6,10 +6,11 @@ import (
 	"time"
 )
 
-// OrderCleaner worker automatically removes old pending orders
+// OrderCleaner worker automatically expires old pending orders
 type OrderCleaner struct {
 	logger       *slog.Logger
 	orderService OrderService
+	tracker      WorkerTracker
 
 	// Duration after which orders are considered old and should be removed
 	expirationDuration time.Duration
@@ -24,10 +25,12 @@ func NewOrderCleaner(
 	orderService OrderService,
 	expirationDuration time.Duration,
 	cleanupInterval time.Duration,
+	tracker WorkerTracker,
 ) *OrderCleaner {
 	return &OrderCleaner{
 		logger:             logger,
 		orderService:       orderService,
+		tracker:            tracker,
 		expirationDuration: expirationDuration,
 		cleanupInterval:    cleanupInterval,
 	}
@@ -67,14 +70,15 @@ func (oc *OrderCleaner) cleanupOldOrders(ctx context.Context) error {
 
 	// Remove orders older than the specified duration
 	count, err := oc.orderService.RemoveOldOrders(ctx, oc.expirationDuration)
+	oc.tracker.Done(int(count), err)
 	if err != nil {
 		return err
 	}
 
 	if count > 0 {
-		oc.logger.Info("Removed old orders", "count", count, "older_than", oc.expirationDuration.String())
+		oc.logger.Info("Expired old orders", "count", count, "older_than", oc.expirationDuration.String())
 	} else {
-		oc.logger.Debug("No old orders to remove")
+		oc.logger.Debug("No old orders to expire")
 	}
 
 	return nil
@@ -0,0 +1,45 @@
+package workers
+
+import (
+	"context"
+	"errors"
+)
+
+// errScannerFailover отменяет подписку сканера по команде оператора
+var errScannerFailover = errors.New("block scanner failover requested")
+
+// CurrentEndpoint returns the WebSocket endpoint the block scanner is subscribed through, empty while it is
+// not connected
+func (bsc *BinanceSmartChain) CurrentEndpoint() string {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+	return bsc.currentEndpoint
+}
+
+// Failover drops the current subscription and moves the scanner to the next WebSocket endpoint, catching up
+// missed blocks as after any reconnect. It reports false when the scanner is not running.
+func (bsc *BinanceSmartChain) Failover() bool {
+	bsc.mu.Lock()
+	cancel := bsc.cancelAttempt
+	bsc.mu.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+	cancel(errScannerFailover)
+	return true
+}
+
+func (bsc *BinanceSmartChain) setAttempt(cancel context.CancelCauseFunc) {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+
+	bsc.cancelAttempt = cancel
+	bsc.currentEndpoint = ""
+}
+
+func (bsc *BinanceSmartChain) setCurrentEndpoint(endpoint string) {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+	bsc.currentEndpoint = endpoint
+}
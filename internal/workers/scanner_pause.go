@@ -0,0 +1,147 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/internal/entities"
+)
+
+// Как часто последний обработанный блок сохраняется в базу во время сканирования
+const checkpointInterval = 15 * time.Second
+
+// errScannerPaused отменяет подписку сканера, приостановленного оператором
+var errScannerPaused = errors.New("block scanner paused")
+
+// ScannerStates хранит контрольную точку сканера и ручную паузу между перезапусками
+type ScannerStates interface {
+	FindState(ctx context.Context, chain entities.Chain) (*entities.ScannerState, error)
+	SaveCheckpoint(ctx context.Context, chain entities.Chain, block uint64) error
+}
+
+// loadState restores the last processed block and the pause from the saved scanner state.
+// Without a saved state the scanner starts from the chain head.
+func (bsc *BinanceSmartChain) loadState(ctx context.Context) {
+	state, err := bsc.states.FindState(ctx, entities.ChainBSC)
+	if err != nil {
+		bsc.logger.WarnContext(ctx, "Failed to load block scanner checkpoint, starting from chain head", "error", err)
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	bsc.mu.Lock()
+	if state.LastBlock != nil && *state.LastBlock > 0 {
+		bsc.lastProcessedBlock = uint64(*state.LastBlock)
+		bsc.checkpointBlock = bsc.lastProcessedBlock
+	}
+	bsc.mu.Unlock()
+
+	if state.Paused {
+		bsc.Pause()
+	}
+	bsc.logger.InfoContext(ctx, "Block scanner checkpoint loaded", "block", state.LastBlock, "paused", state.Paused)
+}
+
+// saveCheckpoint stores the last processed block if it advanced, at most once per checkpoint interval unless forced
+func (bsc *BinanceSmartChain) saveCheckpoint(ctx context.Context, force bool) {
+	bsc.mu.Lock()
+	block := bsc.lastProcessedBlock
+	due := force || time.Since(bsc.checkpointAt) >= checkpointInterval
+	if block == 0 || block == bsc.checkpointBlock || !due {
+		bsc.mu.Unlock()
+		return
+	}
+	bsc.mu.Unlock()
+
+	// Контрольная точка сохраняется и после отмены подписки
+	if err := bsc.states.SaveCheckpoint(context.WithoutCancel(ctx), entities.ChainBSC, block); err != nil {
+		bsc.logger.WarnContext(ctx, "Failed to save block scanner checkpoint", "error", err, "block", block)
+		return
+	}
+
+	bsc.mu.Lock()
+	bsc.checkpointBlock = block
+	bsc.checkpointAt = time.Now()
+	bsc.mu.Unlock()
+}
+
+// Paused reports whether the scanner is paused by an operator
+func (bsc *BinanceSmartChain) Paused() bool {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+	return bsc.resumed != nil
+}
+
+// Pause stops block processing and crediting of deposits until Resume. The current subscription is dropped,
+// a block being processed is not marked and is processed again after resume.
+func (bsc *BinanceSmartChain) Pause() {
+	bsc.mu.Lock()
+	if bsc.resumed != nil {
+		bsc.mu.Unlock()
+		return
+	}
+	bsc.resumed = make(chan struct{})
+	cancel := bsc.cancelAttempt
+	bsc.mu.Unlock()
+
+	if cancel != nil {
+		cancel(errScannerPaused)
+	}
+}
+
+// Resume restarts the scanner from the checkpoint. Blocks produced during the pause are backfilled
+// regardless of the reconnect backfill limit. It reports false when the scanner is not paused.
+func (bsc *BinanceSmartChain) Resume() bool {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+
+	if bsc.resumed == nil {
+		return false
+	}
+	close(bsc.resumed)
+	bsc.resumed = nil
+	bsc.resumeBackfill = true
+	return true
+}
+
+// waitWhilePaused blocks while the scanner is paused, reporting to the worker registry that it is alive.
+// Returns false when ctx is cancelled.
+func (bsc *BinanceSmartChain) waitWhilePaused(ctx context.Context) bool {
+	ticker := time.NewTicker(scannerWatchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		bsc.mu.Lock()
+		resumed := bsc.resumed
+		bsc.mu.Unlock()
+		if resumed == nil {
+			return ctx.Err() == nil
+		}
+
+		bsc.tracker.Beat()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-resumed:
+			bsc.logger.InfoContext(ctx, "Block scanner resumed")
+		case <-ticker.C:
+		}
+	}
+}
+
+// backfillingAfterResume reports whether the scanner still catches up blocks produced during a pause
+func (bsc *BinanceSmartChain) backfillingAfterResume() bool {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+	return bsc.resumeBackfill
+}
+
+// finishResumeBackfill is called once the scanner processed a block of the chain head after resume
+func (bsc *BinanceSmartChain) finishResumeBackfill() {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+	bsc.resumeBackfill = false
+}
@@ -0,0 +1,117 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+)
+
+// Интервал, с которым сторож сверяет последний обработанный блок с головой сети
+const scannerWatchdogInterval = 30 * time.Second
+
+// maxReconnectBackfill — сколько пропущенных блоков догоняется после переподключения.
+// При большем разрыве сканер начинает с головы сети, пропущенный диапазон логируется.
+const maxReconnectBackfill = 2000
+
+// errScannerStalled отменяет подписку сканера, отставшего от сети или переставшего получать блоки
+var errScannerStalled = errors.New("block scanner stalled")
+
+// Метрики отставания сканера, публикуются на /metrics
+var (
+	headLagBlocks       = expvar.NewInt("bsc_head_lag_blocks")
+	lastBlockAgeSeconds = expvar.NewInt("bsc_last_block_age_seconds")
+	scannerRestarts     = expvar.NewInt("bsc_scanner_restarts")
+)
+
+// markBlockProcessed advances the last processed block and the time of the last progress
+func (bsc *BinanceSmartChain) markBlockProcessed(blockNumber uint64) {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+
+	if blockNumber > bsc.lastProcessedBlock {
+		bsc.lastProcessedBlock = blockNumber
+	}
+	bsc.lastProgressAt = time.Now()
+}
+
+func (bsc *BinanceSmartChain) lastProcessed() uint64 {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+	return bsc.lastProcessedBlock
+}
+
+// LastHeartbeat returns the time the scanner last processed a block or (re)connected
+func (bsc *BinanceSmartChain) LastHeartbeat() time.Time {
+	bsc.mu.Lock()
+	defer bsc.mu.Unlock()
+
+	return bsc.lastProgressAt
+}
+
+// watchScanner compares the last processed block with the chain head reported by an HTTP endpoint
+// and cancels the subscription when the lag exceeds the threshold or no block was processed for the stall timeout.
+// The subscription loop then reconnects to the next WebSocket endpoint.
+func (bsc *BinanceSmartChain) watchScanner(ctx context.Context, cancel context.CancelCauseFunc) {
+	maxLag := bsc.config.Blockchain.MaxHeadLag
+	stallTimeout := time.Duration(bsc.config.Blockchain.ScannerStallTimeout) * time.Minute
+
+	ticker := time.NewTicker(scannerWatchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		bsc.mu.Lock()
+		lastProcessed := bsc.lastProcessedBlock
+		idle := time.Since(bsc.lastProgressAt)
+		bsc.mu.Unlock()
+
+		lastBlockAgeSeconds.Set(int64(idle.Seconds()))
+
+		var lag uint64
+		if head, err := bsc.chainHead(ctx); err != nil {
+			bsc.logger.WarnContext(ctx, "Failed to get chain head for scanner lag", "error", err)
+		} else {
+			if head > lastProcessed {
+				lag = head - lastProcessed
+			}
+			headLagBlocks.Set(int64(lag))
+			bsc.tracker.SetLag(int64(lag))
+		}
+
+		switch {
+		// Догоняющий после паузы сканер отстает от сети, пока обрабатывает блоки, сторож следит только за остановкой
+		case maxLag > 0 && lag > maxLag && !bsc.backfillingAfterResume():
+			bsc.logger.ErrorContext(ctx, "Block scanner lags behind chain head, restarting",
+				"lag", lag,
+				"max_lag", maxLag,
+				"last_processed", lastProcessed)
+		case stallTimeout > 0 && idle > stallTimeout:
+			bsc.logger.ErrorContext(ctx, "Block scanner processed no blocks, restarting",
+				"idle", idle.Round(time.Second).String(),
+				"stall_timeout", stallTimeout.String(),
+				"last_processed", lastProcessed)
+		default:
+			continue
+		}
+
+		scannerRestarts.Add(1)
+		cancel(errScannerStalled)
+		return
+	}
+}
+
+func (bsc *BinanceSmartChain) chainHead(ctx context.Context) (uint64, error) {
+	client, err := getHTTPClient(ctx, bsc.logger)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	return client.BlockNumber(ctx)
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+DROP INDEX IF EXISTS idx_audit_log_subject;
+DROP INDEX IF EXISTS idx_audit_log_event_type;
+
+DROP TABLE IF EXISTS audit_log;
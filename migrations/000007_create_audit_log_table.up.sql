@@ -0,0 +1,13 @@
+-- Журнал аудита операций, связанных с ключами и движением средств
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_event_type ON audit_log(event_type);
+CREATE INDEX IF NOT EXISTS idx_audit_log_subject ON audit_log(subject);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
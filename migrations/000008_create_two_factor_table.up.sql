@@ -0,0 +1,9 @@
+-- Секреты TOTP для двухфакторной аутентификации пользователей
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id BIGINT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP WITH TIME ZONE
+);
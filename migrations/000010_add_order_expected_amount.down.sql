@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_wallet_expected_amount;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS expected_amount;
@@ -0,0 +1,8 @@
+-- Уникальная сумма к оплате для ордеров на переиспользуемых кошельках (например, 100.0037 USDT)
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS expected_amount VARCHAR(255);
+
+-- Сумма должна быть уникальной среди ожидающих оплаты ордеров одного кошелька
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_wallet_expected_amount
+    ON orders(wallet_id, expected_amount)
+    WHERE status = 'pending' AND expected_amount IS NOT NULL;
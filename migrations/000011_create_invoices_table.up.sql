@@ -0,0 +1,22 @@
+-- Счета мерчантов: фиатная сумма, принимаемые активы и публичная страница статуса
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY,
+    merchant_id BIGINT NOT NULL,
+    fiat_amount VARCHAR(64) NOT NULL,
+    fiat_currency VARCHAR(8) NOT NULL,
+    accepted_assets TEXT[] NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'open',
+    status_token VARCHAR(64) NOT NULL UNIQUE,
+    selected_asset VARCHAR(16),
+    crypto_amount VARCHAR(64),
+    quote_rate VARCHAR(64),
+    order_id INTEGER REFERENCES orders(id) ON DELETE SET NULL,
+    wallet_address VARCHAR(42),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_merchant_id ON invoices(merchant_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_status ON invoices(status);
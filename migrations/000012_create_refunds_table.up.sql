@@ -0,0 +1,25 @@
+-- Адрес отправителя депозита нужен для возврата средств
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS from_address VARCHAR(42) NOT NULL DEFAULT '';
+
+-- Возвраты отклоненных AML и переплаченных депозитов
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    deposit_tx_hash VARCHAR(255) NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(255) NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    refund_tx_hash VARCHAR(255),
+    error TEXT,
+    initiated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Не больше одного возврата по одной причине для депозита
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_deposit_reason ON refunds(deposit_tx_hash, reason);
+CREATE INDEX IF NOT EXISTS idx_refunds_user_id ON refunds(user_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON refunds(status);
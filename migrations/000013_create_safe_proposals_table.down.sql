@@ -0,0 +1 @@
+DROP TABLE IF EXISTS safe_proposals;
@@ -0,0 +1,21 @@
+-- Предложения транзакций multisig казначейства (Gnosis Safe), ожидающие подписей владельцев
+CREATE TABLE IF NOT EXISTS safe_proposals (
+    id UUID PRIMARY KEY,
+    safe_address VARCHAR(42) NOT NULL,
+    safe_tx_hash VARCHAR(66) NOT NULL UNIQUE,
+    nonce BIGINT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    from_wallet_id INTEGER,
+    to_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'proposed',
+    confirmations INTEGER NOT NULL DEFAULT 0,
+    confirmations_required INTEGER NOT NULL DEFAULT 0,
+    executed_tx_hash VARCHAR(66),
+    initiated_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_safe_proposals_status ON safe_proposals(status);
+CREATE INDEX IF NOT EXISTS idx_safe_proposals_safe_nonce ON safe_proposals(safe_address, nonce);
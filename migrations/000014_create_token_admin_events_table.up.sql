@@ -0,0 +1,19 @@
+-- Административные события контрактов токенов (пауза, черный список, изменение параметров).
+-- Неподтвержденные события с halting = TRUE приостанавливают выводы и свипы токена.
+CREATE TABLE IF NOT EXISTS token_admin_events (
+    id BIGSERIAL PRIMARY KEY,
+    token_address VARCHAR(42) NOT NULL,
+    event_name VARCHAR(64) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL,
+    subject VARCHAR(42),
+    halting BOOLEAN NOT NULL DEFAULT FALSE,
+    acknowledged_by VARCHAR(255),
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_admin_events_unacknowledged ON token_admin_events(token_address)
+    WHERE halting AND acknowledged_at IS NULL;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_wallets_chain;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS unique_chain_user_wallet_index;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS unique_chain_address;
+
+ALTER TABLE wallets
+    ADD CONSTRAINT wallets_address_key UNIQUE (address),
+    ADD CONSTRAINT unique_user_wallet_index UNIQUE (user_id, wallet_index);
+
+ALTER TABLE wallets
+    DROP COLUMN IF EXISTS address_format,
+    DROP COLUMN IF EXISTS network,
+    DROP COLUMN IF EXISTS chain;
@@ -0,0 +1,17 @@
+-- Кошельки разных сетей в одной таблице: сеть, окружение (mainnet/testnet/devnet...) и формат адреса.
+-- Уникальность адреса и индекса пользователя теперь в пределах сети.
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS chain VARCHAR(32) NOT NULL DEFAULT 'bsc',
+    ADD COLUMN IF NOT EXISTS network VARCHAR(32) NOT NULL DEFAULT 'mainnet',
+    ADD COLUMN IF NOT EXISTS address_format VARCHAR(32) NOT NULL DEFAULT 'evm_hex';
+
+UPDATE wallets SET network = 'testnet' WHERE is_testnet;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_address_key;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS unique_user_wallet_index;
+
+ALTER TABLE wallets
+    ADD CONSTRAINT unique_chain_address UNIQUE (chain, network, address),
+    ADD CONSTRAINT unique_chain_user_wallet_index UNIQUE (chain, network, user_id, wallet_index);
+
+CREATE INDEX IF NOT EXISTS idx_wallets_chain ON wallets (chain, network);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_orders_asset_id;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS asset_id;
+
+DROP TABLE IF EXISTS assets;
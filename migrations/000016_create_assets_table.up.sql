@@ -0,0 +1,40 @@
+-- Реестр активов: контракт, точность, лимиты ордеров, комиссия вывода и AML порог по сети.
+CREATE TABLE IF NOT EXISTS assets (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(16) NOT NULL,
+    chain VARCHAR(32) NOT NULL,
+    network VARCHAR(32) NOT NULL,
+    contract_address VARCHAR(128) NOT NULL DEFAULT '',
+    decimals INTEGER NOT NULL CHECK (decimals BETWEEN 0 AND 36),
+    min_order_amount VARCHAR(78) NOT NULL DEFAULT '0',
+    max_order_amount VARCHAR(78),
+    withdrawal_fee VARCHAR(78) NOT NULL DEFAULT '0',
+    aml_threshold VARCHAR(78) NOT NULL DEFAULT '5000',
+    deposits_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    withdrawals_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (code, chain, network)
+);
+
+-- USDT (BEP-20) использует 18 знаков на BSC
+INSERT INTO assets (code, chain, network, contract_address, decimals, min_order_amount)
+VALUES
+    ('USDT', 'bsc', 'mainnet', '0x55d398326f99059fF775485246999027B3197955', 18, '1'),
+    ('USDT', 'bsc', 'testnet', '0x337610d27c682E347C9cD60BD4b3b107C9d34dDd', 18, '1')
+ON CONFLICT (code, chain, network) DO NOTHING;
+
+-- Ордер ссылается на актив, существующие ордера относятся к USDT сети своего кошелька
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS asset_id INTEGER REFERENCES assets(id);
+
+UPDATE orders o
+SET asset_id = a.id
+FROM wallets w, assets a
+WHERE o.asset_id IS NULL
+  AND w.id = o.wallet_id
+  AND a.code = 'USDT'
+  AND a.chain = w.chain
+  AND a.network = w.network;
+
+CREATE INDEX IF NOT EXISTS idx_orders_asset_id ON orders(asset_id);
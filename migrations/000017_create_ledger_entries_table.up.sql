@@ -0,0 +1,23 @@
+-- Журнал исходящих транзакций платформы: выводы, свипы, возвраты и служебные переводы
+-- с комиссией платформы и фактически потраченным газом для отчетов P&L
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY,
+    asset_id INTEGER NOT NULL REFERENCES assets(id),
+    kind VARCHAR(32) NOT NULL,
+    operation VARCHAR(32) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL UNIQUE,
+    from_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL DEFAULT '0',
+    fee VARCHAR(78) NOT NULL DEFAULT '0',
+    gas_price VARCHAR(78) NOT NULL,
+    gas_limit BIGINT NOT NULL,
+    gas_used BIGINT,
+    gas_cost VARCHAR(78),
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    settled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_status ON ledger_entries(status);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_created_at ON ledger_entries(created_at);
@@ -0,0 +1,11 @@
+-- Исполненные запросы на удаление персональных данных (GDPR, статья 17).
+-- Финансовые записи пользователя сохраняются для комплаенса, обезличиваются только персональные поля.
+CREATE TABLE IF NOT EXISTS user_erasures (
+    user_id BIGINT PRIMARY KEY,
+    requested_by VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    sessions_anonymized INTEGER NOT NULL DEFAULT 0,
+    audit_events_redacted INTEGER NOT NULL DEFAULT 0,
+    two_factor_removed BOOLEAN NOT NULL DEFAULT FALSE,
+    erased_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
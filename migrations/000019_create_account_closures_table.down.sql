@@ -0,0 +1,2 @@
+ALTER TABLE wallets DROP COLUMN IF EXISTS archived_at;
+DROP TABLE IF EXISTS account_closures;
@@ -0,0 +1,23 @@
+-- Закрытие аккаунта по запросу пользователя: свип балансов, архивация кошельков, отзыв сессий
+-- и отложенное обезличивание персональных данных после срока хранения
+CREATE TABLE IF NOT EXISTS account_closures (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL UNIQUE,
+    status VARCHAR(32) NOT NULL DEFAULT 'requested',
+    reason TEXT NOT NULL DEFAULT '',
+    wallets_archived INTEGER NOT NULL DEFAULT 0,
+    sessions_revoked INTEGER NOT NULL DEFAULT 0,
+    last_sweep_at TIMESTAMP WITH TIME ZONE,
+    error TEXT,
+    erase_after TIMESTAMP WITH TIME ZONE,
+    erased_at TIMESTAMP WITH TIME ZONE,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_closures_status ON account_closures(status);
+
+-- Кошельки закрытых аккаунтов остаются под наблюдением, но больше не выдаются для ордеров
+ALTER TABLE wallets
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_not_credited;
+DROP INDEX IF EXISTS idx_transactions_created_at;
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS credited_at,
+DROP COLUMN IF EXISTS confirmed_at;
@@ -0,0 +1,7 @@
+-- Время подтверждения и зачисления депозита: задержка от обнаружения (created_at) отслеживается по SLA
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS credited_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_not_credited ON transactions(created_at) WHERE NOT processed;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_withdrawal_tiers;
+DROP TABLE IF EXISTS withdrawals;
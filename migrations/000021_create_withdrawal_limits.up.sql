@@ -0,0 +1,28 @@
+-- Выводы пользователей для лимитов скорости: сумма за скользящий час и сутки по пользователю
+-- и общий отток с горячих кошельков
+CREATE TABLE IF NOT EXISTS withdrawals (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    wallet_id INTEGER NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    hot BOOLEAN NOT NULL DEFAULT TRUE,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66),
+    safe_tx_hash VARCHAR(66),
+    error TEXT,
+    initiated_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user_created ON withdrawals(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_created ON withdrawals(created_at);
+
+-- Уровень лимитов пользователя, назначенный администратором; без записи действует уровень по умолчанию
+CREATE TABLE IF NOT EXISTS user_withdrawal_tiers (
+    user_id BIGINT PRIMARY KEY,
+    tier VARCHAR(32) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Число подтверждений зависит от суммы депозита: требуемое фиксируется при обнаружении,
+-- фактическое — при подтверждении. Зачисление проверяет оба значения
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS required_confirmations INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS confirmations INTEGER NOT NULL DEFAULT 0;
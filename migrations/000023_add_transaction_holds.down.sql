@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_transactions_on_hold;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS released_by,
+DROP COLUMN IF EXISTS released_at,
+DROP COLUMN IF EXISTS held_at,
+DROP COLUMN IF EXISTS hold_reason,
+DROP COLUMN IF EXISTS on_hold;
@@ -0,0 +1,9 @@
+-- Удержание необычно крупных депозитов до ручного освобождения комплаенсом, отдельно от AML статуса
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS on_hold BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS hold_reason TEXT,
+ADD COLUMN IF NOT EXISTS held_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS released_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS released_by VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_on_hold ON transactions(held_at) WHERE on_hold;
@@ -0,0 +1,5 @@
+-- Пустые кошельки после срока хранения выводятся из мониторинга; запись кошелька сохраняется
+ALTER TABLE wallets
+ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_wallets_active ON wallets(address) WHERE retired_at IS NULL;
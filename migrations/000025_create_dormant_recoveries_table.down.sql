@@ -0,0 +1 @@
+DROP TABLE IF EXISTS dormant_recoveries;
@@ -0,0 +1,16 @@
+-- Остатки на неактивных депозитных кошельках без ожидающих ордеров: собранные свипом или переданные на возврат
+CREATE TABLE IF NOT EXISTS dormant_recoveries (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    user_id BIGINT NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    tx_hash VARCHAR(66),
+    refund_id UUID,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dormant_recoveries_created ON dormant_recoveries(created_at);
+CREATE INDEX IF NOT EXISTS idx_dormant_recoveries_wallet ON dormant_recoveries(wallet_id, created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_unsettled;
+ALTER TABLE orders DROP COLUMN IF EXISTS settlement_id;
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS merchant_settlement_accounts;
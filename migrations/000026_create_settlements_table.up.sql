@@ -0,0 +1,39 @@
+-- Адрес, на который выплачиваются расчеты мерчанта, и индивидуальная комиссия (NULL — комиссия по умолчанию)
+CREATE TABLE IF NOT EXISTS merchant_settlement_accounts (
+    merchant_id BIGINT PRIMARY KEY,
+    address VARCHAR(42) NOT NULL,
+    fee_bps INTEGER CHECK (fee_bps IS NULL OR (fee_bps >= 0 AND fee_bps <= 10000)),
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Пакеты выплат мерчантам: сумма завершенных ордеров за вычетом комиссии платформы (минимальные единицы актива)
+CREATE TABLE IF NOT EXISTS settlements (
+    id UUID PRIMARY KEY,
+    merchant_id BIGINT NOT NULL,
+    asset_id INTEGER NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    orders INTEGER NOT NULL,
+    gross_amount VARCHAR(78) NOT NULL,
+    fee_amount VARCHAR(78) NOT NULL,
+    net_amount VARCHAR(78) NOT NULL,
+    fee_bps INTEGER NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66),
+    safe_tx_hash VARCHAR(66),
+    error TEXT,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_merchant ON settlements(merchant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_settlements_status ON settlements(status);
+
+-- Ордер входит не больше чем в один пакет; при неудачной выплате ордера освобождаются для следующего пакета
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS settlement_id UUID REFERENCES settlements(id);
+
+CREATE INDEX IF NOT EXISTS idx_orders_unsettled ON orders(user_id, updated_at) WHERE status = 'completed' AND settlement_id IS NULL;
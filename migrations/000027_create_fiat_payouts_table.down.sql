@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fiat_ledger_postings;
+DROP TABLE IF EXISTS fiat_payouts;
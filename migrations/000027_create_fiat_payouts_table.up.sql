@@ -0,0 +1,40 @@
+-- Фиатные выплаты продавцам за завершенные ордера через провайдеров (банковский перевод, SEPA, СБП)
+CREATE TABLE IF NOT EXISTS fiat_payouts (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    method VARCHAR(32) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    asset VARCHAR(16) NOT NULL,
+    crypto_amount VARCHAR(64) NOT NULL,
+    rate VARCHAR(64) NOT NULL,
+    fiat_amount VARCHAR(64) NOT NULL,
+    currency VARCHAR(8) NOT NULL,
+    destination JSONB NOT NULL,
+    provider_ref VARCHAR(255),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Ордер оплачивается не больше одной действующей выплатой, после неудачи можно запросить новую
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fiat_payouts_order_active ON fiat_payouts(order_id) WHERE status <> 'failed';
+CREATE INDEX IF NOT EXISTS idx_fiat_payouts_user ON fiat_payouts(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_fiat_payouts_status ON fiat_payouts(status);
+
+-- Проводки по выплатам: seller:<id> -> payouts_in_transit:<method> при создании,
+-- payouts_in_transit:<method> -> provider:<method> при исполнении и обратная проводка при неудаче
+CREATE TABLE IF NOT EXISTS fiat_ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    payout_id UUID NOT NULL REFERENCES fiat_payouts(id),
+    debit_account VARCHAR(64) NOT NULL,
+    credit_account VARCHAR(64) NOT NULL,
+    amount VARCHAR(64) NOT NULL,
+    currency VARCHAR(8) NOT NULL,
+    memo TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_fiat_ledger_postings_payout ON fiat_ledger_postings(payout_id);
@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_expected_amount_valid;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_amount_valid;
+
+ALTER TABLE orders
+    ALTER COLUMN amount TYPE VARCHAR(255) USING amount::TEXT,
+    ALTER COLUMN expected_amount TYPE VARCHAR(255) USING expected_amount::TEXT;
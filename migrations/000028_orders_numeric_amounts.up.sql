@@ -0,0 +1,16 @@
+-- Суммы ордеров хранятся в NUMERIC вместо произвольных строк: сравнение и суммирование в SQL становятся точными,
+-- а 100.0037 и 100.00370 считаются одной суммой, в том числе в уникальном индексе ожидаемых сумм кошелька.
+-- Приведение упадет на некорректных строках в существующих ордерах: их нужно исправить до миграции.
+ALTER TABLE orders
+    ALTER COLUMN amount TYPE NUMERIC USING amount::NUMERIC,
+    ALTER COLUMN expected_amount TYPE NUMERIC USING expected_amount::NUMERIC;
+
+-- Сумма положительна и представима в минимальных единицах любого актива реестра (decimals до 36) в пределах uint256.
+-- Точность конкретного актива проверяется при создании ордера, ограничение CHECK не может ссылаться на assets.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_amount_valid;
+ALTER TABLE orders ADD CONSTRAINT orders_amount_valid
+    CHECK (amount > 0 AND scale(amount) <= 36 AND amount < 1e42);
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_expected_amount_valid;
+ALTER TABLE orders ADD CONSTRAINT orders_expected_amount_valid
+    CHECK (expected_amount IS NULL OR (expected_amount >= amount AND scale(expected_amount) <= 36 AND expected_amount < 1e42));
@@ -0,0 +1,25 @@
+ALTER TABLE aml_checks DROP CONSTRAINT IF EXISTS fk_aml_checks_transaction;
+
+DROP INDEX IF EXISTS idx_transactions_wallet_id;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_wallet_id_required;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_wallet;
+ALTER TABLE transactions DROP COLUMN IF EXISTS wallet_id;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_wallet;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_wallet FOREIGN KEY (wallet_id)
+    REFERENCES wallets(id)
+    ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_orders_wallet_expected_amount;
+DROP INDEX IF EXISTS idx_orders_unsettled;
+
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN status TYPE VARCHAR(50) USING status::TEXT;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_wallet_expected_amount
+    ON orders(wallet_id, expected_amount)
+    WHERE status = 'pending' AND expected_amount IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_unsettled ON orders(user_id, updated_at) WHERE status = 'completed' AND settlement_id IS NULL;
+
+DROP TYPE IF EXISTS order_status_type;
@@ -0,0 +1,70 @@
+-- Ссылочная целостность между кошельками, ордерами, транзакциями и AML проверками и enum статусов ордеров.
+-- Нарушения ограничений репозитории возвращают как типизированные ошибки (уникальность, внешний ключ, проверка).
+
+-- Статус ордера — перечисление вместо произвольной строки
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'order_status_type') THEN
+        CREATE TYPE order_status_type AS ENUM ('pending', 'completed');
+    END IF;
+END$$;
+
+-- Частичные индексы сравнивают статус со строкой, поэтому пересоздаются после смены типа
+DROP INDEX IF EXISTS idx_orders_wallet_expected_amount;
+DROP INDEX IF EXISTS idx_orders_unsettled;
+
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN status TYPE order_status_type USING status::order_status_type;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_wallet_expected_amount
+    ON orders(wallet_id, expected_amount)
+    WHERE status = 'pending' AND expected_amount IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_unsettled ON orders(user_id, updated_at) WHERE status = 'completed' AND settlement_id IS NULL;
+
+-- Кошелек с ордерами больше не удаляется каскадно вместе с историей ордеров
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_wallet;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_wallet FOREIGN KEY (wallet_id)
+    REFERENCES wallets(id)
+    ON DELETE RESTRICT;
+
+-- Транзакция ссылается на кошелек по id: адрес уникален только в пределах сети.
+-- Адрес EVM может отслеживаться в нескольких сетях, берется самый ранний кошелек, как в FindWalletByAddress.
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS wallet_id BIGINT;
+
+UPDATE transactions t
+SET wallet_id = (SELECT w.id FROM wallets w WHERE w.address = t.wallet_address ORDER BY w.id LIMIT 1)
+WHERE t.wallet_id IS NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_wallet;
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_wallet FOREIGN KEY (wallet_id)
+    REFERENCES wallets(id)
+    ON DELETE RESTRICT;
+
+-- Депозиты на ранее удаленные кошельки остаются без wallet_id, новые записи обязаны ссылаться на кошелек
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_wallet_id_required;
+ALTER TABLE transactions ADD CONSTRAINT transactions_wallet_id_required
+    CHECK (wallet_id IS NOT NULL) NOT VALID;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_id ON transactions(wallet_id);
+
+-- Результат AML проверки относится к записанной транзакции.
+-- Старые результаты могли сохраняться до записи транзакции, поэтому существующие строки не проверяются (NOT VALID).
+ALTER TABLE aml_checks DROP CONSTRAINT IF EXISTS fk_aml_checks_transaction;
+ALTER TABLE aml_checks ADD CONSTRAINT fk_aml_checks_transaction FOREIGN KEY (transaction_hash)
+    REFERENCES transactions(tx_hash)
+    ON DELETE RESTRICT
+    NOT VALID;
+
+-- Уникальность хеша транзакции (000003) и индекса кошелька пользователя в сети (000015) уже задана,
+-- ограничения восстанавливаются, если их удалили вручную
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'transactions_tx_hash_key') THEN
+        ALTER TABLE transactions ADD CONSTRAINT transactions_tx_hash_key UNIQUE (tx_hash);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'unique_chain_user_wallet_index') THEN
+        ALTER TABLE wallets ADD CONSTRAINT unique_chain_user_wallet_index UNIQUE (chain, network, user_id, wallet_index);
+    END IF;
+END$$;
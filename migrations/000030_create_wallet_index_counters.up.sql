@@ -0,0 +1,17 @@
+-- Счетчик индексов кошельков пользователя в сети: индекс выдается атомарным UPSERT под блокировкой строки,
+-- поэтому несколько экземпляров приложения не выдадут один путь деривации
+CREATE TABLE IF NOT EXISTS wallet_index_counters (
+    chain VARCHAR(32) NOT NULL,
+    network VARCHAR(32) NOT NULL,
+    user_id BIGINT NOT NULL,
+    last_index BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chain, network, user_id)
+);
+
+INSERT INTO wallet_index_counters (chain, network, user_id, last_index)
+SELECT chain, network, user_id, MAX(wallet_index)
+FROM wallets
+GROUP BY chain, network, user_id
+ON CONFLICT (chain, network, user_id) DO UPDATE
+    SET last_index = GREATEST(wallet_index_counters.last_index, EXCLUDED.last_index);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_wallets_pool_available;
+
+ALTER TABLE wallets
+DROP COLUMN IF EXISTS pool_claimed_at,
+DROP COLUMN IF EXISTS pool_tier;
@@ -0,0 +1,8 @@
+-- Пул заранее выведенных и зарегистрированных кошельков по уровням пользователей.
+-- Кошелек пула принадлежит служебному аккаунту пула, пока его не заберет ордер: тогда он переходит пользователю.
+ALTER TABLE wallets
+ADD COLUMN IF NOT EXISTS pool_tier VARCHAR(32),
+ADD COLUMN IF NOT EXISTS pool_claimed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_wallets_pool_available ON wallets(chain, network, pool_tier, id)
+    WHERE pool_tier IS NOT NULL AND pool_claimed_at IS NULL AND retired_at IS NULL;
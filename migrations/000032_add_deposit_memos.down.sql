@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_orders_wallet_memo;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS memo;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS memo;
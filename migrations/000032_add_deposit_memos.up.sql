@@ -0,0 +1,11 @@
+-- Мемо (тег) депозита: позволяет принимать оплату нескольких ордеров на общий адрес
+-- и сопоставлять перевод с ордером по мемо, как на биржах и в сетях XRP/TON/EOS
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS memo VARCHAR(64);
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS memo VARCHAR(64);
+
+-- Мемо уникально среди ожидающих ордеров кошелька, иначе перевод нельзя однозначно сопоставить
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_wallet_memo ON orders(wallet_id, memo)
+    WHERE status = 'pending' AND memo IS NOT NULL;
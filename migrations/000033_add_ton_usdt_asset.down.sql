@@ -0,0 +1,2 @@
+DELETE FROM assets
+WHERE code = 'USDT' AND chain = 'ton' AND network = 'mainnet';
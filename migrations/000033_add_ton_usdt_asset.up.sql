@@ -0,0 +1,5 @@
+-- USDT (жетон) в сети TON: 6 знаков, адрес мастер-контракта в raw форме
+INSERT INTO assets (code, chain, network, contract_address, decimals, min_order_amount)
+VALUES
+    ('USDT', 'ton', 'mainnet', '0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe', 6, '1')
+ON CONFLICT (code, chain, network) DO NOTHING;
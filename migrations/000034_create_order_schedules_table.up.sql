@@ -0,0 +1,21 @@
+-- Регулярные ордера: шаблон (сумма, описание) и cron-расписание в UTC, например ежемесячное выставление счета.
+-- Планировщик создает ордер при наступлении next_run_at и переносит его на следующую активацию расписания
+CREATE TABLE IF NOT EXISTS order_schedules (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    amount NUMERIC NOT NULL CHECK (amount > 0 AND scale(amount) <= 36 AND amount < 1e42),
+    description TEXT NOT NULL DEFAULT '',
+    cron VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'cancelled', 'finished')),
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    -- После max_occurrences созданных ордеров расписание завершается, NULL — без ограничения
+    max_occurrences INTEGER CHECK (max_occurrences IS NULL OR max_occurrences > 0),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_schedules_user ON order_schedules(user_id);
+CREATE INDEX IF NOT EXISTS idx_order_schedules_due ON order_schedules(next_run_at) WHERE status = 'active';
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS order_templates;
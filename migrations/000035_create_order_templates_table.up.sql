@@ -0,0 +1,22 @@
+-- Шаблоны ордеров мерчанта: актив, сумма, описание и адрес обратного вызова.
+-- Ордер и платежная ссылка создаются по идентификатору шаблона одним запросом
+CREATE TABLE IF NOT EXISTS order_templates (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    asset_id INTEGER NOT NULL REFERENCES assets(id),
+    -- NULL — сумма указывается при создании ордера, например для пожертвований
+    amount NUMERIC CHECK (amount IS NULL OR (amount > 0 AND scale(amount) <= 36 AND amount < 1e42)),
+    description TEXT NOT NULL DEFAULT '',
+    callback_url TEXT,
+    -- Архивный шаблон не используется для новых ордеров, но остается для ордеров, созданных по нему
+    archived_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_templates_user ON order_templates(user_id);
+
+-- Шаблон, по которому создан ордер: по нему находится адрес обратного вызова
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS template_id INTEGER REFERENCES order_templates(id);
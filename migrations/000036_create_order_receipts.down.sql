@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS receipt_brandings;
+
+DROP TABLE IF EXISTS order_receipts;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS completed_at,
+DROP COLUMN IF EXISTS completed_tx_hash;
@@ -0,0 +1,26 @@
+-- Транзакция, которой оплачен ордер, и время оплаты: выводятся в квитанции
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS completed_tx_hash VARCHAR(255),
+ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+-- Очередь квитанций: строка добавляется при завершении ордера, отправкой занимается ReceiptService
+CREATE TABLE IF NOT EXISTS order_receipts (
+    order_id INTEGER PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_receipts_pending ON order_receipts(created_at) WHERE status = 'pending';
+
+-- Оформление квитанций мерчанта: название и шаблоны темы и текста (text/template), пусто — шаблон по умолчанию
+CREATE TABLE IF NOT EXISTS receipt_brandings (
+    merchant_id BIGINT PRIMARY KEY,
+    brand_name VARCHAR(100) NOT NULL DEFAULT '',
+    subject_template TEXT NOT NULL DEFAULT '',
+    body_template TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
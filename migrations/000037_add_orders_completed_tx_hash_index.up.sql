@@ -0,0 +1,2 @@
+-- Поиск ордера по транзакции оплаты для страницы деталей транзакции
+CREATE INDEX IF NOT EXISTS idx_orders_completed_tx_hash ON orders(completed_tx_hash) WHERE completed_tx_hash IS NOT NULL;
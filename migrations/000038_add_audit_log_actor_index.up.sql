@@ -0,0 +1,2 @@
+-- События аккаунта в ленте активности ищутся и по инициатору (сессии, 2FA)
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
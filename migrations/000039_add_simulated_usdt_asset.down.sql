@@ -0,0 +1,2 @@
+DELETE FROM assets
+WHERE code = 'USDT' AND chain = 'bsc' AND network = 'simulated';
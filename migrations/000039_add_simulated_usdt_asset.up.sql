@@ -0,0 +1,5 @@
+-- Тестовый USDT симулированной сети для режима симуляции (BLOCKCHAIN_SIMULATED), токен размещается в генезисе
+INSERT INTO assets (code, chain, network, contract_address, decimals, min_order_amount)
+VALUES
+    ('USDT', 'bsc', 'simulated', '0x0000000000000000000000000000000000001337', 18, '1')
+ON CONFLICT (code, chain, network) DO NOTHING;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS late_deposits;
+
+-- Значение enum удалить нельзя: просроченные ордера удаляются, как до миграции
+DELETE FROM orders WHERE status = 'expired';
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS reopened_at,
+DROP COLUMN IF EXISTS expired_at;
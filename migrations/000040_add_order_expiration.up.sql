@@ -0,0 +1,23 @@
+-- Просроченные ордера больше не удаляются, а получают статус expired: депозит, пришедший после
+-- истечения ордера, распознается и возвращается отправителю или переоткрывает ордер
+ALTER TYPE order_status_type ADD VALUE IF NOT EXISTS 'expired';
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS expired_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS reopened_at TIMESTAMP WITH TIME ZONE;
+
+-- Поздние депозиты и принятое по ним решение, один депозит обрабатывается один раз
+CREATE TABLE IF NOT EXISTS late_deposits (
+    id SERIAL PRIMARY KEY,
+    tx_hash VARCHAR(66) NOT NULL UNIQUE,
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    user_id BIGINT NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    refund_id UUID,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_late_deposits_order ON late_deposits(order_id);
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS imported;
+
+DROP TABLE IF EXISTS wallet_import_items;
+DROP TABLE IF EXISTS wallet_imports;
@@ -0,0 +1,27 @@
+-- Импорт кошельков из предыдущей системы: кошельки регистрируются сразу, балансы и история депозитов
+-- догружаются фоновой задачей
+CREATE TABLE IF NOT EXISTS wallet_imports (
+    id SERIAL PRIMARY KEY,
+    source VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    from_block BIGINT NOT NULL,
+    wallets_total INTEGER NOT NULL,
+    wallets_imported INTEGER NOT NULL DEFAULT 0,
+    deposits_found INTEGER NOT NULL DEFAULT 0,
+    requested_by VARCHAR(255) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS wallet_import_items (
+    import_id INTEGER NOT NULL REFERENCES wallet_imports(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id),
+    PRIMARY KEY (import_id, wallet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_imports_pending ON wallet_imports(id) WHERE status IN ('pending', 'running');
+
+-- Исторические депозиты импортированных кошельков не зачисляются в ордера повторно
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS imported BOOLEAN NOT NULL DEFAULT FALSE;
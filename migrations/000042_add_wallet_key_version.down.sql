@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_wallets_key_version;
+
+ALTER TABLE wallets
+DROP COLUMN IF EXISTS key_version;
@@ -0,0 +1,6 @@
+-- Версия мастер-сида, из которого выведен ключ кошелька: при ротации сидов новые кошельки выводятся
+-- из активного сида, а кошельки прежних сидов остаются доступными для вывода
+ALTER TABLE wallets
+ADD COLUMN IF NOT EXISTS key_version INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_wallets_key_version ON wallets(key_version);
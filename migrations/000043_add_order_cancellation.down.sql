@@ -0,0 +1,6 @@
+-- Значение enum удалить нельзя: отмененные ордера удаляются, как при DELETE до миграции
+DELETE FROM orders WHERE status = 'cancelled';
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS cancelled_at,
+DROP COLUMN IF EXISTS cancel_reason;
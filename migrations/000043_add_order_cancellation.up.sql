@@ -0,0 +1,6 @@
+-- Ордер, отмененный пользователем до оплаты, сохраняется с кодом причины отмены
+ALTER TYPE order_status_type ADD VALUE IF NOT EXISTS 'cancelled';
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS cancel_reason VARCHAR(32),
+ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_from_amount;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS ignored_reason;
+
+ALTER TABLE assets
+DROP COLUMN IF EXISTS min_deposit_amount;
@@ -0,0 +1,10 @@
+-- Минимальная сумма депозита актива: меньшие переводы (пыль) записываются, но не зачисляются в ордера
+ALTER TABLE assets
+ADD COLUMN IF NOT EXISTS min_deposit_amount VARCHAR(78) NOT NULL DEFAULT '0';
+
+-- Причина, по которой депозит не сопоставляется с ордерами: dust — меньше минимума актива,
+-- spam — серия одинаковых микропереводов от одного отправителя
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS ignored_reason VARCHAR(32);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_from_amount ON transactions(from_address, amount, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_aml_checks_wallet;
+DROP TABLE IF EXISTS wallet_risk_scores;
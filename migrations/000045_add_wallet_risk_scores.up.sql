@@ -0,0 +1,15 @@
+-- Сводный риск депозитного кошелька по AML проверкам всех входящих переводов. Риск пользователя
+-- складывается из рисков его кошельков и ужесточает пороги проверки его следующих депозитов
+CREATE TABLE IF NOT EXISTS wallet_risk_scores (
+    wallet_address VARCHAR(255) PRIMARY KEY,
+    user_id BIGINT,
+    risk_score FLOAT NOT NULL,
+    max_risk_score FLOAT NOT NULL,
+    checks INTEGER NOT NULL,
+    flagged INTEGER NOT NULL,
+    last_checked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_risk_scores_user ON wallet_risk_scores(user_id);
+CREATE INDEX IF NOT EXISTS idx_aml_checks_wallet ON aml_checks(wallet_address);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_ledger_entries_kind_created_at;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS effective_gas_price;
@@ -0,0 +1,9 @@
+-- Фактическая цена газа из квитанции: gas_price хранит предложенную при отправке цену,
+-- по effective_gas_price строится аналитика комиссий для расписания свипов
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS effective_gas_price VARCHAR(78);
+
+UPDATE ledger_entries
+   SET effective_gas_price = (TRUNC(gas_cost::NUMERIC / gas_used))::TEXT
+ WHERE gas_cost IS NOT NULL AND gas_used > 0;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_kind_created_at ON ledger_entries(kind, created_at);
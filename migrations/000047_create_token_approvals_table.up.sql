@@ -0,0 +1,24 @@
+-- ERC-20 approvals, выданные мастер-кошельком контрактам (форвардеры, коллектор, multisend), с лимитом и сроком.
+-- allowance — последний прочитанный из контракта остаток разрешения
+CREATE TABLE IF NOT EXISTS token_approvals (
+    id BIGSERIAL PRIMARY KEY,
+    token VARCHAR(42) NOT NULL,
+    owner VARCHAR(42) NOT NULL,
+    spender VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    allowance VARCHAR(78),
+    purpose VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'active',
+    tx_hash VARCHAR(66) NOT NULL,
+    revoke_tx_hash VARCHAR(66),
+    created_by VARCHAR(255) NOT NULL,
+    revoked_by VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Новый approve перезаписывает разрешение, поэтому у пары токен/spender не больше одного активного
+CREATE UNIQUE INDEX IF NOT EXISTS idx_token_approvals_active ON token_approvals(token, owner, spender) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_token_approvals_status ON token_approvals(status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_confirmed_at;
+DROP TABLE IF EXISTS reconciliation_reports;
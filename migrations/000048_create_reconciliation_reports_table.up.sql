@@ -0,0 +1,15 @@
+-- Ежедневные сверки для бухгалтерии: подписанная сводка депозитов, выводов, комиссий и газа за день (UTC).
+-- payload хранится как отправленный текст, чтобы подпись оставалась проверяемой при повторной отправке
+CREATE TABLE IF NOT EXISTS reconciliation_reports (
+    day DATE PRIMARY KEY,
+    payload TEXT NOT NULL,
+    signature VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliation_reports_undelivered ON reconciliation_reports(day) WHERE delivered_at IS NULL;
+-- Сверка считает депозиты по дате подтверждения
+CREATE INDEX IF NOT EXISTS idx_transactions_confirmed_at ON transactions(confirmed_at);
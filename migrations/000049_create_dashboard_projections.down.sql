@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_orders_aml_flagged;
+DROP INDEX IF EXISTS idx_transactions_aml_flagged;
+DROP INDEX IF EXISTS idx_orders_created_at;
+DROP INDEX IF EXISTS idx_transactions_updated_at;
+DROP INDEX IF EXISTS idx_orders_updated_at;
+DROP TABLE IF EXISTS dashboard_projection_cursors;
+DROP TABLE IF EXISTS dashboard_aml_backlog;
+DROP TABLE IF EXISTS dashboard_deposits_daily;
+DROP TABLE IF EXISTS dashboard_orders_daily;
@@ -0,0 +1,45 @@
+-- Проекции для дашборда: агрегаты поддерживаются фоновым проектором по изменениям исходных таблиц (updated_at),
+-- запросы дашборда читают только их и не нагружают таблицы ордеров и транзакций.
+-- Ордера и депозиты группируются по дню создания, чтобы изменение строки пересчитывало только ее день
+CREATE TABLE IF NOT EXISTS dashboard_orders_daily (
+    day DATE NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    orders INTEGER NOT NULL,
+    PRIMARY KEY (day, status)
+);
+
+CREATE TABLE IF NOT EXISTS dashboard_deposits_daily (
+    day DATE NOT NULL,
+    chain VARCHAR(32) NOT NULL,
+    network VARCHAR(32) NOT NULL,
+    detected INTEGER NOT NULL,
+    confirmed INTEGER NOT NULL,
+    credited INTEGER NOT NULL,
+    ignored INTEGER NOT NULL,
+    volume NUMERIC(78, 0) NOT NULL, -- Сумма подтвержденных депозитов в минимальных единицах
+    PRIMARY KEY (day, chain, network)
+);
+
+-- Текущая очередь AML, одна строка
+CREATE TABLE IF NOT EXISTS dashboard_aml_backlog (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    queued_checks INTEGER NOT NULL,
+    oldest_queued_at TIMESTAMP WITH TIME ZONE,
+    flagged_deposits INTEGER NOT NULL,
+    held_deposits INTEGER NOT NULL,
+    flagged_orders INTEGER NOT NULL,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Позиция проектора в потоке изменений каждой исходной таблицы
+CREATE TABLE IF NOT EXISTS dashboard_projection_cursors (
+    projection VARCHAR(50) PRIMARY KEY,
+    watermark TIMESTAMP WITH TIME ZONE NOT NULL,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_updated_at ON orders(updated_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_updated_at ON transactions(updated_at);
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_aml_flagged ON transactions(id) WHERE aml_status = 'flagged';
+CREATE INDEX IF NOT EXISTS idx_orders_aml_flagged ON orders(id) WHERE aml_status = 'flagged';
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS trg_transaction_events_immutable ON transaction_events;
+DROP TRIGGER IF EXISTS trg_order_events_immutable ON order_events;
+DROP TRIGGER IF EXISTS trg_transactions_events ON transactions;
+DROP TRIGGER IF EXISTS trg_orders_events ON orders;
+DROP FUNCTION IF EXISTS reject_state_event_change();
+DROP FUNCTION IF EXISTS record_transaction_event();
+DROP FUNCTION IF EXISTS record_order_event();
+DROP FUNCTION IF EXISTS state_event_changed_columns(JSONB, JSONB);
+DROP TABLE IF EXISTS transaction_events;
+DROP TABLE IF EXISTS order_events;
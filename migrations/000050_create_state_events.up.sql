@@ -0,0 +1,150 @@
+-- Журналы событий ордеров и транзакций: каждое изменение строки записывается триггером в той же транзакции БД,
+-- поэтому журнал полон независимо от того, какой код изменил строку. state — строка после изменения
+-- (до удаления для deleted), по журналу восстанавливается состояние на любой момент
+CREATE TABLE IF NOT EXISTS order_events (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50),
+    changed TEXT[] NOT NULL DEFAULT '{}',
+    state JSONB NOT NULL,
+    db_tx_id BIGINT NOT NULL DEFAULT txid_current(),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_events_order ON order_events(order_id, id);
+CREATE INDEX IF NOT EXISTS idx_order_events_occurred_at ON order_events(occurred_at);
+
+CREATE TABLE IF NOT EXISTS transaction_events (
+    id BIGSERIAL PRIMARY KEY,
+    tx_hash VARCHAR(255) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    changed TEXT[] NOT NULL DEFAULT '{}',
+    state JSONB NOT NULL,
+    db_tx_id BIGINT NOT NULL DEFAULT txid_current(),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_tx_hash ON transaction_events(tx_hash, id);
+CREATE INDEX IF NOT EXISTS idx_transaction_events_occurred_at ON transaction_events(occurred_at);
+
+-- Измененные колонки строки, кроме updated_at
+CREATE OR REPLACE FUNCTION state_event_changed_columns(old_row JSONB, new_row JSONB) RETURNS TEXT[] AS $$
+    SELECT COALESCE(ARRAY_AGG(n.key ORDER BY n.key), '{}')
+      FROM jsonb_each(new_row) n
+      LEFT JOIN jsonb_each(old_row) o ON o.key = n.key
+     WHERE n.key <> 'updated_at' AND n.value IS DISTINCT FROM o.value;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION record_order_event() RETURNS TRIGGER AS $$
+DECLARE
+    changed_columns TEXT[];
+    event VARCHAR(32);
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO order_events (order_id, event_type, to_status, state)
+        VALUES (NEW.id, 'created', NEW.status::TEXT, to_jsonb(NEW));
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO order_events (order_id, event_type, from_status, state)
+        VALUES (OLD.id, 'deleted', OLD.status::TEXT, to_jsonb(OLD));
+        RETURN OLD;
+    END IF;
+
+    changed_columns := state_event_changed_columns(to_jsonb(OLD), to_jsonb(NEW));
+    IF cardinality(changed_columns) = 0 THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        event := NEW.status::TEXT;
+    ELSIF NEW.aml_status IS DISTINCT FROM OLD.aml_status THEN
+        event := 'aml_' || NEW.aml_status::TEXT;
+    ELSE
+        event := 'updated';
+    END IF;
+
+    INSERT INTO order_events (order_id, event_type, from_status, to_status, changed, state)
+    VALUES (NEW.id, event, OLD.status::TEXT, NEW.status::TEXT, changed_columns, to_jsonb(NEW));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_transaction_event() RETURNS TRIGGER AS $$
+DECLARE
+    changed_columns TEXT[];
+    event VARCHAR(32);
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO transaction_events (tx_hash, event_type, state)
+        VALUES (NEW.tx_hash, 'detected', to_jsonb(NEW));
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO transaction_events (tx_hash, event_type, state)
+        VALUES (OLD.tx_hash, 'deleted', to_jsonb(OLD));
+        RETURN OLD;
+    END IF;
+
+    changed_columns := state_event_changed_columns(to_jsonb(OLD), to_jsonb(NEW));
+    IF cardinality(changed_columns) = 0 THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.ignored_reason IS NOT NULL AND OLD.ignored_reason IS NULL THEN
+        event := 'ignored';
+    ELSIF NEW.processed AND NOT OLD.processed THEN
+        event := 'credited';
+    ELSIF OLD.processed AND NOT NEW.processed THEN
+        event := 'requeued';
+    ELSIF NEW.confirmed AND NOT OLD.confirmed THEN
+        event := 'confirmed';
+    ELSIF NEW.on_hold AND NOT OLD.on_hold THEN
+        event := 'held';
+    ELSIF OLD.on_hold AND NOT NEW.on_hold THEN
+        event := 'released';
+    ELSIF NEW.aml_status IS DISTINCT FROM OLD.aml_status THEN
+        event := 'aml_' || NEW.aml_status::TEXT;
+    ELSE
+        event := 'updated';
+    END IF;
+
+    INSERT INTO transaction_events (tx_hash, event_type, changed, state)
+    VALUES (NEW.tx_hash, event, changed_columns, to_jsonb(NEW));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- События неизменяемы
+CREATE OR REPLACE FUNCTION reject_state_event_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_orders_events ON orders;
+CREATE TRIGGER trg_orders_events
+    AFTER INSERT OR UPDATE OR DELETE ON orders
+    FOR EACH ROW EXECUTE FUNCTION record_order_event();
+
+DROP TRIGGER IF EXISTS trg_transactions_events ON transactions;
+CREATE TRIGGER trg_transactions_events
+    AFTER INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION record_transaction_event();
+
+DROP TRIGGER IF EXISTS trg_order_events_immutable ON order_events;
+CREATE TRIGGER trg_order_events_immutable
+    BEFORE UPDATE OR DELETE ON order_events
+    FOR EACH ROW EXECUTE FUNCTION reject_state_event_change();
+
+DROP TRIGGER IF EXISTS trg_transaction_events_immutable ON transaction_events;
+CREATE TRIGGER trg_transaction_events_immutable
+    BEFORE UPDATE OR DELETE ON transaction_events
+    FOR EACH ROW EXECUTE FUNCTION reject_state_event_change();
+
+-- Начальное состояние существующих строк, чтобы журнал воспроизводил их с момента включения
+INSERT INTO order_events (order_id, event_type, to_status, state, occurred_at)
+SELECT id, 'snapshot', status::TEXT, to_jsonb(o), COALESCE(updated_at, created_at, NOW()) FROM orders o ORDER BY id;
+
+INSERT INTO transaction_events (tx_hash, event_type, state, occurred_at)
+SELECT tx_hash, 'snapshot', to_jsonb(t), COALESCE(updated_at, created_at, NOW()) FROM transactions t ORDER BY id;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_deposit_evidence_immutable ON deposit_evidence;
+DROP FUNCTION IF EXISTS reject_archived_evidence_change();
+DROP INDEX IF EXISTS idx_transactions_credited_at;
+DROP TABLE IF EXISTS deposit_evidence;
@@ -0,0 +1,35 @@
+-- Архив ончейн-доказательств зачисленных депозитов: ответы ноды по транзакции (с input), квитанции и заголовку блока
+-- в исходном виде, сжатые gzip. sha256 считается по несжатому JSON и проверяется при чтении
+CREATE TABLE IF NOT EXISTS deposit_evidence (
+    tx_hash VARCHAR(255) PRIMARY KEY,
+    chain VARCHAR(32) NOT NULL,
+    block_number BIGINT,
+    block_hash VARCHAR(66),
+    bundle BYTEA,
+    sha256 VARCHAR(64),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    archived_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_deposit_evidence_pending ON deposit_evidence(attempts) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_credited_at ON transactions(credited_at) WHERE credited_at IS NOT NULL;
+
+-- Заархивированное доказательство не изменяется и не удаляется
+CREATE OR REPLACE FUNCTION reject_archived_evidence_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.archived_at IS NOT NULL THEN
+        RAISE EXCEPTION 'evidence of % is archived and immutable', OLD.tx_hash;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_deposit_evidence_immutable ON deposit_evidence;
+CREATE TRIGGER trg_deposit_evidence_immutable
+    BEFORE UPDATE OR DELETE ON deposit_evidence
+    FOR EACH ROW EXECUTE FUNCTION reject_archived_evidence_change();
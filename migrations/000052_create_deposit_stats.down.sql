@@ -0,0 +1,3 @@
+DELETE FROM dashboard_projection_cursors WHERE projection IN ('deposit_stats_hourly', 'deposit_stats_daily');
+DROP TABLE IF EXISTS deposit_stats_daily;
+DROP TABLE IF EXISTS deposit_stats_hourly;
@@ -0,0 +1,22 @@
+-- Статистика депозитов по активу для операционного дашборда: число, объем и уникальные вкладчики за час и за день.
+-- Таблицы поддерживает проектор дашборда: день подтверждения, в котором менялись депозиты, пересчитывается целиком.
+-- Уникальных вкладчиков за день нельзя сложить из часов, поэтому дни хранятся отдельно.
+-- Учитываются подтвержденные депозиты, кроме отброшенных как пыль или спам; актив определяется сетью кошелька
+CREATE TABLE IF NOT EXISTS deposit_stats_hourly (
+    day DATE NOT NULL,
+    hour SMALLINT NOT NULL CHECK (hour BETWEEN 0 AND 23),
+    asset_id INTEGER NOT NULL REFERENCES assets(id),
+    deposits INTEGER NOT NULL,
+    volume NUMERIC(78, 0) NOT NULL, -- Сумма в минимальных единицах
+    depositors INTEGER NOT NULL,    -- Уникальные пользователи
+    PRIMARY KEY (day, hour, asset_id)
+);
+
+CREATE TABLE IF NOT EXISTS deposit_stats_daily (
+    day DATE NOT NULL,
+    asset_id INTEGER NOT NULL REFERENCES assets(id),
+    deposits INTEGER NOT NULL,
+    volume NUMERIC(78, 0) NOT NULL,
+    depositors INTEGER NOT NULL,
+    PRIMARY KEY (day, asset_id)
+);
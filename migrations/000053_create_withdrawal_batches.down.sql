@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_withdrawals_batch;
+DROP INDEX IF EXISTS idx_withdrawals_queued;
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS withdrawal_batches;
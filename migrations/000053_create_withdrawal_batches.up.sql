@@ -0,0 +1,20 @@
+-- Пакеты выводов одного токена, оплачиваемые одной транзакцией BatchCollector.multisend
+CREATE TABLE IF NOT EXISTS withdrawal_batches (
+    id UUID PRIMARY KEY,
+    token VARCHAR(42) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'sending',
+    withdrawals INTEGER NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    tx_hash VARCHAR(66),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawal_batches_status ON withdrawal_batches(status);
+
+-- Пакет, которым оплачен вывод; при откате пакета вывод отправляется отдельно и получает свой tx_hash
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS batch_id UUID REFERENCES withdrawal_batches(id);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_queued ON withdrawals(created_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_withdrawals_batch ON withdrawals(batch_id) WHERE batch_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- История курсов активов к фиатным валютам. Пишется кешем курсов при изменении курса и не реже периода снимков,
+-- по ней транзакции и ордера оцениваются по курсу на момент создания
+CREATE TABLE IF NOT EXISTS price_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    chain VARCHAR(20) NOT NULL,
+    asset VARCHAR(20) NOT NULL,
+    fiat VARCHAR(10) NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate > 0), -- Стоимость одной единицы актива в фиатной валюте
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_snapshots_lookup ON price_snapshots (chain, asset, fiat, recorded_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE wallets DROP COLUMN IF EXISTS omnibus;
+DROP TABLE IF EXISTS merchant_deposit_settings;
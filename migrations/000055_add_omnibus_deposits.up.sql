@@ -0,0 +1,14 @@
+-- Режим приема депозитов мерчанта: dedicated — отдельный кошелек на ордер или клиента, omnibus — общий горячий
+-- кошелек платформы, ордер определяется по уникальной сумме (amount) или мемо (memo). Мерчант без записи — dedicated
+CREATE TABLE IF NOT EXISTS merchant_deposit_settings (
+    merchant_id BIGINT PRIMARY KEY,
+    mode VARCHAR(20) NOT NULL DEFAULT 'dedicated' CHECK (mode IN ('dedicated', 'omnibus')),
+    attribution VARCHAR(20) NOT NULL DEFAULT 'amount' CHECK (attribution IN ('amount', 'memo')),
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Омнибус кошелек: ордера на нем закрываются только переводом с их мемо или точной уникальной суммой.
+-- Флаг не снимается при смене кошелька в конфигурации, чтобы ожидающие ордера старого кошелька сопоставлялись так же
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS omnibus BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,11 @@
+-- Состояние сканеров сетей: контрольная точка (последний обработанный блок) и ручная пауза оператора.
+-- Приостановленный сканер не обрабатывает блоки и не зачисляет депозиты, после возобновления догоняет пропущенные блоки
+CREATE TABLE IF NOT EXISTS scanner_states (
+    chain VARCHAR(20) PRIMARY KEY,
+    last_block BIGINT,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    pause_reason TEXT,
+    paused_at TIMESTAMP WITH TIME ZONE,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS is_test;
+ALTER TABLE orders DROP COLUMN IF EXISTS is_test;
+DROP TABLE IF EXISTS sandbox_merchants;
@@ -0,0 +1,13 @@
+-- Мерчанты в режиме песочницы: работают только с окружением тестовой сети, боевое окружение отклоняет их запросы
+CREATE TABLE IF NOT EXISTS sandbox_merchants (
+    merchant_id BIGINT PRIMARY KEY,
+    enabled_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Признак тестовых данных: ордера и транзакции на кошельках тестовой сети (wallets.is_testnet)
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE orders o SET is_test = TRUE FROM wallets w WHERE w.id = o.wallet_id AND w.is_testnet;
+UPDATE transactions t SET is_test = TRUE FROM wallets w WHERE w.id = t.wallet_id AND w.is_testnet;
@@ -0,0 +1,22 @@
+-- AML проверки адресов получателей выводов и выплат мерчантам. Заблокированный перевод не отправляется,
+-- перевод на разборе отправляется и ждет решения комплаенса. Транзакция, предложение Safe или вывод
+-- в очереди пакета записываются после отправки
+CREATE TABLE IF NOT EXISTS destination_screenings (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(78) NOT NULL,
+    risk_level VARCHAR(20) NOT NULL,
+    risk_score DOUBLE PRECISION NOT NULL,
+    category VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    verdict VARCHAR(20) NOT NULL CHECK (verdict IN ('approved', 'review', 'blocked')),
+    tx_hash VARCHAR(66),
+    safe_tx_hash VARCHAR(66),
+    withdrawal_id UUID,
+    initiated_by VARCHAR(255) NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_destination_screenings_verdict ON destination_screenings (verdict, checked_at DESC);
+CREATE INDEX IF NOT EXISTS idx_destination_screenings_tx_hash ON destination_screenings (tx_hash) WHERE tx_hash IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS merchant_accruals;
+DROP TABLE IF EXISTS order_completions;
@@ -0,0 +1,23 @@
+-- Конвейер после оплаты ордера: запись ставится в очередь в одной транзакции с завершением ордера,
+-- обработчик обновляет начисления мерчанта, публикует событие и отправляет webhook, затем запускает свип и квитанции
+CREATE TABLE IF NOT EXISTS order_completions (
+    order_id INTEGER PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    -- Попытки исчерпаны, запись больше не обрабатывается
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_completions_pending ON order_completions(created_at) WHERE processed_at IS NULL AND NOT failed;
+
+-- Начисления мерчанта: завершенные ордера, еще не вошедшие в выплату (сумма в единицах актива)
+CREATE TABLE IF NOT EXISTS merchant_accruals (
+    merchant_id BIGINT NOT NULL,
+    asset_id INTEGER NOT NULL REFERENCES assets(id),
+    orders INTEGER NOT NULL DEFAULT 0,
+    amount VARCHAR(78) NOT NULL DEFAULT '0',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (merchant_id, asset_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS key_rotation_sweeps;
+DROP TABLE IF EXISTS key_rotations;
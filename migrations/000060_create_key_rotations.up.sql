@@ -0,0 +1,37 @@
+-- Ротация мастер-сида: проверка образца адресов нового сида, активация версии и свипы со старых кошельков
+CREATE TABLE IF NOT EXISTS key_rotations (
+    id SERIAL PRIMARY KEY,
+    from_version INTEGER NOT NULL,
+    to_version INTEGER NOT NULL,
+    -- verified, active, sweeping, completed
+    status VARCHAR(20) NOT NULL DEFAULT 'verified',
+    -- Адреса, выведенные из нового сида и сверенные с результатом церемонии
+    sample_addresses JSONB NOT NULL DEFAULT '[]',
+    started_by VARCHAR(255) NOT NULL,
+    activated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    activated_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Одновременно может идти только одна ротация
+CREATE UNIQUE INDEX IF NOT EXISTS idx_key_rotations_open ON key_rotations((TRUE)) WHERE status <> 'completed';
+
+-- Свип кошелька старого сида на кошелек того же пользователя из нового сида
+CREATE TABLE IF NOT EXISTS key_rotation_sweeps (
+    rotation_id INTEGER NOT NULL REFERENCES key_rotations(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id),
+    user_id BIGINT NOT NULL,
+    from_address VARCHAR(42) NOT NULL,
+    to_wallet_id INTEGER REFERENCES wallets(id),
+    to_address VARCHAR(42),
+    amount VARCHAR(78),
+    -- pending, swept, skipped, failed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66),
+    error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rotation_id, wallet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_rotation_sweeps_pending ON key_rotation_sweeps(rotation_id) WHERE status = 'pending';
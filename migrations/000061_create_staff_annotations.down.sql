@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS staff_tags;
+DROP TABLE IF EXISTS staff_notes;
@@ -0,0 +1,23 @@
+-- Внутренние заметки и теги поддержки и комплаенса к транзакциям и ордерам, доступны только через административный API.
+-- subject_id — хеш транзакции или ID ордера
+CREATE TABLE IF NOT EXISTS staff_notes (
+    id SERIAL PRIMARY KEY,
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('transaction', 'order')),
+    subject_id VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_notes_subject ON staff_notes(subject_type, subject_id);
+
+CREATE TABLE IF NOT EXISTS staff_tags (
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('transaction', 'order')),
+    subject_id VARCHAR(255) NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    added_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (subject_type, subject_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_tags_tag ON staff_tags(tag);
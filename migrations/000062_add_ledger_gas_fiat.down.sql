@@ -0,0 +1,4 @@
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS gas_cost_fiat;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS fiat_currency;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS gas_rate;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS gas_currency;
@@ -0,0 +1,6 @@
+-- Газ в валюте учета по курсу нативной монеты на момент исполнения транзакции.
+-- Пусто у неисполненных транзакций и если курс был недоступен при расчете.
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS gas_currency VARCHAR(10);
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS gas_rate VARCHAR(78);
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS fiat_currency VARCHAR(10);
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS gas_cost_fiat VARCHAR(78);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transactions_from_created_at;
+DROP TABLE IF EXISTS local_aml_blacklist;
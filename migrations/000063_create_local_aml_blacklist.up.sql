@@ -0,0 +1,11 @@
+-- Локальный черный список AML: адреса, добавленные правилами платформы, например при прощупывании
+-- депозитных адресов. Загружается в локальный AML сервис при старте.
+CREATE TABLE IF NOT EXISTS local_aml_blacklist (
+    address VARCHAR(100) PRIMARY KEY,
+    risk_score FLOAT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Поиск микропереводов одного отправителя на разные депозитные адреса (ignored_reason = 'probing')
+CREATE INDEX IF NOT EXISTS idx_transactions_from_created_at ON transactions(from_address, created_at);
@@ -0,0 +1,9 @@
+-- Последние балансы кошельков из мониторинга. Кеш балансов в памяти ограничен по размеру,
+-- вытесненные кошельки читаются отсюда.
+CREATE TABLE IF NOT EXISTS wallet_balances (
+    address VARCHAR(100) PRIMARY KEY,
+    token_balance NUMERIC(78, 0) NOT NULL,  -- В минимальных единицах токена
+    native_balance NUMERIC(78, 0) NOT NULL, -- В wei
+    status VARCHAR(16) NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
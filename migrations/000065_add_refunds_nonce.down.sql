@@ -0,0 +1,2 @@
+ALTER TABLE refunds
+DROP COLUMN IF EXISTS nonce;
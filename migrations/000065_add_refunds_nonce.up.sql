@@ -0,0 +1,3 @@
+-- Нонс транзакции возврата: по нему определяется исход отправки, когда узел не подтвердил прием транзакции
+ALTER TABLE refunds
+ADD COLUMN IF NOT EXISTS nonce BIGINT;
@@ -0,0 +1,11 @@
+-- Резервы суточного бюджета газа: создаются под блокировкой до подписи транзакции
+-- и удаляются после записи ее в журнал. Резерв транзакции с неизвестным исходом отправки
+-- остается до конца суток, чтобы параллельные отправки не превысили бюджет.
+CREATE TABLE IF NOT EXISTS gas_reservations (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL,
+    gas_cost VARCHAR(78) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_gas_reservations_created_at ON gas_reservations(created_at);
@@ -0,0 +1,7 @@
+-- Последний использованный шаг TOTP оператора админки: секреты операторов задаются конфигурацией,
+-- здесь хранится только шаг для защиты от повторного использования кода
+CREATE TABLE IF NOT EXISTS admin_two_factor_steps (
+    operator VARCHAR(255) PRIMARY KEY,
+    last_used_step BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,11 @@
+-- Одноразовые вызовы входа: сессия создается только по подписи вызова сервисом идентификации
+CREATE TABLE IF NOT EXISTS session_challenges (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    challenge VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_challenges_expires_at ON session_challenges(expires_at);
@@ -0,0 +1,13 @@
+-- Свечи пар из рыночного фида: закрытые свечи потока и свечи, догруженные по REST после переподключения.
+-- После перезапуска окно графика восстанавливается отсюда, недостающее догружается с биржи
+CREATE TABLE IF NOT EXISTS candles (
+    symbol VARCHAR(20) NOT NULL,
+    open_time BIGINT NOT NULL, -- Время открытия свечи, мс
+    open DOUBLE PRECISION NOT NULL,
+    high DOUBLE PRECISION NOT NULL,
+    low DOUBLE PRECISION NOT NULL,
+    close DOUBLE PRECISION NOT NULL,
+    volume DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (symbol, open_time)
+);
@@ -0,0 +1,395 @@
+// Package blockrec records the blocks processed by the scanner together with every JSON-RPC call made while
+// processing them, and replays the recordings without a node. A recorded block reproduces a crediting bug
+// deterministically and serves as a regression case for the deposit matching and AML logic.
+//
+// Recording is scoped by context: the Recorder transport captures only requests sent with a context returned
+// by Begin, so one transport can sit in the shared RPC transport chain. A nil *Recorder records nothing.
+package blockrec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// replayURL — адрес, на который ethclient отправляет запросы при воспроизведении; сеть не используется
+const replayURL = "http://blockrec.invalid"
+
+// Call is one JSON-RPC call made while processing the block, with the node answer as received
+type Call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// Recording is the header of a processed block and the RPC calls made while processing it, in call order
+type Recording struct {
+	BlockNumber uint64          `json:"block_number"`
+	BlockHash   string          `json:"block_hash"`
+	Header      json.RawMessage `json:"header"`
+	RecordedAt  time.Time       `json:"recorded_at"`
+	Calls       []Call          `json:"calls"`
+}
+
+// BlockHeader decodes the recorded header and checks that it hashes to the recorded block hash
+func (r *Recording) BlockHeader() (*types.Header, error) {
+	var header types.Header
+	if err := json.Unmarshal(r.Header, &header); err != nil {
+		return nil, fmt.Errorf("blockrec: invalid header of block %d: %w", r.BlockNumber, err)
+	}
+	if !strings.EqualFold(header.Hash().Hex(), r.BlockHash) {
+		return nil, fmt.Errorf("blockrec: header of block %d hashes to %s, recorded %s", r.BlockNumber, header.Hash().Hex(), r.BlockHash)
+	}
+	return &header, nil
+}
+
+// Recorder writes one recording file per block into a directory
+type Recorder struct {
+	dir string
+}
+
+// NewRecorder returns a recorder writing into dir, nil if dir is empty
+func NewRecorder(dir string) (*Recorder, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("blockrec: failed to create %s: %w", dir, err)
+	}
+	return &Recorder{dir: dir}, nil
+}
+
+type sessionKey struct{}
+
+// Session collects the calls of one block until Finish
+type Session struct {
+	dir       string
+	recording Recording
+
+	mu       sync.Mutex
+	finished bool
+}
+
+// Begin starts recording the block: RPC requests sent through the Transport with the returned context
+// are recorded until Finish. A nil recorder returns ctx unchanged and a nil session.
+func (r *Recorder) Begin(ctx context.Context, header *types.Header) (context.Context, *Session) {
+	if r == nil {
+		return ctx, nil
+	}
+
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		// Заголовок, полученный от ноды, всегда сериализуется; без него запись бесполезна
+		return ctx, nil
+	}
+	session := &Session{
+		dir: r.dir,
+		recording: Recording{
+			BlockNumber: header.Number.Uint64(),
+			BlockHash:   header.Hash().Hex(),
+			Header:      encoded,
+			RecordedAt:  time.Now().UTC(),
+		},
+	}
+	return context.WithValue(ctx, sessionKey{}, session), session
+}
+
+func (s *Session) add(calls []Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Запросы, отправленные с контекстом блока после его обработки, например проверки подтверждений, не записываются
+	if !s.finished {
+		s.recording.Calls = append(s.recording.Calls, calls...)
+	}
+}
+
+// Finish stops recording and writes the recording file. It is a no-op on a nil session.
+func (s *Session) Finish() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.finished = true
+	encoded, err := json.MarshalIndent(s.recording, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("blockrec: failed to encode block %d: %w", s.recording.BlockNumber, err)
+	}
+
+	// Файл пишется целиком во временный и переименовывается, чтобы воспроизведение не прочитало его наполовину
+	name := filepath.Join(s.dir, fmt.Sprintf("block_%d_%s.json", s.recording.BlockNumber, s.recording.BlockHash))
+	if err = os.WriteFile(name+".tmp", encoded, 0o640); err != nil {
+		return fmt.Errorf("blockrec: failed to write %s: %w", name, err)
+	}
+	if err = os.Rename(name+".tmp", name); err != nil {
+		return fmt.Errorf("blockrec: failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Transport wraps next so that requests sent with a recording context are recorded with their responses
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	if r == nil {
+		return next
+	}
+	return &recordingTransport{next: next}
+}
+
+type recordingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	session, ok := req.Context().Value(sessionKey{}).(*Session)
+	if !ok || req.Body == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	answer, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(answer))
+
+	// Ответ, который не разбирается как JSON-RPC, отдается клиенту без записи: ошибку покажет сам клиент
+	if calls, err := pairCalls(body, answer); err == nil {
+		session.add(calls)
+	}
+	return resp, nil
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// pairCalls сопоставляет запросы одиночного или batch вызова с ответами по id
+func pairCalls(body, answer []byte) ([]Call, error) {
+	requests, err := decodeBatch[rpcRequest](body)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := decodeBatch[rpcResponse](answer)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]rpcResponse, len(responses))
+	for _, response := range responses {
+		byID[string(response.ID)] = response
+	}
+	calls := make([]Call, 0, len(requests))
+	for _, request := range requests {
+		response, ok := byID[string(request.ID)]
+		if !ok {
+			return nil, fmt.Errorf("blockrec: no response to %s", request.Method)
+		}
+		calls = append(calls, Call{
+			Method: request.Method,
+			Params: compact(request.Params),
+			Result: response.Result,
+			Error:  response.Error,
+		})
+	}
+	return calls, nil
+}
+
+func decodeBatch[T any](data []byte) ([]T, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []T
+		err := json.Unmarshal(data, &batch)
+		return batch, err
+	}
+	var single T
+	if err := json.Unmarshal(data, &single); err != nil {
+		return nil, err
+	}
+	return []T{single}, nil
+}
+
+func compact(params json.RawMessage) json.RawMessage {
+	var buf bytes.Buffer
+	if len(params) == 0 || json.Compact(&buf, params) != nil {
+		return params
+	}
+	return buf.Bytes()
+}
+
+// Load reads the recording file, or all recording files of the directory ordered by block number
+func Load(path string) ([]*Recording, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("blockrec: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "block_*.json")); err != nil {
+			return nil, fmt.Errorf("blockrec: %w", err)
+		}
+	}
+
+	recordings := make([]*Recording, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("blockrec: %w", err)
+		}
+		var recording Recording
+		if err = json.Unmarshal(data, &recording); err != nil {
+			return nil, fmt.Errorf("blockrec: invalid recording %s: %w", file, err)
+		}
+		recordings = append(recordings, &recording)
+	}
+
+	sort.SliceStable(recordings, func(i, j int) bool {
+		return recordings[i].BlockNumber < recordings[j].BlockNumber
+	})
+	return recordings, nil
+}
+
+// ErrNotRecorded is returned to the client for a call absent from the recording: processing took
+// a different path than when the block was recorded
+var ErrNotRecorded = errors.New("blockrec: call not recorded")
+
+// Replayer answers JSON-RPC requests from a recording. Repeated identical calls get the recorded answers
+// in order, the last answer is repeated once they run out.
+type Replayer struct {
+	mu     sync.Mutex
+	calls  map[string][]Call
+	missed []string
+}
+
+// NewReplayer creates a replayer for the recording
+func NewReplayer(recording *Recording) *Replayer {
+	calls := make(map[string][]Call)
+	for _, call := range recording.Calls {
+		key := callKey(call.Method, call.Params)
+		calls[key] = append(calls[key], call)
+	}
+	return &Replayer{calls: calls}
+}
+
+// Dial returns an ethclient whose requests are answered by the replayer
+func (p *Replayer) Dial(ctx context.Context) (*ethclient.Client, error) {
+	client, err := rpc.DialOptions(ctx, replayURL, rpc.WithHTTPClient(&http.Client{Transport: p}))
+	if err != nil {
+		return nil, fmt.Errorf("blockrec: %w", err)
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// Missed returns the calls that were requested during replay but absent from the recording
+func (p *Replayer) Missed() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.missed...)
+}
+
+func (p *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	requests, err := decodeBatch[rpcRequest](body)
+	if err != nil {
+		return nil, fmt.Errorf("blockrec: invalid request: %w", err)
+	}
+
+	responses := make([]rpcResponse, len(requests))
+	for i, request := range requests {
+		call, ok := p.next(request.Method, compact(request.Params))
+		responses[i] = rpcResponse{JSONRPC: "2.0", ID: request.ID, Result: call.Result, Error: call.Error}
+		if !ok {
+			message, _ := json.Marshal(fmt.Sprintf("%s: %s %s", ErrNotRecorded, request.Method, request.Params))
+			responses[i].Error = json.RawMessage(`{"code":-32000,"message":` + string(message) + `}`)
+		}
+		// Ответ без result и error клиент не принимает, пустой result записывается как null
+		if responses[i].Result == nil && responses[i].Error == nil {
+			responses[i].Result = json.RawMessage("null")
+		}
+	}
+
+	var answer []byte
+	if len(body) > 0 && bytes.TrimSpace(body)[0] == '[' {
+		answer, err = json.Marshal(responses)
+	} else {
+		answer, err = json.Marshal(responses[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Status:        "200 OK",
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(answer)),
+		ContentLength: int64(len(answer)),
+		Request:       req,
+	}, nil
+}
+
+func (p *Replayer) next(method string, params json.RawMessage) (Call, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := callKey(method, params)
+	queue := p.calls[key]
+	if len(queue) == 0 {
+		p.missed = append(p.missed, key)
+		return Call{}, false
+	}
+	call := queue[0]
+	if len(queue) > 1 {
+		p.calls[key] = queue[1:]
+	}
+	return call, true
+}
+
+func callKey(method string, params json.RawMessage) string {
+	return method + " " + string(params)
+}
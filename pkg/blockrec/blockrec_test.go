@@ -0,0 +1,123 @@
+package blockrec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNode возвращает ноду, отвечающую на eth_chainId и eth_blockNumber, и счетчик полученных запросов
+func newNode(t *testing.T) (*httptest.Server, *int) {
+	requests := new(int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var request rpcRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		result := map[string]string{"eth_chainId": `"0x38"`, "eth_blockNumber": `"0x10"`}[request.Method]
+		if result == "" {
+			result = "null"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.ID) + `,"result":` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func testHeader() *types.Header {
+	return &types.Header{Number: big.NewInt(16), Difficulty: big.NewInt(2), GasLimit: 30_000_000, Time: 1_700_000_000}
+}
+
+func TestNilRecorder(t *testing.T) {
+	recorder, err := NewRecorder("")
+	require.NoError(t, err)
+	assert.Nil(t, recorder)
+
+	assert.Equal(t, http.DefaultTransport, recorder.Transport(http.DefaultTransport))
+	ctx, session := recorder.Begin(context.Background(), testHeader())
+	assert.Nil(t, session)
+	assert.Equal(t, context.Background(), ctx)
+	assert.NoError(t, session.Finish())
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	node, requests := newNode(t)
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+
+	rpcClient, err := rpc.DialOptions(context.Background(), node.URL,
+		rpc.WithHTTPClient(&http.Client{Transport: recorder.Transport(http.DefaultTransport)}))
+	require.NoError(t, err)
+	client := ethclient.NewClient(rpcClient)
+	defer client.Close()
+
+	// Запрос без контекста записи не попадает в запись
+	_, err = client.BlockNumber(context.Background())
+	require.NoError(t, err)
+
+	header := testHeader()
+	ctx, session := recorder.Begin(context.Background(), header)
+	chainID, err := client.ChainID(ctx)
+	require.NoError(t, err)
+	number, err := client.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.NoError(t, session.Finish())
+
+	// После Finish запросы с контекстом блока больше не записываются
+	_, err = client.BlockNumber(ctx)
+	require.NoError(t, err)
+	sent := *requests
+
+	recordings, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+	assert.Equal(t, uint64(16), recordings[0].BlockNumber)
+	assert.Len(t, recordings[0].Calls, 2)
+
+	decoded, err := recordings[0].BlockHeader()
+	require.NoError(t, err)
+	assert.Equal(t, header.Hash(), decoded.Hash())
+
+	replayer := NewReplayer(recordings[0])
+	replayed, err := replayer.Dial(context.Background())
+	require.NoError(t, err)
+	defer replayed.Close()
+
+	replayedChainID, err := replayed.ChainID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, chainID, replayedChainID)
+	replayedNumber, err := replayed.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, number, replayedNumber)
+
+	// Вызов, которого нет в записи, завершается ошибкой и попадает в список пропущенных
+	_, err = replayed.HeaderByNumber(context.Background(), big.NewInt(1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "call not recorded")
+	assert.Equal(t, []string{`eth_getBlockByNumber ["0x1",false]`}, replayer.Missed())
+
+	assert.Equal(t, sent, *requests, "replay must not reach the node")
+}
+
+func TestBlockHeaderRejectsForeignHash(t *testing.T) {
+	encoded, err := json.Marshal(testHeader())
+	require.NoError(t, err)
+
+	recording := &Recording{BlockNumber: 16, BlockHash: "0x01", Header: encoded}
+	_, err = recording.BlockHeader()
+	assert.Error(t, err)
+}
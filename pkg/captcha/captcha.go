@@ -0,0 +1,82 @@
+// Package captcha verifies CAPTCHA tokens with providers implementing the
+// reCAPTCHA compatible siteverify protocol (Google reCAPTCHA, hCaptcha, Cloudflare Turnstile).
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// Verifier проверяет токены CAPTCHA через siteverify API провайдера
+type Verifier struct {
+	logger    *slog.Logger
+	secret    string
+	verifyURL string
+	timeout   time.Duration
+	client    *http.Client
+}
+
+// NewVerifier creates a new CAPTCHA verifier. Each verification is bounded by timeout, 0 disables the deadline.
+func NewVerifier(logger *slog.Logger, secret, verifyURL string, timeout time.Duration) *Verifier {
+	return &Verifier{
+		logger:    logger,
+		secret:    secret,
+		verifyURL: verifyURL,
+		timeout:   timeout,
+		client:    &http.Client{},
+	}
+}
+
+type verifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify returns an error if the token was not accepted by the provider
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{}
+	form.Set("secret", v.secret)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	ctx, cancel := timeouts.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create captcha request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to verify captcha: %w", timeouts.Classify("captcha", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha provider returned status %d", resp.StatusCode)
+	}
+
+	var result verifyResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode captcha response: %w", timeouts.Classify("captcha", err))
+	}
+
+	if !result.Success {
+		v.logger.DebugContext(ctx, "Captcha rejected", "error_codes", result.ErrorCodes)
+		return fmt.Errorf("captcha rejected: %s", strings.Join(result.ErrorCodes, ", "))
+	}
+
+	return nil
+}
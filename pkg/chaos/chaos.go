@@ -0,0 +1,182 @@
+// Package chaos injects faults into RPC requests, database calls and AML checks at configured rates,
+// so failover, retry and dead-letter paths can be exercised in staging. Faults are injected only by
+// binaries built with the chaos build tag and only when enabled in the configuration: a nil *Injector
+// injects nothing, which is what New returns otherwise.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	tx "github.com/Thiht/transactor/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrInjected marks a fault produced by the injector
+	ErrInjected = errors.New("chaos: injected fault")
+	// ErrNotCompiled is returned by New when faults are enabled in a binary built without the chaos tag
+	ErrNotCompiled = errors.New("chaos: binary built without the chaos tag")
+)
+
+// Число внедренных сбоев по видам, публикуется на /metrics
+var injectedFaults = expvar.NewMap("chaos_injected_faults")
+
+// Config задает долю вызовов со сбоем, от 0 до 1
+type Config struct {
+	Enabled        bool
+	RPCFailureRate float64
+	DBErrorRate    float64
+	AMLDelayRate   float64
+	AMLDelay       time.Duration
+}
+
+// Injector decides per call whether to inject a fault
+type Injector struct {
+	config Config
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New returns the injector for the config, nil if faults are disabled
+func New(config Config) (*Injector, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	if !compiled {
+		return nil, ErrNotCompiled
+	}
+
+	for name, rate := range map[string]float64{
+		"rpc failure rate": config.RPCFailureRate,
+		"db error rate":    config.DBErrorRate,
+		"aml delay rate":   config.AMLDelayRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("chaos %s must be between 0 and 1, got %v", name, rate)
+		}
+	}
+	if config.AMLDelay < 0 {
+		return nil, fmt.Errorf("chaos aml delay must not be negative")
+	}
+
+	return newInjector(config, rand.NewPCG(rand.Uint64(), rand.Uint64())), nil
+}
+
+func newInjector(config Config, source rand.Source) *Injector {
+	return &Injector{
+		config: config,
+		rand:   rand.New(source),
+	}
+}
+
+// hit reports whether the current call should fail with the given rate
+func (i *Injector) hit(kind string, rate float64) bool {
+	if i == nil || rate <= 0 {
+		return false
+	}
+
+	i.mu.Lock()
+	hit := i.rand.Float64() < rate
+	i.mu.Unlock()
+
+	if hit {
+		injectedFaults.Add(kind, 1)
+	}
+	return hit
+}
+
+// Transport wraps next so that RPC requests fail before being sent at the RPC failure rate
+func (i *Injector) Transport(next http.RoundTripper) http.RoundTripper {
+	if i == nil || i.config.RPCFailureRate <= 0 {
+		return next
+	}
+	return &faultyTransport{next: next, injector: i}
+}
+
+type faultyTransport struct {
+	next     http.RoundTripper
+	injector *Injector
+}
+
+func (t *faultyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.injector.hit("rpc", t.injector.config.RPCFailureRate) {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: rpc request to %s", ErrInjected, req.URL.Host)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// DB wraps getter so that queries fail at the DB error rate. Batches and copies are not affected.
+func (i *Injector) DB(getter tx.DBGetter) tx.DBGetter {
+	if i == nil || i.config.DBErrorRate <= 0 {
+		return getter
+	}
+	return func(ctx context.Context) tx.DB {
+		return &faultyDB{DB: getter(ctx), injector: i}
+	}
+}
+
+type faultyDB struct {
+	tx.DB
+	injector *Injector
+}
+
+func (db *faultyDB) fail() bool {
+	return db.injector.hit("db", db.injector.config.DBErrorRate)
+}
+
+func (db *faultyDB) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	if db.fail() {
+		return pgconn.CommandTag{}, fmt.Errorf("%w: database exec", ErrInjected)
+	}
+	return db.DB.Exec(ctx, sql, arguments...)
+}
+
+func (db *faultyDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if db.fail() {
+		return nil, fmt.Errorf("%w: database query", ErrInjected)
+	}
+	return db.DB.Query(ctx, sql, args...)
+}
+
+func (db *faultyDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if db.fail() {
+		return errRow{err: fmt.Errorf("%w: database query", ErrInjected)}
+	}
+	return db.DB.QueryRow(ctx, sql, args...)
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// DelayAML holds an AML check for the configured delay at the AML delay rate.
+// It returns early if ctx is done while waiting, the check then fails with the context error.
+func (i *Injector) DelayAML(ctx context.Context) {
+	if i == nil || i.config.AMLDelay <= 0 || !i.hit("aml_delay", i.config.AMLDelayRate) {
+		return
+	}
+
+	timer := time.NewTimer(i.config.AMLDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,79 @@
+package chaos
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDisabled(t *testing.T) {
+	injector, err := New(Config{RPCFailureRate: 1})
+	require.NoError(t, err)
+	assert.Nil(t, injector)
+
+	// nil injector ничего не внедряет
+	assert.Equal(t, http.DefaultTransport, injector.Transport(http.DefaultTransport))
+	injector.DelayAML(context.Background())
+}
+
+func TestNewRequiresBuildTag(t *testing.T) {
+	injector, err := New(Config{Enabled: true, RPCFailureRate: 0.5})
+	if compiled {
+		require.NoError(t, err)
+		assert.NotNil(t, injector)
+		return
+	}
+	assert.ErrorIs(t, err, ErrNotCompiled)
+	assert.Nil(t, injector)
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	failing := newInjector(Config{RPCFailureRate: 1}, rand.NewPCG(1, 2))
+	client := &http.Client{Transport: failing.Transport(http.DefaultTransport)}
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrInjected)
+
+	passing := newInjector(Config{RPCFailureRate: 0}, rand.NewPCG(1, 2))
+	client = &http.Client{Transport: passing.Transport(http.DefaultTransport)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRate(t *testing.T) {
+	injector := newInjector(Config{}, rand.NewPCG(1, 2))
+
+	hits := 0
+	for range 10000 {
+		if injector.hit("test", 0.2) {
+			hits++
+		}
+	}
+	assert.InDelta(t, 2000, hits, 300)
+}
+
+func TestDelayAML(t *testing.T) {
+	injector := newInjector(Config{AMLDelayRate: 1, AMLDelay: 50 * time.Millisecond}, rand.NewPCG(1, 2))
+
+	start := time.Now()
+	injector.DelayAML(context.Background())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	injector.DelayAML(ctx)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
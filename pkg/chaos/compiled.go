@@ -0,0 +1,6 @@
+//go:build chaos
+
+package chaos
+
+// Сборка с тегом chaos может внедрять сбои
+const compiled = true
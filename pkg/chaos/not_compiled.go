@@ -0,0 +1,6 @@
+//go:build !chaos
+
+package chaos
+
+// Без тега chaos сбои не внедряются независимо от конфигурации
+const compiled = false
@@ -0,0 +1,188 @@
+// Package cron parses standard five-field cron expressions (minute, hour, day of month, month, day of week)
+// and computes their next activation time.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is returned for expressions that cannot be parsed
+var ErrInvalidSchedule = errors.New("cron: invalid schedule")
+
+// Активация ищется не дальше этого горизонта: расписание вроде "0 0 30 2 *" никогда не срабатывает
+const searchYears = 5
+
+// Дескрипторы, заменяющие выражение целиком
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 и 7 — воскресенье
+}
+
+// Schedule is a parsed cron expression. Times are evaluated in the location of the time passed to Next.
+type Schedule struct {
+	expr string
+	// Битовые маски допустимых значений полей
+	minute, hour, dom, month, dow uint64
+	// Ограничены ли день месяца и день недели: если оба, достаточно совпадения любого из них, как в cron
+	domRestricted, dowRestricted bool
+}
+
+// Parse parses a five-field expression such as "0 9 1 * *" (09:00 on the first day of every month)
+// or one of the descriptors @yearly, @monthly, @weekly, @daily and @hourly.
+// Fields support *, values, ranges (1-5), lists (1,15) and steps (*/15, 1-10/2).
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if spec, ok = descriptors[strings.ToLower(spec)]; !ok {
+			return nil, fmt.Errorf("%w: unknown descriptor %q", ErrInvalidSchedule, expr)
+		}
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%w: expected %d fields, got %d", ErrInvalidSchedule, len(fields), len(parts))
+	}
+
+	var masks [5]uint64
+	for i, part := range parts {
+		mask, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		masks[i] = mask
+	}
+
+	// Воскресенье записывается и как 0, и как 7
+	dow := masks[4]
+	if dow&(1<<7) != 0 {
+		dow = dow&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		expr:          expr,
+		minute:        masks[0],
+		hour:          masks[1],
+		dom:           masks[2],
+		month:         masks[3],
+		dow:           dow,
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first activation strictly after t, truncated to the minute.
+// Returns the zero time if the schedule has no activation within the next few years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func has(mask uint64, value int) bool {
+	return mask&(1<<uint(value)) != 0
+}
+
+func parseField(spec string, f field) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(spec, ",") {
+		rangeSpec, stepSpec, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: invalid step %q in %s", ErrInvalidSchedule, item, f.name)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangeSpec == "*":
+		case strings.Contains(rangeSpec, "-"):
+			from, to, _ := strings.Cut(rangeSpec, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(from)
+			hi, err2 = strconv.Atoi(to)
+			if err1 != nil || err2 != nil || lo > hi {
+				return 0, fmt.Errorf("%w: invalid range %q in %s", ErrInvalidSchedule, item, f.name)
+			}
+		default:
+			value, err := strconv.Atoi(rangeSpec)
+			if err != nil {
+				return 0, fmt.Errorf("%w: invalid value %q in %s", ErrInvalidSchedule, item, f.name)
+			}
+			lo, hi = value, value
+			// "5/15" означает значения от 5 с шагом 15 до конца диапазона
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > f.max {
+			return 0, fmt.Errorf("%w: %q is out of range %d-%d in %s", ErrInvalidSchedule, item, f.min, f.max, f.name)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
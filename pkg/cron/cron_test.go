@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	cases := []struct {
+		expr, from, want string
+	}{
+		{"0 9 1 * *", "2026-01-15T10:00:00Z", "2026-02-01T09:00:00Z"},
+		{"0 9 1 * *", "2026-02-01T09:00:00Z", "2026-03-01T09:00:00Z"}, // Строго после from
+		{"@monthly", "2026-12-31T23:59:30Z", "2027-01-01T00:00:00Z"},
+		{"*/15 * * * *", "2026-03-10T10:07:12Z", "2026-03-10T10:15:00Z"},
+		{"30 8 * * 1-5", "2026-10-16T09:00:00Z", "2026-10-19T08:30:00Z"}, // Пятница -> понедельник
+		{"0 0 * * 7", "2026-10-16T00:00:00Z", "2026-10-18T00:00:00Z"},    // 7 — воскресенье
+		{"0 12 29 2 *", "2026-03-01T00:00:00Z", "2028-02-29T12:00:00Z"},
+		{"0 0 31 * *", "2026-04-01T00:00:00Z", "2026-05-31T00:00:00Z"},
+		// День месяца или день недели, если ограничены оба
+		{"0 0 13 * 5", "2026-10-10T00:00:00Z", "2026-10-13T00:00:00Z"},
+		{"0 0 13 * 5", "2026-10-13T00:00:00Z", "2026-10-16T00:00:00Z"},
+		{"5/20 0 1 1 *", "2026-01-01T00:06:00Z", "2026-01-01T00:25:00Z"},
+		{"0 9,18 * * *", "2026-06-01T12:00:00Z", "2026-06-01T18:00:00Z"},
+	}
+	for _, c := range cases {
+		schedule, err := Parse(c.expr)
+		require.NoError(t, err, c.expr)
+		assert.Equal(t, at(c.want), schedule.Next(at(c.from)), "%s from %s", c.expr, c.from)
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	schedule, err := Parse("0 9 1 * *")
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2026, 1, 15, 0, 0, 0, 0, moscow))
+	assert.Equal(t, time.Date(2026, 2, 1, 9, 0, 0, 0, moscow), next)
+	assert.Equal(t, "2026-02-01T06:00:00Z", next.UTC().Format(time.RFC3339))
+}
+
+func TestNextNever(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@fortnightly",
+	}
+	for _, expr := range invalid {
+		_, err := Parse(expr)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, expr)
+	}
+}
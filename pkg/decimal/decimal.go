@@ -0,0 +1,300 @@
+// Package decimal implements exact decimal amounts for money: parsing user input,
+// converting to and from minimal token units, comparisons and explicit rounding.
+// Values are backed by big.Rat, so arithmetic never loses precision the way float64 or big.Float do.
+package decimal
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// maxStringPlaces limits the fractional digits of String for values without a finite decimal representation, e.g. 1/3
+const maxStringPlaces = 36
+
+var (
+	// ErrInvalid is returned for strings that are not plain decimal numbers
+	ErrInvalid = errors.New("invalid decimal")
+	// ErrPrecision is returned when an amount has more fractional digits than the target unit allows
+	ErrPrecision = errors.New("decimal has too many fractional digits")
+)
+
+// Только обычная десятичная запись: без экспоненты, дробей и шестнадцатеричных чисел, которые принимает big.Rat.SetString
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+var ten = big.NewInt(10)
+
+// RoundingMode defines how a value is rounded to a number of fractional digits
+type RoundingMode int
+
+const (
+	RoundDown     RoundingMode = iota // К нулю: 1.29 -> 1.2, -1.29 -> -1.2
+	RoundUp                           // От нуля: 1.21 -> 1.3, -1.21 -> -1.3
+	RoundFloor                        // К минус бесконечности: 1.29 -> 1.2, -1.21 -> -1.3
+	RoundCeiling                      // К плюс бесконечности: 1.21 -> 1.3, -1.29 -> -1.2
+	RoundHalfUp                       // К ближайшему, половина от нуля: 1.25 -> 1.3, -1.25 -> -1.3
+	RoundHalfEven                     // К ближайшему, половина к четному (банковское): 1.25 -> 1.2, 1.35 -> 1.4
+)
+
+// Decimal is an immutable exact decimal value. The zero value is 0.
+type Decimal struct {
+	value *big.Rat
+}
+
+// Zero is the decimal 0
+var Zero = Decimal{}
+
+// Parse parses a plain decimal string such as "100", "-0.5" or "1.000000000000000001".
+// Exponents, fractions and surrounding spaces are rejected.
+func Parse(s string) (Decimal, error) {
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	return Decimal{value: value}, nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is intended for constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewFromInt returns the decimal value of an integer
+func NewFromInt(value int64) Decimal {
+	return Decimal{value: new(big.Rat).SetInt64(value)}
+}
+
+// FromRat returns the decimal value of r
+func FromRat(r *big.Rat) Decimal {
+	return Decimal{value: new(big.Rat).Set(r)}
+}
+
+// FromUnits converts an amount in minimal units to the decimal amount: (1500, 3) -> 1.5
+func FromUnits(units *big.Int, decimals int) Decimal {
+	return Decimal{value: new(big.Rat).SetFrac(units, pow10(decimals))}
+}
+
+// Units converts the amount to minimal units with the given number of decimals: (1.5, 3) -> 1500.
+// Returns ErrPrecision if the amount cannot be represented exactly.
+func (d Decimal) Units(decimals int) (*big.Int, error) {
+	scaled := new(big.Rat).Mul(d.rat(), new(big.Rat).SetInt(pow10(decimals)))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("%w: %s for %d decimals", ErrPrecision, d, decimals)
+	}
+	return new(big.Int).Set(scaled.Num()), nil
+}
+
+// UnitsRounded converts the amount to minimal units, rounding extra fractional digits with mode
+func (d Decimal) UnitsRounded(decimals int, mode RoundingMode) *big.Int {
+	units, _ := d.Round(decimals, mode).Units(decimals)
+	return units
+}
+
+// Round rounds the value to places fractional digits
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	scale := pow10(places)
+	scaled := new(big.Rat).Mul(d.rat(), new(big.Rat).SetInt(scale))
+	if scaled.IsInt() {
+		return d
+	}
+
+	// Знаменатель big.Rat всегда положителен, знак хранится в числителе
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if roundAway(mode, scaled.Sign(), quotient, remainder, scaled.Denom()) {
+		quotient.Add(quotient, big.NewInt(int64(scaled.Sign())))
+	}
+
+	return Decimal{value: new(big.Rat).SetFrac(quotient, scale)}
+}
+
+// roundAway reports whether the truncated quotient must be incremented away from zero
+func roundAway(mode RoundingMode, sign int, quotient, remainder, denominator *big.Int) bool {
+	switch mode {
+	case RoundUp:
+		return true
+	case RoundFloor:
+		return sign < 0
+	case RoundCeiling:
+		return sign > 0
+	case RoundHalfUp, RoundHalfEven:
+		twice := new(big.Int).Lsh(new(big.Int).Abs(remainder), 1)
+		switch twice.Cmp(denominator) {
+		case 1:
+			return true
+		case 0:
+			return mode == RoundHalfUp || quotient.Bit(0) == 1
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// Add returns d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Add(d.rat(), e.rat())}
+}
+
+// Sub returns d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Sub(d.rat(), e.rat())}
+}
+
+// Mul returns d * e
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Mul(d.rat(), e.rat())}
+}
+
+// Cmp compares d and e and returns -1, 0 or +1
+func (d Decimal) Cmp(e Decimal) int {
+	return d.rat().Cmp(e.rat())
+}
+
+// Equal reports whether d and e are the same value, regardless of trailing zeros
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Rat returns a copy of the value as big.Rat
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).Set(d.rat())
+}
+
+// String returns the shortest exact representation without trailing zeros: "1.5", "100", "-0.001".
+// Values without a finite decimal representation are rounded half to even to maxStringPlaces digits.
+func (d Decimal) String() string {
+	places, exact := fractionalPlaces(d.rat().Denom())
+	if !exact {
+		return trimZeros(d.Round(maxStringPlaces, RoundHalfEven).rat().FloatString(maxStringPlaces))
+	}
+	return d.rat().FloatString(places)
+}
+
+// StringFixed rounds the value half up to places fractional digits and keeps trailing zeros: (1.5, 2) -> "1.50"
+func (d Decimal) StringFixed(places int) string {
+	return d.Round(places, RoundHalfUp).rat().FloatString(places)
+}
+
+// MarshalJSON encodes the value as a JSON string, so clients never parse amounts as binary floats
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts both a JSON string and a JSON number
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		text = s
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, the value is sent as text so NUMERIC columns receive it exactly
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC and text columns
+func (d *Decimal) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	case nil:
+		return fmt.Errorf("%w: cannot scan NULL, use *Decimal", ErrInvalid)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalid, src)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.value == nil {
+		return new(big.Rat)
+	}
+	return d.value
+}
+
+// fractionalPlaces returns the number of fractional digits needed to print 1/denominator exactly,
+// or false if the denominator has prime factors other than 2 and 5
+func fractionalPlaces(denominator *big.Int) (int, bool) {
+	rest := new(big.Int).Set(denominator)
+	var twos, fives int
+	two, five := big.NewInt(2), big.NewInt(5)
+	remainder := new(big.Int)
+	for {
+		quotient, r := new(big.Int).QuoRem(rest, two, remainder)
+		if r.Sign() != 0 {
+			break
+		}
+		rest, twos = quotient, twos+1
+	}
+	for {
+		quotient, r := new(big.Int).QuoRem(rest, five, remainder)
+		if r.Sign() != 0 {
+			break
+		}
+		rest, fives = quotient, fives+1
+	}
+	if rest.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	return max(twos, fives), true
+}
+
+func trimZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
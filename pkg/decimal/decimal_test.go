@@ -0,0 +1,230 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	valid := map[string]string{
+		"0":                     "0",
+		"100":                   "100",
+		"100.00":                "100",
+		"+1.5":                  "1.5",
+		"-0.001":                "-0.001",
+		"0000.10":               "0.1",
+		"1.000000000000000001":  "1.000000000000000001",
+		"123456789012345678901": "123456789012345678901",
+	}
+	for input, expected := range valid {
+		d, err := Parse(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, d.String(), input)
+	}
+
+	invalid := []string{"", " 1", "1 ", "1.", ".5", "1e18", "1E-2", "1/3", "0x10", "1,5", "NaN", "Inf", "--1", "1.2.3", "١"}
+	for _, input := range invalid {
+		_, err := Parse(input)
+		assert.ErrorIs(t, err, ErrInvalid, input)
+	}
+}
+
+func TestUnits(t *testing.T) {
+	units, err := MustParse("1.5").Units(18)
+	require.NoError(t, err)
+	assert.Equal(t, "1500000000000000000", units.String())
+
+	// 0.1 не представимо в float64: через big.Float получилось бы 99999999999999999
+	units, err = MustParse("0.1").Units(18)
+	require.NoError(t, err)
+	assert.Equal(t, "100000000000000000", units.String())
+
+	units, err = MustParse("1.000000000000000001").Units(18)
+	require.NoError(t, err)
+	assert.Equal(t, "1000000000000000001", units.String())
+
+	units, err = MustParse("-2.25").Units(2)
+	require.NoError(t, err)
+	assert.Equal(t, "-225", units.String())
+
+	_, err = MustParse("1.0000000000000000001").Units(18)
+	assert.ErrorIs(t, err, ErrPrecision)
+
+	_, err = MustParse("0.001").Units(2)
+	assert.ErrorIs(t, err, ErrPrecision)
+
+	// Лишние нули в дробной части не влияют на точность
+	units, err = MustParse("1.500000").Units(1)
+	require.NoError(t, err)
+	assert.Equal(t, "15", units.String())
+}
+
+func TestFromUnits(t *testing.T) {
+	cases := []struct {
+		units    int64
+		decimals int
+		expected string
+	}{
+		{1500, 3, "1.5"},
+		{1, 18, "0.000000000000000001"},
+		{0, 18, "0"},
+		{-5, 1, "-0.5"},
+		{100, 0, "100"},
+		{123456, 2, "1234.56"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, FromUnits(big.NewInt(c.units), c.decimals).String(), "%d/%d", c.units, c.decimals)
+	}
+
+	// Перевод туда и обратно без потерь
+	units, _ := new(big.Int).SetString("123456789123456789123456789", 10)
+	back, err := FromUnits(units, 18).Units(18)
+	require.NoError(t, err)
+	assert.Equal(t, units.String(), back.String())
+}
+
+func TestRound(t *testing.T) {
+	modes := []RoundingMode{RoundDown, RoundUp, RoundFloor, RoundCeiling, RoundHalfUp, RoundHalfEven}
+
+	// Ожидаемые значения в порядке modes: Down, Up, Floor, Ceiling, HalfUp, HalfEven
+	cases := []struct {
+		input    string
+		places   int
+		expected [6]string
+	}{
+		{"5.5", 0, [6]string{"5", "6", "5", "6", "6", "6"}},
+		{"2.5", 0, [6]string{"2", "3", "2", "3", "3", "2"}},
+		{"1.6", 0, [6]string{"1", "2", "1", "2", "2", "2"}},
+		{"1.1", 0, [6]string{"1", "2", "1", "2", "1", "1"}},
+		{"1.0", 0, [6]string{"1", "1", "1", "1", "1", "1"}},
+		{"-1.0", 0, [6]string{"-1", "-1", "-1", "-1", "-1", "-1"}},
+		{"-1.1", 0, [6]string{"-1", "-2", "-2", "-1", "-1", "-1"}},
+		{"-1.6", 0, [6]string{"-1", "-2", "-2", "-1", "-2", "-2"}},
+		{"-2.5", 0, [6]string{"-2", "-3", "-3", "-2", "-3", "-2"}},
+		{"-5.5", 0, [6]string{"-5", "-6", "-6", "-5", "-6", "-6"}},
+		{"0.4", 0, [6]string{"0", "1", "0", "1", "0", "0"}},
+		{"-0.4", 0, [6]string{"0", "-1", "-1", "0", "0", "0"}},
+		{"0.5", 0, [6]string{"0", "1", "0", "1", "1", "0"}},
+		{"-0.5", 0, [6]string{"0", "-1", "-1", "0", "-1", "0"}},
+		{"1.25", 1, [6]string{"1.2", "1.3", "1.2", "1.3", "1.3", "1.2"}},
+		{"1.35", 1, [6]string{"1.3", "1.4", "1.3", "1.4", "1.4", "1.4"}},
+		{"-1.25", 1, [6]string{"-1.2", "-1.3", "-1.3", "-1.2", "-1.3", "-1.2"}},
+		{"1.251", 1, [6]string{"1.2", "1.3", "1.2", "1.3", "1.3", "1.3"}},
+		{"1.249", 1, [6]string{"1.2", "1.3", "1.2", "1.3", "1.2", "1.2"}},
+		{"0.125", 2, [6]string{"0.12", "0.13", "0.12", "0.13", "0.13", "0.12"}},
+		{"0.135", 2, [6]string{"0.13", "0.14", "0.13", "0.14", "0.14", "0.14"}},
+		{"9.995", 2, [6]string{"9.99", "10", "9.99", "10", "10", "10"}},
+		{"-9.995", 2, [6]string{"-9.99", "-10", "-10", "-9.99", "-10", "-10"}},
+		{"1.005", 2, [6]string{"1", "1.01", "1", "1.01", "1.01", "1"}},
+		{"12.34", 2, [6]string{"12.34", "12.34", "12.34", "12.34", "12.34", "12.34"}},
+		{"12.34", 5, [6]string{"12.34", "12.34", "12.34", "12.34", "12.34", "12.34"}},
+		{"0.0000000000000000015", 18, [6]string{"0.000000000000000001", "0.000000000000000002", "0.000000000000000001", "0.000000000000000002", "0.000000000000000002", "0.000000000000000002"}},
+		{"0.0000000000000000025", 18, [6]string{"0.000000000000000002", "0.000000000000000003", "0.000000000000000002", "0.000000000000000003", "0.000000000000000003", "0.000000000000000002"}},
+	}
+
+	for _, c := range cases {
+		for i, mode := range modes {
+			actual := MustParse(c.input).Round(c.places, mode)
+			assert.Equal(t, c.expected[i], actual.String(), "%s to %d places, mode %d", c.input, c.places, mode)
+		}
+	}
+}
+
+func TestRoundNonTerminating(t *testing.T) {
+	third := FromRat(big.NewRat(1, 3))
+	assert.Equal(t, "0.33", third.Round(2, RoundHalfUp).String())
+	assert.Equal(t, "0.34", third.Round(2, RoundUp).String())
+
+	twoThirds := FromRat(big.NewRat(-2, 3))
+	assert.Equal(t, "-0.67", twoThirds.Round(2, RoundHalfEven).String())
+	assert.Equal(t, "-0.66", twoThirds.Round(2, RoundDown).String())
+	assert.Equal(t, "-0.67", twoThirds.Round(2, RoundFloor).String())
+	assert.Equal(t, "-0.66", twoThirds.Round(2, RoundCeiling).String())
+
+	assert.Equal(t, "0.333333333333333333333333333333333333", third.String())
+}
+
+func TestUnitsRounded(t *testing.T) {
+	amount := MustParse("10.129")
+	assert.Equal(t, "1012", amount.UnitsRounded(2, RoundDown).String())
+	assert.Equal(t, "1013", amount.UnitsRounded(2, RoundHalfUp).String())
+	assert.Equal(t, "10129000", amount.UnitsRounded(6, RoundDown).String())
+}
+
+func TestArithmeticAndComparison(t *testing.T) {
+	a := MustParse("0.1")
+	b := MustParse("0.2")
+
+	// Классический пример ошибки float64: 0.1 + 0.2 != 0.3
+	assert.True(t, a.Add(b).Equal(MustParse("0.3")))
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, 1, b.Cmp(a))
+	assert.Equal(t, 0, MustParse("1.50").Cmp(MustParse("1.5")))
+
+	assert.True(t, Zero.IsZero())
+	assert.Equal(t, "0", Zero.String())
+	assert.Equal(t, 1, Zero.Add(a).Sign())
+	assert.Equal(t, -1, Zero.Sub(a).Sign())
+	assert.Equal(t, "7", NewFromInt(7).String())
+
+	// Операции не изменяют исходные значения
+	_ = a.Add(b)
+	assert.Equal(t, "0.1", a.String())
+	r := a.Rat()
+	r.SetInt64(5)
+	assert.Equal(t, "0.1", a.String())
+}
+
+func TestStringFixed(t *testing.T) {
+	assert.Equal(t, "1.50", MustParse("1.5").StringFixed(2))
+	assert.Equal(t, "1.01", MustParse("1.005").StringFixed(2))
+	assert.Equal(t, "-1.01", MustParse("-1.005").StringFixed(2))
+	assert.Equal(t, "2", MustParse("1.5").StringFixed(0))
+	assert.Equal(t, "0.000000000000000001", FromUnits(big.NewInt(1), 18).StringFixed(18))
+	assert.Equal(t, "0.00", FromUnits(big.NewInt(1), 18).StringFixed(2))
+}
+
+func TestJSON(t *testing.T) {
+	var payload struct {
+		Amount Decimal `json:"amount"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"1.000000000000000001"}`), &payload))
+	assert.Equal(t, "1.000000000000000001", payload.Amount.String())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":12.5}`), &payload))
+	assert.Equal(t, "12.5", payload.Amount.String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"1e3"}`), &payload))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"abc"}`), &payload))
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"12.5"}`, string(data))
+}
+
+func TestDatabaseValue(t *testing.T) {
+	value, err := MustParse("100.0037").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "100.0037", value)
+
+	var d Decimal
+	require.NoError(t, d.Scan("100.003700"))
+	assert.Equal(t, "100.0037", d.String())
+
+	require.NoError(t, d.Scan([]byte("0.000000000000000001")))
+	assert.Equal(t, "0.000000000000000001", d.String())
+
+	require.NoError(t, d.Scan(int64(42)))
+	assert.Equal(t, "42", d.String())
+
+	assert.ErrorIs(t, d.Scan(nil), ErrInvalid)
+	assert.ErrorIs(t, d.Scan(1.5), ErrInvalid)
+	assert.ErrorIs(t, d.Scan("NaN"), ErrInvalid)
+}
@@ -0,0 +1,119 @@
+// Package errreport delivers panics and error-level logs to an error tracking service (Sentry).
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Уровни событий
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Frame is a single frame of a stack trace
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// Event describes a single error occurrence
+type Event struct {
+	Level     string
+	Message   string
+	ErrorType string
+	Tags      map[string]string
+	Extra     map[string]any
+	Stack     []Frame
+	Timestamp time.Time
+}
+
+// Reporter отправляет события в систему отслеживания ошибок
+type Reporter interface {
+	Capture(ctx context.Context, event *Event)
+	// Flush waits until buffered events are sent or the timeout expires
+	Flush(timeout time.Duration)
+}
+
+type holder struct{ Reporter }
+
+var defaultReporter atomic.Value
+
+func init() {
+	defaultReporter.Store(holder{NopReporter{}})
+}
+
+// SetDefault sets the reporter used by Recover and Capture helpers
+func SetDefault(r Reporter) {
+	defaultReporter.Store(holder{r})
+}
+
+// Default returns the reporter set by SetDefault
+func Default() Reporter {
+	return defaultReporter.Load().(holder).Reporter
+}
+
+// NopReporter discards all events. It is the default when no DSN is configured.
+type NopReporter struct{}
+
+func (NopReporter) Capture(context.Context, *Event) {}
+func (NopReporter) Flush(time.Duration)             {}
+
+// CaptureError reports err with the stack trace of the caller
+func CaptureError(ctx context.Context, err error, tags map[string]string) {
+	if err == nil {
+		return
+	}
+	Default().Capture(ctx, &Event{
+		Level:     LevelError,
+		Message:   err.Error(),
+		ErrorType: fmt.Sprintf("%T", err),
+		Tags:      tags,
+		Stack:     Callers(2),
+		Timestamp: time.Now(),
+	})
+}
+
+// CapturePanic reports a recovered panic value with the stack trace of the panicking goroutine
+func CapturePanic(ctx context.Context, recovered any, tags map[string]string) {
+	Default().Capture(ctx, &Event{
+		Level:     LevelFatal,
+		Message:   fmt.Sprintf("panic: %v", recovered),
+		ErrorType: "panic",
+		Tags:      tags,
+		Stack:     Callers(3),
+		Timestamp: time.Now(),
+	})
+}
+
+// Recover must be deferred at the top of long-running goroutines (workers).
+// It reports the panic, waits for delivery and re-panics, so the crash behaviour does not change.
+func Recover(tags map[string]string) {
+	if recovered := recover(); recovered != nil {
+		CapturePanic(context.Background(), recovered, tags)
+		Default().Flush(2 * time.Second)
+		panic(recovered)
+	}
+}
+
+// Callers returns the stack of the calling goroutine, skipping skip frames above Callers itself
+func Callers(skip int) []Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	result := make([]Frame, 0, n)
+	for {
+		frame, more := frames.Next()
+		result = append(result, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return result
+}
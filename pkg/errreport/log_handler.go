@@ -0,0 +1,84 @@
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Атрибуты записей лога, которые становятся тегами события и по которым группируются ошибки
+var tagKeys = map[string]bool{
+	"chain":      true,
+	"worker":     true,
+	"tx_hash":    true,
+	"tx_id":      true,
+	"request_id": true,
+	"user_id":    true,
+	"operation":  true,
+}
+
+// LogHandler reports records of level Error and above to the default reporter
+type LogHandler struct {
+	next  slog.Handler
+	attrs []slog.Attr
+}
+
+// NewLogHandler wraps next with error reporting of error-level records
+func NewLogHandler(next slog.Handler) *LogHandler {
+	return &LogHandler{next: next}
+}
+
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		h.capture(ctx, record)
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	merged = append(merged, h.attrs...)
+	merged = append(merged, attrs...)
+	return &LogHandler{next: h.next.WithAttrs(attrs), attrs: merged}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{next: h.next.WithGroup(name), attrs: h.attrs}
+}
+
+func (h *LogHandler) capture(ctx context.Context, record slog.Record) {
+	event := &Event{
+		Level:     LevelError,
+		Message:   record.Message,
+		ErrorType: record.Message,
+		Tags:      map[string]string{},
+		Extra:     map[string]any{},
+		// Пропускаем кадры самого обработчика и slog
+		Stack:     Callers(4),
+		Timestamp: record.Time,
+	}
+
+	add := func(attr slog.Attr) bool {
+		value := attr.Value.Resolve()
+		switch {
+		case attr.Key == "error":
+			event.Message = fmt.Sprintf("%s: %v", record.Message, value.Any())
+		case tagKeys[attr.Key]:
+			event.Tags[attr.Key] = value.String()
+		default:
+			event.Extra[attr.Key] = value.String()
+		}
+		return true
+	}
+
+	for _, attr := range h.attrs {
+		add(attr)
+	}
+	record.Attrs(add)
+
+	Default().Capture(ctx, event)
+}
@@ -0,0 +1,193 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sentryClient = "p2p-trading-errreport/1.0"
+
+// SentryOptions задаёт параметры отправки событий в Sentry
+type SentryOptions struct {
+	DSN         string
+	Environment string
+	Release     string
+	BufferSize  int
+}
+
+// SentryReporter sends events to Sentry using the store endpoint of the HTTP API
+type SentryReporter struct {
+	storeURL  string
+	publicKey string
+	opts      SentryOptions
+	client    *http.Client
+	server    string
+
+	events chan *Event
+	wg     sync.WaitGroup
+}
+
+// NewSentryReporter parses the DSN (https://<key>@<host>/<project_id>) and starts the delivery goroutine
+func NewSentryReporter(opts SentryOptions) (*SentryReporter, error) {
+	dsn, err := url.Parse(opts.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry DSN: %w", err)
+	}
+	if dsn.User == nil || dsn.User.Username() == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: missing public key")
+	}
+
+	projectID := strings.TrimPrefix(dsn.Path, "/")
+	prefix := ""
+	if idx := strings.LastIndex(projectID, "/"); idx >= 0 {
+		prefix = "/" + projectID[:idx]
+		projectID = projectID[idx+1:]
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: missing project id")
+	}
+
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+
+	server, _ := os.Hostname()
+
+	r := &SentryReporter{
+		storeURL:  fmt.Sprintf("%s://%s%s/api/%s/store/", dsn.Scheme, dsn.Host, prefix, projectID),
+		publicKey: dsn.User.Username(),
+		opts:      opts,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		server:    server,
+		events:    make(chan *Event, opts.BufferSize),
+	}
+
+	go r.run()
+	return r, nil
+}
+
+// Capture enqueues the event. If the buffer is full the event is dropped to never block callers.
+func (r *SentryReporter) Capture(_ context.Context, event *Event) {
+	r.wg.Add(1)
+	select {
+	case r.events <- event:
+	default:
+		r.wg.Done()
+	}
+}
+
+func (r *SentryReporter) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func (r *SentryReporter) run() {
+	for event := range r.events {
+		if err := r.send(event); err != nil {
+			// Используем только stderr: логирование ошибки уровня Error снова попало бы в Sentry
+			_, _ = os.Stderr.WriteString("sentry: " + err.Error() + "\n")
+		}
+		r.wg.Done()
+	}
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func (r *SentryReporter) send(event *Event) error {
+	// Sentry ожидает кадры от самого старого к самому новому
+	frames := make([]sentryFrame, 0, len(event.Stack))
+	for i := len(event.Stack) - 1; i >= 0; i-- {
+		f := event.Stack[i]
+		frames = append(frames, sentryFrame{
+			Function: f.Function,
+			Filename: shortFile(f.File),
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    !strings.HasPrefix(f.Function, "runtime.") && !strings.Contains(f.File, "/pkg/mod/"),
+		})
+	}
+
+	payload := map[string]any{
+		"event_id":    newEventID(),
+		"timestamp":   event.Timestamp.UTC().Format(time.RFC3339Nano),
+		"level":       event.Level,
+		"platform":    "go",
+		"logger":      "slog",
+		"server_name": r.server,
+		"environment": r.opts.Environment,
+		"release":     r.opts.Release,
+		"message":     map[string]string{"formatted": event.Message},
+		"tags":        event.Tags,
+		"extra":       event.Extra,
+		"exception": map[string]any{
+			"values": []map[string]any{{
+				"type":       event.ErrorType,
+				"value":      event.Message,
+				"stacktrace": map[string]any{"frames": frames},
+			}},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s",
+		sentryClient, r.publicKey))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func shortFile(path string) string {
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		if prev := strings.LastIndex(path[:idx], "/"); prev >= 0 {
+			return path[prev+1:]
+		}
+	}
+	return path
+}
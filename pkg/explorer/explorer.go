@@ -0,0 +1,124 @@
+// Package explorer builds block explorer links for transactions and addresses, so API responses
+// and notifications carry ready-made links instead of clients hardcoding explorer URLs per chain.
+package explorer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// site описывает обозреватель сети: базовый адрес, пути страниц транзакции и адреса и общий query
+type site struct {
+	baseURL     string
+	txPath      string
+	addressPath string
+	query       string
+}
+
+func (s site) link(path, value string) string {
+	return s.baseURL + fmt.Sprintf(path, url.PathEscape(value)) + s.query
+}
+
+var (
+	bscScan   = site{txPath: "/tx/%s", addressPath: "/address/%s"}
+	etherscan = site{txPath: "/tx/%s", addressPath: "/address/%s"}
+	solscan   = site{baseURL: "https://solscan.io", txPath: "/tx/%s", addressPath: "/account/%s"}
+	tronscan  = site{txPath: "/#/transaction/%s", addressPath: "/#/address/%s"}
+	tonviewer = site{txPath: "/transaction/%s", addressPath: "/%s"}
+	mempool   = site{txPath: "/tx/%s", addressPath: "/address/%s"}
+)
+
+func with(s site, baseURL, query string) site {
+	if baseURL != "" {
+		s.baseURL = baseURL
+	}
+	s.query = query
+	return s
+}
+
+// Обозреватели по сети и окружению, ключ — chain/network
+var defaultSites = map[string]site{
+	"bsc/mainnet":      with(bscScan, "https://bscscan.com", ""),
+	"bsc/testnet":      with(bscScan, "https://testnet.bscscan.com", ""),
+	"ethereum/mainnet": with(etherscan, "https://etherscan.io", ""),
+	"ethereum/testnet": with(etherscan, "https://sepolia.etherscan.io", ""),
+	"ethereum/sepolia": with(etherscan, "https://sepolia.etherscan.io", ""),
+	"solana/mainnet":   solscan,
+	"solana/devnet":    with(solscan, "", "?cluster=devnet"),
+	"solana/testnet":   with(solscan, "", "?cluster=testnet"),
+	"tron/mainnet":     with(tronscan, "https://tronscan.org", ""),
+	"tron/nile":        with(tronscan, "https://nile.tronscan.org", ""),
+	"tron/shasta":      with(tronscan, "https://shasta.tronscan.org", ""),
+	"ton/mainnet":      with(tonviewer, "https://tonviewer.com", ""),
+	"ton/testnet":      with(tonviewer, "https://testnet.tonviewer.com", ""),
+	"bitcoin/mainnet":  with(mempool, "https://mempool.space", ""),
+	"bitcoin/testnet":  with(mempool, "https://mempool.space/testnet", ""),
+}
+
+// Links builds explorer URLs. The zero value is not usable, create it with New.
+type Links struct {
+	sites map[string]site
+}
+
+// New returns links for the known explorers. overrides replace the base URL of a network in the form
+// chain/network=https://explorer.example, e.g. for a self-hosted explorer; the page paths of the chain are kept.
+func New(overrides []string) (*Links, error) {
+	sites := make(map[string]site, len(defaultSites))
+	for key, s := range defaultSites {
+		sites[key] = s
+	}
+
+	for _, entry := range overrides {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, baseURL, ok := strings.Cut(entry, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+		chain, _, hasNetwork := strings.Cut(key, "/")
+		if !ok || !hasNetwork {
+			return nil, fmt.Errorf("invalid explorer override %q, expected chain/network=url", entry)
+		}
+		if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid explorer URL in %q", entry)
+		}
+
+		s, known := sites[key]
+		if !known {
+			// Пути страниц берем у любого окружения той же сети
+			for other, candidate := range defaultSites {
+				if strings.HasPrefix(other, chain+"/") {
+					s, known = candidate, true
+					break
+				}
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown chain %q in explorer override %q", chain, entry)
+		}
+		sites[key] = with(s, baseURL, "")
+	}
+
+	return &Links{sites: sites}, nil
+}
+
+// TxURL returns the explorer page of the transaction or "" if the network has no known explorer
+func (l *Links) TxURL(chain, network, hash string) string {
+	s, ok := l.sites[chain+"/"+network]
+	if !ok || hash == "" {
+		return ""
+	}
+	return s.link(s.txPath, hash)
+}
+
+// AddressURL returns the explorer page of the address or "" if the network has no known explorer
+func (l *Links) AddressURL(chain, network, address string) string {
+	s, ok := l.sites[chain+"/"+network]
+	if !ok || address == "" {
+		return ""
+	}
+	return s.link(s.addressPath, address)
+}
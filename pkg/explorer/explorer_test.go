@@ -0,0 +1,48 @@
+package explorer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	links, err := New(nil)
+	require.NoError(t, err)
+
+	cases := []struct {
+		chain, network, want string
+	}{
+		{"bsc", "mainnet", "https://bscscan.com/tx/0xabc"},
+		{"bsc", "testnet", "https://testnet.bscscan.com/tx/0xabc"},
+		{"ethereum", "testnet", "https://sepolia.etherscan.io/tx/0xabc"},
+		{"solana", "devnet", "https://solscan.io/tx/0xabc?cluster=devnet"},
+		{"tron", "nile", "https://nile.tronscan.org/#/transaction/0xabc"},
+		{"ton", "mainnet", "https://tonviewer.com/transaction/0xabc"},
+		{"bitcoin", "testnet", "https://mempool.space/testnet/tx/0xabc"},
+		{"dogecoin", "mainnet", ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, links.TxURL(c.chain, c.network, "0xabc"), "%s/%s", c.chain, c.network)
+	}
+
+	assert.Equal(t, "https://bscscan.com/address/0x1234", links.AddressURL("bsc", "mainnet", "0x1234"))
+	assert.Equal(t, "https://solscan.io/account/So111", links.AddressURL("solana", "mainnet", "So111"))
+	assert.Equal(t, "https://tonviewer.com/0:ab%2Fcd", links.AddressURL("ton", "mainnet", "0:ab/cd"))
+	assert.Empty(t, links.TxURL("bsc", "mainnet", ""))
+}
+
+func TestOverrides(t *testing.T) {
+	links, err := New([]string{"bsc/testnet=https://blockscout.example/", "tron/local=http://localhost:8080"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://blockscout.example/tx/0x1", links.TxURL("bsc", "testnet", "0x1"))
+	assert.Equal(t, "https://bscscan.com/tx/0x1", links.TxURL("bsc", "mainnet", "0x1"))
+	assert.Equal(t, "http://localhost:8080/#/address/T1", links.AddressURL("tron", "local", "T1"))
+
+	for _, invalid := range []string{"bsc=https://x.example", "bsc/mainnet", "bsc/mainnet=ftp://x.example", "doge/mainnet=https://x.example"} {
+		_, err := New([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,43 @@
+// Package logger contains slog handlers used by the application.
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AttrsFromContext extracts attributes that should be added to every record logged with the context
+type AttrsFromContext func(ctx context.Context) []slog.Attr
+
+// ContextHandler adds attributes stored in the context (request ID, user ID, ...) to log records
+type ContextHandler struct {
+	next    slog.Handler
+	extract AttrsFromContext
+}
+
+// NewContextHandler wraps next with a handler that enriches records with attributes from the context
+func NewContextHandler(next slog.Handler, extract AttrsFromContext) *ContextHandler {
+	return &ContextHandler{next: next, extract: extract}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if attrs := h.extract(ctx); len(attrs) > 0 {
+			record = record.Clone()
+			record.AddAttrs(attrs...)
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs), extract: h.extract}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name), extract: h.extract}
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/errreport"
+)
+
+// Форматы вывода логов
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Внешние хранилища логов
+const (
+	SinkNone          = ""
+	SinkLoki          = "loki"
+	SinkElasticsearch = "elasticsearch"
+)
+
+// Options описывает конфигурацию логгера приложения
+type Options struct {
+	Level  slog.Leveler
+	Format string
+
+	// Sampling включается, если задано First > 0
+	Sampling SamplingOptions
+
+	Sink       string
+	SinkURL    string
+	SinkIndex  string            // Elasticsearch index
+	SinkLabels map[string]string // Loki stream labels
+	Shipper    ShipperOptions
+
+	Extract AttrsFromContext
+
+	// ReportErrors отправляет записи уровня Error в errreport.Default()
+	ReportErrors bool
+}
+
+// New builds the application logger. The returned closer flushes records buffered for the sink.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(os.Stdout, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(os.Stdout, handlerOpts)
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	var closer io.Closer = nopCloser{}
+
+	var sink Sink
+	switch strings.ToLower(opts.Sink) {
+	case SinkNone:
+	case SinkLoki:
+		sink = NewLokiSink(opts.SinkURL, opts.SinkLabels)
+	case SinkElasticsearch:
+		sink = NewElasticsearchSink(opts.SinkURL, opts.SinkIndex)
+	default:
+		return nil, nil, fmt.Errorf("unknown log sink %q", opts.Sink)
+	}
+
+	if sink != nil {
+		if opts.SinkURL == "" {
+			return nil, nil, fmt.Errorf("log sink %q requires URL", opts.Sink)
+		}
+		shipper := NewShipper(sink, opts.Shipper)
+		closer = shipper
+		// Во внешнее хранилище всегда пишем JSON, независимо от формата stdout
+		handler = NewMultiHandler(handler, slog.NewJSONHandler(shipper, handlerOpts))
+	}
+
+	if opts.Sampling.First > 0 {
+		handler = NewSamplingHandler(handler, opts.Sampling)
+	}
+
+	if opts.ReportErrors {
+		handler = errreport.NewLogHandler(handler)
+	}
+
+	if opts.Extract != nil {
+		handler = NewContextHandler(handler, opts.Extract)
+	}
+
+	return slog.New(handler), closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
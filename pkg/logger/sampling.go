@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingOptions ограничивает количество однотипных записей в секунду.
+// Первые First записей с одинаковым сообщением за интервал пропускаются, далее — каждая Thereafter-я.
+type SamplingOptions struct {
+	// MaxLevel — записи этого уровня и ниже подлежат сэмплированию (обычно Debug)
+	MaxLevel   slog.Level
+	First      uint64
+	Thereafter uint64
+	Tick       time.Duration
+}
+
+// SamplingHandler drops repetitive low level records from noisy paths such as block scanning
+type SamplingHandler struct {
+	next    slog.Handler
+	opts    SamplingOptions
+	counter *sampleCounter
+}
+
+type sampleCounter struct {
+	mu     sync.Mutex
+	counts map[string]*atomic.Uint64
+	reset  time.Time
+}
+
+// NewSamplingHandler wraps next with sampling of records at or below opts.MaxLevel
+func NewSamplingHandler(next slog.Handler, opts SamplingOptions) *SamplingHandler {
+	if opts.Tick <= 0 {
+		opts.Tick = time.Second
+	}
+	return &SamplingHandler{
+		next: next,
+		opts: opts,
+		counter: &sampleCounter{
+			counts: make(map[string]*atomic.Uint64),
+			reset:  time.Now(),
+		},
+	}
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level <= h.opts.MaxLevel && !h.counter.allow(record.Message, record.Time, h.opts) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), opts: h.opts, counter: h.counter}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), opts: h.opts, counter: h.counter}
+}
+
+func (c *sampleCounter) allow(message string, now time.Time, opts SamplingOptions) bool {
+	c.mu.Lock()
+	if now.Sub(c.reset) >= opts.Tick {
+		c.counts = make(map[string]*atomic.Uint64)
+		c.reset = now
+	}
+	counter, ok := c.counts[message]
+	if !ok {
+		counter = &atomic.Uint64{}
+		c.counts[message] = counter
+	}
+	c.mu.Unlock()
+
+	n := counter.Add(1)
+	if n <= opts.First {
+		return true
+	}
+	return opts.Thereafter > 0 && (n-opts.First)%opts.Thereafter == 0
+}
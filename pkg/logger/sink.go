@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink отправляет пачку JSON записей во внешнее хранилище логов
+type Sink interface {
+	Send(ctx context.Context, lines [][]byte) error
+}
+
+// ShipperOptions задаёт параметры буферизации отправки логов
+type ShipperOptions struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// Shipper is an io.Writer for slog.JSONHandler that ships records to a Sink in batches.
+// Writes never block the application: when the buffer is full or the shipper is closed the record is dropped.
+type Shipper struct {
+	sink    Sink
+	opts    ShipperOptions
+	entries chan []byte
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+
+	// Канал записей не закрывается: после Close записи отбрасываются под этой блокировкой
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewShipper starts a background goroutine shipping buffered records to the sink
+func NewShipper(sink Sink, opts ShipperOptions) *Shipper {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 10000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 2 * time.Second
+	}
+
+	s := &Shipper{
+		sink:    sink,
+		opts:    opts,
+		entries: make(chan []byte, opts.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write enqueues a single JSON record. slog handlers call Write once per record.
+func (s *Shipper) Write(p []byte) (int, error) {
+	line := make([]byte, len(p))
+	copy(line, p)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return len(p), nil
+	}
+
+	select {
+	case s.entries <- line:
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of records dropped because the buffer was full or the shipper was closed
+func (s *Shipper) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close flushes buffered records and stops the background goroutine. Records written later are dropped.
+func (s *Shipper) Close() error {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+func (s *Shipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := s.sink.Send(ctx, batch); err != nil {
+			// Логгер здесь использовать нельзя: запись снова попала бы в этот же sink
+			_, _ = os.Stderr.WriteString("log sink error: " + err.Error() + "\n")
+		}
+		cancel()
+		batch = make([][]byte, 0, s.opts.BatchSize)
+	}
+
+	add := func(line []byte) {
+		batch = append(batch, line)
+		if len(batch) >= s.opts.BatchSize {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case line := <-s.entries:
+			add(line)
+		case <-ticker.C:
+			flush()
+		case <-s.stop:
+			// Новые записи уже не поступают, отправляем оставшиеся в буфере
+			for {
+				select {
+				case line := <-s.entries:
+					add(line)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// MultiHandler duplicates every record to all handlers
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler creates a handler writing to all of the given handlers
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *MultiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var firstErr error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &MultiHandler{handlers: handlers}
+}
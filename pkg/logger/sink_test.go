@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySink struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (s *memorySink) Send(_ context.Context, lines [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, line := range lines {
+		s.lines = append(s.lines, string(line))
+	}
+	return nil
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.lines)
+}
+
+func TestShipperCloseFlushesBuffered(t *testing.T) {
+	sink := &memorySink{}
+	shipper := NewShipper(sink, ShipperOptions{BatchSize: 100, FlushInterval: time.Hour})
+
+	for range 10 {
+		_, _ = shipper.Write([]byte(`{"msg":"x"}`))
+	}
+	assert.NoError(t, shipper.Close())
+	assert.Equal(t, 10, sink.count())
+}
+
+func TestShipperWriteAfterCloseIsDropped(t *testing.T) {
+	sink := &memorySink{}
+	shipper := NewShipper(sink, ShipperOptions{})
+	assert.NoError(t, shipper.Close())
+	assert.NoError(t, shipper.Close())
+
+	n, err := shipper.Write([]byte(`{"msg":"late"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 14, n)
+	assert.Equal(t, uint64(1), shipper.Dropped())
+	assert.Equal(t, 0, sink.count())
+}
+
+func TestShipperConcurrentWritesDuringClose(t *testing.T) {
+	sink := &memorySink{}
+	shipper := NewShipper(sink, ShipperOptions{BufferSize: 100000, FlushInterval: time.Millisecond})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				_, _ = shipper.Write([]byte(`{"msg":"x"}`))
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, shipper.Close())
+	wg.Wait()
+
+	assert.Equal(t, 8000, sink.count()+int(shipper.Dropped()))
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var sinkHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// LokiSink pushes records to Grafana Loki via the HTTP push API
+type LokiSink struct {
+	url    string
+	labels map[string]string
+}
+
+// NewLokiSink creates a sink for the Loki base URL (e.g. http://loki:3100) with static stream labels
+func NewLokiSink(baseURL string, labels map[string]string) *LokiSink {
+	// Loki отклоняет потоки с пустыми значениями меток
+	stream := make(map[string]string, len(labels))
+	for name, value := range labels {
+		if value != "" {
+			stream[name] = value
+		}
+	}
+	if len(stream) == 0 {
+		stream["job"] = "p2p-trading"
+	}
+
+	return &LokiSink{
+		url:    strings.TrimRight(baseURL, "/") + "/loki/api/v1/push",
+		labels: stream,
+	}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *LokiSink) Send(ctx context.Context, lines [][]byte) error {
+	values := make([][2]string, 0, len(lines))
+	now := time.Now().UnixNano()
+	for i, line := range lines {
+		// Loki требует уникальные и неубывающие метки времени внутри потока
+		ts := strconv.FormatInt(now+int64(i), 10)
+		values = append(values, [2]string{ts, string(bytes.TrimRight(line, "\n"))})
+	}
+
+	body, err := json.Marshal(map[string][]lokiStream{
+		"streams": {{Stream: s.labels, Values: values}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal loki push request: %w", err)
+	}
+
+	return post(ctx, s.url, "application/json", body)
+}
+
+// ElasticsearchSink indexes records via the Elasticsearch bulk API
+type ElasticsearchSink struct {
+	url   string
+	index string
+}
+
+// NewElasticsearchSink creates a sink for the Elasticsearch base URL writing to the given index
+func NewElasticsearchSink(baseURL, index string) *ElasticsearchSink {
+	return &ElasticsearchSink{
+		url:   strings.TrimRight(baseURL, "/") + "/_bulk",
+		index: index,
+	}
+}
+
+func (s *ElasticsearchSink) Send(ctx context.Context, lines [][]byte) error {
+	action, err := json.Marshal(map[string]map[string]string{"index": {"_index": s.index}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk action: %w", err)
+	}
+
+	var body bytes.Buffer
+	for _, line := range lines {
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(bytes.TrimRight(line, "\n"))
+		body.WriteByte('\n')
+	}
+
+	return post(ctx, s.url, "application/x-ndjson", body.Bytes())
+}
+
+func post(ctx context.Context, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create sink request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := sinkHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("log sink returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package lru implements a size-bounded least recently used cache safe for concurrent use.
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Cache keeps at most capacity entries. Adding to a full cache evicts the least recently used entry.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Начало списка — последние использованные
+	items    map[K]*list.Element
+}
+
+// New creates a cache of the capacity. A non-positive capacity is treated as 1.
+func New[K comparable, V any](capacity int) *Cache[K, V] {
+	capacity = max(capacity, 1)
+	return &Cache[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[K]*list.Element, capacity),
+	}
+}
+
+// Get returns the value of the key and marks it as recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Peek returns the value of the key without changing its recency
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Add stores the value as the most recently used and reports whether another entry was evicted to make room
+func (c *Cache[K, V]) Add(key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(element)
+		return false
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+	if c.order.Len() <= c.capacity {
+		return false
+	}
+	oldest := c.order.Back()
+	c.order.Remove(oldest)
+	delete(c.items, oldest.Value.(*entry[K, V]).key)
+	return true
+}
+
+// Update replaces the value of a cached key without changing its recency. Returns false if the key is not cached.
+// Background refreshes use it so that a pass over every key does not push out the entries readers use.
+func (c *Cache[K, V]) Update(key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	element.Value.(*entry[K, V]).value = value
+	return true
+}
+
+// Remove deletes the key and reports whether it was cached
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.order.Remove(element)
+	delete(c.items, key)
+	return true
+}
+
+// Purge deletes all entries
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.items)
+}
+
+// Len returns the number of cached entries
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Capacity returns the maximum number of entries
+func (c *Cache[K, V]) Capacity() int {
+	return c.capacity
+}
+
+// Range calls fn for every entry from the most to the least recently used until fn returns false.
+// The cache is locked during the iteration, fn must not call its methods.
+func (c *Cache[K, V]) Range(fn func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		e := element.Value.(*entry[K, V])
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package lru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func keys(c *Cache[string, int]) []string {
+	var result []string
+	c.Range(func(key string, _ int) bool {
+		result = append(result, key)
+		return true
+	})
+	return result
+}
+
+func TestAddEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string, int](2)
+
+	assert.False(t, c.Add("a", 1))
+	assert.False(t, c.Add("b", 2))
+
+	// Чтение делает "a" последним использованным, вытесняется "b"
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	assert.True(t, c.Add("c", 3))
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"c", "a"}, keys(c))
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestAddExistingKeyDoesNotEvict(t *testing.T) {
+	c := New[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	assert.False(t, c.Add("a", 10))
+	value, _ := c.Peek("a")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, []string{"a", "b"}, keys(c))
+}
+
+func TestPeekAndUpdateKeepRecency(t *testing.T) {
+	c := New[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	value, ok := c.Peek("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.True(t, c.Update("a", 5))
+	assert.False(t, c.Update("missing", 1))
+	assert.Equal(t, []string{"b", "a"}, keys(c))
+
+	// "a" остался самым старым и вытесняется первым
+	assert.True(t, c.Add("c", 3))
+	_, ok = c.Peek("a")
+	assert.False(t, ok)
+}
+
+func TestRemoveAndPurge(t *testing.T) {
+	c := New[string, int](3)
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	assert.True(t, c.Remove("a"))
+	assert.False(t, c.Remove("a"))
+	assert.Equal(t, []string{"b"}, keys(c))
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+	assert.False(t, c.Add("c", 3))
+	assert.Equal(t, []string{"c"}, keys(c))
+}
+
+func TestRangeStops(t *testing.T) {
+	c := New[string, int](3)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+
+	var visited []string
+	c.Range(func(key string, _ int) bool {
+		visited = append(visited, key)
+		return len(visited) < 2
+	})
+	assert.Equal(t, []string{"c", "b"}, visited)
+}
+
+func TestNonPositiveCapacity(t *testing.T) {
+	c := New[string, int](0)
+	assert.Equal(t, 1, c.Capacity())
+	c.Add("a", 1)
+	assert.True(t, c.Add("b", 2))
+	assert.Equal(t, []string{"b"}, keys(c))
+}
+
+func TestConcurrentUseStaysBounded(t *testing.T) {
+	c := New[string, int](100)
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := strconv.Itoa(worker*1000 + i)
+				c.Add(key, i)
+				c.Get(key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, c.Len())
+}
@@ -0,0 +1,180 @@
+// Package qrcode encodes short strings (payment URIs) into QR codes.
+// Supports byte mode with error correction level M for versions 1-10 (up to 213 bytes),
+// which is enough for EIP-681 payment links.
+package qrcode
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrTooLong is returned when the content does not fit into the largest supported version
+var ErrTooLong = errors.New("qrcode: content too long")
+
+// Параметры блоков коррекции ошибок уровня M для версий 1-10
+type blockLayout struct {
+	ecPerBlock  int
+	shortBlocks int
+	shortData   int // data codewords in short blocks, long blocks have one more
+	longBlocks  int
+}
+
+var levelMBlocks = [...]blockLayout{
+	1:  {10, 1, 16, 0},
+	2:  {16, 1, 28, 0},
+	3:  {26, 1, 44, 0},
+	4:  {18, 2, 32, 0},
+	5:  {24, 2, 43, 0},
+	6:  {16, 4, 27, 0},
+	7:  {18, 4, 31, 0},
+	8:  {22, 2, 38, 2},
+	9:  {22, 3, 36, 2},
+	10: {26, 4, 43, 1},
+}
+
+var alignmentPositions = [...][]int{
+	1:  nil,
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+const maxVersion = 10
+
+// Code is an encoded QR symbol without the quiet zone
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode encodes the content choosing the smallest version that fits
+func Encode(content string) (*Code, error) {
+	data := []byte(content)
+
+	version := 0
+	for v := 1; v <= maxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= levelMBlocks[v].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(version, encodeData(version, data))
+
+	q := newSymbol(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(codewords)
+
+	// Выбираем маску с наименьшим штрафом
+	bestMask, bestPenalty := 0, math.MaxInt
+	for mask := range 8 {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // XOR снимает маску
+	}
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+
+	return &Code{Size: q.size, modules: q.modules}, nil
+}
+
+func (b blockLayout) dataCodewords() int {
+	return b.shortBlocks*b.shortData + b.longBlocks*(b.shortData+1)
+}
+
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// encodeData builds the byte mode bit stream padded to the data capacity of the version
+func encodeData(version int, data []byte) []byte {
+	capacity := levelMBlocks[version].dataCodewords()
+
+	var bits bitBuffer
+	bits.append(0b0100, 4) // byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	bits.append(0, min(4, capacity*8-len(bits))) // terminator
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity*8; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	return bits.bytes()
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon codewords and interleaves them
+func addErrorCorrection(version int, data []byte) []byte {
+	layout := levelMBlocks[version]
+	divisor := rsDivisor(layout.ecPerBlock)
+
+	var blocks, ecBlocks [][]byte
+	offset := 0
+	for i := range layout.shortBlocks + layout.longBlocks {
+		n := layout.shortData
+		if i >= layout.shortBlocks {
+			n++
+		}
+		block := data[offset : offset+n]
+		offset += n
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+	}
+
+	result := make([]byte, 0, len(data)+len(blocks)*layout.ecPerBlock)
+	for i := range layout.shortData + 1 {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range layout.ecPerBlock {
+		for _, ec := range ecBlocks {
+			result = append(result, ec[i])
+		}
+	}
+
+	return result
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
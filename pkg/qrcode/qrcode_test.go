@@ -0,0 +1,156 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// "HELLO WORLD", version 1-M (ISO/IEC 18004 worked example)
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	assert.Equal(t, expected, rsRemainder(data, rsDivisor(10)))
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	q := newSymbol(7)
+	q.drawFormatBits(0)
+	q.drawVersion()
+
+	// Level M, mask 0 -> 101010000010010
+	var format int
+	for i := 0; i <= 5; i++ {
+		format |= boolBit(q.modules[i][8]) << i
+	}
+	format |= boolBit(q.modules[7][8]) << 6
+	format |= boolBit(q.modules[8][8]) << 7
+	format |= boolBit(q.modules[8][7]) << 8
+	for i := 9; i < 15; i++ {
+		format |= boolBit(q.modules[8][14-i]) << i
+	}
+	assert.Equal(t, 0b101010000010010, format)
+
+	// Version 7 information -> 000111110010010100
+	var version int
+	for i := range 18 {
+		version |= boolBit(q.modules[i/3][q.size-11+i%3]) << i
+	}
+	assert.Equal(t, 0x07C94, version)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, content := range []string{
+		"hello",
+		"ethereum:0x55d398326f99059fF775485246999027B3197955@56/transfer?address=0x8ba1f109551bD432803012645Ac136ddd64DBA72&uint256=100003700000000000000",
+		strings.Repeat("x", 213),
+	} {
+		code, err := Encode(content)
+		require.NoError(t, err)
+
+		assert.Equal(t, content, decodeForTest(t, code))
+	}
+
+	_, err := Encode(strings.Repeat("x", 214))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestRender(t *testing.T) {
+	code, err := Encode("hello")
+	require.NoError(t, err)
+
+	img, err := code.PNG(4)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(img, []byte("\x89PNG")))
+
+	assert.Contains(t, string(code.SVG()), `viewBox="0 0 29 29"`)
+}
+
+// decodeForTest reads the symbol back: format info, unmasking, zigzag order and deinterleaving
+func decodeForTest(t *testing.T, code *Code) string {
+	t.Helper()
+
+	version := (code.Size - 17) / 4
+	q := newSymbol(version)
+	q.drawFunctionPatterns()
+
+	var format int
+	for i := range 8 {
+		format |= boolBit(code.Dark(q.size-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		format |= boolBit(code.Dark(8, q.size-15+i)) << i
+	}
+	format ^= 0x5412
+	require.Equal(t, 0, format>>13, "error correction level must be M")
+	mask := (format >> 10) & 0b111
+
+	for y := range q.size {
+		for x := range q.size {
+			q.modules[y][x] = code.Dark(x, y)
+		}
+	}
+	q.applyMask(mask)
+
+	var bits bitBuffer
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.isFunction[y][x] {
+					bits = append(bits, q.modules[y][x])
+				}
+			}
+		}
+	}
+	raw := bits.bytes()
+
+	layout := levelMBlocks[version]
+	blocks := make([][]byte, layout.shortBlocks+layout.longBlocks)
+	pos := 0
+	for i := range layout.shortData + 1 {
+		for b := range blocks {
+			if i < layout.shortData || b >= layout.shortBlocks {
+				blocks[b] = append(blocks[b], raw[pos])
+				pos++
+			}
+		}
+	}
+	var data []byte
+	for _, block := range blocks {
+		data = append(data, block...)
+	}
+
+	// Проверяем коды коррекции ошибок
+	assert.Equal(t, raw[:len(addErrorCorrection(version, data))], addErrorCorrection(version, data))
+
+	var stream bitBuffer
+	for _, b := range data {
+		stream.append(int(b), 8)
+	}
+	require.Equal(t, bitBuffer{false, true, false, false}, stream[:4], "byte mode")
+	length := 0
+	for _, v := range stream[4 : 4+countBits(version)] {
+		length = length<<1 | boolBit(v)
+	}
+	payload := bitBuffer(stream[4+countBits(version) : 4+countBits(version)+8*length]).bytes()
+
+	return string(payload)
+}
+
+func boolBit(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
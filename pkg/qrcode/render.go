@@ -0,0 +1,59 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// QuietZone — ширина белой рамки в модулях, требуемая стандартом
+const QuietZone = 4
+
+// PNG renders the code as a black and white PNG with the given module size in pixels
+func (c *Code) PNG(scale int) ([]byte, error) {
+	if scale <= 0 {
+		scale = 1
+	}
+
+	side := (c.Size + 2*QuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := range c.Size {
+		for x := range c.Size {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for dy := range scale {
+				for dx := range scale {
+					img.SetColorIndex((x+QuietZone)*scale+dx, (y+QuietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("qrcode: failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as a scalable SVG image, one unit per module
+func (c *Code) SVG() []byte {
+	side := c.Size + 2*QuietZone
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, side, side)
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,223 @@
+// Package rpcbatch bundles many JSON-RPC calls into batch requests (rpc.Client.BatchCallContext)
+// to reduce round-trips for receipts, balances and contract calls.
+package rpcbatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultBatchSize — число вызовов в одном batch запросе. Публичные ноды BSC обычно ограничивают batch 50-100 вызовами.
+const DefaultBatchSize = 50
+
+// Batcher splits calls into batches of at most size elements
+type Batcher struct {
+	client *rpc.Client
+	size   int
+}
+
+// New creates a new batcher over the RPC client
+func New(client *rpc.Client, size int) *Batcher {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	return &Batcher{client: client, size: size}
+}
+
+// Call sends the elements in batches. Per-call errors are stored in BatchElem.Error,
+// the returned error means a whole batch could not be delivered.
+func (b *Batcher) Call(ctx context.Context, elems []rpc.BatchElem) error {
+	for start := 0; start < len(elems); start += b.size {
+		end := min(start+b.size, len(elems))
+		if err := b.client.BatchCallContext(ctx, elems[start:end]); err != nil {
+			return fmt.Errorf("batch call failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// BlockNumberAndReceipts fetches the chain head and receipts of the given transactions in one round-trip
+// (per batch). A nil receipt means the transaction is not mined (or was reorged out).
+func (b *Batcher) BlockNumberAndReceipts(ctx context.Context, hashes []common.Hash) (uint64, map[common.Hash]*types.Receipt, error) {
+	var head hexutil.Uint64
+	receipts := make([]*types.Receipt, len(hashes))
+
+	elems := make([]rpc.BatchElem, 0, len(hashes)+1)
+	elems = append(elems, rpc.BatchElem{Method: "eth_blockNumber", Result: &head})
+	for i, hash := range hashes {
+		elems = append(elems, rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []any{hash},
+			Result: &receipts[i],
+		})
+	}
+
+	if err := b.Call(ctx, elems); err != nil {
+		return 0, nil, err
+	}
+	if elems[0].Error != nil {
+		return 0, nil, fmt.Errorf("failed to get block number: %w", elems[0].Error)
+	}
+
+	result := make(map[common.Hash]*types.Receipt, len(hashes))
+	for i, hash := range hashes {
+		if elems[i+1].Error != nil {
+			continue
+		}
+		result[hash] = receipts[i]
+	}
+
+	return uint64(head), result, nil
+}
+
+// BalancesAt fetches native balances at the latest block. Addresses whose call failed are reported in errs.
+func (b *Batcher) BalancesAt(ctx context.Context, addresses []common.Address) ([]*big.Int, []error, error) {
+	results := make([]hexutil.Big, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []any{address, "latest"},
+			Result: &results[i],
+		}
+	}
+
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, nil, err
+	}
+
+	balances := make([]*big.Int, len(addresses))
+	errs := make([]error, len(addresses))
+	for i := range elems {
+		if elems[i].Error != nil {
+			errs[i] = elems[i].Error
+			continue
+		}
+		balances[i] = results[i].ToInt()
+	}
+
+	return balances, errs, nil
+}
+
+// CallContracts executes eth_call for every message at the latest block
+func (b *Batcher) CallContracts(ctx context.Context, msgs []ethereum.CallMsg) ([][]byte, []error, error) {
+	results := make([]hexutil.Bytes, len(msgs))
+	elems := make([]rpc.BatchElem, len(msgs))
+	for i, msg := range msgs {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []any{toCallArg(msg), "latest"},
+			Result: &results[i],
+		}
+	}
+
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, nil, err
+	}
+
+	outputs := make([][]byte, len(msgs))
+	errs := make([]error, len(msgs))
+	for i := range elems {
+		if elems[i].Error != nil {
+			errs[i] = elems[i].Error
+			continue
+		}
+		outputs[i] = results[i]
+	}
+
+	return outputs, errs, nil
+}
+
+// RawEvidence holds the node responses for a transaction exactly as returned: the transaction with its input data,
+// the receipt and the header of its block (eth_getBlockByHash without transaction bodies)
+type RawEvidence struct {
+	Transaction json.RawMessage `json:"transaction"`
+	Receipt     json.RawMessage `json:"receipt"`
+	Block       json.RawMessage `json:"block"`
+}
+
+// RawEvidence fetches the raw transaction, receipt and block header of every hash in two rounds: transactions
+// with receipts, then the blocks named by the receipts. A transaction that is not found or not mined is reported in errs.
+func (b *Batcher) RawEvidence(ctx context.Context, hashes []common.Hash) ([]RawEvidence, []error, error) {
+	evidence := make([]RawEvidence, len(hashes))
+	errs := make([]error, len(hashes))
+
+	elems := make([]rpc.BatchElem, 0, 2*len(hashes))
+	for i, hash := range hashes {
+		elems = append(elems,
+			rpc.BatchElem{Method: "eth_getTransactionByHash", Args: []any{hash}, Result: &evidence[i].Transaction},
+			rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []any{hash}, Result: &evidence[i].Receipt})
+	}
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		blocks  []rpc.BatchElem
+		pending []int
+	)
+	for i := range hashes {
+		if err := errors.Join(elems[2*i].Error, elems[2*i+1].Error); err != nil {
+			errs[i] = err
+			continue
+		}
+		if isNull(evidence[i].Transaction) || isNull(evidence[i].Receipt) {
+			errs[i] = errors.New("transaction not found or not mined")
+			continue
+		}
+
+		var receipt struct {
+			BlockHash common.Hash `json:"blockHash"`
+		}
+		if err := json.Unmarshal(evidence[i].Receipt, &receipt); err != nil {
+			errs[i] = fmt.Errorf("invalid receipt: %w", err)
+			continue
+		}
+		blocks = append(blocks, rpc.BatchElem{Method: "eth_getBlockByHash", Args: []any{receipt.BlockHash, false}, Result: &evidence[i].Block})
+		pending = append(pending, i)
+	}
+	if err := b.Call(ctx, blocks); err != nil {
+		return nil, nil, err
+	}
+
+	for j, i := range pending {
+		switch {
+		case blocks[j].Error != nil:
+			errs[i] = blocks[j].Error
+		case isNull(evidence[i].Block):
+			errs[i] = errors.New("block not found")
+		}
+	}
+
+	return evidence, errs, nil
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+func toCallArg(msg ethereum.CallMsg) map[string]any {
+	arg := map[string]any{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	return arg
+}
@@ -0,0 +1,204 @@
+package rpcmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrEndpointDisabled is returned for requests to an endpoint disabled by an operator
+	ErrEndpointDisabled = errors.New("rpc endpoint disabled")
+	// ErrCircuitOpen is returned while the endpoint breaker is open after consecutive failures
+	ErrCircuitOpen = errors.New("rpc endpoint circuit open")
+	// ErrUnknownEndpoint is returned when an operator action names an endpoint the manager never dialed
+	ErrUnknownEndpoint = errors.New("unknown rpc endpoint")
+)
+
+// Состояния автомата отключения эндпоинта
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// Вес последнего запроса в скользящих средних задержки и доли ошибок
+const (
+	latencySmoothing   = 0.2
+	errorRateSmoothing = 0.1
+)
+
+// health — состояние эндпоинта для операторов и автомат отключения: после breakerThreshold ошибок подряд
+// запросы отклоняются сразу в течение cooldown, затем пропускается один пробный запрос
+type health struct {
+	mu sync.Mutex
+
+	threshold int
+	cooldown  time.Duration
+
+	latency     float64 // миллисекунды, скользящее среднее
+	errorRate   float64 // доля ошибок, скользящее среднее
+	sampled     bool
+	failures    int // ошибок подряд
+	openUntil   time.Time
+	probing     bool
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+
+	disabled       bool
+	disabledReason string
+	disabledAt     time.Time
+}
+
+// admit reports whether a request may be sent now. In the half-open state only one probe is in flight.
+func (h *health) admit() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.disabled {
+		return ErrEndpointDisabled
+	}
+	if h.openUntil.IsZero() {
+		return nil
+	}
+	if time.Now().Before(h.openUntil) || h.probing {
+		return ErrCircuitOpen
+	}
+	h.probing = true
+	return nil
+}
+
+// record accounts the outcome of a request admitted by admit
+func (h *health) record(elapsed time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.probing = false
+	// Запрос, отмененный вызывающей стороной, ничего не говорит о состоянии эндпоинта
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	millis := float64(elapsed.Microseconds()) / 1000
+	if !h.sampled {
+		h.latency, h.errorRate, h.sampled = millis, failed, true
+	} else {
+		h.latency += latencySmoothing * (millis - h.latency)
+		h.errorRate += errorRateSmoothing * (failed - h.errorRate)
+	}
+
+	if err == nil {
+		h.failures = 0
+		h.openUntil = time.Time{}
+		h.lastSuccess = time.Now()
+		return
+	}
+
+	h.failures++
+	h.lastError = err.Error()
+	h.lastErrorAt = time.Now()
+	// Неудачная проба или серия ошибок снова открывает автомат на время cooldown
+	if h.threshold > 0 && (h.failures >= h.threshold || !h.openUntil.IsZero()) {
+		h.openUntil = time.Now().Add(h.cooldown)
+	}
+}
+
+// release frees the half-open probe slot of a request that never reached the endpoint
+func (h *health) release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.probing = false
+}
+
+func (h *health) setDisabled(disabled bool, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.disabled = disabled
+	h.disabledReason = reason
+	h.disabledAt = time.Time{}
+	if disabled {
+		h.disabledAt = time.Now()
+		return
+	}
+	// Включенный вручную эндпоинт начинает с закрытым автоматом
+	h.failures = 0
+	h.openUntil = time.Time{}
+	h.probing = false
+}
+
+func (h *health) isDisabled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.disabled
+}
+
+func (h *health) fill(stats *EndpointStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats.LatencyMillis = h.latency
+	stats.ErrorRate = h.errorRate
+	stats.ConsecutiveFailures = h.failures
+	stats.Breaker = BreakerClosed
+	if !h.openUntil.IsZero() {
+		stats.Breaker = BreakerHalfOpen
+		if time.Now().Before(h.openUntil) {
+			stats.Breaker = BreakerOpen
+			openUntil := h.openUntil
+			stats.BreakerOpenUntil = &openUntil
+		}
+	}
+	if !h.lastSuccess.IsZero() {
+		lastSuccess := h.lastSuccess
+		stats.LastSuccess = &lastSuccess
+	}
+	if !h.lastErrorAt.IsZero() {
+		lastErrorAt := h.lastErrorAt
+		stats.LastError, stats.LastErrorAt = h.lastError, &lastErrorAt
+	}
+	stats.Disabled = h.disabled
+	stats.DisabledReason = h.disabledReason
+	if h.disabled {
+		disabledAt := h.disabledAt
+		stats.DisabledAt = &disabledAt
+	}
+}
+
+// responseError treats throttling and server errors as endpoint failures. JSON-RPC errors arrive with 200
+// and mean the node is reachable.
+func responseError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Disable makes requests to the endpoint fail immediately with ErrEndpointDisabled and new clients fail
+// to dial, so callers that iterate endpoints move to the next one. The endpoint must have been dialed before.
+func (m *Manager) Disable(rawURL, reason string) error {
+	ep, ok := m.lookup(rawURL)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEndpoint, rawURL)
+	}
+	ep.health.setDisabled(true, reason)
+	return nil
+}
+
+// Enable re-enables the endpoint and closes its breaker
+func (m *Manager) Enable(rawURL string) error {
+	ep, ok := m.lookup(rawURL)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEndpoint, rawURL)
+	}
+	ep.health.setDisabled(false, "")
+	return nil
+}
@@ -0,0 +1,137 @@
+package rpcmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned when too many requests are already waiting for the endpoint
+	ErrQueueFull = errors.New("rpc request queue is full")
+	// ErrBudgetExhausted is returned when the endpoint daily request budget is used up
+	ErrBudgetExhausted = errors.New("rpc request budget exhausted")
+)
+
+// limiter — token bucket с очередью ожидания и суточным бюджетом запросов.
+// Каждый запрос резервирует момент времени, в который для него появится токен,
+// поэтому ожидающие запросы обслуживаются по порядку поступления.
+type limiter struct {
+	mu sync.Mutex
+
+	rate  float64 // токенов в секунду, 0 — без ограничения
+	burst float64
+
+	tokens float64
+	last   time.Time
+
+	maxQueue int
+	waiting  int
+
+	budget      int64 // запросов в сутки, 0 — без ограничения
+	used        int64
+	budgetReset time.Time
+}
+
+func newLimiter(rate float64, burst, maxQueue int, budget int64) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	now := time.Now()
+	return &limiter{
+		rate:        rate,
+		burst:       float64(burst),
+		tokens:      float64(burst),
+		last:        now,
+		maxQueue:    maxQueue,
+		budget:      budget,
+		budgetReset: nextUTCMidnight(now),
+	}
+}
+
+// wait blocks until the request may be sent. It returns the time spent waiting.
+func (l *limiter) wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+
+	if l.budget > 0 {
+		if !now.Before(l.budgetReset) {
+			l.used = 0
+			l.budgetReset = nextUTCMidnight(now)
+		}
+		if l.used >= l.budget {
+			l.mu.Unlock()
+			return 0, ErrBudgetExhausted
+		}
+	}
+
+	if l.rate <= 0 {
+		l.used++
+		l.mu.Unlock()
+		return 0, nil
+	}
+
+	// Пополняем корзину за прошедшее время
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		if l.maxQueue > 0 && l.waiting >= l.maxQueue {
+			l.tokens++
+			l.mu.Unlock()
+			return 0, ErrQueueFull
+		}
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.used++
+	l.waiting++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+
+	if delay == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		// Возвращаем зарезервированный токен и бюджет
+		l.mu.Lock()
+		l.tokens++
+		l.used--
+		l.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// stats returns the number of queued requests and the remaining budget (-1 if unlimited)
+func (l *limiter) stats() (int, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	remaining := int64(-1)
+	if l.budget > 0 {
+		remaining = l.budget - l.used
+	}
+	return l.waiting, remaining
+}
+
+func nextUTCMidnight(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
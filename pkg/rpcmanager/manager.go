@@ -0,0 +1,302 @@
+// Package rpcmanager manages blockchain RPC endpoints: it creates clients whose HTTP requests
+// pass through a per-endpoint rate limiter and request budget, are bounded by a per-request deadline
+// and stop reaching endpoints that keep failing or were disabled by an operator, and exposes usage
+// and health metrics via expvar.
+package rpcmanager
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// Limits задаёт ограничения, применяемые к каждому эндпоинту
+type Limits struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxQueue          int
+	DailyBudget       int64
+	Timeout           time.Duration // Дедлайн одного HTTP запроса, включая ожидание лимитера
+	BreakerThreshold  int           // Ошибок подряд до отключения эндпоинта, 0 — без автомата
+	BreakerCooldown   time.Duration // Время, на которое эндпоинт отключается до пробного запроса
+}
+
+// EndpointStats is a snapshot of an endpoint usage
+type EndpointStats struct {
+	URL             string `json:"url"`
+	Requests        int64  `json:"requests"`
+	Errors          int64  `json:"errors"`
+	Throttled       int64  `json:"throttled"`
+	Rejected        int64  `json:"rejected"`
+	TimedOut        int64  `json:"timed_out"`
+	WaitMillis      int64  `json:"wait_ms"`
+	Queued          int    `json:"queued"`
+	BudgetRemaining int64  `json:"budget_remaining"`
+
+	LatencyMillis       float64    `json:"latency_ms"`
+	ErrorRate           float64    `json:"error_rate"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Breaker             string     `json:"breaker"`
+	BreakerOpenUntil    *time.Time `json:"breaker_open_until,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	Disabled            bool       `json:"disabled"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+}
+
+type endpoint struct {
+	url     string
+	limiter *limiter
+	health  health
+
+	requests   atomic.Int64
+	errors     atomic.Int64
+	throttled  atomic.Int64
+	rejected   atomic.Int64
+	timedOut   atomic.Int64
+	waitMillis atomic.Int64
+}
+
+// Manager keeps one limiter per endpoint, shared by all clients dialed for that endpoint
+type Manager struct {
+	limits    Limits
+	transport http.RoundTripper
+
+	mu        sync.RWMutex
+	endpoints map[string]*endpoint
+}
+
+// NewManager creates a new endpoint manager
+func NewManager(limits Limits) *Manager {
+	return &Manager{
+		limits:    limits,
+		transport: http.DefaultTransport,
+		endpoints: make(map[string]*endpoint),
+	}
+}
+
+// WithTransport sets the transport HTTP requests are sent with after passing the limiter,
+// e.g. to inject faults in staging. It must be called before the manager dials any client.
+func (m *Manager) WithTransport(transport http.RoundTripper) *Manager {
+	m.transport = transport
+	return m
+}
+
+var (
+	defaultManager atomic.Pointer[Manager]
+	publishOnce    sync.Once
+)
+
+func init() {
+	defaultManager.Store(NewManager(Limits{}))
+}
+
+// SetDefault sets the manager used by package level Dial helpers and publishes its metrics
+func SetDefault(m *Manager) {
+	defaultManager.Store(m)
+	publishOnce.Do(func() {
+		expvar.Publish("rpc_endpoints", expvar.Func(func() any {
+			return Default().Stats()
+		}))
+	})
+}
+
+// Default returns the manager set by SetDefault
+func Default() *Manager {
+	return defaultManager.Load()
+}
+
+// Dial connects an ethclient through the default manager
+func Dial(ctx context.Context, rawURL string) (*ethclient.Client, error) {
+	return Default().Dial(ctx, rawURL)
+}
+
+// DialRPC connects a raw rpc.Client through the default manager
+func DialRPC(ctx context.Context, rawURL string) (*rpc.Client, error) {
+	return Default().DialRPC(ctx, rawURL)
+}
+
+// Dial connects an ethclient whose HTTP requests are rate limited
+func (m *Manager) Dial(ctx context.Context, rawURL string) (*ethclient.Client, error) {
+	client, err := m.DialRPC(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// DialRPC connects a raw rpc.Client. Only HTTP(S) endpoints are rate limited:
+// WebSocket subscriptions are push based and are not throttled, their health is tracked by dial outcome.
+func (m *Manager) DialRPC(ctx context.Context, rawURL string) (*rpc.Client, error) {
+	ep := m.endpoint(rawURL)
+	if !isHTTP(rawURL) {
+		if err := ep.health.admit(); err != nil {
+			return nil, fmt.Errorf("%s: %w", ep.url, err)
+		}
+		start := time.Now()
+		client, err := rpc.DialContext(ctx, rawURL)
+		ep.health.record(time.Since(start), err)
+		return client, err
+	}
+	if ep.health.isDisabled() {
+		return nil, fmt.Errorf("%s: %w", ep.url, ErrEndpointDisabled)
+	}
+
+	httpClient := &http.Client{
+		Transport: &limitedTransport{next: m.transport, endpoint: ep, timeout: m.limits.Timeout},
+	}
+
+	client, err := rpc.DialOptions(ctx, rawURL, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", rawURL, err)
+	}
+	return client, nil
+}
+
+// Stats returns usage and health of all endpoints known to the manager, sorted by URL
+func (m *Manager) Stats() []EndpointStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make([]EndpointStats, 0, len(m.endpoints))
+	for _, ep := range m.endpoints {
+		queued, remaining := ep.limiter.stats()
+		stat := EndpointStats{
+			URL:             ep.url,
+			Requests:        ep.requests.Load(),
+			Errors:          ep.errors.Load(),
+			Throttled:       ep.throttled.Load(),
+			Rejected:        ep.rejected.Load(),
+			TimedOut:        ep.timedOut.Load(),
+			WaitMillis:      ep.waitMillis.Load(),
+			Queued:          queued,
+			BudgetRemaining: remaining,
+		}
+		ep.health.fill(&stat)
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].URL < stats[j].URL })
+	return stats
+}
+
+func (m *Manager) lookup(rawURL string) (*endpoint, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ep, ok := m.endpoints[strings.TrimRight(rawURL, "/")]
+	return ep, ok
+}
+
+func (m *Manager) endpoint(rawURL string) *endpoint {
+	key := strings.TrimRight(rawURL, "/")
+
+	m.mu.RLock()
+	ep, ok := m.endpoints[key]
+	m.mu.RUnlock()
+	if ok {
+		return ep
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ep, ok = m.endpoints[key]; ok {
+		return ep
+	}
+	ep = &endpoint{
+		url:     key,
+		limiter: newLimiter(m.limits.RequestsPerSecond, m.limits.Burst, m.limits.MaxQueue, m.limits.DailyBudget),
+		health:  health{threshold: m.limits.BreakerThreshold, cooldown: m.limits.BreakerCooldown},
+	}
+	m.endpoints[key] = ep
+	return ep
+}
+
+// limitedTransport rejects requests to disabled or failing endpoints, waits for the endpoint limiter
+// before every HTTP request and bounds the request, including reading the response body, by the timeout
+type limitedTransport struct {
+	next     http.RoundTripper
+	endpoint *endpoint
+	timeout  time.Duration
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.endpoint.health.admit(); err != nil {
+		t.endpoint.rejected.Add(1)
+		return nil, fmt.Errorf("%s: %w", t.endpoint.url, err)
+	}
+
+	ctx, cancel := timeouts.WithTimeout(req.Context(), t.timeout)
+	req = req.WithContext(ctx)
+
+	waited, err := t.endpoint.limiter.wait(ctx)
+	if err != nil {
+		cancel()
+		t.endpoint.health.release()
+		t.endpoint.rejected.Add(1)
+		return nil, t.classify(fmt.Errorf("%s: %w", t.endpoint.url, err))
+	}
+	if waited > 0 {
+		t.endpoint.throttled.Add(1)
+		t.endpoint.waitMillis.Add(waited.Milliseconds())
+	}
+
+	t.endpoint.requests.Add(1)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		cancel()
+		t.endpoint.health.record(time.Since(start), err)
+		t.endpoint.errors.Add(1)
+		return nil, t.classify(err)
+	}
+	statusErr := responseError(resp)
+	t.endpoint.health.record(time.Since(start), statusErr)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		t.endpoint.errors.Add(1)
+	}
+	// Контекст отменяется после чтения ответа, иначе дедлайн оборвал бы тело на полпути
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *limitedTransport) classify(err error) error {
+	if timeouts.IsTimeout(err) {
+		t.endpoint.timedOut.Add(1)
+	}
+	return timeouts.Classify(t.endpoint.url, err)
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func isHTTP(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
@@ -0,0 +1,177 @@
+package safe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// ErrNotFound is returned when the transaction service does not know the requested safe or transaction
+var ErrNotFound = errors.New("safe: not found")
+
+// Client talks to the Safe Transaction Service REST API
+type Client struct {
+	baseURL string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewClient creates a client for the transaction service, e.g. https://safe-transaction-bsc.safe.global.
+// Each request is bounded by timeout, 0 disables the deadline.
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+}
+
+// Info содержит состояние Safe: текущий nonce, владельцев и порог подписей
+type Info struct {
+	Address   string   `json:"address"`
+	Nonce     uint64   `json:"nonce"`
+	Threshold int      `json:"threshold"`
+	Owners    []string `json:"owners"`
+}
+
+// Confirmation is a signature of one of the owners
+type Confirmation struct {
+	Owner     string `json:"owner"`
+	Signature string `json:"signature"`
+}
+
+// MultisigTransaction is a proposed or executed transaction as reported by the transaction service
+type MultisigTransaction struct {
+	Safe                  string         `json:"safe"`
+	SafeTxHash            string         `json:"safeTxHash"`
+	Nonce                 uint64         `json:"nonce"`
+	ConfirmationsRequired int            `json:"confirmationsRequired"`
+	Confirmations         []Confirmation `json:"confirmations"`
+	IsExecuted            bool           `json:"isExecuted"`
+	IsSuccessful          *bool          `json:"isSuccessful"`
+	TransactionHash       *string        `json:"transactionHash"`
+}
+
+// GetInfo returns the on-chain state of the safe
+func (c *Client) GetInfo(ctx context.Context, safe common.Address) (*Info, error) {
+	var info Info
+	if err := c.do(ctx, http.MethodGet, "/api/v1/safes/"+safe.Hex()+"/", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+type proposeRequest struct {
+	To                      string  `json:"to"`
+	Value                   string  `json:"value"`
+	Data                    *string `json:"data"`
+	Operation               uint8   `json:"operation"`
+	SafeTxGas               string  `json:"safeTxGas"`
+	BaseGas                 string  `json:"baseGas"`
+	GasPrice                string  `json:"gasPrice"`
+	GasToken                string  `json:"gasToken"`
+	RefundReceiver          string  `json:"refundReceiver"`
+	Nonce                   uint64  `json:"nonce"`
+	ContractTransactionHash string  `json:"contractTransactionHash"`
+	Sender                  string  `json:"sender"`
+	Signature               string  `json:"signature"`
+	Origin                  string  `json:"origin,omitempty"`
+}
+
+// Propose submits the transaction signed by sender, who must be an owner or a delegate of the safe.
+// The signature is the sender's confirmation of safeTxHash.
+func (c *Client) Propose(ctx context.Context, safe common.Address, tx *Transaction, safeTxHash common.Hash, sender common.Address, signature []byte, origin string) error {
+	req := proposeRequest{
+		To:                      tx.To.Hex(),
+		Value:                   decimal(tx.Value),
+		Operation:               uint8(tx.Operation),
+		SafeTxGas:               decimal(tx.SafeTxGas),
+		BaseGas:                 decimal(tx.BaseGas),
+		GasPrice:                decimal(tx.GasPrice),
+		GasToken:                tx.GasToken.Hex(),
+		RefundReceiver:          tx.RefundReceiver.Hex(),
+		Nonce:                   tx.Nonce,
+		ContractTransactionHash: safeTxHash.Hex(),
+		Sender:                  sender.Hex(),
+		Signature:               hexutil.Encode(signature),
+		Origin:                  origin,
+	}
+	if len(tx.Data) > 0 {
+		data := hexutil.Encode(tx.Data)
+		req.Data = &data
+	}
+
+	return c.do(ctx, http.MethodPost, "/api/v1/safes/"+safe.Hex()+"/multisig-transactions/", req, nil)
+}
+
+// GetTransaction returns the state of a proposed transaction: confirmations and execution result
+func (c *Client) GetTransaction(ctx context.Context, safeTxHash string) (*MultisigTransaction, error) {
+	var tx MultisigTransaction
+	if err := c.do(ctx, http.MethodGet, "/api/v1/multisig-transactions/"+safeTxHash+"/", nil, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("safe: failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	ctx, cancel := timeouts.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("safe: failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("safe: request failed: %w", timeouts.Classify("safe", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("safe: transaction service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("safe: failed to decode response: %w", timeouts.Classify("safe", err))
+	}
+	return nil
+}
+
+func decimal(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
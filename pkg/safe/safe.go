@@ -0,0 +1,73 @@
+// Package safe builds Gnosis Safe multisig transactions and proposes them
+// through the Safe Transaction Service API, so that owners can confirm and execute them.
+package safe
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Operation — тип вызова из Safe: обычный call или delegatecall
+type Operation uint8
+
+const (
+	OperationCall         Operation = 0
+	OperationDelegateCall Operation = 1
+)
+
+var (
+	// keccak256("EIP712Domain(uint256 chainId,address verifyingContract)"), Safe >= 1.3.0
+	domainSeparatorTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+
+	safeTxTypeHash = crypto.Keccak256Hash([]byte(
+		"SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+// Transaction is a Safe multisig transaction. Gas fields are zero for transactions executed by owners
+// who pay for gas themselves, which is the default for proposals.
+type Transaction struct {
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      Operation
+	SafeTxGas      *big.Int
+	BaseGas        *big.Int
+	GasPrice       *big.Int
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Nonce          uint64
+}
+
+// Hash returns the EIP-712 safeTxHash which owners sign to confirm the transaction
+func (tx *Transaction) Hash(chainID *big.Int, safe common.Address) common.Hash {
+	domainSeparator := crypto.Keccak256Hash(
+		domainSeparatorTypeHash.Bytes(),
+		common.LeftPadBytes(chainID.Bytes(), 32),
+		common.LeftPadBytes(safe.Bytes(), 32),
+	)
+
+	structHash := crypto.Keccak256Hash(
+		safeTxTypeHash.Bytes(),
+		common.LeftPadBytes(tx.To.Bytes(), 32),
+		uint256(tx.Value),
+		crypto.Keccak256(tx.Data),
+		common.LeftPadBytes([]byte{byte(tx.Operation)}, 32),
+		uint256(tx.SafeTxGas),
+		uint256(tx.BaseGas),
+		uint256(tx.GasPrice),
+		common.LeftPadBytes(tx.GasToken.Bytes(), 32),
+		common.LeftPadBytes(tx.RefundReceiver.Bytes(), 32),
+		uint256(new(big.Int).SetUint64(tx.Nonce)),
+	)
+
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator.Bytes(), structHash.Bytes())
+}
+
+func uint256(v *big.Int) []byte {
+	if v == nil {
+		return make([]byte, 32)
+	}
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
@@ -0,0 +1,343 @@
+// Package sftp uploads files over SFTP (protocol version 3). Only what file drops need is implemented:
+// a file is written under a temporary name and renamed, so the receiver never reads a partial upload.
+package sftp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Типы пакетов SFTP v3
+const (
+	fxpInit    = 1
+	fxpVersion = 2
+	fxpOpen    = 3
+	fxpClose   = 4
+	fxpWrite   = 6
+	fxpRemove  = 13
+	fxpRename  = 18
+	fxpStatus  = 101
+	fxpHandle  = 102
+)
+
+// Флаги открытия файла
+const (
+	pflagWrite = 0x02
+	pflagCreat = 0x08
+	pflagTrunc = 0x10
+)
+
+const (
+	protocolVersion = 3
+	// writeChunk — размер блока записи, который принимают все серверы
+	writeChunk = 32 * 1024
+	// maxPacket ограничивает ответ сервера, ответы на запросы загрузки короткие
+	maxPacket = 256 * 1024
+)
+
+// StatusOK and StatusNoSuchFile are the status codes the uploader distinguishes
+const (
+	StatusOK         = 0
+	StatusNoSuchFile = 2
+)
+
+// Config describes the SFTP target. Either Password or PrivateKey authenticates the user.
+type Config struct {
+	Address    string // host:port
+	User       string
+	Password   string
+	PrivateKey string // PEM encoded key
+	// HostKey — ключ сервера в формате authorized_keys, без него подключение отклоняется
+	HostKey string
+	Timeout time.Duration
+}
+
+// StatusError is a failure status returned by the server
+type StatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sftp: status %d: %s", e.Code, e.Message)
+}
+
+// Upload writes data to path on the server, replacing an existing file
+func Upload(ctx context.Context, config Config, path string, data []byte) error {
+	clientConfig, err := config.clientConfig()
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: config.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", config.Address)
+	if err != nil {
+		return fmt.Errorf("sftp: failed to connect: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	} else if config.Timeout > 0 {
+		_ = netConn.SetDeadline(time.Now().Add(config.Timeout))
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, config.Address, clientConfig)
+	if err != nil {
+		netConn.Close()
+		return fmt.Errorf("sftp: ssh handshake failed: %w", err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("sftp: failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("sftp: %w", err)
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("sftp: %w", err)
+	}
+	if err = session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("sftp: subsystem request failed: %w", err)
+	}
+
+	return newConn(r, w).upload(path, data)
+}
+
+func (config Config) clientConfig() (*ssh.ClientConfig, error) {
+	if config.HostKey == "" {
+		return nil, errors.New("sftp: host key is required")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("sftp: invalid host key: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if config.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("sftp: invalid private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		auth = append(auth, ssh.Password(config.Password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("sftp: password or private key is required")
+	}
+
+	return &ssh.ClientConfig{
+		User:            config.User,
+		Auth:            auth,
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         config.Timeout,
+	}, nil
+}
+
+// conn — SFTP сессия поверх потоков подсистемы, запросы выполняются последовательно
+type conn struct {
+	r      io.Reader
+	w      io.Writer
+	nextID uint32
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{r: r, w: w}
+}
+
+func (c *conn) upload(path string, data []byte) error {
+	if err := c.init(); err != nil {
+		return err
+	}
+
+	partial := path + ".part"
+	handle, err := c.open(partial)
+	if err != nil {
+		return err
+	}
+	for offset := 0; offset < len(data); offset += writeChunk {
+		end := min(offset+writeChunk, len(data))
+		if err = c.status(c.request(fxpWrite, handle, uint64(offset), data[offset:end])); err != nil {
+			_ = c.status(c.request(fxpClose, handle))
+			return fmt.Errorf("sftp: write failed: %w", err)
+		}
+	}
+	if err = c.status(c.request(fxpClose, handle)); err != nil {
+		return fmt.Errorf("sftp: close failed: %w", err)
+	}
+
+	// В версии 3 rename не заменяет существующий файл
+	var status *StatusError
+	if err = c.status(c.request(fxpRemove, path)); err != nil && !(errors.As(err, &status) && status.Code == StatusNoSuchFile) {
+		return fmt.Errorf("sftp: failed to replace %s: %w", path, err)
+	}
+	if err = c.status(c.request(fxpRename, partial, path)); err != nil {
+		return fmt.Errorf("sftp: rename failed: %w", err)
+	}
+	return nil
+}
+
+func (c *conn) init() error {
+	if err := c.send(fxpInit, encode(uint32(protocolVersion))); err != nil {
+		return err
+	}
+	typ, payload, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ != fxpVersion || len(payload) < 4 {
+		return fmt.Errorf("sftp: unexpected init response %d", typ)
+	}
+	if version := binary.BigEndian.Uint32(payload); version < protocolVersion {
+		return fmt.Errorf("sftp: unsupported server version %d", version)
+	}
+	return nil
+}
+
+func (c *conn) open(path string) ([]byte, error) {
+	typ, payload, err := c.request(fxpOpen, path, uint32(pflagWrite|pflagCreat|pflagTrunc), uint32(0))
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case fxpHandle:
+		handle, _, ok := readString(payload)
+		if !ok {
+			return nil, errors.New("sftp: malformed handle")
+		}
+		return handle, nil
+	case fxpStatus:
+		return nil, fmt.Errorf("sftp: failed to open %s: %w", path, parseStatus(payload))
+	default:
+		return nil, fmt.Errorf("sftp: unexpected open response %d", typ)
+	}
+}
+
+// request отправляет пакет с новым идентификатором и возвращает ответ без идентификатора
+func (c *conn) request(typ byte, fields ...any) (byte, []byte, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.send(typ, encode(append([]any{id}, fields...)...)); err != nil {
+		return 0, nil, err
+	}
+
+	respType, payload, err := c.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(payload) < 4 || binary.BigEndian.Uint32(payload) != id {
+		return 0, nil, errors.New("sftp: response id mismatch")
+	}
+	return respType, payload[4:], nil
+}
+
+func (c *conn) status(typ byte, payload []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	if typ != fxpStatus {
+		return fmt.Errorf("sftp: unexpected response %d", typ)
+	}
+	return parseStatus(payload)
+}
+
+func (c *conn) send(typ byte, payload []byte) error {
+	packet := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(packet, uint32(1+len(payload)))
+	packet[4] = typ
+	if _, err := c.w.Write(append(packet, payload...)); err != nil {
+		return fmt.Errorf("sftp: failed to send packet: %w", err)
+	}
+	return nil
+}
+
+func (c *conn) recv() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("sftp: failed to read packet: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > maxPacket {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, fmt.Errorf("sftp: failed to read packet: %w", err)
+	}
+	return header[4], payload, nil
+}
+
+// parseStatus возвращает nil для SSH_FX_OK и StatusError для остальных кодов
+func parseStatus(payload []byte) error {
+	if len(payload) < 4 {
+		return errors.New("sftp: malformed status")
+	}
+	code := binary.BigEndian.Uint32(payload)
+	if code == StatusOK {
+		return nil
+	}
+	message, _, _ := readString(payload[4:])
+	return &StatusError{Code: code, Message: string(message)}
+}
+
+// encode сериализует uint32, uint64 и строки (string, []byte) в порядке байтов SSH
+func encode(fields ...any) []byte {
+	var buf []byte
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint32:
+			buf = binary.BigEndian.AppendUint32(buf, v)
+		case uint64:
+			buf = binary.BigEndian.AppendUint64(buf, v)
+		case string:
+			buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+			buf = append(buf, v...)
+		case []byte:
+			buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+			buf = append(buf, v...)
+		default:
+			panic(fmt.Sprintf("sftp: cannot encode %T", field))
+		}
+	}
+	return buf
+}
+
+func readString(buf []byte) (value, rest []byte, ok bool) {
+	if len(buf) < 4 {
+		return nil, nil, false
+	}
+	length := binary.BigEndian.Uint32(buf)
+	if uint64(len(buf)-4) < uint64(length) {
+		return nil, nil, false
+	}
+	return buf[4 : 4+length], buf[4+length:], true
+}
+
+// Uploader uploads files to one configured target
+type Uploader struct {
+	config Config
+}
+
+// NewUploader creates an uploader for the target
+func NewUploader(config Config) *Uploader {
+	return &Uploader{config: config}
+}
+
+// Upload writes data to path on the target, replacing an existing file
+func (u *Uploader) Upload(ctx context.Context, path string, data []byte) error {
+	return Upload(ctx, u.config, path, data)
+}
@@ -0,0 +1,157 @@
+package sftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeServer обслуживает запросы загрузки в памяти
+type fakeServer struct {
+	r       io.Reader
+	w       io.Writer
+	files   map[string][]byte
+	handles map[string]string
+	ops     []byte
+}
+
+func (s *fakeServer) serve() {
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(s.r, header[:]); err != nil {
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[:4])-1)
+		if _, err := io.ReadFull(s.r, payload); err != nil {
+			return
+		}
+		typ := header[4]
+		s.ops = append(s.ops, typ)
+
+		if typ == fxpInit {
+			s.reply(fxpVersion, encode(uint32(protocolVersion)))
+			continue
+		}
+
+		id := binary.BigEndian.Uint32(payload)
+		body := payload[4:]
+		switch typ {
+		case fxpOpen:
+			name, _, _ := readString(body)
+			s.files[string(name)] = nil
+			s.handles["h1"] = string(name)
+			s.reply(fxpHandle, encode(id, "h1"))
+		case fxpWrite:
+			handle, rest, _ := readString(body)
+			offset := binary.BigEndian.Uint64(rest)
+			data, _, _ := readString(rest[8:])
+			name := s.handles[string(handle)]
+			file := s.files[name]
+			if uint64(len(file)) != offset {
+				s.reply(fxpStatus, encode(id, uint32(4), "bad offset", ""))
+				continue
+			}
+			s.files[name] = append(file, data...)
+			s.reply(fxpStatus, encode(id, uint32(StatusOK), "", ""))
+		case fxpClose:
+			s.reply(fxpStatus, encode(id, uint32(StatusOK), "", ""))
+		case fxpRemove:
+			name, _, _ := readString(body)
+			if _, ok := s.files[string(name)]; !ok {
+				s.reply(fxpStatus, encode(id, uint32(StatusNoSuchFile), "no such file", ""))
+				continue
+			}
+			delete(s.files, string(name))
+			s.reply(fxpStatus, encode(id, uint32(StatusOK), "", ""))
+		case fxpRename:
+			from, rest, _ := readString(body)
+			to, _, _ := readString(rest)
+			if _, ok := s.files[string(to)]; ok {
+				s.reply(fxpStatus, encode(id, uint32(4), "file exists", ""))
+				continue
+			}
+			s.files[string(to)] = s.files[string(from)]
+			delete(s.files, string(from))
+			s.reply(fxpStatus, encode(id, uint32(StatusOK), "", ""))
+		}
+	}
+}
+
+func (s *fakeServer) reply(typ byte, payload []byte) {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(1+len(payload)))
+	packet = append(packet, typ)
+	_, _ = s.w.Write(append(packet, payload...))
+}
+
+func newTestConn(t *testing.T, files map[string][]byte) (*conn, *fakeServer) {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	t.Cleanup(func() {
+		clientW.Close()
+		serverW.Close()
+	})
+
+	server := &fakeServer{r: serverR, w: serverW, files: files, handles: map[string]string{}}
+	go server.serve()
+	return newConn(clientR, clientW), server
+}
+
+func TestUploadWritesChunksAndRenames(t *testing.T) {
+	c, server := newTestConn(t, map[string][]byte{})
+
+	data := bytes.Repeat([]byte("0123456789"), writeChunk/5) // два блока записи
+	if err := c.upload("/in/report.json", data); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	if !bytes.Equal(server.files["/in/report.json"], data) {
+		t.Fatalf("uploaded %d bytes, want %d", len(server.files["/in/report.json"]), len(data))
+	}
+	if _, ok := server.files["/in/report.json.part"]; ok {
+		t.Fatal("temporary file left on the server")
+	}
+	want := []byte{fxpInit, fxpOpen, fxpWrite, fxpWrite, fxpClose, fxpRemove, fxpRename}
+	if !bytes.Equal(server.ops, want) {
+		t.Fatalf("operations %v, want %v", server.ops, want)
+	}
+}
+
+func TestUploadReplacesExistingFile(t *testing.T) {
+	c, server := newTestConn(t, map[string][]byte{"/in/report.json": []byte("old")})
+
+	if err := c.upload("/in/report.json", []byte("new")); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if got := string(server.files["/in/report.json"]); got != "new" {
+		t.Fatalf("file content %q, want %q", got, "new")
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if err := parseStatus(encode(uint32(StatusOK), "", "")); err != nil {
+		t.Fatalf("OK status: %v", err)
+	}
+
+	var status *StatusError
+	err := parseStatus(encode(uint32(3), "permission denied", "en"))
+	if !errors.As(err, &status) || status.Code != 3 || status.Message != "permission denied" {
+		t.Fatalf("unexpected status error %v", err)
+	}
+}
+
+func TestClientConfigRequiresHostKeyAndAuth(t *testing.T) {
+	if _, err := (Config{User: "finance", Password: "secret"}).clientConfig(); err == nil {
+		t.Fatal("expected an error without a host key")
+	}
+
+	hostKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+	if _, err := (Config{User: "finance", HostKey: hostKey}).clientConfig(); err == nil {
+		t.Fatal("expected an error without credentials")
+	}
+	if _, err := (Config{User: "finance", Password: "secret", HostKey: hostKey}).clientConfig(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package simchain runs an in-process EVM chain on go-ethereum's simulated backend with a mock ERC-20 token,
+// so the deposit pipeline can be exercised end to end in CI without public testnets. The chain serves the
+// standard JSON-RPC over HTTP and WebSocket, seals a block every BlockInterval and pays deposits from a
+// faucet account that holds the whole token supply.
+package simchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// TokenAddress — адрес тестового токена, он размещается в генезисе по фиксированному адресу
+var TokenAddress = common.HexToAddress("0x0000000000000000000000000000000000001337")
+
+const (
+	// ChainID симулированной сети, go-ethereum всегда использует 1337
+	ChainID = 1337
+
+	defaultBlockInterval = time.Second
+	transferGasLimit     = 100000
+)
+
+var (
+	// Весь выпуск токена и запас нативной монеты принадлежат faucet аккаунту
+	tokenSupply  = new(big.Int).Mul(big.NewInt(1_000_000_000), new(big.Int).Exp(big.NewInt(10), big.NewInt(TokenDecimals), nil))
+	faucetNative = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+)
+
+// Config задает порты JSON-RPC на localhost и период блоков. Нулевой порт отключает соответствующий сервер.
+type Config struct {
+	HTTPPort      int
+	WSPort        int
+	BlockInterval time.Duration
+}
+
+// Chain is a running simulated chain
+type Chain struct {
+	backend  *simulated.Backend
+	client   simulated.Client
+	faucet   *ecdsa.PrivateKey
+	interval time.Duration
+
+	httpURL string
+	wsURL   string
+
+	// Транзакции faucet аккаунта отправляются по очереди, чтобы не перепутать нонсы
+	mu sync.Mutex
+}
+
+// New starts the chain with the token deployed at TokenAddress
+func New(config Config) (chain *Chain, err error) {
+	faucet, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate faucet key: %w", err)
+	}
+	faucetAddress := crypto.PubkeyToAddress(faucet.PublicKey)
+
+	alloc := types.GenesisAlloc{
+		faucetAddress: types.Account{Balance: faucetNative},
+		TokenAddress: types.Account{
+			Code: tokenCode(),
+			Storage: map[common.Hash]common.Hash{
+				balanceSlot(faucetAddress): common.BigToHash(tokenSupply),
+				totalSupplySlot:            common.BigToHash(tokenSupply),
+			},
+			Balance: new(big.Int),
+		},
+	}
+
+	// Симулированный бэкенд паникует, если нода не запустилась, например порт уже занят
+	defer func() {
+		if r := recover(); r != nil {
+			chain, err = nil, fmt.Errorf("failed to start simulated chain: %v", r)
+		}
+	}()
+	backend := simulated.NewBackend(alloc, func(nodeConf *node.Config, _ *ethconfig.Config) {
+		nodeConf.IPCPath = ""
+		if config.HTTPPort > 0 {
+			nodeConf.HTTPHost = "127.0.0.1"
+			nodeConf.HTTPPort = config.HTTPPort
+			nodeConf.HTTPModules = []string{"eth", "net", "web3"}
+			nodeConf.HTTPVirtualHosts = []string{"*"}
+		}
+		if config.WSPort > 0 {
+			nodeConf.WSHost = "127.0.0.1"
+			nodeConf.WSPort = config.WSPort
+			nodeConf.WSModules = []string{"eth", "net", "web3"}
+			nodeConf.WSOrigins = []string{"*"}
+		}
+	})
+
+	chain = &Chain{
+		backend:  backend,
+		client:   backend.Client(),
+		faucet:   faucet,
+		interval: config.BlockInterval,
+	}
+	if chain.interval <= 0 {
+		chain.interval = defaultBlockInterval
+	}
+	if config.HTTPPort > 0 {
+		chain.httpURL = fmt.Sprintf("http://127.0.0.1:%d", config.HTTPPort)
+	}
+	if config.WSPort > 0 {
+		chain.wsURL = fmt.Sprintf("ws://127.0.0.1:%d", config.WSPort)
+	}
+
+	return chain, nil
+}
+
+// HTTPURL returns the JSON-RPC HTTP endpoint, empty if it is disabled
+func (c *Chain) HTTPURL() string {
+	return c.httpURL
+}
+
+// WSURL returns the JSON-RPC WebSocket endpoint, empty if it is disabled
+func (c *Chain) WSURL() string {
+	return c.wsURL
+}
+
+// Client returns an in-process client of the chain
+func (c *Chain) Client() simulated.Client {
+	return c.client
+}
+
+// Start seals a block every block interval until ctx is cancelled
+func (c *Chain) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Commit()
+		}
+	}
+}
+
+// Commit seals the pending transactions into a new block
+func (c *Chain) Commit() common.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.backend.Commit()
+}
+
+// Deposit sends amount token units from the faucet to the address with a transfer call, the same way a user
+// wallet pays an order. The memo, if any, is appended after the call arguments as exchanges do.
+func (c *Chain) Deposit(ctx context.Context, to common.Address, amount *big.Int, memo []byte) (common.Hash, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return common.Hash{}, errors.New("deposit amount must be positive")
+	}
+
+	data := append([]byte(nil), selectorTransfer...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	data = append(data, memo...)
+
+	return c.send(ctx, TokenAddress, new(big.Int), data)
+}
+
+// Fund sends wei of the native coin from the faucet, e.g. to pay gas of sweeps from deposit wallets
+func (c *Chain) Fund(ctx context.Context, to common.Address, wei *big.Int) (common.Hash, error) {
+	if wei == nil || wei.Sign() <= 0 {
+		return common.Hash{}, errors.New("fund amount must be positive")
+	}
+	return c.send(ctx, to, wei, nil)
+}
+
+// TokenBalance returns the token balance of the address at the latest block
+func (c *Chain) TokenBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	value, err := c.client.StorageAt(ctx, TokenAddress, balanceSlot(address), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token balance: %w", err)
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+func (c *Chain) send(ctx context.Context, to common.Address, value *big.Int, data []byte) (common.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	from := crypto.PubkeyToAddress(c.faucet.PublicKey)
+	nonce, err := c.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get faucet nonce: %w", err)
+	}
+	gasPrice, err := c.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      transferGasLimit,
+		To:       &to,
+		Value:    value,
+		Data:     data,
+	}), types.LatestSignerForChainID(big.NewInt(ChainID)), c.faucet)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign faucet transaction: %w", err)
+	}
+
+	if err = c.client.SendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send faucet transaction: %w", err)
+	}
+	return tx.Hash(), nil
+}
+
+// Close stops the chain
+func (c *Chain) Close() error {
+	return c.backend.Close()
+}
@@ -0,0 +1,123 @@
+package simchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChain(t *testing.T) *Chain {
+	t.Helper()
+
+	chain, err := New(Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = chain.Close() })
+	return chain
+}
+
+func call(t *testing.T, chain *Chain, data ...[]byte) *big.Int {
+	t.Helper()
+
+	var input []byte
+	for _, part := range data {
+		input = append(input, part...)
+	}
+	result, err := chain.Client().CallContract(context.Background(), ethereum.CallMsg{To: &TokenAddress, Data: input}, nil)
+	require.NoError(t, err)
+	require.Len(t, result, 32)
+	return new(big.Int).SetBytes(result)
+}
+
+func word(address common.Address) []byte {
+	return common.LeftPadBytes(address.Bytes(), 32)
+}
+
+func TestTokenGenesis(t *testing.T) {
+	chain := newTestChain(t)
+	faucet := crypto.PubkeyToAddress(chain.faucet.PublicKey)
+
+	assert.Equal(t, tokenSupply, call(t, chain, selectorBalanceOf, word(faucet)))
+	assert.Equal(t, tokenSupply, call(t, chain, selector("totalSupply()")))
+	assert.Equal(t, int64(TokenDecimals), call(t, chain, selectorDecimals).Int64())
+
+	// Неизвестные методы, например paused() у USDT, возвращают ноль
+	assert.Zero(t, call(t, chain, selector("paused()")).Sign())
+}
+
+func TestDeposit(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	amount := big.NewInt(1_500_000)
+
+	txHash, err := chain.Deposit(ctx, to, amount, []byte("order-42"))
+	require.NoError(t, err)
+	chain.Commit()
+
+	receipt, err := chain.Client().TransactionReceipt(ctx, txHash)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	require.Len(t, receipt.Logs, 1)
+	assert.Equal(t, topicTransfer, receipt.Logs[0].Topics[0])
+	assert.Equal(t, common.BytesToHash(to.Bytes()), receipt.Logs[0].Topics[2])
+	assert.Equal(t, amount, new(big.Int).SetBytes(receipt.Logs[0].Data))
+
+	tx, _, err := chain.Client().TransactionByHash(ctx, txHash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("order-42"), tx.Data()[4+64:])
+
+	balance, err := chain.TokenBalance(ctx, to)
+	require.NoError(t, err)
+	assert.Equal(t, amount, balance)
+	assert.Equal(t, amount, call(t, chain, selectorBalanceOf, word(to)))
+
+	_, err = chain.Deposit(ctx, to, big.NewInt(0), nil)
+	assert.Error(t, err)
+}
+
+func TestFund(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	_, err := chain.Fund(ctx, to, big.NewInt(1e18))
+	require.NoError(t, err)
+	chain.Commit()
+
+	balance, err := chain.Client().BalanceAt(ctx, to, nil)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1e18), balance)
+}
+
+func TestApproveTransferFrom(t *testing.T) {
+	ctx := context.Background()
+	chain := newTestChain(t)
+	faucet := crypto.PubkeyToAddress(chain.faucet.PublicKey)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	amount := big.NewInt(1000)
+
+	// Faucet разрешает самому себе списание и переводит токены через transferFrom
+	approve := append(append(append([]byte(nil), selectorApprove...), word(faucet)...), common.LeftPadBytes(amount.Bytes(), 32)...)
+	_, err := chain.send(ctx, TokenAddress, new(big.Int), approve)
+	require.NoError(t, err)
+	chain.Commit()
+	assert.Equal(t, amount, call(t, chain, selectorAllowance, word(faucet), word(faucet)))
+
+	transferFrom := append(append([]byte(nil), selectorTransferFrom...), word(faucet)...)
+	transferFrom = append(append(transferFrom, word(to)...), common.LeftPadBytes(amount.Bytes(), 32)...)
+	txHash, err := chain.send(ctx, TokenAddress, new(big.Int), transferFrom)
+	require.NoError(t, err)
+	chain.Commit()
+
+	receipt, err := chain.Client().TransactionReceipt(ctx, txHash)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, amount, call(t, chain, selectorBalanceOf, word(to)))
+	assert.Zero(t, call(t, chain, selectorAllowance, word(faucet), word(faucet)).Sign())
+}
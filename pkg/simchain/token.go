@@ -0,0 +1,212 @@
+package simchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TokenDecimals — точность тестового токена, как у USDT (BEP-20)
+const TokenDecimals = 18
+
+// Селекторы методов ERC-20, которые реализует тестовый токен
+var (
+	selectorBalanceOf    = selector("balanceOf(address)")
+	selectorTransfer     = selector("transfer(address,uint256)")
+	selectorTransferFrom = selector("transferFrom(address,address,uint256)")
+	selectorApprove      = selector("approve(address,uint256)")
+	selectorAllowance    = selector("allowance(address,address)")
+	selectorDecimals     = selector("decimals()")
+	selectorTotalSupply  = selector("totalSupply()")
+
+	topicTransfer = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	topicApproval = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+)
+
+// Слоты хранилища: баланс адреса лежит в слоте, равном адресу, разрешение — в keccak256(owner, spender),
+// общий выпуск — в слоте 2^160, который не совпадает ни с одним адресом
+var totalSupplySlot = common.BigToHash(new(big.Int).Lsh(big.NewInt(1), 160))
+
+func balanceSlot(address common.Address) common.Hash {
+	return common.BytesToHash(address.Bytes())
+}
+
+// tokenCode returns the runtime bytecode of a minimal ERC-20: balanceOf, transfer, transferFrom, approve,
+// allowance, decimals and totalSupply with Transfer and Approval events. Any other call returns a zero word,
+// so probes such as paused() or isBlackListed(address) see an unpaused token without a blacklist.
+// There is no constructor: the code and the initial balances are placed into the genesis.
+func tokenCode() []byte {
+	a := newAssembler()
+
+	// Селектор — первые 4 байта calldata
+	a.push(0).op(vm.CALLDATALOAD).push(0xe0).op(vm.SHR)
+	for _, route := range []struct {
+		selector []byte
+		label    string
+	}{
+		{selectorBalanceOf, "balanceOf"},
+		{selectorTransfer, "transfer"},
+		{selectorTransferFrom, "transferFrom"},
+		{selectorApprove, "approve"},
+		{selectorAllowance, "allowance"},
+		{selectorDecimals, "decimals"},
+		{selectorTotalSupply, "totalSupply"},
+	} {
+		a.op(vm.DUP1).pushBytes(route.selector).op(vm.EQ).jumpi(route.label)
+	}
+	a.push(0)
+	a.returnWord()
+
+	a.label("balanceOf")
+	a.arg(0).op(vm.SLOAD)
+	a.returnWord()
+
+	a.label("transfer")
+	a.move(func() { a.op(vm.CALLER) }, func() { a.arg(0) }, func() { a.arg(1) })
+
+	a.label("transferFrom")
+	// allowance[from][caller] -= amount, недостаточное разрешение откатывает вызов
+	a.allowanceSlot(func() { a.arg(0) }, func() { a.op(vm.CALLER) })
+	a.arg(2)
+	a.op(vm.DUP2, vm.SLOAD)
+	a.op(vm.DUP2, vm.DUP2, vm.LT).jumpi("revert")
+	a.op(vm.SUB, vm.SWAP1, vm.SSTORE)
+	a.move(func() { a.arg(0) }, func() { a.arg(1) }, func() { a.arg(2) })
+
+	a.label("approve")
+	a.arg(1)
+	a.allowanceSlot(func() { a.op(vm.CALLER) }, func() { a.arg(0) })
+	a.op(vm.SSTORE)
+	a.arg(1).push(0).op(vm.MSTORE)
+	a.arg(0).op(vm.CALLER).pushBytes(topicApproval.Bytes()).push(32).push(0).op(vm.LOG3)
+	a.push(1)
+	a.returnWord()
+
+	a.label("allowance")
+	a.allowanceSlot(func() { a.arg(0) }, func() { a.arg(1) })
+	a.op(vm.SLOAD)
+	a.returnWord()
+
+	a.label("decimals")
+	a.push(TokenDecimals)
+	a.returnWord()
+
+	a.label("totalSupply")
+	a.pushBytes(totalSupplySlot.Bytes()).op(vm.SLOAD)
+	a.returnWord()
+
+	a.label("revert")
+	a.push(0).op(vm.DUP1, vm.REVERT)
+
+	return a.bytes()
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// assembler собирает байткод EVM, адреса переходов подставляются по меткам после сборки
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{
+		labels: make(map[string]int),
+		fixups: make(map[int]string),
+	}
+}
+
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+func (a *assembler) push(value uint64) *assembler {
+	return a.pushBytes(new(big.Int).SetUint64(value).Bytes())
+}
+
+// pushBytes pushes up to 32 bytes with the shortest PUSH instruction
+func (a *assembler) pushBytes(value []byte) *assembler {
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(value)-1))
+	a.code = append(a.code, value...)
+	return a
+}
+
+func (a *assembler) label(name string) *assembler {
+	a.labels[name] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+func (a *assembler) jumpi(label string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.fixups[len(a.code)] = label
+	a.code = append(a.code, 0, 0)
+	return a.op(vm.JUMPI)
+}
+
+// arg pushes the i-th 32-byte argument of the call, addresses are used as is: callers pass canonical ABI values
+func (a *assembler) arg(i int) *assembler {
+	return a.push(uint64(4 + 32*i)).op(vm.CALLDATALOAD)
+}
+
+// returnWord returns the value on top of the stack as one ABI word
+func (a *assembler) returnWord() {
+	a.push(0).op(vm.MSTORE).push(32).push(0).op(vm.RETURN)
+}
+
+// allowanceSlot pushes keccak256(owner, spender), memory 0..64 is used as scratch space
+func (a *assembler) allowanceSlot(owner, spender func()) {
+	owner()
+	a.push(0).op(vm.MSTORE)
+	spender()
+	a.push(32).op(vm.MSTORE)
+	a.push(64).push(0).op(vm.KECCAK256)
+}
+
+// move transfers amount from one balance to another, emits Transfer and returns true.
+// An insufficient balance reverts the call.
+func (a *assembler) move(from, to, amount func()) {
+	amount()       // [amount]
+	from()         // [amount, from]
+	a.op(vm.SLOAD) // [amount, balanceFrom]
+	a.op(vm.DUP2, vm.DUP2, vm.LT).jumpi("revert")
+	a.op(vm.DUP2, vm.SWAP1, vm.SUB)    // [amount, balanceFrom-amount]
+	from()                             // [amount, newBalance, from]
+	a.op(vm.SSTORE)                    // [amount]
+	a.op(vm.DUP1)                      // [amount, amount]
+	to()                               // [amount, amount, to]
+	a.op(vm.SLOAD, vm.ADD)             // [amount, balanceTo+amount]
+	to()                               // [amount, newBalance, to]
+	a.op(vm.SSTORE)                    // [amount]
+	a.push(0).op(vm.MSTORE)            // []
+	to()                               // [to]
+	from()                             // [to, from]
+	a.pushBytes(topicTransfer.Bytes()) // [to, from, topic]
+	a.push(32).push(0).op(vm.LOG3)     // []
+	a.push(1)
+	a.returnWord()
+}
+
+func (a *assembler) bytes() []byte {
+	code := append([]byte(nil), a.code...)
+	for offset, label := range a.fixups {
+		target, ok := a.labels[label]
+		if !ok {
+			panic(fmt.Sprintf("simchain: undefined label %q", label))
+		}
+		code[offset] = byte(target >> 8)
+		code[offset+1] = byte(target)
+	}
+	return code
+}
@@ -0,0 +1,155 @@
+package solana
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// LamportsPerSOL — SOL делится на 10^9 лампортов
+const LamportsPerSOL = 1_000_000_000
+
+// Client talks to a Solana JSON-RPC node, e.g. https://api.devnet.solana.com
+type Client struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewClient creates a JSON-RPC client. Each request is bounded by timeout, 0 disables the deadline.
+func NewClient(url string, timeout time.Duration) *Client {
+	return &Client{
+		url:     url,
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+}
+
+// RequestAirdrop asks the cluster faucet to send lamports to the address. Only devnet and testnet have a faucet.
+// Returns the signature of the airdrop transaction.
+func (c *Client) RequestAirdrop(ctx context.Context, to PublicKey, lamports uint64) (string, error) {
+	var signature string
+	if err := c.call(ctx, "requestAirdrop", []any{to.String(), lamports}, &signature); err != nil {
+		return "", err
+	}
+	return signature, nil
+}
+
+// LatestBlockhash returns the blockhash to build a transaction against
+func (c *Client) LatestBlockhash(ctx context.Context) (PublicKey, error) {
+	var result struct {
+		Value struct {
+			Blockhash string `json:"blockhash"`
+		} `json:"value"`
+	}
+	if err := c.call(ctx, "getLatestBlockhash", []any{map[string]string{"commitment": "confirmed"}}, &result); err != nil {
+		return PublicKey{}, err
+	}
+	return ParsePublicKey(result.Value.Blockhash)
+}
+
+// MinimumBalanceForRentExemption returns the lamports an account of the size must hold to be exempt from rent
+func (c *Client) MinimumBalanceForRentExemption(ctx context.Context, size uint64) (uint64, error) {
+	var lamports uint64
+	if err := c.call(ctx, "getMinimumBalanceForRentExemption", []any{size}, &lamports); err != nil {
+		return 0, err
+	}
+	return lamports, nil
+}
+
+// SendTransaction submits the signed transaction and returns its signature
+func (c *Client) SendTransaction(ctx context.Context, tx Transaction) (string, error) {
+	encoded := base64.StdEncoding.EncodeToString(tx.Serialize())
+
+	var signature string
+	if err := c.call(ctx, "sendTransaction", []any{encoded, map[string]string{"encoding": "base64"}}, &signature); err != nil {
+		return "", err
+	}
+	return signature, nil
+}
+
+// PrioritizationFee is the lowest priority fee paid by a transaction landed in the slot
+type PrioritizationFee struct {
+	Slot              uint64 `json:"slot"`
+	PrioritizationFee uint64 `json:"prioritizationFee"` // Микролампорты за единицу вычислений
+}
+
+// RecentPrioritizationFees returns the priority fees of the recent slots (up to 150) for transactions that write
+// to all the accounts. Without accounts the fees of the whole cluster are returned.
+func (c *Client) RecentPrioritizationFees(ctx context.Context, writable []PublicKey) ([]PrioritizationFee, error) {
+	addresses := make([]string, 0, len(writable))
+	for _, key := range writable {
+		addresses = append(addresses, key.String())
+	}
+
+	var fees []PrioritizationFee
+	if err := c.call(ctx, "getRecentPrioritizationFees", []any{addresses}, &fees); err != nil {
+		return nil, err
+	}
+	return fees, nil
+}
+
+// RPCError is an error returned by the node
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("solana: rpc error %d: %s", e.Code, e.Message)
+}
+
+func (c *Client) call(ctx context.Context, method string, params []any, out any) error {
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("solana: failed to encode request: %w", err)
+	}
+
+	ctx, cancel := timeouts.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("solana: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("solana: %s failed: %w", method, timeouts.Classify("solana rpc", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("solana: %s: unexpected status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("solana: failed to decode %s response: %w", method, timeouts.Classify("solana rpc", err))
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if err = json.Unmarshal(response.Result, out); err != nil {
+		return fmt.Errorf("solana: unexpected %s result: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package solana
+
+import "encoding/binary"
+
+// ComputeBudgetProgramID задает лимит вычислений и приоритетную комиссию транзакции
+var ComputeBudgetProgramID = MustParsePublicKey("ComputeBudget111111111111111111111111111111")
+
+// MaxComputeUnitLimit — предельный лимит вычислений одной транзакции
+const MaxComputeUnitLimit = 1_400_000
+
+// Номера инструкций программы Compute Budget
+const (
+	computeBudgetSetUnitLimit byte = 2
+	computeBudgetSetUnitPrice byte = 3
+)
+
+// SetComputeUnitLimit caps the compute units the transaction may consume. The priority fee is charged for the limit,
+// not for the units actually used, so the limit should be close to the real consumption.
+func SetComputeUnitLimit(units uint32) Instruction {
+	data := make([]byte, 1+4)
+	data[0] = computeBudgetSetUnitLimit
+	binary.LittleEndian.PutUint32(data[1:], units)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+// SetComputeUnitPrice sets the priority fee in micro-lamports per compute unit
+func SetComputeUnitPrice(microLamports uint64) Instruction {
+	data := make([]byte, 1+8)
+	data[0] = computeBudgetSetUnitPrice
+	binary.LittleEndian.PutUint64(data[1:], microLamports)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+// PriorityFeeLamports returns the priority fee of a transaction with the compute unit limit and price, rounded up
+func PriorityFeeLamports(units uint32, microLamports uint64) uint64 {
+	total := uint64(units) * microLamports
+	return (total + 999_999) / 1_000_000
+}
@@ -0,0 +1,121 @@
+// Package solana implements the small part of the Solana JSON-RPC API and transaction format
+// needed by the devnet faucet: SOL airdrops and minting test SPL tokens.
+package solana
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// ErrInvalidKey is returned for malformed public or secret keys
+var ErrInvalidKey = errors.New("solana: invalid key")
+
+// Программы, к которым обращается кран
+var (
+	SystemProgramID = MustParsePublicKey("11111111111111111111111111111111")
+	TokenProgramID  = MustParsePublicKey("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+)
+
+// PublicKey is an ed25519 public key, the address of a Solana account
+type PublicKey [ed25519.PublicKeySize]byte
+
+// ParsePublicKey parses a base58 address
+func ParsePublicKey(s string) (PublicKey, error) {
+	data, err := base58.Decode(s)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("%w: %q: %w", ErrInvalidKey, s, err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return PublicKey{}, fmt.Errorf("%w: %q is %d bytes long, expected %d", ErrInvalidKey, s, len(data), ed25519.PublicKeySize)
+	}
+
+	var key PublicKey
+	copy(key[:], data)
+	return key, nil
+}
+
+// MustParsePublicKey is ParsePublicKey for constants, it panics on error
+func MustParsePublicKey(s string) PublicKey {
+	key, err := ParsePublicKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// String returns the base58 address
+func (k PublicKey) String() string {
+	return base58.Encode(k[:])
+}
+
+// Keypair signs transactions on behalf of its public key
+type Keypair struct {
+	private ed25519.PrivateKey
+}
+
+// NewKeypair generates a random keypair, e.g. for a new account created by a transaction
+func NewKeypair() (Keypair, error) {
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return Keypair{}, fmt.Errorf("solana: failed to generate keypair: %w", err)
+	}
+	return Keypair{private: private}, nil
+}
+
+// ParseKeypair parses a 64-byte secret key written either as base58 or as the JSON array of
+// solana-keygen key files
+func ParseKeypair(s string) (Keypair, error) {
+	s = strings.TrimSpace(s)
+
+	var (
+		data []byte
+		err  error
+	)
+	if strings.HasPrefix(s, "[") {
+		// []byte из JSON ожидает base64, поэтому массив чисел читается как []uint8 через промежуточный срез
+		var values []uint8
+		var raw []json.Number
+		if err = json.Unmarshal([]byte(s), &raw); err == nil {
+			for _, v := range raw {
+				var b int64
+				if b, err = v.Int64(); err != nil || b < 0 || b > 255 {
+					return Keypair{}, fmt.Errorf("%w: invalid secret key byte %s", ErrInvalidKey, v)
+				}
+				values = append(values, uint8(b))
+			}
+		}
+		data = values
+	} else {
+		data, err = base58.Decode(s)
+	}
+	if err != nil {
+		return Keypair{}, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	if len(data) != ed25519.PrivateKeySize {
+		return Keypair{}, fmt.Errorf("%w: secret key is %d bytes long, expected %d", ErrInvalidKey, len(data), ed25519.PrivateKeySize)
+	}
+
+	private := ed25519.NewKeyFromSeed(data[:ed25519.SeedSize])
+	// Вторая половина ключа — открытый ключ, несовпадение означает поврежденный файл
+	if !ed25519.PublicKey(data[ed25519.SeedSize:]).Equal(private.Public()) {
+		return Keypair{}, fmt.Errorf("%w: public half does not match the seed", ErrInvalidKey)
+	}
+	return Keypair{private: private}, nil
+}
+
+// PublicKey returns the address of the keypair
+func (k Keypair) PublicKey() PublicKey {
+	var key PublicKey
+	copy(key[:], k.private.Public().(ed25519.PublicKey))
+	return key
+}
+
+// Sign signs the message
+func (k Keypair) Sign(message []byte) []byte {
+	return ed25519.Sign(k.private, message)
+}
@@ -0,0 +1,132 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	key, err := ParsePublicKey("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+	require.NoError(t, err)
+	assert.Equal(t, "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", key.String())
+
+	assert.Equal(t, PublicKey{}, SystemProgramID)
+
+	_, err = ParsePublicKey("0OIl")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = ParsePublicKey("11111111")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestParseKeypair(t *testing.T) {
+	keypair, err := NewKeypair()
+	require.NoError(t, err)
+
+	secret := []byte(keypair.private)
+	fromBase58, err := ParseKeypair(base58.Encode(secret))
+	require.NoError(t, err)
+	assert.Equal(t, keypair.PublicKey(), fromBase58.PublicKey())
+
+	// Формат файла solana-keygen — JSON массив байтов
+	numbers := make([]int, len(secret))
+	for i, b := range secret {
+		numbers[i] = int(b)
+	}
+	file, err := json.Marshal(numbers)
+	require.NoError(t, err)
+	fromFile, err := ParseKeypair(string(file))
+	require.NoError(t, err)
+	assert.Equal(t, keypair.PublicKey(), fromFile.PublicKey())
+
+	// Открытая половина ключа не от этого сида
+	other, err := NewKeypair()
+	require.NoError(t, err)
+	otherKey := other.PublicKey()
+	corrupted := append(append([]byte{}, secret[:ed25519.SeedSize]...), otherKey[:]...)
+	_, err = ParseKeypair(base58.Encode(corrupted))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = ParseKeypair("[1, 2, 300]")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestAppendCompactU16(t *testing.T) {
+	assert.Equal(t, []byte{0x00}, appendCompactU16(nil, 0))
+	assert.Equal(t, []byte{0x7f}, appendCompactU16(nil, 0x7f))
+	assert.Equal(t, []byte{0x80, 0x01}, appendCompactU16(nil, 0x80))
+	assert.Equal(t, []byte{0xff, 0x7f}, appendCompactU16(nil, 0x3fff))
+	assert.Equal(t, []byte{0x80, 0x80, 0x01}, appendCompactU16(nil, 0x4000))
+}
+
+func TestNewTransaction(t *testing.T) {
+	authority, err := NewKeypair()
+	require.NoError(t, err)
+	account, err := NewKeypair()
+	require.NoError(t, err)
+	mint := MustParsePublicKey("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU")
+	owner := MustParsePublicKey("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	blockhash := MustParsePublicKey("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N")
+
+	tx, err := NewTransaction([]Instruction{
+		CreateAccount(authority.PublicKey(), account.PublicKey(), 2039280, TokenAccountSize, TokenProgramID),
+		InitializeTokenAccount(account.PublicKey(), mint, owner),
+		MintTo(mint, account.PublicKey(), authority.PublicKey(), 100_000_000),
+	}, blockhash, authority, account)
+	require.NoError(t, err)
+
+	message := tx.Message
+	// Два подписанта с правом записи, программы только для чтения. Владелец передается в данных инструкции.
+	assert.Equal(t, []byte{2, 0, 2}, message[:3])
+	require.Equal(t, byte(5), message[3])
+
+	keys := make([]PublicKey, 5)
+	for i := range keys {
+		copy(keys[i][:], message[4+i*32:])
+	}
+	assert.Equal(t, []PublicKey{authority.PublicKey(), account.PublicKey(), mint, SystemProgramID, TokenProgramID}, keys)
+	assert.Equal(t, blockhash[:], message[4+5*32:4+6*32])
+
+	// Первая инструкция — системная программа с плательщиком и новым аккаунтом
+	instructions := message[4+6*32:]
+	assert.Equal(t, []byte{3, 3, 2, 0, 1, 52}, instructions[:6])
+
+	require.Len(t, tx.Signatures, 2)
+	assert.True(t, ed25519.Verify(ed25519.PublicKey(keys[0][:]), message, tx.Signatures[0]))
+	assert.True(t, ed25519.Verify(ed25519.PublicKey(keys[1][:]), message, tx.Signatures[1]))
+
+	serialized := tx.Serialize()
+	assert.Equal(t, byte(2), serialized[0])
+	assert.Equal(t, 1+2*64+len(message), len(serialized))
+
+	_, err = NewTransaction([]Instruction{
+		CreateAccount(authority.PublicKey(), account.PublicKey(), 1, 0, SystemProgramID),
+	}, blockhash, authority)
+	assert.Error(t, err, "the new account must sign")
+}
+
+func TestComputeBudget(t *testing.T) {
+	limit := SetComputeUnitLimit(200_000)
+	assert.Equal(t, ComputeBudgetProgramID, limit.ProgramID)
+	assert.Empty(t, limit.Accounts)
+	assert.Equal(t, []byte{2, 0x40, 0x0d, 0x03, 0x00}, limit.Data)
+
+	price := SetComputeUnitPrice(1_000_000)
+	assert.Equal(t, []byte{3, 0x40, 0x42, 0x0f, 0, 0, 0, 0, 0}, price.Data)
+
+	// Комиссия округляется вверх до целого лампорта
+	assert.Equal(t, uint64(200_000), PriorityFeeLamports(200_000, 1_000_000))
+	assert.Equal(t, uint64(1), PriorityFeeLamports(1, 1))
+	assert.Equal(t, uint64(0), PriorityFeeLamports(200_000, 0))
+
+	// Программа Compute Budget попадает в аккаунты только для чтения
+	payer, err := NewKeypair()
+	require.NoError(t, err)
+	tx, err := NewTransaction([]Instruction{limit, price}, PublicKey{}, payer)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 0, 1, 2}, tx.Message[:4])
+}
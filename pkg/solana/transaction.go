@@ -0,0 +1,206 @@
+package solana
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// TokenAccountSize — размер аккаунта SPL токена, от него зависит депозит за аренду
+const TokenAccountSize = 165
+
+// Номера инструкций системной программы и программы SPL Token
+const (
+	systemCreateAccount     uint32 = 0
+	tokenMintTo             byte   = 7
+	tokenInitializeAccount3 byte   = 18
+)
+
+// AccountMeta is an account referenced by an instruction
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+// Instruction is a call of an on-chain program
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// CreateAccount creates a new account funded by payer and owned by the program. Both payer and account sign.
+func CreateAccount(payer, account PublicKey, lamports, space uint64, owner PublicKey) Instruction {
+	data := make([]byte, 4+8+8+32)
+	binary.LittleEndian.PutUint32(data[0:], systemCreateAccount)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	binary.LittleEndian.PutUint64(data[12:], space)
+	copy(data[20:], owner[:])
+
+	return Instruction{
+		ProgramID: SystemProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: payer, IsSigner: true, IsWritable: true},
+			{PublicKey: account, IsSigner: true, IsWritable: true},
+		},
+		Data: data,
+	}
+}
+
+// InitializeTokenAccount initializes a created account as a token account of the mint owned by owner
+func InitializeTokenAccount(account, mint, owner PublicKey) Instruction {
+	data := make([]byte, 1+32)
+	data[0] = tokenInitializeAccount3
+	copy(data[1:], owner[:])
+
+	return Instruction{
+		ProgramID: TokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: account, IsWritable: true},
+			{PublicKey: mint},
+		},
+		Data: data,
+	}
+}
+
+// MintTo mints amount base units of the mint to the token account, signed by the mint authority
+func MintTo(mint, account, authority PublicKey, amount uint64) Instruction {
+	data := make([]byte, 1+8)
+	data[0] = tokenMintTo
+	binary.LittleEndian.PutUint64(data[1:], amount)
+
+	return Instruction{
+		ProgramID: TokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: mint, IsWritable: true},
+			{PublicKey: account, IsWritable: true},
+			{PublicKey: authority, IsSigner: true},
+		},
+		Data: data,
+	}
+}
+
+// Transaction is a signed legacy transaction ready to be sent
+type Transaction struct {
+	Signatures [][]byte
+	Message    []byte
+}
+
+// Serialize returns the wire format of the transaction
+func (t Transaction) Serialize() []byte {
+	out := appendCompactU16(nil, len(t.Signatures))
+	for _, signature := range t.Signatures {
+		out = append(out, signature...)
+	}
+	return append(out, t.Message...)
+}
+
+// NewTransaction compiles the instructions into a legacy message paid by the fee payer and signs it.
+// Every signer of the instructions must be among signers, the fee payer is the first signer.
+func NewTransaction(instructions []Instruction, recentBlockhash PublicKey, signers ...Keypair) (Transaction, error) {
+	if len(signers) == 0 {
+		return Transaction{}, fmt.Errorf("solana: transaction has no fee payer")
+	}
+
+	keys, header := compileAccounts(signers[0].PublicKey(), instructions)
+	index := make(map[PublicKey]int, len(keys))
+	for i, key := range keys {
+		index[key] = i
+	}
+
+	message := []byte{header.signatures, header.readonlySigned, header.readonlyUnsigned}
+	message = appendCompactU16(message, len(keys))
+	for _, key := range keys {
+		message = append(message, key[:]...)
+	}
+	message = append(message, recentBlockhash[:]...)
+	message = appendCompactU16(message, len(instructions))
+	for _, instruction := range instructions {
+		message = append(message, byte(index[instruction.ProgramID]))
+		message = appendCompactU16(message, len(instruction.Accounts))
+		for _, account := range instruction.Accounts {
+			message = append(message, byte(index[account.PublicKey]))
+		}
+		message = appendCompactU16(message, len(instruction.Data))
+		message = append(message, instruction.Data...)
+	}
+
+	bySigner := make(map[PublicKey]Keypair, len(signers))
+	for _, signer := range signers {
+		bySigner[signer.PublicKey()] = signer
+	}
+	signatures := make([][]byte, header.signatures)
+	for i := range signatures {
+		signer, ok := bySigner[keys[i]]
+		if !ok {
+			return Transaction{}, fmt.Errorf("solana: missing signature of %s", keys[i])
+		}
+		signatures[i] = signer.Sign(message)
+	}
+
+	return Transaction{Signatures: signatures, Message: message}, nil
+}
+
+type messageHeader struct {
+	signatures       byte
+	readonlySigned   byte
+	readonlyUnsigned byte
+}
+
+// compileAccounts упорядочивает аккаунты так, как требует формат сообщения: подписанты с правом записи
+// (первым плательщик), подписанты только для чтения, затем остальные с правом записи и только для чтения
+func compileAccounts(feePayer PublicKey, instructions []Instruction) ([]PublicKey, messageHeader) {
+	type flags struct{ signer, writable bool }
+
+	order := []PublicKey{feePayer}
+	metas := map[PublicKey]*flags{feePayer: {signer: true, writable: true}}
+	add := func(key PublicKey, signer, writable bool) {
+		meta, ok := metas[key]
+		if !ok {
+			meta = &flags{}
+			metas[key] = meta
+			order = append(order, key)
+		}
+		meta.signer = meta.signer || signer
+		meta.writable = meta.writable || writable
+	}
+	for _, instruction := range instructions {
+		for _, account := range instruction.Accounts {
+			add(account.PublicKey, account.IsSigner, account.IsWritable)
+		}
+		add(instruction.ProgramID, false, false)
+	}
+
+	var header messageHeader
+	keys := make([]PublicKey, 0, len(order))
+	for _, group := range []flags{{true, true}, {true, false}, {false, true}, {false, false}} {
+		for _, key := range order {
+			if *metas[key] != group {
+				continue
+			}
+			keys = append(keys, key)
+			switch {
+			case group.signer && group.writable:
+				header.signatures++
+			case group.signer:
+				header.signatures++
+				header.readonlySigned++
+			case !group.writable:
+				header.readonlyUnsigned++
+			}
+		}
+	}
+	return keys, header
+}
+
+// appendCompactU16 дописывает длину в формате compact-u16: по 7 бит на байт, старший бит — продолжение
+func appendCompactU16(out []byte, n int) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
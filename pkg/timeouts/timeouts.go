@@ -0,0 +1,44 @@
+// Package timeouts bounds calls to external services (RPC nodes, AML and HTTP providers)
+// with context deadlines and classifies deadline errors regardless of where they surfaced.
+package timeouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// ErrDeadlineExceeded marks an external call that did not complete within its deadline
+var ErrDeadlineExceeded = errors.New("external call deadline exceeded")
+
+// WithTimeout bounds ctx by d. A non-positive d leaves the caller's deadline, if any, unchanged.
+func WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// IsTimeout reports whether err is a deadline error: an expired context,
+// a network timeout or an error already classified by Classify
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Classify wraps deadline errors of the named service with ErrDeadlineExceeded and returns other errors as is
+func Classify(service string, err error) error {
+	if !IsTimeout(err) || errors.Is(err, ErrDeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%s: %w: %w", service, ErrDeadlineExceeded, err)
+}
@@ -0,0 +1,56 @@
+package timeouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	ctx, cancel = WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.True(t, IsTimeout(ctx.Err()))
+}
+
+func TestClassify(t *testing.T) {
+	assert.NoError(t, Classify("rpc", nil))
+
+	other := errors.New("connection refused")
+	assert.Same(t, other, Classify("rpc", other))
+	assert.False(t, IsTimeout(other))
+	assert.False(t, IsTimeout(context.Canceled))
+
+	err := Classify("rpc", fmt.Errorf("eth_call: %w", context.DeadlineExceeded))
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "rpc: external call deadline exceeded: eth_call: context deadline exceeded", err.Error())
+
+	// Повторная классификация не добавляет префикс
+	assert.Equal(t, err, Classify("aml", err))
+}
+
+func TestClassifyHTTPClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.True(t, IsTimeout(err))
+	assert.ErrorIs(t, Classify("captcha", err), ErrDeadlineExceeded)
+}
@@ -0,0 +1,130 @@
+// Package ton implements the parts of The Open Network needed to accept jetton deposits:
+// addresses in raw and user-friendly form, bag-of-cells serialization, text comments
+// of transfer payloads and a toncenter API client.
+package ton
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAddress is returned for strings that are neither raw nor user-friendly TON addresses
+var ErrInvalidAddress = errors.New("invalid TON address")
+
+// Флаги первого байта адреса в user-friendly форме
+const (
+	tagBounceable    = 0x11
+	tagNonBounceable = 0x51
+	tagTestnetOnly   = 0x80
+)
+
+// Длина адреса в user-friendly форме: тег, воркчейн, хеш и CRC16, 36 байт в base64
+const friendlyAddressLength = 48
+
+// Address is a standard TON address: workchain and the 256-bit account hash
+type Address struct {
+	Workchain int8
+	Hash      [32]byte
+}
+
+// ParseAddress parses a raw address "0:4f2a..." or a user-friendly one "EQ..."/"UQ..." in base64 or base64url.
+// The checksum of the user-friendly form is verified, its bounce and testnet flags are ignored.
+func ParseAddress(s string) (Address, error) {
+	if workchain, hash, ok := strings.Cut(s, ":"); ok {
+		return parseRawAddress(workchain, hash)
+	}
+	return parseFriendlyAddress(s)
+}
+
+func parseRawAddress(workchain, hash string) (Address, error) {
+	wc, err := strconv.ParseInt(workchain, 10, 8)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w: workchain %q", ErrInvalidAddress, workchain)
+	}
+
+	decoded, err := hex.DecodeString(hash)
+	if err != nil || len(decoded) != 32 {
+		return Address{}, fmt.Errorf("%w: account hash %q", ErrInvalidAddress, hash)
+	}
+
+	address := Address{Workchain: int8(wc)}
+	copy(address.Hash[:], decoded)
+	return address, nil
+}
+
+func parseFriendlyAddress(s string) (Address, error) {
+	if len(s) != friendlyAddressLength {
+		return Address{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+
+	// Кошельки отдают адреса и в base64, и в base64url
+	data, err := base64.URLEncoding.DecodeString(strings.NewReplacer("+", "-", "/", "_").Replace(s))
+	if err != nil || len(data) != 36 {
+		return Address{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+
+	if tag := data[0] &^ tagTestnetOnly; tag != tagBounceable && tag != tagNonBounceable {
+		return Address{}, fmt.Errorf("%w: unknown tag 0x%02x in %q", ErrInvalidAddress, data[0], s)
+	}
+	if crc16(data[:34]) != binary.BigEndian.Uint16(data[34:]) {
+		return Address{}, fmt.Errorf("%w: checksum mismatch in %q", ErrInvalidAddress, s)
+	}
+
+	address := Address{Workchain: int8(data[1])}
+	copy(address.Hash[:], data[2:34])
+	return address, nil
+}
+
+// Raw returns the raw form "workchain:hex", which toncenter uses in responses
+func (a Address) Raw() string {
+	return fmt.Sprintf("%d:%s", a.Workchain, hex.EncodeToString(a.Hash[:]))
+}
+
+// Friendly returns the user-friendly base64url form. Wallets should receive non-bounceable addresses ("UQ..."),
+// contracts bounceable ones ("EQ...").
+func (a Address) Friendly(bounceable, testnet bool) string {
+	data := make([]byte, 36)
+	data[0] = tagNonBounceable
+	if bounceable {
+		data[0] = tagBounceable
+	}
+	if testnet {
+		data[0] |= tagTestnetOnly
+	}
+	data[1] = byte(a.Workchain)
+	copy(data[2:34], a.Hash[:])
+	binary.BigEndian.PutUint16(data[34:], crc16(data[:34]))
+
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// String returns the bounceable mainnet user-friendly form
+func (a Address) String() string {
+	return a.Friendly(true, false)
+}
+
+// IsZero reports whether the address is empty
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// crc16 — CRC-16/XMODEM, которым защищена user-friendly форма адреса
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,58 @@
+package ton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Мастер-контракт USDT в сети TON
+const (
+	usdtMasterFriendly = "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs"
+	usdtMasterRaw      = "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe"
+)
+
+func TestParseAddress(t *testing.T) {
+	friendly, err := ParseAddress(usdtMasterFriendly)
+	require.NoError(t, err)
+	assert.Equal(t, usdtMasterRaw, friendly.Raw())
+	assert.Equal(t, usdtMasterFriendly, friendly.String())
+	assert.Equal(t, "UQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_p0p", friendly.Friendly(false, false))
+
+	raw, err := ParseAddress(usdtMasterRaw)
+	require.NoError(t, err)
+	assert.Equal(t, friendly, raw)
+
+	// Флаги bounce и testnet не влияют на сам адрес
+	for _, form := range []string{friendly.Friendly(false, false), friendly.Friendly(true, true), friendly.Friendly(false, true)} {
+		parsed, err := ParseAddress(form)
+		require.NoError(t, err, form)
+		assert.Equal(t, friendly, parsed, form)
+	}
+
+	masterchain, err := ParseAddress("-1:" + usdtMasterRaw[2:])
+	require.NoError(t, err)
+	assert.Equal(t, int8(-1), masterchain.Workchain)
+	assert.Equal(t, "-1:"+usdtMasterRaw[2:], masterchain.Raw())
+
+	back, err := ParseAddress(masterchain.String())
+	require.NoError(t, err)
+	assert.Equal(t, masterchain, back)
+}
+
+func TestParseAddressInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"0:b113",
+		"x:" + usdtMasterRaw[2:],
+		"0:" + usdtMasterRaw[2:65] + "z",
+		"EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDt", // Неверная контрольная сумма
+		"EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id",
+		"0x55d398326f99059fF775485246999027B3197955",
+	}
+	for _, input := range invalid {
+		_, err := ParseAddress(input)
+		assert.ErrorIs(t, err, ErrInvalidAddress, input)
+	}
+}
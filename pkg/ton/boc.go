@@ -0,0 +1,386 @@
+package ton
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/bits"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidBOC is returned for malformed or unsupported bags of cells
+	ErrInvalidBOC = errors.New("invalid TON bag of cells")
+	// ErrNotComment is returned when a payload is not a text comment, e.g. an encrypted comment or a contract call
+	ErrNotComment = errors.New("payload is not a text comment")
+)
+
+const (
+	bocMagic = 0xb5ee9c72
+	// Ограничение на число ячеек разбираемого BOC: полезная нагрузка перевода намного меньше
+	maxBOCCells = 1024
+	maxCellBits = 1023
+	maxCellRefs = 4
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Cell is an ordinary TON cell: up to 1023 data bits and up to 4 references to other cells
+type Cell struct {
+	data []byte // Биты по старшинству, неиспользуемые биты последнего байта нулевые
+	bits int
+	refs []*Cell
+}
+
+// Bits returns the number of data bits
+func (c *Cell) Bits() int {
+	return c.bits
+}
+
+// Refs returns the referenced cells
+func (c *Cell) Refs() []*Cell {
+	return c.refs
+}
+
+// cellBuilder собирает ячейку побитно
+type cellBuilder struct {
+	data []byte
+	bits int
+	refs []*Cell
+}
+
+func (b *cellBuilder) storeBit(bit bool) {
+	if b.bits%8 == 0 {
+		b.data = append(b.data, 0)
+	}
+	if bit {
+		b.data[b.bits/8] |= 0x80 >> (b.bits % 8)
+	}
+	b.bits++
+}
+
+func (b *cellBuilder) storeUint(value uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.storeBit(value>>i&1 == 1)
+	}
+}
+
+func (b *cellBuilder) storeBytes(p []byte) {
+	for _, v := range p {
+		b.storeUint(uint64(v), 8)
+	}
+}
+
+// storeAddress записывает addr_std$10 anycast:nothing$0 workchain_id:int8 address:bits256
+func (b *cellBuilder) storeAddress(a Address) {
+	b.storeUint(0b100, 3)
+	b.storeUint(uint64(uint8(a.Workchain)), 8)
+	b.storeBytes(a.Hash[:])
+}
+
+func (b *cellBuilder) cell() *Cell {
+	return &Cell{data: b.data, bits: b.bits, refs: b.refs}
+}
+
+// cellSlice читает данные ячейки побитно
+type cellSlice struct {
+	cell *Cell
+	pos  int
+}
+
+func (s *cellSlice) loadUint(n int) (uint64, error) {
+	if n > 64 || s.pos+n > s.cell.bits {
+		return 0, fmt.Errorf("%w: cannot read %d bits at %d of %d", ErrInvalidBOC, n, s.pos, s.cell.bits)
+	}
+
+	var value uint64
+	for range n {
+		bit := s.cell.data[s.pos/8] >> (7 - s.pos%8) & 1
+		value = value<<1 | uint64(bit)
+		s.pos++
+	}
+	return value, nil
+}
+
+// loadAddress читает адрес addr_std без anycast
+func (s *cellSlice) loadAddress() (Address, error) {
+	prefix, err := s.loadUint(3)
+	if err != nil {
+		return Address{}, err
+	}
+	if prefix != 0b100 {
+		return Address{}, fmt.Errorf("%w: not a standard address", ErrInvalidAddress)
+	}
+
+	workchain, err := s.loadUint(8)
+	if err != nil {
+		return Address{}, err
+	}
+	address := Address{Workchain: int8(uint8(workchain))}
+	for i := range address.Hash {
+		v, err := s.loadUint(8)
+		if err != nil {
+			return Address{}, err
+		}
+		address.Hash[i] = byte(v)
+	}
+	return address, nil
+}
+
+// SerializeBOC serializes the tree of cells with the given root, without index and checksum
+func SerializeBOC(root *Cell) []byte {
+	// Ячейки в прямом порядке обхода: ссылки всегда указывают на ячейки с большим индексом
+	var cells []*Cell
+	var collect func(c *Cell)
+	collect = func(c *Cell) {
+		cells = append(cells, c)
+		for _, ref := range c.refs {
+			collect(ref)
+		}
+	}
+	collect(root)
+
+	refSize := byteSize(uint64(len(cells)))
+	index := make(map[*Cell]int, len(cells))
+	for i, c := range cells {
+		index[c] = i
+	}
+
+	var payload []byte
+	for _, c := range cells {
+		payload = append(payload, byte(len(c.refs)), byte((c.bits+7)/8+c.bits/8))
+		data := append([]byte(nil), c.data...)
+		if c.bits%8 != 0 {
+			// Неполный байт завершается единичным битом
+			data[len(data)-1] |= 0x80 >> (c.bits % 8)
+		}
+		payload = append(payload, data...)
+		for _, ref := range c.refs {
+			payload = appendUint(payload, uint64(index[ref]), refSize)
+		}
+	}
+
+	offSize := byteSize(uint64(len(payload)))
+	out := binary.BigEndian.AppendUint32(nil, bocMagic)
+	out = append(out, byte(refSize), byte(offSize))
+	out = appendUint(out, uint64(len(cells)), refSize)
+	out = appendUint(out, 1, refSize) // Один корень
+	out = appendUint(out, 0, refSize) // Без отсутствующих ячеек
+	out = appendUint(out, uint64(len(payload)), offSize)
+	out = appendUint(out, 0, refSize) // Корень — первая ячейка
+	return append(out, payload...)
+}
+
+// ParseBOC deserializes a bag of cells and returns its first root. Exotic cells are rejected.
+func ParseBOC(data []byte) (*Cell, error) {
+	r := &byteReader{data: data}
+	if magic := r.uint(4); r.err != nil || magic != bocMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidBOC)
+	}
+
+	flags := r.uint(1)
+	hasIndex, hasCRC := flags&0x80 != 0, flags&0x40 != 0
+	refSize := int(flags & 0x07)
+	offSize := int(r.uint(1))
+	if refSize == 0 || refSize > 4 || offSize == 0 || offSize > 8 {
+		return nil, fmt.Errorf("%w: bad header sizes", ErrInvalidBOC)
+	}
+
+	cellsCount := int(r.uint(refSize))
+	rootsCount := int(r.uint(refSize))
+	r.uint(refSize) // Отсутствующие ячейки не поддерживаются и не используются в переводах
+	totalSize := int(r.uint(offSize))
+	if r.err != nil || cellsCount == 0 || cellsCount > maxBOCCells || rootsCount == 0 || rootsCount > cellsCount {
+		return nil, fmt.Errorf("%w: bad header", ErrInvalidBOC)
+	}
+
+	root := int(r.uint(refSize))
+	r.skip((rootsCount - 1) * refSize)
+	if hasIndex {
+		r.skip(cellsCount * offSize)
+	}
+	payload := r.next(totalSize)
+	if hasCRC {
+		checksum := r.next(4)
+		if r.err == nil && crc32.Checksum(data[:len(data)-4], castagnoli) != binary.LittleEndian.Uint32(checksum) {
+			return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidBOC)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if root >= cellsCount {
+		return nil, fmt.Errorf("%w: root index %d out of range", ErrInvalidBOC, root)
+	}
+
+	type rawCell struct {
+		data []byte
+		bits int
+		refs []int
+	}
+	raw := make([]rawCell, cellsCount)
+	p := &byteReader{data: payload}
+	for i := range raw {
+		d1, d2 := p.uint(1), p.uint(1)
+		if d1&0x08 != 0 {
+			return nil, fmt.Errorf("%w: exotic cell %d", ErrInvalidBOC, i)
+		}
+		if d1&0x10 != 0 {
+			// Сохраненные хеши и глубины ячейки не нужны для чтения
+			p.skip((bits.OnesCount8(uint8(d1>>5)) + 1) * (32 + 2))
+		}
+		refsCount := int(d1 & 0x07)
+		if refsCount > maxCellRefs {
+			return nil, fmt.Errorf("%w: cell %d has %d refs", ErrInvalidBOC, i, refsCount)
+		}
+
+		cellData := append([]byte(nil), p.next(int(d2+1)/2)...)
+		cellBits := len(cellData) * 8
+		if d2%2 == 1 && len(cellData) > 0 {
+			last := cellData[len(cellData)-1]
+			if last == 0 {
+				return nil, fmt.Errorf("%w: cell %d has no completion tag", ErrInvalidBOC, i)
+			}
+			trailing := bits.TrailingZeros8(last)
+			cellBits -= trailing + 1
+			cellData[len(cellData)-1] &^= 1 << trailing
+		}
+		if cellBits > maxCellBits {
+			return nil, fmt.Errorf("%w: cell %d has %d bits", ErrInvalidBOC, i, cellBits)
+		}
+
+		refs := make([]int, refsCount)
+		for j := range refs {
+			refs[j] = int(p.uint(refSize))
+			if refs[j] <= i || refs[j] >= cellsCount {
+				return nil, fmt.Errorf("%w: cell %d references cell %d", ErrInvalidBOC, i, refs[j])
+			}
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		raw[i] = rawCell{data: cellData, bits: cellBits, refs: refs}
+	}
+
+	// Ссылки указывают только вперед, поэтому ячейки собираются с конца
+	cells := make([]*Cell, cellsCount)
+	for i := cellsCount - 1; i >= 0; i-- {
+		cell := &Cell{data: raw[i].data, bits: raw[i].bits}
+		for _, ref := range raw[i].refs {
+			cell.refs = append(cell.refs, cells[ref])
+		}
+		cells[i] = cell
+	}
+	return cells[root], nil
+}
+
+// Comment returns the text of a comment payload: a zero 32-bit opcode followed by UTF-8 text,
+// continued in the first reference of each cell ("snake" format).
+func (c *Cell) Comment() (string, error) {
+	s := &cellSlice{cell: c}
+	op, err := s.loadUint(32)
+	if err != nil || op != 0 {
+		return "", ErrNotComment
+	}
+
+	var text []byte
+	for cell, offset := c, 4; cell != nil; offset = 0 {
+		if cell.bits%8 != 0 {
+			return "", fmt.Errorf("%w: comment is not byte aligned", ErrInvalidBOC)
+		}
+		text = append(text, cell.data[offset:cell.bits/8]...)
+
+		if len(cell.refs) == 0 {
+			break
+		}
+		cell = cell.refs[0]
+	}
+
+	if !utf8.Valid(text) {
+		return "", fmt.Errorf("%w: comment is not valid UTF-8", ErrInvalidBOC)
+	}
+	return string(text), nil
+}
+
+// DecodeComment parses a serialized payload and returns its text comment
+func DecodeComment(boc []byte) (string, error) {
+	cell, err := ParseBOC(boc)
+	if err != nil {
+		return "", err
+	}
+	return cell.Comment()
+}
+
+// NewComment builds a text comment payload, splitting long text into a chain of cells
+func NewComment(text string) *Cell {
+	data := append(make([]byte, 4), text...)
+
+	// Ячейка вмещает 127 целых байт, в первой из них также опкод
+	const cellBytes = maxCellBits / 8
+	var chunks [][]byte
+	for len(data) > cellBytes {
+		chunks = append(chunks, data[:cellBytes])
+		data = data[cellBytes:]
+	}
+	chunks = append(chunks, data)
+
+	var next *Cell
+	for i := len(chunks) - 1; i >= 0; i-- {
+		b := &cellBuilder{}
+		b.storeBytes(chunks[i])
+		if next != nil {
+			b.refs = []*Cell{next}
+		}
+		next = b.cell()
+	}
+	return next
+}
+
+// byteReader читает поля BOC с проверкой границ, первая ошибка сохраняется
+type byteReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *byteReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidBOC)
+		return nil
+	}
+	p := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return p
+}
+
+func (r *byteReader) skip(n int) {
+	r.next(n)
+}
+
+func (r *byteReader) uint(n int) uint64 {
+	var value uint64
+	for _, b := range r.next(n) {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func appendUint(out []byte, value uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		out = append(out, byte(value>>(8*i)))
+	}
+	return out
+}
+
+// byteSize возвращает число байт, достаточное для записи value
+func byteSize(value uint64) int {
+	size := 1
+	for value >= 1<<(8*size) && size < 8 {
+		size++
+	}
+	return size
+}
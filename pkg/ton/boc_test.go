@@ -0,0 +1,87 @@
+package ton
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeComment(t *testing.T) {
+	// Одна ячейка: нулевой опкод и текст "hello"
+	boc, err := hex.DecodeString("b5ee9c7201010101000b0000120000000068656c6c6f")
+	require.NoError(t, err)
+
+	comment, err := DecodeComment(boc)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", comment)
+
+	assert.Equal(t, boc, SerializeBOC(NewComment("hello")))
+}
+
+func TestCommentRoundTrip(t *testing.T) {
+	texts := []string{"", "4294967295", "Оплата заказа №17", strings.Repeat("memo ", 80)}
+	for _, text := range texts {
+		comment, err := DecodeComment(SerializeBOC(NewComment(text)))
+		require.NoError(t, err, text)
+		assert.Equal(t, text, comment)
+	}
+
+	// Длинный текст продолжается в цепочке ячеек
+	long := NewComment(strings.Repeat("x", 300))
+	require.Len(t, long.Refs(), 1)
+	assert.Equal(t, 127*8, long.Bits())
+	require.Len(t, long.Refs()[0].Refs(), 1)
+}
+
+func TestDecodeCommentNotComment(t *testing.T) {
+	b := &cellBuilder{}
+	b.storeUint(0x0f8a7ea5, 32) // Опкод transfer жетона вместо комментария
+	b.storeUint(1, 64)
+	_, err := DecodeComment(SerializeBOC(b.cell()))
+	assert.ErrorIs(t, err, ErrNotComment)
+
+	short := &cellBuilder{}
+	short.storeUint(0, 16)
+	_, err = DecodeComment(SerializeBOC(short.cell()))
+	assert.ErrorIs(t, err, ErrNotComment)
+}
+
+func TestParseBOCInvalid(t *testing.T) {
+	valid, err := hex.DecodeString("b5ee9c7201010101000b0000120000000068656c6c6f")
+	require.NoError(t, err)
+
+	invalid := [][]byte{
+		nil,
+		valid[:4],
+		valid[:len(valid)-1],
+		append([]byte{0xb5, 0xee, 0x9c, 0x73}, valid[4:]...),
+	}
+	for _, data := range invalid {
+		_, err := ParseBOC(data)
+		assert.ErrorIs(t, err, ErrInvalidBOC, hex.EncodeToString(data))
+	}
+
+	// Ссылка на предыдущую ячейку образовала бы цикл
+	cyclic, err := hex.DecodeString("b5ee9c7201010201000600010001010000")
+	require.NoError(t, err)
+	_, err = ParseBOC(cyclic)
+	assert.ErrorIs(t, err, ErrInvalidBOC)
+}
+
+func TestAddressCell(t *testing.T) {
+	address, err := ParseAddress(usdtMasterFriendly)
+	require.NoError(t, err)
+
+	b := &cellBuilder{}
+	b.storeAddress(address)
+	cell, err := ParseBOC(SerializeBOC(b.cell()))
+	require.NoError(t, err)
+	assert.Equal(t, 267, cell.Bits())
+
+	loaded, err := (&cellSlice{cell: cell}).loadAddress()
+	require.NoError(t, err)
+	assert.Equal(t, address, loaded)
+}
@@ -0,0 +1,200 @@
+package ton
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// ErrNotFound is returned when toncenter has no data for the requested account
+var ErrNotFound = errors.New("ton: not found")
+
+// Client talks to the toncenter v3 indexer API, e.g. https://toncenter.com/api/v3
+type Client struct {
+	baseURL string
+	apiKey  string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewClient creates a toncenter client. The API key is optional, without it requests are rate limited.
+// Each request is bounded by timeout, 0 disables the deadline.
+func NewClient(baseURL, apiKey string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+}
+
+// JettonTransfer is an indexed jetton transfer notification as reported by toncenter.
+// Addresses are in raw form, the payload is a base64 bag of cells.
+type JettonTransfer struct {
+	QueryID            string  `json:"query_id"`
+	Source             string  `json:"source"`      // Владелец кошелька отправителя
+	Destination        string  `json:"destination"` // Владелец кошелька получателя
+	Amount             string  `json:"amount"`      // В минимальных единицах жетона
+	SourceWallet       string  `json:"source_wallet"`
+	JettonMaster       string  `json:"jetton_master"`
+	TransactionHash    string  `json:"transaction_hash"`
+	TransactionLT      string  `json:"transaction_lt"`
+	TransactionNow     int64   `json:"transaction_now"`
+	TransactionAborted bool    `json:"transaction_aborted"`
+	ForwardPayload     *string `json:"forward_payload"`
+}
+
+// LT returns the logical time of the transaction that received the transfer
+func (t JettonTransfer) LT() (uint64, error) {
+	return strconv.ParseUint(t.TransactionLT, 10, 64)
+}
+
+// Hash returns the 32-byte hash of the transaction that received the transfer
+func (t JettonTransfer) Hash() ([]byte, error) {
+	return decodeBase64(t.TransactionHash, 32)
+}
+
+// Comment returns the text comment attached to the transfer, or ErrNotComment if there is none
+func (t JettonTransfer) Comment() (string, error) {
+	if t.ForwardPayload == nil || *t.ForwardPayload == "" {
+		return "", ErrNotComment
+	}
+	boc, err := decodeBase64(*t.ForwardPayload, 0)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidBOC, err)
+	}
+	return DecodeComment(boc)
+}
+
+// IncomingJettonTransfers returns transfers received by the jetton wallet with logical time after afterLT,
+// oldest first. Filtering by the jetton wallet rather than the owner excludes fake jettons
+// that imitate the master: only the genuine master deploys this wallet.
+func (c *Client) IncomingJettonTransfers(ctx context.Context, jettonWallet Address, afterLT uint64, limit int) ([]JettonTransfer, error) {
+	query := url.Values{}
+	query.Set("jetton_wallet", jettonWallet.Raw())
+	query.Set("direction", "in")
+	query.Set("start_lt", strconv.FormatUint(afterLT+1, 10))
+	query.Set("sort", "asc")
+	query.Set("limit", strconv.Itoa(limit))
+
+	var response struct {
+		JettonTransfers []JettonTransfer `json:"jetton_transfers"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/jetton/transfers?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.JettonTransfers, nil
+}
+
+type stackEntry struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// JettonWalletAddress derives the jetton wallet of the owner by calling get_wallet_address of the jetton master
+func (c *Client) JettonWalletAddress(ctx context.Context, master, owner Address) (Address, error) {
+	arg := &cellBuilder{}
+	arg.storeAddress(owner)
+
+	request := struct {
+		Address string       `json:"address"`
+		Method  string       `json:"method"`
+		Stack   []stackEntry `json:"stack"`
+	}{
+		Address: master.Raw(),
+		Method:  "get_wallet_address",
+		Stack:   []stackEntry{{Type: "slice", Value: base64.StdEncoding.EncodeToString(SerializeBOC(arg.cell()))}},
+	}
+
+	var response struct {
+		ExitCode int          `json:"exit_code"`
+		Stack    []stackEntry `json:"stack"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/runGetMethod", request, &response); err != nil {
+		return Address{}, err
+	}
+	if response.ExitCode != 0 {
+		return Address{}, fmt.Errorf("ton: get_wallet_address of %s failed with exit code %d", master, response.ExitCode)
+	}
+	if len(response.Stack) == 0 {
+		return Address{}, fmt.Errorf("ton: get_wallet_address of %s returned an empty stack", master)
+	}
+
+	boc, err := decodeBase64(response.Stack[0].Value, 0)
+	if err != nil {
+		return Address{}, fmt.Errorf("ton: invalid get_wallet_address result: %w", err)
+	}
+	cell, err := ParseBOC(boc)
+	if err != nil {
+		return Address{}, err
+	}
+	return (&cellSlice{cell: cell}).loadAddress()
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("ton: failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	ctx, cancel := timeouts.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("ton: failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ton: request failed: %w", timeouts.Classify("toncenter", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ton: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ton: failed to decode response: %w", timeouts.Classify("toncenter", err))
+	}
+	return nil
+}
+
+// decodeBase64 декодирует base64 и base64url, с дополнением и без. Нулевая length не проверяет длину.
+func decodeBase64(s string, length int) ([]byte, error) {
+	s = strings.TrimRight(strings.NewReplacer("-", "+", "_", "/").Replace(s), "=")
+	data, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if length > 0 && len(data) != length {
+		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(data))
+	}
+	return data, nil
+}
@@ -0,0 +1,76 @@
+// Package webhook delivers JSON payloads signed with HMAC-SHA256 to external systems.
+// The receiver verifies the SignatureHeader against the raw body with the shared secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sand/crypto-p2p-trading-app/backend/pkg/timeouts"
+)
+
+// SignatureHeader carries "sha256=<hex HMAC of the body>"
+const SignatureHeader = "X-Signature"
+
+// Sign returns the hex HMAC-SHA256 of the body with the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the header value is a valid signature of the body
+func Verify(secret string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, body)))
+}
+
+// Client posts signed payloads
+type Client struct {
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewClient creates a webhook client. Each delivery is bounded by timeout, 0 disables the deadline.
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+}
+
+// Send posts the JSON body with its signature. Any status outside 2xx is an error, so the caller can retry.
+func (c *Client) Send(ctx context.Context, url string, body []byte, signature string) error {
+	ctx, cancel := timeouts.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+signature)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: delivery failed: %w", timeouts.Classify("webhook", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
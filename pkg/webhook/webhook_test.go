@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"day":"2026-10-15"}`)
+	signature := Sign("secret", body)
+
+	if !Verify("secret", body, "sha256="+signature) {
+		t.Fatal("valid signature rejected")
+	}
+	if Verify("other", body, "sha256="+signature) {
+		t.Fatal("signature accepted with a different secret")
+	}
+	if Verify("secret", []byte(`{"day":"2026-10-16"}`), "sha256="+signature) {
+		t.Fatal("signature accepted for a different body")
+	}
+	if Verify("secret", body, signature) {
+		t.Fatal("signature accepted without the sha256= prefix")
+	}
+}
+
+func TestSendSignsBody(t *testing.T) {
+	body := []byte(`{"deposits":3}`)
+
+	var received bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		if !Verify("secret", payload, r.Header.Get(SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = true
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second)
+	if err := client.Send(context.Background(), server.URL, body, Sign("secret", body)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if !received {
+		t.Fatal("payload not received")
+	}
+
+	if err := client.Send(context.Background(), server.URL, body, Sign("wrong", body)); err == nil {
+		t.Fatal("expected an error for a rejected delivery")
+	}
+}